@@ -6,12 +6,15 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"syscall"
 
 	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/logging"
 	"github.com/a3tai/mcp-pdf-reader/internal/mcp"
 	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+	"github.com/a3tai/mcp-pdf-reader/internal/samples"
 )
 
 var (
@@ -20,18 +23,41 @@ var (
 	gitCommit = "unknown" // This will be set by build flags
 )
 
-// setupLogging configures logging based on the server mode
-func setupLogging(cfg *config.Config) {
-	if cfg.IsStdioMode() {
-		// In stdio mode, redirect log output to stderr to avoid interfering with MCP protocol
-		log.SetOutput(os.Stderr)
-		// Reduce log verbosity in stdio mode unless debug is enabled
-		if !cfg.IsDebug() {
-			log.SetOutput(os.NewFile(0, os.DevNull))
+// logger records the server's lifecycle; components log under their own names
+var logger = logging.Component("main")
+
+// setupLogging installs the structured logger. Logs always go to stderr so that stdout
+// carries only the MCP protocol in stdio mode; server mode adds the source location.
+func setupLogging(cfg *config.Config) error {
+	return logging.Configure(logging.Options{
+		Level:  cfg.LogLevel,
+		Format: cfg.LogFormat,
+		Output: os.Stderr,
+		Source: cfg.IsServerMode(),
+	})
+}
+
+// fatal logs an error and exits
+func fatal(msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// downloadSamples fetches the sample PDF corpus into the PDF directory; failures are logged, not fatal
+func downloadSamples(ctx context.Context, cfg *config.Config) {
+	dir := filepath.Join(cfg.PDFDirectory, samples.DefaultSubdirectory)
+	results, err := samples.NewDownloader(cfg.MaxFileSize).Download(ctx, dir, samples.DefaultCorpus())
+	if err != nil {
+		logger.Warn("failed to download sample PDFs", "error", err)
+		return
+	}
+
+	for _, result := range results {
+		if result.Status == samples.StatusFailed {
+			logger.Warn("sample not downloaded", "sample", result.Sample.Name, "error", result.Error)
+		} else {
+			logger.Info("sample ready", "sample", result.Sample.Name, "status", result.Status, "path", result.Path)
 		}
-	} else {
-		// In server mode, use normal stdout logging with more detail
-		log.SetFlags(log.LstdFlags | log.Lshortfile)
 	}
 }
 
@@ -50,24 +76,23 @@ func runServerMode(ctx context.Context, cancel context.CancelFunc, server *mcp.S
 	// Wait for shutdown signal or server error
 	select {
 	case sig := <-signalCh:
-		log.Printf("Received signal: %s", sig)
-		log.Println("Initiating graceful shutdown...")
+		logger.Info("received signal, shutting down", "signal", sig.String())
 		cancel()
 
 		// Wait for server to shutdown
 		if err := <-serverErrCh; err != nil {
-			log.Printf("Server shutdown with error: %v", err)
+			logger.Error("server shutdown with error", "error", err)
 			os.Exit(1)
 		}
 
 	case err := <-serverErrCh:
 		if err != nil {
-			log.Printf("Server error: %v", err)
+			logger.Error("server error", "error", err)
 			os.Exit(1)
 		}
 	}
 
-	log.Println("Server stopped successfully")
+	logger.Info("server stopped")
 }
 
 // runStdioMode handles stdio mode execution
@@ -77,11 +102,8 @@ func runStdioMode(ctx context.Context, _ context.CancelFunc, server *mcp.Server)
 
 	// Start server and wait for it to complete
 	if err := server.Run(ctx); err != nil {
-		// Only log to stderr in debug mode to avoid protocol interference
-		if os.Getenv("DEBUG") != "" {
-			log.Printf("Server error: %v", err)
-		}
-		os.Exit(1)
+		// Logs go to stderr, so the error cannot interfere with the protocol on stdout
+		fatal("server error", err)
 	}
 }
 
@@ -98,24 +120,51 @@ func main() {
 	}
 
 	// Set up logging based on mode
-	setupLogging(cfg)
+	if err := setupLogging(cfg); err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
 
 	// Set version if it was provided during build
 	if version != "dev" {
 		cfg.Version = version
 	}
 
-	if cfg.IsDebug() && cfg.IsServerMode() {
-		log.Printf("Starting with configuration: %s", cfg.String())
+	if cfg.IsServerMode() {
+		logger.Debug("starting", "config", cfg.String())
+	}
+
+	if cfg.DownloadSamples {
+		downloadSamples(context.Background(), cfg)
+	}
+
+	escalationPolicy, err := pdf.ParseEscalationPolicy(cfg.EscalationPolicy)
+	if err != nil {
+		fatal("failed to load configuration", err)
+	}
+	classificationProfiles, err := pdf.LoadClassificationProfiles(cfg.ClassifierProfiles)
+	if err != nil {
+		fatal("failed to load configuration", err)
 	}
 
 	// Create PDF service
 	pdfService := pdf.NewService(cfg.MaxFileSize)
+	pdfService.SetEscalationPolicy(escalationPolicy)
+	pdfService.SetClassificationProfiles(classificationProfiles)
+	pdfService.SetMemoryMapping(cfg.MemoryMap)
+	pdfService.SetCacheSize(cfg.CacheSize)
+	pdfService.SetDocumentTTL(cfg.DocumentTTL)
+	pdfService.SetMemoryBudget(cfg.MemoryBudget)
+	pdfService.SetExtractionLimits(cfg.MaxPages, cfg.MaxElements, cfg.PageTimeout)
+	pdfService.SetCheckpointDir(cfg.CheckpointDir)
+	pdfService.SetOCR(cfg.OCREngine, cfg.OCRLanguage)
+	if cfg.FetchURLs {
+		pdfService.SetFetchDir(cfg.FetchDir)
+	}
 
 	// Create MCP server
 	server, err := mcp.NewServer(cfg, pdfService)
 	if err != nil {
-		log.Fatalf("Failed to create MCP server: %v", err)
+		fatal("failed to create MCP server", err)
 	}
 
 	// Set up context for graceful shutdown
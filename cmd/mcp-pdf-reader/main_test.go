@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"log"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/logging"
 )
 
 const (
@@ -137,101 +139,99 @@ func TestPrintVersionWithDefaults(t *testing.T) {
 	}
 }
 
-func TestSetupLogging_StdioMode(t *testing.T) {
-	// Save original log settings
-	originalOutput := log.Writer()
-	originalFlags := log.Flags()
+// captureOutput runs fn with *target, os.Stdout or os.Stderr, redirected to a file and
+// returns what was written
+func captureOutput(t *testing.T, target **os.File, fn func()) string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
 
+	original := *target
+	*target = file
 	defer func() {
-		log.SetOutput(originalOutput)
-		log.SetFlags(originalFlags)
+		*target = original
+		// Later tests must not write to the closed file
+		_ = logging.Configure(logging.Options{Output: io.Discard})
 	}()
+	fn()
 
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSetupLogging_StdioMode(t *testing.T) {
 	tests := []struct {
-		name     string
-		wantType string
-		config   *config.Config
-		isDebug  bool
+		name      string
+		config    *config.Config
+		wantDebug bool
 	}{
 		{
-			name: "stdio mode - debug enabled",
-			config: &config.Config{
-				Mode:     "stdio",
-				LogLevel: "debug",
-			},
-			isDebug:  true,
-			wantType: "stderr",
+			name:      "stdio mode - debug enabled",
+			config:    &config.Config{Mode: "stdio", LogLevel: "debug"},
+			wantDebug: true,
 		},
 		{
-			name: "stdio mode - debug disabled",
-			config: &config.Config{
-				Mode:     "stdio",
-				LogLevel: "info",
-			},
-			isDebug:  false,
-			wantType: "devnull",
+			name:   "stdio mode - debug disabled",
+			config: &config.Config{Mode: "stdio", LogLevel: "info"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			setupLogging(tt.config)
-
-			// Check that output was set appropriately
-			currentOutput := log.Writer()
-
-			switch tt.wantType {
-			case "stderr":
-				if currentOutput != os.Stderr {
-					t.Errorf("setupLogging() for stdio debug mode should set output to stderr")
-				}
-			case "devnull":
-				// For non-debug stdio mode, output should be set to devnull
-				// We can't easily test this directly, but we can verify it's not stderr
-				if currentOutput == os.Stderr {
-					t.Errorf("setupLogging() for stdio non-debug mode should not use stderr")
+			// Capturing stdout as well shows that nothing reaches the protocol stream
+			var stderr string
+			stdout := captureOutput(t, &os.Stdout, func() {
+				stderr = captureOutput(t, &os.Stderr, func() {
+					if err := setupLogging(tt.config); err != nil {
+						t.Fatalf("setupLogging() unexpected error = %v", err)
+					}
+					logging.Component("test").Debug("debug record")
+					logging.Component("test").Info("info record")
+					log.Printf("standard record")
+				})
+			})
+
+			if stdout != "" {
+				t.Errorf("setupLogging() wrote %q to stdout, want nothing", stdout)
+			}
+			for _, want := range []string{"info record", "component=test", "standard record"} {
+				if !strings.Contains(stderr, want) {
+					t.Errorf("stderr = %q, want %q", stderr, want)
 				}
 			}
+			if strings.Contains(stderr, "debug record") != tt.wantDebug {
+				t.Errorf("stderr = %q, want debug records only at debug level", stderr)
+			}
 		})
 	}
 }
 
-func TestSetupLogging_ServerMode(t *testing.T) {
-	// Save original log settings
-	originalOutput := log.Writer()
-	originalFlags := log.Flags()
-
-	defer func() {
-		log.SetOutput(originalOutput)
-		log.SetFlags(originalFlags)
-	}()
+func TestSetupLogging_JSONFormat(t *testing.T) {
+	stderr := captureOutput(t, &os.Stderr, func() {
+		cfg := &config.Config{Mode: "server", LogLevel: "info", LogFormat: "json"}
+		if err := setupLogging(cfg); err != nil {
+			t.Fatalf("setupLogging() unexpected error = %v", err)
+		}
+		logging.Component("extraction").Warn("page skipped", "page", 3)
+	})
 
-	cfg := &config.Config{
-		Mode:     "server",
-		LogLevel: "info",
+	var record map[string]any
+	if err := json.Unmarshal([]byte(stderr), &record); err != nil {
+		t.Fatalf("stderr = %q, want one JSON record: %v", stderr, err)
 	}
-
-	setupLogging(cfg)
-
-	// In server mode, flags should include LstdFlags and Lshortfile
-	currentFlags := log.Flags()
-	expectedFlags := log.LstdFlags | log.Lshortfile
-
-	if currentFlags != expectedFlags {
-		t.Errorf("setupLogging() for server mode: flags = %v, want %v", currentFlags, expectedFlags)
+	if record["msg"] != "page skipped" || record["component"] != "extraction" || record["page"] != 3.0 ||
+		record["level"] != "WARN" || record["source"] == nil {
+		t.Errorf("record = %v, want the warning with its component, attributes, and source", record)
 	}
 }
 
 func TestSetupLogging_EdgeCases(t *testing.T) {
-	// Save original log settings
-	originalOutput := log.Writer()
-	originalFlags := log.Flags()
-
-	defer func() {
-		log.SetOutput(originalOutput)
-		log.SetFlags(originalFlags)
-	}()
-
 	// Test with nil config (this will panic, so we expect it)
 	t.Run("nil config", func(t *testing.T) {
 		defer func() {
@@ -240,22 +240,28 @@ func TestSetupLogging_EdgeCases(t *testing.T) {
 			}
 		}()
 
-		setupLogging(nil)
+		_ = setupLogging(nil)
 	})
 
 	// Test with empty mode
 	t.Run("empty mode", func(t *testing.T) {
-		cfg := &config.Config{
-			Mode: "",
-		}
-
 		defer func() {
 			if r := recover(); r != nil {
 				t.Errorf("setupLogging() with empty mode should not panic: %v", r)
 			}
 		}()
 
-		setupLogging(cfg)
+		captureOutput(t, &os.Stderr, func() {
+			if err := setupLogging(&config.Config{Mode: ""}); err != nil {
+				t.Errorf("setupLogging() unexpected error = %v", err)
+			}
+		})
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		if err := setupLogging(&config.Config{Mode: "stdio", LogFormat: "xml"}); err == nil {
+			t.Error("setupLogging() expected error for invalid log format")
+		}
 	})
 }
 
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/samples"
+	"github.com/spf13/pflag"
+)
+
+var version = "dev" // This will be set by build flags
+
+// tabPadding is the column padding used for tabular output
+const tabPadding = 2
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "samples":
+		err = runSamples(os.Args[2:])
+	case "version", "--version", "-v":
+		fmt.Printf("pdfctl %s\n", version)
+	case "help", "--help", "-h":
+		printUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
+		printUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printUsage prints the top-level usage message
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: pdfctl <command> [options]\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  samples list       List the sample PDF corpus\n")
+	fmt.Fprintf(os.Stderr, "  samples download   Download the sample PDF corpus\n")
+	fmt.Fprintf(os.Stderr, "  version            Print version information\n")
+}
+
+// runSamples handles the samples subcommands
+func runSamples(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("samples requires a subcommand: list or download")
+	}
+
+	defaultDir := os.Getenv("MCP_PDF_DIR")
+	if defaultDir == "" {
+		defaultDir = config.DefaultConfig().PDFDirectory
+	}
+
+	flags := pflag.NewFlagSet("samples "+args[0], pflag.ContinueOnError)
+	dir := flags.String("dir", defaultDir, "PDF directory; samples are stored in <dir>/samples")
+	manifest := flags.String("manifest", "", "JSON manifest replacing the built-in corpus")
+	category := flags.String("category", "", "Only include samples in this category")
+	maxFileSize := flags.Int64("max-file-size", config.DefaultMaxFileSize, "Maximum sample size in bytes")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	corpus := samples.DefaultCorpus()
+	if *manifest != "" {
+		loaded, err := samples.LoadManifest(*manifest)
+		if err != nil {
+			return err
+		}
+		corpus = loaded
+	}
+	corpus = samples.FilterByCategory(corpus, *category)
+
+	switch args[0] {
+	case "list":
+		listSamples(corpus)
+		return nil
+	case "download":
+		return downloadSamples(filepath.Join(*dir, samples.DefaultSubdirectory), corpus, *maxFileSize)
+	default:
+		return fmt.Errorf("unknown samples subcommand: %s", args[0])
+	}
+}
+
+// listSamples prints the corpus as a table
+func listSamples(corpus []samples.Sample) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCATEGORY\tDESCRIPTION")
+	for _, sample := range corpus {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", sample.Name, sample.Category, sample.Description)
+	}
+	w.Flush()
+}
+
+// downloadSamples downloads the corpus and reports the outcome of each sample
+func downloadSamples(dir string, corpus []samples.Sample, maxFileSize int64) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	results, err := samples.NewDownloader(maxFileSize).Download(ctx, dir, corpus)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		switch result.Status {
+		case samples.StatusFailed:
+			failed++
+			fmt.Printf("✗ %s: %s\n", result.Sample.Name, result.Error)
+		case samples.StatusExisting:
+			fmt.Printf("• %s: already present (%s)\n", result.Sample.Name, result.Path)
+		default:
+			fmt.Printf("✓ %s: %s %d bytes, sha256 %s (%s)\n",
+				result.Sample.Name, result.Status, result.Size, result.SHA256, result.Path)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d samples failed to download", failed, len(results))
+	}
+	return nil
+}
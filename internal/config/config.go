@@ -3,9 +3,13 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -17,15 +21,40 @@ const (
 	ModeServer = "server"
 
 	// Default values
-	DefaultPort        = 8080
-	DefaultHost        = "127.0.0.1"
-	DefaultLogLevel    = "info"
-	DefaultMaxFileSize = 100 * 1024 * 1024 // 100MB
+	DefaultPort         = 8080
+	DefaultHost         = "127.0.0.1"
+	DefaultLogLevel     = "info"
+	DefaultLogFormat    = "text"
+	DefaultMaxFileSize  = 100 * 1024 * 1024  // 100MB
+	DefaultMemoryBudget = 1024 * 1024 * 1024 // 1GB
+	DefaultWatchPoll    = 5 * time.Second
+	DefaultMaxPages     = 10000
+	DefaultMaxElements  = 250000
+	DefaultPageTimeout  = 30 * time.Second
+	DefaultDocumentTTL  = 30 * time.Second
+	DefaultOCREngine    = "tesseract"
+	DefaultOCRLanguage  = "eng"
+
+	// Concurrency defaults: tool calls running at once across all sessions and per session, and
+	// how long a call waits for a free slot
+	DefaultMaxConcurrent           = 16
+	DefaultMaxConcurrentPerSession = 4
+	DefaultQueueTimeout            = 30 * time.Second
+
+	// DefaultWorkspaceQuota is how much each session's workspace may hold, in bytes (512MB)
+	DefaultWorkspaceQuota = 512 * 1024 * 1024
+
+	// DefaultFetchSubdirectory is the directory, relative to the PDF directory, that receives
+	// the PDFs pdf_fetch_url downloads when no staging directory is given
+	DefaultFetchSubdirectory = "fetched"
 
 	// Directory permissions
 	DefaultDirPerm = 0o750
 )
 
+// ocrLanguagePattern matches tesseract language names such as "eng" or "deu+fra"
+var ocrLanguagePattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\+[A-Za-z0-9_]+)*$`)
+
 // Config holds all configuration for the PDF MCP server
 type Config struct {
 	// Server configuration
@@ -35,12 +64,60 @@ type Config struct {
 
 	// PDF configuration
 	PDFDirectory string
+	Roots        []string      // Further directories whose PDFs are exposed alongside PDFDirectory
+	WatchPoll    time.Duration // How often the directory is scanned for resource changes; 0 disables watching
 
 	// Application configuration
-	Version     string
-	ServerName  string
-	LogLevel    string
-	MaxFileSize int64 // Maximum PDF file size in bytes
+	Version      string
+	ServerName   string
+	LogLevel     string
+	LogFormat    string        // "text" or "json"
+	MaxFileSize  int64         // Maximum PDF file size in bytes
+	MemoryMap    bool          // Memory-map PDF files instead of buffered reads where supported
+	CacheSize    int64         // Extraction cache limit in bytes; 0 disables the cache
+	DocumentTTL  time.Duration // How long parsed documents stay open between tool calls; 0 disables reuse
+	MemoryBudget int64         // Memory extracted pages may hold across concurrent extractions, in bytes; 0 disables
+
+	// Tool configuration
+	Tools         []string // Tools to register; empty registers every tool
+	DisabledTools []string // Tools never registered, even when listed in Tools
+	ReadOnly      bool     // Withhold tools that produce modified documents and refuse to write output files
+
+	// Request configuration
+	RequestTimeout time.Duration // Time allowed for each extraction tool call; 0 means no limit
+	MaxPages       int           // Pages each structured extraction processes; 0 means no limit
+	MaxElements    int           // Elements each structured extraction returns; 0 means no limit
+	PageTimeout    time.Duration // Time allowed for each page of a structured extraction; 0 means no limit
+
+	// Concurrency configuration
+	MaxConcurrent           int           // Tool calls running at once across all sessions; 0 means no limit
+	MaxConcurrentPerSession int           // Tool calls one session may run at once; 0 means no limit
+	QueueTimeout            time.Duration // How long a call waits for a free slot before failing as busy; 0 fails at once
+	CheckpointDir           string        // Where extracted pages are saved so partial runs can resume; empty disables
+
+	// Quality configuration
+	EscalationPolicy   string // Escalation rules, e.g. "decode_quality<0.6:needs_human"
+	ClassifierProfiles string // JSON or YAML file of document classification profiles
+
+	// Workspace configuration
+	WorkspaceQuota int64 // Bytes each session's workspace may hold before outputs to it are refused; 0 means no limit
+
+	// Remote document configuration
+	FetchURLs bool   // Register pdf_fetch_url, which downloads PDFs over HTTPS
+	FetchDir  string // Staging directory for downloaded PDFs; defaults to a subdirectory of PDFDirectory
+
+	// OCR configuration
+	OCREngine   string // OCR program pdf_ocr_region runs, a name on the PATH or a path
+	OCRLanguage string // Language recognized when a request names none, e.g. "eng" or "deu+fra"
+
+	// Monitoring configuration
+	MetricsAddress string // Address of the HTTP listener serving Prometheus metrics at /metrics; empty disables it
+
+	// Onboarding configuration
+	DownloadSamples bool // Download the sample PDF corpus into the PDF directory at startup
+
+	// ConfigFile is the YAML or TOML file settings were read from; empty when none was given
+	ConfigFile string
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -52,14 +129,28 @@ func DefaultConfig() *Config {
 	}
 
 	return &Config{
-		Mode:         ModeStdio, // Default to stdio mode for MCP compatibility
-		Host:         DefaultHost,
-		Port:         DefaultPort,
-		PDFDirectory: currentDir,
-		Version:      "1.0.0",
-		ServerName:   "mcp-pdf-reader",
-		LogLevel:     DefaultLogLevel,
-		MaxFileSize:  DefaultMaxFileSize,
+		Mode:           ModeStdio, // Default to stdio mode for MCP compatibility
+		Host:           DefaultHost,
+		Port:           DefaultPort,
+		PDFDirectory:   currentDir,
+		Version:        "1.0.0",
+		ServerName:     "mcp-pdf-reader",
+		LogLevel:       DefaultLogLevel,
+		LogFormat:      DefaultLogFormat,
+		MaxFileSize:    DefaultMaxFileSize,
+		MemoryBudget:   DefaultMemoryBudget,
+		WatchPoll:      DefaultWatchPoll,
+		MaxPages:       DefaultMaxPages,
+		MaxElements:    DefaultMaxElements,
+		PageTimeout:    DefaultPageTimeout,
+		DocumentTTL:    DefaultDocumentTTL,
+		OCREngine:      DefaultOCREngine,
+		WorkspaceQuota: DefaultWorkspaceQuota,
+		OCRLanguage:    DefaultOCRLanguage,
+
+		MaxConcurrent:           DefaultMaxConcurrent,
+		MaxConcurrentPerSession: DefaultMaxConcurrentPerSession,
+		QueueTimeout:            DefaultQueueTimeout,
 	}
 }
 
@@ -81,6 +172,9 @@ func LoadFromFlags() (*Config, error) {
 
 	pflag.Parse()
 
+	if err := loadConfigFile(cfg); err != nil {
+		return nil, err
+	}
 	populateConfigFromViper(cfg)
 
 	// Expand paths if needed
@@ -89,6 +183,26 @@ func LoadFromFlags() (*Config, error) {
 			cfg.PDFDirectory = expandedPath
 		}
 	}
+	for i, root := range cfg.Roots {
+		if expandedPath, err := filepath.Abs(expandHome(root)); err == nil {
+			cfg.Roots[i] = expandedPath
+		}
+	}
+	if cfg.CheckpointDir != "" {
+		if expandedPath, err := filepath.Abs(cfg.CheckpointDir); err == nil {
+			cfg.CheckpointDir = expandedPath
+		}
+	}
+	if cfg.FetchDir == "" {
+		cfg.FetchDir = filepath.Join(cfg.PDFDirectory, DefaultFetchSubdirectory)
+	} else if expandedPath, err := filepath.Abs(cfg.FetchDir); err == nil {
+		cfg.FetchDir = expandedPath
+	}
+	if cfg.ClassifierProfiles != "" {
+		if expandedPath, err := filepath.Abs(cfg.ClassifierProfiles); err == nil {
+			cfg.ClassifierProfiles = expandedPath
+		}
+	}
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
@@ -107,26 +221,107 @@ func setupViperEnvironment(cfg *Config) {
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 
 	// Define flags with Viper
+	viper.SetDefault("config", cfg.ConfigFile)
 	viper.SetDefault("mode", cfg.Mode)
 	viper.SetDefault("host", cfg.Host)
 	viper.SetDefault("port", cfg.Port)
 	viper.SetDefault("dir", cfg.PDFDirectory)
+	viper.SetDefault("roots", strings.Join(cfg.Roots, ","))
+	viper.SetDefault("watch-poll", cfg.WatchPoll)
 	viper.SetDefault("log-level", cfg.LogLevel)
+	viper.SetDefault("log-format", cfg.LogFormat)
 	viper.SetDefault("max-file-size", cfg.MaxFileSize)
+	viper.SetDefault("mmap", cfg.MemoryMap)
+	viper.SetDefault("cache-size", cfg.CacheSize)
+	viper.SetDefault("document-ttl", cfg.DocumentTTL)
+	viper.SetDefault("memory-budget", cfg.MemoryBudget)
+	viper.SetDefault("tools", strings.Join(cfg.Tools, ","))
+	viper.SetDefault("disable-tools", strings.Join(cfg.DisabledTools, ","))
+	viper.SetDefault("read-only", cfg.ReadOnly)
+	viper.SetDefault("request-timeout", cfg.RequestTimeout)
+	viper.SetDefault("max-concurrent", cfg.MaxConcurrent)
+	viper.SetDefault("max-concurrent-per-session", cfg.MaxConcurrentPerSession)
+	viper.SetDefault("queue-timeout", cfg.QueueTimeout)
+	viper.SetDefault("max-pages", cfg.MaxPages)
+	viper.SetDefault("max-elements", cfg.MaxElements)
+	viper.SetDefault("page-timeout", cfg.PageTimeout)
+	viper.SetDefault("checkpoint-dir", cfg.CheckpointDir)
+	viper.SetDefault("download-samples", cfg.DownloadSamples)
+	viper.SetDefault("escalation-policy", cfg.EscalationPolicy)
+	viper.SetDefault("classifier-profiles", cfg.ClassifierProfiles)
+	viper.SetDefault("workspace-quota", cfg.WorkspaceQuota)
+	viper.SetDefault("fetch-urls", cfg.FetchURLs)
+	viper.SetDefault("fetch-dir", cfg.FetchDir)
+	viper.SetDefault("ocr-engine", cfg.OCREngine)
+	viper.SetDefault("ocr-language", cfg.OCRLanguage)
+	viper.SetDefault("metrics-addr", cfg.MetricsAddress)
 }
 
 // defineCommandLineFlags sets up all command line flags
 func defineCommandLineFlags(cfg *Config) {
+	pflag.String("config", cfg.ConfigFile,
+		"YAML or TOML file of settings keyed by flag name; flags and environment variables override it")
 	pflag.String("mode", cfg.Mode, "Server mode: 'stdio' for MCP standard I/O, 'server' for HTTP server")
 	pflag.String("host", cfg.Host, "Server host address (server mode only)")
 	pflag.Int("port", cfg.Port, "Server port (server mode only)")
 	pflag.String("dir", cfg.PDFDirectory, "Directory containing PDF files")
+	pflag.String("roots", strings.Join(cfg.Roots, ","),
+		"Comma-separated further directories whose PDFs are exposed alongside --dir, e.g. '~/Documents,/srv/project'")
+	pflag.Duration("watch-poll", cfg.WatchPoll,
+		"How often the PDF directory is scanned to notify resource subscribers of changes (0 disables)")
 	pflag.String("log-level", cfg.LogLevel, "Log level (debug, info, warn, error)")
+	pflag.String("log-format", cfg.LogFormat, "Log format (text, json); logs are written to stderr")
 	pflag.Int64("max-file-size", cfg.MaxFileSize, "Maximum PDF file size in bytes")
+	pflag.Bool("mmap", cfg.MemoryMap, "Memory-map PDF files on 64-bit platforms (falls back to buffered reads)")
+	pflag.Int64("cache-size", cfg.CacheSize, "Extraction cache size in bytes (0 disables caching)")
+	pflag.Duration("document-ttl", cfg.DocumentTTL,
+		"How long a parsed PDF stays open for later tool calls on the same file (0 disables reuse)")
+	pflag.Int64("memory-budget", cfg.MemoryBudget,
+		"Memory extracted pages may hold across concurrent extractions, in bytes; "+
+			"pages over it spill to disk or are left out of partial results (0 disables)")
+	pflag.String("tools", strings.Join(cfg.Tools, ","),
+		"Comma-separated allow-list of tools to register, e.g. 'pdf_read_file,pdf_search_directory' (empty registers all)")
+	pflag.String("disable-tools", strings.Join(cfg.DisabledTools, ","), "Comma-separated tools not to register")
+	pflag.Bool("read-only", cfg.ReadOnly,
+		"Withhold tools that produce modified documents and refuse output_dir/output_path arguments")
+	pflag.Duration("request-timeout", cfg.RequestTimeout,
+		"Time allowed for each extraction tool call before partial results are returned (0 disables)")
+	pflag.Int("max-concurrent", cfg.MaxConcurrent, "Tool calls running at once across all sessions (0 disables)")
+	pflag.Int("max-concurrent-per-session", cfg.MaxConcurrentPerSession,
+		"Tool calls one client session may run at once (0 disables)")
+	pflag.Duration("queue-timeout", cfg.QueueTimeout,
+		"How long a call waits for a free slot before failing as busy (0 fails at once)")
+	pflag.Int("max-pages", cfg.MaxPages,
+		"Pages each structured extraction processes; later pages are left out of a truncated result (0 disables)")
+	pflag.Int("max-elements", cfg.MaxElements,
+		"Elements each structured extraction returns; later elements are dropped from a truncated result (0 disables)")
+	pflag.Duration("page-timeout", cfg.PageTimeout,
+		"Time allowed for each page of a structured extraction before the page is skipped (0 disables)")
+	pflag.String("checkpoint-dir", cfg.CheckpointDir,
+		"Directory for extraction checkpoints that let partial results be resumed (empty disables)")
+	pflag.Bool("download-samples", cfg.DownloadSamples, "Download the sample PDF corpus into <dir>/samples at startup")
+	pflag.String("escalation-policy", cfg.EscalationPolicy,
+		"Comma-separated quality escalation rules, e.g. 'decode_quality<0.6:needs_human,decode_quality<0.2:reject'")
+	pflag.String("classifier-profiles", cfg.ClassifierProfiles,
+		"JSON or YAML file of document type profiles for pdf_classify_document, pdf_extract_invoice, "+
+			"pdf_extract_transactions, and pdf_extract_resume, added to the built-in ones")
+	pflag.Int64("workspace-quota", cfg.WorkspaceQuota,
+		"Bytes each session's workspace may hold before outputs to it are refused (0 disables)")
+	pflag.Bool("fetch-urls", cfg.FetchURLs,
+		"Register pdf_fetch_url, which downloads PDFs over HTTPS for the other tools to read")
+	pflag.String("fetch-dir", cfg.FetchDir, "Staging directory for downloaded PDFs (default: <dir>/fetched)")
+	pflag.String("ocr-engine", cfg.OCREngine, "OCR program pdf_ocr_region runs, a name on the PATH or a path")
+	pflag.String("ocr-language", cfg.OCRLanguage,
+		"Language pdf_ocr_region recognizes when a request names none, e.g. 'eng' or 'deu+fra'")
+	pflag.String("metrics-addr", cfg.MetricsAddress,
+		"Serve Prometheus metrics at /metrics on this address, e.g. '127.0.0.1:9464' (default: disabled)")
 }
 
 // bindFlagsToViper binds command line flags to viper configuration
 func bindFlagsToViper() error {
+	if err := viper.BindPFlag("config", pflag.Lookup("config")); err != nil {
+		return fmt.Errorf("failed to bind config flag: %w", err)
+	}
 	if err := viper.BindPFlag("mode", pflag.Lookup("mode")); err != nil {
 		return fmt.Errorf("failed to bind mode flag: %w", err)
 	}
@@ -139,12 +334,93 @@ func bindFlagsToViper() error {
 	if err := viper.BindPFlag("dir", pflag.Lookup("dir")); err != nil {
 		return fmt.Errorf("failed to bind dir flag: %w", err)
 	}
+	if err := viper.BindPFlag("roots", pflag.Lookup("roots")); err != nil {
+		return fmt.Errorf("failed to bind roots flag: %w", err)
+	}
+	if err := viper.BindPFlag("watch-poll", pflag.Lookup("watch-poll")); err != nil {
+		return fmt.Errorf("failed to bind watch-poll flag: %w", err)
+	}
 	if err := viper.BindPFlag("log-level", pflag.Lookup("log-level")); err != nil {
 		return fmt.Errorf("failed to bind log-level flag: %w", err)
 	}
+	if err := viper.BindPFlag("log-format", pflag.Lookup("log-format")); err != nil {
+		return fmt.Errorf("failed to bind log-format flag: %w", err)
+	}
 	if err := viper.BindPFlag("max-file-size", pflag.Lookup("max-file-size")); err != nil {
 		return fmt.Errorf("failed to bind max-file-size flag: %w", err)
 	}
+	if err := viper.BindPFlag("mmap", pflag.Lookup("mmap")); err != nil {
+		return fmt.Errorf("failed to bind mmap flag: %w", err)
+	}
+	if err := viper.BindPFlag("cache-size", pflag.Lookup("cache-size")); err != nil {
+		return fmt.Errorf("failed to bind cache-size flag: %w", err)
+	}
+	if err := viper.BindPFlag("document-ttl", pflag.Lookup("document-ttl")); err != nil {
+		return fmt.Errorf("failed to bind document-ttl flag: %w", err)
+	}
+	if err := viper.BindPFlag("memory-budget", pflag.Lookup("memory-budget")); err != nil {
+		return fmt.Errorf("failed to bind memory-budget flag: %w", err)
+	}
+	if err := viper.BindPFlag("tools", pflag.Lookup("tools")); err != nil {
+		return fmt.Errorf("failed to bind tools flag: %w", err)
+	}
+	if err := viper.BindPFlag("disable-tools", pflag.Lookup("disable-tools")); err != nil {
+		return fmt.Errorf("failed to bind disable-tools flag: %w", err)
+	}
+	if err := viper.BindPFlag("read-only", pflag.Lookup("read-only")); err != nil {
+		return fmt.Errorf("failed to bind read-only flag: %w", err)
+	}
+	if err := viper.BindPFlag("request-timeout", pflag.Lookup("request-timeout")); err != nil {
+		return fmt.Errorf("failed to bind request-timeout flag: %w", err)
+	}
+	if err := viper.BindPFlag("max-concurrent", pflag.Lookup("max-concurrent")); err != nil {
+		return fmt.Errorf("failed to bind max-concurrent flag: %w", err)
+	}
+	if err := viper.BindPFlag("max-concurrent-per-session", pflag.Lookup("max-concurrent-per-session")); err != nil {
+		return fmt.Errorf("failed to bind max-concurrent-per-session flag: %w", err)
+	}
+	if err := viper.BindPFlag("queue-timeout", pflag.Lookup("queue-timeout")); err != nil {
+		return fmt.Errorf("failed to bind queue-timeout flag: %w", err)
+	}
+	if err := viper.BindPFlag("max-pages", pflag.Lookup("max-pages")); err != nil {
+		return fmt.Errorf("failed to bind max-pages flag: %w", err)
+	}
+	if err := viper.BindPFlag("max-elements", pflag.Lookup("max-elements")); err != nil {
+		return fmt.Errorf("failed to bind max-elements flag: %w", err)
+	}
+	if err := viper.BindPFlag("page-timeout", pflag.Lookup("page-timeout")); err != nil {
+		return fmt.Errorf("failed to bind page-timeout flag: %w", err)
+	}
+	if err := viper.BindPFlag("checkpoint-dir", pflag.Lookup("checkpoint-dir")); err != nil {
+		return fmt.Errorf("failed to bind checkpoint-dir flag: %w", err)
+	}
+	if err := viper.BindPFlag("download-samples", pflag.Lookup("download-samples")); err != nil {
+		return fmt.Errorf("failed to bind download-samples flag: %w", err)
+	}
+	if err := viper.BindPFlag("escalation-policy", pflag.Lookup("escalation-policy")); err != nil {
+		return fmt.Errorf("failed to bind escalation-policy flag: %w", err)
+	}
+	if err := viper.BindPFlag("classifier-profiles", pflag.Lookup("classifier-profiles")); err != nil {
+		return fmt.Errorf("failed to bind classifier-profiles flag: %w", err)
+	}
+	if err := viper.BindPFlag("workspace-quota", pflag.Lookup("workspace-quota")); err != nil {
+		return fmt.Errorf("failed to bind workspace-quota flag: %w", err)
+	}
+	if err := viper.BindPFlag("fetch-urls", pflag.Lookup("fetch-urls")); err != nil {
+		return fmt.Errorf("failed to bind fetch-urls flag: %w", err)
+	}
+	if err := viper.BindPFlag("fetch-dir", pflag.Lookup("fetch-dir")); err != nil {
+		return fmt.Errorf("failed to bind fetch-dir flag: %w", err)
+	}
+	if err := viper.BindPFlag("ocr-engine", pflag.Lookup("ocr-engine")); err != nil {
+		return fmt.Errorf("failed to bind ocr-engine flag: %w", err)
+	}
+	if err := viper.BindPFlag("ocr-language", pflag.Lookup("ocr-language")); err != nil {
+		return fmt.Errorf("failed to bind ocr-language flag: %w", err)
+	}
+	if err := viper.BindPFlag("metrics-addr", pflag.Lookup("metrics-addr")); err != nil {
+		return fmt.Errorf("failed to bind metrics-addr flag: %w", err)
+	}
 	return nil
 }
 
@@ -162,13 +438,43 @@ func setupUsageMessage() {
 			"# stdio mode with custom directory\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --mode=server --dir=/path/to/pdfs       # server mode\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s --mode=server --host=0.0.0.0 --port=8081 # server on all interfaces\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --config=mcp-pdf-reader.yaml             # settings from a file\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nEnvironment Variables:\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_CONFIG      Config file\n")
 		fmt.Fprintf(os.Stderr, "  MCP_PDF_MODE        Server mode\n")
 		fmt.Fprintf(os.Stderr, "  MCP_PDF_HOST        Server host\n")
 		fmt.Fprintf(os.Stderr, "  MCP_PDF_PORT        Server port\n")
 		fmt.Fprintf(os.Stderr, "  MCP_PDF_DIR         PDF directory\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_ROOTS       Further PDF directories\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_WATCH_POLL  Interval between directory scans for resource changes\n")
 		fmt.Fprintf(os.Stderr, "  MCP_PDF_LOG_LEVEL    Log level\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_LOG_FORMAT   Log format\n")
 		fmt.Fprintf(os.Stderr, "  MCP_PDF_MAX_FILE_SIZE Maximum file size\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_MMAP        Memory-map PDF files\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_CACHE_SIZE  Extraction cache size in bytes\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_DOCUMENT_TTL How long parsed PDFs stay open between tool calls\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_MEMORY_BUDGET Memory extracted pages may hold, in bytes\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_TOOLS       Tools to register\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_DISABLE_TOOLS Tools not to register\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_READ_ONLY   Read-only mode\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_REQUEST_TIMEOUT Time allowed for each extraction tool call\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_MAX_CONCURRENT Tool calls running at once\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_MAX_CONCURRENT_PER_SESSION Tool calls one session may run at once\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_QUEUE_TIMEOUT How long a call waits for a free slot\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_MAX_PAGES   Pages each structured extraction processes\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_MAX_ELEMENTS Elements each structured extraction returns\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_PAGE_TIMEOUT Time allowed for each page of a structured extraction\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_CHECKPOINT_DIR Directory for resumable extraction checkpoints\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_DOWNLOAD_SAMPLES Download the sample PDF corpus at startup\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_ESCALATION_POLICY Quality escalation rules\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_CLASSIFIER_PROFILES Document classification profiles file\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_WORKSPACE_QUOTA Bytes each session's workspace may hold\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_FETCH_URLS  Register pdf_fetch_url\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_FETCH_DIR   Staging directory for downloaded PDFs\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_OCR_ENGINE  OCR program\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_OCR_LANGUAGE Default OCR language\n")
+		fmt.Fprintf(os.Stderr, "  MCP_PDF_METRICS_ADDR Address serving Prometheus metrics\n")
+		fmt.Fprintf(os.Stderr, "\nPrecedence: flags, then environment variables, then the config file, then defaults\n")
 	}
 }
 
@@ -188,8 +494,46 @@ func populateConfigFromViper(cfg *Config) {
 	cfg.Host = viper.GetString("host")
 	cfg.Port = viper.GetInt("port")
 	cfg.PDFDirectory = viper.GetString("dir")
+	cfg.Roots = listSetting("roots")
+	cfg.WatchPoll = viper.GetDuration("watch-poll")
 	cfg.LogLevel = viper.GetString("log-level")
+	cfg.LogFormat = viper.GetString("log-format")
 	cfg.MaxFileSize = viper.GetInt64("max-file-size")
+	cfg.MemoryMap = viper.GetBool("mmap")
+	cfg.CacheSize = viper.GetInt64("cache-size")
+	cfg.DocumentTTL = viper.GetDuration("document-ttl")
+	cfg.MemoryBudget = viper.GetInt64("memory-budget")
+	cfg.Tools = listSetting("tools")
+	cfg.DisabledTools = listSetting("disable-tools")
+	cfg.ReadOnly = viper.GetBool("read-only")
+	cfg.RequestTimeout = viper.GetDuration("request-timeout")
+	cfg.MaxConcurrent = viper.GetInt("max-concurrent")
+	cfg.MaxConcurrentPerSession = viper.GetInt("max-concurrent-per-session")
+	cfg.QueueTimeout = viper.GetDuration("queue-timeout")
+	cfg.MaxPages = viper.GetInt("max-pages")
+	cfg.MaxElements = viper.GetInt("max-elements")
+	cfg.PageTimeout = viper.GetDuration("page-timeout")
+	cfg.CheckpointDir = viper.GetString("checkpoint-dir")
+	cfg.DownloadSamples = viper.GetBool("download-samples")
+	cfg.EscalationPolicy = viper.GetString("escalation-policy")
+	cfg.ClassifierProfiles = viper.GetString("classifier-profiles")
+	cfg.WorkspaceQuota = viper.GetInt64("workspace-quota")
+	cfg.FetchURLs = viper.GetBool("fetch-urls")
+	cfg.FetchDir = viper.GetString("fetch-dir")
+	cfg.OCREngine = viper.GetString("ocr-engine")
+	cfg.OCRLanguage = viper.GetString("ocr-language")
+	cfg.MetricsAddress = viper.GetString("metrics-addr")
+}
+
+// splitList splits a comma-separated setting, dropping blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // Validate checks if the configuration is valid
@@ -218,11 +562,83 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("cannot access PDF directory %s: %w", c.PDFDirectory, err)
 	}
 
+	// Validate further roots; unlike the PDF directory they must already exist
+	for _, root := range c.Roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return fmt.Errorf("cannot access root directory %s: %w", root, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("root %s is not a directory", root)
+		}
+	}
+
 	// Validate max file size
 	if c.MaxFileSize <= 0 {
 		return errors.New("maximum file size must be positive")
 	}
 
+	// Validate cache size
+	if c.CacheSize < 0 {
+		return errors.New("cache size cannot be negative")
+	}
+
+	// Validate document TTL
+	if c.DocumentTTL < 0 {
+		return errors.New("document TTL cannot be negative")
+	}
+
+	// Validate memory budget
+	if c.MemoryBudget < 0 {
+		return errors.New("memory budget cannot be negative")
+	}
+
+	// Validate workspace quota
+	if c.WorkspaceQuota < 0 {
+		return errors.New("workspace quota cannot be negative")
+	}
+
+	// Validate request timeout
+	if c.RequestTimeout < 0 {
+		return errors.New("request timeout cannot be negative")
+	}
+
+	// Validate concurrency limits
+	if c.MaxConcurrent < 0 || c.MaxConcurrentPerSession < 0 {
+		return errors.New("concurrency limits cannot be negative")
+	}
+	if c.QueueTimeout < 0 {
+		return errors.New("queue timeout cannot be negative")
+	}
+
+	// Validate extraction limits
+	if c.MaxPages < 0 {
+		return errors.New("max pages cannot be negative")
+	}
+	if c.MaxElements < 0 {
+		return errors.New("max elements cannot be negative")
+	}
+	if c.PageTimeout < 0 {
+		return errors.New("page timeout cannot be negative")
+	}
+
+	// Validate watch interval
+	if c.WatchPoll < 0 {
+		return errors.New("watch poll interval cannot be negative")
+	}
+
+	// Validate OCR language; an unset language means the default
+	if c.OCRLanguage != "" && !ocrLanguagePattern.MatchString(c.OCRLanguage) {
+		return fmt.Errorf("invalid OCR language: %q (use tesseract language names such as eng or deu+fra)", c.OCRLanguage)
+	}
+
+	// Validate metrics address
+	if c.MetricsAddress != "" {
+		if _, port, err := net.SplitHostPort(c.MetricsAddress); err != nil || port == "" {
+			return fmt.Errorf("invalid metrics address: %q (use host:port, such as 127.0.0.1:9464)", c.MetricsAddress)
+		}
+	}
+
 	// Validate log level
 	validLogLevels := map[string]bool{
 		"debug": true,
@@ -234,6 +650,11 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid log level: %s (must be one of: debug, info, warn, error)", c.LogLevel)
 	}
 
+	// Validate log format; an unset format means text
+	if c.LogFormat != "" && c.LogFormat != "text" && c.LogFormat != "json" {
+		return fmt.Errorf("invalid log format: %s (must be one of: text, json)", c.LogFormat)
+	}
+
 	return nil
 }
 
@@ -242,6 +663,30 @@ func (c *Config) Address() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
+// Directories returns the PDF directory followed by the further roots, without repeats
+func (c *Config) Directories() []string {
+	var dirs []string
+	for _, dir := range append([]string{c.PDFDirectory}, c.Roots...) {
+		if dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// expandHome expands a leading ~ to the user's home directory, which shells leave alone
+// inside comma-separated lists
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
 // IsDebug returns true if debug logging is enabled
 func (c *Config) IsDebug() bool {
 	return c.LogLevel == "debug"
@@ -2,7 +2,10 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -25,8 +28,35 @@ func clearEnvVars() {
 	os.Unsetenv("MCP_PDF_HOST")
 	os.Unsetenv("MCP_PDF_PORT")
 	os.Unsetenv("MCP_PDF_DIR")
+	os.Unsetenv("MCP_PDF_WATCH_POLL")
 	os.Unsetenv("MCP_PDF_LOG_LEVEL")
+	os.Unsetenv("MCP_PDF_LOG_FORMAT")
 	os.Unsetenv("MCP_PDF_MAX_FILE_SIZE")
+	os.Unsetenv("MCP_PDF_DOWNLOAD_SAMPLES")
+	os.Unsetenv("MCP_PDF_ESCALATION_POLICY")
+	os.Unsetenv("MCP_PDF_MMAP")
+	os.Unsetenv("MCP_PDF_CACHE_SIZE")
+	os.Unsetenv("MCP_PDF_MEMORY_BUDGET")
+	os.Unsetenv("MCP_PDF_TOOLS")
+	os.Unsetenv("MCP_PDF_DISABLE_TOOLS")
+	os.Unsetenv("MCP_PDF_READ_ONLY")
+	os.Unsetenv("MCP_PDF_REQUEST_TIMEOUT")
+	os.Unsetenv("MCP_PDF_MAX_CONCURRENT")
+	os.Unsetenv("MCP_PDF_MAX_CONCURRENT_PER_SESSION")
+	os.Unsetenv("MCP_PDF_QUEUE_TIMEOUT")
+	os.Unsetenv("MCP_PDF_MAX_PAGES")
+	os.Unsetenv("MCP_PDF_MAX_ELEMENTS")
+	os.Unsetenv("MCP_PDF_PAGE_TIMEOUT")
+	os.Unsetenv("MCP_PDF_CHECKPOINT_DIR")
+	os.Unsetenv("MCP_PDF_CLASSIFIER_PROFILES")
+	os.Unsetenv("MCP_PDF_CONFIG")
+	os.Unsetenv("MCP_PDF_ROOTS")
+	os.Unsetenv("MCP_PDF_WORKSPACE_QUOTA")
+	os.Unsetenv("MCP_PDF_FETCH_URLS")
+	os.Unsetenv("MCP_PDF_FETCH_DIR")
+	os.Unsetenv("MCP_PDF_OCR_ENGINE")
+	os.Unsetenv("MCP_PDF_OCR_LANGUAGE")
+	os.Unsetenv("MCP_PDF_METRICS_ADDR")
 }
 
 func TestLoadFromFlags_DefaultConfig(t *testing.T) {
@@ -72,13 +102,29 @@ func TestLoadFromFlags_DefaultConfig(t *testing.T) {
 
 func TestLoadFromFlags_ValidFlags(t *testing.T) {
 	tests := []struct {
-		name            string
-		argsTemplate    []string
-		wantMode        string
-		wantHost        string
-		wantPort        int
-		wantLogLevel    string
-		wantMaxFileSize int64
+		name              string
+		argsTemplate      []string
+		wantMode          string
+		wantHost          string
+		wantPort          int
+		wantLogLevel      string
+		wantMaxFileSize   int64
+		wantSamples       bool
+		wantEscalation    string
+		wantMemoryMap     bool
+		wantCacheSize     int64
+		wantDocumentTTL   time.Duration // 0 expects the default
+		wantMemoryBudget  int64         // 0 expects the default
+		wantTimeout       time.Duration
+		wantMaxPages      int           // 0 expects the default
+		wantMaxElements   int           // 0 expects the default
+		wantPageTimeout   time.Duration // 0 expects the default
+		wantCheckpointDir string
+		wantWatchPoll     time.Duration // 0 expects the default
+		wantTools         []string
+		wantDisabledTools []string
+		wantReadOnly      bool
+		wantProfiles      string
 	}{
 		{
 			name:            "stdio mode with custom directory",
@@ -125,6 +171,135 @@ func TestLoadFromFlags_ValidFlags(t *testing.T) {
 			wantLogLevel:    "info",
 			wantMaxFileSize: 50000000,
 		},
+		{
+			name:            "download samples",
+			argsTemplate:    []string{"mcp-pdf-reader", "--download-samples", "--dir=%s"},
+			wantMode:        "stdio",
+			wantHost:        "127.0.0.1",
+			wantPort:        8080,
+			wantLogLevel:    "info",
+			wantMaxFileSize: 100 * 1024 * 1024,
+			wantSamples:     true,
+		},
+		{
+			name:            "escalation policy",
+			argsTemplate:    []string{"mcp-pdf-reader", "--escalation-policy=decode_quality<0.6:needs_human", "--dir=%s"},
+			wantMode:        "stdio",
+			wantHost:        "127.0.0.1",
+			wantPort:        8080,
+			wantLogLevel:    "info",
+			wantMaxFileSize: 100 * 1024 * 1024,
+			wantEscalation:  "decode_quality<0.6:needs_human",
+		},
+		{
+			name:            "memory mapping",
+			argsTemplate:    []string{"mcp-pdf-reader", "--mmap", "--dir=%s"},
+			wantMode:        "stdio",
+			wantHost:        "127.0.0.1",
+			wantPort:        8080,
+			wantLogLevel:    "info",
+			wantMaxFileSize: 100 * 1024 * 1024,
+			wantMemoryMap:   true,
+		},
+		{
+			name:            "extraction cache",
+			argsTemplate:    []string{"mcp-pdf-reader", "--cache-size=67108864", "--dir=%s"},
+			wantMode:        "stdio",
+			wantHost:        "127.0.0.1",
+			wantPort:        8080,
+			wantLogLevel:    "info",
+			wantMaxFileSize: 100 * 1024 * 1024,
+			wantCacheSize:   64 * 1024 * 1024,
+		},
+		{
+			name:            "document ttl",
+			argsTemplate:    []string{"mcp-pdf-reader", "--document-ttl=2m", "--dir=%s"},
+			wantMode:        "stdio",
+			wantHost:        "127.0.0.1",
+			wantPort:        8080,
+			wantLogLevel:    "info",
+			wantMaxFileSize: 100 * 1024 * 1024,
+			wantDocumentTTL: 2 * time.Minute,
+		},
+		{
+			name:             "memory budget",
+			argsTemplate:     []string{"mcp-pdf-reader", "--memory-budget=268435456", "--dir=%s"},
+			wantMode:         "stdio",
+			wantHost:         "127.0.0.1",
+			wantPort:         8080,
+			wantLogLevel:     "info",
+			wantMaxFileSize:  100 * 1024 * 1024,
+			wantMemoryBudget: 256 * 1024 * 1024,
+		},
+		{
+			name:            "request timeout",
+			argsTemplate:    []string{"mcp-pdf-reader", "--request-timeout=90s", "--dir=%s"},
+			wantMode:        "stdio",
+			wantHost:        "127.0.0.1",
+			wantPort:        8080,
+			wantLogLevel:    "info",
+			wantMaxFileSize: 100 * 1024 * 1024,
+			wantTimeout:     90 * time.Second,
+		},
+		{
+			name: "extraction limits",
+			argsTemplate: []string{
+				"mcp-pdf-reader", "--max-pages=500", "--max-elements=2000", "--page-timeout=5s", "--dir=%s",
+			},
+			wantMode:        "stdio",
+			wantHost:        "127.0.0.1",
+			wantPort:        8080,
+			wantLogLevel:    "info",
+			wantMaxFileSize: 100 * 1024 * 1024,
+			wantMaxPages:    500,
+			wantMaxElements: 2000,
+			wantPageTimeout: 5 * time.Second,
+		},
+		{
+			name:              "checkpoint directory",
+			argsTemplate:      []string{"mcp-pdf-reader", "--checkpoint-dir=/var/tmp/pdf-checkpoints", "--dir=%s"},
+			wantMode:          "stdio",
+			wantHost:          "127.0.0.1",
+			wantPort:          8080,
+			wantLogLevel:      "info",
+			wantMaxFileSize:   100 * 1024 * 1024,
+			wantCheckpointDir: "/var/tmp/pdf-checkpoints",
+		},
+		{
+			name:            "watch poll interval",
+			argsTemplate:    []string{"mcp-pdf-reader", "--watch-poll=30s", "--dir=%s"},
+			wantMode:        "stdio",
+			wantHost:        "127.0.0.1",
+			wantPort:        8080,
+			wantLogLevel:    "info",
+			wantMaxFileSize: 100 * 1024 * 1024,
+			wantWatchPoll:   30 * time.Second,
+		},
+		{
+			name: "tool selection",
+			argsTemplate: []string{
+				"mcp-pdf-reader", "--tools=pdf_read_file, pdf_redact", "--disable-tools=pdf_redact",
+				"--read-only", "--dir=%s",
+			},
+			wantMode:          "stdio",
+			wantHost:          "127.0.0.1",
+			wantPort:          8080,
+			wantLogLevel:      "info",
+			wantMaxFileSize:   100 * 1024 * 1024,
+			wantTools:         []string{"pdf_read_file", "pdf_redact"},
+			wantDisabledTools: []string{"pdf_redact"},
+			wantReadOnly:      true,
+		},
+		{
+			name:            "classifier profiles",
+			argsTemplate:    []string{"mcp-pdf-reader", "--classifier-profiles=/etc/mcp-pdf/profiles.yaml", "--dir=%s"},
+			wantMode:        "stdio",
+			wantHost:        "127.0.0.1",
+			wantPort:        8080,
+			wantLogLevel:    "info",
+			wantMaxFileSize: 100 * 1024 * 1024,
+			wantProfiles:    "/etc/mcp-pdf/profiles.yaml",
+		},
 	}
 
 	for _, tt := range tests {
@@ -174,6 +349,71 @@ func TestLoadFromFlags_ValidFlags(t *testing.T) {
 			if cfg.MaxFileSize != tt.wantMaxFileSize {
 				t.Errorf("LoadFromFlags() MaxFileSize = %v, want %v", cfg.MaxFileSize, tt.wantMaxFileSize)
 			}
+			if cfg.DownloadSamples != tt.wantSamples {
+				t.Errorf("LoadFromFlags() DownloadSamples = %v, want %v", cfg.DownloadSamples, tt.wantSamples)
+			}
+			if cfg.EscalationPolicy != tt.wantEscalation {
+				t.Errorf("LoadFromFlags() EscalationPolicy = %v, want %v", cfg.EscalationPolicy, tt.wantEscalation)
+			}
+			if cfg.MemoryMap != tt.wantMemoryMap {
+				t.Errorf("LoadFromFlags() MemoryMap = %v, want %v", cfg.MemoryMap, tt.wantMemoryMap)
+			}
+			if cfg.CacheSize != tt.wantCacheSize {
+				t.Errorf("LoadFromFlags() CacheSize = %v, want %v", cfg.CacheSize, tt.wantCacheSize)
+			}
+			wantDocumentTTL := tt.wantDocumentTTL
+			if wantDocumentTTL == 0 {
+				wantDocumentTTL = DefaultDocumentTTL
+			}
+			if cfg.DocumentTTL != wantDocumentTTL {
+				t.Errorf("LoadFromFlags() DocumentTTL = %v, want %v", cfg.DocumentTTL, wantDocumentTTL)
+			}
+			wantMemoryBudget := tt.wantMemoryBudget
+			if wantMemoryBudget == 0 {
+				wantMemoryBudget = DefaultMemoryBudget
+			}
+			if cfg.MemoryBudget != wantMemoryBudget {
+				t.Errorf("LoadFromFlags() MemoryBudget = %v, want %v", cfg.MemoryBudget, wantMemoryBudget)
+			}
+			if cfg.RequestTimeout != tt.wantTimeout {
+				t.Errorf("LoadFromFlags() RequestTimeout = %v, want %v", cfg.RequestTimeout, tt.wantTimeout)
+			}
+			wantMaxPages, wantMaxElements, wantPageTimeout := tt.wantMaxPages, tt.wantMaxElements, tt.wantPageTimeout
+			if wantMaxPages == 0 {
+				wantMaxPages = DefaultMaxPages
+			}
+			if wantMaxElements == 0 {
+				wantMaxElements = DefaultMaxElements
+			}
+			if wantPageTimeout == 0 {
+				wantPageTimeout = DefaultPageTimeout
+			}
+			if cfg.MaxPages != wantMaxPages || cfg.MaxElements != wantMaxElements || cfg.PageTimeout != wantPageTimeout {
+				t.Errorf("LoadFromFlags() limits = %d pages, %d elements, %v per page; want %d, %d, %v",
+					cfg.MaxPages, cfg.MaxElements, cfg.PageTimeout, wantMaxPages, wantMaxElements, wantPageTimeout)
+			}
+			if cfg.CheckpointDir != tt.wantCheckpointDir {
+				t.Errorf("LoadFromFlags() CheckpointDir = %v, want %v", cfg.CheckpointDir, tt.wantCheckpointDir)
+			}
+			wantWatchPoll := tt.wantWatchPoll
+			if wantWatchPoll == 0 {
+				wantWatchPoll = DefaultWatchPoll
+			}
+			if cfg.WatchPoll != wantWatchPoll {
+				t.Errorf("LoadFromFlags() WatchPoll = %v, want %v", cfg.WatchPoll, wantWatchPoll)
+			}
+			if !reflect.DeepEqual(cfg.Tools, tt.wantTools) {
+				t.Errorf("LoadFromFlags() Tools = %v, want %v", cfg.Tools, tt.wantTools)
+			}
+			if !reflect.DeepEqual(cfg.DisabledTools, tt.wantDisabledTools) {
+				t.Errorf("LoadFromFlags() DisabledTools = %v, want %v", cfg.DisabledTools, tt.wantDisabledTools)
+			}
+			if cfg.ReadOnly != tt.wantReadOnly {
+				t.Errorf("LoadFromFlags() ReadOnly = %v, want %v", cfg.ReadOnly, tt.wantReadOnly)
+			}
+			if cfg.ClassifierProfiles != tt.wantProfiles {
+				t.Errorf("LoadFromFlags() ClassifierProfiles = %v, want %v", cfg.ClassifierProfiles, tt.wantProfiles)
+			}
 			// PDFDirectory should be expanded to absolute path
 			if cfg.PDFDirectory == "" {
 				t.Error("LoadFromFlags() PDFDirectory should not be empty")
@@ -200,6 +440,7 @@ func TestLoadFromFlags_EnvironmentVariables(t *testing.T) {
 	os.Setenv("MCP_PDF_PORT", "3000")
 	os.Setenv("MCP_PDF_DIR", tempDir)
 	os.Setenv("MCP_PDF_LOG_LEVEL", "warn")
+	os.Setenv("MCP_PDF_LOG_FORMAT", "json")
 	os.Setenv("MCP_PDF_MAX_FILE_SIZE", "200000000")
 
 	setArgs([]string{"mcp-pdf-reader"})
@@ -222,6 +463,9 @@ func TestLoadFromFlags_EnvironmentVariables(t *testing.T) {
 	if cfg.LogLevel != "warn" {
 		t.Errorf("LoadFromFlags() LogLevel = %v, want %v", cfg.LogLevel, "warn")
 	}
+	if cfg.LogFormat != "json" {
+		t.Errorf("LoadFromFlags() LogFormat = %v, want %v", cfg.LogFormat, "json")
+	}
 	if cfg.MaxFileSize != 200000000 {
 		t.Errorf("LoadFromFlags() MaxFileSize = %v, want %v", cfg.MaxFileSize, 200000000)
 	}
@@ -331,6 +575,26 @@ func TestLoadFromFlags_InvalidLogLevel(t *testing.T) {
 	}
 }
 
+func TestLoadFromFlags_InvalidLogFormat(t *testing.T) {
+	// Save original args
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+		resetFlags()
+		clearEnvVars()
+	}()
+
+	tempDir := t.TempDir()
+	setArgs([]string{"mcp-pdf-reader", "--log-format=xml", "--dir=" + tempDir})
+	resetFlags()
+	clearEnvVars()
+
+	_, err := LoadFromFlags()
+	if err == nil || !containsString(err.Error(), "invalid log format") {
+		t.Errorf("LoadFromFlags() error = %v, want error about invalid log format", err)
+	}
+}
+
 func TestLoadFromFlags_VersionFlag(t *testing.T) {
 	// Save original args
 	originalArgs := os.Args
@@ -353,6 +617,165 @@ func TestLoadFromFlags_VersionFlag(t *testing.T) {
 	}
 }
 
+func TestLoadFromFlags_ConfigFile(t *testing.T) {
+	// Save original args and environment
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+		resetFlags()
+		clearEnvVars()
+	}()
+	clearEnvVars()
+
+	configDir := t.TempDir()
+	configPath := filepath.Join(configDir, "mcp-pdf-reader.yaml")
+	content := `dir: pdfs
+max-file-size: 5000000
+cache-size: 1048576
+tools: [pdf_read_file, pdf_search_directory]
+log-level: debug
+log-format: json
+page-timeout: 10s
+ocr-language: deu+fra
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flags override environment variables, which override the file
+	os.Setenv("MCP_PDF_LOG_LEVEL", "warn")
+	os.Setenv("MCP_PDF_MAX_FILE_SIZE", "6000000")
+	setArgs([]string{"mcp-pdf-reader", "--config=" + configPath, "--max-file-size=7000000"})
+	resetFlags()
+
+	cfg, err := LoadFromFlags()
+	if err != nil {
+		t.Fatalf("LoadFromFlags() unexpected error: %v", err)
+	}
+	if cfg.ConfigFile != configPath {
+		t.Errorf("LoadFromFlags() ConfigFile = %v, want %v", cfg.ConfigFile, configPath)
+	}
+	if cfg.PDFDirectory != filepath.Join(configDir, "pdfs") {
+		t.Errorf("LoadFromFlags() PDFDirectory = %v, want it relative to the config file", cfg.PDFDirectory)
+	}
+	if cfg.MaxFileSize != 7000000 {
+		t.Errorf("LoadFromFlags() MaxFileSize = %v, want the flag's 7000000", cfg.MaxFileSize)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Errorf("LoadFromFlags() LogLevel = %v, want the environment's warn", cfg.LogLevel)
+	}
+	if cfg.LogFormat != "json" || cfg.CacheSize != 1048576 || cfg.PageTimeout != 10*time.Second {
+		t.Errorf("LoadFromFlags() = %+v, want the file's log format, cache size, and page timeout", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Tools, []string{"pdf_read_file", "pdf_search_directory"}) {
+		t.Errorf("LoadFromFlags() Tools = %v, want the file's list", cfg.Tools)
+	}
+	if cfg.OCRLanguage != "deu+fra" || cfg.OCREngine != DefaultOCREngine {
+		t.Errorf("LoadFromFlags() OCR = %s %s, want tesseract deu+fra", cfg.OCREngine, cfg.OCRLanguage)
+	}
+
+	// TOML files are read too, and the file can be named by the environment
+	tomlPath := filepath.Join(configDir, "settings.toml")
+	content = "dir = \"" + filepath.ToSlash(configDir) + "\"\n" +
+		"disable-tools = \"pdf_sanitize, pdf_redact\"\nread-only = true\n"
+	if err := os.WriteFile(tomlPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	clearEnvVars()
+	os.Setenv("MCP_PDF_CONFIG", tomlPath)
+	setArgs([]string{"mcp-pdf-reader"})
+	resetFlags()
+
+	cfg, err = LoadFromFlags()
+	if err != nil {
+		t.Fatalf("LoadFromFlags() unexpected error: %v", err)
+	}
+	if !cfg.ReadOnly || !reflect.DeepEqual(cfg.DisabledTools, []string{"pdf_sanitize", "pdf_redact"}) {
+		t.Errorf("LoadFromFlags() = %+v, want the TOML file's settings", cfg)
+	}
+}
+
+func TestLoadFromFlags_Roots(t *testing.T) {
+	// Save original args and environment
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+		resetFlags()
+		clearEnvVars()
+	}()
+	clearEnvVars()
+
+	dir, documents, project := t.TempDir(), t.TempDir(), t.TempDir()
+	setArgs([]string{"mcp-pdf-reader", "--dir", dir, "--roots", documents + ", " + project + "," + dir})
+	resetFlags()
+
+	cfg, err := LoadFromFlags()
+	if err != nil {
+		t.Fatalf("LoadFromFlags() unexpected error: %v", err)
+	}
+	if want := []string{dir, documents, project}; !reflect.DeepEqual(cfg.Directories(), want) {
+		t.Errorf("LoadFromFlags() Directories() = %v, want %v", cfg.Directories(), want)
+	}
+
+	// Each root is checked on its own
+	missing := filepath.Join(project, "missing")
+	setArgs([]string{"mcp-pdf-reader", "--dir", dir, "--roots", documents + "," + missing})
+	resetFlags()
+	if _, err := LoadFromFlags(); err == nil || !containsString(err.Error(), missing) {
+		t.Errorf("LoadFromFlags() error = %v, want one naming %s", err, missing)
+	}
+}
+
+func TestLoadFromFlags_InvalidConfigFile(t *testing.T) {
+	// Save original args and environment
+	originalArgs := os.Args
+	defer func() {
+		os.Args = originalArgs
+		resetFlags()
+		clearEnvVars()
+	}()
+	clearEnvVars()
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		errorMsg string
+	}{
+		{name: "unknown key", file: "a.yaml", content: "max-file-sise: 100\n", errorMsg: `unknown key "max-file-sise"`},
+		{name: "wrong type", file: "b.yaml", content: "port: eighty\n", errorMsg: `key "port" must be a whole number`},
+		{
+			name: "bad duration", file: "c.toml", content: "page-timeout = 30\n",
+			errorMsg: `key "page-timeout" must be a duration`,
+		},
+		{name: "bad list", file: "d.yaml", content: "tools: [1, 2]\n", errorMsg: `key "tools" must be a list of names`},
+		{name: "bad value", file: "e.yaml", content: "ocr-language: -eng\n", errorMsg: "invalid OCR language"},
+		{name: "unsupported format", file: "f.json", content: "{}", errorMsg: "unsupported format"},
+		{name: "malformed", file: "g.yaml", content: "port: [\n", errorMsg: "failed to read config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			setArgs([]string{"mcp-pdf-reader", "--config", path, "--dir", t.TempDir()})
+			resetFlags()
+
+			_, err := LoadFromFlags()
+			if err == nil || !containsString(err.Error(), tt.errorMsg) {
+				t.Errorf("LoadFromFlags() error = %v, want it to contain %q", err, tt.errorMsg)
+			}
+		})
+	}
+
+	setArgs([]string{"mcp-pdf-reader", "--config", filepath.Join(t.TempDir(), "missing.yaml")})
+	resetFlags()
+	if _, err := LoadFromFlags(); err == nil {
+		t.Error("LoadFromFlags() accepted a missing config file")
+	}
+}
+
 // Helper function to check if a string contains a substring
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) &&
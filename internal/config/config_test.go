@@ -38,6 +38,10 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected default max file size to be 100MB, got %d", cfg.MaxFileSize)
 	}
 
+	if cfg.MemoryBudget != 1024*1024*1024 {
+		t.Errorf("Expected default memory budget to be 1GB, got %d", cfg.MemoryBudget)
+	}
+
 	// Test that PDF directory is set to current working directory by default
 	currentDir, _ := os.Getwd()
 	if cfg.PDFDirectory != currentDir {
@@ -152,6 +156,28 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid metrics address",
+			config: &Config{
+				Mode:           "stdio",
+				PDFDirectory:   "/tmp/test",
+				LogLevel:       "info",
+				MaxFileSize:    1024,
+				MetricsAddress: "127.0.0.1:9464",
+			},
+			wantErr: false,
+		},
+		{
+			name: "metrics address without port",
+			config: &Config{
+				Mode:           "stdio",
+				PDFDirectory:   "/tmp/test",
+				LogLevel:       "info",
+				MaxFileSize:    1024,
+				MetricsAddress: "localhost",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// settingKind is the type of value a config file setting holds
+type settingKind int
+
+const (
+	kindString settingKind = iota
+	kindInt
+	kindBool
+	kindDuration
+	kindList
+	kindPath     // A string naming a file or directory, resolved against the config file's directory
+	kindPathList // A list of paths, each resolved like kindPath
+)
+
+// fileSettings are the keys a config file may set, named as their command line flags
+var fileSettings = map[string]settingKind{
+	"mode":                       kindString,
+	"host":                       kindString,
+	"port":                       kindInt,
+	"dir":                        kindPath,
+	"roots":                      kindPathList,
+	"watch-poll":                 kindDuration,
+	"log-level":                  kindString,
+	"log-format":                 kindString,
+	"max-file-size":              kindInt,
+	"mmap":                       kindBool,
+	"cache-size":                 kindInt,
+	"document-ttl":               kindDuration,
+	"memory-budget":              kindInt,
+	"tools":                      kindList,
+	"disable-tools":              kindList,
+	"read-only":                  kindBool,
+	"request-timeout":            kindDuration,
+	"max-concurrent":             kindInt,
+	"max-concurrent-per-session": kindInt,
+	"queue-timeout":              kindDuration,
+	"max-pages":                  kindInt,
+	"max-elements":               kindInt,
+	"page-timeout":               kindDuration,
+	"checkpoint-dir":             kindPath,
+	"download-samples":           kindBool,
+	"escalation-policy":          kindString,
+	"classifier-profiles":        kindPath,
+	"workspace-quota":            kindInt,
+	"fetch-urls":                 kindBool,
+	"fetch-dir":                  kindPath,
+	"ocr-engine":                 kindString,
+	"ocr-language":               kindString,
+	"metrics-addr":               kindString,
+}
+
+// configFileTypes are the config file extensions read, with the format of each
+var configFileTypes = map[string]string{
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".toml": "toml",
+}
+
+// loadConfigFile reads the config file named by --config or MCP_PDF_CONFIG, if any, beneath
+// the flags and environment variables, so that they override its settings. Unknown keys and
+// values of the wrong type are rejected with the key named.
+func loadConfigFile(cfg *Config) error {
+	path := viper.GetString("config")
+	if path == "" {
+		return nil
+	}
+	if expandedPath, err := filepath.Abs(path); err == nil {
+		path = expandedPath
+	}
+	cfg.ConfigFile = path
+
+	format, ok := configFileTypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return fmt.Errorf("config file %s: unsupported format (use .yaml, .yml, or .toml)", path)
+	}
+	file := viper.New()
+	file.SetConfigFile(path)
+	file.SetConfigType(format)
+	if err := file.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	keys := file.AllKeys()
+	slices.Sort(keys)
+	settings := make(map[string]any, len(keys))
+	for _, key := range keys {
+		kind, known := fileSettings[key]
+		if !known {
+			return fmt.Errorf("config file %s: unknown key %q", path, key)
+		}
+		value := file.Get(key)
+		if err := checkSetting(kind, value); err != nil {
+			return fmt.Errorf("config file %s: key %q %w", path, key, err)
+		}
+		switch kind {
+		case kindPath:
+			settings[key] = resolvePath(path, value.(string))
+		case kindPathList:
+			var paths []any
+			for _, item := range settingItems(value) {
+				paths = append(paths, resolvePath(path, item))
+			}
+			settings[key] = paths
+		default:
+			settings[key] = value
+		}
+	}
+	return viper.MergeConfigMap(settings)
+}
+
+// checkSetting reports a config file value that does not suit its setting
+func checkSetting(kind settingKind, value any) error {
+	switch kind {
+	case kindInt:
+		switch value.(type) {
+		case int, int64, uint64:
+			return nil
+		}
+		return fmt.Errorf("must be a whole number, got %v", value)
+	case kindBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be true or false, got %v", value)
+		}
+	case kindDuration:
+		text, ok := value.(string)
+		if _, err := time.ParseDuration(text); !ok || err != nil {
+			return fmt.Errorf("must be a duration such as 30s or 5m, got %v", value)
+		}
+	case kindList, kindPathList:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("must be a list of names, got %v", value)
+		}
+		for _, item := range items {
+			if _, ok := item.(string); !ok {
+				return fmt.Errorf("must be a list of names, got %v", item)
+			}
+		}
+	default:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("must be a string, got %v", value)
+		}
+	}
+	return nil
+}
+
+// settingItems returns the entries of a list setting given as a list or a comma-separated string
+func settingItems(value any) []string {
+	items, ok := value.([]any)
+	if !ok {
+		return splitList(fmt.Sprint(value))
+	}
+	var list []string
+	for _, item := range items {
+		list = append(list, splitList(fmt.Sprint(item))...)
+	}
+	return list
+}
+
+// resolvePath resolves a relative path of a config file against the file's directory
+func resolvePath(configFile, path string) string {
+	if path == "" || filepath.IsAbs(path) || path == "~" || strings.HasPrefix(path, "~/") {
+		return path
+	}
+	return filepath.Join(filepath.Dir(configFile), path)
+}
+
+// listSetting reads a list setting, given as a comma-separated string by flags and
+// environment variables or as a list or string in a config file
+func listSetting(key string) []string {
+	if items, ok := viper.Get(key).([]any); ok {
+		return settingItems(items)
+	}
+	return splitList(viper.GetString(key))
+}
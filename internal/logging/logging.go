@@ -0,0 +1,135 @@
+// Package logging provides the structured logger shared by the server and the extraction
+// packages. Components take their loggers at package level with Component and write through
+// whatever handler Configure installed last, so diagnostics follow the configured level and
+// format and never reach stdout, which carries the MCP protocol in stdio mode.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configures the shared logger
+type Options struct {
+	Level  string    // debug, info, warn, or error; empty means info
+	Format string    // text or json; empty means text
+	Output io.Writer // Where records are written
+	Source bool      // Include the file and line of the log call
+}
+
+// handler holds the handler installed by Configure. Until then records are discarded.
+var handler atomic.Pointer[slog.Handler]
+
+func init() {
+	var discard slog.Handler = slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1})
+	handler.Store(&discard)
+}
+
+// ParseLevel converts a configured level name to a slog level
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level: %s (must be one of: debug, info, warn, error)", level)
+	}
+}
+
+// New builds a logger writing to opts.Output in the configured format
+func New(opts Options) (*slog.Logger, error) {
+	h, err := newHandler(opts)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(h), nil
+}
+
+func newHandler(opts Options) (slog.Handler, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Output == nil {
+		return nil, fmt.Errorf("log output cannot be nil")
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level, AddSource: opts.Source}
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		return slog.NewTextHandler(opts.Output, handlerOpts), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(opts.Output, handlerOpts), nil
+	default:
+		return nil, fmt.Errorf("invalid log format: %s (must be one of: text, json)", opts.Format)
+	}
+}
+
+// Configure installs the shared handler used by every component logger. It also becomes
+// the slog default, so the standard log package writes through it as well.
+func Configure(opts Options) error {
+	h, err := newHandler(opts)
+	if err != nil {
+		return err
+	}
+	handler.Store(&h)
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// Component returns a logger that tags its records with the component name. It may be
+// called before Configure; records are written with the handler current when they are logged.
+func Component(name string) *slog.Logger {
+	return slog.New(componentHandler{}).With("component", name)
+}
+
+// componentHandler forwards records to the configured handler, replaying the attributes
+// and groups added to the logger since the handler may have been replaced in between
+type componentHandler struct {
+	ops []func(slog.Handler) slog.Handler
+}
+
+func (h componentHandler) current() slog.Handler {
+	current := *handler.Load()
+	for _, op := range h.ops {
+		current = op(current)
+	}
+	return current
+}
+
+func (h componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return (*handler.Load()).Enabled(ctx, level)
+}
+
+func (h componentHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.current().Handle(ctx, record)
+}
+
+func (h componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h componentHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h componentHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return componentHandler{ops: append(ops, op)}
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestComponent_FollowsConfigure(t *testing.T) {
+	defer func() { _ = Configure(Options{Output: io.Discard}) }()
+
+	// Loggers taken before Configure write through the handler installed later
+	logger := Component("extraction").With("path", "a.pdf")
+	logger.Warn("dropped before configuration")
+
+	var text bytes.Buffer
+	if err := Configure(Options{Level: "warn", Output: &text}); err != nil {
+		t.Fatalf("Configure() unexpected error = %v", err)
+	}
+	logger.Info("below the level")
+	logger.Warn("page skipped", "page", 2)
+	if got := text.String(); strings.Contains(got, "dropped") || strings.Contains(got, "below") ||
+		!strings.Contains(got, `msg="page skipped" component=extraction path=a.pdf page=2`) {
+		t.Errorf("text output = %q, want only the warning with its attributes", got)
+	}
+
+	var jsonOut bytes.Buffer
+	if err := Configure(Options{Format: FormatJSON, Output: &jsonOut}); err != nil {
+		t.Fatalf("Configure() unexpected error = %v", err)
+	}
+	logger.WithGroup("stats").Info("done", "pages", 4)
+	var record map[string]any
+	if err := json.Unmarshal(jsonOut.Bytes(), &record); err != nil {
+		t.Fatalf("json output = %q: %v", jsonOut.String(), err)
+	}
+	stats, _ := record["stats"].(map[string]any)
+	if record["component"] != "extraction" || record["path"] != "a.pdf" || stats["pages"] != 4.0 {
+		t.Errorf("json record = %v, want the component, path, and grouped pages", record)
+	}
+}
+
+func TestConfigure_Invalid(t *testing.T) {
+	tests := []Options{
+		{Level: "verbose", Output: io.Discard},
+		{Format: "xml", Output: io.Discard},
+		{},
+	}
+	for _, opts := range tests {
+		if err := Configure(opts); err == nil {
+			t.Errorf("Configure(%+v) expected error", opts)
+		}
+	}
+}
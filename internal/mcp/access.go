@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/config"
+)
+
+// modifyingTools produce modified documents or always write files, so read-only mode
+// withholds them
+var modifyingTools = map[string]bool{
+	"pdf_redact":           true,
+	"pdf_annotate":         true,
+	"pdf_optimize":         true,
+	"pdf_import_form_data": true,
+	"pdf_batch_extract":    true,
+	"pdf_fetch_url":        true,
+
+	"pdf_generate_thumbnails": true, // Writes beside each document unless given output_dir
+}
+
+// optInTools are registered only when the configuration enables them
+var optInTools = map[string]func(*config.Config) bool{
+	"pdf_fetch_url": func(cfg *config.Config) bool { return cfg.FetchURLs },
+}
+
+// outputArguments are the arguments that make a tool write files; read-only mode refuses them
+var outputArguments = []string{"output_dir", "output_path"}
+
+// addTool registers a tool unless the configuration withholds it
+func (s *Server) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if !s.toolAllowed(tool.Name) {
+		s.withheldTools = append(s.withheldTools, tool.Name)
+		return
+	}
+	if s.config.ReadOnly {
+		handler = refuseOutput(tool, handler)
+	}
+	handler = s.instrument(tool.Name, describeErrors(s.limit(tool.Name, s.resolvePaths(handler))))
+	s.mcpServer.AddTool(tool, handler)
+	s.enabledTools = append(s.enabledTools, tool.Name)
+}
+
+// toolAllowed reports whether the allow-list, the disabled tools, and read-only mode let a
+// tool be registered
+func (s *Server) toolAllowed(name string) bool {
+	enabled, optIn := optInTools[name]
+	switch {
+	case optIn && !enabled(s.config):
+		return false
+	case s.config.ReadOnly && modifyingTools[name]:
+		return false
+	case slices.Contains(s.config.DisabledTools, name):
+		return false
+	case len(s.config.Tools) > 0:
+		return slices.Contains(s.config.Tools, name)
+	default:
+		return true
+	}
+}
+
+// checkToolNames rejects configured tool names that match no tool, which are most likely typos
+func (s *Server) checkToolNames() error {
+	for _, name := range slices.Concat(s.config.Tools, s.config.DisabledTools) {
+		if !slices.Contains(s.enabledTools, name) && !slices.Contains(s.withheldTools, name) {
+			return fmt.Errorf("unknown tool in configuration: %s", name)
+		}
+	}
+	return nil
+}
+
+// pathArguments are the arguments naming documents to read, and outputArguments those naming
+// where to write; both accept workspace: paths, and the first the handles of loaded documents
+var pathArguments = []string{"path", "paths"}
+
+// resolvePaths wraps a tool's handler so that workspace: paths can be given wherever the tool
+// takes a path or an output location, and the handles of documents loaded with
+// pdf_load_bytes wherever it takes a path. Outputs to a full workspace are refused.
+func (s *Server) resolvePaths(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		resolved := make(map[string]any, len(args))
+		for name, value := range args {
+			resolved[name] = value
+		}
+		for _, name := range slices.Concat(pathArguments, outputArguments) {
+			output := slices.Contains(outputArguments, name)
+			var err error
+			switch value := args[name].(type) {
+			case string:
+				resolved[name], err = s.resolvePath(ctx, value, output)
+			case []any:
+				paths := make([]any, len(value))
+				for i, item := range value {
+					paths[i] = item
+					if item, ok := item.(string); ok {
+						if paths[i], err = s.resolvePath(ctx, item, output); err != nil {
+							break
+						}
+					}
+				}
+				resolved[name] = paths
+			}
+			if err != nil {
+				return toolError(err), nil
+			}
+		}
+		request.Params.Arguments = resolved
+		return handler(ctx, request)
+	}
+}
+
+// resolvePath maps a workspace: path or loaded document handle to its file, leaving other
+// paths unchanged
+func (s *Server) resolvePath(ctx context.Context, path string, output bool) (string, error) {
+	switch {
+	case strings.HasPrefix(path, workspacePrefix):
+		if output {
+			if err := s.workspace.checkQuota(ctx); err != nil {
+				return "", err
+			}
+		}
+		return s.workspace.resolve(ctx, path)
+	case output:
+		return path, nil
+	default:
+		return s.pdfService.ResolvePath(path)
+	}
+}
+
+// refuseOutput wraps the handler of a tool that accepts output arguments so that calls
+// giving one fail instead of writing files
+func refuseOutput(tool mcp.Tool, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	var refused []string
+	for _, name := range outputArguments {
+		if _, ok := tool.InputSchema.Properties[name]; ok {
+			refused = append(refused, name)
+		}
+	}
+	if len(refused) == 0 {
+		return handler
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		for _, name := range refused {
+			if hasArgument(args, name) {
+				return mcp.NewToolResultError(fmt.Sprintf(
+					"%s is not allowed: the server is in read-only mode and does not write files", name)), nil
+			}
+		}
+		return handler(ctx, request)
+	}
+}
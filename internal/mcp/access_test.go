@@ -0,0 +1,255 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+)
+
+// sendToolCall calls a tool through the protocol and returns the server's response
+func sendToolCall(t *testing.T, s *Server, name string, args map[string]any) mcp.JSONRPCMessage {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0", "id": 1, "method": "tools/call",
+		"params": map[string]any{"name": name, "arguments": args},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.mcpServer.HandleMessage(context.Background(), message)
+}
+
+// callTool calls a registered tool through the protocol
+func callTool(t *testing.T, s *Server, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	response := sendToolCall(t, s, name, args)
+	rpc, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("tools/call %s returned %#v", name, response)
+	}
+	result, ok := rpc.Result.(mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("tools/call %s returned %#v", name, rpc.Result)
+	}
+	return &result
+}
+
+// isRPCError reports whether a response is a protocol error
+func isRPCError(response mcp.JSONRPCMessage) bool {
+	_, ok := response.(mcp.JSONRPCError)
+	return ok
+}
+
+func TestServer_ToolSelection(t *testing.T) {
+	dir := t.TempDir()
+	newServer := func(cfg config.Config) (*Server, error) {
+		cfg.PDFDirectory = dir
+		cfg.ServerName = "test-server"
+		return NewServer(&cfg, pdf.NewService(1024*1024))
+	}
+
+	if _, err := newServer(config.Config{Tools: []string{"pdf_read_fiel"}}); err == nil ||
+		!strings.Contains(err.Error(), "unknown tool in configuration: pdf_read_fiel") {
+		t.Errorf("NewServer() error = %v, want the misspelled tool rejected", err)
+	}
+
+	s, err := newServer(config.Config{
+		Tools:         []string{"pdf_read_file", "pdf_render_page", "pdf_redact", "pdf_server_info"},
+		DisabledTools: []string{"pdf_read_file"},
+		ReadOnly:      true,
+	})
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+
+	want := []string{"pdf_render_page", "pdf_server_info"}
+	if !slices.Equal(s.enabledTools, want) {
+		t.Errorf("enabled tools = %v, want %v", s.enabledTools, want)
+	}
+	if !slices.Contains(s.withheldTools, "pdf_read_file") || !slices.Contains(s.withheldTools, "pdf_redact") {
+		t.Errorf("withheld tools = %v, want the disabled tool and the modifying tool", s.withheldTools)
+	}
+
+	result := callTool(t, s, "pdf_render_page", map[string]any{"path": "/a.pdf", "page": 1, "output_dir": dir})
+	if !result.IsError || !strings.Contains(extractTextFromResult(result), "read-only mode") {
+		t.Errorf("pdf_render_page with output_dir = %q, want a read-only refusal", extractTextFromResult(result))
+	}
+	if response := sendToolCall(t, s, "pdf_redact", map[string]any{"path": "/a.pdf"}); !isRPCError(response) {
+		t.Errorf("pdf_redact returned %#v, want it unknown in read-only mode", response)
+	}
+
+	result = callTool(t, s, "pdf_server_info", map[string]any{"response_format": "json"})
+	var info pdf.PDFServerInfoResult
+	if err := json.Unmarshal([]byte(extractTextFromResult(result)), &info); err != nil {
+		t.Fatalf("pdf_server_info returned %q: %v", extractTextFromResult(result), err)
+	}
+	if !info.ReadOnly || !slices.Equal(info.EnabledTools, want) || len(info.DisabledTools) == 0 {
+		t.Errorf("pdf_server_info = %+v, want read-only mode with the enabled and disabled tools", info)
+	}
+	for _, tool := range info.AvailableTools {
+		if !slices.Contains(want, tool.Name) {
+			t.Errorf("pdf_server_info describes %s, which is not enabled", tool.Name)
+		}
+	}
+}
+
+func TestServer_ReadOnlyWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	writeTextPDF(t, report, "Quarterly report")
+	s, err := NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server", ReadOnly: true},
+		pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+	if !slices.Contains(s.withheldTools, "pdf_generate_thumbnails") {
+		t.Error("pdf_generate_thumbnails, which writes beside each document, registered in read-only mode")
+	}
+
+	// No tool left in read-only mode may write into the document directory by default
+	args := map[string]any{"path": report, "paths": []any{report}, "directory": dir, "page": 1, "pages": "1"}
+	for _, name := range s.enabledTools {
+		callTool(t, s, name, args)
+		var written []string
+		err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && path != report {
+				written = append(written, path)
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(written) > 0 {
+			t.Errorf("%s wrote %v in read-only mode", name, written)
+		}
+	}
+}
+
+func TestServer_OptInTools(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server", Tools: []string{"pdf_fetch_url"}},
+		pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() with an opt-in tool listed unexpected error = %v", err)
+	}
+	if slices.Contains(s.enabledTools, "pdf_fetch_url") || !slices.Contains(s.withheldTools, "pdf_fetch_url") {
+		t.Errorf("pdf_fetch_url registered without --fetch-urls: %v", s.enabledTools)
+	}
+
+	s, err = NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server", FetchURLs: true},
+		pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+	if !slices.Contains(s.enabledTools, "pdf_fetch_url") {
+		t.Errorf("pdf_fetch_url not registered with --fetch-urls: %v", s.enabledTools)
+	}
+	// The service refuses downloads until it is given a staging directory
+	result := callTool(t, s, "pdf_fetch_url", map[string]any{"url": "https://example.com/report.pdf"})
+	if !result.IsError || !strings.Contains(extractTextFromResult(result), "not enabled") {
+		t.Errorf("pdf_fetch_url without a staging directory = %q", extractTextFromResult(result))
+	}
+
+	s, err = NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server", FetchURLs: true, ReadOnly: true},
+		pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+	if slices.Contains(s.enabledTools, "pdf_fetch_url") {
+		t.Error("pdf_fetch_url registered in read-only mode")
+	}
+}
+
+func TestServer_LoadedDocumentHandles(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	writeTextPDF(t, report, "Quarterly report")
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server"}, pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+
+	result := callTool(t, s, "pdf_load_bytes", map[string]any{
+		"content": base64.StdEncoding.EncodeToString(data), "response_format": "json",
+	})
+	var doc pdf.LoadedDocument
+	if err := json.Unmarshal([]byte(extractTextFromResult(result)), &doc); err != nil {
+		t.Fatalf("pdf_load_bytes returned %q: %v", extractTextFromResult(result), err)
+	}
+
+	// The handle stands in for a path, alone or in a list
+	result = callTool(t, s, "pdf_read_file", map[string]any{"path": doc.Handle})
+	if result.IsError || !strings.Contains(extractTextFromResult(result), "Quarterly report") {
+		t.Errorf("pdf_read_file of %s = %q", doc.Handle, extractTextFromResult(result))
+	}
+	result = callTool(t, s, "pdf_compare_set", map[string]any{"paths": []string{doc.Handle, report}})
+	if result.IsError {
+		t.Errorf("pdf_compare_set with a handle = %q", extractTextFromResult(result))
+	}
+	result = callTool(t, s, "pdf_read_file", map[string]any{"path": pdf.LoadedHandlePrefix + "0000"})
+	if !result.IsError || !strings.Contains(extractTextFromResult(result), "unknown handle") {
+		t.Errorf("pdf_read_file of an unknown handle = %q", extractTextFromResult(result))
+	}
+}
+
+func TestServer_ErrorDetails(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.pdf"), []byte("%PDF-1.4 truncated"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server"}, pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]any
+		code pdferrors.Code
+	}{
+		{name: "missing file", args: map[string]any{"path": filepath.Join(dir, "missing.pdf")}, code: pdferrors.NotFound},
+		{name: "broken file", args: map[string]any{"path": filepath.Join(dir, "broken.pdf")}, code: pdferrors.Corrupt},
+		{name: "missing argument", args: map[string]any{}, code: pdferrors.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, s, "pdf_read_file", tt.args)
+			details, ok := result.Meta["error"].(pdferrors.Details)
+			if !result.IsError || !ok || details.Code != tt.code {
+				t.Fatalf("pdf_read_file error = %q, %#v, want code %s", extractTextFromResult(result), result.Meta, tt.code)
+			}
+			text := extractTextFromResult(result)
+			if !strings.Contains(text, details.Message) || !strings.Contains(text, pdferrors.Hint(tt.code)) ||
+				!strings.Contains(text, string(tt.code)) {
+				t.Errorf("pdf_read_file error text = %q, want the message, hint, and code", text)
+			}
+		})
+	}
+
+	result := callTool(t, s, "pdf_read_file", map[string]any{
+		"path": filepath.Join(dir, "missing.pdf"), "response_format": "json",
+	})
+	var response struct {
+		Error pdferrors.Details `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(extractTextFromResult(result)), &response); err != nil ||
+		response.Error.Code != pdferrors.NotFound || response.Error.Hint == "" || response.Error.Retryable {
+		t.Errorf("pdf_read_file JSON error = %q, %v", extractTextFromResult(result), err)
+	}
+}
@@ -0,0 +1,453 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+)
+
+// extractionConfigDescription documents the fields accepted by the "config" argument
+const extractionConfigDescription = "JSON object with extraction options: extract_text, extract_images, " +
+	"extract_tables, extract_forms, extract_annotations, include_coordinates, include_formatting (booleans), " +
+	"pages (array of page numbers), region ({x, y, width, height} in PDF points), first_pages, last_pages, " +
+	"coordinates (pdf: points from the bottom-left corner, the default; top-left: points with Y down the page; " +
+	"normalized: 0-1 fractions of the page from its top-left corner; applies to every returned position), " +
+	"min_confidence (0-1), max_workers (pages extracted concurrently), " +
+	"max_elements (element budget of preview mode), text_normalization ({merge_lines, dehyphenate, " +
+	"preserve_hard_breaks} booleans for structured and semantic text; all true by default), " +
+	"resume_token (from a partial result), page_size (elements and tables per response), " +
+	"cursor (next_cursor of a paginated result)"
+
+// parseExtractionConfig decodes the "config" tool argument over the tool's defaults. The
+// argument may be a JSON string or, for clients that send objects, a JSON object; fields
+// it omits keep their default values. Unknown fields and out-of-range values are errors.
+func parseExtractionConfig(arg interface{}, defaults pdf.ExtractionConfig) (pdf.ExtractionConfig, error) {
+	config := defaults
+
+	var data []byte
+	switch v := arg.(type) {
+	case string:
+		data = []byte(v)
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return config, fmt.Errorf("invalid config: %w", err)
+		}
+		data = encoded
+	default:
+		return config, fmt.Errorf("invalid config: expected a JSON object, got %T", arg)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, fmt.Errorf("invalid config: %w", err)
+	}
+
+	for _, page := range config.Pages {
+		if page < 1 {
+			return config, fmt.Errorf("invalid config: page numbers must be 1 or greater, got %d", page)
+		}
+	}
+	if config.FirstPages < 0 || config.LastPages < 0 {
+		return config, fmt.Errorf("invalid config: first_pages and last_pages cannot be negative")
+	}
+	if config.MaxWorkers < 0 {
+		return config, fmt.Errorf("invalid config: max_workers cannot be negative")
+	}
+	if config.MaxElements < 0 {
+		return config, fmt.Errorf("invalid config: max_elements cannot be negative")
+	}
+	if config.PageSize < 0 {
+		return config, fmt.Errorf("invalid config: page_size cannot be negative")
+	}
+	switch config.Coordinates {
+	case "", pdf.CoordinatesPDF, pdf.CoordinatesTopLeft, pdf.CoordinatesNormalized:
+	default:
+		return config, fmt.Errorf("invalid config: coordinates must be %s, %s, or %s, got %q",
+			pdf.CoordinatesPDF, pdf.CoordinatesTopLeft, pdf.CoordinatesNormalized, config.Coordinates)
+	}
+	if config.MinConfidence < 0 || config.MinConfidence > 1 {
+		return config, fmt.Errorf("invalid config: min_confidence must be between 0 and 1, got %g",
+			config.MinConfidence)
+	}
+
+	return config, nil
+}
+
+// structureConfigDescription documents the fields accepted by the "structure_config" argument
+const structureConfigDescription = "JSON object tuning structure detection: preset (default, academic, legal, " +
+	"form) and overrides of its heading_font_ratio, heading_level1_ratio, heading_level2_ratio " +
+	"(relative to the body text size), heading_max_words, heading_patterns and list_patterns (arrays of " +
+	"regular expressions), paragraph_gap_ratio, detect_headings, detect_lists, merge_lines (booleans)"
+
+// parseStructureConfig decodes the "structure_config" tool argument over the settings of
+// the preset it names. A missing argument selects the default preset.
+func parseStructureConfig(arg interface{}) (*pdf.StructureConfig, error) {
+	var data []byte
+	switch v := arg.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		data = []byte(v)
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid structure_config: %w", err)
+		}
+		data = encoded
+	default:
+		return nil, fmt.Errorf("invalid structure_config: expected a JSON object, got %T", arg)
+	}
+
+	var preset struct {
+		Preset string `json:"preset"`
+	}
+	if err := json.Unmarshal(data, &preset); err != nil {
+		return nil, fmt.Errorf("invalid structure_config: %w", err)
+	}
+	config, err := pdf.NewStructureConfig(preset.Preset)
+	if err != nil {
+		return nil, fmt.Errorf("invalid structure_config: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid structure_config: %w", err)
+	}
+	config.Preset = strings.ToLower(config.Preset)
+	if config.Preset == "" {
+		config.Preset = pdf.StructurePresetDefault
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid structure_config: %w", err)
+	}
+	return &config, nil
+}
+
+// contentQueryDescription documents the forms accepted by the "query" argument
+const contentQueryDescription = "Text to search for, or a JSON object with query criteria: text_query, " +
+	"regex (treat text_query as a regular expression), case_sensitive, content_types (text, image, vector, " +
+	"form, annotation, structural, list), pages, bounding_box {x, y, width, height}, min_confidence (0-1), " +
+	"clause (a clause number such as 1.1.2, Article IV, or §12(a), or an anchor from pdf_get_clauses)"
+
+// parseContentQuery decodes the "query" tool argument. A JSON object (or a string holding
+// one) is decoded as a full content query; any other string is a plain text query.
+func parseContentQuery(arg interface{}) (pdf.ContentQuery, error) {
+	var query pdf.ContentQuery
+
+	var data []byte
+	switch v := arg.(type) {
+	case string:
+		if !strings.HasPrefix(strings.TrimSpace(v), "{") {
+			query.TextQuery = v
+			return query, nil
+		}
+		data = []byte(v)
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return query, fmt.Errorf("invalid query: %w", err)
+		}
+		data = encoded
+	default:
+		return query, fmt.Errorf("invalid query: expected text or a JSON object, got %T", arg)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&query); err != nil {
+		return query, fmt.Errorf("invalid query: %w", err)
+	}
+
+	return query, nil
+}
+
+// parseRedactionRegions decodes the "regions" tool argument: an array of region objects, or a
+// string holding one
+func parseRedactionRegions(arg interface{}) ([]pdf.RedactionRegion, error) {
+	var regions []pdf.RedactionRegion
+	if err := decodeObjectArray("regions", arg, &regions); err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+// parseOCRRegions decodes the "regions" argument of OCR: an array of region objects, or a
+// string holding one
+func parseOCRRegions(arg interface{}) ([]pdf.Rectangle, error) {
+	var regions []pdf.Rectangle
+	if err := decodeObjectArray("regions", arg, &regions); err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+// parseAnnotationSpecs decodes the "annotations" tool argument: an array of annotation
+// objects, or a string holding one
+func parseAnnotationSpecs(arg interface{}) ([]pdf.AnnotationSpec, error) {
+	var specs []pdf.AnnotationSpec
+	if err := decodeObjectArray("annotations", arg, &specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// decodeObjectArray decodes an array argument given as an array of objects or as a JSON
+// string, rejecting unknown fields. A missing or blank argument leaves out unchanged.
+func decodeObjectArray(name string, arg interface{}, out interface{}) error {
+	var data []byte
+	switch v := arg.(type) {
+	case nil:
+		return nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+		data = []byte(v)
+	case []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		data = encoded
+	default:
+		return fmt.Errorf("invalid %s: expected an array of objects, got %T", name, arg)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// hasArgument reports whether an optional argument was supplied with a non-empty value
+func hasArgument(args map[string]interface{}, name string) bool {
+	switch v := args[name].(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	default:
+		return true
+	}
+}
+
+// regionDescription documents the region parameter of the tools reading part of a page
+const regionDescription = "Only read this part of each page: {x, y, width, height} in PDF points from " +
+	"the bottom-left corner, or the same four numbers as \"x,y,width,height\""
+
+// withPageSelection adds the pages, region, first_pages, and last_pages parameters of the
+// read and extract tools
+func withPageSelection() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		withPages()(tool)
+		withRegion()(tool)
+		mcp.WithNumber("first_pages",
+			mcp.Description("Only read the first N pages, extended by up to 2 pages to finish the current section"),
+		)(tool)
+		mcp.WithNumber("last_pages",
+			mcp.Description("Only read the last N pages, extended by up to 2 pages to start at a section heading"),
+		)(tool)
+	}
+}
+
+// withPages adds the pages parameter of the tools reading selected pages
+func withPages() mcp.ToolOption {
+	return mcp.WithString("pages",
+		mcp.Description("Pages to read as numbers and ranges, such as \"1-5,9\" (default: all pages)"),
+	)
+}
+
+// withRegion adds the region parameter of the tools reading part of a page
+func withRegion() mcp.ToolOption {
+	return mcp.WithObject("region",
+		mcp.Description(regionDescription),
+		mcp.Properties(map[string]any{
+			"x":      map[string]any{"type": "number", "description": "Left edge"},
+			"y":      map[string]any{"type": "number", "description": "Bottom edge"},
+			"width":  map[string]any{"type": "number"},
+			"height": map[string]any{"type": "number"},
+		}),
+	)
+}
+
+// withTimeout adds the timeout parameter of the extraction and query tools
+func withTimeout() mcp.ToolOption {
+	return mcp.WithNumber("timeout",
+		mcp.Description("Seconds to spend before stopping and returning the pages extracted so far "+
+			"(default: the server's request timeout)"),
+	)
+}
+
+// withResumeToken adds the resume_token parameter of the checkpointed extraction tools
+func withResumeToken() mcp.ToolOption {
+	return mcp.WithString("resume_token",
+		mcp.Description("Token from a partial result; continues that extraction from its last completed page"),
+	)
+}
+
+// withPagination adds the page_size and cursor parameters of the paginated extraction tools
+func withPagination() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithNumber("page_size",
+			mcp.Description("Return the result in responses of this many elements and tables, in page order; "+
+				"each response gives a next_cursor for the next one"),
+		)(tool)
+		mcp.WithString("cursor",
+			mcp.Description("next_cursor from the previous response; repeat the request's other arguments unchanged"),
+		)(tool)
+	}
+}
+
+// requestContext bounds a tool call by its timeout argument, or by the server's request
+// timeout when the argument is absent. A zero timeout leaves the call unbounded. Calls
+// carrying a progress token are sent progress notifications.
+func (s *Server) requestContext(ctx context.Context, request mcp.CallToolRequest) (context.Context, context.CancelFunc) {
+	ctx = withProgress(ctx, request)
+	timeout := s.config.RequestTimeout
+	if seconds := request.GetFloat("timeout", 0); seconds > 0 {
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// applyPageSelection copies the pages, region, first_pages, and last_pages arguments into an
+// extraction config
+func applyPageSelection(request mcp.CallToolRequest, config *pdf.ExtractionConfig) error {
+	pages, region, err := pageSelectionArguments(request)
+	if err != nil {
+		return err
+	}
+	if pages != nil {
+		config.Pages = pages
+	}
+	if region != nil {
+		config.Region = region
+	}
+	config.FirstPages = request.GetInt("first_pages", config.FirstPages)
+	config.LastPages = request.GetInt("last_pages", config.LastPages)
+	return nil
+}
+
+// pageSelectionArguments decodes the pages and region arguments; either is nil when absent
+func pageSelectionArguments(request mcp.CallToolRequest) (pages []int, region *pdf.Rectangle, err error) {
+	args := request.GetArguments()
+	if pages, err = parsePages(args["pages"]); err != nil {
+		return nil, nil, err
+	}
+	if hasArgument(args, "region") {
+		if region, err = parseRegion(args["region"]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return pages, region, nil
+}
+
+// parsePages decodes a pages argument given as a page list such as "1-5,9" or as an array of
+// page numbers. A missing argument selects no pages.
+func parsePages(arg interface{}) ([]int, error) {
+	switch v := arg.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		pages, err := pdf.ParsePageRanges(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pages: %w", err)
+		}
+		return pages, nil
+	case []interface{}:
+		pages := make([]int, len(v))
+		for i, item := range v {
+			page, ok := item.(float64)
+			if !ok || page != float64(int(page)) || page < 1 {
+				return nil, fmt.Errorf("invalid pages: expected page numbers of 1 or greater, got %v", item)
+			}
+			pages[i] = int(page)
+		}
+		return pages, nil
+	default:
+		return nil, fmt.Errorf("invalid pages: expected a page list or an array of page numbers, got %T", arg)
+	}
+}
+
+// parseRegion decodes a region argument given as an object {x, y, width, height}, or as a
+// string holding that object or the four numbers separated by commas
+func parseRegion(arg interface{}) (*pdf.Rectangle, error) {
+	var data []byte
+	switch v := arg.(type) {
+	case string:
+		if !strings.HasPrefix(strings.TrimSpace(v), "{") {
+			return parseRegionNumbers(v)
+		}
+		data = []byte(v)
+	case map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid region: %w", err)
+		}
+		data = encoded
+	default:
+		return nil, fmt.Errorf("invalid region: expected an object {x, y, width, height}, got %T", arg)
+	}
+
+	var region pdf.Rectangle
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&region); err != nil {
+		return nil, fmt.Errorf("invalid region: %w", err)
+	}
+	return &region, nil
+}
+
+// parseRegionNumbers decodes a region given as "x,y,width,height"
+func parseRegionNumbers(s string) (*pdf.Rectangle, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid region: expected x,y,width,height, got %q", s)
+	}
+	values := make([]float64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid region: expected x,y,width,height, got %q", s)
+		}
+		values[i] = value
+	}
+	return &pdf.Rectangle{X: values[0], Y: values[1], Width: values[2], Height: values[3]}, nil
+}
+
+// applyResumeToken copies the resume_token argument into an extraction config
+func applyResumeToken(request mcp.CallToolRequest, config *pdf.ExtractionConfig) {
+	config.ResumeToken = request.GetString("resume_token", config.ResumeToken)
+}
+
+// applyPagination copies the page_size and cursor arguments into an extraction config
+func applyPagination(request mcp.CallToolRequest, config *pdf.ExtractionConfig) error {
+	config.PageSize = request.GetInt("page_size", config.PageSize)
+	config.Cursor = request.GetString("cursor", config.Cursor)
+	if config.PageSize < 0 {
+		return fmt.Errorf("page_size cannot be negative")
+	}
+	return nil
+}
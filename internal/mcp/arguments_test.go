@@ -0,0 +1,397 @@
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+)
+
+func TestParseExtractionConfig(t *testing.T) {
+	defaults := pdf.ExtractionConfig{ExtractText: true, IncludeCoordinates: true}
+
+	tests := []struct {
+		name     string
+		arg      interface{}
+		want     pdf.ExtractionConfig
+		errorMsg string
+	}{
+		{
+			name: "empty object keeps defaults",
+			arg:  "{}",
+			want: defaults,
+		},
+		{
+			name: "fields override defaults",
+			arg:  `{"extract_tables": true, "include_coordinates": false, "pages": [1, 3], "min_confidence": 0.5}`,
+			want: pdf.ExtractionConfig{ExtractText: true, ExtractTables: true, Pages: []int{1, 3}, MinConfidence: 0.5},
+		},
+		{
+			name: "object argument",
+			arg:  map[string]interface{}{"extract_images": true, "pages": []interface{}{2.0}},
+			want: pdf.ExtractionConfig{ExtractText: true, ExtractImages: true, IncludeCoordinates: true, Pages: []int{2}},
+		},
+		{
+			name:     "malformed JSON",
+			arg:      `{"pages": [1,}`,
+			errorMsg: "invalid config",
+		},
+		{
+			name:     "unknown field",
+			arg:      `{"extract_everything": true}`,
+			errorMsg: "unknown field",
+		},
+		{
+			name:     "wrong type",
+			arg:      `{"pages": "1-3"}`,
+			errorMsg: "invalid config",
+		},
+		{
+			name:     "invalid page",
+			arg:      `{"pages": [0]}`,
+			errorMsg: "page numbers must be 1 or greater",
+		},
+		{
+			name:     "confidence out of range",
+			arg:      `{"min_confidence": 1.5}`,
+			errorMsg: "min_confidence must be between 0 and 1",
+		},
+		{
+			name: "coordinates",
+			arg:  `{"coordinates": "top-left"}`,
+			want: pdf.ExtractionConfig{ExtractText: true, IncludeCoordinates: true, Coordinates: "top-left"},
+		},
+		{
+			name:     "unknown coordinates",
+			arg:      `{"coordinates": "bottom-right"}`,
+			errorMsg: "coordinates must be pdf, top-left, or normalized",
+		},
+		{
+			name:     "negative page size",
+			arg:      `{"page_size": -5}`,
+			errorMsg: "page_size cannot be negative",
+		},
+		{
+			name:     "not an object",
+			arg:      42.0,
+			errorMsg: "expected a JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExtractionConfig(tt.arg, defaults)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("parseExtractionConfig() error = %v, want error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseExtractionConfig() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseExtractionConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_ExtractionConfigErrors(t *testing.T) {
+	cfg := &config.Config{
+		Mode:         "stdio",
+		PDFDirectory: t.TempDir(),
+		Version:      "1.0.0",
+		ServerName:   "test-server",
+		MaxFileSize:  1024 * 1024,
+	}
+	server, err := NewServer(cfg, pdf.NewService(cfg.MaxFileSize))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"path":   "/tmp/document.pdf",
+				"config": `{"pages": [-1]}`,
+			},
+		},
+	}
+
+	handlers := map[string]func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		"structured": server.handlePDFExtractStructured,
+		"tables":     server.handlePDFExtractTables,
+		"semantic":   server.handlePDFExtractSemantic,
+		"complete":   server.handlePDFExtractComplete,
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handler failed: %v", err)
+			}
+			if !result.IsError || !strings.Contains(extractTextFromResult(result), "invalid config") {
+				t.Errorf("expected invalid config error, got: %s", extractTextFromResult(result))
+			}
+		})
+	}
+}
+
+func TestParseContentQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      interface{}
+		want     pdf.ContentQuery
+		errorMsg string
+	}{
+		{
+			name: "plain text",
+			arg:  "revenue growth",
+			want: pdf.ContentQuery{TextQuery: "revenue growth"},
+		},
+		{
+			name: "JSON string",
+			arg: `{"text_query": "Q[1-4] 20\\d\\d", "regex": true, "case_sensitive": true,
+				"content_types": ["text"], "pages": [2], "min_confidence": 0.5,
+				"bounding_box": {"x": 0, "y": 600, "width": 612, "height": 192}}`,
+			want: pdf.ContentQuery{
+				TextQuery:     `Q[1-4] 20\d\d`,
+				Regex:         true,
+				CaseSensitive: true,
+				ContentTypes:  []string{"text"},
+				Pages:         []int{2},
+				MinConfidence: 0.5,
+				BoundingBox:   &pdf.Rectangle{Y: 600, Width: 612, Height: 192},
+			},
+		},
+		{
+			name: "object argument",
+			arg:  map[string]interface{}{"content_types": []interface{}{"annotation"}},
+			want: pdf.ContentQuery{ContentTypes: []string{"annotation"}},
+		},
+		{
+			name:     "malformed JSON",
+			arg:      `{"text_query": }`,
+			errorMsg: "invalid query",
+		},
+		{
+			name:     "unknown field",
+			arg:      `{"text": "revenue"}`,
+			errorMsg: "unknown field",
+		},
+		{
+			name:     "not text or object",
+			arg:      []interface{}{"revenue"},
+			errorMsg: "expected text or a JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseContentQuery(tt.arg)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("parseContentQuery() error = %v, want error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseContentQuery() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseContentQuery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRedactionRegions(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      interface{}
+		want     []pdf.RedactionRegion
+		errorMsg string
+	}{
+		{
+			name: "missing",
+			arg:  nil,
+		},
+		{
+			name: "array argument",
+			arg: []interface{}{
+				map[string]interface{}{"page": 2, "x": 72, "y": 640, "width": 200, "height": 24},
+			},
+			want: []pdf.RedactionRegion{{Page: 2, X: 72, Y: 640, Width: 200, Height: 24}},
+		},
+		{
+			name: "JSON string",
+			arg:  `[{"page": 1, "x": 0, "y": 0, "width": 612, "height": 72}]`,
+			want: []pdf.RedactionRegion{{Page: 1, Width: 612, Height: 72}},
+		},
+		{
+			name:     "unknown field",
+			arg:      `[{"page": 1, "left": 0}]`,
+			errorMsg: "unknown field",
+		},
+		{
+			name:     "not an array",
+			arg:      42.0,
+			errorMsg: "expected an array of objects",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRedactionRegions(tt.arg)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("parseRedactionRegions() error = %v, want error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRedactionRegions() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRedactionRegions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStructureConfig(t *testing.T) {
+	legal, err := pdf.NewStructureConfig(pdf.StructurePresetLegal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legal.HeadingMaxWords = 6
+
+	tests := []struct {
+		name     string
+		arg      interface{}
+		want     *pdf.StructureConfig
+		errorMsg string
+	}{
+		{
+			name: "missing",
+			arg:  nil,
+		},
+		{
+			name: "preset with an override",
+			arg:  map[string]interface{}{"preset": "Legal", "heading_max_words": 6},
+			want: &legal,
+		},
+		{
+			name:     "unknown preset",
+			arg:      `{"preset": "poetry"}`,
+			errorMsg: "invalid structure preset: poetry",
+		},
+		{
+			name:     "unknown field",
+			arg:      `{"heading_ratio": 2}`,
+			errorMsg: "unknown field",
+		},
+		{
+			name:     "invalid pattern",
+			arg:      `{"heading_patterns": ["[a-"]}`,
+			errorMsg: "invalid heading pattern",
+		},
+		{
+			name:     "levels out of order",
+			arg:      `{"heading_level1_ratio": 1.3, "heading_level2_ratio": 1.5}`,
+			errorMsg: "heading_level1_ratio (1.3) cannot be below heading_level2_ratio (1.5)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStructureConfig(tt.arg)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("parseStructureConfig() error = %v, want error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStructureConfig() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStructureConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePages(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      interface{}
+		want     []int
+		errorMsg string
+	}{
+		{name: "missing", arg: nil},
+		{name: "page list", arg: "1-3,9", want: []int{1, 2, 3, 9}},
+		{name: "array argument", arg: []interface{}{4.0, 2.0}, want: []int{4, 2}},
+		{name: "invalid range", arg: "3-1", errorMsg: "end before start"},
+		{name: "fractional page", arg: []interface{}{1.5}, errorMsg: "page numbers of 1 or greater"},
+		{name: "not a list", arg: true, errorMsg: "expected a page list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePages(tt.arg)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("parsePages() error = %v, want error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePages() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRegion(t *testing.T) {
+	want := &pdf.Rectangle{X: 72, Y: 500, Width: 200.5, Height: 100}
+	tests := []struct {
+		name     string
+		arg      interface{}
+		errorMsg string
+	}{
+		{name: "object argument", arg: map[string]interface{}{"x": 72, "y": 500, "width": 200.5, "height": 100}},
+		{name: "JSON string", arg: `{"x": 72, "y": 500, "width": 200.5, "height": 100}`},
+		{name: "numbers", arg: "72, 500, 200.5, 100"},
+		{name: "unknown field", arg: `{"left": 72}`, errorMsg: "unknown field"},
+		{name: "three numbers", arg: "72,500,200", errorMsg: "expected x,y,width,height"},
+		{name: "not a region", arg: 42.0, errorMsg: "expected an object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRegion(tt.arg)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("parseRegion() error = %v, want error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRegion() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("parseRegion() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
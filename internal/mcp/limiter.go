@@ -0,0 +1,246 @@
+package mcp
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+)
+
+// unlimitedTools answer from memory or are needed while the server is saturated, such as
+// deployment probes, so they are never queued
+var unlimitedTools = map[string]bool{
+	"pdf_server_info":    true,
+	"pdf_health":         true,
+	"pdf_extract_status": true,
+	"pdf_list_outputs":   true,
+}
+
+// limiter bounds the tool calls running at once, across all sessions and per session. Calls
+// beyond a limit wait in their session's queue, and freed slots go to the sessions in turn,
+// so that one session firing many calls cannot starve the others.
+type limiter struct {
+	mu         sync.Mutex
+	global     int // Calls running at once across all sessions; 0 means no limit
+	perSession int // Calls one session may run at once; 0 means no limit
+	wait       time.Duration
+	running    int
+	sessions   map[string]*sessionSlots
+	turns      []string // Sessions with waiting calls, the next to be served first
+}
+
+// sessionSlots are the running and waiting calls of one session
+type sessionSlots struct {
+	running int
+	waiting []*slotWaiter
+}
+
+// slotWaiter is a call waiting for a slot; ready is closed when it is granted one
+type slotWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// newLimiter creates a limiter; zero limits remove them, and a zero wait refuses calls
+// beyond a limit at once
+func newLimiter(global, perSession int, wait time.Duration) *limiter {
+	return &limiter{global: global, perSession: perSession, wait: wait, sessions: make(map[string]*sessionSlots)}
+}
+
+// acquire takes a slot for a call of a session, waiting up to the limiter's wait for one.
+// queued is told how many calls are waiting ahead when the call has to wait. The returned
+// function frees the slot.
+func (l *limiter) acquire(ctx context.Context, session string, queued func(ahead int)) (func(), error) {
+	l.mu.Lock()
+	slots := l.session(session)
+	if len(slots.waiting) == 0 && l.available(slots) {
+		l.grant(slots)
+		l.mu.Unlock()
+		return l.releaser(session), nil
+	}
+	if l.wait <= 0 {
+		running := l.running
+		l.forget(session)
+		l.mu.Unlock()
+		return nil, l.busy(running)
+	}
+
+	w := &slotWaiter{ready: make(chan struct{})}
+	slots.waiting = append(slots.waiting, w)
+	if len(slots.waiting) == 1 {
+		l.turns = append(l.turns, session)
+	}
+	ahead := l.waitingCalls() - 1
+	l.mu.Unlock()
+	if queued != nil {
+		queued(ahead)
+	}
+
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+	select {
+	case <-w.ready:
+		return l.releaser(session), nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.granted {
+		// The slot was granted as the wait ended; give it back
+		l.release(session)
+	} else {
+		l.withdraw(session, w)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, l.busy(l.running)
+}
+
+// session returns the slots of a session, creating them; l.mu must be held
+func (l *limiter) session(id string) *sessionSlots {
+	slots, ok := l.sessions[id]
+	if !ok {
+		slots = &sessionSlots{}
+		l.sessions[id] = slots
+	}
+	return slots
+}
+
+// available reports whether a session may start another call; l.mu must be held
+func (l *limiter) available(slots *sessionSlots) bool {
+	return (l.global <= 0 || l.running < l.global) && (l.perSession <= 0 || slots.running < l.perSession)
+}
+
+// grant counts a call of a session as running; l.mu must be held
+func (l *limiter) grant(slots *sessionSlots) {
+	l.running++
+	slots.running++
+}
+
+// releaser returns the function that frees a session's slot, once
+func (l *limiter) releaser(session string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.release(session)
+		})
+	}
+}
+
+// release frees a slot of a session and hands free slots to waiting calls; l.mu must be held
+func (l *limiter) release(session string) {
+	slots := l.sessions[session]
+	l.running--
+	slots.running--
+	l.forget(session)
+	l.dispatch()
+}
+
+// dispatch grants free slots to waiting calls, taking sessions in turn: the session served
+// moves to the back of the line. l.mu must be held.
+func (l *limiter) dispatch() {
+	for {
+		served := -1
+		for i, id := range l.turns {
+			if l.available(l.sessions[id]) {
+				served = i
+				break
+			}
+		}
+		if served < 0 {
+			return
+		}
+		id := l.turns[served]
+		slots := l.sessions[id]
+		w := slots.waiting[0]
+		slots.waiting = slots.waiting[1:]
+		l.grant(slots)
+		w.granted = true
+		close(w.ready)
+
+		l.turns = append(l.turns[:served], l.turns[served+1:]...)
+		if len(slots.waiting) > 0 {
+			l.turns = append(l.turns, id)
+		}
+	}
+}
+
+// withdraw removes a call that stopped waiting from its session's queue; l.mu must be held
+func (l *limiter) withdraw(session string, w *slotWaiter) {
+	slots := l.sessions[session]
+	for i, other := range slots.waiting {
+		if other == w {
+			slots.waiting = append(slots.waiting[:i], slots.waiting[i+1:]...)
+			break
+		}
+	}
+	if len(slots.waiting) == 0 {
+		for i, id := range l.turns {
+			if id == session {
+				l.turns = append(l.turns[:i], l.turns[i+1:]...)
+				break
+			}
+		}
+	}
+	l.forget(session)
+}
+
+// forget drops the slots of a session with no running or waiting calls; l.mu must be held
+func (l *limiter) forget(session string) {
+	if slots := l.sessions[session]; slots.running == 0 && len(slots.waiting) == 0 {
+		delete(l.sessions, session)
+	}
+}
+
+// waitingCalls counts the calls waiting in every session; l.mu must be held
+func (l *limiter) waitingCalls() int {
+	count := 0
+	for _, slots := range l.sessions {
+		count += len(slots.waiting)
+	}
+	return count
+}
+
+// busy returns the error of a call refused for want of a slot
+func (l *limiter) busy(running int) error {
+	return pdferrors.New(pdferrors.Busy, "server is busy: %d tool calls are running "+
+		"(limits: %s in total, %s per session) and no slot freed within the queue timeout of %s",
+		running, limitText(l.global), limitText(l.perSession), l.wait)
+}
+
+// limitText describes a concurrency limit
+func limitText(limit int) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return strconv.Itoa(limit)
+}
+
+// limit wraps a tool's handler so that its calls take a slot from the server's limiter,
+// waiting in line when the server or the session is saturated and failing with a BUSY error
+// when no slot frees in time. Waiting calls that carry a progress token are told so.
+func (s *Server) limit(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if unlimitedTools[name] {
+		return handler
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		release, err := s.limiter.acquire(ctx, sessionID(ctx), func(ahead int) {
+			notifyQueued(ctx, request, ahead)
+		})
+		if err != nil {
+			return toolError(err), nil
+		}
+		defer release()
+		return handler(ctx, request)
+	}
+}
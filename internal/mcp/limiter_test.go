@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+)
+
+func TestLimiter_Limits(t *testing.T) {
+	l := newLimiter(0, 2, 0)
+	ctx := context.Background()
+
+	first, err := l.acquire(ctx, "a", nil)
+	if err != nil {
+		t.Fatalf("acquire() unexpected error = %v", err)
+	}
+	if _, err := l.acquire(ctx, "a", nil); err != nil {
+		t.Fatalf("acquire() of the second slot unexpected error = %v", err)
+	}
+	if _, err := l.acquire(ctx, "a", nil); pdferrors.CodeOf(err) != pdferrors.Busy {
+		t.Errorf("acquire() beyond the session limit error = %v, want BUSY", err)
+	}
+	if _, err := l.acquire(ctx, "b", nil); err != nil {
+		t.Errorf("acquire() of another session unexpected error = %v", err)
+	}
+
+	first()
+	first() // Releasing twice frees one slot
+	if _, err := l.acquire(ctx, "a", nil); err != nil {
+		t.Errorf("acquire() after a release unexpected error = %v", err)
+	}
+	if l.running != 3 {
+		t.Errorf("running = %d, want 3", l.running)
+	}
+}
+
+func TestLimiter_Queue(t *testing.T) {
+	l := newLimiter(1, 0, time.Second)
+	ctx := context.Background()
+	release, err := l.acquire(ctx, "a", nil)
+	if err != nil {
+		t.Fatalf("acquire() unexpected error = %v", err)
+	}
+
+	// Session a queues three calls before session b queues one; b is served second
+	served := make(chan string, 4)
+	queue := func(session string) {
+		queued := make(chan int, 1)
+		go func() {
+			release, err := l.acquire(ctx, session, func(ahead int) { queued <- ahead })
+			if err != nil {
+				served <- "error: " + err.Error()
+				return
+			}
+			served <- session
+			release()
+		}()
+		<-queued
+	}
+	queue("a")
+	queue("a")
+	queue("a")
+	queue("b")
+
+	release()
+	var order []string
+	for range 4 {
+		order = append(order, <-served)
+	}
+	if order[0] != "a" || order[1] != "b" {
+		t.Errorf("served %v, want session b served after one call of session a", order)
+	}
+}
+
+func TestLimiter_QueueTimeout(t *testing.T) {
+	l := newLimiter(1, 0, 20*time.Millisecond)
+	if _, err := l.acquire(context.Background(), "a", nil); err != nil {
+		t.Fatalf("acquire() unexpected error = %v", err)
+	}
+	ahead := -1
+	_, err := l.acquire(context.Background(), "b", func(n int) { ahead = n })
+	if pdferrors.CodeOf(err) != pdferrors.Busy || ahead != 0 {
+		t.Errorf("acquire() after the queue timeout = %v (ahead %d), want BUSY", err, ahead)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.acquire(ctx, "b", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("acquire() of a canceled call error = %v", err)
+	}
+	if len(l.sessions) != 1 || len(l.turns) != 0 {
+		t.Errorf("sessions = %v, turns = %v, want only the running session left", l.sessions, l.turns)
+	}
+}
+
+func TestServer_ConcurrencyLimit(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	writeTextPDF(t, report, "Quarterly report")
+	s, err := NewServer(&config.Config{
+		PDFDirectory: dir, ServerName: "test-server", MaxConcurrentPerSession: 1,
+	}, pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+
+	release, err := s.limiter.acquire(context.Background(), defaultSession, nil)
+	if err != nil {
+		t.Fatalf("acquire() unexpected error = %v", err)
+	}
+	result := callTool(t, s, "pdf_read_file", map[string]any{"path": report})
+	details, ok := result.Meta["error"].(pdferrors.Details)
+	if !ok || details.Code != pdferrors.Busy || !details.Retryable {
+		t.Errorf("pdf_read_file while saturated = %q, want a retryable BUSY error", extractTextFromResult(result))
+	}
+	if result := callTool(t, s, "pdf_health", map[string]any{}); result.IsError {
+		t.Errorf("pdf_health while saturated = %q, want it never queued", extractTextFromResult(result))
+	}
+
+	release()
+	if result := callTool(t, s, "pdf_read_file", map[string]any{"path": report}); result.IsError {
+		t.Errorf("pdf_read_file after the release = %q", extractTextFromResult(result))
+	}
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+)
+
+// metricsShutdownTimeout bounds how long the metrics listener waits for scrapes in progress
+// when the server stops
+const metricsShutdownTimeout = 5 * time.Second
+
+// instrument wraps a tool's handler so that the service's metrics record every call: its
+// duration, the size of the documents it names, and the code of the error it returns, if any
+func (s *Server) instrument(name string, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started := time.Now()
+		bytes := s.inputBytes(ctx, request)
+		result, err := handler(ctx, request)
+
+		var code string
+		switch {
+		case err != nil:
+			code = string(pdferrors.CodeOf(err))
+		case result != nil && result.IsError:
+			if details, ok := result.Meta[errorMetaKey].(pdferrors.Details); ok {
+				code = string(details.Code)
+			} else {
+				code = string(pdferrors.Internal)
+			}
+		}
+		s.pdfService.Metrics().ObserveTool(name, time.Since(started), bytes, code)
+		return result, err
+	}
+}
+
+// inputBytes returns the total size of the documents a request's path arguments name;
+// arguments that name no readable file count nothing
+func (s *Server) inputBytes(ctx context.Context, request mcp.CallToolRequest) int64 {
+	var paths []string
+	args := request.GetArguments()
+	for _, name := range pathArguments {
+		switch value := args[name].(type) {
+		case string:
+			paths = append(paths, value)
+		case []any:
+			for _, item := range value {
+				if item, ok := item.(string); ok {
+					paths = append(paths, item)
+				}
+			}
+		}
+	}
+
+	var total int64
+	for _, path := range paths {
+		resolved, err := s.resolvePath(ctx, path, false)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(resolved); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// metricsHandler serves the service's metrics in the Prometheus text exposition format
+func (s *Server) metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := s.pdfService.WriteMetrics(w); err != nil {
+			logger.Warn("failed to write metrics", "error", err)
+		}
+	})
+	return mux
+}
+
+// serveMetrics starts the HTTP listener serving /metrics on address and returns a function
+// that stops it
+func (s *Server) serveMetrics(address string) (func(), error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start metrics listener: %w", err)
+	}
+	httpServer := &http.Server{Handler: s.metricsHandler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics listener stopped", "error", err)
+		}
+	}()
+	logger.Info("serving metrics", "address", listener.Addr().String())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+		defer cancel()
+		httpServer.Shutdown(ctx) //nolint:errcheck // The server is stopping
+	}, nil
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+)
+
+func TestServer_Metrics(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	writeTextPDF(t, report, "Quarterly report")
+	s, err := NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server"}, pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+
+	callTool(t, s, "pdf_read_file", map[string]any{"path": report})
+	callTool(t, s, "pdf_read_file", map[string]any{"path": filepath.Join(dir, "missing.pdf")})
+
+	result := callTool(t, s, "pdf_server_info", map[string]any{"response_format": "json"})
+	var info pdf.PDFServerInfoResult
+	if err := json.Unmarshal([]byte(extractTextFromResult(result)), &info); err != nil {
+		t.Fatalf("pdf_server_info returned %q: %v", extractTextFromResult(result), err)
+	}
+	if info.Metrics == nil || info.Metrics.ToolCalls != 2 || info.Metrics.ToolErrors != 1 {
+		t.Fatalf("pdf_server_info metrics = %+v, want the two earlier calls", info.Metrics)
+	}
+	if tool := info.Metrics.Tools[0]; tool.Name != "pdf_read_file" || tool.BytesRead == 0 {
+		t.Errorf("pdf_server_info tool metrics = %+v", tool)
+	}
+
+	server := httptest.NewServer(s.metricsHandler())
+	t.Cleanup(server.Close)
+	response, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics unexpected error = %v", err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK ||
+		!strings.HasPrefix(response.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("GET /metrics = %d %s", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	for _, want := range []string{
+		`mcp_pdf_tool_calls_total{tool="pdf_read_file"} 2`,
+		`mcp_pdf_tool_calls_total{tool="pdf_server_info"} 1`,
+		`mcp_pdf_tool_errors_total{tool="pdf_read_file",code="NOT_FOUND"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("GET /metrics lacks %q:\n%s", want, body)
+		}
+	}
+
+	stop, err := s.serveMetrics("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("serveMetrics() unexpected error = %v", err)
+	}
+	stop()
+	if _, err := s.serveMetrics("not an address"); err == nil {
+		t.Error("serveMetrics() of an invalid address succeeded")
+	}
+}
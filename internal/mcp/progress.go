@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// progressInterval is the least time between two progress notifications of a request, so
+// that documents of thousands of pages do not flood the client
+const progressInterval = 250 * time.Millisecond
+
+// progressMethod is the method of MCP progress notifications
+const progressMethod = "notifications/progress"
+
+// withProgress makes the service report the progress of a request to the client as MCP
+// progress notifications, when the request carries a progress token
+func withProgress(ctx context.Context, request mcp.CallToolRequest) context.Context {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return ctx
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return ctx
+	}
+	send := func(params map[string]any) error {
+		return mcpServer.SendNotificationToClient(ctx, progressMethod, params)
+	}
+	return pdf.WithProgress(ctx, progressNotifier(request.Params.Meta.ProgressToken, send))
+}
+
+// notifyQueued tells the client that a call carrying a progress token waits for a free slot,
+// with a progress notification at zero that the call's own progress then follows
+func notifyQueued(ctx context.Context, request mcp.CallToolRequest, ahead int) {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return
+	}
+	params := map[string]any{
+		"progressToken": request.Params.Meta.ProgressToken,
+		"progress":      0,
+		"message":       fmt.Sprintf("queued: server is busy, %d calls waiting ahead", ahead),
+	}
+	if err := mcpServer.SendNotificationToClient(ctx, progressMethod, params); err != nil {
+		logger.Debug("progress notification not sent", "error", err)
+	}
+}
+
+// progressNotifier returns a progress reporter that sends notifications for token. Progress
+// only ever increases, so reports arriving out of order are dropped, and reports closer
+// together than progressInterval are dropped except the last.
+func progressNotifier(token mcp.ProgressToken, send func(params map[string]any) error) pdf.ProgressFunc {
+	var mu sync.Mutex
+	var last time.Time
+	progress := 0
+	return func(done, total int, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if done <= progress || (done < total && time.Since(last) < progressInterval) {
+			return
+		}
+		progress, last = done, time.Now()
+
+		params := map[string]any{"progressToken": token, "progress": done}
+		if total > 0 {
+			params["total"] = total
+		}
+		if message != "" {
+			params["message"] = message
+		}
+		if err := send(params); err != nil {
+			logger.Debug("progress notification not sent", "error", err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package mcp
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestProgressNotifier(t *testing.T) {
+	var sent []string
+	report := progressNotifier("extract-1", func(params map[string]any) error {
+		if params["progressToken"] != "extract-1" {
+			t.Errorf("notification token = %v, want extract-1", params["progressToken"])
+		}
+		sent = append(sent, fmt.Sprintf("%v/%v %v", params["progress"], params["total"], params["message"]))
+		return nil
+	})
+
+	report(1, 4, "extracted page 1")
+	report(2, 4, "extracted page 2") // Too soon after the first
+	report(1, 4, "extracted page 1") // Out of order
+	report(4, 4, "extracted page 4") // The last is always sent
+	time.Sleep(progressInterval)
+	report(3, 4, "extracted page 3") // Progress never decreases
+
+	want := "[1/4 extracted page 1 4/4 extracted page 4]"
+	if fmt.Sprint(sent) != want {
+		t.Errorf("progressNotifier() sent %v, want %s", sent, want)
+	}
+}
@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// workflowPrompt is a prompt template that walks a client through a common PDF workflow with
+// the server's tools, naming each call and its parameters so that clients without their own
+// integration get good defaults
+type workflowPrompt struct {
+	prompt mcp.Prompt
+	tools  []string // Tools the workflow calls; the prompt is offered only when all are registered
+	text   func(args map[string]string) string
+}
+
+// registerPrompts registers the workflow prompts whose tools are all registered
+func (s *Server) registerPrompts() {
+	for _, p := range s.workflowPrompts() {
+		if !s.toolsEnabled(p.tools) {
+			continue
+		}
+		s.mcpServer.AddPrompt(p.prompt, promptHandler(p))
+		s.enabledPrompts = append(s.enabledPrompts, p.prompt.Name)
+	}
+}
+
+// toolsEnabled reports whether every tool was registered
+func (s *Server) toolsEnabled(tools []string) bool {
+	for _, tool := range tools {
+		if !slices.Contains(s.enabledTools, tool) {
+			return false
+		}
+	}
+	return true
+}
+
+// promptHandler checks a prompt's required arguments and fills in its template
+func promptHandler(p workflowPrompt) func(context.Context, mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return func(_ context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := make(map[string]string, len(request.Params.Arguments))
+		for name, value := range request.Params.Arguments {
+			args[name] = strings.TrimSpace(value)
+		}
+		for _, arg := range p.prompt.Arguments {
+			if arg.Required && args[arg.Name] == "" {
+				return nil, fmt.Errorf("prompt %s requires the %s argument", p.prompt.Name, arg.Name)
+			}
+		}
+		return mcp.NewGetPromptResult(p.prompt.Description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(p.text(args))),
+		}), nil
+	}
+}
+
+// toolCall formats a tool call with its arguments as JSON, for prompts to spell out
+func toolCall(tool string, args map[string]any) string {
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		encoded = []byte("{}")
+	}
+	return fmt.Sprintf("`%s` with `%s`", tool, encoded)
+}
+
+// workflowPrompts returns the server's prompt templates. Steps that write files are left out
+// in read-only mode, where the tools refuse output paths.
+func (s *Server) workflowPrompts() []workflowPrompt {
+	readOnly := s.config.ReadOnly
+	return []workflowPrompt{
+		{
+			prompt: mcp.NewPrompt("summarize_pdf",
+				mcp.WithPromptDescription("Summarize a PDF, following its outline and reading long documents in chunks"),
+				mcp.WithArgument("path", mcp.RequiredArgument(), mcp.ArgumentDescription("Full path to the PDF file")),
+				mcp.WithArgument("focus", mcp.ArgumentDescription("What the summary should concentrate on")),
+			),
+			tools: []string{"pdf_get_metadata", "pdf_get_outline", "pdf_chunk_content"},
+			text: func(args map[string]string) string {
+				path := args["path"]
+				text := fmt.Sprintf("Summarize the PDF %s.\n\n", path)
+				text += fmt.Sprintf("1. Call %s for its title, author, and page count.\n",
+					toolCall("pdf_get_metadata", map[string]any{"path": path}))
+				text += fmt.Sprintf("2. Call %s; when it has bookmarks, organize the summary by its top-level "+
+					"sections.\n", toolCall("pdf_get_outline", map[string]any{"path": path}))
+				text += fmt.Sprintf("3. Call %s and read the chunks in order, noting the key points of each "+
+					"before moving on, so that long documents fit.\n",
+					toolCall("pdf_chunk_content", map[string]any{"path": path, "max_tokens": 1500}))
+				text += "4. Write a summary of a short paragraph followed by the key points as a list, citing " +
+					"the pages they come from."
+				if focus := args["focus"]; focus != "" {
+					text += fmt.Sprintf("\n\nConcentrate on: %s", focus)
+				}
+				return text
+			},
+		},
+		{
+			prompt: mcp.NewPrompt("extract_tables_csv",
+				mcp.WithPromptDescription("Extract every table of a PDF as CSV"),
+				mcp.WithArgument("path", mcp.RequiredArgument(), mcp.ArgumentDescription("Full path to the PDF file")),
+				mcp.WithArgument("pages", mcp.ArgumentDescription("Pages to read, such as 1-5,9 (default: all)")),
+				mcp.WithArgument("output_path", mcp.ArgumentDescription("CSV file to write; several tables are "+
+					"numbered name-1.csv, name-2.csv, ... (default: beside the PDF)")),
+			),
+			tools: []string{"pdf_extract_tables"},
+			text: func(args map[string]string) string {
+				path := args["path"]
+				call := map[string]any{"path": path}
+				if pages := args["pages"]; pages != "" {
+					call["pages"] = pages
+				}
+				if readOnly {
+					return fmt.Sprintf("Extract every table of the PDF %s as CSV.\n\n1. Call %s.\n"+
+						"2. Write each table as a CSV code block with its header row first, introduced by its page "+
+						"and position. Say so when no tables are found.", path, toolCall("pdf_extract_tables", call))
+				}
+				output := args["output_path"]
+				if output == "" {
+					output = strings.TrimSuffix(path, filepath.Ext(path)) + "-tables.csv"
+				}
+				call["export_format"] = pdf.TableExportCSV
+				call["output_path"] = output
+				return fmt.Sprintf("Extract every table of the PDF %s as CSV.\n\n1. Call %s.\n"+
+					"2. Report the CSV files written, with the page, size, and header row of each table. Say so "+
+					"when no tables are found.", path, toolCall("pdf_extract_tables", call))
+			},
+		},
+		{
+			prompt: mcp.NewPrompt("compare_pdfs",
+				mcp.WithPromptDescription("Compare two PDFs and describe how they differ"),
+				mcp.WithArgument("path_a", mcp.RequiredArgument(), mcp.ArgumentDescription("Full path to the first PDF")),
+				mcp.WithArgument("path_b", mcp.RequiredArgument(), mcp.ArgumentDescription("Full path to the second PDF")),
+			),
+			tools: []string{"pdf_compare_set", "pdf_export_text"},
+			text: func(args map[string]string) string {
+				a, b := args["path_a"], args["path_b"]
+				text := fmt.Sprintf("Compare the PDFs %s and %s.\n\n", a, b)
+				text += fmt.Sprintf("1. Call %s for how similar their text and layout are.\n",
+					toolCall("pdf_compare_set", map[string]any{"paths": []string{a, b}}))
+				text += fmt.Sprintf("2. Call %s and %s.\n",
+					toolCall("pdf_export_text", map[string]any{"path": a, "format": pdf.TextFormatMarkdown,
+						"strip_headers_footers": true}),
+					toolCall("pdf_export_text", map[string]any{"path": b, "format": pdf.TextFormatMarkdown,
+						"strip_headers_footers": true}))
+				text += "3. Go through the documents section by section and list what was added, removed, and " +
+					"changed, quoting the changed wording and giving page numbers. Lead with the changes that " +
+					"matter most, such as amounts, dates, names, and obligations, and end with the similarity score."
+				return text
+			},
+		},
+		{
+			prompt: mcp.NewPrompt("check_pdf_safety",
+				mcp.WithPromptDescription("Check whether a PDF is safe to process before reading it"),
+				mcp.WithArgument("path", mcp.RequiredArgument(), mcp.ArgumentDescription("Full path to the PDF file")),
+			),
+			tools: []string{"pdf_security_scan", "pdf_get_permissions"},
+			text: func(args map[string]string) string {
+				path := args["path"]
+				text := fmt.Sprintf("Check whether the PDF %s is safe to process.\n\n", path)
+				text += fmt.Sprintf("1. Call %s.\n", toolCall("pdf_security_scan", map[string]any{"path": path}))
+				text += fmt.Sprintf("2. Call %s to learn whether its text may be extracted.\n",
+					toolCall("pdf_get_permissions", map[string]any{"path": path}))
+				text += fmt.Sprintf("3. When the risk is %s, stop and explain the findings without processing the "+
+					"document further. Otherwise report the risk, the findings worth knowing about, and what the "+
+					"permissions allow.", pdf.SecurityRiskHigh)
+				return text
+			},
+		},
+		{
+			prompt: mcp.NewPrompt("find_in_pdfs",
+				mcp.WithPromptDescription("Find which PDFs in a directory mention something, and where"),
+				mcp.WithArgument("query", mcp.RequiredArgument(), mcp.ArgumentDescription("Text to search for")),
+				mcp.WithArgument("directory", mcp.ArgumentDescription("Directory to search (default: the "+
+					"server's directory)")),
+			),
+			tools: []string{"pdf_search_content_directory", "pdf_find_text"},
+			text: func(args map[string]string) string {
+				query := args["query"]
+				call := map[string]any{"query": query}
+				if directory := args["directory"]; directory != "" {
+					call["directory"] = directory
+				}
+				text := fmt.Sprintf("Find the PDFs that mention %q.\n\n", query)
+				text += fmt.Sprintf("1. Call %s.\n", toolCall("pdf_search_content_directory", call))
+				text += fmt.Sprintf("2. For the files with the most matches, call %s with each file's path "+
+					"to locate every occurrence.\n", toolCall("pdf_find_text", map[string]any{"query": query}))
+				text += "3. List the matching files, most matches first, with the pages and a snippet of each match."
+				return text
+			},
+		},
+	}
+}
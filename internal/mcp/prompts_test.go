@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+)
+
+// sendPromptRequest sends a prompts method through the protocol and returns the server's response
+func sendPromptRequest(t *testing.T, s *Server, method string, params map[string]any) mcp.JSONRPCMessage {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.mcpServer.HandleMessage(context.Background(), message)
+}
+
+// promptText gets a prompt through the protocol and returns the text of its message
+func promptText(t *testing.T, s *Server, name string, args map[string]string) string {
+	t.Helper()
+	response := sendPromptRequest(t, s, "prompts/get", map[string]any{"name": name, "arguments": args})
+	rpc, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("prompts/get %s returned %#v", name, response)
+	}
+	result, ok := rpc.Result.(mcp.GetPromptResult)
+	if !ok || len(result.Messages) != 1 {
+		t.Fatalf("prompts/get %s returned %#v", name, rpc.Result)
+	}
+	text, ok := result.Messages[0].Content.(mcp.TextContent)
+	if !ok || result.Messages[0].Role != mcp.RoleUser {
+		t.Fatalf("prompts/get %s returned message %#v", name, result.Messages[0])
+	}
+	return text.Text
+}
+
+func TestServer_Prompts(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server"}, pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+
+	response := sendPromptRequest(t, s, "prompts/list", map[string]any{})
+	rpc, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("prompts/list returned %#v", response)
+	}
+	var names []string
+	for _, prompt := range rpc.Result.(mcp.ListPromptsResult).Prompts {
+		names = append(names, prompt.Name)
+	}
+	slices.Sort(names)
+	want := []string{"check_pdf_safety", "compare_pdfs", "extract_tables_csv", "find_in_pdfs", "summarize_pdf"}
+	if !slices.Equal(names, want) {
+		t.Errorf("prompts = %v, want %v", names, want)
+	}
+
+	text := promptText(t, s, "extract_tables_csv", map[string]string{"path": "/docs/report.pdf", "pages": "2-3"})
+	call := "`pdf_extract_tables` with `{\"export_format\":\"csv\",\"output_path\":\"/docs/report-tables.csv\"," +
+		"\"pages\":\"2-3\",\"path\":\"/docs/report.pdf\"}`"
+	if !strings.Contains(text, call) {
+		t.Errorf("extract_tables_csv text = %q, want the call %s", text, call)
+	}
+	text = promptText(t, s, "summarize_pdf", map[string]string{"path": "/docs/report.pdf", "focus": "risks"})
+	if !strings.Contains(text, "`pdf_chunk_content`") || !strings.HasSuffix(text, "Concentrate on: risks") {
+		t.Errorf("summarize_pdf text = %q", text)
+	}
+
+	if response := sendPromptRequest(t, s, "prompts/get", map[string]any{
+		"name": "compare_pdfs", "arguments": map[string]string{"path_a": "/docs/a.pdf"},
+	}); !isRPCError(response) {
+		t.Errorf("prompts/get without a required argument = %#v, want an error", response)
+	}
+
+	// Prompts are offered only with their tools, and write no files in read-only mode
+	s, err = NewServer(&config.Config{
+		PDFDirectory: dir, ServerName: "test-server", ReadOnly: true, DisabledTools: []string{"pdf_compare_set"},
+	}, pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+	if slices.Contains(s.enabledPrompts, "compare_pdfs") || !slices.Contains(s.enabledPrompts, "summarize_pdf") {
+		t.Errorf("prompts with pdf_compare_set disabled = %v", s.enabledPrompts)
+	}
+	text = promptText(t, s, "extract_tables_csv", map[string]string{"path": "/docs/report.pdf"})
+	if strings.Contains(text, "output_path") {
+		t.Errorf("read-only extract_tables_csv text = %q, want no output path", text)
+	}
+}
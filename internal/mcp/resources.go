@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// resourceState is what the watcher remembers of a PDF exposed as a resource
+type resourceState struct {
+	size    int64
+	modTime time.Time
+}
+
+// resourceChanges lists the resource URIs one directory scan added, removed, and updated
+type resourceChanges struct {
+	added   []string
+	removed []string
+	updated []string
+}
+
+// resourceRegistry tracks the PDFs of the configured directories exposed as MCP resources
+type resourceRegistry struct {
+	mu    sync.Mutex
+	known map[string]resourceState // By resource URI
+}
+
+// resourceURI names a PDF as a file:// URI
+func resourceURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// syncResources scans the configured directories and brings the server's resources in line
+// with them. New PDFs are added and deleted ones removed, which notifies clients that the
+// resource list changed; PDFs whose size or modification time changed are reported to
+// clients as updated.
+func (s *Server) syncResources() (resourceChanges, error) {
+	var changes resourceChanges
+	var files []pdf.FileInfo
+	for _, dir := range s.config.Directories() {
+		found, err := s.pdfService.FindPDFsInDirectory(dir)
+		if err != nil {
+			return changes, err
+		}
+		files = append(files, found...)
+	}
+
+	current := make(map[string]resourceState, len(files))
+	paths := make(map[string]string, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file.Path)
+		if err != nil {
+			continue // Removed between the scan and now; the next scan drops it
+		}
+		uri := resourceURI(file.Path)
+		current[uri] = resourceState{size: info.Size(), modTime: info.ModTime()}
+		paths[uri] = file.Path
+	}
+
+	s.resources.mu.Lock()
+	defer s.resources.mu.Unlock()
+
+	var added []server.ServerResource
+	for uri, state := range current {
+		previous, ok := s.resources.known[uri]
+		switch {
+		case !ok:
+			added = append(added, server.ServerResource{
+				Resource: mcp.NewResource(uri, filepath.Base(paths[uri]),
+					mcp.WithResourceDescription(fmt.Sprintf("PDF document %s (%d bytes)", paths[uri], state.size)),
+					mcp.WithMIMEType("application/pdf"),
+				),
+				Handler: s.resourceHandler(paths[uri]),
+			})
+			changes.added = append(changes.added, uri)
+		case previous != state:
+			changes.updated = append(changes.updated, uri)
+		}
+	}
+	for uri := range s.resources.known {
+		if _, ok := current[uri]; !ok {
+			changes.removed = append(changes.removed, uri)
+		}
+	}
+	s.resources.known = current
+
+	// AddResources notifies once for the whole batch; removals notify one by one
+	if len(added) > 0 {
+		s.mcpServer.AddResources(added...)
+	}
+	for _, uri := range changes.removed {
+		s.mcpServer.RemoveResource(uri)
+	}
+	for _, uri := range changes.updated {
+		s.mcpServer.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+	}
+
+	sort.Strings(changes.added)
+	sort.Strings(changes.removed)
+	sort.Strings(changes.updated)
+	return changes, nil
+}
+
+// resourceHandler reads a PDF resource as its extracted text
+func (s *Server) resourceHandler(path string) server.ResourceHandlerFunc {
+	return func(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		result, err := s.pdfService.PDFReadFile(pdf.PDFReadFileRequest{Path: path})
+		if err != nil {
+			return nil, err
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "text/plain",
+				Text:     result.Content,
+			},
+		}, nil
+	}
+}
+
+// watchResources rescans the configured directories every interval until ctx is done
+func (s *Server) watchResources(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changes, err := s.syncResources()
+			if err != nil {
+				logger.Warn("failed to scan PDF directories for resources", "dirs", s.config.Directories(), "error", err)
+				continue
+			}
+			if len(changes.added)+len(changes.removed)+len(changes.updated) > 0 {
+				logger.Debug("PDF resources changed", "added", len(changes.added),
+					"removed", len(changes.removed), "updated", len(changes.updated))
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+)
+
+// writeTextPDF writes a one-page PDF showing text
+func writeTextPDF(t *testing.T, path, text string) {
+	t.Helper()
+	content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R " +
+			"/Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// listResources lists the server's resources through the protocol
+func listResources(t *testing.T, s *Server) []mcp.Resource {
+	t.Helper()
+	response := s.mcpServer.HandleMessage(context.Background(),
+		json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`))
+	rpc, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("resources/list returned %#v", response)
+	}
+	return rpc.Result.(mcp.ListResourcesResult).Resources
+}
+
+func TestServer_Resources(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	writeTextPDF(t, report, "Quarterly report")
+
+	cfg := &config.Config{PDFDirectory: dir, ServerName: "test-server", Version: "1.0.0"}
+	s, err := NewServer(cfg, pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+
+	resources := listResources(t, s)
+	if len(resources) != 1 || resources[0].URI != resourceURI(report) ||
+		resources[0].Name != "report.pdf" || resources[0].MIMEType != "application/pdf" {
+		t.Fatalf("resources/list = %+v, want report.pdf as a PDF resource", resources)
+	}
+
+	read := fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":%q}}`, resources[0].URI)
+	response := s.mcpServer.HandleMessage(context.Background(), json.RawMessage(read))
+	rpc, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("resources/read returned %#v", response)
+	}
+	contents := rpc.Result.(mcp.ReadResourceResult).Contents
+	if len(contents) != 1 || !strings.Contains(contents[0].(mcp.TextResourceContents).Text, "Quarterly report") {
+		t.Errorf("resources/read = %+v, want the text of report.pdf", contents)
+	}
+
+	// Add a document, remove one, and rewrite another between scans
+	invoice := filepath.Join(dir, "invoice.pdf")
+	notes := filepath.Join(dir, "notes.pdf")
+	writeTextPDF(t, invoice, "Invoice")
+	writeTextPDF(t, notes, "Notes")
+	if _, err := s.syncResources(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(report); err != nil {
+		t.Fatal(err)
+	}
+	writeTextPDF(t, notes, "Revised notes")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(notes, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := s.syncResources()
+	if err != nil {
+		t.Fatalf("syncResources() unexpected error = %v", err)
+	}
+	want := resourceChanges{removed: []string{resourceURI(report)}, updated: []string{resourceURI(notes)}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("syncResources() = %+v, want %+v", changes, want)
+	}
+	if resources := listResources(t, s); len(resources) != 2 {
+		t.Errorf("resources/list = %+v, want invoice.pdf and notes.pdf", resources)
+	}
+}
+
+func TestServer_ResourcesFromRoots(t *testing.T) {
+	dir, project := t.TempDir(), t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	plan := filepath.Join(project, "plan.pdf")
+	writeTextPDF(t, report, "Quarterly report")
+	writeTextPDF(t, plan, "Project plan")
+
+	cfg := &config.Config{PDFDirectory: dir, Roots: []string{project, dir}, ServerName: "test-server"}
+	s, err := NewServer(cfg, pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+
+	uris := map[string]bool{}
+	for _, resource := range listResources(t, s) {
+		uris[resource.URI] = true
+	}
+	if len(uris) != 2 || !uris[resourceURI(report)] || !uris[resourceURI(plan)] {
+		t.Errorf("resources/list = %v, want the PDFs of both roots", uris)
+	}
+
+	result := callTool(t, s, "pdf_server_info", map[string]any{"response_format": "json"})
+	var info pdf.PDFServerInfoResult
+	if err := json.Unmarshal([]byte(extractTextFromResult(result)), &info); err != nil {
+		t.Fatalf("pdf_server_info returned %q: %v", extractTextFromResult(result), err)
+	}
+	if !reflect.DeepEqual(info.Roots, []string{dir, project}) {
+		t.Errorf("pdf_server_info roots = %v, want %v", info.Roots, []string{dir, project})
+	}
+}
@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+)
+
+// Response formats accepted by every tool's response_format parameter
+const (
+	ResponseFormatMarkdown = "markdown"
+	ResponseFormatJSON     = "json"
+)
+
+// withResponseFormat adds the response_format parameter shared by all tools
+func withResponseFormat() mcp.ToolOption {
+	return mcp.WithString("response_format",
+		mcp.Description("Response format: 'markdown' for readable text (default) or 'json' for the raw result object"),
+		mcp.Enum(ResponseFormatMarkdown, ResponseFormatJSON),
+		mcp.DefaultString(ResponseFormatMarkdown),
+	)
+}
+
+// newToolResult returns the tool result in the format requested by the client: the
+// formatted Markdown text, or the result struct serialized as a JSON text content block
+func newToolResult(request mcp.CallToolRequest, result any, markdown string) (*mcp.CallToolResult, error) {
+	switch format := request.GetString("response_format", ResponseFormatMarkdown); format {
+	case ResponseFormatMarkdown, "":
+		return mcp.NewToolResultText(markdown), nil
+	case ResponseFormatJSON:
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to encode result as JSON: %v", err)), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("invalid response_format: %s (must be %s or %s)",
+			format, ResponseFormatMarkdown, ResponseFormatJSON)), nil
+	}
+}
+
+// errorMetaKey names the _meta entry of error results that holds the error's details
+const errorMetaKey = "error"
+
+// toolError returns the result of a tool call that failed with err, carrying the error's
+// code, remediation hint, and any partial result in its details
+func toolError(err error) *mcp.CallToolResult {
+	result := mcp.NewToolResultError(err.Error())
+	result.Meta = map[string]any{errorMetaKey: pdferrors.Describe(err)}
+	return result
+}
+
+// describeErrors wraps a tool's handler so that every error result is machine-readable: its
+// _meta holds the details of the error, classified from the message when the handler gave
+// none, and its text adds the hint and code, or is the details as JSON when the client asked
+// for JSON responses.
+func describeErrors(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		if err != nil || result == nil || !result.IsError {
+			return result, err
+		}
+		details, ok := result.Meta[errorMetaKey].(pdferrors.Details)
+		if !ok {
+			details = pdferrors.DescribeMessage(errorText(result))
+			if result.Meta == nil {
+				result.Meta = map[string]any{}
+			}
+			result.Meta[errorMetaKey] = details
+		}
+
+		text := formatToolError(details)
+		if request.GetString("response_format", ResponseFormatMarkdown) == ResponseFormatJSON {
+			if data, err := json.MarshalIndent(map[string]any{errorMetaKey: details}, "", "  "); err == nil {
+				text = string(data)
+			}
+		}
+		result.Content = []mcp.Content{mcp.NewTextContent(text)}
+		return result, nil
+	}
+}
+
+// errorText joins the text of an error result
+func errorText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// formatToolError formats an error's message with its hint and code
+func formatToolError(details pdferrors.Details) string {
+	text := fmt.Sprintf("%s\n\n💡 %s\n🏷️  Error code: %s", details.Message, details.Hint, details.Code)
+	if details.Retryable {
+		text += " (retryable)"
+	}
+	if details.Partial != nil {
+		text += "\n⚠️  A partial result is attached in _meta.error.partial"
+	}
+	return text
+}
@@ -2,20 +2,40 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
-	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/logging"
 	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// logger reports the server's lifecycle; tool errors are returned to the client instead
+var logger = logging.Component("mcp")
+
 // Server represents the MCP server instance
 type Server struct {
 	config     *config.Config
 	pdfService *pdf.Service
 	mcpServer  *server.MCPServer
+	resources  resourceRegistry
+	workspace  *workspace
+	limiter    *limiter
+
+	enabledTools   []string // Tools registered, in registration order
+	withheldTools  []string // Tools the configuration kept from being registered
+	enabledPrompts []string // Workflow prompts registered, those whose tools are all registered
 }
 
 // NewServer creates a new MCP server instance
@@ -24,21 +44,44 @@ func NewServer(cfg *config.Config, pdfService *pdf.Service) (*Server, error) {
 		return nil, fmt.Errorf("pdfService cannot be nil")
 	}
 
+	// Remove each session's workspace when the session ends
+	workspace := &workspace{quota: cfg.WorkspaceQuota}
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		workspace.removeSession(session.SessionID())
+	})
+
 	// Create MCP server
 	mcpServer := server.NewMCPServer(
 		cfg.ServerName,
 		cfg.Version,
 		server.WithToolCapabilities(false), // We don't support dynamic tool capabilities
+		server.WithResourceCapabilities(false, true),
+		server.WithPromptCapabilities(false),
+		server.WithHooks(hooks),
 	)
 
 	s := &Server{
 		config:     cfg,
 		pdfService: pdfService,
 		mcpServer:  mcpServer,
+		workspace:  workspace,
+		limiter:    newLimiter(cfg.MaxConcurrent, cfg.MaxConcurrentPerSession, cfg.QueueTimeout),
 	}
 
 	// Register tools
 	s.registerTools()
+	if err := s.checkToolNames(); err != nil {
+		return nil, err
+	}
+
+	// Offer prompt templates for the workflows the registered tools support
+	s.registerPrompts()
+
+	// Expose the PDFs of the configured directories as resources
+	if _, err := s.syncResources(); err != nil {
+		logger.Warn("failed to list PDF resources", "dirs", cfg.Directories(), "error", err)
+	}
 
 	return s, nil
 }
@@ -60,19 +103,97 @@ func (s *Server) registerBasicTools() {
 			mcp.Required(),
 			mcp.Description("Full path to the PDF file"),
 		),
+		mcp.WithString("layout",
+			mcp.Description("Text layout (default: raw): raw reads text in the order the PDF draws it, "+
+				"layout keeps columns and indentation with spaces, reading-order reads multi-column pages "+
+				"a column at a time, markdown converts headings, lists, and tables to Markdown"),
+			mcp.Enum(pdf.LayoutRaw, pdf.LayoutPositioned, pdf.LayoutReadingOrder, pdf.LayoutMarkdown),
+		),
+		mcp.WithBoolean("strip_headers_footers",
+			mcp.Description("Leave out lines repeated at the same height on at least half of the pages read, "+
+				"such as running headers, footers, page numbers, and watermarks (default: false)"),
+		),
+		withPageSelection(),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfReadFileTool, s.handlePDFReadFile)
+	s.addTool(pdfReadFileTool, s.handlePDFReadFile)
 
 	// Register PDF assets file tool
 	pdfAssetsFileTool := mcp.NewTool(
 		"pdf_assets_file",
-		mcp.WithDescription("Extract visual assets like images from a PDF file"),
+		mcp.WithDescription("Extract visual assets like images from a PDF file with the size and effective "+
+			"DPI each is drawn at, optionally classifying them as barcodes, QR codes, signatures, or stamps"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithBoolean("classify",
+			mcp.Description("Decode each image to detect barcodes and QR codes, returning their payloads, "+
+				"and handwritten signatures and rubber stamps (default: false)"),
+		),
+		mcp.WithNumber("min_dpi",
+			mcp.Description("Flag images drawn below this resolution, for print-quality checks (default: 300)"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfAssetsFileTool, s.handlePDFAssetsFile)
+
+	// Register PDF render page tool
+	pdfRenderPageTool := mcp.NewTool(
+		"pdf_render_page",
+		mcp.WithDescription("Render a page to a PNG or JPEG image at a given resolution, for inspecting "+
+			"scanned or graphical pages visually. Requires pdftoppm (poppler-utils) or mutool (mupdf-tools)"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithNumber("page",
+			mcp.Description("Page number to render, starting at 1 (default: 1)"),
+		),
+		mcp.WithNumber("dpi",
+			mcp.Description("Resolution in dots per inch, 36-600 (default: 150)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Image format (default: png)"),
+			mcp.Enum(pdf.RenderFormatPNG, pdf.RenderFormatJPEG),
+		),
+		mcp.WithNumber("quality",
+			mcp.Description("JPEG quality, 1-100 (default: 85)"),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Save the image to this directory instead of returning it"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfRenderPageTool, s.handlePDFRenderPage)
+
+	// Register PDF OCR region tool
+	pdfOCRRegionTool := mcp.NewTool(
+		"pdf_ocr_region",
+		mcp.WithDescription("Recognize the text of selected regions of a page, such as a stamp or a scanned "+
+			"signature block, or of every image on the page, returning the text with its confidence and "+
+			"word boxes in PDF points. Requires tesseract and pdftoppm (poppler-utils) or mutool (mupdf-tools)"),
 		mcp.WithString("path",
 			mcp.Required(),
 			mcp.Description("Full path to the PDF file"),
 		),
+		mcp.WithNumber("page",
+			mcp.Description("Page number, starting at 1 (default: 1)"),
+		),
+		mcp.WithArray("regions",
+			mcp.Description("Areas to recognize as objects {x, y, width, height} in PDF points "+
+				"(origin at the bottom-left of the page); every image on the page when omitted"),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+		mcp.WithString("language",
+			mcp.Description("Tesseract language, such as eng or deu+fra (default: eng)"),
+		),
+		mcp.WithNumber("dpi",
+			mcp.Description("Resolution the page is rendered at for recognition, 36-600 (default: 300)"),
+		),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfAssetsFileTool, s.handlePDFAssetsFile)
+	s.addTool(pdfOCRRegionTool, s.handlePDFOCRRegion)
 
 	// Register PDF validate file tool
 	pdfValidateFileTool := mcp.NewTool(
@@ -82,8 +203,9 @@ func (s *Server) registerBasicTools() {
 			mcp.Required(),
 			mcp.Description("Full path to the PDF file"),
 		),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfValidateFileTool, s.handlePDFValidateFile)
+	s.addTool(pdfValidateFileTool, s.handlePDFValidateFile)
 
 	// Register PDF stats file tool
 	pdfStatsFileTool := mcp.NewTool(
@@ -93,8 +215,50 @@ func (s *Server) registerBasicTools() {
 			mcp.Required(),
 			mcp.Description("Full path to the PDF file"),
 		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfStatsFileTool, s.handlePDFStatsFile)
+
+	// Register PDF load bytes tool
+	pdfLoadBytesTool := mcp.NewTool(
+		"pdf_load_bytes",
+		mcp.WithDescription("Load a PDF held in memory instead of a file: send its bytes base64-encoded and get "+
+			"back a handle such as bytes:3f9a... to pass as the path of any other tool. Content is limited to the "+
+			"maximum file size; documents are kept for an hour after their last use"),
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description("Base64-encoded PDF, optionally as a data:application/pdf;base64,... URL"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name to remember the document by, such as its original file name"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfLoadBytesTool, s.handlePDFLoadBytes)
+
+	// Register PDF list outputs tool
+	pdfListOutputsTool := mcp.NewTool(
+		"pdf_list_outputs",
+		mcp.WithDescription("List the files in this session's workspace, newest first. Tools that write files "+
+			"accept workspace: paths as output_dir or output_path, such as workspace:renders or "+
+			"workspace:report-redacted.pdf, and every tool reads workspace: paths; the workspace is private to "+
+			"the session, limited by a quota, and deleted when the session ends or the server stops"),
+		withResponseFormat(),
+	)
+	s.addTool(pdfListOutputsTool, s.handlePDFListOutputs)
+
+	// Register PDF get output tool
+	pdfGetOutputTool := mcp.NewTool(
+		"pdf_get_output",
+		mcp.WithDescription("Retrieve a file from this session's workspace: text files such as CSV, JSON, and "+
+			"Markdown as text, and other files base64-encoded, with images also returned as image content"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("workspace: path of the file, as listed by pdf_list_outputs"),
+		),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfStatsFileTool, s.handlePDFStatsFile)
+	s.addTool(pdfGetOutputTool, s.handlePDFGetOutput)
 }
 
 // registerExtractionTools registers structured extraction tools
@@ -108,13 +272,20 @@ func (s *Server) registerExtractionTools() {
 			mcp.Description("Full path to the PDF file"),
 		),
 		mcp.WithString("mode",
-			mcp.Description("Extraction mode: raw, structured, semantic, table, complete (default: structured)"),
+			mcp.Description("Extraction mode: raw, structured, semantic, table, complete, or preview for a sample of "+
+				"a large document (first and last pages, section starts, table and form pages) within "+
+				"max_elements (default: structured)"),
 		),
 		mcp.WithString("config",
-			mcp.Description("JSON string with extraction configuration options"),
+			mcp.Description(extractionConfigDescription),
 		),
+		withPageSelection(),
+		withResumeToken(),
+		withPagination(),
+		withTimeout(),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfExtractStructuredTool, s.handlePDFExtractStructured)
+	s.addTool(pdfExtractStructuredTool, s.handlePDFExtractStructured)
 
 	// Register PDF extract tables tool
 	pdfExtractTablesTool := mcp.NewTool(
@@ -125,24 +296,42 @@ func (s *Server) registerExtractionTools() {
 			mcp.Description("Full path to the PDF file"),
 		),
 		mcp.WithString("config",
-			mcp.Description("JSON string with extraction configuration options"),
+			mcp.Description(extractionConfigDescription),
+		),
+		mcp.WithString("export_format",
+			mcp.Description("Also write the tables to output_path: csv (one file per table) or xlsx "+
+				"(one sheet per table); default: inferred from the output_path extension"),
+			mcp.Enum(pdf.TableExportCSV, pdf.TableExportXLSX),
 		),
+		mcp.WithString("output_path",
+			mcp.Description("File to export the tables to; several CSV tables are numbered name-1.csv, name-2.csv, ..."),
+		),
+		withPageSelection(),
+		withResumeToken(),
+		withTimeout(),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfExtractTablesTool, s.handlePDFExtractTables)
+	s.addTool(pdfExtractTablesTool, s.handlePDFExtractTables)
 
 	// Register PDF extract semantic tool
 	pdfExtractSemanticTool := mcp.NewTool(
 		"pdf_extract_semantic",
-		mcp.WithDescription("Extract content with semantic grouping and relationship detection"),
+		mcp.WithDescription("Extract content with semantic grouping and relationship detection. Tagged PDFs return their "+
+			"structure tree, and dates, amounts, emails, phone numbers, addresses, invoice numbers, and SSNs "+
+			"are returned as typed entities"),
 		mcp.WithString("path",
 			mcp.Required(),
 			mcp.Description("Full path to the PDF file"),
 		),
 		mcp.WithString("config",
-			mcp.Description("JSON string with extraction configuration options"),
+			mcp.Description(extractionConfigDescription),
 		),
+		withPageSelection(),
+		withResumeToken(),
+		withTimeout(),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfExtractSemanticTool, s.handlePDFExtractSemantic)
+	s.addTool(pdfExtractSemanticTool, s.handlePDFExtractSemantic)
 
 	// Register PDF extract complete tool
 	pdfExtractCompleteTool := mcp.NewTool(
@@ -153,10 +342,148 @@ func (s *Server) registerExtractionTools() {
 			mcp.Description("Full path to the PDF file"),
 		),
 		mcp.WithString("config",
-			mcp.Description("JSON string with extraction configuration options"),
+			mcp.Description(extractionConfigDescription),
+		),
+		withPageSelection(),
+		withResumeToken(),
+		withPagination(),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractCompleteTool, s.handlePDFExtractComplete)
+
+	// Register PDF export document tool
+	pdfExportDocumentTool := mcp.NewTool(
+		"pdf_export_document",
+		mcp.WithDescription("Convert the detected structure of a PDF (headings, paragraphs, lists, tables) "+
+			"into an editable DOCX or ODT skeleton. Images are kept as placeholder paragraphs"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Document format (default: docx)"),
+			mcp.Enum(pdf.ExportFormatDOCX, pdf.ExportFormatODT),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Save the document to this directory instead of returning it"),
+		),
+		mcp.WithString("structure_config",
+			mcp.Description(structureConfigDescription),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExportDocumentTool, s.handlePDFExportDocument)
+
+	// Register PDF export text tool
+	pdfExportTextTool := mcp.NewTool(
+		"pdf_export_text",
+		mcp.WithDescription("Export a PDF's cleaned text for indexing and embeddings as plain text, Markdown, or "+
+			"JSON Lines with one heading, paragraph, list item, or table per line with its page and bounding box. "+
+			"Running headers and footers can be removed and words hyphenated across lines joined"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Text format (default: text)"),
+			mcp.Enum(pdf.TextFormatPlain, pdf.TextFormatMarkdown, pdf.TextFormatJSONL),
+		),
+		withPages(),
+		mcp.WithBoolean("strip_headers_footers",
+			mcp.Description("Remove lines repeated at the top or bottom of at least half of the pages, "+
+				"such as running titles and page numbers (default: false)"),
+		),
+		mcp.WithBoolean("dehyphenate",
+			mcp.Description("Join words broken with a hyphen at the end of a line (default: false)"),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Save the text to this directory instead of returning it"),
+		),
+		mcp.WithString("structure_config",
+			mcp.Description(structureConfigDescription),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExportTextTool, s.handlePDFExportText)
+
+	// Register PDF extract reading order tool
+	pdfExtractReadingOrderTool := mcp.NewTool(
+		"pdf_extract_reading_order",
+		mcp.WithDescription("Get a PDF's logical blocks in the order they are read, for text-to-speech and "+
+			"accessibility tools: headings, paragraphs, list items, and tables, each with its type, text, page, "+
+			"and bounding box. Columns are read one after another, titles set across them in place, and "+
+			"rotated pages as displayed"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		withPages(),
+		mcp.WithBoolean("strip_headers_footers",
+			mcp.Description("Leave out lines repeated at the top or bottom of at least half of the pages, "+
+				"such as running titles and page numbers (default: false)"),
+		),
+		mcp.WithString("structure_config",
+			mcp.Description(structureConfigDescription),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractReadingOrderTool, s.handlePDFExtractReadingOrder)
+
+	// Register PDF chunk content tool
+	pdfChunkContentTool := mcp.NewTool(
+		"pdf_chunk_content",
+		mcp.WithDescription("Split a PDF's text into chunks for retrieval and summarization. Chunks follow the "+
+			"detected sections: a heading always starts a new chunk, and paragraphs, list items, and tables are "+
+			"only split when one alone is over the limit. Each chunk has its order, page range, and section "+
+			"headings"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		withPages(),
+		mcp.WithNumber("max_tokens",
+			mcp.Description("Largest chunk in tokens, estimated at four characters per token (default: 512)"),
+		),
+		mcp.WithNumber("max_characters",
+			mcp.Description("Largest chunk in characters, instead of max_tokens"),
+		),
+		mcp.WithNumber("overlap",
+			mcp.Description("Text from the end of a chunk repeated at the start of the next one in the same "+
+				"section, in the unit of the limit (default: 0)"),
+		),
+		mcp.WithString("structure_config",
+			mcp.Description(structureConfigDescription),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfChunkContentTool, s.handlePDFChunkContent)
+
+	// Register PDF export book tool
+	pdfExportBookTool := mcp.NewTool(
+		"pdf_export_book",
+		mcp.WithDescription("Convert a long, structured PDF (book, manual) into an EPUB or a navigable HTML bundle "+
+			"with one chapter per top-level bookmark and a page anchor (chapter file#page-N) for every page"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
 		),
+		mcp.WithString("format",
+			mcp.Description("Book format (default: epub)"),
+			mcp.Enum(pdf.ExportFormatEPUB, pdf.ExportFormatHTML),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Save the book to this directory instead of returning it; "+
+				"HTML bundles are saved as a folder named after the PDF"),
+		),
+		withTimeout(),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfExtractCompleteTool, s.handlePDFExtractComplete)
+	s.addTool(pdfExportBookTool, s.handlePDFExportBook)
 
 	// Register PDF query content tool
 	pdfQueryContentTool := mcp.NewTool(
@@ -168,10 +495,116 @@ func (s *Server) registerExtractionTools() {
 		),
 		mcp.WithString("query",
 			mcp.Required(),
-			mcp.Description("JSON string with query criteria for filtering content"),
+			mcp.Description(contentQueryDescription),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfQueryContentTool, s.handlePDFQueryContent)
+
+	// PDF query set tool
+	pdfQuerySetTool := mcp.NewTool(
+		"pdf_query_set",
+		mcp.WithDescription("Run a content query across a specific list of PDF files concurrently, "+
+			"returning per-file match counts and a global ranking of the best matches"),
+		mcp.WithArray("paths",
+			mcp.Required(),
+			mcp.Description("Full paths to the PDF files to query (up to 20)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description(contentQueryDescription),
+		),
+		mcp.WithNumber("max_results",
+			mcp.Description("Maximum number of ranked matches to return (default: 50)"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfQuerySetTool, s.handlePDFQuerySet)
+
+	// PDF batch extract tool
+	pdfBatchExtractTool := mcp.NewTool(
+		"pdf_batch_extract",
+		mcp.WithDescription("Extract every PDF in a directory, or a list of PDF files, with a bounded pool of workers, "+
+			"writing each file's result as JSON to an output directory with a manifest of successes, failures, "+
+			"and timings"),
+		mcp.WithString("directory",
+			mcp.Description("Directory whose PDF files, including those in subdirectories, are extracted "+
+				"(uses default if neither directory nor paths is given)"),
+		),
+		mcp.WithArray("paths",
+			mcp.Description("Full paths to the PDF files to extract, instead of a directory (up to 1000)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("output_dir",
+			mcp.Required(),
+			mcp.Description("Directory the per-file JSON results and manifest.json are written to"),
+		),
+		mcp.WithString("mode",
+			mcp.Description("Extraction mode: structured (default), semantic, table, or complete"),
+		),
+		mcp.WithString("config",
+			mcp.Description(extractionConfigDescription),
+		),
+		mcp.WithNumber("max_concurrency",
+			mcp.Description("Number of files extracted at once (default: 4, max: 16)"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfBatchExtractTool, s.handlePDFBatchExtract)
+
+	// Register extraction job tools
+	pdfExtractStartTool := mcp.NewTool(
+		"pdf_extract_start",
+		mcp.WithDescription("Start extracting a large PDF in the background and return a job ID at once; "+
+			"follow it with pdf_extract_status and fetch the output with pdf_extract_result. The job keeps "+
+			"running if this request is cancelled"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithString("mode",
+			mcp.Description("Extraction mode: structured (default), semantic, table, or complete"),
+		),
+		mcp.WithString("config",
+			mcp.Description(extractionConfigDescription),
+		),
+		withPageSelection(),
+		withResumeToken(),
+		mcp.WithNumber("timeout",
+			mcp.Description("Seconds the job may run before stopping with the pages extracted so far "+
+				"(default: no limit)"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractStartTool, s.handlePDFExtractStart)
+
+	pdfExtractStatusTool := mcp.NewTool(
+		"pdf_extract_status",
+		mcp.WithDescription("Report the status and page progress of a job started with pdf_extract_start"),
+		mcp.WithString("job_id",
+			mcp.Required(),
+			mcp.Description("Job ID returned by pdf_extract_start"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractStatusTool, s.handlePDFExtractStatus)
+
+	pdfExtractResultTool := mcp.NewTool(
+		"pdf_extract_result",
+		mcp.WithDescription("Fetch the output of a finished pdf_extract_start job, optionally in pages of "+
+			"page_size elements and tables. Results are kept for an hour after the job finishes"),
+		mcp.WithString("job_id",
+			mcp.Required(),
+			mcp.Description("Job ID returned by pdf_extract_start"),
 		),
+		withPagination(),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfQueryContentTool, s.handlePDFQueryContent)
+	s.addTool(pdfExtractResultTool, s.handlePDFExtractResult)
 }
 
 // registerUtilityTools registers utility and information tools
@@ -186,8 +619,34 @@ func (s *Server) registerUtilityTools() {
 		mcp.WithString("query",
 			mcp.Description("Optional search query for fuzzy matching"),
 		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfSearchDirectoryTool, s.handlePDFSearchDirectory)
+
+	// Register PDF search content directory tool
+	pdfSearchContentDirectoryTool := mcp.NewTool(
+		"pdf_search_content_directory",
+		mcp.WithDescription("Search the text of every PDF in a directory, returning per-file match counts, "+
+			"page numbers, and snippets around the matches"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("Text to search for"),
+		),
+		mcp.WithString("directory",
+			mcp.Description("Directory path to search (uses default if empty)"),
+		),
+		mcp.WithBoolean("case_sensitive",
+			mcp.Description("Match letter case exactly (default: false)"),
+		),
+		mcp.WithNumber("max_results",
+			mcp.Description("Maximum number of snippets returned across all files (default: 100)"),
+		),
+		mcp.WithNumber("max_concurrency",
+			mcp.Description("Number of files searched at once (default: 4, max: 16)"),
+		),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfSearchDirectoryTool, s.handlePDFSearchDirectory)
+	s.addTool(pdfSearchContentDirectoryTool, s.handlePDFSearchContentDirectory)
 
 	// Register PDF stats directory tool
 	pdfStatsDirectoryTool := mcp.NewTool(
@@ -196,15 +655,60 @@ func (s *Server) registerUtilityTools() {
 		mcp.WithString("directory",
 			mcp.Description("Directory path to analyze (uses default if empty)"),
 		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfStatsDirectoryTool, s.handlePDFStatsDirectory)
+
+	// Register PDF generate thumbnails tool
+	pdfGenerateThumbnailsTool := mcp.NewTool(
+		"pdf_generate_thumbnails",
+		mcp.WithDescription("Make small PNG previews of the first page, or chosen pages, of every PDF in a "+
+			"directory, saved beside the files or in a cache directory, for visual file pickers. Thumbnails "+
+			"newer than their document are reused. Requires pdftoppm (poppler-utils) or mutool (mupdf-tools)"),
+		mcp.WithString("directory",
+			mcp.Description("Directory path to scan (uses default if empty)"),
+		),
+		withPages(),
+		mcp.WithNumber("max_size",
+			mcp.Description("Longest side of each thumbnail in pixels, 32-1024 (default: 256)"),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Cache directory to save thumbnails in, mirroring the scanned directory "+
+				"(default: beside each PDF)"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Render thumbnails again even when a saved one is newer than its PDF (default: false)"),
+		),
+		mcp.WithBoolean("include_data",
+			mcp.Description("Also return the thumbnails as images (default: false)"),
+		),
+		mcp.WithNumber("max_concurrency",
+			mcp.Description("Number of files rendered at once (default: 4, max: 16)"),
+		),
+		withTimeout(),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfStatsDirectoryTool, s.handlePDFStatsDirectory)
+	s.addTool(pdfGenerateThumbnailsTool, s.handlePDFGenerateThumbnails)
 
 	// Register PDF server info tool
 	pdfServerInfoTool := mcp.NewTool(
 		"pdf_server_info",
 		mcp.WithDescription("Get server information, available tools, directory contents, and usage guidance"),
+		withResponseFormat(),
+	)
+	s.addTool(pdfServerInfoTool, s.handlePDFServerInfo)
+
+	// Register PDF health tool
+	pdfHealthTool := mcp.NewTool(
+		"pdf_health",
+		mcp.WithDescription("Check the server's health for deployment probes: that the PDF directories are "+
+			"readable, temporary space is writable, OCR is available, the memory budget is not exhausted, and a "+
+			"synthetic one-page document extracts correctly. Reports pass or fail for each subsystem; the "+
+			"status is fail when any required check fails."),
+		withTimeout(),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfServerInfoTool, s.handlePDFServerInfo)
+	s.addTool(pdfHealthTool, s.handlePDFHealth)
 
 	// Register PDF get page info tool
 	pdfGetPageInfoTool := mcp.NewTool(
@@ -214,8 +718,9 @@ func (s *Server) registerUtilityTools() {
 			mcp.Required(),
 			mcp.Description("Full path to the PDF file"),
 		),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfGetPageInfoTool, s.handlePDFGetPageInfo)
+	s.addTool(pdfGetPageInfoTool, s.handlePDFGetPageInfo)
 
 	// Register PDF get metadata tool
 	pdfGetMetadataTool := mcp.NewTool(
@@ -225,710 +730,4564 @@ func (s *Server) registerUtilityTools() {
 			mcp.Required(),
 			mcp.Description("Full path to the PDF file"),
 		),
+		withResponseFormat(),
 	)
-	s.mcpServer.AddTool(pdfGetMetadataTool, s.handlePDFGetMetadata)
-}
-
-// Handler functions
-func (s *Server) handlePDFReadFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	path, err := request.RequireString("path")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
-
-	req := pdf.PDFReadFileRequest{Path: path}
-	result, err := s.pdfService.PDFReadFile(req)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
-
-	responseText := fmt.Sprintf("Successfully read PDF: %s\n", result.Path)
-	responseText += fmt.Sprintf("Pages: %d\n", result.Pages)
-	responseText += fmt.Sprintf("Size: %d bytes\n", result.Size)
-	responseText += fmt.Sprintf("Content Type: %s\n", result.ContentType)
-	responseText += fmt.Sprintf("Has Images: %t\n", result.HasImages)
-	if result.HasImages {
-		responseText += fmt.Sprintf("Image Count: %d\n", result.ImageCount)
-	}
-
-	// Add guidance based on content type
-	switch result.ContentType {
-	case "scanned_images":
-		responseText += "\n🔍 RECOMMENDATION: This PDF appears to contain scanned images with little or no " +
-			"extractable text. Consider using 'pdf_assets_file' to extract the images.\n"
-	case "mixed":
-		responseText += "\n💡 INFO: This PDF contains both text and images. You may want to use " +
-			"'pdf_assets_file' to extract the images as well.\n"
-	case "no_content":
-		responseText += "\n⚠️  WARNING: This PDF appears to have no readable content or images.\n"
-	}
-
-	responseText += "\nContent:\n"
-	responseText += result.Content
+	s.addTool(pdfGetMetadataTool, s.handlePDFGetMetadata)
+
+	// Register PDF get permissions tool
+	pdfGetPermissionsTool := mcp.NewTool(
+		"pdf_get_permissions",
+		mcp.WithDescription("Report the encryption filter, algorithm, and key length of a PDF, the permissions it "+
+			"grants (print, copy, modify, annotate, fill forms, accessibility extraction), and whether the "+
+			"server can extract its text under them"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfGetPermissionsTool, s.handlePDFGetPermissions)
 
-	return mcp.NewToolResultText(responseText), nil
-}
+	// Register PDF fingerprint tool
+	pdfFingerprintTool := mcp.NewTool(
+		"pdf_fingerprint",
+		mcp.WithDescription("Compute a layout fingerprint (text density grid and anchor positions) for a PDF file"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfFingerprintTool, s.handlePDFFingerprint)
 
-func (s *Server) handlePDFAssetsFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	path, err := request.RequireString("path")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
+	// Register PDF match template tool
+	pdfMatchTemplateTool := mcp.NewTool(
+		"pdf_match_template",
+		mcp.WithDescription("Identify which known template or form a PDF corresponds to by comparing page layouts"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithArray("templates",
+			mcp.Required(),
+			mcp.Description("Template PDF files or directories containing template PDFs"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("min_score",
+			mcp.Description("Minimum similarity score (0-1) required for a match (default: 0.75)"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfMatchTemplateTool, s.handlePDFMatchTemplate)
 
-	req := pdf.PDFAssetsFileRequest{Path: path}
-	result, err := s.pdfService.PDFAssetsFile(req)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
+	// Register PDF get outline tool
+	pdfGetOutlineTool := mcp.NewTool(
+		"pdf_get_outline",
+		mcp.WithDescription("Get the document outline (bookmarks) with titles, destination pages, and zoom levels"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfGetOutlineTool, s.handlePDFGetOutline)
 
-	responseText := s.formatPDFAssetsFileResult(result)
-	return mcp.NewToolResultText(responseText), nil
-}
+	// Register PDF extract section tool
+	pdfExtractSectionTool := mcp.NewTool(
+		"pdf_extract_section",
+		mcp.WithDescription("Extract the text of one section, appendix, exhibit, schedule, or annex, "+
+			"addressed by name (e.g. \"Exhibit A\", \"Annex 3\") or by outline title"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithString("section",
+			mcp.Required(),
+			mcp.Description("Appendix name (\"Exhibit A\"), appendix label (\"A\"), or outline title"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractSectionTool, s.handlePDFExtractSection)
+
+	// Register PDF get clauses tool
+	pdfGetClausesTool := mcp.NewTool(
+		"pdf_get_clauses",
+		mcp.WithDescription("Get the clause tree of a contract or other legal document from its numbering: "+
+			"articles (Article IV), sections (Section 4.1), paragraphs (§12(a)), decimal clauses (1.1.2), "+
+			"and lettered, Roman, or numbered subdivisions ((a), (iv), (1)). Each clause has its number, a "+
+			"stable anchor derived from the number, its title, and the pages it spans. Pass a clause number "+
+			"or anchor as the clause of a pdf_query_content query to search within it"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithBoolean("include_text",
+			mcp.Description("Include the text of each clause (default: false)"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfGetClausesTool, s.handlePDFGetClauses)
 
-func (s *Server) handlePDFValidateFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	path, err := request.RequireString("path")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
+	// PDF extract attachments tool
+	pdfExtractAttachmentsTool := mcp.NewTool(
+		"pdf_extract_attachments",
+		mcp.WithDescription("List files embedded in a PDF (EmbeddedFiles and file attachment annotations) "+
+			"with names, MIME types, and sizes; optionally save them to disk or return them base64-encoded"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithArray("names",
+			mcp.Description("Only return attachments with these names (default: all)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Directory to save the attachment payloads into"),
+		),
+		mcp.WithBoolean("include_content",
+			mcp.Description("Return attachment payloads base64-encoded (default: false)"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractAttachmentsTool, s.handlePDFExtractAttachments)
+
+	// PDF portfolio tool
+	pdfPortfolioTool := mcp.NewTool(
+		"pdf_portfolio",
+		mcp.WithDescription("List the documents of a PDF portfolio (a /Collection of embedded files behind a "+
+			"cover sheet) with their schema fields, and optionally read the text of each PDF member, "+
+			"recursing into nested portfolios. Every PDF member gets a path of the form "+
+			"<portfolio>#member=<name> that any tool accepts"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF portfolio"),
+		),
+		mcp.WithArray("members",
+			mcp.Description("Only these members, by name (default: all)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("include_text",
+			mcp.Description("Read the text of each PDF member (default: false)"),
+		),
+		mcp.WithNumber("max_chars",
+			mcp.Description("Characters of text kept per member (default: 4000)"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfPortfolioTool, s.handlePDFPortfolio)
 
-	req := pdf.PDFValidateFileRequest{Path: path}
-	result, err := s.pdfService.PDFValidateFile(req)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
+	// PDF extract links tool
+	pdfExtractLinksTool := mcp.NewTool(
+		"pdf_extract_links",
+		mcp.WithDescription("Extract hyperlinks and internal cross-references with their page areas, "+
+			"resolving URI, GoTo, and GoToR actions and destination page numbers"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		withPages(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractLinksTool, s.handlePDFExtractLinks)
 
-	var responseText string
-	if result.Valid {
-		responseText = fmt.Sprintf("PDF file %s is valid and readable", result.Path)
-	} else {
-		responseText = fmt.Sprintf("PDF validation failed for %s: %s", result.Path, result.Message)
-	}
+	// PDF find text tool
+	pdfFindTextTool := mcp.NewTool(
+		"pdf_find_text",
+		mcp.WithDescription("Find every occurrence of a phrase with its page number and bounding box in PDF points "+
+			"(origin at the bottom-left of the page), for highlighting or matching against form fields and tables"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("Phrase to find; spaces match any spacing between words, including line breaks"),
+		),
+		mcp.WithBoolean("case_sensitive",
+			mcp.Description("Match the case of the phrase exactly (default: false)"),
+		),
+		mcp.WithBoolean("whole_word",
+			mcp.Description("Only match the phrase at word boundaries (default: false)"),
+		),
+		withPages(),
+		withRegion(),
+		mcp.WithNumber("max_results",
+			mcp.Description("Maximum number of occurrences to return (default: 500)"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfFindTextTool, s.handlePDFFindText)
+
+	// PDF extract entities tool
+	pdfExtractEntitiesTool := mcp.NewTool(
+		"pdf_extract_entities",
+		mcp.WithDescription("Find dates, currency amounts, email addresses, phone numbers, street addresses, "+
+			"invoice numbers, and Social Security numbers in a PDF's text, with normalized values, page numbers, "+
+			"bounding boxes in PDF points, and confidence"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithArray("types",
+			mcp.Description("Entity types to find (default: all)"),
+			mcp.Items(map[string]any{"type": "string", "enum": []string{
+				pdf.EntityDate, pdf.EntityAmount, pdf.EntityEmail, pdf.EntityPhone,
+				pdf.EntityAddress, pdf.EntityInvoiceNumber, pdf.EntitySSN,
+			}}),
+		),
+		withPages(),
+		withRegion(),
+		mcp.WithNumber("min_confidence",
+			mcp.Description("Only return entities with at least this confidence, from 0 to 1 (default: 0)"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractEntitiesTool, s.handlePDFExtractEntities)
+
+	// PDF classify document tool
+	pdfClassifyDocumentTool := mcp.NewTool(
+		"pdf_classify_document",
+		mcp.WithDescription("Rank the document types a PDF may be, such as invoice, contract, resume, or "+
+			"academic paper, by the keywords and patterns found in the text of its first pages. Returns the "+
+			"best candidates with scores from 0 to 1 and the signals that matched. Custom types can be added "+
+			"with the --classifier-profiles file"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithNumber("top_n",
+			mcp.Description("Number of candidate types to return (default: 3)"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfClassifyDocumentTool, s.handlePDFClassifyDocument)
+
+	// PDF extract invoice tool
+	pdfExtractInvoiceTool := mcp.NewTool(
+		"pdf_extract_invoice",
+		mcp.WithDescription("Read the fields of an invoice or receipt: vendor, invoice number, invoice and "+
+			"due dates, currency, line items with quantities, unit prices, and amounts, and the subtotal, tax, "+
+			"total, and amount due. The document is classified first, as by pdf_classify_document, and fields "+
+			"are read only when it is an invoice or receipt unless force is set. Totals that do not add up are "+
+			"reported as warnings"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Read the fields even when the document is not classified as an invoice or receipt"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractInvoiceTool, s.handlePDFExtractInvoice)
+
+	// PDF extract transactions tool
+	pdfExtractTransactionsTool := mcp.NewTool(
+		"pdf_extract_transactions",
+		mcp.WithDescription("Read the transactions of a bank statement as date, description, signed amount, "+
+			"and balance, from its tables with date and amount, or debit and credit, columns. Descriptions "+
+			"running over several lines are joined, tables continuing on later pages without headers are "+
+			"followed, and the opening and closing balances and totals are reported. The document is "+
+			"classified first, as by pdf_classify_document, and read only when it is a bank statement unless "+
+			"force is set. Balances that do not follow from the amounts are reported as warnings"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Read the transactions even when the document is not classified as a bank statement"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractTransactionsTool, s.handlePDFExtractTransactions)
+
+	// PDF extract resume tool
+	pdfExtractResumeTool := mcp.NewTool(
+		"pdf_extract_resume",
+		mcp.WithDescription("Read a resume or CV as a standard schema: contact details (name, emails, phones, "+
+			"address, links), summary, experience entries (title, company, location, start and end dates, "+
+			"highlights), education entries (institution, degree, dates), skills, and any other sections by "+
+			"heading. Sections are found at their headings; dates are normalized as YYYY-MM or YYYY. The "+
+			"document is classified first, as by pdf_classify_document, and read only when it is a resume "+
+			"unless force is set"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Read the sections even when the document is not classified as a resume"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExtractResumeTool, s.handlePDFExtractResume)
+
+	// PDF redact tool
+	pdfRedactTool := mcp.NewTool(
+		"pdf_redact",
+		mcp.WithDescription("Produce a copy of a PDF with regions and every occurrence of search terms removed: "+
+			"text and images under them are deleted from the page content, not just covered, and the areas are "+
+			"painted black. Existing redaction annotations are applied as well"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithArray("regions",
+			mcp.Description("Areas to redact as objects {page, x, y, width, height} in PDF points "+
+				"(origin at the bottom-left of the page)"),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+		mcp.WithArray("terms",
+			mcp.Description("Phrases to redact wherever they occur; spaces match any spacing between words"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("case_sensitive",
+			mcp.Description("Match the case of the terms exactly (default: false)"),
+		),
+		mcp.WithBoolean("whole_word",
+			mcp.Description("Only match the terms at word boundaries (default: false)"),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Save the redacted document to this directory instead of returning it"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfRedactTool, s.handlePDFRedact)
+
+	// PDF annotate tool
+	pdfAnnotateTool := mcp.NewTool(
+		"pdf_annotate",
+		mcp.WithDescription("Produce a copy of a PDF with highlight, sticky note, and rectangle annotations "+
+			"added at given coordinates or around every occurrence of a phrase, for marking up findings. "+
+			"Existing annotations are kept"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithArray("annotations",
+			mcp.Required(),
+			mcp.Description("Annotations as objects {type, text, page, x, y, width, height, comment, color, author}: "+
+				"type is highlight, note, or rectangle; give text to annotate every occurrence of a phrase "+
+				"(on page only, when given), or a page and region in PDF points (origin at the bottom-left "+
+				"of the page); color is hex such as #ffcc00"),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+		mcp.WithBoolean("case_sensitive",
+			mcp.Description("Match the case of phrases exactly (default: false)"),
+		),
+		mcp.WithBoolean("whole_word",
+			mcp.Description("Only match phrases at word boundaries (default: false)"),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Save the annotated document to this directory instead of returning it"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfAnnotateTool, s.handlePDFAnnotate)
+
+	// PDF accessibility report tool
+	pdfAccessibilityReportTool := mcp.NewTool(
+		"pdf_accessibility_report",
+		mcp.WithDescription("Audit a PDF for accessibility against WCAG and PDF/UA: tagging and content drawn "+
+			"outside tags, the document language and title, figures without alternate text, tagged reading "+
+			"order that differs from the visual order, form fields without labels, and optionally the contrast "+
+			"of text with its background, sampled from rendered pages"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		withPages(),
+		mcp.WithBoolean("check_contrast",
+			mcp.Description("Render the pages to flag text under the WCAG contrast minimum; needs pdftoppm or "+
+				"mutool (default: false)"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfAccessibilityReportTool, s.handlePDFAccessibilityReport)
+
+	// PDF optimize report tool
+	pdfOptimizeReportTool := mcp.NewTool(
+		"pdf_optimize_report",
+		mcp.WithDescription("Report how a PDF is stored and what optimizing it would save: whether it is "+
+			"linearized for fast web view, its object stream usage, unused objects, duplicate image streams, "+
+			"and the estimated size after pdf_optimize"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfOptimizeReportTool, s.handlePDFOptimizeReport)
+
+	// PDF optimize tool
+	pdfOptimizeTool := mcp.NewTool(
+		"pdf_optimize",
+		mcp.WithDescription("Produce a smaller copy of a PDF: unused objects are dropped, repeated images are "+
+			"stored once, streams are compressed, and objects are packed into object streams. Images can "+
+			"optionally be downsampled. The copy is not linearized"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithNumber("max_image_dpi",
+			mcp.Description("Downsample images drawn above this resolution to it, at least 36 "+
+				"(default: images are kept as they are)"),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Save the optimized document to this directory instead of returning it"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfOptimizeTool, s.handlePDFOptimize)
+
+	// PDF sanitize tool
+	pdfSanitizeTool := mcp.NewTool(
+		"pdf_sanitize",
+		mcp.WithDescription("Write a copy of a PDF that is safe to share outside an organization: authoring "+
+			"metadata (author, creator, producer, dates, custom properties, XMP metadata and its edit history) "+
+			"is removed, JavaScript and launch actions are stripped, embedded files and file attachments are "+
+			"dropped, and content in layers hidden by default is deleted. Title, subject, and keywords are kept"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithString("author",
+			mcp.Description("Author to record in place of the original, such as a department name (default: none)"),
+		),
+		mcp.WithBoolean("keep_metadata",
+			mcp.Description("Keep the Info dictionary and XMP metadata (default: false)"),
+		),
+		mcp.WithBoolean("keep_javascript",
+			mcp.Description("Keep JavaScript and launch actions (default: false)"),
+		),
+		mcp.WithBoolean("keep_attachments",
+			mcp.Description("Keep embedded files and file attachment annotations (default: false)"),
+		),
+		mcp.WithBoolean("keep_hidden_layers",
+			mcp.Description("Keep the content of layers hidden by default (default: false)"),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Save the sanitized document to this directory instead of returning it"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfSanitizeTool, s.handlePDFSanitize)
+
+	// PDF security scan tool
+	pdfSecurityScanTool := mcp.NewTool(
+		"pdf_security_scan",
+		mcp.WithDescription("Scan a PDF for active content before processing it: JavaScript at the document, "+
+			"page, annotation, and field levels, open and additional actions that launch programs, embedded "+
+			"executables, URI actions to suspicious addresses, and XFA scripts. Returns each finding and a risk "+
+			"level (none, low, medium, high); refuse high-risk files from untrusted sources. Nothing is run"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfSecurityScanTool, s.handlePDFSecurityScan)
+
+	// PDF fetch URL tool
+	pdfFetchURLTool := mcp.NewTool(
+		"pdf_fetch_url",
+		mcp.WithDescription("Download a PDF over HTTPS into the server's staging directory and return a local "+
+			"path that every other tool accepts. Downloads are limited to the maximum file size and the request "+
+			"timeout, must be served as a PDF or binary data, and cannot reach addresses on the local network. "+
+			"Fetching a URL again replaces its earlier download; consider pdf_security_scan before reading "+
+			"documents from untrusted sites"),
+		mcp.WithString("url",
+			mcp.Required(),
+			mcp.Description("https URL of the PDF"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfFetchURLTool, s.handlePDFFetchURL)
+
+	// PDF inspect object tool
+	pdfInspectObjectTool := mcp.NewTool(
+		"pdf_inspect_object",
+		mcp.WithDescription("Inspect one indirect object of a PDF by number and generation for debugging "+
+			"malformed files: its location, kind, type, and dictionary as JSON with references left unresolved, "+
+			"and for streams their filters, lengths, and the start of their data"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithNumber("object",
+			mcp.Required(),
+			mcp.Description("Object number, as in \"12 0 R\""),
+		),
+		mcp.WithNumber("generation",
+			mcp.Description("Generation number (default: 0)"),
+		),
+		mcp.WithNumber("max_bytes",
+			mcp.Description("Stream data to include, up to 65536 bytes (default: 1024)"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfInspectObjectTool, s.handlePDFInspectObject)
+
+	// PDF export form data tool
+	pdfExportFormDataTool := mcp.NewTool(
+		"pdf_export_form_data",
+		mcp.WithDescription("List the AcroForm fields of a PDF with their types, values, and options, and "+
+			"export the values as JSON, FDF, or XFDF form data for use with other form tools"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Form data format (default: json)"),
+			mcp.Enum(pdf.FormDataJSON, pdf.FormDataFDF, pdf.FormDataXFDF),
+		),
+		mcp.WithString("output_path",
+			mcp.Description("Save the form data to this file instead of returning it"),
+		),
+		mcp.WithBoolean("terminal_only",
+			mcp.Description("List only the fields holding values, leaving out the groups that qualify their "+
+				"names, such as topmostSubform[0] of an XFA-converted form (default: false)"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExportFormDataTool, s.handlePDFExportFormData)
 
-	return mcp.NewToolResultText(responseText), nil
+	// PDF export comments tool
+	pdfExportCommentsTool := mcp.NewTool(
+		"pdf_export_comments",
+		mcp.WithDescription("Export the review comments and markup of a PDF as threads sorted by date, each with "+
+			"its author, date, page, the text it marks, and its chain of replies, as JSON or Markdown"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Export format (default: json)"),
+			mcp.Enum(pdf.CommentsJSON, pdf.CommentsMarkdown),
+		),
+		withPages(),
+		mcp.WithString("output_path",
+			mcp.Description("Save the comments to this file instead of returning them"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfExportCommentsTool, s.handlePDFExportComments)
+
+	// PDF import form data tool
+	pdfImportFormDataTool := mcp.NewTool(
+		"pdf_import_form_data",
+		mcp.WithDescription("Produce a copy of a PDF with its AcroForm fields filled from JSON, FDF, or XFDF "+
+			"form data. Values are matched to fields by fully qualified name; checkboxes take their on "+
+			"state or true/false, and choice fields one of their options by export value or displayed text"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Full path to the PDF file"),
+		),
+		mcp.WithString("data",
+			mcp.Description("Form data: a JSON object of field names and values, or FDF or XFDF content"),
+		),
+		mcp.WithString("data_path",
+			mcp.Description("Path to a form data file, instead of data"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Form data format (default: from the data_path extension or the content)"),
+			mcp.Enum(pdf.FormDataJSON, pdf.FormDataFDF, pdf.FormDataXFDF),
+		),
+		mcp.WithString("output_dir",
+			mcp.Description("Save the filled document to this directory instead of returning it"),
+		),
+		withTimeout(),
+		withResponseFormat(),
+	)
+	s.addTool(pdfImportFormDataTool, s.handlePDFImportFormData)
+
+	// PDF compare set tool
+	pdfCompareSetTool := mcp.NewTool(
+		"pdf_compare_set",
+		mcp.WithDescription("Compare a set of PDF files pairwise by text and layout, returning a similarity "+
+			"matrix, clusters of near-identical documents, and outliers that differ from the rest"),
+		mcp.WithArray("paths",
+			mcp.Required(),
+			mcp.Description("Full paths to the PDF files to compare (2 to 20)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("threshold",
+			mcp.Description("Similarity (0-1) at which documents are grouped into a cluster (default: 0.9)"),
+		),
+		withResponseFormat(),
+	)
+	s.addTool(pdfCompareSetTool, s.handlePDFCompareSet)
 }
 
-func (s *Server) handlePDFStatsFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// Handler functions
+func (s *Server) handlePDFReadFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	path, err := request.RequireString("path")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolError(err), nil
 	}
 
-	req := pdf.PDFStatsFileRequest{Path: path}
-	result, err := s.pdfService.PDFStatsFile(req)
+	var config pdf.ExtractionConfig
+	if err := applyPageSelection(request, &config); err != nil {
+		return toolError(err), nil
+	}
+	req := pdf.PDFReadFileRequest{
+		Path:       path,
+		FirstPages: config.FirstPages,
+		LastPages:  config.LastPages,
+		Layout:     request.GetString("layout", ""),
+		Pages:      config.Pages,
+		Region:     config.Region,
+
+		StripHeadersFooters: request.GetBool("strip_headers_footers", false),
+	}
+	result, err := s.pdfService.PDFReadFile(req)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolError(err), nil
 	}
 
-	responseText := s.formatPDFStatsFileResult(result)
-	return mcp.NewToolResultText(responseText), nil
+	responseText := fmt.Sprintf("Successfully read PDF: %s\n", result.Path)
+	responseText += fmt.Sprintf("Pages: %d\n", result.Pages)
+	if result.PageSelection != nil {
+		responseText += fmt.Sprintf("Pages Read: %s\n", formatPageSelection(result.PageSelection))
+	}
+	if result.Region != nil {
+		responseText += fmt.Sprintf("Region: %s\n", formatRegion(*result.Region))
+	}
+	if len(result.RepeatedLines) > 0 {
+		responseText += fmt.Sprintf("Headers, Footers, and Watermarks Removed: %d lines\n", len(result.RepeatedLines))
+	}
+	responseText += fmt.Sprintf("Size: %d bytes\n", result.Size)
+	if result.Layout != "" {
+		responseText += fmt.Sprintf("Layout: %s\n", result.Layout)
+	}
+	responseText += fmt.Sprintf("Content Type: %s\n", result.ContentType)
+	responseText += fmt.Sprintf("Has Images: %t\n", result.HasImages)
+	if result.HasImages {
+		responseText += fmt.Sprintf("Image Count: %d\n", result.ImageCount)
+	}
+	if result.PortfolioMembers > 0 {
+		responseText += fmt.Sprintf("\n📁 INFO: This PDF is a portfolio of %d files and the text below is its cover "+
+			"sheet. Use 'pdf_portfolio' to list and read the member documents.\n", result.PortfolioMembers)
+	}
+
+	// Add guidance based on content type
+	switch result.ContentType {
+	case "scanned_images":
+		responseText += "\n🔍 RECOMMENDATION: This PDF appears to contain scanned images with little or no " +
+			"extractable text. Consider using 'pdf_assets_file' to extract the images.\n"
+	case "mixed":
+		responseText += "\n💡 INFO: This PDF contains both text and images. You may want to use " +
+			"'pdf_assets_file' to extract the images as well.\n"
+	case "no_content":
+		responseText += "\n⚠️  WARNING: This PDF appears to have no readable content or images.\n"
+	}
+
+	responseText += formatEscalation(result.Escalation)
+
+	responseText += "\nContent:\n"
+	responseText += result.Content
+
+	return newToolResult(request, result, responseText)
 }
 
-func (s *Server) handlePDFSearchDirectory(ctx context.Context, request mcp.CallToolRequest) (
-	*mcp.CallToolResult, error,
-) {
-	args := request.GetArguments()
+func (s *Server) handlePDFAssetsFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
 
-	directory := s.config.PDFDirectory // default
-	if dir, ok := args["directory"].(string); ok && dir != "" {
-		directory = dir
+	req := pdf.PDFAssetsFileRequest{
+		Path:     path,
+		Classify: request.GetBool("classify", false),
+		MinDPI:   request.GetFloat("min_dpi", 0),
+	}
+	result, err := s.pdfService.PDFAssetsFile(req)
+	if err != nil {
+		return toolError(err), nil
 	}
 
-	query := ""
-	if q, ok := args["query"].(string); ok {
-		query = q
+	responseText := s.formatPDFAssetsFileResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFOCRRegion(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+	regions, err := parseOCRRegions(request.GetArguments()["regions"])
+	if err != nil {
+		return toolError(err), nil
 	}
 
-	req := pdf.PDFSearchDirectoryRequest{
-		Directory: directory,
-		Query:     query,
+	req := pdf.PDFOCRRegionRequest{
+		Path:     path,
+		Page:     request.GetInt("page", 0),
+		Regions:  regions,
+		Language: request.GetString("language", ""),
+		DPI:      request.GetInt("dpi", 0),
+	}
+	result, err := s.pdfService.PDFOCRRegion(req)
+	if err != nil {
+		return toolError(err), nil
 	}
 
-	result, err := s.pdfService.PDFSearchDirectory(req)
+	responseText := s.formatPDFOCRRegionResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFRenderPage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolError(err), nil
 	}
 
-	var responseText string
-	if result.TotalCount == 0 {
-		responseText = fmt.Sprintf("No PDF files found in directory: %s", result.Directory)
-		if result.SearchQuery != "" {
-			responseText += fmt.Sprintf(" (searched for: %s)", result.SearchQuery)
-		}
-	} else {
-		responseText = s.formatPDFSearchDirectoryResult(result)
+	req := pdf.PDFRenderPageRequest{
+		Path:      path,
+		Page:      request.GetInt("page", 0),
+		DPI:       request.GetInt("dpi", 0),
+		Format:    request.GetString("format", ""),
+		Quality:   request.GetInt("quality", 0),
+		OutputDir: request.GetString("output_dir", ""),
+	}
+	result, err := s.pdfService.PDFRenderPage(req)
+	if err != nil {
+		return toolError(err), nil
 	}
 
-	return mcp.NewToolResultText(responseText), nil
+	responseText := s.formatPDFRenderPageResult(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError || result.Data == "" {
+		return toolResult, err
+	}
+
+	// Vision clients read the image from an image content block; JSON responses already carry it
+	if request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		toolResult.Content = append(toolResult.Content, mcp.NewImageContent(result.Data, result.MIMEType))
+	}
+	return toolResult, nil
 }
 
-func (s *Server) handlePDFStatsDirectory(ctx context.Context, request mcp.CallToolRequest) (
-	*mcp.CallToolResult, error,
-) {
-	args := request.GetArguments()
+func (s *Server) handlePDFExportText(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
 
-	directory := s.config.PDFDirectory // default
-	if dir, ok := args["directory"].(string); ok && dir != "" {
-		directory = dir
+	pages, err := parsePages(request.GetArguments()["pages"])
+	if err != nil {
+		return toolError(err), nil
+	}
+	structure, err := parseStructureConfig(request.GetArguments()["structure_config"])
+	if err != nil {
+		return toolError(err), nil
 	}
 
-	req := pdf.PDFStatsDirectoryRequest{Directory: directory}
-	result, err := s.pdfService.PDFStatsDirectory(req)
+	req := pdf.PDFExportTextRequest{
+		Path:                path,
+		Format:              request.GetString("format", ""),
+		Pages:               pages,
+		StripHeadersFooters: request.GetBool("strip_headers_footers", false),
+		Dehyphenate:         request.GetBool("dehyphenate", false),
+		OutputDir:           request.GetString("output_dir", ""),
+		StructureConfig:     structure,
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFExportText(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExportTextResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractReadingOrder(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	pages, err := parsePages(request.GetArguments()["pages"])
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolError(err), nil
+	}
+	structure, err := parseStructureConfig(request.GetArguments()["structure_config"])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExtractReadingOrderRequest{
+		Path:                path,
+		Pages:               pages,
+		StripHeadersFooters: request.GetBool("strip_headers_footers", false),
+		StructureConfig:     structure,
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFExtractReadingOrder(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractReadingOrderResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFChunkContent(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	pages, err := parsePages(request.GetArguments()["pages"])
+	if err != nil {
+		return toolError(err), nil
+	}
+	structure, err := parseStructureConfig(request.GetArguments()["structure_config"])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFChunkContentRequest{
+		Path:            path,
+		Pages:           pages,
+		MaxTokens:       request.GetInt("max_tokens", 0),
+		MaxCharacters:   request.GetInt("max_characters", 0),
+		Overlap:         request.GetInt("overlap", 0),
+		StructureConfig: structure,
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFChunkContent(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFChunkContentResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExportDocument(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	structure, err := parseStructureConfig(request.GetArguments()["structure_config"])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExportDocumentRequest{
+		Path:            path,
+		Format:          request.GetString("format", ""),
+		OutputDir:       request.GetString("output_dir", ""),
+		StructureConfig: structure,
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFExportDocument(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExportDocumentResult(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError || result.Data == "" {
+		return toolResult, err
+	}
+
+	// Return the document as an embedded resource; JSON responses already carry it
+	if request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		stem := strings.TrimSuffix(filepath.Base(result.Path), filepath.Ext(result.Path))
+		toolResult.Content = append(toolResult.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      stem + "." + result.Format,
+			MIMEType: result.MIMEType,
+			Blob:     result.Data,
+		}))
+	}
+	return toolResult, nil
+}
+
+func (s *Server) handlePDFExportBook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExportBookRequest{
+		Path:      path,
+		Format:    request.GetString("format", ""),
+		OutputDir: request.GetString("output_dir", ""),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFExportBook(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExportBookResult(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError || result.Data == "" {
+		return toolResult, err
+	}
+
+	// Return the archive as an embedded resource; JSON responses already carry it
+	if request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		name := strings.TrimSuffix(filepath.Base(result.Path), filepath.Ext(result.Path)) + "." + result.Format
+		if result.Format == pdf.ExportFormatHTML {
+			name += ".zip"
+		}
+		toolResult.Content = append(toolResult.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      name,
+			MIMEType: result.MIMEType,
+			Blob:     result.Data,
+		}))
+	}
+	return toolResult, nil
+}
+
+func (s *Server) handlePDFLoadBytes(
+	_ context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	content, err := request.RequireString("content")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	result, err := s.pdfService.LoadBytes(pdf.PDFLoadBytesRequest{
+		Content: content,
+		Name:    request.GetString("name", ""),
+	})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatLoadedDocument(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFListOutputs(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	result, err := s.workspace.list(ctx)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatWorkspaceListing(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFGetOutput(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	// The path was resolved to the session's workspace before the handler runs
+	file, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+	dir, err := s.workspace.sessionDirectory(ctx, true)
+	if err != nil {
+		return toolError(err), nil
+	}
+	rel, err := filepath.Rel(dir, file)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return mcp.NewToolResultError("path must name a file in the workspace, such as workspace:report.csv"), nil
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("no such output: %s", workspacePrefix+filepath.ToSlash(rel))), nil
+	}
+	if info.IsDir() {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is a directory; list its files with pdf_list_outputs",
+			workspacePrefix+filepath.ToSlash(rel))), nil
+	}
+	if s.config.MaxFileSize > 0 && info.Size() > s.config.MaxFileSize {
+		return mcp.NewToolResultError(fmt.Sprintf("output too large: %d bytes (max: %d bytes)",
+			info.Size(), s.config.MaxFileSize)), nil
+	}
+	data, err := os.ReadFile(file) //nolint:gosec // The path is confined to the session's workspace
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read output: %v", err)), nil
+	}
+
+	result := &WorkspaceOutput{WorkspaceFile: describeWorkspaceFile(workspacePrefix+filepath.ToSlash(rel), file, info)}
+	mediaType, _, _ := mime.ParseMediaType(result.MIMEType)
+	if strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" || mediaType == "application/xml" {
+		result.Text = string(data)
+	} else {
+		result.Data = base64.StdEncoding.EncodeToString(data)
+	}
+
+	responseText := s.formatWorkspaceOutput(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError {
+		return toolResult, err
+	}
+
+	// Vision clients read images from image content blocks; JSON responses already carry them
+	if strings.HasPrefix(mediaType, "image/") &&
+		request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		toolResult.Content = append(toolResult.Content, mcp.NewImageContent(result.Data, mediaType))
+	}
+	return toolResult, nil
+}
+
+func (s *Server) handlePDFValidateFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFValidateFileRequest{Path: path}
+	result, err := s.pdfService.PDFValidateFile(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	var responseText string
+	if result.Valid {
+		responseText = fmt.Sprintf("PDF file %s is valid and readable", result.Path)
+	} else {
+		responseText = fmt.Sprintf("PDF validation failed for %s: %s", result.Path, result.Message)
+	}
+
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFStatsFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFStatsFileRequest{Path: path}
+	result, err := s.pdfService.PDFStatsFile(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFStatsFileResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFSearchDirectory(ctx context.Context, request mcp.CallToolRequest) (
+	*mcp.CallToolResult, error,
+) {
+	args := request.GetArguments()
+
+	directory := s.config.PDFDirectory // default
+	if dir, ok := args["directory"].(string); ok && dir != "" {
+		directory = dir
+	}
+
+	query := ""
+	if q, ok := args["query"].(string); ok {
+		query = q
+	}
+
+	req := pdf.PDFSearchDirectoryRequest{
+		Directory: directory,
+		Query:     query,
+	}
+
+	result, err := s.pdfService.PDFSearchDirectory(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	var responseText string
+	if result.TotalCount == 0 {
+		responseText = fmt.Sprintf("No PDF files found in directory: %s", result.Directory)
+		if result.SearchQuery != "" {
+			responseText += fmt.Sprintf(" (searched for: %s)", result.SearchQuery)
+		}
+	} else {
+		responseText = s.formatPDFSearchDirectoryResult(result)
+	}
+
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFSearchContentDirectory(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	query, err := request.RequireString("query")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	directory := request.GetString("directory", "")
+	if directory == "" {
+		directory = s.config.PDFDirectory
+	}
+
+	req := pdf.PDFSearchContentRequest{
+		Directory:      directory,
+		Query:          query,
+		CaseSensitive:  request.GetBool("case_sensitive", false),
+		MaxResults:     request.GetInt("max_results", 0),
+		MaxConcurrency: request.GetInt("max_concurrency", 0),
+	}
+	result, err := s.pdfService.PDFSearchContentDirectory(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFSearchContentResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFStatsDirectory(ctx context.Context, request mcp.CallToolRequest) (
+	*mcp.CallToolResult, error,
+) {
+	args := request.GetArguments()
+
+	directory := s.config.PDFDirectory // default
+	if dir, ok := args["directory"].(string); ok && dir != "" {
+		directory = dir
+	}
+
+	req := pdf.PDFStatsDirectoryRequest{Directory: directory}
+	result, err := s.pdfService.PDFStatsDirectory(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFStatsDirectoryResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFGenerateThumbnails(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	pages, err := parsePages(request.GetArguments()["pages"])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFGenerateThumbnailsRequest{
+		Directory:      request.GetString("directory", ""),
+		Pages:          pages,
+		MaxSize:        request.GetInt("max_size", 0),
+		OutputDir:      request.GetString("output_dir", ""),
+		Overwrite:      request.GetBool("overwrite", false),
+		IncludeData:    request.GetBool("include_data", false),
+		MaxConcurrency: request.GetInt("max_concurrency", 0),
+	}
+	if req.Directory == "" {
+		req.Directory = s.config.PDFDirectory
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFGenerateThumbnails(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFGenerateThumbnailsResult(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError {
+		return toolResult, err
+	}
+
+	// Vision clients read the images from image content blocks; JSON responses already carry them
+	if request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		for _, thumbnail := range result.Thumbnails {
+			if thumbnail.Data != "" {
+				toolResult.Content = append(toolResult.Content, mcp.NewImageContent(thumbnail.Data, "image/png"))
+			}
+		}
+	}
+	return toolResult, nil
+}
+
+func (s *Server) handlePDFServerInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	req := pdf.PDFServerInfoRequest{}
+	result, err := s.pdfService.PDFServerInfo(req, s.config.ServerName, s.config.Version, s.config.PDFDirectory)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	// Describe only the tools this server registered
+	available := result.AvailableTools[:0]
+	for _, tool := range result.AvailableTools {
+		if slices.Contains(s.enabledTools, tool.Name) {
+			available = append(available, tool)
+		}
+	}
+	result.AvailableTools = available
+	result.EnabledTools = s.enabledTools
+	result.DisabledTools = s.withheldTools
+	result.ReadOnly = s.config.ReadOnly
+	result.Roots = s.config.Directories()
+	result.Metrics = s.pdfService.MetricsSummary()
+
+	responseText := s.formatPDFServerInfoResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFHealth(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFHealth(ctx, pdf.PDFHealthRequest{Directories: s.config.Directories()})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFHealthResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+// New structured extraction handlers
+
+func (s *Server) handlePDFExtractStructured(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	args := request.GetArguments()
+
+	req := pdf.PDFExtractStructuredRequest{
+		Path: path,
+	}
+
+	// Handle optional mode parameter
+	if mode, ok := args["mode"].(string); ok {
+		req.Mode = mode
+	}
+
+	// Handle optional config parameter
+	if hasArgument(args, "config") {
+		req.Config, err = parseExtractionConfig(args["config"], pdf.ExtractionConfig{
+			ExtractText:        true,
+			IncludeCoordinates: true,
+			IncludeFormatting:  true,
+		})
+		if err != nil {
+			return toolError(err), nil
+		}
+	}
+	if err := applyPageSelection(request, &req.Config); err != nil {
+		return toolError(err), nil
+	}
+	applyResumeToken(request, &req.Config)
+	if err := applyPagination(request, &req.Config); err != nil {
+		return toolError(err), nil
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.ExtractStructured(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractTables(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	exportFormat := request.GetString("export_format", "")
+	outputPath := request.GetString("output_path", "")
+	return s.handleExtractionRequest(ctx, request,
+		func(ctx context.Context, path string, config pdf.ExtractionConfig) (*pdf.PDFExtractResult, error) {
+			return s.pdfService.ExtractTables(ctx, pdf.PDFExtractTablesRequest{
+				Path:         path,
+				Config:       config,
+				ExportFormat: exportFormat,
+				OutputPath:   outputPath,
+			})
+		}, pdf.ExtractionConfig{
+			ExtractText:        true,
+			ExtractTables:      true,
+			IncludeCoordinates: true,
+		})
+}
+
+func (s *Server) handlePDFExtractSemantic(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	return s.handleExtractionRequest(ctx, request,
+		func(ctx context.Context, path string, config pdf.ExtractionConfig) (*pdf.PDFExtractResult, error) {
+			return s.pdfService.ExtractSemantic(ctx, pdf.PDFExtractSemanticRequest{Path: path, Config: config})
+		}, pdf.ExtractionConfig{
+			ExtractText:        true,
+			IncludeCoordinates: true,
+			IncludeFormatting:  true,
+		})
+}
+
+// handleExtractionRequest is a common handler for extraction requests
+func (s *Server) handleExtractionRequest(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	handler func(context.Context, string, pdf.ExtractionConfig) (*pdf.PDFExtractResult, error),
+	defaultConfig pdf.ExtractionConfig,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	args := request.GetArguments()
+	config := defaultConfig
+
+	// Handle optional config parameter
+	if hasArgument(args, "config") {
+		config, err = parseExtractionConfig(args["config"], defaultConfig)
+		if err != nil {
+			return toolError(err), nil
+		}
+	}
+	if err := applyPageSelection(request, &config); err != nil {
+		return toolError(err), nil
+	}
+	applyResumeToken(request, &config)
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := handler(ctx, path, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractComplete(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	args := request.GetArguments()
+
+	req := pdf.PDFExtractCompleteRequest{
+		Path: path,
+	}
+
+	// Handle optional config parameter; complete extraction always enables every content type,
+	// so only pages and min_confidence change the result
+	if hasArgument(args, "config") {
+		req.Config, err = parseExtractionConfig(args["config"], pdf.ExtractionConfig{
+			ExtractText:        true,
+			ExtractImages:      true,
+			ExtractTables:      true,
+			ExtractForms:       true,
+			ExtractAnnotations: true,
+			IncludeCoordinates: true,
+			IncludeFormatting:  true,
+		})
+		if err != nil {
+			return toolError(err), nil
+		}
+	}
+	if err := applyPageSelection(request, &req.Config); err != nil {
+		return toolError(err), nil
+	}
+	applyResumeToken(request, &req.Config)
+	if err := applyPagination(request, &req.Config); err != nil {
+		return toolError(err), nil
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.ExtractComplete(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFQueryContent(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	queryArg, ok := request.GetArguments()["query"]
+	if !ok {
+		return mcp.NewToolResultError("required argument \"query\" not found"), nil
+	}
+	query, err := parseContentQuery(queryArg)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFQueryContentRequest{
+		Path:  path,
+		Query: query,
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.QueryContent(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFQueryResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFQuerySet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	paths, err := request.RequireStringSlice("paths")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	queryArg, ok := request.GetArguments()["query"]
+	if !ok {
+		return mcp.NewToolResultError("required argument \"query\" not found"), nil
+	}
+	query, err := parseContentQuery(queryArg)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFQuerySetRequest{
+		Paths:      paths,
+		Query:      query,
+		MaxResults: request.GetInt("max_results", 0),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.QuerySet(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFQuerySetResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFBatchExtract(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	outputDir, err := request.RequireString("output_dir")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	args := request.GetArguments()
+	req := pdf.PDFBatchExtractRequest{
+		Directory:      request.GetString("directory", ""),
+		Mode:           request.GetString("mode", ""),
+		OutputDir:      outputDir,
+		MaxConcurrency: request.GetInt("max_concurrency", 0),
+	}
+	if hasArgument(args, "paths") {
+		if req.Paths, err = request.RequireStringSlice("paths"); err != nil {
+			return toolError(err), nil
+		}
+	}
+	if req.Directory == "" && len(req.Paths) == 0 {
+		req.Directory = s.config.PDFDirectory
+	}
+	if hasArgument(args, "config") {
+		req.Config, err = parseExtractionConfig(args["config"], pdf.ExtractionConfig{})
+		if err != nil {
+			return toolError(err), nil
+		}
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.BatchExtract(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFBatchExtractResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractStart(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	args := request.GetArguments()
+	req := pdf.PDFExtractStartRequest{
+		Path:    path,
+		Mode:    request.GetString("mode", ""),
+		Timeout: request.GetFloat("timeout", 0),
+	}
+	if hasArgument(args, "config") {
+		req.Config, err = parseExtractionConfig(args["config"], pdf.ExtractionConfig{})
+		if err != nil {
+			return toolError(err), nil
+		}
+	}
+	if err := applyPageSelection(request, &req.Config); err != nil {
+		return toolError(err), nil
+	}
+	applyResumeToken(request, &req.Config)
+
+	result, err := s.pdfService.StartExtraction(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatExtractionJob(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractStatus(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	jobID, err := request.RequireString("job_id")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	result, err := s.pdfService.ExtractionStatus(pdf.PDFExtractStatusRequest{JobID: jobID})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatExtractionJob(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractResult(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	jobID, err := request.RequireString("job_id")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	result, err := s.pdfService.ExtractionJobResult(pdf.PDFExtractJobResultRequest{
+		JobID:    jobID,
+		PageSize: request.GetInt("page_size", 0),
+		Cursor:   request.GetString("cursor", ""),
+	})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFGetPageInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFGetPageInfoRequest{Path: path}
+	result, err := s.pdfService.GetPageInfo(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFPageInfoResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFGetMetadata(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFGetMetadataRequest{Path: path}
+	result, err := s.pdfService.GetMetadata(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFMetadataResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFGetPermissions(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFGetPermissionsRequest{Path: path}
+	result, err := s.pdfService.PDFGetPermissions(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFGetPermissionsResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFFingerprint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFFingerprintRequest{Path: path}
+	result, err := s.pdfService.PDFFingerprint(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFFingerprintResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFMatchTemplate(ctx context.Context, request mcp.CallToolRequest) (
+	*mcp.CallToolResult, error,
+) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFMatchTemplateRequest{
+		Path:      path,
+		Templates: request.GetStringSlice("templates", nil),
+		MinScore:  request.GetFloat("min_score", 0),
+	}
+	result, err := s.pdfService.PDFMatchTemplate(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFMatchTemplateResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFGetOutline(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFGetOutlineRequest{Path: path}
+	result, err := s.pdfService.PDFGetOutline(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFOutlineResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractSection(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	section, err := request.RequireString("section")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExtractSectionRequest{Path: path, Section: section}
+	result, err := s.pdfService.PDFExtractSection(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractSectionResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFGetClauses(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFGetClausesRequest{
+		Path:        path,
+		IncludeText: request.GetBool("include_text", false),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFGetClauses(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFGetClausesResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractAttachments(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExtractAttachmentsRequest{
+		Path:           path,
+		Names:          request.GetStringSlice("names", nil),
+		OutputDir:      request.GetString("output_dir", ""),
+		IncludeContent: request.GetBool("include_content", false),
+	}
+	result, err := s.pdfService.PDFExtractAttachments(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFAttachmentsResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFPortfolio(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFPortfolioRequest{
+		Path:        path,
+		Members:     request.GetStringSlice("members", nil),
+		IncludeText: request.GetBool("include_text", false),
+		MaxChars:    request.GetInt("max_chars", 0),
+	}
+	result, err := s.pdfService.PDFPortfolio(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFPortfolioResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractLinks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	pages, err := parsePages(request.GetArguments()["pages"])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExtractLinksRequest{
+		Path:  path,
+		Pages: pages,
+	}
+	result, err := s.pdfService.PDFExtractLinks(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFLinksResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFFindText(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+	query, err := request.RequireString("query")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	pages, region, err := pageSelectionArguments(request)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFFindTextRequest{
+		Path:          path,
+		Query:         query,
+		CaseSensitive: request.GetBool("case_sensitive", false),
+		WholeWord:     request.GetBool("whole_word", false),
+		Pages:         pages,
+		MaxResults:    request.GetInt("max_results", 0),
+		Region:        region,
+	}
+	result, err := s.pdfService.PDFFindText(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFFindTextResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractEntities(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	pages, region, err := pageSelectionArguments(request)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExtractEntitiesRequest{
+		Path:          path,
+		Types:         request.GetStringSlice("types", nil),
+		Pages:         pages,
+		MinConfidence: request.GetFloat("min_confidence", 0),
+		Region:        region,
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFExtractEntities(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractEntitiesResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFClassifyDocument(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFClassifyDocumentRequest{
+		Path: path,
+		TopN: request.GetInt("top_n", 0),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFClassifyDocument(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFClassifyDocumentResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractInvoice(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExtractInvoiceRequest{
+		Path:  path,
+		Force: request.GetBool("force", false),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFExtractInvoice(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractInvoiceResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractTransactions(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExtractTransactionsRequest{
+		Path:  path,
+		Force: request.GetBool("force", false),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFExtractTransactions(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractTransactionsResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExtractResume(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExtractResumeRequest{
+		Path:  path,
+		Force: request.GetBool("force", false),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFExtractResume(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExtractResumeResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFRedact(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+	regions, err := parseRedactionRegions(request.GetArguments()["regions"])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFRedactRequest{
+		Path:          path,
+		Regions:       regions,
+		Terms:         request.GetStringSlice("terms", nil),
+		CaseSensitive: request.GetBool("case_sensitive", false),
+		WholeWord:     request.GetBool("whole_word", false),
+		OutputDir:     request.GetString("output_dir", ""),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFRedact(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFRedactResult(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError || result.Data == "" {
+		return toolResult, err
+	}
+
+	// Return the document as an embedded resource; JSON responses already carry it
+	if request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		stem := strings.TrimSuffix(filepath.Base(result.Path), filepath.Ext(result.Path))
+		toolResult.Content = append(toolResult.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      stem + "-redacted.pdf",
+			MIMEType: result.MIMEType,
+			Blob:     result.Data,
+		}))
+	}
+	return toolResult, nil
+}
+
+func (s *Server) handlePDFAccessibilityReport(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	pages, err := parsePages(request.GetArguments()["pages"])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFAccessibilityReportRequest{
+		Path:          path,
+		Pages:         pages,
+		CheckContrast: request.GetBool("check_contrast", false),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFAccessibilityReport(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFAccessibilityReportResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFOptimizeReport(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFOptimizeReport(ctx, pdf.PDFOptimizeReportRequest{Path: path})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFOptimizeReportResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFOptimize(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFOptimizeRequest{
+		Path:        path,
+		MaxImageDPI: request.GetFloat("max_image_dpi", 0),
+		OutputDir:   request.GetString("output_dir", ""),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFOptimize(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFOptimizeResult(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError || result.Data == "" {
+		return toolResult, err
+	}
+
+	// Return the document as an embedded resource; JSON responses already carry it
+	if request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		stem := strings.TrimSuffix(filepath.Base(result.Path), filepath.Ext(result.Path))
+		toolResult.Content = append(toolResult.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      stem + "-optimized.pdf",
+			MIMEType: result.MIMEType,
+			Blob:     result.Data,
+		}))
+	}
+	return toolResult, nil
+}
+
+func (s *Server) handlePDFSanitize(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFSanitizeRequest{
+		Path:             path,
+		Author:           request.GetString("author", ""),
+		KeepMetadata:     request.GetBool("keep_metadata", false),
+		KeepJavaScript:   request.GetBool("keep_javascript", false),
+		KeepAttachments:  request.GetBool("keep_attachments", false),
+		KeepHiddenLayers: request.GetBool("keep_hidden_layers", false),
+		OutputDir:        request.GetString("output_dir", ""),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFSanitize(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFSanitizeResult(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError || result.Data == "" {
+		return toolResult, err
+	}
+
+	// Return the document as an embedded resource; JSON responses already carry it
+	if request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		stem := strings.TrimSuffix(filepath.Base(result.Path), filepath.Ext(result.Path))
+		toolResult.Content = append(toolResult.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      stem + "-sanitized.pdf",
+			MIMEType: result.MIMEType,
+			Blob:     result.Data,
+		}))
+	}
+	return toolResult, nil
+}
+
+func (s *Server) handlePDFSecurityScan(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFSecurityScan(ctx, pdf.PDFSecurityScanRequest{Path: path})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFSecurityScanResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFFetchURL(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	url, err := request.RequireString("url")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFFetchURL(ctx, pdf.PDFFetchURLRequest{URL: url})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFFetchURLResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFInspectObject(
+	_ context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+	object, err := request.RequireInt("object")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	result, err := s.pdfService.PDFInspectObject(pdf.PDFInspectObjectRequest{
+		Path:       path,
+		Object:     object,
+		Generation: request.GetInt("generation", 0),
+		MaxBytes:   request.GetInt("max_bytes", 0),
+	})
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFInspectObjectResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFAnnotate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+	annotations, err := parseAnnotationSpecs(request.GetArguments()["annotations"])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFAnnotateRequest{
+		Path:          path,
+		Annotations:   annotations,
+		CaseSensitive: request.GetBool("case_sensitive", false),
+		WholeWord:     request.GetBool("whole_word", false),
+		OutputDir:     request.GetString("output_dir", ""),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFAnnotate(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFAnnotateResult(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError || result.Data == "" {
+		return toolResult, err
+	}
+
+	// Return the document as an embedded resource; JSON responses already carry it
+	if request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		stem := strings.TrimSuffix(filepath.Base(result.Path), filepath.Ext(result.Path))
+		toolResult.Content = append(toolResult.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      stem + "-annotated.pdf",
+			MIMEType: result.MIMEType,
+			Blob:     result.Data,
+		}))
+	}
+	return toolResult, nil
+}
+
+func (s *Server) handlePDFExportFormData(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExportFormDataRequest{
+		Path:         path,
+		Format:       request.GetString("format", ""),
+		OutputPath:   request.GetString("output_path", ""),
+		TerminalOnly: request.GetBool("terminal_only", false),
+	}
+	result, err := s.pdfService.PDFExportFormData(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExportFormDataResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFExportComments(
+	ctx context.Context, request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	pages, err := parsePages(request.GetArguments()["pages"])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFExportCommentsRequest{
+		Path:       path,
+		Format:     request.GetString("format", ""),
+		Pages:      pages,
+		OutputPath: request.GetString("output_path", ""),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFExportComments(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFExportCommentsResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+func (s *Server) handlePDFImportFormData(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFImportFormDataRequest{
+		Path:      path,
+		Data:      request.GetString("data", ""),
+		DataPath:  request.GetString("data_path", ""),
+		Format:    request.GetString("format", ""),
+		OutputDir: request.GetString("output_dir", ""),
+	}
+
+	ctx, cancel := s.requestContext(ctx, request)
+	defer cancel()
+
+	result, err := s.pdfService.PDFImportFormData(ctx, req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFImportFormDataResult(result)
+	toolResult, err := newToolResult(request, result, responseText)
+	if err != nil || toolResult.IsError || result.Data == "" {
+		return toolResult, err
+	}
+
+	// Return the document as an embedded resource; JSON responses already carry it
+	if request.GetString("response_format", ResponseFormatMarkdown) != ResponseFormatJSON {
+		stem := strings.TrimSuffix(filepath.Base(result.Path), filepath.Ext(result.Path))
+		toolResult.Content = append(toolResult.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      stem + "-filled.pdf",
+			MIMEType: result.MIMEType,
+			Blob:     result.Data,
+		}))
+	}
+	return toolResult, nil
+}
+
+func (s *Server) handlePDFCompareSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	paths, err := request.RequireStringSlice("paths")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	req := pdf.PDFCompareSetRequest{
+		Paths:     paths,
+		Threshold: request.GetFloat("threshold", 0),
+	}
+	result, err := s.pdfService.PDFCompareSet(req)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	responseText := s.formatPDFCompareSetResult(result)
+	return newToolResult(request, result, responseText)
+}
+
+// Formatting methods
+func (s *Server) formatPDFSearchDirectoryResult(result *pdf.PDFSearchDirectoryResult) string {
+	text := fmt.Sprintf("Found %d PDF file(s) in directory: %s\n", result.TotalCount, result.Directory)
+	if result.SearchQuery != "" {
+		text += fmt.Sprintf("Search query: %s\n", result.SearchQuery)
+	}
+	text += "\nFiles:\n"
+
+	for i, file := range result.Files {
+		text += fmt.Sprintf("%d. %s\n", i+1, file.Name)
+		text += fmt.Sprintf("   Path: %s\n", file.Path)
+		text += fmt.Sprintf("   Size: %d bytes\n", file.Size)
+		text += fmt.Sprintf("   Modified: %s\n", file.ModifiedTime)
+		if i < len(result.Files)-1 {
+			text += "\n"
+		}
+	}
+
+	return text
+}
+
+func (s *Server) formatPDFSearchContentResult(result *pdf.PDFSearchContentResult) string {
+	text := fmt.Sprintf("🔍 Content Search: %q\n", result.Query)
+	text += fmt.Sprintf("📁 Directory: %s\n", result.Directory)
+	text += fmt.Sprintf("📊 %d match(es) in %d of %d file(s)\n",
+		result.TotalMatches, result.FilesMatched, result.FilesSearched)
+	if result.FilesFailed > 0 {
+		text += fmt.Sprintf("⚠️ %d file(s) could not be searched\n", result.FilesFailed)
+	}
+
+	for _, file := range result.Files {
+		text += fmt.Sprintf("\n📄 %s\n", file.Path)
+		if file.Error != "" {
+			text += fmt.Sprintf("   ❌ %s\n", file.Error)
+			continue
+		}
+
+		text += fmt.Sprintf("   %d match(es) on pages %v\n", file.MatchCount, file.Pages)
+		for _, snippet := range file.Snippets {
+			text += fmt.Sprintf("   • p.%d: %s\n", snippet.Page, snippet.Text)
+		}
+	}
+
+	if result.Truncated {
+		text += "\n✂️ Snippets truncated; raise max_results to see more\n"
+	}
+
+	return text
+}
+
+func (s *Server) formatPDFStatsDirectoryResult(result *pdf.PDFStatsDirectoryResult) string {
+	text := "PDF Directory Statistics\n"
+	text += fmt.Sprintf("Directory: %s\n", result.Directory)
+	text += fmt.Sprintf("Total PDF files: %d\n", result.TotalFiles)
+	text += fmt.Sprintf("Total size: %d bytes\n", result.TotalSize)
+
+	if result.TotalFiles > 0 {
+		text += fmt.Sprintf("Average file size: %d bytes\n", result.AverageFileSize)
+		if result.LargestFileName != "" {
+			text += fmt.Sprintf("Largest file: %s (%d bytes)\n", result.LargestFileName, result.LargestFileSize)
+		}
+		if result.SmallestFileName != "" {
+			text += fmt.Sprintf("Smallest file: %s (%d bytes)\n", result.SmallestFileName, result.SmallestFileSize)
+		}
+	}
+
+	return text
+}
+
+func (s *Server) formatPDFGenerateThumbnailsResult(result *pdf.PDFGenerateThumbnailsResult) string {
+	text := fmt.Sprintf("🖼️ Thumbnails for %d PDFs in %s, at most %d pixels\n",
+		result.Files, result.Directory, result.MaxSize)
+	text += fmt.Sprintf("✨ %d generated, ♻️ %d reused, ❌ %d failed (renderer: %s)\n",
+		result.Generated, result.Reused, result.Failed, result.Renderer)
+	if result.OutputDir != "" {
+		text += fmt.Sprintf("💾 Cache directory: %s\n", result.OutputDir)
+	}
+	for _, thumbnail := range result.Thumbnails {
+		if thumbnail.Error != "" {
+			text += fmt.Sprintf("  ❌ %s page %d: %s\n", thumbnail.Path, thumbnail.Page, thumbnail.Error)
+			continue
+		}
+		text += fmt.Sprintf("  %s page %d: %s (%dx%d)\n",
+			thumbnail.Path, thumbnail.Page, thumbnail.OutputPath, thumbnail.Width, thumbnail.Height)
+	}
+	return text
+}
+
+func (s *Server) formatPDFStatsFileResult(result *pdf.PDFStatsFileResult) string {
+	text := "PDF File Statistics\n"
+	text += fmt.Sprintf("File: %s\n", result.Path)
+	text += fmt.Sprintf("Size: %d bytes\n", result.Size)
+	text += fmt.Sprintf("Pages: %d\n", result.Pages)
+	text += fmt.Sprintf("Modified: %s\n", result.ModifiedDate)
+
+	if result.Title != "" {
+		text += fmt.Sprintf("Title: %s\n", result.Title)
+	}
+	if result.Author != "" {
+		text += fmt.Sprintf("Author: %s\n", result.Author)
+	}
+	if result.Subject != "" {
+		text += fmt.Sprintf("Subject: %s\n", result.Subject)
+	}
+	if result.Producer != "" {
+		text += fmt.Sprintf("Producer: %s\n", result.Producer)
+	}
+	if result.CreatedDate != "" {
+		text += fmt.Sprintf("Created: %s\n", result.CreatedDate)
+	}
+	for _, issue := range result.PageIssues {
+		text += fmt.Sprintf("⚠️ Page tree: %s\n", issue)
+	}
+
+	return text
+}
+
+func (s *Server) formatPDFAssetsFileResult(result *pdf.PDFAssetsFileResult) string {
+	text := fmt.Sprintf("PDF Assets for: %s\n", result.Path)
+	text += fmt.Sprintf("Total images found: %d\n", result.TotalCount)
+
+	if result.TotalCount > 0 {
+		text += "\nImages:\n"
+		for i, img := range result.Images {
+			text += fmt.Sprintf("%d. Page %s: %dx%d pixels, Format: %s",
+				i+1, pageNumber(img.PageNumber, img.PageLabel), img.Width, img.Height, img.Format)
+			if img.Size > 0 {
+				text += fmt.Sprintf(", Size: %d bytes", img.Size)
+			}
+			if img.Kind != "" {
+				text += fmt.Sprintf(", Kind: %s (%.0f%% confidence)", img.Kind, img.Confidence*100)
+			}
+			if img.LowDPI {
+				text += " ⚠️ low resolution"
+			}
+			text += "\n"
+			for _, placement := range img.Placements {
+				text += fmt.Sprintf("   Drawn at %.2f x %.2f in (%.1f x %.1f pt), %.0f DPI\n",
+					placement.WidthInches, placement.HeightInches,
+					placement.WidthPoints, placement.HeightPoints, placement.EffectiveDPI)
+			}
+			if img.Payload != "" {
+				text += fmt.Sprintf("   %s payload: %s\n", img.Symbology, img.Payload)
+			}
+		}
+	}
+	if result.LowDPI > 0 {
+		text += fmt.Sprintf("\n⚠️  %d of %d images are drawn below %.0f DPI\n",
+			result.LowDPI, result.TotalCount, result.MinDPI)
+	}
+	if result.Classified > 0 && result.Classified < result.TotalCount {
+		text += fmt.Sprintf("\nClassified %d of %d images; the others use compressions that are not decoded\n",
+			result.Classified, result.TotalCount)
+	}
+
+	return text
+}
+
+func (s *Server) formatPDFServerInfoResult(result *pdf.PDFServerInfoResult) string {
+	text := fmt.Sprintf("📋 %s v%s - Server Information\n", result.ServerName, result.Version)
+	text += fmt.Sprintf("📁 Default Directory: %s\n", result.DefaultDirectory)
+	if len(result.Roots) > 1 {
+		text += fmt.Sprintf("🗂️  Roots: %s\n", strings.Join(result.Roots, ", "))
+	}
+	text += fmt.Sprintf("📏 Max File Size: %d MB\n", result.MaxFileSize/(1024*1024))
+	if result.ReadOnly {
+		text += "🔒 Read-only mode: tools that modify documents are disabled and output files are refused\n"
+	}
+	text += fmt.Sprintf("✅ Enabled Tools (%d): %s\n", len(result.EnabledTools), strings.Join(result.EnabledTools, ", "))
+	if len(result.DisabledTools) > 0 {
+		text += fmt.Sprintf("🚫 Disabled Tools: %s\n", strings.Join(result.DisabledTools, ", "))
+	}
+	if m := result.Metrics; m != nil {
+		text += fmt.Sprintf("📈 Activity: %d tool calls (%d failed), %d extractions (%d partial, %d pages) "+
+			"in %s; cache hit rate %.0f%%, document reuse %.0f%%\n",
+			m.ToolCalls, m.ToolErrors, m.Extractions, m.PartialExtractions, m.PagesExtracted,
+			time.Duration(m.UptimeSeconds)*time.Second, m.CacheHitRate*100, m.HandleHitRate*100)
+		for i, tool := range m.Tools {
+			if i >= 5 {
+				break
+			}
+			text += fmt.Sprintf("   • %s: %d calls, %d failed, %.0f ms mean, %.0f ms max\n",
+				tool.Name, tool.Calls, tool.Errors, tool.MeanDurationMS, tool.MaxDurationMS)
+		}
+	}
+	text += "\n"
+
+	// Directory contents
+	if len(result.DirectoryContents) > 0 {
+		text += fmt.Sprintf("📂 Directory Contents (%d PDF files found):\n", len(result.DirectoryContents))
+		for i, file := range result.DirectoryContents {
+			if i >= 10 { // Limit to first 10 files for readability
+				text += fmt.Sprintf("   ... and %d more files\n", len(result.DirectoryContents)-10)
+				break
+			}
+			text += fmt.Sprintf("   %d. %s (%d bytes)\n", i+1, file.Name, file.Size)
+		}
+		text += "\n"
+	} else {
+		text += "📂 Directory Contents: No PDF files found in default directory\n\n"
+	}
+
+	// Available tools
+	text += "🛠️  Available Tools:\n"
+	for _, tool := range result.AvailableTools {
+		text += fmt.Sprintf("\n• %s\n", tool.Name)
+		text += fmt.Sprintf("  Description: %s\n", tool.Description)
+		text += fmt.Sprintf("  Usage: %s\n", tool.Usage)
+		text += fmt.Sprintf("  Parameters: %s\n", tool.Parameters)
+	}
+
+	// Supported formats
+	if len(result.SupportedFormats) > 0 {
+		text += "\n🖼️  Supported Image Formats:\n"
+		for _, format := range result.SupportedFormats {
+			text += fmt.Sprintf("  • %s\n", format)
+		}
+	}
+
+	// Usage guidance
+	text += "\n" + result.UsageGuidance
+
+	return text
+}
+
+func (s *Server) formatPDFHealthResult(result *pdf.PDFHealthResult) string {
+	text := "✅ Health: pass\n"
+	if result.Status != pdf.HealthPass {
+		text = "❌ Health: fail\n"
+	}
+	text += fmt.Sprintf("🕐 Checked: %s\n\n", result.CheckedAt)
+	for _, check := range result.Checks {
+		icon := "✅"
+		switch {
+		case check.Status == pdf.HealthPass:
+		case check.Required:
+			icon = "❌"
+		default:
+			icon = "⚠️ "
+		}
+		optional := ""
+		if !check.Required {
+			optional = " (optional)"
+		}
+		text += fmt.Sprintf("%s %s%s: %s [%.1f ms]\n", icon, check.Name, optional, check.Detail, check.DurationMS)
+	}
+	return text
+}
+
+// New formatting methods for structured extraction results
+
+func (s *Server) formatPDFExtractResult(result *pdf.PDFExtractResult) string {
+	text := fmt.Sprintf("📄 PDF Extraction Results: %s\n", result.FilePath)
+	text += fmt.Sprintf("🔧 Mode: %s\n", result.Mode)
+	text += fmt.Sprintf("📖 Pages: %d (processed: %v)\n", result.TotalPages, result.ProcessedPages)
+	if result.PageSelection != nil {
+		text += fmt.Sprintf("📑 Page Window: %s\n", formatPageSelection(result.PageSelection))
+	}
+	if result.Preview != nil {
+		text += formatPreview(result.Preview)
+	}
+	if result.Partial {
+		text += "⏳ Partial result: extraction stopped before all pages were processed\n"
+		if result.ResumeToken != "" {
+			text += fmt.Sprintf("🔁 Resume: repeat the call with resume_token %q to continue from the last completed page\n",
+				result.ResumeToken)
+		}
+	}
+	if len(result.Summary.Truncations) > 0 {
+		text += "✂️ Truncated by server limits:\n"
+		for _, truncation := range result.Summary.Truncations {
+			text += fmt.Sprintf("  • %s\n", truncation)
+		}
+	}
+	if page := result.Pagination; page != nil {
+		text += fmt.Sprintf("📃 Items %d-%d of %d\n",
+			min(page.Offset+1, page.TotalItems), page.Offset+len(result.Elements)+len(result.Tables), page.TotalItems)
+		if page.NextCursor != "" {
+			text += fmt.Sprintf("➡️ More: repeat the call with cursor %q for the next %d items\n",
+				page.NextCursor, page.PageSize)
+		}
+	}
+	text += fmt.Sprintf("🎯 Quality: %s\n", result.Summary.Quality)
+	text += fmt.Sprintf("📊 Total Elements: %d\n", result.Summary.TotalElements)
+	text += formatEscalation(result.Escalation)
+	text += "\n"
+
+	// Content type breakdown
+	text += "📋 Content Types Found:\n"
+	for contentType, count := range result.Summary.ContentTypes {
+		text += fmt.Sprintf("  • %s: %d\n", contentType, count)
+	}
+	text += "\n"
+
+	// Tables if found
+	if len(result.Tables) > 0 {
+		text += fmt.Sprintf("📊 Tables Found: %d\n", len(result.Tables))
+		for i, table := range result.Tables {
+			text += fmt.Sprintf("  Table %d: %d rows × %d columns (%d cells)\n",
+				i+1, len(table.Rows), len(table.Columns), table.CellCount)
+			if table.PageNumber > 0 {
+				text += fmt.Sprintf("    - Page: %s\n", pageNumber(table.PageNumber, table.PageLabel))
+			}
+			if table.HasHeaders {
+				text += "    - Has headers\n"
+			}
+			if table.Strategy != "" {
+				text += fmt.Sprintf("    - Detected by: %s\n", table.Strategy)
+			}
+			text += fmt.Sprintf("    - Confidence: %.2f\n", table.Confidence)
+			if types := columnTypes(table); types != "" {
+				text += fmt.Sprintf("    - Column types: %s\n", types)
+			}
+		}
+		text += "\n"
+	}
+	if export := result.Export; export != nil {
+		if len(export.Files) == 0 {
+			text += fmt.Sprintf("💾 No tables to export as %s\n\n", export.Format)
+		} else {
+			text += fmt.Sprintf("💾 Exported %d tables as %s: %s\n\n", export.Tables, export.Format,
+				strings.Join(export.Files, ", "))
+		}
+	}
+
+	// Logical structure of tagged documents
+	if result.Structure != nil {
+		text += fmt.Sprintf("🏷️ Tagged Structure: %d elements", result.Structure.ElementCount)
+		if result.Structure.Truncated {
+			text += " (truncated)"
+		}
+		text += "\n"
+		lines := 0
+		text += formatStructureElements(result.Structure.Elements, 1, &lines)
+		text += "\n"
+	}
+
+	// Entities found in semantic mode
+	if len(result.Entities) > 0 {
+		text += fmt.Sprintf("🏷️ Entities: %d", len(result.Entities))
+		text += formatEntities(result.Entities, 30)
+		text += "\n"
+	}
+
+	// Page breakdown
+	if len(result.Summary.PageBreakdown) > 0 {
+		text += "📄 Page Breakdown:\n"
+		for _, page := range result.Summary.PageBreakdown {
+			text += fmt.Sprintf("  Page %s: %d elements\n", pageNumber(page.Page, page.Label), page.Elements)
+		}
+		text += "\n"
+	}
+
+	// Suggestions
+	if len(result.Summary.Suggestions) > 0 {
+		text += "💡 Suggestions:\n"
+		for _, suggestion := range result.Summary.Suggestions {
+			text += fmt.Sprintf("  • %s\n", suggestion)
+		}
+		text += "\n"
+	}
+
+	// Pages whose text did not fully map to Unicode
+	var uncertain []pdf.PageDecoding
+	for _, page := range result.Decoding {
+		if page.Confidence < 1 {
+			uncertain = append(uncertain, page)
+		}
+	}
+	if len(uncertain) > 0 {
+		text += "🔤 Text Decoding:\n"
+		for _, page := range uncertain {
+			text += fmt.Sprintf("  Page %d: %.0f%% confidence, %d of %d codes unmapped, %d guessed",
+				page.Page, 100*page.Confidence, page.Unmapped, page.Glyphs, page.Guessed)
+			if len(page.Fonts) > 0 {
+				text += fmt.Sprintf(" (%s)", strings.Join(page.Fonts, ", "))
+			}
+			text += "\n"
+		}
+		text += "\n"
+	}
+
+	if result.ReadStats != nil {
+		stats := result.ReadStats
+		text += fmt.Sprintf("💽 Storage: %s (%.2f ms/read, %.1f MB/s), %d reads + %d cache hits, "+
+			"chunk %d KB, read-ahead %d\n\n",
+			stats.StorageClass, stats.AvgReadLatencyMs, stats.ThroughputMBps, stats.PhysicalReads,
+			stats.CacheHits, stats.ChunkSize/1024, stats.ReadAhead)
+	}
+
+	if timing := result.Timing; timing != nil {
+		text += fmt.Sprintf("⏱️  Timing: %.1f ms total (parse %.1f, decode %.1f, extract %.1f, post-process %.1f)\n",
+			timing.TotalMs, timing.ParseMs, timing.FilterDecodeMs, timing.ExtractMs, timing.PostProcessMs)
+		if len(timing.SlowestPages) > 1 {
+			text += "  Slowest pages:\n"
+			for _, page := range timing.SlowestPages {
+				text += fmt.Sprintf("    Page %d: %.1f ms (parse %.1f, decode %.1f, extract %.1f, post-process %.1f)\n",
+					page.Page, page.TotalMs, page.ParseMs, page.FilterDecodeMs, page.ExtractMs, page.PostProcessMs)
+			}
+		}
+		text += "\n"
+	}
+
+	// Warnings and errors, located precisely when the engine reported structured issues
+	if len(result.Issues) > 0 {
+		text += formatParseIssues(result.Issues)
+	} else {
+		if len(result.Warnings) > 0 {
+			text += "⚠️  Warnings:\n"
+			for _, warning := range result.Warnings {
+				text += fmt.Sprintf("  • %s\n", warning)
+			}
+			text += "\n"
+		}
+
+		if len(result.Errors) > 0 {
+			text += "❌ Errors:\n"
+			for _, error := range result.Errors {
+				text += fmt.Sprintf("  • %s\n", error)
+			}
+			text += "\n"
+		}
+	}
+
+	// Show first few elements as examples
+	if len(result.Elements) > 0 {
+		text += fmt.Sprintf("🔍 Content Elements (showing first %d):\n", minInt(5, len(result.Elements)))
+		for i, element := range result.Elements {
+			if i >= 5 {
+				text += fmt.Sprintf("  ... and %d more elements\n", len(result.Elements)-5)
+				break
+			}
+			text += fmt.Sprintf("  %d. %s on page %s (confidence: %.2f)\n",
+				i+1, element.Type, pageNumber(element.PageNumber, element.PageLabel), element.Confidence)
+
+			// Show content preview for text elements
+			if element.Type == "text" {
+				if contentStr, ok := element.Content.(string); ok {
+					preview := contentStr
+					if len(preview) > 100 {
+						preview = preview[:100] + "..."
+					}
+					text += fmt.Sprintf("     Content: %s\n", preview)
+				}
+			}
+		}
+	}
+
+	return text
+}
+
+// columnTypes lists the inferred data types of a table's columns, or nothing when no
+// column has one
+func columnTypes(table pdf.TableElement) string {
+	types := make([]string, len(table.Columns))
+	typed := false
+	for i, col := range table.Columns {
+		types[i] = col.DataType
+		if col.DataType == "" {
+			types[i] = "-"
+		} else {
+			typed = true
+		}
+	}
+	if !typed {
+		return ""
+	}
+	return strings.Join(types, ", ")
+}
+
+func (s *Server) formatPDFQueryResult(result *pdf.PDFQueryResult) string {
+	text := fmt.Sprintf("🔍 Query Results: %s\n", result.FilePath)
+	text += fmt.Sprintf("📊 Matches Found: %d\n", result.MatchCount)
+	text += fmt.Sprintf("🎯 Average Confidence: %.2f\n\n", result.Summary.Confidence)
+
+	// Query details
+	text += "🔎 Query Details:\n"
+	if len(result.Query.ContentTypes) > 0 {
+		text += fmt.Sprintf("  Content Types: %v\n", result.Query.ContentTypes)
+	}
+	if len(result.Query.Pages) > 0 {
+		text += fmt.Sprintf("  Pages: %v\n", result.Query.Pages)
+	}
+	if result.Query.TextQuery != "" {
+		text += fmt.Sprintf("  Text Query: %s", result.Query.TextQuery)
+		if result.Query.Regex {
+			text += " (regex)"
+		}
+		if result.Query.CaseSensitive {
+			text += " (case-sensitive)"
+		}
+		text += "\n"
+	}
+	if box := result.Query.BoundingBox; box != nil {
+		text += fmt.Sprintf("  Area: %.1f,%.1f %.1f×%.1f\n", box.X, box.Y, box.Width, box.Height)
+	}
+	if result.Query.MinConfidence > 0 {
+		text += fmt.Sprintf("  Min Confidence: %.2f\n", result.Query.MinConfidence)
+	}
+	if clause := result.Clause; clause != nil {
+		text += fmt.Sprintf("  Clause: %s", clause.Number)
+		if clause.Title != "" {
+			text += " " + clause.Title
+		}
+		text += fmt.Sprintf(" (pages %d-%d)\n", clause.Page, clause.EndPage)
+	}
+	text += "\n"
+
+	// Result breakdown
+	if len(result.Summary.TypeBreakdown) > 0 {
+		text += "📋 Result Breakdown by Type:\n"
+		for contentType, count := range result.Summary.TypeBreakdown {
+			text += fmt.Sprintf("  • %s: %d\n", contentType, count)
+		}
+		text += "\n"
+	}
+
+	if len(result.Summary.PageBreakdown) > 0 {
+		text += "📄 Result Breakdown by Page:\n"
+		for page, count := range result.Summary.PageBreakdown {
+			text += fmt.Sprintf("  • Page %d: %d\n", page, count)
+		}
+		text += "\n"
+	}
+
+	// Show matching elements
+	if len(result.Elements) > 0 {
+		text += fmt.Sprintf("🎯 Matching Elements (showing first %d):\n", minInt(10, len(result.Elements)))
+		for i, element := range result.Elements {
+			if i >= 10 {
+				text += fmt.Sprintf("  ... and %d more matches\n", len(result.Elements)-10)
+				break
+			}
+			text += fmt.Sprintf("  %d. %s on page %s (confidence: %.2f)\n",
+				i+1, element.Type, pageNumber(element.PageNumber, element.PageLabel), element.Confidence)
+		}
+	}
+
+	return text
+}
+
+func (s *Server) formatPDFQuerySetResult(result *pdf.PDFQuerySetResult) string {
+	text := fmt.Sprintf("🔍 Query Set Results: %q\n", result.Query.TextQuery)
+	text += fmt.Sprintf("📊 Matches Found: %d in %d of %d file(s)\n",
+		result.TotalMatches, result.FilesMatched, len(result.Files))
+	if result.FilesFailed > 0 {
+		text += fmt.Sprintf("❌ Failed Files: %d\n", result.FilesFailed)
+	}
+	text += "\n"
+
+	text += "📁 Results by File:\n"
+	for _, file := range result.Files {
+		if file.Error != "" {
+			text += fmt.Sprintf("  • %s: error: %s\n", file.Path, file.Error)
+			continue
+		}
+		text += fmt.Sprintf("  • %s: %d match(es), top score %.2f\n", file.Path, file.MatchCount, file.TopScore)
+	}
+	text += "\n"
+
+	if len(result.Matches) > 0 {
+		text += fmt.Sprintf("🏆 Top Matches (showing %d):\n", len(result.Matches))
+		for _, match := range result.Matches {
+			text += fmt.Sprintf("  %d. [%.2f] %s, page %s (%s)\n", match.Rank, match.Score, filepath.Base(match.Path),
+				pageNumber(match.Element.PageNumber, match.Element.PageLabel), match.Element.Type)
+			if contentStr, ok := match.Element.Content.(string); ok && contentStr != "" {
+				preview := contentStr
+				if len(preview) > 100 {
+					preview = preview[:100] + "..."
+				}
+				text += fmt.Sprintf("     Content: %s\n", preview)
+			}
+		}
+	}
+
+	return text
+}
+
+// formatPDFBatchExtractResult lists the outcome of each file of a batch extraction
+func (s *Server) formatPDFBatchExtractResult(result *pdf.PDFBatchExtractResult) string {
+	text := fmt.Sprintf("📦 Batch Extraction (%s mode): %d file(s) in %.0f ms\n",
+		result.Mode, result.TotalFiles, result.DurationMs)
+	text += fmt.Sprintf("✅ Succeeded: %d", result.Succeeded)
+	if result.Partial > 0 {
+		text += fmt.Sprintf("  ⚠️ Partial: %d", result.Partial)
+	}
+	if result.Failed > 0 {
+		text += fmt.Sprintf("  ❌ Failed: %d", result.Failed)
+	}
+	text += fmt.Sprintf("\n💾 Manifest: %s\n\n", result.ManifestPath)
+
+	for _, file := range result.Files {
+		switch file.Status {
+		case pdf.BatchFailed:
+			text += fmt.Sprintf("  ❌ %s: %s\n", file.Path, file.Error)
+		default:
+			icon := "✅"
+			if file.Status == pdf.BatchPartial {
+				icon = "⚠️"
+			}
+			text += fmt.Sprintf("  %s %s: %d of %d page(s), %d element(s), %d table(s) in %.0f ms → %s\n",
+				icon, file.Path, file.ProcessedPages, file.TotalPages, file.Elements, file.Tables,
+				file.DurationMs, file.OutputPath)
+		}
+	}
+	return text
+}
+
+func (s *Server) formatExtractionJob(job *pdf.ExtractionJob) string {
+	text := fmt.Sprintf("🗂️ Extraction Job %s (%s mode): %s\n", job.JobID, job.Mode, job.Status)
+	text += fmt.Sprintf("📄 File: %s\n", job.Path)
+	if job.PagesTotal > 0 {
+		text += fmt.Sprintf("📖 Pages: %d of %d\n", job.PagesDone, job.PagesTotal)
+	} else {
+		text += fmt.Sprintf("📖 Pages: %d\n", job.PagesDone)
+	}
+	text += fmt.Sprintf("⏱️ Elapsed: %.0f ms\n", job.ElapsedMs)
+	if job.Message != "" && job.Status == pdf.JobRunning {
+		text += fmt.Sprintf("💬 %s\n", job.Message)
+	}
+	switch job.Status {
+	case pdf.JobRunning:
+		text += "\nCheck again with pdf_extract_status; fetch the output with pdf_extract_result once it finishes.\n"
+	case pdf.BatchFailed:
+		text += fmt.Sprintf("❌ Error: %s\n", job.Error)
+	default:
+		text += "\nFetch the output with pdf_extract_result.\n"
+	}
+	if job.ResumeToken != "" {
+		text += fmt.Sprintf("🔁 Resume token: %s (start a new job with it to continue)\n", job.ResumeToken)
+	}
+	return text
+}
+
+func (s *Server) formatPDFPageInfoResult(result *pdf.PDFPageInfoResult) string {
+	text := fmt.Sprintf("📄 Page Information: %s\n", result.FilePath)
+	text += fmt.Sprintf("📖 Total Pages: %d\n\n", len(result.Pages))
+
+	for _, page := range result.Pages {
+		text += fmt.Sprintf("Page %s:\n", pageNumber(page.Number, page.Label))
+		text += fmt.Sprintf("  Dimensions: %.1f × %.1f pts\n", page.Width, page.Height)
+		if page.Rotation != 0 {
+			text += fmt.Sprintf("  Rotation: %d°\n", page.Rotation)
+		}
+		text += formatPageBox("Media Box", page.MediaBox, "")
+		for _, box := range []struct {
+			key, name string
+			rect      pdf.Rectangle
+		}{
+			{"CropBox", "Crop Box", page.CropBox},
+			{"BleedBox", "Bleed Box", page.BleedBox},
+			{"TrimBox", "Trim Box", page.TrimBox},
+			{"ArtBox", "Art Box", page.ArtBox},
+		} {
+			if slices.Contains(page.ExplicitBoxes, box.key) {
+				text += formatPageBox(box.name, box.rect, "")
+			} else if box.rect != page.MediaBox {
+				text += formatPageBox(box.name, box.rect, " (default)")
+			}
+		}
+		text += "\n"
+	}
+
+	return text
+}
+
+// formatPageBox describes a page box by its corners
+func formatPageBox(name string, box pdf.Rectangle, note string) string {
+	return fmt.Sprintf("  %s: (%.1f, %.1f) to (%.1f, %.1f) — %.1f × %.1f pts%s\n",
+		name, box.X, box.Y, box.X+box.Width, box.Y+box.Height, box.Width, box.Height, note)
+}
+
+func (s *Server) formatPDFMetadataResult(result *pdf.PDFMetadataResult) string {
+	text := fmt.Sprintf("📋 Document Metadata: %s\n\n", result.FilePath)
+
+	metadata := result.Metadata
+
+	if metadata.Title != "" {
+		text += fmt.Sprintf("📖 Title: %s\n", metadata.Title)
+	}
+	if metadata.Author != "" {
+		text += fmt.Sprintf("👤 Author: %s\n", metadata.Author)
+	}
+	if metadata.Subject != "" {
+		text += fmt.Sprintf("📝 Subject: %s\n", metadata.Subject)
+	}
+	if metadata.Creator != "" {
+		text += fmt.Sprintf("🛠️ Creator: %s\n", metadata.Creator)
+	}
+	if metadata.Producer != "" {
+		text += fmt.Sprintf("🏭 Producer: %s\n", metadata.Producer)
+	}
+	if metadata.CreationDate != "" {
+		text += fmt.Sprintf("📅 Created: %s\n", metadata.CreationDate)
+	}
+	if metadata.ModificationDate != "" {
+		text += fmt.Sprintf("📅 Modified: %s\n", metadata.ModificationDate)
+	}
+	if len(metadata.Keywords) > 0 {
+		text += fmt.Sprintf("🏷️ Keywords: %v\n", metadata.Keywords)
+	}
+	if metadata.Version != "" {
+		text += fmt.Sprintf("📄 PDF Version: %s\n", metadata.Version)
+	}
+	if metadata.PageLayout != "" {
+		text += fmt.Sprintf("📐 Page Layout: %s\n", metadata.PageLayout)
+	}
+	if metadata.PageMode != "" {
+		text += fmt.Sprintf("🖥️ Page Mode: %s\n", metadata.PageMode)
+	}
+	if metadata.Encrypted {
+		text += "🔒 Document is encrypted\n"
+	}
+
+	if len(metadata.CustomProperties) > 0 {
+		text += "\n🏷️ Custom Properties:\n"
+		for key, value := range metadata.CustomProperties {
+			text += fmt.Sprintf("  • %s: %s\n", key, value)
+		}
+	}
+
+	return text
+}
+
+func (s *Server) formatPDFGetPermissionsResult(result *pdf.PDFGetPermissionsResult) string {
+	text := fmt.Sprintf("🔐 Permissions: %s\n\n", result.Path)
+	switch {
+	case result.RequiresPassword:
+		text += "🔒 Encrypted with a user password\n"
+	case result.Encryption != nil:
+		enc := result.Encryption
+		text += fmt.Sprintf("🔒 Encrypted: %s filter, %s with a %d-bit key (V%d, R%d)\n",
+			enc.Filter, enc.Algorithm, enc.KeyLength, enc.Version, enc.Revision)
+		if !enc.MetadataEncrypted {
+			text += "📋 Metadata is not encrypted\n"
+		}
+	default:
+		text += "🔓 Not encrypted\n"
+	}
+
+	if !result.RequiresPassword {
+		mark := func(allowed bool) string {
+			if allowed {
+				return "✅"
+			}
+			return "❌"
+		}
+		p := result.Permissions
+		text += "\n"
+		text += fmt.Sprintf("  %s Print (%s high quality)\n", mark(p.Print), mark(p.PrintHighQuality))
+		text += fmt.Sprintf("  %s Copy text and graphics\n", mark(p.Copy))
+		text += fmt.Sprintf("  %s Modify\n", mark(p.Modify))
+		text += fmt.Sprintf("  %s Annotate\n", mark(p.Annotate))
+		text += fmt.Sprintf("  %s Fill forms\n", mark(p.FillForms))
+		text += fmt.Sprintf("  %s Extract for accessibility\n", mark(p.AccessibilityExtraction))
+		text += fmt.Sprintf("  %s Assemble pages\n", mark(p.Assemble))
+	}
+
+	text += fmt.Sprintf("\n📝 Text extraction: %s (%s)\n", result.TextExtraction, result.Reason)
+	return text
+}
+
+func (s *Server) formatPDFFingerprintResult(result *pdf.DocumentFingerprint) string {
+	text := fmt.Sprintf("🧬 Layout Fingerprint: %s\n", result.Path)
+	text += fmt.Sprintf("🔑 Hash: %s\n", result.Hash)
+	text += fmt.Sprintf("📖 Pages: %d (fingerprinted: %d)\n", result.PageCount, len(result.Pages))
+	text += fmt.Sprintf("📐 Grid: %d × %d\n\n", result.GridSize, result.GridSize)
+
+	for _, page := range result.Pages {
+		text += fmt.Sprintf("Page %d (%.0f × %.0f pts):\n", page.Page, page.Width, page.Height)
+		for row := 0; row < result.GridSize; row++ {
+			text += "  "
+			for col := 0; col < result.GridSize; col++ {
+				text += densitySymbol(page.Density[row*result.GridSize+col])
+			}
+			text += "\n"
+		}
+		if len(page.Anchors) > 0 {
+			anchors := make([]string, 0, len(page.Anchors))
+			for _, anchor := range page.Anchors {
+				anchors = append(anchors, anchor.Text)
+			}
+			text += fmt.Sprintf("  Anchors: %s\n", strings.Join(anchors, ", "))
+		}
+		text += "\n"
+	}
+
+	return text
+}
+
+func (s *Server) formatPDFMatchTemplateResult(result *pdf.PDFMatchTemplateResult) string {
+	text := fmt.Sprintf("🧩 Template Matching: %s\n", result.Path)
+	if result.BestMatch != nil {
+		text += fmt.Sprintf("✅ Best match: %s (score: %.2f)\n", result.BestMatch.TemplateName, result.BestMatch.Score)
+	} else {
+		text += fmt.Sprintf("❌ No template matched (minimum score: %.2f)\n", result.MinScore)
+	}
+	text += "\n"
+
+	if len(result.Matches) > 0 {
+		text += "📊 Candidates:\n"
+		for i, match := range result.Matches {
+			text += fmt.Sprintf("  %d. %s — score %.2f (layout %.2f, anchors %.2f)\n",
+				i+1, match.TemplateName, match.Score, match.LayoutSimilarity, match.AnchorSimilarity)
+			text += fmt.Sprintf("     Path: %s\n", match.TemplatePath)
+		}
+		text += "\n"
+	}
+
+	if len(result.Errors) > 0 {
+		text += "❌ Errors:\n"
+		for _, err := range result.Errors {
+			text += fmt.Sprintf("  • %s\n", err)
+		}
+	}
+
+	return text
+}
+
+func (s *Server) formatPDFOutlineResult(result *pdf.PDFGetOutlineResult) string {
+	text := fmt.Sprintf("🔖 Document Outline: %s\n", result.Path)
+	if !result.HasOutline {
+		text += "\nThis document has no bookmarks.\n"
+	} else {
+		text += fmt.Sprintf("📑 Bookmarks: %d (max depth: %d)\n\n", result.TotalItems, result.MaxDepth)
+		text += formatOutlineItems(result.Items, 0)
+	}
+
+	if len(result.Appendices) > 0 {
+		text += fmt.Sprintf("\n📎 Appendices: %d\n", len(result.Appendices))
+		for _, appendix := range result.Appendices {
+			text += "• " + appendix.Name()
+			if appendix.Title != "" {
+				text += ": " + appendix.Title
+			}
+			text += fmt.Sprintf(" → pages %d-%d\n", appendix.StartPage, appendix.EndPage)
+		}
+	}
+	return text
+}
+
+func (s *Server) formatPDFExtractSectionResult(result *pdf.PDFExtractSectionResult) string {
+	name := result.Title
+	if result.Kind != "section" {
+		name = pdf.Appendix{Kind: result.Kind, Label: result.Label}.Name()
+		if result.Title != "" {
+			name += ": " + result.Title
+		}
+	}
+
+	text := fmt.Sprintf("📑 Section: %s\n", name)
+	text += fmt.Sprintf("📄 File: %s\n", result.Path)
+	text += fmt.Sprintf("📖 Pages: %d-%d\n", result.StartPage, result.EndPage)
+	text += formatEscalation(result.Escalation)
+	text += "\n" + result.Content
+	return text
+}
+
+func (s *Server) formatPDFGetClausesResult(result *pdf.PDFGetClausesResult) string {
+	text := fmt.Sprintf("⚖️ Clauses of %s\n", result.Path)
+	text += fmt.Sprintf("📄 Pages: %d\n", result.TotalPages)
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	if result.ClauseCount == 0 {
+		text += "\nNo numbered clauses found.\n"
+		return text
+	}
+	text += fmt.Sprintf("📑 Clauses: %d\n\n", result.ClauseCount)
+	text += formatClauses(result.Clauses, 0)
+	return text
+}
+
+// formatClauses formats a clause tree, indenting subdivisions under their clause
+func formatClauses(clauses []pdf.Clause, depth int) string {
+	text := ""
+	for _, clause := range clauses {
+		text += strings.Repeat("  ", depth) + clause.Number
+		if clause.Title != "" {
+			text += " " + clause.Title
+		}
+		text += fmt.Sprintf(" [%s] → page %d", clause.Anchor, clause.Page)
+		if clause.EndPage > clause.Page {
+			text += fmt.Sprintf("-%d", clause.EndPage)
+		}
+		text += "\n"
+		if clause.Text != "" {
+			text += strings.Repeat("  ", depth+1) + clause.Text + "\n"
+		}
+		text += formatClauses(clause.Children, depth+1)
+	}
+	return text
+}
+
+func (s *Server) formatPDFRenderPageResult(result *pdf.PDFRenderPageResult) string {
+	text := fmt.Sprintf("🖼️ Rendered page %d of %s\n", result.Page, result.Path)
+	text += fmt.Sprintf("📐 %dx%d pixels at %d dpi\n", result.Width, result.Height, result.DPI)
+	text += fmt.Sprintf("🗂️ Format: %s (%d bytes)\n", result.MIMEType, result.Size)
+	text += fmt.Sprintf("🔧 Renderer: %s\n", result.Renderer)
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to: %s\n", result.OutputPath)
+	}
+	return text
+}
+
+func (s *Server) formatPDFOCRRegionResult(result *pdf.PDFOCRRegionResult) string {
+	text := fmt.Sprintf("🔎 Recognized %d regions on page %d of %s\n", len(result.Regions), result.Page, result.Path)
+	text += fmt.Sprintf("🔧 %s (%s) on a %d dpi render by %s\n",
+		result.Engine, result.Language, result.DPI, result.Renderer)
+	for i, region := range result.Regions {
+		box := region.BoundingBox
+		text += fmt.Sprintf("\n%d. %s at (%.1f, %.1f) %.1fx%.1f: %d words, confidence %.2f\n",
+			i+1, region.Source, box.X, box.Y, box.Width, box.Height, len(region.Words), region.Confidence)
+		if region.Text == "" {
+			text += "   (no text recognized)\n"
+			continue
+		}
+		for _, line := range strings.Split(region.Text, "\n") {
+			text += "   " + line + "\n"
+		}
+	}
+	return text
+}
+
+func (s *Server) formatPDFExportDocumentResult(result *pdf.PDFExportDocumentResult) string {
+	text := fmt.Sprintf("📝 Exported %s to %s (%d pages)\n", result.Path, strings.ToUpper(result.Format), result.Pages)
+	text += fmt.Sprintf("🧱 %d headings, %d paragraphs, %d list items, %d tables\n",
+		result.Headings, result.Paragraphs, result.ListItems, result.Tables)
+	structure := result.Structure
+	text += fmt.Sprintf("🧭 Structure preset: %s (headings from %.2fx body size, up to %d words",
+		structure.Preset, structure.HeadingFontRatio, structure.HeadingMaxWords)
+	if !structure.DetectHeadings {
+		text += ", headings off"
+	}
+	if !structure.DetectLists {
+		text += ", lists off"
+	}
+	if !structure.MergeLines {
+		text += ", one block per line"
+	}
+	text += ")\n"
+	if result.Images > 0 {
+		text += fmt.Sprintf("🖼️ %d images kept as placeholders\n", result.Images)
+	}
+	text += fmt.Sprintf("🗂️ Format: %s (%d bytes)\n", result.MIMEType, result.Size)
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to: %s\n", result.OutputPath)
+	}
+	return text
+}
+
+// formatPDFExportTextResult formats the summary of a text export followed by the text
+func (s *Server) formatPDFExportTextResult(result *pdf.PDFExportTextResult) string {
+	text := fmt.Sprintf("📝 Exported the text of %s as %s (%d pages, %d elements)\n",
+		result.Path, result.Format, result.Pages, result.Elements)
+	if result.LinesRemoved > 0 {
+		text += fmt.Sprintf("✂️ Removed %d header and footer lines: %s\n",
+			result.LinesRemoved, strings.Join(result.HeadersFooters, " | "))
+	}
+	if result.Dehyphenated > 0 {
+		text += fmt.Sprintf("🔗 Joined %d hyphenated words\n", result.Dehyphenated)
+	}
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	text += fmt.Sprintf("🗂️ Format: %s (%d bytes)\n", result.MIMEType, result.Size)
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to: %s\n", result.OutputPath)
+		return text
+	}
+	return text + "\n" + result.Content
+}
+
+// formatPDFExtractReadingOrderResult formats the blocks of a PDF in reading order
+func (s *Server) formatPDFExtractReadingOrderResult(result *pdf.PDFExtractReadingOrderResult) string {
+	text := fmt.Sprintf("🔊 Reading order of %s (%d pages, %d blocks)\n", result.Path, result.Pages, len(result.Blocks))
+	if result.LinesRemoved > 0 {
+		text += fmt.Sprintf("✂️ Left out %d header and footer lines\n", result.LinesRemoved)
+	}
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	page := 0
+	for _, block := range result.Blocks {
+		if block.Page != page {
+			page = block.Page
+			text += fmt.Sprintf("\n📄 Page %d\n", page)
+		}
+		kind := block.Type
+		if block.Level > 0 {
+			kind += fmt.Sprintf(" %d", block.Level)
+		}
+		text += fmt.Sprintf("%d. [%s] %s\n", block.Order, kind, block.Text)
+	}
+	return text
+}
+
+// formatPDFChunkContentResult formats every chunk with its pages and section
+func (s *Server) formatPDFChunkContentResult(result *pdf.PDFChunkContentResult) string {
+	text := fmt.Sprintf("✂️ %d chunks from %s (%d pages)\n", len(result.Chunks), result.Path, result.TotalPages)
+	text += fmt.Sprintf("📏 Limit: %d %s, overlap %d, structure preset %s\n",
+		result.Limit, result.Unit, result.Overlap, result.Structure.Preset)
+	for _, chunk := range result.Chunks {
+		pages := fmt.Sprintf("page %d", chunk.StartPage)
+		if chunk.EndPage != chunk.StartPage {
+			pages = fmt.Sprintf("pages %d-%d", chunk.StartPage, chunk.EndPage)
+		}
+		text += fmt.Sprintf("\n--- Chunk %d (%s, %d characters, ~%d tokens)", chunk.Order, pages,
+			chunk.Characters, chunk.Tokens)
+		if len(chunk.SectionPath) > 0 {
+			text += " § " + strings.Join(chunk.SectionPath, " > ")
+		}
+		text += " ---\n" + chunk.Text + "\n"
+	}
+	return text
+}
+
+func (s *Server) formatPDFExportBookResult(result *pdf.PDFExportBookResult) string {
+	text := fmt.Sprintf("📚 Exported %q from %s to %s (%d pages)\n", result.Title, result.Path,
+		strings.ToUpper(result.Format), result.Pages)
+	text += fmt.Sprintf("📑 %d chapters:\n", len(result.Chapters))
+	for _, chapter := range result.Chapters {
+		title := chapter.Title
+		if title == "" {
+			title = result.Title
+		}
+		if chapter.EndPage < chapter.StartPage {
+			text += fmt.Sprintf("  • %s → %s (title only)\n", title, chapter.File)
+			continue
+		}
+		text += fmt.Sprintf("  • %s → %s (pages %d-%d)\n", title, chapter.File, chapter.StartPage, chapter.EndPage)
+	}
+	text += "🔗 Pages are anchored as <chapter file>#page-<n>\n"
+	if result.Images > 0 || result.ImagePlaceholders > 0 {
+		text += fmt.Sprintf("🖼️ %d images embedded, %d kept as placeholders\n", result.Images, result.ImagePlaceholders)
+	}
+	text += fmt.Sprintf("🗂️ Format: %s (%d bytes)\n", result.MIMEType, result.Size)
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to: %s\n", result.OutputPath)
+	}
+	return text
+}
+
+// formatOutlineItems renders outline items as an indented tree
+func formatOutlineItems(items []pdf.OutlineItem, indent int) string {
+	text := ""
+	for _, item := range items {
+		text += strings.Repeat("  ", indent) + "• " + item.Title
+		switch {
+		case item.Page > 0:
+			text += " → page " + pageNumber(item.Page, item.PageLabel)
+			if item.Zoom > 0 {
+				text += fmt.Sprintf(" (zoom %.0f%%)", item.Zoom*100)
+			} else if item.DestinationType != "" && item.DestinationType != "XYZ" {
+				text += fmt.Sprintf(" (%s)", item.DestinationType)
+			}
+		case item.URI != "":
+			text += " → " + item.URI
+		}
+		text += "\n"
+		text += formatOutlineItems(item.Children, indent+1)
+	}
+	return text
+}
+
+// densityShades maps density thresholds to shade characters, from lightest to darkest
+var densityShades = []struct {
+	below  float64
+	symbol string
+}{
+	{0.02, "░"},
+	{0.05, "▒"},
+	{0.1, "▓"},
+}
+
+// densitySymbol renders a density grid cell as a shade character
+func densitySymbol(density float64) string {
+	if density == 0 {
+		return "·"
+	}
+	for _, shade := range densityShades {
+		if density < shade.below {
+			return shade.symbol
+		}
+	}
+	return "█"
+}
+
+// formatPDFAttachmentsResult formats the embedded files found in a document
+func (s *Server) formatPDFAttachmentsResult(result *pdf.PDFExtractAttachmentsResult) string {
+	text := fmt.Sprintf("📎 Attachments: %s\n", result.Path)
+	if result.TotalCount == 0 {
+		text += "\nThis document has no embedded files.\n"
+		return text
+	}
+	text += fmt.Sprintf("📦 Files: %d (%d bytes total)\n", result.TotalCount, result.TotalSize)
+	if result.OutputDir != "" {
+		text += fmt.Sprintf("💾 Saved to: %s\n", result.OutputDir)
+	}
+
+	for i, attachment := range result.Attachments {
+		text += fmt.Sprintf("\n%d. %s\n", i+1, attachment.Name)
+		text += fmt.Sprintf("   Type: %s\n", attachment.MIMEType)
+		text += fmt.Sprintf("   Size: %d bytes\n", attachment.Size)
+		if attachment.Source == pdf.AttachmentSourceAnnotation {
+			text += fmt.Sprintf("   Source: annotation on page %s\n", pageNumber(attachment.Page, attachment.PageLabel))
+		} else {
+			text += "   Source: document attachment\n"
+		}
+		if attachment.Description != "" {
+			text += fmt.Sprintf("   Description: %s\n", attachment.Description)
+		}
+		if attachment.ModDate != "" {
+			text += fmt.Sprintf("   Modified: %s\n", attachment.ModDate)
+		} else if attachment.CreationDate != "" {
+			text += fmt.Sprintf("   Created: %s\n", attachment.CreationDate)
+		}
+		if attachment.Checksum != "" {
+			text += fmt.Sprintf("   MD5: %s\n", attachment.Checksum)
+		}
+		if attachment.ChecksumMismatch {
+			text += "   ⚠️  Checksum does not match the value stored in the PDF\n"
+		}
+		if attachment.SavedPath != "" {
+			text += fmt.Sprintf("   Saved: %s\n", attachment.SavedPath)
+		}
+		if attachment.Error != "" {
+			text += fmt.Sprintf("   ❌ Error: %s\n", attachment.Error)
+		}
+		if attachment.Content != "" {
+			text += fmt.Sprintf("   Content (base64): %s\n", attachment.Content)
+		}
+	}
+
+	return text
+}
+
+// formatPDFPortfolioResult formats the members of a portfolio with their fields and text
+func (s *Server) formatPDFPortfolioResult(result *pdf.PDFPortfolioResult) string {
+	text := fmt.Sprintf("📁 Portfolio: %s\n", result.Path)
+	if !result.IsPortfolio {
+		text += "ℹ️  This document is not a portfolio; its embedded files are listed as attachments.\n"
+	} else {
+		text += fmt.Sprintf("🖼️  View: %s\n", result.View)
+		if result.InitialDocument != "" {
+			text += fmt.Sprintf("📌 Shown first: %s\n", result.InitialDocument)
+		}
+	}
+	if result.TotalCount == 0 {
+		text += "\nThis document has no embedded files.\n"
+		return text
+	}
+	text += fmt.Sprintf("📦 Members: %d (%d PDFs)\n", result.TotalCount, result.PDFCount)
+	text += formatPortfolioMembers(result, "")
+	return text
+}
+
+// formatPortfolioMembers lists the members of a portfolio, those of nested portfolios
+// indented under their member
+func formatPortfolioMembers(result *pdf.PDFPortfolioResult, indent string) string {
+	var text string
+	for i, member := range result.Members {
+		text += fmt.Sprintf("\n%s%d. %s (%s, %d bytes)\n", indent, i+1, member.Name, member.MIMEType, member.Size)
+		if member.Description != "" {
+			text += fmt.Sprintf("%s   Description: %s\n", indent, member.Description)
+		}
+		for _, field := range result.Fields {
+			if value, ok := member.Fields[field.Key]; ok && !field.Hidden {
+				text += fmt.Sprintf("%s   %s: %s\n", indent, field.Name, value)
+			}
+		}
+		if member.Reference != "" {
+			text += fmt.Sprintf("%s   Path: %s\n", indent, member.Reference)
+		}
+		if member.Pages > 0 {
+			text += fmt.Sprintf("%s   Pages: %d\n", indent, member.Pages)
+		}
+		if member.Error != "" {
+			text += fmt.Sprintf("%s   ❌ Error: %s\n", indent, member.Error)
+		}
+		if member.Text != "" {
+			suffix := ""
+			if member.Truncated {
+				suffix = " …"
+			}
+			text += fmt.Sprintf("%s   Text: %s%s\n", indent, member.Text, suffix)
+		}
+		if member.Portfolio != nil {
+			text += fmt.Sprintf("%s   📁 Nested portfolio of %d members:\n", indent, member.Portfolio.TotalCount)
+			text += formatPortfolioMembers(member.Portfolio, indent+"   ")
+		}
+	}
+	return text
+}
+
+// formatPDFFindTextResult formats the occurrences of a phrase, grouped by page
+func (s *Server) formatPDFFindTextResult(result *pdf.PDFFindTextResult) string {
+	text := fmt.Sprintf("🔎 Occurrences of %q in %s\n", result.Query, result.Path)
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	if result.TotalMatches == 0 {
+		text += "\nNo occurrences found.\n"
+		return text
+	}
+	text += fmt.Sprintf("📊 Total: %d on %d pages\n", result.TotalMatches, len(result.Pages))
+	if result.Truncated {
+		text += fmt.Sprintf("✂️ Showing the first %d occurrences\n", len(result.Matches))
+	}
+
+	page := 0
+	for _, match := range result.Matches {
+		if match.Page != page {
+			page = match.Page
+			text += fmt.Sprintf("\n📄 Page %s:\n", pageNumber(page, match.PageLabel))
+		}
+		box := match.BoundingBox
+		text += fmt.Sprintf("  • %q at [%.1f, %.1f, %.1f×%.1f]", match.Text, box.X, box.Y, box.Width, box.Height)
+		if len(match.Rects) > 1 {
+			text += fmt.Sprintf(" across %d lines", len(match.Rects))
+		}
+		text += fmt.Sprintf("\n    %s\n", match.Context)
+	}
+	return text
+}
+
+// formatPDFExtractEntitiesResult formats the entities found in a document, grouped by page
+func (s *Server) formatPDFExtractEntitiesResult(result *pdf.PDFExtractEntitiesResult) string {
+	text := fmt.Sprintf("🏷️ Entities in %s\n", result.Path)
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	if len(result.Entities) == 0 {
+		text += "\nNo entities found.\n"
+		return text
+	}
+	types := make([]string, 0, len(result.Counts))
+	for entityType, count := range result.Counts {
+		types = append(types, fmt.Sprintf("%s: %d", entityType, count))
+	}
+	sort.Strings(types)
+	text += fmt.Sprintf("📊 Total: %d (%s)\n", len(result.Entities), strings.Join(types, ", "))
+	text += formatEntities(result.Entities, len(result.Entities))
+	return text
+}
+
+// formatEntities lists entities grouped by page, stopping after limit entities
+func formatEntities(entities []pdf.NamedEntity, limit int) string {
+	text := ""
+	page := 0
+	for i, entity := range entities {
+		if i == limit {
+			text += fmt.Sprintf("  ... and %d more\n", len(entities)-limit)
+			break
+		}
+		if entity.Page != page {
+			page = entity.Page
+			text += fmt.Sprintf("\n📄 Page %s:\n", pageNumber(page, entity.PageLabel))
+		}
+		box := entity.BoundingBox
+		text += fmt.Sprintf("  • %s %q", entity.Type, entity.Text)
+		if entity.Value != entity.Text {
+			text += fmt.Sprintf(" = %s", entity.Value)
+			if entity.Currency != "" {
+				text += " " + entity.Currency
+			}
+		}
+		text += fmt.Sprintf(" at [%.1f, %.1f, %.1f×%.1f] (confidence %.2f)\n",
+			box.X, box.Y, box.Width, box.Height, entity.Confidence)
+	}
+	return text
+}
+
+// formatPDFClassifyDocumentResult formats the candidate types of a document, best first
+func (s *Server) formatPDFClassifyDocumentResult(result *pdf.PDFClassifyDocumentResult) string {
+	text := fmt.Sprintf("🗂️ Document type of %s\n", result.Path)
+	text += fmt.Sprintf("📄 Pages read: %d of %d, scored against %d profiles\n",
+		result.PagesRead, result.TotalPages, result.Profiles)
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	if len(result.Candidates) == 0 {
+		text += "\nNo document type matched.\n"
+		return text
+	}
+	text += "\n"
+	for i, candidate := range result.Candidates {
+		text += fmt.Sprintf("%d. %s (score %.2f, %s)", i+1, candidate.Type, candidate.Score, candidate.Source)
+		if candidate.Description != "" {
+			text += " - " + candidate.Description
+		}
+		text += fmt.Sprintf("\n   Matched: %s\n", strings.Join(candidate.Matched, ", "))
+	}
+	return text
+}
+
+// formatPDFExtractInvoiceResult formats the fields of an invoice or receipt
+func (s *Server) formatPDFExtractInvoiceResult(result *pdf.PDFExtractInvoiceResult) string {
+	text := fmt.Sprintf("🧾 Invoice fields of %s\n", result.Path)
+	if result.DocumentType == "" {
+		text += "🗂️ Document type: none matched\n"
+	} else {
+		text += fmt.Sprintf("🗂️ Document type: %s (score %.2f)\n", result.DocumentType, result.Score)
+	}
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	invoice := result.Invoice
+	if invoice == nil {
+		text += "\nNot an invoice or receipt; set force to read its fields anyway.\n"
+		return text
 	}
 
-	responseText := s.formatPDFStatsDirectoryResult(result)
-	return mcp.NewToolResultText(responseText), nil
+	text += "\n"
+	for _, field := range []struct{ name, value string }{
+		{"Vendor", invoice.Vendor},
+		{"Invoice number", invoice.InvoiceNumber},
+		{"Invoice date", invoice.InvoiceDate},
+		{"Due date", invoice.DueDate},
+		{"Currency", invoice.Currency},
+		{"Subtotal", invoice.Subtotal},
+		{"Tax", invoice.Tax},
+		{"Total", invoice.Total},
+		{"Amount due", invoice.AmountDue},
+	} {
+		if field.value != "" {
+			text += fmt.Sprintf("%s: %s\n", field.name, field.value)
+		}
+	}
+	if len(invoice.LineItems) > 0 {
+		text += fmt.Sprintf("\n📋 Line items (%d):\n", len(invoice.LineItems))
+		for i, item := range invoice.LineItems {
+			text += fmt.Sprintf("%d. %s", i+1, item.Description)
+			if item.Quantity != "" {
+				text += fmt.Sprintf(" × %s", item.Quantity)
+			}
+			if item.UnitPrice != "" {
+				text += fmt.Sprintf(" @ %s", item.UnitPrice)
+			}
+			if item.Amount != "" {
+				text += fmt.Sprintf(" = %s", item.Amount)
+			}
+			text += fmt.Sprintf(" (page %d)\n", item.Page)
+		}
+	}
+	for _, warning := range result.Warnings {
+		text += fmt.Sprintf("⚠️ %s\n", warning)
+	}
+	return text
 }
 
-func (s *Server) handlePDFServerInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	req := pdf.PDFServerInfoRequest{}
-	result, err := s.pdfService.PDFServerInfo(req, s.config.ServerName, s.config.Version, s.config.PDFDirectory)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+// formatPDFExtractTransactionsResult formats the transactions of a bank statement
+func (s *Server) formatPDFExtractTransactionsResult(result *pdf.PDFExtractTransactionsResult) string {
+	text := fmt.Sprintf("🏦 Transactions of %s\n", result.Path)
+	if result.DocumentType == "" {
+		text += "🗂️ Document type: none matched\n"
+	} else {
+		text += fmt.Sprintf("🗂️ Document type: %s (score %.2f)\n", result.DocumentType, result.Score)
+	}
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	statement := result.Statement
+	if statement == nil {
+		text += "\nNot a bank statement; set force to read its transactions anyway.\n"
+		return text
 	}
 
-	responseText := s.formatPDFServerInfoResult(result)
-	return mcp.NewToolResultText(responseText), nil
+	if statement.Currency != "" {
+		text += fmt.Sprintf("💱 Currency: %s\n", statement.Currency)
+	}
+	if statement.OpeningBalance != "" {
+		text += fmt.Sprintf("Opening balance: %s\n", statement.OpeningBalance)
+	}
+	if statement.ClosingBalance != "" {
+		text += fmt.Sprintf("Closing balance: %s\n", statement.ClosingBalance)
+	}
+	text += fmt.Sprintf("Credits: %s, debits: %s\n", statement.TotalCredits, statement.TotalDebits)
+	text += fmt.Sprintf("\n📋 Transactions (%d):\n", len(statement.Transactions))
+	for _, tx := range statement.Transactions {
+		text += fmt.Sprintf("%s  %s  %s", tx.Date, tx.Description, tx.Amount)
+		if tx.Balance != "" {
+			text += fmt.Sprintf("  (balance %s)", tx.Balance)
+		}
+		text += "\n"
+	}
+	for _, warning := range result.Warnings {
+		text += fmt.Sprintf("⚠️ %s\n", warning)
+	}
+	return text
 }
 
-// New structured extraction handlers
-
-func (s *Server) handlePDFExtractStructured(
-	ctx context.Context, request mcp.CallToolRequest,
-) (*mcp.CallToolResult, error) {
-	path, err := request.RequireString("path")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+// formatPDFExtractResumeResult formats the sections of a resume
+func (s *Server) formatPDFExtractResumeResult(result *pdf.PDFExtractResumeResult) string {
+	text := fmt.Sprintf("👤 Resume %s\n", result.Path)
+	if result.DocumentType == "" {
+		text += "🗂️ Document type: none matched\n"
+	} else {
+		text += fmt.Sprintf("🗂️ Document type: %s (score %.2f)\n", result.DocumentType, result.Score)
 	}
-
-	args := request.GetArguments()
-
-	req := pdf.PDFExtractStructuredRequest{
-		Path: path,
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	resume := result.Resume
+	if resume == nil {
+		text += "\nNot a resume; set force to read its sections anyway.\n"
+		return text
 	}
 
-	// Handle optional mode parameter
-	if mode, ok := args["mode"].(string); ok {
-		req.Mode = mode
+	contact := resume.Contact
+	if contact.Name != "" {
+		text += fmt.Sprintf("Name: %s\n", contact.Name)
+	}
+	for _, email := range contact.Emails {
+		text += fmt.Sprintf("📧 %s\n", email)
+	}
+	for _, phone := range contact.Phones {
+		text += fmt.Sprintf("📞 %s\n", phone)
+	}
+	if contact.Address != "" {
+		text += fmt.Sprintf("🏠 %s\n", contact.Address)
+	}
+	for _, link := range contact.Links {
+		text += fmt.Sprintf("🔗 %s\n", link)
+	}
+	if resume.Summary != "" {
+		text += fmt.Sprintf("\n📝 Summary: %s\n", resume.Summary)
 	}
 
-	// Handle optional config parameter (simplified for now)
-	if configStr, ok := args["config"].(string); ok && configStr != "" {
-		// For now, just use default config
-		// TODO: Parse JSON config string when needed
-		req.Config = pdf.ExtractionConfig{
-			ExtractText:        true,
-			IncludeCoordinates: true,
-			IncludeFormatting:  true,
+	text += fmt.Sprintf("\n💼 Experience (%d):\n", len(resume.Experience))
+	for _, job := range resume.Experience {
+		text += fmt.Sprintf("%s, %s", job.Title, job.Company)
+		if job.StartDate != "" || job.EndDate != "" {
+			text += fmt.Sprintf(" (%s – %s)", job.StartDate, job.EndDate)
+		}
+		if job.Location != "" {
+			text += fmt.Sprintf(" [%s]", job.Location)
+		}
+		text += "\n"
+		for _, highlight := range job.Highlights {
+			text += fmt.Sprintf("  • %s\n", highlight)
 		}
 	}
-
-	result, err := s.pdfService.ExtractStructured(req)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	text += fmt.Sprintf("\n🎓 Education (%d):\n", len(resume.Education))
+	for _, school := range resume.Education {
+		text += fmt.Sprintf("%s, %s", school.Degree, school.Institution)
+		if school.EndDate != "" {
+			text += fmt.Sprintf(" (%s)", school.EndDate)
+		}
+		text += "\n"
 	}
-
-	responseText := s.formatPDFExtractResult(result)
-	return mcp.NewToolResultText(responseText), nil
-}
-
-func (s *Server) handlePDFExtractTables(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	return s.handleExtractionRequest(request,
-		func(path string, config pdf.ExtractionConfig) (*pdf.PDFExtractResult, error) {
-			return s.pdfService.ExtractTables(pdf.PDFExtractTablesRequest{Path: path, Config: config})
-		}, pdf.ExtractionConfig{
-			ExtractText:        true,
-			ExtractTables:      true,
-			IncludeCoordinates: true,
-		})
+	if len(resume.Skills) > 0 {
+		text += fmt.Sprintf("\n🛠️ Skills: %s\n", strings.Join(resume.Skills, ", "))
+	}
+	for _, section := range resume.Sections {
+		text += fmt.Sprintf("\n📌 %s:\n", section.Heading)
+		for _, line := range section.Lines {
+			text += line + "\n"
+		}
+	}
+	return text
 }
 
-func (s *Server) handlePDFExtractSemantic(
-	ctx context.Context, request mcp.CallToolRequest,
-) (*mcp.CallToolResult, error) {
-	return s.handleExtractionRequest(request,
-		func(path string, config pdf.ExtractionConfig) (*pdf.PDFExtractResult, error) {
-			return s.pdfService.ExtractSemantic(pdf.PDFExtractSemanticRequest{Path: path, Config: config})
-		}, pdf.ExtractionConfig{
-			ExtractText:        true,
-			IncludeCoordinates: true,
-			IncludeFormatting:  true,
-		})
+// formatPDFRedactResult formats the summary of a redacted document
+func (s *Server) formatPDFRedactResult(result *pdf.PDFRedactResult) string {
+	text := fmt.Sprintf("⬛ Redacted %s\n", result.Path)
+	text += fmt.Sprintf("📄 Pages: %v\n", result.Pages)
+	text += fmt.Sprintf("📐 Areas: %d", len(result.Regions))
+	if result.TermMatches > 0 {
+		text += fmt.Sprintf(" (%d term matches)", result.TermMatches)
+	}
+	if result.Annotations > 0 {
+		text += fmt.Sprintf(" (%d redaction annotations applied)", result.Annotations)
+	}
+	text += "\n"
+	text += fmt.Sprintf("✂️ Removed %d glyphs and %d images\n", result.GlyphsRemoved, result.ImagesRemoved)
+	text += fmt.Sprintf("🗂️ Format: %s (%d bytes)\n", result.MIMEType, result.Size)
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to %s\n", result.OutputPath)
+	}
+	return text
 }
 
-// handleExtractionRequest is a common handler for extraction requests
-func (s *Server) handleExtractionRequest(
-	request mcp.CallToolRequest,
-	handler func(string, pdf.ExtractionConfig) (*pdf.PDFExtractResult, error),
-	defaultConfig pdf.ExtractionConfig,
-) (*mcp.CallToolResult, error) {
-	path, err := request.RequireString("path")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+// formatPDFOptimizeReportResult formats how a document is stored and what optimizing it would save
+func (s *Server) formatPDFAccessibilityReportResult(result *pdf.PDFAccessibilityReportResult) string {
+	text := fmt.Sprintf("♿ Accessibility report for %s (%d of %d pages)\n", result.Path, result.Pages, result.TotalPages)
+	tagged := "no"
+	if result.Tagged {
+		tagged = "yes"
 	}
-
-	args := request.GetArguments()
-	config := defaultConfig
-
-	// Handle optional config parameter (simplified for now)
-	if configStr, ok := args["config"].(string); ok && configStr != "" {
-		// For now, just use default config
-		// TODO: Parse JSON config string when needed
-		config = defaultConfig
+	text += fmt.Sprintf("🏷️ Tagged: %s", tagged)
+	if result.Language != "" {
+		text += fmt.Sprintf(", language %s", result.Language)
 	}
-
-	result, err := handler(path, config)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	if result.Title != "" {
+		text += fmt.Sprintf(", title %q", result.Title)
 	}
-
-	responseText := s.formatPDFExtractResult(result)
-	return mcp.NewToolResultText(responseText), nil
+	text += "\n"
+	text += fmt.Sprintf("🖼️ Figures: %d (%d without alternate text)\n", result.Figures, result.FiguresWithoutAlt)
+	text += fmt.Sprintf("📝 Form fields: %d (%d without labels)\n", result.FormFields, result.UnlabeledFields)
+	if result.ContrastChecked {
+		text += fmt.Sprintf("🎨 Contrast: %d lines sampled with %s\n", result.ContrastSamples, result.Renderer)
+	}
+	text += fmt.Sprintf("📋 %d errors, %d warnings\n", result.Errors, result.Warnings)
+	for _, issue := range result.Issues {
+		icon := "⚠️"
+		if issue.Severity == pdf.AccessSeverityError {
+			icon = "❌"
+		}
+		text += fmt.Sprintf("  %s [%s] ", icon, issue.Rule)
+		if issue.Page > 0 {
+			text += fmt.Sprintf("page %d: ", issue.Page)
+		}
+		text += fmt.Sprintf("%s (%s)\n", issue.Message, issue.Standard)
+	}
+	for _, note := range result.Notes {
+		text += fmt.Sprintf("ℹ️ %s\n", note)
+	}
+	if len(result.FailedPages) > 0 {
+		text += fmt.Sprintf("⚠️ Pages that could not be read: %v\n", result.FailedPages)
+	}
+	return text
 }
 
-func (s *Server) handlePDFExtractComplete(
-	ctx context.Context, request mcp.CallToolRequest,
-) (*mcp.CallToolResult, error) {
-	path, err := request.RequireString("path")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+func (s *Server) formatPDFOptimizeReportResult(result *pdf.PDFOptimizeReportResult) string {
+	text := fmt.Sprintf("🗜️ Optimization report for %s\n", result.Path)
+	text += fmt.Sprintf("📏 Size: %d bytes", result.FileSize)
+	if result.Version != "" {
+		text += fmt.Sprintf(", PDF %s", result.Version)
 	}
-
-	args := request.GetArguments()
-
-	req := pdf.PDFExtractCompleteRequest{
-		Path: path,
+	text += "\n"
+	switch {
+	case result.LinearizationStale:
+		text += "🌐 Linearized, but changed since: fast web view no longer applies\n"
+	case result.Linearized:
+		text += "🌐 Linearized for fast web view\n"
+	default:
+		text += "🌐 Not linearized\n"
 	}
-
-	// Handle optional config parameter (simplified for now)
-	if configStr, ok := args["config"].(string); ok && configStr != "" {
-		// For now, just use default config for complete extraction
-		req.Config = pdf.ExtractionConfig{
-			ExtractText:        true,
-			ExtractImages:      true,
-			ExtractTables:      true,
-			ExtractForms:       true,
-			ExtractAnnotations: true,
-			IncludeCoordinates: true,
-			IncludeFormatting:  true,
+	text += fmt.Sprintf("🧱 Objects: %d (%d unused), %d in %d object streams",
+		result.Objects, result.UnusedObjects, result.ObjectsInStreams, result.ObjectStreams)
+	if result.XRefStream {
+		text += ", cross-reference stream"
+	}
+	text += "\n"
+	text += fmt.Sprintf("🖼️ Images: %d (%d above %d DPI), %d uncompressed streams\n",
+		result.Images, result.HighResolutionImages, pdf.HighResolutionDPI, result.UncompressedStreams)
+	if len(result.DuplicateImages) > 0 {
+		text += fmt.Sprintf("♻️ Duplicate images (%d bytes in extra copies):\n", result.DuplicateImageBytes)
+		for _, group := range result.DuplicateImages {
+			text += fmt.Sprintf("  • %dx%d image stored %d times as objects %v (%d bytes each)\n",
+				group.Width, group.Height, len(group.Objects), group.Objects, group.Size)
 		}
 	}
-
-	result, err := s.pdfService.ExtractComplete(req)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	text += fmt.Sprintf("💾 Estimated size after pdf_optimize: %d bytes (saves %d bytes, %.1f%%)\n",
+		result.EstimatedSize, result.EstimatedSavings, result.SavingsPercent)
+	if len(result.Recommendations) > 0 {
+		text += "\nRecommendations:\n"
+		for _, recommendation := range result.Recommendations {
+			text += fmt.Sprintf("  • %s\n", recommendation)
+		}
 	}
-
-	responseText := s.formatPDFExtractResult(result)
-	return mcp.NewToolResultText(responseText), nil
+	return text
 }
 
-func (s *Server) handlePDFQueryContent(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	path, err := request.RequireString("path")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+// formatPDFOptimizeResult formats the summary of an optimized document
+func (s *Server) formatPDFOptimizeResult(result *pdf.PDFOptimizeResult) string {
+	text := fmt.Sprintf("🗜️ Optimized %s\n", result.Path)
+	text += fmt.Sprintf("📏 %d → %d bytes (saved %d bytes, %.1f%%)\n",
+		result.OriginalSize, result.Size, result.Savings, result.SavingsPercent)
+	text += fmt.Sprintf("♻️ Merged %d duplicate images, compressed %d streams, downsampled %d images\n",
+		result.DuplicateImagesMerged, result.StreamsCompressed, result.ImagesDownsampled)
+	text += fmt.Sprintf("🗂️ Format: %s (%d bytes)\n", result.MIMEType, result.Size)
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to %s\n", result.OutputPath)
 	}
+	return text
+}
 
-	queryStr, err := request.RequireString("query")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+func (s *Server) formatPDFSanitizeResult(result *pdf.PDFSanitizeResult) string {
+	text := fmt.Sprintf("🧼 Sanitized %s\n", result.Path)
+	if len(result.InfoEntries) > 0 {
+		text += fmt.Sprintf("🪪 Info entries removed or replaced: %s\n", strings.Join(result.InfoEntries, ", "))
 	}
-
-	// For now, create a simple query based on the query string
-	// TODO: Parse JSON query string when needed
-	query := pdf.ContentQuery{
-		TextQuery: queryStr,
+	text += fmt.Sprintf("🏷️ Removed %d XMP metadata streams and %d private data dictionaries\n",
+		result.MetadataStreams, result.PrivateData)
+	text += fmt.Sprintf("📜 Removed %d JavaScript and launch actions\n", result.JavaScriptActions)
+	text += fmt.Sprintf("📎 Removed %d embedded files\n", result.EmbeddedFiles)
+	if len(result.HiddenLayers) > 0 {
+		text += fmt.Sprintf("🫥 Hidden layers: %s (%d pieces of content removed)\n",
+			strings.Join(result.HiddenLayers, ", "), result.HiddenContent)
 	}
-
-	req := pdf.PDFQueryContentRequest{
-		Path:  path,
-		Query: query,
+	for _, warning := range result.Warnings {
+		text += fmt.Sprintf("⚠️ %s\n", warning)
 	}
-
-	result, err := s.pdfService.QueryContent(req)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	text += fmt.Sprintf("🗂️ Format: %s (%d bytes)\n", result.MIMEType, result.Size)
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to %s\n", result.OutputPath)
 	}
-
-	responseText := s.formatPDFQueryResult(result)
-	return mcp.NewToolResultText(responseText), nil
+	return text
 }
 
-func (s *Server) handlePDFGetPageInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	path, err := request.RequireString("path")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+func (s *Server) formatWorkspaceListing(result *WorkspaceListing) string {
+	if len(result.Files) == 0 {
+		return "🗃️ The workspace is empty. Give tools a workspace: path as output_dir or output_path to " +
+			"save their outputs here."
 	}
-
-	req := pdf.PDFGetPageInfoRequest{Path: path}
-	result, err := s.pdfService.GetPageInfo(req)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	text := fmt.Sprintf("🗃️ Workspace: %d files, %d bytes", len(result.Files), result.TotalSize)
+	if result.Quota > 0 {
+		text += fmt.Sprintf(" of %d", result.Quota)
 	}
+	text += "\n\n"
+	for _, file := range result.Files {
+		text += fmt.Sprintf("📄 %s (%s, %d bytes, %s)\n", file.Path, file.MIMEType, file.Size, file.Modified)
+	}
+	return text
+}
 
-	responseText := s.formatPDFPageInfoResult(result)
-	return mcp.NewToolResultText(responseText), nil
+func (s *Server) formatWorkspaceOutput(result *WorkspaceOutput) string {
+	text := fmt.Sprintf("📄 %s (%s, %d bytes)\n", result.Path, result.MIMEType, result.Size)
+	if result.Text != "" {
+		return text + "\n" + result.Text
+	}
+	return text + fmt.Sprintf("\n📦 Base64 data: %d characters", len(result.Data))
 }
 
-func (s *Server) handlePDFGetMetadata(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	path, err := request.RequireString("path")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+func (s *Server) formatLoadedDocument(result *pdf.LoadedDocument) string {
+	text := "📥 Loaded PDF"
+	if result.Name != "" {
+		text += fmt.Sprintf(" %s", result.Name)
 	}
+	text += fmt.Sprintf(" (%d bytes)\n", result.Size)
+	text += fmt.Sprintf("🔖 Handle: %s\n", result.Handle)
+	text += fmt.Sprintf("🔑 SHA-256: %s\n", result.SHA256)
+	text += "\nPass the handle as the path of the other tools to read the document."
+	return text
+}
 
-	req := pdf.PDFGetMetadataRequest{Path: path}
-	result, err := s.pdfService.GetMetadata(req)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+func (s *Server) formatPDFFetchURLResult(result *pdf.PDFFetchURLResult) string {
+	text := fmt.Sprintf("🌐 Fetched %s\n", result.URL)
+	if result.FinalURL != result.URL {
+		text += fmt.Sprintf("↪️ Redirected to: %s\n", result.FinalURL)
 	}
+	text += fmt.Sprintf("📄 Local path: %s\n", result.Path)
+	text += fmt.Sprintf("📏 Size: %d bytes\n", result.Size)
+	text += fmt.Sprintf("🔑 SHA-256: %s\n", result.SHA256)
+	text += "\nPass the local path to the other tools to read the document."
+	return text
+}
 
-	responseText := s.formatPDFMetadataResult(result)
-	return mcp.NewToolResultText(responseText), nil
+func (s *Server) formatPDFSecurityScanResult(result *pdf.PDFSecurityScanResult) string {
+	icons := map[string]string{
+		pdf.SecurityRiskNone: "✅", pdf.SecurityRiskLow: "🟡",
+		pdf.SecurityRiskMedium: "🟠", pdf.SecurityRiskHigh: "🛑",
+	}
+	text := fmt.Sprintf("🛡️ Security scan of %s\n", result.Path)
+	text += fmt.Sprintf("%s Risk: %s, %s\n", icons[result.Risk], result.Risk, result.Summary)
+	text += fmt.Sprintf("📜 JavaScript actions: %d (%d run automatically)\n",
+		result.JavaScriptActions, result.AutomaticJavaScript)
+	text += fmt.Sprintf("🚀 Launch actions: %d\n", result.LaunchActions)
+	text += fmt.Sprintf("🔗 URI actions: %d (%d suspicious)\n", result.URIActions, result.SuspiciousURIs)
+	text += fmt.Sprintf("📎 Embedded files: %d (%d executables)\n", result.EmbeddedFiles, result.EmbeddedExecutables)
+	if result.XFA {
+		text += fmt.Sprintf("📋 XFA form with %d scripts\n", result.XFAScripts)
+	}
+	for _, finding := range result.Findings {
+		text += fmt.Sprintf("  %s [%s] %s", icons[finding.Risk], finding.Category, finding.Location)
+		if finding.Page > 0 {
+			text += fmt.Sprintf(" on page %d", finding.Page)
+		}
+		text += fmt.Sprintf(": %s\n", finding.Detail)
+	}
+	return text
 }
 
-// Formatting methods
-func (s *Server) formatPDFSearchDirectoryResult(result *pdf.PDFSearchDirectoryResult) string {
-	text := fmt.Sprintf("Found %d PDF file(s) in directory: %s\n", result.TotalCount, result.Directory)
-	if result.SearchQuery != "" {
-		text += fmt.Sprintf("Search query: %s\n", result.SearchQuery)
+// formatPDFInspectObjectResult formats an inspected object with its dictionary as JSON
+func (s *Server) formatPDFInspectObjectResult(result *pdf.PDFInspectObjectResult) string {
+	text := fmt.Sprintf("🔬 Object %d %d R in %s\n", result.Object, result.Generation, result.Path)
+	if result.ObjectStream > 0 {
+		text += fmt.Sprintf("📍 Stored in object stream %d\n", result.ObjectStream)
+	} else {
+		text += fmt.Sprintf("📍 Stored at offset %d\n", result.Offset)
+	}
+	text += fmt.Sprintf("🧩 Kind: %s", result.Kind)
+	if result.Type != "" {
+		text += fmt.Sprintf(", type %s", result.Type)
+	}
+	if result.Subtype != "" {
+		text += fmt.Sprintf(", subtype %s", result.Subtype)
+	}
+	text += "\n"
+	if value, err := json.MarshalIndent(result.Value, "", "  "); err == nil {
+		text += fmt.Sprintf("\n```json\n%s\n```\n", value)
+	}
+	if result.Truncated {
+		text += "✂️ Values past the inspection limits are shown as \"...\"\n"
 	}
-	text += "\nFiles:\n"
 
-	for i, file := range result.Files {
-		text += fmt.Sprintf("%d. %s\n", i+1, file.Name)
-		text += fmt.Sprintf("   Path: %s\n", file.Path)
-		text += fmt.Sprintf("   Size: %d bytes\n", file.Size)
-		text += fmt.Sprintf("   Modified: %s\n", file.ModifiedTime)
-		if i < len(result.Files)-1 {
-			text += "\n"
+	if stream := result.Stream; stream != nil {
+		text += fmt.Sprintf("\n🌊 Stream: %d bytes stored", stream.Length)
+		if len(stream.Filters) > 0 {
+			text += fmt.Sprintf(", filters %s", strings.Join(stream.Filters, ", "))
+		}
+		if stream.Decoded {
+			text += fmt.Sprintf(", %d bytes decoded", stream.DecodedLength)
+		}
+		text += "\n"
+		if stream.DecodeError != "" {
+			text += fmt.Sprintf("⚠️ %s; data is shown as stored\n", stream.DecodeError)
+		}
+		if stream.Data != "" {
+			text += fmt.Sprintf("📄 Data (%s", stream.DataEncoding)
+			if stream.DataTruncated {
+				text += ", truncated"
+			}
+			text += fmt.Sprintf("):\n```\n%s\n```\n", stream.Data)
 		}
 	}
-
 	return text
 }
 
-func (s *Server) formatPDFStatsDirectoryResult(result *pdf.PDFStatsDirectoryResult) string {
-	text := "PDF Directory Statistics\n"
-	text += fmt.Sprintf("Directory: %s\n", result.Directory)
-	text += fmt.Sprintf("Total PDF files: %d\n", result.TotalFiles)
-	text += fmt.Sprintf("Total size: %d bytes\n", result.TotalSize)
+// formatPDFAnnotateResult formats the summary of an annotated document
+func (s *Server) formatPDFAnnotateResult(result *pdf.PDFAnnotateResult) string {
+	text := fmt.Sprintf("🖍️ Annotated %s\n", result.Path)
+	text += fmt.Sprintf("📄 Pages: %v\n", result.Pages)
+	text += fmt.Sprintf("📝 Annotations: %d\n", len(result.Annotations))
+	for _, annotation := range result.Annotations {
+		text += fmt.Sprintf("  • %s on page %d at (%.1f, %.1f, %.1f×%.1f)", annotation.Type, annotation.Page,
+			annotation.X, annotation.Y, annotation.Width, annotation.Height)
+		if annotation.Text != "" {
+			text += fmt.Sprintf(" around %q", annotation.Text)
+		}
+		if annotation.Comment != "" {
+			text += fmt.Sprintf(": %s", annotation.Comment)
+		}
+		text += "\n"
+	}
+	if len(result.Unmatched) > 0 {
+		text += fmt.Sprintf("⚠️ Not found: %s\n", strings.Join(result.Unmatched, ", "))
+	}
+	text += fmt.Sprintf("🗂️ Format: %s (%d bytes)\n", result.MIMEType, result.Size)
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to %s\n", result.OutputPath)
+	}
+	return text
+}
 
-	if result.TotalFiles > 0 {
-		text += fmt.Sprintf("Average file size: %d bytes\n", result.AverageFileSize)
-		if result.LargestFileName != "" {
-			text += fmt.Sprintf("Largest file: %s (%d bytes)\n", result.LargestFileName, result.LargestFileSize)
+// formatPDFExportFormDataResult formats a document's form fields and the exported data
+func (s *Server) formatPDFExportFormDataResult(result *pdf.PDFExportFormDataResult) string {
+	text := fmt.Sprintf("📋 Form data: %s\n", result.Path)
+	if len(result.Fields) == 0 {
+		text += "\nNo form fields found.\n"
+		return text
+	}
+	text += fmt.Sprintf("📊 Fields: %d (%d exported as %s)\n", len(result.Fields), result.Exported, result.Format)
+	if result.SignaturesExist {
+		text += "🔏 The document is signed"
+		if result.AppendOnly {
+			text += "; changes must be appended to keep the signatures valid"
 		}
-		if result.SmallestFileName != "" {
-			text += fmt.Sprintf("Smallest file: %s (%d bytes)\n", result.SmallestFileName, result.SmallestFileSize)
+		text += "\n"
+	}
+	for _, field := range result.Fields {
+		if field.Type == "group" {
+			text += fmt.Sprintf("  • %s (group of %d fields)\n", field.Name, len(field.Kids))
+			continue
+		}
+		text += fmt.Sprintf("  • %s (%s", field.Name, field.Type)
+		if field.Page > 0 {
+			text += fmt.Sprintf(", page %d", field.Page)
+		}
+		text += ")"
+		switch {
+		case len(field.Values) > 0:
+			text += fmt.Sprintf(": %s", strings.Join(field.Values, ", "))
+		case field.Value != "":
+			text += fmt.Sprintf(": %s", field.Value)
+		}
+		if field.Checked {
+			text += " ☑"
+			if field.ExportValue != "" && field.ExportValue != field.Value {
+				text += fmt.Sprintf(" (exports %s)", field.ExportValue)
+			}
+		}
+		switch {
+		case len(field.Choices) > 0:
+			options := make([]string, len(field.Choices))
+			for i, choice := range field.Choices {
+				options[i] = choice.ExportValue
+				if choice.Display != choice.ExportValue {
+					options[i] += fmt.Sprintf(" (%s)", choice.Display)
+				}
+				if choice.Selected {
+					options[i] += " ✓"
+				}
+			}
+			text += fmt.Sprintf(" [options: %s]", strings.Join(options, ", "))
+		case len(field.Options) > 0:
+			text += fmt.Sprintf(" [options: %s]", strings.Join(field.Options, ", "))
+		}
+		if field.ReadOnly {
+			text += " 🔒"
+		}
+		var layout []string
+		if field.Multiline {
+			layout = append(layout, "multiline")
+		}
+		if field.Password {
+			layout = append(layout, "password")
+		}
+		if field.CombCells > 0 {
+			layout = append(layout, fmt.Sprintf("comb of %d cells", field.CombCells))
 		}
+		if len(field.RichText) > 0 {
+			layout = append(layout, fmt.Sprintf("rich text in %d runs", len(field.RichText)))
+		}
+		if field.Combo {
+			layout = append(layout, "combo box")
+		}
+		if field.Editable {
+			layout = append(layout, "editable")
+		}
+		if field.Sorted {
+			layout = append(layout, "sorted")
+		}
+		if field.MultiSelect {
+			layout = append(layout, "multiple selection")
+		}
+		if field.DoNotSpellCheck {
+			layout = append(layout, "no spell check")
+		}
+		if len(layout) > 0 {
+			text += fmt.Sprintf(" [%s]", strings.Join(layout, ", "))
+		}
+		if field.LockedBy != "" {
+			text += fmt.Sprintf(" 🔒 locked by %s", field.LockedBy)
+		}
+		if sig := field.Signature; sig != nil {
+			text += ": " + formatFormSignature(sig)
+		}
+		if field.Calculated {
+			text += " 🧮 calculated, the stored value may be stale"
+		}
+		if len(field.Actions) > 0 {
+			triggers := make([]string, 0, len(field.Actions))
+			for trigger := range field.Actions {
+				triggers = append(triggers, trigger)
+			}
+			sort.Strings(triggers)
+			text += fmt.Sprintf(" [scripts: %s]", strings.Join(triggers, ", "))
+		}
+		text += "\n"
+	}
+	if len(result.CalculationOrder) > 0 {
+		text += fmt.Sprintf("🧮 Calculation order: %s\n", strings.Join(result.CalculationOrder, " → "))
+	}
+	for _, page := range result.Pages {
+		text += fmt.Sprintf("⇥ Page %d tab order (%s): %s\n",
+			page.Page, page.TabOrder, strings.Join(page.Fields, " → "))
+	}
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to %s\n", result.OutputPath)
+	} else {
+		text += fmt.Sprintf("\n%s", result.Content)
 	}
-
 	return text
 }
 
-func (s *Server) formatPDFStatsFileResult(result *pdf.PDFStatsFileResult) string {
-	text := "PDF File Statistics\n"
-	text += fmt.Sprintf("File: %s\n", result.Path)
-	text += fmt.Sprintf("Size: %d bytes\n", result.Size)
-	text += fmt.Sprintf("Pages: %d\n", result.Pages)
-	text += fmt.Sprintf("Modified: %s\n", result.ModifiedDate)
+func (s *Server) formatPDFExportCommentsResult(result *pdf.PDFExportCommentsResult) string {
+	text := fmt.Sprintf("💬 Comments: %s\n", result.Path)
+	if result.Comments == 0 {
+		text += "\nNo review comments found.\n"
+		return text
+	}
+	text += fmt.Sprintf("📊 %d comments in %d threads (%s)\n", result.Comments, len(result.Threads), result.Format)
+	if len(result.Authors) > 0 {
+		text += fmt.Sprintf("👥 Authors: %s\n", strings.Join(result.Authors, ", "))
+	}
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to %s\n", result.OutputPath)
+	} else {
+		text += fmt.Sprintf("\n%s", result.Content)
+	}
+	return text
+}
 
-	if result.Title != "" {
-		text += fmt.Sprintf("Title: %s\n", result.Title)
+// formatFormSignature describes a signature field's state and what the signer stated
+func formatFormSignature(sig *pdf.FormSignature) string {
+	if !sig.Signed {
+		return "unsigned"
 	}
-	if result.Author != "" {
-		text += fmt.Sprintf("Author: %s\n", result.Author)
+	text := "signed"
+	if sig.Signer != "" {
+		text += " by " + sig.Signer
 	}
-	if result.Subject != "" {
-		text += fmt.Sprintf("Subject: %s\n", result.Subject)
+	if sig.SigningTime != "" {
+		text += " on " + sig.SigningTime
 	}
-	if result.Producer != "" {
-		text += fmt.Sprintf("Producer: %s\n", result.Producer)
+	var details []string
+	if sig.Reason != "" {
+		details = append(details, "reason: "+sig.Reason)
 	}
-	if result.CreatedDate != "" {
-		text += fmt.Sprintf("Created: %s\n", result.CreatedDate)
+	if sig.Location != "" {
+		details = append(details, "location: "+sig.Location)
+	}
+	if sig.Certifies {
+		details = append(details, fmt.Sprintf("certifies the document, permissions %d", sig.Permissions))
+	}
+	switch sig.LockAction {
+	case "All":
+		details = append(details, "locks all fields")
+	case "Include":
+		details = append(details, "locks "+strings.Join(sig.LockFields, ", "))
+	case "Exclude":
+		details = append(details, "locks all fields except "+strings.Join(sig.LockFields, ", "))
+	}
+	if len(details) > 0 {
+		text += " (" + strings.Join(details, "; ") + ")"
 	}
-
 	return text
 }
 
-func (s *Server) formatPDFAssetsFileResult(result *pdf.PDFAssetsFileResult) string {
-	text := fmt.Sprintf("PDF Assets for: %s\n", result.Path)
-	text += fmt.Sprintf("Total images found: %d\n", result.TotalCount)
-
-	if result.TotalCount > 0 {
-		text += "\nImages:\n"
-		for i, img := range result.Images {
-			text += fmt.Sprintf("%d. Page %d: %dx%d pixels, Format: %s",
-				i+1, img.PageNumber, img.Width, img.Height, img.Format)
-			if img.Size > 0 {
-				text += fmt.Sprintf(", Size: %d bytes", img.Size)
-			}
-			text += "\n"
+// formatPDFImportFormDataResult formats the summary of a filled document
+func (s *Server) formatPDFImportFormDataResult(result *pdf.PDFImportFormDataResult) string {
+	text := fmt.Sprintf("✍️ Filled %s from %s form data\n", result.Path, result.Format)
+	text += fmt.Sprintf("📝 Fields filled: %d\n", len(result.Filled))
+	for _, field := range result.Filled {
+		value := field.Value
+		if len(field.Values) > 0 {
+			value = strings.Join(field.Values, ", ")
 		}
+		text += fmt.Sprintf("  • %s = %q\n", field.Name, value)
+	}
+	for _, skipped := range result.Skipped {
+		text += fmt.Sprintf("⚠️ Skipped %s: %s\n", skipped.Name, skipped.Reason)
+	}
+	if len(result.Unmatched) > 0 {
+		text += fmt.Sprintf("⚠️ No such fields: %s\n", strings.Join(result.Unmatched, ", "))
+	}
+	text += fmt.Sprintf("🗂️ Format: %s (%d bytes)\n", result.MIMEType, result.Size)
+	if result.OutputPath != "" {
+		text += fmt.Sprintf("💾 Saved to %s\n", result.OutputPath)
 	}
-
 	return text
 }
 
-func (s *Server) formatPDFServerInfoResult(result *pdf.PDFServerInfoResult) string {
-	text := fmt.Sprintf("📋 %s v%s - Server Information\n", result.ServerName, result.Version)
-	text += fmt.Sprintf("📁 Default Directory: %s\n", result.DefaultDirectory)
-	text += fmt.Sprintf("📏 Max File Size: %d MB\n\n", result.MaxFileSize/(1024*1024))
-
-	// Directory contents
-	if len(result.DirectoryContents) > 0 {
-		text += fmt.Sprintf("📂 Directory Contents (%d PDF files found):\n", len(result.DirectoryContents))
-		for i, file := range result.DirectoryContents {
-			if i >= 10 { // Limit to first 10 files for readability
-				text += fmt.Sprintf("   ... and %d more files\n", len(result.DirectoryContents)-10)
-				break
-			}
-			text += fmt.Sprintf("   %d. %s (%d bytes)\n", i+1, file.Name, file.Size)
-		}
-		text += "\n"
-	} else {
-		text += "📂 Directory Contents: No PDF files found in default directory\n\n"
-	}
-
-	// Available tools
-	text += "🛠️  Available Tools:\n"
-	for _, tool := range result.AvailableTools {
-		text += fmt.Sprintf("\n• %s\n", tool.Name)
-		text += fmt.Sprintf("  Description: %s\n", tool.Description)
-		text += fmt.Sprintf("  Usage: %s\n", tool.Usage)
-		text += fmt.Sprintf("  Parameters: %s\n", tool.Parameters)
+// formatPDFLinksResult formats the links found in a document, grouped by page
+func (s *Server) formatPDFLinksResult(result *pdf.PDFExtractLinksResult) string {
+	text := fmt.Sprintf("🔗 Links: %s\n", result.Path)
+	if result.TotalCount == 0 {
+		text += "\nNo links found.\n"
+		return text
 	}
+	text += fmt.Sprintf("📊 Total: %d (internal: %d, external: %d)\n", result.TotalCount,
+		result.InternalCount, result.ExternalCount)
+
+	page := 0
+	for _, link := range result.Links {
+		if link.Page != page {
+			page = link.Page
+			text += fmt.Sprintf("\n📄 Page %s:\n", pageNumber(page, link.PageLabel))
+		}
 
-	// Supported formats
-	if len(result.SupportedFormats) > 0 {
-		text += "\n🖼️  Supported Image Formats:\n"
-		for _, format := range result.SupportedFormats {
-			text += fmt.Sprintf("  • %s\n", format)
+		text += "  • "
+		switch {
+		case link.URI != "":
+			text += link.URI
+		case link.TargetFile != "" && link.TargetPage > 0:
+			text += fmt.Sprintf("%s, page %d", link.TargetFile, link.TargetPage)
+		case link.TargetFile != "":
+			text += link.TargetFile
+		case link.TargetPage > 0:
+			text += "page " + pageNumber(link.TargetPage, link.TargetPageLabel)
+		case link.NamedDestination != "":
+			text += link.NamedDestination
+		default:
+			text += "(no target)"
+		}
+		if link.NamedDestination != "" && (link.TargetPage > 0 || link.TargetFile != "") {
+			text += fmt.Sprintf(" [%s]", link.NamedDestination)
 		}
+		text += fmt.Sprintf(" (%s) at [%.0f, %.0f, %.0f×%.0f]\n", link.Action,
+			link.BoundingBox.X, link.BoundingBox.Y, link.BoundingBox.Width, link.BoundingBox.Height)
 	}
 
-	// Usage guidance
-	text += "\n" + result.UsageGuidance
-
 	return text
 }
 
-// New formatting methods for structured extraction results
-
-func (s *Server) formatPDFExtractResult(result *pdf.PDFExtractResult) string {
-	text := fmt.Sprintf("📄 PDF Extraction Results: %s\n", result.FilePath)
-	text += fmt.Sprintf("🔧 Mode: %s\n", result.Mode)
-	text += fmt.Sprintf("📖 Pages: %d (processed: %v)\n", result.TotalPages, result.ProcessedPages)
-	text += fmt.Sprintf("🎯 Quality: %s\n", result.Summary.Quality)
-	text += fmt.Sprintf("📊 Total Elements: %d\n\n", result.Summary.TotalElements)
+// formatParseIssues lists parse issues with the page, object, and offset they were found at
+func formatParseIssues(issues []pdf.ParseIssue) string {
+	text := fmt.Sprintf("🩺 Parse Issues (%d):\n", len(issues))
+	for _, issue := range issues {
+		icon := "⚠️ "
+		if issue.Severity == "error" {
+			icon = "❌"
+		}
 
-	// Content type breakdown
-	text += "📋 Content Types Found:\n"
-	for contentType, count := range result.Summary.ContentTypes {
-		text += fmt.Sprintf("  • %s: %d\n", contentType, count)
-	}
-	text += "\n"
+		var location []string
+		if issue.Page > 0 {
+			location = append(location, fmt.Sprintf("page %d", issue.Page))
+		}
+		if issue.Object != "" {
+			location = append(location, "object "+issue.Object)
+		}
+		if issue.Offset > 0 {
+			location = append(location, fmt.Sprintf("offset %d", issue.Offset))
+		}
 
-	// Tables if found
-	if len(result.Tables) > 0 {
-		text += fmt.Sprintf("📊 Tables Found: %d\n", len(result.Tables))
-		for i, table := range result.Tables {
-			text += fmt.Sprintf("  Table %d: %d rows × %d columns (%d cells)\n",
-				i+1, len(table.Rows), len(table.Columns), table.CellCount)
-			if table.HasHeaders {
-				text += "    - Has headers\n"
-			}
-			text += fmt.Sprintf("    - Confidence: %.2f\n", table.Confidence)
+		text += fmt.Sprintf("  %s [%s] %s", icon, issue.Stage, issue.Message)
+		if len(location) > 0 {
+			text += fmt.Sprintf(" (%s)", strings.Join(location, ", "))
 		}
 		text += "\n"
 	}
+	return text + "\n"
+}
 
-	// Page breakdown
-	if len(result.Summary.PageBreakdown) > 0 {
-		text += "📄 Page Breakdown:\n"
-		for _, page := range result.Summary.PageBreakdown {
-			text += fmt.Sprintf("  Page %d: %d elements\n", page.Page, page.Elements)
+func (s *Server) formatPDFCompareSetResult(result *pdf.PDFCompareSetResult) string {
+	text := fmt.Sprintf("🔀 Document Comparison: %d file(s)\n", len(result.Documents))
+	text += fmt.Sprintf("🎯 Cluster Threshold: %.2f\n\n", result.Threshold)
+
+	text += "📁 Documents:\n"
+	for i, doc := range result.Documents {
+		name := filepath.Base(doc.Path)
+		if doc.Error != "" {
+			text += fmt.Sprintf("  %d. %s: error: %s\n", i+1, name, doc.Error)
+			continue
+		}
+		text += fmt.Sprintf("  %d. %s (%d pages, %d words)\n", i+1, name, doc.Pages, doc.Words)
+		text += fmt.Sprintf("     Closest: %s (%.2f), average %.2f",
+			filepath.Base(doc.ClosestPath), doc.ClosestSimilarity, doc.AverageSimilarity)
+		if doc.Cluster > 0 {
+			text += fmt.Sprintf(", cluster %d", doc.Cluster)
+		}
+		if doc.Outlier {
+			text += ", ⚠️ outlier"
 		}
 		text += "\n"
 	}
+	text += "\n"
 
-	// Suggestions
-	if len(result.Summary.Suggestions) > 0 {
-		text += "💡 Suggestions:\n"
-		for _, suggestion := range result.Summary.Suggestions {
-			text += fmt.Sprintf("  • %s\n", suggestion)
+	text += "📊 Similarity Matrix:\n"
+	for i, row := range result.Matrix {
+		text += fmt.Sprintf("  %2d.", i+1)
+		for _, score := range row {
+			text += fmt.Sprintf(" %.2f", score)
 		}
 		text += "\n"
 	}
+	text += "\n"
 
-	// Warnings and errors
-	if len(result.Warnings) > 0 {
-		text += "⚠️  Warnings:\n"
-		for _, warning := range result.Warnings {
-			text += fmt.Sprintf("  • %s\n", warning)
+	if len(result.Clusters) > 0 {
+		text += "🧩 Clusters:\n"
+		for _, cluster := range result.Clusters {
+			names := make([]string, len(cluster.Paths))
+			for i, path := range cluster.Paths {
+				names[i] = filepath.Base(path)
+			}
+			text += fmt.Sprintf("  • Cluster %d (min similarity %.2f): %s\n",
+				cluster.ID, cluster.MinSimilarity, strings.Join(names, ", "))
 		}
 		text += "\n"
 	}
 
-	if len(result.Errors) > 0 {
-		text += "❌ Errors:\n"
-		for _, error := range result.Errors {
-			text += fmt.Sprintf("  • %s\n", error)
+	if len(result.Outliers) > 0 {
+		text += "⚠️ Outliers:\n"
+		for _, path := range result.Outliers {
+			text += fmt.Sprintf("  • %s\n", path)
 		}
 		text += "\n"
 	}
 
-	// Show first few elements as examples
-	if len(result.Elements) > 0 {
-		text += fmt.Sprintf("🔍 Content Elements (showing first %d):\n", minInt(5, len(result.Elements)))
-		for i, element := range result.Elements {
-			if i >= 5 {
-				text += fmt.Sprintf("  ... and %d more elements\n", len(result.Elements)-5)
-				break
-			}
-			text += fmt.Sprintf("  %d. %s on page %d (confidence: %.2f)\n",
-				i+1, element.Type, element.PageNumber, element.Confidence)
-
-			// Show content preview for text elements
-			if element.Type == "text" {
-				if contentStr, ok := element.Content.(string); ok {
-					preview := contentStr
-					if len(preview) > 100 {
-						preview = preview[:100] + "..."
-					}
-					text += fmt.Sprintf("     Content: %s\n", preview)
-				}
-			}
-		}
+	if len(result.Pairs) > 0 {
+		least := result.Pairs[len(result.Pairs)-1]
+		text += fmt.Sprintf("🔎 Least similar pair: %s and %s (%.2f: text %.2f, structure %.2f)\n",
+			filepath.Base(least.PathA), filepath.Base(least.PathB),
+			least.Similarity, least.TextSimilarity, least.StructuralSimilarity)
 	}
 
 	return text
 }
 
-func (s *Server) formatPDFQueryResult(result *pdf.PDFQueryResult) string {
-	text := fmt.Sprintf("🔍 Query Results: %s\n", result.FilePath)
-	text += fmt.Sprintf("📊 Matches Found: %d\n", result.MatchCount)
-	text += fmt.Sprintf("🎯 Average Confidence: %.2f\n\n", result.Summary.Confidence)
-
-	// Query details
-	text += "🔎 Query Details:\n"
-	if len(result.Query.ContentTypes) > 0 {
-		text += fmt.Sprintf("  Content Types: %v\n", result.Query.ContentTypes)
-	}
-	if len(result.Query.Pages) > 0 {
-		text += fmt.Sprintf("  Pages: %v\n", result.Query.Pages)
-	}
-	if result.Query.TextQuery != "" {
-		text += fmt.Sprintf("  Text Query: %s\n", result.Query.TextQuery)
-	}
-	if result.Query.MinConfidence > 0 {
-		text += fmt.Sprintf("  Min Confidence: %.2f\n", result.Query.MinConfidence)
-	}
-	text += "\n"
-
-	// Result breakdown
-	if len(result.Summary.TypeBreakdown) > 0 {
-		text += "📋 Result Breakdown by Type:\n"
-		for contentType, count := range result.Summary.TypeBreakdown {
-			text += fmt.Sprintf("  • %s: %d\n", contentType, count)
+// formatStructureElements lists structure elements as an indented outline, stopping after
+// 30 lines in total
+func formatStructureElements(elements []pdf.StructureElement, indent int, lines *int) string {
+	text := ""
+	for _, element := range elements {
+		if *lines >= 30 {
+			if *lines == 30 {
+				text += strings.Repeat("  ", indent) + "...\n"
+				*lines++
+			}
+			return text
 		}
-		text += "\n"
-	}
+		*lines++
 
-	if len(result.Summary.PageBreakdown) > 0 {
-		text += "📄 Result Breakdown by Page:\n"
-		for page, count := range result.Summary.PageBreakdown {
-			text += fmt.Sprintf("  • Page %d: %d\n", page, count)
+		text += strings.Repeat("  ", indent) + "• " + element.Role
+		content := element.Text
+		if element.AltText != "" {
+			content = "alt: " + element.AltText
 		}
-		text += "\n"
-	}
-
-	// Show matching elements
-	if len(result.Elements) > 0 {
-		text += fmt.Sprintf("🎯 Matching Elements (showing first %d):\n", minInt(10, len(result.Elements)))
-		for i, element := range result.Elements {
-			if i >= 10 {
-				text += fmt.Sprintf("  ... and %d more matches\n", len(result.Elements)-10)
-				break
-			}
-			text += fmt.Sprintf("  %d. %s on page %d (confidence: %.2f)\n",
-				i+1, element.Type, element.PageNumber, element.Confidence)
+		if len(content) > 100 {
+			content = content[:100] + "..."
+		}
+		if content != "" {
+			text += ": " + content
 		}
+		if element.Page > 0 {
+			text += fmt.Sprintf(" (page %d)", element.Page)
+		}
+		text += "\n" + formatStructureElements(element.Children, indent+1, lines)
 	}
-
 	return text
 }
 
-func (s *Server) formatPDFPageInfoResult(result *pdf.PDFPageInfoResult) string {
-	text := fmt.Sprintf("📄 Page Information: %s\n", result.FilePath)
-	text += fmt.Sprintf("📖 Total Pages: %d\n\n", len(result.Pages))
-
-	for _, page := range result.Pages {
-		text += fmt.Sprintf("Page %d:\n", page.Number)
-		text += fmt.Sprintf("  Dimensions: %.1f × %.1f pts\n", page.Width, page.Height)
-		if page.Rotation != 0 {
-			text += fmt.Sprintf("  Rotation: %d°\n", page.Rotation)
-		}
-		text += fmt.Sprintf("  Media Box: (%.1f, %.1f) to (%.1f, %.1f)\n",
-			page.MediaBox.X, page.MediaBox.Y,
-			page.MediaBox.X+page.MediaBox.Width, page.MediaBox.Y+page.MediaBox.Height)
-		text += "\n"
+// pageNumber formats a page number with the label a viewer shows for the page, when the
+// label differs from the number
+func pageNumber(number int, label string) string {
+	if label == "" || label == strconv.Itoa(number) {
+		return strconv.Itoa(number)
 	}
-
-	return text
+	return fmt.Sprintf("%d [%s]", number, label)
 }
 
-func (s *Server) formatPDFMetadataResult(result *pdf.PDFMetadataResult) string {
-	text := fmt.Sprintf("📋 Document Metadata: %s\n\n", result.FilePath)
-
-	metadata := result.Metadata
-
-	if metadata.Title != "" {
-		text += fmt.Sprintf("📖 Title: %s\n", metadata.Title)
-	}
-	if metadata.Author != "" {
-		text += fmt.Sprintf("👤 Author: %s\n", metadata.Author)
-	}
-	if metadata.Subject != "" {
-		text += fmt.Sprintf("📝 Subject: %s\n", metadata.Subject)
-	}
-	if metadata.Creator != "" {
-		text += fmt.Sprintf("🛠️ Creator: %s\n", metadata.Creator)
-	}
-	if metadata.Producer != "" {
-		text += fmt.Sprintf("🏭 Producer: %s\n", metadata.Producer)
-	}
-	if metadata.CreationDate != "" {
-		text += fmt.Sprintf("📅 Created: %s\n", metadata.CreationDate)
+// formatPageSelection describes the pages chosen for a first_pages/last_pages request
+func formatPageSelection(selection *pdf.PageSelection) string {
+	var windows []string
+	if selection.FirstPages > 0 {
+		windows = append(windows, fmt.Sprintf("first %d", selection.FirstPages))
 	}
-	if metadata.ModificationDate != "" {
-		text += fmt.Sprintf("📅 Modified: %s\n", metadata.ModificationDate)
+	if selection.LastPages > 0 {
+		windows = append(windows, fmt.Sprintf("last %d", selection.LastPages))
 	}
-	if len(metadata.Keywords) > 0 {
-		text += fmt.Sprintf("🏷️ Keywords: %v\n", metadata.Keywords)
+
+	pages := make([]string, len(selection.Pages))
+	for i, page := range selection.Pages {
+		pages[i] = pageNumber(page, selection.Labels[page])
 	}
-	if metadata.Version != "" {
-		text += fmt.Sprintf("📄 PDF Version: %s\n", metadata.Version)
+	text := fmt.Sprintf("[%s] of %d", strings.Join(pages, " "), selection.TotalPages)
+	if len(windows) > 0 {
+		text += fmt.Sprintf(" (%s)", strings.Join(windows, ", "))
 	}
-	if metadata.PageLayout != "" {
-		text += fmt.Sprintf("📐 Page Layout: %s\n", metadata.PageLayout)
+	if len(selection.Extended) > 0 {
+		text += fmt.Sprintf(", extended to section boundaries with pages %v", selection.Extended)
 	}
-	if metadata.PageMode != "" {
-		text += fmt.Sprintf("🖥️ Page Mode: %s\n", metadata.PageMode)
+	return text
+}
+
+// formatRegion describes a page region by its corners in PDF points
+func formatRegion(region pdf.Rectangle) string {
+	return fmt.Sprintf("(%.1f, %.1f) to (%.1f, %.1f) pt", region.X, region.Y,
+		region.X+region.Width, region.Y+region.Height)
+}
+
+// formatPreview lists the pages sampled for a preview and what the element budget left out
+func formatPreview(preview *pdf.PreviewSelection) string {
+	pages := make([]string, len(preview.Pages))
+	for i, page := range preview.Pages {
+		pages[i] = fmt.Sprintf("%s (%s)", pageNumber(page.Page, page.PageLabel), strings.Join(page.Reasons, ", "))
 	}
-	if metadata.Encrypted {
-		text += "🔒 Document is encrypted\n"
+	text := fmt.Sprintf("🔭 Preview: %d of %d pages: %s\n", len(preview.Pages), preview.TotalPages,
+		strings.Join(pages, "; "))
+	text += fmt.Sprintf("🧮 Element Budget: %d", preview.ElementBudget)
+	if preview.ElementsOmitted > 0 {
+		text += fmt.Sprintf(" (%d elements omitted; extract the pages of interest for everything)",
+			preview.ElementsOmitted)
 	}
+	return text + "\n"
+}
 
-	if len(metadata.CustomProperties) > 0 {
-		text += "\n🏷️ Custom Properties:\n"
-		for key, value := range metadata.CustomProperties {
-			text += fmt.Sprintf("  • %s: %s\n", key, value)
-		}
+// formatEscalation reports the escalation policy outcome, or nothing when no policy is configured
+func formatEscalation(escalation *pdf.QualityEscalation) string {
+	if escalation == nil {
+		return ""
 	}
 
+	text := fmt.Sprintf("🧪 Decode Quality: %.2f\n", escalation.DecodeQuality)
+	if escalation.NeedsHuman {
+		text += "🚩 NEEDS HUMAN REVIEW: extracted text is below the configured quality threshold\n"
+	}
+	for _, warning := range escalation.Warnings {
+		text += fmt.Sprintf("⚠️  WARNING: %s\n", warning)
+	}
 	return text
 }
 
@@ -942,6 +5301,18 @@ func minInt(a, b int) int {
 
 // Run starts the MCP server in the configured mode
 func (s *Server) Run(ctx context.Context) error {
+	defer s.workspace.cleanup()
+	defer s.pdfService.Close()
+	if s.config.MetricsAddress != "" {
+		stop, err := s.serveMetrics(s.config.MetricsAddress)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+	if s.config.WatchPoll > 0 {
+		go s.watchResources(ctx, s.config.WatchPoll)
+	}
 	if s.config.IsServerMode() {
 		return s.runServerMode(ctx)
 	} else {
@@ -951,10 +5322,7 @@ func (s *Server) Run(ctx context.Context) error {
 
 // runStdioMode runs the server in stdio mode
 func (s *Server) runStdioMode(_ context.Context) error {
-	if s.config.IsDebug() {
-		log.Printf("Starting PDF MCP server in stdio mode")
-		log.Printf("PDF directory: %s", s.config.PDFDirectory)
-	}
+	logger.Debug("starting PDF MCP server in stdio mode", "dir", s.config.PDFDirectory)
 
 	// Use the mark3labs/mcp-go server.ServeStdio function
 	if err := server.ServeStdio(s.mcpServer); err != nil {
@@ -967,7 +5335,6 @@ func (s *Server) runStdioMode(_ context.Context) error {
 func (s *Server) runServerMode(ctx context.Context) error {
 	// For now, we'll just use stdio mode since the mark3labs library
 	// handles the transport differently
-	log.Printf("Server mode not yet implemented with mark3labs/mcp-go")
-	log.Printf("Falling back to stdio mode")
+	logger.Warn("server mode not yet implemented with mark3labs/mcp-go, falling back to stdio mode")
 	return s.runStdioMode(ctx)
 }
@@ -2,6 +2,9 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -483,3 +486,37 @@ func TestServer_Run_MultipleShutdowns(t *testing.T) {
 		}
 	}
 }
+
+func TestServer_Run_RemovesLoadedDocuments(t *testing.T) {
+	cfg := &config.Config{
+		Mode:         "stdio",
+		PDFDirectory: t.TempDir(),
+		MaxFileSize:  100 * 1024 * 1024,
+		ServerName:   "test-server",
+		Version:      "1.0.0",
+	}
+	pdfService := pdf.NewService(cfg.MaxFileSize)
+	server, err := NewServer(cfg, pdfService)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	source := filepath.Join(cfg.PDFDirectory, "loaded.pdf")
+	writeTextPDF(t, source, "Loaded")
+	data, err := os.ReadFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := pdfService.LoadBytes(pdf.PDFLoadBytesRequest{Content: base64.StdEncoding.EncodeToString(data)})
+	if err != nil {
+		t.Fatalf("LoadBytes() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = server.Run(ctx)
+
+	if _, err := os.Stat(filepath.Dir(doc.Path)); !os.IsNotExist(err) {
+		t.Errorf("directory of loaded documents remains after Run() returned: %v", err)
+	}
+}
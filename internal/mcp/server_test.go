@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -255,6 +256,76 @@ func TestServer_HandlePDFStatsDirectory(t *testing.T) {
 	}
 }
 
+func TestServer_ResponseFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), make([]byte, 512), 0o644); err != nil {
+			t.Fatalf("failed to create test file %s: %v", name, err)
+		}
+	}
+
+	cfg := &config.Config{
+		Mode:         "stdio",
+		PDFDirectory: tempDir,
+		Version:      "1.0.0",
+		ServerName:   "test-server",
+		MaxFileSize:  1024 * 1024,
+	}
+	server, err := NewServer(cfg, pdf.NewService(cfg.MaxFileSize))
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	newRequest := func(format string) mcp.CallToolRequest {
+		return mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Arguments: map[string]interface{}{
+					"directory":       tempDir,
+					"response_format": format,
+				},
+			},
+		}
+	}
+
+	t.Run("json", func(t *testing.T) {
+		result, err := server.handlePDFStatsDirectory(context.Background(), newRequest("json"))
+		if err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("unexpected error result: %s", extractTextFromResult(result))
+		}
+
+		var stats pdf.PDFStatsDirectoryResult
+		if err := json.Unmarshal([]byte(extractTextFromResult(result)), &stats); err != nil {
+			t.Fatalf("response is not valid JSON: %v", err)
+		}
+		if stats.TotalFiles != 2 || stats.TotalSize != 1024 {
+			t.Errorf("stats = %+v, want 2 files totalling 1024 bytes", stats)
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		result, err := server.handlePDFStatsDirectory(context.Background(), newRequest("markdown"))
+		if err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		if resultText := extractTextFromResult(result); !strings.Contains(resultText, "Total PDF files: 2") {
+			t.Errorf("content should mention 2 PDF files, got: %s", resultText)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		result, err := server.handlePDFStatsDirectory(context.Background(), newRequest("xml"))
+		if err != nil {
+			t.Fatalf("handler failed: %v", err)
+		}
+		if !result.IsError || !strings.Contains(extractTextFromResult(result), "invalid response_format") {
+			t.Errorf("expected invalid response_format error, got: %s", extractTextFromResult(result))
+		}
+	})
+}
+
 func TestServer_DefaultDirectory(t *testing.T) {
 	// Create temp directory
 	tempDir, err := os.MkdirTemp("", "mcp_default_test")
@@ -474,3 +545,27 @@ func extractTextFromResult(result *mcp.CallToolResult) string {
 
 	return ""
 }
+
+func TestServer_Health(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server"}, pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+
+	result := callTool(t, s, "pdf_health", map[string]any{})
+	text := extractTextFromResult(result)
+	if result.IsError || !strings.Contains(text, "Health: pass") || !strings.Contains(text, "self_test") {
+		t.Errorf("pdf_health = %q", text)
+	}
+
+	result = callTool(t, s, "pdf_health", map[string]any{"response_format": "json"})
+	var health pdf.PDFHealthResult
+	if err := json.Unmarshal([]byte(extractTextFromResult(result)), &health); err != nil {
+		t.Fatalf("pdf_health returned %q: %v", extractTextFromResult(result), err)
+	}
+	if health.Status != pdf.HealthPass || len(health.Checks) != 5 ||
+		health.Checks[0].Detail != dir+" is readable (0 PDF files)" {
+		t.Errorf("pdf_health = %+v", health)
+	}
+}
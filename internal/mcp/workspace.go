@@ -0,0 +1,203 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+)
+
+// workspacePrefix starts the paths of files in a session's workspace, which tools accept
+// wherever they take a path or an output location
+const workspacePrefix = "workspace:"
+
+// defaultSession names the session of requests that arrive outside a client session
+const defaultSession = "default"
+
+// workspace keeps a temporary directory for each client session, in which tools write their
+// outputs when given workspace: paths. Directories live under one root created on first use,
+// and are removed when their session ends and all of them when the server stops.
+type workspace struct {
+	mu    sync.Mutex
+	root  string
+	quota int64 // Bytes each session's directory may hold before writes to it are refused; 0 means no limit
+}
+
+// WorkspaceFile is a file in a session's workspace
+type WorkspaceFile struct {
+	Path     string `json:"path"`      // workspace: path to give tools
+	File     string `json:"file"`      // Location on disk
+	Size     int64  `json:"size"`      // Size in bytes
+	Modified string `json:"modified"`  // Last modification time, RFC 3339
+	MIMEType string `json:"mime_type"` // Type guessed from the extension
+}
+
+// WorkspaceListing lists the files of a session's workspace
+type WorkspaceListing struct {
+	Directory string          `json:"directory"`
+	Files     []WorkspaceFile `json:"files"`
+	TotalSize int64           `json:"total_size"`
+	Quota     int64           `json:"quota,omitempty"`
+}
+
+// WorkspaceOutput is the content of a file in a session's workspace
+type WorkspaceOutput struct {
+	WorkspaceFile
+	Text string `json:"text,omitempty"` // Content of text files
+	Data string `json:"data,omitempty"` // Base64-encoded content of other files
+}
+
+// sessionDirectory returns the workspace directory of the session making a request,
+// creating it when create is set
+func (w *workspace) sessionDirectory(ctx context.Context, create bool) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.root == "" {
+		if !create {
+			return "", nil
+		}
+		root, err := os.MkdirTemp("", "mcp-pdf-reader-workspace-")
+		if err != nil {
+			return "", fmt.Errorf("cannot create workspace: %w", err)
+		}
+		w.root = root
+	}
+	dir := filepath.Join(w.root, sessionKey(sessionID(ctx)))
+	if create {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return "", fmt.Errorf("cannot create workspace: %w", err)
+		}
+	}
+	return dir, nil
+}
+
+// resolve maps a workspace: path to its file in the requesting session's directory. Paths
+// may name subdirectories but cannot leave the directory.
+func (w *workspace) resolve(ctx context.Context, name string) (string, error) {
+	rel := path.Clean("/" + strings.TrimPrefix(name, workspacePrefix))
+	dir, err := w.sessionDirectory(ctx, true)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), nil
+}
+
+// checkQuota refuses writes to a session's directory once its files reach the quota
+func (w *workspace) checkQuota(ctx context.Context) error {
+	if w.quota <= 0 {
+		return nil
+	}
+	listing, err := w.list(ctx)
+	if err != nil {
+		return err
+	}
+	if listing.TotalSize >= w.quota {
+		return pdferrors.New(pdferrors.TooLarge, "workspace is full: its files take %d bytes (quota: %d bytes); "+
+			"read them with pdf_get_output and start a new session to free it", listing.TotalSize, w.quota)
+	}
+	return nil
+}
+
+// list describes the files of the requesting session's directory, newest first
+func (w *workspace) list(ctx context.Context) (*WorkspaceListing, error) {
+	dir, err := w.sessionDirectory(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	listing := &WorkspaceListing{Directory: dir, Files: []WorkspaceFile{}, Quota: w.quota}
+	if dir == "" {
+		return listing, nil
+	}
+	modified := map[string]time.Time{}
+	err = filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil //nolint:nilerr // Removed during the walk
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		listing.Files = append(listing.Files, describeWorkspaceFile(workspacePrefix+filepath.ToSlash(rel), file, info))
+		listing.TotalSize += info.Size()
+		modified[file] = info.ModTime()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list workspace: %w", err)
+	}
+	sort.SliceStable(listing.Files, func(i, j int) bool {
+		return modified[listing.Files[i].File].After(modified[listing.Files[j].File])
+	})
+	return listing, nil
+}
+
+// describeWorkspaceFile describes a file of a workspace
+func describeWorkspaceFile(name, file string, info os.FileInfo) WorkspaceFile {
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(file)))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return WorkspaceFile{
+		Path:     name,
+		File:     file,
+		Size:     info.Size(),
+		Modified: info.ModTime().UTC().Format(time.RFC3339),
+		MIMEType: mimeType,
+	}
+}
+
+// removeSession removes the directory of a session that ended
+func (w *workspace) removeSession(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.root != "" {
+		os.RemoveAll(filepath.Join(w.root, sessionKey(id)))
+	}
+}
+
+// cleanup removes every session's directory
+func (w *workspace) cleanup() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.root != "" {
+		os.RemoveAll(w.root)
+		w.root = ""
+	}
+}
+
+// sessionID names the client session of a request
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil && session.SessionID() != "" {
+		return session.SessionID()
+	}
+	return defaultSession
+}
+
+// sessionKey turns a session ID, which clients may choose, into a safe directory name
+func sessionKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/config"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf"
+)
+
+// listOutputs lists the workspace through the protocol
+func listOutputs(t *testing.T, s *Server) WorkspaceListing {
+	t.Helper()
+	result := callTool(t, s, "pdf_list_outputs", map[string]any{"response_format": "json"})
+	var listing WorkspaceListing
+	if err := json.Unmarshal([]byte(extractTextFromResult(result)), &listing); err != nil {
+		t.Fatalf("pdf_list_outputs returned %q: %v", extractTextFromResult(result), err)
+	}
+	return listing
+}
+
+func TestServer_Workspace(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	writeTextPDF(t, report, "Quarterly report")
+	s, err := NewServer(&config.Config{PDFDirectory: dir, ServerName: "test-server", MaxFileSize: 1024 * 1024},
+		pdf.NewService(1024*1024))
+	if err != nil {
+		t.Fatalf("NewServer() unexpected error = %v", err)
+	}
+	t.Cleanup(s.workspace.cleanup)
+	if listing := listOutputs(t, s); len(listing.Files) != 0 {
+		t.Errorf("pdf_list_outputs before any output = %+v, want none", listing)
+	}
+
+	// Outputs written to the workspace are listed, readable by other tools, and retrievable
+	result := callTool(t, s, "pdf_sanitize", map[string]any{"path": report, "output_dir": "workspace:shared"})
+	if result.IsError {
+		t.Fatalf("pdf_sanitize into the workspace = %q", extractTextFromResult(result))
+	}
+	listing := listOutputs(t, s)
+	if len(listing.Files) != 1 || !strings.HasPrefix(listing.Files[0].Path, "workspace:shared/") ||
+		listing.Files[0].MIMEType != "application/pdf" || listing.TotalSize == 0 {
+		t.Fatalf("pdf_list_outputs = %+v, want the sanitized copy", listing)
+	}
+	output := listing.Files[0].Path
+	result = callTool(t, s, "pdf_read_file", map[string]any{"path": output})
+	if result.IsError || !strings.Contains(extractTextFromResult(result), "Quarterly report") {
+		t.Errorf("pdf_read_file of %s = %q", output, extractTextFromResult(result))
+	}
+	result = callTool(t, s, "pdf_get_output", map[string]any{"path": output, "response_format": "json"})
+	var retrieved WorkspaceOutput
+	if err := json.Unmarshal([]byte(extractTextFromResult(result)), &retrieved); err != nil || retrieved.Data == "" {
+		t.Errorf("pdf_get_output of %s = %q, %v, want its data", output, extractTextFromResult(result), err)
+	}
+
+	// Paths cannot leave the workspace
+	for _, path := range []string{"workspace:../../etc/passwd", report, "workspace:shared"} {
+		if result := callTool(t, s, "pdf_get_output", map[string]any{"path": path}); !result.IsError {
+			t.Errorf("pdf_get_output of %s = %q, want an error", path, extractTextFromResult(result))
+		}
+	}
+	escaped, err := s.workspace.resolve(context.Background(), "workspace:../../outside.pdf")
+	if err != nil || !strings.HasPrefix(escaped, listing.Directory+string(filepath.Separator)) {
+		t.Errorf("resolve() of an escaping path = %q, %v, want it inside %s", escaped, err, listing.Directory)
+	}
+
+	// Outputs to a full workspace are refused
+	s.workspace.quota = listing.TotalSize
+	result = callTool(t, s, "pdf_sanitize", map[string]any{"path": report, "output_dir": "workspace:again"})
+	if !result.IsError || !strings.Contains(extractTextFromResult(result), "workspace is full") {
+		t.Errorf("pdf_sanitize into a full workspace = %q", extractTextFromResult(result))
+	}
+
+	// Ending the session or stopping the server removes the files
+	s.workspace.removeSession(defaultSession)
+	if listing := listOutputs(t, s); len(listing.Files) != 0 {
+		t.Errorf("pdf_list_outputs after the session ended = %+v", listing)
+	}
+	root := s.workspace.root
+	s.workspace.cleanup()
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Errorf("workspace %s remains after cleanup: %v", root, err)
+	}
+}
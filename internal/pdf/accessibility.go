@@ -0,0 +1,459 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// Accessibility rules, each named after what it checks
+const (
+	AccessRuleTagged          = "tagged"
+	AccessRuleUntaggedContent = "untagged-content"
+	AccessRuleLanguage        = "document-language"
+	AccessRuleTitle           = "document-title"
+	AccessRuleAltText         = "figure-alt-text"
+	AccessRuleReadingOrder    = "reading-order"
+	AccessRuleFormLabel       = "form-field-label"
+	AccessRuleContrast        = "color-contrast"
+	AccessRuleImageOnly       = "image-only-page"
+)
+
+// Accessibility issue severities: errors fail WCAG or PDF/UA, warnings are likely to trouble
+// readers and should be checked by hand
+const (
+	AccessSeverityError   = "error"
+	AccessSeverityWarning = "warning"
+)
+
+// Accessibility audit constants
+const (
+	// contrastDPI is the resolution pages are rendered at to sample contrast, fine enough for
+	// body text stems to cover whole pixels
+	contrastDPI = 150
+	// minTextContrast and minLargeTextContrast are the WCAG 1.4.3 contrast minimums for
+	// text and for large text, 18 points and up
+	minTextContrast      = 4.5
+	minLargeTextContrast = 3.0
+	largeTextSize        = 18.0
+	// contrastMinLetters is the fewest letters a line needs to be sampled
+	contrastMinLetters = 3
+	// contrastInkShare is the smallest share of a line's pixels read as a color of its own,
+	// so stray anti-aliased pixels are not taken for the text
+	contrastInkShare = 0.005
+	// readingOrderKeyLength is how much of the start of a tagged element is looked for in the
+	// page's visual reading order
+	readingOrderKeyLength = 24
+)
+
+// AccessibilityAuditor reports what keeps PDFs from being read with assistive technology
+type AccessibilityAuditor struct {
+	maxFileSize int64
+	validator   *Validator
+	renderer    *Renderer
+}
+
+// NewAccessibilityAuditor creates a new accessibility auditor with the specified constraints
+func NewAccessibilityAuditor(maxFileSize int64) *AccessibilityAuditor {
+	return &AccessibilityAuditor{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+		renderer:    NewRenderer(maxFileSize),
+	}
+}
+
+// accessibilityCatalog holds the catalog entries an accessibility audit reads
+type accessibilityCatalog struct {
+	language     string
+	marked       bool // MarkInfo says the document is tagged
+	displayTitle bool // Viewers show the title rather than the file name
+}
+
+// Report audits a document against the WCAG success criteria and PDF/UA clauses that can be
+// checked from the file: tagging and untagged content, the document language and title,
+// alternate text on figures, the tagged reading order against the visual one, labels on form
+// fields, and, when requested and a renderer is installed, the contrast of text with its
+// background in rendered pages.
+func (a *AccessibilityAuditor) Report(
+	ctx context.Context, req PDFAccessibilityReportRequest,
+) (*PDFAccessibilityReportResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := core.OpenShared(ctx, req.Path, core.Options{Validate: a.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	numbering := extraction.NewPageNumbering(doc.Reader)
+	pages := req.Pages
+	if len(pages) == 0 {
+		for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+			pages = append(pages, pageNum)
+		}
+	}
+	for _, pageNum := range pages {
+		if pageNum < 1 || pageNum > numbering.Count() {
+			return nil, pageOutOfRange(pageNum, numbering.Count())
+		}
+	}
+
+	result := &PDFAccessibilityReportResult{
+		Path:       req.Path,
+		TotalPages: numbering.Count(),
+		Pages:      len(pages),
+		Issues:     []AccessibilityIssue{},
+	}
+	add := func(rule, severity string, page int, standard, format string, args ...interface{}) {
+		result.Issues = append(result.Issues, accessibilityIssue(rule, severity, page, standard, format, args...))
+	}
+
+	catalog := readAccessibilityCatalog(doc.Reader)
+	tree, _ := extraction.ReadStructureTree(doc.Reader, numbering, pages)
+	result.Tagged = tree != nil
+	result.Language = catalog.language
+	if metadata, _ := extraction.ReadMetadata(doc.Reader, ""); metadata != nil {
+		result.Title = strings.TrimSpace(metadata.Title)
+	}
+
+	switch {
+	case !result.Tagged:
+		add(AccessRuleTagged, AccessSeverityError, 0, "PDF/UA 7.1, WCAG 1.3.1",
+			"document is not tagged: assistive technology cannot tell its headings, lists, tables, and figures apart")
+	case !catalog.marked:
+		add(AccessRuleTagged, AccessSeverityWarning, 0, "PDF/UA 7.1",
+			"document has a structure tree but its MarkInfo does not mark it as tagged")
+	}
+	if result.Language == "" {
+		add(AccessRuleLanguage, AccessSeverityError, 0, "PDF/UA 7.2, WCAG 3.1.1",
+			"document language is not set, so screen readers may read it with the wrong voice")
+	}
+	switch {
+	case result.Title == "":
+		add(AccessRuleTitle, AccessSeverityError, 0, "PDF/UA 7.1, WCAG 2.4.2", "document has no title")
+	case !catalog.displayTitle:
+		add(AccessRuleTitle, AccessSeverityWarning, 0, "PDF/UA 7.1",
+			"viewers show the file name rather than the title, as DisplayDocTitle is not set")
+	}
+
+	var figures []extraction.StructureElement
+	if tree != nil {
+		figures = structureFigures(tree.Elements)
+	}
+	for _, figure := range figures {
+		result.Figures++
+		if figure.AltText == "" && figure.ActualText == "" {
+			result.FiguresWithoutAlt++
+			add(AccessRuleAltText, AccessSeverityError, figure.Page, "PDF/UA 7.3, WCAG 1.1.1",
+				"figure has no alternate text")
+		}
+	}
+
+	lines := make(map[int][]pageLine, len(pages))
+	rasters := make(map[int]pageRaster, len(pages))
+	for _, pageNum := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pageLines, _, raster, err := readingOrderPage(numbering, pageNum)
+		if err != nil {
+			result.FailedPages = append(result.FailedPages, pageNum)
+			continue
+		}
+		lines[pageNum], rasters[pageNum] = pageLines, raster
+
+		if result.Tagged {
+			marking, err := extraction.PageContentMarking(numbering.Page(pageNum))
+			if err == nil && marking.Untagged > 0 {
+				result.UntaggedPages = append(result.UntaggedPages, pageNum)
+				add(AccessRuleUntaggedContent, AccessSeverityError, pageNum, "PDF/UA 7.1",
+					"%d pieces of text or graphics are drawn outside tags and are not marked as artifacts",
+					marking.Untagged)
+			}
+			if misplaced := misplacedElements(tree.Elements, pageNum, pageLines); misplaced > 0 {
+				add(AccessRuleReadingOrder, AccessSeverityWarning, pageNum, "WCAG 1.3.2",
+					"%d tagged elements are read out of their visual order", misplaced)
+			}
+		}
+		if len(pageLines) == 0 {
+			if images, err := extraction.PageImagePlacements(numbering.Page(pageNum)); err == nil && len(images) > 0 {
+				add(AccessRuleImageOnly, AccessSeverityWarning, pageNum, "WCAG 1.4.5",
+					"page has images but no text; if it is scanned, its text cannot be read without OCR")
+			}
+		}
+	}
+
+	for _, field := range readFormFieldsSafely(doc.Reader) {
+		page := 0
+		for _, p := range field.Pages {
+			if p > 0 {
+				page = p
+				break
+			}
+		}
+		if len(req.Pages) > 0 && !slices.Contains(req.Pages, page) {
+			continue
+		}
+		result.FormFields++
+		if strings.TrimSpace(extraction.InheritedAttribute(field.Field, "TU").Text()) == "" {
+			result.UnlabeledFields++
+			add(AccessRuleFormLabel, AccessSeverityError, page, "PDF/UA 7.18.1, WCAG 4.1.2",
+				"form field %q has no label (tooltip) for assistive technology", field.Name)
+		}
+	}
+
+	if req.CheckContrast {
+		a.checkContrast(req.Path, pages, lines, rasters, result)
+	}
+
+	for _, issue := range result.Issues {
+		if issue.Severity == AccessSeverityError {
+			result.Errors++
+		} else {
+			result.Warnings++
+		}
+	}
+	return result, nil
+}
+
+// accessibilityIssue builds an issue with a formatted message
+func accessibilityIssue(
+	rule, severity string, page int, standard, format string, args ...interface{},
+) AccessibilityIssue {
+	return AccessibilityIssue{Rule: rule, Severity: severity, Page: page, Message: fmt.Sprintf(format, args...),
+		Standard: standard}
+}
+
+// readAccessibilityCatalog reads the document language, MarkInfo, and viewer preferences
+// from the catalog
+func readAccessibilityCatalog(r *pdf.Reader) (catalog accessibilityCatalog) {
+	// The parser panics on malformed objects; entries read so far are kept
+	defer func() {
+		_ = recover()
+	}()
+
+	root := r.Trailer().Key("Root")
+	catalog.language = strings.TrimSpace(root.Key("Lang").Text())
+	catalog.marked = root.Key("MarkInfo").Key("Marked").Bool()
+	catalog.displayTitle = root.Key("ViewerPreferences").Key("DisplayDocTitle").Bool()
+	return catalog
+}
+
+// readFormFieldsSafely reads the form fields of a document, or none when its form is
+// malformed
+func readFormFieldsSafely(r *pdf.Reader) (fields []extraction.FormField) {
+	defer func() {
+		if recover() != nil {
+			fields = nil
+		}
+	}()
+	return extraction.ReadFormFields(r)
+}
+
+// structureFigures returns the figures of a structure tree in document order
+func structureFigures(elements []extraction.StructureElement) []extraction.StructureElement {
+	var figures []extraction.StructureElement
+	for _, element := range elements {
+		if element.Role == "Figure" {
+			figures = append(figures, element)
+		}
+		figures = append(figures, structureFigures(element.Children)...)
+	}
+	return figures
+}
+
+// misplacedElements counts the tagged elements of a page whose text comes before that of
+// the element tagged ahead of them in the page's visual reading order. Elements are found by
+// the start of their text; those not found, as text drawn across lines differently, are
+// skipped.
+func misplacedElements(elements []extraction.StructureElement, pageNum int, lines []pageLine) int {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Text
+	}
+	visual := orderKey(strings.Join(texts, " "), -1)
+
+	misplaced, last := 0, -1
+	var walk func(elements []extraction.StructureElement)
+	walk = func(elements []extraction.StructureElement) {
+		for _, element := range elements {
+			if len(element.Children) > 0 {
+				walk(element.Children)
+				continue
+			}
+			text := element.ActualText
+			if text == "" {
+				text = element.Text
+			}
+			if element.Page != pageNum || element.Role == "Figure" || text == "" {
+				continue
+			}
+			pos := strings.Index(visual, orderKey(text, readingOrderKeyLength))
+			switch {
+			case pos < 0:
+			case pos < last:
+				misplaced++
+			default:
+				last = pos
+			}
+		}
+	}
+	walk(elements)
+	return misplaced
+}
+
+// orderKey folds text for matching against the reading order: lower case, single spaces,
+// and at most length runes when length is not negative
+func orderKey(text string, length int) string {
+	key := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	if length >= 0 && utf8.RuneCountInString(key) > length {
+		key = string([]rune(key)[:length])
+	}
+	return key
+}
+
+// checkContrast renders the audited pages and samples the contrast of each line of text with
+// its background, adding an issue for each page with lines under the WCAG 1.4.3 minimum.
+// Pages that cannot be rendered are noted.
+func (a *AccessibilityAuditor) checkContrast(
+	path string, pages []int, lines map[int][]pageLine, rasters map[int]pageRaster,
+	result *PDFAccessibilityReportResult,
+) {
+	backend, err := a.renderer.findBackend()
+	if err != nil {
+		result.Notes = append(result.Notes, fmt.Sprintf("contrast not checked: %v", err))
+		return
+	}
+	result.ContrastChecked = true
+	result.Renderer = backend.name
+
+	for _, pageNum := range pages {
+		if len(lines[pageNum]) == 0 {
+			continue
+		}
+		if _, err := checkRenderSize(path, pageNum, contrastDPI); err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("page %d: contrast not checked: %v", pageNum, err))
+			continue
+		}
+		rendered, err := runRenderBackend(backend, path, pageNum, contrastDPI)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("page %d: contrast not checked: %v", pageNum, err))
+			continue
+		}
+		img, err := png.Decode(bytes.NewReader(rendered))
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("page %d: %s produced an unreadable image: %v",
+				pageNum, backend.name, err))
+			continue
+		}
+
+		raster := rasters[pageNum]
+		scaled := pageRaster{box: raster.box, rotation: raster.rotation, scale: contrastDPI / pointsPerInch}
+		failing, lowest, lowestText := 0, math.Inf(1), ""
+		for _, line := range lines[pageNum] {
+			if countLetters(line.Text) < contrastMinLetters {
+				continue
+			}
+			pixels := scaled.toPixels(raster.fromDisplay(lineBounds(line))).Intersect(img.Bounds())
+			ratio, ok := textContrast(img, pixels)
+			if !ok {
+				continue
+			}
+			result.ContrastSamples++
+			minimum := minTextContrast
+			if line.FontSize >= largeTextSize {
+				minimum = minLargeTextContrast
+			}
+			if ratio < minimum {
+				failing++
+				if ratio < lowest {
+					lowest, lowestText = ratio, line.Text
+				}
+			}
+		}
+		if failing > 0 {
+			result.Issues = append(result.Issues, accessibilityIssue(AccessRuleContrast, AccessSeverityWarning,
+				pageNum, "WCAG 1.4.3", "%d lines of text fall under the contrast minimum, the lowest %.1f:1 in %q",
+				failing, lowest, lowestText))
+		}
+	}
+}
+
+// countLetters counts the letters and digits of text
+func countLetters(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// textContrast returns the WCAG contrast ratio between the text of a line and its background
+// in the pixels covering the line. The background is the most common luminance; the text is
+// the luminance furthest from it covering enough of the line, so the cores of the glyphs are
+// measured rather than their anti-aliased edges. Lines with no such luminance are not read.
+func textContrast(img image.Image, pixels image.Rectangle) (float64, bool) {
+	var histogram [256]int
+	total := 0
+	for y := pixels.Min.Y; y < pixels.Max.Y; y++ {
+		for x := pixels.Min.X; x < pixels.Max.X; x++ {
+			histogram[int(math.Round(relativeLuminance(img.At(x, y))*255))]++
+			total++
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+
+	background := 0
+	for level, count := range histogram {
+		if count > histogram[background] {
+			background = level
+		}
+	}
+	threshold := max(1, int(float64(total)*contrastInkShare))
+	text := -1
+	for level, count := range histogram {
+		if count >= threshold && level != background &&
+			(text < 0 || math.Abs(float64(level-background)) > math.Abs(float64(text-background))) {
+			text = level
+		}
+	}
+	if text < 0 {
+		return 0, false
+	}
+	lighter, darker := float64(max(text, background))/255, float64(min(text, background))/255
+	return (lighter + 0.05) / (darker + 0.05), true
+}
+
+// relativeLuminance returns the WCAG relative luminance of a color, between 0 for black and
+// 1 for white
+func relativeLuminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	linear := func(v uint32) float64 {
+		s := float64(v) / 0xffff
+		if s <= 0.04045 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
@@ -0,0 +1,104 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"testing"
+)
+
+// taggedFormPDF is a tagged page whose paragraphs are tagged in the opposite order to the
+// one they are drawn in, with a figure without alternate text, a line drawn outside tags, and
+// two form fields, one of them without a label
+func taggedFormPDF() string {
+	content := "/P << /MCID 0 >> BDC BT /F1 12 Tf 72 700 Td (First paragraph on top) Tj ET EMC\n" +
+		"/P << /MCID 1 >> BDC BT /F1 12 Tf 72 650 Td (Second paragraph below) Tj ET EMC\n" +
+		"/Figure << /MCID 2 >> BDC 0 0 m 100 100 l S EMC\n" +
+		"BT /F1 12 Tf 72 600 Td (Stray untagged text) Tj ET\n" +
+		"/Artifact BMC BT /F1 8 Tf 72 40 Td (Page 1) Tj ET EMC"
+	return buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /StructTreeRoot 6 0 R /MarkInfo << /Marked true >> " +
+			"/AcroForm << /Fields [11 0 R 12 0 R] >> >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> " +
+			"/Contents 5 0 R /Annots [11 0 R 12 0 R] >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+		"<< /Type /StructTreeRoot /K 7 0 R >>",
+		"<< /Type /StructElem /S /Document /P 6 0 R /K [8 0 R 9 0 R 10 0 R] >>",
+		"<< /Type /StructElem /S /P /P 7 0 R /Pg 4 0 R /K 1 >>",
+		"<< /Type /StructElem /S /P /P 7 0 R /Pg 4 0 R /K 0 >>",
+		"<< /Type /StructElem /S /Figure /P 7 0 R /Pg 4 0 R /K 2 >>",
+		"<< /FT /Tx /T (name) /TU (Full name) /Subtype /Widget /Rect [72 500 272 520] /P 4 0 R >>",
+		"<< /FT /Tx /T (email) /Subtype /Widget /Rect [72 460 272 480] /P 4 0 R >>",
+	})
+}
+
+func TestAccessibilityAuditor_Report(t *testing.T) {
+	path := createTempFile(t, "tagged.pdf", taggedFormPDF())
+	auditor := NewAccessibilityAuditor(100 * 1024 * 1024)
+
+	result, err := auditor.Report(context.Background(), PDFAccessibilityReportRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Report() unexpected error = %v", err)
+	}
+
+	var got []string
+	for _, issue := range result.Issues {
+		got = append(got, fmt.Sprintf("%s %s %d", issue.Rule, issue.Severity, issue.Page))
+	}
+	want := []string{
+		"document-language error 0",
+		"document-title error 0",
+		"figure-alt-text error 1",
+		"untagged-content error 1",
+		"reading-order warning 1",
+		"form-field-label error 1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !result.Tagged || result.Figures != 1 || result.FormFields != 2 || result.UnlabeledFields != 1 ||
+		result.Errors != 5 || result.Warnings != 1 || result.ContrastChecked {
+		t.Errorf("Report() = %+v", result)
+	}
+
+	untagged := createTempFile(t, "untagged.pdf", buildTestPDF("BT /F1 12 Tf 72 700 Td (Hello) Tj ET"))
+	result, err = auditor.Report(context.Background(), PDFAccessibilityReportRequest{Path: untagged})
+	if err != nil {
+		t.Fatalf("Report() unexpected error = %v", err)
+	}
+	if result.Tagged || len(result.Issues) == 0 || result.Issues[0].Rule != AccessRuleTagged {
+		t.Errorf("Report() for an untagged document = %+v, want it flagged as untagged", result.Issues)
+	}
+}
+
+func TestTextContrast(t *testing.T) {
+	tests := []struct {
+		name string
+		ink  color.Color
+		want float64
+	}{
+		{"black on white", color.Black, 21},
+		{"light gray on white", color.Gray{Y: 0xaa}, 2.3},
+	}
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, 100, 20))
+		draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(10, 5, 60, 15), image.NewUniform(tt.ink), image.Point{}, draw.Src)
+
+		ratio, ok := textContrast(img, img.Bounds())
+		if !ok || math.Abs(ratio-tt.want) > 0.1 {
+			t.Errorf("%s: textContrast() = %.2f, %v, want %.1f", tt.name, ratio, ok, tt.want)
+		}
+	}
+
+	blank := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	if _, ok := textContrast(blank, blank.Bounds()); ok {
+		t.Error("textContrast() read a contrast from a blank line")
+	}
+}
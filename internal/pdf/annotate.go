@@ -0,0 +1,416 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+	"github.com/ledongthuc/pdf"
+)
+
+// Annotation drawing constants
+const (
+	// noteIconSize is the width and height of a sticky note's icon, in points
+	noteIconSize = 20.0
+	// rectangleLineWidth is the width of a rectangle annotation's outline, in points
+	rectangleLineWidth = 2.0
+	// rectanglePadding is the space left between matched text and a rectangle around it
+	rectanglePadding = 2.0
+	// annotationFlags marks annotations as printable; notes also keep their icon size and
+	// orientation when the page is zoomed or rotated
+	annotationFlags = 4
+	noteFlags       = 28
+)
+
+// annotatedMIMEType is the MIME type of annotated documents
+const annotatedMIMEType = "application/pdf"
+
+// defaultAnnotationColors are the colors used when an annotation does not give one
+var defaultAnnotationColors = map[string][3]float64{
+	AnnotationHighlight: {1, 0.92, 0.23},
+	AnnotationNote:      {1, 0.82, 0.2},
+	AnnotationRectangle: {0.9, 0.1, 0.1},
+}
+
+// annotationSubtypes are the PDF annotation subtypes of each annotation type
+var annotationSubtypes = map[string]string{
+	AnnotationHighlight: "Highlight",
+	AnnotationNote:      "Text",
+	AnnotationRectangle: "Square",
+}
+
+// Annotator adds annotations to PDF pages and writes the annotated document
+type Annotator struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewAnnotator creates a new annotator with the specified constraints
+func NewAnnotator(maxFileSize int64) *Annotator {
+	return &Annotator{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// plannedAnnotation is an annotation placed on a page, ready to be written
+type plannedAnnotation struct {
+	spec  AnnotationSpec
+	color [3]float64
+	rect  extraction.BoundingBox   // The annotation's rectangle
+	areas []extraction.BoundingBox // Highlighted areas, one per line of matched text
+	text  string                   // Matched text
+}
+
+// Annotate writes a copy of the document with highlight, note, and rectangle annotations
+// added at the requested regions or around every occurrence of the requested phrases.
+// Highlights and rectangles carry their own appearance, so they show in viewers that do
+// not draw annotations themselves; notes are drawn with the viewer's note icon. Existing
+// annotations are kept.
+func (a *Annotator) Annotate(ctx context.Context, req PDFAnnotateRequest) (*PDFAnnotateResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	if len(req.Annotations) == 0 {
+		return nil, fmt.Errorf("nothing to annotate: give at least one annotation")
+	}
+	colors := make([][3]float64, len(req.Annotations))
+	for i, spec := range req.Annotations {
+		if _, ok := annotationSubtypes[spec.Type]; !ok {
+			return nil, fmt.Errorf("annotation %d: invalid type %q (must be %s, %s, or %s)",
+				i+1, spec.Type, AnnotationHighlight, AnnotationNote, AnnotationRectangle)
+		}
+		color, err := parseAnnotationColor(spec.Color, defaultAnnotationColors[spec.Type])
+		if err != nil {
+			return nil, fmt.Errorf("annotation %d: %w", i+1, err)
+		}
+		colors[i] = color
+		if strings.TrimSpace(spec.Text) != "" {
+			continue
+		}
+		if spec.Page == 0 {
+			return nil, fmt.Errorf("annotation %d: give a phrase in text, or a page and region", i+1)
+		}
+		// A note's region only places its icon, so it may be a point
+		if spec.Type != AnnotationNote && (spec.Width <= 0 || spec.Height <= 0) {
+			return nil, fmt.Errorf("annotation %d on page %d must have a positive width and height", i+1, spec.Page)
+		}
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: a.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	reader := doc.Reader
+
+	if !reader.Trailer().Key("Encrypt").IsNull() {
+		return nil, fmt.Errorf("cannot annotate encrypted documents")
+	}
+
+	result := &PDFAnnotateResult{
+		Path:        req.Path,
+		MIMEType:    annotatedMIMEType,
+		Pages:       []int{},
+		Annotations: []AddedAnnotation{},
+	}
+
+	numbering := extraction.NewPageNumbering(reader)
+	texts := make(map[int]*extraction.PageText)
+	plan := make(map[int][]plannedAnnotation)
+	for i, spec := range req.Annotations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if spec.Page != 0 && (spec.Page < 1 || spec.Page > numbering.Count()) {
+			return nil, pageOutOfRange(spec.Page, numbering.Count())
+		}
+
+		words := strings.Fields(spec.Text)
+		if len(words) == 0 {
+			plan[spec.Page] = append(plan[spec.Page], placeAnnotation(spec, colors[i], regionBox(spec), nil, ""))
+			continue
+		}
+
+		pages := []int{spec.Page}
+		if spec.Page == 0 {
+			pages = pages[:0]
+			for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+				pages = append(pages, pageNum)
+			}
+		}
+		pattern := findTextPattern(words, req.CaseSensitive, req.WholeWord)
+		matched := false
+		for _, pageNum := range pages {
+			text, ok := texts[pageNum]
+			if !ok {
+				if text, err = pageText(numbering, pageNum); err != nil {
+					text = nil
+				}
+				texts[pageNum] = text
+			}
+			if text == nil {
+				continue
+			}
+			for _, match := range text.Find(pattern) {
+				matched = true
+				plan[pageNum] = append(plan[pageNum],
+					placeAnnotation(spec, colors[i], match.BoundingBox, match.Rects, match.Text))
+			}
+		}
+		if !matched {
+			result.Unmatched = append(result.Unmatched, strings.Join(words, " "))
+		}
+	}
+	if len(plan) == 0 {
+		return nil, fmt.Errorf("nothing to annotate: no text found for %s", strings.Join(result.Unmatched, ", "))
+	}
+
+	writer := newDocumentWriter(doc)
+	modified := time.Now()
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		planned := plan[pageNum]
+		if len(planned) == 0 {
+			continue
+		}
+		result.Pages = append(result.Pages, pageNum)
+		for _, annotation := range planned {
+			rect := annotation.rect
+			result.Annotations = append(result.Annotations, AddedAnnotation{
+				Type:    annotation.spec.Type,
+				Page:    pageNum,
+				X:       rect.LowerLeft.X,
+				Y:       rect.LowerLeft.Y,
+				Width:   rect.Width,
+				Height:  rect.Height,
+				Text:    annotation.text,
+				Comment: annotation.spec.Comment,
+			})
+		}
+		page := numbering.Page(pageNum)
+		writer.edits[extraction.RefOf(page.V)] = annotatePageEdit(page, planned, modified)
+	}
+
+	data, err := writer.write(reader.Trailer())
+	if err != nil {
+		return nil, fmt.Errorf("failed to write annotated document: %w", err)
+	}
+	result.Size = len(data)
+
+	if req.OutputDir == "" {
+		result.Data = base64.StdEncoding.EncodeToString(data)
+		return result, nil
+	}
+
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+	stem := strings.TrimSuffix(filepath.Base(req.Path), filepath.Ext(req.Path))
+	result.OutputPath = filepath.Join(req.OutputDir, stem+"-annotated.pdf")
+	if err := fsys.WriteFile(result.OutputPath, data, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save annotated document: %w", err)
+	}
+
+	return result, nil
+}
+
+// placeAnnotation sizes an annotation for the area it marks. Highlights cover the area,
+// rectangles surround it, and notes put their icon at its top-right corner. A region is
+// highlighted as one area.
+func placeAnnotation(
+	spec AnnotationSpec, color [3]float64, area extraction.BoundingBox, areas []extraction.BoundingBox, text string,
+) plannedAnnotation {
+	annotation := plannedAnnotation{spec: spec, color: color, rect: area, areas: areas, text: text}
+	switch spec.Type {
+	case AnnotationHighlight:
+		if len(annotation.areas) == 0 {
+			annotation.areas = []extraction.BoundingBox{area}
+		}
+	case AnnotationRectangle:
+		if text != "" {
+			annotation.rect = newBox(area.LowerLeft.X-rectanglePadding, area.LowerLeft.Y-rectanglePadding,
+				area.Width+2*rectanglePadding, area.Height+2*rectanglePadding)
+		}
+	case AnnotationNote:
+		x, top := area.UpperRight.X, area.UpperRight.Y
+		if text == "" {
+			// A region's note icon sits at the region's top-left corner, or at its point
+			x, top = area.LowerLeft.X, math.Max(area.UpperRight.Y, area.LowerLeft.Y+noteIconSize)
+		}
+		annotation.rect = newBox(x, top-noteIconSize, noteIconSize, noteIconSize)
+	}
+	return annotation
+}
+
+// regionBox returns the region of an annotation spec
+func regionBox(spec AnnotationSpec) extraction.BoundingBox {
+	return newBox(spec.X, spec.Y, spec.Width, spec.Height)
+}
+
+// newBox returns the box with the given lower-left corner and size
+func newBox(x, y, width, height float64) extraction.BoundingBox {
+	return extraction.BoundingBox{
+		LowerLeft:  extraction.Coordinate{X: x, Y: y},
+		UpperRight: extraction.Coordinate{X: x + width, Y: y + height},
+		Width:      width,
+		Height:     height,
+	}
+}
+
+// annotatePageEdit writes a page whose annotations include the planned ones
+func annotatePageEdit(page pdf.Page, planned []plannedAnnotation, modified time.Time) objectEdit {
+	return func(w *documentWriter, b *bytes.Buffer, v pdf.Value) error {
+		pageNumber := w.reference(v)
+
+		var annots bytes.Buffer
+		annots.WriteByte('[')
+		existing := page.V.Key("Annots")
+		for i := 0; i < existing.Len(); i++ {
+			if err := w.child(&annots, existing.Index(i), extraction.RefOf(existing), 1); err != nil {
+				return err
+			}
+			annots.WriteByte(' ')
+		}
+		for i, annotation := range planned {
+			number, err := w.addAnnotation(annotation, pageNumber, modified)
+			if err != nil {
+				return fmt.Errorf("failed to add annotation %d: %w", i+1, err)
+			}
+			fmt.Fprintf(&annots, "%d 0 R ", number)
+		}
+		annots.Truncate(annots.Len() - 1)
+		annots.WriteByte(']')
+
+		return w.dict(b, v, map[string]string{"Annots": annots.String()}, 0)
+	}
+}
+
+// addAnnotation adds an annotation dictionary, and the appearance of highlights and
+// rectangles, returning the annotation's number
+func (w *documentWriter) addAnnotation(a plannedAnnotation, pageNumber int, modified time.Time) (int, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<</Type /Annot /Subtype /%s /Rect [%s] /P %d 0 R /C [%s] /M ",
+		annotationSubtypes[a.spec.Type], formatBox(a.rect), pageNumber, formatColor(a.color))
+	writeTextString(&b, modified.UTC().Format("D:20060102150405Z"))
+	if a.spec.Comment != "" {
+		b.WriteString(" /Contents ")
+		writeTextString(&b, a.spec.Comment)
+	}
+	if a.spec.Author != "" {
+		b.WriteString(" /T ")
+		writeTextString(&b, a.spec.Author)
+	}
+
+	switch a.spec.Type {
+	case AnnotationNote:
+		fmt.Fprintf(&b, " /F %d /Name /Comment /Open false>>", noteFlags)
+		return w.addObject(b.Bytes()), nil
+	case AnnotationHighlight:
+		b.WriteString(" /QuadPoints [")
+		for i, area := range a.areas {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			// Upper-left, upper-right, lower-left, lower-right, the order viewers expect
+			fmt.Fprintf(&b, "%s %s %s %s %s %s %s %s",
+				formatNumber(area.LowerLeft.X), formatNumber(area.UpperRight.Y),
+				formatNumber(area.UpperRight.X), formatNumber(area.UpperRight.Y),
+				formatNumber(area.LowerLeft.X), formatNumber(area.LowerLeft.Y),
+				formatNumber(area.UpperRight.X), formatNumber(area.LowerLeft.Y))
+		}
+		b.WriteByte(']')
+	case AnnotationRectangle:
+		fmt.Fprintf(&b, " /BS <</W %s>>", formatNumber(rectangleLineWidth))
+	}
+
+	appearance, err := w.addAppearance(a)
+	if err != nil {
+		return 0, err
+	}
+	fmt.Fprintf(&b, " /F %d /AP <</N %d 0 R>>>>", annotationFlags, appearance)
+	return w.addObject(b.Bytes()), nil
+}
+
+// addAppearance adds the form XObject drawing a highlight or rectangle. Highlights are
+// multiplied onto the page, so the text under them stays readable.
+func (w *documentWriter) addAppearance(a plannedAnnotation) (int, error) {
+	var content bytes.Buffer
+	replace := map[string]string{
+		"Type":    "/XObject",
+		"Subtype": "/Form",
+		"BBox":    "[" + formatBox(a.rect) + "]",
+	}
+	switch a.spec.Type {
+	case AnnotationHighlight:
+		replace["Resources"] = "<</ExtGState <</GS0 <</Type /ExtGState /BM /Multiply>>>>>>"
+		fmt.Fprintf(&content, "/GS0 gs %s rg\n", formatColor(a.color))
+		for _, area := range a.areas {
+			fmt.Fprintf(&content, "%s %s %s %s re f\n", formatNumber(area.LowerLeft.X),
+				formatNumber(area.LowerLeft.Y), formatNumber(area.Width), formatNumber(area.Height))
+		}
+	case AnnotationRectangle:
+		inset := rectangleLineWidth / 2
+		fmt.Fprintf(&content, "%s RG %s w\n%s %s %s %s re S\n", formatColor(a.color), formatNumber(rectangleLineWidth),
+			formatNumber(a.rect.LowerLeft.X+inset), formatNumber(a.rect.LowerLeft.Y+inset),
+			formatNumber(a.rect.Width-2*inset), formatNumber(a.rect.Height-2*inset))
+	}
+	return w.addStream(pdf.Value{}, replace, content.Bytes())
+}
+
+// parseAnnotationColor parses a hex color such as "#ffcc00", returning fallback for an
+// empty color
+func parseAnnotationColor(s string, fallback [3]float64) ([3]float64, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	digits, err := hex.DecodeString(strings.TrimPrefix(s, "#"))
+	if err != nil || len(digits) != 3 {
+		return fallback, fmt.Errorf("invalid color %q: expected a hex color such as #ffcc00", s)
+	}
+	return [3]float64{float64(digits[0]) / 255, float64(digits[1]) / 255, float64(digits[2]) / 255}, nil
+}
+
+// writeTextString writes a PDF text string: ASCII as is, anything else as UTF-16 with a
+// byte order mark
+func writeTextString(b *bytes.Buffer, s string) {
+	data := []byte(s)
+	for _, r := range s {
+		if r > '~' {
+			data = []byte{0xFE, 0xFF}
+			for _, unit := range utf16.Encode([]rune(s)) {
+				data = append(data, byte(unit>>8), byte(unit))
+			}
+			break
+		}
+	}
+	fmt.Fprintf(b, "<%x>", data)
+}
+
+// formatBox formats a box as the four numbers of a PDF rectangle
+func formatBox(box extraction.BoundingBox) string {
+	return strings.Join([]string{
+		formatNumber(box.LowerLeft.X), formatNumber(box.LowerLeft.Y),
+		formatNumber(box.UpperRight.X), formatNumber(box.UpperRight.Y),
+	}, " ")
+}
+
+// formatColor formats an RGB color as the operands of a color operator
+func formatColor(color [3]float64) string {
+	return formatNumber(color[0]) + " " + formatNumber(color[1]) + " " + formatNumber(color[2])
+}
+
+// formatNumber formats a number for a PDF object or content stream, to a thousandth
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
@@ -0,0 +1,117 @@
+package pdf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+)
+
+func TestAnnotator_AddsAnnotations(t *testing.T) {
+	content := "BT /F1 12 Tf 72 700 Td (Balance due on receipt) Tj ET"
+	path := createTempFile(t, "invoice.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R 7 0 R] /Count 2 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> " +
+			"/Contents 5 0 R /Annots [6 0 R] >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+		"<< /Type /Annot /Subtype /Link /Rect [72 600 200 620] /A << /S /URI /URI (https://example.com) >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	}))
+
+	result, err := NewAnnotator(10*1024*1024).Annotate(context.Background(), PDFAnnotateRequest{
+		Path: path,
+		Annotations: []AnnotationSpec{
+			{Type: AnnotationHighlight, Text: "balance due", Comment: "Check the total", Author: "Reviewer"},
+			{Type: AnnotationNote, Text: "receipt", Comment: "Échéance à préciser"},
+			{Type: AnnotationRectangle, Page: 2, X: 72, Y: 500, Width: 200, Height: 100, Color: "#0000ff"},
+			{Type: AnnotationHighlight, Text: "penalty"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Annotate() unexpected error = %v", err)
+	}
+	if fmt.Sprint(result.Pages) != "[1 2]" || len(result.Annotations) != 3 {
+		t.Fatalf("Annotate() pages = %v with %d annotations, want 3 on pages 1 and 2",
+			result.Pages, len(result.Annotations))
+	}
+	if fmt.Sprint(result.Unmatched) != "[penalty]" {
+		t.Errorf("Annotate() unmatched = %v, want [penalty]", result.Unmatched)
+	}
+	highlight := result.Annotations[0]
+	if highlight.Text != "Balance due" || highlight.X < 71 || highlight.X > 73 || highlight.Y < 690 {
+		t.Errorf("Annotate() highlight = %+v, want it over the matched text", highlight)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil {
+		t.Fatalf("annotated data is not base64: %v", err)
+	}
+	annotated := filepath.Join(t.TempDir(), "annotated.pdf")
+	if err := os.WriteFile(annotated, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, r, err := pdf.Open(annotated)
+	if err != nil {
+		t.Fatalf("annotated document does not open: %v", err)
+	}
+	defer f.Close()
+
+	annots := r.Page(1).V.Key("Annots")
+	if annots.Len() != 3 || annots.Index(0).Key("Subtype").Name() != "Link" {
+		t.Fatalf("page 1 has %d annotations, want the link kept and 2 added", annots.Len())
+	}
+	added := annots.Index(1)
+	if added.Key("Subtype").Name() != "Highlight" || added.Key("QuadPoints").Len() != 8 ||
+		added.Key("Contents").Text() != "Check the total" || added.Key("T").Text() != "Reviewer" {
+		t.Errorf("highlight annotation = %v, want one quad with the comment and author", added)
+	}
+	if added.Key("AP").Key("N").Key("Subtype").Name() != "Form" {
+		t.Errorf("highlight annotation has no appearance stream")
+	}
+	if note := annots.Index(2); note.Key("Subtype").Name() != "Text" ||
+		note.Key("Contents").Text() != "Échéance à préciser" {
+		t.Errorf("note annotation = %v, want a text note with the comment", note)
+	}
+
+	square := r.Page(2).V.Key("Annots").Index(0)
+	if square.Key("Subtype").Name() != "Square" || square.Key("C").Index(2).Float64() != 1 ||
+		square.Key("Rect").Index(2).Float64() != 272 {
+		t.Errorf("rectangle annotation = %v, want a blue square over the region", square)
+	}
+	if raw, _ := r.Page(1).GetPlainText(nil); !strings.Contains(raw, "Balance") {
+		t.Errorf("annotated page lost its text: %q", raw)
+	}
+}
+
+func TestAnnotator_RejectsInvalidRequests(t *testing.T) {
+	path := createTempFile(t, "plain.pdf", buildTestPDF("BT /F1 12 Tf 72 700 Td (Nothing here) Tj ET"))
+	annotator := NewAnnotator(10 * 1024 * 1024)
+
+	tests := map[string]struct {
+		specs    []AnnotationSpec
+		errorMsg string
+	}{
+		"no annotations":  {nil, "nothing to annotate"},
+		"unknown type":    {[]AnnotationSpec{{Type: "underline", Text: "here"}}, "invalid type"},
+		"bad color":       {[]AnnotationSpec{{Type: AnnotationNote, Text: "here", Color: "red"}}, "invalid color"},
+		"no placement":    {[]AnnotationSpec{{Type: AnnotationNote}}, "give a phrase"},
+		"empty region":    {[]AnnotationSpec{{Type: AnnotationRectangle, Page: 1, Width: 10}}, "positive width"},
+		"page past end":   {[]AnnotationSpec{{Type: AnnotationNote, Page: 3}}, "out of range"},
+		"phrase not here": {[]AnnotationSpec{{Type: AnnotationHighlight, Text: "missing"}}, "no text found for missing"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := annotator.Annotate(context.Background(), PDFAnnotateRequest{Path: path, Annotations: tt.specs})
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Annotate() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package pdf
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Appendix detection constants
+const (
+	// appendixHeadingLines is how many lines from the top of a page are checked for a heading
+	appendixHeadingLines = 3
+	// appendixMaxTitleWords is the longest heading line accepted without a larger font, so
+	// body text that merely starts with "Schedule 1 sets out..." is not mistaken for one
+	appendixMaxTitleWords = 8
+)
+
+// Appendix sources
+const (
+	AppendixSourceOutline = "outline"
+	AppendixSourceHeading = "heading"
+)
+
+var (
+	// appendixPattern matches appendix headings such as "Exhibit A", "Annex 3: Pricing" or
+	// "Schedule B-1 - Fees", capturing the kind, the label, and the title
+	appendixPattern = regexp.MustCompile(`^(?i:(appendix|exhibit|schedule|annex|attachment|addendum))\s+` +
+		`([A-Z]{1,2}|\d{1,3}|[IVXLC]{1,6})((?:[-.]\d{1,3})*)\b\s*[:.\-–—]?\s*(.*)$`)
+	// tocEntryPattern matches table of contents entries: dot leaders or a trailing page number
+	tocEntryPattern = regexp.MustCompile(`\.{3,}|…|\s\d+$`)
+)
+
+// detectAppendices finds appendices, exhibits, schedules, annexes, attachments, and addenda
+// from outline titles and from headings at the top of pages. Each unit runs until the next
+// one starts or the document ends. Pages repeating the heading of the unit they continue,
+// and table of contents pages listing several units, do not start a new unit.
+func detectAppendices(numbering *extraction.PageNumbering, outline []OutlineItem) []Appendix {
+	found := make(map[string]*Appendix)
+	var keys []string
+	add := func(kind, label, title string, page int, source string) {
+		key := kind + " " + label
+		if existing, ok := found[key]; ok {
+			// The outline is authoritative; repeated page headings mark continuation pages
+			if source == AppendixSourceOutline && existing.Source != AppendixSourceOutline {
+				existing.StartPage = page
+				existing.Source = source
+			}
+			if existing.Title == "" {
+				existing.Title = title
+			}
+			return
+		}
+		found[key] = &Appendix{Kind: kind, Label: label, Title: title, StartPage: page, Source: source}
+		keys = append(keys, key)
+	}
+
+	var walk func(items []OutlineItem)
+	walk = func(items []OutlineItem) {
+		for _, item := range items {
+			if kind, label, title, ok := parseAppendixHeading(item.Title); ok && item.Page > 0 {
+				add(kind, label, title, item.Page, AppendixSourceOutline)
+			}
+			walk(item.Children)
+		}
+	}
+	walk(outline)
+
+	total := numbering.Count()
+	for pageNum := 1; pageNum <= total; pageNum++ {
+		lines, bodySize := pageTopLines(numbering.Page(pageNum), appendixHeadingLines)
+
+		var headings []pageLine
+		for _, line := range lines {
+			if _, _, _, ok := parseAppendixHeading(line.Text); !ok || tocEntryPattern.MatchString(line.Text) {
+				continue
+			}
+			if line.Words <= appendixMaxTitleWords || line.FontSize >= bodySize*headingFontRatio {
+				headings = append(headings, line)
+			}
+		}
+		if len(headings) != 1 {
+			continue
+		}
+
+		kind, label, title, _ := parseAppendixHeading(headings[0].Text)
+		add(kind, label, title, pageNum, AppendixSourceHeading)
+	}
+
+	appendices := make([]Appendix, 0, len(keys))
+	for _, key := range keys {
+		appendices = append(appendices, *found[key])
+	}
+	sort.SliceStable(appendices, func(i, j int) bool {
+		return appendices[i].StartPage < appendices[j].StartPage
+	})
+	for i := range appendices {
+		appendices[i].EndPage = total
+		if i+1 < len(appendices) {
+			appendices[i].EndPage = max(appendices[i+1].StartPage-1, appendices[i].StartPage)
+		}
+	}
+
+	return appendices
+}
+
+// parseAppendixHeading splits a heading such as "Exhibit B-1: Pricing" into its normalized
+// kind ("exhibit"), label ("B-1"), and title ("Pricing")
+func parseAppendixHeading(text string) (kind, label, title string, ok bool) {
+	match := appendixPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return "", "", "", false
+	}
+	title = strings.TrimSpace(match[4])
+	if strings.HasPrefix(strings.ToLower(title), "(continued") {
+		title = ""
+	}
+	return strings.ToLower(match[1]), strings.ToUpper(match[2]) + match[3], title, true
+}
+
+// Name returns the unit's display name, e.g. "Exhibit A"
+func (a Appendix) Name() string {
+	if a.Kind == "" {
+		return a.Label
+	}
+	return strings.ToUpper(a.Kind[:1]) + a.Kind[1:] + " " + a.Label
+}
@@ -0,0 +1,174 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// exhibitPages builds a contract whose exhibits and schedules start on their own pages, with a
+// table of contents listing them and a body page that mentions an exhibit in running text
+func exhibitPages() []string {
+	pages := sectionedPages(9, map[int]string{
+		1: "1 Introduction",
+		4: "Exhibit A: Pricing",
+		6: "Exhibit A (continued)",
+		7: "Schedule 2 - Fees",
+	})
+	pages[1] = "BT /F1 12 Tf 72 720 Td (Exhibit A Pricing ........ 4) Tj 0 -20 Td (Schedule 2 Fees ........ 7) Tj ET"
+	pages[2] = "BT /F1 12 Tf 72 720 Td (Exhibit A sets out the pricing that applies to every order placed " +
+		"under this agreement) Tj ET"
+	return pages
+}
+
+func TestParseAppendixHeading(t *testing.T) {
+	tests := []struct {
+		text       string
+		wantOK     bool
+		wantKind   string
+		wantLabel  string
+		wantTitle  string
+		wantString string
+	}{
+		{text: "Exhibit A", wantOK: true, wantKind: "exhibit", wantLabel: "A", wantString: "Exhibit A"},
+		{text: "ANNEX 3: Service Levels", wantOK: true, wantKind: "annex", wantLabel: "3", wantTitle: "Service Levels",
+			wantString: "Annex 3"},
+		{text: "Schedule B-1 - Fees", wantOK: true, wantKind: "schedule", wantLabel: "B-1", wantTitle: "Fees",
+			wantString: "Schedule B-1"},
+		{text: "Appendix IV (continued)", wantOK: true, wantKind: "appendix", wantLabel: "IV", wantString: "Appendix IV"},
+		{text: "Attachments are listed below", wantOK: false},
+		{text: "See Exhibit A", wantOK: false},
+		{text: "Schedule of fees", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			kind, label, title, ok := parseAppendixHeading(tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("parseAppendixHeading(%q) ok = %v, want %v", tt.text, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if kind != tt.wantKind || label != tt.wantLabel || title != tt.wantTitle {
+				t.Errorf("parseAppendixHeading(%q) = %q, %q, %q, want %q, %q, %q",
+					tt.text, kind, label, title, tt.wantKind, tt.wantLabel, tt.wantTitle)
+			}
+			if name := (Appendix{Kind: kind, Label: label}).Name(); name != tt.wantString {
+				t.Errorf("Name() = %q, want %q", name, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestDetectAppendices(t *testing.T) {
+	r := openTestPDF(t, "exhibits.pdf", buildTestPDF(exhibitPages()...))
+
+	appendices := detectAppendices(extraction.NewPageNumbering(r), nil)
+	want := []Appendix{
+		{Kind: "exhibit", Label: "A", Title: "Pricing", StartPage: 4, EndPage: 6, Source: AppendixSourceHeading},
+		{Kind: "schedule", Label: "2", Title: "Fees", StartPage: 7, EndPage: 9, Source: AppendixSourceHeading},
+	}
+	if len(appendices) != len(want) {
+		t.Fatalf("detectAppendices() = %+v, want %+v", appendices, want)
+	}
+	for i := range want {
+		if appendices[i] != want[i] {
+			t.Errorf("appendix %d = %+v, want %+v", i, appendices[i], want[i])
+		}
+	}
+}
+
+func TestOutline_GetOutlineAppendices(t *testing.T) {
+	outline := NewOutline(100 * 1024 * 1024)
+	path := createTempFile(t, "exhibits.pdf", buildTestPDF(exhibitPages()...))
+
+	result, err := outline.GetOutline(PDFGetOutlineRequest{Path: path})
+	if err != nil {
+		t.Fatalf("GetOutline() unexpected error = %v", err)
+	}
+	if result.HasOutline {
+		t.Error("GetOutline() HasOutline = true, want false for a document without bookmarks")
+	}
+	if len(result.Appendices) != 2 || result.Appendices[0].Name() != "Exhibit A" {
+		t.Errorf("GetOutline() appendices = %+v, want Exhibit A and Schedule 2", result.Appendices)
+	}
+}
+
+func TestSections_ExtractSection(t *testing.T) {
+	sections := NewSections(100 * 1024 * 1024)
+	exhibits := createTempFile(t, "exhibits.pdf", buildTestPDF(exhibitPages()...))
+	outlined := createTempFile(t, "outlined.pdf", outlinedPDFContent(6, 1, 4))
+
+	tests := []struct {
+		name      string
+		path      string
+		section   string
+		wantKind  string
+		wantStart int
+		wantEnd   int
+		wantText  string
+	}{
+		{name: "appendix by name", path: exhibits, section: "exhibit  a", wantKind: "exhibit",
+			wantStart: 4, wantEnd: 6, wantText: "Body text of page 5"},
+		{name: "appendix by label", path: exhibits, section: "2", wantKind: "schedule",
+			wantStart: 7, wantEnd: 9, wantText: "Body text of page 9"},
+		{name: "outline title", path: outlined, section: "Section 1", wantKind: "section",
+			wantStart: 1, wantEnd: 3, wantText: "Plain page 3"},
+		{name: "last outline item runs to the end", path: outlined, section: "section 2", wantKind: "section",
+			wantStart: 4, wantEnd: 6, wantText: "Plain page 6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := sections.ExtractSection(PDFExtractSectionRequest{Path: tt.path, Section: tt.section})
+			if err != nil {
+				t.Fatalf("ExtractSection() unexpected error = %v", err)
+			}
+			if result.Kind != tt.wantKind || result.StartPage != tt.wantStart || result.EndPage != tt.wantEnd {
+				t.Errorf("ExtractSection() = %s pages %d-%d, want %s pages %d-%d",
+					result.Kind, result.StartPage, result.EndPage, tt.wantKind, tt.wantStart, tt.wantEnd)
+			}
+			if !strings.Contains(result.Content, tt.wantText) {
+				t.Errorf("ExtractSection() content = %q, want it to contain %q", result.Content, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestSections_ExtractSectionErrors(t *testing.T) {
+	sections := NewSections(100 * 1024 * 1024)
+	exhibits := createTempFile(t, "exhibits.pdf", buildTestPDF(exhibitPages()...))
+
+	tests := []struct {
+		name     string
+		req      PDFExtractSectionRequest
+		errorMsg string
+	}{
+		{name: "empty path", req: PDFExtractSectionRequest{Section: "Exhibit A"}, errorMsg: "path cannot be empty"},
+		{name: "empty section", req: PDFExtractSectionRequest{Path: exhibits}, errorMsg: "section cannot be empty"},
+		{
+			name:     "non-existent file",
+			req:      PDFExtractSectionRequest{Path: "/non/existent/file.pdf", Section: "Exhibit A"},
+			errorMsg: "file does not exist",
+		},
+		{
+			name:     "unknown section lists the available ones",
+			req:      PDFExtractSectionRequest{Path: exhibits, Section: "Exhibit C"},
+			errorMsg: `available sections: "Exhibit A", "Schedule 2"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sections.ExtractSection(tt.req)
+			if err == nil {
+				t.Fatal("ExtractSection() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("ExtractSection() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
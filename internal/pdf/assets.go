@@ -2,8 +2,10 @@ package pdf
 
 import (
 	"fmt"
-	"os"
+	"math"
 
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
 	"github.com/ledongthuc/pdf"
 )
 
@@ -12,8 +14,18 @@ const (
 	RGBComponentCount = 3
 	// BitsPerByte represents the number of bits in a byte
 	BitsPerByte = 8
+	// defaultMinImageDPI is the resolution print-quality checks expect of images
+	defaultMinImageDPI = 300
+	// placementPrecision rounds placement sizes and resolutions to hundredths
+	placementPrecision = 100
 )
 
+// assetScan holds what extraction works out for each image it lists
+type assetScan struct {
+	classify func(pdf.Value, *ImageInfo) // Set when classifying images
+	minDPI   float64
+}
+
 // Assets handles PDF asset extraction operations
 type Assets struct {
 	maxFileSize int64
@@ -33,54 +45,73 @@ func (a *Assets) ExtractAssets(req PDFAssetsFileRequest) (*PDFAssetsFileResult,
 	if req.Path == "" {
 		return nil, fmt.Errorf("path cannot be empty")
 	}
-
-	// Check if file exists and get basic info
-	fileInfo, err := os.Stat(req.Path)
-	if os.IsNotExist(err) {
-		return nil, fmt.Errorf("file does not exist: %s", req.Path)
+	if req.MinDPI < 0 {
+		return nil, fmt.Errorf("min_dpi cannot be negative")
 	}
-	if err != nil {
-		return nil, fmt.Errorf("cannot access file: %w", err)
+	scan := assetScan{minDPI: req.MinDPI}
+	if scan.minDPI == 0 {
+		scan.minDPI = defaultMinImageDPI
 	}
 
-	// Validate file
-	if err := a.validator.ValidateFileInfo(req.Path, fileInfo); err != nil {
+	// Check if file exists and get basic info
+	doc, err := core.Open(req.Path, core.Options{Validate: a.validator.ValidateFileInfo})
+	if err != nil {
 		return nil, err
 	}
-
-	// Open and parse PDF
-	f, r, err := pdf.Open(req.Path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	defer doc.Close()
+	r := doc.Reader
+
+	// Scan through pages looking for images, decoding them when classifying
+	classified := 0
+	if req.Classify {
+		scan.classify = func(obj pdf.Value, info *ImageInfo) {
+			img, err := decodeImageXObject(doc, obj)
+			if err != nil {
+				return
+			}
+			classified++
+			if kind, ok := classifyImage(img); ok {
+				info.Kind, info.Symbology, info.Payload = kind.kind, kind.symbology, kind.payload
+				info.Confidence = kind.confidence
+			}
+		}
 	}
-	defer f.Close()
-
-	// Scan through pages looking for images
-	images := a.extractImagesFromPages(r)
+	images := a.extractImagesFromPages(r, scan)
 
 	result := &PDFAssetsFileResult{
 		Path:       req.Path,
 		Images:     images,
 		TotalCount: len(images),
+		Classified: classified,
+		MinDPI:     scan.minDPI,
+	}
+	for _, img := range images {
+		if img.LowDPI {
+			result.LowDPI++
+		}
 	}
 
 	return result, nil
 }
 
 // extractImagesFromPages scans all pages for image objects
-func (a *Assets) extractImagesFromPages(r *pdf.Reader) []ImageInfo {
+func (a *Assets) extractImagesFromPages(r *pdf.Reader, scan assetScan) []ImageInfo {
 	var images []ImageInfo
 
-	for pageNum := 1; pageNum <= r.NumPage(); pageNum++ {
-		pageImages := a.extractImagesFromPage(r, pageNum)
+	numbering := extraction.NewPageNumbering(r)
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		pageImages := a.extractImagesFromPage(numbering, pageNum, scan)
+		for i := range pageImages {
+			pageImages[i].PageLabel = numbering.Label(pageNum)
+		}
 		images = append(images, pageImages...)
 	}
 
 	return images
 }
 
-// extractImagesFromPage extracts images from a specific page
-func (a *Assets) extractImagesFromPage(r *pdf.Reader, pageNum int) []ImageInfo {
+// extractImagesFromPage extracts images from a specific page with where the page draws them
+func (a *Assets) extractImagesFromPage(numbering *extraction.PageNumbering, pageNum int, scan assetScan) []ImageInfo {
 	var images []ImageInfo
 
 	defer func() {
@@ -90,7 +121,7 @@ func (a *Assets) extractImagesFromPage(r *pdf.Reader, pageNum int) []ImageInfo {
 		}
 	}()
 
-	page := r.Page(pageNum)
+	page := numbering.Page(pageNum)
 	if page.V.IsNull() {
 		return images
 	}
@@ -107,6 +138,13 @@ func (a *Assets) extractImagesFromPage(r *pdf.Reader, pageNum int) []ImageInfo {
 		return images
 	}
 
+	// Placements are left out when the content stream cannot be interpreted
+	placements := map[string][]extraction.ImagePlacement{}
+	drawn, _ := extraction.PageImagePlacements(page)
+	for _, placement := range drawn {
+		placements[placement.Name] = append(placements[placement.Name], placement)
+	}
+
 	// Iterate through XObjects looking for images
 	for _, key := range xObjects.Keys() {
 		obj := xObjects.Key(key)
@@ -123,6 +161,10 @@ func (a *Assets) extractImagesFromPage(r *pdf.Reader, pageNum int) []ImageInfo {
 		// Extract image information
 		imageInfo := a.extractImageInfo(obj, pageNum)
 		if imageInfo != nil {
+			placeImage(imageInfo, placements[key], scan.minDPI)
+			if scan.classify != nil {
+				scan.classify(obj, imageInfo)
+			}
 			images = append(images, *imageInfo)
 		}
 	}
@@ -233,3 +275,32 @@ func (a *Assets) GetSupportedFormats() []string {
 		"RLE",
 	}
 }
+
+// placeImage records the size and resolution of each drawing of an image, flagging the
+// image when any of them falls below minDPI
+func placeImage(info *ImageInfo, placements []extraction.ImagePlacement, minDPI float64) {
+	round := func(v float64) float64 { return math.Round(v*placementPrecision) / placementPrecision }
+	for _, placement := range placements {
+		width, height := placement.Size()
+		if width == 0 || height == 0 {
+			continue
+		}
+		dpiX := float64(info.Width) / (width / pointsPerInch)
+		dpiY := float64(info.Height) / (height / pointsPerInch)
+		effective := round(math.Min(dpiX, dpiY))
+		info.Placements = append(info.Placements, ImagePlacement{
+			BoundingBox:  convertBoundingBox(placement.BoundingBox),
+			WidthPoints:  round(width),
+			HeightPoints: round(height),
+			WidthInches:  round(width / pointsPerInch),
+			HeightInches: round(height / pointsPerInch),
+			DPIX:         round(dpiX),
+			DPIY:         round(dpiY),
+			EffectiveDPI: effective,
+		})
+		if info.EffectiveDPI == 0 || effective < info.EffectiveDPI {
+			info.EffectiveDPI = effective
+		}
+	}
+	info.LowDPI = len(info.Placements) > 0 && info.EffectiveDPI < minDPI
+}
@@ -1,6 +1,8 @@
 package pdf
 
 import (
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -470,3 +472,145 @@ func TestAssets_PanicRecovery(t *testing.T) {
 		}
 	})
 }
+
+func TestAssets_ExtractAssetsClassify(t *testing.T) {
+	barcode := barImage(code128Modules, 2, 40)
+
+	// The QR code is an image mask of one bit a pixel, its Decode array painting the 1s
+	qr := moduleImage(qrVersion2M, 3)
+	stride := (qr.Rect.Dx() + 7) / 8
+	mask := make([]byte, stride*qr.Rect.Dy())
+	for y := 0; y < qr.Rect.Dy(); y++ {
+		for x := 0; x < qr.Rect.Dx(); x++ {
+			if qr.GrayAt(x, y).Y == 0 {
+				mask[y*stride+x/8] |= 0x80 >> (x % 8)
+			}
+		}
+	}
+
+	path := createTempFile(t, "label.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R " +
+			"/Resources << /XObject << /Bar 5 0 R /QR 6 0 R /Fax 7 0 R >> >> >>",
+		"<< /Length 0 >>\nstream\n\nendstream",
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray "+
+			"/BitsPerComponent 8 /Length %d >>\nstream\n%s\nendstream",
+			barcode.Rect.Dx(), barcode.Rect.Dy(), len(barcode.Pix), barcode.Pix),
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ImageMask true /Decode [1 0] "+
+			"/Length %d >>\nstream\n%s\nendstream",
+			qr.Rect.Dx(), qr.Rect.Dy(), len(mask), mask),
+		"<< /Type /XObject /Subtype /Image /Width 8 /Height 8 /ColorSpace /DeviceGray /BitsPerComponent 1 " +
+			"/Filter /CCITTFaxDecode /Length 4 >>\nstream\nfax!\nendstream",
+	}))
+
+	result, err := NewAssets(1024 * 1024).ExtractAssets(PDFAssetsFileRequest{Path: path, Classify: true})
+	if err != nil {
+		t.Fatalf("ExtractAssets() unexpected error = %v", err)
+	}
+	if result.TotalCount != 3 || result.Classified != 2 {
+		t.Fatalf("ExtractAssets() found %d images and classified %d, want 3 and 2", result.TotalCount, result.Classified)
+	}
+
+	kinds := map[string]ImageInfo{}
+	for _, img := range result.Images {
+		kinds[img.Kind] = img
+	}
+	if got := kinds[ImageKindBarcode]; got.Symbology != SymbologyCode128 || got.Payload != "INV-2024-0042" {
+		t.Errorf("barcode image = %+v, want its Code 128 payload", got)
+	}
+	if got := kinds[ImageKindQRCode]; got.Symbology != SymbologyQR || got.Payload != "https://example.com/inv/42" {
+		t.Errorf("QR code image = %+v, want its payload", got)
+	}
+	if got, ok := kinds[""]; !ok || got.Confidence != 0 {
+		t.Errorf("fax image = %+v, want it listed without a kind", got)
+	}
+
+	// Without classify, images are only listed
+	result, err = NewAssets(1024 * 1024).ExtractAssets(PDFAssetsFileRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractAssets() unexpected error = %v", err)
+	}
+	for _, img := range result.Images {
+		if img.Kind != "" || result.Classified != 0 {
+			t.Errorf("ExtractAssets() without classify gave %+v", img)
+		}
+	}
+}
+
+func TestAssets_ExtractAssetsPlacements(t *testing.T) {
+	// A 60x30 pixel image drawn at 20x10 points and again turned a quarter at 28.8x14.4
+	// points, and a second image the page never draws
+	pixels := strings.Repeat("\x80", 60*30)
+	path := createTempFile(t, "placed.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R " +
+			"/Resources << /XObject << /Photo 5 0 R /Unused 5 0 R >> >> >>",
+		"<< /Length 73 >>\nstream\nq 20 0 0 10 72 700 cm /Photo Do Q q 0 28.8 -14.4 0 300 500 cm /Photo Do Q\nendstream",
+		"<< /Type /XObject /Subtype /Image /Width 60 /Height 30 /ColorSpace /DeviceGray /BitsPerComponent 8 " +
+			"/Length 1800 >>\nstream\n" + pixels + "\nendstream",
+	}))
+
+	result, err := NewAssets(1024 * 1024).ExtractAssets(PDFAssetsFileRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractAssets() unexpected error = %v", err)
+	}
+	if result.TotalCount != 2 || result.MinDPI != defaultMinImageDPI || result.LowDPI != 1 {
+		t.Fatalf("ExtractAssets() = %+v, want two images with one below 300 DPI", result)
+	}
+
+	var photo, unused ImageInfo
+	for _, img := range result.Images {
+		if len(img.Placements) > 0 {
+			photo = img
+		} else {
+			unused = img
+		}
+	}
+	want := []ImagePlacement{
+		{
+			BoundingBox: Rectangle{X: 72, Y: 700, Width: 20, Height: 10},
+			WidthPoints: 20, HeightPoints: 10, WidthInches: 0.28, HeightInches: 0.14,
+			DPIX: 216, DPIY: 216, EffectiveDPI: 216,
+		},
+		{
+			BoundingBox: Rectangle{X: 285.6, Y: 500, Width: 14.4, Height: 28.8},
+			WidthPoints: 28.8, HeightPoints: 14.4, WidthInches: 0.4, HeightInches: 0.2,
+			DPIX: 150, DPIY: 150, EffectiveDPI: 150,
+		},
+	}
+	if len(photo.Placements) != len(want) {
+		t.Fatalf("photo placements = %+v, want %+v", photo.Placements, want)
+	}
+	for i, placement := range photo.Placements {
+		box, wantBox := placement.BoundingBox, want[i].BoundingBox
+		if math.Abs(box.X-wantBox.X)+math.Abs(box.Y-wantBox.Y)+
+			math.Abs(box.Width-wantBox.Width)+math.Abs(box.Height-wantBox.Height) > 1e-6 {
+			t.Errorf("placement %d box = %+v, want %+v", i, box, wantBox)
+		}
+		placement.BoundingBox = wantBox
+		if placement != want[i] {
+			t.Errorf("placement %d = %+v, want %+v", i, placement, want[i])
+		}
+	}
+	if photo.EffectiveDPI != 150 || !photo.LowDPI {
+		t.Errorf("photo effective DPI = %g (low %t), want 150 flagged low", photo.EffectiveDPI, photo.LowDPI)
+	}
+	if unused.EffectiveDPI != 0 || unused.LowDPI {
+		t.Errorf("undrawn image = %+v, want no resolution", unused)
+	}
+
+	// A lower threshold passes the photo
+	result, err = NewAssets(1024 * 1024).ExtractAssets(PDFAssetsFileRequest{Path: path, MinDPI: 150})
+	if err != nil {
+		t.Fatalf("ExtractAssets() unexpected error = %v", err)
+	}
+	if result.LowDPI != 0 {
+		t.Errorf("ExtractAssets(min_dpi 150) flagged %d images, want none", result.LowDPI)
+	}
+
+	if _, err := NewAssets(1024 * 1024).ExtractAssets(PDFAssetsFileRequest{Path: path, MinDPI: -1}); err == nil {
+		t.Error("ExtractAssets(min_dpi -1) expected error but got none")
+	}
+}
@@ -0,0 +1,246 @@
+package pdf
+
+import (
+	"crypto/md5" //nolint:gosec // MD5 is the checksum algorithm defined for embedded file streams
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+	"github.com/ledongthuc/pdf"
+)
+
+// Attachment sources
+const (
+	AttachmentSourceEmbeddedFiles = "embedded_files"
+	AttachmentSourceAnnotation    = "annotation"
+
+	// attachmentFilePerm is the permission used for saved attachments
+	attachmentFilePerm = 0o600
+	// attachmentDirPerm is the permission used when creating the output directory
+	attachmentDirPerm = 0o750
+	// defaultAttachmentMIME is reported when neither the PDF nor the file name identify the type
+	defaultAttachmentMIME = "application/octet-stream"
+)
+
+// Attachments handles extraction of embedded files from PDF documents
+type Attachments struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewAttachments creates a new attachment extractor with the specified constraints
+func NewAttachments(maxFileSize int64) *Attachments {
+	return &Attachments{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// ExtractAttachments lists the files embedded in a PDF and optionally saves or returns their payloads
+func (a *Attachments) ExtractAttachments(
+	req PDFExtractAttachmentsRequest,
+) (result *PDFExtractAttachmentsResult, err error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: a.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	if req.OutputDir != "" {
+		if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+			return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+		}
+	}
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			result = nil
+			err = fmt.Errorf("failed to read attachments: %v", rec)
+		}
+	}()
+
+	result = &PDFExtractAttachmentsResult{
+		Path:        req.Path,
+		OutputDir:   req.OutputDir,
+		Attachments: []AttachmentInfo{},
+	}
+
+	wanted := make(map[string]bool, len(req.Names))
+	for _, name := range req.Names {
+		wanted[name] = true
+	}
+
+	savedNames := make(map[string]bool)
+	add := func(spec pdf.Value, key, source string, page int, pageLabel string) {
+		info, payload := a.readFileSpec(spec, key)
+		info.Source = source
+		info.Page = page
+		info.PageLabel = pageLabel
+		if len(wanted) > 0 && !wanted[info.Name] {
+			return
+		}
+
+		if payload != nil {
+			if req.IncludeContent {
+				info.Content = base64.StdEncoding.EncodeToString(payload)
+			}
+			if req.OutputDir != "" {
+				saved, err := saveAttachment(req.OutputDir, info.Name, payload, savedNames)
+				if err != nil {
+					info.Error = err.Error()
+				}
+				info.SavedPath = saved
+			}
+		}
+		result.Attachments = append(result.Attachments, info)
+	}
+
+	root := r.Trailer().Key("Root")
+	extraction.WalkNameTree(root.Key("Names").Key("EmbeddedFiles"), func(key string, spec pdf.Value) {
+		add(spec, key, AttachmentSourceEmbeddedFiles, 0, "")
+	})
+
+	numbering := extraction.NewPageNumbering(r)
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		annots := numbering.Page(pageNum).V.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			annot := annots.Index(i)
+			if annot.Key("Subtype").Name() != "FileAttachment" {
+				continue
+			}
+			add(annot.Key("FS"), annot.Key("Contents").Text(), AttachmentSourceAnnotation, pageNum,
+				numbering.Label(pageNum))
+		}
+	}
+
+	result.TotalCount = len(result.Attachments)
+	for _, info := range result.Attachments {
+		result.TotalSize += info.Size
+	}
+
+	return result, nil
+}
+
+// readFileSpec describes a file specification and reads its embedded payload. Payload
+// errors are recorded on the returned info so that one broken attachment does not hide the rest.
+func (a *Attachments) readFileSpec(spec pdf.Value, key string) (info AttachmentInfo, payload []byte) {
+	info.Name = fileSpecName(spec, key)
+	info.Description = spec.Key("Desc").Text()
+
+	stream := spec.Key("EF").Key("UF")
+	if stream.Kind() != pdf.Stream {
+		stream = spec.Key("EF").Key("F")
+	}
+	if stream.Kind() != pdf.Stream {
+		info.MIMEType = guessAttachmentMIME(info.Name, "")
+		info.Error = "attachment has no embedded file stream"
+		return info, nil
+	}
+
+	params := stream.Key("Params")
+	info.MIMEType = guessAttachmentMIME(info.Name, stream.Key("Subtype").Name())
+	info.Size = params.Key("Size").Int64()
+	info.CreationDate = formatPDFDate(params.Key("CreationDate").RawString())
+	info.ModDate = formatPDFDate(params.Key("ModDate").RawString())
+
+	payload, err := a.readStream(stream)
+	if err != nil {
+		info.Error = err.Error()
+		return info, nil
+	}
+
+	sum := md5.Sum(payload) //nolint:gosec // see import
+	info.Size = int64(len(payload))
+	info.Checksum = hex.EncodeToString(sum[:])
+	if expected := params.Key("CheckSum").RawString(); expected != "" {
+		info.ChecksumMismatch = hex.EncodeToString([]byte(expected)) != info.Checksum
+	}
+
+	return info, payload
+}
+
+// readStream decodes an embedded file stream, bounded by the maximum file size
+func (a *Attachments) readStream(stream pdf.Value) (data []byte, err error) {
+	// Unsupported filters panic inside the parser
+	defer func() {
+		if rec := recover(); rec != nil {
+			data = nil
+			err = fmt.Errorf("cannot decode attachment: %v", rec)
+		}
+	}()
+
+	rc := stream.Reader()
+	defer rc.Close()
+
+	data, err = io.ReadAll(io.LimitReader(rc, a.maxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode attachment: %w", err)
+	}
+	if int64(len(data)) > a.maxFileSize {
+		return nil, fmt.Errorf("attachment too large (max: %d bytes)", a.maxFileSize)
+	}
+	return data, nil
+}
+
+// fileSpecName returns the most specific file name from a file specification
+func fileSpecName(spec pdf.Value, fallback string) string {
+	if name := extraction.FileSpecPath(spec); name != "" {
+		return name
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return "attachment"
+}
+
+// guessAttachmentMIME prefers the MIME type declared in the PDF and falls back to the file extension
+func guessAttachmentMIME(name, subtype string) string {
+	if strings.Contains(subtype, "/") {
+		return subtype
+	}
+	if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); byExt != "" {
+		return strings.SplitN(byExt, ";", 2)[0]
+	}
+	return defaultAttachmentMIME
+}
+
+// saveAttachment writes a payload into dir under a sanitized, unique file name
+func saveAttachment(dir, name string, payload []byte, used map[string]bool) (string, error) {
+	base := filepath.Base(filepath.Clean("/" + strings.ReplaceAll(name, `\`, "/")))
+	if base == "/" || base == "." {
+		base = "attachment"
+	}
+
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	candidate := base
+	for i := 2; ; i++ {
+		target := filepath.Join(dir, candidate)
+		if _, err := fsys.Stat(target); os.IsNotExist(err) && !used[candidate] {
+			break
+		}
+		candidate = stem + "_" + strconv.Itoa(i) + ext
+	}
+	used[candidate] = true
+
+	target := filepath.Join(dir, candidate)
+	if err := fsys.WriteFile(target, payload, attachmentFilePerm); err != nil {
+		return "", fmt.Errorf("failed to save attachment: %w", err)
+	}
+	return target, nil
+}
@@ -0,0 +1,154 @@
+package pdf
+
+import (
+	"crypto/md5" //nolint:gosec // matches the embedded file checksum algorithm
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	invoiceXML = "<invoice><total>42.00</total></invoice>"
+	notesText  = "Reviewed and approved"
+)
+
+// embeddedFileStream builds an uncompressed embedded file stream object
+func embeddedFileStream(subtype, data, extraParams string) string {
+	return fmt.Sprintf("<< /Type /EmbeddedFile /Subtype %s /Length %d /Params << /Size %d %s >> >>\nstream\n%s\nendstream",
+		subtype, len(data), len(data), extraParams, data)
+}
+
+// attachmentsPDFContent builds a one-page document with a document-level attachment in the
+// EmbeddedFiles name tree and a file attachment annotation on the page
+func attachmentsPDFContent() string {
+	sum := md5.Sum([]byte(invoiceXML)) //nolint:gosec // see import
+	return buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Names << /EmbeddedFiles << /Names [(invoice.xml) 4 0 R] >> >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [6 0 R] >>",
+		"<< /Type /Filespec /F (invoice.xml) /UF (invoice.xml) /Desc (ZUGFeRD invoice data) /EF << /F 5 0 R >> >>",
+		embeddedFileStream("/text#2Fxml", invoiceXML,
+			"/CreationDate (D:20240131120000Z) /CheckSum <"+hex.EncodeToString(sum[:])+">"),
+		"<< /Type /Annot /Subtype /FileAttachment /Rect [72 700 90 720] /Contents (Notes) /FS 7 0 R >>",
+		"<< /Type /Filespec /F (../notes.txt) /EF << /F 8 0 R >> >>",
+		embeddedFileStream("/Unknown", notesText, ""),
+	})
+}
+
+func TestAttachments_ExtractAttachmentsErrors(t *testing.T) {
+	attachments := NewAttachments(100 * 1024 * 1024)
+
+	tests := []struct {
+		name     string
+		path     string
+		errorMsg string
+	}{
+		{
+			name:     "empty path",
+			path:     "",
+			errorMsg: "path cannot be empty",
+		},
+		{
+			name:     "non-existent file",
+			path:     "/non/existent/file.pdf",
+			errorMsg: "file does not exist",
+		},
+		{
+			name:     "directory instead of file",
+			path:     createTempDir(t),
+			errorMsg: "path is a directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := attachments.ExtractAttachments(PDFExtractAttachmentsRequest{Path: tt.path})
+			if err == nil {
+				t.Fatal("ExtractAttachments() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("ExtractAttachments() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestAttachments_ExtractAttachments(t *testing.T) {
+	attachments := NewAttachments(100 * 1024 * 1024)
+	path := createTempFile(t, "invoice.pdf", attachmentsPDFContent())
+	outputDir := filepath.Join(createTempDir(t), "out")
+
+	result, err := attachments.ExtractAttachments(PDFExtractAttachmentsRequest{
+		Path:           path,
+		OutputDir:      outputDir,
+		IncludeContent: true,
+	})
+	if err != nil {
+		t.Fatalf("ExtractAttachments() unexpected error = %v", err)
+	}
+	if result.TotalCount != 2 {
+		t.Fatalf("ExtractAttachments() count = %d, want 2", result.TotalCount)
+	}
+
+	invoice := result.Attachments[0]
+	if invoice.Name != "invoice.xml" || invoice.MIMEType != "text/xml" || invoice.Source != AttachmentSourceEmbeddedFiles {
+		t.Errorf("invoice = %+v, want invoice.xml text/xml from embedded files", invoice)
+	}
+	if invoice.Size != int64(len(invoiceXML)) || invoice.ChecksumMismatch || invoice.Error != "" {
+		t.Errorf("invoice size = %d, mismatch = %v, error = %q", invoice.Size, invoice.ChecksumMismatch, invoice.Error)
+	}
+	if invoice.CreationDate != "2024-01-31T12:00:00Z" {
+		t.Errorf("invoice creation date = %q, want 2024-01-31T12:00:00Z", invoice.CreationDate)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(invoice.Content)
+	if err != nil || string(decoded) != invoiceXML {
+		t.Errorf("invoice content = %q, want %q", decoded, invoiceXML)
+	}
+
+	notes := result.Attachments[1]
+	if notes.Source != AttachmentSourceAnnotation || notes.Page != 1 || notes.MIMEType != "text/plain" {
+		t.Errorf("notes = %+v, want text/plain annotation on page 1", notes)
+	}
+
+	// Saved names are reduced to their base name so they stay inside the output directory
+	if notes.SavedPath != filepath.Join(outputDir, "notes.txt") {
+		t.Errorf("notes saved path = %q, want inside %s", notes.SavedPath, outputDir)
+	}
+	saved, err := os.ReadFile(notes.SavedPath)
+	if err != nil || string(saved) != notesText {
+		t.Errorf("saved notes = %q (%v), want %q", saved, err, notesText)
+	}
+}
+
+func TestAttachments_ExtractAttachmentsByName(t *testing.T) {
+	attachments := NewAttachments(100 * 1024 * 1024)
+	path := createTempFile(t, "invoice.pdf", attachmentsPDFContent())
+
+	result, err := attachments.ExtractAttachments(PDFExtractAttachmentsRequest{
+		Path:  path,
+		Names: []string{"invoice.xml"},
+	})
+	if err != nil {
+		t.Fatalf("ExtractAttachments() unexpected error = %v", err)
+	}
+	if result.TotalCount != 1 || result.Attachments[0].Content != "" || result.Attachments[0].SavedPath != "" {
+		t.Errorf("ExtractAttachments() = %+v, want only invoice.xml metadata", result.Attachments)
+	}
+}
+
+func TestAttachments_ExtractAttachmentsNone(t *testing.T) {
+	attachments := NewAttachments(100 * 1024 * 1024)
+	path := createTempFile(t, "plain.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Hello) Tj ET"))
+
+	result, err := attachments.ExtractAttachments(PDFExtractAttachmentsRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractAttachments() unexpected error = %v", err)
+	}
+	if result.TotalCount != 0 {
+		t.Errorf("ExtractAttachments() count = %d, want 0", result.TotalCount)
+	}
+}
@@ -0,0 +1,469 @@
+package pdf
+
+import (
+	"image"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Barcode symbologies
+const (
+	SymbologyCode128 = "code128"
+	SymbologyEAN13   = "ean13"
+	SymbologyCode39  = "code39"
+	SymbologyQR      = "qr"
+)
+
+// Linear barcode decoding constants
+const (
+	// maxModuleDeviation is how far, in modules summed over a symbol's runs, the runs may
+	// stray from the pattern they are read as
+	maxModuleDeviation = 1.5
+	// code39WideRatio is how many times the width of the widest narrow element the
+	// narrowest wide element must be
+	code39WideRatio = 1.5
+	// minBarcodeRuns is how many bars and spaces a scanline crosses for the image to look
+	// like a barcode that could not be decoded
+	minBarcodeRuns = 40
+	// barcodeColumnAgreement is the share of columns that must be the same on every scanline
+	// through the middle of such an image
+	barcodeColumnAgreement = 0.95
+)
+
+// code128Patterns are the bar and space widths of the Code 128 symbols by value; the last
+// is the stop pattern
+var code128Patterns = []string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code 128 symbol values with special meanings
+const (
+	code128Shift  = 98
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128CodeA  = 101
+	code128FNC1   = 102
+	code128StartA = 103
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// eanDigitPatterns are the widths of the EAN left-hand odd-parity digits; even-parity
+// digits run the other way and right-hand digits are the same widths starting with a bar
+var eanDigitPatterns = []string{"3211", "2221", "2122", "1411", "1132", "1231", "1114", "1312", "1213", "3112"}
+
+// eanFirstDigitParity gives the parity pattern of the six left-hand digits that encodes the
+// first digit of an EAN-13 code, with G marking even parity
+var eanFirstDigitParity = []string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// code39Alphabet and code39Patterns give the Code 39 characters and their narrow and wide
+// bars and spaces; '*' starts and stops a code
+const code39Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-. $/+%*"
+
+var code39Patterns = []string{
+	"nnnwwnwnn", "wnnwnnnnw", "nnwwnnnnw", "wnwwnnnnn", "nnnwwnnnw", "wnnwwnnnn", "nnwwwnnnn", "nnnwnnwnw",
+	"wnnwnnwnn", "nnwwnnwnn", "wnnnnwnnw", "nnwnnwnnw", "wnwnnwnnn", "nnnnwwnnw", "wnnnwwnnn", "nnwnwwnnn",
+	"nnnnnwwnw", "wnnnnwwnn", "nnwnnwwnn", "nnnnwwwnn", "wnnnnnnww", "nnwnnnnww", "wnwnnnnwn", "nnnnwnnww",
+	"wnnnwnnwn", "nnwnwnnwn", "nnnnnnwww", "wnnnnnwwn", "nnwnnnwwn", "nnnnwnwwn", "wwnnnnnnw", "nwwnnnnnw",
+	"wwwnnnnnn", "nwnnwnnnw", "wwnnwnnnn", "nwwnwnnnn", "nwnnnnwnw", "wwnnnnwnn", "nwwnnnwnn", "nwnwnwnnn",
+	"nwnwnnnwn", "nwnnnwnwn", "nnnwnwnwn", "nwnnwnwnn",
+}
+
+// bitmap is a binarized image, true where it is dark
+type bitmap struct {
+	width, height int
+	dark          []bool
+}
+
+// at reports whether a pixel is dark; pixels outside the bitmap are light
+func (b bitmap) at(x, y int) bool {
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return false
+	}
+	return b.dark[y*b.width+x]
+}
+
+// binarize returns the luminance of an image's pixels and a bitmap dark where the
+// luminance falls below the threshold that best separates ink from background
+func binarize(img image.Image) ([]uint8, bitmap) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	luminance := make([]uint8, width*height)
+	var histogram [256]int
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			l := uint8((299*r + 587*g + 114*b) / 1000 >> 8)
+			luminance[y*width+x] = l
+			histogram[l]++
+		}
+	}
+
+	threshold := otsuThreshold(histogram, width*height)
+	b := bitmap{width: width, height: height, dark: make([]bool, width*height)}
+	for i, l := range luminance {
+		b.dark[i] = int(l) <= threshold
+	}
+	return luminance, b
+}
+
+// otsuThreshold returns the luminance that splits a histogram into the two classes with the
+// greatest variance between them
+func otsuThreshold(histogram [256]int, total int) int {
+	var sum float64
+	for l, count := range histogram {
+		sum += float64(l * count)
+	}
+	var below, sumBelow, best float64
+	threshold := -1
+	for l, count := range histogram {
+		below += float64(count)
+		if below == 0 {
+			continue
+		}
+		above := float64(total) - below
+		if above == 0 {
+			break
+		}
+		sumBelow += float64(l * count)
+		meanBelow, meanAbove := sumBelow/below, (sum-sumBelow)/above
+		if variance := below * above * (meanBelow - meanAbove) * (meanBelow - meanAbove); variance > best {
+			best, threshold = variance, l
+		}
+	}
+	return threshold
+}
+
+// decodeLinearBarcode reads a Code 128, EAN-13, or Code 39 barcode from scanlines across
+// the middle of a bitmap, in both directions and, for barcodes printed on their side,
+// down its columns
+func decodeLinearBarcode(b bitmap) (symbology, payload string, ok bool) {
+	for _, share := range []float64{0.5, 0.4, 0.6, 0.3, 0.7, 0.2, 0.8} {
+		rows := barRuns(b.width, func(i int) bool { return b.at(i, int(float64(b.height)*share)) })
+		columns := barRuns(b.height, func(i int) bool { return b.at(int(float64(b.width)*share), i) })
+		for _, runs := range [][]int{rows, columns} {
+			for _, reversed := range []bool{false, true} {
+				line := runs
+				if reversed {
+					line = reverseRuns(runs)
+				}
+				if payload, ok := decodeCode128(line); ok {
+					return SymbologyCode128, payload, true
+				}
+				if payload, ok := decodeEAN13(line); ok {
+					return SymbologyEAN13, payload, true
+				}
+				if payload, ok := decodeCode39(line); ok {
+					return SymbologyCode39, payload, true
+				}
+			}
+		}
+	}
+	return "", "", false
+}
+
+// barRuns returns the lengths of the alternating dark and light runs of a scanline from its
+// first dark pixel to its last, so even indexes are bars
+func barRuns(length int, dark func(int) bool) []int {
+	var runs []int
+	current := true
+	for i := 0; i < length; i++ {
+		d := dark(i)
+		switch {
+		case len(runs) == 0 && !d:
+			continue
+		case len(runs) == 0 || d != current:
+			runs = append(runs, 1)
+			current = d
+		default:
+			runs[len(runs)-1]++
+		}
+	}
+	if len(runs) > 0 && !current {
+		runs = runs[:len(runs)-1]
+	}
+	return runs
+}
+
+// reverseRuns returns the runs of a scanline read the other way
+func reverseRuns(runs []int) []int {
+	reversed := make([]int, len(runs))
+	for i, run := range runs {
+		reversed[len(runs)-1-i] = run
+	}
+	return reversed
+}
+
+// patternDeviation returns how far, in modules, runs stray from the widths of a pattern
+// spanning the given number of modules
+func patternDeviation(runs []int, pattern string, modules int) float64 {
+	total := 0
+	for _, run := range runs {
+		total += run
+	}
+	unit := float64(total) / float64(modules)
+	var deviation float64
+	for i, run := range runs {
+		deviation += math.Abs(float64(run)/unit - float64(pattern[i]-'0'))
+	}
+	return deviation
+}
+
+// matchCode128 returns the value of the Code 128 symbol whose widths the six runs best fit,
+// or -1
+func matchCode128(runs []int) int {
+	best, bestDeviation := -1, maxModuleDeviation
+	for value, pattern := range code128Patterns[:code128Stop] {
+		if deviation := patternDeviation(runs, pattern, 11); deviation < bestDeviation {
+			best, bestDeviation = value, deviation
+		}
+	}
+	return best
+}
+
+// decodeCode128 reads a Code 128 barcode from the runs of a scanline, checking its checksum
+func decodeCode128(runs []int) (string, bool) {
+	const symbolRuns, stopRuns = 6, 7
+	for start := 0; start+symbolRuns+stopRuns <= len(runs); start += 2 {
+		first := matchCode128(runs[start : start+symbolRuns])
+		if first < code128StartA || first > code128StartC {
+			continue
+		}
+		values := []int{first}
+		for i := start + symbolRuns; i+symbolRuns <= len(runs); i += symbolRuns {
+			if i+stopRuns <= len(runs) &&
+				patternDeviation(runs[i:i+stopRuns], code128Patterns[code128Stop], 13) < maxModuleDeviation {
+				if payload, ok := code128Text(values); ok {
+					return payload, true
+				}
+				break
+			}
+			value := matchCode128(runs[i : i+symbolRuns])
+			if value < 0 || value >= code128StartA {
+				break
+			}
+			values = append(values, value)
+		}
+	}
+	return "", false
+}
+
+// code128Text checks the checksum closing a run of Code 128 values and returns the text
+// they encode
+func code128Text(values []int) (string, bool) {
+	if len(values) < 3 {
+		return "", false
+	}
+	sum := values[0]
+	for i := 1; i < len(values)-1; i++ {
+		sum += i * values[i]
+	}
+	if sum%103 != values[len(values)-1] {
+		return "", false
+	}
+
+	set := map[int]byte{code128StartA: 'A', code128StartB: 'B', code128StartC: 'C'}[values[0]]
+	shifted := false
+	var text strings.Builder
+	for _, value := range values[1 : len(values)-1] {
+		current := set
+		if shifted {
+			current = map[byte]byte{'A': 'B', 'B': 'A'}[set]
+			shifted = false
+		}
+		switch {
+		case current == 'C' && value < 100:
+			text.WriteString(string([]byte{'0' + byte(value/10), '0' + byte(value%10)}))
+		case current == 'C' && value == code128CodeB, current == 'A' && value == code128CodeB:
+			set = 'B'
+		case current == 'C' && value == code128CodeA, current == 'B' && value == code128CodeA:
+			set = 'A'
+		case current != 'C' && value == code128CodeC:
+			set = 'C'
+		case current != 'C' && value == code128Shift:
+			shifted = true
+		case value == code128FNC1 || value >= 96:
+			// Function codes carry no text
+		case current == 'A' && value >= 64:
+			text.WriteByte(byte(value - 64))
+		default:
+			text.WriteByte(byte(value + 32))
+		}
+	}
+	return text.String(), true
+}
+
+// decodeEAN13 reads an EAN-13 (or UPC-A) barcode from the runs of a scanline, checking its
+// check digit
+func decodeEAN13(runs []int) (string, bool) {
+	const totalRuns, totalModules = 59, 95
+	guard := func(runs []int) bool {
+		return patternDeviation(runs, strings.Repeat("1", len(runs)), len(runs)) < maxModuleDeviation
+	}
+	for start := 0; start+totalRuns <= len(runs); start += 2 {
+		span := runs[start : start+totalRuns]
+		total := 0
+		for _, run := range span {
+			total += run
+		}
+		unit := float64(total) / totalModules
+		// The guards are one module wide
+		if !guard(span[:3]) || !guard(span[27:32]) || !guard(span[56:]) ||
+			math.Abs(float64(span[0])-unit) > unit/2 {
+			continue
+		}
+
+		digits := make([]byte, 13)
+		parity := ""
+		ok := true
+		for i := 0; i < 12 && ok; i++ {
+			offset := 3 + i*4
+			if i >= 6 {
+				offset = 32 + (i-6)*4
+			}
+			digit, even := matchEANDigit(span[offset:offset+4], i < 6)
+			if digit < 0 {
+				ok = false
+				break
+			}
+			digits[i+1] = '0' + byte(digit)
+			switch {
+			case i < 6 && even:
+				parity += "G"
+			case i < 6:
+				parity += "L"
+			}
+		}
+		if !ok {
+			continue
+		}
+		first := -1
+		for digit, pattern := range eanFirstDigitParity {
+			if pattern == parity {
+				first = digit
+			}
+		}
+		if first < 0 {
+			continue
+		}
+		digits[0] = '0' + byte(first)
+
+		sum := 0
+		for i, digit := range digits[:12] {
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += int(digit-'0') * weight
+		}
+		if (10-sum%10)%10 == int(digits[12]-'0') {
+			return string(digits), true
+		}
+	}
+	return "", false
+}
+
+// matchEANDigit returns the digit whose widths four runs best fit and, on the left-hand
+// side, whether it has even parity, or -1
+func matchEANDigit(runs []int, left bool) (int, bool) {
+	best, even, bestDeviation := -1, false, maxModuleDeviation
+	for digit, pattern := range eanDigitPatterns {
+		if deviation := patternDeviation(runs, pattern, 7); deviation < bestDeviation {
+			best, even, bestDeviation = digit, false, deviation
+		}
+		if !left {
+			continue
+		}
+		reversed := string([]byte{pattern[3], pattern[2], pattern[1], pattern[0]})
+		if deviation := patternDeviation(runs, reversed, 7); deviation < bestDeviation {
+			best, even, bestDeviation = digit, true, deviation
+		}
+	}
+	return best, even
+}
+
+// matchCode39 returns the Code 39 character of nine runs with three wide, or 0
+func matchCode39(runs []int) byte {
+	sorted := slices.Clone(runs)
+	slices.Sort(sorted)
+	narrowestWide, widestNarrow := sorted[len(sorted)-3], sorted[len(sorted)-4]
+	if float64(narrowestWide) < float64(widestNarrow)*code39WideRatio {
+		return 0
+	}
+	pattern := make([]byte, len(runs))
+	for i, run := range runs {
+		pattern[i] = 'n'
+		if run >= narrowestWide {
+			pattern[i] = 'w'
+		}
+	}
+	for i, p := range code39Patterns {
+		if p == string(pattern) {
+			return code39Alphabet[i]
+		}
+	}
+	return 0
+}
+
+// decodeCode39 reads a Code 39 barcode, framed by '*', from the runs of a scanline
+func decodeCode39(runs []int) (string, bool) {
+	const charRuns = 9
+	for start := 0; start+2*charRuns <= len(runs); start += 2 {
+		if matchCode39(runs[start:start+charRuns]) != '*' {
+			continue
+		}
+		var text strings.Builder
+		for i := start + charRuns + 1; i+charRuns <= len(runs); i += charRuns + 1 {
+			char := matchCode39(runs[i : i+charRuns])
+			if char == 0 {
+				break
+			}
+			if char == '*' {
+				if text.Len() > 0 {
+					return text.String(), true
+				}
+				break
+			}
+			text.WriteByte(char)
+		}
+	}
+	return "", false
+}
+
+// looksLikeBarcode reports whether scanlines through the middle of a bitmap cross many bars
+// that run straight across all of them, as an undecoded barcode's do
+func looksLikeBarcode(b bitmap) bool {
+	middle := b.height / 2
+	if len(barRuns(b.width, func(x int) bool { return b.at(x, middle) })) < minBarcodeRuns {
+		return false
+	}
+	rows := []int{b.height * 3 / 10, b.height * 4 / 10, middle, b.height * 6 / 10, b.height * 7 / 10}
+	agreeing := 0
+	for x := 0; x < b.width; x++ {
+		same := true
+		for _, y := range rows[1:] {
+			if b.at(x, y) != b.at(x, rows[0]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			agreeing++
+		}
+	}
+	return float64(agreeing) >= float64(b.width)*barcodeColumnAgreement
+}
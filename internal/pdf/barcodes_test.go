@@ -0,0 +1,211 @@
+package pdf
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// qrVersion2M encodes "https://example.com/inv/42" at error correction level M
+var qrVersion2M = []string{
+	"#######.####..#...#######",
+	"#.....#.#..#....#.#.....#",
+	"#.###.#.#..####.#.#.###.#",
+	"#.###.#...#..#..#.#.###.#",
+	"#.###.#.#..####.#.#.###.#",
+	"#.....#...###.#.#.#.....#",
+	"#######.#.#.#.#.#.#######",
+	".........#####.##........",
+	"#..#######.##...##..#.###",
+	".##.##.....#######.#####.",
+	"#..#.###....##.###.###..#",
+	".#.#.#...####.#..###.####",
+	"...####.#####..##.##....#",
+	"######.###..#.###...#..#.",
+	"##.#.##.#.#.##.##.#.#####",
+	"#..###.#...##....###.##.#",
+	"#...#.##.#.#.#..#####.##.",
+	"........##..##..#...#.##.",
+	"#######.#####...#.#.#...#",
+	"#.....#.##...####...#....",
+	"#.###.#.##....#######....",
+	"#.###.#.##.##...###....##",
+	"#.###.#..###.#.#.#..#####",
+	"#.....#..#.....#...##.###",
+	"#######.#...#.#.#.#..#..#",
+}
+
+// qrVersion4Q encodes "https://example.com/inv/2024-0042" at level Q, in two blocks
+var qrVersion4Q = []string{
+	"#######.######....####.#..#######",
+	"#.....#..#..#.###.#.##..#.#.....#",
+	"#.###.#..#..##.#.....##.#.#.###.#",
+	"#.###.#.....#.....##.#..#.#.###.#",
+	"#.###.#.#.#..#####..#.#...#.###.#",
+	"#.....#.#####...##.#.####.#.....#",
+	"#######.#.#.#.#.#.#.#.#.#.#######",
+	".........#.#.####.####.#.........",
+	".#######..#..##.##.#..##...##...#",
+	"#.#.#..##.#...#..#.##..#..##.####",
+	"##.##.###...#.####..#.#.....#.#..",
+	"##.###......#..######.......###.#",
+	"#...###..####.###..#####....##...",
+	"#..##..###.#.#..###..#..####..###",
+	"...#..##.#.##.##..#.###..###..##.",
+	"#.###..##..#.####....##.####..#..",
+	"#####.#...#..#####......##.###..#",
+	".#...#......##...#.#.#.#..##.####",
+	"..##..##.#....#.....##...#.##.#..",
+	"#.####.#.#..#.#.#...###.#..######",
+	"#...###.##.######..#.#..#...##.##",
+	"#.###......#..##.##.#.#..###..#.#",
+	"#..##.#.####.#.....#..####.....#.",
+	"#..#.#.####....##..###.#.#..#.##.",
+	"#...#.##.....##....#..########...",
+	"........#.#.###.#.###...#...#.#.#",
+	"#######.#..#..##.##.#.###.#.#.#..",
+	"#.....#.##....#..####..##...###.#",
+	"#.###.#.#.##..#########.######.#.",
+	"#.###.#.##.#..##.#...#..##..###.#",
+	"#.###.#.##.....#....#....###..#..",
+	"#.....#.####.#..#....##.#...#.#..",
+	"#######.......#####...#.#.##...#.",
+}
+
+// Modules of linear barcodes, 1 for a bar
+const (
+	code128Modules = "1101001000011000100010101110001101110101100010011011100101110111101100100111011101001100101111011101" +
+		"0011011100101110111101101100110010110111000110001010001100011101011"
+	ean13Modules  = "10100011010100111010111101111010001001011001101010100001010000101000010111010010000101100110101"
+	code39Modules = "1001011011010110101001011010110100101101101101001010100101011011011010010101101011001010110110110010" +
+		"1010100101101101"
+)
+
+// moduleImage draws a QR code's modules, '#' for dark, scale pixels a side with a quiet
+// zone of four modules
+func moduleImage(rows []string, scale int) *image.Gray {
+	size := (len(rows) + 8) * scale
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	for y, row := range rows {
+		for x, module := range row {
+			if module != '#' {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetGray((x+4)*scale+dx, (y+4)*scale+dy, color.Gray{})
+				}
+			}
+		}
+	}
+	return img
+}
+
+// barImage draws a linear barcode's modules, '1' for a bar, scale pixels wide and height
+// pixels tall with a quiet zone of ten modules
+func barImage(modules string, scale, height int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, (len(modules)+20)*scale, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	for i, module := range modules {
+		if module != '1' {
+			continue
+		}
+		for dx := 0; dx < scale; dx++ {
+			for y := 0; y < height; y++ {
+				img.SetGray((i+10)*scale+dx, y, color.Gray{})
+			}
+		}
+	}
+	return img
+}
+
+func TestDecodeLinearBarcode(t *testing.T) {
+	tests := []struct {
+		name          string
+		modules       string
+		scale         int
+		wantSymbology string
+		wantPayload   string
+	}{
+		{"code 128", code128Modules, 2, SymbologyCode128, "INV-2024-0042"},
+		{"code 128 at one pixel a module", code128Modules, 1, SymbologyCode128, "INV-2024-0042"},
+		{"ean-13", ean13Modules, 3, SymbologyEAN13, "4006381333931"},
+		{"code 39", code39Modules, 2, SymbologyCode39, "ABC-123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, b := binarize(barImage(tt.modules, tt.scale, 40))
+			symbology, payload, ok := decodeLinearBarcode(b)
+			if !ok || symbology != tt.wantSymbology || payload != tt.wantPayload {
+				t.Errorf("decodeLinearBarcode() = %s %q (%t), want %s %q",
+					symbology, payload, ok, tt.wantSymbology, tt.wantPayload)
+			}
+		})
+	}
+
+	// A barcode drawn upside down reads the same
+	_, b := binarize(barImage(reverseModules(code128Modules), 2, 40))
+	if _, payload, ok := decodeLinearBarcode(b); !ok || payload != "INV-2024-0042" {
+		t.Errorf("decodeLinearBarcode(upside down) = %q (%t), want the payload", payload, ok)
+	}
+}
+
+// reverseModules returns a barcode's modules right to left
+func reverseModules(modules string) string {
+	reversed := []byte(modules)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	return string(reversed)
+}
+
+func TestDecodeQR(t *testing.T) {
+	damaged := append([]string(nil), qrVersion4Q...)
+	for _, y := range []int{12, 20, 28} {
+		row := []byte(damaged[y])
+		for x := 12; x < 16; x++ {
+			row[x] ^= '#' ^ '.'
+		}
+		damaged[y] = string(row)
+	}
+
+	tests := []struct {
+		name  string
+		rows  []string
+		scale int
+		want  string
+	}{
+		{"version 2", qrVersion2M, 4, "https://example.com/inv/42"},
+		{"version 4 in two blocks", qrVersion4Q, 3, "https://example.com/inv/2024-0042"},
+		{"corrected errors", damaged, 3, "https://example.com/inv/2024-0042"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, b := binarize(moduleImage(tt.rows, tt.scale))
+			payload, detected, ok := decodeQR(b)
+			if !detected || !ok || payload != tt.want {
+				t.Errorf("decodeQR() = %q (detected %t, ok %t), want %q", payload, detected, ok, tt.want)
+			}
+		})
+	}
+
+	// A code turned on its side reads the same
+	turned := make([]string, len(qrVersion2M))
+	for y := range turned {
+		var row strings.Builder
+		for x := range qrVersion2M {
+			row.WriteByte(qrVersion2M[len(qrVersion2M)-1-x][y])
+		}
+		turned[y] = row.String()
+	}
+	_, b := binarize(moduleImage(turned, 4))
+	if payload, _, ok := decodeQR(b); !ok || payload != "https://example.com/inv/42" {
+		t.Errorf("decodeQR(turned) = %q (%t), want the payload", payload, ok)
+	}
+}
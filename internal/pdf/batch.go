@@ -0,0 +1,233 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Batch extraction limits
+const (
+	maxBatchFiles       = 1000 // Documents accepted in one batch
+	defaultBatchWorkers = 4    // Documents extracted concurrently when MaxConcurrency is unset
+	maxBatchWorkers     = 16   // Upper bound on MaxConcurrency
+)
+
+// batchManifestName is the file the batch manifest is written to in the output directory
+const batchManifestName = "manifest.json"
+
+// Batch file statuses
+const (
+	BatchSucceeded = "succeeded"
+	BatchPartial   = "partial" // Some pages are missing, as when the request timed out
+	BatchFailed    = "failed"
+)
+
+// batchModes are the extraction modes a batch can run
+var batchModes = []string{"structured", "semantic", "table", "complete"}
+
+// BatchExtract extracts every document of a directory or list of paths with a bounded pool
+// of workers, writing each document's result as JSON to the output directory along with a
+// manifest of the batch. Documents that cannot be extracted are recorded as failures and do
+// not stop the batch; when ctx ends, documents not yet started are recorded as failed.
+func (s *Service) BatchExtract(ctx context.Context, req PDFBatchExtractRequest) (*PDFBatchExtractResult, error) {
+	if (req.Directory == "") == (len(req.Paths) == 0) {
+		return nil, fmt.Errorf("give exactly one of directory or paths")
+	}
+	if req.OutputDir == "" {
+		return nil, fmt.Errorf("output_dir cannot be empty")
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = "structured"
+	}
+	if !slices.Contains(batchModes, mode) {
+		return nil, fmt.Errorf("invalid mode: %q (must be one of %s)", mode, strings.Join(batchModes, ", "))
+	}
+
+	workers := req.MaxConcurrency
+	switch {
+	case workers < 0:
+		return nil, fmt.Errorf("max_concurrency must be positive, got %d", workers)
+	case workers == 0:
+		workers = defaultBatchWorkers
+	case workers > maxBatchWorkers:
+		workers = maxBatchWorkers
+	}
+
+	paths := req.Paths
+	if req.Directory != "" {
+		files, err := s.FindPDFsInDirectory(req.Directory)
+		if err != nil {
+			return nil, err
+		}
+		paths = make([]string, len(files))
+		for i, file := range files {
+			paths[i] = file.Path
+		}
+	}
+	if len(paths) > maxBatchFiles {
+		return nil, fmt.Errorf("too many files: %d (max: %d)", len(paths), maxBatchFiles)
+	}
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			return nil, fmt.Errorf("path cannot be empty")
+		}
+		if seen[path] {
+			return nil, fmt.Errorf("duplicate path: %s", path)
+		}
+		seen[path] = true
+	}
+
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+
+	// Progress counts documents; the pages of each document are not reported
+	start := time.Now()
+	outputs := batchOutputNames(paths)
+	files := make([]BatchFileResult, len(paths))
+	fileCtx := extraction.WithProgress(ctx, nil)
+	var done atomic.Int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, path := range paths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			files[i] = s.extractBatchFile(fileCtx, path, mode, req.Config, filepath.Join(req.OutputDir, outputs[i]))
+			extraction.ReportProgress(ctx, int(done.Add(1)), len(paths),
+				fmt.Sprintf("%s: %s", filepath.Base(path), files[i].Status))
+		}(i, path)
+	}
+	wg.Wait()
+
+	result := &PDFBatchExtractResult{
+		Mode:         mode,
+		Directory:    req.Directory,
+		OutputDir:    req.OutputDir,
+		ManifestPath: filepath.Join(req.OutputDir, batchManifestName),
+		Files:        files,
+		TotalFiles:   len(files),
+	}
+	for _, file := range files {
+		switch file.Status {
+		case BatchSucceeded:
+			result.Succeeded++
+		case BatchPartial:
+			result.Partial++
+		default:
+			result.Failed++
+		}
+	}
+	result.DurationMs = durationMs(time.Since(start))
+
+	manifest, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := fsys.WriteFile(result.ManifestPath, manifest, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save manifest: %w", err)
+	}
+	return result, nil
+}
+
+// extractBatchFile extracts one document of a batch and writes its result to outputPath
+func (s *Service) extractBatchFile(
+	ctx context.Context, path, mode string, config ExtractionConfig, outputPath string,
+) BatchFileResult {
+	file := BatchFileResult{Path: path}
+	start := time.Now()
+	defer func() { file.DurationMs = durationMs(time.Since(start)) }()
+
+	if err := ctx.Err(); err != nil {
+		file.Status = BatchFailed
+		file.Error = fmt.Sprintf("not extracted: %v", err)
+		return file
+	}
+
+	result, err := s.extractMode(ctx, path, mode, config)
+	if err != nil {
+		file.Status = BatchFailed
+		file.Error = err.Error()
+		return file
+	}
+
+	file.TotalPages = result.TotalPages
+	file.ProcessedPages = len(result.ProcessedPages)
+	file.Elements = len(result.Elements)
+	file.Tables = len(result.Tables)
+	file.Warnings = len(result.Warnings)
+	switch {
+	case len(result.ProcessedPages) == 0 && len(result.Errors) > 0:
+		// Unreadable documents yield an empty result describing the failure
+		file.Status = BatchFailed
+		file.Error = strings.Join(result.Errors, "; ")
+		return file
+	case result.Partial:
+		file.Status = BatchPartial
+	default:
+		file.Status = BatchSucceeded
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		file.Status = BatchFailed
+		file.Error = fmt.Sprintf("failed to encode result: %v", err)
+		return file
+	}
+	if err := fsys.WriteFile(outputPath, data, exportFilePerm); err != nil {
+		file.Status = BatchFailed
+		file.Error = fmt.Sprintf("failed to save result: %v", err)
+		return file
+	}
+	file.OutputPath = outputPath
+	return file
+}
+
+// extractMode runs the extraction tool of a mode on a document
+func (s *Service) extractMode(
+	ctx context.Context, path, mode string, config ExtractionConfig,
+) (*PDFExtractResult, error) {
+	switch mode {
+	case "semantic":
+		return s.ExtractSemantic(ctx, PDFExtractSemanticRequest{Path: path, Config: config})
+	case "table":
+		return s.ExtractTables(ctx, PDFExtractTablesRequest{Path: path, Config: config})
+	case "complete":
+		return s.ExtractComplete(ctx, PDFExtractCompleteRequest{Path: path, Config: config})
+	default:
+		return s.ExtractStructured(ctx, PDFExtractStructuredRequest{Path: path, Mode: mode, Config: config})
+	}
+}
+
+// batchOutputNames names the result file of each document after the document, numbering
+// documents that share a name so that no result overwrites another
+func batchOutputNames(paths []string) []string {
+	names := make([]string, len(paths))
+	used := map[string]bool{batchManifestName: true}
+	for i, path := range paths {
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		name := base + ".json"
+		for n := 2; used[name]; n++ {
+			name = base + "-" + strconv.Itoa(n) + ".json"
+		}
+		used[name] = true
+		names[i] = name
+	}
+	return names
+}
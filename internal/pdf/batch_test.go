@@ -0,0 +1,129 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestService_BatchExtractErrors(t *testing.T) {
+	service := NewService(100 * 1024 * 1024)
+	outputDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		req      PDFBatchExtractRequest
+		errorMsg string
+	}{
+		{
+			name:     "neither directory nor paths",
+			req:      PDFBatchExtractRequest{OutputDir: outputDir},
+			errorMsg: "exactly one of directory or paths",
+		},
+		{
+			name:     "no output directory",
+			req:      PDFBatchExtractRequest{Paths: []string{"/a.pdf"}},
+			errorMsg: "output_dir cannot be empty",
+		},
+		{
+			name:     "invalid mode",
+			req:      PDFBatchExtractRequest{Paths: []string{"/a.pdf"}, Mode: "preview", OutputDir: outputDir},
+			errorMsg: "invalid mode",
+		},
+		{
+			name:     "duplicate path",
+			req:      PDFBatchExtractRequest{Paths: []string{"/a.pdf", "/a.pdf"}, OutputDir: outputDir},
+			errorMsg: "duplicate path",
+		},
+		{
+			name:     "too many paths",
+			req:      PDFBatchExtractRequest{Paths: make([]string, maxBatchFiles+1), OutputDir: outputDir},
+			errorMsg: "too many files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.BatchExtract(context.Background(), tt.req)
+			if err == nil {
+				t.Fatal("BatchExtract() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("BatchExtract() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestService_BatchExtract(t *testing.T) {
+	service := NewService(100 * 1024 * 1024)
+
+	january := createTempFile(t, "statement.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (January balance) Tj ET"))
+	february := createTempFile(t, "statement.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (February balance) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Transactions) Tj ET",
+	))
+	damaged := createTempFile(t, "damaged.pdf", "%PDF-1.4\nnot a document")
+	outputDir := filepath.Join(t.TempDir(), "results")
+
+	// Progress counts documents, not the pages within them
+	var mu sync.Mutex
+	var progress []string
+	ctx := WithProgress(context.Background(), func(done, total int, _ string) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress = append(progress, fmt.Sprintf("%d/%d", done, total))
+	})
+	result, err := service.BatchExtract(ctx, PDFBatchExtractRequest{
+		Paths:          []string{january, february, damaged},
+		OutputDir:      outputDir,
+		MaxConcurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("BatchExtract() unexpected error = %v", err)
+	}
+
+	if result.Mode != "structured" || result.TotalFiles != 3 || result.Succeeded != 2 || result.Failed != 1 {
+		t.Fatalf("BatchExtract() = %+v, want 2 of 3 files extracted in structured mode", result)
+	}
+	sort.Strings(progress)
+	if fmt.Sprint(progress) != "[1/3 2/3 3/3]" {
+		t.Errorf("BatchExtract() reported progress %v, want 1, 2, and 3 of 3 documents", progress)
+	}
+	first, second, failed := result.Files[0], result.Files[1], result.Files[2]
+	if first.OutputPath != filepath.Join(outputDir, "statement.json") ||
+		second.OutputPath != filepath.Join(outputDir, "statement-2.json") {
+		t.Errorf("BatchExtract() outputs %q and %q, want statement.json and statement-2.json",
+			first.OutputPath, second.OutputPath)
+	}
+	if second.Status != BatchSucceeded || second.TotalPages != 2 || second.ProcessedPages != 2 {
+		t.Errorf("BatchExtract() second file = %+v, want both pages extracted", second)
+	}
+	if failed.Status != BatchFailed || failed.Error == "" || failed.OutputPath != "" {
+		t.Errorf("BatchExtract() damaged file = %+v, want a failure without output", failed)
+	}
+
+	data, err := os.ReadFile(second.OutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var extracted PDFExtractResult
+	if err := json.Unmarshal(data, &extracted); err != nil || extracted.FilePath != february {
+		t.Errorf("result file holds %q (error %v), want the extraction of %s", extracted.FilePath, err, february)
+	}
+
+	data, err = os.ReadFile(result.ManifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest PDFBatchExtractResult
+	if err := json.Unmarshal(data, &manifest); err != nil || len(manifest.Files) != 3 || manifest.Failed != 1 {
+		t.Errorf("manifest = %+v (error %v), want the batch result", manifest, err)
+	}
+}
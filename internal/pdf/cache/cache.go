@@ -0,0 +1,182 @@
+// Package cache provides an in-memory LRU cache for extraction results keyed by file content
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Stats reports cache occupancy and effectiveness
+type Stats struct {
+	Entries   int   `json:"entries"`
+	Bytes     int64 `json:"bytes"`
+	MaxBytes  int64 `json:"max_bytes"`
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+}
+
+// entry is a cached value with the size it was charged against the limit
+type entry struct {
+	key   string
+	value any
+	size  int64
+}
+
+// fileStamp identifies a file version cheaply so unchanged files are not hashed again
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// hashEntry memoizes a file's content hash for the version it was computed from
+type hashEntry struct {
+	stamp fileStamp
+	hash  string
+}
+
+// Cache is a size-bounded LRU cache safe for concurrent use. A cache with a zero
+// limit stores nothing, so callers can keep it unconditionally in their code paths.
+type Cache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	used     int64
+	entries  map[string]*list.Element
+	lru      *list.List
+	hashes   map[string]hashEntry
+	stats    Stats
+}
+
+// New creates a cache holding at most maxBytes of estimated value size
+func New(maxBytes int64) *Cache {
+	return &Cache{
+		maxBytes: max(maxBytes, 0),
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		hashes:   make(map[string]hashEntry),
+	}
+}
+
+// Enabled reports whether the cache stores anything
+func (c *Cache) Enabled() bool {
+	return c != nil && c.maxBytes > 0
+}
+
+// FileKey returns a key identifying the file's current content: its SHA-256 and
+// modification time. Hashes are reused while the file's size and mtime are unchanged.
+func (c *Cache) FileKey(path string) (string, error) {
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot access file: %w", err)
+	}
+	stamp := fileStamp{size: info.Size(), modTime: info.ModTime()}
+
+	c.mu.Lock()
+	memo, ok := c.hashes[path]
+	c.mu.Unlock()
+	if ok && memo.stamp == stamp {
+		return fileKey(memo.hash, stamp), nil
+	}
+
+	hash, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.hashes[path] = hashEntry{stamp: stamp, hash: hash}
+	c.mu.Unlock()
+
+	return fileKey(hash, stamp), nil
+}
+
+// Get returns the value stored under key and marks it as recently used
+func (c *Cache) Get(key string) (any, bool) {
+	if !c.Enabled() {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	c.stats.Hits++
+	return elem.Value.(*entry).value, true
+}
+
+// Put stores value under key, evicting least recently used entries until it fits.
+// Values larger than the whole cache are not stored.
+func (c *Cache) Put(key string, value any, size int64) {
+	if !c.Enabled() || size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	for c.used+size > c.maxBytes && c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+
+	c.entries[key] = c.lru.PushFront(&entry{key: key, value: value, size: size})
+	c.used += size
+}
+
+// Stats returns a snapshot of the cache statistics
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.Bytes = c.used
+	stats.MaxBytes = c.maxBytes
+	return stats
+}
+
+// remove drops an entry; the caller must hold the lock
+func (c *Cache) remove(elem *list.Element) {
+	e := c.lru.Remove(elem).(*entry)
+	delete(c.entries, e.key)
+	c.used -= e.size
+}
+
+// fileKey formats a content hash and modification time as a cache key prefix
+func fileKey(hash string, stamp fileStamp) string {
+	return fmt.Sprintf("%s:%d", hash, stamp.modTime.UnixNano())
+}
+
+// hashFile computes the SHA-256 of a file's content
+func hashFile(path string) (string, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
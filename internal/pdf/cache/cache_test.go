@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_LRUEviction(t *testing.T) {
+	c := New(100)
+
+	c.Put("a", "first", 40)
+	c.Put("b", "second", 40)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(a) missed, want hit")
+	}
+
+	// b is now least recently used and must make room for c
+	c.Put("c", "third", 40)
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) hit, want evicted")
+	}
+	if value, ok := c.Get("a"); !ok || value != "first" {
+		t.Errorf("Get(a) = %v, %v, want first, true", value, ok)
+	}
+
+	stats := c.Stats()
+	if stats.Entries != 2 || stats.Bytes != 80 || stats.Evictions != 1 {
+		t.Errorf("Stats() = %+v, want 2 entries, 80 bytes, 1 eviction", stats)
+	}
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Stats() hits/misses = %d/%d, want 2/1", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCache_Limits(t *testing.T) {
+	c := New(100)
+	c.Put("big", "value", 101)
+	if _, ok := c.Get("big"); ok {
+		t.Error("Get(big) hit, want values larger than the cache skipped")
+	}
+
+	c.Put("a", "old", 60)
+	c.Put("a", "new", 30)
+	if value, _ := c.Get("a"); value != "new" || c.Stats().Bytes != 30 {
+		t.Errorf("replaced entry = %v with %d bytes, want new with 30", value, c.Stats().Bytes)
+	}
+
+	disabled := New(0)
+	disabled.Put("a", "value", 1)
+	if _, ok := disabled.Get("a"); ok || disabled.Enabled() {
+		t.Error("zero-size cache stored a value, want disabled")
+	}
+}
+
+func TestCache_FileKey(t *testing.T) {
+	c := New(1024)
+	path := filepath.Join(t.TempDir(), "doc.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4 original"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := c.FileKey(path)
+	if err != nil {
+		t.Fatalf("FileKey() unexpected error = %v", err)
+	}
+	if again, _ := c.FileKey(path); again != key {
+		t.Errorf("FileKey() = %q then %q, want stable keys for an unchanged file", key, again)
+	}
+
+	if err := os.WriteFile(path, []byte("%PDF-1.4 modified"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := c.FileKey(path)
+	if err != nil {
+		t.Fatalf("FileKey() unexpected error = %v", err)
+	}
+	if changed == key || strings.Split(changed, ":")[0] == strings.Split(key, ":")[0] {
+		t.Errorf("FileKey() = %q after modification, want a new hash", changed)
+	}
+
+	if _, err := c.FileKey(filepath.Join(t.TempDir(), "missing.pdf")); err == nil {
+		t.Error("FileKey() expected error for missing file")
+	}
+}
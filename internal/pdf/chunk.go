@@ -0,0 +1,314 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Chunk size units
+const (
+	ChunkUnitTokens     = "tokens"
+	ChunkUnitCharacters = "characters"
+)
+
+// Chunking constants
+const (
+	// defaultChunkTokens is the chunk size when no limit is given
+	defaultChunkTokens = 512
+	// charsPerToken estimates tokens from characters, the usual approximation for English
+	// text and subword tokenizers
+	charsPerToken = 4
+)
+
+// ChunkContent splits a document's text into chunks for retrieval. Chunks follow the
+// detected structure: a heading always starts a new chunk, so no chunk spans two sections,
+// and paragraphs, list items, and tables are only split when one alone is over the limit.
+func (e *Exporter) ChunkContent(ctx context.Context, req PDFChunkContentRequest) (*PDFChunkContentResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	unit, limit := ChunkUnitTokens, req.MaxTokens
+	switch {
+	case req.MaxTokens < 0 || req.MaxCharacters < 0:
+		return nil, fmt.Errorf("max_tokens and max_characters cannot be negative")
+	case req.MaxTokens > 0 && req.MaxCharacters > 0:
+		return nil, fmt.Errorf("set max_tokens or max_characters, not both")
+	case req.MaxCharacters > 0:
+		unit, limit = ChunkUnitCharacters, req.MaxCharacters
+	case req.MaxTokens == 0:
+		limit = defaultChunkTokens
+	}
+	if req.Overlap < 0 || req.Overlap >= limit {
+		return nil, fmt.Errorf("overlap must be between 0 and %d %s", limit-1, unit)
+	}
+
+	structure := defaultStructure
+	if req.StructureConfig != nil {
+		if err := req.StructureConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid structure_config: %w", err)
+		}
+		structure, _ = newStructureDetector(*req.StructureConfig)
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := e.open(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	numbering := extraction.NewPageNumbering(r)
+	pages := req.Pages
+	if len(pages) == 0 {
+		for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+			pages = append(pages, pageNum)
+		}
+	}
+	for _, pageNum := range pages {
+		if pageNum < 1 || pageNum > numbering.Count() {
+			return nil, pageOutOfRange(pageNum, numbering.Count())
+		}
+	}
+
+	tables, err := detectTables(ctx, e.engine, req.Path, req.Pages)
+	if err != nil {
+		return nil, err
+	}
+
+	chunker := newContentChunker(unit, limit, req.Overlap)
+	for _, pageNum := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, block := range e.pageBlocks(numbering, pageNum, tables[pageNum], structure) {
+			chunker.add(block, pageNum)
+		}
+	}
+	chunker.flush(false)
+
+	return &PDFChunkContentResult{
+		Path:       req.Path,
+		TotalPages: numbering.Count(),
+		Unit:       unit,
+		Limit:      limit,
+		Overlap:    req.Overlap,
+		Chunks:     chunker.chunks,
+		Structure:  structure.config,
+	}, nil
+}
+
+// chunkPiece is the Markdown of a block, or of part of one that is over the limit
+type chunkPiece struct {
+	text string
+	page int
+	list bool // Consecutive list items are separated by a single line break
+}
+
+// chunkHeading is a heading whose section is still open
+type chunkHeading struct {
+	level int
+	title string
+}
+
+// contentChunker packs blocks into chunks that stay within one section
+type contentChunker struct {
+	measure  func(string) int
+	limit    int
+	overlap  int
+	chunks   []ContentChunk
+	pieces   []chunkPiece
+	carried  int            // Leading pieces repeated from the previous chunk
+	headings []chunkHeading // Open sections, from the top level down
+}
+
+// newContentChunker creates a chunker measuring text in the given unit
+func newContentChunker(unit string, limit, overlap int) *contentChunker {
+	measure := utf8.RuneCountInString
+	if unit == ChunkUnitTokens {
+		measure = estimateTokens
+	}
+	return &contentChunker{measure: measure, limit: limit, overlap: overlap, chunks: []ContentChunk{}}
+}
+
+// estimateTokens estimates the number of tokens in text from its length
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// add appends a block to the current chunk, closing the chunk first when the block starts
+// a new section or does not fit
+func (c *contentChunker) add(block exportBlock, page int) {
+	switch block.kind {
+	case blockImage:
+		// Image placeholders carry no text worth retrieving
+		return
+	case blockHeading:
+		c.flush(false)
+		for len(c.headings) > 0 && c.headings[len(c.headings)-1].level >= block.level {
+			c.headings = c.headings[:len(c.headings)-1]
+		}
+		c.headings = append(c.headings, chunkHeading{level: block.level, title: block.text})
+	}
+
+	text := writeMarkdown([]exportBlock{block})
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	// Parts leave room for the text repeated from the previous chunk
+	for _, part := range splitChunkText(text, c.limit-c.overlap, c.measure) {
+		c.addPiece(chunkPiece{text: part, page: page, list: block.kind == blockListItem})
+	}
+}
+
+// addPiece appends a piece of at most the limit, starting a new chunk when it does not fit
+func (c *contentChunker) addPiece(piece chunkPiece) {
+	fits := func() bool {
+		return c.measure(joinChunkPieces(append(c.pieces[:len(c.pieces):len(c.pieces)], piece))) <= c.limit
+	}
+	if !fits() {
+		c.flush(true)
+		// Drop the repeated text when it leaves no room for the piece
+		if !fits() {
+			c.pieces, c.carried = nil, 0
+		}
+	}
+	c.pieces = append(c.pieces, piece)
+}
+
+// flush closes the current chunk. With carry, the next chunk opens with the end of this one.
+func (c *contentChunker) flush(carry bool) {
+	if len(c.pieces) == c.carried {
+		// Nothing was added after the repeated text
+		c.pieces, c.carried = nil, 0
+		return
+	}
+
+	text := joinChunkPieces(c.pieces)
+	last := c.pieces[len(c.pieces)-1]
+	chunk := ContentChunk{
+		Order:      len(c.chunks) + 1,
+		Text:       text,
+		StartPage:  c.pieces[0].page,
+		EndPage:    last.page,
+		Characters: utf8.RuneCountInString(text),
+		Tokens:     estimateTokens(text),
+	}
+	if c.carried > 0 {
+		chunk.Overlap = utf8.RuneCountInString(joinChunkPieces(c.pieces[:c.carried]))
+	}
+	for _, heading := range c.headings {
+		chunk.SectionPath = append(chunk.SectionPath, heading.title)
+		chunk.Section = heading.title
+	}
+	c.chunks = append(c.chunks, chunk)
+
+	c.pieces, c.carried = nil, 0
+	if carry && c.overlap > 0 {
+		if tail := overlapTail(text, c.overlap, c.measure); tail != "" {
+			c.pieces = []chunkPiece{{text: tail, page: last.page, list: last.list}}
+			c.carried = 1
+		}
+	}
+}
+
+// joinChunkPieces joins pieces with a blank line between blocks
+func joinChunkPieces(pieces []chunkPiece) string {
+	var b strings.Builder
+	for i, piece := range pieces {
+		if i > 0 {
+			if piece.list && pieces[i-1].list {
+				b.WriteByte('\n')
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(piece.text)
+	}
+	return b.String()
+}
+
+// overlapTail returns the longest run of whole words ending text that is within the overlap
+func overlapTail(text string, overlap int, measure func(string) int) string {
+	tail := ""
+	for end := len(text); end > 0; {
+		start := strings.LastIndexAny(text[:end], " \t\n")
+		candidate := strings.TrimSpace(text[start+1:])
+		if measure(candidate) > overlap {
+			break
+		}
+		tail = candidate
+		if start < 0 {
+			break
+		}
+		end = start
+	}
+	return tail
+}
+
+// splitChunkText splits text over the limit at line breaks, then between words, and cuts
+// words that are over the limit on their own
+func splitChunkText(text string, limit int, measure func(string) int) []string {
+	if measure(text) <= limit {
+		return []string{text}
+	}
+
+	separator, units := " ", strings.Fields(text)
+	if strings.Contains(text, "\n") {
+		separator, units = "\n", strings.Split(text, "\n")
+	}
+
+	var parts []string
+	current := ""
+	for _, unit := range units {
+		if strings.TrimSpace(unit) == "" {
+			continue
+		}
+		if measure(unit) > limit {
+			if current != "" {
+				parts = append(parts, current)
+				current = ""
+			}
+			if separator == "\n" {
+				parts = append(parts, splitChunkText(unit, limit, measure)...)
+			} else {
+				parts = append(parts, cutChunkWord(unit, limit, measure)...)
+			}
+			continue
+		}
+		candidate := unit
+		if current != "" {
+			candidate = current + separator + unit
+		}
+		if measure(candidate) > limit {
+			parts = append(parts, current)
+			candidate = unit
+		}
+		current = candidate
+	}
+	if current != "" {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// cutChunkWord cuts a word into the longest pieces within the limit
+func cutChunkWord(word string, limit int, measure func(string) int) []string {
+	var parts []string
+	runes := []rune(word)
+	for len(runes) > 0 {
+		n := sort.Search(len(runes), func(i int) bool { return measure(string(runes[:i+1])) > limit })
+		n = max(n, 1)
+		parts = append(parts, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return parts
+}
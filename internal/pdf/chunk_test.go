@@ -0,0 +1,108 @@
+package pdf
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExporter_ChunkContent(t *testing.T) {
+	exporter := NewExporter(100 * 1024 * 1024)
+	path := createTempFile(t, "manual.pdf", buildTestPDF(
+		"BT /F1 24 Tf 72 760 Td (Installation Guide) Tj ET\n"+
+			"BT /F1 14 Tf 72 730 Td (Requirements) Tj ET\n"+
+			"BT /F1 10 Tf 72 710 Td (The server needs a recent Go toolchain and a directory of PDF files.) Tj ET\n"+
+			"BT /F1 10 Tf 72 690 Td (- Linux or macOS) Tj ET\n"+
+			"BT /F1 10 Tf 72 678 Td (- Two gigabytes of memory) Tj ET",
+		"BT /F1 14 Tf 72 760 Td (Setup) Tj ET\n"+
+			"BT /F1 10 Tf 72 740 Td (Build the binary with make and start it with the directory to serve.) Tj ET\n"+
+			"BT /F1 10 Tf 72 720 Td (Clients connect over standard input and output by default.) Tj ET",
+	))
+
+	result, err := exporter.ChunkContent(context.Background(), PDFChunkContentRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ChunkContent() unexpected error = %v", err)
+	}
+	if result.Unit != ChunkUnitTokens || result.Limit != defaultChunkTokens {
+		t.Errorf("ChunkContent() limit = %d %s, want %d tokens", result.Limit, result.Unit, defaultChunkTokens)
+	}
+	// Every heading starts a chunk, so the title, Requirements, and Setup are each their own section
+	want := []struct {
+		section    []string
+		start, end int
+		contains   string
+	}{
+		{[]string{"Installation Guide"}, 1, 1, "# Installation Guide"},
+		{[]string{"Installation Guide", "Requirements"}, 1, 1, "- Linux or macOS\n- Two gigabytes"},
+		{[]string{"Installation Guide", "Setup"}, 2, 2, "## Setup"},
+	}
+	if len(result.Chunks) != len(want) {
+		t.Fatalf("ChunkContent() = %+v, want %d chunks", result.Chunks, len(want))
+	}
+	for i, w := range want {
+		chunk := result.Chunks[i]
+		if chunk.Order != i+1 || !slices.Equal(chunk.SectionPath, w.section) ||
+			chunk.StartPage != w.start || chunk.EndPage != w.end || !strings.Contains(chunk.Text, w.contains) {
+			t.Errorf("chunk %d = %+v, want section %v on pages %d-%d containing %q",
+				i, chunk, w.section, w.start, w.end, w.contains)
+		}
+	}
+
+	// A small character limit splits the Setup section, repeating the end of each chunk
+	result, err = exporter.ChunkContent(context.Background(), PDFChunkContentRequest{
+		Path: path, Pages: []int{2}, MaxCharacters: 80, Overlap: 20,
+	})
+	if err != nil {
+		t.Fatalf("ChunkContent() unexpected error = %v", err)
+	}
+	if len(result.Chunks) < 2 {
+		t.Fatalf("ChunkContent() = %+v, want the section split", result.Chunks)
+	}
+	for i, chunk := range result.Chunks {
+		if chunk.Characters > 80 || chunk.Section != "Setup" || chunk.StartPage != 2 {
+			t.Errorf("chunk %d = %+v, want at most 80 characters of Setup on page 2", i, chunk)
+		}
+		if i == 0 {
+			continue
+		}
+		previous := result.Chunks[i-1].Text
+		if chunk.Overlap == 0 || chunk.Overlap > 20 || !strings.HasSuffix(previous, chunk.Text[:chunk.Overlap]) {
+			t.Errorf("chunk %d = %+v, want it to open with up to 20 characters ending %q", i, chunk, previous)
+		}
+	}
+
+	for _, req := range []PDFChunkContentRequest{
+		{},
+		{Path: path, MaxTokens: 100, MaxCharacters: 100},
+		{Path: path, MaxTokens: -1},
+		{Path: path, MaxTokens: 100, Overlap: 100},
+		{Path: path, Pages: []int{3}},
+	} {
+		if _, err := exporter.ChunkContent(context.Background(), req); err == nil {
+			t.Errorf("ChunkContent(%+v) expected an error", req)
+		}
+	}
+}
+
+func TestSplitChunkText(t *testing.T) {
+	measure := func(s string) int { return len(s) }
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{"fits", "one two", 9, []string{"one two"}},
+		{"words", "one two three four", 9, []string{"one two", "three", "four"}},
+		{"lines", "| a | b |\n| c | d |", 9, []string{"| a | b |", "| c | d |"}},
+		{"long word", "abcdefghijk", 7, []string{"abcdefg", "hijk"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitChunkText(tt.text, tt.limit, measure); !slices.Equal(got, tt.want) {
+				t.Errorf("splitChunkText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Classification constants
+const (
+	// classifyMaxPages is how many pages from the start of a document are read to classify it
+	classifyMaxPages = 10
+	// defaultClassifyTopN is the number of candidate types returned by default
+	defaultClassifyTopN = 3
+	// patternWeight is the weight of a pattern match relative to a keyword, since patterns
+	// such as invoice numbers are more specific than single words
+	patternWeight = 2.0
+)
+
+// Classification profile sources
+const (
+	ProfileSourceBuiltin = "builtin"
+	ProfileSourceCustom  = "custom"
+)
+
+// ClassificationProfile describes a document type by the keywords and patterns its text
+// contains
+type ClassificationProfile struct {
+	Type        string   `json:"type" yaml:"type"`
+	Description string   `json:"description,omitempty" yaml:"description"`
+	Keywords    []string `json:"keywords,omitempty" yaml:"keywords"` // Words or phrases, matched case-insensitively
+	Patterns    []string `json:"patterns,omitempty" yaml:"patterns"` // Regular expressions
+}
+
+// ClassificationProfiles is a file of custom profiles. Custom profiles replace built-in
+// profiles of the same type and add the others, unless ReplaceBuiltin drops the built-in
+// profiles altogether.
+type ClassificationProfiles struct {
+	ReplaceBuiltin bool                    `json:"replace_builtin" yaml:"replace_builtin"`
+	Profiles       []ClassificationProfile `json:"profiles" yaml:"profiles"`
+}
+
+// builtinProfiles are the document types recognized without a profiles file
+var builtinProfiles = []ClassificationProfile{
+	{
+		Type:        "invoice",
+		Description: "Bill for goods or services",
+		Keywords:    []string{"invoice", "bill to", "amount due", "due date", "subtotal", "tax", "payment terms", "total"},
+		Patterns:    []string{`(?i)invoice\s*(no|number|#)\.?\s*:?\s*[A-Z0-9-]{3,}`},
+	},
+	{
+		Type:        "receipt",
+		Description: "Proof of a completed payment",
+		Keywords:    []string{"receipt", "paid", "change", "cash", "card", "thank you", "total"},
+		Patterns:    []string{`(?i)(visa|mastercard|amex)\s*[x*]{2,}\d{4}`},
+	},
+	{
+		Type:        "purchase_order",
+		Description: "Order issued by a buyer to a supplier",
+		Keywords:    []string{"purchase order", "ship to", "vendor", "quantity", "unit price", "delivery date"},
+		Patterns:    []string{`(?i)\bP\.?O\.?\s*(no|number|#)\.?\s*:?\s*[A-Z0-9-]{3,}`},
+	},
+	{
+		Type:        "contract",
+		Description: "Agreement between parties",
+		Keywords: []string{"agreement", "parties", "hereinafter", "whereas", "terms and conditions",
+			"governing law", "termination", "in witness whereof", "indemnify"},
+		Patterns: []string{`(?i)this\s+(agreement|contract)\s+is\s+(made|entered)`},
+	},
+	{
+		Type:        "resume",
+		Description: "Curriculum vitae or resume",
+		Keywords: []string{"resume", "curriculum vitae", "experience", "education", "skills",
+			"references available", "objective"},
+		Patterns: []string{`(?i)\b(19|20)\d{2}\s*[-–]\s*((19|20)\d{2}|present)\b`},
+	},
+	{
+		Type:        "academic_paper",
+		Description: "Research article or thesis",
+		Keywords: []string{"abstract", "introduction", "related work", "methodology", "results",
+			"conclusion", "references", "et al"},
+		Patterns: []string{`\[\d{1,3}(,\s*\d{1,3})*\]`, `(?i)\bdoi:\s*10\.\d{4,}/`},
+	},
+	{
+		Type:        "letter",
+		Description: "Correspondence",
+		Keywords:    []string{"dear", "sincerely", "regards", "yours faithfully", "yours truly", "enclosure"},
+	},
+	{
+		Type:        "bank_statement",
+		Description: "Account statement from a bank",
+		Keywords: []string{"statement", "account number", "opening balance", "closing balance",
+			"deposits", "withdrawals", "statement period"},
+		Patterns: []string{`(?i)\b(IBAN|sort code|routing number)\b`},
+	},
+	{
+		Type:        "form",
+		Description: "Form to be filled in",
+		Keywords:    []string{"please print", "signature", "date of birth", "check one", "office use only", "applicant"},
+		Patterns:    []string{`_{5,}`, `\[\s?\]|☐`},
+	},
+	{
+		Type:        "report",
+		Description: "Business or technical report",
+		Keywords: []string{"executive summary", "table of contents", "findings", "recommendations",
+			"overview", "appendix", "quarter"},
+	},
+}
+
+// LoadClassificationProfiles reads a JSON or YAML profiles file and checks that every profile
+// names a type, has a keyword or pattern, and has patterns that compile. An empty path
+// returns nil.
+func LoadClassificationProfiles(path string) (*ClassificationProfiles, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read classifier profiles: %w", err)
+	}
+
+	// YAML is a superset of JSON, so one decoder reads both
+	var profiles ClassificationProfiles
+	if err := yaml.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("invalid classifier profiles %s: %w", path, err)
+	}
+	if len(profiles.Profiles) == 0 {
+		return nil, fmt.Errorf("invalid classifier profiles %s: no profiles", path)
+	}
+	seen := make(map[string]bool)
+	for _, profile := range profiles.Profiles {
+		if _, err := compileProfile(profile, ProfileSourceCustom); err != nil {
+			return nil, fmt.Errorf("invalid classifier profiles %s: %w", path, err)
+		}
+		if seen[profile.Type] {
+			return nil, fmt.Errorf("invalid classifier profiles %s: type %s defined twice", path, profile.Type)
+		}
+		seen[profile.Type] = true
+	}
+	return &profiles, nil
+}
+
+// classificationSignal is a compiled keyword or pattern of a profile
+type classificationSignal struct {
+	name   string // The keyword or pattern as written
+	re     *regexp.Regexp
+	weight float64
+}
+
+// compiledProfile is a profile ready to score text
+type compiledProfile struct {
+	ClassificationProfile
+	source  string
+	signals []classificationSignal
+	total   float64 // Sum of the signal weights
+}
+
+// compileProfile turns keywords into case-insensitive whole-word expressions and compiles
+// the patterns
+func compileProfile(profile ClassificationProfile, source string) (*compiledProfile, error) {
+	if strings.TrimSpace(profile.Type) == "" {
+		return nil, fmt.Errorf("profile without a type")
+	}
+	if len(profile.Keywords) == 0 && len(profile.Patterns) == 0 {
+		return nil, fmt.Errorf("profile %s has no keywords or patterns", profile.Type)
+	}
+
+	compiled := &compiledProfile{ClassificationProfile: profile, source: source}
+	for _, keyword := range profile.Keywords {
+		words := strings.Fields(keyword)
+		if len(words) == 0 {
+			continue
+		}
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		re := regexp.MustCompile(`(?i)\b` + strings.Join(words, `\s+`) + `\b`)
+		compiled.signals = append(compiled.signals, classificationSignal{name: keyword, re: re, weight: 1})
+	}
+	for _, pattern := range profile.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s has an invalid pattern %q: %w", profile.Type, pattern, err)
+		}
+		compiled.signals = append(compiled.signals, classificationSignal{name: pattern, re: re, weight: patternWeight})
+	}
+	for _, signal := range compiled.signals {
+		compiled.total += signal.weight
+	}
+	return compiled, nil
+}
+
+// Classifier ranks the document types a PDF may be by the keywords and patterns in its text
+type Classifier struct {
+	maxFileSize int64
+	validator   *Validator
+	profiles    []*compiledProfile
+}
+
+// NewClassifier creates a new classifier with the built-in profiles
+func NewClassifier(maxFileSize int64) *Classifier {
+	c := &Classifier{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+	c.SetProfiles(nil)
+	return c
+}
+
+// SetProfiles combines custom profiles with the built-in ones; nil restores the built-in
+// profiles. Profiles are expected to come from LoadClassificationProfiles, so any that do
+// not compile are skipped.
+func (c *Classifier) SetProfiles(custom *ClassificationProfiles) {
+	var profiles []*compiledProfile
+	replaced := make(map[string]bool)
+	if custom != nil {
+		for _, profile := range custom.Profiles {
+			compiled, err := compileProfile(profile, ProfileSourceCustom)
+			if err != nil {
+				logger.Warn("skipping classifier profile", "type", profile.Type, "error", err)
+				continue
+			}
+			profiles = append(profiles, compiled)
+			replaced[profile.Type] = true
+		}
+	}
+	if custom == nil || !custom.ReplaceBuiltin {
+		for _, profile := range builtinProfiles {
+			if replaced[profile.Type] {
+				continue
+			}
+			compiled, err := compileProfile(profile, ProfileSourceBuiltin)
+			if err != nil {
+				panic(err)
+			}
+			profiles = append(profiles, compiled)
+		}
+	}
+	c.profiles = profiles
+}
+
+// Classify scores the text of a document's first pages against every profile and returns
+// the best candidates. A profile's score is the share of its keywords and patterns found,
+// with patterns counting double.
+func (c *Classifier) Classify(ctx context.Context, req PDFClassifyDocumentRequest) (*PDFClassifyDocumentResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	if req.TopN < 0 {
+		return nil, fmt.Errorf("top_n cannot be negative")
+	}
+	topN := req.TopN
+	if topN == 0 {
+		topN = defaultClassifyTopN
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: c.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	numbering := extraction.NewPageNumbering(r)
+	result := &PDFClassifyDocumentResult{
+		Path:       req.Path,
+		TotalPages: numbering.Count(),
+		PagesRead:  min(numbering.Count(), classifyMaxPages),
+		Profiles:   len(c.profiles),
+	}
+
+	var text strings.Builder
+	for pageNum := 1; pageNum <= result.PagesRead; pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := pageText(numbering, pageNum)
+		if err != nil {
+			result.FailedPages = append(result.FailedPages, pageNum)
+			continue
+		}
+		text.WriteString(page.Text)
+		text.WriteByte('\n')
+	}
+
+	result.Candidates = c.rank(text.String(), topN)
+	return result, nil
+}
+
+// rank scores text against every profile and returns up to topN matching types, best first
+func (c *Classifier) rank(text string, topN int) []ClassificationCandidate {
+	candidates := []ClassificationCandidate{}
+	for _, profile := range c.profiles {
+		candidate := profile.score(text)
+		if candidate.Score > 0 {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > topN {
+		candidates = candidates[:topN]
+	}
+	return candidates
+}
+
+// score matches a profile's signals against text
+func (p *compiledProfile) score(text string) ClassificationCandidate {
+	candidate := ClassificationCandidate{
+		Type:        p.Type,
+		Description: p.Description,
+		Source:      p.source,
+		Matched:     []string{},
+	}
+	matched := 0.0
+	for _, signal := range p.signals {
+		if signal.re.MatchString(text) {
+			matched += signal.weight
+			candidate.Matched = append(candidate.Matched, signal.name)
+		}
+	}
+	if p.total > 0 {
+		candidate.Score = matched / p.total
+	}
+	return candidate
+}
@@ -0,0 +1,125 @@
+package pdf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifier_Classify(t *testing.T) {
+	classifier := NewClassifier(100 * 1024 * 1024)
+	path := createTempFile(t, "invoice.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (INVOICE  Invoice No. A-1001) Tj ET\n"+
+			"BT /F1 12 Tf 72 700 Td (Bill to: Acme Corp. Payment terms: net 30) Tj ET\n"+
+			"BT /F1 12 Tf 72 680 Td (Subtotal 100.00 Tax 8.00 Total 108.00 Amount due 108.00) Tj ET\n"+
+			"BT /F1 12 Tf 72 660 Td (Purchase order PO-7731) Tj ET",
+	))
+
+	result, err := classifier.Classify(context.Background(), PDFClassifyDocumentRequest{Path: path, TopN: 2})
+	if err != nil {
+		t.Fatalf("Classify() unexpected error = %v", err)
+	}
+	if len(result.Candidates) != 2 {
+		t.Fatalf("Classify() = %+v, want 2 candidates", result.Candidates)
+	}
+	best := result.Candidates[0]
+	if best.Type != "invoice" || best.Source != ProfileSourceBuiltin {
+		t.Errorf("best candidate = %+v, want the built-in invoice profile", best)
+	}
+	if best.Score <= result.Candidates[1].Score || best.Score > 1 {
+		t.Errorf("scores = %v and %v, want the first higher and at most 1", best.Score, result.Candidates[1].Score)
+	}
+	if result.PagesRead != 1 || result.Profiles != len(builtinProfiles) {
+		t.Errorf("Classify() read %d pages against %d profiles, want 1 page against %d",
+			result.PagesRead, result.Profiles, len(builtinProfiles))
+	}
+
+	// A custom profile replaces the built-in one of the same type and adds new types
+	profilesPath := filepath.Join(t.TempDir(), "profiles.yaml")
+	profiles := `profiles:
+  - type: invoice
+    keywords: [remittance]
+  - type: acme_invoice
+    description: Invoices from Acme
+    keywords: [acme corp]
+    patterns: ['PO-\d{4}']
+`
+	if err := os.WriteFile(profilesPath, []byte(profiles), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadClassificationProfiles(profilesPath)
+	if err != nil {
+		t.Fatalf("LoadClassificationProfiles() unexpected error = %v", err)
+	}
+	classifier.SetProfiles(loaded)
+
+	result, err = classifier.Classify(context.Background(), PDFClassifyDocumentRequest{Path: path, TopN: 10})
+	if err != nil {
+		t.Fatalf("Classify() unexpected error = %v", err)
+	}
+	best = result.Candidates[0]
+	if best.Type != "acme_invoice" || best.Score != 1 || best.Source != ProfileSourceCustom {
+		t.Errorf("best candidate = %+v, want acme_invoice with score 1", best)
+	}
+	for _, candidate := range result.Candidates {
+		if candidate.Type == "invoice" {
+			t.Errorf("candidate %+v, want the replaced invoice profile not to match", candidate)
+		}
+	}
+
+	// replace_builtin drops the built-in profiles
+	loaded.ReplaceBuiltin = true
+	classifier.SetProfiles(loaded)
+	result, err = classifier.Classify(context.Background(), PDFClassifyDocumentRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Classify() unexpected error = %v", err)
+	}
+	if result.Profiles != 2 || len(result.Candidates) != 1 {
+		t.Errorf("Classify() = %+v, want one candidate from 2 profiles", result)
+	}
+
+	for _, req := range []PDFClassifyDocumentRequest{
+		{},
+		{Path: path, TopN: -1},
+		{Path: filepath.Join(t.TempDir(), "missing.pdf")},
+	} {
+		if _, err := classifier.Classify(context.Background(), req); err == nil {
+			t.Errorf("Classify(%+v) expected an error", req)
+		}
+	}
+}
+
+func TestLoadClassificationProfiles(t *testing.T) {
+	if profiles, err := LoadClassificationProfiles(""); profiles != nil || err != nil {
+		t.Errorf("LoadClassificationProfiles(\"\") = %v, %v, want nil", profiles, err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"json", `{"replace_builtin": true, "profiles": [{"type": "memo", "keywords": ["memo"]}]}`, false},
+		{"no profiles", `profiles: []`, true},
+		{"no type", `profiles: [{keywords: [memo]}]`, true},
+		{"no signals", `profiles: [{type: memo}]`, true},
+		{"bad pattern", `profiles: [{type: memo, patterns: ['(']}]`, true},
+		{"duplicate type", `profiles: [{type: memo, keywords: [a]}, {type: memo, keywords: [b]}]`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profiles")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			profiles, err := LoadClassificationProfiles(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadClassificationProfiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (!profiles.ReplaceBuiltin || profiles.Profiles[0].Type != "memo") {
+				t.Errorf("LoadClassificationProfiles() = %+v", profiles)
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Clause numbering schemes
+const (
+	ClauseArticle   = "article"   // Article IV
+	ClauseSection   = "section"   // Section 4.1
+	ClauseParagraph = "paragraph" // §12(a)
+	ClauseDecimal   = "decimal"   // 1.1.2
+	ClauseLetter    = "letter"    // (a)
+	ClauseRoman     = "roman"     // (iv)
+	ClauseNumber    = "number"    // (1)
+)
+
+// Clause detection constants
+const (
+	// clauseTitleWords is the longest text after a clause number read as its title rather
+	// than as the start of its text
+	clauseTitleWords = 10
+	// clauseInlineTitleWords is the longest run-in title, as "Definitions." in
+	// "1.1 Definitions. In this Agreement..."
+	clauseInlineTitleWords = 4
+	// clauseTolerance is how far, in points, an element may reach past the lines of a clause
+	// and still lie within it, as glyph boxes and line edges are measured differently
+	clauseTolerance = 3.0
+)
+
+// Clause numbers at the start of a line. Each captures the number, any subdivisions in
+// parentheses, and the rest of the line.
+var (
+	articleClause = regexp.MustCompile(`^(?i:article)\s+([IVXLCDM]{1,7}|\d{1,3})\b\.?` +
+		`((?:\([a-z0-9]{1,4}\))*)\s*[:.\-–—]?\s*(.*)$`)
+	sectionClause = regexp.MustCompile(`^(?i:section|sec\.)\s+(\d{1,3}(?:\.\d{1,3})*)\.?` +
+		`((?:\([a-z0-9]{1,4}\))*)\s*[:.\-–—]?\s*(.*)$`)
+	paragraphClause = regexp.MustCompile(`^§{1,2}\s*(\d{1,4}(?:\.\d{1,3})*)` +
+		`((?:\([a-z0-9]{1,4}\))*)\s*[:.\-–—]?\s*(.*)$`)
+	decimalClause = regexp.MustCompile(`^(\d{1,3}(?:\.\d{1,3})+|\d{1,3}\.)\.?` +
+		`((?:\([a-z0-9]{1,4}\))*)\s+([\p{Lu}(].*)$`)
+	parenClause = regexp.MustCompile(`^\(([a-z]{1,2}|[ivxlc]{1,6}|\d{1,3})\)\s+(.*)$`)
+	// clausePart is one subdivision in parentheses
+	clausePart = regexp.MustCompile(`\(([a-z0-9]{1,4})\)`)
+	// lowerRoman is a lowercase Roman numeral
+	lowerRoman = regexp.MustCompile(`^[ivxlc]+$`)
+	// dotLeaders mark table of contents entries, which name clauses without opening them
+	dotLeaders = regexp.MustCompile(`\.{4,}|…`)
+	// clauseTitleSmallWords may start lowercase within a title
+	clauseTitleSmallWords = []string{"a", "an", "and", "as", "by", "for", "in", "of", "on", "or", "the", "to"}
+)
+
+// ClauseReader builds the clause tree of legal documents from their numbering
+type ClauseReader struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewClauseReader creates a new clause reader
+func NewClauseReader(maxFileSize int64) *ClauseReader {
+	return &ClauseReader{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// clauseHead is a line opening a clause, as read from its number
+type clauseHead struct {
+	kind   string
+	number string // As printed, without trailing punctuation
+	key    string // Anchor of the number alone; subdivisions in parentheses add to their parent's
+	style  string // Clauses of one style at one depth are siblings
+	rest   string
+}
+
+// clauseNode is a clause being built, with the indexes of the clauses around it
+type clauseNode struct {
+	clause   Clause
+	parent   int // -1 for top-level clauses
+	style    string
+	children []int
+}
+
+// GetClauses reads the clause tree of a document from the numbers opening its lines:
+// articles (Article IV), sections (Section 4.1), paragraphs (§12(a)), decimal numbers
+// (1.1.2), and lettered, Roman, or numbered subdivisions ((a), (iv), (1))
+func (r *ClauseReader) GetClauses(ctx context.Context, req PDFGetClausesRequest) (*PDFGetClausesResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := core.OpenShared(ctx, req.Path, core.Options{Validate: r.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	numbering := extraction.NewPageNumbering(doc.Reader)
+	document := newDocumentLines(numbering)
+	if err := document.read(ctx, 1, numbering.Count()); err != nil {
+		return nil, err
+	}
+
+	clauses := buildClauses(document.lines)
+	if !req.IncludeText {
+		clearClauseText(clauses)
+	}
+	return &PDFGetClausesResult{
+		Path:        req.Path,
+		TotalPages:  numbering.Count(),
+		ClauseCount: countClauses(clauses),
+		Clauses:     clauses,
+		FailedPages: document.failedPages,
+	}, nil
+}
+
+// findClause reads the clause tree of a document and returns the clause with a number or
+// anchor
+func (r *ClauseReader) findClause(ctx context.Context, path, number string) (*Clause, error) {
+	result, err := r.GetClauses(ctx, PDFGetClausesRequest{Path: path, IncludeText: true})
+	if err != nil {
+		return nil, err
+	}
+	clause := lookupClause(result.Clauses, number)
+	if clause == nil {
+		return nil, fmt.Errorf("clause %q not found in %s", number, path)
+	}
+	return clause, nil
+}
+
+// buildClauses nests the clauses opened by lines into a tree. A style first seen inside a
+// clause is a level below it; a style seen before closes the clauses down to its level, so
+// "2.1" after "1.1(a)" is a sibling of "1.1" and "Article II" closes every clause of Article
+// I. Lines without a number continue the text of the clause above them, and each clause ends
+// where its text or its last subdivision does.
+func buildClauses(lines []textLine) []Clause {
+	var nodes []clauseNode
+	var stack []int
+	levels := make(map[string]int)
+	anchors := make(map[string]int)
+	lastLetter := ""
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		head, ok := parseClauseHead(strings.TrimSpace(line.Text), lastLetter)
+		if !ok {
+			if len(stack) > 0 {
+				open := &nodes[stack[len(stack)-1]].clause
+				open.Text = strings.TrimSpace(open.Text + " " + strings.TrimSpace(line.Text))
+				open.EndPage, open.Bottom = line.page, line.Bottom
+			}
+			continue
+		}
+		if head.kind == ClauseLetter {
+			lastLetter = head.key
+		}
+
+		level, seen := levels[head.style]
+		for len(stack) > 0 {
+			top := nodes[stack[len(stack)-1]].style
+			if top != head.style && (!seen || levels[top] < level) {
+				break
+			}
+			stack = stack[:len(stack)-1]
+		}
+		if !seen {
+			levels[head.style] = len(stack)
+		}
+		parent := -1
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+
+		clause := Clause{Number: head.number, Anchor: head.key, Kind: head.kind, Level: len(stack) + 1,
+			Page: line.page, Top: line.Top}
+		if parent >= 0 && (head.kind == ClauseLetter || head.kind == ClauseRoman || head.kind == ClauseNumber) {
+			clause.Anchor = nodes[parent].clause.Anchor + "-" + head.key
+		}
+		if anchors[clause.Anchor]++; anchors[clause.Anchor] > 1 {
+			clause.Anchor += "-" + strconv.Itoa(anchors[clause.Anchor])
+		}
+		clause.Title, clause.Text = splitClauseTitle(head.rest)
+		if head.rest == "" && (head.kind == ClauseArticle || head.kind == ClauseSection) && i+1 < len(lines) {
+			// Articles and sections are often numbered on a line of their own above the title
+			next := strings.TrimSpace(lines[i+1].Text)
+			if _, isHead := parseClauseHead(next, lastLetter); !isHead &&
+				len(strings.Fields(next)) <= clauseTitleWords && isClauseTitle(next) {
+				clause.Title = next
+				i++
+				line = lines[i]
+			}
+		}
+		clause.EndPage, clause.Bottom = line.page, line.Bottom
+
+		nodes = append(nodes, clauseNode{clause: clause, parent: parent, style: head.style})
+		if parent >= 0 {
+			nodes[parent].children = append(nodes[parent].children, len(nodes)-1)
+		}
+		stack = append(stack, len(nodes)-1)
+	}
+
+	// Subdivisions follow their clause, so one pass from the end carries where they end up
+	// to the clauses holding them
+	var roots []int
+	for i := len(nodes) - 1; i >= 0; i-- {
+		parent := nodes[i].parent
+		if parent < 0 {
+			roots = append([]int{i}, roots...)
+			continue
+		}
+		child, holder := nodes[i].clause, &nodes[parent].clause
+		if child.EndPage > holder.EndPage || (child.EndPage == holder.EndPage && child.Bottom < holder.Bottom) {
+			holder.EndPage, holder.Bottom = child.EndPage, child.Bottom
+		}
+	}
+	return clauseTree(nodes, roots)
+}
+
+// clauseTree returns the clauses at indexes with their subdivisions nested in them
+func clauseTree(nodes []clauseNode, indexes []int) []Clause {
+	clauses := make([]Clause, len(indexes))
+	for i, index := range indexes {
+		clauses[i] = nodes[index].clause
+		clauses[i].Children = clauseTree(nodes, nodes[index].children)
+	}
+	return clauses
+}
+
+// parseClauseHead reads the clause number opening a line. A single i, v, or x in
+// parentheses is a Roman numeral unless it follows the letter before it, as "(i)" follows
+// "(h)". Articles and sections are only read as such when a title or nothing follows, so
+// "Section 5 of this Agreement" in running text does not open a clause.
+func parseClauseHead(text, lastLetter string) (clauseHead, bool) {
+	if dotLeaders.MatchString(text) {
+		return clauseHead{}, false
+	}
+	if m := articleClause.FindStringSubmatch(text); m != nil && startsTitle(m[3]) {
+		value := m[1]
+		if _, err := strconv.Atoi(value); err != nil {
+			value = strconv.Itoa(romanValue(value))
+		}
+		head := clauseHead{kind: ClauseArticle, key: "article-" + value, style: ClauseArticle}
+		return withClauseParts(head, text, m[2], m[3]), true
+	}
+	if m := sectionClause.FindStringSubmatch(text); m != nil && startsTitle(m[3]) {
+		head := clauseHead{kind: ClauseSection, key: "section-" + m[1], style: numberStyle(ClauseSection, m[1])}
+		return withClauseParts(head, text, m[2], m[3]), true
+	}
+	if m := paragraphClause.FindStringSubmatch(text); m != nil {
+		head := clauseHead{kind: ClauseParagraph, key: "s" + m[1], style: numberStyle(ClauseParagraph, m[1])}
+		return withClauseParts(head, text, m[2], m[3]), true
+	}
+	if m := decimalClause.FindStringSubmatch(text); m != nil {
+		number := strings.TrimSuffix(m[1], ".")
+		head := clauseHead{kind: ClauseDecimal, key: number, style: numberStyle(ClauseDecimal, number)}
+		return withClauseParts(head, text, m[2], m[3]), true
+	}
+	if m := parenClause.FindStringSubmatch(text); m != nil {
+		value := m[1]
+		kind := ClauseLetter
+		switch {
+		case value[0] >= '0' && value[0] <= '9':
+			kind = ClauseNumber
+		case lowerRoman.MatchString(value) && (len(value) > 1 || strings.Contains("ivx", value)) &&
+			!(len(value) == 1 && len(lastLetter) == 1 && value[0] == lastLetter[0]+1):
+			kind = ClauseRoman
+		}
+		return clauseHead{kind: kind, number: "(" + value + ")", key: value, style: kind, rest: m[2]}, true
+	}
+	return clauseHead{}, false
+}
+
+// startsTitle reports whether the text after an article or section number can be its title
+func startsTitle(rest string) bool {
+	first, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || unicode.IsUpper(first)
+}
+
+// numberStyle names the style of a number by its scheme and how many parts it has, so
+// "1.1" and "1.1.1" are a level apart
+func numberStyle(kind, number string) string {
+	return fmt.Sprintf("%s-%d", kind, strings.Count(number, ".")+1)
+}
+
+// withClauseParts completes a clause head with the number as printed and the subdivisions
+// in parentheses that follow it, as "(a)" in "§12(a)"
+func withClauseParts(head clauseHead, text, parts, rest string) clauseHead {
+	head.number = strings.TrimRight(strings.TrimSpace(strings.TrimSuffix(text, rest)), " :.-–—")
+	head.rest = rest
+	subdivisions := clausePart.FindAllStringSubmatch(parts, -1)
+	for _, part := range subdivisions {
+		head.key += "-" + part[1]
+	}
+	if len(subdivisions) > 0 {
+		head.style += fmt.Sprintf("(%d)", len(subdivisions))
+	}
+	return head
+}
+
+// romanValue reads an upper case Roman numeral
+func romanValue(numeral string) int {
+	values := map[rune]int{'I': 1, 'V': 5, 'X': 10, 'L': 50, 'C': 100, 'D': 500, 'M': 1000}
+	total, previous := 0, 0
+	for i := len(numeral) - 1; i >= 0; i-- {
+		value := values[rune(numeral[i])]
+		if value < previous {
+			total -= value
+		} else {
+			total += value
+			previous = value
+		}
+	}
+	return total
+}
+
+// splitClauseTitle splits the text after a clause number into the clause's title and the
+// start of its text: a short line in title case is a title, as is a run-in title ending in
+// a period before the text
+func splitClauseTitle(rest string) (title, text string) {
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return "", ""
+	}
+	if len(strings.Fields(rest)) <= clauseTitleWords && isClauseTitle(rest) {
+		return strings.TrimSuffix(rest, ":"), ""
+	}
+	if before, after, ok := strings.Cut(rest, ". "); ok &&
+		len(strings.Fields(before)) <= clauseInlineTitleWords && isClauseTitle(before) {
+		return before, strings.TrimSpace(after)
+	}
+	return "", rest
+}
+
+// isClauseTitle reports whether text is set as a title: every word but short linking ones
+// capitalized, and no closing punctuation but a colon
+func isClauseTitle(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text[len(text)-1:], ".;,") {
+		return false
+	}
+	for i, word := range strings.Fields(text) {
+		first, ok := firstLetter(word)
+		if !ok || unicode.IsUpper(first) {
+			continue
+		}
+		if i == 0 || !slices.Contains(clauseTitleSmallWords, strings.ToLower(word)) {
+			return false
+		}
+	}
+	_, hasLetter := firstLetter(text)
+	return hasLetter
+}
+
+// clearClauseText drops the text of clauses, keeping their numbers, titles, and places
+func clearClauseText(clauses []Clause) {
+	for i := range clauses {
+		clauses[i].Text = ""
+		clearClauseText(clauses[i].Children)
+	}
+}
+
+// countClauses counts clauses and their subdivisions
+func countClauses(clauses []Clause) int {
+	count := len(clauses)
+	for _, clause := range clauses {
+		count += countClauses(clause.Children)
+	}
+	return count
+}
+
+// clauseAnchor reads a clause number as given in a query, such as "1.1.2", "Article IV",
+// "Section 4.1(a)", or "§12(a)", into the anchor the clause tree gives it. Anything else is
+// taken as an anchor.
+func clauseAnchor(number string) string {
+	number = strings.TrimSuffix(strings.TrimSpace(number), ".")
+	if head, ok := parseClauseHead(number+" X", ""); ok {
+		return head.key
+	}
+	return strings.ToLower(number)
+}
+
+// clauseCore strips the scheme from an anchor, so "Section 4.1", "§4.1", and "4.1" name the
+// same clause when the document numbers it only one way
+func clauseCore(anchor string) string {
+	anchor = strings.TrimPrefix(anchor, "section-")
+	if len(anchor) > 1 && anchor[0] == 's' && anchor[1] >= '0' && anchor[1] <= '9' {
+		anchor = anchor[1:]
+	}
+	return anchor
+}
+
+// lookupClause finds the clause with a number or anchor, preferring the exact anchor over
+// the same number in another scheme
+func lookupClause(clauses []Clause, number string) *Clause {
+	anchor := clauseAnchor(number)
+	var find func(clauses []Clause, match func(Clause) bool) *Clause
+	find = func(clauses []Clause, match func(Clause) bool) *Clause {
+		for i := range clauses {
+			if match(clauses[i]) {
+				return &clauses[i]
+			}
+			if found := find(clauses[i].Children, match); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	if clause := find(clauses, func(c Clause) bool { return c.Anchor == anchor }); clause != nil {
+		return clause
+	}
+	return find(clauses, func(c Clause) bool { return clauseCore(c.Anchor) == clauseCore(anchor) })
+}
+
+// contains reports whether an element lies wholly within a clause: on its pages, from its
+// number down on the first and down to its end on the last. Elements running on into the
+// next clause, as lists continuing past it, are left out.
+func (c *Clause) contains(element ContentElement) bool {
+	page, box := element.PageNumber, element.BoundingBox
+	if page < c.Page || page > c.EndPage {
+		return false
+	}
+	return (page != c.Page || box.Y+box.Height <= c.Top+clauseTolerance) &&
+		(page != c.EndPage || box.Y >= c.Bottom-clauseTolerance)
+}
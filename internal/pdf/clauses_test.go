@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// contractContent is a contract numbered by articles, decimal clauses, and lettered and
+// Roman subdivisions
+func contractContent() string {
+	var b strings.Builder
+	y := 760
+	line := func(x int, s string) {
+		fmt.Fprintf(&b, "BT /F1 10 Tf %d %d Td (%s) Tj ET\n", x, y, s)
+		y -= 16
+	}
+	line(72, "SERVICES AGREEMENT")
+	line(72, "This Agreement is made between Acme Ltd and Globex Corp.")
+	line(72, "ARTICLE I")
+	line(72, "DEFINITIONS")
+	line(72, "1.1 Definitions. In this Agreement the following terms apply.")
+	line(90, "\\(a\\) Services means the work described in Schedule 1;")
+	line(90, "\\(b\\) Fees means the amounts payable under Article II.")
+	line(72, "1.2 Interpretation")
+	line(72, "Headings do not affect interpretation.")
+	line(72, "ARTICLE II")
+	line(72, "PAYMENT")
+	line(72, "2.1 The Client shall pay the Fees within thirty days of invoice.")
+	line(90, "\\(a\\) Late payments bear interest:")
+	line(108, "\\(i\\) at two percent per month; and")
+	line(108, "\\(ii\\) from the due date until paid.")
+	line(72, "2.2 Taxes")
+	line(72, "Fees exclude value added tax.")
+	return b.String()
+}
+
+func TestClauseReader_GetClauses(t *testing.T) {
+	path := createTempFile(t, "contract.pdf", buildTestPDF(contractContent()))
+	reader := NewClauseReader(100 * 1024 * 1024)
+
+	result, err := reader.GetClauses(context.Background(), PDFGetClausesRequest{Path: path})
+	if err != nil {
+		t.Fatalf("GetClauses() unexpected error = %v", err)
+	}
+
+	var got []string
+	var walk func(clauses []Clause)
+	walk = func(clauses []Clause) {
+		for _, clause := range clauses {
+			got = append(got, fmt.Sprintf("%d %s %s %s %q", clause.Level, clause.Kind, clause.Number, clause.Anchor,
+				clause.Title))
+			walk(clause.Children)
+		}
+	}
+	walk(result.Clauses)
+	want := []string{
+		`1 article ARTICLE I article-1 "DEFINITIONS"`,
+		`2 decimal 1.1 1.1 "Definitions"`,
+		`3 letter (a) 1.1-a ""`,
+		`3 letter (b) 1.1-b ""`,
+		`2 decimal 1.2 1.2 "Interpretation"`,
+		`1 article ARTICLE II article-2 "PAYMENT"`,
+		`2 decimal 2.1 2.1 ""`,
+		`3 letter (a) 2.1-a ""`,
+		`4 roman (i) 2.1-a-i ""`,
+		`4 roman (ii) 2.1-a-ii ""`,
+		`2 decimal 2.2 2.2 "Taxes"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("clauses =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if result.ClauseCount != len(want) || result.Clauses[0].Text != "" {
+		t.Errorf("ClauseCount = %d, want %d and no text", result.ClauseCount, len(want))
+	}
+
+	clause, err := reader.findClause(context.Background(), path, "2.1(a)")
+	if err != nil {
+		t.Fatalf("findClause() unexpected error = %v", err)
+	}
+	if clause.Anchor != "2.1-a" || clause.Text != "Late payments bear interest:" || len(clause.Children) != 2 {
+		t.Errorf("findClause(2.1(a)) = %+v, want the late payment clause", clause)
+	}
+	if clause, err := reader.findClause(context.Background(), path, "Article II"); err != nil ||
+		clause.Title != "PAYMENT" {
+		t.Errorf("findClause(Article II) = %+v, %v, want the payment article", clause, err)
+	}
+	if _, err := reader.findClause(context.Background(), path, "9.9"); err == nil {
+		t.Error("findClause(9.9) expected an error for a missing clause")
+	}
+}
+
+func TestService_QueryContentClause(t *testing.T) {
+	path := createTempFile(t, "contract.pdf", buildTestPDF(contractContent()))
+	service := NewService(100 * 1024 * 1024)
+
+	result, err := service.QueryContent(context.Background(), PDFQueryContentRequest{
+		Path:  path,
+		Query: ContentQuery{TextQuery: "Fees", Clause: "Section 2.1"},
+	})
+	if err != nil {
+		t.Fatalf("QueryContent() unexpected error = %v", err)
+	}
+	if result.Clause == nil || result.Clause.Anchor != "2.1" || result.MatchCount == 0 {
+		t.Fatalf("QueryContent() = %+v, want matches within clause 2.1", result)
+	}
+	for _, element := range result.Elements {
+		if text := fmt.Sprint(element.Content); !strings.Contains(text, "pay the Fees") {
+			t.Errorf("element %q lies outside clause 2.1", text)
+		}
+	}
+}
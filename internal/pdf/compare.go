@@ -0,0 +1,334 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Document set comparison constants
+const (
+	// maxCompareFiles limits the number of documents compared in one request
+	maxCompareFiles = 20
+	// compareShingleSize is the number of consecutive words in each text shingle
+	compareShingleSize = 3
+	// compareTextWeight is the weight of text similarity in the combined score
+	compareTextWeight = 0.7
+	// defaultCompareThreshold is the similarity at which documents are considered near-identical
+	defaultCompareThreshold = 0.9
+	// outlierMinDocuments is the number of compared documents needed to report outliers
+	outlierMinDocuments = 3
+	// outlierMinSpread is the standard deviation below which a set is too uniform to have outliers
+	outlierMinSpread = 0.01
+)
+
+// Comparer computes pairwise similarity across a set of documents
+type Comparer struct {
+	maxFileSize int64
+	reader      *Reader
+	templates   *TemplateMatcher
+}
+
+// NewComparer creates a new document comparer with the specified constraints
+func NewComparer(maxFileSize int64) *Comparer {
+	return &Comparer{
+		maxFileSize: maxFileSize,
+		reader:      NewReader(maxFileSize),
+		templates:   NewTemplateMatcher(maxFileSize),
+	}
+}
+
+// documentProfile holds the features of one document used for comparison
+type documentProfile struct {
+	shingles    map[string]struct{}
+	fingerprint *DocumentFingerprint
+}
+
+// CompareSet computes the text and structural similarity of every pair of documents, groups
+// near-identical documents into clusters, and flags documents that resemble none of the others.
+// Documents that cannot be read are reported in their entry and compare as 0 to the rest.
+func (c *Comparer) CompareSet(req PDFCompareSetRequest) (*PDFCompareSetResult, error) {
+	if len(req.Paths) < 2 {
+		return nil, fmt.Errorf("at least two paths must be provided")
+	}
+	if len(req.Paths) > maxCompareFiles {
+		return nil, fmt.Errorf("too many paths: %d (max: %d)", len(req.Paths), maxCompareFiles)
+	}
+
+	seen := make(map[string]bool, len(req.Paths))
+	for _, path := range req.Paths {
+		if path == "" {
+			return nil, fmt.Errorf("path cannot be empty")
+		}
+		if seen[path] {
+			return nil, fmt.Errorf("duplicate path: %s", path)
+		}
+		seen[path] = true
+	}
+
+	threshold := req.Threshold
+	if threshold <= 0 {
+		threshold = defaultCompareThreshold
+	}
+
+	n := len(req.Paths)
+	result := &PDFCompareSetResult{
+		Documents: make([]ComparedDocument, n),
+		Matrix:    make([][]float64, n),
+		Pairs:     []DocumentPairSimilarity{},
+		Clusters:  []DocumentCluster{},
+		Outliers:  []string{},
+		Threshold: threshold,
+	}
+
+	profiles := make([]*documentProfile, n)
+	for i, path := range req.Paths {
+		result.Documents[i] = ComparedDocument{Path: path}
+		result.Matrix[i] = make([]float64, n)
+		result.Matrix[i][i] = 1
+
+		profile, doc, err := c.profile(path)
+		if err != nil {
+			result.Documents[i].Error = err.Error()
+			continue
+		}
+		profiles[i] = profile
+		result.Documents[i].Pages = doc.Pages
+		result.Documents[i].Words = doc.Words
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if profiles[i] == nil || profiles[j] == nil {
+				continue
+			}
+			pair := compareProfiles(profiles[i], profiles[j])
+			pair.PathA = req.Paths[i]
+			pair.PathB = req.Paths[j]
+			result.Matrix[i][j] = pair.Similarity
+			result.Matrix[j][i] = pair.Similarity
+			result.Pairs = append(result.Pairs, pair)
+		}
+	}
+
+	sort.SliceStable(result.Pairs, func(a, b int) bool {
+		return result.Pairs[a].Similarity > result.Pairs[b].Similarity
+	})
+
+	summarizeDocuments(result, profiles)
+	result.Clusters = clusterDocuments(result, profiles, threshold)
+	result.Outliers = findOutliers(result, profiles)
+
+	return result, nil
+}
+
+// profile reads the text and layout fingerprint of a document
+func (c *Comparer) profile(path string) (*documentProfile, ComparedDocument, error) {
+	doc := ComparedDocument{Path: path}
+
+	read, err := c.reader.ReadFile(PDFReadFileRequest{Path: path})
+	if err != nil {
+		return nil, doc, err
+	}
+
+	fingerprint, err := c.templates.Fingerprint(PDFFingerprintRequest{Path: path})
+	if err != nil {
+		return nil, doc, err
+	}
+
+	words := compareWords(read.Content)
+	doc.Pages = read.Pages
+	doc.Words = len(words)
+
+	return &documentProfile{
+		shingles:    buildShingles(words),
+		fingerprint: fingerprint,
+	}, doc, nil
+}
+
+// compareProfiles scores two documents. Structural similarity is symmetric: anchor overlap
+// is measured in both directions and averaged. Documents without text are compared by
+// structure alone.
+func compareProfiles(a, b *documentProfile) DocumentPairSimilarity {
+	structural := (CompareFingerprints(a.fingerprint, b.fingerprint).Score +
+		CompareFingerprints(b.fingerprint, a.fingerprint).Score) / 2
+
+	pair := DocumentPairSimilarity{StructuralSimilarity: roundScore(structural)}
+	if len(a.shingles) == 0 && len(b.shingles) == 0 {
+		pair.Similarity = pair.StructuralSimilarity
+		return pair
+	}
+
+	text := jaccardSimilarity(a.shingles, b.shingles)
+	pair.TextSimilarity = roundScore(text)
+	pair.Similarity = roundScore(compareTextWeight*text + (1-compareTextWeight)*structural)
+	return pair
+}
+
+// summarizeDocuments fills in each document's average and closest similarity
+func summarizeDocuments(result *PDFCompareSetResult, profiles []*documentProfile) {
+	for i := range result.Documents {
+		if profiles[i] == nil {
+			continue
+		}
+
+		total, count := 0.0, 0
+		doc := &result.Documents[i]
+		for j, score := range result.Matrix[i] {
+			if j == i || profiles[j] == nil {
+				continue
+			}
+			total += score
+			count++
+			if doc.ClosestPath == "" || score > doc.ClosestSimilarity {
+				doc.ClosestPath = result.Documents[j].Path
+				doc.ClosestSimilarity = score
+			}
+		}
+		if count > 0 {
+			doc.AverageSimilarity = roundScore(total / float64(count))
+		}
+	}
+}
+
+// clusterDocuments links documents whose similarity reaches the threshold (single linkage)
+// and returns the groups with more than one member
+func clusterDocuments(result *PDFCompareSetResult, profiles []*documentProfile, threshold float64) []DocumentCluster {
+	parent := make([]int, len(profiles))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range profiles {
+		for j := i + 1; j < len(profiles); j++ {
+			if profiles[i] != nil && profiles[j] != nil && result.Matrix[i][j] >= threshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	var roots []int
+	for i := range profiles {
+		if profiles[i] == nil {
+			continue
+		}
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	clusters := []DocumentCluster{}
+	for _, root := range roots {
+		group := members[root]
+		if len(group) < 2 {
+			continue
+		}
+
+		cluster := DocumentCluster{ID: len(clusters) + 1, MinSimilarity: 1}
+		for a, i := range group {
+			cluster.Paths = append(cluster.Paths, result.Documents[i].Path)
+			result.Documents[i].Cluster = cluster.ID
+			for _, j := range group[a+1:] {
+				cluster.MinSimilarity = math.Min(cluster.MinSimilarity, result.Matrix[i][j])
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters
+}
+
+// findOutliers flags documents whose average similarity to the set is more than one standard
+// deviation below the mean of all averages
+func findOutliers(result *PDFCompareSetResult, profiles []*documentProfile) []string {
+	outliers := []string{}
+
+	var averages []float64
+	for i, doc := range result.Documents {
+		if profiles[i] != nil {
+			averages = append(averages, doc.AverageSimilarity)
+		}
+	}
+	if len(averages) < outlierMinDocuments {
+		return outliers
+	}
+
+	mean := 0.0
+	for _, avg := range averages {
+		mean += avg
+	}
+	mean /= float64(len(averages))
+
+	variance := 0.0
+	for _, avg := range averages {
+		variance += (avg - mean) * (avg - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(averages)))
+	if stddev < outlierMinSpread {
+		return outliers
+	}
+
+	for i := range result.Documents {
+		doc := &result.Documents[i]
+		if profiles[i] != nil && doc.Cluster == 0 && doc.AverageSimilarity < mean-stddev {
+			doc.Outlier = true
+			outliers = append(outliers, doc.Path)
+		}
+	}
+
+	return outliers
+}
+
+// compareWords splits text into lowercase words, ignoring punctuation
+func compareWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// buildShingles returns the set of overlapping word sequences in a text. Texts shorter than
+// a shingle are represented by their words.
+func buildShingles(words []string) map[string]struct{} {
+	shingles := make(map[string]struct{})
+	if len(words) < compareShingleSize {
+		for _, word := range words {
+			shingles[word] = struct{}{}
+		}
+		return shingles
+	}
+
+	for i := 0; i+compareShingleSize <= len(words); i++ {
+		shingles[strings.Join(words[i:i+compareShingleSize], " ")] = struct{}{}
+	}
+	return shingles
+}
+
+// jaccardSimilarity returns the size of the intersection of two sets over the size of their union
+func jaccardSimilarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+
+	shared := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
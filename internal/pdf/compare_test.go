@@ -0,0 +1,129 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// agreementContent draws a short agreement whose clauses can be varied between documents
+func agreementContent(clauses ...string) string {
+	var b strings.Builder
+	b.WriteString("BT /F1 12 Tf\n")
+	y := 720
+	for _, clause := range clauses {
+		fmt.Fprintf(&b, "1 0 0 1 72 %d Tm (%s) Tj\n", y, clause)
+		y -= 20
+	}
+	b.WriteString("ET")
+	return b.String()
+}
+
+var standardClauses = []string{
+	"This services agreement is made between the provider and the customer",
+	"The provider shall deliver the services described in schedule one",
+	"The customer shall pay all invoices within thirty days of receipt",
+	"Either party may terminate this agreement with ninety days written notice",
+	"This agreement is governed by the laws of the state of delaware",
+}
+
+func TestComparer_CompareSetErrors(t *testing.T) {
+	comparer := NewComparer(100 * 1024 * 1024)
+
+	tests := []struct {
+		name     string
+		req      PDFCompareSetRequest
+		errorMsg string
+	}{
+		{
+			name:     "single path",
+			req:      PDFCompareSetRequest{Paths: []string{"/a.pdf"}},
+			errorMsg: "at least two paths",
+		},
+		{
+			name:     "empty path",
+			req:      PDFCompareSetRequest{Paths: []string{"/a.pdf", ""}},
+			errorMsg: "path cannot be empty",
+		},
+		{
+			name:     "duplicate path",
+			req:      PDFCompareSetRequest{Paths: []string{"/a.pdf", "/a.pdf"}},
+			errorMsg: "duplicate path",
+		},
+		{
+			name:     "too many paths",
+			req:      PDFCompareSetRequest{Paths: make([]string, maxCompareFiles+1)},
+			errorMsg: "too many paths",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := comparer.CompareSet(tt.req)
+			if err == nil {
+				t.Fatal("CompareSet() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("CompareSet() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestComparer_CompareSet(t *testing.T) {
+	comparer := NewComparer(100 * 1024 * 1024)
+
+	amended := append([]string{}, standardClauses...)
+	amended[3] = "Neither party may terminate this agreement before the end of the initial term"
+
+	acme := createTempFile(t, "acme.pdf", buildTestPDF(agreementContent(standardClauses...)))
+	globex := createTempFile(t, "globex.pdf", buildTestPDF(agreementContent(standardClauses...)))
+	initech := createTempFile(t, "initech.pdf", buildTestPDF(agreementContent(amended...)))
+	memo := createTempFile(t, "memo.pdf", buildTestPDF(agreementContent(
+		"Quarterly memo",
+		"Office closed on friday",
+	)))
+	missing := "/non/existent/file.pdf"
+
+	result, err := comparer.CompareSet(PDFCompareSetRequest{
+		Paths:     []string{acme, globex, initech, memo, missing},
+		Threshold: 0.6,
+	})
+	if err != nil {
+		t.Fatalf("CompareSet() unexpected error = %v", err)
+	}
+
+	if len(result.Documents) != 5 || len(result.Matrix) != 5 {
+		t.Fatalf("CompareSet() = %d documents, %d matrix rows, want 5", len(result.Documents), len(result.Matrix))
+	}
+	if result.Matrix[0][1] != 1 || result.Matrix[0][1] != result.Matrix[1][0] {
+		t.Errorf("identical documents similarity = %.2f / %.2f, want 1", result.Matrix[0][1], result.Matrix[1][0])
+	}
+	if amendedScore := result.Matrix[0][2]; amendedScore >= 1 || amendedScore < 0.6 {
+		t.Errorf("amended document similarity = %.2f, want high but below 1", amendedScore)
+	}
+	if result.Matrix[0][3] >= result.Matrix[0][2] {
+		t.Errorf("unrelated memo similarity %.2f should be below amended %.2f", result.Matrix[0][3], result.Matrix[0][2])
+	}
+	if !strings.Contains(result.Documents[4].Error, "does not exist") || result.Matrix[0][4] != 0 {
+		t.Errorf("missing document = %+v, want an error and no similarity", result.Documents[4])
+	}
+
+	if len(result.Clusters) != 1 || len(result.Clusters[0].Paths) != 3 {
+		t.Fatalf("CompareSet() clusters = %+v, want the three agreements in one cluster", result.Clusters)
+	}
+	if result.Documents[0].Cluster != 1 || result.Documents[3].Cluster != 0 {
+		t.Errorf("cluster assignments = %d, %d, want 1 for acme and 0 for memo",
+			result.Documents[0].Cluster, result.Documents[3].Cluster)
+	}
+	if result.Documents[0].ClosestPath != globex {
+		t.Errorf("acme closest = %s, want %s", result.Documents[0].ClosestPath, globex)
+	}
+
+	if len(result.Outliers) != 1 || result.Outliers[0] != memo || !result.Documents[3].Outlier {
+		t.Errorf("CompareSet() outliers = %v, want [%s]", result.Outliers, memo)
+	}
+	if len(result.Pairs) != 6 || result.Pairs[0].Similarity < result.Pairs[5].Similarity {
+		t.Errorf("CompareSet() pairs = %+v, want 6 pairs most similar first", result.Pairs)
+	}
+}
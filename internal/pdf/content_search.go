@@ -0,0 +1,196 @@
+package pdf
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Content search limits
+const (
+	defaultContentSearchWorkers = 4   // Documents searched concurrently when MaxConcurrency is unset
+	maxContentSearchWorkers     = 16  // Upper bound on MaxConcurrency
+	defaultContentSearchResults = 100 // Snippets returned when MaxResults is unset
+	contentSnippetRadius        = 60  // Characters of context on each side of a match
+)
+
+// SearchContent runs a text query across the content of every PDF in a directory. Documents
+// are searched concurrently; each matching document reports its match count, the pages the
+// query occurs on, and snippets around the matches, up to MaxResults snippets in total.
+// Documents that cannot be read are reported with their error and do not fail the search.
+func (s *Search) SearchContent(req PDFSearchContentRequest) (*PDFSearchContentResult, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+
+	workers := req.MaxConcurrency
+	switch {
+	case workers < 0:
+		return nil, fmt.Errorf("max_concurrency must be positive, got %d", workers)
+	case workers == 0:
+		workers = defaultContentSearchWorkers
+	case workers > maxContentSearchWorkers:
+		workers = maxContentSearchWorkers
+	}
+
+	maxResults := req.MaxResults
+	if maxResults <= 0 {
+		maxResults = defaultContentSearchResults
+	}
+
+	files, err := s.FindPDFsInDirectory(req.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	expr := regexp.QuoteMeta(query)
+	if !req.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	pattern := regexp.MustCompile(expr)
+
+	found := make([]ContentSearchFile, len(files))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, file := range files {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			found[i] = searchFileContent(path, pattern)
+		}(i, file.Path)
+	}
+	wg.Wait()
+
+	result := &PDFSearchContentResult{
+		Directory:     req.Directory,
+		Query:         query,
+		CaseSensitive: req.CaseSensitive,
+		Files:         []ContentSearchFile{},
+		FilesSearched: len(files),
+	}
+
+	for _, file := range found {
+		switch {
+		case file.Error != "":
+			result.FilesFailed++
+		case file.MatchCount > 0:
+			result.FilesMatched++
+			result.TotalMatches += file.MatchCount
+		default:
+			continue
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	// Most matches first; stable so that equally matching files keep directory order
+	sort.SliceStable(result.Files, func(a, b int) bool {
+		return result.Files[a].MatchCount > result.Files[b].MatchCount
+	})
+
+	remaining := maxResults
+	for i := range result.Files {
+		snippets := result.Files[i].Snippets
+		if len(snippets) > remaining {
+			result.Files[i].Snippets = snippets[:remaining]
+			result.Truncated = true
+		}
+		remaining -= len(result.Files[i].Snippets)
+	}
+
+	return result, nil
+}
+
+// searchFileContent finds every occurrence of pattern in a document's page text
+func searchFileContent(path string, pattern *regexp.Regexp) (file ContentSearchFile) {
+	file = ContentSearchFile{Path: path, Pages: []int{}, Snippets: []ContentSnippet{}}
+
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			file = ContentSearchFile{Path: path, Error: fmt.Sprintf("failed to read content: %v", rec)}
+		}
+	}()
+
+	numbering := extraction.NewPageNumbering(r)
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		page := numbering.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+
+		content, err := extraction.PlainText(page)
+		if err != nil {
+			// Continue with other pages even if one fails
+			continue
+		}
+		text := strings.Join(strings.Fields(content), " ")
+
+		matches := pattern.FindAllStringIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		file.MatchCount += len(matches)
+		file.Pages = append(file.Pages, pageNum)
+		for _, match := range matches {
+			file.Snippets = append(file.Snippets, ContentSnippet{
+				Page:      pageNum,
+				PageLabel: numbering.Label(pageNum),
+				Text:      contentSnippet(text, match[0], match[1]),
+			})
+		}
+	}
+
+	return file
+}
+
+// contentSnippet returns the match with up to contentSnippetRadius characters of context on
+// each side, cut at word boundaries and marked with ellipses where text was left out
+func contentSnippet(text string, start, end int) string {
+	from := max(start-contentSnippetRadius, 0)
+	to := min(end+contentSnippetRadius, len(text))
+
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from++
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to--
+	}
+	if from > 0 {
+		if space := strings.IndexByte(text[from:start], ' '); space >= 0 {
+			from += space + 1
+		}
+	}
+	if to < len(text) {
+		if space := strings.LastIndexByte(text[end:to], ' '); space >= 0 {
+			to = end + space
+		}
+	}
+
+	snippet := text[from:to]
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
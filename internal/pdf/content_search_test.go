@@ -0,0 +1,148 @@
+package pdf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// contentSearchDir writes a directory of documents for content search tests: two mention
+// the invoice, one does not, and one is not a readable PDF
+func contentSearchDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"contract.pdf": buildTestPDF(
+			"BT /F1 12 Tf 72 720 Td (The Invoice is due within thirty days) Tj ET",
+			"BT /F1 12 Tf 72 720 Td (Late fees apply) Tj ET",
+			"BT /F1 12 Tf 72 720 Td (Send each invoice to accounts and keep a copy of the invoice) Tj ET",
+		),
+		"letter.pdf":  buildTestPDF("BT /F1 12 Tf 72 720 Td (Please find the invoice enclosed) Tj ET"),
+		"memo.pdf":    buildTestPDF("BT /F1 12 Tf 72 720 Td (Nothing to see here) Tj ET"),
+		"corrupt.pdf": "%PDF-1.4\nthis is not a valid document",
+		"notes.txt":   "invoice",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to create test file %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestSearch_SearchContent(t *testing.T) {
+	search := NewSearch(100 * 1024 * 1024)
+	dir := contentSearchDir(t)
+
+	result, err := search.SearchContent(PDFSearchContentRequest{Directory: dir, Query: "invoice"})
+	if err != nil {
+		t.Fatalf("SearchContent() unexpected error = %v", err)
+	}
+	if result.FilesSearched != 4 || result.FilesMatched != 2 || result.FilesFailed != 1 || result.TotalMatches != 4 {
+		t.Fatalf("SearchContent() = %d searched, %d matched, %d failed, %d matches, want 4, 2, 1, 4",
+			result.FilesSearched, result.FilesMatched, result.FilesFailed, result.TotalMatches)
+	}
+
+	contract := result.Files[0]
+	if filepath.Base(contract.Path) != "contract.pdf" || contract.MatchCount != 3 {
+		t.Fatalf("first file = %s with %d matches, want contract.pdf with 3", contract.Path, contract.MatchCount)
+	}
+	if len(contract.Pages) != 2 || contract.Pages[0] != 1 || contract.Pages[1] != 3 {
+		t.Errorf("contract pages = %v, want [1 3]", contract.Pages)
+	}
+	if len(contract.Snippets) != 3 || !strings.Contains(contract.Snippets[0].Text, "Invoice is due") {
+		t.Errorf("contract snippets = %+v, want three with context", contract.Snippets)
+	}
+	if failed := result.Files[len(result.Files)-1]; filepath.Base(failed.Path) != "corrupt.pdf" || failed.Error == "" {
+		t.Errorf("last file = %+v, want corrupt.pdf with an error", failed)
+	}
+
+	sensitive, err := search.SearchContent(PDFSearchContentRequest{Directory: dir, Query: "Invoice", CaseSensitive: true})
+	if err != nil {
+		t.Fatalf("SearchContent() unexpected error = %v", err)
+	}
+	if sensitive.TotalMatches != 1 || sensitive.FilesMatched != 1 {
+		t.Errorf("case sensitive search = %d matches in %d files, want 1 in 1",
+			sensitive.TotalMatches, sensitive.FilesMatched)
+	}
+
+	capped, err := search.SearchContent(PDFSearchContentRequest{
+		Directory: dir, Query: "invoice", MaxResults: 2, MaxConcurrency: 1,
+	})
+	if err != nil {
+		t.Fatalf("SearchContent() unexpected error = %v", err)
+	}
+	snippets := 0
+	for _, file := range capped.Files {
+		snippets += len(file.Snippets)
+	}
+	if snippets != 2 || !capped.Truncated || capped.TotalMatches != 4 {
+		t.Errorf("capped search = %d snippets (truncated %v, %d matches), want 2 snippets of 4 matches",
+			snippets, capped.Truncated, capped.TotalMatches)
+	}
+}
+
+func TestSearch_SearchContentErrors(t *testing.T) {
+	search := NewSearch(100 * 1024 * 1024)
+
+	tests := []struct {
+		name     string
+		req      PDFSearchContentRequest
+		errorMsg string
+	}{
+		{
+			name:     "empty query",
+			req:      PDFSearchContentRequest{Directory: t.TempDir()},
+			errorMsg: "query cannot be empty",
+		},
+		{
+			name:     "empty directory",
+			req:      PDFSearchContentRequest{Query: "invoice"},
+			errorMsg: "directory cannot be empty",
+		},
+		{
+			name:     "non-existent directory",
+			req:      PDFSearchContentRequest{Directory: "/non/existent/dir", Query: "invoice"},
+			errorMsg: "directory does not exist",
+		},
+		{
+			name:     "negative concurrency",
+			req:      PDFSearchContentRequest{Directory: t.TempDir(), Query: "invoice", MaxConcurrency: -1},
+			errorMsg: "max_concurrency must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := search.SearchContent(tt.req)
+			if err == nil {
+				t.Fatal("SearchContent() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("SearchContent() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestContentSnippet(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 20) + "the invoice number" + strings.Repeat(" dolor sit", 20)
+	start := strings.Index(text, "invoice")
+
+	snippet := contentSnippet(text, start, start+len("invoice"))
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+		t.Errorf("contentSnippet() = %q, want ellipses on both sides", snippet)
+	}
+	if !strings.Contains(snippet, "the invoice number") {
+		t.Errorf("contentSnippet() = %q, want the match with its context", snippet)
+	}
+	if strings.Contains(snippet, "…m ") || strings.Contains(snippet, " d…") {
+		t.Errorf("contentSnippet() = %q, want cuts at word boundaries", snippet)
+	}
+
+	if short := contentSnippet("an invoice", 3, 10); short != "an invoice" {
+		t.Errorf("contentSnippet() = %q, want the whole text when it is short", short)
+	}
+}
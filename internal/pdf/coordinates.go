@@ -0,0 +1,145 @@
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Coordinate systems of extraction results
+const (
+	CoordinatesPDF        = "pdf"        // Points from the bottom-left corner of the page, as in the file
+	CoordinatesTopLeft    = "top-left"   // Points from the top-left corner, with Y growing down the page
+	CoordinatesNormalized = "normalized" // Fractions of the page from its top-left corner, from 0 to 1
+)
+
+// validateCoordinates checks a coordinate system name; empty selects CoordinatesPDF
+func validateCoordinates(system string) error {
+	switch system {
+	case "", CoordinatesPDF, CoordinatesTopLeft, CoordinatesNormalized:
+		return nil
+	default:
+		return fmt.Errorf("invalid coordinates: %q (must be %s, %s, or %s)",
+			system, CoordinatesPDF, CoordinatesTopLeft, CoordinatesNormalized)
+	}
+}
+
+// pageFrames maps page numbers to their MediaBox, the space extracted positions are
+// measured in
+type pageFrames struct {
+	system string
+	boxes  map[int]Rectangle
+}
+
+// newPageFrames prepares the conversion of positions into a coordinate system
+func newPageFrames(system string, pages []PageInfo) pageFrames {
+	frames := pageFrames{system: system, boxes: make(map[int]Rectangle, len(pages))}
+	for _, page := range pages {
+		if page.MediaBox.Width > 0 && page.MediaBox.Height > 0 {
+			frames.boxes[page.Number] = page.MediaBox
+		}
+	}
+	return frames
+}
+
+// point converts a position on a page from PDF space
+func (f pageFrames) point(page int, x, y float64) (float64, float64) {
+	box, ok := f.boxes[page]
+	if !ok || f.system == CoordinatesPDF {
+		return x, y
+	}
+	x, y = x-box.X, box.Y+box.Height-y
+	if f.system == CoordinatesNormalized {
+		x, y = x/box.Width, y/box.Height
+	}
+	return x, y
+}
+
+// rect converts a rectangle on a page from PDF space. Its corner stays the one nearest
+// the origin, so X and Y are the top-left corner in the top-left systems. Empty
+// rectangles mark positions that were not extracted and are kept as they are.
+func (f pageFrames) rect(page int, r Rectangle) Rectangle {
+	box, ok := f.boxes[page]
+	if !ok || f.system == CoordinatesPDF || r == (Rectangle{}) {
+		return r
+	}
+	x, y := f.point(page, r.X, r.Y+r.Height)
+	if f.system == CoordinatesNormalized {
+		return Rectangle{X: x, Y: y, Width: r.Width / box.Width, Height: r.Height / box.Height}
+	}
+	return Rectangle{X: x, Y: y, Width: r.Width, Height: r.Height}
+}
+
+// convertCoordinates expresses every position of a result in the given coordinate system:
+// those of elements, with their list items, vector paths, and annotation markup and ink,
+// and of tables with their rows, columns, and cells. Form fields and annotations are
+// elements. Positions are converted in place; the result must not share them with the
+// extraction cache.
+func convertCoordinates(result *PDFExtractResult, system string, pages []PageInfo) {
+	frames := newPageFrames(system, pages)
+	frames.elements(result.Elements)
+	for i := range result.Tables {
+		table := &result.Tables[i]
+		page := table.PageNumber
+		table.BoundingBox = frames.rect(page, table.BoundingBox)
+		for j := range table.Columns {
+			table.Columns[j].BoundingBox = frames.rect(page, table.Columns[j].BoundingBox)
+		}
+		for j := range table.Rows {
+			row := &table.Rows[j]
+			row.BoundingBox = frames.rect(page, row.BoundingBox)
+			for k := range row.Cells {
+				row.Cells[k].BoundingBox = frames.rect(page, row.Cells[k].BoundingBox)
+			}
+		}
+	}
+}
+
+// paths converts lists of points, such as the corners of text markup or the strokes of
+// ink, into new lists, leaving those of the engine's result as they are
+func (f pageFrames) paths(page int, paths [][]extraction.Coordinate) [][]extraction.Coordinate {
+	if paths == nil {
+		return nil
+	}
+	converted := make([][]extraction.Coordinate, len(paths))
+	for i, path := range paths {
+		converted[i] = make([]extraction.Coordinate, len(path))
+		for j, p := range path {
+			converted[i][j].X, converted[i][j].Y = f.point(page, p.X, p.Y)
+		}
+	}
+	return converted
+}
+
+// elements converts the positions of elements and their children
+func (f pageFrames) elements(elements []ContentElement) {
+	for i := range elements {
+		element := &elements[i]
+		page := element.PageNumber
+		element.BoundingBox = f.rect(page, element.BoundingBox)
+
+		switch content := element.Content.(type) {
+		case ListElement:
+			for j := range content.Items {
+				content.Items[j].BoundingBox = f.rect(page, content.Items[j].BoundingBox)
+			}
+		case extraction.VectorElement:
+			// Vector content is shared with the engine's result, so its paths are copied
+			commands := make([]extraction.VectorCmd, len(content.Commands))
+			for j, command := range content.Commands {
+				points := make([]extraction.Coordinate, len(command.Points))
+				for k, p := range command.Points {
+					points[k].X, points[k].Y = f.point(page, p.X, p.Y)
+				}
+				commands[j] = extraction.VectorCmd{Command: command.Command, Points: points}
+			}
+			content.Commands = commands
+			element.Content = content
+		case extraction.AnnotationElement:
+			content.QuadPoints = f.paths(page, content.QuadPoints)
+			content.InkList = f.paths(page, content.InkList)
+			element.Content = content
+		}
+		f.elements(element.Children)
+	}
+}
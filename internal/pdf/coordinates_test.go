@@ -0,0 +1,76 @@
+package pdf
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestExtractionService_Coordinates(t *testing.T) {
+	service := NewExtractionService(100 * 1024 * 1024)
+	service.SetCacheSize(1 << 20)
+	path := createTempFile(t, "table.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 740 Td (Quarterly report) Tj ET\n"+ruledTableContent(),
+	))
+
+	extract := func(system string) *PDFExtractResult {
+		t.Helper()
+		result, err := service.ExtractStructured(context.Background(), PDFExtractRequest{
+			Path:   path,
+			Config: ExtractConfig{ExtractText: true, ExtractTables: true, Coordinates: system},
+		})
+		if err != nil {
+			t.Fatalf("ExtractStructured(%q) unexpected error = %v", system, err)
+		}
+		if len(result.Elements) == 0 || len(result.Tables) == 0 {
+			t.Fatalf("ExtractStructured(%q) = %d elements, %d tables; want both", system,
+				len(result.Elements), len(result.Tables))
+		}
+		return result
+	}
+
+	pdfResult := extract(CoordinatesPDF)
+	topLeft := extract(CoordinatesTopLeft)
+	normalized := extract(CoordinatesNormalized)
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	boxes := func(result *PDFExtractResult) []Rectangle {
+		rects := []Rectangle{result.Elements[0].BoundingBox, result.Tables[0].BoundingBox}
+		for _, row := range result.Tables[0].Rows {
+			for _, cell := range row.Cells {
+				rects = append(rects, cell.BoundingBox)
+			}
+		}
+		return rects
+	}
+	want, gotTopLeft, gotNormalized := boxes(pdfResult), boxes(topLeft), boxes(normalized)
+	for i, box := range want {
+		flipped := Rectangle{X: box.X, Y: 792 - box.Y - box.Height, Width: box.Width, Height: box.Height}
+		if tl := gotTopLeft[i]; !near(tl.X, flipped.X) || !near(tl.Y, flipped.Y) ||
+			!near(tl.Width, flipped.Width) || !near(tl.Height, flipped.Height) {
+			t.Errorf("top-left box %d = %+v, want %+v", i, tl, flipped)
+		}
+		scaled := Rectangle{
+			X: flipped.X / 612, Y: flipped.Y / 792, Width: flipped.Width / 612, Height: flipped.Height / 792,
+		}
+		if n := gotNormalized[i]; !near(n.X, scaled.X) || !near(n.Y, scaled.Y) ||
+			!near(n.Width, scaled.Width) || !near(n.Height, scaled.Height) {
+			t.Errorf("normalized box %d = %+v, want %+v", i, n, scaled)
+		}
+	}
+
+	// Converting a cached result leaves the cached positions in PDF space
+	if again := extract(""); again.Elements[0].BoundingBox != pdfResult.Elements[0].BoundingBox {
+		t.Errorf("default coordinates after conversion = %+v, want %+v",
+			again.Elements[0].BoundingBox, pdfResult.Elements[0].BoundingBox)
+	}
+
+	_, err := service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Config: ExtractConfig{Coordinates: "bottom-right"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid coordinates") {
+		t.Errorf("ExtractStructured() error = %v, want an invalid coordinates error", err)
+	}
+}
@@ -0,0 +1,248 @@
+// Package core opens PDF documents for every extractor: it checks the file, parses it with
+// the password it is given, and reads it through the storage backend suited to it, so that
+// tools differ only in what they read from the document.
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// ErrPasswordRequired reports an encrypted document the empty password does not open
+var ErrPasswordRequired = pdferrors.New(pdferrors.Encrypted, "the document is encrypted and needs a password")
+
+// Options control how a document is opened
+type Options struct {
+	// MemoryMap reads the file through a memory mapping instead of the adaptive chunk cache
+	MemoryMap bool
+	// Password is tried on encrypted documents after the empty password
+	Password string
+	// Validate checks the file before it is parsed, such as against the size limit of a tool
+	Validate func(path string, info os.FileInfo) error
+}
+
+// Document is an open PDF together with the storage backing it. Documents are read either
+// through a memory mapping of the file or, by default and whenever mapping fails, through
+// the adaptive chunk cache. A document may be shared by several readers; it is closed when
+// the last of them closes it.
+type Document struct {
+	Reader *pdf.Reader
+
+	file     fs.File
+	source   io.ReaderAt // The file's bytes, as read from the file system
+	info     os.FileInfo
+	mapped   []byte
+	adaptive *adaptiveReader
+
+	mu        *sync.Mutex // Guards refs; the handle cache's lock once the cache holds the document
+	refs      int
+	cache     *handleCache // Cache holding the document open between readers, if any
+	key       handleKey
+	idleSince time.Time
+}
+
+// Open checks and opens a PDF for parsing. With MemoryMap set the file is mapped into
+// memory, which avoids a system call and a copy per parser read on very large files.
+// Platforms without mmap or a 64-bit address space, and files that cannot be mapped, fall
+// back to buffered reads, as do files of file systems other than the host's. Files are
+// opened through the file system fsys.Current returns. While SetHandleCache enables the
+// handle cache, a document opened earlier is returned again as long as its file is unchanged.
+// The file must not be truncated while the document is open. The caller must Close it.
+func Open(path string, opts Options) (*Document, error) {
+	if path == "" {
+		return nil, pdferrors.New(pdferrors.InvalidArgument, "path cannot be empty")
+	}
+	info, err := fsys.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, pdferrors.New(pdferrors.NotFound, "file does not exist: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot access file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("path is a directory, not a file: %s", path)
+	}
+	if opts.Validate != nil {
+		if err := opts.Validate(path, info); err != nil {
+			return nil, err
+		}
+	}
+
+	key := handleKey{path: path, password: opts.Password, memoryMap: opts.MemoryMap}
+	cached := handles.enabled()
+	if cached {
+		if doc := handles.get(key, info); doc != nil {
+			return doc, nil
+		}
+	}
+
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	if info, err = f.Stat(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+
+	doc := &Document{file: f, info: info, mu: new(sync.Mutex), refs: 1}
+	if doc.source, err = readerAt(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	if osFile, ok := f.(*os.File); ok && opts.MemoryMap && info.Size() > 0 {
+		if data, err := mapFile(osFile, info.Size()); err == nil {
+			doc.mapped = data
+		}
+	}
+	var source io.ReaderAt
+	if doc.mapped != nil {
+		source = bytes.NewReader(doc.mapped)
+	} else {
+		doc.adaptive = newAdaptiveReader(doc.source, info.Size())
+		source = doc.adaptive
+	}
+
+	if doc.Reader, err = parse(source, info.Size(), opts.Password); err != nil {
+		doc.release()
+		return nil, err
+	}
+	if cached {
+		handles.put(key, doc)
+	}
+	return doc, nil
+}
+
+// readerAt returns random access to a file's bytes, reading files that offer none whole
+func readerAt(f fs.File) (io.ReaderAt, error) {
+	if source, ok := f.(io.ReaderAt); ok {
+		return source, nil
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// parse reads the document's cross-reference table and trailer, decrypting it with the
+// empty password or the one given. The parser panics on some malformed files.
+func parse(source io.ReaderAt, size int64, password string) (r *pdf.Reader, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = pdferrors.New(pdferrors.Corrupt, "failed to open PDF: %v", rec)
+		}
+	}()
+
+	var passwords func() string
+	if password != "" {
+		passwords = func() string {
+			next := password
+			password = ""
+			return next
+		}
+	}
+	r, err = pdf.NewReaderEncrypted(source, size, passwords)
+	switch {
+	case err == nil:
+		return r, nil
+	case errors.Is(err, pdf.ErrInvalidPassword) && passwords == nil:
+		return nil, fmt.Errorf("failed to open PDF: %w", ErrPasswordRequired)
+	case errors.Is(err, pdf.ErrInvalidPassword):
+		return nil, pdferrors.New(pdferrors.Encrypted, "failed to open PDF: the password does not decrypt the document")
+	default:
+		return nil, pdferrors.New(pdferrors.Corrupt, "failed to open PDF: %w", err)
+	}
+}
+
+// Info describes the file as it was when the document was opened
+func (d *Document) Info() os.FileInfo {
+	return d.info
+}
+
+// ReadAt reads the file's bytes through the document's storage, for the parts of a file
+// the parser does not expose, such as raw stream data
+func (d *Document) ReadAt(p []byte, off int64) (int, error) {
+	if d.mapped != nil {
+		return bytes.NewReader(d.mapped).ReadAt(p, off)
+	}
+	return d.adaptive.ReadAt(p, off)
+}
+
+// MemoryMapped reports whether the document is read through a memory mapping
+func (d *Document) MemoryMapped() bool {
+	return d.mapped != nil
+}
+
+// Stats returns how the document was read. Mapped documents have no physical reads to
+// report; the whole file counts as read.
+func (d *Document) Stats() ReadStats {
+	if d.mapped != nil {
+		return ReadStats{StorageClass: StorageLocal, MemoryMapped: true, BytesRead: d.info.Size()}
+	}
+	return d.adaptive.Stats()
+}
+
+// headerPattern finds the version in the header of a PDF file
+var headerPattern = regexp.MustCompile(`%PDF-(\d+\.\d+)`)
+
+// HeaderVersion returns the PDF version in the file header, such as "1.7", or "" when the
+// first kilobyte holds no header
+func (d *Document) HeaderVersion() string {
+	head := make([]byte, 1024)
+	n, _ := d.source.ReadAt(head, 0)
+	if match := headerPattern.FindSubmatch(head[:n]); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+// acquire adds a reader of a shared document
+func (d *Document) acquire() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.refs++
+}
+
+// Close ends one reader's use of the document, releasing the mapping, if any, and closing
+// the file once no reader is left. Documents the handle cache holds stay open until they
+// expire.
+func (d *Document) Close() error {
+	d.mu.Lock()
+	d.refs--
+	last := d.refs == 0
+	held := last && d.cache != nil
+	if held {
+		d.cache.idle(d)
+	}
+	d.mu.Unlock()
+	if !last || held {
+		return nil
+	}
+	return d.release()
+}
+
+// release unmaps and closes the file
+func (d *Document) release() error {
+	var err error
+	if d.mapped != nil {
+		err = unmapFile(d.mapped)
+		d.mapped = nil
+	}
+	if closeErr := d.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
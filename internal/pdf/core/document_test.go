@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestPDF writes a one-page PDF with a valid cross-reference table
+func writeTestPDF(t *testing.T) string {
+	t.Helper()
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	}
+	var b strings.Builder
+	b.WriteString("%PDF-1.6\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xrefOffset := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	path := filepath.Join(t.TempDir(), "test.pdf")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	return path
+}
+
+func TestOpen(t *testing.T) {
+	path := writeTestPDF(t)
+
+	for _, memoryMap := range []bool{false, true} {
+		doc, err := Open(path, Options{MemoryMap: memoryMap})
+		if err != nil {
+			t.Fatalf("Open(memoryMap %v) unexpected error = %v", memoryMap, err)
+		}
+		if pages := doc.Reader.NumPage(); pages != 1 {
+			t.Errorf("NumPage() = %d, want 1", pages)
+		}
+		if version := doc.HeaderVersion(); version != "1.6" {
+			t.Errorf("HeaderVersion() = %q, want 1.6", version)
+		}
+		head := make([]byte, 5)
+		if _, err := doc.ReadAt(head, 0); err != nil || string(head) != "%PDF-" {
+			t.Errorf("ReadAt() = %q (%v), want the header", head, err)
+		}
+		if err := doc.Close(); err != nil {
+			t.Errorf("Close() unexpected error = %v", err)
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	dir := t.TempDir()
+	damaged := filepath.Join(dir, "damaged.pdf")
+	if err := os.WriteFile(damaged, []byte("%PDF-1.4\nnot a document"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	rejected := fmt.Errorf("file too large")
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     Options
+		errorMsg string
+	}{
+		{name: "empty path", path: "", errorMsg: "path cannot be empty"},
+		{name: "missing file", path: filepath.Join(dir, "missing.pdf"), errorMsg: "file does not exist"},
+		{name: "directory", path: dir, errorMsg: "path is a directory"},
+		{name: "damaged file", path: damaged, errorMsg: "failed to open PDF"},
+		{
+			name:     "validation",
+			path:     writeTestPDF(t),
+			opts:     Options{Validate: func(string, os.FileInfo) error { return rejected }},
+			errorMsg: "file too large",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Open(tt.path, tt.opts)
+			if err == nil {
+				doc.Close()
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Open() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestOpenShared(t *testing.T) {
+	path := writeTestPDF(t)
+	ctx, release := WithDocuments(context.Background())
+
+	first, err := OpenShared(ctx, path, Options{})
+	if err != nil {
+		t.Fatalf("OpenShared() unexpected error = %v", err)
+	}
+	second, err := OpenShared(ctx, path, Options{})
+	if err != nil {
+		t.Fatalf("OpenShared() unexpected error = %v", err)
+	}
+	if first != second {
+		t.Errorf("OpenShared() opened the same path twice within one request")
+	}
+
+	// Validation still applies to a document opened earlier in the request
+	_, err = OpenShared(ctx, path, Options{Validate: func(string, os.FileInfo) error { return fmt.Errorf("rejected") }})
+	if err == nil {
+		t.Errorf("OpenShared() skipped validation of a shared document")
+	}
+
+	// A reader still holding the document after the request is done can keep reading it
+	first.Close()
+	release()
+	if pages := second.Reader.NumPage(); pages != 1 {
+		t.Errorf("NumPage() after release = %d, want 1", pages)
+	}
+	head := make([]byte, 4)
+	if _, err := second.ReadAt(head, 0); err != nil {
+		t.Errorf("ReadAt() after release unexpected error = %v", err)
+	}
+	second.Close()
+
+	// Without a pool, and after release, every open is separate
+	for _, ctx := range []context.Context{context.Background(), ctx} {
+		a, err := OpenShared(ctx, path, Options{})
+		if err != nil {
+			t.Fatalf("OpenShared() unexpected error = %v", err)
+		}
+		b, err := OpenShared(ctx, path, Options{})
+		if err != nil {
+			t.Fatalf("OpenShared() unexpected error = %v", err)
+		}
+		if a == b {
+			t.Errorf("OpenShared() shared a document outside of a request")
+		}
+		a.Close()
+		b.Close()
+	}
+}
+
+func TestOpen_HandleCache(t *testing.T) {
+	SetHandleCache(time.Minute, 2)
+	t.Cleanup(func() { SetHandleCache(0, 0) })
+	path := writeTestPDF(t)
+	before := HandleCacheStats()
+
+	first, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	first.Close()
+	second, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	if second != first {
+		t.Errorf("Open() parsed an unchanged file again, want the held document")
+	}
+	if pages := second.Reader.NumPage(); pages != 1 {
+		t.Errorf("NumPage() of the held document = %d, want 1", pages)
+	}
+
+	// A document opened with other options is a separate handle
+	mapped, err := Open(path, Options{MemoryMap: true})
+	if err != nil {
+		t.Fatalf("Open(memoryMap) unexpected error = %v", err)
+	}
+	if mapped == second {
+		t.Errorf("Open(memoryMap) returned the buffered document")
+	}
+	mapped.Close()
+
+	// Changing the file invalidates the held document; its reader keeps reading the old one
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read PDF: %v", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		t.Fatalf("Failed to rewrite PDF: %v", err)
+	}
+	third, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open() after change unexpected error = %v", err)
+	}
+	if third == second {
+		t.Errorf("Open() after change returned the stale document")
+	}
+	head := make([]byte, 4)
+	if _, err := second.ReadAt(head, 0); err != nil {
+		t.Errorf("ReadAt() of the invalidated document unexpected error = %v", err)
+	}
+	second.Close()
+	third.Close()
+
+	stats := HandleCacheStats()
+	if hits := stats.Hits - before.Hits; hits != 1 {
+		t.Errorf("HandleCacheStats() hits = %d, want 1", hits)
+	}
+	if invalidations := stats.Invalidations - before.Invalidations; invalidations != 1 {
+		t.Errorf("HandleCacheStats() invalidations = %d, want 1", invalidations)
+	}
+	if stats.Open != 2 {
+		t.Errorf("HandleCacheStats() open = %d, want 2", stats.Open)
+	}
+}
+
+func TestOpen_HandleCacheExpires(t *testing.T) {
+	SetHandleCache(20*time.Millisecond, 4)
+	t.Cleanup(func() { SetHandleCache(0, 0) })
+
+	doc, err := Open(writeTestPDF(t), Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	doc.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for HandleCacheStats().Open > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("idle document still held after its TTL: %+v", HandleCacheStats())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HandleStats reports how often tool calls reused a parsed document
+type HandleStats struct {
+	Open          int   `json:"open"`          // Documents held, in use or idle
+	Hits          int64 `json:"hits"`          // Opens served by a held document
+	Misses        int64 `json:"misses"`        // Opens that parsed the file
+	Invalidations int64 `json:"invalidations"` // Held documents dropped because their file changed
+	Expirations   int64 `json:"expirations"`   // Held documents closed after staying idle for the TTL
+}
+
+// handleKey identifies the documents that can stand in for one another: the same file,
+// decrypted with the same password and read through the same storage
+type handleKey struct {
+	path      string
+	password  string
+	memoryMap bool
+}
+
+// handleCache keeps parsed documents open between the tool calls reading them, so that a
+// session reading a file with several tools parses its cross-reference table once. Held
+// documents share the cache's lock for their reference counts.
+type handleCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	entries map[handleKey]*Document
+	stats   HandleStats
+}
+
+// handles is the document cache Open consults; it holds nothing until SetHandleCache
+// enables it
+var handles = &handleCache{entries: make(map[handleKey]*Document)}
+
+// SetHandleCache keeps up to maxDocuments parsed documents open for ttl after their last
+// reader closes them, so that Open returns them again while their file is unchanged. A
+// document whose file has a new size or modification time is parsed afresh. A zero ttl
+// disables the cache and closes the idle documents it holds. The cache is shared by every
+// caller in the process.
+func SetHandleCache(ttl time.Duration, maxDocuments int) {
+	c := handles
+	c.mu.Lock()
+	c.ttl, c.max = max(ttl, 0), max(maxDocuments, 0)
+	var closed []*Document
+	if c.ttl == 0 || c.max == 0 {
+		for _, doc := range c.entries {
+			closed = append(closed, c.remove(doc)...)
+		}
+	}
+	c.mu.Unlock()
+	closeAll(closed)
+}
+
+// HandleCacheStats reports the documents the cache holds and how often they were reused
+func HandleCacheStats() HandleStats {
+	c := handles
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Open = len(c.entries)
+	return stats
+}
+
+// enabled reports whether the cache holds documents
+func (c *handleCache) enabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ttl > 0 && c.max > 0
+}
+
+// get returns a held document of the key for another reader while it still describes the
+// file, as info finds it now
+func (c *handleCache) get(key handleKey, info os.FileInfo) *Document {
+	c.mu.Lock()
+	closed := c.expire()
+	doc, ok := c.entries[key]
+	switch {
+	case !ok:
+		c.stats.Misses++
+		doc = nil
+	case doc.info.Size() != info.Size() || !doc.info.ModTime().Equal(info.ModTime()):
+		c.stats.Invalidations++
+		c.stats.Misses++
+		closed = append(closed, c.remove(doc)...)
+		doc = nil
+	default:
+		c.stats.Hits++
+		doc.refs++
+	}
+	c.mu.Unlock()
+	closeAll(closed)
+	return doc
+}
+
+// put holds a newly opened document for later readers, making room by closing the
+// documents idle the longest. Documents are not held while every held one is in use.
+func (c *handleCache) put(key handleKey, doc *Document) {
+	c.mu.Lock()
+	var closed []*Document
+	if old, ok := c.entries[key]; ok {
+		closed = append(closed, c.remove(old)...)
+	}
+	idle := make([]*Document, 0, len(c.entries))
+	for _, held := range c.entries {
+		if held.refs == 0 {
+			idle = append(idle, held)
+		}
+	}
+	slices.SortFunc(idle, func(a, b *Document) int { return a.idleSince.Compare(b.idleSince) })
+	for len(c.entries) >= c.max && len(idle) > 0 {
+		closed = append(closed, c.remove(idle[0])...)
+		idle = idle[1:]
+	}
+	if c.ttl > 0 && len(c.entries) < c.max {
+		// The document is not shared yet, so its lock can be swapped for the cache's
+		doc.cache, doc.key, doc.mu = c, key, &c.mu
+		c.entries[key] = doc
+	}
+	c.mu.Unlock()
+	closeAll(closed)
+}
+
+// idle starts a held document's TTL once its last reader has closed it; c.mu must be held
+func (c *handleCache) idle(doc *Document) {
+	doc.idleSince = time.Now()
+	time.AfterFunc(c.ttl, c.sweep)
+}
+
+// sweep closes the documents that stayed idle for the TTL
+func (c *handleCache) sweep() {
+	c.mu.Lock()
+	closed := c.expire()
+	c.mu.Unlock()
+	closeAll(closed)
+}
+
+// expire drops the documents idle for the TTL and returns those to close; c.mu must be held
+func (c *handleCache) expire() []*Document {
+	var closed []*Document
+	for _, doc := range c.entries {
+		if doc.refs == 0 && time.Since(doc.idleSince) >= c.ttl {
+			c.stats.Expirations++
+			closed = append(closed, c.remove(doc)...)
+		}
+	}
+	return closed
+}
+
+// remove stops holding a document and returns it when no reader is left to close it;
+// documents still in use close when their last reader is done. c.mu must be held.
+func (c *handleCache) remove(doc *Document) []*Document {
+	delete(c.entries, doc.key)
+	doc.cache = nil
+	if doc.refs == 0 {
+		return []*Document{doc}
+	}
+	return nil
+}
+
+// closeAll releases documents dropped from the cache, outside of its lock
+func closeAll(docs []*Document) {
+	for _, doc := range docs {
+		doc.release()
+	}
+}
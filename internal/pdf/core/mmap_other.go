@@ -0,0 +1,19 @@
+//go:build !((linux || darwin || freebsd || netbsd || openbsd || dragonfly) && (amd64 || arm64 || ppc64 || ppc64le || riscv64 || s390x || loong64 || mips64 || mips64le))
+
+package core
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile reports that memory mapping is unavailable: the platform lacks mmap, or its 32-bit
+// address space cannot hold the large files mapping is meant for
+func mapFile(_ *os.File, _ int64) ([]byte, error) {
+	return nil, errors.New("memory mapping is not supported on this platform")
+}
+
+// unmapFile is a no-op where mapping is unavailable
+func unmapFile(_ []byte) error {
+	return nil
+}
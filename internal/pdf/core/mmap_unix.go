@@ -0,0 +1,18 @@
+//go:build (linux || darwin || freebsd || netbsd || openbsd || dragonfly) && (amd64 || arm64 || ppc64 || ppc64le || riscv64 || s390x || loong64 || mips64 || mips64le)
+
+package core
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the whole file read-only into memory
+func mapFile(f *os.File, size int64) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// unmapFile releases a mapping created by mapFile
+func unmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Adaptive read parameters. The parser issues many small reads scattered across the file;
+// on high-latency storage (network mounts, cloud drives) each one pays the full round trip,
+// so reads are coalesced into chunks sized from the observed latency and throughput.
+const (
+	minChunkSize     = 16 * 1024
+	initialChunkSize = 64 * 1024
+	maxChunkSize     = 4 * 1024 * 1024
+	maxReadAhead     = 4 // Extra chunks fetched per read once access turns sequential
+	maxCachedChunks  = 16
+	// latencyAmortization sizes chunks so transfer time is this multiple of the per-read latency
+	latencyAmortization = 4
+	// latencySmoothing is the weight of the newest sample in the moving averages
+	latencySmoothing = 0.3
+	// slowStorageLatency separates local disks from network or cloud storage
+	slowStorageLatency = 2 * time.Millisecond
+)
+
+// Storage classes reported in read statistics
+const (
+	StorageLocal  = "local"
+	StorageRemote = "remote"
+)
+
+// ReadStats describes how a document was read from storage and the parameters chosen for it
+type ReadStats struct {
+	StorageClass   string        `json:"storage_class"`
+	ChunkSize      int64         `json:"chunk_size"`
+	ReadAhead      int           `json:"read_ahead"` // Chunks fetched ahead on sequential access
+	PhysicalReads  int           `json:"physical_reads"`
+	CacheHits      int           `json:"cache_hits"`
+	BytesRead      int64         `json:"bytes_read"`
+	AvgReadLatency time.Duration `json:"avg_read_latency"`
+	Throughput     float64       `json:"throughput_bytes_per_sec"`
+	MemoryMapped   bool          `json:"memory_mapped,omitempty"` // Read through a memory mapping instead
+}
+
+// adaptiveReader wraps a file with a chunk cache whose chunk size and read-ahead adapt to
+// the latency and throughput observed on each physical read
+type adaptiveReader struct {
+	mu   sync.Mutex
+	src  io.ReaderAt
+	size int64
+
+	chunkSize int64
+	readAhead int
+	lastEnd   int64
+	chunks    map[int64][]byte // Keyed by chunk start offset
+	order     []int64          // Chunk starts in insertion order for eviction
+
+	latency    float64 // Smoothed seconds per physical read
+	throughput float64 // Smoothed bytes per second
+	stats      ReadStats
+}
+
+// newAdaptiveReader creates an adaptive reader over src, which holds size bytes
+func newAdaptiveReader(src io.ReaderAt, size int64) *adaptiveReader {
+	return &adaptiveReader{
+		src:       src,
+		size:      size,
+		chunkSize: initialChunkSize,
+		lastEnd:   -1,
+		chunks:    make(map[int64][]byte),
+	}
+}
+
+// ReadAt implements io.ReaderAt, serving reads from cached chunks where possible
+func (r *adaptiveReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sequential := off == r.lastEnd
+	n := 0
+	for n < len(p) && off+int64(n) < r.size {
+		pos := off + int64(n)
+		chunk, start := r.cachedChunk(pos)
+		if chunk == nil {
+			var err error
+			chunk, start, err = r.fetch(pos, sequential)
+			if err != nil {
+				return n, err
+			}
+		} else {
+			r.stats.CacheHits++
+		}
+		n += copy(p[n:], chunk[pos-start:])
+	}
+
+	r.lastEnd = off + int64(n)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// cachedChunk returns the cached chunk containing pos, if any
+func (r *adaptiveReader) cachedChunk(pos int64) ([]byte, int64) {
+	for _, start := range r.order {
+		chunk := r.chunks[start]
+		if pos >= start && pos < start+int64(len(chunk)) {
+			return chunk, start
+		}
+	}
+	return nil, 0
+}
+
+// fetch performs one physical read covering pos and adapts the parameters to its timing
+func (r *adaptiveReader) fetch(pos int64, sequential bool) ([]byte, int64, error) {
+	if sequential {
+		r.readAhead = min(r.readAhead+1, maxReadAhead)
+	} else {
+		r.readAhead = 0
+	}
+
+	length := min(r.chunkSize*int64(1+r.readAhead), r.size-pos)
+	buf := make([]byte, length)
+
+	started := time.Now()
+	n, err := r.src.ReadAt(buf, pos)
+	elapsed := time.Since(started)
+	if n == 0 && err != nil {
+		return nil, 0, err
+	}
+	buf = buf[:n]
+
+	r.observe(int64(n), elapsed)
+	r.store(pos, buf)
+	return buf, pos, nil
+}
+
+// observe updates the smoothed latency and throughput and re-derives the chunk size
+func (r *adaptiveReader) observe(n int64, elapsed time.Duration) {
+	r.stats.PhysicalReads++
+	r.stats.BytesRead += n
+
+	seconds := elapsed.Seconds()
+	if r.stats.PhysicalReads == 1 {
+		r.latency = seconds
+	} else {
+		r.latency = latencySmoothing*seconds + (1-latencySmoothing)*r.latency
+	}
+	if seconds > 0 {
+		rate := float64(n) / seconds
+		if r.throughput == 0 {
+			r.throughput = rate
+		} else {
+			r.throughput = latencySmoothing*rate + (1-latencySmoothing)*r.throughput
+		}
+	}
+
+	// Large enough that the transfer dominates the fixed cost of each round trip
+	if r.throughput > 0 {
+		target := int64(r.throughput * r.latency * latencyAmortization)
+		r.chunkSize = max(minChunkSize, min(maxChunkSize, roundUpPow2(target)))
+	}
+}
+
+// store caches a chunk, evicting the oldest once the cache is full
+func (r *adaptiveReader) store(start int64, chunk []byte) {
+	if _, exists := r.chunks[start]; !exists {
+		r.order = append(r.order, start)
+	}
+	r.chunks[start] = chunk
+
+	for len(r.order) > maxCachedChunks {
+		delete(r.chunks, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+// Stats returns the read statistics and the parameters currently in use
+func (r *adaptiveReader) Stats() ReadStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats
+	stats.ChunkSize = r.chunkSize
+	stats.ReadAhead = r.readAhead
+	stats.AvgReadLatency = time.Duration(r.latency * float64(time.Second))
+	stats.Throughput = r.throughput
+	stats.StorageClass = StorageLocal
+	if stats.AvgReadLatency >= slowStorageLatency {
+		stats.StorageClass = StorageRemote
+	}
+	return stats
+}
+
+// roundUpPow2 rounds n up to the next power of two
+func roundUpPow2(n int64) int64 {
+	p := int64(1)
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+// slowReaderAt simulates high-latency storage by sleeping before every read
+type slowReaderAt struct {
+	data    []byte
+	latency time.Duration
+	reads   int
+}
+
+func (s *slowReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	s.reads++
+	time.Sleep(s.latency)
+	return bytes.NewReader(s.data).ReadAt(p, off)
+}
+
+func testData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestAdaptiveReader_ReadAtMatchesSource(t *testing.T) {
+	data := testData(300 * 1024)
+	reader := newAdaptiveReader(bytes.NewReader(data), int64(len(data)))
+
+	offsets := []int64{0, 4096, 250 * 1024, 17, 299 * 1024}
+	for _, off := range offsets {
+		buf := make([]byte, 8192)
+		n, err := reader.ReadAt(buf, off)
+		want := min(int64(len(buf)), int64(len(data))-off)
+		if int64(n) != want {
+			t.Fatalf("ReadAt(%d) = %d bytes, want %d", off, n, want)
+		}
+		if n < len(buf) && err != io.EOF {
+			t.Errorf("ReadAt(%d) short read error = %v, want io.EOF", off, err)
+		}
+		if !bytes.Equal(buf[:n], data[off:off+int64(n)]) {
+			t.Errorf("ReadAt(%d) returned different bytes than the source", off)
+		}
+	}
+
+	if _, err := reader.ReadAt(make([]byte, 1), int64(len(data))); err != io.EOF {
+		t.Errorf("ReadAt(end) error = %v, want io.EOF", err)
+	}
+}
+
+func TestAdaptiveReader_AdaptsToSlowStorage(t *testing.T) {
+	data := testData(8 * 1024 * 1024)
+	src := &slowReaderAt{data: data, latency: 5 * time.Millisecond}
+	reader := newAdaptiveReader(src, int64(len(data)))
+
+	// Read sequentially in the parser's small block size
+	buf := make([]byte, 4096)
+	for off := int64(0); off < 2*1024*1024; off += int64(len(buf)) {
+		if _, err := reader.ReadAt(buf, off); err != nil {
+			t.Fatalf("ReadAt(%d) unexpected error = %v", off, err)
+		}
+	}
+
+	stats := reader.Stats()
+	if stats.StorageClass != StorageRemote {
+		t.Errorf("StorageClass = %s, want %s", stats.StorageClass, StorageRemote)
+	}
+	if stats.ChunkSize <= initialChunkSize {
+		t.Errorf("ChunkSize = %d, want larger than the initial %d on slow storage", stats.ChunkSize, initialChunkSize)
+	}
+	if stats.ReadAhead == 0 {
+		t.Error("ReadAhead = 0, want read-ahead on sequential access")
+	}
+	if src.reads >= 2*1024*1024/len(buf)/10 {
+		t.Errorf("physical reads = %d, want reads coalesced well below one per block", src.reads)
+	}
+	if stats.PhysicalReads != src.reads || stats.CacheHits == 0 {
+		t.Errorf("stats = %+v, want %d physical reads and cache hits", stats, src.reads)
+	}
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// documentsKey is the context key of the documents shared within a request
+type documentsKey struct{}
+
+// documents are the documents opened within one request, by path
+type documents struct {
+	mu   sync.Mutex
+	open map[string]*Document
+}
+
+// WithDocuments returns a context within which OpenShared opens each file once, however
+// many steps of a request read it, and the function that releases those documents when
+// the request is done. Readers still using a document when it is released keep it open
+// until they close it.
+func WithDocuments(ctx context.Context) (context.Context, func()) {
+	pool := &documents{open: make(map[string]*Document)}
+	release := func() {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		for _, doc := range pool.open {
+			doc.Close()
+		}
+		pool.open = nil
+	}
+	return context.WithValue(ctx, documentsKey{}, pool), release
+}
+
+// OpenShared opens a document like Open, returning the document already opened for the
+// same path within the context's WithDocuments, if any. The options' Validate check still
+// applies to a shared document. The caller must Close it.
+func OpenShared(ctx context.Context, path string, opts Options) (*Document, error) {
+	pool, _ := ctx.Value(documentsKey{}).(*documents)
+	if pool == nil {
+		return Open(path, opts)
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if pool.open == nil {
+		return Open(path, opts)
+	}
+
+	if doc, ok := pool.open[path]; ok {
+		if opts.Validate != nil {
+			if err := opts.Validate(path, doc.info); err != nil {
+				return nil, err
+			}
+		}
+		doc.acquire()
+		return doc, nil
+	}
+	doc, err := Open(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	doc.acquire()
+	pool.open[path] = doc
+	return doc, nil
+}
@@ -0,0 +1,15 @@
+package pdf
+
+import (
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// formatPDFDate converts a PDF date string to RFC 3339, returning the raw value when it cannot be parsed
+func formatPDFDate(s string) string {
+	if t, ok := extraction.ParsePDFDate(s); ok {
+		return t.Format(time.RFC3339)
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// EntityExtractor finds typed values such as dates, amounts, and contact details in PDF text
+type EntityExtractor struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewEntityExtractor creates a new entity extractor with the specified constraints
+func NewEntityExtractor(maxFileSize int64) *EntityExtractor {
+	return &EntityExtractor{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// ExtractEntities returns the dates, currency amounts, email addresses, phone numbers, street
+// addresses, invoice numbers, and Social Security numbers on the requested pages, each with
+// its normalized value, position, and confidence. Entities are found by pattern and
+// context, so confidence is lower for forms that are often something else, such as numeric
+// dates read month first or phone numbers without a country code or area code parentheses.
+func (e *EntityExtractor) ExtractEntities(
+	ctx context.Context, req PDFExtractEntitiesRequest,
+) (*PDFExtractEntitiesResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	for _, entityType := range req.Types {
+		if !slices.Contains(extraction.EntityTypes, entityType) {
+			return nil, fmt.Errorf("invalid entity type: %q (must be one of %s)",
+				entityType, strings.Join(extraction.EntityTypes, ", "))
+		}
+	}
+	if req.MinConfidence < 0 || req.MinConfidence > 1 {
+		return nil, fmt.Errorf("min_confidence must be between 0 and 1")
+	}
+	if err := validateRegion(req.Region); err != nil {
+		return nil, err
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: e.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	numbering := extraction.NewPageNumbering(r)
+	result := &PDFExtractEntitiesResult{
+		Path:       req.Path,
+		TotalPages: numbering.Count(),
+		Entities:   []NamedEntity{},
+		Counts:     make(map[string]int),
+	}
+
+	region := regionBounds(req.Region)
+	pages := req.Pages
+	if len(pages) == 0 {
+		for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+			pages = append(pages, pageNum)
+		}
+	}
+
+	for _, pageNum := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if pageNum < 1 || pageNum > numbering.Count() {
+			return nil, pageOutOfRange(pageNum, numbering.Count())
+		}
+
+		text, err := pageText(numbering, pageNum)
+		if err != nil {
+			result.FailedPages = append(result.FailedPages, pageNum)
+			continue
+		}
+		for _, entity := range extraction.FindEntities(text, req.Types) {
+			if entity.Confidence < req.MinConfidence {
+				continue
+			}
+			if region != nil && !extraction.CenteredIn(entity.BoundingBox, *region) {
+				continue
+			}
+			entity.Page = pageNum
+			entity.PageLabel = numbering.Label(pageNum)
+			result.Entities = append(result.Entities, convertEntity(entity))
+			result.Counts[entity.Type]++
+		}
+	}
+
+	return result, nil
+}
+
+// convertEntities maps the engine's entities to the public type
+func convertEntities(entities []extraction.Entity) []NamedEntity {
+	if len(entities) == 0 {
+		return nil
+	}
+	converted := make([]NamedEntity, len(entities))
+	for i, entity := range entities {
+		converted[i] = convertEntity(entity)
+	}
+	return converted
+}
+
+func convertEntity(entity extraction.Entity) NamedEntity {
+	return NamedEntity{
+		Type:        entity.Type,
+		Text:        entity.Text,
+		Value:       entity.Value,
+		Currency:    entity.Currency,
+		Page:        entity.Page,
+		PageLabel:   entity.PageLabel,
+		BoundingBox: convertBoundingBox(entity.BoundingBox),
+		Confidence:  entity.Confidence,
+	}
+}
@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEntityExtractor_ExtractEntities(t *testing.T) {
+	extractor := NewEntityExtractor(100 * 1024 * 1024)
+	path := createTempFile(t, "invoice.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (Invoice No. A-1001 dated March 3, 2024) Tj ET\n"+
+			"BT /F1 12 Tf 72 700 Td (Questions: billing@example.com or 555-010-4477) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Amount due: $1,250.00) Tj ET",
+	))
+
+	result, err := extractor.ExtractEntities(context.Background(), PDFExtractEntitiesRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractEntities() unexpected error = %v", err)
+	}
+	want := []struct {
+		typ, value string
+		page       int
+	}{
+		{EntityInvoiceNumber, "A-1001", 1},
+		{EntityDate, "2024-03-03", 1},
+		{EntityEmail, "billing@example.com", 1},
+		{EntityPhone, "5550104477", 1},
+		{EntityAmount, "1250", 2},
+	}
+	if len(result.Entities) != len(want) {
+		t.Fatalf("ExtractEntities() = %+v, want %d entities", result.Entities, len(want))
+	}
+	for i, w := range want {
+		entity := result.Entities[i]
+		if entity.Type != w.typ || entity.Value != w.value || entity.Page != w.page {
+			t.Errorf("entity %d = %s %q on page %d, want %s %q on page %d",
+				i, entity.Type, entity.Value, entity.Page, w.typ, w.value, w.page)
+		}
+		if entity.BoundingBox.Width <= 0 || entity.BoundingBox.Y < 690 {
+			t.Errorf("entity %d box = %+v, want it on the text lines", i, entity.BoundingBox)
+		}
+	}
+	if result.Counts[EntityDate] != 1 || result.Counts[EntityAmount] != 1 {
+		t.Errorf("counts = %v, want one date and one amount", result.Counts)
+	}
+
+	tests := []struct {
+		name string
+		req  PDFExtractEntitiesRequest
+		want int
+	}{
+		{"selected types", PDFExtractEntitiesRequest{Types: []string{EntityEmail, EntityAmount}}, 2},
+		{"selected pages", PDFExtractEntitiesRequest{Pages: []int{2}}, 1},
+		{"min confidence", PDFExtractEntitiesRequest{MinConfidence: 0.8}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Path = path
+			result, err := extractor.ExtractEntities(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("ExtractEntities() unexpected error = %v", err)
+			}
+			if len(result.Entities) != tt.want {
+				t.Errorf("ExtractEntities() = %+v, want %d entities", result.Entities, tt.want)
+			}
+		})
+	}
+
+	for _, req := range []PDFExtractEntitiesRequest{
+		{},
+		{Path: path, Types: []string{"name"}},
+		{Path: path, Pages: []int{3}},
+		{Path: path, MinConfidence: 2},
+	} {
+		if _, err := extractor.ExtractEntities(context.Background(), req); err == nil {
+			t.Errorf("ExtractEntities(%+v) expected an error", req)
+		}
+	}
+}
+
+func TestExtractionService_SemanticEntities(t *testing.T) {
+	service := NewExtractionService(100 * 1024 * 1024)
+	path := createTempFile(t, "contact.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (Write to support@example.com by 2024-06-30) Tj ET",
+	))
+
+	result, err := service.ExtractSemantic(context.Background(), PDFExtractRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractSemantic() unexpected error = %v", err)
+	}
+	if len(result.Entities) != 2 || result.Entities[0].Type != EntityEmail || result.Entities[1].Type != EntityDate {
+		t.Fatalf("ExtractSemantic() entities = %+v, want an email and a date", result.Entities)
+	}
+	if result.Entities[1].Page != 1 || result.Entities[1].Value != "2024-06-30" {
+		t.Errorf("date entity = %+v, want 2024-06-30 on page 1", result.Entities[1])
+	}
+}
@@ -0,0 +1,202 @@
+// Package errors defines the codes tools report their failures with, so that clients can
+// decide programmatically whether to retry, ask for other arguments, or give up. Errors
+// carry a code, a remediation hint follows from it, and a failure that produced part of
+// its result may carry that partial result along. Codes are given where errors are
+// created; classifying an error by its message is a fallback for those without one.
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// Code classifies a tool failure
+type Code string
+
+// Error codes
+const (
+	NotFound           Code = "NOT_FOUND"           // The file, section, or object named does not exist
+	Encrypted          Code = "ENCRYPTED"           // The document is encrypted with a password the server lacks
+	Corrupt            Code = "CORRUPT"             // The file is not a well-formed PDF
+	TooLarge           Code = "TOO_LARGE"           // The file or output exceeds a configured limit
+	UnsupportedFeature Code = "UNSUPPORTED_FEATURE" // The document uses, or the request needs, something not supported
+	Timeout            Code = "TIMEOUT"             // The request ran out of time
+	Busy               Code = "BUSY"                // The server or session is running as many calls as it may
+	InvalidArgument    Code = "INVALID_ARGUMENT"    // An argument is missing, malformed, or names a missing page
+	Internal           Code = "INTERNAL"            // Any other failure
+)
+
+// hints tell clients how to recover from each kind of failure
+var hints = map[Code]string{
+	NotFound: "Check the path: list the available documents with pdf_search_directory, " +
+		"and note that relative paths are resolved against the configured directory.",
+	Encrypted: "The document needs a password the server does not have; " +
+		"check it with pdf_get_permissions or provide a decrypted copy.",
+	Corrupt: "The file could not be parsed as a PDF; check that it is complete and not damaged, " +
+		"or inspect it with pdf_validate_file.",
+	TooLarge: "Select fewer pages, or raise the limit with --max-file-size when starting the server.",
+	UnsupportedFeature: "The document or request needs a feature this server does not support; " +
+		"install the program named in the message, if any, or use another tool.",
+	Timeout:         "Retry with a larger timeout argument, or select fewer pages.",
+	Busy:            "Wait for earlier calls to finish before retrying, or make fewer calls in parallel.",
+	InvalidArgument: "Check the tool's arguments against its input schema.",
+	Internal:        "Retry the request; if it keeps failing, report the message.",
+}
+
+// Error is an error with a code, and the partial result of the work done before it occurred
+type Error struct {
+	Code    Code
+	Err     error // The underlying error, whose message is reported
+	Partial any   // Result produced before the failure, if any
+}
+
+// Error returns the underlying error's message
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New formats an error with a code, like fmt.Errorf
+func New(code Code, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// WithPartial attaches the partial result of a failed operation to its error, keeping the
+// error's code
+func WithPartial(err error, partial any) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: CodeOf(err), Err: err, Partial: partial}
+}
+
+// CodeOf classifies an error: by the code of an Error it wraps, by the standard errors for
+// missing files, expired contexts, and truncated data, and failing those by its message
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var coded *Error
+	if stderrors.As(err, &coded) {
+		return coded.Code
+	}
+	switch {
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case stderrors.Is(err, fs.ErrNotExist):
+		return NotFound
+	case stderrors.Is(err, io.ErrUnexpectedEOF):
+		return Corrupt
+	}
+	return CodeOfMessage(err.Error())
+}
+
+// messageCodes are the phrases of error messages that reveal their code, checked in order.
+// Pages out of range are arguments the document cannot satisfy, not missing files.
+var messageCodes = []struct {
+	phrase string
+	code   Code
+}{
+	{"required argument", InvalidArgument},
+	{"out of range", InvalidArgument},
+	{"timed out", Timeout},
+	{"deadline exceeded", Timeout},
+	{"encrypted", Encrypted},
+	{"password", Encrypted},
+	{"too large", TooLarge},
+	{"exceeds", TooLarge},
+	{"quota", TooLarge},
+	{"does not exist", NotFound},
+	{"not found", NotFound},
+	{"no such", NotFound},
+	{"unknown handle", NotFound},
+	{"not a pdf", Corrupt},
+	{"unexpected eof", Corrupt},
+	{"failed to open pdf", Corrupt},
+	{"malformed", Corrupt},
+	{"corrupt", Corrupt},
+	{"not supported", UnsupportedFeature},
+	{"unsupported", UnsupportedFeature},
+	{"not installed", UnsupportedFeature},
+	{"not enabled", UnsupportedFeature},
+	{"not decoded", UnsupportedFeature},
+	{"cannot be empty", InvalidArgument},
+	{"required", InvalidArgument},
+	{"invalid", InvalidArgument},
+	{"must ", InvalidArgument},
+	{"unknown", InvalidArgument},
+}
+
+// CodeOfMessage classifies an error by its message alone, for errors reported as text
+func CodeOfMessage(message string) Code {
+	message = strings.ToLower(message)
+	for _, m := range messageCodes {
+		if strings.Contains(message, m.phrase) {
+			return m.code
+		}
+	}
+	return Internal
+}
+
+// PartialOf returns the partial result attached to an error, if any
+func PartialOf(err error) any {
+	var coded *Error
+	for err != nil {
+		if !stderrors.As(err, &coded) {
+			return nil
+		}
+		if coded.Partial != nil {
+			return coded.Partial
+		}
+		err = coded.Err
+	}
+	return nil
+}
+
+// Hint returns the remediation hint for a code
+func Hint(code Code) string {
+	if hint, ok := hints[code]; ok {
+		return hint
+	}
+	return hints[Internal]
+}
+
+// Retryable reports whether repeating a request that failed with a code may succeed
+func Retryable(code Code) bool {
+	return code == Timeout || code == Busy || code == Internal
+}
+
+// Details is the machine-readable description of a tool error
+type Details struct {
+	Code      Code   `json:"code"`
+	Message   string `json:"message"`
+	Hint      string `json:"hint"`
+	Retryable bool   `json:"retryable"`
+	Partial   any    `json:"partial,omitempty"` // Result produced before the failure
+}
+
+// Describe returns the details of an error
+func Describe(err error) Details {
+	code := CodeOf(err)
+	return Details{
+		Code:      code,
+		Message:   err.Error(),
+		Hint:      Hint(code),
+		Retryable: Retryable(code),
+		Partial:   PartialOf(err),
+	}
+}
+
+// DescribeMessage returns the details of an error reported as text
+func DescribeMessage(message string) Details {
+	code := CodeOfMessage(message)
+	return Details{Code: code, Message: message, Hint: Hint(code), Retryable: Retryable(code)}
+}
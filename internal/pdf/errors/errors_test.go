@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	_, statErr := os.Stat("/nonexistent/report.pdf")
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{name: "coded", err: New(Encrypted, "the document is encrypted"), want: Encrypted},
+		{name: "wrapped", err: fmt.Errorf("failed to open PDF: %w", New(Corrupt, "bad xref")), want: Corrupt},
+		{name: "deadline", err: fmt.Errorf("extraction stopped: %w", context.DeadlineExceeded), want: Timeout},
+		{name: "missing file", err: statErr, want: NotFound},
+		{name: "message", err: stderrors.New("file too large: 20 bytes (max: 10 bytes)"), want: TooLarge},
+		{name: "argument", err: stderrors.New(`required argument "path" not found`), want: InvalidArgument},
+		{name: "truncated", err: fmt.Errorf("failed to read stream: %w", io.ErrUnexpectedEOF), want: Corrupt},
+		{name: "truncated message", err: stderrors.New("stream: unexpected EOF"), want: Corrupt},
+		{name: "out of range", err: stderrors.New("page 9 out of range (document has 2 pages)"), want: InvalidArgument},
+		{name: "other", err: stderrors.New("something went wrong"), want: Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	partial := []string{"table 1"}
+	cause := New(TooLarge, "output too large")
+	err := fmt.Errorf("export failed: %w", WithPartial(cause, partial))
+
+	details := Describe(err)
+	if details.Code != TooLarge || details.Message != "export failed: output too large" ||
+		details.Hint != Hint(TooLarge) || details.Retryable {
+		t.Errorf("Describe() = %+v", details)
+	}
+	if got, ok := details.Partial.([]string); !ok || len(got) != 1 {
+		t.Errorf("Describe().Partial = %#v, want the partial result", details.Partial)
+	}
+	if !stderrors.Is(err, cause) {
+		t.Error("WithPartial() does not wrap the original error")
+	}
+
+	if timeout := DescribeMessage("pdftoppm timed out after 30s"); timeout.Code != Timeout || !timeout.Retryable {
+		t.Errorf("DescribeMessage() = %+v, want a retryable timeout", timeout)
+	}
+}
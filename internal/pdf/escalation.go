@@ -0,0 +1,155 @@
+package pdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Escalation metrics
+const (
+	// MetricDecodeQuality is the share of extracted characters that decoded to printable text
+	MetricDecodeQuality = "decode_quality"
+)
+
+// Escalation actions, from mildest to strictest
+const (
+	EscalationWarn       = "warn"        // Add a warning to the result
+	EscalationNeedsHuman = "needs_human" // Flag the result for human review
+	EscalationReject     = "reject"      // Fail the request instead of returning low quality content
+	escalationOCR        = "ocr"         // Recognized so that the error can explain it is unavailable
+)
+
+// EscalationRule triggers an action when a quality metric falls below a threshold
+type EscalationRule struct {
+	Metric string
+	Below  float64
+	Action string
+}
+
+// String formats the rule in the syntax accepted by ParseEscalationPolicy
+func (r EscalationRule) String() string {
+	return fmt.Sprintf("%s<%s:%s", r.Metric, strconv.FormatFloat(r.Below, 'f', -1, 64), r.Action)
+}
+
+// EscalationPolicy is the operator-defined set of quality safeguards applied to every tool
+// that returns extracted text
+type EscalationPolicy struct {
+	Rules []EscalationRule
+}
+
+// ParseEscalationPolicy parses a comma-separated list of rules such as
+// "decode_quality<0.6:needs_human,decode_quality<0.2:reject". An empty spec is an empty policy.
+func ParseEscalationPolicy(spec string) (EscalationPolicy, error) {
+	var policy EscalationPolicy
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		condition, action, ok := strings.Cut(part, ":")
+		if !ok {
+			return EscalationPolicy{}, fmt.Errorf("invalid escalation rule %q: expected metric<threshold:action", part)
+		}
+		metric, threshold, ok := strings.Cut(condition, "<")
+		if !ok {
+			return EscalationPolicy{}, fmt.Errorf("invalid escalation rule %q: expected metric<threshold:action", part)
+		}
+
+		rule := EscalationRule{
+			Metric: strings.TrimSpace(metric),
+			Action: strings.TrimSpace(action),
+		}
+		if rule.Metric != MetricDecodeQuality {
+			return EscalationPolicy{}, fmt.Errorf("invalid escalation rule %q: unknown metric %q (supported: %s)",
+				part, rule.Metric, MetricDecodeQuality)
+		}
+
+		below, err := strconv.ParseFloat(strings.TrimSpace(threshold), 64)
+		if err != nil || below < 0 || below > 1 {
+			return EscalationPolicy{}, fmt.Errorf("invalid escalation rule %q: threshold must be between 0 and 1", part)
+		}
+		rule.Below = below
+
+		switch rule.Action {
+		case EscalationWarn, EscalationNeedsHuman, EscalationReject:
+		case escalationOCR:
+			return EscalationPolicy{}, fmt.Errorf("invalid escalation rule %q: no OCR engine is available", part)
+		default:
+			return EscalationPolicy{}, fmt.Errorf("invalid escalation rule %q: unknown action %q (supported: %s, %s, %s)",
+				part, rule.Action, EscalationWarn, EscalationNeedsHuman, EscalationReject)
+		}
+
+		policy.Rules = append(policy.Rules, rule)
+	}
+	return policy, nil
+}
+
+// Evaluate applies the policy to the extracted text, returning nil when the policy has no
+// rules and an error when a reject rule is triggered
+func (p EscalationPolicy) Evaluate(text string) (*QualityEscalation, error) {
+	if len(p.Rules) == 0 {
+		return nil, nil
+	}
+
+	escalation := &QualityEscalation{DecodeQuality: DecodeQuality(text)}
+	for _, rule := range p.Rules {
+		if escalation.DecodeQuality >= rule.Below {
+			continue
+		}
+
+		escalation.Triggered = append(escalation.Triggered, rule.String())
+		switch rule.Action {
+		case EscalationWarn:
+			escalation.Warnings = append(escalation.Warnings,
+				fmt.Sprintf("decode quality %.2f is below %g", escalation.DecodeQuality, rule.Below))
+		case EscalationNeedsHuman:
+			escalation.NeedsHuman = true
+		case EscalationReject:
+			return nil, fmt.Errorf("rejected by escalation policy: decode quality %.2f is below %g",
+				escalation.DecodeQuality, rule.Below)
+		}
+	}
+	return escalation, nil
+}
+
+// DecodeQuality returns the share of non-space characters in text that decoded to printable
+// characters, between 0 and 1. Replacement characters, control characters, private use and
+// unassigned code points are what broken font encodings produce. Empty text scores 0.
+func DecodeQuality(text string) float64 {
+	total, printable := 0, 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if r != utf8.RuneError && unicode.IsPrint(r) {
+			printable++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return roundScore(float64(printable) / float64(total))
+}
+
+// extractedText returns the text of an extraction result for quality assessment
+func extractedText(result *PDFExtractResult) string {
+	var builder strings.Builder
+	for _, element := range result.Elements {
+		builder.WriteString(queryElementText(element))
+		builder.WriteByte('\n')
+	}
+	for _, table := range result.Tables {
+		for _, row := range table.Rows {
+			for _, cell := range row.Cells {
+				builder.WriteString(cell.Content)
+				builder.WriteByte('\n')
+			}
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,125 @@
+package pdf
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseEscalationPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		wantRules []EscalationRule
+		errorMsg  string
+	}{
+		{name: "empty", spec: ""},
+		{
+			name: "several rules",
+			spec: "decode_quality<0.6:needs_human, decode_quality < 0.2 : reject",
+			wantRules: []EscalationRule{
+				{Metric: MetricDecodeQuality, Below: 0.6, Action: EscalationNeedsHuman},
+				{Metric: MetricDecodeQuality, Below: 0.2, Action: EscalationReject},
+			},
+		},
+		{name: "missing action", spec: "decode_quality<0.6", errorMsg: "expected metric<threshold:action"},
+		{name: "missing threshold", spec: "decode_quality:warn", errorMsg: "expected metric<threshold:action"},
+		{name: "unknown metric", spec: "ocr_confidence<0.7:needs_human", errorMsg: "unknown metric"},
+		{name: "threshold out of range", spec: "decode_quality<1.5:warn", errorMsg: "between 0 and 1"},
+		{name: "unknown action", spec: "decode_quality<0.5:retry", errorMsg: "unknown action"},
+		{name: "ocr unavailable", spec: "decode_quality<0.6:ocr", errorMsg: "no OCR engine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseEscalationPolicy(tt.spec)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("ParseEscalationPolicy() error = %v, want error containing %v", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEscalationPolicy() unexpected error = %v", err)
+			}
+			if len(policy.Rules) != len(tt.wantRules) {
+				t.Fatalf("ParseEscalationPolicy() = %+v, want %+v", policy.Rules, tt.wantRules)
+			}
+			for i, rule := range tt.wantRules {
+				if policy.Rules[i] != rule {
+					t.Errorf("rule %d = %+v, want %+v", i, policy.Rules[i], rule)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeQuality(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{text: "Clean text, all printable.", want: 1},
+		{text: "ab\ufffd\ufffd", want: 0.5},
+		{text: "a\x01\x02\x03", want: 0.25},
+		{text: "x\ue000", want: 0.5},
+		{text: " \n\t", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := DecodeQuality(tt.text); got != tt.want {
+			t.Errorf("DecodeQuality(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestService_EscalationPolicy(t *testing.T) {
+	clean := createTempFile(t, "clean.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Clean readable text) Tj ET"))
+	garbled := createTempFile(t, "garbled.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (ab\\001\\002\\003\\004\\005\\006) Tj ET"))
+
+	service := NewService(100 * 1024 * 1024)
+	result, err := service.PDFReadFile(PDFReadFileRequest{Path: garbled})
+	if err != nil {
+		t.Fatalf("PDFReadFile() unexpected error = %v", err)
+	}
+	if result.Escalation != nil {
+		t.Errorf("PDFReadFile() escalation = %+v, want none without a policy", result.Escalation)
+	}
+
+	policy, err := ParseEscalationPolicy("decode_quality<0.8:warn,decode_quality<0.6:needs_human")
+	if err != nil {
+		t.Fatalf("ParseEscalationPolicy() unexpected error = %v", err)
+	}
+	service.SetEscalationPolicy(policy)
+
+	result, err = service.PDFReadFile(PDFReadFileRequest{Path: clean})
+	if err != nil {
+		t.Fatalf("PDFReadFile() unexpected error = %v", err)
+	}
+	if result.Escalation == nil || result.Escalation.DecodeQuality != 1 || result.Escalation.NeedsHuman {
+		t.Errorf("clean escalation = %+v, want quality 1 without review", result.Escalation)
+	}
+
+	result, err = service.PDFReadFile(PDFReadFileRequest{Path: garbled})
+	if err != nil {
+		t.Fatalf("PDFReadFile() unexpected error = %v", err)
+	}
+	if e := result.Escalation; e == nil || !e.NeedsHuman || len(e.Triggered) != 2 || len(e.Warnings) != 1 {
+		t.Errorf("garbled escalation = %+v, want both rules triggered", result.Escalation)
+	}
+
+	extracted, err := service.ExtractStructured(context.Background(), PDFExtractStructuredRequest{Path: garbled})
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	if extracted.Escalation == nil || !extracted.Escalation.NeedsHuman {
+		t.Errorf("ExtractStructured() escalation = %+v, want needs_human", extracted.Escalation)
+	}
+
+	policy, _ = ParseEscalationPolicy("decode_quality<0.6:reject")
+	service.SetEscalationPolicy(policy)
+	if _, err := service.PDFReadFile(PDFReadFileRequest{Path: garbled}); err == nil ||
+		!strings.Contains(err.Error(), "rejected by escalation policy") {
+		t.Errorf("PDFReadFile() error = %v, want rejection", err)
+	}
+}
@@ -0,0 +1,301 @@
+package pdf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+	"github.com/ledongthuc/pdf"
+)
+
+// Export formats
+const (
+	ExportFormatDOCX = "docx"
+	ExportFormatODT  = "odt"
+
+	docxMIMEType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	odtMIMEType  = "application/vnd.oasis.opendocument.text"
+)
+
+// Document structure constants
+const (
+	// paragraphGapRatio is the vertical gap between lines, relative to the body text size,
+	// that starts a new paragraph
+	paragraphGapRatio = 0.8
+	// headingLevel1Ratio and headingLevel2Ratio are the font size ratios, relative to the
+	// body text, of first and second level headings; smaller headings are third level
+	headingLevel1Ratio = 1.6
+	headingLevel2Ratio = 1.35
+	// exportFilePerm is the permission of exported documents
+	exportFilePerm = 0o600
+)
+
+// listItemPattern matches a bullet or number marker opening a list item
+var listItemPattern = regexp.MustCompile(`^(?:[•▪◦‣∙·*\-–]|\(?(\d{1,3}|[a-z])[.)])\s+`)
+
+// Block kinds of an exported document
+const (
+	blockHeading   = "heading"
+	blockParagraph = "paragraph"
+	blockListItem  = "list_item"
+	blockTable     = "table"
+	blockImage     = "image"
+)
+
+// exportBlock is one structural element of an exported document
+type exportBlock struct {
+	kind    string
+	level   int        // Heading level, 1-3
+	ordered bool       // Numbered rather than bulleted list item
+	text    string     // Heading, paragraph, list item, or image placeholder text
+	rows    [][]string // Table cells, every row padded to the same width
+	image   pdf.Value  // Image XObject, for formats that can embed decodable images
+	bounds  Rectangle  // Area of the text or table on its page, in PDF points
+}
+
+// documentWriters serialize blocks into each export format
+var documentWriters = map[string]struct {
+	mimeType string
+	write    func(blocks []exportBlock) ([]byte, error)
+}{
+	ExportFormatDOCX: {docxMIMEType, writeDOCX},
+	ExportFormatODT:  {odtMIMEType, writeODT},
+}
+
+// Exporter converts the detected structure of a PDF into an editable document
+type Exporter struct {
+	maxFileSize int64
+	validator   *Validator
+	assets      *Assets
+	engine      *extraction.DefaultEngine
+}
+
+// NewExporter creates a new document exporter with the specified constraints
+func NewExporter(maxFileSize int64) *Exporter {
+	return &Exporter{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+		assets:      NewAssets(maxFileSize),
+		engine:      extraction.NewEngineWithConfig(maxFileSize, maxFileSize, false),
+	}
+}
+
+// ExportDocument converts headings, paragraphs, lists, tables, and images into a DOCX or
+// ODT skeleton, returning it base64-encoded or saving it to the output directory. Images
+// become placeholder paragraphs because the parser cannot decode image streams.
+func (e *Exporter) ExportDocument(ctx context.Context, req PDFExportDocumentRequest) (*PDFExportDocumentResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = ExportFormatDOCX
+	}
+	writer, ok := documentWriters[format]
+	if !ok {
+		return nil, fmt.Errorf("invalid format: %s (must be %s or %s)", req.Format, ExportFormatDOCX, ExportFormatODT)
+	}
+
+	structure := defaultStructure
+	if req.StructureConfig != nil {
+		if err := req.StructureConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid structure_config: %w", err)
+		}
+		structure, _ = newStructureDetector(*req.StructureConfig)
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := e.open(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	tables, err := detectTables(ctx, e.engine, req.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	numbering := extraction.NewPageNumbering(r)
+	result := &PDFExportDocumentResult{
+		Path:      req.Path,
+		Format:    format,
+		MIMEType:  writer.mimeType,
+		Pages:     numbering.Count(),
+		Structure: structure.config,
+	}
+
+	var blocks []exportBlock
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, e.pageBlocks(numbering, pageNum, tables[pageNum], structure)...)
+	}
+	for _, block := range blocks {
+		switch block.kind {
+		case blockHeading:
+			result.Headings++
+		case blockParagraph:
+			result.Paragraphs++
+		case blockListItem:
+			result.ListItems++
+		case blockTable:
+			result.Tables++
+		case blockImage:
+			result.Images++
+		}
+	}
+
+	data, err := writer.write(blocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s document: %w", format, err)
+	}
+	result.Size = len(data)
+
+	if req.OutputDir == "" {
+		result.Data = base64.StdEncoding.EncodeToString(data)
+		return result, nil
+	}
+
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+	stem := strings.TrimSuffix(filepath.Base(req.Path), filepath.Ext(req.Path))
+	result.OutputPath = filepath.Join(req.OutputDir, stem+"."+format)
+	if err := fsys.WriteFile(result.OutputPath, data, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save exported document: %w", err)
+	}
+
+	return result, nil
+}
+
+// open validates and opens a document, sharing it with the table detection of the request
+// when ctx comes from core.WithDocuments
+func (e *Exporter) open(ctx context.Context, path string) (*core.Document, error) {
+	return core.OpenShared(ctx, path, core.Options{Validate: e.validator.ValidateFileInfo})
+}
+
+// detectTables runs table detection over the given pages, or the whole document when pages
+// is empty, grouping tables by page
+func detectTables(
+	ctx context.Context, engine extraction.Engine, path string, pages []int,
+) (map[int][]extraction.TableElement, error) {
+	extracted, err := engine.Extract(ctx, extraction.ExtractionRequest{
+		FilePath: path,
+		Config:   extraction.ExtractionConfig{Mode: extraction.ModeTable, ExtractTables: true, Pages: pages},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect tables: %w", err)
+	}
+	if extracted.Partial {
+		return nil, fmt.Errorf("table detection did not finish: %w", ctx.Err())
+	}
+
+	tables := make(map[int][]extraction.TableElement)
+	for _, table := range extracted.Tables {
+		tables[table.PageNumber] = append(tables[table.PageNumber], table)
+	}
+	return tables, nil
+}
+
+// pageBlocks turns a page's lines into headings, paragraphs, and list items, placing each
+// table where its first line would have been and appending image placeholders
+func (e *Exporter) pageBlocks(
+	numbering *extraction.PageNumbering, pageNum int, tables []extraction.TableElement, structure *structureDetector,
+) (blocks []exportBlock) {
+	// The parser panics on malformed pages; keep the blocks built so far
+	defer func() {
+		if recover() != nil {
+			blocks = append(blocks, exportBlock{kind: blockParagraph,
+				text: fmt.Sprintf("[Page %d could not be read]", pageNum)})
+		}
+	}()
+
+	page := numbering.Page(pageNum)
+	lines, bodySize := pageTopLines(page, math.MaxInt)
+	blocks = structure.textBlocks(lines, bodySize, tables)
+	for _, obj := range pageImageObjects(page) {
+		if image := e.assets.extractImageInfo(obj, pageNum); image != nil {
+			blocks = append(blocks, exportBlock{kind: blockImage, image: obj,
+				text: fmt.Sprintf("[Image: %dx%d %s on page %d]", image.Width, image.Height, image.Format, pageNum)})
+		}
+	}
+
+	return blocks
+}
+
+// pageImageObjects returns the image XObjects in a page's resources
+func pageImageObjects(page pdf.Page) []pdf.Value {
+	xObjects := page.V.Key("Resources").Key("XObject")
+	if xObjects.Kind() != pdf.Dict {
+		return nil
+	}
+
+	var images []pdf.Value
+	for _, key := range xObjects.Keys() {
+		if obj := xObjects.Key(key); obj.Key("Subtype").Name() == "Image" {
+			images = append(images, obj)
+		}
+	}
+	return images
+}
+
+// tableContaining returns the index of the table whose bounds contain the middle of the
+// line, or -1
+func tableContaining(tables []extraction.TableElement, line pageLine) int {
+	middle := (line.Top + line.Bottom) / 2
+	for i, table := range tables {
+		box := table.BoundingBox
+		if middle >= box.LowerLeft.Y && middle <= box.UpperRight.Y {
+			return i
+		}
+	}
+	return -1
+}
+
+// tableBlock copies a detected table's cell text, padding rows to the widest row
+func tableBlock(table extraction.TableElement) exportBlock {
+	width := 0
+	for _, row := range table.Rows {
+		for _, cell := range row.Cells {
+			width = max(width, cell.ColIndex+1)
+		}
+	}
+
+	rows := make([][]string, len(table.Rows))
+	for i, row := range table.Rows {
+		rows[i] = make([]string, width)
+		for _, cell := range row.Cells {
+			if cell.ColIndex >= 0 && cell.ColIndex < width {
+				rows[i][cell.ColIndex] = cell.Content
+			}
+		}
+	}
+	return exportBlock{kind: blockTable, rows: rows, bounds: convertBoundingBox(table.BoundingBox)}
+}
+
+// lineBounds returns the area a line covers
+func lineBounds(line pageLine) Rectangle {
+	return Rectangle{X: line.Left, Y: line.Bottom, Width: line.Right - line.Left, Height: line.Top - line.Bottom}
+}
+
+// unionRectangles returns the smallest rectangle covering both
+func unionRectangles(a, b Rectangle) Rectangle {
+	x, y := math.Min(a.X, b.X), math.Min(a.Y, b.Y)
+	return Rectangle{
+		X: x, Y: y,
+		Width:  math.Max(a.X+a.Width, b.X+b.Width) - x,
+		Height: math.Max(a.Y+a.Height, b.Y+b.Height) - y,
+	}
+}
@@ -0,0 +1,512 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+	"github.com/ledongthuc/pdf"
+)
+
+// Book export formats
+const (
+	ExportFormatEPUB = "epub"
+	ExportFormatHTML = "html"
+
+	epubMIMEType = "application/epub+zip"
+	zipMIMEType  = "application/zip"
+)
+
+// frontMatterTitle names the pages before the first outline chapter
+const frontMatterTitle = "Front matter"
+
+// bookChapter is a chapter of an exported book and the outline entries nested under it
+type bookChapter struct {
+	BookChapter
+	children []OutlineItem
+}
+
+// ExportBook converts a long-form document into an EPUB or a bundle of linked HTML pages.
+// Top-level outline entries become chapters and deeper entries link into them; every page
+// is anchored as <chapter file>#page-<n> so readers can cite it. A chapter starting on the
+// same page as the next one holds only its title. Images are embedded when they are
+// uncompressed or Flate-compressed gray or RGB; others become placeholders.
+func (e *Exporter) ExportBook(ctx context.Context, req PDFExportBookRequest) (*PDFExportBookResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = ExportFormatEPUB
+	}
+	if format != ExportFormatEPUB && format != ExportFormatHTML {
+		return nil, fmt.Errorf("invalid format: %s (must be %s or %s)", req.Format, ExportFormatEPUB, ExportFormatHTML)
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := e.open(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	tables, err := detectTables(ctx, e.engine, req.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	numbering := extraction.NewPageNumbering(r)
+	stem := strings.TrimSuffix(filepath.Base(req.Path), filepath.Ext(req.Path))
+	result := &PDFExportBookResult{
+		Path:   req.Path,
+		Format: format,
+		Title:  documentTitle(r, stem),
+		Pages:  numbering.Count(),
+	}
+
+	chapters := bookChapters(readOutline(req.Path, r, numbering).Items, numbering.Count(), format)
+	book := &bookWriter{format: format, result: result}
+	for i := range chapters {
+		chapter := &chapters[i]
+		var blocks [][]exportBlock
+		for pageNum := chapter.StartPage; pageNum <= chapter.EndPage; pageNum++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, e.pageBlocks(numbering, pageNum, tables[pageNum], defaultStructure))
+		}
+		book.addChapter(chapter, blocks)
+		result.Chapters = append(result.Chapters, chapter.BookChapter)
+	}
+
+	if format == ExportFormatHTML && req.OutputDir != "" {
+		return result, book.saveHTML(req.OutputDir, stem, chapters)
+	}
+
+	data, err := book.archive(chapters, bookIdentifier(req.Path), fileModTime(req.Path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s book: %w", format, err)
+	}
+	result.MIMEType = zipMIMEType
+	if format == ExportFormatEPUB {
+		result.MIMEType = epubMIMEType
+	}
+	result.Size = len(data)
+
+	if req.OutputDir == "" {
+		result.Data = base64.StdEncoding.EncodeToString(data)
+		return result, nil
+	}
+
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+	result.OutputPath = filepath.Join(req.OutputDir, stem+"."+format)
+	if err := fsys.WriteFile(result.OutputPath, data, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save exported book: %w", err)
+	}
+
+	return result, nil
+}
+
+// documentTitle reads the document information title, falling back to the file name
+func documentTitle(r *pdf.Reader, fallback string) (title string) {
+	defer func() {
+		if recover() != nil {
+			title = fallback
+		}
+	}()
+
+	if title = strings.TrimSpace(r.Trailer().Key("Info").Key("Title").Text()); title == "" {
+		title = fallback
+	}
+	return title
+}
+
+// fileModTime returns when the file was last modified, or the zero time
+func fileModTime(path string) time.Time {
+	fileInfo, err := fsys.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return fileInfo.ModTime()
+}
+
+// bookIdentifier derives a stable identifier for the book from the file's contents
+func bookIdentifier(path string) string {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "urn:pdf:" + filepath.Base(path)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "urn:pdf:" + filepath.Base(path)
+	}
+	return "urn:sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// bookChapters splits the pages into chapters at each top-level outline entry, with a
+// front matter chapter for any pages before the first. Documents without an outline become
+// a single chapter.
+func bookChapters(outline []OutlineItem, totalPages int, format string) []bookChapter {
+	var starts []OutlineItem
+	for _, item := range outline {
+		if item.Page > 0 && item.Page <= totalPages {
+			starts = append(starts, item)
+		}
+	}
+
+	var chapters []bookChapter
+	if len(starts) == 0 || starts[0].Page > 1 {
+		end := totalPages
+		if len(starts) > 0 {
+			end = starts[0].Page - 1
+		}
+		chapters = append(chapters, bookChapter{BookChapter: BookChapter{Title: frontMatterTitle, StartPage: 1, EndPage: end}})
+		if len(starts) == 0 {
+			chapters[0].Title = ""
+		}
+	}
+	for i, item := range starts {
+		end := totalPages
+		if i+1 < len(starts) {
+			end = max(starts[i+1].Page-1, item.Page-1)
+		}
+		chapters = append(chapters, bookChapter{
+			BookChapter: BookChapter{Title: item.Title, StartPage: item.Page, EndPage: end},
+			children:    item.Children,
+		})
+	}
+
+	ext := "xhtml"
+	if format == ExportFormatHTML {
+		ext = "html"
+	}
+	for i := range chapters {
+		chapters[i].File = fmt.Sprintf("chapter-%03d.%s", i+1, ext)
+	}
+	return chapters
+}
+
+// bookWriter accumulates the files of a book as its chapters are rendered
+type bookWriter struct {
+	format string
+	result *PDFExportBookResult
+	files  []zipEntry // Chapters and images
+}
+
+const bookStylesheet = `body { font-family: serif; line-height: 1.5; margin: 1em auto; max-width: 40em; }
+section.page { margin-bottom: 1.5em; }
+table { border-collapse: collapse; }
+td { border: 1px solid #999; padding: 0.2em 0.5em; }
+p.image-placeholder { color: #666; font-style: italic; }
+img { max-width: 100%; }
+`
+
+// addChapter renders a chapter's pages of blocks into a chapter file, embedding the images
+// it can decode
+func (b *bookWriter) addChapter(chapter *bookChapter, pages [][]exportBlock) {
+	var body strings.Builder
+	title := chapter.Title
+	if title == "" {
+		title = b.result.Title
+	}
+	b.writeHeader(&body, title)
+	body.WriteString("<h1>")
+	writeEscaped(&body, title)
+	body.WriteString("</h1>\n")
+
+	for i, blocks := range pages {
+		pageNum := chapter.StartPage + i
+		fmt.Fprintf(&body, "<section class=\"page\" id=\"page-%d\">\n", pageNum)
+		imageIndex := 0
+		var list string // Element of the open list, if any
+		for _, block := range blocks {
+			if list != "" && block.kind != blockListItem {
+				fmt.Fprintf(&body, "</%s>\n", list)
+				list = ""
+			}
+			switch block.kind {
+			case blockHeading:
+				// h1 is the chapter title
+				fmt.Fprintf(&body, "<h%d>", block.level+1)
+				writeEscaped(&body, block.text)
+				fmt.Fprintf(&body, "</h%d>\n", block.level+1)
+			case blockListItem:
+				element := "ul"
+				if block.ordered {
+					element = "ol"
+				}
+				if list != element {
+					if list != "" {
+						fmt.Fprintf(&body, "</%s>\n", list)
+					}
+					fmt.Fprintf(&body, "<%s>\n", element)
+					list = element
+				}
+				writeHTMLElement(&body, "li", "", block.text)
+			case blockTable:
+				writeHTMLTable(&body, block.rows)
+			case blockImage:
+				imageIndex++
+				b.writeImage(&body, block, pageNum, imageIndex)
+			default:
+				writeHTMLElement(&body, "p", "", block.text)
+			}
+		}
+		if list != "" {
+			fmt.Fprintf(&body, "</%s>\n", list)
+		}
+		body.WriteString("</section>\n")
+	}
+	body.WriteString("</body>\n</html>\n")
+
+	b.files = append(b.files, zipEntry{name: chapter.File, data: body.String()})
+}
+
+// writeImage embeds a decodable image as PNG, or writes its placeholder text
+func (b *bookWriter) writeImage(body *strings.Builder, block exportBlock, pageNum, index int) {
+	data, err := decodeImagePNG(block.image)
+	if err != nil {
+		writeHTMLElement(body, "p", "image-placeholder", block.text)
+		b.result.ImagePlaceholders++
+		return
+	}
+
+	name := fmt.Sprintf("images/page-%d-%d.png", pageNum, index)
+	b.files = append(b.files, zipEntry{name: name, data: string(data)})
+	b.result.Images++
+	fmt.Fprintf(body, "<p><img src=\"%s\" alt=\"", name)
+	writeEscaped(body, strings.Trim(block.text, "[]"))
+	body.WriteString("\"/></p>\n")
+}
+
+// writeHeader opens an XHTML document, which EPUB requires and browsers accept as HTML
+func (b *bookWriter) writeHeader(body *strings.Builder, title string) {
+	if b.format == ExportFormatEPUB {
+		body.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	}
+	body.WriteString("<!DOCTYPE html>\n")
+	body.WriteString(`<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">` + "\n")
+	body.WriteString("<head>\n<meta charset=\"UTF-8\"/>\n<title>")
+	writeEscaped(body, title)
+	body.WriteString("</title>\n<link rel=\"stylesheet\" href=\"style.css\"/>\n</head>\n<body>\n")
+}
+
+// navigation renders the table of contents, linking nested outline entries to the page
+// anchors of their chapter
+func (b *bookWriter) navigation(chapters []bookChapter) string {
+	var body strings.Builder
+	b.writeHeader(&body, b.result.Title)
+	body.WriteString(`<nav epub:type="toc" id="toc">` + "\n<h1>")
+	writeEscaped(&body, b.result.Title)
+	body.WriteString("</h1>\n<ol>\n")
+	for _, chapter := range chapters {
+		title := chapter.Title
+		if title == "" {
+			title = b.result.Title
+		}
+		fmt.Fprintf(&body, "<li><a href=\"%s\">", chapter.File)
+		writeEscaped(&body, title)
+		body.WriteString("</a>")
+		writeNavItems(&body, chapter, chapter.children)
+		body.WriteString("</li>\n")
+	}
+	body.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+	return body.String()
+}
+
+// writeNavItems writes nested outline entries; entries without a page in the chapter link
+// to the chapter itself
+func writeNavItems(body *strings.Builder, chapter bookChapter, items []OutlineItem) {
+	if len(items) == 0 {
+		return
+	}
+	body.WriteString("\n<ol>\n")
+	for _, item := range items {
+		href := chapter.File
+		if item.Page >= chapter.StartPage && item.Page <= chapter.EndPage {
+			href += fmt.Sprintf("#page-%d", item.Page)
+		}
+		fmt.Fprintf(body, "<li><a href=\"%s\">", href)
+		writeEscaped(body, item.Title)
+		body.WriteString("</a>")
+		writeNavItems(body, chapter, item.Children)
+		body.WriteString("</li>\n")
+	}
+	body.WriteString("</ol>\n")
+}
+
+// archive packages the book as an EPUB or, for HTML, as a zip of the bundle
+func (b *bookWriter) archive(chapters []bookChapter, identifier string, modified time.Time) ([]byte, error) {
+	if b.format == ExportFormatHTML {
+		return writeZip(b.htmlEntries(chapters))
+	}
+
+	var manifest, spine strings.Builder
+	manifest.WriteString(`<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` +
+		`<item id="css" href="style.css" media-type="text/css"/>`)
+	for i, file := range b.files {
+		id := fmt.Sprintf("item-%d", i+1)
+		mediaType := "image/png"
+		if strings.HasSuffix(file.name, ".xhtml") {
+			mediaType = "application/xhtml+xml"
+			fmt.Fprintf(&spine, `<itemref idref="%s"/>`, id)
+		}
+		fmt.Fprintf(&manifest, `<item id="%s" href="%s" media-type="%s"/>`, id, file.name, mediaType)
+	}
+
+	var opf strings.Builder
+	opf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">` +
+		`<metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:identifier id="book-id">`)
+	writeEscaped(&opf, identifier)
+	opf.WriteString("</dc:identifier><dc:title>")
+	writeEscaped(&opf, b.result.Title)
+	// The language of the source is unknown
+	fmt.Fprintf(&opf, `</dc:title><dc:language>und</dc:language>`+
+		`<meta property="dcterms:modified">%s</meta></metadata>`, modified.UTC().Format("2006-01-02T15:04:05Z"))
+	fmt.Fprintf(&opf, "<manifest>%s</manifest><spine>%s</spine></package>", manifest.String(), spine.String())
+
+	entries := []zipEntry{
+		{name: "mimetype", data: epubMIMEType, stored: true},
+		{name: "META-INF/container.xml", data: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+			`<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container"><rootfiles>` +
+			`<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>` +
+			`</rootfiles></container>`},
+		{name: "OEBPS/content.opf", data: opf.String()},
+		{name: "OEBPS/nav.xhtml", data: b.navigation(chapters)},
+		{name: "OEBPS/style.css", data: bookStylesheet},
+	}
+	for _, file := range b.files {
+		entries = append(entries, zipEntry{name: "OEBPS/" + file.name, data: file.data})
+	}
+	return writeZip(entries)
+}
+
+// htmlEntries lists the files of an HTML bundle, with the table of contents as index.html
+func (b *bookWriter) htmlEntries(chapters []bookChapter) []zipEntry {
+	entries := []zipEntry{
+		{name: "index.html", data: b.navigation(chapters)},
+		{name: "style.css", data: bookStylesheet},
+	}
+	return append(entries, b.files...)
+}
+
+// saveHTML writes an HTML bundle into a directory named after the document
+func (b *bookWriter) saveHTML(outputDir, stem string, chapters []bookChapter) error {
+	dir := filepath.Join(outputDir, stem)
+	if err := fsys.MkdirAll(filepath.Join(dir, "images"), attachmentDirPerm); err != nil {
+		return fmt.Errorf("cannot create output directory %s: %w", dir, err)
+	}
+
+	for _, entry := range b.htmlEntries(chapters) {
+		if err := fsys.WriteFile(filepath.Join(dir, entry.name), []byte(entry.data), exportFilePerm); err != nil {
+			return fmt.Errorf("failed to save exported book: %w", err)
+		}
+		b.result.Size += len(entry.data)
+	}
+	b.result.MIMEType = "text/html"
+	b.result.OutputPath = filepath.Join(dir, "index.html")
+	return nil
+}
+
+// writeHTMLElement writes text inside an element with an optional class
+func writeHTMLElement(body *strings.Builder, element, class, text string) {
+	if class != "" {
+		fmt.Fprintf(body, "<%s class=\"%s\">", element, class)
+	} else {
+		fmt.Fprintf(body, "<%s>", element)
+	}
+	writeEscaped(body, text)
+	fmt.Fprintf(body, "</%s>\n", element)
+}
+
+// writeHTMLTable writes a table of text cells
+func writeHTMLTable(body *strings.Builder, rows [][]string) {
+	body.WriteString("<table>\n")
+	for _, row := range rows {
+		body.WriteString("<tr>")
+		for _, cell := range row {
+			body.WriteString("<td>")
+			writeEscaped(body, cell)
+			body.WriteString("</td>")
+		}
+		body.WriteString("</tr>\n")
+	}
+	body.WriteString("</table>\n")
+}
+
+// decodeImagePNG converts an 8-bit gray or RGB image XObject to PNG. The parser can only
+// decode Flate-compressed and uncompressed streams, so JPEG and other encodings fail.
+func decodeImagePNG(obj pdf.Value) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to decode image: %v", r)
+		}
+	}()
+
+	if filter := obj.Key("Filter"); filter.Kind() != pdf.Null && filter.Name() != "FlateDecode" {
+		return nil, fmt.Errorf("unsupported image filter: %v", filter)
+	}
+	if bpc := obj.Key("BitsPerComponent").Int64(); bpc != 8 {
+		return nil, fmt.Errorf("unsupported bits per component: %d", bpc)
+	}
+
+	width, height := int(obj.Key("Width").Int64()), int(obj.Key("Height").Int64())
+	if width <= 0 || height <= 0 || width*height > maxRenderPixels {
+		return nil, fmt.Errorf("unsupported image size: %dx%d", width, height)
+	}
+
+	var components int
+	switch obj.Key("ColorSpace").Name() {
+	case "DeviceGray":
+		components = 1
+	case "DeviceRGB":
+		components = 3
+	default:
+		return nil, fmt.Errorf("unsupported color space: %v", obj.Key("ColorSpace"))
+	}
+
+	rc := obj.Reader()
+	defer rc.Close()
+	samples := make([]byte, width*height*components)
+	if _, err := io.ReadFull(rc, samples); err != nil {
+		return nil, fmt.Errorf("failed to read image samples: %w", err)
+	}
+
+	var img image.Image
+	if components == 1 {
+		img = &image.Gray{Pix: samples, Stride: width, Rect: image.Rect(0, 0, width, height)}
+	} else {
+		rgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+		for i := 0; i < width*height; i++ {
+			copy(rgba.Pix[i*4:], samples[i*3:i*3+3])
+			rgba.Pix[i*4+3] = 0xff
+		}
+		img = rgba
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,141 @@
+package pdf
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bookPDFContent builds a three-page document: a cover, then a chapter whose second page
+// is bookmarked as a nested section. The chapter's first page holds a 2x2 RGB image.
+func bookPDFContent() string {
+	stream := func(content string) string {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content)
+	}
+	pixels := "\xff\x00\x00\x00\xff\x00\x00\x00\xff\xff\xff\xff"
+
+	return buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 10 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R 5 0 R 6 0 R] /Count 3 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents 7 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] " +
+			"/Resources << /Font << /F1 3 0 R >> /XObject << /Im1 12 0 R >> >> /Contents 8 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents 9 0 R >>",
+		stream("BT /F1 12 Tf 72 720 Td (Cover page) Tj ET"),
+		stream("BT /F1 12 Tf 72 720 Td (Opening text & notes) Tj ET q 20 0 0 20 72 600 cm /Im1 Do Q"),
+		stream("BT /F1 12 Tf 72 720 Td (More details) Tj ET"),
+		"<< /Type /Outlines /First 11 0 R /Last 11 0 R /Count 2 >>",
+		"<< /Title (Chapter One) /Parent 10 0 R /First 13 0 R /Last 13 0 R /Count 1 /Dest [5 0 R /Fit] >>",
+		"<< /Type /XObject /Subtype /Image /Width 2 /Height 2 /ColorSpace /DeviceRGB /BitsPerComponent 8 " +
+			"/Length 12 >>\nstream\n" + pixels + "\nendstream",
+		"<< /Title (Details) /Parent 11 0 R /Dest [6 0 R /Fit] >>",
+	})
+}
+
+// zipEntries reads every file of a zip package
+func zipEntries(t *testing.T, data []byte) ([]*zip.File, map[string]string) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("exported book is not a zip package: %v", err)
+	}
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		contents[f.Name] = unzipEntry(t, data, f.Name)
+	}
+	return zr.File, contents
+}
+
+func TestExporter_ExportBookEPUB(t *testing.T) {
+	exporter := NewExporter(100 * 1024 * 1024)
+	path := createTempFile(t, "guide.pdf", bookPDFContent())
+
+	result, err := exporter.ExportBook(context.Background(), PDFExportBookRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExportBook() unexpected error = %v", err)
+	}
+	if result.Format != ExportFormatEPUB || result.MIMEType != epubMIMEType || result.Title != "guide" {
+		t.Errorf("ExportBook() = %s %s titled %q, want epub titled guide", result.Format, result.MIMEType, result.Title)
+	}
+
+	wantChapters := []BookChapter{
+		{Title: frontMatterTitle, File: "chapter-001.xhtml", StartPage: 1, EndPage: 1},
+		{Title: "Chapter One", File: "chapter-002.xhtml", StartPage: 2, EndPage: 3},
+	}
+	if fmt.Sprint(result.Chapters) != fmt.Sprint(wantChapters) {
+		t.Errorf("ExportBook() chapters = %+v, want %+v", result.Chapters, wantChapters)
+	}
+	if result.Images != 1 || result.ImagePlaceholders != 0 {
+		t.Errorf("ExportBook() images = %d embedded, %d placeholders, want 1 and 0", result.Images, result.ImagePlaceholders)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, contents := zipEntries(t, data)
+	if files[0].Name != "mimetype" || files[0].Method != zip.Store {
+		t.Errorf("first entry = %s (method %d), want mimetype stored uncompressed", files[0].Name, files[0].Method)
+	}
+
+	chapter := contents["OEBPS/chapter-002.xhtml"]
+	for _, want := range []string{
+		"<h1>Chapter One</h1>",
+		`<section class="page" id="page-3">`,
+		"<p>Opening text &amp; notes</p>",
+		`<img src="images/page-2-1.png"`,
+	} {
+		if !strings.Contains(chapter, want) {
+			t.Errorf("chapter-002.xhtml missing %q", want)
+		}
+	}
+	if !strings.Contains(contents["OEBPS/nav.xhtml"], `<a href="chapter-002.xhtml#page-3">Details</a>`) {
+		t.Errorf("nav.xhtml does not link the nested section to its page:\n%s", contents["OEBPS/nav.xhtml"])
+	}
+	if !strings.HasPrefix(contents["OEBPS/images/page-2-1.png"], "\x89PNG") {
+		t.Error("embedded image is not a PNG")
+	}
+	if !strings.Contains(contents["OEBPS/content.opf"], `<itemref idref="item-1"/>`) {
+		t.Errorf("content.opf spine missing the first chapter:\n%s", contents["OEBPS/content.opf"])
+	}
+}
+
+func TestExporter_ExportBookHTML(t *testing.T) {
+	exporter := NewExporter(100 * 1024 * 1024)
+	path := createTempFile(t, "guide.pdf", bookPDFContent())
+	outputDir := t.TempDir()
+
+	result, err := exporter.ExportBook(context.Background(), PDFExportBookRequest{
+		Path: path, Format: "html", OutputDir: outputDir,
+	})
+	if err != nil {
+		t.Fatalf("ExportBook() unexpected error = %v", err)
+	}
+	if result.OutputPath != filepath.Join(outputDir, "guide", "index.html") || result.Data != "" {
+		t.Fatalf("ExportBook() output path = %q, want guide/index.html and no data", result.OutputPath)
+	}
+
+	for _, name := range []string{"index.html", "style.css", "chapter-001.html", "chapter-002.html", "images/page-2-1.png"} {
+		if _, err := os.Stat(filepath.Join(outputDir, "guide", name)); err != nil {
+			t.Errorf("HTML bundle missing %s: %v", name, err)
+		}
+	}
+	index, err := os.ReadFile(result.OutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `<a href="chapter-002.html">Chapter One</a>`) {
+		t.Errorf("index.html does not link the chapter:\n%s", index)
+	}
+
+	if _, err := exporter.ExportBook(context.Background(), PDFExportBookRequest{Path: path, Format: "mobi"}); err == nil {
+		t.Error("ExportBook() expected error for unsupported format")
+	}
+}
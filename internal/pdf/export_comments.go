@@ -0,0 +1,272 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Comment export formats
+const (
+	CommentsJSON     = "json"
+	CommentsMarkdown = "markdown"
+)
+
+// nonCommentTypes are the annotation subtypes that are not review comments: navigation,
+// form widgets, media, and the windows that show other annotations
+var nonCommentTypes = map[string]bool{
+	"Link":        true,
+	"Popup":       true,
+	"Widget":      true,
+	"Screen":      true,
+	"Movie":       true,
+	"Sound":       true,
+	"PrinterMark": true,
+	"TrapNet":     true,
+	"Watermark":   true,
+	"3D":          true,
+	"RichMedia":   true,
+}
+
+// ExportComments collects the review comments and markup of a document into threads, each
+// a comment with its chain of replies, sorted by date. Undated comments follow the dated
+// ones in page order. The threads are returned or saved as JSON or Markdown.
+func (e *Exporter) ExportComments(ctx context.Context, req PDFExportCommentsRequest) (*PDFExportCommentsResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	format := strings.ToLower(req.Format)
+	switch format {
+	case "":
+		format = CommentsJSON
+	case CommentsJSON, CommentsMarkdown:
+	default:
+		return nil, fmt.Errorf("invalid format: %s (must be %s or %s)", req.Format, CommentsJSON, CommentsMarkdown)
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := e.open(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	for _, pageNum := range req.Pages {
+		if pageNum < 1 || pageNum > doc.Reader.NumPage() {
+			return nil, pageOutOfRange(pageNum, doc.Reader.NumPage())
+		}
+	}
+
+	extracted, err := e.engine.Extract(ctx, extraction.ExtractionRequest{
+		FilePath: req.Path,
+		Config: extraction.ExtractionConfig{
+			Mode:               extraction.ModeStructured,
+			ExtractAnnotations: true,
+			Pages:              req.Pages,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations: %w", err)
+	}
+
+	result := &PDFExportCommentsResult{
+		Path:    req.Path,
+		Format:  format,
+		Threads: commentThreads(extracted.Elements),
+	}
+	authors := make(map[string]bool)
+	walkComments(result.Threads, func(comment *ReviewComment) {
+		result.Comments++
+		if comment.Author != "" && !authors[comment.Author] {
+			authors[comment.Author] = true
+			result.Authors = append(result.Authors, comment.Author)
+		}
+	})
+
+	var data []byte
+	if format == CommentsMarkdown {
+		data = []byte(writeCommentsMarkdown(filepath.Base(req.Path), result.Threads))
+	} else if data, err = json.MarshalIndent(result.Threads, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to encode comments: %w", err)
+	}
+
+	if req.OutputPath == "" {
+		result.Content = string(data)
+		return result, nil
+	}
+	if err := fsys.MkdirAll(filepath.Dir(req.OutputPath), attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", filepath.Dir(req.OutputPath), err)
+	}
+	if err := fsys.WriteFile(req.OutputPath, data, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save comments: %w", err)
+	}
+	result.OutputPath = req.OutputPath
+	return result, nil
+}
+
+// commentNode is a comment with the date it is sorted by and the replies to it
+type commentNode struct {
+	comment ReviewComment
+	date    time.Time
+	replyTo string
+	replies []*commentNode
+}
+
+// commentThreads turns annotation elements into comments and hangs each reply under the
+// comment it answers. Replies to annotations that are not comments start their own thread.
+func commentThreads(elements []extraction.ContentElement) []ReviewComment {
+	var nodes []*commentNode
+	byID := make(map[string]*commentNode)
+	for _, element := range elements {
+		annotation, ok := element.Content.(extraction.AnnotationElement)
+		if element.Type != extraction.ContentTypeAnnotation || !ok || nonCommentTypes[annotation.AnnotationType] {
+			continue
+		}
+		node := &commentNode{
+			comment: ReviewComment{
+				ID:         element.ID,
+				Type:       annotation.AnnotationType,
+				Page:       element.PageNumber,
+				PageLabel:  element.PageLabel,
+				Author:     annotation.Author,
+				Subject:    annotation.Subject,
+				Text:       annotation.Content,
+				QuotedText: annotation.MarkedText,
+			},
+			date:    annotation.ModifiedDate,
+			replyTo: annotation.InReplyTo,
+		}
+		if node.date.IsZero() {
+			node.date = annotation.CreationDate
+		}
+		if !node.date.IsZero() {
+			node.comment.Date = node.date.Format(time.RFC3339)
+		}
+		nodes = append(nodes, node)
+		byID[element.ID] = node
+	}
+
+	var roots []*commentNode
+	for _, node := range nodes {
+		if parent, ok := byID[node.replyTo]; ok && parent != node {
+			parent.replies = append(parent.replies, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	seen := make(map[*commentNode]bool)
+	threads := sortThreads(roots, seen)
+	// Comments replying to each other in a loop have no root; each loop starts a thread
+	for _, node := range nodes {
+		if !seen[node] {
+			threads = append(threads, sortThreads([]*commentNode{node}, seen)...)
+		}
+	}
+	return threads
+}
+
+// sortThreads copies comments with their replies into threads sorted by date. The sort is
+// stable, so comments without a date keep their page order after the dated ones. Each
+// comment is copied once.
+func sortThreads(nodes []*commentNode, seen map[*commentNode]bool) []ReviewComment {
+	slices.SortStableFunc(nodes, func(a, b *commentNode) int {
+		switch {
+		case a.date.IsZero() && b.date.IsZero():
+			return 0
+		case a.date.IsZero():
+			return 1
+		case b.date.IsZero():
+			return -1
+		default:
+			return a.date.Compare(b.date)
+		}
+	})
+	threads := make([]ReviewComment, 0, len(nodes))
+	for _, node := range nodes {
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		thread := node.comment
+		if len(node.replies) > 0 {
+			thread.Replies = sortThreads(node.replies, seen)
+		}
+		threads = append(threads, thread)
+	}
+	return threads
+}
+
+// walkComments calls fn for each comment and then for its replies
+func walkComments(comments []ReviewComment, fn func(comment *ReviewComment)) {
+	for i := range comments {
+		fn(&comments[i])
+		walkComments(comments[i].Replies, fn)
+	}
+}
+
+// writeCommentsMarkdown renders threads as a section per comment, with the text it marks
+// quoted and its replies as a nested list
+func writeCommentsMarkdown(title string, threads []ReviewComment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Comments: %s\n", title)
+	if len(threads) == 0 {
+		b.WriteString("\nNo comments.\n")
+	}
+	for _, thread := range threads {
+		page := fmt.Sprintf("Page %d", thread.Page)
+		if thread.PageLabel != "" && thread.PageLabel != strconv.Itoa(thread.Page) {
+			page += fmt.Sprintf(" (%s)", thread.PageLabel)
+		}
+		heading := []string{page, thread.Type}
+		if thread.Author != "" {
+			heading = append(heading, thread.Author)
+		}
+		if thread.Date != "" {
+			heading = append(heading, thread.Date)
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", strings.Join(heading, " · "))
+		if thread.QuotedText != "" {
+			fmt.Fprintf(&b, "> %s\n\n", thread.QuotedText)
+		}
+		if thread.Subject != "" {
+			fmt.Fprintf(&b, "**%s**\n\n", thread.Subject)
+		}
+		if thread.Text != "" {
+			fmt.Fprintf(&b, "%s\n\n", thread.Text)
+		}
+		writeRepliesMarkdown(&b, thread.Replies, 0)
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// writeRepliesMarkdown writes replies as list items, indenting replies to replies
+func writeRepliesMarkdown(b *strings.Builder, replies []ReviewComment, depth int) {
+	for _, reply := range replies {
+		author := reply.Author
+		if author == "" {
+			author = "Unknown"
+		}
+		fmt.Fprintf(b, "%s- **%s**", strings.Repeat("  ", depth), author)
+		if reply.Date != "" {
+			fmt.Fprintf(b, " (%s)", reply.Date)
+		}
+		text := strings.ReplaceAll(reply.Text, "\n", " ")
+		if text == "" {
+			text = reply.Type
+		}
+		fmt.Fprintf(b, ": %s\n", text)
+		writeRepliesMarkdown(b, reply.Replies, depth+1)
+	}
+	if depth == 0 && len(replies) > 0 {
+		b.WriteString("\n")
+	}
+}
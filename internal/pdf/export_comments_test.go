@@ -0,0 +1,96 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExporter_ExportComments(t *testing.T) {
+	content := "BT /F1 12 Tf 72 700 Td (Termination for convenience) Tj ET"
+	path := createTempFile(t, "contract.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> " +
+			"/Contents 5 0 R /Annots [6 0 R 7 0 R 8 0 R 9 0 R 10 0 R 11 0 R] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Annot /Subtype /Highlight /Rect [70 695 250 715] /Contents (Too broad) /T (Alice) " +
+			"/M (D:20240302090000Z) /Popup 7 0 R /QuadPoints [70 715 250 715 70 695 250 695] >>",
+		"<< /Type /Annot /Subtype /Popup /Rect [400 600 550 700] /Parent 6 0 R >>",
+		"<< /Type /Annot /Subtype /Text /Rect [20 700 40 720] /Contents (Limit it to 90 days) /T (Bob) " +
+			"/M (D:20240303100000Z) /IRT 6 0 R >>",
+		"<< /Type /Annot /Subtype /Text /Rect [20 650 40 670] /Contents (Agreed) /T (Alice) " +
+			"/M (D:20240304110000Z) /IRT 8 0 R >>",
+		"<< /Type /Annot /Subtype /Text /Rect [20 500 40 520] /Contents (Check the governing law) /T (Carol) " +
+			"/CreationDate (D:20240301080000Z) >>",
+		"<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /URI /URI (https://example.com) >> >>",
+	}))
+	exporter := NewExporter(100 * 1024 * 1024)
+
+	result, err := exporter.ExportComments(context.Background(), PDFExportCommentsRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExportComments() unexpected error = %v", err)
+	}
+	if result.Comments != 4 || len(result.Threads) != 2 {
+		t.Fatalf("ExportComments() = %d comments in %d threads, want 4 in 2", result.Comments, len(result.Threads))
+	}
+	if got := strings.Join(result.Authors, ","); got != "Carol,Alice,Bob" {
+		t.Errorf("authors = %s, want Carol,Alice,Bob", got)
+	}
+
+	// Threads are oldest first; replies nest under the comment they answer
+	first, second := result.Threads[0], result.Threads[1]
+	if first.Author != "Carol" || first.Date != "2024-03-01T08:00:00Z" || len(first.Replies) != 0 {
+		t.Errorf("first thread = %+v, want Carol's note dated by its creation", first)
+	}
+	if second.Type != "Highlight" || second.QuotedText != "Termination for convenience" {
+		t.Errorf("second thread = %+v, want the highlight quoting its text", second)
+	}
+	if len(second.Replies) != 1 || second.Replies[0].Author != "Bob" ||
+		len(second.Replies[0].Replies) != 1 || second.Replies[0].Replies[0].Text != "Agreed" {
+		t.Errorf("replies = %+v, want Bob answered by Alice", second.Replies)
+	}
+
+	var threads []ReviewComment
+	if err := json.Unmarshal([]byte(result.Content), &threads); err != nil || len(threads) != 2 {
+		t.Errorf("content = %s (%v), want the threads as JSON", result.Content, err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "comments", "contract.md")
+	markdown, err := exporter.ExportComments(context.Background(), PDFExportCommentsRequest{
+		Path:       path,
+		Format:     CommentsMarkdown,
+		OutputPath: outputPath,
+	})
+	if err != nil {
+		t.Fatalf("ExportComments(markdown) unexpected error = %v", err)
+	}
+	data, err := os.ReadFile(markdown.OutputPath)
+	if err != nil {
+		t.Fatalf("Failed to read exported comments: %v", err)
+	}
+	for _, want := range []string{
+		"### Page 1 · Highlight · Alice · 2024-03-02T09:00:00Z",
+		"> Termination for convenience",
+		"- **Bob** (2024-03-03T10:00:00Z): Limit it to 90 days",
+		"  - **Alice** (2024-03-04T11:00:00Z): Agreed",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("markdown missing %q:\n%s", want, data)
+		}
+	}
+
+	_, err = exporter.ExportComments(context.Background(), PDFExportCommentsRequest{Path: path, Format: "xml"})
+	if err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("ExportComments(xml) error = %v, want an invalid format error", err)
+	}
+	_, err = exporter.ExportComments(context.Background(), PDFExportCommentsRequest{Path: path, Pages: []int{2}})
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("ExportComments(page 2) error = %v, want an out of range error", err)
+	}
+}
@@ -0,0 +1,146 @@
+package pdf
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ` +
+	`ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ` +
+	`ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`</Types>`
+
+const docxPackageRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" ` +
+	`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" ` +
+	`Target="word/document.xml"/></Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" ` +
+	`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" ` +
+	`Target="styles.xml"/></Relationships>`
+
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/>` +
+	`<w:pPr><w:spacing w:after="120"/></w:pPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:keepNext/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:keepNext/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:sz w:val="28"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading3"><w:name w:val="heading 3"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:keepNext/><w:outlineLvl w:val="2"/></w:pPr><w:rPr><w:b/><w:sz w:val="24"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="ListParagraph"><w:name w:val="List Paragraph"/>` +
+	`<w:basedOn w:val="Normal"/><w:pPr><w:ind w:left="720"/></w:pPr></w:style>` +
+	`<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:tblPr><w:tblBorders>` +
+	`<w:top w:val="single" w:sz="4"/><w:left w:val="single" w:sz="4"/><w:bottom w:val="single" w:sz="4"/>` +
+	`<w:right w:val="single" w:sz="4"/><w:insideH w:val="single" w:sz="4"/><w:insideV w:val="single" w:sz="4"/>` +
+	`</w:tblBorders></w:tblPr></w:style></w:styles>`
+
+// writeDOCX packages blocks as a WordprocessingML document. List items keep their original
+// markers as text rather than relying on numbering definitions.
+func writeDOCX(blocks []exportBlock) ([]byte, error) {
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	body.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	for _, block := range blocks {
+		switch block.kind {
+		case blockHeading:
+			writeDOCXParagraph(&body, fmt.Sprintf("Heading%d", block.level), block.text)
+		case blockListItem:
+			writeDOCXParagraph(&body, "ListParagraph", block.text)
+		case blockTable:
+			writeDOCXTable(&body, block.rows)
+		default:
+			writeDOCXParagraph(&body, "", block.text)
+		}
+	}
+	body.WriteString(`<w:sectPr/></w:body></w:document>`)
+
+	return writeZip([]zipEntry{
+		{name: "[Content_Types].xml", data: docxContentTypes},
+		{name: "_rels/.rels", data: docxPackageRels},
+		{name: "word/_rels/document.xml.rels", data: docxDocumentRels},
+		{name: "word/styles.xml", data: docxStyles},
+		{name: "word/document.xml", data: body.String()},
+	})
+}
+
+// writeDOCXParagraph writes a paragraph with an optional style
+func writeDOCXParagraph(b *strings.Builder, style, text string) {
+	b.WriteString("<w:p>")
+	if style != "" {
+		fmt.Fprintf(b, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+	}
+	b.WriteString(`<w:r><w:t xml:space="preserve">`)
+	writeEscaped(b, text)
+	b.WriteString("</w:t></w:r></w:p>")
+}
+
+// writeDOCXTable writes a bordered table; every cell needs a paragraph, even when empty
+func writeDOCXTable(b *strings.Builder, rows [][]string) {
+	b.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="0" w:type="auto"/></w:tblPr>`)
+	b.WriteString("<w:tblGrid>")
+	if len(rows) > 0 {
+		b.WriteString(strings.Repeat("<w:gridCol/>", len(rows[0])))
+	}
+	b.WriteString("</w:tblGrid>")
+	for _, row := range rows {
+		b.WriteString("<w:tr>")
+		for _, cell := range row {
+			b.WriteString("<w:tc>")
+			writeDOCXParagraph(b, "", cell)
+			b.WriteString("</w:tc>")
+		}
+		b.WriteString("</w:tr>")
+	}
+	b.WriteString("</w:tbl>")
+	// Word requires a paragraph between a table and whatever follows it
+	b.WriteString("<w:p/>")
+}
+
+// zipEntry is one file of a zip-based document package
+type zipEntry struct {
+	name   string
+	data   string
+	stored bool // Written without compression
+}
+
+// writeZip packages entries in order
+func writeZip(entries []zipEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		method := zip.Deflate
+		if entry.stored {
+			method = zip.Store
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.name, Method: method})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(entry.data)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeEscaped writes text as XML character data; characters XML cannot carry are replaced
+func writeEscaped(b *strings.Builder, text string) {
+	// Writing to a strings.Builder cannot fail
+	_ = xml.EscapeText(b, []byte(text))
+}
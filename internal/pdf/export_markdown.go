@@ -0,0 +1,75 @@
+package pdf
+
+import (
+	"strings"
+)
+
+// markdownEscaper escapes characters that would end a Markdown table cell or start a line break
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// writeMarkdown formats blocks as Markdown. Bulleted list items get a dash and numbered ones
+// keep their number; other markers, such as letters, stay part of the item's text. The
+// first row of a table is its header.
+func writeMarkdown(blocks []exportBlock) string {
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			// Consecutive list items form one list
+			if block.kind == blockListItem && blocks[i-1].kind == blockListItem {
+				b.WriteByte('\n')
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+
+		switch block.kind {
+		case blockHeading:
+			b.WriteString(strings.Repeat("#", block.level) + " " + block.text)
+		case blockListItem:
+			b.WriteString(markdownListItem(block.text))
+		case blockTable:
+			writeMarkdownTable(&b, block.rows)
+		default:
+			b.WriteString(block.text)
+		}
+	}
+	return b.String()
+}
+
+// markdownListItem replaces a list item's bullet with a dash and writes its number the way
+// Markdown numbers list items
+func markdownListItem(text string) string {
+	marker := listItemPattern.FindStringSubmatch(text)
+	if marker == nil {
+		return "- " + text
+	}
+	rest := text[len(marker[0]):]
+	switch {
+	case marker[1] == "":
+		return "- " + rest
+	case marker[1][0] >= '0' && marker[1][0] <= '9':
+		return marker[1] + ". " + rest
+	default:
+		return "- " + text
+	}
+}
+
+// writeMarkdownTable writes rows as a Markdown table with the first row as its header
+func writeMarkdownTable(b *strings.Builder, rows [][]string) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return
+	}
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" " + markdownEscaper.Replace(strings.TrimSpace(cell)) + " |")
+		}
+	}
+
+	writeRow(rows[0])
+	b.WriteString("\n|" + strings.Repeat(" --- |", len(rows[0])))
+	for _, row := range rows[1:] {
+		b.WriteByte('\n')
+		writeRow(row)
+	}
+}
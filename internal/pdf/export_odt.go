@@ -0,0 +1,107 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+const odtManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">` +
+	`<manifest:file-entry manifest:full-path="/" manifest:version="1.2" ` +
+	`manifest:media-type="application/vnd.oasis.opendocument.text"/>` +
+	`<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>` +
+	`<manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>` +
+	`</manifest:manifest>`
+
+const odtStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+	`xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" ` +
+	`xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" office:version="1.2">` +
+	`<office:styles>` +
+	`<style:style style:name="Standard" style:family="paragraph"/>` +
+	`<style:style style:name="Heading_20_1" style:display-name="Heading 1" style:family="paragraph" ` +
+	`style:parent-style-name="Standard" style:default-outline-level="1">` +
+	`<style:text-properties fo:font-size="16pt" fo:font-weight="bold"/></style:style>` +
+	`<style:style style:name="Heading_20_2" style:display-name="Heading 2" style:family="paragraph" ` +
+	`style:parent-style-name="Standard" style:default-outline-level="2">` +
+	`<style:text-properties fo:font-size="14pt" fo:font-weight="bold"/></style:style>` +
+	`<style:style style:name="Heading_20_3" style:display-name="Heading 3" style:family="paragraph" ` +
+	`style:parent-style-name="Standard" style:default-outline-level="3">` +
+	`<style:text-properties fo:font-size="12pt" fo:font-weight="bold"/></style:style>` +
+	`</office:styles></office:document-styles>`
+
+// writeODT packages blocks as an OpenDocument text document. Consecutive list items share
+// one list and keep their original markers as text.
+func writeODT(blocks []exportBlock) ([]byte, error) {
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	body.WriteString(`<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+		`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+		`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" office:version="1.2">`)
+	body.WriteString("<office:body><office:text>")
+
+	inList := false
+	tables := 0
+	for _, block := range blocks {
+		if inList && block.kind != blockListItem {
+			body.WriteString("</text:list>")
+			inList = false
+		}
+		switch block.kind {
+		case blockHeading:
+			fmt.Fprintf(&body, `<text:h text:style-name="Heading_20_%d" text:outline-level="%d">`, block.level, block.level)
+			writeEscaped(&body, block.text)
+			body.WriteString("</text:h>")
+		case blockListItem:
+			if !inList {
+				body.WriteString("<text:list>")
+				inList = true
+			}
+			body.WriteString("<text:list-item>")
+			writeODTParagraph(&body, block.text)
+			body.WriteString("</text:list-item>")
+		case blockTable:
+			tables++
+			writeODTTable(&body, fmt.Sprintf("Table%d", tables), block.rows)
+		default:
+			writeODTParagraph(&body, block.text)
+		}
+	}
+	if inList {
+		body.WriteString("</text:list>")
+	}
+	body.WriteString("</office:text></office:body></office:document-content>")
+
+	// The mimetype entry must come first and be stored uncompressed
+	return writeZip([]zipEntry{
+		{name: "mimetype", data: odtMIMEType, stored: true},
+		{name: "META-INF/manifest.xml", data: odtManifest},
+		{name: "styles.xml", data: odtStyles},
+		{name: "content.xml", data: body.String()},
+	})
+}
+
+// writeODTParagraph writes a plain paragraph
+func writeODTParagraph(b *strings.Builder, text string) {
+	b.WriteString("<text:p>")
+	writeEscaped(b, text)
+	b.WriteString("</text:p>")
+}
+
+// writeODTTable writes a table with one column definition per cell of the first row
+func writeODTTable(b *strings.Builder, name string, rows [][]string) {
+	fmt.Fprintf(b, `<table:table table:name="%s">`, name)
+	if len(rows) > 0 {
+		fmt.Fprintf(b, `<table:table-column table:number-columns-repeated="%d"/>`, len(rows[0]))
+	}
+	for _, row := range rows {
+		b.WriteString("<table:table-row>")
+		for _, cell := range row {
+			b.WriteString(`<table:table-cell office:value-type="string">`)
+			writeODTParagraph(b, cell)
+			b.WriteString("</table:table-cell>")
+		}
+		b.WriteString("</table:table-row>")
+	}
+	b.WriteString("</table:table>")
+}
@@ -0,0 +1,283 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Table export formats of pdf_extract_tables
+const (
+	TableExportCSV  = "csv"  // One CSV file per table
+	TableExportXLSX = "xlsx" // One workbook with a sheet per table
+)
+
+// maxSheetNameLength is the longest worksheet name spreadsheet applications accept
+const maxSheetNameLength = 31
+
+// Cell styles of exported workbooks, as indexes into xlsxStyles' cell formats
+const (
+	xlsxStyleHeader   = 1
+	xlsxStyleDate     = 2
+	xlsxStylePercent  = 3
+	xlsxStyleCurrency = 4
+)
+
+// xlsxEpoch is day zero of spreadsheet date serial numbers
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+const xlsxContentTypesHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ` +
+	`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ` +
+	`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+
+const xlsxPackageRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" ` +
+	`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" ` +
+	`Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font>` +
+	`<font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill>` +
+	`<fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="10" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+	`</styleSheet>`
+
+// validTableExport checks the export options of a table extraction, inferring the format
+// from the output path's extension when none is given
+func validTableExport(format, outputPath string) (string, error) {
+	if format == "" && outputPath == "" {
+		return "", nil
+	}
+	if outputPath == "" {
+		return "", fmt.Errorf("export_format requires output_path")
+	}
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(outputPath)), ".")
+	}
+	if format != TableExportCSV && format != TableExportXLSX {
+		return "", fmt.Errorf("invalid export_format: %q (must be %s or %s)", format, TableExportCSV, TableExportXLSX)
+	}
+	return format, nil
+}
+
+// exportTables writes tables to outputPath. A single CSV table is written to the path
+// itself; several are numbered in order, as name-1.csv, name-2.csv, and so on. Header rows
+// keep their text, while data cells are written as their normalized values.
+func exportTables(tables []TableElement, format, outputPath string) (*TableExport, error) {
+	export := &TableExport{Format: format, Tables: len(tables), Files: []string{}}
+	if len(tables) == 0 {
+		return export, nil
+	}
+	if err := fsys.MkdirAll(filepath.Dir(outputPath), attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", filepath.Dir(outputPath), err)
+	}
+
+	if format == TableExportXLSX {
+		data, err := writeXLSX(tables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write workbook: %w", err)
+		}
+		if err := fsys.WriteFile(outputPath, data, exportFilePerm); err != nil {
+			return nil, fmt.Errorf("failed to save workbook: %w", err)
+		}
+		export.Files = append(export.Files, outputPath)
+		return export, nil
+	}
+
+	ext := filepath.Ext(outputPath)
+	stem := strings.TrimSuffix(outputPath, ext)
+	for i, table := range tables {
+		path := outputPath
+		if len(tables) > 1 {
+			path = fmt.Sprintf("%s-%d%s", stem, i+1, ext)
+		}
+		data, err := writeTableCSV(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write table %d: %w", i+1, err)
+		}
+		if err := fsys.WriteFile(path, data, exportFilePerm); err != nil {
+			return nil, fmt.Errorf("failed to save table %d: %w", i+1, err)
+		}
+		export.Files = append(export.Files, path)
+	}
+	return export, nil
+}
+
+// exportedCellValue returns the text written for a cell
+func exportedCellValue(row TableRow, cell TableCell) string {
+	if row.IsHeader || cell.Value == "" {
+		return strings.TrimSpace(cell.Content)
+	}
+	return cell.Value
+}
+
+// writeTableCSV writes a table as CSV, one record per row
+func writeTableCSV(table TableElement) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, row := range table.Rows {
+		record := make([]string, len(table.Columns))
+		for _, cell := range row.Cells {
+			if cell.ColIndex >= 0 && cell.ColIndex < len(record) {
+				record[cell.ColIndex] = exportedCellValue(row, cell)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// writeXLSX packages tables as a SpreadsheetML workbook with one sheet per table, named
+// after its page. Numbers, amounts, percentages, and dates are stored as numbers with a
+// matching format, and header rows are bold.
+func writeXLSX(tables []TableElement) ([]byte, error) {
+	var contentTypes, workbook, rels strings.Builder
+	contentTypes.WriteString(xlsxContentTypesHead)
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	workbook.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	rels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	sheets := make([]zipEntry, 0, len(tables))
+	perPage := make(map[int]int)
+	for i, table := range tables {
+		n := i + 1
+		perPage[table.PageNumber]++
+		name := fmt.Sprintf("Page %d Table %d", table.PageNumber, perPage[table.PageNumber])
+		if len(name) > maxSheetNameLength {
+			name = fmt.Sprintf("Table %d", n)
+		}
+
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, name, n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+			`Target="worksheets/sheet%d.xml"/>`, n, n)
+		sheets = append(sheets, zipEntry{name: fmt.Sprintf("xl/worksheets/sheet%d.xml", n), data: writeXLSXSheet(table)})
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" `+
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" `+
+		`Target="styles.xml"/></Relationships>`, len(tables)+1)
+
+	return writeZip(append([]zipEntry{
+		{name: "[Content_Types].xml", data: contentTypes.String()},
+		{name: "_rels/.rels", data: xlsxPackageRels},
+		{name: "xl/workbook.xml", data: workbook.String()},
+		{name: "xl/_rels/workbook.xml.rels", data: rels.String()},
+		{name: "xl/styles.xml", data: xlsxStyles},
+	}, sheets...))
+}
+
+// writeXLSXSheet writes the worksheet of one table
+func writeXLSXSheet(table TableElement) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range table.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for _, cell := range row.Cells {
+			if strings.TrimSpace(cell.Content) == "" {
+				continue
+			}
+			ref := xlsxColumnName(cell.ColIndex) + strconv.Itoa(r+1)
+			if number, style, ok := xlsxNumber(row, cell); ok {
+				fmt.Fprintf(&b, `<c r="%s"`, ref)
+				if style != 0 {
+					fmt.Fprintf(&b, ` s="%d"`, style)
+				}
+				fmt.Fprintf(&b, `><v>%s</v></c>`, number)
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"`, ref)
+			if row.IsHeader {
+				fmt.Fprintf(&b, ` s="%d"`, xlsxStyleHeader)
+			}
+			b.WriteString(`><is><t xml:space="preserve">`)
+			writeEscaped(&b, exportedCellValue(row, cell))
+			b.WriteString(`</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+
+	// Merged cells cover the grid positions they span
+	var merges []string
+	for r, row := range table.Rows {
+		for _, cell := range row.Cells {
+			if cell.RowSpan > 1 || cell.ColSpan > 1 {
+				merges = append(merges, xlsxColumnName(cell.ColIndex)+strconv.Itoa(r+1)+":"+
+					xlsxColumnName(cell.ColIndex+max(cell.ColSpan, 1)-1)+strconv.Itoa(r+max(cell.RowSpan, 1)))
+			}
+		}
+	}
+	if len(merges) > 0 {
+		fmt.Fprintf(&b, `<mergeCells count="%d">`, len(merges))
+		for _, ref := range merges {
+			fmt.Fprintf(&b, `<mergeCell ref="%s"/>`, ref)
+		}
+		b.WriteString(`</mergeCells>`)
+	}
+	b.WriteString(`</worksheet>`)
+	return b.String()
+}
+
+// xlsxNumber returns the stored number and style of a data cell holding a number, amount,
+// percentage, or date
+func xlsxNumber(row TableRow, cell TableCell) (string, int, bool) {
+	if row.IsHeader || cell.Value == "" {
+		return "", 0, false
+	}
+	switch cell.DataType {
+	case extraction.CellTypeNumber:
+		return cell.Value, 0, true
+	case extraction.CellTypeCurrency:
+		return cell.Value, xlsxStyleCurrency, true
+	case extraction.CellTypePercent:
+		return cell.Value, xlsxStylePercent, true
+	case extraction.CellTypeDate:
+		date, err := time.Parse("2006-01-02", cell.Value)
+		if err != nil || date.Before(xlsxEpoch) {
+			return "", 0, false
+		}
+		return strconv.Itoa(int(date.Sub(xlsxEpoch).Hours() / 24)), xlsxStyleDate, true
+	}
+	return "", 0, false
+}
+
+// xlsxColumnName returns the letters naming a zero-based column: A to Z, then AA, AB, ...
+func xlsxColumnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pricedTableContent is a ruled table of items, prices, and due dates
+func pricedTableContent() string {
+	var b strings.Builder
+	b.WriteString("1 w\n")
+	for _, y := range []int{700, 680, 660, 640} {
+		fmt.Fprintf(&b, "100 %d m 400 %d l S\n", y, y)
+	}
+	for _, x := range []int{100, 200, 300, 400} {
+		fmt.Fprintf(&b, "%d 640 m %d 700 l S\n", x, x)
+	}
+	cells := [][]string{{"Item", "Price", "Due"}, {"Apple", "$1,200.50", "2024-03-01"}, {"Pear", "(15)", "03/15/2024"}}
+	for r, row := range cells {
+		for c, word := range row {
+			fmt.Fprintf(&b, "BT /F1 10 Tf %d %d Td (%s) Tj ET\n", 105+c*100, 686-r*20, word)
+		}
+	}
+	return b.String()
+}
+
+func TestService_ExtractTablesExport(t *testing.T) {
+	service := NewService(10 * 1024 * 1024)
+	path := createTempFile(t, "prices.pdf", buildTestPDF(pricedTableContent(), pricedTableContent()))
+	dir := t.TempDir()
+
+	extract := func(format, outputPath string) (*PDFExtractResult, error) {
+		return service.ExtractTables(context.Background(), PDFExtractTablesRequest{
+			Path:         path,
+			Config:       ExtractionConfig{ExtractText: true, ExtractTables: true},
+			ExportFormat: format,
+			OutputPath:   outputPath,
+		})
+	}
+
+	result, err := extract("", filepath.Join(dir, "prices.csv"))
+	if err != nil {
+		t.Fatalf("ExtractTables() unexpected error = %v", err)
+	}
+	if len(result.Tables) != 2 {
+		t.Fatalf("ExtractTables() found %d tables, want 2", len(result.Tables))
+	}
+	columns := result.Tables[0].Columns
+	if columns[0].DataType != "text" || columns[1].DataType != "number" || columns[2].DataType != "date" {
+		t.Errorf("ExtractTables() column types = %+v, want text, number, date", columns)
+	}
+
+	// Tables are numbered when there are several, and data cells hold normalized values
+	want := []string{filepath.Join(dir, "prices-1.csv"), filepath.Join(dir, "prices-2.csv")}
+	if result.Export == nil || result.Export.Format != TableExportCSV || fmt.Sprint(result.Export.Files) != fmt.Sprint(want) {
+		t.Fatalf("ExtractTables() export = %+v, want CSV files %v", result.Export, want)
+	}
+	data, err := os.ReadFile(want[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if csv := string(data); csv != "Item,Price,Due\nApple,1200.5,2024-03-01\nPear,-15,2024-03-15\n" {
+		t.Errorf("exported CSV = %q", csv)
+	}
+
+	result, err = extract(TableExportXLSX, filepath.Join(dir, "out", "prices.xlsx"))
+	if err != nil {
+		t.Fatalf("ExtractTables() unexpected error = %v", err)
+	}
+	workbook, err := zip.OpenReader(result.Export.Files[0])
+	if err != nil {
+		t.Fatalf("exported workbook does not open: %v", err)
+	}
+	defer workbook.Close()
+	parts := map[string]string{}
+	for _, file := range workbook.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[file.Name] = string(content)
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Page 2 Table 1" sheetId="2"`) {
+		t.Errorf("workbook sheets = %s, want one sheet per table named by page", parts["xl/workbook.xml"])
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, cell := range []string{
+		`<c r="A1" t="inlineStr" s="1"><is><t xml:space="preserve">Item</t></is></c>`,
+		`<c r="B2" s="4"><v>1200.5</v></c>`,
+		`<c r="C3" s="2"><v>45366</v></c>`,
+	} {
+		if !strings.Contains(sheet, cell) {
+			t.Errorf("worksheet %s, want cell %s", sheet, cell)
+		}
+	}
+
+	if _, err := extract("json", filepath.Join(dir, "prices.json")); err == nil ||
+		!strings.Contains(err.Error(), "invalid export_format") {
+		t.Errorf("ExtractTables() with an unknown format error = %v, want it rejected", err)
+	}
+	if _, err := extract(TableExportCSV, ""); err == nil || !strings.Contains(err.Error(), "requires output_path") {
+		t.Errorf("ExtractTables() without output_path error = %v, want it rejected", err)
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumnName(index); got != want {
+			t.Errorf("xlsxColumnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
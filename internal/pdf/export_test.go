@@ -0,0 +1,155 @@
+package pdf
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// exportTestContent is a page with a heading, a two-line paragraph, a bulleted list, and a
+// ruled table
+func exportTestContent() string {
+	return "BT /F1 20 Tf 72 775 Td (Quarterly Report) Tj ET\n" +
+		"BT /F1 10 Tf 72 750 Td (Sales grew in every region this quarter) Tj ET\n" +
+		"BT /F1 10 Tf 72 738 Td (and inventory stayed flat.) Tj ET\n" +
+		"BT /F1 10 Tf 72 722 Td (- First point) Tj ET\n" +
+		"BT /F1 10 Tf 72 710 Td (- Second point) Tj ET\n" +
+		ruledTableContent()
+}
+
+// unzipEntry reads one file from a zip package
+func unzipEntry(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("exported document is not a zip package: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	t.Fatalf("exported document has no %s", name)
+	return ""
+}
+
+func TestExporter_ExportDocument(t *testing.T) {
+	exporter := NewExporter(100 * 1024 * 1024)
+	path := createTempFile(t, "report.pdf", buildTestPDF(exportTestContent()))
+
+	result, err := exporter.ExportDocument(context.Background(), PDFExportDocumentRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExportDocument() unexpected error = %v", err)
+	}
+	if result.Format != ExportFormatDOCX || result.Headings != 1 || result.Paragraphs != 1 ||
+		result.ListItems != 2 || result.Tables != 1 {
+		t.Errorf("ExportDocument() = %s with %d headings, %d paragraphs, %d list items, %d tables, "+
+			"want docx with 1, 1, 2, 1", result.Format, result.Headings, result.Paragraphs, result.ListItems, result.Tables)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil || len(data) != result.Size {
+		t.Fatalf("ExportDocument() data decodes to %d bytes (%v), want %d", len(data), err, result.Size)
+	}
+	document := unzipEntry(t, data, "word/document.xml")
+	for _, want := range []string{
+		`<w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t xml:space="preserve">Quarterly Report`,
+		"Sales grew in every region this quarter and inventory stayed flat.",
+		`<w:pStyle w:val="ListParagraph"/></w:pPr><w:r><w:t xml:space="preserve">- Second point`,
+		`<w:tc><w:p><w:r><w:t xml:space="preserve">Apple</w:t>`,
+	} {
+		if !strings.Contains(document, want) {
+			t.Errorf("document.xml missing %q", want)
+		}
+	}
+	// Table cells must not be repeated as paragraphs
+	if strings.Count(document, ">Apple<") != 1 {
+		t.Errorf("document.xml has Apple %d times, want once inside the table", strings.Count(document, ">Apple<"))
+	}
+}
+
+func TestExporter_ExportDocument_StructureConfig(t *testing.T) {
+	exporter := NewExporter(100 * 1024 * 1024)
+	path := createTempFile(t, "report.pdf", buildTestPDF(exportTestContent()))
+
+	form, err := NewStructureConfig(StructurePresetForm)
+	if err != nil {
+		t.Fatalf("NewStructureConfig() unexpected error = %v", err)
+	}
+	result, err := exporter.ExportDocument(context.Background(), PDFExportDocumentRequest{Path: path, StructureConfig: &form})
+	if err != nil {
+		t.Fatalf("ExportDocument() unexpected error = %v", err)
+	}
+	// Forms keep every line on its own and have no lists
+	if result.Headings != 1 || result.Paragraphs != 4 || result.ListItems != 0 || result.Structure.Preset != StructurePresetForm {
+		t.Errorf("ExportDocument() = %d headings, %d paragraphs, %d list items with preset %q, "+
+			"want 1, 4, 0 with the form preset", result.Headings, result.Paragraphs, result.ListItems, result.Structure.Preset)
+	}
+
+	form.ListPatterns = []string{"(unclosed"}
+	if _, err := exporter.ExportDocument(context.Background(), PDFExportDocumentRequest{
+		Path: path, StructureConfig: &form,
+	}); err == nil || !strings.Contains(err.Error(), "invalid list pattern") {
+		t.Errorf("ExportDocument() error = %v, want the invalid list pattern rejected", err)
+	}
+}
+
+func TestExporter_ExportODT(t *testing.T) {
+	exporter := NewExporter(100 * 1024 * 1024)
+	path := createTempFile(t, "report.pdf", buildTestPDF(exportTestContent()))
+	outputDir := filepath.Join(t.TempDir(), "exports")
+
+	result, err := exporter.ExportDocument(context.Background(), PDFExportDocumentRequest{
+		Path: path, Format: "ODT", OutputDir: outputDir,
+	})
+	if err != nil {
+		t.Fatalf("ExportDocument() unexpected error = %v", err)
+	}
+	if result.Data != "" || result.OutputPath != filepath.Join(outputDir, "report.odt") {
+		t.Fatalf("ExportDocument() output path = %q with %d data bytes, want report.odt and no data",
+			result.OutputPath, len(result.Data))
+	}
+
+	data, err := os.ReadFile(result.OutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := zr.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("first entry = %s (method %d), want mimetype stored uncompressed", first.Name, first.Method)
+	}
+
+	content := unzipEntry(t, data, "content.xml")
+	for _, want := range []string{
+		`<text:h text:style-name="Heading_20_1" text:outline-level="1">Quarterly Report</text:h>`,
+		"<text:list><text:list-item><text:p>- First point</text:p></text:list-item><text:list-item>",
+		`<table:table-column table:number-columns-repeated="2"/>`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content.xml missing %q", want)
+		}
+	}
+
+	if _, err := exporter.ExportDocument(context.Background(), PDFExportDocumentRequest{Path: path, Format: "rtf"}); err == nil {
+		t.Error("ExportDocument() expected error for unsupported format")
+	}
+}
@@ -0,0 +1,347 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Text export formats
+const (
+	TextFormatPlain    = "text"
+	TextFormatMarkdown = "markdown"
+	TextFormatJSONL    = "jsonl"
+)
+
+// textWriters serialize the blocks of each page into each text export format
+var textWriters = map[string]struct {
+	mimeType  string
+	extension string
+	write     func(pages []textExportPage) (string, error)
+}{
+	TextFormatPlain:    {"text/plain", "txt", writePlainTextPages},
+	TextFormatMarkdown: {"text/markdown", "md", writeMarkdownPages},
+	TextFormatJSONL:    {"application/jsonl", "jsonl", writeJSONLPages},
+}
+
+// Running header and footer constants
+const (
+	// runningLineDepth is how many lines at the top and at the bottom of a page may be a
+	// running header or footer
+	runningLineDepth = 2
+	// runningLineShare is the share of the exported pages a line must appear on to be a
+	// running header or footer
+	runningLineShare = 0.5
+)
+
+// digitRun matches the page numbers and dates that change between running headers
+var digitRun = regexp.MustCompile(`\d+`)
+
+// textExportPage is the structure of one exported page
+type textExportPage struct {
+	number int
+	label  string
+	blocks []exportBlock
+}
+
+// ExportText exports a document's text as plain text, Markdown, or JSON Lines with one
+// element per line, optionally without running headers and footers and with words broken
+// across lines joined again. The text is returned or saved to the output directory.
+func (e *Exporter) ExportText(ctx context.Context, req PDFExportTextRequest) (*PDFExportTextResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = TextFormatPlain
+	}
+	writer, ok := textWriters[format]
+	if !ok {
+		return nil, fmt.Errorf("invalid format: %s (must be %s, %s, or %s)",
+			req.Format, TextFormatPlain, TextFormatMarkdown, TextFormatJSONL)
+	}
+
+	structure := defaultStructure
+	if req.StructureConfig != nil {
+		if err := req.StructureConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid structure_config: %w", err)
+		}
+		structure, _ = newStructureDetector(*req.StructureConfig)
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := e.open(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	numbering := extraction.NewPageNumbering(r)
+	pages := req.Pages
+	if len(pages) == 0 {
+		for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+			pages = append(pages, pageNum)
+		}
+	}
+	for _, pageNum := range pages {
+		if pageNum < 1 || pageNum > numbering.Count() {
+			return nil, pageOutOfRange(pageNum, numbering.Count())
+		}
+	}
+
+	tables, err := detectTables(ctx, e.engine, req.Path, req.Pages)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PDFExportTextResult{
+		Path:     req.Path,
+		Format:   format,
+		MIMEType: writer.mimeType,
+		Pages:    len(pages),
+	}
+
+	lines := make([][]pageLine, len(pages))
+	bodySizes := make([]float64, len(pages))
+	for i, pageNum := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		lines[i], bodySizes[i], err = readPageLines(numbering, pageNum)
+		if err != nil {
+			result.FailedPages = append(result.FailedPages, pageNum)
+		}
+	}
+
+	if req.StripHeadersFooters {
+		running := findRunningLines(lines)
+		reported := make(map[string]bool)
+		for i := range lines {
+			var removed []string
+			lines[i], removed = stripRunningLines(lines[i], running)
+			result.LinesRemoved += len(removed)
+			for _, text := range removed {
+				if key := runningLineKey(text); !reported[key] {
+					reported[key] = true
+					result.HeadersFooters = append(result.HeadersFooters, text)
+				}
+			}
+		}
+	}
+
+	exported := make([]textExportPage, len(pages))
+	for i, pageNum := range pages {
+		if req.Dehyphenate {
+			var joined int
+			lines[i], joined = dehyphenateLines(lines[i])
+			result.Dehyphenated += joined
+		}
+		exported[i] = textExportPage{
+			number: pageNum,
+			label:  numbering.Label(pageNum),
+			blocks: structure.textBlocks(lines[i], bodySizes[i], tables[pageNum]),
+		}
+		result.Elements += len(exported[i].blocks)
+	}
+
+	content, err := writer.write(exported)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", format, err)
+	}
+	result.Size = len(content)
+
+	if req.OutputDir == "" {
+		result.Content = content
+		return result, nil
+	}
+
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+	stem := strings.TrimSuffix(filepath.Base(req.Path), filepath.Ext(req.Path))
+	result.OutputPath = filepath.Join(req.OutputDir, stem+"."+writer.extension)
+	if err := fsys.WriteFile(result.OutputPath, []byte(content), exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save exported text: %w", err)
+	}
+
+	return result, nil
+}
+
+// readPageLines reads the lines of a page from the top down
+func readPageLines(numbering *extraction.PageNumbering, pageNum int) (lines []pageLine, bodySize float64, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			lines, bodySize, err = nil, 0, fmt.Errorf("failed to read page %d: %v", pageNum, rec)
+		}
+	}()
+
+	lines, bodySize = pageTopLines(numbering.Page(pageNum), math.MaxInt)
+	return lines, bodySize, nil
+}
+
+// runningLineKey normalizes a line so running headers and footers that differ only in
+// their numbers, such as "Page 3 of 12", match
+func runningLineKey(text string) string {
+	return strings.ToLower(digitRun.ReplaceAllString(strings.Join(strings.Fields(text), " "), "#"))
+}
+
+// edgeLines returns the indexes of the lines at the top and at the bottom of a page
+func edgeLines(count int) []int {
+	var indexes []int
+	for i := 0; i < count; i++ {
+		if i < runningLineDepth || i >= count-runningLineDepth {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// findRunningLines returns the keys of the lines found at the top or bottom of at least
+// half of the pages, and of at least two
+func findRunningLines(pages [][]pageLine) map[string]bool {
+	counts := make(map[string]int)
+	for _, lines := range pages {
+		seen := make(map[string]bool)
+		for _, i := range edgeLines(len(lines)) {
+			key := runningLineKey(lines[i].Text)
+			if key != "" && !seen[key] {
+				seen[key] = true
+				counts[key]++
+			}
+		}
+	}
+
+	minPages := max(2, int(math.Ceil(float64(len(pages))*runningLineShare)))
+	running := make(map[string]bool)
+	for key, count := range counts {
+		if count >= minPages {
+			running[key] = true
+		}
+	}
+	return running
+}
+
+// stripRunningLines removes running headers and footers from the top and bottom of a page
+func stripRunningLines(lines []pageLine, running map[string]bool) (kept []pageLine, removed []string) {
+	edges := make(map[int]bool)
+	for _, i := range edgeLines(len(lines)) {
+		edges[i] = true
+	}
+	for i, line := range lines {
+		if edges[i] && running[runningLineKey(line.Text)] {
+			removed = append(removed, line.Text)
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept, removed
+}
+
+// dehyphenateLines joins words broken with a hyphen at the end of a line, moving the rest of
+// the word up from the next line
+func dehyphenateLines(lines []pageLine) ([]pageLine, int) {
+	joined := 0
+	for i := 0; i+1 < len(lines); i++ {
+		var ok bool
+		lines[i].Text, lines[i+1].Text, ok = extraction.JoinHyphenated(lines[i].Text, lines[i+1].Text)
+		if ok {
+			lines[i+1].Words--
+			joined++
+		}
+	}
+
+	kept := lines[:0]
+	for _, line := range lines {
+		if line.Text != "" {
+			kept = append(kept, line)
+		}
+	}
+	return kept, joined
+}
+
+// writeMarkdownPages formats the pages as one Markdown document
+func writeMarkdownPages(pages []textExportPage) (string, error) {
+	var blocks []exportBlock
+	for _, page := range pages {
+		blocks = append(blocks, page.blocks...)
+	}
+	return writeMarkdown(blocks) + "\n", nil
+}
+
+// writePlainTextPages formats the pages as plain text with a blank line between blocks and
+// table cells separated by tabs
+func writePlainTextPages(pages []textExportPage) (string, error) {
+	var b strings.Builder
+	var previous *exportBlock
+	for _, page := range pages {
+		for i := range page.blocks {
+			block := &page.blocks[i]
+			if previous != nil {
+				if block.kind == blockListItem && previous.kind == blockListItem {
+					b.WriteByte('\n')
+				} else {
+					b.WriteString("\n\n")
+				}
+			}
+			previous = block
+
+			if block.kind == blockTable {
+				b.WriteString(writePlainTable(block.rows))
+			} else {
+				b.WriteString(block.text)
+			}
+		}
+	}
+	if previous != nil {
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// writeJSONLPages formats the pages as JSON Lines with one element per line
+func writeJSONLPages(pages []textExportPage) (string, error) {
+	var b strings.Builder
+	for _, page := range pages {
+		for _, block := range page.blocks {
+			element := TextExportElement{
+				Page:        page.number,
+				PageLabel:   page.label,
+				Type:        block.kind,
+				Level:       block.level,
+				Text:        block.text,
+				Rows:        block.rows,
+				BoundingBox: block.bounds,
+			}
+			if block.kind == blockTable {
+				element.Text = writePlainTable(block.rows)
+			}
+			line, err := json.Marshal(element)
+			if err != nil {
+				return "", err
+			}
+			b.Write(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), nil
+}
+
+// writePlainTable joins table rows with line breaks and cells with tabs
+func writePlainTable(rows [][]string) string {
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = strings.Join(row, "\t")
+	}
+	return strings.Join(lines, "\n")
+}
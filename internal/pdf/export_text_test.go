@@ -0,0 +1,93 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// exportTextPage is a page with a running header, a heading, a paragraph with a hyphenated
+// line break, and a page number footer
+func exportTextPage(number, heading, word string) string {
+	return "BT /F1 9 Tf 72 770 Td (Annual Report 2024) Tj ET\n" +
+		"BT /F1 16 Tf 72 740 Td (" + heading + ") Tj ET\n" +
+		"BT /F1 10 Tf 72 710 Td (Revenue grew thanks to the new " + word + "-) Tj ET\n" +
+		"BT /F1 10 Tf 72 698 Td (bution centers opened this year.) Tj ET\n" +
+		"BT /F1 10 Tf 72 686 Td (Costs in " + heading + " stayed within the budget.) Tj ET\n" +
+		"BT /F1 9 Tf 300 40 Td (Page " + number + ") Tj ET"
+}
+
+func TestExporter_ExportText(t *testing.T) {
+	exporter := NewExporter(100 * 1024 * 1024)
+	path := createTempFile(t, "annual.pdf", buildTestPDF(
+		exportTextPage("1", "Europe", "distri"),
+		exportTextPage("2", "Asia", "contri"),
+	))
+
+	result, err := exporter.ExportText(context.Background(), PDFExportTextRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExportText() unexpected error = %v", err)
+	}
+	if result.Format != TextFormatPlain || !strings.Contains(result.Content, "Annual Report 2024") ||
+		!strings.Contains(result.Content, "distri- bution") {
+		t.Errorf("ExportText() = %+v, want the plain text as extracted", result)
+	}
+
+	result, err = exporter.ExportText(context.Background(), PDFExportTextRequest{
+		Path: path, StripHeadersFooters: true, Dehyphenate: true,
+	})
+	if err != nil {
+		t.Fatalf("ExportText() unexpected error = %v", err)
+	}
+	if strings.Contains(result.Content, "Annual Report") || strings.Contains(result.Content, "Page") {
+		t.Errorf("ExportText() content = %q, want headers and footers removed", result.Content)
+	}
+	if !strings.Contains(result.Content, "new distribution centers") || !strings.Contains(result.Content, "Asia") {
+		t.Errorf("ExportText() content = %q, want the broken word joined and the body kept", result.Content)
+	}
+	if result.LinesRemoved != 4 || len(result.HeadersFooters) != 2 || result.Dehyphenated != 2 {
+		t.Errorf("ExportText() = %+v, want 4 lines removed as 2 running lines and 2 words joined", result)
+	}
+
+	// JSON Lines carry the page and position of every element
+	outputDir := filepath.Join(t.TempDir(), "text")
+	result, err = exporter.ExportText(context.Background(), PDFExportTextRequest{
+		Path: path, Format: "JSONL", Pages: []int{2}, StripHeadersFooters: true, OutputDir: outputDir,
+	})
+	if err != nil {
+		t.Fatalf("ExportText() unexpected error = %v", err)
+	}
+	if result.Content != "" || result.OutputPath != filepath.Join(outputDir, "annual.jsonl") {
+		t.Fatalf("ExportText() = %+v, want the text saved to annual.jsonl", result)
+	}
+	data, err := os.ReadFile(result.OutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	// A single page has nothing to compare against, so its header and footer stay
+	if len(lines) != 4 || result.LinesRemoved != 0 {
+		t.Fatalf("JSONL = %q with %d lines removed, want 4 elements and none removed", data, result.LinesRemoved)
+	}
+	var element TextExportElement
+	if err := json.Unmarshal([]byte(lines[2]), &element); err != nil {
+		t.Fatalf("JSONL line %q: %v", lines[2], err)
+	}
+	if element.Page != 2 || element.Type != blockParagraph || element.BoundingBox.X != 72 ||
+		element.BoundingBox.Y > 686 || element.BoundingBox.Y+element.BoundingBox.Height < 710 {
+		t.Errorf("element = %+v, want the paragraph of page 2 spanning its three lines", element)
+	}
+
+	for _, req := range []PDFExportTextRequest{
+		{},
+		{Path: path, Format: "html"},
+		{Path: path, Pages: []int{3}},
+	} {
+		if _, err := exporter.ExportText(context.Background(), req); err == nil {
+			t.Errorf("ExportText(%+v) expected an error", req)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package extraction
+
+import (
+	"sort"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Triggers of document, page, and annotation actions, beside the field triggers
+const (
+	TriggerOpen        = "open"         // OpenAction and named document scripts, run as the document opens
+	TriggerClose       = "close"        // WC: before the document closes
+	TriggerSave        = "save"         // WS, DS: before and after the document is saved
+	TriggerPrint       = "print"        // WP, DP: before and after the document is printed
+	TriggerPageOpen    = "page_open"    // O of a page, PO of an annotation: the page opens
+	TriggerPageClose   = "page_close"   // C of a page, PC of an annotation: the page closes
+	TriggerPageVisible = "page_visible" // PV, PI: the annotation's page comes into or out of view
+	TriggerActivate    = "activate"     // A: the annotation is clicked
+	TriggerMouse       = "mouse"        // E, X, D, U: the pointer enters, leaves, presses, or releases
+	TriggerFocus       = "focus"        // Fo, Bl: the field gains or loses focus
+)
+
+// Where actions are attached
+const (
+	ActionSourceDocument   = "document"
+	ActionSourcePage       = "page"
+	ActionSourceAnnotation = "annotation"
+	ActionSourceField      = "field"
+)
+
+// Keys of the /AA additional-actions dictionaries of the catalog, pages, and annotations
+// and fields, with their triggers
+var (
+	documentTriggers = map[string]string{
+		"WC": TriggerClose, "WS": TriggerSave, "DS": TriggerSave, "WP": TriggerPrint, "DP": TriggerPrint,
+	}
+	pageTriggers       = map[string]string{"O": TriggerPageOpen, "C": TriggerPageClose}
+	annotationTriggers = map[string]string{
+		"E": TriggerMouse, "X": TriggerMouse, "D": TriggerMouse, "U": TriggerMouse,
+		"Fo": TriggerFocus, "Bl": TriggerFocus,
+		"PO": TriggerPageOpen, "PC": TriggerPageClose, "PV": TriggerPageVisible, "PI": TriggerPageVisible,
+		"K": TriggerKeystroke, "F": TriggerFormat, "V": TriggerValidate, "C": TriggerCalculate,
+	}
+)
+
+// automaticTriggers are the triggers that fire without the user doing anything with the
+// document beyond opening, closing, saving, or printing it and turning its pages
+var automaticTriggers = map[string]bool{
+	TriggerOpen: true, TriggerClose: true, TriggerSave: true, TriggerPrint: true,
+	TriggerPageOpen: true, TriggerPageClose: true, TriggerPageVisible: true,
+}
+
+// Action is an action a document performs, with where it is attached and what runs it
+type Action struct {
+	Type      string // The action's /S: JavaScript, Launch, URI, SubmitForm, ...
+	Trigger   string // One of the Trigger constants
+	Source    string // One of the ActionSource constants
+	Name      string // Script name, qualified field name, or annotation subtype
+	Page      int    // Page of page and annotation actions
+	Automatic bool   // Runs without the user acting on the document's content
+	Script    string // Source of JavaScript actions
+	Target    string // URI of URI actions, file or address of Launch, GoToR, GoToE, SubmitForm, and ImportData
+}
+
+// DocumentActions returns the actions a document performs: its open action and named
+// scripts, and the actions of the document, its pages, their annotations, and its form
+// fields, each followed by the actions chained to it with /Next. Fields are read from the
+// form's field tree as well as from the pages' widgets, once each.
+func DocumentActions(r *pdf.Reader, numbering *PageNumbering) (actions []Action) {
+	c := &actionCollector{seen: make(map[ObjectRef]bool)}
+
+	// The parser panics on malformed objects; actions found so far are kept
+	defer func() {
+		if recover() != nil {
+			actions = c.actions
+		}
+	}()
+
+	root := r.Trailer().Key("Root")
+	c.add(root.Key("OpenAction"), Action{Trigger: TriggerOpen, Source: ActionSourceDocument})
+	c.additional(root.Key("AA"), documentTriggers, Action{Source: ActionSourceDocument})
+	WalkNameTree(root.Key("Names").Key("JavaScript"), func(name string, action pdf.Value) {
+		c.add(action, Action{Trigger: TriggerOpen, Source: ActionSourceDocument, Name: name})
+	})
+
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		page := numbering.Page(pageNum).V
+		c.additional(page.Key("AA"), pageTriggers, Action{Source: ActionSourcePage, Page: pageNum})
+
+		annots := page.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			annot := annots.Index(i)
+			base := Action{Source: ActionSourceAnnotation, Name: annot.Key("Subtype").Name(), Page: pageNum}
+			if base.Name == "Widget" {
+				base.Source, base.Name = ActionSourceField, fieldName(annot)
+			}
+			c.node(annot, base)
+		}
+	}
+
+	var walk func(node pdf.Value, depth int)
+	walk = func(node pdf.Value, depth int) {
+		if node.Kind() != pdf.Dict || depth > maxFieldDepth {
+			return
+		}
+		c.node(node, Action{Source: ActionSourceField, Name: fieldName(node)})
+		kids := node.Key("Kids")
+		for i := 0; i < kids.Len(); i++ {
+			walk(kids.Index(i), depth+1)
+		}
+	}
+	fields := root.Key("AcroForm").Key("Fields")
+	for i := 0; i < fields.Len(); i++ {
+		walk(fields.Index(i), 0)
+	}
+	return c.actions
+}
+
+// actionCollector gathers actions, reading each annotation and field once
+type actionCollector struct {
+	seen    map[ObjectRef]bool
+	actions []Action
+}
+
+// node adds the activation and additional actions of an annotation or field it has not read
+func (c *actionCollector) node(node pdf.Value, base Action) {
+	if ref := RefOf(node); ref.ID != 0 {
+		if c.seen[ref] {
+			return
+		}
+		c.seen[ref] = true
+	}
+	activate := base
+	activate.Trigger = TriggerActivate
+	c.add(node.Key("A"), activate)
+	c.additional(node.Key("AA"), annotationTriggers, base)
+}
+
+// additional adds the actions of an /AA dictionary, in key order
+func (c *actionCollector) additional(aa pdf.Value, triggers map[string]string, base Action) {
+	if aa.Kind() != pdf.Dict {
+		return
+	}
+	keys := aa.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if trigger, ok := triggers[key]; ok {
+			action := base
+			action.Trigger = trigger
+			c.add(aa.Key(key), action)
+		}
+	}
+}
+
+// add adds an action and the actions chained to it by /Next, up to maxActionChain of them.
+// Values that are not action dictionaries, such as an /OpenAction destination, are ignored.
+func (c *actionCollector) add(action pdf.Value, base Action) {
+	queue := []pdf.Value{action}
+	for n := 0; len(queue) > 0 && n < maxActionChain; n++ {
+		action, queue = queue[0], queue[1:]
+		if action.Kind() != pdf.Dict || action.Key("S").Kind() != pdf.Name {
+			continue
+		}
+
+		a := base
+		a.Type = action.Key("S").Name()
+		a.Automatic = automaticTriggers[a.Trigger]
+		switch a.Type {
+		case "JavaScript":
+			a.Script = readScript(action.Key("JS"))
+		case "URI":
+			a.Target = action.Key("URI").RawString()
+		case "Launch":
+			a.Target = FileSpecPath(action.Key("F"))
+			if a.Target == "" {
+				a.Target = action.Key("Win").Key("F").Text()
+			}
+		case "GoToR", "GoToE", "SubmitForm", "ImportData":
+			a.Target = FileSpecPath(action.Key("F"))
+		}
+		c.actions = append(c.actions, a)
+
+		next := action.Key("Next")
+		if next.Kind() == pdf.Array {
+			for i := 0; i < next.Len(); i++ {
+				queue = append(queue, next.Index(i))
+			}
+		} else {
+			queue = append(queue, next)
+		}
+	}
+}
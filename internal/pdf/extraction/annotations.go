@@ -0,0 +1,115 @@
+package extraction
+
+import (
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// textMarkupTypes are the annotation subtypes marking up text through QuadPoints
+var textMarkupTypes = map[string]bool{
+	"Highlight": true,
+	"Underline": true,
+	"StrikeOut": true,
+	"Squiggly":  true,
+}
+
+// parseAnnotation reads the entries every annotation may have, its author, dates, popup,
+// and the annotation it replies to, and those of its subtype: the quadrilaterals of text
+// markup, the strokes of ink, and the icon name of notes and stamps. Popups and replies
+// are named by the IDs of their elements in ids; those on other pages are left out.
+func parseAnnotation(annot pdf.Value, ids map[ObjectRef]string) AnnotationElement {
+	subtype := annot.Key("Subtype").Name()
+	content := AnnotationElement{
+		AnnotationType: subtype,
+		Content:        annot.Key("Contents").Text(),
+		Author:         annot.Key("T").Text(),
+		Subject:        annot.Key("Subj").Text(),
+	}
+	if date, ok := ParsePDFDate(annot.Key("CreationDate").Text()); ok {
+		content.CreationDate = date
+	}
+	if date, ok := ParsePDFDate(annot.Key("M").Text()); ok {
+		content.ModifiedDate = date
+	}
+
+	if popup := annot.Key("Popup"); !popup.IsNull() {
+		content.Popup = ids[RefOf(popup)]
+	}
+	if irt := annot.Key("IRT"); !irt.IsNull() {
+		if content.InReplyTo = ids[RefOf(irt)]; content.InReplyTo != "" {
+			content.ReplyType = "reply"
+			if annot.Key("RT").Name() == "Group" {
+				content.ReplyType = "group"
+			}
+		}
+	}
+
+	switch {
+	case textMarkupTypes[subtype]:
+		content.QuadPoints = quadPoints(annot.Key("QuadPoints"))
+	case subtype == "Ink":
+		content.InkList = inkList(annot.Key("InkList"))
+	case subtype == "Stamp" || subtype == "Text" || subtype == "FileAttachment":
+		content.Name = annot.Key("Name").Name()
+	}
+	return content
+}
+
+// quadPoints reads a QuadPoints array, eight numbers per quadrilateral, into its corners
+func quadPoints(v pdf.Value) [][]Coordinate {
+	if v.Kind() != pdf.Array {
+		return nil
+	}
+	var quads [][]Coordinate
+	for i := 0; i+8 <= v.Len(); i += 8 {
+		quad := make([]Coordinate, 4)
+		for j := range quad {
+			quad[j] = Coordinate{X: v.Index(i + 2*j).Float64(), Y: v.Index(i + 2*j + 1).Float64()}
+		}
+		quads = append(quads, quad)
+	}
+	return quads
+}
+
+// inkList reads an InkList array of strokes, each a flat array of coordinates
+func inkList(v pdf.Value) [][]Coordinate {
+	if v.Kind() != pdf.Array {
+		return nil
+	}
+	var strokes [][]Coordinate
+	for i := 0; i < v.Len(); i++ {
+		path := v.Index(i)
+		if path.Kind() != pdf.Array {
+			continue
+		}
+		stroke := make([]Coordinate, 0, path.Len()/2)
+		for j := 0; j+2 <= path.Len(); j += 2 {
+			stroke = append(stroke, Coordinate{X: path.Index(j).Float64(), Y: path.Index(j + 1).Float64()})
+		}
+		if len(stroke) > 0 {
+			strokes = append(strokes, stroke)
+		}
+	}
+	return strokes
+}
+
+// markedText returns the words whose center lies inside the quadrilaterals of a text
+// markup annotation, quadrilateral by quadrilateral in reading order
+func markedText(words []WordElement, quads [][]Coordinate) string {
+	var marked []string
+	taken := make([]bool, len(words))
+	for _, quad := range quads {
+		box := boxFromPoints(quad[0], quad[0])
+		for _, corner := range quad[1:] {
+			box = unionBoxes(box, boxFromPoints(corner, corner))
+		}
+		for i, word := range words {
+			if !taken[i] && boxContains(box, boxCenter(word.BoundingBox)) {
+				taken[i] = true
+				marked = append(marked, word.Text)
+			}
+		}
+	}
+	return strings.Join(marked, " ")
+}
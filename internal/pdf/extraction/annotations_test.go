@@ -0,0 +1,81 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExtract_AnnotationTypes(t *testing.T) {
+	content := "BT /F1 12 Tf 72 700 Td (Quarterly revenue grew) Tj ET"
+	path := writeRawPDF(t, "reviewed.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> " +
+			"/Contents 5 0 R /Annots [6 0 R 7 0 R 8 0 R 9 0 R 10 0 R] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Annot /Subtype /Highlight /Rect [70 695 165 715] /Contents (Check this) /T (Alice) " +
+			"/M (D:20240131120000Z) /Popup 7 0 R /QuadPoints [70 715 165 715 70 695 165 695] >>",
+		"<< /Type /Annot /Subtype /Popup /Rect [400 600 550 700] /Parent 6 0 R >>",
+		"<< /Type /Annot /Subtype /Text /Rect [20 700 40 720] /Contents (Agreed) /T (Bob) /Name /Comment " +
+			"/IRT 6 0 R >>",
+		"<< /Type /Annot /Subtype /Ink /Rect [100 100 200 200] /InkList [[100 100 150 150 200 120] [110 190]] >>",
+		"<< /Type /Annot /Subtype /Stamp /Rect [300 300 400 340] /Name /Approved /RT /Group /IRT 99 0 R >>",
+	})
+
+	result, err := NewEngine().Extract(context.Background(), ExtractionRequest{
+		FilePath: path,
+		Config:   ExtractionConfig{Mode: ModeStructured, ExtractAnnotations: true},
+	})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	annotations := make(map[string]ContentElement)
+	for _, element := range result.Elements {
+		if element.Type == ContentTypeAnnotation {
+			annotations[element.Content.(AnnotationElement).AnnotationType] = element
+		}
+	}
+	if len(annotations) != 5 {
+		t.Fatalf("Extract() annotations = %+v, want 5", annotations)
+	}
+
+	highlight := annotations["Highlight"].Content.(AnnotationElement)
+	wantQuad := [][]Coordinate{{{X: 70, Y: 715}, {X: 165, Y: 715}, {X: 70, Y: 695}, {X: 165, Y: 695}}}
+	if !reflect.DeepEqual(highlight.QuadPoints, wantQuad) {
+		t.Errorf("highlight quad points = %v, want %v", highlight.QuadPoints, wantQuad)
+	}
+	if highlight.MarkedText != "Quarterly revenue" {
+		t.Errorf("highlight marked text = %q, want %q", highlight.MarkedText, "Quarterly revenue")
+	}
+	if highlight.Author != "Alice" || !highlight.ModifiedDate.Equal(time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("highlight author %q, modified %v; want Alice at 2024-01-31 12:00 UTC",
+			highlight.Author, highlight.ModifiedDate)
+	}
+
+	popup := annotations["Popup"]
+	if highlight.Popup != popup.ID || popup.Parent == nil || *popup.Parent != annotations["Highlight"].ID {
+		t.Errorf("highlight popup %q, popup %s parent %v; want them linked", highlight.Popup, popup.ID, popup.Parent)
+	}
+
+	reply := annotations["Text"].Content.(AnnotationElement)
+	if reply.InReplyTo != annotations["Highlight"].ID || reply.ReplyType != "reply" || reply.Name != "Comment" {
+		t.Errorf("reply = %+v, want a Comment replying to the highlight", reply)
+	}
+
+	ink := annotations["Ink"].Content.(AnnotationElement)
+	wantInk := [][]Coordinate{{{X: 100, Y: 100}, {X: 150, Y: 150}, {X: 200, Y: 120}}, {{X: 110, Y: 190}}}
+	if !reflect.DeepEqual(ink.InkList, wantInk) {
+		t.Errorf("ink list = %v, want %v", ink.InkList, wantInk)
+	}
+
+	// Replies to annotations that are not on the page are left unlinked
+	stamp := annotations["Stamp"].Content.(AnnotationElement)
+	if stamp.Name != "Approved" || stamp.InReplyTo != "" || stamp.ReplyType != "" {
+		t.Errorf("stamp = %+v, want the Approved icon and no reply", stamp)
+	}
+}
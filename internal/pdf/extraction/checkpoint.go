@@ -0,0 +1,187 @@
+package extraction
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Checkpoint file permissions
+const (
+	checkpointDirPerm  = 0o750
+	checkpointFilePerm = 0o600
+)
+
+// checkpointTokenBytes is the length of the hash used as a resume token
+const checkpointTokenBytes = 16
+
+func init() {
+	// Element content and properties are interfaces; gob needs their concrete types
+	for _, value := range []any{
+		TextElement{}, WordElement{}, LineElement{}, ImageElement{}, VectorElement{}, FormElement{},
+		AnnotationElement{}, TableElement{}, StructuralElement{}, TextProperties{},
+	} {
+		gob.Register(value)
+	}
+}
+
+// SetCheckpointDir enables checkpointing: every extracted page is saved under dir so that a
+// cancelled or crashed extraction can be resumed with the token reported in its partial
+// result. An empty dir disables checkpointing.
+func (e *DefaultEngine) SetCheckpointDir(dir string) {
+	e.checkpointDir = dir
+}
+
+// checkpoint stores the finished pages of one extraction run
+type checkpoint struct {
+	token string
+	dir   string
+}
+
+// checkpointPage is the saved form of a page outcome
+type checkpointPage struct {
+	Elements []ContentElement
+	Tables   []TableElement
+	Scratch  ExtractionResult
+	Timing   PageTiming
+}
+
+// openCheckpoint prepares the checkpoint of a request and, when the request resumes an
+// earlier run, loads the pages it finished. It returns nil when checkpointing is disabled.
+func (e *DefaultEngine) openCheckpoint(req ExtractionRequest, pages []int) (*checkpoint, map[int]pageOutcome, error) {
+	if e.checkpointDir == "" {
+		if req.ResumeToken != "" {
+			return nil, nil, fmt.Errorf("cannot resume: checkpointing is disabled on this server")
+		}
+		return nil, nil, nil
+	}
+
+	token, err := checkpointToken(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	cp := &checkpoint{token: token, dir: filepath.Join(e.checkpointDir, token)}
+	if req.ResumeToken == "" {
+		return cp, nil, nil
+	}
+	if req.ResumeToken != token {
+		return nil, nil, fmt.Errorf("cannot resume: token does not match %s with this configuration; "+
+			"the file or the extraction settings have changed", req.FilePath)
+	}
+
+	resumed := make(map[int]pageOutcome)
+	for _, pageNum := range pages {
+		if outcome, ok := cp.load(pageNum); ok {
+			resumed[pageNum] = outcome
+		}
+	}
+	return cp, resumed, nil
+}
+
+// checkpointToken identifies a file version and the settings that shape per-page results;
+// worker count and query filters do not change what is saved
+func checkpointToken(req ExtractionRequest) (string, error) {
+	path, err := filepath.Abs(req.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve path: %w", err)
+	}
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot access file: %w", err)
+	}
+
+	config := req.Config
+	config.MaxWorkers = 0
+	settings, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode configuration: %w", err)
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%d\x00%d\x00", path, info.Size(), info.ModTime().UnixNano())
+	hash.Write(settings)
+	return hex.EncodeToString(hash.Sum(nil)[:checkpointTokenBytes]), nil
+}
+
+// pagePath returns where a page is saved
+func (c *checkpoint) pagePath(pageNum int) string {
+	return filepath.Join(c.dir, "page-"+strconv.Itoa(pageNum)+".gob")
+}
+
+// save writes a finished page. The file is renamed into place so that a crash never leaves
+// a half-written page behind. Pages that cannot be encoded are simply not checkpointed.
+func (c *checkpoint) save(outcome pageOutcome) error {
+	data, err := encodePage(outcome)
+	if err != nil {
+		return err
+	}
+
+	if err := fsys.MkdirAll(c.dir, checkpointDirPerm); err != nil {
+		return fmt.Errorf("cannot create checkpoint directory: %w", err)
+	}
+	tmp, err := fsys.WriteTemp(c.dir, "page-*.tmp", data, checkpointFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	defer fsys.Remove(tmp)
+	return fsys.Rename(tmp, c.pagePath(outcome.timing.Page))
+}
+
+// load reads a saved page; unreadable pages are extracted again
+func (c *checkpoint) load(pageNum int) (pageOutcome, bool) {
+	data, err := fsys.ReadFile(c.pagePath(pageNum))
+	if err != nil {
+		return pageOutcome{}, false
+	}
+	outcome, err := decodePage(data)
+	if err != nil {
+		return pageOutcome{}, false
+	}
+	return outcome, true
+}
+
+// encodePage encodes a page outcome in its saved form
+func encodePage(outcome pageOutcome) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(checkpointPage{
+		Elements: outcome.elements,
+		Tables:   outcome.tables,
+		Scratch:  outcome.scratch,
+		Timing:   outcome.timing,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode page %d: %w", outcome.timing.Page, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodePage decodes a page outcome saved by encodePage
+func decodePage(data []byte) (pageOutcome, error) {
+	var saved checkpointPage
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&saved); err != nil {
+		return pageOutcome{}, err
+	}
+	return pageOutcome{
+		elements: saved.Elements,
+		tables:   saved.Tables,
+		scratch:  saved.Scratch,
+		timing:   saved.Timing,
+	}, nil
+}
+
+// remove deletes the checkpoint once its extraction has finished
+func (c *checkpoint) remove() error {
+	if err := fsys.RemoveAll(c.dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package extraction
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+)
+
+func TestExtract_ResumesFromCheckpoint(t *testing.T) {
+	const pages = 6
+	path := writePagesPDF(t, pages)
+	dir := t.TempDir()
+
+	engine := NewEngine()
+	engine.SetCheckpointDir(dir)
+	req := ExtractionRequest{
+		FilePath: path,
+		Config:   ExtractionConfig{Mode: ModeStructured, ExtractText: true, MaxWorkers: 2},
+	}
+
+	// Simulate an interrupted run that finished the first half of the document
+	cp, _, err := engine.openCheckpoint(req, nil)
+	if err != nil {
+		t.Fatalf("openCheckpoint() unexpected error = %v", err)
+	}
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	numbering := NewPageNumbering(doc.Reader)
+	done, wait := engine.extractPages(context.Background(), numbering, []int{1, 2, 3}, req.Config,
+		NewLinkResolver(doc.Reader, numbering), cp, nil)
+	doc.Close()
+	if len(done) != 3 || wait != nil {
+		t.Fatalf("extractPages() = %d outcomes, want 3", len(done))
+	}
+
+	req.ResumeToken = cp.token
+	_, resumed, err := engine.openCheckpoint(req, engine.determinePagesToProcess(nil, pages))
+	if err != nil {
+		t.Fatalf("openCheckpoint() resume unexpected error = %v", err)
+	}
+	if len(resumed) != 3 {
+		t.Fatalf("openCheckpoint() resumed %d pages, want 3", len(resumed))
+	}
+
+	result, err := engine.Extract(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	if result.Partial || result.ResumeToken != "" {
+		t.Errorf("Extract() partial = %v, resume token = %q, want a complete result", result.Partial, result.ResumeToken)
+	}
+	timings := result.ExtractionInfo.ProcessingStats.PageTimings
+	if len(timings) != pages {
+		t.Fatalf("Extract() merged %d pages, want %d", len(timings), pages)
+	}
+	for i, timing := range timings {
+		if timing.Page != i+1 {
+			t.Errorf("page %d merged at position %d", timing.Page, i)
+		}
+	}
+	var text strings.Builder
+	for _, element := range result.Elements {
+		if te, ok := element.Content.(TextElement); ok {
+			text.WriteString(te.Text)
+		}
+	}
+	for _, want := range []string{"Page 1", "Page 4", "Page 6"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Extract() text missing %q", want)
+		}
+	}
+
+	// A completed run cleans up its checkpoint
+	if _, err := os.Stat(filepath.Join(dir, cp.token)); !os.IsNotExist(err) {
+		t.Errorf("checkpoint directory still present after completion: %v", err)
+	}
+}
+
+func TestExtract_RejectsUnknownResumeToken(t *testing.T) {
+	path := writePagesPDF(t, 1)
+	req := ExtractionRequest{
+		FilePath:    path,
+		Config:      ExtractionConfig{Mode: ModeRaw, ExtractText: true},
+		ResumeToken: "0123456789abcdef",
+	}
+
+	if _, err := NewEngine().Extract(context.Background(), req); err == nil ||
+		!strings.Contains(err.Error(), "checkpointing is disabled") {
+		t.Errorf("Extract() without checkpoint dir error = %v, want checkpointing disabled", err)
+	}
+
+	engine := NewEngine()
+	engine.SetCheckpointDir(t.TempDir())
+	if _, err := engine.Extract(context.Background(), req); err == nil ||
+		!strings.Contains(err.Error(), "token does not match") {
+		t.Errorf("Extract() with stale token error = %v, want token mismatch", err)
+	}
+}
@@ -0,0 +1,320 @@
+package extraction
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// maxCMapRange bounds the codes a single bfrange may map, guarding against ranges that
+// would otherwise be expanded into huge lookups
+const maxCMapRange = 1 << 16
+
+// codeRange is a range of character codes of one length, bounded byte by byte as
+// codespace ranges are
+type codeRange struct {
+	lo, hi string
+}
+
+// contains reports whether a code falls in the range
+func (r codeRange) contains(code string) bool {
+	if len(code) != len(r.lo) {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < r.lo[i] || code[i] > r.hi[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// unicodeRange maps consecutive codes to Unicode, either by incrementing the text of the
+// first code or from a list with one entry per code
+type unicodeRange struct {
+	lo, hi string
+	first  string   // UTF-16BE text of the first code
+	list   []string // UTF-16BE text of each code
+}
+
+// toUnicodeMap is a parsed ToUnicode CMap
+type toUnicodeMap struct {
+	codespace []codeRange
+	chars     map[string]string // UTF-8 text of single codes
+	ranges    []unicodeRange
+}
+
+// parseToUnicode reads the codespace and the bfchar and bfrange mappings of a ToUnicode
+// CMap stream
+func parseToUnicode(stream pdf.Value) (*toUnicodeMap, error) {
+	data, err := readStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := parseContent(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ToUnicode CMap: %w", err)
+	}
+
+	m := &toUnicodeMap{chars: make(map[string]string)}
+	for _, op := range ops {
+		args := op.operands
+		switch op.operator {
+		case "endcodespacerange":
+			m.codespace = append(m.codespace, readCodespace(args)...)
+		case "endbfchar":
+			for i := 0; i+1 < len(args); i += 2 {
+				if args[i].kind == contentString && args[i+1].kind == contentString {
+					m.chars[args[i].text] = utf16Text(args[i+1].text)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(args); i += 3 {
+				lo, hi, dst := args[i], args[i+1], args[i+2]
+				if lo.kind != contentString || hi.kind != contentString || len(lo.text) != len(hi.text) ||
+					lo.text > hi.text || codeValue(hi.text)-codeValue(lo.text) >= maxCMapRange {
+					continue
+				}
+				r := unicodeRange{lo: lo.text, hi: hi.text}
+				switch dst.kind {
+				case contentString:
+					r.first = dst.text
+				case contentArray:
+					for _, item := range dst.items {
+						r.list = append(r.list, item.text)
+					}
+				default:
+					continue
+				}
+				m.ranges = append(m.ranges, r)
+			}
+		}
+	}
+	if len(m.chars) == 0 && len(m.ranges) == 0 {
+		return nil, fmt.Errorf("ToUnicode CMap maps no codes")
+	}
+	return m, nil
+}
+
+// readCodespace reads the codespace ranges given to endcodespacerange
+func readCodespace(args []contentObject) []codeRange {
+	var ranges []codeRange
+	for i := 0; i+1 < len(args); i += 2 {
+		lo, hi := args[i].text, args[i+1].text
+		if args[i].kind == contentString && len(lo) >= 1 && len(lo) <= 4 && len(lo) == len(hi) {
+			ranges = append(ranges, codeRange{lo: lo, hi: hi})
+		}
+	}
+	return ranges
+}
+
+// lookup returns the text a code maps to
+func (m *toUnicodeMap) lookup(code string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	if text, ok := m.chars[code]; ok {
+		return text, true
+	}
+	for _, r := range m.ranges {
+		if len(code) != len(r.lo) || code < r.lo || code > r.hi {
+			continue
+		}
+		offset := codeValue(code) - codeValue(r.lo)
+		if r.list != nil {
+			if offset < len(r.list) {
+				return utf16Text(r.list[offset]), true
+			}
+			return "", false
+		}
+		return utf16Text(incrementUTF16(r.first, offset)), true
+	}
+	return "", false
+}
+
+// codeValue reads a code as a big-endian number
+func codeValue(code string) int {
+	value := 0
+	for i := 0; i < len(code); i++ {
+		value = value<<8 | int(code[i])
+	}
+	return value
+}
+
+// incrementUTF16 adds offset to the last UTF-16 code unit of text, carrying into the bytes
+// before it as producers that overflow a byte expect
+func incrementUTF16(text string, offset int) string {
+	b := []byte(text)
+	for i := len(b) - 1; i >= 0 && offset > 0; i-- {
+		sum := int(b[i]) + offset
+		b[i] = byte(sum)
+		offset = sum >> 8
+	}
+	return string(b)
+}
+
+// utf16Text decodes the UTF-16BE text of a CMap destination. Odd-length destinations,
+// which some producers write as single bytes, are read byte by byte.
+func utf16Text(raw string) string {
+	if len(raw)%2 != 0 {
+		runes := make([]rune, len(raw))
+		for i := 0; i < len(raw); i++ {
+			runes[i] = rune(raw[i])
+		}
+		return string(runes)
+	}
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = uint16(raw[2*i])<<8 | uint16(raw[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// splitCode returns the length of the code at the start of raw under a codespace, or zero
+// when no range matches
+func splitCode(raw string, codespace []codeRange) int {
+	for n := 1; n <= 4 && n <= len(raw); n++ {
+		for _, r := range codespace {
+			if r.contains(raw[:n]) {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// predefinedCMap is one of the named CMaps a composite font may use as its encoding
+type predefinedCMap struct {
+	name     string
+	identity bool                             // Codes are CIDs, with no Unicode meaning of their own
+	length   func(raw string) int             // Length of the code at the start of a string
+	unicode  func(code string) (string, bool) // Text of a code; nil for the Identity CMaps
+}
+
+// lookupPredefinedCMap recognizes the predefined CMaps whose codes can be read as
+// Unicode: the Identity CMaps, the Unicode CMaps of each Adobe character collection, and
+// the legacy CJK encodings
+func lookupPredefinedCMap(name string) *predefinedCMap {
+	base := strings.TrimSuffix(strings.TrimSuffix(name, "-H"), "-V")
+	cmap := &predefinedCMap{name: name}
+	switch {
+	case base == "Identity":
+		cmap.identity = true
+		cmap.length = fixedLength(2)
+	case strings.HasSuffix(base, "-UCS2"):
+		cmap.length = fixedLength(2)
+		cmap.unicode = func(code string) (string, bool) { return utf16Text(code), true }
+	case strings.HasSuffix(base, "-UTF16"):
+		cmap.length = utf16Length
+		cmap.unicode = func(code string) (string, bool) { return utf16Text(code), true }
+	case strings.HasSuffix(base, "-UTF8"):
+		cmap.length = utf8Length
+		cmap.unicode = func(code string) (string, bool) { return code, utf8.ValidString(code) }
+	case strings.HasSuffix(base, "-UTF32"):
+		cmap.length = fixedLength(4)
+		cmap.unicode = func(code string) (string, bool) {
+			r := rune(codeValue(code))
+			return string(r), utf8.ValidRune(r)
+		}
+	case strings.Contains(base, "RKSJ"):
+		cmap.length = shiftJISLength
+		cmap.unicode = legacyText(japanese.ShiftJIS)
+	case base == "EUC":
+		cmap.length = eucJPLength
+		cmap.unicode = legacyText(japanese.EUCJP)
+	case strings.Contains(base, "B5"):
+		cmap.length = leadByteLength
+		cmap.unicode = legacyText(traditionalchinese.Big5)
+	case strings.HasPrefix(base, "KSC"):
+		cmap.length = leadByteLength
+		cmap.unicode = legacyText(korean.EUCKR)
+	case base == "GBK2K":
+		cmap.length = gb18030Length
+		cmap.unicode = legacyText(simplifiedchinese.GB18030)
+	case strings.HasPrefix(base, "GB"):
+		cmap.length = leadByteLength
+		cmap.unicode = legacyText(simplifiedchinese.GBK)
+	default:
+		return nil
+	}
+	return cmap
+}
+
+// fixedLength splits strings into codes of n bytes
+func fixedLength(n int) func(string) int {
+	return func(raw string) int { return min(n, len(raw)) }
+}
+
+// utf16Length keeps surrogate pairs together
+func utf16Length(raw string) int {
+	if len(raw) >= 4 && raw[0] >= 0xD8 && raw[0] <= 0xDB {
+		return 4
+	}
+	return min(2, len(raw))
+}
+
+// utf8Length reads the length of a UTF-8 sequence from its first byte
+func utf8Length(raw string) int {
+	_, size := utf8.DecodeRuneInString(raw)
+	return max(size, 1)
+}
+
+// leadByteLength splits double-byte charsets, where bytes from 0x80 up lead a pair
+func leadByteLength(raw string) int {
+	if raw[0] < 0x80 {
+		return 1
+	}
+	return min(2, len(raw))
+}
+
+// shiftJISLength splits Shift-JIS, where half-width katakana take one byte
+func shiftJISLength(raw string) int {
+	if b := raw[0]; b < 0x81 || (b >= 0xA0 && b <= 0xDF) {
+		return 1
+	}
+	return min(2, len(raw))
+}
+
+// eucJPLength splits EUC-JP, where 0x8E leads half-width katakana and 0x8F the
+// supplementary plane
+func eucJPLength(raw string) int {
+	switch b := raw[0]; {
+	case b == 0x8F:
+		return min(3, len(raw))
+	case b >= 0x80:
+		return min(2, len(raw))
+	default:
+		return 1
+	}
+}
+
+// gb18030Length splits GB 18030, whose four-byte codes have a digit as their second byte
+func gb18030Length(raw string) int {
+	if raw[0] < 0x80 {
+		return 1
+	}
+	if len(raw) >= 4 && raw[1] >= 0x30 && raw[1] <= 0x39 {
+		return 4
+	}
+	return min(2, len(raw))
+}
+
+// legacyText decodes codes of a legacy charset
+func legacyText(enc encoding.Encoding) func(string) (string, bool) {
+	return func(code string) (string, bool) {
+		text, err := enc.NewDecoder().Bytes([]byte(code))
+		if err != nil || len(text) == 0 || bytes.ContainsRune(text, utf8.RuneError) {
+			return "", false
+		}
+		return string(text), true
+	}
+}
@@ -0,0 +1,332 @@
+package extraction
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// contentKind is the type of an object in a content stream
+type contentKind int
+
+const (
+	contentNumber contentKind = iota
+	contentName
+	contentString
+	contentArray
+	contentDict
+	contentLiteral // true, false, null
+	contentKeyword // An operator
+)
+
+// contentObject is an operand or operator of a content stream
+type contentObject struct {
+	kind  contentKind
+	num   float64
+	text  string          // Name, string bytes, literal, or operator
+	items []contentObject // Array elements, or dictionary keys and values in turn
+}
+
+// contentOp is one operator with its operands and the bytes they span in the stream
+type contentOp struct {
+	operator   string
+	operands   []contentObject
+	start, end int
+}
+
+// contentLexer splits a decoded content stream into objects
+type contentLexer struct {
+	data []byte
+	pos  int
+}
+
+func isContentSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isContentDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skip moves past whitespace and comments
+func (l *contentLexer) skip() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isContentSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next reads the next object; ok is false at the end of the stream
+func (l *contentLexer) next() (obj contentObject, ok bool, err error) {
+	l.skip()
+	if l.pos >= len(l.data) {
+		return contentObject{}, false, nil
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		return contentObject{kind: contentName, text: l.name()}, true, nil
+	case c == '(':
+		l.pos++
+		text, err := l.literalString()
+		return contentObject{kind: contentString, text: text}, true, err
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		items, err := l.dict()
+		return contentObject{kind: contentDict, items: items}, true, err
+	case c == '<':
+		l.pos++
+		text, err := l.hexString()
+		return contentObject{kind: contentString, text: text}, true, err
+	case c == '[':
+		l.pos++
+		items, err := l.array()
+		return contentObject{kind: contentArray, items: items}, true, err
+	case isContentDelimiter(c):
+		return contentObject{}, false, fmt.Errorf("unexpected %q at offset %d", c, l.pos)
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isContentSpace(l.data[l.pos]) && !isContentDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	if num, err := strconv.ParseFloat(word, 64); err == nil {
+		return contentObject{kind: contentNumber, num: num}, true, nil
+	}
+	switch word {
+	case "true", "false", "null":
+		return contentObject{kind: contentLiteral, text: word}, true, nil
+	}
+	return contentObject{kind: contentKeyword, text: word}, true, nil
+}
+
+// name reads a name after its slash, decoding #xx escapes
+func (l *contentLexer) name() string {
+	var b []byte
+	for l.pos < len(l.data) && !isContentSpace(l.data[l.pos]) && !isContentDelimiter(l.data[l.pos]) {
+		c := l.data[l.pos]
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		l.pos++
+	}
+	return string(b)
+}
+
+// literalString reads a parenthesized string after its opening parenthesis
+func (l *contentLexer) literalString() (string, error) {
+	var b []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(b), nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				continue
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e < '0' || e > '7' {
+					c = e
+					break
+				}
+				v := int(e - '0')
+				for n := 1; n < 3 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; n++ {
+					v = v*8 + int(l.data[l.pos]-'0')
+					l.pos++
+				}
+				c = byte(v)
+			}
+		}
+		b = append(b, c)
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// hexString reads a hexadecimal string after its opening bracket
+func (l *contentLexer) hexString() (string, error) {
+	var digits []byte
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			if len(digits)%2 == 1 {
+				digits = append(digits, '0')
+			}
+			b := make([]byte, len(digits)/2)
+			for i := range b {
+				v, err := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+				if err != nil {
+					return "", fmt.Errorf("invalid hex string: %w", err)
+				}
+				b[i] = byte(v)
+			}
+			return string(b), nil
+		}
+		if !isContentSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	return "", fmt.Errorf("unterminated hex string")
+}
+
+// array reads array elements after the opening bracket
+func (l *contentLexer) array() ([]contentObject, error) {
+	var items []contentObject
+	for {
+		l.skip()
+		if l.pos < len(l.data) && l.data[l.pos] == ']' {
+			l.pos++
+			return items, nil
+		}
+		item, ok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		items = append(items, item)
+	}
+}
+
+// dict reads dictionary keys and values after the opening brackets
+func (l *contentLexer) dict() ([]contentObject, error) {
+	var items []contentObject
+	for {
+		l.skip()
+		if bytes.HasPrefix(l.data[l.pos:], []byte(">>")) {
+			l.pos += 2
+			return items, nil
+		}
+		item, ok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("unterminated dictionary")
+		}
+		items = append(items, item)
+	}
+}
+
+// inlineImage skips the dictionary and data of an inline image after its BI operator
+func (l *contentLexer) inlineImage() ([]contentObject, error) {
+	var params []contentObject
+	for {
+		obj, ok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("unterminated inline image")
+		}
+		if obj.kind == contentKeyword && obj.text == "ID" {
+			break
+		}
+		params = append(params, obj)
+	}
+
+	// The data starts after a single whitespace byte and ends at an EI surrounded by whitespace
+	data := l.pos + 1
+	for i := data; i+1 < len(l.data); i++ {
+		if l.data[i] != 'E' || l.data[i+1] != 'I' || !isContentSpace(l.data[i-1]) {
+			continue
+		}
+		if i+2 == len(l.data) || isContentSpace(l.data[i+2]) || isContentDelimiter(l.data[i+2]) {
+			l.pos = i + 2
+			return params, nil
+		}
+	}
+	return nil, fmt.Errorf("inline image without EI")
+}
+
+// parseContent splits a decoded content stream into operations
+func parseContent(data []byte) ([]contentOp, error) {
+	lexer := &contentLexer{data: data}
+	var ops []contentOp
+	var operands []contentObject
+	start := -1
+	for {
+		lexer.skip()
+		if start < 0 {
+			start = lexer.pos
+		}
+		obj, ok, err := lexer.next()
+		if err != nil {
+			return nil, fmt.Errorf("invalid content stream: %w", err)
+		}
+		if !ok {
+			return ops, nil
+		}
+		if obj.kind != contentKeyword {
+			operands = append(operands, obj)
+			continue
+		}
+
+		op := contentOp{operator: obj.text, operands: operands, start: start}
+		if obj.text == "BI" {
+			if op.operands, err = lexer.inlineImage(); err != nil {
+				return nil, fmt.Errorf("invalid content stream: %w", err)
+			}
+		}
+		op.end = lexer.pos
+		ops = append(ops, op)
+		operands = nil
+		start = -1
+	}
+}
+
+// writeContentString writes a string operand in hexadecimal form
+func writeContentString(b *bytes.Buffer, s string) {
+	fmt.Fprintf(b, "<%x>", s)
+}
+
+// formatContentNumber formats a number without an exponent, as content streams require
+func formatContentNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
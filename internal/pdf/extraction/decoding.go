@@ -0,0 +1,225 @@
+package extraction
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// guessWeight is how much a code read without a known encoding counts towards the decoding
+// confidence of a page
+const guessWeight = 0.5
+
+// PageDecoding reports how well the character codes drawn on a page mapped to Unicode
+type PageDecoding struct {
+	Page       int      `json:"page"`
+	Glyphs     int      `json:"glyphs"`
+	Unmapped   int      `json:"unmapped,omitempty"` // Codes extracted as U+FFFD
+	Guessed    int      `json:"guessed,omitempty"`  // Codes read without a known encoding
+	Confidence float64  `json:"confidence"`         // Share of codes mapped, guesses counting half
+	Fonts      []string `json:"fonts,omitempty"`    // Fonts with unmapped or guessed codes
+}
+
+// decodedPage is the text of a page read through the fonts' own encodings
+type decodedPage struct {
+	glyphs   []pdf.Text
+	text     string // In content order, like the parser's GetPlainText
+	decoding PageDecoding
+}
+
+// decoderState is the graphics state tracked while decoding; the decoder measures codes
+// itself, so the text state's font is unused
+type decoderState struct {
+	ctm     affineMatrix
+	text    textState
+	decoder *fontDecoder
+}
+
+// decodePageText reads the text of a page through the ToUnicode and encoding CMaps of its
+// fonts, giving each code a glyph positioned like the parser's. The parser cannot read
+// codes of more than one byte or composite fonts with non-Identity encodings, so pages
+// drawn with composite fonts are read here instead.
+func decodePageText(page pdf.Page) (decoded *decodedPage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			decoded = nil
+			err = fmt.Errorf("failed to read page content: %v", r)
+		}
+	}()
+
+	data, err := readContents(page.V.Key("Contents"))
+	if err != nil {
+		return nil, err
+	}
+	ops, err := parseContent(data)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded = &decodedPage{}
+	resources := page.Resources()
+	decoders := map[string]*fontDecoder{}
+	weak := map[string]bool{}
+	state := decoderState{ctm: identityMatrix, text: textState{scale: 1}}
+	var stack []decoderState
+	var tm, tlm affineMatrix
+	var text strings.Builder
+
+	show := func(raw string) {
+		d := state.decoder
+		if d == nil {
+			return
+		}
+		for _, code := range d.decode(raw) {
+			render := affineMatrix{state.text.size * state.text.scale, 0, 0, state.text.size, 0, state.text.rise}.
+				multiply(tm).multiply(state.ctm)
+			decoded.glyphs = append(decoded.glyphs, pdf.Text{
+				Font: d.name, FontSize: render[0], X: render[4], Y: render[5],
+				W: code.width * render[0], S: code.text,
+			})
+			text.WriteString(code.text)
+
+			decoded.decoding.Glyphs++
+			switch code.mapping {
+			case mappedNone:
+				decoded.decoding.Unmapped++
+				weak[d.name] = true
+			case mappedGuess:
+				decoded.decoding.Guessed++
+				weak[d.name] = true
+			}
+
+			advance := code.width*state.text.size + state.text.charSpacing
+			if code.code == " " {
+				advance += state.text.wordSpacing
+			}
+			tm = affineMatrix{1, 0, 0, 1, advance * state.text.scale, 0}.multiply(tm)
+		}
+	}
+
+	for _, op := range ops {
+		args := op.operands
+		switch op.operator {
+		case "q":
+			stack = append(stack, state)
+		case "Q":
+			if len(stack) > 0 {
+				state = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			if m, ok := operandMatrix(args); ok {
+				state.ctm = m.multiply(state.ctm)
+			}
+		case "BT":
+			tm, tlm = identityMatrix, identityMatrix
+			text.WriteByte('\n')
+		case "Tf":
+			if len(args) == 2 {
+				name := args[0].text
+				if _, ok := decoders[name]; !ok {
+					decoders[name] = newFontDecoder(resources.Key("Font").Key(name))
+				}
+				state.decoder = decoders[name]
+				state.text.size = args[1].num
+			}
+		case "Tc":
+			state.text.charSpacing = numberOperand(args, 0)
+		case "Tw":
+			state.text.wordSpacing = numberOperand(args, 0)
+		case "Tz":
+			state.text.scale = numberOperand(args, 0) / 100
+		case "TL":
+			state.text.leading = numberOperand(args, 0)
+		case "Ts":
+			state.text.rise = numberOperand(args, 0)
+		case "Td", "TD":
+			if len(args) == 2 {
+				if op.operator == "TD" {
+					state.text.leading = -args[1].num
+				}
+				tlm = affineMatrix{1, 0, 0, 1, args[0].num, args[1].num}.multiply(tlm)
+				tm = tlm
+			}
+		case "Tm":
+			if m, ok := operandMatrix(args); ok {
+				tm, tlm = m, m
+			}
+		case "T*":
+			tlm = affineMatrix{1, 0, 0, 1, 0, -state.text.leading}.multiply(tlm)
+			tm = tlm
+			text.WriteByte('\n')
+		case "Tj", "'", "\"":
+			if op.operator == "\"" && len(args) == 3 {
+				state.text.wordSpacing, state.text.charSpacing = args[0].num, args[1].num
+			}
+			if op.operator != "Tj" {
+				tlm = affineMatrix{1, 0, 0, 1, 0, -state.text.leading}.multiply(tlm)
+				tm = tlm
+			}
+			if len(args) > 0 {
+				show(args[len(args)-1].text)
+			}
+		case "TJ":
+			if len(args) == 0 {
+				break
+			}
+			for _, item := range args[0].items {
+				switch item.kind {
+				case contentString:
+					show(item.text)
+				case contentNumber:
+					tm = affineMatrix{1, 0, 0, 1, -item.num / 1000 * state.text.size * state.text.scale, 0}.multiply(tm)
+				}
+			}
+		}
+	}
+
+	decoded.text = text.String()
+	for name := range weak {
+		decoded.decoding.Fonts = append(decoded.decoding.Fonts, name)
+	}
+	slices.Sort(decoded.decoding.Fonts)
+	decoded.decoding.Confidence = decodingConfidence(decoded.decoding)
+	return decoded, nil
+}
+
+// decodingConfidence returns the share of a page's codes mapped to Unicode. Pages without
+// text are fully confident.
+func decodingConfidence(d PageDecoding) float64 {
+	if d.Glyphs == 0 {
+		return 1
+	}
+	mapped := d.Glyphs - d.Unmapped - d.Guessed
+	return (float64(mapped) + guessWeight*float64(d.Guessed)) / float64(d.Glyphs)
+}
+
+// PageTextDecoding reports how well the text of a page maps to Unicode through its fonts'
+// encodings
+func PageTextDecoding(page pdf.Page, pageNum int) (PageDecoding, error) {
+	decoded, err := decodePageText(page)
+	if err != nil {
+		return PageDecoding{Page: pageNum}, err
+	}
+	decoded.decoding.Page = pageNum
+	return decoded.decoding, nil
+}
+
+// pageHasCompositeFonts reports whether a page's resources hold a composite (Type0) font,
+// whose text the parser cannot read reliably
+func pageHasCompositeFonts(page pdf.Page) (composite bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			composite = false
+		}
+	}()
+	fonts := page.Resources().Key("Font")
+	for _, name := range fonts.Keys() {
+		if fonts.Key(name).Key("Subtype").Name() == "Type0" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package extraction
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+)
+
+// toUnicodeCMap maps 0003 to H, 0004-0005 to i-j, and 0006 to 中
+const toUnicodeCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def
+/CMapName /Adobe-Identity-UCS def
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0003> <0048>
+<0006> <4E2D>
+endbfchar
+1 beginbfrange
+<0004> <0005> <0069>
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+// writeCompositeFontPDF writes a three-page PDF drawn with composite fonts: an Identity-H
+// font with a ToUnicode CMap, one without, and a UniGB-UCS2-H font
+func writeCompositeFontPDF(t *testing.T) string {
+	t.Helper()
+	stream := func(content string) string {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+	}
+	page := func(content int) string {
+		return fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R "+
+			"/Resources << /Font << /F1 6 0 R /F2 9 0 R /F3 10 0 R >> >> >>", content)
+	}
+	descendant := "<< /Type /Font /Subtype /CIDFontType2 /BaseFont /SimSun " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /DW 1000 /W [3 [500 600]] >>"
+
+	return writeRawPDF(t, "composite.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>",
+		page(11),
+		page(12),
+		page(13),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /ABCDEF+SimSun /Encoding /Identity-H " +
+			"/DescendantFonts [7 0 R] /ToUnicode 8 0 R >>",
+		descendant,
+		stream(toUnicodeCMap),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Unmapped /Encoding /Identity-H /DescendantFonts [7 0 R] >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H " +
+			"/DescendantFonts [7 0 R] >>",
+		stream("BT /F1 12 Tf 72 720 Td <000300040006> Tj ET"),
+		stream("BT /F2 12 Tf 72 720 Td <00030004> Tj ET"),
+		stream("BT /F3 12 Tf 72 720 Td <4E2D6587> Tj ET"),
+	})
+}
+
+func TestPlainText_CompositeFonts(t *testing.T) {
+	doc, err := core.Open(writeCompositeFontPDF(t), core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	tests := []struct {
+		page       int
+		want       string
+		confidence float64
+		unmapped   int
+	}{
+		{page: 1, want: "Hi中", confidence: 1},
+		{page: 2, want: "��", confidence: 0, unmapped: 2},
+		{page: 3, want: "中文", confidence: 1},
+	}
+	for _, tt := range tests {
+		page := doc.Reader.Page(tt.page)
+		text, err := PlainText(page)
+		if err != nil {
+			t.Fatalf("PlainText(page %d) unexpected error = %v", tt.page, err)
+		}
+		if strings.TrimSpace(text) != tt.want {
+			t.Errorf("PlainText(page %d) = %q, want %q", tt.page, text, tt.want)
+		}
+
+		decoding, err := PageTextDecoding(page, tt.page)
+		if err != nil {
+			t.Fatalf("PageTextDecoding(page %d) unexpected error = %v", tt.page, err)
+		}
+		if decoding.Confidence != tt.confidence || decoding.Unmapped != tt.unmapped {
+			t.Errorf("PageTextDecoding(page %d) = %+v, want confidence %v with %d unmapped",
+				tt.page, decoding, tt.confidence, tt.unmapped)
+		}
+	}
+
+	// Glyphs advance by the CID widths of the descendant font
+	glyphs, err := pageGlyphs(doc.Reader.Page(1))
+	if err != nil {
+		t.Fatalf("pageGlyphs() unexpected error = %v", err)
+	}
+	wantX := []float64{72, 78, 85.2}
+	if len(glyphs) != len(wantX) {
+		t.Fatalf("pageGlyphs() = %+v, want %d glyphs", glyphs, len(wantX))
+	}
+	for i, x := range wantX {
+		if math.Abs(glyphs[i].X-x) > 1e-9 || glyphs[i].Font != "ABCDEF+SimSun" {
+			t.Errorf("glyph %d = %+v, want ABCDEF+SimSun at x %v", i, glyphs[i], x)
+		}
+	}
+}
+
+func TestLookupPredefinedCMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes string
+		want  string
+	}{
+		{name: "UniJIS-UTF16-H", codes: "\xd8\x3d\xde\x00\x00\x41", want: "😀A"},
+		{name: "90ms-RKSJ-H", codes: "\x93\xfa\x96\x7b\xb1", want: "日本ｱ"},
+		{name: "ETen-B5-H", codes: "\xa4\xa4\xa4\xe5", want: "中文"},
+		{name: "KSCms-UHC-H", codes: "\xc7\xd1A", want: "한A"},
+		{name: "GBK-EUC-H", codes: "\xd6\xd0\xce\xc4", want: "中文"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmap := lookupPredefinedCMap(tt.name)
+			if cmap == nil {
+				t.Fatalf("lookupPredefinedCMap(%q) = nil", tt.name)
+			}
+			var b strings.Builder
+			for raw := tt.codes; len(raw) > 0; {
+				n := cmap.length(raw)
+				text, ok := cmap.unicode(raw[:n])
+				if !ok {
+					t.Fatalf("code % x not mapped", raw[:n])
+				}
+				b.WriteString(text)
+				raw = raw[n:]
+			}
+			if b.String() != tt.want {
+				t.Errorf("decoded %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+
+	if lookupPredefinedCMap("Adobe-Japan1-6") != nil {
+		t.Error("lookupPredefinedCMap() recognized a CMap without a Unicode meaning")
+	}
+}
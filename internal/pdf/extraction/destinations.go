@@ -0,0 +1,184 @@
+package extraction
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Traversal limits guarding against malformed, cyclic documents
+const (
+	maxPageTreeDepth = 64
+	maxNameTreeDepth = 32
+)
+
+// Positions of the view parameters in explicit destination arrays
+const (
+	destTypeIndex = 1
+	destArg1Index = 2
+	xyzTopIndex   = 3
+	xyzZoomIndex  = 4
+)
+
+// ObjectRef identifies an indirect PDF object by its object and generation numbers
+type ObjectRef struct {
+	ID  uint32
+	Gen uint16
+}
+
+// String formats the reference as it appears in a PDF file, e.g. "12 0 R"
+func (o ObjectRef) String() string {
+	return fmt.Sprintf("%d %d R", o.ID, o.Gen)
+}
+
+// RefOf returns the indirect object a value was loaded from. The parser does not expose
+// object numbers, so they are read from its unexported pointer field; direct objects report
+// the object containing them and invalid values report the zero reference.
+func RefOf(v pdf.Value) ObjectRef {
+	ptr := reflect.ValueOf(v).FieldByName("ptr")
+	if !ptr.IsValid() || ptr.NumField() < 2 {
+		return ObjectRef{}
+	}
+	return ObjectRef{
+		ID:  uint32(ptr.Field(0).Uint()),
+		Gen: uint16(ptr.Field(1).Uint()),
+	}
+}
+
+// Destination is an explicit destination: a target page and how to display it
+type Destination struct {
+	Page int     // 1-based target page, 0 when unknown
+	Type string  // XYZ, Fit, FitH, FitV, FitR, FitB, FitBH, FitBV
+	Left float64 // Horizontal position, when the type specifies one
+	Top  float64 // Vertical position, when the type specifies one
+	Zoom float64 // 0 means keep the current zoom
+}
+
+// ResolveDestination turns a named destination into its explicit destination array
+func ResolveDestination(r *pdf.Reader, dest pdf.Value) pdf.Value {
+	var name string
+	switch dest.Kind() {
+	case pdf.Array:
+		return dest
+	case pdf.Dict:
+		return dest.Key("D")
+	case pdf.Name:
+		name = dest.Name()
+	case pdf.String:
+		name = dest.RawString()
+	default:
+		return pdf.Value{}
+	}
+
+	root := r.Trailer().Key("Root")
+
+	// PDF 1.1 style destination dictionary
+	if named := root.Key("Dests").Key(name); !named.IsNull() {
+		return unwrapDestination(named)
+	}
+
+	// PDF 1.2+ name tree
+	return unwrapDestination(LookupNameTree(root.Key("Names").Key("Dests"), name))
+}
+
+// ParseDestination reads the page and view parameters from an explicit destination array.
+// Page objects are numbered through pages, which is nil for remote destinations; integer
+// pages (remote destinations) are zero-based.
+func ParseDestination(dest pdf.Value, pages *PageNumbering) Destination {
+	var result Destination
+	if dest.Kind() != pdf.Array || dest.Len() == 0 {
+		return result
+	}
+
+	target := dest.Index(0)
+	switch target.Kind() {
+	case pdf.Dict:
+		result.Page = pages.Number(target)
+	case pdf.Integer:
+		result.Page = int(target.Int64()) + 1
+	}
+
+	if dest.Len() <= destTypeIndex {
+		return result
+	}
+	result.Type = dest.Index(destTypeIndex).Name()
+
+	switch result.Type {
+	case "XYZ":
+		if dest.Len() > xyzTopIndex {
+			result.Left = dest.Index(destArg1Index).Float64()
+			result.Top = dest.Index(xyzTopIndex).Float64()
+		}
+		if dest.Len() > xyzZoomIndex {
+			result.Zoom = dest.Index(xyzZoomIndex).Float64()
+		}
+	case "FitH", "FitBH":
+		result.Top = dest.Index(destArg1Index).Float64()
+	case "FitV", "FitBV":
+		result.Left = dest.Index(destArg1Index).Float64()
+	}
+
+	return result
+}
+
+// unwrapDestination returns the destination array from a named destination entry
+func unwrapDestination(v pdf.Value) pdf.Value {
+	if v.Kind() == pdf.Dict {
+		return v.Key("D")
+	}
+	return v
+}
+
+// LookupNameTree finds a key in a PDF name tree
+func LookupNameTree(root pdf.Value, key string) pdf.Value {
+	return lookupNameTree(root, key, 0)
+}
+
+func lookupNameTree(node pdf.Value, key string, depth int) pdf.Value {
+	if node.Kind() != pdf.Dict || depth > maxNameTreeDepth {
+		return pdf.Value{}
+	}
+
+	names := node.Key("Names")
+	for i := 0; i+1 < names.Len(); i += 2 {
+		if names.Index(i).RawString() == key {
+			return names.Index(i + 1)
+		}
+	}
+
+	kids := node.Key("Kids")
+	for i := 0; i < kids.Len(); i++ {
+		kid := kids.Index(i)
+		limits := kid.Key("Limits")
+		if limits.Len() == 2 && (key < limits.Index(0).RawString() || key > limits.Index(1).RawString()) {
+			continue
+		}
+		if found := lookupNameTree(kid, key, depth+1); !found.IsNull() {
+			return found
+		}
+	}
+
+	return pdf.Value{}
+}
+
+// WalkNameTree calls fn for every key/value pair in a PDF name tree, in tree order
+func WalkNameTree(root pdf.Value, fn func(key string, value pdf.Value)) {
+	walkNameTree(root, 0, fn)
+}
+
+func walkNameTree(node pdf.Value, depth int, fn func(key string, value pdf.Value)) {
+	if node.Kind() != pdf.Dict || depth > maxNameTreeDepth {
+		return
+	}
+
+	names := node.Key("Names")
+	for i := 0; i+1 < names.Len(); i += 2 {
+		fn(names.Index(i).RawString(), names.Index(i+1))
+	}
+
+	kids := node.Key("Kids")
+	for i := 0; i < kids.Len(); i++ {
+		walkNameTree(kids.Index(i), depth+1, fn)
+	}
+}
@@ -1,16 +1,23 @@
 package extraction
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/a3tai/mcp-pdf-reader/internal/logging"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
 	"github.com/ledongthuc/pdf"
 )
 
+// logger reports extraction diagnostics; they are also returned to callers as parse issues
+var logger = logging.Component("extraction")
+
 // Constants for PDF processing
 const (
 	defaultTableDetectionThreshold = 0.7
@@ -38,8 +45,9 @@ const (
 
 // Engine defines the interface for PDF content extraction
 type Engine interface {
-	// Extract performs content extraction based on the provided request
-	Extract(req ExtractionRequest) (*ExtractionResult, error)
+	// Extract performs content extraction based on the provided request. When ctx ends
+	// during extraction, the pages finished so far are returned as a partial result.
+	Extract(ctx context.Context, req ExtractionRequest) (*ExtractionResult, error)
 
 	// Query searches extracted content using the provided query
 	Query(elements []ContentElement, query Query) ([]ContentElement, error)
@@ -54,14 +62,17 @@ type Engine interface {
 // PageInfo represents information about a single PDF page
 type PageInfo struct {
 	Number   int         `json:"number"`
-	Width    float64     `json:"width"`
-	Height   float64     `json:"height"`
+	Label    string      `json:"label,omitempty"`
+	Width    float64     `json:"width"`  // As displayed, after rotation
+	Height   float64     `json:"height"` // As displayed, after rotation
 	Rotation int         `json:"rotation"`
 	MediaBox BoundingBox `json:"media_box"`
 	CropBox  BoundingBox `json:"crop_box,omitempty"`
 	BleedBox BoundingBox `json:"bleed_box,omitempty"`
 	TrimBox  BoundingBox `json:"trim_box,omitempty"`
 	ArtBox   BoundingBox `json:"art_box,omitempty"`
+	// Boxes the document sets for the page, such as "CropBox"; the others take their defaults
+	ExplicitBoxes []string `json:"explicit_boxes,omitempty"`
 }
 
 // DefaultEngine implements the Engine interface
@@ -71,6 +82,12 @@ type DefaultEngine struct {
 	ocrEnabled       bool
 	tableDetectionTh float64
 	debugMode        bool
+	memoryMap        bool
+	maxWorkers       int           // Pages extracted concurrently unless a request sets MaxWorkers
+	checkpointDir    string        // Where finished pages are saved for resuming; empty disables checkpoints
+	memory           *MemoryBudget // Shared by concurrent extractions; nil means no limit
+	memoryWait       time.Duration // How long a spilled page waits for memory to be released
+	limits           Limits        // Bounds on the work of each extraction
 }
 
 // NewEngine creates a new extraction engine with default settings
@@ -81,6 +98,7 @@ func NewEngine() *DefaultEngine {
 		ocrEnabled:       false,
 		tableDetectionTh: defaultTableDetectionThreshold,
 		debugMode:        false,
+		maxWorkers:       defaultMaxWorkers(),
 	}
 }
 
@@ -92,29 +110,45 @@ func NewEngineWithConfig(maxFileSize, maxTextSize int64, ocrEnabled bool) *Defau
 		ocrEnabled:       ocrEnabled,
 		tableDetectionTh: defaultTableDetectionThreshold,
 		debugMode:        false,
+		maxWorkers:       defaultMaxWorkers(),
 	}
 }
 
+// SetMemoryMapping enables reading documents through a memory mapping where the platform supports it
+func (e *DefaultEngine) SetMemoryMapping(enabled bool) {
+	e.memoryMap = enabled
+}
+
 // Extract performs comprehensive content extraction from a PDF
-func (e *DefaultEngine) Extract(req ExtractionRequest) (*ExtractionResult, error) {
+func (e *DefaultEngine) Extract(ctx context.Context, req ExtractionRequest) (*ExtractionResult, error) {
 	startTime := time.Now()
 
 	// Validate request
 	if err := e.validateRequest(req); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Open PDF file
-	f, pdfReader, err := pdf.Open(req.FilePath)
+	doc, err := core.OpenShared(ctx, req.FilePath, core.Options{MemoryMap: e.memoryMap})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open PDF: %w", err)
+		return nil, err
 	}
-	defer f.Close()
+	keepOpen := false
+	defer func() {
+		if !keepOpen {
+			doc.Close()
+		}
+	}()
+	pdfReader := doc.Reader
+	numbering := NewPageNumbering(pdfReader)
 
 	// Initialize result
 	result := &ExtractionResult{
 		FilePath:       req.FilePath,
-		TotalPages:     pdfReader.NumPage(),
+		TotalPages:     numbering.Count(),
 		ProcessedPages: []int{},
 		Elements:       []ContentElement{},
 		Tables:         []TableElement{},
@@ -129,108 +163,220 @@ func (e *DefaultEngine) Extract(req ExtractionRequest) (*ExtractionResult, error
 	}
 
 	// Extract metadata
-	metadata, err := e.extractMetadata(pdfReader)
+	metadata, err := ReadMetadata(pdfReader, doc.HeaderVersion())
 	if err != nil {
-		result.Warnings = append(result.Warnings, fmt.Sprintf("metadata extraction failed: %v", err))
-	} else {
-		result.Metadata = *metadata
+		result.addIssue(newParseIssue(SeverityWarning, StageMetadata, 0, pdfReader.Trailer().Key("Info"),
+			fmt.Errorf("metadata extraction failed: %w", err)))
+	}
+	result.Metadata = *metadata
+	for _, issue := range numbering.Issues {
+		result.addIssue(issue)
 	}
 
 	// Determine pages to process
-	pagesToProcess := e.determinePagesToProcess(req.Config.Pages, pdfReader.NumPage())
+	pagesToProcess := e.limitPages(result, e.determinePagesToProcess(req.Config.Pages, numbering.Count()))
 	result.ProcessedPages = pagesToProcess
 
-	// Extract content from each page
+	// Pages finished by an earlier, checkpointed run are not extracted again
+	cp, resumed, err := e.openCheckpoint(req, pagesToProcess)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make([]int, 0, len(pagesToProcess))
 	for _, pageNum := range pagesToProcess {
-		pageElements, pageErrors := e.extractPageContent(pdfReader, pageNum, req.Config)
-		result.Elements = append(result.Elements, pageElements...)
-
-		if len(pageErrors) > 0 {
-			for _, err := range pageErrors {
-				result.Errors = append(result.Errors, fmt.Sprintf("page %d: %v", pageNum, err))
-			}
+		if _, ok := resumed[pageNum]; !ok {
+			remaining = append(remaining, pageNum)
 		}
 	}
 
-	// Post-process content based on mode
-	if err := e.postProcessContent(result, req.Config); err != nil {
-		result.Warnings = append(result.Warnings, fmt.Sprintf("post-processing failed: %v", err))
+	// Extract content from each page, holding finished pages within the memory budget.
+	// Progress counts the pages resumed from the checkpoint as done.
+	links := NewLinkResolver(pdfReader, numbering)
+	spool := e.newSpool()
+	defer spool.release()
+	pageCtx := WithProgress(ctx, func(done, _ int, message string) {
+		ReportProgress(ctx, len(resumed)+done, len(pagesToProcess), message)
+	})
+	extracted, wait := e.extractPages(pageCtx, numbering, remaining, req.Config, links, cp, spool)
+	outcomes := mergeResumed(pagesToProcess, resumed, extracted)
+	processed := make([]int, 0, len(outcomes))
+	var omitted, timedOut []int
+	for _, outcome := range outcomes {
+		outcome, ok := spool.restore(ctx, outcome)
+		if !ok {
+			omitted = append(omitted, outcome.timing.Page)
+			continue
+		}
+		if outcome.timedOut {
+			timedOut = append(timedOut, outcome.timing.Page)
+		}
+		result.mergePage(outcome)
+		processed = append(processed, outcome.timing.Page)
+	}
+	if wait != nil {
+		// Abandoned workers may still be reading; close the document once they stop
+		keepOpen = true
+		go func() {
+			wait()
+			doc.Close()
+		}()
+	}
+	if len(outcomes) < len(pagesToProcess) {
+		result.Partial = true
+		result.ProcessedPages = processed
+		result.addIssue(newParseIssue(SeverityError, StagePage, 0, pdf.Value{},
+			fmt.Errorf("extraction stopped after %d of %d pages: %w", len(outcomes), len(pagesToProcess), ctx.Err())))
+		if cp != nil {
+			result.ResumeToken = cp.token
+		}
+	} else if cp != nil {
+		if err := cp.remove(); err != nil {
+			result.addIssue(newParseIssue(SeverityWarning, StagePage, 0, pdf.Value{},
+				fmt.Errorf("failed to remove checkpoint: %w", err)))
+		}
+	}
+	if len(omitted) > 0 {
+		// Rather than exhaust the server's memory, return the pages that fit
+		result.Partial = true
+		result.ProcessedPages = processed
+		result.addIssue(newParseIssue(SeverityWarning, StagePage, 0, pdf.Value{},
+			fmt.Errorf("%d of %d pages were left out to stay within the server's memory budget: %v; "+
+				"extract them separately with pages", len(omitted), len(outcomes), omitted)))
+	}
+	if len(timedOut) > 0 {
+		result.truncate(fmt.Sprintf("%d pages exceeded the server's per-page time limit of %s and were skipped: %v",
+			len(timedOut), e.limits.PageTimeout, timedOut), true)
 	}
+	reserved, spilled := spool.stats()
+	result.ExtractionInfo.ProcessingStats.MemoryUsed = reserved
+	result.ExtractionInfo.ProcessingStats.SpilledPages = spilled
+
+	// Post-process content based on mode, after setting running headers, footers, and
+	// watermarks apart from the content, putting the lines of its text back together, and
+	// gathering list items into lists
+	postProcessStart := time.Now()
+	e.labelRepeatedContent(result, numbering)
+	e.normalizeContent(result, req.Config)
+	e.reconstructLists(result, req.Config, numbering)
+	if err := e.postProcessContent(result, req.Config, pdfReader, numbering); err != nil {
+		result.addIssue(newParseIssue(SeverityWarning, StagePostProcessing, 0, pdf.Value{},
+			fmt.Errorf("post-processing failed: %w", err)))
+	}
+	result.ExtractionInfo.ProcessingStats.addStageTime(StagePostProcessing, time.Since(postProcessStart))
 
 	// Apply query filter if provided
 	if req.Query != nil {
 		filteredElements, err := e.Query(result.Elements, *req.Query)
 		if err != nil {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("query filter failed: %v", err))
+			result.addIssue(newParseIssue(SeverityWarning, StageQuery, 0, pdf.Value{},
+				fmt.Errorf("query filter failed: %w", err)))
 		} else {
 			result.Elements = filteredElements
 		}
 	}
 
+	e.limitElements(result)
+
 	// Finalize extraction info
 	endTime := time.Now()
 	result.ExtractionInfo.EndTime = endTime
 	result.ExtractionInfo.Duration = endTime.Sub(startTime)
 	result.ExtractionInfo.ElementCounts = e.countElements(result.Elements)
+	result.ExtractionInfo.ElementCounts.Tables = len(result.Tables)
+	readStats := doc.Stats()
+	result.ExtractionInfo.ProcessingStats.Read = readStats
+	result.ExtractionInfo.ProcessingStats.BytesProcessed = readStats.BytesRead
 
 	return result, nil
 }
 
-// extractPageContent extracts all content from a single page
+// extractPageContent extracts all content from a single page. Each stage runs in isolation so
+// that a damaged object only loses the content of the stage that reads it; problems are
+// recorded on the result as parse issues. Time spent in each phase is recorded in timing.
 func (e *DefaultEngine) extractPageContent(
-	pdfReader *pdf.Reader, pageNum int, config ExtractionConfig,
-) ([]ContentElement, []error) {
+	numbering *PageNumbering, pageNum int, config ExtractionConfig, result *ExtractionResult, links *LinkResolver,
+	timing *PageTiming,
+) []ContentElement {
 	var elements []ContentElement
-	var errors []error
 
-	page := pdfReader.Page(pageNum)
+	parseStart := time.Now()
+	page := numbering.Page(pageNum)
 	if page.V.IsNull() {
-		return elements, []error{fmt.Errorf("invalid page %d", pageNum)}
+		result.addIssue(newParseIssue(SeverityError, StagePage, pageNum, page.V,
+			fmt.Errorf("invalid page %d", pageNum)))
+		timing.Parse = time.Since(parseStart)
+		return elements
 	}
 
 	// Get page dimensions (for future use in coordinate calculations)
-	_, err := e.getPageInfo(page, pageNum)
-	if err != nil {
-		errors = append(errors, fmt.Errorf("failed to get page info: %w", err))
+	if _, err := e.getPageInfo(numbering, pageNum); err != nil {
 		// Continue with default dimensions
+		result.addIssue(newParseIssue(SeverityWarning, StagePage, pageNum, page.V,
+			fmt.Errorf("failed to get page info: %w", err)))
 	}
-
-	// Extract text content
-	if config.ExtractText {
-		textElements, textErrors := e.extractTextFromPage(page, pageNum, config)
-		elements = append(elements, textElements...)
-		errors = append(errors, textErrors...)
+	timing.Parse = time.Since(parseStart)
+
+	// Broken filters are reported by the stages that read the content
+	decodeStart := time.Now()
+	_ = decodeContentStreams(page)
+	timing.FilterDecode = time.Since(decodeStart)
+
+	stages := []struct {
+		enabled bool
+		stage   string
+		extract func(pdf.Page, int, ExtractionConfig) ([]ContentElement, []error)
+	}{
+		{config.ExtractText, StageText, e.extractTextFromPage},
+		{config.ExtractImages, StageImages, e.extractImagesFromPage},
+		{config.ExtractImages, StageFigures, e.extractFiguresFromPage},
+		{config.ExtractVectors, StageVectors, e.extractVectorsFromPage},
+		{config.ExtractForms, StageForms, e.extractFormsFromPage},
+		{config.ExtractAnnotations, StageAnnotations, func(
+			page pdf.Page, pageNum int, config ExtractionConfig,
+		) ([]ContentElement, []error) {
+			return e.extractAnnotationsFromPage(page, pageNum, config, links)
+		}},
 	}
 
-	// Extract images
-	if config.ExtractImages {
-		imageElements, imageErrors := e.extractImagesFromPage(page, pageNum, config)
-		elements = append(elements, imageElements...)
-		errors = append(errors, imageErrors...)
+	for _, stage := range stages {
+		if !stage.enabled {
+			continue
+		}
+		stageStart := time.Now()
+		stageElements, stageErrors := runExtractionStage(stage.extract, page, pageNum, config)
+		elapsed := time.Since(stageStart)
+		timing.Extract += elapsed
+		result.ExtractionInfo.ProcessingStats.addStageTime(stage.stage, elapsed)
+		elements = append(elements, stageElements...)
+		for _, err := range stageErrors {
+			result.addIssue(newParseIssue(SeverityError, stage.stage, pageNum, page.V, err))
+		}
 	}
 
-	// Extract vector graphics
-	if config.ExtractVectors {
-		vectorElements, vectorErrors := e.extractVectorsFromPage(page, pageNum, config)
-		elements = append(elements, vectorElements...)
-		errors = append(errors, vectorErrors...)
+	// Failures to read the content are reported by the text stage
+	if config.ExtractText {
+		if decoding, err := PageTextDecoding(page, pageNum); err == nil && decoding.Glyphs > 0 {
+			stats := &result.ExtractionInfo.ProcessingStats
+			stats.PageDecoding = append(stats.PageDecoding, decoding)
+		}
 	}
 
-	// Extract form fields
-	if config.ExtractForms {
-		formElements, formErrors := e.extractFormsFromPage(page, pageNum, config)
-		elements = append(elements, formElements...)
-		errors = append(errors, formErrors...)
-	}
+	return elements
+}
 
-	// Extract annotations
-	if config.ExtractAnnotations {
-		annotationElements, annotErrors := e.extractAnnotationsFromPage(page, pageNum, config)
-		elements = append(elements, annotationElements...)
-		errors = append(errors, annotErrors...)
-	}
+// runExtractionStage runs a single page extraction stage, converting parser panics into errors
+func runExtractionStage(
+	extract func(pdf.Page, int, ExtractionConfig) ([]ContentElement, []error),
+	page pdf.Page, pageNum int, config ExtractionConfig,
+) (elements []ContentElement, errs []error) {
+	defer func() {
+		if r := recover(); r != nil {
+			elements = nil
+			errs = []error{fmt.Errorf("failed to parse page content: %v", r)}
+		}
+	}()
 
-	return elements, errors
+	return extract(page, pageNum, config)
 }
 
 // extractTextFromPage extracts text content with positioning and formatting
@@ -240,8 +386,17 @@ func (e *DefaultEngine) extractTextFromPage(
 	var elements []ContentElement
 	var errors []error
 
+	// Text of a region is read from the positions of its words
+	if config.Region != nil {
+		regionElements, err := e.extractRegionText(page, pageNum, config)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("failed to extract region text: %w", err))
+		}
+		return regionElements, errors
+	}
+
 	// Get basic text content
-	textContent, err := page.GetPlainText(nil)
+	textContent, err := PlainText(page)
 	if err != nil {
 		errors = append(errors, fmt.Errorf("failed to extract text: %w", err))
 		return elements, errors
@@ -264,7 +419,7 @@ func (e *DefaultEngine) extractTextFromPage(
 	}
 
 	// If structured mode, try to extract positioning and formatting
-	if config.Mode == ModeStructured || config.Mode == ModeComplete {
+	if config.Mode == ModeStructured || config.Mode == ModeComplete || config.Mode == ModePreview {
 		if structuredElements, err := e.extractStructuredText(page, pageNum, config); err != nil {
 			errors = append(errors, fmt.Errorf("structured text extraction failed: %w", err))
 			elements = append(elements, textElement) // Fallback to basic text
@@ -288,7 +443,7 @@ func (e *DefaultEngine) extractStructuredText(
 	// the page's content stream to get detailed positioning and formatting
 
 	// Get text content and create word-level elements if possible
-	textContent, err := page.GetPlainText(nil)
+	textContent, err := PlainText(page)
 	if err != nil {
 		return nil, err
 	}
@@ -456,155 +611,301 @@ func (e *DefaultEngine) extractVectorsFromPage(
 	var elements []ContentElement
 	var errors []error
 
-	// Vector extraction would require parsing the page's content stream
-	// This is a complex task that involves interpreting PDF graphics operators
-	// For now, we'll return an empty result with a note
+	vectors, err := interpretPageGraphics(page)
+	if err != nil {
+		return elements, []error{fmt.Errorf("vector extraction failed: %w", err)}
+	}
 
-	if e.debugMode {
-		errors = append(errors, fmt.Errorf("vector extraction not yet implemented - requires content stream parsing"))
+	for i, vector := range vectors {
+		elements = append(elements, ContentElement{
+			ID:          e.generateID("vector", pageNum, i),
+			Type:        ContentTypeVector,
+			PageNumber:  pageNum,
+			BoundingBox: vectorBounds(vector),
+			Content:     vector,
+			ZOrder:      i,
+			Confidence:  1.0,
+		})
 	}
 
 	return elements, errors
 }
 
-// extractFormsFromPage extracts form fields from a page
+// extractFormsFromPage extracts the form fields whose widgets the page shows, one element
+// per widget. A radio group thus yields an element for each of its buttons on the page.
 func (e *DefaultEngine) extractFormsFromPage(
 	page pdf.Page, pageNum int, config ExtractionConfig,
 ) ([]ContentElement, []error) {
 	var elements []ContentElement
-	var errors []error
 
-	// Form field extraction would require access to the AcroForm dictionary
-	// This is typically at the document level, not page level
+	seen := make(map[ObjectRef]bool)
+	annots := page.V.Key("Annots")
+	for i := 0; i < annots.Len(); i++ {
+		widget := annots.Index(i)
+		if widget.Key("Subtype").Name() != "Widget" {
+			continue
+		}
+		if ref := RefOf(widget); ref.ID != 0 {
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+		}
+
+		field := widgetField(widget)
+		content := FormElement{
+			FieldType:    field.Type,
+			FieldName:    field.Name,
+			DefaultValue: fieldDefault(field.Field),
+			Required:     field.Flags&FieldFlagRequired != 0,
+			ReadOnly:     field.Flags&FieldFlagReadOnly != 0,
+			Options:      field.Options,
+			MaxLength:    int(InheritedAttribute(field.Field, "MaxLen").Int64()),
+			Signature:    field.Signature,
+			Actions:      field.Actions,
+			Calculated:   field.Calculated,
+
+			Multiline:       field.Multiline,
+			Password:        field.Password,
+			DoNotSpellCheck: field.DoNotSpellCheck,
+			CombCells:       field.CombCells,
+			RichText:        field.RichText,
+
+			Choices:     field.Choices,
+			Combo:       field.Combo,
+			Editable:    field.Editable,
+			Sorted:      field.Sorted,
+			MultiSelect: field.MultiSelect,
+		}
+		if field.HasValue() {
+			content.Value = field.Value
+			if len(field.Values) > 1 {
+				content.Value = field.Values
+			}
+		}
+		for j, w := range field.Widgets {
+			if j < len(field.Buttons) && RefOf(w) == RefOf(widget) {
+				content.Checked = field.Buttons[j].Checked
+				content.ExportValue = field.Buttons[j].ExportValue
+			}
+		}
 
-	if e.debugMode {
-		errors = append(errors, fmt.Errorf("form extraction requires document-level AcroForm access"))
+		box, _ := rectBox(widget.Key("Rect"))
+		elements = append(elements, ContentElement{
+			ID:          e.generateID("form", pageNum, len(elements)),
+			Type:        ContentTypeForm,
+			PageNumber:  pageNum,
+			BoundingBox: box,
+			Content:     content,
+			Confidence:  1.0,
+		})
 	}
 
-	return elements, errors
+	return elements, nil
 }
 
 // extractAnnotationsFromPage extracts annotations from a page
 func (e *DefaultEngine) extractAnnotationsFromPage(
-	page pdf.Page, pageNum int, config ExtractionConfig,
+	page pdf.Page, pageNum int, config ExtractionConfig, links *LinkResolver,
 ) ([]ContentElement, []error) {
 	var elements []ContentElement
 	var errors []error
 
-	// Get annotations array
 	annotations := page.V.Key("Annots")
-	if annotations.IsNull() {
+	if annotations.Kind() != pdf.Array {
 		return elements, errors
 	}
 
-	// Process each annotation
-	annotIndex := 0
-	if annotations.Kind() == pdf.Array {
-		for i := 0; i < annotations.Len(); i++ {
-			annot := annotations.Index(i)
-			if annot.IsNull() {
-				continue
-			}
-
-			// Get annotation type
-			annotType := annot.Key("Subtype")
-			if annotType.IsNull() {
-				continue
-			}
+	// Annotations refer to one another as popups and replies, so their elements are
+	// numbered before those references are resolved
+	var annots []pdf.Value
+	ids := make(map[ObjectRef]string)
+	for i := 0; i < annotations.Len(); i++ {
+		annot := annotations.Index(i)
+		if annot.IsNull() || annot.Key("Subtype").IsNull() {
+			continue
+		}
+		if ref := RefOf(annot); ref.ID != 0 {
+			ids[ref] = e.generateID("annotation", pageNum, len(annots))
+		}
+		annots = append(annots, annot)
+	}
 
-			// Get annotation content
-			content := ""
-			if contents := annot.Key("Contents"); !contents.IsNull() {
-				content = contents.Text()
-			}
+	var words []WordElement // Read for the first text markup annotation
+	wordsRead := false
+	for i, annot := range annots {
+		content := parseAnnotation(annot, ids)
+
+		// Resolve where links lead
+		if content.AnnotationType == "Link" {
+			link := links.Resolve(annot)
+			content.Link = &link
+			content.URI = link.URI
+			content.Destination = link.NamedDestination
+		}
 
-			// Get annotation rectangle
-			rect := annot.Key("Rect")
-			var bbox BoundingBox
-			if !rect.IsNull() && rect.Kind() == pdf.Array && rect.Len() >= 4 {
-				bbox = BoundingBox{
-					LowerLeft: Coordinate{
-						X: rect.Index(0).Float64(),
-						Y: rect.Index(1).Float64(),
-					},
-					UpperRight: Coordinate{
-						X: rect.Index(2).Float64(),
-						Y: rect.Index(3).Float64(),
-					},
+		if len(content.QuadPoints) > 0 {
+			if !wordsRead {
+				var err error
+				if words, err = PageWords(page); err != nil {
+					errors = append(errors, fmt.Errorf("failed to read the text under annotations: %w", err))
 				}
-				bbox.Width = bbox.UpperRight.X - bbox.LowerLeft.X
-				bbox.Height = bbox.UpperRight.Y - bbox.LowerLeft.Y
-			}
-
-			annotElement := ContentElement{
-				ID:          e.generateID("annotation", pageNum, annotIndex),
-				Type:        ContentTypeAnnotation,
-				PageNumber:  pageNum,
-				BoundingBox: bbox,
-				Content: AnnotationElement{
-					AnnotationType: annotType.Name(),
-					Content:        content,
-				},
-				Confidence: 1.0,
+				wordsRead = true
 			}
+			content.MarkedText = markedText(words, content.QuadPoints)
+		}
 
-			elements = append(elements, annotElement)
-			annotIndex++
+		bbox, _ := rectBox(annot.Key("Rect"))
+		annotElement := ContentElement{
+			ID:          e.generateID("annotation", pageNum, i),
+			Type:        ContentTypeAnnotation,
+			PageNumber:  pageNum,
+			BoundingBox: bbox,
+			Content:     content,
+			Confidence:  1.0,
 		}
+		// Popups belong to the annotation whose note they show
+		if parent := ids[RefOf(annot.Key("Parent"))]; parent != "" && content.AnnotationType == "Popup" {
+			annotElement.Parent = &parent
+		}
+
+		elements = append(elements, annotElement)
 	}
 
 	return elements, errors
 }
 
 // postProcessContent performs post-processing based on extraction mode
-func (e *DefaultEngine) postProcessContent(result *ExtractionResult, config ExtractionConfig) error {
+func (e *DefaultEngine) postProcessContent(
+	result *ExtractionResult, config ExtractionConfig, pdfReader *pdf.Reader, numbering *PageNumbering,
+) error {
 	switch config.Mode {
-	case ModeTable:
-		return e.detectTables(result, config)
 	case ModeSemantic:
-		return e.groupSemanticContent(result, config)
-	case ModeComplete:
-		// Perform all post-processing
-		if err := e.detectTables(result, config); err != nil {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("table detection failed: %v", err))
+		if err := e.groupSemanticContent(result, config, pdfReader, numbering); err != nil {
+			return err
 		}
-		if err := e.groupSemanticContent(result, config); err != nil {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("semantic grouping failed: %v", err))
+		e.findEntities(result, config, numbering)
+	case ModeComplete:
+		// Tables are detected per page during extraction
+		if err := e.groupSemanticContent(result, config, pdfReader, numbering); err != nil {
+			result.addIssue(newParseIssue(SeverityWarning, StagePostProcessing, 0, pdf.Value{},
+				fmt.Errorf("semantic grouping failed: %w", err)))
 		}
-	case ModeRaw, ModeStructured, ModeForm:
+	case ModeRaw, ModeStructured, ModeForm, ModeTable, ModePreview:
 		// No additional post-processing needed for these modes
 	}
 
 	return nil
 }
 
-// detectTables attempts to detect tabular structures in the content
-func (e *DefaultEngine) detectTables(result *ExtractionResult, config ExtractionConfig) error {
-	// Table detection algorithm would analyze text positioning and alignment
-	// This is a simplified implementation
+// shouldDetectTables reports whether table detection was requested
+func (e *DefaultEngine) shouldDetectTables(config ExtractionConfig) bool {
+	return config.ExtractTables || config.Mode == ModeTable || config.Mode == ModeComplete
+}
 
-	textElements := e.filterElementsByType(result.Elements, ContentTypeText)
-	if len(textElements) < minTableElements {
-		return nil
+// detectTables finds tables on a page using ruling lines and text alignment, then merges
+// the two detections so that tables found by both strategies are reported once.
+func (e *DefaultEngine) detectTables(page pdf.Page, pageNum int, config ExtractionConfig) ([]TableElement, error) {
+	if page.V.IsNull() {
+		return nil, fmt.Errorf("invalid page %d", pageNum)
+	}
+
+	threshold := config.TableDetectionTh
+	if threshold <= 0 {
+		threshold = e.tableDetectionTh
+	}
+
+	ruled, err := e.detectRuledTables(page, pageNum)
+	if err != nil {
+		return nil, fmt.Errorf("ruling line detection failed: %w", err)
+	}
+
+	aligned, err := e.detectAlignedTables(page, pageNum, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("text alignment detection failed: %w", err)
+	}
+
+	var accepted []TableElement
+	for _, table := range mergeTableDetections(ruled, aligned) {
+		if table.Confidence >= threshold {
+			inferTableTypes(&table)
+			accepted = append(accepted, table)
+		}
+	}
+
+	return accepted, nil
+}
+
+// detectAlignedTables detects tables from words whose rows share a consistent column count
+func (e *DefaultEngine) detectAlignedTables(page pdf.Page, pageNum int, threshold float64) ([]TableElement, error) {
+	glyphs, err := pageGlyphs(page)
+	if err != nil {
+		return nil, err
+	}
+
+	words := groupGlyphsIntoWords(glyphs)
+	if len(words) < minTableElements {
+		return nil, nil
+	}
+
+	textElements := make([]ContentElement, len(words))
+	for i, word := range words {
+		textElements[i] = ContentElement{
+			Type:        ContentTypeText,
+			PageNumber:  pageNum,
+			BoundingBox: word.Box,
+			Content:     TextElement{Text: word.Text},
+			Confidence:  1.0,
+		}
 	}
 
 	// Group elements by approximate Y coordinates (rows)
 	rows := e.groupElementsByRow(textElements, rowTolerance)
-
 	if len(rows) < minRowsForTable {
-		return nil
+		return nil, nil
+	}
+	for _, row := range rows {
+		sort.Slice(row, func(i, j int) bool {
+			return row[i].BoundingBox.LowerLeft.X < row[j].BoundingBox.LowerLeft.X
+		})
 	}
 
 	// Check if rows have similar column structure
-	if table, confidence := e.analyzeTableStructure(rows); confidence > config.TableDetectionTh {
-		result.Tables = append(result.Tables, *table)
+	table, confidence := e.analyzeTableStructure(rows)
+	if table == nil || confidence < threshold || len(table.Rows) < minRowsForTable {
+		return nil, nil
 	}
 
-	return nil
+	table.PageNumber = pageNum
+	table.Strategy = TableStrategyTextAlignment
+	for i, row := range table.Rows {
+		for j, cell := range row.Cells {
+			if i == 0 && j == 0 {
+				table.BoundingBox = cell.BoundingBox
+				continue
+			}
+			table.BoundingBox = unionBoxes(table.BoundingBox, cell.BoundingBox)
+		}
+	}
+
+	return []TableElement{*table}, nil
 }
 
-// groupSemanticContent groups related content elements
-func (e *DefaultEngine) groupSemanticContent(result *ExtractionResult, _ ExtractionConfig) error {
+// groupSemanticContent groups related content elements. Tagged documents describe their
+// logical structure themselves, so their structure tree is read instead of inferring the
+// structure from the layout.
+func (e *DefaultEngine) groupSemanticContent(
+	result *ExtractionResult, _ ExtractionConfig, pdfReader *pdf.Reader, numbering *PageNumbering,
+) error {
+	tree, issues := ReadStructureTree(pdfReader, numbering, result.ProcessedPages)
+	for _, issue := range issues {
+		result.addIssue(issue)
+	}
+	if tree != nil {
+		result.Structure = tree
+		return nil
+	}
+
 	// Semantic grouping would analyze content relationships
 	// This could include grouping nearby text, associating labels with values, etc.
 
@@ -612,12 +913,38 @@ func (e *DefaultEngine) groupSemanticContent(result *ExtractionResult, _ Extract
 	return e.groupElementsByProximity(result.Elements, proximityThreshold)
 }
 
+// findEntities collects the entities in the text of the processed pages, within the
+// configured region when there is one
+func (e *DefaultEngine) findEntities(result *ExtractionResult, config ExtractionConfig, numbering *PageNumbering) {
+	for _, pageNum := range result.ProcessedPages {
+		text, err := NewPageText(numbering.Page(pageNum))
+		if err != nil {
+			result.addIssue(newParseIssue(SeverityWarning, StagePostProcessing, pageNum, pdf.Value{},
+				fmt.Errorf("entity extraction failed: %w", err)))
+			continue
+		}
+		for _, entity := range FindEntities(text, nil) {
+			if config.Region != nil && !CenteredIn(entity.BoundingBox, *config.Region) {
+				continue
+			}
+			entity.Page = pageNum
+			entity.PageLabel = numbering.Label(pageNum)
+			result.Entities = append(result.Entities, entity)
+		}
+	}
+}
+
 // Query filters content elements based on the provided query
 func (e *DefaultEngine) Query(elements []ContentElement, query Query) ([]ContentElement, error) {
+	matchText, err := NewTextMatcher(query.TextQuery, query.Regex, query.CaseSensitive)
+	if err != nil {
+		return nil, err
+	}
+
 	var filtered []ContentElement
 
 	for _, element := range elements {
-		if e.matchesQuery(element, query) {
+		if e.matchesQuery(element, query, matchText) {
 			filtered = append(filtered, element)
 		}
 	}
@@ -625,8 +952,32 @@ func (e *DefaultEngine) Query(elements []ContentElement, query Query) ([]Content
 	return filtered, nil
 }
 
+// NewTextMatcher returns a predicate implementing a text query: a case-insensitive substring
+// by default, or a regular expression. It returns nil when the query is empty.
+func NewTextMatcher(text string, regex, caseSensitive bool) (func(string) bool, error) {
+	switch {
+	case text == "":
+		return nil, nil
+	case regex:
+		pattern := text
+		if !caseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", text, err)
+		}
+		return re.MatchString, nil
+	case caseSensitive:
+		return func(s string) bool { return strings.Contains(s, text) }, nil
+	default:
+		needle := strings.ToLower(text)
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), needle) }, nil
+	}
+}
+
 // matchesQuery checks if an element matches the query criteria
-func (e *DefaultEngine) matchesQuery(element ContentElement, query Query) bool {
+func (e *DefaultEngine) matchesQuery(element ContentElement, query Query, matchText func(string) bool) bool {
 	// Check content type filter
 	if len(query.ContentTypes) > 0 {
 		found := false
@@ -668,10 +1019,8 @@ func (e *DefaultEngine) matchesQuery(element ContentElement, query Query) bool {
 	}
 
 	// Check text query
-	if query.TextQuery != "" {
-		if !e.elementContainsText(element, query.TextQuery) {
-			return false
-		}
+	if matchText != nil && !matchText(elementText(element)) {
+		return false
 	}
 
 	return true
@@ -691,16 +1040,6 @@ func (e *DefaultEngine) validateRequest(req ExtractionRequest) error {
 	return nil
 }
 
-func (e *DefaultEngine) extractMetadata(pdfReader *pdf.Reader) (*PDFMetadata, error) {
-	metadata := &PDFMetadata{}
-
-	// Extract basic metadata if available
-	// This would require accessing the document's Info dictionary
-	// For now, return empty metadata
-
-	return metadata, nil
-}
-
 func (e *DefaultEngine) determinePagesToProcess(requestedPages []int, totalPages int) []int {
 	if len(requestedPages) == 0 {
 		// Process all pages
@@ -722,29 +1061,102 @@ func (e *DefaultEngine) determinePagesToProcess(requestedPages []int, totalPages
 	return validPages
 }
 
-func (e *DefaultEngine) getPageInfo(page pdf.Page, pageNum int) (*PageInfo, error) {
-	// Get page dimensions from MediaBox
-	mediaBox := page.V.Key("MediaBox")
-	if mediaBox.IsNull() || mediaBox.Kind() != pdf.Array || mediaBox.Len() < 4 {
+// PageCropBox returns the part of a page viewers and renderers show: its CropBox clipped to
+// the MediaBox, or the MediaBox when it sets none. It reports false when the MediaBox is invalid.
+func PageCropBox(numbering *PageNumbering, pageNum int) (BoundingBox, bool) {
+	mediaBox, ok := pageBox(numbering.MediaBox(pageNum))
+	if !ok {
+		return BoundingBox{}, false
+	}
+	if box, ok := pageBox(InheritedAttribute(numbering.Page(pageNum).V, "CropBox")); ok {
+		return clipBox(box, mediaBox), true
+	}
+	return mediaBox, true
+}
+
+// getPageInfo describes a page from its inherited MediaBox and rotation, along with its
+// other page boxes. The CropBox is inherited like the MediaBox and defaults to it; the
+// BleedBox, TrimBox, and ArtBox are set on the page itself and default to the CropBox. Every
+// box is clipped to the MediaBox, as viewers and printers do.
+func (e *DefaultEngine) getPageInfo(numbering *PageNumbering, pageNum int) (*PageInfo, error) {
+	mediaBox, ok := pageBox(numbering.MediaBox(pageNum))
+	if !ok {
 		return nil, fmt.Errorf("invalid MediaBox")
 	}
 
-	llx := mediaBox.Index(0).Float64()
-	lly := mediaBox.Index(1).Float64()
-	urx := mediaBox.Index(2).Float64()
-	ury := mediaBox.Index(3).Float64()
-
-	return &PageInfo{
-		Number: pageNum,
-		Width:  urx - llx,
-		Height: ury - lly,
-		MediaBox: BoundingBox{
-			LowerLeft:  Coordinate{X: llx, Y: lly},
-			UpperRight: Coordinate{X: urx, Y: ury},
-			Width:      urx - llx,
-			Height:     ury - lly,
-		},
-	}, nil
+	rotation := numbering.Rotation(pageNum)
+	width, height := mediaBox.Width, mediaBox.Height
+	if rotation == 90 || rotation == 270 {
+		width, height = height, width
+	}
+
+	info := &PageInfo{
+		Number:   pageNum,
+		Label:    numbering.Label(pageNum),
+		Width:    width,
+		Height:   height,
+		Rotation: rotation,
+		MediaBox: mediaBox,
+		CropBox:  mediaBox,
+	}
+
+	page := numbering.Page(pageNum).V
+	if box, ok := pageBox(InheritedAttribute(page, "CropBox")); ok {
+		info.CropBox = clipBox(box, mediaBox)
+		info.ExplicitBoxes = append(info.ExplicitBoxes, "CropBox")
+	}
+	for _, target := range []struct {
+		key string
+		box *BoundingBox
+	}{
+		{"BleedBox", &info.BleedBox},
+		{"TrimBox", &info.TrimBox},
+		{"ArtBox", &info.ArtBox},
+	} {
+		*target.box = info.CropBox
+		if box, ok := pageBox(page.Key(target.key)); ok {
+			*target.box = clipBox(box, mediaBox)
+			info.ExplicitBoxes = append(info.ExplicitBoxes, target.key)
+		}
+	}
+	return info, nil
+}
+
+// pageBox reads a page box rectangle, which may name its corners in any order
+func pageBox(value pdf.Value) (BoundingBox, bool) {
+	if value.Kind() != pdf.Array || value.Len() < 4 {
+		return BoundingBox{}, false
+	}
+	var coords [4]float64
+	for i := range coords {
+		item := value.Index(i)
+		if item.Kind() != pdf.Integer && item.Kind() != pdf.Real {
+			return BoundingBox{}, false
+		}
+		coords[i] = item.Float64()
+	}
+	llx, urx := min(coords[0], coords[2]), max(coords[0], coords[2])
+	lly, ury := min(coords[1], coords[3]), max(coords[1], coords[3])
+	return newBox(llx, lly, urx, ury), true
+}
+
+// clipBox reduces a page box to its intersection with the media box
+func clipBox(box, mediaBox BoundingBox) BoundingBox {
+	llx := max(box.LowerLeft.X, mediaBox.LowerLeft.X)
+	lly := max(box.LowerLeft.Y, mediaBox.LowerLeft.Y)
+	urx := max(min(box.UpperRight.X, mediaBox.UpperRight.X), llx)
+	ury := max(min(box.UpperRight.Y, mediaBox.UpperRight.Y), lly)
+	return newBox(llx, lly, urx, ury)
+}
+
+// newBox builds a bounding box from its corners
+func newBox(llx, lly, urx, ury float64) BoundingBox {
+	return BoundingBox{
+		LowerLeft:  Coordinate{X: llx, Y: lly},
+		UpperRight: Coordinate{X: urx, Y: ury},
+		Width:      urx - llx,
+		Height:     ury - lly,
+	}
 }
 
 func (e *DefaultEngine) generateID(prefix string, pageNum, index int) string {
@@ -869,18 +1281,27 @@ func (e *DefaultEngine) analyzeTableStructure(rows [][]ContentElement) (*TableEl
 		}
 	}
 
-	// Process rows
-	for rowIdx, row := range rows {
-		if len(row) != commonColCount {
-			continue // Skip inconsistent rows
-		}
-
+	// Rows lining up with every column give their words one to a column; the others,
+	// such as headings spanning several columns or rows missing a value, are reconciled
+	// with the columns those rows line up in
+	ranges := alignedColumnRanges(rows, commonColCount)
+	first, last := alignedTableRows(rows, commonColCount, ranges)
+	for i := first; i >= 0 && i <= last; i++ {
+		row := rows[i]
+		rowIdx := len(table.Rows)
 		tableRow := TableRow{
 			Index:    rowIdx,
-			Cells:    make([]TableCell, len(row)),
 			IsHeader: rowIdx == 0,
 		}
 
+		if len(row) != commonColCount {
+			tableRow.Cells = alignedRowCells(row, ranges, rowIdx)
+			table.CellCount += len(tableRow.Cells)
+			table.Rows = append(table.Rows, tableRow)
+			continue
+		}
+
+		tableRow.Cells = make([]TableCell, len(row))
 		for colIdx := range row {
 			element := row[colIdx]
 			cell := TableCell{
@@ -901,6 +1322,8 @@ func (e *DefaultEngine) analyzeTableStructure(rows [][]ContentElement) (*TableEl
 
 		table.Rows = append(table.Rows, tableRow)
 	}
+	extendHeaderRowSpans(table)
+	markTableHeaders(table)
 
 	return table, confidence
 }
@@ -918,42 +1341,49 @@ func (e *DefaultEngine) boundingBoxesIntersect(box1, box2 BoundingBox) bool {
 		box2.UpperRight.Y < box1.LowerLeft.Y)
 }
 
-func (e *DefaultEngine) elementContainsText(element ContentElement, query string) bool {
+// elementText returns the searchable text of an element
+func elementText(element ContentElement) string {
 	switch content := element.Content.(type) {
 	case TextElement:
-		return strings.Contains(strings.ToLower(content.Text), strings.ToLower(query))
+		return content.Text
 	case AnnotationElement:
-		return strings.Contains(strings.ToLower(content.Content), strings.ToLower(query))
+		return content.Content
+	case ListElement:
+		return content.Text()
+	case FigureElement:
+		return content.Caption
 	}
-	return false
+	return ""
 }
 
+// GetMetadata reads the document's Info dictionary and XMP metadata. Parts of the metadata
+// that cannot be read are left out.
 func (e *DefaultEngine) GetMetadata(filePath string) (*PDFMetadata, error) {
-	f, pdfReader, err := pdf.Open(filePath)
+	doc, err := core.Open(filePath, core.Options{MemoryMap: e.memoryMap})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open PDF: %w", err)
+		return nil, err
 	}
-	defer f.Close()
+	defer doc.Close()
 
-	return e.extractMetadata(pdfReader)
+	metadata, err := ReadMetadata(doc.Reader, doc.HeaderVersion())
+	if err != nil {
+		logger.Debug("incomplete metadata", "path", filePath, "error", err)
+	}
+	return metadata, nil
 }
 
 // GetPageInfo returns information about all pages in the PDF
 func (e *DefaultEngine) GetPageInfo(filePath string) ([]PageInfo, error) {
-	f, pdfReader, err := pdf.Open(filePath)
+	doc, err := core.Open(filePath, core.Options{MemoryMap: e.memoryMap})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open PDF: %w", err)
+		return nil, err
 	}
-	defer f.Close()
+	defer doc.Close()
+	numbering := NewPageNumbering(doc.Reader)
 
 	var pages []PageInfo
-	for pageNum := 1; pageNum <= pdfReader.NumPage(); pageNum++ {
-		page := pdfReader.Page(pageNum)
-		if page.V.IsNull() {
-			continue
-		}
-
-		pageInfo, err := e.getPageInfo(page, pageNum)
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		pageInfo, err := e.getPageInfo(numbering, pageNum)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get info for page %d: %w", pageNum, err)
 		}
@@ -0,0 +1,238 @@
+package extraction
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Entity types found by FindEntities
+const (
+	EntityDate          = "date"
+	EntityAmount        = "amount"
+	EntityEmail         = "email"
+	EntityPhone         = "phone"
+	EntityAddress       = "address"
+	EntityInvoiceNumber = "invoice_number"
+	EntitySSN           = "ssn"
+)
+
+// EntityTypes lists the entity types in the order overlapping matches are resolved: an
+// earlier type claims text before a later one, so an invoice number is not also read as a
+// phone number or a ZIP code as an amount
+var EntityTypes = []string{
+	EntityEmail, EntitySSN, EntityInvoiceNumber, EntityAddress, EntityPhone, EntityDate, EntityAmount,
+}
+
+// Entity is a typed value found in a page's text
+type Entity struct {
+	Type        string      `json:"type"`
+	Text        string      `json:"text"`               // The matched text as it appears on the page
+	Value       string      `json:"value"`              // Normalized value
+	Currency    string      `json:"currency,omitempty"` // ISO code of an amount's currency, when marked
+	Page        int         `json:"page"`
+	PageLabel   string      `json:"page_label,omitempty"`
+	BoundingBox BoundingBox `json:"bounding_box"`
+	Confidence  float64     `json:"confidence"`
+}
+
+// entityRule finds one entity type: its pattern, and a check that normalizes a match and
+// rates it, rejecting it with zero confidence
+type entityRule struct {
+	pattern *regexp.Regexp
+	check   func(text string, match TextMatch) (value, currency string, confidence float64)
+}
+
+// entityNumber is an amount with optional thousands separators and decimals
+const entityNumber = `(?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d+)?`
+
+// entityMonth is an English month name or abbreviation
+const entityMonth = `(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*\.?`
+
+// currencyCodes maps the currency symbols and codes accepted in amounts to ISO codes
+var currencyCodes = map[string]string{
+	"US$": "USD", "$": "USD", "€": "EUR", "£": "GBP", "¥": "JPY", "₹": "INR",
+	"USD": "USD", "EUR": "EUR", "GBP": "GBP", "JPY": "JPY", "CHF": "CHF", "CAD": "CAD", "AUD": "AUD",
+}
+
+// amountCurrencyPattern finds the currency marking an amount
+var amountCurrencyPattern = regexp.MustCompile(`US\$|[$€£¥₹]|USD|EUR|GBP|JPY|CHF|CAD|AUD`)
+
+// monthWordPattern finds the month of a date, with the period of an abbreviation
+var monthWordPattern = regexp.MustCompile(`[A-Za-z]+\.?`)
+
+// ssnContextPattern finds the labels that mark a nearby number as a Social Security number
+var ssnContextPattern = regexp.MustCompile(`(?i)\b(?:SSN|social security)\b`)
+
+// invoiceNumberPattern extracts the number from an invoice number match
+var invoiceNumberPattern = regexp.MustCompile(`[A-Z0-9][A-Z0-9/-]*$`)
+
+// entityRules are the rules of each entity type
+var entityRules = map[string]entityRule{
+	EntityEmail: {
+		pattern: regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}\b`),
+		check: func(_ string, match TextMatch) (string, string, float64) {
+			return strings.ToLower(match.Text), "", 0.95
+		},
+	},
+	EntitySSN: {
+		pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		check: func(text string, match TextMatch) (string, string, float64) {
+			area, group, serial := match.Text[:3], match.Text[4:6], match.Text[7:]
+			if area == "000" || area == "666" || area[0] == '9' || group == "00" || serial == "0000" {
+				return "", "", 0
+			}
+			// A label just before the number makes it certain
+			before := text[max(0, match.Start-30):match.Start]
+			if ssnContextPattern.MatchString(before) {
+				return match.Text, "", 0.98
+			}
+			return match.Text, "", 0.8
+		},
+	},
+	EntityInvoiceNumber: {
+		pattern: regexp.MustCompile(`(?i)\binvoice\s*(?:no\.?|number|num\.?|#|id)?\s*[:#.]?\s*` +
+			`[A-Z0-9][A-Z0-9/-]*\d[A-Z0-9/-]*\b`),
+		check: func(_ string, match TextMatch) (string, string, float64) {
+			return invoiceNumberPattern.FindString(match.Text), "", 0.9
+		},
+	},
+	EntityAddress: {
+		pattern: regexp.MustCompile(`\b\d{1,6}\s+(?:[A-Z][A-Za-z0-9.'-]*\s+){1,4}` +
+			`(?:Street|St|Avenue|Ave|Road|Rd|Boulevard|Blvd|Lane|Ln|Drive|Dr|Court|Ct|Way|Place|Pl|Terrace|` +
+			`Parkway|Pkwy|Highway|Hwy|Square|Sq)\b\.?` +
+			`(?:,?\s+(?:Suite|Ste\.?|Apt\.?|Unit|#)\s*[A-Za-z0-9-]+)?` +
+			`(?:,\s*[A-Z][A-Za-z]+(?:\s+[A-Z][A-Za-z]+)*,?\s+[A-Z]{2}\s+\d{5}(?:-\d{4})?\b)?`),
+		check: func(_ string, match TextMatch) (string, string, float64) {
+			value := strings.Join(strings.Fields(match.Text), " ")
+			// A city, state, and ZIP code complete the address
+			if last, _ := utf8.DecodeLastRuneInString(value); unicode.IsDigit(last) {
+				return value, "", 0.9
+			}
+			return value, "", 0.7
+		},
+	},
+	EntityPhone: {
+		pattern: regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)\s?|\b\d{3}[\s.-])\d{3}[\s.-]\d{4}\b`),
+		check: func(text string, match TextMatch) (string, string, float64) {
+			// The pattern cannot rule out being the end of a longer number
+			if r, _ := utf8.DecodeLastRuneInString(text[:match.Start]); unicode.IsDigit(r) || r == '-' {
+				return "", "", 0
+			}
+			var digits strings.Builder
+			for _, r := range match.Text {
+				if unicode.IsDigit(r) || r == '+' {
+					digits.WriteRune(r)
+				}
+			}
+			if strings.HasPrefix(match.Text, "+") || strings.HasPrefix(match.Text, "(") {
+				return digits.String(), "", 0.85
+			}
+			return digits.String(), "", 0.75
+		},
+	},
+	EntityDate: {
+		pattern: regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b|\b\d{1,2}/\d{1,2}/(?:\d{4}|\d{2})\b|` +
+			`\b` + entityMonth + `\s+\d{1,2},?\s+\d{4}\b|\b\d{1,2}\s+` + entityMonth + `\s+\d{4}\b`),
+		check: func(_ string, match TextMatch) (string, string, float64) {
+			text := strings.Join(strings.Fields(match.Text), " ")
+			// Abbreviations may end in a period, and long ones such as Sept are cut to three letters
+			text = monthWordPattern.ReplaceAllStringFunc(text, func(month string) string {
+				month = strings.TrimSuffix(month, ".")
+				if len(month) > 3 && !isMonthName(month) {
+					month = month[:3]
+				}
+				return month
+			})
+			date, ok := parseCellDate(text, false, &cellEvidence{})
+			if !ok {
+				return "", "", 0
+			}
+			// Numeric dates are read month first, which may be wrong
+			if strings.Contains(text, "/") {
+				return date.Format("2006-01-02"), "", 0.75
+			}
+			return date.Format("2006-01-02"), "", 0.9
+		},
+	},
+	EntityAmount: {
+		pattern: regexp.MustCompile(`(?:US\$|[$€£¥₹])\s?-?` + entityNumber +
+			`|\b` + entityNumber + `\s?(?:USD|EUR|GBP|JPY|CHF|CAD|AUD)\b` +
+			`|\b(?:USD|EUR|GBP|JPY|CHF|CAD|AUD)\s?-?` + entityNumber),
+		check: func(_ string, match TextMatch) (string, string, float64) {
+			dataType, value := InferCellValue(match.Text)
+			if dataType != CellTypeCurrency {
+				return "", "", 0
+			}
+			return value, currencyCodes[amountCurrencyPattern.FindString(match.Text)], 0.9
+		},
+	},
+}
+
+// isMonthName reports whether a word is the full name of a month
+func isMonthName(word string) bool {
+	return slices.ContainsFunc(monthNames, func(name string) bool { return strings.EqualFold(word, name) })
+}
+
+// monthNames are the full English month names
+var monthNames = []string{
+	"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December",
+}
+
+// FindEntities returns the dates, currency amounts, email addresses, phone numbers, street
+// addresses, invoice numbers, and Social Security numbers in a page's text, in text order,
+// with their normalized values and positions. Only the given types are returned; none
+// means all. Text is claimed by at most one entity, in the order of EntityTypes.
+func FindEntities(text *PageText, types []string) []Entity {
+	var entities []Entity
+	var claimed [][2]int
+	for _, entityType := range EntityTypes {
+		if len(types) > 0 && !slices.Contains(types, entityType) {
+			continue
+		}
+		rule := entityRules[entityType]
+		for _, match := range text.Find(rule.pattern) {
+			if overlapsClaimed(claimed, match.Start, match.End) {
+				continue
+			}
+			value, currency, confidence := rule.check(text.Text, match)
+			if confidence == 0 {
+				continue
+			}
+			claimed = append(claimed, [2]int{match.Start, match.End})
+			entities = append(entities, Entity{
+				Type:        entityType,
+				Text:        match.Text,
+				Value:       value,
+				Currency:    currency,
+				BoundingBox: match.BoundingBox,
+				Confidence:  confidence,
+			})
+		}
+	}
+
+	// Each entity claimed the span at its index
+	order := make([]int, len(entities))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return claimed[a][0] - claimed[b][0] })
+	sorted := make([]Entity, len(entities))
+	for i, index := range order {
+		sorted[i] = entities[index]
+	}
+	return sorted
+}
+
+// overlapsClaimed reports whether a span overlaps text already claimed by an entity
+func overlapsClaimed(claimed [][2]int, start, end int) bool {
+	for _, span := range claimed {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
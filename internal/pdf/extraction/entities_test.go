@@ -0,0 +1,94 @@
+package extraction
+
+import (
+	"testing"
+)
+
+// testPageText lays out text on one line with a 5 point wide box per byte
+func testPageText(text string) *PageText {
+	page := &PageText{Text: text}
+	for i := 0; i < len(text); i++ {
+		page.glyphs = append(page.glyphs, pageGlyph{
+			start: i,
+			end:   i + 1,
+			box: BoundingBox{
+				LowerLeft:  Coordinate{X: float64(5 * i), Y: 700},
+				UpperRight: Coordinate{X: float64(5 * (i + 1)), Y: 710},
+				Width:      5,
+				Height:     10,
+			},
+		})
+	}
+	return page
+}
+
+func TestFindEntities(t *testing.T) {
+	tests := []struct {
+		text     string
+		typ      string
+		value    string
+		currency string
+	}{
+		{"Contact Jane.Doe@Example.com today", EntityEmail, "jane.doe@example.com", ""},
+		{"SSN: 123-45-6789", EntitySSN, "123-45-6789", ""},
+		{"Invoice No. INV-2024-0042 issued", EntityInvoiceNumber, "INV-2024-0042", ""},
+		{"Ship to 1600 Pennsylvania Avenue, Washington, DC 20500", EntityAddress,
+			"1600 Pennsylvania Avenue, Washington, DC 20500", ""},
+		{"Call (555) 123-4567 now", EntityPhone, "5551234567", ""},
+		{"Call +1 555-123-4567 now", EntityPhone, "+15551234567", ""},
+		{"Due 2024-03-01.", EntityDate, "2024-03-01", ""},
+		{"Due Sept. 5, 2024", EntityDate, "2024-09-05", ""},
+		{"Due 5 March 2024", EntityDate, "2024-03-05", ""},
+		{"Total $1,234.50 due", EntityAmount, "1234.5", "USD"},
+		{"Total 99.90 EUR due", EntityAmount, "99.9", "EUR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			entities := FindEntities(testPageText(tt.text), nil)
+			if len(entities) != 1 {
+				t.Fatalf("FindEntities() = %+v, want one %s", entities, tt.typ)
+			}
+			entity := entities[0]
+			if entity.Type != tt.typ || entity.Value != tt.value || entity.Currency != tt.currency {
+				t.Errorf("FindEntities() = %s %q (%s), want %s %q (%s)",
+					entity.Type, entity.Value, entity.Currency, tt.typ, tt.value, tt.currency)
+			}
+			if entity.Confidence <= 0 || entity.Confidence > 1 {
+				t.Errorf("confidence = %v, want within (0, 1]", entity.Confidence)
+			}
+		})
+	}
+}
+
+func TestFindEntities_ResolvesOverlaps(t *testing.T) {
+	text := "Invoice #2024-0042 dated 03/15/2024 for $250 from 12 Main St, call 555-867-5309; " +
+		"000-12-3456 is invalid"
+	entities := FindEntities(testPageText(text), nil)
+
+	want := []struct{ typ, text string }{
+		{EntityInvoiceNumber, "Invoice #2024-0042"},
+		{EntityDate, "03/15/2024"},
+		{EntityAmount, "$250"},
+		{EntityAddress, "12 Main St"},
+		{EntityPhone, "555-867-5309"},
+	}
+	if len(entities) != len(want) {
+		t.Fatalf("FindEntities() = %+v, want %d entities", entities, len(want))
+	}
+	for i, w := range want {
+		if entities[i].Type != w.typ || entities[i].Text != w.text {
+			t.Errorf("entity %d = %s %q, want %s %q", i, entities[i].Type, entities[i].Text, w.typ, w.text)
+		}
+	}
+
+	// The amount's box covers its four glyphs
+	amount := entities[2]
+	start := float64(5 * len("Invoice #2024-0042 dated 03/15/2024 for "))
+	if amount.BoundingBox.Width != 20 || amount.BoundingBox.LowerLeft.X != start {
+		t.Errorf("amount box = %+v, want the glyphs of $250", amount.BoundingBox)
+	}
+
+	if only := FindEntities(testPageText(text), []string{EntityDate}); len(only) != 1 || only[0].Type != EntityDate {
+		t.Errorf("FindEntities(date) = %+v, want only the date", only)
+	}
+}
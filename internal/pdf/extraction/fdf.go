@@ -0,0 +1,167 @@
+package extraction
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// FormValue is the value form data gives a field
+type FormValue struct {
+	Name   string   // Fully qualified field name
+	Values []string // One value, or the selections of a multiple-selection list
+}
+
+// fdfReader resolves the objects of an FDF file
+type fdfReader struct {
+	objects map[int]contentObject
+}
+
+// ParseFDF reads the field values of an FDF file. Fields may be listed by fully qualified
+// name or as a tree of partial names, and may be indirect objects. Strings are decoded as
+// PDF text strings; names, such as button states, are taken as they are.
+func ParseFDF(data []byte) ([]FormValue, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%FDF-")) {
+		return nil, fmt.Errorf("not an FDF file: missing %%FDF header")
+	}
+
+	reader := fdfReader{objects: make(map[int]contentObject)}
+	lexer := &contentLexer{data: data}
+	var trailer, root contentObject
+	var previous []contentObject
+	for {
+		obj, ok, err := lexer.next()
+		if err != nil {
+			return nil, fmt.Errorf("invalid FDF: %w", err)
+		}
+		if !ok {
+			break
+		}
+		if obj.kind != contentKeyword {
+			previous = append(previous, obj)
+			continue
+		}
+
+		switch obj.text {
+		case "obj":
+			n := len(previous)
+			value, ok, err := lexer.next()
+			if err != nil || !ok || n < 2 || previous[n-2].kind != contentNumber {
+				return nil, fmt.Errorf("invalid FDF: malformed object at offset %d", lexer.pos)
+			}
+			reader.objects[int(previous[n-2].num)] = value
+			if value.kind == contentDict && root.kind != contentDict {
+				if _, ok := reader.dict(value)["FDF"]; ok {
+					root = value
+				}
+			}
+		case "stream":
+			// Embedded files and scripts are not needed for field values
+			end := bytes.Index(data[lexer.pos:], []byte("endstream"))
+			if end < 0 {
+				return nil, fmt.Errorf("invalid FDF: unterminated stream")
+			}
+			lexer.pos += end + len("endstream")
+		case "trailer":
+			if trailer, ok, err = lexer.next(); err != nil || !ok {
+				return nil, fmt.Errorf("invalid FDF: malformed trailer")
+			}
+		}
+		previous = previous[:0]
+	}
+
+	if trailer.kind == contentDict {
+		if value, ok := reader.dict(trailer)["Root"]; ok && value.kind == contentDict {
+			root = value
+		}
+	}
+	fdf, ok := reader.dict(root)["FDF"]
+	if !ok || fdf.kind != contentDict {
+		return nil, fmt.Errorf("invalid FDF: no FDF dictionary")
+	}
+
+	var values []FormValue
+	var walk func(fields contentObject, parentName string, depth int)
+	walk = func(fields contentObject, parentName string, depth int) {
+		if depth > maxFieldDepth {
+			return
+		}
+		for _, field := range reader.array(fields) {
+			entries := reader.dict(field)
+			name := parentName
+			if partial, ok := entries["T"]; ok && partial.kind == contentString {
+				if name != "" {
+					name += "."
+				}
+				name += decodeTextString(partial.text)
+			}
+			if value, ok := entries["V"]; ok {
+				values = append(values, FormValue{Name: name, Values: reader.values(value)})
+			}
+			if kids, ok := entries["Kids"]; ok {
+				walk(kids, name, depth+1)
+			}
+		}
+	}
+	walk(reader.dict(fdf)["Fields"], "", 0)
+	return values, nil
+}
+
+// resolve returns the object at items[i], following an indirect reference, and the index
+// after it
+func (r fdfReader) resolve(items []contentObject, i int) (contentObject, int) {
+	if i+2 < len(items) && items[i].kind == contentNumber && items[i+1].kind == contentNumber &&
+		items[i+2].kind == contentKeyword && items[i+2].text == "R" {
+		return r.objects[int(items[i].num)], i + 3
+	}
+	return items[i], i + 1
+}
+
+// dict returns the entries of a dictionary; anything else has none
+func (r fdfReader) dict(obj contentObject) map[string]contentObject {
+	entries := make(map[string]contentObject)
+	if obj.kind != contentDict {
+		return entries
+	}
+	for i := 0; i+1 < len(obj.items); {
+		key := obj.items[i]
+		var value contentObject
+		value, i = r.resolve(obj.items, i+1)
+		if key.kind == contentName {
+			entries[key.text] = value
+		}
+	}
+	return entries
+}
+
+// array returns the elements of an array; anything else has none
+func (r fdfReader) array(obj contentObject) []contentObject {
+	if obj.kind != contentArray {
+		return nil
+	}
+	var elements []contentObject
+	for i := 0; i < len(obj.items); {
+		var element contentObject
+		element, i = r.resolve(obj.items, i)
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+// values returns a field value as text: a string or name, or each string of an array
+func (r fdfReader) values(obj contentObject) []string {
+	switch obj.kind {
+	case contentString:
+		return []string{decodeTextString(obj.text)}
+	case contentName:
+		return []string{obj.text}
+	case contentArray:
+		var values []string
+		for _, element := range r.array(obj) {
+			if element.kind == contentString {
+				values = append(values, decodeTextString(element.text))
+			}
+		}
+		return values
+	}
+	return nil
+}
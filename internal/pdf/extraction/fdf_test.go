@@ -0,0 +1,36 @@
+package extraction
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFDF(t *testing.T) {
+	// Fields given by qualified name and as a tree, with an indirect field, a button state,
+	// a UTF-16 string, a multiple selection, and a stream to skip
+	data := "%FDF-1.2\n%\xe2\xe3\xcf\xd3\n" +
+		"1 0 obj\n<< /FDF << /F (form.pdf) /Fields [<< /T (person) /Kids [2 0 R << /T (agree) /V /Yes >>] >>" +
+		" << /T (address.city) /V (Paris) >> << /T (colors) /V [(Red) (Green)] >>] >> >>\nendobj\n" +
+		"2 0 obj\n<< /T (name) /V <FEFF0041006400E1> >>\nendobj\n" +
+		"3 0 obj\n<< /Length 9 >>\nstream\n/V (junk)\nendstream\nendobj\n" +
+		"trailer\n<< /Root 1 0 R >>\n%%EOF\n"
+
+	values, err := ParseFDF([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseFDF failed: %v", err)
+	}
+	var got []string
+	for _, value := range values {
+		got = append(got, value.Name+"="+strings.Join(value.Values, "|"))
+	}
+	want := "person.name=Adá, person.agree=Yes, address.city=Paris, colors=Red|Green"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ", "))
+	}
+
+	for _, invalid := range []string{"%PDF-1.4\n", "%FDF-1.2\n1 0 obj\n<< /Fields [] >>\nendobj\n"} {
+		if _, err := ParseFDF([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package extraction
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Roles of figure regions
+const (
+	RoleFigure   = "figure"
+	RoleChart    = "chart"
+	RoleEquation = "equation"
+)
+
+// Figure detection constants
+const (
+	// minFigureSize is the smallest width and height, in points, of an image taken for a
+	// figure; smaller images are icons, bullets, and rules
+	minFigureSize = 12.0
+	// minDrawingSize is the smallest width and height, in points, of a drawing of paths
+	minDrawingSize = 36.0
+	// minDrawingPaths is how many paths a drawing needs
+	minDrawingPaths = 3
+	// figureClusterGap is how close, in points, paths and images lie to belong to one region
+	figureClusterGap = 8.0
+	// captionGap is how far, in points, a caption may lie above or below its region
+	captionGap = 24.0
+	// minChartBars is how many filled bars standing on one baseline make a bar chart
+	minChartBars = 3
+	// chartAxisShare is the share of a region's width and height its axes span
+	chartAxisShare = 0.5
+	// maxEquationHeight is the tallest, in points, a displayed equation is taken to be
+	maxEquationHeight = 60.0
+	// pageBackgroundShare is the share of the page a rectangle covers to be its background
+	pageBackgroundShare = 0.9
+	// captionedConfidence and uncaptionedConfidence rate regions with and without a caption
+	captionedConfidence   = 0.9
+	uncaptionedConfidence = 0.6
+)
+
+var (
+	// figureCaption matches the opening of a caption, such as "Figure 3:" or "Eq. (2)"
+	figureCaption = regexp.MustCompile(
+		`^(?i)(fig(?:ure)?|chart|graph|plot|diagram|exhibit|illustration|eq(?:uation)?)\.?\s*\(?\d+`)
+	// equationNumber matches the number set beside a displayed equation, such as "(3)" or "(2.1)"
+	equationNumber = regexp.MustCompile(`^\(\d+(?:\.\d+)*[a-z]?\)$`)
+)
+
+// figureRegion is an area of a page drawn with images and paths
+type figureRegion struct {
+	box     BoundingBox
+	images  int
+	vectors []VectorElement
+}
+
+// figure is a region found to be a figure, chart, or equation
+type figure struct {
+	figureRegion
+	role       string
+	caption    string
+	confidence float64
+}
+
+// detectFigures finds the figures, charts, and rendered equations of a page. Regions are
+// the images the page places and the clusters of paths it draws; clusters of rules only,
+// such as table borders, are left out unless captioned. A caption beside the region names
+// its role, and otherwise axes or bars make a chart and a number such as "(3)" beside a
+// short region an equation.
+func detectFigures(page pdf.Page) ([]figure, error) {
+	vectors, images, err := interpretPageDrawing(page)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) == 0 && len(images) == 0 {
+		return nil, nil
+	}
+	words, err := PageWords(page)
+	if err != nil {
+		return nil, err
+	}
+	var rows []pageRow
+	for _, row := range wordRows(words) {
+		texts := make([]string, len(row))
+		box := row[0].BoundingBox
+		for i, word := range row {
+			texts[i] = word.Text
+			box = unionBoxes(box, word.BoundingBox)
+		}
+		rows = append(rows, pageRow{text: strings.Join(texts, " "), box: box})
+	}
+
+	media, hasMedia := pageBox(InheritedAttribute(page.V, "MediaBox"))
+	var regions []figureRegion
+	for _, image := range images {
+		if box := image.BoundingBox; box.Width >= minFigureSize && box.Height >= minFigureSize {
+			regions = addToRegions(regions, figureRegion{box: box, images: 1})
+		}
+	}
+	for _, vector := range vectors {
+		box := vectorBounds(vector)
+		if hasMedia && box.Width >= media.Width*pageBackgroundShare && box.Height >= media.Height*pageBackgroundShare {
+			continue
+		}
+		regions = addToRegions(regions, figureRegion{box: box, vectors: []VectorElement{vector}})
+	}
+
+	var figures []figure
+	for _, region := range regions {
+		found := figure{figureRegion: region, confidence: uncaptionedConfidence}
+		if caption, ok := regionCaption(region.box, rows); ok {
+			found.caption = caption
+			found.confidence = captionedConfidence
+		}
+		found.role = figureRole(found, rows)
+		if found.role == "" {
+			continue
+		}
+		figures = append(figures, found)
+	}
+	sort.SliceStable(figures, func(a, b int) bool {
+		return figures[a].box.UpperRight.Y > figures[b].box.UpperRight.Y
+	})
+	return figures, nil
+}
+
+// pageRow is a line of text of a page
+type pageRow struct {
+	text string
+	box  BoundingBox
+}
+
+// addToRegions adds a region to the regions, merging it with every region it comes within
+// the cluster gap of, as it grows
+func addToRegions(regions []figureRegion, region figureRegion) []figureRegion {
+	for merged := true; merged; {
+		merged = false
+		kept := regions[:0]
+		for _, other := range regions {
+			if boxesWithin(region.box, other.box, figureClusterGap) {
+				region.box = unionBoxes(region.box, other.box)
+				region.images += other.images
+				region.vectors = append(region.vectors, other.vectors...)
+				merged = true
+				continue
+			}
+			kept = append(kept, other)
+		}
+		regions = kept
+	}
+	return append(regions, region)
+}
+
+// boxesWithin reports whether two boxes lie within a distance of each other
+func boxesWithin(a, b BoundingBox, gap float64) bool {
+	return a.LowerLeft.X-gap <= b.UpperRight.X && b.LowerLeft.X-gap <= a.UpperRight.X &&
+		a.LowerLeft.Y-gap <= b.UpperRight.Y && b.LowerLeft.Y-gap <= a.UpperRight.Y
+}
+
+// regionCaption returns the caption just below a region, or else just above it, that
+// overlaps it horizontally
+func regionCaption(box BoundingBox, rows []pageRow) (string, bool) {
+	var above string
+	for _, row := range rows {
+		if row.box.UpperRight.X < box.LowerLeft.X || row.box.LowerLeft.X > box.UpperRight.X ||
+			!figureCaption.MatchString(row.text) {
+			continue
+		}
+		if gap := box.LowerLeft.Y - row.box.UpperRight.Y; gap >= -1 && gap <= captionGap {
+			return row.text, true
+		}
+		if gap := row.box.LowerLeft.Y - box.UpperRight.Y; gap >= -1 && gap <= captionGap && above == "" {
+			above = row.text
+		}
+	}
+	return above, above != ""
+}
+
+// figureRole names what a region shows, or returns "" when it is not a figure. Regions of
+// paths alone need a caption, or bars or curved and slanted lines, to be a figure; rules
+// alone are table borders and underlines.
+func figureRole(found figure, rows []pageRow) string {
+	var bars, drawn int
+	for _, vector := range found.vectors {
+		switch {
+		case isBar(vector):
+			bars++
+		case isDrawn(vector):
+			drawn++
+		}
+	}
+
+	if found.caption != "" {
+		switch label := strings.ToLower(figureCaption.FindStringSubmatch(found.caption)[1]); {
+		case strings.HasPrefix(label, "eq"):
+			return RoleEquation
+		case label == "chart" || label == "graph" || label == "plot" || hasChartShape(found.figureRegion, bars):
+			return RoleChart
+		default:
+			return RoleFigure
+		}
+	}
+
+	numbered := found.box.Height <= maxEquationHeight && hasEquationNumber(found.box, rows)
+	drawing := len(found.vectors) >= minDrawingPaths && (drawn > 0 || bars >= minChartBars) &&
+		found.box.Width >= minDrawingSize && found.box.Height >= minDrawingSize
+	switch {
+	case numbered && (found.images > 0 || drawn > 0):
+		return RoleEquation
+	case found.images == 0 && !drawing:
+		return ""
+	case hasChartShape(found.figureRegion, bars):
+		return RoleChart
+	default:
+		return RoleFigure
+	}
+}
+
+// isBar reports whether a path is a filled rectangle thicker than a rule
+func isBar(vector VectorElement) bool {
+	box := vectorBounds(vector)
+	return vector.Type == VectorTypeRect && vector.FillColor != "" &&
+		box.Width > rulingThickness && box.Height > rulingThickness
+}
+
+// isDrawn reports whether a path curves or runs at a slant, as the lines of drawings, plots,
+// and glyph outlines do and table borders never do
+func isDrawn(vector VectorElement) bool {
+	for _, cmd := range vector.Commands {
+		if cmd.Command == "curveto" {
+			return true
+		}
+		if cmd.Command == "lineto" && len(cmd.Points) == 2 {
+			dx := math.Abs(cmd.Points[1].X - cmd.Points[0].X)
+			dy := math.Abs(cmd.Points[1].Y - cmd.Points[0].Y)
+			if dx > rulingThickness && dy > rulingThickness {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasChartShape reports whether a region has a horizontal and a vertical axis spanning
+// most of it, or bars standing on one baseline
+func hasChartShape(region figureRegion, bars int) bool {
+	if bars >= minChartBars {
+		baselines := make(map[int]int)
+		for _, vector := range region.vectors {
+			if isBar(vector) {
+				baselines[int(math.Round(vectorBounds(vector).LowerLeft.Y))]++
+			}
+		}
+		for _, count := range baselines {
+			if count >= minChartBars {
+				return true
+			}
+		}
+	}
+
+	rulings := collectRulings(region.vectors)
+	spans := func(segments []rulingSegment, length float64) bool {
+		for _, segment := range segments {
+			if segment.end-segment.start >= length*chartAxisShare {
+				return true
+			}
+		}
+		return false
+	}
+	return spans(rulings.horizontal, region.box.Width) && spans(rulings.vertical, region.box.Height)
+}
+
+// hasEquationNumber reports whether a number such as "(3)" stands to the right of a region,
+// level with it
+func hasEquationNumber(box BoundingBox, rows []pageRow) bool {
+	for _, row := range rows {
+		middle := (row.box.LowerLeft.Y + row.box.UpperRight.Y) / 2
+		if row.box.LowerLeft.X >= box.UpperRight.X && middle >= box.LowerLeft.Y && middle <= box.UpperRight.Y &&
+			equationNumber.MatchString(strings.TrimSpace(row.text)) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractFiguresFromPage marks the figures, charts, and equations of a page as image
+// elements with the role found
+func (e *DefaultEngine) extractFiguresFromPage(
+	page pdf.Page, pageNum int, config ExtractionConfig,
+) ([]ContentElement, []error) {
+	figures, err := detectFigures(page)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	elements := make([]ContentElement, len(figures))
+	for i, found := range figures {
+		elements[i] = ContentElement{
+			ID:          e.generateID("figure", pageNum, i),
+			Type:        ContentTypeImage,
+			Role:        found.role,
+			PageNumber:  pageNum,
+			BoundingBox: found.box,
+			Content: FigureElement{
+				Caption: found.caption,
+				Images:  found.images,
+				Vectors: len(found.vectors),
+			},
+			Confidence: found.confidence,
+		}
+	}
+	return elements, nil
+}
@@ -0,0 +1,80 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// writeFiguresPDF writes a page with a captioned image, a bar chart, a ruled table, and a
+// numbered equation drawn as paths
+func writeFiguresPDF(t *testing.T) string {
+	t.Helper()
+
+	content := "" +
+		// An image with its caption below
+		"q 200 0 0 100 72 600 cm /Im1 Do Q\n" +
+		"BT /F1 9 Tf 72 585 Td (Figure 1: Site plan) Tj ET\n" +
+		// A bar chart with axes
+		"72 300 m 372 300 l S 72 300 m 72 480 l S\n" +
+		"90 300 40 60 re f 150 300 40 120 re f 210 300 40 90 re f 270 300 40 150 re f\n" +
+		"BT /F1 9 Tf 72 285 Td (Figure 2: Revenue by year) Tj ET\n" +
+		// A ruled table without a caption
+		"400 400 150 80 re S 400 440 m 550 440 l S 475 400 m 475 480 l S\n" +
+		"BT /F1 9 Tf 410 455 Td (Region) Tj ET BT /F1 9 Tf 485 455 Td (Sales) Tj ET\n" +
+		// An equation drawn as outlines, with its number
+		"200 150 m 220 180 240 120 260 150 c S 270 150 m 300 170 l S\n" +
+		"BT /F1 10 Tf 500 155 Td (\\(1\\)) Tj ET"
+
+	image := "<< /Type /XObject /Subtype /Image /Width 2 /Height 2 /ColorSpace /DeviceGray " +
+		"/BitsPerComponent 8 /Length 4 >>\nstream\n\x00\x00\x00\x00\nendstream"
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] " +
+			"/Resources << /Font << /F1 3 0 R >> /XObject << /Im1 6 0 R >> >> /Contents 5 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+		image,
+	}
+	return writeRawPDF(t, "figures.pdf", objects)
+}
+
+func TestExtract_DetectsFigures(t *testing.T) {
+	result, err := NewEngine().Extract(context.Background(), ExtractionRequest{
+		FilePath: writeFiguresPDF(t), Config: ExtractionConfig{Mode: ModeStructured, ExtractImages: true},
+	})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	var figures []ContentElement
+	for _, element := range result.Elements {
+		if _, ok := element.Content.(FigureElement); ok {
+			figures = append(figures, element)
+		}
+	}
+	want := []struct {
+		role    string
+		caption string
+		images  int
+	}{
+		{RoleFigure, "Figure 1: Site plan", 1},
+		{RoleChart, "Figure 2: Revenue by year", 0},
+		{RoleEquation, "", 0},
+	}
+	if len(figures) != len(want) {
+		t.Fatalf("Extract() figures = %+v, want %d", figures, len(want))
+	}
+	for i, w := range want {
+		figure := figures[i]
+		content := figure.Content.(FigureElement)
+		if figure.Type != ContentTypeImage || figure.Role != w.role || content.Caption != w.caption ||
+			content.Images != w.images {
+			t.Errorf("figure %d = %+v, want a %s captioned %q with %d images", i, figure, w.role, w.caption, w.images)
+		}
+	}
+	if box := figures[0].BoundingBox; box.LowerLeft.X != 72 || box.LowerLeft.Y != 600 || box.Width != 200 {
+		t.Errorf("image figure at %+v, want where the image is placed", box)
+	}
+}
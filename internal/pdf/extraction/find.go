@@ -0,0 +1,93 @@
+package extraction
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// TextMatch is an occurrence of a pattern in a page's text
+type TextMatch struct {
+	Text        string        `json:"text"`
+	Start       int           `json:"start"` // Byte offsets of the match within the page text
+	End         int           `json:"end"`
+	BoundingBox BoundingBox   `json:"bounding_box"` // Union of Rects
+	Rects       []BoundingBox `json:"rects"`        // One box per line the match spans, for highlighting
+}
+
+// PageText is a page's words in reading order joined by single spaces, along with the
+// glyph positions needed to locate matches in it
+type PageText struct {
+	Text   string
+	glyphs []pageGlyph
+}
+
+// pageGlyph places one glyph's text within the page text
+type pageGlyph struct {
+	start, end int
+	line       int
+	box        BoundingBox
+}
+
+// NewPageText lays out a page's glyphs into searchable text
+func NewPageText(page pdf.Page) (*PageText, error) {
+	glyphs, err := pageGlyphs(page)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	text := &PageText{}
+	for i, word := range groupGlyphsIntoWords(glyphs) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		offset := b.Len()
+		b.WriteString(word.Text)
+		for _, g := range word.Glyphs {
+			text.glyphs = append(text.glyphs, pageGlyph{
+				start: offset + g.Start,
+				end:   offset + g.End,
+				line:  word.Line,
+				box:   g.Box,
+			})
+		}
+	}
+	text.Text = b.String()
+	return text, nil
+}
+
+// Find returns every occurrence of pattern with the boxes of the glyphs it covers. A match
+// running over several lines has one rectangle per line.
+func (t *PageText) Find(pattern *regexp.Regexp) []TextMatch {
+	var matches []TextMatch
+	for _, loc := range pattern.FindAllStringIndex(t.Text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		match := TextMatch{Text: t.Text[loc[0]:loc[1]], Start: loc[0], End: loc[1]}
+		line := -1
+		for _, g := range t.glyphs {
+			if g.end <= loc[0] || g.start >= loc[1] {
+				continue
+			}
+			if g.line != line {
+				match.Rects = append(match.Rects, g.box)
+				line = g.line
+			} else {
+				last := &match.Rects[len(match.Rects)-1]
+				*last = unionBoxes(*last, g.box)
+			}
+		}
+		if len(match.Rects) == 0 {
+			continue
+		}
+		match.BoundingBox = match.Rects[0]
+		for _, rect := range match.Rects[1:] {
+			match.BoundingBox = unionBoxes(match.BoundingBox, rect)
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
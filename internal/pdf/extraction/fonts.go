@@ -0,0 +1,186 @@
+package extraction
+
+import (
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// codeMapping classifies how a character code was mapped to Unicode
+type codeMapping int
+
+const (
+	mappedUnicode codeMapping = iota // By a ToUnicode CMap, a Unicode or legacy CMap, or a named encoding
+	mappedGuess                      // Read as PDFDocEncoding for lack of an encoding
+	mappedNone                       // Not mapped; extracted as U+FFFD
+)
+
+// standardFonts are the non-symbolic standard 14 fonts, whose codes follow the standard
+// encoding when a font dictionary gives none
+var standardFonts = map[string]bool{
+	"Courier": true, "Courier-Bold": true, "Courier-Oblique": true, "Courier-BoldOblique": true,
+	"Helvetica": true, "Helvetica-Bold": true, "Helvetica-Oblique": true, "Helvetica-BoldOblique": true,
+	"Times-Roman": true, "Times-Bold": true, "Times-Italic": true, "Times-BoldItalic": true,
+}
+
+// decodedCode is one character code of a string shown with a font
+type decodedCode struct {
+	code    string
+	text    string
+	width   float64 // Advance for a font size of one, in text space units
+	mapping codeMapping
+}
+
+// fontDecoder maps the character codes of a font to Unicode. Composite (Type0) fonts are
+// read through their ToUnicode CMap, falling back to the Unicode meaning of a predefined
+// encoding CMap; simple fonts through their ToUnicode CMap, falling back to the parser's
+// encodings.
+type fontDecoder struct {
+	name      string // Base font
+	composite bool
+	toUnicode *toUnicodeMap
+	cmap      *predefinedCMap // Predefined encoding of a composite font
+	codespace []codeRange     // Codespace of an embedded encoding CMap
+	simple    pdf.TextEncoding
+	trusted   bool // The simple encoding is known rather than assumed
+	metrics   *fontMetrics
+}
+
+// newFontDecoder reads the encoding of a font dictionary. Damaged encodings leave the
+// decoder mapping nothing rather than failing, since the parser panics on them.
+func newFontDecoder(font pdf.Value) (decoder *fontDecoder) {
+	name := font.Key("BaseFont").Name()
+	decoder = &fontDecoder{name: name, composite: font.Key("Subtype").Name() == "Type0"}
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Debug("font encoding unreadable", "font", name, "error", r)
+			decoder.simple = nil
+		}
+	}()
+
+	decoder.metrics = newFontMetrics(font)
+	if toUnicode := font.Key("ToUnicode"); toUnicode.Kind() == pdf.Stream {
+		if m, err := parseToUnicode(toUnicode); err == nil {
+			decoder.toUnicode = m
+		} else {
+			logger.Debug("ToUnicode CMap unreadable", "font", name, "error", err)
+		}
+	}
+
+	encoding := font.Key("Encoding")
+	if decoder.composite {
+		switch encoding.Kind() {
+		case pdf.Name:
+			decoder.cmap = lookupPredefinedCMap(encoding.Name())
+		case pdf.Stream:
+			if data, err := readStream(encoding); err == nil {
+				if ops, err := parseContent(data); err == nil {
+					for _, op := range ops {
+						if op.operator == "endcodespacerange" {
+							decoder.codespace = append(decoder.codespace, readCodespace(op.operands)...)
+						}
+					}
+				}
+			}
+		}
+		return decoder
+	}
+
+	switch encoding.Kind() {
+	case pdf.Name, pdf.Dict:
+		decoder.trusted = true
+	default:
+		_, base, _ := strings.Cut(name, "+")
+		decoder.trusted = standardFonts[name] || standardFonts[base]
+	}
+	if decoder.toUnicode == nil || decoder.trusted {
+		// Without an encoding the parser reads the ToUnicode CMap itself, so it is only
+		// asked when the CMap is missing or a named encoding backs it
+		decoder.simple = pdf.Font{V: font}.Encoder()
+	}
+	return decoder
+}
+
+// decode splits a shown string into character codes and maps each to Unicode
+func (d *fontDecoder) decode(raw string) []decodedCode {
+	var codes []decodedCode
+	for len(raw) > 0 {
+		n := d.codeLength(raw)
+		code := decodedCode{code: raw[:n]}
+		raw = raw[n:]
+
+		if text, ok := d.toUnicode.lookup(code.code); ok {
+			code.text = text
+		} else {
+			code.text, code.mapping = d.fallback(code.code)
+		}
+		code.width = d.width(code.code)
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// codeLength returns the length of the code at the start of raw
+func (d *fontDecoder) codeLength(raw string) int {
+	if !d.composite {
+		return 1
+	}
+	if d.cmap != nil {
+		return max(d.cmap.length(raw), 1)
+	}
+	if n := splitCode(raw, d.codespace); n > 0 {
+		return n
+	}
+	if d.toUnicode != nil {
+		if n := splitCode(raw, d.toUnicode.codespace); n > 0 {
+			return n
+		}
+	}
+	return min(2, len(raw))
+}
+
+// fallback maps a code the ToUnicode CMap does not cover
+func (d *fontDecoder) fallback(code string) (string, codeMapping) {
+	if d.composite {
+		if d.cmap != nil && d.cmap.unicode != nil {
+			if text, ok := d.cmap.unicode(code); ok {
+				return text, mappedUnicode
+			}
+		}
+		return "�", mappedNone
+	}
+	if d.simple == nil {
+		return "�", mappedNone
+	}
+	text := d.simple.Decode(code)
+	if text == "" || strings.ContainsRune(text, '�') {
+		return "�", mappedNone
+	}
+	if !d.trusted {
+		return text, mappedGuess
+	}
+	return text, mappedUnicode
+}
+
+// width returns the advance of a code. The widths of composite fonts are indexed by CID,
+// which equals the code only under the Identity CMaps and embedded CMaps that are
+// effectively identity; other predefined CMaps use the default width.
+func (d *fontDecoder) width(code string) float64 {
+	if d.metrics == nil {
+		return fallbackGlyphWidth / 1000
+	}
+	if d.composite && d.cmap != nil && !d.cmap.identity {
+		return d.metrics.defaultWidth * d.metrics.scale
+	}
+	return d.metrics.width([]byte(code))
+}
+
+// Decode implements the parser's TextEncoding, so the decoder can stand in for a font's
+// encoding when only text is needed
+func (d *fontDecoder) Decode(raw string) string {
+	var b strings.Builder
+	for _, code := range d.decode(raw) {
+		b.WriteString(code.text)
+	}
+	return b.String()
+}
@@ -0,0 +1,753 @@
+package extraction
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// maxFieldDepth limits how deep the field tree of an interactive form is followed
+const maxFieldDepth = 32
+
+// Form field types
+const (
+	FieldTypeText      = "text"
+	FieldTypeCheckbox  = "checkbox"
+	FieldTypeRadio     = "radio"
+	FieldTypeButton    = "button" // Push buttons, which hold no value
+	FieldTypeChoice    = "choice"
+	FieldTypeSignature = "signature"
+	FieldTypeGroup     = "group" // Non-terminal fields, which only group their kids and hold no value
+)
+
+// Form field flags (the /Ff entry)
+const (
+	FieldFlagReadOnly        = 1 << 0
+	FieldFlagRequired        = 1 << 1
+	FieldFlagNoExport        = 1 << 2
+	FieldFlagMultiline       = 1 << 12 // Text fields
+	FieldFlagPassword        = 1 << 13 // Text fields
+	FieldFlagRadio           = 1 << 15
+	FieldFlagPushButton      = 1 << 16
+	FieldFlagCombo           = 1 << 17
+	FieldFlagEdit            = 1 << 18
+	FieldFlagSort            = 1 << 19 // Choice fields: options are sorted for display
+	FieldFlagFileSelect      = 1 << 20 // Text fields
+	FieldFlagMultiSelect     = 1 << 21
+	FieldFlagDoNotSpellCheck = 1 << 22 // Text and choice fields
+	FieldFlagComb            = 1 << 24 // Text fields with a /MaxLen
+	FieldFlagRichText        = 1 << 25 // Text fields
+)
+
+// Signature flags (the AcroForm /SigFlags entry)
+const (
+	SigFlagSignaturesExist = 1 << 0
+	SigFlagAppendOnly      = 1 << 1 // Changes must be appended, or signatures are invalidated
+)
+
+// Lock actions of a signature field's /Lock dictionary: the fields locked once it is signed
+const (
+	LockAll     = "All"
+	LockInclude = "Include"
+	LockExclude = "Exclude"
+)
+
+// DocMDP permissions granted by a certification signature
+const (
+	CertifyNoChanges   = 1
+	CertifyFormFilling = 2 // Filling in forms and signing
+	CertifyAnnotations = 3 // Also annotating
+)
+
+// Field triggers of the /AA additional-actions dictionary, named for their purpose
+const (
+	TriggerKeystroke = "keystroke" // K: checks or changes each keystroke
+	TriggerFormat    = "format"    // F: formats the value for display
+	TriggerValidate  = "validate"  // V: checks the value once entered
+	TriggerCalculate = "calculate" // C: computes the value from other fields
+)
+
+// fieldTriggers maps the keys of a field's /AA dictionary to their triggers
+var fieldTriggers = map[string]string{
+	"K": TriggerKeystroke,
+	"F": TriggerFormat,
+	"V": TriggerValidate,
+	"C": TriggerCalculate,
+}
+
+// maxScriptSize limits how much of a JavaScript action's source is read
+const maxScriptSize = 1 << 20
+
+// maxActionChain limits how many actions chained by /Next are followed
+const maxActionChain = 16
+
+// FormField is a field of a document's interactive form. A terminal field holds a value and
+// is shown by one or more widget annotations; a non-terminal field, of type FieldTypeGroup,
+// only names the fields below it.
+type FormField struct {
+	Name    string      // Fully qualified name: the partial names from the root joined by periods
+	Parent  string      // Fully qualified name of the parent field, empty for a root field
+	Kids    []string    // Fully qualified names of the child fields, not counting widgets
+	Type    string      // One of the FieldType constants
+	Value   string      // Text, selected option, or button state
+	Values  []string    // Selections of a multiple-selection list
+	Options []string    // Export values of a choice field, or the on states of a checkbox or radio button
+	Flags   int         // FieldFlag bits, including inherited ones
+	Field   pdf.Value   // The field dictionary
+	Widgets []pdf.Value // Widget annotations showing the field, which may be the field itself
+	Pages   []int       // Page of each widget, 0 for a widget on no page
+
+	// Checkbox and radio button state
+	Checked     bool           // Whether the button is on
+	ExportValue string         // Export value of the on button, empty when off
+	Buttons     []ButtonWidget // The field's widgets in order, such as the kids of a radio group
+
+	Signature *SignatureInfo // State of a signature field
+
+	// Choice field options with the text each shows and whether it is selected, and the
+	// field's layout: a combo box rather than a list, editable to hold text of the user's
+	// own, sorted, or allowing several selections
+	Choices     []ChoiceOption
+	Combo       bool
+	Editable    bool
+	Sorted      bool
+	MultiSelect bool
+
+	// Text field layout. A comb field spaces its value evenly over CombCells cells, one
+	// character each; a rich text field holds styled text in /RV, read into RichText, whose
+	// plain text is Value.
+	Multiline       bool
+	Password        bool
+	DoNotSpellCheck bool // Also set on choice fields
+	CombCells       int
+	RichText        []RichTextSpan
+
+	// JavaScript source of the field's additional actions by trigger, kept as written and
+	// never run. A field with a calculate action, or listed in the form's calculation
+	// order, shows a computed value that /V may not have caught up with.
+	Actions    map[string]string
+	Calculated bool
+}
+
+// SignatureInfo describes a signature field: whether it is signed, what the signer stated,
+// and the fields it locks
+type SignatureInfo struct {
+	Signed      bool      `json:"signed"`
+	Signer      string    `json:"signer,omitempty"` // As recorded in the signature, not verified
+	Reason      string    `json:"reason,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	ContactInfo string    `json:"contact_info,omitempty"`
+	SigningTime time.Time `json:"signing_time,omitempty"`
+	Filter      string    `json:"filter,omitempty"`     // Signature handler, e.g. Adobe.PPKLite
+	SubFilter   string    `json:"sub_filter,omitempty"` // Encoding, e.g. adbe.pkcs7.detached
+
+	LockAction string   `json:"lock_action,omitempty"` // One of the Lock constants; empty locks no fields
+	LockFields []string `json:"lock_fields,omitempty"` // Fields named by an Include or Exclude lock
+
+	// A certification signature limits changes to the document to its DocMDP permissions
+	Certifies   bool `json:"certifies,omitempty"`
+	Permissions int  `json:"permissions,omitempty"` // One of the Certify constants
+}
+
+// ChoiceOption is an option of a choice field. /Opt gives an option as its text, or as a
+// pair of the export value the field holds and the text it shows.
+type ChoiceOption struct {
+	ExportValue string `json:"export_value"`
+	Display     string `json:"display"` // The export value when the option shows no other text
+	Selected    bool   `json:"selected,omitempty"`
+}
+
+// ButtonWidget is one widget of a checkbox or radio button field. Each radio button in a
+// group is a widget with its own on state.
+type ButtonWidget struct {
+	State       string // Appearance state the widget shows when on; empty without an appearance
+	ExportValue string // Value the widget stands for: its /Opt entry, or else its state
+	Checked     bool   // Whether the widget shows its on state
+}
+
+// HasValue reports whether a field holds form data; push buttons, signatures, and groups do
+// not
+func (f FormField) HasValue() bool {
+	return f.Type != FieldTypeButton && f.Type != FieldTypeSignature && f.Type != FieldTypeGroup
+}
+
+// ReadFormFields returns the terminal fields of a document's interactive form in field
+// tree order
+func ReadFormFields(reader *pdf.Reader) []FormField {
+	return readFormFields(reader, false)
+}
+
+// ReadFormFieldTree returns every field of a document's interactive form in field tree
+// order, each group before the fields below it
+func ReadFormFieldTree(reader *pdf.Reader) []FormField {
+	return readFormFields(reader, true)
+}
+
+// readFormFields walks the field tree of a document's interactive form, listing groups
+// when asked to. Names are qualified by the field's ancestors, following a root's /Parent
+// chain when a writer lists a kid among the form's top-level fields. Such kids are walked
+// after the other roots, so those reached from their parents keep their place in the tree.
+func readFormFields(reader *pdf.Reader, groups bool) []FormField {
+	var fields []FormField
+	seen := make(map[ObjectRef]bool)
+
+	var walk func(node pdf.Value, parentName string, depth int)
+	walk = func(node pdf.Value, parentName string, depth int) {
+		if node.Kind() != pdf.Dict || depth > maxFieldDepth {
+			return
+		}
+		if ref := RefOf(node); ref.ID != 0 {
+			if seen[ref] {
+				return
+			}
+			seen[ref] = true
+		}
+
+		name := qualifiedName(parentName, node)
+
+		// Kids without a partial name are the field's widgets; the others are fields
+		var widgets, fieldKids []pdf.Value
+		var kidNames []string
+		kids := node.Key("Kids")
+		for i := 0; i < kids.Len(); i++ {
+			kid := kids.Index(i)
+			if kid.Key("T").IsNull() && kid.Key("FT").IsNull() {
+				widgets = append(widgets, kid)
+				continue
+			}
+			fieldKids = append(fieldKids, kid)
+			kidNames = append(kidNames, qualifiedName(name, kid))
+		}
+
+		if len(fieldKids) > 0 && len(widgets) == 0 {
+			if groups {
+				fields = append(fields, FormField{
+					Name: name, Parent: parentName, Kids: kidNames, Type: FieldTypeGroup, Field: node,
+				})
+			}
+			for _, kid := range fieldKids {
+				walk(kid, name, depth+1)
+			}
+			return
+		}
+		for _, kid := range fieldKids {
+			walk(kid, name, depth+1)
+		}
+		if node.Key("Subtype").Name() == "Widget" {
+			widgets = append([]pdf.Value{node}, widgets...)
+		}
+		field := newFormField(node, name, widgets)
+		field.Parent, field.Kids = parentName, kidNames
+		fields = append(fields, field)
+	}
+
+	roots := reader.Trailer().Key("Root").Key("AcroForm").Key("Fields")
+	for _, kids := range []bool{false, true} {
+		for i := 0; i < roots.Len(); i++ {
+			root := roots.Index(i)
+			if (root.Key("Parent").Kind() == pdf.Dict) == kids {
+				walk(root, fieldName(root.Key("Parent")), 0)
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		calculated := make(map[string]bool)
+		for _, name := range CalculationOrder(reader) {
+			calculated[name] = true
+		}
+		for i := range fields {
+			fields[i].Calculated = fields[i].Calculated || calculated[fields[i].Name]
+		}
+
+		numbering := NewPageNumbering(reader)
+		annotations := annotationPages(numbering)
+		for i := range fields {
+			for _, widget := range fields[i].Widgets {
+				fields[i].Pages = append(fields[i].Pages, widgetPage(widget, numbering, annotations))
+			}
+		}
+	}
+	return fields
+}
+
+// widgetField reads the terminal field a widget annotation shows: the widget itself when
+// field and widget are merged, or else its parent
+func widgetField(widget pdf.Value) FormField {
+	node := widget
+	if widget.Key("T").IsNull() && widget.Key("FT").IsNull() && widget.Key("Parent").Kind() == pdf.Dict {
+		node = widget.Key("Parent")
+	}
+
+	var widgets []pdf.Value
+	if node.Key("Subtype").Name() == "Widget" {
+		widgets = append(widgets, node)
+	}
+	kids := node.Key("Kids")
+	for i := 0; i < kids.Len(); i++ {
+		if kid := kids.Index(i); kid.Key("T").IsNull() && kid.Key("FT").IsNull() {
+			widgets = append(widgets, kid)
+		}
+	}
+	if len(widgets) == 0 {
+		widgets = []pdf.Value{widget}
+	}
+	field := newFormField(node, fieldName(node), widgets)
+	field.Parent = fieldName(node.Key("Parent"))
+	return field
+}
+
+// qualifiedName returns the fully qualified name of a field below the named parent; a field
+// without a partial name shares its parent's name
+func qualifiedName(parentName string, node pdf.Value) string {
+	partial := node.Key("T")
+	if partial.Kind() != pdf.String {
+		return parentName
+	}
+	if parentName == "" {
+		return partial.Text()
+	}
+	return parentName + "." + partial.Text()
+}
+
+// fieldName returns the fully qualified name of a field, joining the partial names of the
+// field and its ancestors
+func fieldName(node pdf.Value) string {
+	var parts []string
+	for depth := 0; node.Kind() == pdf.Dict && depth <= maxFieldDepth; depth++ {
+		if partial := node.Key("T"); partial.Kind() == pdf.String {
+			parts = append([]string{partial.Text()}, parts...)
+		}
+		node = node.Key("Parent")
+	}
+	return strings.Join(parts, ".")
+}
+
+// fieldDefault returns the value a field is reset to, or nil when it has none
+func fieldDefault(node pdf.Value) interface{} {
+	value := InheritedAttribute(node, "DV")
+	switch value.Kind() {
+	case pdf.String:
+		return value.Text()
+	case pdf.Name:
+		return value.Name()
+	}
+	return nil
+}
+
+// rectBox converts an annotation rectangle to a bounding box, reporting false when the
+// rectangle is malformed
+func rectBox(rect pdf.Value) (BoundingBox, bool) {
+	if rect.Kind() != pdf.Array || rect.Len() < 4 {
+		return BoundingBox{}, false
+	}
+	return boxFromPoints(
+		Coordinate{X: rect.Index(0).Float64(), Y: rect.Index(1).Float64()},
+		Coordinate{X: rect.Index(2).Float64(), Y: rect.Index(3).Float64()},
+	), true
+}
+
+// annotationPages maps the annotations listed in each page's /Annots array to the page
+func annotationPages(numbering *PageNumbering) map[ObjectRef]int {
+	pages := make(map[ObjectRef]int)
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		annots := numbering.Page(pageNum).V.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			if ref := RefOf(annots.Index(i)); ref.ID != 0 {
+				if _, seen := pages[ref]; !seen {
+					pages[ref] = pageNum
+				}
+			}
+		}
+	}
+	return pages
+}
+
+// widgetPage returns the page showing a widget. Viewers draw the annotations a page lists,
+// so that list decides; the widget's /P entry, which writers often leave out or point at
+// the first page, only places widgets no page lists.
+func widgetPage(widget pdf.Value, numbering *PageNumbering, annotations map[ObjectRef]int) int {
+	if pageNum, ok := annotations[RefOf(widget)]; ok {
+		return pageNum
+	}
+	return numbering.Number(widget.Key("P"))
+}
+
+// newFormField reads the type, flags, value, and options of a terminal field
+func newFormField(node pdf.Value, name string, widgets []pdf.Value) FormField {
+	field := FormField{
+		Name:    name,
+		Flags:   int(InheritedAttribute(node, "Ff").Int64()),
+		Field:   node,
+		Widgets: widgets,
+	}
+
+	switch InheritedAttribute(node, "FT").Name() {
+	case "Btn":
+		switch {
+		case field.Flags&FieldFlagPushButton != 0:
+			field.Type = FieldTypeButton
+		case field.Flags&FieldFlagRadio != 0:
+			field.Type = FieldTypeRadio
+		default:
+			field.Type = FieldTypeCheckbox
+		}
+	case "Ch":
+		field.Type = FieldTypeChoice
+	case "Sig":
+		field.Type = FieldTypeSignature
+	default:
+		field.Type = FieldTypeText
+	}
+
+	value := InheritedAttribute(node, "V")
+	switch value.Kind() {
+	case pdf.String:
+		field.Value = value.Text()
+	case pdf.Name:
+		field.Value = value.Name()
+	case pdf.Stream:
+		field.Value = readScript(value) // Rich text fields may hold their plain text in a stream
+	case pdf.Array:
+		for i := 0; i < value.Len(); i++ {
+			if item := value.Index(i); item.Kind() == pdf.String {
+				field.Values = append(field.Values, item.Text())
+			}
+		}
+		if len(field.Values) > 0 {
+			field.Value = field.Values[0]
+		}
+	}
+
+	switch field.Type {
+	case FieldTypeText:
+		readTextLayout(&field, node)
+	case FieldTypeChoice:
+		readChoices(&field, node)
+	case FieldTypeCheckbox, FieldTypeRadio:
+		field.Options = OnStates(widgets)
+		readButtonState(&field, InheritedAttribute(node, "Opt"))
+	case FieldTypeSignature:
+		field.Signature = readSignature(node)
+	}
+
+	field.Actions = fieldActions(node)
+	_, field.Calculated = field.Actions[TriggerCalculate]
+	return field
+}
+
+// fieldActions returns the JavaScript source of a field's additional actions by trigger.
+// Actions other than JavaScript are left out.
+func fieldActions(node pdf.Value) map[string]string {
+	aa := node.Key("AA")
+	if aa.Kind() != pdf.Dict {
+		return nil
+	}
+	var actions map[string]string
+	for key, trigger := range fieldTriggers {
+		if script := actionScript(aa.Key(key)); script != "" {
+			if actions == nil {
+				actions = make(map[string]string)
+			}
+			actions[trigger] = script
+		}
+	}
+	return actions
+}
+
+// actionScript returns the JavaScript of an action and the actions chained to it by /Next,
+// one script per line. The source is a string or a stream.
+func actionScript(action pdf.Value) string {
+	var scripts []string
+	for depth := 0; action.Kind() == pdf.Dict && depth < maxActionChain; depth++ {
+		if action.Key("S").Name() == "JavaScript" {
+			if script := readScript(action.Key("JS")); script != "" {
+				scripts = append(scripts, script)
+			}
+		}
+		// /Next is an action or an array of them; only the first of an array is followed
+		next := action.Key("Next")
+		if next.Kind() == pdf.Array {
+			next = next.Index(0)
+		}
+		action = next
+	}
+	return strings.Join(scripts, "\n")
+}
+
+// readChoices reads the options of a choice field and its layout flags. The options
+// selected are those /I lists by index, which tells apart options sharing an export value,
+// or else those whose export value the field holds.
+func readChoices(field *FormField, node pdf.Value) {
+	field.Combo = field.Flags&FieldFlagCombo != 0
+	field.Editable = field.Flags&FieldFlagEdit != 0
+	field.Sorted = field.Flags&FieldFlagSort != 0
+	field.MultiSelect = field.Flags&FieldFlagMultiSelect != 0
+	field.DoNotSpellCheck = field.Flags&FieldFlagDoNotSpellCheck != 0
+
+	opts := InheritedAttribute(node, "Opt")
+	for i := 0; i < opts.Len(); i++ {
+		opt := opts.Index(i)
+		choice := ChoiceOption{ExportValue: opt.Text(), Display: opt.Text()}
+		if opt.Kind() == pdf.Array {
+			choice.ExportValue, choice.Display = opt.Index(0).Text(), opt.Index(1).Text()
+			if opt.Len() < 2 {
+				choice.Display = choice.ExportValue
+			}
+		}
+		field.Options = append(field.Options, choice.ExportValue)
+		field.Choices = append(field.Choices, choice)
+	}
+
+	selected := field.Values
+	if len(selected) == 0 && field.Value != "" {
+		selected = []string{field.Value}
+	}
+	indices := InheritedAttribute(node, "I")
+	byIndex := indices.Len() > 0
+	for i := 0; i < indices.Len(); i++ {
+		if index := int(indices.Index(i).Int64()); index < 0 || index >= len(field.Choices) ||
+			!slices.Contains(selected, field.Choices[index].ExportValue) {
+			byIndex = false // Stale indices, left behind by a writer that only changed /V
+		}
+	}
+	for i := range field.Choices {
+		field.Choices[i].Selected = !byIndex && slices.Contains(selected, field.Choices[i].ExportValue)
+	}
+	if byIndex {
+		for i := 0; i < indices.Len(); i++ {
+			field.Choices[indices.Index(i).Int64()].Selected = true
+		}
+	}
+}
+
+// readTextLayout reads the flags of a text field that decide how its value is shown, and
+// the styled text of a rich text field. The comb flag only applies to a field with a
+// maximum length that is neither multiline, a password, nor a file selection.
+func readTextLayout(field *FormField, node pdf.Value) {
+	field.Multiline = field.Flags&FieldFlagMultiline != 0
+	field.Password = field.Flags&FieldFlagPassword != 0
+	field.DoNotSpellCheck = field.Flags&FieldFlagDoNotSpellCheck != 0
+
+	const notComb = FieldFlagMultiline | FieldFlagPassword | FieldFlagFileSelect
+	if field.Flags&FieldFlagComb != 0 && field.Flags&notComb == 0 {
+		field.CombCells = int(InheritedAttribute(node, "MaxLen").Int64())
+	}
+
+	if field.Flags&FieldFlagRichText == 0 {
+		return
+	}
+	if rv := readScript(InheritedAttribute(node, "RV")); rv != "" {
+		var text string
+		text, field.RichText = ParseRichText(rv)
+		if field.Value == "" {
+			field.Value = text
+		}
+	}
+}
+
+// readScript reads the source of a JavaScript action, or other text, from a string or
+// stream
+func readScript(js pdf.Value) (script string) {
+	switch js.Kind() {
+	case pdf.String:
+		return js.Text()
+	case pdf.Stream:
+		// The PDF library panics on streams it cannot decode
+		defer func() {
+			if recover() != nil {
+				script = ""
+			}
+		}()
+		reader := js.Reader()
+		defer reader.Close()
+		data, err := io.ReadAll(io.LimitReader(reader, maxScriptSize))
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+	return ""
+}
+
+// CalculationOrder returns the names of the fields in the form's /CO array, the order in
+// which viewers run their calculate actions
+func CalculationOrder(reader *pdf.Reader) []string {
+	var names []string
+	order := reader.Trailer().Key("Root").Key("AcroForm").Key("CO")
+	for i := 0; i < order.Len(); i++ {
+		if name := fieldName(order.Index(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// readSignature reads a signature field's lock and, when signed, its signature dictionary
+func readSignature(node pdf.Value) *SignatureInfo {
+	info := &SignatureInfo{}
+	if lock := node.Key("Lock"); lock.Kind() == pdf.Dict {
+		info.LockAction = lock.Key("Action").Name()
+		fields := lock.Key("Fields")
+		for i := 0; i < fields.Len(); i++ {
+			info.LockFields = append(info.LockFields, fields.Index(i).Text())
+		}
+	}
+
+	sig := InheritedAttribute(node, "V")
+	if sig.Kind() != pdf.Dict {
+		return info
+	}
+	info.Signed = true
+	info.Signer = sig.Key("Name").Text()
+	info.Reason = sig.Key("Reason").Text()
+	info.Location = sig.Key("Location").Text()
+	info.ContactInfo = sig.Key("ContactInfo").Text()
+	info.SigningTime, _ = ParsePDFDate(sig.Key("M").RawString())
+	info.Filter = sig.Key("Filter").Name()
+	info.SubFilter = sig.Key("SubFilter").Name()
+
+	refs := sig.Key("Reference")
+	for i := 0; i < refs.Len(); i++ {
+		ref := refs.Index(i)
+		if ref.Key("TransformMethod").Name() != "DocMDP" {
+			continue
+		}
+		info.Certifies = true
+		// Permissions default to allowing form filling and signing
+		info.Permissions = CertifyFormFilling
+		if p := ref.Key("TransformParams").Key("P"); p.Kind() == pdf.Integer {
+			info.Permissions = int(p.Int64())
+		}
+	}
+	return info
+}
+
+// SigFlags returns the /SigFlags entry of a document's interactive form
+func SigFlags(reader *pdf.Reader) int {
+	return int(reader.Trailer().Key("Root").Key("AcroForm").Key("SigFlags").Int64())
+}
+
+// SignatureLocks returns the fields locked by signed signature fields, mapped to the name
+// of the first signature locking them
+func SignatureLocks(fields []FormField) map[string]string {
+	locks := make(map[string]string)
+	for _, sig := range fields {
+		if sig.Signature == nil || !sig.Signature.Signed || sig.Signature.LockAction == "" {
+			continue
+		}
+		for _, field := range fields {
+			if field.Name == sig.Name || locks[field.Name] != "" {
+				continue
+			}
+			listed := slices.ContainsFunc(sig.Signature.LockFields, func(name string) bool {
+				// A lock naming a field also locks its descendants
+				return field.Name == name || strings.HasPrefix(field.Name, name+".")
+			})
+			switch sig.Signature.LockAction {
+			case LockAll:
+				locks[field.Name] = sig.Name
+			case LockInclude:
+				if listed {
+					locks[field.Name] = sig.Name
+				}
+			case LockExclude:
+				if !listed {
+					locks[field.Name] = sig.Name
+				}
+			}
+		}
+	}
+	return locks
+}
+
+// readButtonState determines which widgets of a checkbox or radio button are on. The field
+// value names the on state; when it names no state of the widgets, as when it is missing
+// or the widgets were switched without updating it, the widgets' /AS entries decide. The
+// /Opt array gives the export value of each widget, so that a radio group whose kids share
+// a state name or use index states such as "0" and "1" still reports what was chosen.
+func readButtonState(field *FormField, opts pdf.Value) {
+	fromValue := field.Value != "" && (field.Value == "Off" || slices.Contains(field.Options, field.Value))
+	if !fromValue && len(field.Options) > 0 {
+		field.Value = "Off"
+	}
+
+	for i, widget := range field.Widgets {
+		button := ButtonWidget{State: widgetOnState(widget)}
+		button.ExportValue = button.State
+		if i < opts.Len() {
+			if export := opts.Index(i).Text(); export != "" {
+				button.ExportValue = export
+			}
+		}
+
+		switch {
+		case fromValue:
+			button.Checked = button.State != "" && button.State == field.Value
+		case button.State != "" && widget.Key("AS").Name() == button.State:
+			button.Checked = true
+			if field.Value == "Off" {
+				field.Value = button.State
+			}
+		}
+		field.Buttons = append(field.Buttons, button)
+	}
+
+	for _, button := range field.Buttons {
+		if button.Checked {
+			field.Checked = true
+			field.ExportValue = button.ExportValue
+			break
+		}
+	}
+
+	// Without appearances the value is all there is
+	if len(field.Options) == 0 {
+		field.Checked = field.Value != "" && field.Value != "Off"
+		if field.Checked {
+			field.ExportValue = field.Value
+		}
+	}
+	if field.Value == "" {
+		field.Value = "Off"
+	}
+}
+
+// widgetOnState returns the appearance state other than Off of a button widget, taken from
+// its normal appearances or else its down appearances
+func widgetOnState(widget pdf.Value) string {
+	for _, key := range []string{"N", "D"} {
+		appearances := widget.Key("AP").Key(key)
+		if appearances.Kind() != pdf.Dict {
+			continue
+		}
+		for _, state := range appearances.Keys() {
+			if state != "Off" {
+				return state
+			}
+		}
+	}
+	return ""
+}
+
+// OnStates returns the appearance states other than Off of a button's widgets, in order
+func OnStates(widgets []pdf.Value) []string {
+	var states []string
+	seen := make(map[string]bool)
+	for _, widget := range widgets {
+		normal := widget.Key("AP").Key("N")
+		if normal.Kind() != pdf.Dict {
+			continue
+		}
+		for _, state := range normal.Keys() {
+			if state != "Off" && !seen[state] {
+				seen[state] = true
+				states = append(states, state)
+			}
+		}
+	}
+	return states
+}
@@ -0,0 +1,370 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+)
+
+func TestReadFormFields_ButtonStates(t *testing.T) {
+	ap := func(state string) string {
+		return "/AP << /N << /" + state + " 9 0 R /Off 9 0 R >> >>"
+	}
+	path := writeRawPDF(t, "buttons.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R 5 0 R 6 0 R 10 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		// The value names a state the widget does not have; the widget shows its custom on state
+		"<< /FT /Btn /T (custom) /V /Yes /AS /Checked /Subtype /Widget " + ap("Checked") + " >>",
+		// No value and the widget is off
+		"<< /FT /Btn /T (unset) /AS /Off /Subtype /Widget " + ap("On") + " >>",
+		// A radio group with index states and export values in /Opt
+		"<< /FT /Btn /Ff 32768 /T (size) /V /1 /Opt [(Small) (Large)] /Kids [7 0 R 8 0 R] >>",
+		"<< /Subtype /Widget /Parent 6 0 R /AS /Off " + ap("0") + " >>",
+		"<< /Subtype /Widget /Parent 6 0 R /AS /1 " + ap("1") + " >>",
+		"<< /Type /XObject /Subtype /Form /BBox [0 0 10 10] /Length 0 >>\nstream\n\nendstream",
+		// A radio group without a value, switched on through a kid's appearance state
+		"<< /FT /Btn /Ff 32768 /T (color) /Kids [11 0 R 12 0 R] >>",
+		"<< /Subtype /Widget /Parent 10 0 R /AS /red " + ap("red") + " >>",
+		"<< /Subtype /Widget /Parent 10 0 R /AS /Off " + ap("blue") + " >>",
+	})
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	fields := make(map[string]FormField)
+	for _, field := range ReadFormFields(doc.Reader) {
+		fields[field.Name] = field
+	}
+
+	tests := []struct {
+		name        string
+		value       string
+		checked     bool
+		exportValue string
+		buttons     []ButtonWidget
+	}{
+		{"custom", "Checked", true, "Checked", []ButtonWidget{{"Checked", "Checked", true}}},
+		{"unset", "Off", false, "", []ButtonWidget{{"On", "On", false}}},
+		{"size", "1", true, "Large", []ButtonWidget{{"0", "Small", false}, {"1", "Large", true}}},
+		{"color", "red", true, "red", []ButtonWidget{{"red", "red", true}, {"blue", "blue", false}}},
+	}
+	for _, tt := range tests {
+		field := fields[tt.name]
+		if field.Value != tt.value || field.Checked != tt.checked || field.ExportValue != tt.exportValue {
+			t.Errorf("%s: value %q, checked %t, export value %q; want %q, %t, %q", tt.name,
+				field.Value, field.Checked, field.ExportValue, tt.value, tt.checked, tt.exportValue)
+		}
+		if !reflect.DeepEqual(field.Buttons, tt.buttons) {
+			t.Errorf("%s: buttons = %+v, want %+v", tt.name, field.Buttons, tt.buttons)
+		}
+	}
+}
+
+// writeTaxFormPDF writes a two-page form laid out like a W-2 with a 1099 copy: the
+// employer box is on page 1, the wage and state boxes on page 2. The wage widget's /P
+// entry wrongly names page 1, the state field has no /P, and the copy checkbox is listed
+// on no page but names page 2.
+func writeTaxFormPDF(t *testing.T) string {
+	t.Helper()
+	return writeRawPDF(t, "w2.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [5 0 R 6 0 R 8 0 R 9 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [5 0 R] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [7 0 R 8 0 R 7 0 R] >>",
+		"<< /FT /Tx /T (employer) /V (Acme Corp) /Subtype /Widget /Rect [36 700 300 720] /P 3 0 R >>",
+		"<< /T (box1) /FT /Tx /Ff 2 /MaxLen 12 /V (52,000.00) /Kids [7 0 R] >>",
+		"<< /Subtype /Widget /Parent 6 0 R /Rect [320 650 500 670] /P 3 0 R >>",
+		"<< /FT /Tx /T (state) /V (NY) /DV (CA) /Subtype /Widget /Rect [300 600 340 620] >>",
+		"<< /FT /Btn /T (corrected) /V /Yes /Subtype /Widget /Rect [36 760 48 772] /P 4 0 R " +
+			"/AP << /N << /Yes 10 0 R /Off 10 0 R >> >> >>",
+		"<< /Type /XObject /Subtype /Form /BBox [0 0 10 10] /Length 0 >>\nstream\n\nendstream",
+	})
+}
+
+func TestReadFormFields_WidgetPages(t *testing.T) {
+	doc, err := core.Open(writeTaxFormPDF(t), core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	pages := make(map[string][]int)
+	for _, field := range ReadFormFields(doc.Reader) {
+		pages[field.Name] = field.Pages
+	}
+	want := map[string][]int{"employer": {1}, "box1": {2}, "state": {2}, "corrected": {2}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("ReadFormFields() pages = %v, want %v", pages, want)
+	}
+}
+
+// writeSubformPDF writes a form converted from XFA, whose fields are grouped by subform
+// and page. The form's /Fields array wrongly lists the second page's field beside its root.
+func writeSubformPDF(t *testing.T) string {
+	t.Helper()
+	return writeRawPDF(t, "f1040.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [8 0 R 4 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [6 0 R 8 0 R] >>",
+		"<< /T (topmostSubform[0]) /Kids [5 0 R 7 0 R] >>",
+		"<< /T (Page1[0]) /Parent 4 0 R /Kids [6 0 R] >>",
+		"<< /FT /Tx /T (f1_01[0]) /V (Jane) /Parent 5 0 R /Subtype /Widget /Rect [36 700 300 720] >>",
+		"<< /T (Page2[0]) /Parent 4 0 R /Kids [8 0 R] >>",
+		"<< /FT /Tx /T (f2_01[0]) /V (42) /Parent 7 0 R /Subtype /Widget /Rect [36 600 300 620] >>",
+	})
+}
+
+func TestReadFormFieldTree(t *testing.T) {
+	doc, err := core.Open(writeSubformPDF(t), core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	var tree []string
+	for _, field := range ReadFormFieldTree(doc.Reader) {
+		tree = append(tree, fmt.Sprintf("%s (%s) parent %q kids %v", field.Name, field.Type, field.Parent, field.Kids))
+	}
+	// The kid listed among the roots is placed under its group, which comes before it
+	want := []string{
+		`topmostSubform[0] (group) parent "" kids [topmostSubform[0].Page1[0] topmostSubform[0].Page2[0]]`,
+		`topmostSubform[0].Page1[0] (group) parent "topmostSubform[0]" kids [topmostSubform[0].Page1[0].f1_01[0]]`,
+		`topmostSubform[0].Page1[0].f1_01[0] (text) parent "topmostSubform[0].Page1[0]" kids []`,
+		`topmostSubform[0].Page2[0] (group) parent "topmostSubform[0]" kids [topmostSubform[0].Page2[0].f2_01[0]]`,
+		`topmostSubform[0].Page2[0].f2_01[0] (text) parent "topmostSubform[0].Page2[0]" kids []`,
+	}
+	if !reflect.DeepEqual(tree, want) {
+		t.Errorf("ReadFormFieldTree() =\n%v\nwant\n%v", tree, want)
+	}
+
+	var terminal []string
+	for _, field := range ReadFormFields(doc.Reader) {
+		terminal = append(terminal, field.Name)
+	}
+	if len(terminal) != 2 || terminal[0] != "topmostSubform[0].Page1[0].f1_01[0]" {
+		t.Errorf("ReadFormFields() = %v, want only the two text fields in tree order", terminal)
+	}
+}
+
+func TestReadFormFields_TextLayout(t *testing.T) {
+	rv := `<body xmlns="http://www.w3.org/1999/xhtml"><p>Ship <b>today</b></p></body>`
+	path := writeRawPDF(t, "layout.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R 5 0 R 6 0 R 7 0 R 9 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		// A comb of 9 cells, which spell checking skips
+		"<< /FT /Tx /T (ssn) /Ff 20971520 /MaxLen 9 /V (123456789) /Subtype /Widget >>",
+		// The comb flag is ignored on a multiline field
+		"<< /FT /Tx /T (notes) /Ff 16781312 /MaxLen 200 /Subtype /Widget >>",
+		"<< /FT /Tx /T (pin) /Ff 8192 /Subtype /Widget >>",
+		// A rich text field with its styled text in a stream and no plain value
+		"<< /FT /Tx /T (memo) /Ff 33554432 /RV 8 0 R /Subtype /Widget >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(rv), rv),
+		"<< /FT /Ch /T (state) /Ff 4325376 /Opt [(NY) (CA)] /Subtype /Widget >>",
+	})
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	fields := make(map[string]FormField)
+	for _, field := range ReadFormFields(doc.Reader) {
+		fields[field.Name] = field
+	}
+	if ssn := fields["ssn"]; ssn.CombCells != 9 || !ssn.DoNotSpellCheck || ssn.Multiline {
+		t.Errorf("ssn = %+v, want a comb of 9 cells without spell checking", ssn)
+	}
+	if notes := fields["notes"]; notes.CombCells != 0 || !notes.Multiline {
+		t.Errorf("notes = %+v, want a multiline field that is no comb", notes)
+	}
+	if pin := fields["pin"]; !pin.Password {
+		t.Errorf("pin = %+v, want a password field", pin)
+	}
+	memo := fields["memo"]
+	want := []RichTextSpan{{Text: "Ship"}, {Text: " today", Bold: true}}
+	if memo.Value != "Ship today" || !reflect.DeepEqual(memo.RichText, want) {
+		t.Errorf("memo value %q, rich text %+v; want %q, %+v", memo.Value, memo.RichText, "Ship today", want)
+	}
+	if state := fields["state"]; !state.DoNotSpellCheck || state.Multiline {
+		t.Errorf("state = %+v, want a choice field without spell checking", state)
+	}
+}
+
+func TestReadFormFields_Choices(t *testing.T) {
+	path := writeRawPDF(t, "choices.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R 5 0 R 6 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		// An editable, sorted combo box of export value and text pairs
+		"<< /FT /Ch /T (state) /Ff 917504 /Opt [[(NY) (New York)] [(CA) (California)]] /V (CA) /Subtype /Widget >>",
+		// A multiple-selection list telling apart two options with the same export value
+		"<< /FT /Ch /T (tags) /Ff 2097152 /Opt [(a) (b) (a)] /V [(a) (b)] /I [1 2] /Subtype /Widget >>",
+		// Indices left stale by a writer that changed only the value
+		"<< /FT /Ch /T (size) /Opt [(S) (M) (L)] /V (L) /I [0] /Subtype /Widget >>",
+	})
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	fields := make(map[string]FormField)
+	for _, field := range ReadFormFields(doc.Reader) {
+		fields[field.Name] = field
+	}
+	state := fields["state"]
+	want := []ChoiceOption{{"NY", "New York", false}, {"CA", "California", true}}
+	if !reflect.DeepEqual(state.Choices, want) || !reflect.DeepEqual(state.Options, []string{"NY", "CA"}) {
+		t.Errorf("state choices %+v, options %v; want %+v", state.Choices, state.Options, want)
+	}
+	if !state.Combo || !state.Editable || !state.Sorted || state.MultiSelect {
+		t.Errorf("state = %+v, want an editable, sorted combo box", state)
+	}
+	tags := fields["tags"]
+	want = []ChoiceOption{{"a", "a", false}, {"b", "b", true}, {"a", "a", true}}
+	if !reflect.DeepEqual(tags.Choices, want) || !tags.MultiSelect || tags.Combo {
+		t.Errorf("tags choices = %+v, want the options /I selects in a multiple-selection list", tags.Choices)
+	}
+	want = []ChoiceOption{{"S", "S", false}, {"M", "M", false}, {"L", "L", true}}
+	if size := fields["size"]; !reflect.DeepEqual(size.Choices, want) {
+		t.Errorf("size choices = %+v, want the option the value selects", size.Choices)
+	}
+}
+
+func TestExtract_FormFieldsOnTheirPages(t *testing.T) {
+	result, err := NewEngine().Extract(context.Background(), ExtractionRequest{
+		FilePath: writeTaxFormPDF(t),
+		Config:   ExtractionConfig{Mode: ModeComplete, ExtractForms: true},
+	})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	type placed struct {
+		page int
+		form FormElement
+		box  BoundingBox
+	}
+	forms := make(map[string]placed)
+	for _, element := range result.Elements {
+		if element.Type != ContentTypeForm {
+			continue
+		}
+		form := element.Content.(FormElement)
+		if _, dup := forms[form.FieldName]; dup {
+			t.Errorf("field %s extracted twice", form.FieldName)
+		}
+		forms[form.FieldName] = placed{element.PageNumber, form, element.BoundingBox}
+	}
+
+	// The checkbox listed on no page is not drawn, so page extraction leaves it out
+	if len(forms) != 3 || forms["employer"].page != 1 || forms["box1"].page != 2 || forms["state"].page != 2 {
+		t.Fatalf("Extract() forms = %+v, want employer on page 1, box1 and state on page 2", forms)
+	}
+	box1 := forms["box1"]
+	if box1.form.Value != "52,000.00" || !box1.form.Required || box1.form.MaxLength != 12 ||
+		box1.box.LowerLeft.X != 320 || box1.box.Width != 180 {
+		t.Errorf("box1 = %+v, want its value, flags, length, and widget rectangle", box1)
+	}
+	if state := forms["state"].form; state.DefaultValue != "CA" || state.Value != "NY" {
+		t.Errorf("state = %+v, want value NY and default CA", state)
+	}
+}
+
+func TestReadFormFields_Signatures(t *testing.T) {
+	path := writeRawPDF(t, "signed.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R 5 0 R 7 0 R 8 0 R] /SigFlags 3 >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /FT /Sig /T (approver) /Subtype /Widget /Rect [0 0 0 0] /V 6 0 R " +
+			"/Lock << /Type /SigFieldLock /Action /Include /Fields [(amount)] >> >>",
+		"<< /FT /Sig /T (witness) /Subtype /Widget /Rect [0 0 0 0] >>",
+		"<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /adbe.pkcs7.detached /Name (Jane Roe) " +
+			"/Reason (Approved) /Location (Boston) /M (D:20240315093000Z) /Contents <00> /ByteRange [0 1 2 3] " +
+			"/Reference [<< /TransformMethod /DocMDP /TransformParams << /P 1 >> >>] >>",
+		"<< /T (amount) /Kids [9 0 R] >>",
+		"<< /FT /Tx /T (memo) /Subtype /Widget /Rect [0 0 0 0] >>",
+		"<< /FT /Tx /T (total) /Parent 7 0 R /Subtype /Widget /Rect [0 0 0 0] >>",
+	})
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	if flags := SigFlags(doc.Reader); flags != SigFlagSignaturesExist|SigFlagAppendOnly {
+		t.Errorf("SigFlags() = %d, want signatures exist and append only", flags)
+	}
+
+	fields := ReadFormFields(doc.Reader)
+	byName := make(map[string]FormField)
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+	approver := byName["approver"].Signature
+	want := &SignatureInfo{
+		Signed: true, Signer: "Jane Roe", Reason: "Approved", Location: "Boston",
+		SigningTime: time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC),
+		Filter:      "Adobe.PPKLite", SubFilter: "adbe.pkcs7.detached",
+		LockAction: LockInclude, LockFields: []string{"amount"},
+		Certifies: true, Permissions: CertifyNoChanges,
+	}
+	if !reflect.DeepEqual(approver, want) {
+		t.Errorf("approver signature = %+v, want %+v", approver, want)
+	}
+	if witness := byName["witness"].Signature; witness == nil || witness.Signed {
+		t.Errorf("witness signature = %+v, want an unsigned field", witness)
+	}
+
+	// The lock names a parent field, locking its descendants
+	if locks := SignatureLocks(fields); !reflect.DeepEqual(locks, map[string]string{"amount.total": "approver"}) {
+		t.Errorf("SignatureLocks() = %v, want amount.total locked by approver", locks)
+	}
+}
+
+func TestReadFormFields_CalculationActions(t *testing.T) {
+	script := "event.value = this.getField('price').value * 2;"
+	path := writeRawPDF(t, "calculated.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R 5 0 R 6 0 R] /CO [6 0 R 5 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /FT /Tx /T (price) /V (10) /Subtype /Widget /Rect [0 0 0 0] " +
+			"/AA << /K << /S /JavaScript /JS (AFNumber_Keystroke\\(2\\);) >> /F << /S /URI /URI (http://x) >> >> >>",
+		"<< /FT /Tx /T (total) /V (20) /Subtype /Widget /Rect [0 0 0 0] /AA << /C 7 0 R >> >>",
+		"<< /FT /Tx /T (tax) /V (2) /Subtype /Widget /Rect [0 0 0 0] >>",
+		"<< /S /JavaScript /JS 8 0 R /Next << /S /JavaScript /JS (AFRound\\(\\);) >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(script), script),
+	})
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	if order := CalculationOrder(doc.Reader); !reflect.DeepEqual(order, []string{"tax", "total"}) {
+		t.Errorf("CalculationOrder() = %q, want tax, total", order)
+	}
+
+	fields := ReadFormFields(doc.Reader)
+	if len(fields) != 3 {
+		t.Fatalf("ReadFormFields() returned %d fields, want 3", len(fields))
+	}
+	price, total, tax := fields[0], fields[1], fields[2]
+	if price.Calculated || !reflect.DeepEqual(price.Actions, map[string]string{TriggerKeystroke: "AFNumber_Keystroke(2);"}) {
+		t.Errorf("price = calculated %t, actions %q, want the keystroke script only", price.Calculated, price.Actions)
+	}
+	if !total.Calculated || total.Actions[TriggerCalculate] != script+"\nAFRound();" {
+		t.Errorf("total = calculated %t, actions %q, want the chained calculate scripts", total.Calculated, total.Actions)
+	}
+	if !tax.Calculated || tax.Actions != nil {
+		t.Errorf("tax = calculated %t, actions %q, want calculated from the order alone", tax.Calculated, tax.Actions)
+	}
+}
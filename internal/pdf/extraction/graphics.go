@@ -0,0 +1,286 @@
+package extraction
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Vector element types produced by the graphics interpreter
+const (
+	VectorTypeLine = "line"
+	VectorTypeRect = "rect"
+	VectorTypePath = "path"
+)
+
+// affineMatrix is a PDF transformation matrix [a b c d e f]
+type affineMatrix [6]float64
+
+var identityMatrix = affineMatrix{1, 0, 0, 1, 0, 0}
+
+// multiply returns m × n (apply m first, then n)
+func (m affineMatrix) multiply(n affineMatrix) affineMatrix {
+	return affineMatrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// transform maps a point from user space into page space
+func (m affineMatrix) transform(x, y float64) Coordinate {
+	return Coordinate{
+		X: m[0]*x + m[2]*y + m[4],
+		Y: m[1]*x + m[3]*y + m[5],
+	}
+}
+
+// graphicsState holds the subset of the PDF graphics state needed for path extraction
+type graphicsState struct {
+	ctm       affineMatrix
+	lineWidth float64
+}
+
+// pathBuilder accumulates path construction operators until a painting operator is seen
+type pathBuilder struct {
+	commands []VectorCmd
+	current  Coordinate
+	start    Coordinate
+	rects    []BoundingBox
+}
+
+func (b *pathBuilder) reset() {
+	b.commands = nil
+	b.rects = nil
+}
+
+// interpretPageGraphics walks the page content stream and returns the stroked and filled
+// paths it draws. Only path geometry is tracked; colors, clipping, and shading are ignored.
+func interpretPageGraphics(page pdf.Page) ([]VectorElement, error) {
+	vectors, _, err := interpretPageDrawing(page)
+	return vectors, err
+}
+
+// ImagePlacement is one drawing of an image XObject by a page's content stream
+type ImagePlacement struct {
+	Name        string     // Resource name of the image in the page's XObject dictionary
+	Matrix      [6]float64 // Transformation from the image's unit square to page space
+	BoundingBox BoundingBox
+}
+
+// Size returns the width and height, in points, the image is drawn at, measured along its
+// own edges so rotated and skewed placements keep the image's proportions
+func (p ImagePlacement) Size() (width, height float64) {
+	return math.Hypot(p.Matrix[0], p.Matrix[1]), math.Hypot(p.Matrix[2], p.Matrix[3])
+}
+
+// PageImagePlacements returns where a page places its image XObjects, in page space
+func PageImagePlacements(page pdf.Page) ([]ImagePlacement, error) {
+	_, images, err := interpretPageDrawing(page)
+	return images, err
+}
+
+// interpretPageDrawing walks the page content stream and returns the paths it draws and
+// where it places image XObjects. Images drawn inside form XObjects are not found.
+func interpretPageDrawing(page pdf.Page) (vectors []VectorElement, images []ImagePlacement, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("content stream interpretation failed: %v", r)
+		}
+	}()
+
+	contents := page.V.Key("Contents")
+	if contents.IsNull() {
+		return nil, nil, nil
+	}
+	xObjects := page.V.Key("Resources").Key("XObject")
+
+	state := graphicsState{ctm: identityMatrix, lineWidth: 1}
+	var stack []graphicsState
+	var path pathBuilder
+
+	paint := func(stroked, filled bool) {
+		if !stroked && !filled {
+			path.reset()
+			return
+		}
+		vectors = append(vectors, path.toVectors(state, stroked, filled)...)
+		path.reset()
+	}
+
+	pdf.Interpret(contents, func(stk *pdf.Stack, op string) {
+		n := stk.Len()
+		args := make([]pdf.Value, n)
+		for i := n - 1; i >= 0; i-- {
+			args[i] = stk.Pop()
+		}
+
+		switch op {
+		case "q":
+			stack = append(stack, state)
+		case "Q":
+			if len(stack) > 0 {
+				state = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			if len(args) == 6 {
+				var m affineMatrix
+				for i := range m {
+					m[i] = args[i].Float64()
+				}
+				state.ctm = m.multiply(state.ctm)
+			}
+		case "w":
+			if len(args) == 1 {
+				state.lineWidth = args[0].Float64()
+			}
+		case "m":
+			if len(args) == 2 {
+				p := state.ctm.transform(args[0].Float64(), args[1].Float64())
+				path.current, path.start = p, p
+				path.commands = append(path.commands, VectorCmd{Command: "moveto", Points: []Coordinate{p}})
+			}
+		case "l":
+			if len(args) == 2 {
+				p := state.ctm.transform(args[0].Float64(), args[1].Float64())
+				path.commands = append(path.commands, VectorCmd{
+					Command: "lineto", Points: []Coordinate{path.current, p},
+				})
+				path.current = p
+			}
+		case "c", "v", "y":
+			if len(args) >= 4 {
+				last := len(args)
+				p := state.ctm.transform(args[last-2].Float64(), args[last-1].Float64())
+				path.commands = append(path.commands, VectorCmd{
+					Command: "curveto", Points: []Coordinate{path.current, p},
+				})
+				path.current = p
+			}
+		case "h":
+			path.commands = append(path.commands, VectorCmd{
+				Command: "closepath", Points: []Coordinate{path.current, path.start},
+			})
+			path.current = path.start
+		case "re":
+			if len(args) == 4 {
+				x, y := args[0].Float64(), args[1].Float64()
+				w, h := args[2].Float64(), args[3].Float64()
+				path.rects = append(path.rects, boxFromPoints(
+					state.ctm.transform(x, y),
+					state.ctm.transform(x+w, y+h),
+				))
+			}
+		case "S", "s":
+			paint(true, false)
+		case "f", "F", "f*":
+			paint(false, true)
+		case "B", "B*", "b", "b*":
+			paint(true, true)
+		case "n":
+			paint(false, false)
+		case "Do":
+			if len(args) == 1 && xObjects.Key(args[0].Name()).Key("Subtype").Name() == "Image" {
+				images = append(images, ImagePlacement{
+					Name: args[0].Name(), Matrix: state.ctm, BoundingBox: unitSquareBox(state.ctm),
+				})
+			}
+		}
+	})
+
+	return vectors, images, nil
+}
+
+// toVectors converts the accumulated path into vector elements
+func (b *pathBuilder) toVectors(state graphicsState, stroked, filled bool) []VectorElement {
+	var vectors []VectorElement
+	strokeWidth := state.lineWidth * math.Sqrt(math.Abs(state.ctm[0]*state.ctm[3]-state.ctm[1]*state.ctm[2]))
+
+	for _, rect := range b.rects {
+		vector := VectorElement{
+			Type: VectorTypeRect,
+			Commands: []VectorCmd{{
+				Command: "rect",
+				Points:  []Coordinate{rect.LowerLeft, rect.UpperRight},
+			}},
+			StrokeWidth: strokeWidth,
+		}
+		if filled {
+			vector.FillColor = "filled"
+		}
+		if stroked {
+			vector.StrokeColor = "stroked"
+		}
+		vectors = append(vectors, vector)
+	}
+
+	if len(b.commands) == 0 {
+		return vectors
+	}
+
+	// A single straight segment is reported as a line; anything else is a generic path
+	vectorType := VectorTypePath
+	segments := 0
+	for _, cmd := range b.commands {
+		if cmd.Command == "lineto" {
+			segments++
+		} else if cmd.Command == "curveto" {
+			segments = -1
+			break
+		}
+	}
+	if segments == 1 && len(b.commands) == 2 {
+		vectorType = VectorTypeLine
+	}
+
+	vector := VectorElement{
+		Type:        vectorType,
+		Commands:    b.commands,
+		StrokeWidth: strokeWidth,
+	}
+	if filled {
+		vector.FillColor = "filled"
+	}
+	if stroked {
+		vector.StrokeColor = "stroked"
+	}
+
+	return append(vectors, vector)
+}
+
+// vectorBounds computes the bounding box covering all points of a vector element
+func vectorBounds(vector VectorElement) BoundingBox {
+	first := true
+	var minX, minY, maxX, maxY float64
+	for _, cmd := range vector.Commands {
+		for _, p := range cmd.Points {
+			if first {
+				minX, minY, maxX, maxY = p.X, p.Y, p.X, p.Y
+				first = false
+				continue
+			}
+			minX = math.Min(minX, p.X)
+			minY = math.Min(minY, p.Y)
+			maxX = math.Max(maxX, p.X)
+			maxY = math.Max(maxY, p.Y)
+		}
+	}
+	return boxFromPoints(Coordinate{X: minX, Y: minY}, Coordinate{X: maxX, Y: maxY})
+}
+
+// boxFromPoints builds a normalized bounding box from two opposite corners
+func boxFromPoints(a, b Coordinate) BoundingBox {
+	ll := Coordinate{X: math.Min(a.X, b.X), Y: math.Min(a.Y, b.Y)}
+	ur := Coordinate{X: math.Max(a.X, b.X), Y: math.Max(a.Y, b.Y)}
+	return BoundingBox{
+		LowerLeft:  ll,
+		UpperRight: ur,
+		Width:      ur.X - ll.X,
+		Height:     ur.Y - ll.Y,
+	}
+}
@@ -0,0 +1,118 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// IssueSeverity classifies how a parse issue affected extraction
+type IssueSeverity string
+
+const (
+	// SeverityError means content from the affected stage was lost
+	SeverityError IssueSeverity = "error"
+	// SeverityWarning means extraction continued with reduced fidelity
+	SeverityWarning IssueSeverity = "warning"
+)
+
+// Extraction stages reported on parse issues
+const (
+	StageMetadata       = "metadata"
+	StagePage           = "page"
+	StageText           = "text"
+	StageImages         = "images"
+	StageVectors        = "vectors"
+	StageForms          = "forms"
+	StageAnnotations    = "annotations"
+	StageTables         = "tables"
+	StageFigures        = "figures"
+	StagePostProcessing = "post_processing"
+	StageQuery          = "query"
+)
+
+// ParseIssue describes a single problem encountered while reading a document, located as
+// precisely as the parser allows so users can find the damaged object in their file
+type ParseIssue struct {
+	Severity IssueSeverity `json:"severity"`
+	Stage    string        `json:"stage"`
+	Page     int           `json:"page,omitempty"`
+	Object   string        `json:"object,omitempty"` // Indirect reference such as "12 0 R"
+	Offset   int64         `json:"offset,omitempty"` // Byte offset reported by the parser
+	Message  string        `json:"message"`
+}
+
+// String formats the issue as a single line, matching the legacy Errors/Warnings entries
+func (i ParseIssue) String() string {
+	text := i.Message
+	if i.Page > 0 {
+		text = fmt.Sprintf("page %d: %s", i.Page, text)
+	}
+	return text
+}
+
+var (
+	// issueObjectPattern matches object references in parser messages, e.g. "loading 12 0 R: ..."
+	issueObjectPattern = regexp.MustCompile(`\b(\d+) (\d+) R\b`)
+	// issueOffsetPattern matches byte offsets in parser messages, e.g. "reading at offset 4096"
+	issueOffsetPattern = regexp.MustCompile(`\boffset (\d+)`)
+)
+
+// newParseIssue builds an issue from an error, taking the object and offset from the parser
+// message when present and falling back to the object being processed
+func newParseIssue(severity IssueSeverity, stage string, page int, object pdf.Value, err error) ParseIssue {
+	issue := ParseIssue{
+		Severity: severity,
+		Stage:    stage,
+		Page:     page,
+		Message:  err.Error(),
+	}
+
+	if match := issueObjectPattern.FindStringSubmatch(issue.Message); match != nil {
+		issue.Object = match[1] + " " + match[2] + " R"
+	} else if ref := RefOf(object); ref.ID != 0 {
+		issue.Object = ref.String()
+	}
+
+	if match := issueOffsetPattern.FindStringSubmatch(issue.Message); match != nil {
+		if offset, parseErr := strconv.ParseInt(match[1], 10, 64); parseErr == nil {
+			issue.Offset = offset
+		}
+	}
+
+	return issue
+}
+
+// addIssue records an issue in the structured list and in the matching legacy string list
+func (r *ExtractionResult) addIssue(issue ParseIssue) {
+	r.Issues = append(r.Issues, issue)
+	if issue.Severity == SeverityError {
+		r.Errors = append(r.Errors, issue.String())
+	} else {
+		r.Warnings = append(r.Warnings, issue.String())
+	}
+	issue.log()
+}
+
+// log records the issue at debug level, or at warning level when content was lost
+func (i ParseIssue) log() {
+	level := slog.LevelDebug
+	if i.Severity == SeverityError {
+		level = slog.LevelWarn
+	}
+	attrs := []any{"stage", i.Stage}
+	if i.Page > 0 {
+		attrs = append(attrs, "page", i.Page)
+	}
+	if i.Object != "" {
+		attrs = append(attrs, "object", i.Object)
+	}
+	if i.Offset > 0 {
+		attrs = append(attrs, "offset", i.Offset)
+	}
+	logger.Log(context.Background(), level, i.Message, attrs...)
+}
@@ -0,0 +1,127 @@
+package extraction
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// HiddenLayers returns the layers, or optional content groups, that a document's default
+// configuration hides, by reference, with their names. A configuration whose base state is
+// OFF hides every group it does not turn on.
+func HiddenLayers(r *pdf.Reader) (hidden map[ObjectRef]string) {
+	hidden = map[ObjectRef]string{}
+
+	// The parser panics on malformed objects; groups found so far are kept
+	defer func() {
+		_ = recover()
+	}()
+
+	properties := r.Trailer().Key("Root").Key("OCProperties")
+	config := properties.Key("D")
+	off := config.Key("OFF")
+	for i := 0; i < off.Len(); i++ {
+		group := off.Index(i)
+		hidden[RefOf(group)] = group.Key("Name").Text()
+	}
+	if config.Key("BaseState").Name() != "OFF" {
+		return hidden
+	}
+
+	on := map[ObjectRef]bool{}
+	for i := 0; i < config.Key("ON").Len(); i++ {
+		on[RefOf(config.Key("ON").Index(i))] = true
+	}
+	groups := properties.Key("OCGs")
+	for i := 0; i < groups.Len(); i++ {
+		group := groups.Index(i)
+		if !on[RefOf(group)] {
+			hidden[RefOf(group)] = group.Key("Name").Text()
+		}
+	}
+	return hidden
+}
+
+// InHiddenLayer reports whether content marked with an optional content group or
+// membership dictionary is hidden: a group in hidden, or a membership whose groups are all
+// hidden
+func InHiddenLayer(oc pdf.Value, hidden map[ObjectRef]string) bool {
+	if oc.Kind() != pdf.Dict || len(hidden) == 0 {
+		return false
+	}
+	if oc.Key("Type").Name() != "OCMD" {
+		_, ok := hidden[RefOf(oc)]
+		return ok
+	}
+
+	groups := oc.Key("OCGs")
+	if groups.Kind() == pdf.Dict {
+		_, ok := hidden[RefOf(groups)]
+		return ok
+	}
+	for i := 0; i < groups.Len(); i++ {
+		if _, ok := hidden[RefOf(groups.Index(i))]; !ok {
+			return false
+		}
+	}
+	return groups.Len() > 0
+}
+
+// StripHiddenLayers rewrites a page's content stream without the marked content sequences
+// and XObjects that belong to hidden layers, returning the new content and how many
+// sequences and XObjects were removed. Content is nil when nothing is hidden. Hidden content
+// inside form XObjects is left in place.
+func StripHiddenLayers(page pdf.Page, hidden map[ObjectRef]string) (content []byte, removed int, err error) {
+	if len(hidden) == 0 {
+		return nil, 0, nil
+	}
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			content, removed, err = nil, 0, fmt.Errorf("failed to read page content: %v", rec)
+		}
+	}()
+
+	data, err := readContents(page.V.Key("Contents"))
+	if err != nil {
+		return nil, 0, err
+	}
+	ops, err := parseContent(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resources := page.Resources()
+	var out bytes.Buffer
+	skipping := 0 // Depth of marked content within a hidden sequence, 0 outside one
+	for _, op := range ops {
+		args := op.operands
+		switch {
+		case skipping > 0:
+			switch op.operator {
+			case "BMC", "BDC":
+				skipping++
+			case "EMC":
+				skipping--
+			}
+			continue
+		case op.operator == "BDC" && len(args) == 2 && args[0].text == "OC" && args[1].kind == contentName &&
+			InHiddenLayer(resources.Key("Properties").Key(args[1].text), hidden):
+			skipping = 1
+			removed++
+			continue
+		case op.operator == "Do" && len(args) == 1 &&
+			InHiddenLayer(resources.Key("XObject").Key(args[0].text).Key("OC"), hidden):
+			removed++
+			continue
+		}
+		out.Write(data[op.start:op.end])
+		out.WriteByte('\n')
+	}
+	if removed == 0 {
+		return nil, 0, nil
+	}
+	return out.Bytes(), removed, nil
+}
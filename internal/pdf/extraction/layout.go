@@ -0,0 +1,242 @@
+package extraction
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Layout constants for grouping positioned glyphs
+const (
+	// wordGapRatio is the gap, relative to font size, that separates two words
+	wordGapRatio = 0.25
+	// lineToleranceRatio is the baseline difference, relative to font size, tolerated within a line
+	lineToleranceRatio = 0.5
+	// glyphDescentRatio is the portion of the font size drawn below the baseline
+	glyphDescentRatio = 0.2
+)
+
+// pageGlyphs returns the positioned glyphs drawn on a page, recovering from parser panics.
+// Glyphs under redaction annotations are left out. Pages drawn with composite fonts are read
+// through the fonts' CMaps.
+func pageGlyphs(page pdf.Page) (glyphs []pdf.Text, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			glyphs = nil
+			err = fmt.Errorf("failed to read page content: %v", r)
+		}
+	}()
+
+	var drawn []pdf.Text
+	if pageHasCompositeFonts(page) {
+		decoded, err := decodePageText(page)
+		if err != nil {
+			return nil, err
+		}
+		drawn = decoded.glyphs
+	} else {
+		drawn = page.Content().Text
+	}
+
+	var runs []int // Index of the first glyph of each string drawn
+	var prevX, prevY, prevEnd float64
+	for i, t := range drawn {
+		// Skip the synthetic line breaks inserted after TJ arrays
+		if t.S == "\n" || t.S == "" {
+			continue
+		}
+
+		// Without a width table (standard fonts, and CID fonts the parser cannot measure) the
+		// parser cannot advance the text position, so every glyph of a string starts at the
+		// same point; spread them by the fallback width
+		x, y := t.X, t.Y
+		if i > 0 && t.W <= 0 && x == prevX && y == prevY {
+			t.X = prevEnd
+		} else {
+			runs = append(runs, len(glyphs))
+		}
+		prevX, prevY = x, y
+		prevEnd = glyphBox(t).UpperRight.X
+		glyphs = append(glyphs, t)
+	}
+	squeezeRuns(glyphs, runs)
+
+	redactions := PageRedactions(page)
+	if len(redactions) == 0 {
+		return glyphs, nil
+	}
+	kept := glyphs[:0]
+	for _, t := range glyphs {
+		if !redacted(redactions, glyphBox(t)) {
+			kept = append(kept, t)
+		}
+	}
+	return kept, nil
+}
+
+// squeezeRuns narrows strings spread by the fallback width that run past the start of the
+// next string on the same baseline, so their glyphs end where the next string begins rather
+// than interleaving with it. runs holds the index of the first glyph of each string.
+func squeezeRuns(glyphs []pdf.Text, runs []int) {
+	for r := 0; r+1 < len(runs); r++ {
+		start, end := runs[r], runs[r+1]
+		first, next := glyphs[start], glyphs[end]
+		if end-start < 2 || first.W > 0 || next.Y != first.Y {
+			continue
+		}
+		runEnd := glyphBox(glyphs[end-1]).UpperRight.X
+		if next.X <= first.X || next.X >= runEnd {
+			continue
+		}
+
+		scale := (next.X - first.X) / (runEnd - first.X)
+		for i := start; i < end; i++ {
+			glyphs[i].X = first.X + (glyphs[i].X-first.X)*scale
+			glyphs[i].W = (glyphBox(glyphs[i]).UpperRight.X - glyphBox(glyphs[i]).LowerLeft.X) * scale
+		}
+	}
+}
+
+// glyphBox returns the approximate bounding box of a single glyph
+func glyphBox(g pdf.Text) BoundingBox {
+	height := g.FontSize
+	if height <= 0 {
+		height = defaultFontSize
+	}
+	width := g.W
+	if width <= 0 {
+		width = height / 2
+	}
+	return boxFromPoints(
+		Coordinate{X: g.X, Y: g.Y - height*glyphDescentRatio},
+		Coordinate{X: g.X + width, Y: g.Y + height*(1-glyphDescentRatio)},
+	)
+}
+
+// positionedWord is a run of glyphs on the same baseline without a significant gap
+type positionedWord struct {
+	Text     string
+	Box      BoundingBox
+	FontName string
+	FontSize float64
+	Line     int         // Index of the line in reading order
+	Glyphs   []glyphSpan // Position of each glyph's text within Text
+}
+
+// glyphSpan is the byte range of one glyph's text within a word and the glyph's box
+type glyphSpan struct {
+	Start, End int
+	Box        BoundingBox
+}
+
+// groupGlyphsIntoWords sorts glyphs into reading order and merges adjacent ones into words
+func groupGlyphsIntoWords(glyphs []pdf.Text) []positionedWord {
+	if len(glyphs) == 0 {
+		return nil
+	}
+
+	sorted := make([]pdf.Text, len(glyphs))
+	copy(sorted, glyphs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		tol := math.Max(sorted[i].FontSize, sorted[j].FontSize) * lineToleranceRatio
+		if math.Abs(sorted[i].Y-sorted[j].Y) > tol {
+			return sorted[i].Y > sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+
+	var words []positionedWord
+	var builder strings.Builder
+	var current positionedWord
+	var lastEnd float64
+	var lastY float64
+	line := 0
+
+	flush := func() {
+		if builder.Len() > 0 {
+			current.Text = builder.String()
+			words = append(words, current)
+		}
+		builder.Reset()
+	}
+
+	for i, g := range sorted {
+		box := glyphBox(g)
+		isSpace := strings.TrimSpace(g.S) == ""
+		if i > 0 {
+			tol := math.Max(g.FontSize, current.FontSize) * lineToleranceRatio
+			gap := g.X - lastEnd
+			newLine := math.Abs(g.Y-lastY) > tol
+			if newLine {
+				line++
+			}
+			if newLine || isSpace || gap > math.Max(g.FontSize, 1)*wordGapRatio {
+				flush()
+			}
+		}
+		lastEnd = box.UpperRight.X
+		lastY = g.Y
+		if isSpace {
+			continue
+		}
+		if builder.Len() == 0 {
+			current = positionedWord{Box: box, FontName: g.Font, FontSize: g.FontSize, Line: line}
+		} else {
+			current.Box = unionBoxes(current.Box, box)
+		}
+		start := builder.Len()
+		builder.WriteString(g.S)
+		current.Glyphs = append(current.Glyphs, glyphSpan{Start: start, End: builder.Len(), Box: box})
+	}
+	flush()
+
+	return words
+}
+
+// PageWords returns the words drawn on a page in reading order with their positions
+func PageWords(page pdf.Page) ([]WordElement, error) {
+	glyphs, err := pageGlyphs(page)
+	if err != nil {
+		return nil, err
+	}
+
+	positioned := groupGlyphsIntoWords(glyphs)
+	words := make([]WordElement, len(positioned))
+	for i, w := range positioned {
+		words[i] = WordElement{
+			Text:        w.Text,
+			BoundingBox: w.Box,
+			Properties: TextProperties{
+				FontName: w.FontName,
+				FontSize: w.FontSize,
+			},
+			Confidence: 1.0,
+		}
+	}
+	return words, nil
+}
+
+// unionBoxes returns the smallest bounding box containing both boxes
+func unionBoxes(a, b BoundingBox) BoundingBox {
+	return boxFromPoints(
+		Coordinate{X: math.Min(a.LowerLeft.X, b.LowerLeft.X), Y: math.Min(a.LowerLeft.Y, b.LowerLeft.Y)},
+		Coordinate{X: math.Max(a.UpperRight.X, b.UpperRight.X), Y: math.Max(a.UpperRight.Y, b.UpperRight.Y)},
+	)
+}
+
+// boxCenter returns the center point of a bounding box
+func boxCenter(b BoundingBox) Coordinate {
+	return Coordinate{
+		X: (b.LowerLeft.X + b.UpperRight.X) / 2,
+		Y: (b.LowerLeft.Y + b.UpperRight.Y) / 2,
+	}
+}
+
+// boxContains reports whether the point lies inside the bounding box
+func boxContains(b BoundingBox, p Coordinate) bool {
+	return p.X >= b.LowerLeft.X && p.X <= b.UpperRight.X &&
+		p.Y >= b.LowerLeft.Y && p.Y <= b.UpperRight.Y
+}
@@ -0,0 +1,37 @@
+package extraction
+
+import (
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+)
+
+func TestSqueezeRuns(t *testing.T) {
+	// Two strings on one baseline spread by the fallback width of 5: "abcd" from 100 to 120,
+	// running past "ef" at 110, and a string on the next line that is left alone
+	glyphs := []pdf.Text{
+		{S: "a", X: 100, Y: 700, FontSize: 10},
+		{S: "b", X: 105, Y: 700, FontSize: 10},
+		{S: "c", X: 110, Y: 700, FontSize: 10},
+		{S: "d", X: 115, Y: 700, FontSize: 10},
+		{S: "e", X: 110, Y: 700, FontSize: 10},
+		{S: "f", X: 115, Y: 700, FontSize: 10},
+		{S: "g", X: 100, Y: 680, FontSize: 10},
+		{S: "h", X: 105, Y: 680, FontSize: 10},
+	}
+	squeezeRuns(glyphs, []int{0, 4, 6})
+
+	for i, want := range []float64{100, 102.5, 105, 107.5, 110, 115, 100, 105} {
+		if glyphs[i].X != want {
+			t.Errorf("glyph %q X = %g, want %g", glyphs[i].S, glyphs[i].X, want)
+		}
+	}
+	if end := glyphBox(glyphs[3]).UpperRight.X; end != 110 {
+		t.Errorf("squeezed string ends at %g, want 110 where the next begins", end)
+	}
+
+	words := groupGlyphsIntoWords(glyphs)
+	if len(words) != 2 || words[0].Text != "abcdef" || words[1].Text != "gh" {
+		t.Errorf("groupGlyphsIntoWords() = %+v, want abcdef and gh", words)
+	}
+}
@@ -0,0 +1,89 @@
+package extraction
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Limits bound the work of a single extraction, so that a document under the file size
+// limit with tens of thousands of pages, or one pathological page, cannot hold the server.
+// Zero values disable a limit.
+type Limits struct {
+	MaxPages    int           // Pages processed per request; later requested pages are left out
+	MaxElements int           // Elements returned per request; later elements are dropped
+	PageTimeout time.Duration // Time allowed for each page before it is skipped
+}
+
+// SetLimits bounds the pages, elements, and per-page processing time of every extraction
+func (e *DefaultEngine) SetLimits(limits Limits) {
+	e.limits = limits
+}
+
+// truncate records that a limit cut the result short. Pages left out make the result partial.
+func (r *ExtractionResult) truncate(message string, pagesLeftOut bool) {
+	r.Truncations = append(r.Truncations, message)
+	r.addIssue(newParseIssue(SeverityWarning, StagePage, 0, pdf.Value{}, errors.New(message)))
+	if pagesLeftOut {
+		r.Partial = true
+	}
+}
+
+// limitPages keeps the first pages of a request within the page limit
+func (e *DefaultEngine) limitPages(result *ExtractionResult, pages []int) []int {
+	limit := e.limits.MaxPages
+	if limit <= 0 || len(pages) <= limit {
+		return pages
+	}
+	result.truncate(fmt.Sprintf("only the first %d of %d requested pages were processed, the server's page "+
+		"limit; request the others with pages", limit, len(pages)), true)
+	return pages[:limit]
+}
+
+// limitElements drops the elements past the element limit
+func (e *DefaultEngine) limitElements(result *ExtractionResult) {
+	limit := e.limits.MaxElements
+	if limit <= 0 || len(result.Elements) <= limit {
+		return
+	}
+	result.truncate(fmt.Sprintf("only the first %d of %d elements were returned, the server's element limit; "+
+		"narrow the request with pages, region, or a query", limit, len(result.Elements)), false)
+	result.Elements = result.Elements[:limit]
+}
+
+// extractPageWithin extracts a page, giving up on it once the page timeout passes. The parser
+// cannot be interrupted, so a page that runs over keeps reading in the background, tracked by
+// stragglers so the document stays open until it stops; what it extracts is discarded.
+func (e *DefaultEngine) extractPageWithin(
+	numbering *PageNumbering, pageNum int, config ExtractionConfig, links *LinkResolver,
+	stragglers *sync.WaitGroup,
+) pageOutcome {
+	timeout := e.limits.PageTimeout
+	if timeout <= 0 {
+		return e.extractPage(numbering, pageNum, config, links)
+	}
+
+	done := make(chan pageOutcome, 1)
+	stragglers.Add(1)
+	go func() {
+		defer stragglers.Done()
+		done <- e.extractPage(numbering, pageNum, config, links)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case outcome := <-done:
+		return outcome
+	case <-timer.C:
+	}
+
+	outcome := pageOutcome{timedOut: true}
+	outcome.timing.Page = pageNum
+	outcome.timing.Total = timeout
+	outcome.scratch.addIssue(newParseIssue(SeverityError, StagePage, pageNum, pdf.Value{},
+		fmt.Errorf("page skipped after exceeding the per-page time limit of %s", timeout)))
+	return outcome
+}
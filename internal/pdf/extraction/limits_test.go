@@ -0,0 +1,61 @@
+package extraction
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtract_Limits(t *testing.T) {
+	path := writePagesPDF(t, 6)
+	request := ExtractionRequest{
+		FilePath: path,
+		Config:   ExtractionConfig{Mode: ModeStructured, ExtractText: true, MaxWorkers: 2},
+	}
+
+	engine := NewEngine()
+	engine.SetLimits(Limits{MaxPages: 4, MaxElements: 3})
+	result, err := engine.Extract(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	if !reflect.DeepEqual(result.ProcessedPages, []int{1, 2, 3, 4}) || !result.Partial {
+		t.Errorf("Extract() processed pages %v (partial %t), want the first 4 of a partial result",
+			result.ProcessedPages, result.Partial)
+	}
+	if len(result.Elements) != 3 || result.ExtractionInfo.ElementCounts.Total != 3 {
+		t.Errorf("Extract() returned %d elements, want 3", len(result.Elements))
+	}
+	if len(result.Truncations) != 2 || !strings.Contains(result.Truncations[0], "first 4 of 6 requested pages") ||
+		!strings.Contains(result.Truncations[1], "first 3 of 4 elements") {
+		t.Errorf("Extract() truncations = %q, want the page and element limits", result.Truncations)
+	}
+	if len(result.Warnings) < 2 {
+		t.Errorf("Extract() warnings = %q, want the truncations reported", result.Warnings)
+	}
+
+	// Pages running over the page timeout are skipped
+	engine = NewEngine()
+	engine.SetLimits(Limits{PageTimeout: time.Nanosecond})
+	result, err = engine.Extract(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Extract(page timeout) unexpected error = %v", err)
+	}
+	if len(result.Elements) != 0 || !result.Partial || len(result.Truncations) != 1 ||
+		!strings.Contains(result.Truncations[0], "6 pages exceeded") {
+		t.Errorf("Extract(page timeout) = %d elements, truncations %q, want every page skipped",
+			len(result.Elements), result.Truncations)
+	}
+
+	// Without limits every page and element is returned
+	result, err = NewEngine().Extract(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	if len(result.ProcessedPages) != 6 || len(result.Elements) != 6 || result.Partial || result.Truncations != nil {
+		t.Errorf("Extract() without limits = %d pages, %d elements, truncations %q",
+			len(result.ProcessedPages), len(result.Elements), result.Truncations)
+	}
+}
@@ -0,0 +1,110 @@
+package extraction
+
+import (
+	"github.com/ledongthuc/pdf"
+)
+
+// Link actions
+const (
+	LinkActionURI    = "uri"    // Web or mail link
+	LinkActionGoTo   = "goto"   // Destination in this document
+	LinkActionGoToR  = "gotor"  // Destination in another PDF file
+	LinkActionLaunch = "launch" // Opens an external file or application
+	LinkActionNamed  = "named"  // Viewer action such as NextPage
+	LinkActionOther  = "other"
+)
+
+// Link describes where a link annotation leads
+type Link struct {
+	Action           string  `json:"action"`
+	URI              string  `json:"uri,omitempty"`
+	TargetPage       int     `json:"target_page,omitempty"`       // 1-based page in the target document
+	TargetPageLabel  string  `json:"target_page_label,omitempty"` // Label of a target page in this document
+	TargetFile       string  `json:"target_file,omitempty"`       // File opened by GoToR and Launch actions
+	NamedDestination string  `json:"named_destination,omitempty"` // Destination name before resolution
+	DestinationType  string  `json:"destination_type,omitempty"`  // XYZ, Fit, FitH, ...
+	Left             float64 `json:"left,omitempty"`
+	Top              float64 `json:"top,omitempty"`
+	Zoom             float64 `json:"zoom,omitempty"`
+}
+
+// LinkResolver resolves link annotations against a document and its page numbering. It is
+// safe for concurrent use.
+type LinkResolver struct {
+	reader *pdf.Reader
+	pages  *PageNumbering
+}
+
+// NewLinkResolver creates a link resolver for a document
+func NewLinkResolver(r *pdf.Reader, pages *PageNumbering) *LinkResolver {
+	return &LinkResolver{reader: r, pages: pages}
+}
+
+// Resolve describes the target of a Link annotation from its /Dest entry or /A action
+func (l *LinkResolver) Resolve(annot pdf.Value) Link {
+	if dest := annot.Key("Dest"); !dest.IsNull() {
+		link := Link{Action: LinkActionGoTo}
+		l.applyDestination(&link, dest, true)
+		return link
+	}
+
+	action := annot.Key("A")
+	switch action.Key("S").Name() {
+	case "URI":
+		return Link{Action: LinkActionURI, URI: action.Key("URI").RawString()}
+	case "GoTo":
+		link := Link{Action: LinkActionGoTo}
+		l.applyDestination(&link, action.Key("D"), true)
+		return link
+	case "GoToR":
+		link := Link{Action: LinkActionGoToR, TargetFile: FileSpecPath(action.Key("F"))}
+		l.applyDestination(&link, action.Key("D"), false)
+		return link
+	case "Launch":
+		return Link{Action: LinkActionLaunch, TargetFile: FileSpecPath(action.Key("F"))}
+	case "Named":
+		return Link{Action: LinkActionNamed, NamedDestination: action.Key("N").Name()}
+	default:
+		return Link{Action: LinkActionOther}
+	}
+}
+
+// applyDestination fills in the target page and view. Named destinations can only be
+// resolved within this document; remote ones are reported by name.
+func (l *LinkResolver) applyDestination(link *Link, dest pdf.Value, local bool) {
+	switch dest.Kind() {
+	case pdf.Name:
+		link.NamedDestination = dest.Name()
+	case pdf.String:
+		link.NamedDestination = dest.Text()
+	}
+
+	var pages *PageNumbering
+	if local {
+		dest = ResolveDestination(l.reader, dest)
+		pages = l.pages
+	}
+
+	target := ParseDestination(dest, pages)
+	link.TargetPage = target.Page
+	if local {
+		link.TargetPageLabel = l.pages.Label(target.Page)
+	}
+	link.DestinationType = target.Type
+	link.Left = target.Left
+	link.Top = target.Top
+	link.Zoom = target.Zoom
+}
+
+// FileSpecPath returns the path named by a file specification string or dictionary
+func FileSpecPath(spec pdf.Value) string {
+	if spec.Kind() == pdf.String {
+		return spec.Text()
+	}
+	for _, key := range []string{"UF", "F", "Unix", "DOS", "Mac"} {
+		if path := spec.Key(key).Text(); path != "" {
+			return path
+		}
+	}
+	return ""
+}
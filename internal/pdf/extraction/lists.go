@@ -0,0 +1,228 @@
+package extraction
+
+import (
+	"regexp"
+	"strings"
+)
+
+// List marker styles
+const (
+	MarkerBullet     = "bullet"
+	MarkerDecimal    = "decimal"
+	MarkerLowerAlpha = "lower-alpha"
+	MarkerUpperAlpha = "upper-alpha"
+	MarkerLowerRoman = "lower-roman"
+	MarkerUpperRoman = "upper-roman"
+)
+
+// List reconstruction constants
+const (
+	// minListItems is how many items in a row make a list; a single numbered line is more
+	// likely a numbered heading
+	minListItems = 2
+	// listIndentTolerance is how far, in points, the markers of items at one level may lie
+	// apart
+	listIndentTolerance = 3.0
+)
+
+var (
+	// listMarker matches the bullet, number, or letter opening a list item and the space
+	// after it
+	listMarker = regexp.MustCompile(`^\s*([-•*–·▪◦‣○■□➢►]|` +
+		`\(?(?:\d{1,3}|[a-zA-Z]|[ivxlcdm]{1,6}|[IVXLCDM]{1,6})[.)])\s+`)
+	// romanLetters matches the letters of a Roman numeral
+	romanLetters = regexp.MustCompile(`^(?i)[ivxlcdm]+$`)
+)
+
+// Text returns the text of the list's items, one per line
+func (l ListElement) Text() string {
+	texts := make([]string, len(l.Items))
+	for i, item := range l.Items {
+		texts[i] = item.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// markerStyle names the style of a list marker given the item before it. Of the single
+// letters only i, v, and x are read as Roman numerals, and not when they follow the letter
+// before them, as "i." follows "h.".
+func markerStyle(marker string, previous ListItem) string {
+	value := strings.Trim(marker, "().")
+	switch {
+	case value == "" || strings.ContainsAny(value, "-•*–·▪◦‣○■□➢►"):
+		return MarkerBullet
+	case value[0] >= '0' && value[0] <= '9':
+		return MarkerDecimal
+	case romanLetters.MatchString(value) && (len(value) > 1 ||
+		strings.ContainsAny(value, "ivxIVX") && !followsLetter(value, previous.Marker)):
+		if value == strings.ToLower(value) {
+			return MarkerLowerRoman
+		}
+		return MarkerUpperRoman
+	case value == strings.ToLower(value):
+		return MarkerLowerAlpha
+	default:
+		return MarkerUpperAlpha
+	}
+}
+
+// followsLetter reports whether a single-letter marker value comes right after the letter
+// of the previous marker
+func followsLetter(value, previous string) bool {
+	previous = strings.Trim(previous, "().")
+	return len(value) == 1 && len(previous) == 1 && value[0] == previous[0]+1
+}
+
+// listRow is a line of a page as drawn, used to find where list items start
+type listRow struct {
+	first string
+	box   BoundingBox
+}
+
+// pageListRows returns the lines of a page from the top down with their first words
+func pageListRows(numbering *PageNumbering, pageNum int) []listRow {
+	words, err := PageWords(numbering.Page(pageNum))
+	if err != nil {
+		return nil
+	}
+	var rows []listRow
+	for _, row := range wordRows(words) {
+		box := row[0].BoundingBox
+		for _, word := range row {
+			box = unionBoxes(box, word.BoundingBox)
+		}
+		rows = append(rows, listRow{first: row[0].Text, box: box})
+	}
+	return rows
+}
+
+// reconstructLists replaces runs of text elements opening with list markers by list
+// elements. Nesting comes from how far each item's marker is indented, read from the page,
+// or from changes of marker style when the page gives no positions.
+func (e *DefaultEngine) reconstructLists(result *ExtractionResult, config ExtractionConfig, numbering *PageNumbering) {
+	if config.Mode != ModeStructured && config.Mode != ModeComplete {
+		return
+	}
+
+	itemText := func(element ContentElement) (string, bool) {
+		text, ok := element.Content.(TextElement)
+		if !ok || element.Type != ContentTypeText || element.Parent != nil || !listMarker.MatchString(text.Text) {
+			return "", false
+		}
+		return text.Text, true
+	}
+
+	rows := make(map[int][]listRow)
+	used := make(map[int]int) // Rows of each page already matched to items
+	elements := make([]ContentElement, 0, len(result.Elements))
+	lists := 0
+	for i := 0; i < len(result.Elements); {
+		element := result.Elements[i]
+		run := 0
+		for i+run < len(result.Elements) && result.Elements[i+run].PageNumber == element.PageNumber {
+			if _, ok := itemText(result.Elements[i+run]); !ok {
+				break
+			}
+			run++
+		}
+		if run < minListItems {
+			elements = append(elements, element)
+			i++
+			continue
+		}
+
+		pageNum := element.PageNumber
+		if _, ok := rows[pageNum]; !ok {
+			rows[pageNum] = pageListRows(numbering, pageNum)
+		}
+		list := ContentElement{
+			ID:          e.generateID("list", pageNum, lists),
+			Type:        ContentTypeList,
+			PageNumber:  pageNum,
+			PageLabel:   element.PageLabel,
+			BoundingBox: element.BoundingBox,
+			Confidence:  element.Confidence,
+		}
+		items := make([]ListItem, run)
+		indents := make([]float64, run)
+		positioned := true
+		for j, source := range result.Elements[i : i+run] {
+			text, _ := itemText(source)
+			match := listMarker.FindStringSubmatch(text)
+			var previous ListItem
+			if j > 0 {
+				previous = items[j-1]
+			}
+			items[j] = ListItem{
+				Text:        strings.TrimSpace(text[len(match[0]):]),
+				Marker:      match[1],
+				MarkerStyle: markerStyle(match[1], previous),
+				BoundingBox: source.BoundingBox,
+			}
+
+			// The item's line on the page is the next one opening with its marker
+			found := false
+			for k := used[pageNum]; k < len(rows[pageNum]); k++ {
+				if rows[pageNum][k].first == match[1] {
+					items[j].BoundingBox = rows[pageNum][k].box
+					indents[j] = rows[pageNum][k].box.LowerLeft.X
+					used[pageNum] = k + 1
+					found = true
+					break
+				}
+			}
+			positioned = positioned && found
+			// The list covers the lines its items run on to as well as their marker lines
+			list.BoundingBox = unionBoxes(list.BoundingBox, unionBoxes(items[j].BoundingBox, source.BoundingBox))
+			list.Confidence = min(list.Confidence, source.Confidence)
+		}
+		if positioned {
+			nestByIndent(items, indents)
+		} else {
+			nestByMarkerStyle(items)
+		}
+
+		list.Content = ListElement{Ordered: items[0].MarkerStyle != MarkerBullet, Items: items}
+		elements = append(elements, list)
+		lists++
+		i += run
+	}
+	result.Elements = elements
+}
+
+// nestByIndent sets the level of each item from how far its marker is indented beyond the
+// items before it
+func nestByIndent(items []ListItem, indents []float64) {
+	stack := []float64{indents[0]}
+	for i := range items {
+		for len(stack) > 1 && indents[i] < stack[len(stack)-1]-listIndentTolerance {
+			stack = stack[:len(stack)-1]
+		}
+		if indents[i] > stack[len(stack)-1]+listIndentTolerance {
+			stack = append(stack, indents[i])
+		}
+		items[i].Level = len(stack) - 1
+	}
+}
+
+// nestByMarkerStyle sets the level of each item from its marker style: a new style opens a
+// nested level, and a style used further out closes the levels inside it
+func nestByMarkerStyle(items []ListItem) {
+	stack := []string{items[0].MarkerStyle}
+	for i := range items {
+		style := items[i].MarkerStyle
+		level := -1
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j] == style {
+				level = j
+				break
+			}
+		}
+		if level < 0 {
+			stack = append(stack, style)
+			level = len(stack) - 1
+		}
+		stack = stack[:level+1]
+		items[i].Level = level
+	}
+}
@@ -0,0 +1,101 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestExtract_ReconstructsLists(t *testing.T) {
+	content := "BT /F1 10 Tf 72 720 Td (Follow these steps to install and start the server on a new machine:) Tj ET\n" +
+		"BT /F1 10 Tf 72 700 Td (1. Install the server) Tj ET\n" +
+		"BT /F1 10 Tf 90 686 Td (a. Download the binary) Tj ET\n" +
+		"BT /F1 10 Tf 90 672 Td (b. Unpack the archive) Tj ET\n" +
+		"BT /F1 10 Tf 72 658 Td (2. Start the server) Tj ET\n" +
+		"BT /F1 10 Tf 72 630 Td (The server then listens on standard input and output for its clients.) Tj ET\n" +
+		"BT /F1 10 Tf 72 610 Td (- Linux) Tj ET\n" +
+		"BT /F1 10 Tf 72 596 Td (- macOS) Tj ET"
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents 5 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+	}
+	path := writeRawPDF(t, "steps.pdf", objects)
+
+	result, err := NewEngine().Extract(context.Background(), ExtractionRequest{
+		FilePath: path, Config: ExtractionConfig{Mode: ModeStructured, ExtractText: true},
+	})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	var lists []ListElement
+	var types []ContentType
+	for _, element := range result.Elements {
+		types = append(types, element.Type)
+		if list, ok := element.Content.(ListElement); ok {
+			lists = append(lists, list)
+		}
+	}
+	want := []ContentType{ContentTypeText, ContentTypeList, ContentTypeText, ContentTypeList}
+	if fmt.Sprint(types) != fmt.Sprint(want) {
+		t.Fatalf("Extract() element types = %v, want %v", types, want)
+	}
+
+	steps := lists[0]
+	wantItems := []ListItem{
+		{Text: "Install the server", Marker: "1.", MarkerStyle: MarkerDecimal, Level: 0},
+		{Text: "Download the binary", Marker: "a.", MarkerStyle: MarkerLowerAlpha, Level: 1},
+		{Text: "Unpack the archive", Marker: "b.", MarkerStyle: MarkerLowerAlpha, Level: 1},
+		{Text: "Start the server", Marker: "2.", MarkerStyle: MarkerDecimal, Level: 0},
+	}
+	if !steps.Ordered || len(steps.Items) != len(wantItems) {
+		t.Fatalf("steps = %+v, want an ordered list of %d items", steps, len(wantItems))
+	}
+	for i, want := range wantItems {
+		item := steps.Items[i]
+		if item.Text != want.Text || item.Marker != want.Marker || item.MarkerStyle != want.MarkerStyle ||
+			item.Level != want.Level {
+			t.Errorf("item %d = %+v, want %+v", i, item, want)
+		}
+	}
+	if x := steps.Items[1].BoundingBox.LowerLeft.X; x < 89 || x > 91 {
+		t.Errorf("nested item starts at x = %g, want its position on the page", x)
+	}
+
+	platforms := lists[1]
+	if platforms.Ordered || len(platforms.Items) != 2 || platforms.Items[1].Text != "macOS" ||
+		platforms.Items[1].MarkerStyle != MarkerBullet {
+		t.Errorf("platforms = %+v, want a bulleted list of two items", platforms)
+	}
+}
+
+func TestNestByMarkerStyle(t *testing.T) {
+	markers := []string{"1.", "a)", "i.", "ii.", "b)", "2.", "3."}
+	wantStyles := []string{
+		MarkerDecimal, MarkerLowerAlpha, MarkerLowerRoman, MarkerLowerRoman, MarkerLowerAlpha, MarkerDecimal,
+		MarkerDecimal,
+	}
+	wantLevels := []int{0, 1, 2, 2, 1, 0, 0}
+
+	items := make([]ListItem, len(markers))
+	var previous ListItem
+	for i, marker := range markers {
+		items[i] = ListItem{Marker: marker, MarkerStyle: markerStyle(marker, previous)}
+		previous = items[i]
+	}
+	nestByMarkerStyle(items)
+	for i, item := range items {
+		if item.MarkerStyle != wantStyles[i] || item.Level != wantLevels[i] {
+			t.Errorf("item %q = %s at level %d, want %s at level %d",
+				item.Marker, item.MarkerStyle, item.Level, wantStyles[i], wantLevels[i])
+		}
+	}
+
+	// Letters continuing a lettered list stay letters
+	if style := markerStyle("i.", ListItem{Marker: "h."}); style != MarkerLowerAlpha {
+		t.Errorf("markerStyle(i. after h.) = %s, want %s", style, MarkerLowerAlpha)
+	}
+}
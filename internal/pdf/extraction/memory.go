@@ -0,0 +1,313 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Estimated in-memory sizes, in bytes, used to charge extracted pages against the memory
+// budget. They include the slice, string, and interface headers around each value.
+const (
+	elementBytes = 512 // A content element with its ID, box, and content
+	wordBytes    = 160 // A positioned word or line
+	commandBytes = 64  // A vector path command
+	pointBytes   = 16  // A point of an annotation's markup or ink
+	cellBytes    = 256 // A table cell with its box
+)
+
+// spillFilePerm keeps spilled pages readable only by the server
+const spillFilePerm = 0o600
+
+// defaultMemoryWait is how long a spilled page waits for memory released by other
+// extractions before it is left out of the result
+const defaultMemoryWait = 2 * time.Second
+
+// MemoryBudget bounds the memory held by extracted pages across all extractions running at
+// once. Finished pages reserve their estimated size until their extraction returns; a page
+// that does not fit is spilled to a temporary file and read back once memory is released.
+// A zero limit disables the budget.
+type MemoryBudget struct {
+	limit int64
+
+	mu       sync.Mutex
+	used     int64
+	peak     int64
+	released chan struct{} // Closed and replaced whenever memory is released
+}
+
+// MemoryStats reports the occupancy of a memory budget
+type MemoryStats struct {
+	Limit int64 `json:"limit"`
+	InUse int64 `json:"in_use"`
+	Peak  int64 `json:"peak"`
+}
+
+// NewMemoryBudget creates a budget of limit bytes; zero disables it
+func NewMemoryBudget(limit int64) *MemoryBudget {
+	return &MemoryBudget{limit: max(limit, 0), released: make(chan struct{})}
+}
+
+// Enabled reports whether the budget limits memory
+func (b *MemoryBudget) Enabled() bool {
+	return b != nil && b.limit > 0
+}
+
+// TryReserve reserves n bytes, reporting false without reserving when they do not fit
+func (b *MemoryBudget) TryReserve(n int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit > 0 && b.used+n > b.limit {
+		return false
+	}
+	b.used += n
+	b.peak = max(b.peak, b.used)
+	return true
+}
+
+// Reserve reserves n bytes, waiting up to wait for other extractions to release enough.
+// It reports false when the wait ends or ctx is done first.
+func (b *MemoryBudget) Reserve(ctx context.Context, n int64, wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		// Take the channel before trying, so that a release in between is not missed
+		b.mu.Lock()
+		released := b.released
+		b.mu.Unlock()
+		if b.TryReserve(n) {
+			return true
+		}
+		select {
+		case <-released:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// Release returns n reserved bytes to the budget and wakes reservations waiting for them
+func (b *MemoryBudget) Release(n int64) {
+	if n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used = max(b.used-n, 0)
+	close(b.released)
+	b.released = make(chan struct{})
+}
+
+// Stats reports the budget's limit, current reservations, and the most ever reserved
+func (b *MemoryBudget) Stats() MemoryStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return MemoryStats{Limit: b.limit, InUse: b.used, Peak: b.peak}
+}
+
+// SetMemoryBudget limits the memory extracted pages may hold across concurrent extractions
+// to limit bytes; zero removes the limit. Replacing the budget does not affect extractions
+// already running.
+func (e *DefaultEngine) SetMemoryBudget(limit int64) {
+	e.memory = NewMemoryBudget(limit)
+	e.memoryWait = defaultMemoryWait
+}
+
+// MemoryStats reports the occupancy of the engine's memory budget
+func (e *DefaultEngine) MemoryStats() MemoryStats {
+	if e.memory == nil {
+		return MemoryStats{}
+	}
+	return e.memory.Stats()
+}
+
+// pageSpool keeps the finished pages of one extraction within the memory budget. A nil
+// spool holds every page in memory.
+type pageSpool struct {
+	budget *MemoryBudget
+	wait   time.Duration
+
+	mu       sync.Mutex
+	reserved int64
+	spilled  int
+	starved  bool // A page waited for memory in vain, so later pages do not wait
+	files    map[string]bool
+}
+
+// newSpool returns the spool of one extraction, or nil without a memory budget
+func (e *DefaultEngine) newSpool() *pageSpool {
+	if !e.memory.Enabled() {
+		return nil
+	}
+	return &pageSpool{budget: e.memory, wait: e.memoryWait, files: make(map[string]bool)}
+}
+
+// hold charges a finished page against the budget. A page that does not fit is written to
+// a temporary file and the outcome returned keeps only its timing and issues; a page that
+// cannot be written either is dropped.
+func (s *pageSpool) hold(outcome pageOutcome) pageOutcome {
+	if s == nil {
+		return outcome
+	}
+	outcome.size = outcomeSize(outcome)
+	if s.budget.TryReserve(outcome.size) {
+		s.mu.Lock()
+		s.reserved += outcome.size
+		s.mu.Unlock()
+		return outcome
+	}
+
+	path, err := spillPage(outcome)
+	outcome.elements = nil
+	outcome.tables = nil
+	if err != nil {
+		logger.Warn("page over the memory budget dropped", "page", outcome.timing.Page, "error", err)
+		outcome.dropped = true
+		return outcome
+	}
+	outcome.spill = path
+	s.mu.Lock()
+	s.spilled++
+	s.files[path] = true
+	s.mu.Unlock()
+	return outcome
+}
+
+// restore returns a held page with its content, reading a spilled page back once the
+// budget can take it. It reports false for pages that are left out.
+func (s *pageSpool) restore(ctx context.Context, outcome pageOutcome) (pageOutcome, bool) {
+	if outcome.dropped {
+		return outcome, false
+	}
+	if s == nil || outcome.spill == "" {
+		return outcome, true
+	}
+	defer s.remove(outcome.spill)
+
+	s.mu.Lock()
+	wait := s.wait
+	if s.starved {
+		wait = 0
+	}
+	s.mu.Unlock()
+	if !s.budget.Reserve(ctx, outcome.size, wait) {
+		s.mu.Lock()
+		s.starved = true
+		s.mu.Unlock()
+		return outcome, false
+	}
+	s.mu.Lock()
+	s.reserved += outcome.size
+	s.mu.Unlock()
+
+	data, err := fsys.ReadFile(outcome.spill)
+	if err != nil {
+		return outcome, false
+	}
+	restored, err := decodePage(data)
+	if err != nil {
+		return outcome, false
+	}
+	restored.size = outcome.size
+	return restored, true
+}
+
+// release returns the extraction's reservations to the budget and deletes any spilled
+// pages that were not read back
+func (s *pageSpool) release() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	files := make([]string, 0, len(s.files))
+	for path := range s.files {
+		files = append(files, path)
+	}
+	reserved := s.reserved
+	s.reserved = 0
+	s.mu.Unlock()
+
+	for _, path := range files {
+		s.remove(path)
+	}
+	s.budget.Release(reserved)
+}
+
+// stats reports the bytes reserved by the extraction and the pages it spilled
+func (s *pageSpool) stats() (reserved int64, spilled int) {
+	if s == nil {
+		return 0, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reserved, s.spilled
+}
+
+// remove deletes a spilled page
+func (s *pageSpool) remove(path string) {
+	fsys.Remove(path)
+	s.mu.Lock()
+	delete(s.files, path)
+	s.mu.Unlock()
+}
+
+// spillPage writes a page to a temporary file readable only by the server
+func spillPage(outcome pageOutcome) (string, error) {
+	data, err := encodePage(outcome)
+	if err != nil {
+		return "", err
+	}
+	path, err := fsys.WriteTemp("", "mcp-pdf-page-*.gob", data, spillFilePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to write spill file: %w", err)
+	}
+	return path, nil
+}
+
+// outcomeSize estimates the memory held by a page's elements and tables
+func outcomeSize(outcome pageOutcome) int64 {
+	size := elementsSize(outcome.elements)
+	for _, table := range outcome.tables {
+		size += elementBytes
+		for _, row := range table.Rows {
+			for _, cell := range row.Cells {
+				size += cellBytes + int64(len(cell.Content)+len(cell.Value))
+			}
+		}
+	}
+	return size
+}
+
+// elementsSize estimates the memory held by elements and their children
+func elementsSize(elements []ContentElement) int64 {
+	var size int64
+	for _, element := range elements {
+		size += elementBytes + int64(len(element.ID)+len(element.PageLabel))
+		switch content := element.Content.(type) {
+		case TextElement:
+			size += int64(len(content.Text)) + int64(len(content.Words)+len(content.Lines))*wordBytes
+			for _, word := range content.Words {
+				size += int64(len(word.Text))
+			}
+		case ImageElement:
+			size += int64(len(content.Data))
+		case VectorElement:
+			size += int64(len(content.Commands)) * commandBytes
+		case AnnotationElement:
+			size += int64(len(content.Content) + len(content.MarkedText))
+			for _, quad := range content.QuadPoints {
+				size += int64(len(quad)) * pointBytes
+			}
+			for _, stroke := range content.InkList {
+				size += int64(len(stroke)) * pointBytes
+			}
+		}
+		size += elementsSize(element.Children)
+	}
+	return size
+}
@@ -0,0 +1,107 @@
+package extraction
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryTestRequest extracts the text of every page
+func memoryTestRequest(path string) ExtractionRequest {
+	return ExtractionRequest{
+		FilePath: path,
+		Config:   ExtractionConfig{Mode: ModeStructured, ExtractText: true, MaxWorkers: 1},
+	}
+}
+
+func TestMemoryBudget_ReserveWaitsForRelease(t *testing.T) {
+	budget := NewMemoryBudget(100)
+	if !budget.TryReserve(80) || budget.TryReserve(30) {
+		t.Fatal("TryReserve() should fit 80 bytes and then refuse 30 more")
+	}
+
+	if budget.Reserve(context.Background(), 30, 10*time.Millisecond) {
+		t.Error("Reserve() succeeded without memory being released")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		budget.Release(50)
+	}()
+	if !budget.Reserve(context.Background(), 30, time.Second) {
+		t.Fatal("Reserve() should succeed once memory is released")
+	}
+	if stats := budget.Stats(); stats != (MemoryStats{Limit: 100, InUse: 60, Peak: 80}) {
+		t.Errorf("Stats() = %+v, want 60 of 100 bytes in use with a peak of 80", stats)
+	}
+}
+
+func TestExtract_LeavesOutPagesOverMemoryBudget(t *testing.T) {
+	spillDir := t.TempDir()
+	t.Setenv("TMPDIR", spillDir)
+	path := writePagesPDF(t, 4)
+
+	// Measure one page, then allow about two
+	engine := NewEngine()
+	full, err := engine.Extract(context.Background(), memoryTestRequest(path))
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	pageSize := elementsSize(full.Elements) / 4
+
+	engine.SetMemoryBudget(2*pageSize + pageSize/2)
+	engine.memoryWait = 10 * time.Millisecond
+	result, err := engine.Extract(context.Background(), memoryTestRequest(path))
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	if !result.Partial || !reflect.DeepEqual(result.ProcessedPages, []int{1, 2}) {
+		t.Errorf("Extract() partial %t with pages %v, want a partial result of pages 1 and 2",
+			result.Partial, result.ProcessedPages)
+	}
+	if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[len(result.Warnings)-1], "[3 4]") {
+		t.Errorf("Extract() warnings = %q, want pages 3 and 4 reported as left out", result.Warnings)
+	}
+	if stats := result.ExtractionInfo.ProcessingStats; stats.SpilledPages != 2 || stats.MemoryUsed != 2*pageSize {
+		t.Errorf("Extract() spilled %d pages using %d bytes, want 2 pages spilled using %d",
+			stats.SpilledPages, stats.MemoryUsed, 2*pageSize)
+	}
+
+	if stats := engine.MemoryStats(); stats.InUse != 0 {
+		t.Errorf("MemoryStats() = %+v, want every reservation released", stats)
+	}
+	if entries, _ := os.ReadDir(spillDir); len(entries) != 0 {
+		t.Errorf("spill files left behind: %v", entries)
+	}
+}
+
+func TestExtract_RestoresSpilledPagesOnceMemoryIsReleased(t *testing.T) {
+	path := writePagesPDF(t, 3)
+	engine := NewEngine()
+	engine.SetMemoryBudget(1 << 20)
+
+	// Another extraction holds the whole budget until shortly after this one finishes
+	engine.memory.TryReserve(1 << 20)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		engine.memory.Release(1 << 20)
+	}()
+
+	result, err := engine.Extract(context.Background(), memoryTestRequest(path))
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	if result.Partial || len(result.ProcessedPages) != 3 || result.ExtractionInfo.ProcessingStats.SpilledPages != 3 {
+		t.Errorf("Extract() partial %t with pages %v after spilling %d, want all 3 pages spilled and restored",
+			result.Partial, result.ProcessedPages, result.ExtractionInfo.ProcessingStats.SpilledPages)
+	}
+	for i, element := range result.Elements {
+		if text := element.Content.(TextElement).Text; !strings.Contains(text, "Page") {
+			t.Errorf("element %d text = %q, want the restored page text", i, text)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package extraction
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// XMP namespaces read into the document metadata
+const (
+	nsRDF  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsXML  = "http://www.w3.org/XML/1998/namespace"
+	nsDC   = "http://purl.org/dc/elements/1.1/"
+	nsXMP  = "http://ns.adobe.com/xap/1.0/"
+	nsPDF  = "http://ns.adobe.com/pdf/1.3/"
+	nsPDFX = "http://ns.adobe.com/pdfx/1.3/" // Custom Info dictionary keys mirrored in XMP
+)
+
+// maxXMPSize caps the metadata stream read, so a damaged length cannot exhaust memory
+const maxXMPSize = 4 << 20
+
+// xmpPrefixes are the prefixes naming XMP properties kept as custom properties. Properties
+// of the pdfx namespace mirror custom Info keys and keep their bare names.
+var xmpPrefixes = map[string]string{nsDC: "dc", nsXMP: "xmp", nsPDF: "pdf"}
+
+// pdfDateLayouts are the accepted forms of a PDF date string after the "D:" prefix,
+// from most to least precise
+var pdfDateLayouts = []string{
+	"20060102150405Z07'00'",
+	"20060102150405Z07'00",
+	"20060102150405Z0700",
+	"20060102150405Z07",
+	"20060102150405",
+	"200601021504",
+	"2006010215",
+	"20060102",
+	"200601",
+	"2006",
+}
+
+// xmpDateLayouts are the accepted forms of an XMP date, from most to least precise
+var xmpDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// keywordSeparator splits a keyword list written as one string
+var keywordSeparator = regexp.MustCompile(`[;,]`)
+
+// ParsePDFDate parses a PDF date string such as "D:20240131120000+01'00'"
+func ParsePDFDate(s string) (time.Time, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range pdfDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseXMPDate parses an XMP date, an ISO 8601 date such as "2024-01-31T12:00:00+01:00"
+func parseXMPDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range xmpDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ReadMetadata reads a document's metadata from its Info dictionary and its XMP metadata
+// stream. XMP takes precedence, as PDF 2.0 requires, unless the Info dictionary was
+// modified later than the XMP, or the XMP gives no date to compare: a tool unaware of XMP
+// then updated the Info dictionary alone, leaving the XMP stale. Values found in only one
+// source are kept either way. The header version is replaced by a later version declared
+// in the XMP or the catalog. Info keys and XMP properties without a field of their own
+// become custom properties. The metadata read is returned even when part of it could not
+// be, along with the error.
+func ReadMetadata(r *pdf.Reader, headerVersion string) (*PDFMetadata, error) {
+	metadata := &PDFMetadata{Version: headerVersion}
+	var errs []error
+
+	info, err := readInfoMetadata(r)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to read Info dictionary: %w", err))
+	}
+	xmp, xmpDate, err := readXMPMetadata(r)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to read XMP metadata: %w", err))
+	}
+
+	primary, secondary := xmp, info
+	if xmp == nil || (info != nil && !info.ModificationDate.IsZero() && info.ModificationDate.After(xmpDate)) {
+		primary, secondary = info, xmp
+	}
+	mergeMetadata(metadata, primary)
+	mergeMetadata(metadata, secondary)
+
+	if err := readCatalogMetadata(r, metadata); err != nil {
+		errs = append(errs, err)
+	}
+	return metadata, errors.Join(errs...)
+}
+
+// readCatalogMetadata reads the viewer preferences, version, and encryption of a document
+func readCatalogMetadata(r *pdf.Reader, metadata *PDFMetadata) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("failed to read document catalog: %v", rec)
+		}
+	}()
+
+	trailer := r.Trailer()
+	catalog := trailer.Key("Root")
+	metadata.PageLayout = catalog.Key("PageLayout").Name()
+	metadata.PageMode = catalog.Key("PageMode").Name()
+	metadata.Encrypted = !trailer.Key("Encrypt").IsNull()
+
+	// A catalog version overrides the header when it is later
+	if version := catalog.Key("Version").Name(); newerVersion(version, metadata.Version) {
+		metadata.Version = version
+	}
+	return nil
+}
+
+// newerVersion reports whether PDF version a is later than b; an unparsable a never is
+func newerVersion(a, b string) bool {
+	va, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return false
+	}
+	vb, err := strconv.ParseFloat(b, 64)
+	return err != nil || va > vb
+}
+
+// readInfoMetadata reads the document Info dictionary; nil means there is none
+func readInfoMetadata(r *pdf.Reader) (metadata *PDFMetadata, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			metadata = nil
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+
+	info := r.Trailer().Key("Info")
+	if info.Kind() != pdf.Dict {
+		return nil, nil
+	}
+
+	metadata = &PDFMetadata{}
+	for _, key := range info.Keys() {
+		value := info.Key(key)
+		text := strings.TrimSpace(infoText(value))
+		if text == "" {
+			continue
+		}
+		switch key {
+		case "Title":
+			metadata.Title = text
+		case "Author":
+			metadata.Author = text
+		case "Subject":
+			metadata.Subject = text
+		case "Keywords":
+			metadata.Keywords = splitKeywords(text)
+		case "Creator":
+			metadata.Creator = text
+		case "Producer":
+			metadata.Producer = text
+		case "CreationDate":
+			metadata.CreationDate, _ = ParsePDFDate(value.RawString())
+		case "ModDate":
+			metadata.ModificationDate, _ = ParsePDFDate(value.RawString())
+		default:
+			setCustomProperty(metadata, key, text)
+		}
+	}
+	return metadata, nil
+}
+
+// infoText returns the text of an Info dictionary value; dictionaries and arrays have none
+func infoText(value pdf.Value) string {
+	switch value.Kind() {
+	case pdf.String:
+		return value.Text()
+	case pdf.Name:
+		return value.Name()
+	case pdf.Integer, pdf.Real, pdf.Bool:
+		return value.String()
+	}
+	return ""
+}
+
+// splitKeywords splits a keyword list written as one string on commas and semicolons
+func splitKeywords(s string) []string {
+	var keywords []string
+	for _, keyword := range keywordSeparator.Split(s, -1) {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// setCustomProperty records a metadata value without a field of its own
+func setCustomProperty(metadata *PDFMetadata, key, value string) {
+	if metadata.CustomProperties == nil {
+		metadata.CustomProperties = make(map[string]string)
+	}
+	metadata.CustomProperties[key] = value
+}
+
+// xmlNode is an element of an XMP packet, kept generic because properties may use any name
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+// readXMPMetadata reads the XMP metadata stream of the catalog, returning its metadata and
+// the latest of its modification and metadata dates. nil means there is no stream.
+func readXMPMetadata(r *pdf.Reader) (metadata *PDFMetadata, date time.Time, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			metadata = nil
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+
+	stream := r.Trailer().Key("Root").Key("Metadata")
+	if stream.Kind() != pdf.Stream {
+		return nil, time.Time{}, nil
+	}
+	reader := stream.Reader()
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, maxXMPSize))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return parseXMP(data)
+}
+
+// parseXMP reads the dc, xmp, and pdf properties of an XMP packet, along with the custom
+// properties of the pdfx namespace. Properties may be written as attributes of an
+// rdf:Description or as its child elements.
+func parseXMP(data []byte) (*PDFMetadata, time.Time, error) {
+	var root xmlNode
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&root); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	metadata := &PDFMetadata{}
+	var date time.Time
+	var pdfKeywords []string
+	var descriptions []xmlNode
+	collectDescriptions(root, &descriptions)
+	for _, description := range descriptions {
+		properties := make([]xmlNode, 0, len(description.Attrs)+len(description.Nodes))
+		for _, attr := range description.Attrs {
+			properties = append(properties, xmlNode{XMLName: attr.Name, Text: attr.Value})
+		}
+		properties = append(properties, description.Nodes...)
+
+		for _, property := range properties {
+			values := xmpValues(property)
+			if len(values) == 0 {
+				continue
+			}
+			name := property.XMLName
+			switch name {
+			case xml.Name{Space: nsDC, Local: "title"}:
+				metadata.Title = values[0]
+			case xml.Name{Space: nsDC, Local: "creator"}:
+				metadata.Author = strings.Join(values, "; ")
+			case xml.Name{Space: nsDC, Local: "description"}:
+				metadata.Subject = values[0]
+			case xml.Name{Space: nsDC, Local: "subject"}:
+				metadata.Keywords = values
+			case xml.Name{Space: nsPDF, Local: "Keywords"}:
+				pdfKeywords = splitKeywords(values[0])
+			case xml.Name{Space: nsPDF, Local: "Producer"}:
+				metadata.Producer = values[0]
+			case xml.Name{Space: nsXMP, Local: "CreatorTool"}:
+				metadata.Creator = values[0]
+			case xml.Name{Space: nsXMP, Local: "CreateDate"}:
+				metadata.CreationDate, _ = parseXMPDate(values[0])
+			case xml.Name{Space: nsXMP, Local: "ModifyDate"}:
+				metadata.ModificationDate, _ = parseXMPDate(values[0])
+				date = laterDate(date, metadata.ModificationDate)
+			case xml.Name{Space: nsXMP, Local: "MetadataDate"}:
+				if t, ok := parseXMPDate(values[0]); ok {
+					date = laterDate(date, t)
+				}
+				setCustomProperty(metadata, "xmp:MetadataDate", values[0])
+			case xml.Name{Space: nsPDF, Local: "PDFVersion"}:
+				metadata.Version = values[0]
+			default:
+				if name.Space == nsPDFX {
+					setCustomProperty(metadata, name.Local, strings.Join(values, "; "))
+				} else if prefix, ok := xmpPrefixes[name.Space]; ok {
+					setCustomProperty(metadata, prefix+":"+name.Local, strings.Join(values, "; "))
+				}
+			}
+		}
+	}
+
+	// dc:subject is the keyword list proper; pdf:Keywords is its copy of the Info string
+	if len(metadata.Keywords) == 0 {
+		metadata.Keywords = pdfKeywords
+	}
+	return metadata, date, nil
+}
+
+// collectDescriptions gathers the rdf:Description elements of an XMP packet
+func collectDescriptions(node xmlNode, descriptions *[]xmlNode) {
+	if node.XMLName == (xml.Name{Space: nsRDF, Local: "Description"}) {
+		*descriptions = append(*descriptions, node)
+		return
+	}
+	for _, child := range node.Nodes {
+		collectDescriptions(child, descriptions)
+	}
+}
+
+// xmpValues returns the values of an XMP property: the items of an rdf:Seq or rdf:Bag, the
+// default language of an rdf:Alt, or the simple value. Structured values have none.
+func xmpValues(property xmlNode) []string {
+	if len(property.Nodes) == 0 {
+		if text := strings.TrimSpace(property.Text); text != "" {
+			return []string{text}
+		}
+		for _, attr := range property.Attrs {
+			if attr.Name == (xml.Name{Space: nsRDF, Local: "resource"}) {
+				return []string{attr.Value}
+			}
+		}
+		return nil
+	}
+
+	container := property.Nodes[0]
+	if container.XMLName.Space != nsRDF {
+		return nil
+	}
+	var values []string
+	for _, item := range container.Nodes {
+		if item.XMLName != (xml.Name{Space: nsRDF, Local: "li"}) {
+			continue
+		}
+		text := strings.TrimSpace(item.Text)
+		if text == "" {
+			continue
+		}
+		if container.XMLName.Local == "Alt" {
+			for _, attr := range item.Attrs {
+				if attr.Name == (xml.Name{Space: nsXML, Local: "lang"}) && attr.Value == "x-default" {
+					return []string{text}
+				}
+			}
+		}
+		values = append(values, text)
+	}
+	if container.XMLName.Local == "Alt" && len(values) > 0 {
+		return values[:1]
+	}
+	return values
+}
+
+// laterDate returns the later of two dates
+func laterDate(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// mergeMetadata fills the empty fields of metadata from source, adding the custom
+// properties it does not have yet
+func mergeMetadata(metadata, source *PDFMetadata) {
+	if source == nil {
+		return
+	}
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&metadata.Title, source.Title)
+	fill(&metadata.Author, source.Author)
+	fill(&metadata.Subject, source.Subject)
+	fill(&metadata.Creator, source.Creator)
+	fill(&metadata.Producer, source.Producer)
+	if metadata.CreationDate.IsZero() {
+		metadata.CreationDate = source.CreationDate
+	}
+	if metadata.ModificationDate.IsZero() {
+		metadata.ModificationDate = source.ModificationDate
+	}
+	if len(metadata.Keywords) == 0 {
+		metadata.Keywords = source.Keywords
+	}
+	if source.Version != "" && newerVersion(source.Version, metadata.Version) {
+		metadata.Version = source.Version
+	}
+	for key, value := range source.CustomProperties {
+		if _, ok := metadata.CustomProperties[key]; !ok {
+			setCustomProperty(metadata, key, value)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package extraction
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/ledongthuc/pdf"
+)
+
+// testXMP is an XMP packet writing properties both as attributes and as elements
+const testXMP = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmp:CreatorTool="Writer 7" xmp:CreateDate="2024-01-31T12:00:00+01:00"
+    xmp:ModifyDate="2024-02-01T09:30:00Z" xmp:Rating="3"/>
+  <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:pdf="http://ns.adobe.com/pdf/1.3/" xmlns:pdfx="http://ns.adobe.com/pdfx/1.3/">
+   <dc:title><rdf:Alt><rdf:li xml:lang="en">Report</rdf:li>` +
+	`<rdf:li xml:lang="x-default">Annual Report</rdf:li></rdf:Alt></dc:title>
+   <dc:creator><rdf:Seq><rdf:li>Ada Lovelace</rdf:li><rdf:li>Charles Babbage</rdf:li></rdf:Seq></dc:creator>
+   <dc:subject><rdf:Bag><rdf:li>finance</rdf:li><rdf:li>2024</rdf:li></rdf:Bag></dc:subject>
+   <pdf:Keywords>ignored, because dc:subject is given</pdf:Keywords>
+   <pdf:Producer>Press 2</pdf:Producer>
+   <pdfx:Department>Accounts</pdfx:Department>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+// writeMetadataPDF writes a one-page document with the given Info dictionary and, when xmp
+// is not empty, an XMP metadata stream
+func writeMetadataPDF(t *testing.T, info, xmp string) string {
+	t.Helper()
+
+	catalog := "<< /Type /Catalog /Pages 2 0 R /PageMode /UseOutlines /Version /1.7 >>"
+	if xmp != "" {
+		catalog = "<< /Type /Catalog /Pages 2 0 R /PageMode /UseOutlines /Version /1.7 /Metadata 5 0 R >>"
+	}
+	path := writeRawPDF(t, "metadata.pdf", []string{
+		catalog,
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		info,
+		fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(xmp)+1, xmp),
+	})
+
+	// The trailer follows the cross-reference table, so adding to it moves no object
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "/Root 1 0 R", "/Root 1 0 R /Info 4 0 R", 1))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readTestMetadata(t *testing.T, path string) *PDFMetadata {
+	t.Helper()
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	metadata, err := ReadMetadata(doc.Reader, doc.HeaderVersion())
+	if err != nil {
+		t.Fatalf("ReadMetadata() unexpected error = %v", err)
+	}
+	return metadata
+}
+
+func TestReadMetadata_Info(t *testing.T) {
+	path := writeMetadataPDF(t, "<< /Title (Quarterly Report) /Author <FEFF0041006400E1> "+
+		"/Keywords (tax; audit, 2024) /Creator (Writer) /Producer (Press) "+
+		"/CreationDate (D:20240131120000+01'00') /ModDate (D:20240201) "+
+		"/Trapped /False /Department (Accounts) /Revision 4 /Extra << /A 1 >> >>", "")
+	metadata := readTestMetadata(t, path)
+
+	if metadata.Title != "Quarterly Report" || metadata.Author != "Adá" || metadata.Creator != "Writer" ||
+		metadata.Producer != "Press" {
+		t.Errorf("ReadMetadata() = %+v, want the Info strings", metadata)
+	}
+	if !reflect.DeepEqual(metadata.Keywords, []string{"tax", "audit", "2024"}) {
+		t.Errorf("Keywords = %q, want tax, audit, 2024", metadata.Keywords)
+	}
+	if want := time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC); !metadata.CreationDate.Equal(want) {
+		t.Errorf("CreationDate = %v, want %v", metadata.CreationDate, want)
+	}
+	want := map[string]string{"Trapped": "False", "Department": "Accounts", "Revision": "4"}
+	if !reflect.DeepEqual(metadata.CustomProperties, want) {
+		t.Errorf("CustomProperties = %v, want %v", metadata.CustomProperties, want)
+	}
+	if metadata.Version != "1.7" || metadata.PageMode != "UseOutlines" || metadata.Encrypted {
+		t.Errorf("ReadMetadata() version %q, page mode %q, encrypted %t, want 1.7 from the catalog, "+
+			"UseOutlines, and not encrypted", metadata.Version, metadata.PageMode, metadata.Encrypted)
+	}
+}
+
+func TestReadMetadata_XMPPrecedence(t *testing.T) {
+	// The XMP was modified after the Info dictionary, so its values win
+	path := writeMetadataPDF(t, "<< /Title (Old Title) /Subject (Only in Info) /Producer (Old Press) "+
+		"/ModDate (D:20240101000000Z) /Department (Sales) >>", testXMP)
+	metadata := readTestMetadata(t, path)
+
+	if metadata.Title != "Annual Report" || metadata.Author != "Ada Lovelace; Charles Babbage" ||
+		metadata.Creator != "Writer 7" || metadata.Producer != "Press 2" || metadata.Subject != "Only in Info" {
+		t.Errorf("ReadMetadata() = %+v, want XMP values with the Info subject", metadata)
+	}
+	if !reflect.DeepEqual(metadata.Keywords, []string{"finance", "2024"}) {
+		t.Errorf("Keywords = %q, want the dc:subject bag", metadata.Keywords)
+	}
+	if want := time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC); !metadata.ModificationDate.Equal(want) {
+		t.Errorf("ModificationDate = %v, want the XMP date %v", metadata.ModificationDate, want)
+	}
+	if metadata.CustomProperties["Department"] != "Accounts" || metadata.CustomProperties["xmp:Rating"] != "3" {
+		t.Errorf("CustomProperties = %v, want pdfx:Department and xmp:Rating", metadata.CustomProperties)
+	}
+
+	// An Info dictionary modified after the XMP was updated by a tool unaware of XMP
+	path = writeMetadataPDF(t, "<< /Title (New Title) /ModDate (D:20240301000000Z) >>", testXMP)
+	metadata = readTestMetadata(t, path)
+	if metadata.Title != "New Title" || metadata.Producer != "Press 2" {
+		t.Errorf("ReadMetadata() = %+v, want the newer Info title and the XMP producer", metadata)
+	}
+}
+
+func TestReadMetadata_ReportsDamagedXMP(t *testing.T) {
+	path := writeMetadataPDF(t, "<< /Title (Kept) >>", "<x:xmpmeta><rdf:RDF>")
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	metadata, err := ReadMetadata(r, "1.4")
+	if err == nil || !strings.Contains(err.Error(), "XMP") {
+		t.Errorf("ReadMetadata() error = %v, want an XMP error", err)
+	}
+	if metadata.Title != "Kept" {
+		t.Errorf("ReadMetadata() title = %q, want the Info title despite the damaged XMP", metadata.Title)
+	}
+}
@@ -0,0 +1,226 @@
+package extraction
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Text normalization constants
+const (
+	// shortLineShare is the share of the longest line of a text below which a line is short:
+	// the last line of a paragraph, a heading, or a line of verse
+	shortLineShare = 0.6
+	// minHardBreakLines is how many short lines in a row keep their line breaks, as verse or
+	// an address does
+	minHardBreakLines = 3
+)
+
+var (
+	// lineEndHyphen matches a word broken with a hyphen, Unicode hyphen, or soft hyphen at
+	// the end of a line
+	lineEndHyphen = regexp.MustCompile(`\p{L}[-\x{2010}\x{00AD}]$`)
+	// listItemStart matches the bullet or number opening a list item
+	listItemStart = regexp.MustCompile(`^\s*([-•*–·▪]|\(?\d{1,3}[.)]|\(?[a-z][.)])\s`)
+	// codeLine matches an indented line or one ending like a statement or block of code
+	codeLine = regexp.MustCompile(`^(\t| {2,})\S|[{};]\s*$`)
+)
+
+// DefaultTextNormalization returns the normalization applied when a request does not choose
+// one: lines are merged into paragraphs and broken words joined, keeping hard line breaks
+func DefaultTextNormalization() TextNormalization {
+	return TextNormalization{MergeLines: true, Dehyphenate: true, PreserveHardBreaks: true}
+}
+
+// JoinHyphenated joins a word broken with a hyphen at the end of a line, moving the rest of
+// the word up from the next line, and returns both lines. A capitalized continuation is more
+// likely part of a hyphenated compound such as "Franco-German", so it is left alone.
+func JoinHyphenated(line, next string) (string, string, bool) {
+	if !lineEndHyphen.MatchString(line) {
+		return line, next, false
+	}
+	first, rest, _ := strings.Cut(strings.TrimLeft(next, " \t"), " ")
+	r, _ := utf8.DecodeRuneInString(first)
+	if !unicode.IsLower(r) {
+		return line, next, false
+	}
+	_, size := utf8.DecodeLastRuneInString(line)
+	return line[:len(line)-size] + first, rest, true
+}
+
+// textBlock is a paragraph, or a run of lines keeping their breaks, made of the lines first
+// to last of a text
+type textBlock struct {
+	text        string
+	first, last int
+}
+
+// normalizeLines puts lines of text back together into blocks. Paragraphs end at blank
+// lines, after short lines, and before list items. Code, and runs of short lines such as
+// verse and addresses, keep their line breaks when hard breaks are preserved.
+func normalizeLines(lines []string, options TextNormalization) []textBlock {
+	lines = append([]string(nil), lines...)
+	blank := make([]bool, len(lines))
+	short := make([]bool, len(lines))
+	longest := 0
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\r")
+		blank[i] = strings.TrimSpace(lines[i]) == ""
+		longest = max(longest, utf8.RuneCountInString(strings.TrimSpace(lines[i])))
+	}
+	for i, line := range lines {
+		short[i] = !blank[i] && float64(utf8.RuneCountInString(strings.TrimSpace(line))) < shortLineShare*float64(longest)
+	}
+
+	hard := make([]bool, len(lines))
+	if options.PreserveHardBreaks {
+		for i, line := range lines {
+			hard[i] = !blank[i] && codeLine.MatchString(line)
+		}
+		for start := 0; start < len(lines); {
+			end := start
+			for end < len(lines) && short[end] && !listItemStart.MatchString(lines[end]) {
+				end++
+			}
+			if end-start >= minHardBreakLines {
+				for i := start; i < end; i++ {
+					hard[i] = true
+				}
+			}
+			start = max(end, start+1)
+		}
+	}
+
+	if options.Dehyphenate {
+		for i := 0; i+1 < len(lines); i++ {
+			if !blank[i] && !blank[i+1] && !hard[i] && !hard[i+1] {
+				lines[i], lines[i+1], _ = JoinHyphenated(lines[i], lines[i+1])
+			}
+		}
+	}
+
+	var blocks []textBlock
+	var current *textBlock
+	for i, line := range lines {
+		if blank[i] {
+			current = nil
+			continue
+		}
+		text := strings.TrimSpace(line)
+		if hard[i] {
+			text = line
+		}
+		if text == "" {
+			// Moved up into the line before by dehyphenation
+			if current != nil {
+				current.last = i
+			}
+			continue
+		}
+
+		switch {
+		case current == nil, !options.MergeLines, hard[i] != hard[current.first],
+			!hard[i] && (short[current.last] || listItemStart.MatchString(line)):
+			blocks = append(blocks, textBlock{text: text, first: i, last: i})
+			current = &blocks[len(blocks)-1]
+		case hard[i]:
+			current.text += "\n" + text
+			current.last = i
+		default:
+			current.text += " " + text
+			current.last = i
+		}
+	}
+	return blocks
+}
+
+// NormalizeText puts text broken into lines back together. Merged paragraphs are separated
+// by blank lines.
+func NormalizeText(text string, options TextNormalization) string {
+	blocks := normalizeLines(strings.Split(text, "\n"), options)
+	texts := make([]string, len(blocks))
+	for i, block := range blocks {
+		texts[i] = block.text
+	}
+	if options.MergeLines {
+		return strings.Join(texts, "\n\n")
+	}
+	return strings.Join(texts, "\n")
+}
+
+// normalizeContent normalizes the text of structured and semantic extractions. Elements
+// holding a page's text are normalized in place; runs of line elements are replaced by one
+// element for each paragraph, spanning its lines.
+func (e *DefaultEngine) normalizeContent(result *ExtractionResult, config ExtractionConfig) {
+	if config.Mode != ModeStructured && config.Mode != ModeSemantic {
+		return
+	}
+	options := DefaultTextNormalization()
+	if config.TextNormalization != nil {
+		options = *config.TextNormalization
+	}
+	if !options.MergeLines && !options.Dehyphenate {
+		return
+	}
+
+	lineText := func(element ContentElement) (string, bool) {
+		text, ok := element.Content.(TextElement)
+		if !ok || element.Type != ContentTypeText || element.Parent != nil || strings.Contains(text.Text, "\n") {
+			return "", false
+		}
+		return text.Text, true
+	}
+
+	elements := make([]ContentElement, 0, len(result.Elements))
+	for i := 0; i < len(result.Elements); {
+		element := result.Elements[i]
+		if _, ok := lineText(element); !ok {
+			if text, ok := element.Content.(TextElement); ok && element.Type == ContentTypeText {
+				text.Text = NormalizeText(text.Text, options)
+				element.Content = text
+			}
+			elements = append(elements, element)
+			i++
+			continue
+		}
+
+		// A run of line elements on the page
+		run := []ContentElement{element}
+		for i+len(run) < len(result.Elements) {
+			next := result.Elements[i+len(run)]
+			if _, ok := lineText(next); !ok || next.PageNumber != element.PageNumber {
+				break
+			}
+			run = append(run, next)
+		}
+		lines := make([]string, len(run))
+		for j, line := range run {
+			lines[j], _ = lineText(line)
+		}
+		for _, block := range normalizeLines(lines, options) {
+			elements = append(elements, mergeLineElements(run[block.first:block.last+1], block.text))
+		}
+		i += len(run)
+	}
+	result.Elements = elements
+}
+
+// mergeLineElements makes one element of the text of a run of line elements, spanning them
+// and holding their words
+func mergeLineElements(lines []ContentElement, text string) ContentElement {
+	merged := lines[0]
+	content := merged.Content.(TextElement)
+	content.Text = text
+	merged.Content = content
+	merged.Children = nil
+	for _, line := range lines {
+		merged.BoundingBox = unionBoxes(merged.BoundingBox, line.BoundingBox)
+		merged.Confidence = min(merged.Confidence, line.Confidence)
+		for _, child := range line.Children {
+			child.Parent = &merged.ID
+			merged.Children = append(merged.Children, child)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,120 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestNormalizeText(t *testing.T) {
+	paragraphs := "Text extraction splits the words of long lines when the extrac-\n" +
+		"tion engine breaks them with a hyphen across two lines, and\n" +
+		"the reader has to join them.\n" +
+		"Franco-German talks continued through the whole of the long\n" +
+		"afternoon.\n" +
+		"- a list item\n" +
+		"- another item"
+	verse := "The first paragraph holds a line long enough to measure the others by.\n" +
+		"Roses are red,\n" +
+		"Violets are blue,\n" +
+		"Sugar is sweet.\n" +
+		"func main() {\n" +
+		"    fmt.Println(\"hi\")\n" +
+		"}"
+
+	tests := []struct {
+		name    string
+		text    string
+		options TextNormalization
+		want    string
+	}{
+		{
+			name:    "paragraphs",
+			text:    paragraphs,
+			options: DefaultTextNormalization(),
+			want: "Text extraction splits the words of long lines when the extraction engine breaks them " +
+				"with a hyphen across two lines, and the reader has to join them.\n\n" +
+				"Franco-German talks continued through the whole of the long afternoon.\n\n" +
+				"- a list item\n\n- another item",
+		},
+		{
+			name:    "dehyphenate only",
+			text:    "a broken extrac-\ntion and a Franco-\nGerman compound",
+			options: TextNormalization{Dehyphenate: true},
+			want:    "a broken extraction\nand a Franco-\nGerman compound",
+		},
+		{
+			name:    "hard breaks",
+			text:    verse,
+			options: DefaultTextNormalization(),
+			want: "The first paragraph holds a line long enough to measure the others by.\n\n" +
+				"Roses are red,\nViolets are blue,\nSugar is sweet.\nfunc main() {\n    fmt.Println(\"hi\")\n}",
+		},
+		{
+			name:    "hard breaks merged",
+			text:    "The first paragraph holds a line long enough to measure the others by.\nRoses are red,\nViolets are blue,",
+			options: TextNormalization{MergeLines: true},
+			want: "The first paragraph holds a line long enough to measure the others by. Roses are red,\n\n" +
+				"Violets are blue,",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeText(tt.text, tt.options); got != tt.want {
+				t.Errorf("NormalizeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtract_NormalizesStructuredText(t *testing.T) {
+	content := "BT /F1 10 Tf 72 720 Td (Normalization joins the lines of a paragraph whose text was broken by the extrac-) Tj ET\n" +
+		"BT /F1 10 Tf 72 708 Td (tion engine into separate lines of the page.) Tj ET\n" +
+		"BT /F1 10 Tf 72 680 Td (- A list item starts its own element) Tj ET"
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents 5 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+	}
+	path := writeRawPDF(t, "paragraph.pdf", objects)
+	engine := NewEngine()
+
+	result, err := engine.Extract(context.Background(), ExtractionRequest{
+		FilePath: path, Config: ExtractionConfig{Mode: ModeStructured, ExtractText: true, IncludeCoordinates: true},
+	})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	if len(result.Elements) != 2 {
+		t.Fatalf("Extract() = %d elements, want a paragraph and a list item", len(result.Elements))
+	}
+	paragraph := result.Elements[0]
+	want := "Normalization joins the lines of a paragraph whose text was broken by the extraction " +
+		"engine into separate lines of the page."
+	if text := paragraph.Content.(TextElement).Text; text != want {
+		t.Errorf("paragraph = %q, want %q", text, want)
+	}
+	if paragraph.BoundingBox.Height <= defaultLineHeight || len(paragraph.Children) != 22 {
+		t.Errorf("paragraph spans %+v with %d words, want both lines and their words",
+			paragraph.BoundingBox, len(paragraph.Children))
+	}
+	for _, word := range paragraph.Children {
+		if word.Parent == nil || *word.Parent != paragraph.ID {
+			t.Errorf("word %+v does not belong to the paragraph %s", word, paragraph.ID)
+		}
+	}
+
+	// Turning normalization off keeps one element per line
+	result, err = engine.Extract(context.Background(), ExtractionRequest{
+		FilePath: path,
+		Config:   ExtractionConfig{Mode: ModeStructured, ExtractText: true, TextNormalization: &TextNormalization{}},
+	})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	if len(result.Elements) != 3 {
+		t.Errorf("Extract() = %d elements, want one per line", len(result.Elements))
+	}
+}
@@ -0,0 +1,47 @@
+package extraction
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// lookupKey is the key of the dictionary ObjectByRef resolves its reference through
+const lookupKey = "Object"
+
+// ObjectByRef loads an indirect object by its object and generation numbers, the way
+// references to it are resolved. The parser offers no lookup by number, so a dictionary
+// holding the reference is assembled from its unexported types and the reference read from
+// it. Objects missing from the cross-reference table, or stored under another generation,
+// load as null.
+func ObjectByRef(r *pdf.Reader, ref ObjectRef) (v pdf.Value, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("failed to load object %s: %v", ref, rec)
+		}
+	}()
+
+	holder := r.Trailer()
+	fields := reflect.ValueOf(&holder).Elem()
+	data, ptr := fields.FieldByName("data"), fields.FieldByName("ptr")
+	if !data.IsValid() || data.IsNil() || data.Elem().Kind() != reflect.Map || !ptr.IsValid() || ptr.NumField() < 2 {
+		return pdf.Value{}, fmt.Errorf("failed to load object %s: unsupported parser", ref)
+	}
+
+	target := reflect.New(ptr.Type()).Elem()
+	settable(target.Field(0)).SetUint(uint64(ref.ID))
+	settable(target.Field(1)).SetUint(uint64(ref.Gen))
+	dictType := data.Elem().Type()
+	dict := reflect.MakeMap(dictType)
+	dict.SetMapIndex(reflect.ValueOf(lookupKey).Convert(dictType.Key()), target)
+	settable(data).Set(dict)
+
+	return holder.Key(lookupKey), nil
+}
+
+// settable returns an addressable unexported field that may be assigned
+func settable(field reflect.Value) reflect.Value {
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
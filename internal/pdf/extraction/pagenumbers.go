@@ -0,0 +1,295 @@
+package extraction
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// maxNumberTreeDepth limits recursion into the /PageLabels number tree
+const maxNumberTreeDepth = 32
+
+// Page label limits, which keep labels short whatever a file's /St entries say
+const (
+	maxPageLabelStart   = 1<<31 - 1 // Largest /St honoured; larger ones count from 1
+	maxNumeralPageLabel = 3999      // Largest value written in roman numerals or letters, then decimal
+)
+
+// StagePageTree is the stage of issues found while numbering pages
+const StagePageTree = "page_tree"
+
+// PageNumbering is the page numbering shared by every tool. Pages are numbered from 1 in the
+// order of the page tree's leaves, which is the order viewers display them and the order the
+// external renderers use. Rotation does not affect numbering; it is reported per page.
+//
+// The parser has its own numbering, which trusts the /Count and /Type entries of the page
+// tree and stops at the root's count. Every entry that makes it disagree with the tree is
+// recorded in Issues when the numbering is built, so results can say when a file's page
+// tree is damaged. Building the numbering reads each page tree node once, where looking up
+// every page through the parser reads the tree again for each page.
+type PageNumbering struct {
+	pages  []pdf.Page
+	index  map[ObjectRef]int
+	labels []pageLabelRange
+	Issues []ParseIssue
+}
+
+// pageLabelRange is one entry of the /PageLabels number tree, covering the pages from its
+// zero-based start index up to the next range
+type pageLabelRange struct {
+	start  int
+	style  string // D, R, r, A, a, or empty for labels that are only a prefix
+	prefix string
+	first  int // Value of the range's first page, /St
+}
+
+// NewPageNumbering walks the page tree of a document, reads its page labels, and checks the
+// parser's numbering against the tree
+func NewPageNumbering(r *pdf.Reader) (n *PageNumbering) {
+	n = &PageNumbering{index: make(map[ObjectRef]int)}
+
+	// The parser panics on malformed objects; pages numbered so far are kept
+	defer func() {
+		if rec := recover(); rec != nil {
+			n.addIssue(0, pdf.Value{}, fmt.Errorf("page tree could not be read completely: %v", rec))
+		}
+	}()
+
+	root := r.Trailer().Key("Root").Key("Pages")
+	n.walk(root, 0, make(map[ObjectRef]bool))
+	n.check(r, root)
+	n.labels = readPageLabels(r.Trailer().Key("Root").Key("PageLabels"))
+	return n
+}
+
+// walk numbers the leaves below a page tree node. Nodes with kids are treated as page tree
+// nodes and nodes without as pages, whatever their /Type says, as viewers do.
+func (n *PageNumbering) walk(node pdf.Value, depth int, visiting map[ObjectRef]bool) {
+	if depth > maxPageTreeDepth {
+		n.addIssue(0, node, fmt.Errorf("page tree nested more than %d levels deep", maxPageTreeDepth))
+		return
+	}
+	ref := RefOf(node)
+	if visiting[ref] {
+		n.addIssue(0, node, fmt.Errorf("page tree node %s contains itself", ref))
+		return
+	}
+
+	kids := node.Key("Kids")
+	if kids.Kind() != pdf.Array {
+		if node.Kind() != pdf.Dict {
+			n.addIssue(0, node, fmt.Errorf("page tree entry %s is not a dictionary", ref))
+			return
+		}
+		n.pages = append(n.pages, pdf.Page{V: node})
+		pageNum := len(n.pages)
+		if typ := node.Key("Type").Name(); typ != "Page" {
+			n.addIssue(pageNum, node, fmt.Errorf("page object has /Type %q instead of /Page", typ))
+		}
+		if first, seen := n.index[ref]; seen {
+			n.addIssue(pageNum, node, fmt.Errorf("page object is also page %d", first))
+		} else if ref.ID != 0 {
+			n.index[ref] = pageNum
+		}
+		return
+	}
+
+	if typ := node.Key("Type").Name(); typ != "Pages" {
+		n.addIssue(0, node, fmt.Errorf("page tree node %s has /Type %q instead of /Pages", ref, typ))
+	}
+	if ref.ID != 0 {
+		visiting[ref] = true
+		defer delete(visiting, ref)
+	}
+	before := len(n.pages)
+	for i := 0; i < kids.Len(); i++ {
+		n.walk(kids.Index(i), depth+1, visiting)
+	}
+	if count := node.Key("Count"); count.Kind() == pdf.Integer && int(count.Int64()) != len(n.pages)-before {
+		n.addIssue(0, node, fmt.Errorf("page tree node %s has /Count %d but holds %d pages",
+			ref, count.Int64(), len(n.pages)-before))
+	}
+}
+
+// check compares the parser's page count with the page tree
+func (n *PageNumbering) check(r *pdf.Reader, root pdf.Value) {
+	if total := r.NumPage(); total != len(n.pages) {
+		n.addIssue(0, root, fmt.Errorf("document declares %d pages but its page tree holds %d; pages are "+
+			"numbered by the page tree", total, len(n.pages)))
+	}
+}
+
+// addIssue records a page tree problem
+func (n *PageNumbering) addIssue(pageNum int, object pdf.Value, err error) {
+	n.Issues = append(n.Issues, newParseIssue(SeverityWarning, StagePageTree, pageNum, object, err))
+}
+
+// Count returns the number of pages
+func (n *PageNumbering) Count() int {
+	return len(n.pages)
+}
+
+// Page returns a page by its 1-based number, or a null page when out of range
+func (n *PageNumbering) Page(pageNum int) pdf.Page {
+	if pageNum < 1 || pageNum > len(n.pages) {
+		return pdf.Page{}
+	}
+	return n.pages[pageNum-1]
+}
+
+// Number returns the 1-based number of a page object, or 0 when it is not a page or the
+// numbering is nil
+func (n *PageNumbering) Number(page pdf.Value) int {
+	if n == nil {
+		return 0
+	}
+	return n.index[RefOf(page)]
+}
+
+// Label returns the page label a viewer shows for a page, or an empty string when the
+// document does not label its pages
+func (n *PageNumbering) Label(pageNum int) string {
+	if n == nil || len(n.labels) == 0 || pageNum < 1 || pageNum > len(n.pages) {
+		return ""
+	}
+	index := pageNum - 1
+	i := sort.Search(len(n.labels), func(i int) bool { return n.labels[i].start > index }) - 1
+	if i < 0 {
+		return ""
+	}
+	label := n.labels[i]
+	return label.prefix + formatPageLabel(label.style, label.first+index-label.start)
+}
+
+// Labels returns the labels of all pages by number, or nil when the document does not
+// label its pages
+func (n *PageNumbering) Labels() map[int]string {
+	if len(n.labels) == 0 {
+		return nil
+	}
+	labels := make(map[int]string, len(n.pages))
+	for pageNum := 1; pageNum <= len(n.pages); pageNum++ {
+		labels[pageNum] = n.Label(pageNum)
+	}
+	return labels
+}
+
+// Rotation returns the clockwise rotation a viewer applies to a page in degrees: 0, 90,
+// 180, or 270. /Rotate is inherited from the page tree.
+func (n *PageNumbering) Rotation(pageNum int) int {
+	rotate := InheritedAttribute(n.Page(pageNum).V, "Rotate")
+	if rotate.Kind() != pdf.Integer && rotate.Kind() != pdf.Real {
+		return 0
+	}
+	degrees := int(rotate.Float64()) % 360
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees / 90 * 90
+}
+
+// MediaBox returns the media box of a page, inherited from the page tree
+func (n *PageNumbering) MediaBox(pageNum int) pdf.Value {
+	return InheritedAttribute(n.Page(pageNum).V, "MediaBox")
+}
+
+// InheritedAttribute looks up a page attribute on the page or the nearest page tree node
+// above it that sets it
+func InheritedAttribute(node pdf.Value, key string) pdf.Value {
+	for depth := 0; node.Kind() == pdf.Dict && depth <= maxPageTreeDepth; depth++ {
+		if value := node.Key(key); !value.IsNull() {
+			return value
+		}
+		node = node.Key("Parent")
+	}
+	return pdf.Value{}
+}
+
+// readPageLabels collects the ranges of the /PageLabels number tree in page order
+func readPageLabels(tree pdf.Value) []pageLabelRange {
+	var ranges []pageLabelRange
+	var walk func(node pdf.Value, depth int)
+	walk = func(node pdf.Value, depth int) {
+		if node.Kind() != pdf.Dict || depth > maxNumberTreeDepth {
+			return
+		}
+		nums := node.Key("Nums")
+		for i := 0; i+1 < nums.Len(); i += 2 {
+			start, label := nums.Index(i), nums.Index(i+1)
+			if start.Kind() != pdf.Integer || label.Kind() != pdf.Dict {
+				continue
+			}
+			first := 1
+			if st := label.Key("St"); st.Kind() == pdf.Integer && st.Int64() > 0 && st.Int64() <= maxPageLabelStart {
+				first = int(st.Int64())
+			}
+			ranges = append(ranges, pageLabelRange{
+				start:  int(start.Int64()),
+				style:  label.Key("S").Name(),
+				prefix: label.Key("P").Text(),
+				first:  first,
+			})
+		}
+		kids := node.Key("Kids")
+		for i := 0; i < kids.Len(); i++ {
+			walk(kids.Index(i), depth+1)
+		}
+	}
+	walk(tree, 0)
+
+	sort.SliceStable(ranges, func(a, b int) bool { return ranges[a].start < ranges[b].start })
+	return ranges
+}
+
+// formatPageLabel formats the numeric part of a page label in a /PageLabels style
+func formatPageLabel(style string, value int) string {
+	switch style {
+	case "D":
+		return strconv.Itoa(value)
+	case "R":
+		return romanNumeral(value)
+	case "r":
+		return strings.ToLower(romanNumeral(value))
+	case "A":
+		return letterNumeral(value)
+	case "a":
+		return strings.ToLower(letterNumeral(value))
+	}
+	return ""
+}
+
+// romanNumeral formats a positive number in upper case roman numerals, and numbers past
+// maxNumeralPageLabel in decimal
+func romanNumeral(value int) string {
+	if value <= 0 || value > maxNumeralPageLabel {
+		return strconv.Itoa(value)
+	}
+	numerals := []struct {
+		value  int
+		symbol string
+	}{
+		{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"}, {100, "C"}, {90, "XC"},
+		{50, "L"}, {40, "XL"}, {10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+	}
+	var b strings.Builder
+	for _, numeral := range numerals {
+		for value >= numeral.value {
+			b.WriteString(numeral.symbol)
+			value -= numeral.value
+		}
+	}
+	return b.String()
+}
+
+// letterNumeral formats a positive number as letters the way PDF page labels count them:
+// A to Z, then AA to ZZ, then AAA and so on, up to maxNumeralPageLabel, then in decimal
+func letterNumeral(value int) string {
+	if value <= 0 || value > maxNumeralPageLabel {
+		return strconv.Itoa(value)
+	}
+	letter := string(rune('A' + (value-1)%26))
+	return strings.Repeat(letter, (value-1)/26+1)
+}
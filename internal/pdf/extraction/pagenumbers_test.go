@@ -0,0 +1,180 @@
+package extraction
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+)
+
+// writeLabeledPDF writes five pages in two page tree branches: roman front matter, arabic
+// body pages, and an appendix page with a prefix. The root rotates its pages a quarter turn,
+// the second branch resets that, and the last page turns back. The root overstates its page
+// count and the third page has no /Type.
+func writeLabeledPDF(t *testing.T) string {
+	t.Helper()
+
+	content := "BT /F1 12 Tf 72 720 Td (Preface) Tj ET"
+	return writeRawPDF(t, "labeled.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /PageLabels << /Nums [0 << /S /r >> 2 << /S /D >> " +
+			"4 << /S /D /P (A-) >>] >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 6 /Rotate 90 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [5 0 R 6 0 R 7 0 R] /Count 3 >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [8 0 R 9 0 R] /Count 2 /Rotate 0 >>",
+		"<< /Type /Page /Parent 3 0 R /Resources << /Font << /F1 10 0 R >> >> /Contents 11 0 R >>",
+		"<< /Type /Page /Parent 3 0 R >>",
+		"<< /Parent 3 0 R >>",
+		"<< /Type /Page /Parent 4 0 R >>",
+		"<< /Type /Page /Parent 4 0 R /Rotate -90 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+	})
+}
+
+func TestPageNumbering(t *testing.T) {
+	doc, err := core.Open(writeLabeledPDF(t), core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	numbering := NewPageNumbering(doc.Reader)
+	if numbering.Count() != 5 {
+		t.Fatalf("Count() = %d, want the 5 pages of the page tree", numbering.Count())
+	}
+
+	wantLabels := map[int]string{1: "i", 2: "ii", 3: "1", 4: "2", 5: "A-1"}
+	if labels := numbering.Labels(); !reflect.DeepEqual(labels, wantLabels) {
+		t.Errorf("Labels() = %v, want %v", labels, wantLabels)
+	}
+
+	for pageNum, want := range map[int]int{1: 90, 3: 90, 4: 0, 5: 270} {
+		if rotation := numbering.Rotation(pageNum); rotation != want {
+			t.Errorf("Rotation(%d) = %d, want %d", pageNum, rotation, want)
+		}
+	}
+
+	var messages []string
+	for _, issue := range numbering.Issues {
+		if issue.Stage != StagePageTree {
+			t.Errorf("issue stage = %q, want %q", issue.Stage, StagePageTree)
+		}
+		messages = append(messages, issue.Message)
+	}
+	joined := strings.Join(messages, "\n")
+	for _, want := range []string{"has /Count 6 but holds 5 pages", "declares 6 pages", `/Type ""`} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Issues = %q, want one mentioning %q", messages, want)
+		}
+	}
+
+	// Page information is given as displayed, and elements carry their page's label
+	engine := NewEngine()
+	info, err := engine.getPageInfo(numbering, 1)
+	if err != nil {
+		t.Fatalf("getPageInfo() unexpected error = %v", err)
+	}
+	if info.Label != "i" || info.Rotation != 90 || info.Width != 792 || info.Height != 612 {
+		t.Errorf("getPageInfo() = %+v, want label i rotated to 792x612", info)
+	}
+
+	config := ExtractionConfig{Mode: ModeStructured, ExtractText: true}
+	outcome := engine.extractPage(numbering, 1, config, NewLinkResolver(doc.Reader, numbering))
+	if len(outcome.elements) == 0 {
+		t.Fatal("extractPage() found no elements on the first page")
+	}
+	for _, element := range outcome.elements {
+		if element.PageLabel != "i" {
+			t.Errorf("element %s page label = %q, want i", element.ID, element.PageLabel)
+		}
+	}
+}
+
+func TestGetPageInfo_PageBoxes(t *testing.T) {
+	// The page tree sets the MediaBox and CropBox for both pages; the first page adds a
+	// BleedBox that reaches past the MediaBox and a TrimBox given from its upper corner
+	path := writeRawPDF(t, "prepress.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 648 864] /CropBox [9 9 639 855] >>",
+		"<< /Type /Page /Parent 2 0 R /BleedBox [-20 9 639 855] /TrimBox [630 846 18 18] >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+	})
+
+	pages, err := NewEngine().GetPageInfo(path)
+	if err != nil {
+		t.Fatalf("GetPageInfo() unexpected error = %v", err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("GetPageInfo() returned %d pages, want 2", len(pages))
+	}
+
+	crop := newBox(9, 9, 639, 855)
+	first, second := pages[0], pages[1]
+	if first.MediaBox != newBox(0, 0, 648, 864) || first.CropBox != crop {
+		t.Errorf("page 1 media box %+v and crop box %+v, want both inherited", first.MediaBox, first.CropBox)
+	}
+	if first.BleedBox != newBox(0, 9, 639, 855) {
+		t.Errorf("page 1 bleed box = %+v, want it clipped to the media box", first.BleedBox)
+	}
+	if first.TrimBox != newBox(18, 18, 630, 846) || first.ArtBox != crop {
+		t.Errorf("page 1 trim box %+v and art box %+v, want the normalized trim box and the crop box",
+			first.TrimBox, first.ArtBox)
+	}
+	if want := []string{"CropBox", "BleedBox", "TrimBox"}; !reflect.DeepEqual(first.ExplicitBoxes, want) {
+		t.Errorf("page 1 explicit boxes = %v, want %v", first.ExplicitBoxes, want)
+	}
+
+	// Boxes other than the CropBox are not inherited
+	if second.BleedBox != crop || second.TrimBox != crop || second.ArtBox != crop {
+		t.Errorf("page 2 = %+v, want the bleed, trim, and art boxes to default to the crop box", second)
+	}
+}
+
+func TestFormatPageLabel(t *testing.T) {
+	tests := []struct {
+		style string
+		value int
+		want  string
+	}{
+		{"D", 12, "12"},
+		{"R", 4, "IV"},
+		{"r", 1994, "mcmxciv"},
+		{"A", 1, "A"},
+		{"A", 27, "AA"},
+		{"a", 55, "ccc"},
+		{"", 3, ""},
+		{"R", 3999, "MMMCMXCIX"},
+		{"R", 4000, "4000"},
+		{"A", 900000000000000000, "900000000000000000"},
+	}
+	for _, tt := range tests {
+		if got := formatPageLabel(tt.style, tt.value); got != tt.want {
+			t.Errorf("formatPageLabel(%q, %d) = %q, want %q", tt.style, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPageNumbering_HugeLabelStart(t *testing.T) {
+	path := writeRawPDF(t, "huge-labels.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /PageLabels << /Nums [0 << /S /A /St 900000000000000000 >> " +
+			"1 << /S /R /St 2000000000 >> 2 << /S /D /St 9223372036854775807 >>] >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+	})
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	// Labels stay short instead of spelling out /St in letters or roman numerals
+	numbering := NewPageNumbering(doc.Reader)
+	want := map[int]string{1: "A", 2: "2000000000", 3: "1"}
+	if got := numbering.Labels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() = %v, want %v", got, want)
+	}
+}
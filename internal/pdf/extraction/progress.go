@@ -0,0 +1,23 @@
+package extraction
+
+import "context"
+
+// ProgressFunc receives the progress of a long-running operation: done of total steps
+// finished, with a short description of the latest one
+type ProgressFunc func(done, total int, message string)
+
+// progressKey is the context key of the progress reporter
+type progressKey struct{}
+
+// WithProgress returns a context whose extractions report their progress to report; a nil
+// report stops progress being reported to the reporter of ctx
+func WithProgress(ctx context.Context, report ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// ReportProgress reports progress to the reporter of ctx, if it has one
+func ReportProgress(ctx context.Context, done, total int, message string) {
+	if report, _ := ctx.Value(progressKey{}).(ProgressFunc); report != nil {
+		report(done, total, message)
+	}
+}
@@ -0,0 +1,527 @@
+package extraction
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Redaction constants
+const (
+	// maxFormDepth limits how deeply nested form XObjects are rewritten
+	maxFormDepth = 12
+	// fallbackGlyphWidth is the width, in thousandths of the font size, of glyphs in fonts
+	// without a width table; it matches the width glyphBox assumes for them
+	fallbackGlyphWidth = 500
+	// glyphCenterRatio is the height of a glyph's center above the baseline, relative to the
+	// font size, for the box glyphBox assumes
+	glyphCenterRatio = 0.5 - glyphDescentRatio
+)
+
+// RedactedContent is a content stream with everything drawn in the redaction areas removed
+type RedactedContent struct {
+	Content []byte
+	Forms   []RedactedForm // Rewritten form XObjects the content draws in their place
+	Glyphs  int            // Glyphs removed, including those in forms
+	Images  int            // Images removed, including those in forms
+}
+
+// RedactedForm is a copy of a form XObject with redacted content, drawn under a new
+// resource name. Its resources gain the names of the forms nested in it.
+type RedactedForm struct {
+	Name      string
+	Form      pdf.Value // The original form XObject
+	Resources pdf.Value // The form's resources, or those it inherits
+	Content   RedactedContent
+}
+
+// RedactPageContent rewrites a page's content stream without the text and images drawn in
+// the given areas, then paints the areas black. Glyphs are removed when their center lies in
+// an area and replaced by an equal advance so the remaining text keeps its position. Images
+// overlapping an area are removed whole, since their pixels cannot be edited; form XObjects
+// overlapping an area are rewritten the same way as the page. Vector graphics are kept.
+func RedactPageContent(page pdf.Page, areas []BoundingBox) (content *RedactedContent, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			content = nil
+			err = fmt.Errorf("failed to read page content: %v", r)
+		}
+	}()
+
+	data, err := readContents(page.V.Key("Contents"))
+	if err != nil {
+		return nil, err
+	}
+	redactor := &contentRedactor{areas: areas, names: map[string]bool{}}
+	content, depth, err := redactor.redact(data, page.Resources(), identityMatrix, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wrap the page content so the boxes are painted in default user space
+	var b bytes.Buffer
+	b.WriteString("q\n")
+	b.Write(content.Content)
+	b.WriteString("\n")
+	for range depth {
+		b.WriteString("Q\n")
+	}
+	b.WriteString("Q\nq 0 g\n")
+	for _, area := range areas {
+		fmt.Fprintf(&b, "%s %s %s %s re f\n", formatContentNumber(area.LowerLeft.X),
+			formatContentNumber(area.LowerLeft.Y), formatContentNumber(area.Width), formatContentNumber(area.Height))
+	}
+	b.WriteString("Q\n")
+	content.Content = b.Bytes()
+	return content, nil
+}
+
+// readContents decodes a page's content stream or array of streams
+func readContents(contents pdf.Value) ([]byte, error) {
+	streams := []pdf.Value{contents}
+	if contents.Kind() == pdf.Array {
+		streams = streams[:0]
+		for i := 0; i < contents.Len(); i++ {
+			streams = append(streams, contents.Index(i))
+		}
+	}
+
+	var b bytes.Buffer
+	for _, stream := range streams {
+		if stream.Kind() != pdf.Stream {
+			continue
+		}
+		data, err := readStream(stream)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// readStream decodes a stream; the parser panics on filters it does not support
+func readStream(stream pdf.Value) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot decode content stream: %v", r)
+		}
+	}()
+	rc := stream.Reader()
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// contentRedactor removes content drawn in the redaction areas
+type contentRedactor struct {
+	areas []BoundingBox
+	names map[string]bool // Resource names given to rewritten forms
+	forms int
+}
+
+// textState holds the text parameters of the graphics state
+type textState struct {
+	font        *fontMetrics
+	size        float64
+	charSpacing float64
+	wordSpacing float64
+	scale       float64
+	leading     float64
+	rise        float64
+}
+
+// redactionState is the graphics state tracked while redacting
+type redactionState struct {
+	ctm  affineMatrix
+	text textState
+}
+
+// redact rewrites one content stream drawn with the given resources and transformation. It
+// returns the number of graphics states left unbalanced at its end.
+func (c *contentRedactor) redact(
+	data []byte, resources pdf.Value, ctm affineMatrix, depth int,
+) (*RedactedContent, int, error) {
+	ops, err := parseContent(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := &RedactedContent{}
+	state := redactionState{ctm: ctm, text: textState{scale: 1}}
+	var stack []redactionState
+	var tm, tlm affineMatrix
+	fonts := map[string]*fontMetrics{}
+
+	var out bytes.Buffer
+	for _, op := range ops {
+		args := op.operands
+		var replacement []byte
+		removed := false
+
+		switch op.operator {
+		case "q":
+			stack = append(stack, state)
+		case "Q":
+			if len(stack) > 0 {
+				state = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			if m, ok := operandMatrix(args); ok {
+				state.ctm = m.multiply(state.ctm)
+			}
+		case "BT":
+			tm, tlm = identityMatrix, identityMatrix
+		case "Tf":
+			if len(args) == 2 {
+				name := args[0].text
+				if _, ok := fonts[name]; !ok {
+					fonts[name] = newFontMetrics(resources.Key("Font").Key(name))
+				}
+				state.text.font = fonts[name]
+				state.text.size = args[1].num
+			}
+		case "Tc":
+			state.text.charSpacing = numberOperand(args, 0)
+		case "Tw":
+			state.text.wordSpacing = numberOperand(args, 0)
+		case "Tz":
+			state.text.scale = numberOperand(args, 0) / 100
+		case "TL":
+			state.text.leading = numberOperand(args, 0)
+		case "Ts":
+			state.text.rise = numberOperand(args, 0)
+		case "Td", "TD":
+			if len(args) == 2 {
+				if op.operator == "TD" {
+					state.text.leading = -args[1].num
+				}
+				tlm = affineMatrix{1, 0, 0, 1, args[0].num, args[1].num}.multiply(tlm)
+				tm = tlm
+			}
+		case "Tm":
+			if m, ok := operandMatrix(args); ok {
+				tm, tlm = m, m
+			}
+		case "T*":
+			tlm = affineMatrix{1, 0, 0, 1, 0, -state.text.leading}.multiply(tlm)
+			tm = tlm
+		case "Tj", "'", "\"", "TJ":
+			var prefix bytes.Buffer
+			if op.operator == "\"" && len(args) == 3 {
+				state.text.wordSpacing, state.text.charSpacing = args[0].num, args[1].num
+				fmt.Fprintf(&prefix, "%s Tw %s Tc ", formatContentNumber(args[0].num), formatContentNumber(args[1].num))
+			}
+			if op.operator == "'" || op.operator == "\"" {
+				tlm = affineMatrix{1, 0, 0, 1, 0, -state.text.leading}.multiply(tlm)
+				tm = tlm
+				prefix.WriteString("T* ")
+			}
+			if len(args) == 0 {
+				break
+			}
+			shown := args[len(args)-1]
+			if op.operator != "TJ" {
+				shown = contentObject{kind: contentArray, items: []contentObject{shown}}
+			}
+			array, count := c.showText(shown.items, &state, &tm)
+			if count > 0 {
+				result.Glyphs += count
+				prefix.Write(array)
+				prefix.WriteString(" TJ")
+				replacement = prefix.Bytes()
+			}
+		case "BI":
+			if c.overlaps(unitSquareBox(state.ctm)) {
+				result.Images++
+				removed = true
+			}
+		case "Do":
+			if len(args) == 0 {
+				break
+			}
+			xobject := resources.Key("XObject").Key(args[0].text)
+			switch xobject.Key("Subtype").Name() {
+			case "Image":
+				if c.overlaps(unitSquareBox(state.ctm)) {
+					result.Images++
+					removed = true
+				}
+			case "Form":
+				form, err := c.redactForm(xobject, resources, state.ctm, depth)
+				if err != nil {
+					return nil, 0, err
+				}
+				if form != nil {
+					result.Glyphs += form.Content.Glyphs
+					result.Images += form.Content.Images
+					result.Forms = append(result.Forms, *form)
+					replacement = []byte("/" + form.Name + " Do")
+				}
+			}
+		}
+
+		switch {
+		case removed:
+			continue
+		case replacement != nil:
+			out.Write(replacement)
+		default:
+			out.Write(data[op.start:op.end])
+		}
+		out.WriteByte('\n')
+	}
+
+	result.Content = out.Bytes()
+	return result, len(stack), nil
+}
+
+// showText places the glyphs of a TJ array and rebuilds it without the redacted ones. The
+// count of removed glyphs is zero when the array is unchanged.
+func (c *contentRedactor) showText(items []contentObject, state *redactionState, tm *affineMatrix) ([]byte, int) {
+	text := state.text
+	var b bytes.Buffer
+	b.WriteByte('[')
+	removed := 0
+	for _, item := range items {
+		switch item.kind {
+		case contentNumber:
+			*tm = affineMatrix{1, 0, 0, 1, -item.num / 1000 * text.size * text.scale, 0}.multiply(*tm)
+			b.WriteString(formatContentNumber(item.num) + " ")
+		case contentString:
+			var kept []byte
+			for _, code := range text.font.codes(item.text) {
+				width := text.font.width(code)
+				advance := width*text.size + text.charSpacing
+				if len(code) == 1 && code[0] == ' ' {
+					advance += text.wordSpacing
+				}
+
+				render := affineMatrix{text.size * text.scale, 0, 0, text.size, 0, text.rise}.
+					multiply(*tm).multiply(state.ctm)
+				center := render.transform(width/2, glyphCenterRatio)
+				if c.contains(center) {
+					removed++
+					if len(kept) > 0 {
+						writeContentString(&b, string(kept))
+						b.WriteByte(' ')
+						kept = nil
+					}
+					if text.size != 0 {
+						b.WriteString(formatContentNumber(-advance/text.size*1000) + " ")
+					}
+				} else {
+					kept = append(kept, code...)
+				}
+				*tm = affineMatrix{1, 0, 0, 1, advance * text.scale, 0}.multiply(*tm)
+			}
+			if len(kept) > 0 {
+				writeContentString(&b, string(kept))
+				b.WriteByte(' ')
+			}
+		}
+	}
+	b.WriteByte(']')
+	return b.Bytes(), removed
+}
+
+// redactForm rewrites a form XObject overlapping the redaction areas. It returns nil when
+// the form draws nothing in them.
+func (c *contentRedactor) redactForm(
+	form, parentResources pdf.Value, ctm affineMatrix, depth int,
+) (*RedactedForm, error) {
+	matrix := identityMatrix
+	if m := form.Key("Matrix"); m.Len() == len(matrix) {
+		for i := range matrix {
+			matrix[i] = m.Index(i).Float64()
+		}
+	}
+	ctm = matrix.multiply(ctm)
+
+	if bbox := form.Key("BBox"); bbox.Len() == 4 {
+		corners := []Coordinate{
+			ctm.transform(bbox.Index(0).Float64(), bbox.Index(1).Float64()),
+			ctm.transform(bbox.Index(2).Float64(), bbox.Index(1).Float64()),
+			ctm.transform(bbox.Index(0).Float64(), bbox.Index(3).Float64()),
+			ctm.transform(bbox.Index(2).Float64(), bbox.Index(3).Float64()),
+		}
+		if !c.overlaps(pointsBox(corners)) {
+			return nil, nil
+		}
+	}
+	if depth >= maxFormDepth {
+		return nil, fmt.Errorf("form XObjects nested more than %d deep", maxFormDepth)
+	}
+
+	data, err := readStream(form)
+	if err != nil {
+		return nil, err
+	}
+	resources := form.Key("Resources")
+	if resources.IsNull() {
+		resources = parentResources
+	}
+	content, _, err := c.redact(data, resources, ctm, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	if content.Glyphs == 0 && content.Images == 0 {
+		return nil, nil
+	}
+
+	// Pick a name unused by the resources drawing the form
+	var name string
+	for name == "" || c.names[name] || !parentResources.Key("XObject").Key(name).IsNull() {
+		c.forms++
+		name = fmt.Sprintf("Redacted%d", c.forms)
+	}
+	c.names[name] = true
+	return &RedactedForm{Name: name, Form: form, Resources: resources, Content: *content}, nil
+}
+
+// contains reports whether a point lies in a redaction area
+func (c *contentRedactor) contains(p Coordinate) bool {
+	for _, area := range c.areas {
+		if boxContains(area, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether a box shares any area with a redaction area
+func (c *contentRedactor) overlaps(box BoundingBox) bool {
+	for _, area := range c.areas {
+		if box.LowerLeft.X < area.UpperRight.X && area.LowerLeft.X < box.UpperRight.X &&
+			box.LowerLeft.Y < area.UpperRight.Y && area.LowerLeft.Y < box.UpperRight.Y {
+			return true
+		}
+	}
+	return false
+}
+
+// unitSquareBox returns where an image, drawn in the unit square, lands on the page
+func unitSquareBox(ctm affineMatrix) BoundingBox {
+	return pointsBox([]Coordinate{
+		ctm.transform(0, 0), ctm.transform(1, 0), ctm.transform(0, 1), ctm.transform(1, 1),
+	})
+}
+
+// pointsBox returns the smallest box containing the points
+func pointsBox(points []Coordinate) BoundingBox {
+	box := boxFromPoints(points[0], points[0])
+	for _, p := range points[1:] {
+		box = unionBoxes(box, boxFromPoints(p, p))
+	}
+	return box
+}
+
+// operandMatrix reads the six numbers of a cm or Tm operator
+func operandMatrix(args []contentObject) (affineMatrix, bool) {
+	var m affineMatrix
+	if len(args) != len(m) {
+		return m, false
+	}
+	for i := range m {
+		m[i] = args[i].num
+	}
+	return m, true
+}
+
+// numberOperand returns the i'th operand as a number, or zero when it is missing
+func numberOperand(args []contentObject, i int) float64 {
+	if i >= len(args) {
+		return 0
+	}
+	return args[i].num
+}
+
+// fontMetrics gives the advance widths of a font's character codes, in text space units
+type fontMetrics struct {
+	twoByte      bool // Type0 fonts use two-byte codes
+	firstChar    int
+	widths       map[int]float64
+	defaultWidth float64
+	scale        float64 // Glyph space to text space
+}
+
+// newFontMetrics reads the widths of a font dictionary. Fonts without a width table use
+// the fallback width, like the rest of the layout code.
+func newFontMetrics(font pdf.Value) *fontMetrics {
+	metrics := &fontMetrics{widths: map[int]float64{}, defaultWidth: fallbackGlyphWidth, scale: 0.001}
+
+	switch font.Key("Subtype").Name() {
+	case "Type0":
+		metrics.twoByte = true
+		desc := font.Key("DescendantFonts").Index(0)
+		metrics.defaultWidth = 1000
+		if dw := desc.Key("DW"); dw.Kind() == pdf.Integer || dw.Kind() == pdf.Real {
+			metrics.defaultWidth = dw.Float64()
+		}
+		w := desc.Key("W")
+		for i := 0; i < w.Len(); {
+			first := int(w.Index(i).Int64())
+			if next := w.Index(i + 1); next.Kind() == pdf.Array {
+				for j := 0; j < next.Len(); j++ {
+					metrics.widths[first+j] = next.Index(j).Float64()
+				}
+				i += 2
+				continue
+			}
+			last := int(w.Index(i + 1).Int64())
+			for code := first; code <= last && code-first < 1<<16; code++ {
+				metrics.widths[code] = w.Index(i + 2).Float64()
+			}
+			i += 3
+		}
+		return metrics
+	case "Type3":
+		if m := font.Key("FontMatrix"); m.Len() == 6 {
+			metrics.scale = m.Index(0).Float64()
+		}
+	}
+
+	widths := font.Key("Widths")
+	if widths.Len() == 0 {
+		return metrics
+	}
+	metrics.firstChar = int(font.Key("FirstChar").Int64())
+	for i := 0; i < widths.Len(); i++ {
+		metrics.widths[metrics.firstChar+i] = widths.Index(i).Float64()
+	}
+	metrics.defaultWidth = font.Key("FontDescriptor").Key("MissingWidth").Float64()
+	return metrics
+}
+
+// codes splits a string into character codes
+func (f *fontMetrics) codes(s string) [][]byte {
+	size := 1
+	if f != nil && f.twoByte {
+		size = 2
+	}
+	codes := make([][]byte, 0, len(s)/size)
+	for i := 0; i < len(s); i += size {
+		codes = append(codes, []byte(s[i:min(i+size, len(s))]))
+	}
+	return codes
+}
+
+// width returns the advance of a character code for a font size of one
+func (f *fontMetrics) width(code []byte) float64 {
+	if f == nil {
+		return fallbackGlyphWidth / 1000
+	}
+	value := 0
+	for _, b := range code {
+		value = value<<8 | int(b)
+	}
+	width, ok := f.widths[value]
+	if !ok {
+		width = f.defaultWidth
+	}
+	return math.Abs(width * f.scale)
+}
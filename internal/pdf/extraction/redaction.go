@@ -0,0 +1,102 @@
+package extraction
+
+import (
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// quadPointsPerRect is the number of coordinates describing one quadrilateral in /QuadPoints
+const quadPointsPerRect = 8
+
+// PageRedactions returns the areas marked by the redaction annotations of a page. Marked
+// content is still present in the file until the redactions are applied, so extraction
+// leaves it out instead.
+func PageRedactions(page pdf.Page) []BoundingBox {
+	var areas []BoundingBox
+	annots := page.V.Key("Annots")
+	for i := 0; i < annots.Len(); i++ {
+		annot := annots.Index(i)
+		if annot.Key("Subtype").Name() != "Redact" {
+			continue
+		}
+		areas = append(areas, RedactionAreas(annot)...)
+	}
+	return areas
+}
+
+// RedactionAreas returns the areas of a redaction annotation: one per quadrilateral of
+// /QuadPoints, or its /Rect when it has none
+func RedactionAreas(annot pdf.Value) []BoundingBox {
+	var areas []BoundingBox
+	quads := annot.Key("QuadPoints")
+	for i := 0; i+quadPointsPerRect <= quads.Len(); i += quadPointsPerRect {
+		box := boxFromPoints(
+			Coordinate{X: quads.Index(i).Float64(), Y: quads.Index(i + 1).Float64()},
+			Coordinate{X: quads.Index(i).Float64(), Y: quads.Index(i + 1).Float64()},
+		)
+		for j := 2; j < quadPointsPerRect; j += 2 {
+			point := Coordinate{X: quads.Index(i + j).Float64(), Y: quads.Index(i + j + 1).Float64()}
+			box = unionBoxes(box, boxFromPoints(point, point))
+		}
+		areas = append(areas, box)
+	}
+	if len(areas) > 0 {
+		return areas
+	}
+
+	rect := annot.Key("Rect")
+	if rect.Len() == 4 {
+		areas = append(areas, boxFromPoints(
+			Coordinate{X: rect.Index(0).Float64(), Y: rect.Index(1).Float64()},
+			Coordinate{X: rect.Index(2).Float64(), Y: rect.Index(3).Float64()},
+		))
+	}
+	return areas
+}
+
+// redacted reports whether a glyph's center lies in one of the areas
+func redacted(areas []BoundingBox, box BoundingBox) bool {
+	center := boxCenter(box)
+	for _, area := range areas {
+		if boxContains(area, center) {
+			return true
+		}
+	}
+	return false
+}
+
+// PlainText returns a page's text like the parser's GetPlainText, leaving out text under
+// redaction annotations. Pages without redactions are read by the parser unchanged, or
+// through the fonts' CMaps when they use composite fonts.
+func PlainText(page pdf.Page) (string, error) {
+	if len(PageRedactions(page)) == 0 {
+		if !pageHasCompositeFonts(page) {
+			return page.GetPlainText(nil)
+		}
+		decoded, err := decodePageText(page)
+		if err != nil {
+			return "", err
+		}
+		return decoded.text, nil
+	}
+
+	glyphs, err := pageGlyphs(page)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	lastLine := 0
+	for i, word := range groupGlyphsIntoWords(glyphs) {
+		switch {
+		case i == 0:
+		case word.Line != lastLine:
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(' ')
+		}
+		b.WriteString(word.Text)
+		lastLine = word.Line
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,123 @@
+package extraction
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// CenteredIn reports whether content lies in a region. Content is assigned by the center
+// of its box, so a word or table straddling the edge belongs to the side holding most of it.
+func CenteredIn(box, region BoundingBox) bool {
+	return boxContains(region, boxCenter(box))
+}
+
+// extractRegionText reads the text of a page region as lines of positioned words, ordered
+// top to bottom and left to right. Unlike the estimated positions of structured text, the
+// boxes are those of the glyphs drawn.
+func (e *DefaultEngine) extractRegionText(
+	page pdf.Page, pageNum int, config ExtractionConfig,
+) ([]ContentElement, error) {
+	words, err := PageWords(page)
+	if err != nil {
+		return nil, err
+	}
+
+	var inside []WordElement
+	for _, word := range words {
+		if CenteredIn(word.BoundingBox, *config.Region) {
+			inside = append(inside, word)
+		}
+	}
+
+	var elements []ContentElement
+	for lineIdx, row := range wordRows(inside) {
+		texts := make([]string, len(row))
+		box := row[0].BoundingBox
+		fontSize := 0.0
+		for i, word := range row {
+			texts[i] = word.Text
+			box = unionBoxes(box, word.BoundingBox)
+			fontSize = math.Max(fontSize, word.Properties.FontSize)
+		}
+
+		line := ContentElement{
+			ID:          e.generateID("line", pageNum, lineIdx),
+			Type:        ContentTypeText,
+			PageNumber:  pageNum,
+			BoundingBox: box,
+			Content: TextElement{
+				Text:       strings.Join(texts, " "),
+				Properties: TextProperties{FontName: row[0].Properties.FontName, FontSize: fontSize},
+			},
+			Confidence: 1.0,
+		}
+		if config.IncludeCoordinates {
+			for wordIdx, word := range row {
+				line.Children = append(line.Children, ContentElement{
+					ID:          e.generateID("word", pageNum, lineIdx*1000+wordIdx),
+					Type:        ContentTypeText,
+					PageNumber:  pageNum,
+					BoundingBox: word.BoundingBox,
+					Content:     TextElement{Text: word.Text, Properties: word.Properties},
+					Parent:      &line.ID,
+					Confidence:  word.Confidence,
+				})
+			}
+		}
+		elements = append(elements, line)
+	}
+
+	return elements, nil
+}
+
+// wordRows groups words into rows from the top down, each ordered left to right. A word
+// joins the current row while its top is within half its height of the row's top.
+func wordRows(words []WordElement) [][]WordElement {
+	sorted := append([]WordElement{}, words...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].BoundingBox.UpperRight.Y > sorted[b].BoundingBox.UpperRight.Y
+	})
+
+	var rows [][]WordElement
+	rowTop := 0.0
+	for _, word := range sorted {
+		box := word.BoundingBox
+		if len(rows) == 0 || rowTop-box.UpperRight.Y > box.Height/2 {
+			rows = append(rows, nil)
+			rowTop = box.UpperRight.Y
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], word)
+	}
+	for _, row := range rows {
+		sort.SliceStable(row, func(a, b int) bool {
+			return row[a].BoundingBox.LowerLeft.X < row[b].BoundingBox.LowerLeft.X
+		})
+	}
+	return rows
+}
+
+// clipToRegion keeps the elements centered in a region. Text has already been read from the
+// region word by word.
+func clipToRegion(elements []ContentElement, region BoundingBox) []ContentElement {
+	kept := elements[:0]
+	for _, element := range elements {
+		if CenteredIn(element.BoundingBox, region) {
+			kept = append(kept, element)
+		}
+	}
+	return kept
+}
+
+// tablesInRegion keeps the tables centered in a region
+func tablesInRegion(tables []TableElement, region BoundingBox) []TableElement {
+	kept := tables[:0]
+	for _, table := range tables {
+		if CenteredIn(table.BoundingBox, region) {
+			kept = append(kept, table)
+		}
+	}
+	return kept
+}
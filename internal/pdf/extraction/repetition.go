@@ -0,0 +1,244 @@
+package extraction
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Roles of repeated text
+const (
+	RoleHeader    = "header"
+	RoleFooter    = "footer"
+	RoleWatermark = "watermark"
+)
+
+// Repetition analysis constants
+const (
+	// repeatedLineShare is the share of the analyzed pages a line must appear on to be
+	// running text rather than content
+	repeatedLineShare = 0.5
+	// repeatedLineTolerance is how far, in points, the centers of a repeated line may lie
+	// apart between pages
+	repeatedLineTolerance = 4.0
+	// marginShare is the share of the page height at the top and at the bottom holding
+	// headers and footers; repeated text between them is a watermark
+	marginShare = 0.15
+	// letterHeight is the height of a US Letter page, assumed for pages without a media box
+	letterHeight = 792.0
+)
+
+// repeatedDigits masks the page numbers and dates that change between running headers
+var repeatedDigits = regexp.MustCompile(`\d+`)
+
+// RepeatedLine is a line of text drawn at about the same position on many pages: a running
+// header or footer, a page number, or a watermark
+type RepeatedLine struct {
+	Text        string      `json:"text"` // As drawn on this page
+	Role        string      `json:"role"` // header, footer, or watermark
+	Page        int         `json:"page"`
+	BoundingBox BoundingBox `json:"bounding_box"`
+	key         string
+}
+
+// repeatedLineKey normalizes a line so lines that differ only in their numbers, such as
+// "Page 3 of 12" and "Page 4 of 12", match
+func repeatedLineKey(text string) string {
+	return strings.ToLower(repeatedDigits.ReplaceAllString(strings.Join(strings.Fields(text), " "), "#"))
+}
+
+// FindRepeatedLines finds the lines of text drawn at about the same height on at least half
+// of the given pages, and on at least two, grouped by page. Lines are compared with their
+// numbers masked, so page numbers are found as well.
+func FindRepeatedLines(numbering *PageNumbering, pages []int) map[int][]RepeatedLine {
+	if len(pages) < 2 {
+		return nil
+	}
+
+	// Occurrences of each line, by normalized text
+	occurrences := make(map[string][]RepeatedLine)
+	for _, pageNum := range pages {
+		words, err := PageWords(numbering.Page(pageNum))
+		if err != nil {
+			continue
+		}
+		role := marginRoles(numbering, pageNum)
+		for _, row := range wordRows(words) {
+			texts := make([]string, len(row))
+			box := row[0].BoundingBox
+			for i, word := range row {
+				texts[i] = word.Text
+				box = unionBoxes(box, word.BoundingBox)
+			}
+			line := RepeatedLine{Text: strings.Join(texts, " "), Page: pageNum, BoundingBox: box}
+			line.key = repeatedLineKey(line.Text)
+			if line.key == "" {
+				continue
+			}
+			line.Role = role(box)
+			occurrences[line.key] = append(occurrences[line.key], line)
+		}
+	}
+
+	minPages := max(2, int(math.Ceil(float64(len(pages))*repeatedLineShare)))
+	repeated := make(map[int][]RepeatedLine)
+	for _, lines := range occurrences {
+		for _, line := range repeatedAtSameHeight(lines, minPages) {
+			repeated[line.Page] = append(repeated[line.Page], line)
+		}
+	}
+	for _, lines := range repeated {
+		sort.Slice(lines, func(a, b int) bool {
+			return lines[a].BoundingBox.UpperRight.Y > lines[b].BoundingBox.UpperRight.Y
+		})
+	}
+	return repeated
+}
+
+// repeatedAtSameHeight returns the occurrences of a line whose centers lie within the
+// tolerance of an occurrence shared by at least minPages pages
+func repeatedAtSameHeight(lines []RepeatedLine, minPages int) []RepeatedLine {
+	if len(lines) < minPages {
+		return nil
+	}
+	center := func(line RepeatedLine) float64 {
+		return (line.BoundingBox.LowerLeft.Y + line.BoundingBox.UpperRight.Y) / 2
+	}
+
+	matched := make([]bool, len(lines))
+	for _, anchor := range lines {
+		pages := make(map[int]bool)
+		for _, line := range lines {
+			if math.Abs(center(line)-center(anchor)) <= repeatedLineTolerance {
+				pages[line.Page] = true
+			}
+		}
+		if len(pages) < minPages {
+			continue
+		}
+		for i, line := range lines {
+			if math.Abs(center(line)-center(anchor)) <= repeatedLineTolerance {
+				matched[i] = true
+			}
+		}
+	}
+
+	var repeated []RepeatedLine
+	for i, line := range lines {
+		if matched[i] {
+			repeated = append(repeated, line)
+		}
+	}
+	return repeated
+}
+
+// marginRoles returns a function naming the role of repeated text on a page by where it
+// lies: in the top or bottom margin, or between them
+func marginRoles(numbering *PageNumbering, pageNum int) func(box BoundingBox) string {
+	media, ok := pageBox(numbering.MediaBox(pageNum))
+	if !ok {
+		media = newBox(0, 0, 0, letterHeight)
+	}
+	margin := media.Height * marginShare
+	return func(box BoundingBox) string {
+		switch middle := (box.LowerLeft.Y + box.UpperRight.Y) / 2; {
+		case middle >= media.UpperRight.Y-margin:
+			return RoleHeader
+		case middle <= media.LowerLeft.Y+margin:
+			return RoleFooter
+		default:
+			return RoleWatermark
+		}
+	}
+}
+
+// matchRepeatedLine returns the repeated line of a page with the given text
+func matchRepeatedLine(lines []RepeatedLine, text string) (RepeatedLine, bool) {
+	key := repeatedLineKey(text)
+	for _, line := range lines {
+		if line.key == key {
+			return line, true
+		}
+	}
+	return RepeatedLine{}, false
+}
+
+// RemoveRepeatedLines removes the lines of a page's text that are repeated lines of the
+// page, returning the remaining text and the lines removed
+func RemoveRepeatedLines(text string, lines []RepeatedLine) (string, []RepeatedLine) {
+	if len(lines) == 0 {
+		return text, nil
+	}
+
+	var kept []string
+	var removed []RepeatedLine
+	for _, textLine := range strings.Split(text, "\n") {
+		if line, ok := matchRepeatedLine(lines, textLine); ok {
+			removed = append(removed, line)
+			continue
+		}
+		kept = append(kept, textLine)
+	}
+	if len(removed) == 0 {
+		return text, nil
+	}
+	return strings.Trim(strings.Join(kept, "\n"), "\n"), removed
+}
+
+// labelRepeatedContent marks the text repeated across the extracted pages as structural.
+// Line elements that are repeated lines are relabeled in place; the repeated lines of
+// elements holding a page's whole text are taken out of the text and follow it as
+// structural elements of their own.
+func (e *DefaultEngine) labelRepeatedContent(result *ExtractionResult, numbering *PageNumbering) {
+	var pages []int
+	seen := make(map[int]bool)
+	for i := range result.Elements {
+		if page := result.Elements[i].PageNumber; result.Elements[i].Type == ContentTypeText && !seen[page] {
+			seen[page] = true
+			pages = append(pages, page)
+		}
+	}
+	repeated := FindRepeatedLines(numbering, pages)
+	if len(repeated) == 0 {
+		return
+	}
+
+	elements := make([]ContentElement, 0, len(result.Elements))
+	for _, element := range result.Elements {
+		text, ok := element.Content.(TextElement)
+		lines := repeated[element.PageNumber]
+		if element.Type != ContentTypeText || !ok || len(lines) == 0 {
+			elements = append(elements, element)
+			continue
+		}
+
+		if !strings.Contains(strings.TrimSpace(text.Text), "\n") {
+			if line, ok := matchRepeatedLine(lines, text.Text); ok {
+				element.Type = ContentTypeStructural
+				element.Role = line.Role
+				element.BoundingBox = line.BoundingBox
+			}
+			elements = append(elements, element)
+			continue
+		}
+
+		remaining, removed := RemoveRepeatedLines(text.Text, lines)
+		text.Text = remaining
+		element.Content = text
+		elements = append(elements, element)
+		for i, line := range removed {
+			elements = append(elements, ContentElement{
+				ID:          e.generateID("structural", element.PageNumber, i),
+				Type:        ContentTypeStructural,
+				Role:        line.Role,
+				PageNumber:  element.PageNumber,
+				PageLabel:   element.PageLabel,
+				BoundingBox: line.BoundingBox,
+				Content:     TextElement{Text: line.Text},
+				Confidence:  1.0,
+			})
+		}
+	}
+	result.Elements = elements
+}
@@ -0,0 +1,105 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+)
+
+// writeRunningHeadersPDF writes a document whose pages share a header, a numbered footer,
+// and a watermark around a different line of body text
+func writeRunningHeadersPDF(t *testing.T, bodies ...string) string {
+	t.Helper()
+
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", "", "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"}
+	kids := make([]string, 0, len(bodies))
+	for i, body := range bodies {
+		content := "BT /F1 9 Tf 72 770 Td (Quarterly Review) Tj ET\n" +
+			"BT /F1 40 Tf 200 400 Td (DRAFT) Tj ET\n" +
+			"BT /F1 11 Tf 72 600 Td (" + body + ") Tj ET\n" +
+			fmt.Sprintf("BT /F1 9 Tf 300 30 Td (Page %d) Tj ET", i+1)
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+i*2))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] "+
+				"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+i*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(bodies))
+
+	return writeRawPDF(t, "review.pdf", objects)
+}
+
+func TestFindRepeatedLines(t *testing.T) {
+	path := writeRunningHeadersPDF(t, "Sales rose in March.", "Costs fell in April.", "Margins held in May.")
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+
+	numbering := NewPageNumbering(doc.Reader)
+	repeated := FindRepeatedLines(numbering, []int{1, 2, 3})
+	for pageNum := 1; pageNum <= 3; pageNum++ {
+		roles := make(map[string]string)
+		for _, line := range repeated[pageNum] {
+			roles[line.Text] = line.Role
+		}
+		want := map[string]string{
+			"Quarterly Review":              RoleHeader,
+			"DRAFT":                         RoleWatermark,
+			fmt.Sprintf("Page %d", pageNum): RoleFooter,
+		}
+		if len(roles) != len(want) {
+			t.Errorf("page %d repeated lines = %v, want %v", pageNum, roles, want)
+			continue
+		}
+		for text, role := range want {
+			if roles[text] != role {
+				t.Errorf("page %d %q role = %q, want %q", pageNum, text, roles[text], role)
+			}
+		}
+	}
+
+	if repeated := FindRepeatedLines(numbering, []int{2}); len(repeated) != 0 {
+		t.Errorf("FindRepeatedLines() for one page = %v, want none", repeated)
+	}
+
+	text, removed := RemoveRepeatedLines("Quarterly Review\nDRAFT\nCosts fell in April.\nPage 2", repeated[2])
+	if text != "Costs fell in April." || len(removed) != 3 {
+		t.Errorf("RemoveRepeatedLines() = %q, %v, want only the body left", text, removed)
+	}
+}
+
+func TestExtract_LabelsRepeatedContentStructural(t *testing.T) {
+	path := writeRunningHeadersPDF(t, "Sales rose in March.", "Costs fell in April.")
+	engine := NewEngine()
+
+	result, err := engine.Extract(context.Background(), ExtractionRequest{
+		FilePath: path, Config: ExtractionConfig{Mode: ModeRaw, ExtractText: true},
+	})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+
+	roles := make(map[string]int)
+	for _, element := range result.Elements {
+		text, _ := element.Content.(TextElement)
+		switch element.Type {
+		case ContentTypeStructural:
+			roles[element.Role]++
+			if element.BoundingBox.Height <= 0 {
+				t.Errorf("structural element %q has no position", text.Text)
+			}
+		case ContentTypeText:
+			if strings.Contains(text.Text, "Quarterly Review") || strings.Contains(text.Text, "DRAFT") {
+				t.Errorf("text element %q still holds repeated lines", text.Text)
+			}
+		}
+	}
+	if roles[RoleHeader] != 2 || roles[RoleFooter] != 2 || roles[RoleWatermark] != 2 {
+		t.Errorf("structural elements by role = %v, want a header, footer, and watermark on each page", roles)
+	}
+}
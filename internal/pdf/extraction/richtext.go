@@ -0,0 +1,183 @@
+package extraction
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// RichTextSpan is a run of rich text in one style
+type RichTextSpan struct {
+	Text      string  `json:"text"`
+	Bold      bool    `json:"bold,omitempty"`
+	Italic    bool    `json:"italic,omitempty"`
+	Underline bool    `json:"underline,omitempty"`
+	Font      string  `json:"font,omitempty"`  // First family of the font-family property
+	Size      float64 `json:"size,omitempty"`  // Font size in points
+	Color     string  `json:"color,omitempty"` // As written, such as #FF0000
+}
+
+// sameStyle reports whether two spans are in the same style
+func (s RichTextSpan) sameStyle(other RichTextSpan) bool {
+	other.Text = s.Text
+	return s == other
+}
+
+// ParseRichText reads a rich text string, the XHTML body of a field's /RV entry, into its
+// plain text and its runs of text by style. Styles are taken from b, i, and u elements and
+// the font, color, and text-decoration properties of style attributes; paragraphs and line
+// breaks become newlines, and other markup is dropped, keeping its text. Malformed markup
+// ends the text where it stops parsing.
+func ParseRichText(xhtml string) (string, []RichTextSpan) {
+	decoder := xml.NewDecoder(strings.NewReader(xhtml))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var spans []RichTextSpan
+	var text strings.Builder
+	space := false // Whitespace was seen since the last text, to be written before the next
+	add := func(s string, style RichTextSpan) {
+		if last := len(spans) - 1; last >= 0 && spans[last].sameStyle(style) {
+			spans[last].Text += s
+		} else {
+			style.Text = s
+			spans = append(spans, style)
+		}
+		text.WriteString(s)
+	}
+	lineStart := func() bool {
+		return text.Len() == 0 || strings.HasSuffix(text.String(), "\n")
+	}
+
+	styles := []RichTextSpan{{}}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		style := styles[len(styles)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "b", "strong":
+				style.Bold = true
+			case "i", "em":
+				style.Italic = true
+			case "u":
+				style.Underline = true
+			case "p", "div":
+				if !lineStart() {
+					add("\n", style)
+				}
+				space = false
+			case "br":
+				add("\n", style)
+				space = false
+			}
+			for _, attr := range t.Attr {
+				if strings.EqualFold(attr.Name.Local, "style") {
+					applyRichTextStyle(&style, attr.Value)
+				}
+			}
+			styles = append(styles, style)
+		case xml.EndElement:
+			if len(styles) > 1 {
+				styles = styles[:len(styles)-1]
+			}
+		case xml.CharData:
+			// Whitespace collapses as in HTML, and none is kept at either end of a line
+			words := strings.FieldsFunc(string(t), isSpace)
+			if len(t) > 0 && isSpace(rune(t[0])) {
+				space = true
+			}
+			if len(words) > 0 {
+				s := strings.Join(words, " ")
+				if space && !lineStart() {
+					s = " " + s
+				}
+				add(s, style)
+				space = isSpace(rune(t[len(t)-1]))
+			}
+		}
+	}
+	for len(spans) > 0 {
+		last := len(spans) - 1
+		if spans[last].Text = strings.TrimRight(spans[last].Text, "\n"); spans[last].Text != "" {
+			break
+		}
+		spans = spans[:last]
+	}
+	return strings.TrimRight(text.String(), "\n"), spans
+}
+
+// applyRichTextStyle applies the properties of a CSS style attribute to a span's style
+func applyRichTextStyle(style *RichTextSpan, css string) {
+	for _, declaration := range strings.Split(css, ";") {
+		property, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(property)) {
+		case "font-weight":
+			weight, err := strconv.Atoi(value)
+			style.Bold = strings.EqualFold(value, "bold") || strings.EqualFold(value, "bolder") ||
+				(err == nil && weight >= 600)
+		case "font-style":
+			style.Italic = strings.EqualFold(value, "italic") || strings.EqualFold(value, "oblique")
+		case "text-decoration":
+			style.Underline = strings.Contains(strings.ToLower(value), "underline")
+		case "font-family":
+			style.Font = fontFamily(value)
+		case "font-size":
+			if size, ok := fontSize(value); ok {
+				style.Size = size
+			}
+		case "color":
+			style.Color = value
+		case "font":
+			applyFontShorthand(style, value)
+		}
+	}
+}
+
+// applyFontShorthand applies the font property: style and weight keywords, then a size and
+// the font families
+func applyFontShorthand(style *RichTextSpan, value string) {
+	fields := strings.Fields(value)
+	for i, field := range fields {
+		switch strings.ToLower(field) {
+		case "italic", "oblique":
+			style.Italic = true
+		case "bold", "bolder":
+			style.Bold = true
+		case "normal":
+		default:
+			// A size may be followed by a line height, as in 12pt/14pt
+			if size, ok := fontSize(strings.SplitN(field, "/", 2)[0]); ok {
+				style.Size = size
+				style.Font = fontFamily(strings.Join(fields[i+1:], " "))
+			}
+			return
+		}
+	}
+}
+
+// fontFamily returns the first family of a font-family list, unquoted
+func fontFamily(families string) string {
+	family, _, _ := strings.Cut(families, ",")
+	return strings.Trim(strings.TrimSpace(family), `'"`)
+}
+
+// fontSize reads a font size in points, given in pt or px or without a unit
+func fontSize(value string) (float64, bool) {
+	value = strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(value), "pt"), "px")
+	size, err := strconv.ParseFloat(value, 64)
+	return size, err == nil && size > 0
+}
+
+// isSpace reports whether a character is XML whitespace
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
@@ -0,0 +1,55 @@
+package extraction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRichText(t *testing.T) {
+	tests := []struct {
+		name  string
+		xhtml string
+		text  string
+		spans []RichTextSpan
+	}{
+		{
+			name: "paragraphs with inline styles",
+			xhtml: `<?xml version="1.0"?><body xmlns="http://www.w3.org/1999/xhtml" ` +
+				`style="font-size:12pt;font-family:'Helvetica', sans-serif">` + "\n" +
+				`<p>Total <b>due</b> now</p>` + "\n" +
+				`<p style="color:#FF0000;font-style:italic">Late&nbsp;fee <span style="text-decoration:underline">` +
+				`applies</span></p></body>`,
+			text: "Total due now\nLate\u00a0fee applies",
+			spans: []RichTextSpan{
+				{Text: "Total", Font: "Helvetica", Size: 12},
+				{Text: " due", Bold: true, Font: "Helvetica", Size: 12},
+				{Text: " now\n", Font: "Helvetica", Size: 12},
+				{Text: "Late\u00a0fee", Italic: true, Font: "Helvetica", Size: 12, Color: "#FF0000"},
+				{Text: " applies", Italic: true, Underline: true, Font: "Helvetica", Size: 12, Color: "#FF0000"},
+			},
+		},
+		{
+			name:  "font shorthand and line breaks",
+			xhtml: `<body><span style="font: bold 10pt/12pt Courier">A<br/>B<br/></span></body>`,
+			text:  "A\nB",
+			spans: []RichTextSpan{{Text: "A\nB", Bold: true, Font: "Courier", Size: 10}},
+		},
+		{
+			name:  "malformed markup",
+			xhtml: `<body><p>Kept <i>text`,
+			text:  "Kept text",
+			spans: []RichTextSpan{{Text: "Kept"}, {Text: " text", Italic: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, spans := ParseRichText(tt.xhtml)
+			if text != tt.text {
+				t.Errorf("ParseRichText() text = %q, want %q", text, tt.text)
+			}
+			if !reflect.DeepEqual(spans, tt.spans) {
+				t.Errorf("ParseRichText() spans = %+v, want %+v", spans, tt.spans)
+			}
+		})
+	}
+}
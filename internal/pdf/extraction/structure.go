@@ -0,0 +1,516 @@
+package extraction
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Structure tree constants
+const (
+	// StageStructure is the stage of issues found while reading the structure tree
+	StageStructure = "structure"
+	// maxStructureElements is the most structure elements read from one document
+	maxStructureElements = 20000
+	// maxStructureDepth limits recursion into the structure tree
+	maxStructureDepth = 64
+	// maxRoleMapSteps limits how many role map entries are followed for one type
+	maxRoleMapSteps = 8
+	// wordGapThousandths is the TJ adjustment, in thousandths of the font size, read as a space
+	wordGapThousandths = 250
+)
+
+// standardStructureTypes are the structure types defined by the PDF specification. Other
+// types must be mapped onto them through the role map.
+var standardStructureTypes = map[string]bool{
+	"Document": true, "Part": true, "Art": true, "Sect": true, "Div": true, "BlockQuote": true,
+	"Caption": true, "TOC": true, "TOCI": true, "Index": true, "NonStruct": true, "Private": true,
+	"H": true, "H1": true, "H2": true, "H3": true, "H4": true, "H5": true, "H6": true, "P": true,
+	"L": true, "LI": true, "Lbl": true, "LBody": true,
+	"Table": true, "TR": true, "TH": true, "TD": true, "THead": true, "TBody": true, "TFoot": true,
+	"Span": true, "Quote": true, "Note": true, "Reference": true, "BibEntry": true, "Code": true,
+	"Link": true, "Annot": true, "Ruby": true, "RB": true, "RT": true, "RP": true,
+	"Warichu": true, "WT": true, "WP": true, "Figure": true, "Formula": true, "Form": true,
+}
+
+// inlineStructureTypes are the inline-level types, whose text is part of the text of the
+// element containing them rather than an element of its own
+var inlineStructureTypes = map[string]bool{
+	"Span": true, "Quote": true, "Note": true, "Reference": true, "BibEntry": true, "Code": true,
+	"Link": true, "Annot": true, "Ruby": true, "RB": true, "RT": true, "RP": true,
+	"Warichu": true, "WT": true, "WP": true,
+}
+
+// StructureElement is an element of a tagged document's logical structure
+type StructureElement struct {
+	Role       string             `json:"role"`           // Standard structure type, after role mapping
+	Type       string             `json:"type,omitempty"` // Type used by the document, when role mapped
+	Page       int                `json:"page,omitempty"`
+	Text       string             `json:"text,omitempty"` // Including the text of inline elements
+	AltText    string             `json:"alt_text,omitempty"`
+	ActualText string             `json:"actual_text,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Lang       string             `json:"lang,omitempty"`
+	Children   []StructureElement `json:"children,omitempty"`
+}
+
+// StructureTree is the logical structure of a tagged document
+type StructureTree struct {
+	Elements     []StructureElement `json:"elements"`
+	ElementCount int                `json:"element_count"`       // Elements read, including those on other pages
+	Truncated    bool               `json:"truncated,omitempty"` // More than maxStructureElements elements
+}
+
+// structureReader reads a structure tree and the marked content its elements refer to
+type structureReader struct {
+	pages   *PageNumbering
+	roleMap pdf.Value
+	read    map[int]bool           // Pages whose content is read; elements on other pages are left out
+	text    map[int]map[int]string // Marked content text by page and MCID
+	visited map[ObjectRef]bool
+	tree    *StructureTree
+	issues  []ParseIssue
+}
+
+// ReadStructureTree reads the logical structure of a tagged document, limited to the given
+// pages. Element text is read from the marked content of the pages' content streams; marked
+// content inside form XObjects is not read. It returns nil when the document has no
+// structure tree.
+func ReadStructureTree(r *pdf.Reader, pages *PageNumbering, pageNums []int) (tree *StructureTree, issues []ParseIssue) {
+	root := r.Trailer().Key("Root").Key("StructTreeRoot")
+	if root.Kind() != pdf.Dict {
+		return nil, nil
+	}
+
+	reader := &structureReader{
+		pages:   pages,
+		roleMap: root.Key("RoleMap"),
+		read:    make(map[int]bool, len(pageNums)),
+		text:    make(map[int]map[int]string),
+		visited: make(map[ObjectRef]bool),
+		tree:    &StructureTree{Elements: []StructureElement{}},
+	}
+	for _, pageNum := range pageNums {
+		reader.read[pageNum] = true
+	}
+
+	// The parser panics on malformed objects; elements read so far are kept
+	defer func() {
+		if rec := recover(); rec != nil {
+			reader.addIssue(0, root, fmt.Errorf("structure tree could not be read completely: %v", rec))
+			issues = reader.issues
+		}
+	}()
+
+	top := StructureElement{Children: reader.tree.Elements}
+	defer func() { reader.tree.Elements = top.Children }()
+	forEachKid(root.Key("K"), func(kid pdf.Value) {
+		reader.walkKid(kid, 0, 0, &top, nil)
+	})
+	return reader.tree, reader.issues
+}
+
+// forEachKid calls fn for each kid of a /K entry, which is a single kid or an array of them
+func forEachKid(kids pdf.Value, fn func(kid pdf.Value)) {
+	if kids.Kind() != pdf.Array {
+		if !kids.IsNull() {
+			fn(kids)
+		}
+		return
+	}
+	for i := 0; i < kids.Len(); i++ {
+		fn(kids.Index(i))
+	}
+}
+
+// walkKid reads one kid of a structure element: marked content, a marked content reference,
+// or a structure element. Block elements are added to the parent's children; the text of
+// marked content and of inline elements is added to text. NonStruct elements only group
+// their kids, which are added to the parent as if they were its own.
+func (s *structureReader) walkKid(kid pdf.Value, pageNum, depth int, parent *StructureElement, text *strings.Builder) {
+	switch {
+	case kid.Kind() == pdf.Integer:
+		s.appendMarkedContent(text, pageNum, int(kid.Int64()))
+	case kid.Key("Type").Name() == "MCR":
+		if pg := kid.Key("Pg"); !pg.IsNull() {
+			pageNum = s.pages.Number(pg)
+		}
+		if parent.Page == 0 {
+			parent.Page = pageNum
+		}
+		s.appendMarkedContent(text, pageNum, int(kid.Key("MCID").Int64()))
+	case kid.Key("Type").Name() == "OBJR":
+		// Annotations and XObjects belong to the element but carry no marked content
+	case kid.Kind() == pdf.Dict:
+		element, ok := s.walkElement(kid, pageNum, depth+1)
+		if !ok {
+			return
+		}
+		if (inlineStructureTypes[element.Role] || element.Role == "NonStruct") && text != nil {
+			first := text.Len() == 0 && len(parent.Children) == 0
+			appendText(text, element.text())
+			parent.Children = append(parent.Children, element.Children...)
+			if parent.Page == 0 && first {
+				parent.Page = element.Page
+			}
+			return
+		}
+		parent.Children = append(parent.Children, element)
+	}
+}
+
+// walkElement reads a structure element and its kids. Elements without text, alternate
+// descriptions, or children on the pages being read are left out, except figures.
+func (s *structureReader) walkElement(node pdf.Value, pageNum, depth int) (element StructureElement, ok bool) {
+	ref := RefOf(node)
+	switch {
+	case depth > maxStructureDepth:
+		s.addIssue(0, node, fmt.Errorf("structure tree nested more than %d levels deep", maxStructureDepth))
+		return element, false
+	case s.visited[ref]:
+		s.addIssue(0, node, fmt.Errorf("structure element %s appears more than once", ref))
+		return element, false
+	case s.tree.ElementCount >= maxStructureElements:
+		s.tree.Truncated = true
+		return element, false
+	}
+	if ref.ID != 0 {
+		s.visited[ref] = true
+	}
+	s.tree.ElementCount++
+
+	if pg := node.Key("Pg"); !pg.IsNull() {
+		pageNum = s.pages.Number(pg)
+	}
+	typ := node.Key("S").Name()
+	element = StructureElement{
+		Role:       s.role(typ),
+		Page:       pageNum,
+		AltText:    node.Key("Alt").Text(),
+		ActualText: node.Key("ActualText").Text(),
+		Title:      node.Key("T").Text(),
+		Lang:       node.Key("Lang").Text(),
+	}
+	if element.Role != typ {
+		element.Type = typ
+	}
+
+	var text strings.Builder
+	forEachKid(node.Key("K"), func(kid pdf.Value) {
+		s.walkKid(kid, pageNum, depth, &element, &text)
+	})
+	element.Text = text.String()
+
+	if element.Page == 0 && len(element.Children) > 0 {
+		element.Page = element.Children[0].Page
+	}
+	// Text is only read from the pages being read. Figures on them are kept even without a
+	// description, so a missing one can be found.
+	described := element.AltText != "" || element.ActualText != "" || element.Role == "Figure"
+	return element, element.Text != "" || (s.read[element.Page] && described) || len(element.Children) > 0
+}
+
+// text returns the text an element stands for: its replacement text when it has one
+func (e StructureElement) text() string {
+	if e.ActualText != "" {
+		return e.ActualText
+	}
+	return e.Text
+}
+
+// role maps a structure type onto a standard type through the role map. Types that do not
+// map onto a standard type are returned unchanged.
+func (s *structureReader) role(typ string) string {
+	role := typ
+	for range maxRoleMapSteps {
+		if standardStructureTypes[role] {
+			return role
+		}
+		mapped := s.roleMap.Key(role).Name()
+		if mapped == "" || mapped == role {
+			break
+		}
+		role = mapped
+	}
+	return typ
+}
+
+// appendMarkedContent adds the text of a marked content sequence, reading the page's marked
+// content when it is first needed
+func (s *structureReader) appendMarkedContent(text *strings.Builder, pageNum, mcid int) {
+	if text == nil || !s.read[pageNum] {
+		return
+	}
+	content, ok := s.text[pageNum]
+	if !ok {
+		var err error
+		content, err = markedContentText(s.pages.Page(pageNum))
+		if err != nil {
+			s.addIssue(pageNum, s.pages.Page(pageNum).V, fmt.Errorf("marked content unreadable: %w", err))
+		}
+		s.text[pageNum] = content
+	}
+	appendText(text, content[mcid])
+}
+
+// addIssue records a structure tree problem
+func (s *structureReader) addIssue(pageNum int, object pdf.Value, err error) {
+	s.issues = append(s.issues, newParseIssue(SeverityWarning, StageStructure, pageNum, object, err))
+}
+
+// appendText adds text, separated by a space from the text before it
+func appendText(b *strings.Builder, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(text)
+}
+
+// markedSequence is an open marked content sequence
+type markedSequence struct {
+	mcid     int  // -1 for sequences without an MCID
+	replaced bool // Drawn text is replaced by /ActualText
+}
+
+// markedContentText returns the text drawn in each marked content sequence of a page, by
+// MCID. Sequences with /ActualText stand for that text instead of the text drawn in them.
+func markedContentText(page pdf.Page) (texts map[int]string, err error) {
+	texts = make(map[int]string)
+
+	// The parser panics on malformed fonts
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+
+	data, err := readContents(page.V.Key("Contents"))
+	if err != nil {
+		return texts, err
+	}
+	ops, err := parseContent(data)
+	if err != nil {
+		return texts, err
+	}
+
+	var marks []markedSequence
+	builders := make(map[int]*strings.Builder)
+	builder := func(mcid int) *strings.Builder {
+		if builders[mcid] == nil {
+			builders[mcid] = &strings.Builder{}
+		}
+		return builders[mcid]
+	}
+	// current returns the builder of the innermost sequence with an MCID, or nil when drawn
+	// text is replaced or not marked
+	current := func() *strings.Builder {
+		for i := len(marks) - 1; i >= 0; i-- {
+			if marks[i].replaced {
+				return nil
+			}
+			if marks[i].mcid >= 0 {
+				return builder(marks[i].mcid)
+			}
+		}
+		return nil
+	}
+	space := func(b *strings.Builder) {
+		if b != nil && b.Len() > 0 && !strings.HasSuffix(b.String(), " ") {
+			b.WriteByte(' ')
+		}
+	}
+
+	resources := page.Resources()
+	encoders := make(map[string]pdf.TextEncoding)
+	var encoder pdf.TextEncoding
+	newLine := false
+	show := func(raw string) {
+		b := current()
+		if b == nil || encoder == nil {
+			return
+		}
+		if newLine {
+			space(b)
+		}
+		newLine = false
+		b.WriteString(encoder.Decode(raw))
+	}
+
+	for _, op := range ops {
+		args := op.operands
+		switch op.operator {
+		case "BMC":
+			marks = append(marks, markedSequence{mcid: -1})
+		case "BDC":
+			mark := markedSequence{mcid: -1}
+			actual, hasActual := "", false
+			if len(args) == 2 && args[1].kind == contentName {
+				props := resources.Key("Properties").Key(args[1].text)
+				if mcid := props.Key("MCID"); mcid.Kind() == pdf.Integer {
+					mark.mcid = int(mcid.Int64())
+				}
+				if text := props.Key("ActualText"); !text.IsNull() {
+					actual, hasActual = text.Text(), true
+				}
+			} else if len(args) == 2 {
+				props := args[1].items
+				for i := 0; i+1 < len(props); i += 2 {
+					switch props[i].text {
+					case "MCID":
+						mark.mcid = int(props[i+1].num)
+					case "ActualText":
+						actual, hasActual = decodeTextString(props[i+1].text), true
+					}
+				}
+			}
+			if hasActual {
+				b := current()
+				if mark.mcid >= 0 {
+					b = builder(mark.mcid)
+				}
+				if b != nil {
+					space(b)
+					b.WriteString(actual)
+				}
+				mark.replaced = true
+			}
+			marks = append(marks, mark)
+		case "EMC":
+			if len(marks) > 0 {
+				marks = marks[:len(marks)-1]
+			}
+		case "Tf":
+			if len(args) == 2 {
+				name := args[0].text
+				if _, ok := encoders[name]; !ok {
+					encoders[name] = newFontDecoder(resources.Key("Font").Key(name))
+				}
+				encoder = encoders[name]
+			}
+		case "Td", "TD":
+			// Moves along the line, such as between kerned glyphs, are not line breaks
+			if len(args) == 2 && args[1].num != 0 {
+				newLine = true
+			}
+		case "T*", "Tm":
+			newLine = true
+		case "Tj", "'", "\"":
+			if op.operator != "Tj" {
+				newLine = true
+			}
+			if len(args) > 0 {
+				show(args[len(args)-1].text)
+			}
+		case "TJ":
+			if len(args) == 0 {
+				break
+			}
+			for _, item := range args[0].items {
+				switch {
+				case item.kind == contentString:
+					show(item.text)
+				case item.kind == contentNumber && item.num < -wordGapThousandths:
+					space(current())
+				}
+			}
+		}
+	}
+
+	for mcid, b := range builders {
+		texts[mcid] = strings.Join(strings.Fields(b.String()), " ")
+	}
+	return texts, nil
+}
+
+// ContentMarking counts what a page's content stream draws, text and XObjects, by how it is
+// marked: as tagged content the structure tree can refer to, as an artifact such as a
+// running header, or not at all
+type ContentMarking struct {
+	Tagged    int `json:"tagged"`
+	Artifacts int `json:"artifacts"`
+	Untagged  int `json:"untagged"`
+}
+
+// PageContentMarking counts the text and XObjects a page draws by how they are marked.
+// Content drawn inside form XObjects is counted with the XObject.
+func PageContentMarking(page pdf.Page) (marking ContentMarking, err error) {
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+
+	data, err := readContents(page.V.Key("Contents"))
+	if err != nil {
+		return marking, err
+	}
+	ops, err := parseContent(data)
+	if err != nil {
+		return marking, err
+	}
+
+	type mark struct{ tagged, artifact bool }
+	var marks []mark
+	properties := page.Resources().Key("Properties")
+	count := func() {
+		for i := len(marks) - 1; i >= 0; i-- {
+			switch {
+			case marks[i].artifact:
+				marking.Artifacts++
+				return
+			case marks[i].tagged:
+				marking.Tagged++
+				return
+			}
+		}
+		marking.Untagged++
+	}
+
+	for _, op := range ops {
+		args := op.operands
+		switch op.operator {
+		case "BMC", "BDC":
+			m := mark{artifact: len(args) > 0 && args[0].text == "Artifact"}
+			if op.operator == "BDC" && len(args) == 2 {
+				if args[1].kind == contentName {
+					m.tagged = properties.Key(args[1].text).Key("MCID").Kind() == pdf.Integer
+				}
+				for i := 0; i+1 < len(args[1].items); i += 2 {
+					m.tagged = m.tagged || args[1].items[i].text == "MCID"
+				}
+			}
+			marks = append(marks, m)
+		case "EMC":
+			if len(marks) > 0 {
+				marks = marks[:len(marks)-1]
+			}
+		case "Tj", "TJ", "'", "\"", "Do":
+			count()
+		}
+	}
+	return marking, nil
+}
+
+// decodeTextString decodes a PDF text string: UTF-16 with a byte order mark, or
+// PDFDocEncoding, whose printable characters match Latin-1
+func decodeTextString(raw string) string {
+	if len(raw) >= 2 && raw[0] == 0xfe && raw[1] == 0xff {
+		units := make([]uint16, 0, (len(raw)-2)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	runes := make([]rune, len(raw))
+	for i := 0; i < len(raw); i++ {
+		runes[i] = rune(raw[i])
+	}
+	return string(runes)
+}
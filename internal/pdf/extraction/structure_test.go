@@ -0,0 +1,138 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+)
+
+// writeTaggedPDF writes a two page tagged document: a heading and a paragraph with an
+// emphasized span on the first page, and a figure and a paragraph continued from the first
+// page on the second. The heading uses a custom type mapped onto H1 and is kerned, and the
+// figure is grouped in a NonStruct element and its marked content carries replacement text.
+func writeTaggedPDF(t *testing.T) string {
+	t.Helper()
+
+	page1 := "/H1 << /MCID 0 >> BDC BT /F1 18 Tf 72 720 Td (Annual Re) Tj 95 0 Td (port) Tj ET EMC\n" +
+		"/P << /MCID 1 >> BDC BT /F1 12 Tf 72 690 Td [(Revenue)-300(grew)] TJ ET EMC\n" +
+		"/Span << /MCID 2 >> BDC BT /F1 12 Tf 150 690 Td (sharply) Tj ET EMC\n" +
+		"/Artifact BMC BT /F1 8 Tf 72 40 Td (Page 1) Tj ET EMC"
+	page2 := "/P << /MCID 0 >> BDC BT /F1 12 Tf 72 720 Td (this year.) Tj ET EMC\n" +
+		"/Figure << /MCID 1 /ActualText (Bar chart) >> BDC 0 0 m 100 100 l S EMC"
+	stream := func(content string) string {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content)
+	}
+	page := func(contents int) string {
+		return fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", contents)
+	}
+
+	return writeRawPDF(t, "tagged.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R /StructTreeRoot 8 0 R /MarkInfo << /Marked true >> >>",
+		"<< /Type /Pages /Kids [4 0 R 5 0 R] /Count 2 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		page(6),
+		page(7),
+		stream(page1),
+		stream(page2),
+		"<< /Type /StructTreeRoot /K 9 0 R /RoleMap << /Title /H1 >> >>",
+		"<< /Type /StructElem /S /Document /P 8 0 R /K [10 0 R 11 0 R 14 0 R] >>",
+		"<< /Type /StructElem /S /Title /P 9 0 R /Pg 4 0 R /K 0 >>",
+		"<< /Type /StructElem /S /P /P 9 0 R /Pg 4 0 R /K [1 12 0 R << /Type /MCR /Pg 5 0 R /MCID 0 >>] >>",
+		"<< /Type /StructElem /S /Span /P 11 0 R /Pg 4 0 R /K 2 >>",
+		"<< /Type /StructElem /S /Figure /P 14 0 R /Pg 5 0 R /Alt (Revenue by quarter) /K 1 >>",
+		"<< /Type /StructElem /S /NonStruct /P 9 0 R /K 13 0 R >>",
+	})
+}
+
+func TestReadStructureTree(t *testing.T) {
+	doc, err := core.Open(writeTaggedPDF(t), core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+	numbering := NewPageNumbering(doc.Reader)
+
+	tree, issues := ReadStructureTree(doc.Reader, numbering, []int{1, 2})
+	if tree == nil || len(issues) > 0 {
+		t.Fatalf("ReadStructureTree() = %v with issues %v, want a tree", tree, issues)
+	}
+	want := []StructureElement{{
+		Role: "Document",
+		Page: 1,
+		Children: []StructureElement{
+			{Role: "H1", Type: "Title", Page: 1, Text: "Annual Report"},
+			{Role: "P", Page: 1, Text: "Revenue grew sharply this year."},
+			{Role: "Figure", Page: 2, Text: "Bar chart", AltText: "Revenue by quarter"},
+		},
+	}}
+	if !reflect.DeepEqual(tree.Elements, want) {
+		t.Errorf("ReadStructureTree() elements = %+v, want %+v", tree.Elements, want)
+	}
+	if tree.ElementCount != 6 {
+		t.Errorf("ReadStructureTree() element count = %d, want 6", tree.ElementCount)
+	}
+
+	// Only elements on the pages being read are kept
+	tree, _ = ReadStructureTree(doc.Reader, numbering, []int{2})
+	children := tree.Elements[0].Children
+	if len(children) != 2 || children[0].Text != "this year." || children[1].Role != "Figure" {
+		t.Errorf("ReadStructureTree() for page 2 = %+v, want the paragraph's end and the figure", children)
+	}
+}
+
+func TestMarkedContentText(t *testing.T) {
+	doc, err := core.Open(writeTaggedPDF(t), core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	texts, err := markedContentText(NewPageNumbering(doc.Reader).Page(2))
+	if err != nil {
+		t.Fatalf("markedContentText() unexpected error = %v", err)
+	}
+	if want := map[int]string{0: "this year.", 1: "Bar chart"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("markedContentText() = %v, want %v", texts, want)
+	}
+}
+
+func TestPageContentMarking(t *testing.T) {
+	doc, err := core.Open(writeTaggedPDF(t), core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	marking, err := PageContentMarking(NewPageNumbering(doc.Reader).Page(1))
+	if err != nil {
+		t.Fatalf("PageContentMarking() unexpected error = %v", err)
+	}
+	if want := (ContentMarking{Tagged: 4, Artifacts: 1}); marking != want {
+		t.Errorf("PageContentMarking() = %+v, want %+v", marking, want)
+	}
+}
+
+func TestExtract_SemanticPrefersStructureTree(t *testing.T) {
+	engine := NewEngine()
+	config := ExtractionConfig{Mode: ModeSemantic, ExtractText: true}
+
+	result, err := engine.Extract(context.Background(), ExtractionRequest{FilePath: writeTaggedPDF(t), Config: config})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	if result.Structure == nil || result.Structure.ElementCount != 6 {
+		t.Errorf("Extract() structure = %+v, want the document's structure tree", result.Structure)
+	}
+
+	result, err = engine.Extract(context.Background(), ExtractionRequest{FilePath: writePagesPDF(t, 2), Config: config})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	if result.Structure != nil {
+		t.Errorf("Extract() structure = %+v for an untagged document, want none", result.Structure)
+	}
+}
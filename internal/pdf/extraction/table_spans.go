@@ -0,0 +1,268 @@
+package extraction
+
+import (
+	"math"
+	"strings"
+)
+
+// Constants reconciling rows of borderless tables with their columns
+const (
+	// phraseGapRatio is the largest gap between words of one phrase, relative to their height
+	phraseGapRatio = 0.6
+	// maxSpanningHeaderRows is how many rows grouping columns under spanning headings may
+	// sit above the rows that line up with every column
+	maxSpanningHeaderRows = 2
+	// headerPitchRatio is how much farther than the rows below a spanning heading row may be
+	headerPitchRatio = 1.5
+)
+
+// columnRange is the horizontal extent of a column of a borderless table
+type columnRange struct {
+	left, right float64
+}
+
+// markTableHeaders marks the header rows of a table and names each column after the
+// header cells over it, top down. The first row is a header; so are the rows its merged
+// cells reach down into and, when it groups columns under a spanning cell, the row below
+// naming them. At least one row is left as data.
+func markTableHeaders(table *TableElement) {
+	if len(table.Rows) == 0 {
+		return
+	}
+	headerRows := 1
+	for _, cell := range table.Rows[0].Cells {
+		headerRows = max(headerRows, cell.RowSpan)
+		if cell.ColSpan > 1 {
+			headerRows = max(headerRows, 2)
+		}
+	}
+	headerRows = max(1, min(headerRows, len(table.Rows)-1))
+
+	names := make([][]string, len(table.Columns))
+	for r := range table.Rows {
+		row := &table.Rows[r]
+		row.IsHeader = r < headerRows
+		if !row.IsHeader {
+			continue
+		}
+		for _, cell := range row.Cells {
+			if cell.Content == "" {
+				continue
+			}
+			for c := cell.ColIndex; c < cell.ColIndex+max(cell.ColSpan, 1) && c < len(names); c++ {
+				if c >= 0 {
+					names[c] = append(names[c], cell.Content)
+				}
+			}
+		}
+	}
+	for c := range table.Columns {
+		table.Columns[c].Header = strings.Join(names[c], " ")
+	}
+}
+
+// alignedColumnRanges measures the columns of a borderless table from the rows whose
+// words line up one to a column
+func alignedColumnRanges(rows [][]ContentElement, columns int) []columnRange {
+	ranges := make([]columnRange, columns)
+	for c := range ranges {
+		ranges[c] = columnRange{left: math.Inf(1), right: math.Inf(-1)}
+	}
+	for _, row := range rows {
+		if len(row) != columns {
+			continue
+		}
+		for c, element := range row {
+			ranges[c].left = math.Min(ranges[c].left, element.BoundingBox.LowerLeft.X)
+			ranges[c].right = math.Max(ranges[c].right, element.BoundingBox.UpperRight.X)
+		}
+	}
+	return ranges
+}
+
+// alignedTableRows picks the rows of a borderless table: those from the first to the last
+// row lining up with every column, and up to maxSpanningHeaderRows rows just above them
+// with fewer phrases than columns, within the table's width, which hold headings spanning
+// several columns. The indices of the first and last row are returned.
+func alignedTableRows(rows [][]ContentElement, columns int, ranges []columnRange) (first, last int) {
+	first, last = -1, -1
+	for i, row := range rows {
+		if len(row) == columns {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return first, last
+	}
+
+	rowY := func(i int) float64 { return rows[i][0].BoundingBox.LowerLeft.Y }
+	pitch := 0.0
+	if last > first {
+		pitch = (rowY(first) - rowY(last)) / float64(last-first)
+	}
+	left, right := ranges[0].left, ranges[len(ranges)-1].right
+	start := first
+	for start > 0 && first-start < maxSpanningHeaderRows {
+		above := rows[start-1]
+		if pitch <= 0 || rowY(start-1)-rowY(start) > pitch*headerPitchRatio ||
+			len(rowPhrases(above)) >= columns {
+			break
+		}
+		inside := true
+		for _, element := range above {
+			box := element.BoundingBox
+			inside = inside && box.LowerLeft.X >= left-pitch && box.UpperRight.X <= right+pitch
+		}
+		if !inside {
+			break
+		}
+		start--
+	}
+	return start, last
+}
+
+// rowPhrases joins the words of a row, sorted left to right, that lie closer together than
+// a space between columns
+func rowPhrases(row []ContentElement) [][]ContentElement {
+	var phrases [][]ContentElement
+	for i, element := range row {
+		if i > 0 {
+			prev := row[i-1].BoundingBox
+			gap := element.BoundingBox.LowerLeft.X - prev.UpperRight.X
+			height := math.Max(element.BoundingBox.Height, prev.Height)
+			if gap <= height*phraseGapRatio {
+				phrases[len(phrases)-1] = append(phrases[len(phrases)-1], element)
+				continue
+			}
+		}
+		phrases = append(phrases, []ContentElement{element})
+	}
+	return phrases
+}
+
+// alignedRowCells places the phrases of a row that does not line up with every column in
+// the columns they overlap, or those they are centered over. A phrase overlapping several
+// columns spans them, and phrases reaching into a column already taken join its cell.
+// Columns no phrase reaches have no cell.
+func alignedRowCells(row []ContentElement, ranges []columnRange, rowIdx int) []TableCell {
+	var cells []TableCell
+	for _, phrase := range rowPhrases(row) {
+		box := phrase[0].BoundingBox
+		words := make([]string, 0, len(phrase))
+		for _, element := range phrase {
+			box = unionBoxes(box, element.BoundingBox)
+			if text, ok := element.Content.(TextElement); ok {
+				words = append(words, text.Text)
+			}
+		}
+
+		first, last := -1, -1
+		for c, r := range ranges {
+			if box.LowerLeft.X < r.right && r.left < box.UpperRight.X {
+				if first < 0 {
+					first = c
+				}
+				last = c
+			}
+		}
+		if first < 0 {
+			first, last = nearestColumns(box, ranges)
+		}
+
+		if n := len(cells); n > 0 && first <= cells[n-1].ColIndex+max(cells[n-1].ColSpan, 1)-1 {
+			cell := &cells[n-1]
+			cell.Content += " " + strings.Join(words, " ")
+			cell.BoundingBox = unionBoxes(cell.BoundingBox, box)
+			last = max(last, cell.ColIndex+max(cell.ColSpan, 1)-1)
+			cell.ColSpan = spanOf(last - cell.ColIndex + 1)
+			continue
+		}
+		cells = append(cells, TableCell{
+			RowIndex:    rowIdx,
+			ColIndex:    first,
+			Content:     strings.Join(words, " "),
+			BoundingBox: box,
+			ColSpan:     spanOf(last - first + 1),
+			Confidence:  phrase[0].Confidence,
+		})
+	}
+	return cells
+}
+
+// nearestColumns finds the columns of a phrase that overlaps none. A phrase in the gap
+// between two columns, such as a short heading, spans the run of columns around the gap it
+// is best centered over; any other phrase belongs to the nearest column.
+func nearestColumns(box BoundingBox, ranges []columnRange) (first, last int) {
+	center := (box.LowerLeft.X + box.UpperRight.X) / 2
+	nearest := math.Inf(1)
+	for gap := 0; gap+1 < len(ranges); gap++ {
+		if box.LowerLeft.X < ranges[gap].right || box.UpperRight.X > ranges[gap+1].left {
+			continue
+		}
+		for c1 := gap; c1 >= 0; c1-- {
+			for c2 := gap + 1; c2 < len(ranges); c2++ {
+				if distance := math.Abs((ranges[c1].left+ranges[c2].right)/2 - center); distance < nearest {
+					first, last, nearest = c1, c2, distance
+				}
+			}
+		}
+		return first, last
+	}
+	for c, r := range ranges {
+		if distance := math.Abs((r.left+r.right)/2 - center); distance < nearest {
+			first, last, nearest = c, c, distance
+		}
+	}
+	return first, last
+}
+
+// extendHeaderRowSpans lets the cells of a borderless table's first row reach down into
+// the second when it names the columns a spanning heading groups, and nothing stands under
+// them, as a heading such as Region beside Sales over Q1 and Q2
+func extendHeaderRowSpans(table *TableElement) {
+	if len(table.Rows) < 3 {
+		return
+	}
+	grouped := false
+	for _, cell := range table.Rows[0].Cells {
+		grouped = grouped || cell.ColSpan > 1
+	}
+	if !grouped {
+		return
+	}
+	taken := make(map[int]bool)
+	for _, cell := range table.Rows[1].Cells {
+		for c := cell.ColIndex; c < cell.ColIndex+max(cell.ColSpan, 1); c++ {
+			taken[c] = true
+		}
+	}
+	bottom := math.Inf(1)
+	for _, cell := range table.Rows[1].Cells {
+		bottom = math.Min(bottom, cell.BoundingBox.LowerLeft.Y)
+	}
+	for i := range table.Rows[0].Cells {
+		cell := &table.Rows[0].Cells[i]
+		free := true
+		for c := cell.ColIndex; c < cell.ColIndex+max(cell.ColSpan, 1); c++ {
+			free = free && !taken[c]
+		}
+		if free {
+			cell.RowSpan = 2
+			cell.BoundingBox = boxFromPoints(
+				Coordinate{X: cell.BoundingBox.LowerLeft.X, Y: math.Min(bottom, cell.BoundingBox.LowerLeft.Y)},
+				cell.BoundingBox.UpperRight,
+			)
+		}
+	}
+}
+
+// spanOf returns a span as stored in a cell: zero for a single row or column
+func spanOf(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,118 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// extractPageTables writes a one-page document with the given content and detects its tables
+func extractPageTables(t *testing.T, content string) []TableElement {
+	t.Helper()
+	path := writeRawPDF(t, "table.pdf", []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> " +
+			"/Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	})
+	result, err := NewEngine().Extract(context.Background(), ExtractionRequest{
+		FilePath: path,
+		Config:   ExtractionConfig{Mode: ModeTable, TableDetectionTh: minimumConfidenceThreshold},
+	})
+	if err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	return result.Tables
+}
+
+// tableCells lists a table's cells as "row,col content" with their spans
+func tableCells(table TableElement) []string {
+	var cells []string
+	for _, row := range table.Rows {
+		for _, cell := range row.Cells {
+			text := fmt.Sprintf("%d,%d %s", cell.RowIndex, cell.ColIndex, cell.Content)
+			if cell.RowSpan > 1 || cell.ColSpan > 1 {
+				text += fmt.Sprintf(" [%dx%d]", max(cell.RowSpan, 1), max(cell.ColSpan, 1))
+			}
+			cells = append(cells, text)
+		}
+	}
+	return cells
+}
+
+func TestDetectTables_RuledMergedCells(t *testing.T) {
+	// Item spans two rows and Sales two columns: their inner boundaries are not drawn
+	var b strings.Builder
+	b.WriteString("1 w\n100 700 m 400 700 l S\n200 680 m 400 680 l S\n100 660 m 400 660 l S\n100 640 m 400 640 l S\n")
+	b.WriteString("100 640 m 100 700 l S\n200 640 m 200 700 l S\n300 640 m 300 680 l S\n400 640 m 400 700 l S\n")
+	for _, word := range []struct {
+		x, y int
+		text string
+	}{
+		{105, 676, "Item"}, {205, 686, "Sales"}, {205, 666, "Q1"}, {305, 666, "Q2"},
+		{105, 646, "Apple"}, {205, 646, "3"}, {305, 646, "5"},
+	} {
+		fmt.Fprintf(&b, "BT /F1 10 Tf %d %d Td (%s) Tj ET\n", word.x, word.y, word.text)
+	}
+
+	tables := extractPageTables(t, b.String())
+	if len(tables) != 1 {
+		t.Fatalf("detected %d tables, want 1", len(tables))
+	}
+	table := tables[0]
+	want := []string{"0,0 Item [2x1]", "0,1 Sales [1x2]", "1,1 Q1", "1,2 Q2", "2,0 Apple", "2,1 3", "2,2 5"}
+	if got := tableCells(table); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("cells = %q, want %q", got, want)
+	}
+	if !table.Rows[1].IsHeader || table.Rows[2].IsHeader {
+		t.Errorf("header rows = %v, %v, %v; want the first two", table.Rows[0].IsHeader,
+			table.Rows[1].IsHeader, table.Rows[2].IsHeader)
+	}
+	for c, header := range []string{"Item", "Sales Q1", "Sales Q2"} {
+		if table.Columns[c].Header != header {
+			t.Errorf("column %d header = %q, want %q", c, table.Columns[c].Header, header)
+		}
+	}
+	if merged := table.Rows[0].Cells[0].BoundingBox; merged.LowerLeft.Y != 660 || merged.UpperRight.Y != 700 {
+		t.Errorf("merged cell box = %+v, want it to cover both rows", merged)
+	}
+}
+
+func TestDetectTables_AlignedSpanningHeader(t *testing.T) {
+	// Sales is centered over the Q1 and Q2 columns; nothing stands under Region
+	var b strings.Builder
+	fmt.Fprintf(&b, "BT /F1 10 Tf 100 700 Td (Region) Tj ET\nBT /F1 10 Tf 243 700 Td (Sales) Tj ET\n")
+	fmt.Fprintf(&b, "BT /F1 10 Tf 200 685 Td (Q1) Tj ET\nBT /F1 10 Tf 300 685 Td (Q2) Tj ET\n")
+	for i, region := range []string{"North", "South", "East", "West", "Central", "Coast", "Hills", "Lakes"} {
+		y := 670 - i*15
+		fmt.Fprintf(&b, "BT /F1 10 Tf 100 %d Td (%s) Tj ET\n", y, region)
+		fmt.Fprintf(&b, "BT /F1 10 Tf 200 %d Td (%d) Tj ET\nBT /F1 10 Tf 300 %d Td (%d) Tj ET\n", y, 10+i, y, 20+i)
+	}
+	// Rows missing a value keep the values they have in their columns
+	b.WriteString("BT /F1 10 Tf 100 550 Td (Islands) Tj ET\nBT /F1 10 Tf 300 550 Td (7) Tj ET\n")
+	b.WriteString("BT /F1 10 Tf 100 535 Td (Total) Tj ET\nBT /F1 10 Tf 200 535 Td (100) Tj ET\n")
+	b.WriteString("BT /F1 10 Tf 300 535 Td (200) Tj ET\n")
+
+	tables := extractPageTables(t, b.String())
+	if len(tables) != 1 {
+		t.Fatalf("detected %d tables, want 1", len(tables))
+	}
+	table := tables[0]
+	cells := tableCells(table)
+	for _, want := range []string{"0,0 Region [2x1]", "0,1 Sales [1x2]", "1,1 Q1", "1,2 Q2", "10,0 Islands", "10,2 7"} {
+		if !strings.Contains(strings.Join(cells, "|"), want) {
+			t.Errorf("cells = %q, want %q", cells, want)
+		}
+	}
+	if len(table.Rows) != 12 {
+		t.Errorf("rows = %d, want 12", len(table.Rows))
+	}
+	for c, header := range []string{"Region", "Sales Q1", "Sales Q2"} {
+		if table.Columns[c].Header != header {
+			t.Errorf("column %d header = %q, want %q", c, table.Columns[c].Header, header)
+		}
+	}
+}
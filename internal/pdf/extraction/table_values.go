@@ -0,0 +1,293 @@
+package extraction
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Data types of table cells and columns
+const (
+	CellTypeText     = "text"
+	CellTypeNumber   = "number"
+	CellTypeCurrency = "currency"
+	CellTypePercent  = "percent"
+	CellTypeDate     = "date"
+)
+
+// Number patterns: with a decimal point and commas, apostrophes, or spaces grouping
+// thousands, or with a decimal comma and points or spaces grouping them
+var (
+	pointNumberPattern = regexp.MustCompile(`^(\d{1,3}([,' ’]\d{3})+|\d+)?(\.\d+)?$`)
+	commaNumberPattern = regexp.MustCompile(`^(\d{1,3}([. ]\d{3})+|\d+)?(,\d+)?$`)
+)
+
+// currencySymbols are the symbols and codes recognized before or after an amount
+var currencySymbols = []string{
+	"US$", "R$", "USD", "EUR", "GBP", "JPY", "CHF", "CAD", "AUD", "SEK", "NOK", "DKK", "PLN", "BRL", "INR",
+	"$", "€", "£", "¥", "₹", "zł", "kr",
+}
+
+// Accepted date forms: unambiguous ones, and numeric ones read with the month or the day
+// first. Dates with points are always day first.
+var (
+	cellDateLayouts = []string{
+		"2006-01-02", "2006/01/02", "Jan 2, 2006", "January 2, 2006", "Jan 2 2006", "2 Jan 2006", "2 January 2006",
+		"02-Jan-2006", "2-Jan-06",
+	}
+	monthFirstDateLayouts = []string{"1/2/2006", "1/2/06", "1-2-2006"}
+	dayFirstDateLayouts   = []string{"2/1/2006", "2/1/06", "2-1-2006", "2.1.2006", "2.1.06"}
+)
+
+// cellLocale is how a column writes numbers and dates
+type cellLocale struct {
+	decimalComma bool // 1.234,5 rather than 1,234.5
+	dayFirst     bool // 15/03/2024 rather than 03/15/2024
+}
+
+// cellEvidence is what a cell's content tells of its column's locale: the conventions
+// under which alone it can be read
+type cellEvidence struct {
+	decimalComma, decimalPoint bool
+	dayFirst, monthFirst       bool
+}
+
+// localeVotes counts the cells whose content can only be read under each convention
+type localeVotes struct {
+	decimalComma, decimalPoint int
+	dayFirst, monthFirst       int
+}
+
+// add counts a cell's evidence
+func (v *localeVotes) add(evidence cellEvidence) {
+	if evidence.decimalComma {
+		v.decimalComma++
+	}
+	if evidence.decimalPoint {
+		v.decimalPoint++
+	}
+	if evidence.dayFirst {
+		v.dayFirst++
+	}
+	if evidence.monthFirst {
+		v.monthFirst++
+	}
+}
+
+// locale picks the conventions with the most votes, falling back on those of the whole
+// table, and otherwise on a decimal point and the month first
+func (v localeVotes) locale(fallback localeVotes) cellLocale {
+	if v.decimalComma == v.decimalPoint {
+		v.decimalComma, v.decimalPoint = fallback.decimalComma, fallback.decimalPoint
+	}
+	if v.dayFirst == v.monthFirst {
+		v.dayFirst, v.monthFirst = fallback.dayFirst, fallback.monthFirst
+	}
+	return cellLocale{decimalComma: v.decimalComma > v.decimalPoint, dayFirst: v.dayFirst > v.monthFirst}
+}
+
+// InferCellValue infers the data type of a cell's content and returns it normalized:
+// numbers, currency amounts, and percentages as plain decimals, with percentages as
+// fractions, and dates as YYYY-MM-DD. Parentheses and trailing minus signs mark negative
+// amounts. Numbers with a decimal comma, such as 1.234,5, and dates with the day first
+// are recognized when they cannot be read otherwise. Empty content has no type.
+func InferCellValue(content string) (dataType, value string) {
+	dataType, value, _ = inferCell(content, cellLocale{})
+	return dataType, value
+}
+
+// inferCell infers a cell's data type and normalized value, reading numbers and dates
+// that fit several conventions by those of the locale
+func inferCell(content string, locale cellLocale) (dataType, value string, evidence cellEvidence) {
+	text := strings.Join(strings.Fields(content), " ")
+	if text == "" {
+		return "", "", evidence
+	}
+
+	if date, ok := parseCellDate(text, locale.dayFirst, &evidence); ok {
+		return CellTypeDate, date.Format("2006-01-02"), evidence
+	}
+
+	amount, negative := text, false
+	if strings.HasPrefix(amount, "(") && strings.HasSuffix(amount, ")") {
+		amount, negative = amount[1:len(amount)-1], true
+	}
+	switch {
+	case strings.HasPrefix(amount, "-") || strings.HasPrefix(amount, "−"):
+		_, size := utf8.DecodeRuneInString(amount)
+		amount, negative = amount[size:], !negative
+	case strings.HasSuffix(amount, "-"):
+		amount, negative = amount[:len(amount)-1], !negative
+	case strings.HasPrefix(amount, "+"):
+		amount = amount[1:]
+	}
+	amount = strings.TrimSpace(amount)
+
+	dataType = CellTypeNumber
+	if trimmed, ok := strings.CutSuffix(amount, "%"); ok {
+		dataType, amount = CellTypePercent, strings.TrimSpace(trimmed)
+	} else {
+		for _, symbol := range currencySymbols {
+			if trimmed, ok := strings.CutPrefix(amount, symbol); ok {
+				dataType, amount = CellTypeCurrency, strings.TrimSpace(trimmed)
+				break
+			}
+			if trimmed, ok := strings.CutSuffix(amount, symbol); ok {
+				dataType, amount = CellTypeCurrency, strings.TrimSpace(trimmed)
+				break
+			}
+		}
+		// A sign may also follow the currency symbol
+		if rest, ok := strings.CutPrefix(amount, "-"); ok && dataType == CellTypeCurrency {
+			amount, negative = rest, !negative
+		}
+	}
+
+	number, ok := parseCellNumber(amount, locale.decimalComma, &evidence)
+	if !ok {
+		return CellTypeText, text, cellEvidence{}
+	}
+	if dataType == CellTypePercent {
+		number /= 100
+	}
+	if negative {
+		number = -number
+	}
+	return dataType, strconv.FormatFloat(number, 'f', -1, 64), evidence
+}
+
+// parseCellNumber parses a number written with a decimal point or a decimal comma. A
+// number both can read, such as 1,234, is read by the locale's convention; a number only
+// one can read is evidence of it.
+func parseCellNumber(amount string, decimalComma bool, evidence *cellEvidence) (float64, bool) {
+	if amount == "" || strings.Trim(amount, ".,") == "" {
+		return 0, false
+	}
+	pointValue, pointOK := parseGroupedNumber(amount, pointNumberPattern, "', ’", ".")
+	commaValue, commaOK := parseGroupedNumber(amount, commaNumberPattern, ". ", ",")
+	switch {
+	case pointOK && commaOK:
+		if decimalComma {
+			return commaValue, true
+		}
+		return pointValue, true
+	case pointOK:
+		evidence.decimalPoint = true
+		return pointValue, true
+	case commaOK:
+		evidence.decimalComma = true
+		return commaValue, true
+	}
+	return 0, false
+}
+
+// parseGroupedNumber parses a number matching pattern, dropping the group separators and
+// reading the decimal separator as a point
+func parseGroupedNumber(amount string, pattern *regexp.Regexp, groups, decimal string) (float64, bool) {
+	if !pattern.MatchString(amount) {
+		return 0, false
+	}
+	digits := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(groups, r) {
+			return -1
+		}
+		return r
+	}, amount)
+	number, err := strconv.ParseFloat(strings.Replace(digits, decimal, ".", 1), 64)
+	return number, err == nil
+}
+
+// parseCellDate parses a date in one of the accepted layouts. A numeric date both the month
+// first and the day first layouts read, such as 03/04/2024, is read by dayFirst; a date only
+// one reads is evidence of it.
+func parseCellDate(text string, dayFirst bool, evidence *cellEvidence) (time.Time, bool) {
+	for _, layout := range cellDateLayouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			return date, true
+		}
+	}
+	monthDate, monthOK := parseDateLayouts(text, monthFirstDateLayouts)
+	dayDate, dayOK := parseDateLayouts(text, dayFirstDateLayouts)
+	switch {
+	case monthOK && dayOK && !monthDate.Equal(dayDate):
+		if dayFirst {
+			return dayDate, true
+		}
+		return monthDate, true
+	case monthOK:
+		evidence.monthFirst = !dayOK
+		return monthDate, true
+	case dayOK:
+		evidence.dayFirst = true
+		return dayDate, true
+	}
+	return time.Time{}, false
+}
+
+// parseDateLayouts parses a date in the first of the layouts that reads it
+func parseDateLayouts(text string, layouts []string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// inferTableTypes sets the data type and normalized value of every cell, and the data type
+// of each column: the type its data cells share, number for a mix of numbers, amounts, and
+// percentages, and text for any other mix. Each column's locale is decided first by the
+// cells only one convention reads, or those of the whole table, so that a column of
+// 1.234,50 amounts reads its 1.250 as one thousand two hundred fifty.
+func inferTableTypes(table *TableElement) {
+	columnVotes := make([]localeVotes, len(table.Columns))
+	var tableVotes localeVotes
+	for _, row := range table.Rows {
+		if row.IsHeader {
+			continue
+		}
+		for _, cell := range row.Cells {
+			_, _, evidence := inferCell(cell.Content, cellLocale{})
+			tableVotes.add(evidence)
+			if cell.ColIndex >= 0 && cell.ColIndex < len(columnVotes) {
+				columnVotes[cell.ColIndex].add(evidence)
+			}
+		}
+	}
+
+	columnTypes := make([]string, len(table.Columns))
+	mixed := make([]bool, len(table.Columns))
+	for r := range table.Rows {
+		row := &table.Rows[r]
+		for c := range row.Cells {
+			cell := &row.Cells[c]
+			col := cell.ColIndex
+			locale := tableVotes.locale(localeVotes{})
+			if col >= 0 && col < len(columnVotes) {
+				locale = columnVotes[col].locale(tableVotes)
+			}
+			cell.DataType, cell.Value, _ = inferCell(cell.Content, locale)
+			if row.IsHeader || cell.DataType == "" || col < 0 || col >= len(columnTypes) || mixed[col] {
+				continue
+			}
+			switch {
+			case columnTypes[col] == "" || columnTypes[col] == cell.DataType:
+				columnTypes[col] = cell.DataType
+			case isNumericType(columnTypes[col]) && isNumericType(cell.DataType):
+				columnTypes[col] = CellTypeNumber
+			default:
+				columnTypes[col], mixed[col] = CellTypeText, true
+			}
+		}
+	}
+	for c := range table.Columns {
+		table.Columns[c].DataType = columnTypes[c]
+	}
+}
+
+// isNumericType reports whether a data type holds a number
+func isNumericType(dataType string) bool {
+	return dataType == CellTypeNumber || dataType == CellTypeCurrency || dataType == CellTypePercent
+}
@@ -0,0 +1,93 @@
+package extraction
+
+import "testing"
+
+func TestInferCellValue(t *testing.T) {
+	tests := []struct {
+		content  string
+		dataType string
+		value    string
+	}{
+		{"", "", ""},
+		{"  Apple  pie ", CellTypeText, "Apple pie"},
+		{"1,234.50", CellTypeNumber, "1234.5"},
+		{"(42)", CellTypeNumber, "-42"},
+		{"17-", CellTypeNumber, "-17"},
+		{"$1,200", CellTypeCurrency, "1200"},
+		{"-€ 3.5", CellTypeCurrency, "-3.5"},
+		{"9.99 USD", CellTypeCurrency, "9.99"},
+		{"12.5%", CellTypePercent, "0.125"},
+		{"2024-03-01", CellTypeDate, "2024-03-01"},
+		{"03/15/2024", CellTypeDate, "2024-03-15"},
+		{"15/03/2024", CellTypeDate, "2024-03-15"},
+		{"Mar 5, 2024", CellTypeDate, "2024-03-05"},
+		{"1.2.3", CellTypeText, "1.2.3"},
+		{"12,34", CellTypeNumber, "12.34"},
+		{"1.234,56", CellTypeNumber, "1234.56"},
+		{"1 234,5 €", CellTypeCurrency, "1234.5"},
+		{"1'234.50 CHF", CellTypeCurrency, "1234.5"},
+		{"1,234", CellTypeNumber, "1234"},
+		{"1.250", CellTypeNumber, "1.25"},
+		{"15.03.2024", CellTypeDate, "2024-03-15"},
+		{"12,34,5", CellTypeText, "12,34,5"},
+	}
+	for _, tt := range tests {
+		dataType, value := InferCellValue(tt.content)
+		if dataType != tt.dataType || value != tt.value {
+			t.Errorf("InferCellValue(%q) = %q, %q, want %q, %q", tt.content, dataType, value, tt.dataType, tt.value)
+		}
+	}
+}
+
+func TestInferTableTypes(t *testing.T) {
+	table := TableElement{Columns: make([]TableCol, 3)}
+	for r, row := range [][]string{{"Item", "Amount", "Due"}, {"Apple", "$3", "2024-01-31"}, {"Pear", "5%", "soon"}} {
+		tableRow := TableRow{Index: r, IsHeader: r == 0}
+		for c, content := range row {
+			tableRow.Cells = append(tableRow.Cells, TableCell{RowIndex: r, ColIndex: c, Content: content})
+		}
+		table.Rows = append(table.Rows, tableRow)
+	}
+	inferTableTypes(&table)
+
+	for c, want := range []string{CellTypeText, CellTypeNumber, CellTypeText} {
+		if got := table.Columns[c].DataType; got != want {
+			t.Errorf("column %d data type = %q, want %q", c, got, want)
+		}
+	}
+	if cell := table.Rows[1].Cells[1]; cell.DataType != CellTypeCurrency || cell.Value != "3" {
+		t.Errorf("amount cell = %+v, want currency 3", cell)
+	}
+}
+
+func TestInferTableTypes_Locale(t *testing.T) {
+	table := TableElement{Columns: make([]TableCol, 3)}
+	rows := [][]string{
+		{"Datum", "Betrag", "Menge"},
+		{"03/04/2024", "1.234,50 €", "1.250"},
+		{"25/12/2024", "1.250 €", "12,5"},
+	}
+	for r, row := range rows {
+		tableRow := TableRow{Index: r, IsHeader: r == 0}
+		for c, content := range row {
+			tableRow.Cells = append(tableRow.Cells, TableCell{RowIndex: r, ColIndex: c, Content: content})
+		}
+		table.Rows = append(table.Rows, tableRow)
+	}
+	inferTableTypes(&table)
+
+	// Cells only one convention reads decide how the column reads the others
+	want := [][]string{{"2024-04-03", "1234.5", "1250"}, {"2024-12-25", "1250", "12.5"}}
+	for r, values := range want {
+		for c, value := range values {
+			if got := table.Rows[r+1].Cells[c].Value; got != value {
+				t.Errorf("cell %d,%d value = %q, want %q", r+1, c, got, value)
+			}
+		}
+	}
+	for c, want := range []string{CellTypeDate, CellTypeCurrency, CellTypeNumber} {
+		if got := table.Columns[c].DataType; got != want {
+			t.Errorf("column %d data type = %q, want %q", c, got, want)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package extraction
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Table detection strategies reported in TableElement.Strategy
+const (
+	TableStrategyTextAlignment = "text_alignment"
+	TableStrategyRulingLines   = "ruling_lines"
+	TableStrategyMerged        = "ruling_lines+text_alignment"
+)
+
+// Ruling line detection constants
+const (
+	// rulingThickness is the maximum thickness of a rectangle treated as a line
+	rulingThickness = 3.0
+	// rulingMinLength is the minimum length of a segment considered a ruling line
+	rulingMinLength = 10.0
+	// rulingSnapTolerance merges ruling positions closer than this distance
+	rulingSnapTolerance = 2.0
+	// rulingConfidenceBoost is added when both strategies agree on a table
+	rulingConfidenceBoost = 0.1
+	// minColumnsForTable is the minimum number of columns in a ruled table
+	minColumnsForTable = 2
+)
+
+// rulingSegment is an axis-aligned line segment; for horizontal segments pos is Y and
+// [start, end] spans X, for vertical segments pos is X and [start, end] spans Y.
+type rulingSegment struct {
+	pos   float64
+	start float64
+	end   float64
+}
+
+// rulingSet holds the horizontal and vertical ruling lines of a page
+type rulingSet struct {
+	horizontal []rulingSegment
+	vertical   []rulingSegment
+}
+
+// collectRulings converts vector elements into horizontal and vertical ruling segments
+func collectRulings(vectors []VectorElement) rulingSet {
+	var set rulingSet
+
+	addBox := func(box BoundingBox, stroked bool) {
+		switch {
+		case box.Height <= rulingThickness && box.Width >= rulingMinLength:
+			set.horizontal = append(set.horizontal, rulingSegment{
+				pos: (box.LowerLeft.Y + box.UpperRight.Y) / 2, start: box.LowerLeft.X, end: box.UpperRight.X,
+			})
+		case box.Width <= rulingThickness && box.Height >= rulingMinLength:
+			set.vertical = append(set.vertical, rulingSegment{
+				pos: (box.LowerLeft.X + box.UpperRight.X) / 2, start: box.LowerLeft.Y, end: box.UpperRight.Y,
+			})
+		case stroked && box.Width >= rulingMinLength && box.Height >= rulingMinLength:
+			// Stroked cell borders contribute all four edges; filled areas are backgrounds
+			ll, ur := box.LowerLeft, box.UpperRight
+			set.horizontal = append(set.horizontal,
+				rulingSegment{pos: ll.Y, start: ll.X, end: ur.X},
+				rulingSegment{pos: ur.Y, start: ll.X, end: ur.X})
+			set.vertical = append(set.vertical,
+				rulingSegment{pos: ll.X, start: ll.Y, end: ur.Y},
+				rulingSegment{pos: ur.X, start: ll.Y, end: ur.Y})
+		}
+	}
+
+	for _, vector := range vectors {
+		switch vector.Type {
+		case VectorTypeRect:
+			addBox(vectorBounds(vector), vector.StrokeColor != "")
+		case VectorTypeLine, VectorTypePath:
+			for _, cmd := range vector.Commands {
+				if (cmd.Command == "lineto" || cmd.Command == "closepath") && len(cmd.Points) == 2 {
+					addBox(boxFromPoints(cmd.Points[0], cmd.Points[1]), vector.StrokeColor != "")
+				}
+			}
+		}
+	}
+
+	return set
+}
+
+// detectRuledTables finds tables whose cells are delimited by drawn ruling lines
+func (e *DefaultEngine) detectRuledTables(page pdf.Page, pageNum int) ([]TableElement, error) {
+	vectors, err := interpretPageGraphics(page)
+	if err != nil {
+		return nil, err
+	}
+
+	rulings := collectRulings(vectors)
+	if len(rulings.horizontal) < minRowsForTable+1 || len(rulings.vertical) < minColumnsForTable+1 {
+		return nil, nil
+	}
+
+	glyphs, err := pageGlyphs(page)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []TableElement
+	for _, group := range groupConnectedRulings(rulings) {
+		if table := e.buildRuledTable(group, glyphs, pageNum); table != nil {
+			tables = append(tables, *table)
+		}
+	}
+
+	return tables, nil
+}
+
+// HasRuledTable reports whether a page draws enough horizontal and vertical ruling lines to
+// hold a ruled table. Only the page's graphics are read, which makes it a cheap check for
+// choosing pages worth running full table detection on.
+func HasRuledTable(page pdf.Page) bool {
+	vectors, err := interpretPageGraphics(page)
+	if err != nil {
+		return false
+	}
+	rulings := collectRulings(vectors)
+	return len(rulings.horizontal) >= minRowsForTable+1 && len(rulings.vertical) >= minColumnsForTable+1
+}
+
+// groupConnectedRulings splits rulings into clusters of mutually intersecting segments,
+// each cluster being a candidate table
+func groupConnectedRulings(set rulingSet) []rulingSet {
+	total := len(set.horizontal) + len(set.vertical)
+	parent := make([]int, total)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	offset := len(set.horizontal)
+	for i, h := range set.horizontal {
+		for j, v := range set.vertical {
+			if segmentsIntersect(h, v) {
+				parent[find(i)] = find(offset + j)
+			}
+		}
+	}
+
+	groups := make(map[int]*rulingSet)
+	var order []int
+	for i := 0; i < total; i++ {
+		root := find(i)
+		group, ok := groups[root]
+		if !ok {
+			group = &rulingSet{}
+			groups[root] = group
+			order = append(order, root)
+		}
+		if i < offset {
+			group.horizontal = append(group.horizontal, set.horizontal[i])
+		} else {
+			group.vertical = append(group.vertical, set.vertical[i-offset])
+		}
+	}
+
+	result := make([]rulingSet, 0, len(order))
+	for _, root := range order {
+		result = append(result, *groups[root])
+	}
+	return result
+}
+
+// segmentsIntersect reports whether a horizontal and a vertical segment touch
+func segmentsIntersect(h, v rulingSegment) bool {
+	return v.pos >= h.start-rulingSnapTolerance && v.pos <= h.end+rulingSnapTolerance &&
+		h.pos >= v.start-rulingSnapTolerance && h.pos <= v.end+rulingSnapTolerance
+}
+
+// snapPositions clusters nearby ruling positions and returns them sorted ascending
+func snapPositions(segments []rulingSegment) []float64 {
+	positions := make([]float64, 0, len(segments))
+	for _, s := range segments {
+		positions = append(positions, s.pos)
+	}
+	sort.Float64s(positions)
+
+	var snapped []float64
+	for _, p := range positions {
+		if len(snapped) > 0 && p-snapped[len(snapped)-1] <= rulingSnapTolerance {
+			continue
+		}
+		snapped = append(snapped, p)
+	}
+	return snapped
+}
+
+// buildRuledTable constructs a table from a cluster of ruling lines and the page glyphs
+func (e *DefaultEngine) buildRuledTable(group rulingSet, glyphs []pdf.Text, pageNum int) *TableElement {
+	ys := snapPositions(group.horizontal)
+	xs := snapPositions(group.vertical)
+	if len(ys) < minRowsForTable+1 || len(xs) < minColumnsForTable+1 {
+		return nil
+	}
+
+	numRows := len(ys) - 1
+	numCols := len(xs) - 1
+
+	// Assign each glyph to the cell containing its center; rows run top to bottom
+	cellGlyphs := make([][][]pdf.Text, numRows)
+	for r := range cellGlyphs {
+		cellGlyphs[r] = make([][]pdf.Text, numCols)
+	}
+	for _, g := range glyphs {
+		center := boxCenter(glyphBox(g))
+		row := locateInterval(ys, center.Y)
+		col := locateInterval(xs, center.X)
+		if row < 0 || col < 0 {
+			continue
+		}
+		cellGlyphs[numRows-1-row][col] = append(cellGlyphs[numRows-1-row][col], g)
+	}
+
+	table := &TableElement{
+		Rows:        make([]TableRow, 0, numRows),
+		Columns:     make([]TableCol, numCols),
+		PageNumber:  pageNum,
+		BoundingBox: boxFromPoints(Coordinate{X: xs[0], Y: ys[0]}, Coordinate{X: xs[numCols], Y: ys[numRows]}),
+		Strategy:    TableStrategyRulingLines,
+	}
+
+	for c := 0; c < numCols; c++ {
+		table.Columns[c] = TableCol{
+			Index: c,
+			BoundingBox: boxFromPoints(
+				Coordinate{X: xs[c], Y: ys[0]}, Coordinate{X: xs[c+1], Y: ys[numRows]},
+			),
+		}
+	}
+
+	// Grid positions merge across the boundaries between them that are not drawn
+	covered := make([][]bool, numRows)
+	for r := range covered {
+		covered[r] = make([]bool, numCols)
+	}
+	filledCells := 0
+	for r := 0; r < numRows; r++ {
+		top := ys[numRows-r]
+		bottom := ys[numRows-r-1]
+		row := TableRow{
+			Index:       r,
+			Cells:       make([]TableCell, 0, numCols),
+			BoundingBox: boxFromPoints(Coordinate{X: xs[0], Y: bottom}, Coordinate{X: xs[numCols], Y: top}),
+			IsHeader:    r == 0,
+		}
+		for c := 0; c < numCols; c++ {
+			if covered[r][c] {
+				continue
+			}
+			rowSpan, colSpan := ruledCellSpan(group, xs, ys, covered, r, c)
+			var glyphs []pdf.Text
+			for rr := r; rr < r+rowSpan; rr++ {
+				for cc := c; cc < c+colSpan; cc++ {
+					covered[rr][cc] = true
+					glyphs = append(glyphs, cellGlyphs[rr][cc]...)
+				}
+			}
+			content := strings.TrimSpace(joinGlyphs(glyphs))
+			if content != "" {
+				filledCells++
+			}
+			cell := TableCell{
+				RowIndex: r,
+				ColIndex: c,
+				Content:  content,
+				BoundingBox: boxFromPoints(
+					Coordinate{X: xs[c], Y: ys[numRows-r-rowSpan]}, Coordinate{X: xs[c+colSpan], Y: top},
+				),
+				Confidence: defaultConfidenceThreshold,
+			}
+			if rowSpan > 1 {
+				cell.RowSpan = rowSpan
+			}
+			if colSpan > 1 {
+				cell.ColSpan = colSpan
+			}
+			row.Cells = append(row.Cells, cell)
+			table.CellCount++
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	if filledCells == 0 {
+		return nil
+	}
+
+	table.HasHeaders = numRows > 1
+	table.Confidence = rulingCoverage(group, xs, ys)
+	markTableHeaders(table)
+	return table
+}
+
+// ruledCellSpan measures the merged cell whose top-left grid position is row r, column c:
+// it extends right while the vertical boundary through its first row is not drawn, and
+// down while the horizontal boundary under all its columns is not drawn. Rows run top to
+// bottom, so row r lies between ys[len(ys)-1-r] and the position below it.
+func ruledCellSpan(group rulingSet, xs, ys []float64, covered [][]bool, r, c int) (rowSpan, colSpan int) {
+	numRows, numCols := len(ys)-1, len(xs)-1
+	middle := (ys[numRows-r] + ys[numRows-r-1]) / 2
+	colSpan = 1
+	for c+colSpan < numCols && !covered[r][c+colSpan] && !segmentCovers(group.vertical, xs[c+colSpan], middle) {
+		colSpan++
+	}
+
+	rowSpan = 1
+	for r+rowSpan < numRows {
+		boundary := ys[numRows-r-rowSpan]
+		for cc := c; cc < c+colSpan; cc++ {
+			if covered[r+rowSpan][cc] || segmentCovers(group.horizontal, boundary, (xs[cc]+xs[cc+1])/2) {
+				return rowSpan, colSpan
+			}
+		}
+		rowSpan++
+	}
+	return rowSpan, colSpan
+}
+
+// locateInterval returns the index i such that bounds[i] <= v < bounds[i+1], or -1
+func locateInterval(bounds []float64, v float64) int {
+	for i := 0; i+1 < len(bounds); i++ {
+		if v >= bounds[i] && v < bounds[i+1] {
+			return i
+		}
+	}
+	return -1
+}
+
+// rulingCoverage estimates confidence as the fraction of grid edges backed by a drawn ruling
+func rulingCoverage(group rulingSet, xs, ys []float64) float64 {
+	covered, total := 0, 0
+	for _, y := range ys {
+		for c := 0; c+1 < len(xs); c++ {
+			total++
+			if segmentCovers(group.horizontal, y, (xs[c]+xs[c+1])/2) {
+				covered++
+			}
+		}
+	}
+	for _, x := range xs {
+		for r := 0; r+1 < len(ys); r++ {
+			total++
+			if segmentCovers(group.vertical, x, (ys[r]+ys[r+1])/2) {
+				covered++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return minimumConfidenceThreshold + (1-minimumConfidenceThreshold)*float64(covered)/float64(total)
+}
+
+// segmentCovers reports whether any segment at pos spans the given point
+func segmentCovers(segments []rulingSegment, pos, at float64) bool {
+	for _, s := range segments {
+		if math.Abs(s.pos-pos) <= rulingSnapTolerance && at >= s.start && at <= s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// joinGlyphs concatenates glyphs in reading order, inserting spaces at word gaps
+func joinGlyphs(glyphs []pdf.Text) string {
+	words := groupGlyphsIntoWords(glyphs)
+	parts := make([]string, len(words))
+	for i, w := range words {
+		parts[i] = w.Text
+	}
+	return strings.Join(parts, " ")
+}
+
+// mergeTableDetections combines ruling-line and text-alignment detections. Alignment tables
+// overlapping a ruled table on the same page are folded into it and raise its confidence.
+func mergeTableDetections(ruled, aligned []TableElement) []TableElement {
+	merged := make([]TableElement, 0, len(ruled)+len(aligned))
+	merged = append(merged, ruled...)
+
+	for _, candidate := range aligned {
+		absorbed := false
+		for i := range merged {
+			if merged[i].Strategy == TableStrategyTextAlignment || merged[i].PageNumber != candidate.PageNumber {
+				continue
+			}
+			if tableBoxesOverlap(merged[i].BoundingBox, candidate.BoundingBox) {
+				merged[i].Strategy = TableStrategyMerged
+				merged[i].Confidence = math.Min(1.0, merged[i].Confidence+rulingConfidenceBoost)
+				absorbed = true
+				break
+			}
+		}
+		if !absorbed {
+			merged = append(merged, candidate)
+		}
+	}
+
+	return merged
+}
+
+// tableBoxesOverlap reports whether two table regions intersect; empty boxes never overlap
+func tableBoxesOverlap(a, b BoundingBox) bool {
+	if a.Width == 0 && a.Height == 0 || b.Width == 0 && b.Height == 0 {
+		return false
+	}
+	return a.LowerLeft.X < b.UpperRight.X && b.LowerLeft.X < a.UpperRight.X &&
+		a.LowerLeft.Y < b.UpperRight.Y && b.LowerLeft.Y < a.UpperRight.Y
+}
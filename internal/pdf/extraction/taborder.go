@@ -0,0 +1,218 @@
+package extraction
+
+import (
+	"sort"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Tab orders of form fields on a page, from the page's /Tabs entry
+const (
+	TabOrderRow        = "row"        // R: rows from top to bottom, each from left to right
+	TabOrderColumn     = "column"     // C: columns from left to right, each from top to bottom
+	TabOrderStructure  = "structure"  // S: the order of the structure tree
+	TabOrderAnnotation = "annotation" // A, W, or no /Tabs: the order of the page's /Annots array
+)
+
+// pageTabOrders maps the values of a page's /Tabs entry to tab orders
+var pageTabOrders = map[string]string{
+	"R": TabOrderRow,
+	"C": TabOrderColumn,
+	"S": TabOrderStructure,
+}
+
+// FormPage lists the form fields shown on a page in the order the Tab key visits them
+type FormPage struct {
+	Page     int
+	Tabs     string   // The page's /Tabs entry as written, empty when it has none
+	TabOrder string   // One of the TabOrder constants
+	Fields   []string // Fully qualified names, each at the first of its widgets the page shows
+}
+
+// tabStop is a widget of a field on a page
+type tabStop struct {
+	field      string
+	widget     ObjectRef
+	box        BoundingBox
+	annotation int // Position in the page's /Annots array
+	structure  int // Position in the structure tree's order
+}
+
+// FormTabOrder groups the terminal fields of a document's form by the pages showing their
+// widgets, as ReadFormFields returns them, and orders each page's fields by its tab order.
+// Row and column orders compare the widgets' rectangles: a widget whose top is within half
+// the height of a row's first widget shares its row, and likewise for columns. Structure
+// order falls back to annotation order for widgets the structure tree leaves out, and
+// annotation order puts widgets the page does not list last.
+func FormTabOrder(reader *pdf.Reader, fields []FormField) []FormPage {
+	numbering := NewPageNumbering(reader)
+	positions := annotationPositions(numbering)
+	var structure map[ObjectRef]int
+
+	stops := make(map[int][]tabStop)
+	for _, field := range fields {
+		for i, widget := range field.Widgets {
+			if i >= len(field.Pages) || field.Pages[i] == 0 {
+				continue
+			}
+			stop := tabStop{field: field.Name, widget: RefOf(widget), annotation: len(positions)}
+			stop.box, _ = rectBox(widget.Key("Rect"))
+			if position, ok := positions[stop.widget]; ok {
+				stop.annotation = position
+			}
+			stops[field.Pages[i]] = append(stops[field.Pages[i]], stop)
+		}
+	}
+
+	var pages []FormPage
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		pageStops := stops[pageNum]
+		if len(pageStops) == 0 {
+			continue
+		}
+		page := FormPage{Page: pageNum, Tabs: numbering.Page(pageNum).V.Key("Tabs").Name()}
+		page.TabOrder = pageTabOrders[page.Tabs]
+		if page.TabOrder == "" {
+			page.TabOrder = TabOrderAnnotation
+		}
+
+		// Annotation order breaks ties in the others, so a stable sort starts from it
+		sort.SliceStable(pageStops, func(i, j int) bool {
+			return pageStops[i].annotation < pageStops[j].annotation
+		})
+		switch page.TabOrder {
+		case TabOrderRow:
+			pageStops = rowOrder(pageStops)
+		case TabOrderColumn:
+			pageStops = columnOrder(pageStops)
+		case TabOrderStructure:
+			if structure == nil {
+				structure = structureOrder(reader)
+			}
+			pageStops = byStructure(pageStops, structure)
+		}
+
+		seen := make(map[string]bool)
+		for _, stop := range pageStops {
+			if !seen[stop.field] {
+				seen[stop.field] = true
+				page.Fields = append(page.Fields, stop.field)
+			}
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// rowOrder orders widgets by rows from the top of the page, each row from left to right. A
+// widget starts a new row when its top is below the middle of the row's first widget.
+func rowOrder(stops []tabStop) []tabStop {
+	sort.SliceStable(stops, func(i, j int) bool {
+		return stops[i].box.UpperRight.Y > stops[j].box.UpperRight.Y
+	})
+	var ordered []tabStop
+	for start := 0; start < len(stops); {
+		first := stops[start].box
+		end := start + 1
+		for end < len(stops) && stops[end].box.UpperRight.Y > first.UpperRight.Y-first.Height/2 {
+			end++
+		}
+		row := stops[start:end]
+		sort.SliceStable(row, func(i, j int) bool { return row[i].box.LowerLeft.X < row[j].box.LowerLeft.X })
+		ordered = append(ordered, row...)
+		start = end
+	}
+	return ordered
+}
+
+// columnOrder orders widgets by columns from the left of the page, each column from top to
+// bottom. A widget starts a new column when its left edge is past the middle of the
+// column's first widget.
+func columnOrder(stops []tabStop) []tabStop {
+	sort.SliceStable(stops, func(i, j int) bool {
+		return stops[i].box.LowerLeft.X < stops[j].box.LowerLeft.X
+	})
+	var ordered []tabStop
+	for start := 0; start < len(stops); {
+		first := stops[start].box
+		end := start + 1
+		for end < len(stops) && stops[end].box.LowerLeft.X < first.LowerLeft.X+first.Width/2 {
+			end++
+		}
+		column := stops[start:end]
+		sort.SliceStable(column, func(i, j int) bool {
+			return column[i].box.UpperRight.Y > column[j].box.UpperRight.Y
+		})
+		ordered = append(ordered, column...)
+		start = end
+	}
+	return ordered
+}
+
+// byStructure orders widgets by the structure tree, those it leaves out last
+func byStructure(stops []tabStop, structure map[ObjectRef]int) []tabStop {
+	for i := range stops {
+		stops[i].structure = -1
+		if position, ok := structure[stops[i].widget]; ok && stops[i].widget.ID != 0 {
+			stops[i].structure = position
+		}
+	}
+	sort.SliceStable(stops, func(i, j int) bool {
+		a, b := stops[i].structure, stops[j].structure
+		if (a < 0) != (b < 0) {
+			return a >= 0
+		}
+		return a < b
+	})
+	return stops
+}
+
+// annotationPositions maps the annotations listed in each page's /Annots array to their
+// position in it
+func annotationPositions(numbering *PageNumbering) map[ObjectRef]int {
+	positions := make(map[ObjectRef]int)
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		annots := numbering.Page(pageNum).V.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			if ref := RefOf(annots.Index(i)); ref.ID != 0 {
+				if _, seen := positions[ref]; !seen {
+					positions[ref] = i
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// structureOrder maps the annotations the structure tree refers to through object
+// references to the order a depth-first walk of the tree meets them in
+func structureOrder(reader *pdf.Reader) map[ObjectRef]int {
+	order := make(map[ObjectRef]int)
+	visited := make(map[ObjectRef]bool)
+	elements := 0
+	var walk func(node pdf.Value, depth int)
+	walk = func(node pdf.Value, depth int) {
+		if node.Kind() != pdf.Dict || depth > maxStructureDepth || elements >= maxStructureElements {
+			return
+		}
+		// Object references are mostly direct objects, which share their element's reference
+		if node.Key("Type").Name() == "OBJR" {
+			if ref := RefOf(node.Key("Obj")); ref.ID != 0 {
+				if _, ok := order[ref]; !ok {
+					order[ref] = len(order)
+				}
+			}
+			return
+		}
+		if ref := RefOf(node); ref.ID != 0 {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		elements++
+		forEachKid(node.Key("K"), func(kid pdf.Value) { walk(kid, depth+1) })
+	}
+	walk(reader.Trailer().Key("Root").Key("StructTreeRoot"), 0)
+	return order
+}
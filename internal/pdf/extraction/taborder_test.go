@@ -0,0 +1,65 @@
+package extraction
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+)
+
+func TestFormTabOrder(t *testing.T) {
+	// Each page shows a two by two grid of fields, listed in its /Annots array bottom right
+	// first. The top right field sits a little higher than the top left one.
+	rects := map[string]string{
+		"a": "[50 700 150 720]", "b": "[300 702 400 722]", "c": "[50 600 150 620]", "d": "[300 600 400 620]",
+	}
+	tabs := []string{"/Tabs /R", "/Tabs /C", "/Tabs /S", ""}
+	objects := []string{"", ""}
+	var pages, fields []string
+	widget := map[string]int{}
+	for i, tab := range tabs {
+		page := len(objects) + 1
+		pages = append(pages, fmt.Sprintf("%d 0 R", page))
+		annots := make([]string, 4)
+		objects = append(objects, "")
+		for j, name := range []string{"a", "b", "c", "d"} {
+			ref := page + 1 + j
+			widget[fmt.Sprintf("%s%d", name, i+1)] = ref
+			annots[3-j] = fmt.Sprintf("%d 0 R", ref)
+			fields = append(fields, fmt.Sprintf("%d 0 R", ref))
+			objects = append(objects, fmt.Sprintf("<< /FT /Tx /T (%s%d) /Subtype /Widget /Rect %s /P %d 0 R >>",
+				name, i+1, rects[name], page))
+		}
+		objects[page-1] = fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [%s] %s >>",
+			strings.Join(annots, " "), tab)
+	}
+	// The structure tree of the third page names its bottom left field, then its top left one
+	root := len(objects) + 1
+	objects = append(objects,
+		fmt.Sprintf("<< /Type /StructTreeRoot /K %d 0 R >>", root+1),
+		fmt.Sprintf("<< /Type /StructElem /S /Form /K [<< /Type /OBJR /Obj %d 0 R >> << /Type /OBJR /Obj %d 0 R >>] >>",
+			widget["c3"], widget["a3"]))
+	objects[0] = fmt.Sprintf("<< /Type /Catalog /Pages 2 0 R /StructTreeRoot %d 0 R /AcroForm << /Fields [%s] >> >>",
+		root, strings.Join(fields, " "))
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pages, " "), len(pages))
+
+	doc, err := core.Open(writeRawPDF(t, "tabs.pdf", objects), core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	got := FormTabOrder(doc.Reader, ReadFormFields(doc.Reader))
+	want := []FormPage{
+		{Page: 1, Tabs: "R", TabOrder: TabOrderRow, Fields: []string{"a1", "b1", "c1", "d1"}},
+		{Page: 2, Tabs: "C", TabOrder: TabOrderColumn, Fields: []string{"a2", "c2", "b2", "d2"}},
+		// Fields the structure tree leaves out follow in annotation order
+		{Page: 3, Tabs: "S", TabOrder: TabOrderStructure, Fields: []string{"c3", "a3", "d3", "b3"}},
+		{Page: 4, TabOrder: TabOrderAnnotation, Fields: []string{"d4", "c4", "b4", "a4"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FormTabOrder() =\n%+v\nwant\n%+v", got, want)
+	}
+}
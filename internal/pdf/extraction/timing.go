@@ -0,0 +1,77 @@
+package extraction
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PageTiming breaks down the time spent on a single page
+type PageTiming struct {
+	Page         int           `json:"page"`
+	Parse        time.Duration `json:"parse"`         // Loading the page object and its dimensions
+	FilterDecode time.Duration `json:"filter_decode"` // Decoding the page's content streams
+	Extract      time.Duration `json:"extract"`       // Running the enabled extraction stages
+	PostProcess  time.Duration `json:"post_process"`  // Table detection on the page
+	Total        time.Duration `json:"total"`
+}
+
+// SlowestPages returns up to n page timings ordered from slowest to fastest
+func SlowestPages(timings []PageTiming, n int) []PageTiming {
+	sorted := make([]PageTiming, len(timings))
+	copy(sorted, timings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Total > sorted[j].Total
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+// decodeContentStreams decodes a page's content streams once, discarding the output. The
+// parser gives no hook into its own decoding, so filter cost is measured by timing this pass.
+func decodeContentStreams(page pdf.Page) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to decode content stream: %v", r)
+		}
+	}()
+
+	contents := page.V.Key("Contents")
+	streams := []pdf.Value{contents}
+	if contents.Kind() == pdf.Array {
+		streams = streams[:0]
+		for i := 0; i < contents.Len(); i++ {
+			streams = append(streams, contents.Index(i))
+		}
+	}
+
+	for _, stream := range streams {
+		if stream.Kind() != pdf.Stream {
+			continue
+		}
+		rc := stream.Reader()
+		_, copyErr := io.Copy(io.Discard, rc)
+		rc.Close()
+		if copyErr != nil {
+			return fmt.Errorf("failed to decode content stream: %w", copyErr)
+		}
+	}
+
+	return nil
+}
+
+// addStageTime accumulates a page stage's duration into the matching document-wide statistic
+func (s *ProcessingStats) addStageTime(stage string, elapsed time.Duration) {
+	switch stage {
+	case StageText:
+		s.TextExtractionTime += elapsed
+	case StageImages:
+		s.ImageExtractionTime += elapsed
+	case StageVectors:
+		s.VectorExtractionTime += elapsed
+	case StageTables, StageFigures, StagePostProcessing:
+		s.StructureDetectionTime += elapsed
+	}
+}
@@ -2,6 +2,8 @@ package extraction
 
 import (
 	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
 )
 
 // ContentType represents the type of content extracted from PDF
@@ -15,6 +17,7 @@ const (
 	ContentTypeAnnotation ContentType = "annotation"
 	ContentTypeMetadata   ContentType = "metadata"
 	ContentTypeStructural ContentType = "structural"
+	ContentTypeList       ContentType = "list"
 )
 
 // ExtractionMode defines how content should be extracted
@@ -27,6 +30,7 @@ const (
 	ModeForm       ExtractionMode = "form"       // Focus on form fields and data
 	ModeTable      ExtractionMode = "table"      // Detect and extract tabular data
 	ModeComplete   ExtractionMode = "complete"   // Extract all available content types
+	ModePreview    ExtractionMode = "preview"    // Sampled pages of a large document, as structured text
 )
 
 // Coordinate represents a point in PDF coordinate space
@@ -61,7 +65,9 @@ type TextProperties struct {
 type ContentElement struct {
 	ID          string           `json:"id"`
 	Type        ContentType      `json:"type"`
+	Role        string           `json:"role,omitempty"` // header, footer, or watermark for repeated structural text
 	PageNumber  int              `json:"page_number"`
+	PageLabel   string           `json:"page_label,omitempty"` // Label a viewer shows for the page
 	BoundingBox BoundingBox      `json:"bounding_box"`
 	Content     interface{}      `json:"content"`
 	Properties  interface{}      `json:"properties,omitempty"`
@@ -96,6 +102,22 @@ type LineElement struct {
 	Baseline    float64        `json:"baseline,omitempty"`
 }
 
+// ListElement is a list rebuilt from lines opening with bullets or numbers. Items are in
+// reading order; nested items follow the item they belong to with a deeper level.
+type ListElement struct {
+	Ordered bool       `json:"ordered"` // The outermost items are numbered or lettered
+	Items   []ListItem `json:"items"`
+}
+
+// ListItem is an item of a list
+type ListItem struct {
+	Text        string      `json:"text"`         // Without its marker
+	Marker      string      `json:"marker"`       // As printed, such as "•" or "2."
+	MarkerStyle string      `json:"marker_style"` // bullet, decimal, lower-alpha, upper-alpha, lower-roman, or upper-roman
+	Level       int         `json:"level"`        // Nesting depth, 0 for the outermost items
+	BoundingBox BoundingBox `json:"bounding_box"`
+}
+
 // ImageElement represents extracted image content
 type ImageElement struct {
 	Format           string `json:"format"` // PNG, JPEG, etc.
@@ -108,6 +130,14 @@ type ImageElement struct {
 	Size             int64  `json:"size"`
 }
 
+// FigureElement is the content of an image element marking a figure, chart, or equation:
+// the region of the page its images and paths cover
+type FigureElement struct {
+	Caption string `json:"caption,omitempty"` // Line such as "Figure 3: Revenue by region" next to the region
+	Images  int    `json:"images"`            // Images drawn in the region
+	Vectors int    `json:"vectors"`           // Paths drawn in the region
+}
+
 // VectorElement represents vector graphics content
 type VectorElement struct {
 	Type        string      `json:"type"` // path, line, curve, etc.
@@ -131,8 +161,30 @@ type FormElement struct {
 	DefaultValue interface{} `json:"default_value,omitempty"`
 	Required     bool        `json:"required,omitempty"`
 	ReadOnly     bool        `json:"read_only,omitempty"`
-	Options      []string    `json:"options,omitempty"` // For choice fields
+	Options      []string    `json:"options,omitempty"` // Export values of a choice field's options
 	MaxLength    int         `json:"max_length,omitempty"`
+	Checked      bool        `json:"checked,omitempty"`      // The checkbox or radio button widget is on
+	ExportValue  string      `json:"export_value,omitempty"` // Value the checkbox or radio button widget stands for
+
+	Signature *SignatureInfo `json:"signature,omitempty"` // State of a signature field
+
+	// Text field layout: a comb field spaces its value over CombCells cells, and a rich text
+	// field's styled text is given in RichText
+	Multiline       bool           `json:"multiline,omitempty"`
+	Password        bool           `json:"password,omitempty"`
+	DoNotSpellCheck bool           `json:"do_not_spell_check,omitempty"`
+	CombCells       int            `json:"comb_cells,omitempty"`
+	RichText        []RichTextSpan `json:"rich_text,omitempty"`
+
+	// Choice field options with their display text and selection, and the field's layout
+	Choices     []ChoiceOption `json:"choices,omitempty"`
+	Combo       bool           `json:"combo,omitempty"`
+	Editable    bool           `json:"editable,omitempty"`
+	Sorted      bool           `json:"sorted,omitempty"`
+	MultiSelect bool           `json:"multi_select,omitempty"`
+
+	Actions    map[string]string `json:"actions,omitempty"`    // JavaScript of the field's actions by trigger
+	Calculated bool              `json:"calculated,omitempty"` // The value is computed, so Value may be stale
 }
 
 // AnnotationElement represents PDF annotations
@@ -140,20 +192,35 @@ type AnnotationElement struct {
 	AnnotationType string    `json:"annotation_type"` // highlight, note, link, etc.
 	Content        string    `json:"content,omitempty"`
 	Author         string    `json:"author,omitempty"`
+	Subject        string    `json:"subject,omitempty"`
 	CreationDate   time.Time `json:"creation_date,omitempty"`
 	ModifiedDate   time.Time `json:"modified_date,omitempty"`
 	URI            string    `json:"uri,omitempty"` // For link annotations
 	Destination    string    `json:"destination,omitempty"`
 	Color          string    `json:"color,omitempty"`
+	Link           *Link     `json:"link,omitempty"` // Resolved target of link annotations
+	Name           string    `json:"name,omitempty"` // Icon of notes, stamps, and attachments, such as "Approved"
+
+	QuadPoints [][]Coordinate `json:"quad_points,omitempty"` // Corners of each area text markup covers
+	MarkedText string         `json:"marked_text,omitempty"` // Text inside the areas of text markup
+	InkList    [][]Coordinate `json:"ink_list,omitempty"`    // Points of each stroke of ink annotations
+
+	Popup     string `json:"popup,omitempty"`       // ID of the popup element showing the annotation
+	InReplyTo string `json:"in_reply_to,omitempty"` // ID of the annotation this one replies to
+	ReplyType string `json:"reply_type,omitempty"`  // reply, or group when grouped with InReplyTo
 }
 
 // TableElement represents detected tabular data
 type TableElement struct {
-	Rows       []TableRow `json:"rows"`
-	Columns    []TableCol `json:"columns"`
-	CellCount  int        `json:"cell_count"`
-	HasHeaders bool       `json:"has_headers,omitempty"`
-	Confidence float64    `json:"confidence,omitempty"`
+	Rows        []TableRow  `json:"rows"`
+	Columns     []TableCol  `json:"columns"`
+	CellCount   int         `json:"cell_count"`
+	HasHeaders  bool        `json:"has_headers,omitempty"`
+	Confidence  float64     `json:"confidence,omitempty"`
+	PageNumber  int         `json:"page_number,omitempty"`
+	PageLabel   string      `json:"page_label,omitempty"`
+	BoundingBox BoundingBox `json:"bounding_box"`
+	Strategy    string      `json:"strategy,omitempty"` // text_alignment, ruling_lines, or both
 }
 
 // TableRow represents a table row
@@ -169,7 +236,7 @@ type TableCol struct {
 	Index       int         `json:"index"`
 	Header      string      `json:"header,omitempty"`
 	BoundingBox BoundingBox `json:"bounding_box"`
-	DataType    string      `json:"data_type,omitempty"` // text, number, date, etc.
+	DataType    string      `json:"data_type,omitempty"` // Type shared by the column's data cells
 }
 
 // TableCell represents a single table cell
@@ -177,18 +244,14 @@ type TableCell struct {
 	RowIndex    int         `json:"row_index"`
 	ColIndex    int         `json:"col_index"`
 	Content     string      `json:"content"`
+	Value       string      `json:"value,omitempty"` // Content normalized for its data type
 	BoundingBox BoundingBox `json:"bounding_box"`
-	Spans       CellSpan    `json:"spans,omitempty"`
-	DataType    string      `json:"data_type,omitempty"`
+	RowSpan     int         `json:"row_span,omitempty"`  // Rows a merged cell covers, from RowIndex down
+	ColSpan     int         `json:"col_span,omitempty"`  // Columns a merged cell covers, from ColIndex right
+	DataType    string      `json:"data_type,omitempty"` // text, number, currency, percent, or date
 	Confidence  float64     `json:"confidence,omitempty"`
 }
 
-// CellSpan represents cell spanning information
-type CellSpan struct {
-	RowSpan int `json:"row_span,omitempty"`
-	ColSpan int `json:"col_span,omitempty"`
-}
-
 // StructuralElement represents structural information
 type StructuralElement struct {
 	StructType string `json:"struct_type"` // paragraph, heading, list, etc.
@@ -217,7 +280,19 @@ type ExtractionConfig struct {
 	TableDetectionTh   float64        `json:"table_detection_threshold,omitempty"`
 	OCREnabled         bool           `json:"ocr_enabled,omitempty"`
 	OCRLanguages       []string       `json:"ocr_languages,omitempty"`
-	Pages              []int          `json:"pages,omitempty"` // Specific pages to extract
+	Pages              []int          `json:"pages,omitempty"`       // Specific pages to extract
+	MaxWorkers         int            `json:"max_workers,omitempty"` // Pages extracted concurrently; 0 uses the engine default
+	Region             *BoundingBox   `json:"region,omitempty"`      // Part of each page to extract
+
+	// TextNormalization joins the lines of structured and semantic text; nil applies the default
+	TextNormalization *TextNormalization `json:"text_normalization,omitempty"`
+}
+
+// TextNormalization selects how extracted text broken into lines is put back together
+type TextNormalization struct {
+	MergeLines         bool `json:"merge_lines"`          // Join the lines of a paragraph
+	Dehyphenate        bool `json:"dehyphenate"`          // Join words broken with a hyphen at the end of a line
+	PreserveHardBreaks bool `json:"preserve_hard_breaks"` // Keep the line breaks of code, verse, and addresses
 }
 
 // ExtractionResult represents the complete extraction result
@@ -227,10 +302,16 @@ type ExtractionResult struct {
 	ProcessedPages []int            `json:"processed_pages"`
 	Elements       []ContentElement `json:"elements"`
 	Tables         []TableElement   `json:"tables,omitempty"`
+	Structure      *StructureTree   `json:"structure,omitempty"` // Logical structure of tagged documents
+	Entities       []Entity         `json:"entities,omitempty"`  // Found in semantic mode
 	Metadata       PDFMetadata      `json:"metadata"`
 	ExtractionInfo ExtractionInfo   `json:"extraction_info"`
 	Warnings       []string         `json:"warnings,omitempty"`
 	Errors         []string         `json:"errors,omitempty"`
+	Issues         []ParseIssue     `json:"issues,omitempty"`       // Structured form of Warnings and Errors
+	Partial        bool             `json:"partial,omitempty"`      // Extraction stopped before all pages were processed
+	ResumeToken    string           `json:"resume_token,omitempty"` // Resumes a partial, checkpointed extraction
+	Truncations    []string         `json:"truncations,omitempty"`  // Server limits that cut the result short
 }
 
 // PDFMetadata represents document metadata
@@ -273,13 +354,18 @@ type ElementCounts struct {
 
 // ProcessingStats provides statistics about the extraction process
 type ProcessingStats struct {
-	TextExtractionTime     time.Duration `json:"text_extraction_time"`
-	ImageExtractionTime    time.Duration `json:"image_extraction_time"`
-	VectorExtractionTime   time.Duration `json:"vector_extraction_time"`
-	StructureDetectionTime time.Duration `json:"structure_detection_time"`
-	OCRTime                time.Duration `json:"ocr_time,omitempty"`
-	BytesProcessed         int64         `json:"bytes_processed"`
-	MemoryUsed             int64         `json:"memory_used,omitempty"`
+	TextExtractionTime     time.Duration  `json:"text_extraction_time"`
+	ImageExtractionTime    time.Duration  `json:"image_extraction_time"`
+	VectorExtractionTime   time.Duration  `json:"vector_extraction_time"`
+	StructureDetectionTime time.Duration  `json:"structure_detection_time"`
+	OCRTime                time.Duration  `json:"ocr_time,omitempty"`
+	FilterDecodeTime       time.Duration  `json:"filter_decode_time"`
+	PageTimings            []PageTiming   `json:"page_timings,omitempty"` // In processing order
+	BytesProcessed         int64          `json:"bytes_processed"`
+	MemoryUsed             int64          `json:"memory_used,omitempty"`   // Bytes charged against the memory budget
+	SpilledPages           int            `json:"spilled_pages,omitempty"` // Pages written to disk while over the budget
+	Read                   core.ReadStats `json:"read"`                    // Storage access pattern and adaptive read parameters
+	PageDecoding           []PageDecoding `json:"page_decoding,omitempty"` // How well each page's text mapped to Unicode
 }
 
 // Query represents a content query for filtering results
@@ -288,13 +374,16 @@ type Query struct {
 	Pages         []int                  `json:"pages,omitempty"`
 	BoundingBox   *BoundingBox           `json:"bounding_box,omitempty"`
 	TextQuery     string                 `json:"text_query,omitempty"`
+	Regex         bool                   `json:"regex,omitempty"`          // TextQuery is a regular expression
+	CaseSensitive bool                   `json:"case_sensitive,omitempty"` // Match TextQuery case exactly
 	Properties    map[string]interface{} `json:"properties,omitempty"`
 	MinConfidence float64                `json:"min_confidence,omitempty"`
 }
 
 // ExtractionRequest represents a request for content extraction
 type ExtractionRequest struct {
-	FilePath string           `json:"file_path"`
-	Config   ExtractionConfig `json:"config"`
-	Query    *Query           `json:"query,omitempty"`
+	FilePath    string           `json:"file_path"`
+	Config      ExtractionConfig `json:"config"`
+	Query       *Query           `json:"query,omitempty"`
+	ResumeToken string           `json:"-"` // Continue the checkpointed run of an earlier partial result
 }
@@ -0,0 +1,242 @@
+package extraction
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// maxWorkersLimit caps page extraction concurrency regardless of configuration
+const maxWorkersLimit = 32
+
+// defaultMaxWorkers uses one worker per CPU, within the limit
+func defaultMaxWorkers() int {
+	return min(runtime.NumCPU(), maxWorkersLimit)
+}
+
+// pageOutcome holds everything extracted from a single page, so that pages can be processed
+// concurrently and merged in page order
+type pageOutcome struct {
+	elements []ContentElement
+	tables   []TableElement
+	scratch  ExtractionResult // Collects the page's issues and stage times
+	timing   PageTiming
+
+	// Memory budget bookkeeping
+	size    int64  // Estimated bytes the elements and tables hold
+	spill   string // Temporary file holding the page while it does not fit the budget
+	dropped bool   // The page did not fit the budget and could not be spilled
+
+	timedOut bool // The page ran over the page timeout and was skipped
+}
+
+// SetMaxWorkers sets how many pages are extracted concurrently when a request does not
+// specify max_workers; values below one extract pages serially
+func (e *DefaultEngine) SetMaxWorkers(workers int) {
+	e.maxWorkers = min(max(workers, 1), maxWorkersLimit)
+}
+
+// workerCount resolves the number of workers for a request
+func (e *DefaultEngine) workerCount(config ExtractionConfig, pages int) int {
+	workers := e.maxWorkers
+	if config.MaxWorkers > 0 {
+		workers = config.MaxWorkers
+	}
+	return min(max(workers, 1), maxWorkersLimit, max(pages, 1))
+}
+
+// indexedOutcome is a page outcome tagged with the page's position in the request
+type indexedOutcome struct {
+	index   int
+	outcome pageOutcome
+}
+
+// extractPages extracts the given pages with a pool of workers. The parser's reader is
+// read-only after opening, so workers share it; each page records into its own outcome
+// and outcomes are returned in the order of pages. When ctx ends first, only the pages
+// finished so far are returned and wait blocks until the abandoned workers have stopped
+// reading the document; the parser cannot be interrupted in the middle of a page.
+// With a checkpoint, every finished page is saved as soon as it is extracted; with a spool,
+// finished pages are held within the memory budget. Pages running over the page timeout are
+// skipped, and wait then also blocks until they stop reading. Each finished page is reported
+// to the progress reporter of ctx.
+func (e *DefaultEngine) extractPages(
+	ctx context.Context, numbering *PageNumbering, pages []int, config ExtractionConfig, links *LinkResolver,
+	cp *checkpoint, spool *pageSpool,
+) (outcomes []pageOutcome, wait func()) {
+	results := make(chan indexedOutcome, len(pages))
+	jobs := make(chan int)
+	var wg, stragglers sync.WaitGroup
+	var timedOut atomic.Bool
+	for range e.workerCount(config, len(pages)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				outcome := e.extractPageWithin(numbering, pages[i], config, links, &stragglers)
+				timedOut.CompareAndSwap(false, outcome.timedOut)
+				if cp != nil && !outcome.timedOut {
+					if err := cp.save(outcome); err != nil {
+						outcome.scratch.addIssue(newParseIssue(SeverityWarning, StagePage, pages[i], pdf.Value{},
+							fmt.Errorf("failed to checkpoint page: %w", err)))
+					}
+				}
+				results <- indexedOutcome{index: i, outcome: spool.hold(outcome)}
+			}
+		}()
+	}
+
+	dispatched := 0
+dispatch:
+	for i := range pages {
+		select {
+		case jobs <- i:
+			dispatched++
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+
+	finished := make([]*pageOutcome, len(pages))
+	received := 0
+collect:
+	for received < dispatched {
+		select {
+		case r := <-results:
+			finished[r.index] = &r.outcome
+			received++
+			ReportProgress(ctx, received, len(pages), fmt.Sprintf("extracted page %d", pages[r.index]))
+		case <-ctx.Done():
+			break collect
+		}
+	}
+	// Keep pages that finished while the cancellation was being noticed
+drain:
+	for received < dispatched {
+		select {
+		case r := <-results:
+			finished[r.index] = &r.outcome
+			received++
+		default:
+			break drain
+		}
+	}
+
+	for _, outcome := range finished {
+		if outcome != nil {
+			outcomes = append(outcomes, *outcome)
+		}
+	}
+	if received < dispatched || timedOut.Load() {
+		return outcomes, func() {
+			wg.Wait()
+			stragglers.Wait()
+		}
+	}
+	return outcomes, nil
+}
+
+// mergeResumed combines pages loaded from a checkpoint with freshly extracted ones, in the
+// order of pages
+func mergeResumed(pages []int, resumed map[int]pageOutcome, extracted []pageOutcome) []pageOutcome {
+	if len(resumed) == 0 {
+		return extracted
+	}
+	byPage := make(map[int]pageOutcome, len(extracted))
+	for _, outcome := range extracted {
+		byPage[outcome.timing.Page] = outcome
+	}
+	outcomes := make([]pageOutcome, 0, len(resumed)+len(extracted))
+	for _, pageNum := range pages {
+		if outcome, ok := resumed[pageNum]; ok {
+			outcomes = append(outcomes, outcome)
+		} else if outcome, ok := byPage[pageNum]; ok {
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
+}
+
+// extractPage extracts content and tables from a single page and labels them with the page's
+// label. A parser panic outside the isolated extraction stages loses the page rather than
+// the whole document.
+func (e *DefaultEngine) extractPage(
+	numbering *PageNumbering, pageNum int, config ExtractionConfig, links *LinkResolver,
+) (outcome pageOutcome) {
+	pageStart := time.Now()
+	outcome.timing.Page = pageNum
+	stats := &outcome.scratch.ExtractionInfo.ProcessingStats
+
+	defer func() {
+		if r := recover(); r != nil {
+			outcome.elements = nil
+			outcome.tables = nil
+			outcome.scratch.addIssue(newParseIssue(SeverityError, StagePage, pageNum, pdf.Value{},
+				fmt.Errorf("failed to process page: %v", r)))
+		}
+		outcome.timing.Total = time.Since(pageStart)
+	}()
+
+	outcome.elements = e.extractPageContent(numbering, pageNum, config, &outcome.scratch, links, &outcome.timing)
+
+	// Detect tables using both ruling lines and text alignment
+	if e.shouldDetectTables(config) {
+		tableStart := time.Now()
+		page := numbering.Page(pageNum)
+		tables, err := e.detectTables(page, pageNum, config)
+		if err != nil {
+			outcome.scratch.addIssue(newParseIssue(SeverityWarning, StageTables, pageNum, page.V,
+				fmt.Errorf("table detection failed: %w", err)))
+		}
+		outcome.tables = tables
+		outcome.timing.PostProcess = time.Since(tableStart)
+		stats.addStageTime(StageTables, outcome.timing.PostProcess)
+	}
+
+	if config.Region != nil {
+		outcome.elements = clipToRegion(outcome.elements, *config.Region)
+		outcome.tables = tablesInRegion(outcome.tables, *config.Region)
+	}
+
+	if label := numbering.Label(pageNum); label != "" {
+		labelElements(outcome.elements, label)
+		for i := range outcome.tables {
+			outcome.tables[i].PageLabel = label
+		}
+	}
+
+	return outcome
+}
+
+// labelElements sets the page label of elements and their children
+func labelElements(elements []ContentElement, label string) {
+	for i := range elements {
+		elements[i].PageLabel = label
+		labelElements(elements[i].Children, label)
+	}
+}
+
+// mergePage appends a page's outcome to the document result
+func (r *ExtractionResult) mergePage(outcome pageOutcome) {
+	r.Elements = append(r.Elements, outcome.elements...)
+	r.Tables = append(r.Tables, outcome.tables...)
+	for _, issue := range outcome.scratch.Issues {
+		r.addIssue(issue)
+	}
+
+	stats := &r.ExtractionInfo.ProcessingStats
+	pageStats := outcome.scratch.ExtractionInfo.ProcessingStats
+	stats.TextExtractionTime += pageStats.TextExtractionTime
+	stats.ImageExtractionTime += pageStats.ImageExtractionTime
+	stats.VectorExtractionTime += pageStats.VectorExtractionTime
+	stats.StructureDetectionTime += pageStats.StructureDetectionTime
+	stats.FilterDecodeTime += outcome.timing.FilterDecode
+	stats.PageTimings = append(stats.PageTimings, outcome.timing)
+	stats.PageDecoding = append(stats.PageDecoding, pageStats.PageDecoding...)
+}
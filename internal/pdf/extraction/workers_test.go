@@ -0,0 +1,120 @@
+package extraction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+)
+
+// writePagesPDF writes a document with one line of text on each page
+func writePagesPDF(t *testing.T, pages int) string {
+	t.Helper()
+
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", "", "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"}
+	kids := make([]string, 0, pages)
+	for i := range pages {
+		content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (Page %d) Tj ET", i+1)
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+i*2))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] "+
+				"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+i*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages)
+
+	return writeRawPDF(t, "pages.pdf", objects)
+}
+
+// writeRawPDF writes a document from its numbered objects, the first being the catalog
+func writeRawPDF(t *testing.T, name string, objects []string) string {
+	t.Helper()
+
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xrefOffset := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractPages_StopsWhenContextEnds(t *testing.T) {
+	const pages = 64
+	path := writePagesPDF(t, pages)
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+
+	engine := NewEngine()
+	config := ExtractionConfig{Mode: ModeStructured, ExtractText: true, MaxWorkers: 2}
+	pageNums := engine.determinePagesToProcess(nil, pages)
+	numbering := NewPageNumbering(doc.Reader)
+	links := NewLinkResolver(doc.Reader, numbering)
+
+	complete, wait := engine.extractPages(context.Background(), numbering, pageNums, config, links, nil, nil)
+	if len(complete) != pages || wait != nil {
+		t.Fatalf("extractPages() = %d outcomes, want all %d", len(complete), pages)
+	}
+
+	// A cancelled context stops dispatching pages, keeping finished ones in page order
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	partial, wait := engine.extractPages(ctx, numbering, pageNums, config, links, nil, nil)
+	if wait != nil {
+		wait()
+	}
+	if len(partial) == pages {
+		t.Fatalf("extractPages() with cancelled context = %d outcomes, want fewer than %d", len(partial), pages)
+	}
+	for i := 1; i < len(partial); i++ {
+		if partial[i].timing.Page <= partial[i-1].timing.Page {
+			t.Errorf("partial outcomes out of page order: page %d after %d", partial[i].timing.Page,
+				partial[i-1].timing.Page)
+		}
+	}
+
+	_, err = engine.Extract(ctx, ExtractionRequest{FilePath: path, Config: config})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Extract() with cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestExtract_ReportsProgress(t *testing.T) {
+	path := writePagesPDF(t, 5)
+
+	var mu sync.Mutex
+	var reports []string
+	ctx := WithProgress(context.Background(), func(done, total int, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, fmt.Sprintf("%d/%d %s", done, total, message))
+	})
+	config := ExtractionConfig{Mode: ModeStructured, ExtractText: true, MaxWorkers: 2}
+	if _, err := NewEngine().Extract(ctx, ExtractionRequest{FilePath: path, Config: config}); err != nil {
+		t.Fatalf("Extract() unexpected error = %v", err)
+	}
+	if len(reports) != 5 || !strings.HasPrefix(reports[4], "5/5 extracted page") {
+		t.Errorf("Extract() reported progress %q, want one report per page", reports)
+	}
+}
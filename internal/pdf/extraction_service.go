@@ -1,19 +1,60 @@
 package pdf
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/logging"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/cache"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// logger reports service diagnostics that are not returned to the caller
+var logger = logging.Component("pdf")
+
+// Extraction quality levels reported in ExtractionSummary
+const (
+	qualityHigh   = "high"
+	qualityMedium = "medium"
+	qualityLow    = "low"
+
+	// bytesPerMB converts byte rates to megabytes per second
+	bytesPerMB = 1024 * 1024
+
+	// slowestPagesReported is the number of page timings included in results
+	slowestPagesReported = 5
+	// slowPageShare is the share of extraction time above which a single page is called out
+	slowPageShare = 0.5
+	// lowDecodingConfidence is the decoding confidence below which a page's text is called out
+	lowDecodingConfidence = 0.8
 )
 
 // ExtractionService provides enhanced PDF content extraction capabilities
 type ExtractionService struct {
 	maxFileSize int64
+	engine      extraction.Engine
+	memoryMap   bool
+	cache       *cache.Cache
+	cursors     *cache.Cache // Results being paged through while the extraction cache is disabled
+	metrics     *Metrics     // Records the extractions the engine runs; nil records nothing
 }
 
 // NewExtractionService creates a new extraction service
 func NewExtractionService(maxFileSize int64) *ExtractionService {
 	return &ExtractionService{
 		maxFileSize: maxFileSize,
+		engine:      extraction.NewEngineWithConfig(maxFileSize, maxFileSize, false),
+		cache:       cache.New(0),
+		cursors:     cache.New(cursorCacheBytes),
 	}
 }
 
@@ -29,15 +70,27 @@ type PDFExtractRequest struct {
 
 // ExtractConfig provides simplified configuration for MCP tools
 type ExtractConfig struct {
-	ExtractText        bool    `json:"extract_text,omitempty"`
-	ExtractImages      bool    `json:"extract_images,omitempty"`
-	ExtractTables      bool    `json:"extract_tables,omitempty"`
-	ExtractForms       bool    `json:"extract_forms,omitempty"`
-	ExtractAnnotations bool    `json:"extract_annotations,omitempty"`
-	IncludeCoordinates bool    `json:"include_coordinates,omitempty"`
-	IncludeFormatting  bool    `json:"include_formatting,omitempty"`
-	Pages              []int   `json:"pages,omitempty"`
-	MinConfidence      float64 `json:"min_confidence,omitempty"`
+	ExtractText        bool       `json:"extract_text,omitempty"`
+	ExtractImages      bool       `json:"extract_images,omitempty"`
+	ExtractTables      bool       `json:"extract_tables,omitempty"`
+	ExtractForms       bool       `json:"extract_forms,omitempty"`
+	ExtractAnnotations bool       `json:"extract_annotations,omitempty"`
+	IncludeCoordinates bool       `json:"include_coordinates,omitempty"`
+	IncludeFormatting  bool       `json:"include_formatting,omitempty"`
+	Pages              []int      `json:"pages,omitempty"`
+	FirstPages         int        `json:"first_pages,omitempty"` // Opening pages, extended to a section boundary
+	LastPages          int        `json:"last_pages,omitempty"`  // Closing pages, extended to a section boundary
+	MinConfidence      float64    `json:"min_confidence,omitempty"`
+	MaxWorkers         int        `json:"max_workers,omitempty"`  // Pages extracted concurrently; 0 uses the default
+	MaxElements        int        `json:"max_elements,omitempty"` // Element budget of preview mode
+	ResumeToken        string     `json:"resume_token,omitempty"` // Continues a partial, checkpointed extraction
+	PageSize           int        `json:"page_size,omitempty"`    // Elements and tables per response; 0 returns all
+	Cursor             string     `json:"cursor,omitempty"`       // Continues a paginated result from its next_cursor
+	Region             *Rectangle `json:"region,omitempty"`       // Part of each page to extract; nil is the whole page
+	Coordinates        string     `json:"coordinates,omitempty"`  // pdf, top-left, or normalized; empty is pdf
+
+	// TextNormalization joins the lines of structured and semantic text; nil applies the default
+	TextNormalization *TextNormalization `json:"text_normalization,omitempty"`
 }
 
 // PDFQueryRequest represents a request to query extracted content
@@ -47,7 +100,7 @@ type PDFQueryRequest struct {
 }
 
 // ExtractStructured performs structured content extraction with positioning and formatting
-func (s *ExtractionService) ExtractStructured(req PDFExtractRequest) (*PDFExtractResult, error) {
+func (s *ExtractionService) ExtractStructured(ctx context.Context, req PDFExtractRequest) (*PDFExtractResult, error) {
 	if err := s.validatePath(req.Path); err != nil {
 		return nil, err
 	}
@@ -58,27 +111,147 @@ func (s *ExtractionService) ExtractStructured(req PDFExtractRequest) (*PDFExtrac
 		mode = "structured"
 	}
 
-	// For now, return a placeholder result
-	// TODO: Implement actual structured extraction
-	return &PDFExtractResult{
+	if err := validateRegion(req.Config.Region); err != nil {
+		return nil, err
+	}
+	if err := validateCoordinates(req.Config.Coordinates); err != nil {
+		return nil, err
+	}
+
+	// Page selection and extraction read the document once between them
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+
+	// Sample the pages of a preview, which reads them as structured lines with their tables
+	// and form fields
+	var preview *PreviewSelection
+	if extraction.ExtractionMode(mode) == extraction.ModePreview {
+		if len(req.Config.Pages) > 0 || req.Config.FirstPages != 0 || req.Config.LastPages != 0 {
+			return nil, fmt.Errorf("preview mode selects its own pages; pages, first_pages, and last_pages cannot be given")
+		}
+		var err error
+		preview, err = s.selectPreview(ctx, req.Path)
+		if err != nil {
+			return nil, err
+		}
+		preview.ElementBudget = req.Config.MaxElements
+		if preview.ElementBudget <= 0 {
+			preview.ElementBudget = DefaultPreviewElements
+		}
+		req.Config.Pages = make([]int, len(preview.Pages))
+		for i, page := range preview.Pages {
+			req.Config.Pages[i] = page.Page
+		}
+		req.Config.ExtractText = true
+		req.Config.ExtractTables = true
+		req.Config.ExtractAnnotations = true
+		req.Config.IncludeCoordinates = false
+	}
+
+	// Resolve first/last page windows into explicit pages
+	var selection *PageSelection
+	if req.Config.FirstPages != 0 || req.Config.LastPages != 0 {
+		if len(req.Config.Pages) > 0 {
+			return nil, fmt.Errorf("pages cannot be combined with first_pages or last_pages")
+		}
+		var err error
+		selection, err = s.selectPages(ctx, req.Path, req.Config.FirstPages, req.Config.LastPages)
+		if err != nil {
+			return nil, err
+		}
+		req.Config.Pages = selection.Pages
+	}
+
+	extractReq := extraction.ExtractionRequest{
+		FilePath:    req.Path,
+		Config:      s.buildEngineConfig(extraction.ExtractionMode(mode), req.Config),
+		Query:       convertContentQuery(req.Query),
+		ResumeToken: req.Config.ResumeToken,
+	}
+
+	// Paginated results are kept between responses, in the extraction cache when it is enabled
+	store := s.cache
+	var cursor resultCursor
+	if req.Config.PageSize > 0 {
+		if !store.Enabled() {
+			store = s.cursors
+		}
+		var err error
+		if cursor, err = openCursor(store, req, extractReq); err != nil {
+			return nil, err
+		}
+	} else if req.Config.Cursor != "" {
+		return nil, fmt.Errorf("cursor requires page_size")
+	}
+
+	cached, err := loadCached(ctx, store, req.Path, "extract", extractReq, func() (any, error) {
+		started := time.Now()
+		result, err := s.engine.Extract(ctx, extractReq)
+		if err == nil && s.metrics != nil {
+			s.metrics.observeExtraction(len(result.ProcessedPages), time.Since(started), result.Partial)
+		}
+		return result, err
+	})
+	if err != nil {
+		// Unreadable documents yield an empty result describing the failure
+		logger.Warn("extraction failed", "path", req.Path, "error", err)
+		return &PDFExtractResult{
+			FilePath:       req.Path,
+			Mode:           mode,
+			ProcessedPages: []int{},
+			Elements:       []ContentElement{},
+			Tables:         []TableElement{},
+			Summary: ExtractionSummary{
+				ContentTypes: make(map[string]int),
+				Quality:      qualityLow,
+			},
+			Errors: []string{err.Error()},
+		}, nil //nolint:nilerr // Errors are reported in the result
+	}
+	engineResult := cached.(*extraction.ExtractionResult)
+
+	result := &PDFExtractResult{
 		FilePath:       req.Path,
 		Mode:           mode,
-		TotalPages:     1,
-		ProcessedPages: []int{1},
-		Elements:       []ContentElement{},
-		Tables:         []TableElement{},
-		Summary: ExtractionSummary{
-			ContentTypes:  make(map[string]int),
-			TotalElements: 0,
-			Quality:       "medium",
-		},
-		Metadata: DocumentMetadata{},
-		Warnings: []string{"Structured extraction not yet fully implemented"},
-	}, nil
+		TotalPages:     engineResult.TotalPages,
+		ProcessedPages: engineResult.ProcessedPages,
+		Elements:       convertElements(engineResult.Elements, req.Config.MinConfidence),
+		Tables:         convertTables(engineResult.Tables),
+		Structure:      convertStructure(engineResult.Structure),
+		Entities:       convertEntities(engineResult.Entities),
+		Metadata:       DocumentMetadata{},
+		Warnings:       engineResult.Warnings,
+		Errors:         engineResult.Errors,
+		Issues:         convertIssues(engineResult.Issues),
+		ReadStats:      convertReadStats(engineResult.ExtractionInfo.ProcessingStats.Read),
+		Timing:         convertTiming(engineResult.ExtractionInfo),
+		Decoding:       convertDecoding(engineResult.ExtractionInfo.ProcessingStats.PageDecoding),
+		Partial:        engineResult.Partial,
+		ResumeToken:    engineResult.ResumeToken,
+		PageSelection:  selection,
+		Preview:        preview,
+	}
+	if preview != nil {
+		applyPreviewBudget(result, preview)
+	}
+	result.Summary = s.buildExtractionSummary(result, extractReq.Config)
+	result.Summary.Truncations = engineResult.Truncations
+	if system := req.Config.Coordinates; system != "" && system != CoordinatesPDF {
+		pages, err := s.GetPageInfo(req.Path)
+		if err != nil {
+			return nil, err
+		}
+		convertCoordinates(result, system, pages)
+	}
+	if req.Config.PageSize > 0 {
+		paginateResult(result, cursor, req.Config.PageSize)
+	}
+
+	return result, nil
 }
 
 // ExtractTables performs table detection and extraction
-func (s *ExtractionService) ExtractTables(req PDFExtractRequest) (*PDFExtractResult, error) {
+func (s *ExtractionService) ExtractTables(ctx context.Context, req PDFExtractRequest) (*PDFExtractResult, error) {
 	if err := s.validatePath(req.Path); err != nil {
 		return nil, err
 	}
@@ -88,11 +261,11 @@ func (s *ExtractionService) ExtractTables(req PDFExtractRequest) (*PDFExtractRes
 	req.Config.ExtractTables = true
 	req.Config.ExtractText = true // Need text for table detection
 
-	return s.ExtractStructured(req)
+	return s.ExtractStructured(ctx, req)
 }
 
 // ExtractSemantic performs semantic content grouping
-func (s *ExtractionService) ExtractSemantic(req PDFExtractRequest) (*PDFExtractResult, error) {
+func (s *ExtractionService) ExtractSemantic(ctx context.Context, req PDFExtractRequest) (*PDFExtractResult, error) {
 	if err := s.validatePath(req.Path); err != nil {
 		return nil, err
 	}
@@ -103,11 +276,11 @@ func (s *ExtractionService) ExtractSemantic(req PDFExtractRequest) (*PDFExtractR
 	req.Config.IncludeCoordinates = true
 	req.Config.IncludeFormatting = true
 
-	return s.ExtractStructured(req)
+	return s.ExtractStructured(ctx, req)
 }
 
 // ExtractComplete performs comprehensive extraction of all content types
-func (s *ExtractionService) ExtractComplete(req PDFExtractRequest) (*PDFExtractResult, error) {
+func (s *ExtractionService) ExtractComplete(ctx context.Context, req PDFExtractRequest) (*PDFExtractResult, error) {
 	if err := s.validatePath(req.Path); err != nil {
 		return nil, err
 	}
@@ -122,14 +295,17 @@ func (s *ExtractionService) ExtractComplete(req PDFExtractRequest) (*PDFExtractR
 	req.Config.IncludeCoordinates = true
 	req.Config.IncludeFormatting = true
 
-	return s.ExtractStructured(req)
+	return s.ExtractStructured(ctx, req)
 }
 
 // QueryContent searches extracted content using the provided query
-func (s *ExtractionService) QueryContent(req PDFQueryRequest) (*PDFQueryResult, error) {
+func (s *ExtractionService) QueryContent(ctx context.Context, req PDFQueryRequest) (*PDFQueryResult, error) {
 	if err := s.validatePath(req.Path); err != nil {
 		return nil, err
 	}
+	if err := req.Query.Validate(); err != nil {
+		return nil, err
+	}
 
 	// First extract content in structured mode
 	extractReq := PDFExtractRequest{
@@ -144,15 +320,14 @@ func (s *ExtractionService) QueryContent(req PDFQueryRequest) (*PDFQueryResult,
 			IncludeCoordinates: true,
 			IncludeFormatting:  true,
 		},
+		Query: &req.Query,
 	}
 
-	extractResult, err := s.ExtractStructured(extractReq)
+	extractResult, err := s.ExtractStructured(ctx, extractReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract content for querying: %w", err)
 	}
 
-	// For now, return all elements (query filtering not yet implemented)
-	// TODO: Implement actual query filtering
 	result := &PDFQueryResult{
 		FilePath:   req.Path,
 		Query:      req.Query,
@@ -164,14 +339,161 @@ func (s *ExtractionService) QueryContent(req PDFQueryRequest) (*PDFQueryResult,
 	return result, nil
 }
 
+// SetMemoryMapping enables reading documents through a memory mapping where the platform supports it
+func (s *ExtractionService) SetMemoryMapping(enabled bool) {
+	s.memoryMap = enabled
+	if engine, ok := s.engine.(*extraction.DefaultEngine); ok {
+		engine.SetMemoryMapping(enabled)
+	}
+}
+
+// SetCheckpointDir saves extracted pages under dir so that interrupted extractions can be
+// resumed; an empty dir disables checkpoints
+func (s *ExtractionService) SetCheckpointDir(dir string) {
+	if engine, ok := s.engine.(*extraction.DefaultEngine); ok {
+		engine.SetCheckpointDir(dir)
+	}
+}
+
+// SetMemoryBudget limits the memory extracted pages may hold across concurrent extractions;
+// zero removes the limit
+func (s *ExtractionService) SetMemoryBudget(maxBytes int64) {
+	if engine, ok := s.engine.(*extraction.DefaultEngine); ok {
+		engine.SetMemoryBudget(maxBytes)
+	}
+}
+
+// SetLimits bounds the pages processed and elements returned per extraction and the time
+// spent on each page; zero values remove a limit
+func (s *ExtractionService) SetLimits(maxPages, maxElements int, pageTimeout time.Duration) {
+	if engine, ok := s.engine.(*extraction.DefaultEngine); ok {
+		engine.SetLimits(extraction.Limits{MaxPages: maxPages, MaxElements: maxElements, PageTimeout: pageTimeout})
+	}
+}
+
+// SetCacheSize enables the extraction cache with the given limit in bytes; zero disables it.
+// Replacing the cache drops any results cached so far.
+func (s *ExtractionService) SetCacheSize(maxBytes int64) {
+	s.cache = cache.New(maxBytes)
+}
+
+// CacheStats reports extraction cache occupancy and hit rates
+func (s *ExtractionService) CacheStats() cache.Stats {
+	return s.cache.Stats()
+}
+
+// cached returns the result of load for a file and request, reusing a previous result while
+// the file content is unchanged
+func (s *ExtractionService) cached(
+	ctx context.Context, path, kind string, params any, load func() (any, error),
+) (any, error) {
+	return loadCached(ctx, s.cache, path, kind, params, load)
+}
+
+// loadCached returns the result of load for a file and request from store, loading and
+// storing it when missing. Results are charged against the limit by their JSON size;
+// results loaded after ctx ended, or cut short by the memory budget, may be partial and
+// are not stored.
+func loadCached(
+	ctx context.Context, store *cache.Cache, path, kind string, params any, load func() (any, error),
+) (any, error) {
+	if !store.Enabled() {
+		return load()
+	}
+
+	key, err := cacheKey(store, path, kind, params)
+	if err != nil {
+		return load()
+	}
+
+	if value, ok := store.Get(key); ok {
+		return value, nil
+	}
+
+	value, err := load()
+	if err != nil || ctx.Err() != nil {
+		return value, err
+	}
+	if result, ok := value.(*extraction.ExtractionResult); ok && result.Partial {
+		return value, nil
+	}
+	if encoded, err := json.Marshal(value); err == nil {
+		store.Put(key, value, int64(len(encoded)))
+	}
+	return value, nil
+}
+
+// cacheKey identifies a file's current content, a kind of result, and the parameters the
+// result is produced with
+func cacheKey(store *cache.Cache, path, kind string, params any) (string, error) {
+	fileKey, err := store.FileKey(path)
+	if err != nil {
+		return "", err
+	}
+	encodedParams, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode cache key: %w", err)
+	}
+	return fileKey + ":" + kind + ":" + string(encodedParams), nil
+}
+
+// selectPages opens a document to resolve first/last page windows at section boundaries
+func (s *ExtractionService) selectPages(
+	ctx context.Context, path string, firstPages, lastPages int,
+) (*PageSelection, error) {
+	doc, err := core.OpenShared(ctx, path, core.Options{MemoryMap: s.memoryMap})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	return SelectPages(doc.Reader, firstPages, lastPages)
+}
+
+// selectPreview opens a document to sample the pages of a preview
+func (s *ExtractionService) selectPreview(ctx context.Context, path string) (*PreviewSelection, error) {
+	doc, err := core.OpenShared(ctx, path, core.Options{MemoryMap: s.memoryMap})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	return SelectPreviewPages(doc.Reader)
+}
+
 // GetPageInfo returns detailed page information
 func (s *ExtractionService) GetPageInfo(path string) ([]PageInfo, error) {
 	if err := s.validatePath(path); err != nil {
 		return nil, err
 	}
 
-	// TODO: Implement actual page info extraction
-	return []PageInfo{}, nil
+	cached, err := s.cached(context.Background(), path, "page_info", nil, func() (any, error) {
+		return s.engine.GetPageInfo(path)
+	})
+	if err != nil {
+		// Unreadable documents report no pages, matching ExtractStructured
+		logger.Warn("page info extraction failed", "path", path, "error", err)
+		return []PageInfo{}, nil //nolint:nilerr // The path was validated above
+	}
+
+	enginePages := cached.([]extraction.PageInfo)
+	pages := make([]PageInfo, len(enginePages))
+	for i, page := range enginePages {
+		pages[i] = PageInfo{
+			Number:        page.Number,
+			Label:         page.Label,
+			Width:         page.Width,
+			Height:        page.Height,
+			Rotation:      page.Rotation,
+			MediaBox:      convertBoundingBox(page.MediaBox),
+			CropBox:       convertBoundingBox(page.CropBox),
+			BleedBox:      convertBoundingBox(page.BleedBox),
+			TrimBox:       convertBoundingBox(page.TrimBox),
+			ArtBox:        convertBoundingBox(page.ArtBox),
+			ExplicitBoxes: page.ExplicitBoxes,
+		}
+	}
+	return pages, nil
 }
 
 // GetMetadata extracts comprehensive document metadata
@@ -180,31 +502,420 @@ func (s *ExtractionService) GetMetadata(path string) (*DocumentMetadata, error)
 		return nil, err
 	}
 
-	// TODO: Implement actual metadata extraction
-	return &DocumentMetadata{}, nil
+	cached, err := s.cached(context.Background(), path, "metadata", nil, func() (any, error) {
+		return s.engine.GetMetadata(path)
+	})
+	if err != nil {
+		// Unreadable documents report empty metadata, matching ExtractStructured
+		logger.Warn("metadata extraction failed", "path", path, "error", err)
+		return &DocumentMetadata{}, nil //nolint:nilerr // The path was validated above
+	}
+
+	metadata := cached.(*extraction.PDFMetadata)
+	result := &DocumentMetadata{
+		Title:            metadata.Title,
+		Author:           metadata.Author,
+		Subject:          metadata.Subject,
+		Creator:          metadata.Creator,
+		Producer:         metadata.Producer,
+		Keywords:         metadata.Keywords,
+		PageLayout:       metadata.PageLayout,
+		PageMode:         metadata.PageMode,
+		Version:          metadata.Version,
+		Encrypted:        metadata.Encrypted,
+		CustomProperties: metadata.CustomProperties,
+	}
+	if !metadata.CreationDate.IsZero() {
+		result.CreationDate = metadata.CreationDate.Format(time.RFC3339)
+	}
+	if !metadata.ModificationDate.IsZero() {
+		result.ModificationDate = metadata.ModificationDate.Format(time.RFC3339)
+	}
+	return result, nil
 }
 
 // Helper methods
 
+// buildEngineConfig maps the simplified MCP configuration onto the engine configuration
+func (s *ExtractionService) buildEngineConfig(
+	mode extraction.ExtractionMode, cfg ExtractConfig,
+) extraction.ExtractionConfig {
+	engineConfig := extraction.ExtractionConfig{
+		Mode:               mode,
+		ExtractText:        cfg.ExtractText,
+		ExtractImages:      cfg.ExtractImages,
+		ExtractForms:       cfg.ExtractForms,
+		ExtractAnnotations: cfg.ExtractAnnotations,
+		ExtractTables:      cfg.ExtractTables,
+		PreserveFormatting: cfg.IncludeFormatting,
+		IncludeCoordinates: cfg.IncludeCoordinates,
+		IncludeProperties:  cfg.IncludeFormatting,
+		Pages:              cfg.Pages,
+		MaxWorkers:         cfg.MaxWorkers,
+		Region:             regionBounds(cfg.Region),
+		TextNormalization:  (*extraction.TextNormalization)(cfg.TextNormalization),
+	}
+
+	// Extract text when no content type was selected explicitly
+	if !cfg.ExtractText && !cfg.ExtractImages && !cfg.ExtractForms && !cfg.ExtractAnnotations && !cfg.ExtractTables {
+		engineConfig.ExtractText = true
+	}
+
+	return engineConfig
+}
+
+// buildExtractionSummary summarizes element counts, page distribution, and quality
+func (s *ExtractionService) buildExtractionSummary(
+	result *PDFExtractResult, cfg extraction.ExtractionConfig,
+) ExtractionSummary {
+	summary := ExtractionSummary{
+		ContentTypes:  make(map[string]int),
+		TotalElements: len(result.Elements),
+		HasStructure:  len(result.Tables) > 0 || result.Structure != nil,
+	}
+
+	pages := make(map[int]*PageSummary)
+	for _, element := range result.Elements {
+		summary.ContentTypes[element.Type]++
+		if len(element.Children) > 0 {
+			summary.HasStructure = true
+		}
+
+		page, ok := pages[element.PageNumber]
+		if !ok {
+			page = &PageSummary{Page: element.PageNumber, Label: element.PageLabel, Types: make(map[string]int)}
+			pages[element.PageNumber] = page
+		}
+		page.Elements++
+		page.Types[element.Type]++
+	}
+	if len(result.Tables) > 0 {
+		summary.ContentTypes["table"] = len(result.Tables)
+	}
+
+	for _, pageNum := range result.ProcessedPages {
+		if page, ok := pages[pageNum]; ok {
+			summary.PageBreakdown = append(summary.PageBreakdown, *page)
+		}
+	}
+
+	switch {
+	case len(result.Elements) == 0 && len(result.Tables) == 0:
+		summary.Quality = qualityLow
+		summary.Suggestions = append(summary.Suggestions,
+			"No content was extracted; the document may be scanned or image-based")
+	case len(result.Errors) > 0:
+		summary.Quality = qualityMedium
+	default:
+		summary.Quality = qualityHigh
+	}
+
+	tablesRequested := cfg.ExtractTables || cfg.Mode == extraction.ModeTable || cfg.Mode == extraction.ModeComplete
+	if tablesRequested && len(result.Tables) == 0 {
+		summary.Suggestions = append(summary.Suggestions,
+			"No tables detected; tables without ruling lines or consistent column alignment may be missed")
+	}
+
+	if timing := result.Timing; timing != nil && len(result.ProcessedPages) > 1 && len(timing.SlowestPages) > 0 {
+		if slowest := timing.SlowestPages[0]; slowest.TotalMs > slowPageShare*timing.TotalMs {
+			summary.Suggestions = append(summary.Suggestions, fmt.Sprintf(
+				"Page %d took %.0f%% of the extraction time; extract other page ranges separately to avoid it",
+				slowest.Page, 100*slowest.TotalMs/timing.TotalMs))
+		}
+	}
+
+	var unreadable []string
+	for _, page := range result.Decoding {
+		if page.Confidence < lowDecodingConfidence {
+			unreadable = append(unreadable, strconv.Itoa(page.Page))
+		}
+	}
+	if len(unreadable) > 0 {
+		summary.Suggestions = append(summary.Suggestions, fmt.Sprintf(
+			"Text on page(s) %s could not be reliably mapped to Unicode; fonts without ToUnicode maps "+
+				"may need OCR or rendering to read", strings.Join(unreadable, ", ")))
+	}
+
+	return summary
+}
+
+// convertDecoding converts the engine's per-page decoding reports, in page order
+func convertDecoding(pages []extraction.PageDecoding) []PageDecoding {
+	if len(pages) == 0 {
+		return nil
+	}
+	converted := make([]PageDecoding, len(pages))
+	for i, page := range pages {
+		converted[i] = PageDecoding(page)
+	}
+	sort.Slice(converted, func(i, j int) bool { return converted[i].Page < converted[j].Page })
+	return converted
+}
+
+// convertElements converts engine elements into MCP response elements, dropping those
+// below the minimum confidence
+func convertElements(elements []extraction.ContentElement, minConfidence float64) []ContentElement {
+	converted := make([]ContentElement, 0, len(elements))
+	for i := range elements {
+		if elements[i].Confidence < minConfidence {
+			continue
+		}
+		converted = append(converted, convertElement(elements[i], minConfidence))
+	}
+	return converted
+}
+
+func convertElement(element extraction.ContentElement, minConfidence float64) ContentElement {
+	converted := ContentElement{
+		ID:          element.ID,
+		Type:        string(element.Type),
+		Role:        element.Role,
+		PageNumber:  element.PageNumber,
+		PageLabel:   element.PageLabel,
+		BoundingBox: convertBoundingBox(element.BoundingBox),
+		Content:     element.Content,
+		Parent:      element.Parent,
+		ZOrder:      element.ZOrder,
+		Confidence:  element.Confidence,
+	}
+
+	// Text content is exposed as a plain string with formatting moved to properties
+	if text, ok := element.Content.(extraction.TextElement); ok {
+		converted.Content = text.Text
+		if text.Properties.FontName != "" || text.Properties.FontSize > 0 {
+			converted.Properties = map[string]interface{}{
+				"font_name": text.Properties.FontName,
+				"font_size": text.Properties.FontSize,
+			}
+		}
+	}
+
+	if list, ok := element.Content.(extraction.ListElement); ok {
+		items := make([]ListItem, len(list.Items))
+		for i, item := range list.Items {
+			items[i] = ListItem{
+				Text:        item.Text,
+				Marker:      item.Marker,
+				MarkerStyle: item.MarkerStyle,
+				Level:       item.Level,
+				BoundingBox: convertBoundingBox(item.BoundingBox),
+			}
+		}
+		converted.Content = ListElement{Ordered: list.Ordered, Items: items}
+	}
+
+	if len(element.Children) > 0 {
+		converted.Children = convertElements(element.Children, minConfidence)
+	}
+
+	return converted
+}
+
+// convertStructure maps the engine's structure tree to the public type
+func convertStructure(tree *extraction.StructureTree) *StructureTree {
+	if tree == nil {
+		return nil
+	}
+	return &StructureTree{
+		Elements:     convertStructureElements(tree.Elements),
+		ElementCount: tree.ElementCount,
+		Truncated:    tree.Truncated,
+	}
+}
+
+// convertStructureElements maps structure elements and their children to the public type
+func convertStructureElements(elements []extraction.StructureElement) []StructureElement {
+	converted := make([]StructureElement, len(elements))
+	for i, element := range elements {
+		converted[i] = StructureElement{
+			Role:       element.Role,
+			Type:       element.Type,
+			Page:       element.Page,
+			Text:       element.Text,
+			AltText:    element.AltText,
+			ActualText: element.ActualText,
+			Title:      element.Title,
+			Lang:       element.Lang,
+		}
+		if len(element.Children) > 0 {
+			converted[i].Children = convertStructureElements(element.Children)
+		}
+	}
+	return converted
+}
+
+// convertReadStats maps the engine's storage read statistics to the public type
+func convertReadStats(stats core.ReadStats) *ReadStats {
+	if stats.PhysicalReads == 0 && !stats.MemoryMapped {
+		return nil
+	}
+	return &ReadStats{
+		StorageClass:     stats.StorageClass,
+		ChunkSize:        stats.ChunkSize,
+		ReadAhead:        stats.ReadAhead,
+		PhysicalReads:    stats.PhysicalReads,
+		CacheHits:        stats.CacheHits,
+		BytesRead:        stats.BytesRead,
+		AvgReadLatencyMs: float64(stats.AvgReadLatency) / float64(time.Millisecond),
+		ThroughputMBps:   stats.Throughput / bytesPerMB,
+		MemoryMapped:     stats.MemoryMapped,
+	}
+}
+
+// convertTiming summarizes the engine's per-page timings, keeping the slowest pages
+func convertTiming(info extraction.ExtractionInfo) *ExtractionTiming {
+	if info.Duration == 0 {
+		return nil
+	}
+
+	stats := info.ProcessingStats
+	timing := &ExtractionTiming{
+		TotalMs:        durationMs(info.Duration),
+		FilterDecodeMs: durationMs(stats.FilterDecodeTime),
+		PostProcessMs:  durationMs(stats.StructureDetectionTime),
+	}
+	for _, page := range stats.PageTimings {
+		timing.ParseMs += durationMs(page.Parse)
+		timing.ExtractMs += durationMs(page.Extract)
+	}
+	for _, page := range extraction.SlowestPages(stats.PageTimings, slowestPagesReported) {
+		timing.SlowestPages = append(timing.SlowestPages, PageTiming{
+			Page:           page.Page,
+			TotalMs:        durationMs(page.Total),
+			ParseMs:        durationMs(page.Parse),
+			FilterDecodeMs: durationMs(page.FilterDecode),
+			ExtractMs:      durationMs(page.Extract),
+			PostProcessMs:  durationMs(page.PostProcess),
+		})
+	}
+	return timing
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// convertIssues maps engine parse issues to the public issue type
+func convertIssues(issues []extraction.ParseIssue) []ParseIssue {
+	if len(issues) == 0 {
+		return nil
+	}
+	converted := make([]ParseIssue, 0, len(issues))
+	for _, issue := range issues {
+		converted = append(converted, ParseIssue{
+			Severity: string(issue.Severity),
+			Stage:    issue.Stage,
+			Page:     issue.Page,
+			Object:   issue.Object,
+			Offset:   issue.Offset,
+			Message:  issue.Message,
+		})
+	}
+	return converted
+}
+
+func convertTables(tables []extraction.TableElement) []TableElement {
+	converted := make([]TableElement, 0, len(tables))
+	for _, table := range tables {
+		out := TableElement{
+			Rows:        make([]TableRow, 0, len(table.Rows)),
+			Columns:     make([]TableCol, 0, len(table.Columns)),
+			CellCount:   table.CellCount,
+			HasHeaders:  table.HasHeaders,
+			Confidence:  table.Confidence,
+			PageNumber:  table.PageNumber,
+			PageLabel:   table.PageLabel,
+			BoundingBox: convertBoundingBox(table.BoundingBox),
+			Strategy:    table.Strategy,
+		}
+
+		for _, col := range table.Columns {
+			out.Columns = append(out.Columns, TableCol{
+				Index:       col.Index,
+				Header:      col.Header,
+				BoundingBox: convertBoundingBox(col.BoundingBox),
+				DataType:    col.DataType,
+			})
+		}
+
+		for _, row := range table.Rows {
+			outRow := TableRow{
+				Index:       row.Index,
+				Cells:       make([]TableCell, 0, len(row.Cells)),
+				BoundingBox: convertBoundingBox(row.BoundingBox),
+				IsHeader:    row.IsHeader,
+			}
+			for _, cell := range row.Cells {
+				outRow.Cells = append(outRow.Cells, TableCell{
+					RowIndex:    cell.RowIndex,
+					ColIndex:    cell.ColIndex,
+					Content:     cell.Content,
+					Value:       cell.Value,
+					BoundingBox: convertBoundingBox(cell.BoundingBox),
+					RowSpan:     cell.RowSpan,
+					ColSpan:     cell.ColSpan,
+					DataType:    cell.DataType,
+					Confidence:  cell.Confidence,
+				})
+			}
+			out.Rows = append(out.Rows, outRow)
+		}
+
+		converted = append(converted, out)
+	}
+	return converted
+}
+
+func convertBoundingBox(box extraction.BoundingBox) Rectangle {
+	return Rectangle{
+		X:      box.LowerLeft.X,
+		Y:      box.LowerLeft.Y,
+		Width:  box.Width,
+		Height: box.Height,
+	}
+}
+
+// convertContentQuery maps an MCP content query to the engine's query filter
+func convertContentQuery(q *ContentQuery) *extraction.Query {
+	if q == nil {
+		return nil
+	}
+
+	query := &extraction.Query{
+		Pages:         q.Pages,
+		TextQuery:     q.TextQuery,
+		Regex:         q.Regex,
+		CaseSensitive: q.CaseSensitive,
+		MinConfidence: q.MinConfidence,
+	}
+	for _, contentType := range q.ContentTypes {
+		query.ContentTypes = append(query.ContentTypes, extraction.ContentType(contentType))
+	}
+	query.BoundingBox = regionBounds(q.BoundingBox)
+
+	return query
+}
+
 func (s *ExtractionService) validatePath(path string) error {
 	if path == "" {
-		return fmt.Errorf("path cannot be empty")
+		return pdferrors.New(pdferrors.InvalidArgument, "path cannot be empty")
 	}
 
-	fileInfo, err := os.Stat(path)
+	fileInfo, err := fsys.Stat(path)
 	if os.IsNotExist(err) {
-		return fmt.Errorf("file does not exist: %s", path)
+		return pdferrors.New(pdferrors.NotFound, "file does not exist: %s", path)
 	}
 	if err != nil {
 		return fmt.Errorf("cannot access file: %w", err)
 	}
 
 	if fileInfo.IsDir() {
-		return fmt.Errorf("path is a directory, not a file: %s", path)
+		return pdferrors.New(pdferrors.InvalidArgument, "path is a directory, not a file: %s", path)
 	}
 
 	if fileInfo.Size() > s.maxFileSize {
-		return fmt.Errorf("file too large: %d bytes (max: %d bytes)", fileInfo.Size(), s.maxFileSize)
+		return pdferrors.New(pdferrors.TooLarge, "file too large: %d bytes (max: %d bytes)", fileInfo.Size(), s.maxFileSize)
 	}
 
 	return nil
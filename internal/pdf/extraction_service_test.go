@@ -1,9 +1,15 @@
 package pdf
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
 )
 
 func TestNewExtractionService(t *testing.T) {
@@ -119,7 +125,7 @@ func TestExtractionService_ExtractStructured(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := service.ExtractStructured(tt.req)
+			result, err := service.ExtractStructured(context.Background(), tt.req)
 
 			if tt.wantError {
 				if err == nil {
@@ -162,6 +168,28 @@ func TestExtractionService_ExtractStructured(t *testing.T) {
 	}
 }
 
+func TestExtractionService_ValidatePathCodes(t *testing.T) {
+	service := NewExtractionService(16)
+	large := createTempFile(t, "large.pdf", generateMinimalPDFContent())
+	tests := []struct {
+		name string
+		path string
+		want pdferrors.Code
+	}{
+		{name: "empty path", path: "", want: pdferrors.InvalidArgument},
+		{name: "missing file", path: filepath.Join(t.TempDir(), "missing.pdf"), want: pdferrors.NotFound},
+		{name: "directory", path: t.TempDir(), want: pdferrors.InvalidArgument},
+		{name: "too large", path: large, want: pdferrors.TooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := pdferrors.CodeOf(service.validatePath(tt.path)); code != tt.want {
+				t.Errorf("validatePath(%q) error code = %s, want %s", tt.path, code, tt.want)
+			}
+		})
+	}
+}
+
 func TestExtractionService_ExtractTables(t *testing.T) {
 	service := NewExtractionService(100 * 1024 * 1024)
 
@@ -192,7 +220,7 @@ func TestExtractionService_ExtractTables(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := service.ExtractTables(tt.req)
+			result, err := service.ExtractTables(context.Background(), tt.req)
 
 			if tt.wantError {
 				if err == nil {
@@ -218,6 +246,258 @@ func TestExtractionService_ExtractTables(t *testing.T) {
 	}
 }
 
+func TestExtractionService_ExtractTablesRulingLines(t *testing.T) {
+	service := NewExtractionService(100 * 1024 * 1024)
+
+	req := PDFExtractRequest{
+		Path: createTempFile(t, "ruled.pdf", buildTestPDF(ruledTableContent())),
+	}
+
+	result, err := service.ExtractTables(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ExtractTables() unexpected error = %v", err)
+	}
+	if len(result.Tables) != 1 {
+		t.Fatalf("ExtractTables() found %d tables, want 1 (errors: %v)", len(result.Tables), result.Errors)
+	}
+
+	table := result.Tables[0]
+	if !strings.HasPrefix(table.Strategy, "ruling_lines") {
+		t.Errorf("table Strategy = %q, want ruling_lines", table.Strategy)
+	}
+	if table.PageNumber != 1 {
+		t.Errorf("table PageNumber = %d, want 1", table.PageNumber)
+	}
+	if len(table.Rows) != 3 || len(table.Columns) != 2 {
+		t.Fatalf("table size = %dx%d, want 3x2", len(table.Rows), len(table.Columns))
+	}
+	if table.Confidence < 0.9 {
+		t.Errorf("table Confidence = %.2f, want >= 0.9 for a fully ruled grid", table.Confidence)
+	}
+
+	want := [][]string{{"Name", "Qty"}, {"Apple", "3"}, {"Pear", "5"}}
+	for r, row := range want {
+		for c, content := range row {
+			if got := table.Rows[r].Cells[c].Content; got != content {
+				t.Errorf("cell[%d][%d] = %q, want %q", r, c, got, content)
+			}
+		}
+	}
+	if table.Columns[0].Header != "Name" {
+		t.Errorf("column 0 Header = %q, want Name", table.Columns[0].Header)
+	}
+
+	if result.ReadStats == nil || result.ReadStats.BytesRead == 0 || result.ReadStats.ChunkSize == 0 {
+		t.Errorf("ReadStats = %+v, want storage read statistics", result.ReadStats)
+	}
+}
+
+func TestService_MemoryMapping(t *testing.T) {
+	path := createTempFile(t, "mapped.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (First page text) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Second page text) Tj ET",
+	))
+
+	service := NewService(100 * 1024 * 1024)
+	buffered, err := service.PDFReadFile(PDFReadFileRequest{Path: path})
+	if err != nil {
+		t.Fatalf("PDFReadFile() unexpected error = %v", err)
+	}
+
+	service.SetMemoryMapping(true)
+	mapped, err := service.PDFReadFile(PDFReadFileRequest{Path: path})
+	if err != nil {
+		t.Fatalf("PDFReadFile() with memory mapping unexpected error = %v", err)
+	}
+	if mapped.Content != buffered.Content {
+		t.Errorf("mapped content = %q, want %q as read through buffered reads", mapped.Content, buffered.Content)
+	}
+
+	result, err := service.ExtractStructured(context.Background(), PDFExtractStructuredRequest{Path: path, Config: ExtractionConfig{LastPages: 1}})
+	if err != nil {
+		t.Fatalf("ExtractStructured() with memory mapping unexpected error = %v", err)
+	}
+	if result.PageSelection == nil || len(result.Elements) == 0 {
+		t.Errorf("ExtractStructured() = %d elements with selection %+v, want the last page window",
+			len(result.Elements), result.PageSelection)
+	}
+
+	doc, err := core.Open(path, core.Options{MemoryMap: true})
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer doc.Close()
+	if !doc.MemoryMapped() {
+		t.Skip("memory mapping is not supported on this platform")
+	}
+	if result.ReadStats == nil || !result.ReadStats.MemoryMapped || result.ReadStats.BytesRead == 0 {
+		t.Errorf("ReadStats = %+v, want a memory-mapped read of the whole file", result.ReadStats)
+	}
+}
+
+func TestExtractionService_Cache(t *testing.T) {
+	path := createTempFile(t, "cached.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Cached text) Tj ET"))
+
+	service := NewExtractionService(100 * 1024 * 1024)
+	service.SetCacheSize(16 * 1024 * 1024)
+
+	req := PDFExtractRequest{Path: path}
+	first, err := service.ExtractStructured(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	second, err := service.ExtractStructured(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	if len(second.Elements) != len(first.Elements) || len(first.Elements) == 0 {
+		t.Errorf("cached ExtractStructured() = %d elements, want %d", len(second.Elements), len(first.Elements))
+	}
+
+	// Different settings are cached separately
+	if _, err := service.ExtractStructured(context.Background(), PDFExtractRequest{Path: path, Mode: "complete"}); err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+
+	pages, err := service.GetPageInfo(path)
+	if err != nil {
+		t.Fatalf("GetPageInfo() unexpected error = %v", err)
+	}
+	if len(pages) != 1 || pages[0].Width != 612 || pages[0].Height != 792 {
+		t.Errorf("GetPageInfo() = %+v, want one 612x792 page", pages)
+	}
+	if _, err := service.GetPageInfo(path); err != nil {
+		t.Fatalf("GetPageInfo() unexpected error = %v", err)
+	}
+
+	stats := service.CacheStats()
+	if stats.Hits != 2 || stats.Misses != 3 || stats.Entries != 3 {
+		t.Errorf("CacheStats() = %+v, want 2 hits, 3 misses, 3 entries", stats)
+	}
+
+	// Rewriting the file invalidates its entries
+	if err := os.WriteFile(path, []byte(buildTestPDF("BT /F1 12 Tf 72 720 Td (New text) Tj ET")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ExtractStructured(context.Background(), req); err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	if stats := service.CacheStats(); stats.Misses != 4 {
+		t.Errorf("CacheStats() misses = %d after modifying the file, want 4", stats.Misses)
+	}
+}
+
+func TestExtractionService_Timing(t *testing.T) {
+	path := createTempFile(t, "timed.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (First page text) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Second page text) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Third page text) Tj ET",
+	))
+
+	service := NewExtractionService(100 * 1024 * 1024)
+	// Page times only add up within the total when pages are extracted one at a time
+	result, err := service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Mode:   "complete",
+		Config: ExtractConfig{MaxWorkers: 1},
+	})
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+
+	timing := result.Timing
+	if timing == nil || timing.TotalMs <= 0 {
+		t.Fatalf("Timing = %+v, want a positive total", timing)
+	}
+	if len(timing.SlowestPages) != 3 {
+		t.Fatalf("SlowestPages = %d entries, want one per processed page", len(timing.SlowestPages))
+	}
+
+	var pageTotal float64
+	for i, page := range timing.SlowestPages {
+		if i > 0 && page.TotalMs > timing.SlowestPages[i-1].TotalMs {
+			t.Errorf("SlowestPages not ordered slowest first: %+v", timing.SlowestPages)
+		}
+		if parts := page.ParseMs + page.FilterDecodeMs + page.ExtractMs + page.PostProcessMs; parts > page.TotalMs {
+			t.Errorf("page %d phases sum to %.3f ms, more than its %.3f ms total", page.Page, parts, page.TotalMs)
+		}
+		pageTotal += page.TotalMs
+	}
+	if pageTotal > timing.TotalMs {
+		t.Errorf("page timings sum to %.3f ms, more than the %.3f ms total", pageTotal, timing.TotalMs)
+	}
+}
+
+func TestExtractionService_ParallelPagesKeepOrder(t *testing.T) {
+	pages := make([]string, 12)
+	for i := range pages {
+		pages[i] = fmt.Sprintf("BT /F1 12 Tf 72 720 Td (Page %d text) Tj ET", i+1)
+	}
+	path := createTempFile(t, "parallel.pdf", buildTestPDF(pages...))
+	service := NewExtractionService(100 * 1024 * 1024)
+
+	extract := func(workers int) *PDFExtractResult {
+		result, err := service.ExtractStructured(context.Background(), PDFExtractRequest{
+			Path:   path,
+			Mode:   "complete",
+			Config: ExtractConfig{MaxWorkers: workers},
+		})
+		if err != nil {
+			t.Fatalf("ExtractStructured() with %d workers unexpected error = %v", workers, err)
+		}
+		return result
+	}
+
+	serial := extract(1)
+	parallel := extract(8)
+	if len(serial.Elements) == 0 || len(parallel.Elements) != len(serial.Elements) {
+		t.Fatalf("parallel extraction = %d elements, want %d", len(parallel.Elements), len(serial.Elements))
+	}
+	for i := range serial.Elements {
+		if parallel.Elements[i].ID != serial.Elements[i].ID {
+			t.Fatalf("element %d = %s, want %s in serial order", i, parallel.Elements[i].ID, serial.Elements[i].ID)
+		}
+	}
+	if len(parallel.Errors) != len(serial.Errors) {
+		t.Errorf("parallel errors = %v, want %v", parallel.Errors, serial.Errors)
+	}
+}
+
+func TestExtractionService_ReportsParseIssues(t *testing.T) {
+	service := NewExtractionService(100 * 1024 * 1024)
+
+	// Page 2 uses a content stream filter the parser cannot decode
+	content := buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 5 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		"<< /Length 0 >>\nstream\n\nendstream",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 6 0 R >>",
+		"<< /Length 4 /Filter /LZWDecode >>\nstream\nABCD\nendstream",
+	})
+
+	req := PDFExtractRequest{
+		Path:   createTempFile(t, "damaged.pdf", content),
+		Config: ExtractConfig{ExtractText: true},
+	}
+
+	result, err := service.ExtractStructured(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	if len(result.Issues) == 0 {
+		t.Fatalf("ExtractStructured() reported no issues (errors: %v)", result.Errors)
+	}
+
+	issue := result.Issues[0]
+	if issue.Severity != "error" || issue.Stage != "text" || issue.Page != 2 || issue.Object != "5 0 R" {
+		t.Errorf("issue = %+v, want text error on page 2 object 5 0 R", issue)
+	}
+	if len(result.Errors) != len(result.Issues) || !strings.HasPrefix(result.Errors[0], "page 2: ") {
+		t.Errorf("Errors = %v, want one legacy entry per issue", result.Errors)
+	}
+}
+
 func TestExtractionService_ExtractSemantic(t *testing.T) {
 	service := NewExtractionService(100 * 1024 * 1024)
 
@@ -230,7 +510,7 @@ func TestExtractionService_ExtractSemantic(t *testing.T) {
 		},
 	}
 
-	result, err := service.ExtractSemantic(req)
+	result, err := service.ExtractSemantic(context.Background(), req)
 	if err != nil {
 		t.Errorf("ExtractSemantic() unexpected error = %v", err)
 		return
@@ -253,7 +533,7 @@ func TestExtractionService_ExtractComplete(t *testing.T) {
 		Path: createTempFile(t, "test.pdf", generateMinimalPDFContent()),
 	}
 
-	result, err := service.ExtractComplete(req)
+	result, err := service.ExtractComplete(context.Background(), req)
 	if err != nil {
 		t.Errorf("ExtractComplete() unexpected error = %v", err)
 		return
@@ -303,7 +583,7 @@ func TestExtractionService_QueryContent(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := service.QueryContent(tt.req)
+			result, err := service.QueryContent(context.Background(), tt.req)
 
 			if tt.wantError {
 				if err == nil {
@@ -581,3 +861,74 @@ func abs(x float64) float64 {
 	}
 	return x
 }
+
+// buildTestPDF assembles a well-formed PDF whose pages use the given content streams and
+// a Helvetica font resource named F1
+func buildTestPDF(pageContents ...string) string {
+	return buildCatalogPDF("<< /Type /Catalog /Pages 2 0 R >>", pageContents...)
+}
+
+// buildCatalogPDF is buildTestPDF with the given document catalog, whose page tree is 2 0 R
+func buildCatalogPDF(catalog string, pageContents ...string) string {
+	numPages := len(pageContents)
+	objects := []string{
+		catalog,
+		"", // Pages tree, filled in below
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+
+	kids := make([]string, 0, numPages)
+	for i, content := range pageContents {
+		pageID := 4 + i*2
+		contentID := pageID + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] "+
+				"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", contentID),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), numPages)
+
+	return buildRawPDF(objects)
+}
+
+// buildRawPDF serializes objects numbered from 1 with a valid cross-reference table;
+// object 1 must be the document catalog
+func buildRawPDF(objects []string) string {
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xrefOffset := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	return b.String()
+}
+
+// ruledTableContent draws a 3x2 grid of ruling lines with a word in each cell
+func ruledTableContent() string {
+	var b strings.Builder
+	b.WriteString("1 w\n")
+	for _, y := range []int{700, 680, 660, 640} {
+		fmt.Fprintf(&b, "100 %d m 300 %d l S\n", y, y)
+	}
+	for _, x := range []int{100, 200, 300} {
+		fmt.Fprintf(&b, "%d 640 m %d 700 l S\n", x, x)
+	}
+	cells := [][]string{{"Name", "Qty"}, {"Apple", "3"}, {"Pear", "5"}}
+	for r, row := range cells {
+		for c, word := range row {
+			fmt.Fprintf(&b, "BT /F1 10 Tf %d %d Td (%s) Tj ET\n", 105+c*100, 686-r*20, word)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,220 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// URL fetch defaults
+const (
+	// defaultFetchTimeout bounds a download when the caller's context has no deadline
+	defaultFetchTimeout = 60 * time.Second
+	// maxFetchRedirects is how many redirects a download follows
+	maxFetchRedirects = 5
+	// maxFetchNameLength bounds the part of a staged file's name taken from its URL
+	maxFetchNameLength = 80
+
+	fetchFilePerm = 0o600
+	fetchDirPerm  = 0o750
+)
+
+// fetchContentTypes are the Content-Type values a download may declare; servers often send
+// PDFs as generic binary data, so the file's %PDF- header is checked as well
+var fetchContentTypes = map[string]bool{
+	"application/pdf":          true,
+	"application/x-pdf":        true,
+	"application/octet-stream": true,
+	"binary/octet-stream":      true,
+}
+
+// URLFetcher downloads PDFs over HTTPS into a staging directory, so that the other tools can
+// read remote documents by their local path. Downloads are limited in size and time, must
+// declare a PDF or binary content type and start with a PDF header, and may not reach
+// loopback, private, or link-local addresses.
+type URLFetcher struct {
+	maxFileSize  int64
+	dir          string
+	client       *http.Client
+	allowPrivate bool // Whether addresses on the local network may be fetched; only tests allow it
+}
+
+// NewURLFetcher creates a new URL fetcher with the specified constraints
+func NewURLFetcher(maxFileSize int64) *URLFetcher {
+	f := &URLFetcher{maxFileSize: maxFileSize}
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: f.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would be dialed instead of the checked address
+	transport.DialContext = dialer.DialContext
+	f.client = &http.Client{Transport: transport, CheckRedirect: checkFetchRedirect}
+	return f
+}
+
+// SetDir sets the staging directory downloads are saved in
+func (f *URLFetcher) SetDir(dir string) {
+	f.dir = dir
+}
+
+// Fetch downloads a PDF into the staging directory and returns its local path. Fetching a URL
+// again replaces its earlier download.
+func (f *URLFetcher) Fetch(ctx context.Context, req PDFFetchURLRequest) (*PDFFetchURLResult, error) {
+	if f.dir == "" {
+		return nil, pdferrors.New(pdferrors.UnsupportedFeature,
+			"fetching URLs is not enabled: no staging directory is configured")
+	}
+	target, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if target.Scheme != "https" || target.Host == "" {
+		return nil, fmt.Errorf("only https URLs can be fetched, got %q", req.URL)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultFetchTimeout)
+		defer cancel()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	request.Header.Set("Accept", "application/pdf")
+	response, err := f.client.Do(request)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, pdferrors.New(pdferrors.Timeout, "download of %s timed out", req.URL)
+		}
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: HTTP %d", response.StatusCode)
+	}
+	contentType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if contentType != "" && !fetchContentTypes[contentType] {
+		return nil, fmt.Errorf("URL did not return a PDF: content type %s", contentType)
+	}
+	if response.ContentLength > f.maxFileSize {
+		return nil, pdferrors.New(pdferrors.TooLarge, "file too large: %d bytes (max: %d bytes)",
+			response.ContentLength, f.maxFileSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, f.maxFileSize+1))
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, pdferrors.New(pdferrors.Timeout, "download of %s timed out", req.URL)
+		}
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	if int64(len(data)) > f.maxFileSize {
+		return nil, pdferrors.New(pdferrors.TooLarge, "file too large (max: %d bytes)", f.maxFileSize)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, pdferrors.New(pdferrors.Corrupt, "downloaded file is not a PDF")
+	}
+
+	output, err := f.save(target, response.Request.URL, data)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return &PDFFetchURLResult{
+		URL:         req.URL,
+		FinalURL:    response.Request.URL.String(),
+		Path:        output,
+		Size:        int64(len(data)),
+		ContentType: contentType,
+		SHA256:      hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// save writes a download to the staging directory through a temporary file, so that other
+// tools never read a partial document. The file is named after the document's final URL,
+// behind a hash of the requested URL that keeps the downloads of different URLs apart.
+func (f *URLFetcher) save(requested, source *url.URL, data []byte) (string, error) {
+	if err := fsys.MkdirAll(f.dir, fetchDirPerm); err != nil {
+		return "", fmt.Errorf("cannot create staging directory %s: %w", f.dir, err)
+	}
+	sum := sha256.Sum256([]byte(requested.String()))
+	output := filepath.Join(f.dir, hex.EncodeToString(sum[:6])+"-"+fetchFileName(source))
+
+	tmp, err := fsys.WriteTemp(f.dir, ".fetch-*.part", data, fetchFilePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to write download: %w", err)
+	}
+	defer fsys.Remove(tmp)
+	if err := fsys.Rename(tmp, output); err != nil {
+		return "", fmt.Errorf("failed to store download: %w", err)
+	}
+	return output, nil
+}
+
+// fetchFileName derives a safe file name ending in .pdf from the last segment of a URL's path
+func fetchFileName(source *url.URL) string {
+	name := strings.TrimSuffix(path.Base(source.Path), path.Ext(source.Path))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	name = strings.Trim(name, "_")
+	if len(name) > maxFetchNameLength {
+		name = name[:maxFetchNameLength]
+	}
+	if name == "" {
+		name = "document"
+	}
+	return name + ".pdf"
+}
+
+// checkFetchRedirect follows a bounded number of redirects, and only to https URLs
+func checkFetchRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxFetchRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxFetchRedirects)
+	}
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing redirect to %s: only https URLs can be fetched", req.URL)
+	}
+	return nil
+}
+
+// checkAddress refuses connections to loopback, private, link-local, and unspecified
+// addresses, so that fetched URLs cannot reach services on the server's own network. It runs
+// on the resolved address of every connection, redirects included.
+func (f *URLFetcher) checkAddress(_, address string, _ syscall.RawConn) error {
+	if f.allowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("refusing to connect to %s: local network addresses cannot be fetched", host)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package pdf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFetcher returns a URL fetcher that trusts server's certificate and may reach it on
+// the loopback address
+func testFetcher(t *testing.T, server *httptest.Server, maxFileSize int64) *URLFetcher {
+	t.Helper()
+	f := NewURLFetcher(maxFileSize)
+	f.SetDir(t.TempDir())
+	f.allowPrivate = true
+	transport := f.client.Transport.(*http.Transport)
+	transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+	return f
+}
+
+func TestURLFetcher_Fetch(t *testing.T) {
+	content := buildTestPDF("BT /F1 12 Tf 72 720 Td (Remote report) Tj ET")
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/docs/Q3 report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte(content))
+		case "/latest":
+			http.Redirect(w, r, "/docs/Q3%20report.pdf", http.StatusFound)
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html></html>"))
+		case "/fake.pdf":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("not a pdf"))
+		case "/slow.pdf":
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte(content))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	f := testFetcher(t, server, 1024*1024)
+	result, err := f.Fetch(context.Background(), PDFFetchURLRequest{URL: server.URL + "/latest"})
+	if err != nil {
+		t.Fatalf("Fetch() unexpected error = %v", err)
+	}
+	if !strings.HasSuffix(result.Path, "-Q3_report.pdf") || result.FinalURL != server.URL+"/docs/Q3%20report.pdf" {
+		t.Errorf("Fetch() = %+v, want the redirected document saved as Q3_report.pdf", result)
+	}
+	if result.Size != int64(len(content)) || result.ContentType != "application/pdf" || len(result.SHA256) != 64 {
+		t.Errorf("Fetch() = %+v", result)
+	}
+	if data, err := os.ReadFile(result.Path); err != nil || string(data) != content {
+		t.Errorf("saved download = %q, %v", data, err)
+	}
+	again, err := f.Fetch(context.Background(), PDFFetchURLRequest{URL: server.URL + "/latest"})
+	if err != nil || again.Path != result.Path {
+		t.Errorf("Fetch() again = %+v, %v, want the same path", again, err)
+	}
+
+	timedOut, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := f.Fetch(timedOut, PDFFetchURLRequest{URL: server.URL + "/slow.pdf"}); err == nil ||
+		!strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Fetch() of a slow download error = %v, want a timeout", err)
+	}
+
+	tests := []struct {
+		name     string
+		fetcher  *URLFetcher
+		url      string
+		errorMsg string
+	}{
+		{name: "http", fetcher: f, url: "http://example.com/a.pdf", errorMsg: "only https URLs"},
+		{name: "not found", fetcher: f, url: server.URL + "/missing.pdf", errorMsg: "HTTP 404"},
+		{name: "html", fetcher: f, url: server.URL + "/page.html", errorMsg: "content type text/html"},
+		{name: "not a pdf", fetcher: f, url: server.URL + "/fake.pdf", errorMsg: "not a PDF"},
+		{name: "too large", fetcher: testFetcher(t, server, 64), url: server.URL + "/latest", errorMsg: "too large"},
+		{name: "local network", fetcher: NewURLFetcher(1024), url: server.URL + "/latest", errorMsg: "local network"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.fetcher.dir == "" {
+				tt.fetcher.SetDir(t.TempDir())
+			}
+			_, err := tt.fetcher.Fetch(context.Background(), PDFFetchURLRequest{URL: tt.url})
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Fetch() error = %v, want it to contain %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestFetchFileName(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/files/Annual%20Report%202024.PDF": "Annual_Report_2024.pdf",
+		"https://example.com/download?id=7":                    "download.pdf",
+		"https://example.com/":                                 "document.pdf",
+		"https://example.com/../../etc/passwd":                 "passwd.pdf",
+	}
+	for raw, want := range tests {
+		source, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fetchFileName(source); got != want {
+			t.Errorf("fetchFileName(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package pdf
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// defaultFindTextResults is the number of occurrences returned when MaxResults is unset
+const defaultFindTextResults = 500
+
+// TextFinder locates phrases on the page with their positions
+type TextFinder struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewTextFinder creates a new phrase finder with the specified constraints
+func NewTextFinder(maxFileSize int64) *TextFinder {
+	return &TextFinder{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// FindText returns every occurrence of a phrase with its page and bounding box. Whitespace
+// in the phrase matches any spacing between words, including line breaks, so a phrase
+// wrapping onto the next line is found with one rectangle per line. With a region only the
+// occurrences centered inside it are returned.
+func (t *TextFinder) FindText(req PDFFindTextRequest) (result *PDFFindTextResult, err error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	words := strings.Fields(req.Query)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	if err := validateRegion(req.Region); err != nil {
+		return nil, err
+	}
+
+	maxResults := req.MaxResults
+	if maxResults <= 0 {
+		maxResults = defaultFindTextResults
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: t.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	pattern := findTextPattern(words, req.CaseSensitive, req.WholeWord)
+	result = &PDFFindTextResult{
+		Path:          req.Path,
+		Query:         strings.Join(words, " "),
+		CaseSensitive: req.CaseSensitive,
+		WholeWord:     req.WholeWord,
+		Matches:       []TextOccurrence{},
+		Pages:         []int{},
+	}
+
+	numbering := extraction.NewPageNumbering(r)
+	region := regionBounds(req.Region)
+	pages := req.Pages
+	if len(pages) == 0 {
+		for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+			pages = append(pages, pageNum)
+		}
+	}
+
+	for _, pageNum := range pages {
+		if pageNum < 1 || pageNum > numbering.Count() {
+			return nil, pageOutOfRange(pageNum, numbering.Count())
+		}
+
+		text, err := pageText(numbering, pageNum)
+		if err != nil {
+			result.FailedPages = append(result.FailedPages, pageNum)
+			continue
+		}
+
+		matches := text.Find(pattern)
+		if region != nil {
+			matches = slices.DeleteFunc(matches, func(match extraction.TextMatch) bool {
+				return !extraction.CenteredIn(match.BoundingBox, *region)
+			})
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		result.Pages = append(result.Pages, pageNum)
+		result.TotalMatches += len(matches)
+		for _, match := range matches {
+			if len(result.Matches) == maxResults {
+				result.Truncated = true
+				break
+			}
+			occurrence := TextOccurrence{
+				Page:        pageNum,
+				PageLabel:   numbering.Label(pageNum),
+				Text:        match.Text,
+				BoundingBox: convertBoundingBox(match.BoundingBox),
+				Context:     contentSnippet(text.Text, match.Start, match.End),
+			}
+			for _, rect := range match.Rects {
+				occurrence.Rects = append(occurrence.Rects, convertBoundingBox(rect))
+			}
+			result.Matches = append(result.Matches, occurrence)
+		}
+	}
+
+	return result, nil
+}
+
+// findTextPattern builds a pattern matching the words separated by any whitespace
+func findTextPattern(words []string, caseSensitive, wholeWord bool) *regexp.Regexp {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	expr := strings.Join(quoted, `\s+`)
+	if wholeWord {
+		expr = `\b` + expr + `\b`
+	}
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+	return regexp.MustCompile(expr)
+}
+
+// pageText lays out one page for searching; the parser panics on malformed pages
+func pageText(numbering *extraction.PageNumbering, pageNum int) (text *extraction.PageText, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = pdferrors.New(pdferrors.Corrupt, "failed to read page %d: %v", pageNum, rec)
+		}
+	}()
+
+	page := numbering.Page(pageNum)
+	if page.V.IsNull() {
+		return nil, pageOutOfRange(pageNum, numbering.Count())
+	}
+	return extraction.NewPageText(page)
+}
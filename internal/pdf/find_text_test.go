@@ -0,0 +1,88 @@
+package pdf
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTextFinder_FindText(t *testing.T) {
+	finder := NewTextFinder(100 * 1024 * 1024)
+	path := createTempFile(t, "fox.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (The quick brown fox) Tj ET\n"+
+			"BT /F1 12 Tf 72 700 Td (jumps over the quick) Tj ET\n"+
+			"BT /F1 12 Tf 72 680 Td (brown dog) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (No foxes here) Tj ET",
+	))
+
+	result, err := finder.FindText(PDFFindTextRequest{Path: path, Query: "Quick  brown"})
+	if err != nil {
+		t.Fatalf("FindText() unexpected error = %v", err)
+	}
+	if result.TotalMatches != 2 || len(result.Matches) != 2 || len(result.Pages) != 1 || result.Pages[0] != 1 {
+		t.Fatalf("FindText() = %d matches on pages %v, want 2 on page 1", result.TotalMatches, result.Pages)
+	}
+
+	first := result.Matches[0]
+	if first.Text != "quick brown" || len(first.Rects) != 1 {
+		t.Errorf("first match = %q with %d rects, want \"quick brown\" on one line", first.Text, len(first.Rects))
+	}
+	// The baseline is at 720 and the box starts after "The "
+	if first.BoundingBox.X <= 72 || math.Abs(first.BoundingBox.Y+first.BoundingBox.Height*0.2-720) > 0.5 {
+		t.Errorf("first match box = %+v, want it after x=72 on the y=720 baseline", first.BoundingBox)
+	}
+	if !strings.Contains(first.Context, "The quick brown fox") {
+		t.Errorf("first match context = %q, want the surrounding line", first.Context)
+	}
+
+	// The second occurrence wraps from the second line onto the third
+	second := result.Matches[1]
+	if len(second.Rects) != 2 || second.Rects[0].Y <= second.Rects[1].Y {
+		t.Fatalf("second match rects = %+v, want two lines top to bottom", second.Rects)
+	}
+	if second.BoundingBox.Height <= second.Rects[0].Height {
+		t.Errorf("second match box = %+v, want it to cover both lines", second.BoundingBox)
+	}
+
+	tests := []struct {
+		name string
+		req  PDFFindTextRequest
+		want int
+	}{
+		{"case sensitive", PDFFindTextRequest{Query: "The", CaseSensitive: true}, 1},
+		{"substring", PDFFindTextRequest{Query: "fox"}, 2},
+		{"whole word", PDFFindTextRequest{Query: "fox", WholeWord: true}, 1},
+		{"selected pages", PDFFindTextRequest{Query: "fox", Pages: []int{2}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Path = path
+			result, err := finder.FindText(tt.req)
+			if err != nil {
+				t.Fatalf("FindText() unexpected error = %v", err)
+			}
+			if result.TotalMatches != tt.want {
+				t.Errorf("FindText() = %d matches, want %d", result.TotalMatches, tt.want)
+			}
+		})
+	}
+
+	limited, err := finder.FindText(PDFFindTextRequest{Path: path, Query: "o", MaxResults: 2})
+	if err != nil {
+		t.Fatalf("FindText() unexpected error = %v", err)
+	}
+	if len(limited.Matches) != 2 || !limited.Truncated || limited.TotalMatches <= 2 {
+		t.Errorf("FindText() = %d of %d matches (truncated %v), want 2 of more", len(limited.Matches),
+			limited.TotalMatches, limited.Truncated)
+	}
+
+	for _, req := range []PDFFindTextRequest{
+		{Path: path, Query: "  "},
+		{Path: path, Query: "fox", Pages: []int{3}},
+		{Path: "/non/existent/file.pdf", Query: "fox"},
+	} {
+		if _, err := finder.FindText(req); err == nil {
+			t.Errorf("FindText(%+v) expected error", req)
+		}
+	}
+}
@@ -0,0 +1,507 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+	"github.com/ledongthuc/pdf"
+)
+
+// filledMIMEType is the MIME type of filled documents
+const filledMIMEType = "application/pdf"
+
+// formDataExtensions are the file extensions of each form data format
+var formDataExtensions = map[string]string{
+	".json": FormDataJSON,
+	".fdf":  FormDataFDF,
+	".xfdf": FormDataXFDF,
+}
+
+// FormData exports the values of a document's form fields and fills them from form data
+type FormData struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewFormData creates a new form data handler with the specified constraints
+func NewFormData(maxFileSize int64) *FormData {
+	return &FormData{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// Export lists the fields of a document's interactive form with their values, and writes
+// the values as form data. Push buttons, signatures, groups, and fields marked not to be
+// exported are listed but left out of the data.
+func (d *FormData) Export(req PDFExportFormDataRequest) (*PDFExportFormDataResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	format, err := validFormDataFormat(req.Format, FormDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: d.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	reader := doc.Reader
+
+	result := &PDFExportFormDataResult{
+		Path:   req.Path,
+		Format: format,
+		Fields: []FormFieldValue{},
+	}
+	sigFlags := extraction.SigFlags(reader)
+	result.SignaturesExist = sigFlags&extraction.SigFlagSignaturesExist != 0
+	result.AppendOnly = sigFlags&extraction.SigFlagAppendOnly != 0
+	result.CalculationOrder = extraction.CalculationOrder(reader)
+
+	var exported []FormFieldValue
+	readFields := extraction.ReadFormFieldTree
+	if req.TerminalOnly {
+		readFields = extraction.ReadFormFields
+	}
+	fields := readFields(reader)
+	locks := extraction.SignatureLocks(fields)
+	for _, field := range fields {
+		value := FormFieldValue{
+			Name:     field.Name,
+			Type:     field.Type,
+			Parent:   field.Parent,
+			Kids:     field.Kids,
+			Value:    field.Value,
+			Values:   field.Values,
+			Options:  field.Options,
+			ReadOnly: field.Flags&extraction.FieldFlagReadOnly != 0,
+
+			Checked:     field.Checked,
+			ExportValue: field.ExportValue,
+			Buttons:     convertButtons(field.Buttons, field.Pages),
+			Signature:   convertSignature(field.Signature),
+			LockedBy:    locks[field.Name],
+			Actions:     field.Actions,
+			Calculated:  field.Calculated,
+
+			Multiline:       field.Multiline,
+			Password:        field.Password,
+			DoNotSpellCheck: field.DoNotSpellCheck,
+			CombCells:       field.CombCells,
+			RichText:        convertRichText(field.RichText),
+
+			Choices:     convertChoices(field.Choices),
+			Combo:       field.Combo,
+			Editable:    field.Editable,
+			Sorted:      field.Sorted,
+			MultiSelect: field.MultiSelect,
+		}
+		if len(field.Pages) > 0 {
+			value.Page = field.Pages[0]
+		}
+		result.Fields = append(result.Fields, value)
+		if field.HasValue() && field.Flags&extraction.FieldFlagNoExport == 0 {
+			exported = append(exported, value)
+		}
+	}
+	result.Exported = len(exported)
+	for _, page := range extraction.FormTabOrder(reader, fields) {
+		result.Pages = append(result.Pages, FormPage(page))
+	}
+
+	data, err := encodeFormData(format, exported, filepath.Base(req.Path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode form data: %w", err)
+	}
+
+	if req.OutputPath == "" {
+		result.Content = string(data)
+		return result, nil
+	}
+	if err := fsys.MkdirAll(filepath.Dir(req.OutputPath), attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", filepath.Dir(req.OutputPath), err)
+	}
+	if err := fsys.WriteFile(req.OutputPath, data, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save form data: %w", err)
+	}
+	result.OutputPath = req.OutputPath
+	return result, nil
+}
+
+// Import writes a copy of the document with its form fields filled from form data. Values
+// are matched to fields by fully qualified name. Checkboxes accept their on state or
+// true/false, radio buttons one of their states, buttons also an export value, and choice
+// fields one of their options, by export value or the text it shows, unless they are
+// editable. Filled text and choice fields drop their appearance and the
+// form asks viewers to redraw them, so the new values show; an XFA form is removed so
+// viewers show the AcroForm fields that hold them. Signed documents are refused, since the
+// filled copy is rewritten rather than appended to and would invalidate their signatures.
+func (d *FormData) Import(ctx context.Context, req PDFImportFormDataRequest) (*PDFImportFormDataResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	if (req.Data == "") == (req.DataPath == "") {
+		return nil, fmt.Errorf("give the form data in exactly one of data or data_path")
+	}
+	format, err := validFormDataFormat(req.Format, "")
+	if err != nil {
+		return nil, err
+	}
+
+	data := []byte(req.Data)
+	if req.DataPath != "" {
+		if data, err = d.readFormData(req.DataPath); err != nil {
+			return nil, err
+		}
+		if format == "" {
+			format = formDataExtensions[strings.ToLower(filepath.Ext(req.DataPath))]
+		}
+	}
+	if format == "" {
+		if format, err = detectFormDataFormat(data); err != nil {
+			return nil, err
+		}
+	}
+	values, err := decodeFormData(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: d.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	reader := doc.Reader
+
+	if !reader.Trailer().Key("Encrypt").IsNull() {
+		return nil, fmt.Errorf("cannot fill encrypted documents")
+	}
+	fields := extraction.ReadFormFields(reader)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("document has no form fields")
+	}
+	// The filled copy is rewritten rather than appended to, which would break signatures
+	for _, field := range fields {
+		if field.Signature != nil && field.Signature.Signed {
+			return nil, fmt.Errorf("cannot fill signed documents: filling would invalidate signature %s", field.Name)
+		}
+	}
+	byName := make(map[string]extraction.FormField, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	result := &PDFImportFormDataResult{
+		Path:     req.Path,
+		Format:   format,
+		MIMEType: filledMIMEType,
+		Filled:   []FormFieldValue{},
+	}
+	replacements := make(map[extraction.ObjectRef]map[string]string)
+	replace := func(v pdf.Value, key, value string) {
+		ref := extraction.RefOf(v)
+		if replacements[ref] == nil {
+			replacements[ref] = make(map[string]string)
+		}
+		replacements[ref][key] = value
+	}
+	for _, value := range values {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		field, ok := byName[value.Name]
+		if !ok {
+			result.Unmatched = append(result.Unmatched, value.Name)
+			continue
+		}
+		filled, reason := fillValue(field, value.Values)
+		if reason != "" {
+			result.Skipped = append(result.Skipped, SkippedFormValue{Name: value.Name, Reason: reason})
+			continue
+		}
+
+		replace(field.Field, "V", encodeFieldValue(field.Type, filled))
+		switch field.Type {
+		case extraction.FieldTypeCheckbox, extraction.FieldTypeRadio:
+			// Each widget shows the chosen state if it has an appearance for it
+			for _, widget := range field.Widgets {
+				state := "Off"
+				if !widget.Key("AP").Key("N").Key(filled.Value).IsNull() {
+					state = filled.Value
+				}
+				var name bytes.Buffer
+				writeName(&name, state)
+				replace(widget, "AS", name.String())
+			}
+		case extraction.FieldTypeChoice:
+			replace(field.Field, "I", "")
+			fallthrough
+		default:
+			for _, widget := range field.Widgets {
+				replace(widget, "AP", "")
+			}
+		}
+		result.Filled = append(result.Filled, filled)
+	}
+	if len(result.Filled) == 0 {
+		return nil, fmt.Errorf("nothing to fill: no values matched a fillable field (%d unmatched, %d skipped)",
+			len(result.Unmatched), len(result.Skipped))
+	}
+
+	writer := newDocumentWriter(doc)
+	for ref, entries := range replacements {
+		writer.edits[ref] = replaceEdit(entries)
+	}
+	root := reader.Trailer().Key("Root")
+	acroForm := root.Key("AcroForm")
+	formEntries := map[string]string{"NeedAppearances": "true", "XFA": ""}
+	if ref := extraction.RefOf(acroForm); ref != extraction.RefOf(root) {
+		writer.edits[ref] = replaceEdit(formEntries)
+	} else {
+		// The form is stored in the catalog, so the catalog is rewritten with it
+		writer.edits[ref] = func(w *documentWriter, b *bytes.Buffer, v pdf.Value) error {
+			var form bytes.Buffer
+			if err := w.dict(&form, acroForm, formEntries, 1); err != nil {
+				return err
+			}
+			return w.dict(b, v, map[string]string{"AcroForm": form.String()}, 0)
+		}
+	}
+
+	output, err := writer.write(reader.Trailer())
+	if err != nil {
+		return nil, fmt.Errorf("failed to write filled document: %w", err)
+	}
+	result.Size = len(output)
+
+	if req.OutputDir == "" {
+		result.Data = base64.StdEncoding.EncodeToString(output)
+		return result, nil
+	}
+
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+	stem := strings.TrimSuffix(filepath.Base(req.Path), filepath.Ext(req.Path))
+	result.OutputPath = filepath.Join(req.OutputDir, stem+"-filled.pdf")
+	if err := fsys.WriteFile(result.OutputPath, output, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save filled document: %w", err)
+	}
+
+	return result, nil
+}
+
+// readFormData reads a form data file, which is held to the document size limit
+func (d *FormData) readFormData(path string) ([]byte, error) {
+	fileInfo, err := fsys.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("form data file does not exist: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot access form data file: %w", err)
+	}
+	if fileInfo.Size() > d.maxFileSize {
+		return nil, fmt.Errorf("form data file too large: %d bytes (max: %d bytes)", fileInfo.Size(), d.maxFileSize)
+	}
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read form data file: %w", err)
+	}
+	return data, nil
+}
+
+// fillValue checks values given for a field, returning the field's new value or the reason
+// they cannot fill it
+func fillValue(field extraction.FormField, values []string) (FormFieldValue, string) {
+	filled := FormFieldValue{Name: field.Name, Type: field.Type, Options: field.Options}
+	if !field.HasValue() {
+		return filled, fmt.Sprintf("%s fields hold no value", field.Type)
+	}
+	if field.Flags&extraction.FieldFlagReadOnly != 0 {
+		return filled, "field is read-only"
+	}
+	if len(values) > 1 && (field.Type != extraction.FieldTypeChoice || field.Flags&extraction.FieldFlagMultiSelect == 0) {
+		return filled, fmt.Sprintf("%d values given for a field holding one", len(values))
+	}
+	if field.Type == extraction.FieldTypeChoice {
+		// An option may be named by the text it shows instead of its export value
+		values = slices.Clone(values)
+		for i, v := range values {
+			values[i] = choiceExportValue(field, v)
+		}
+	}
+	value := ""
+	if len(values) > 0 {
+		value = values[0]
+	}
+
+	switch field.Type {
+	case extraction.FieldTypeCheckbox, extraction.FieldTypeRadio:
+		switch {
+		case value == "Off" || slices.Contains(field.Options, value):
+		case buttonState(field, value) != "":
+			value = buttonState(field, value)
+		case isFormFalse(value):
+			value = "Off"
+		case isFormTrue(value) && len(field.Options) == 1:
+			value = field.Options[0]
+		default:
+			return filled, fmt.Sprintf("%q is not one of the states %s", value,
+				strings.Join(append(slices.Clone(field.Options), "Off"), ", "))
+		}
+	case extraction.FieldTypeChoice:
+		if len(field.Options) > 0 && field.Flags&extraction.FieldFlagEdit == 0 {
+			for _, v := range values {
+				if !slices.Contains(field.Options, v) {
+					return filled, fmt.Sprintf("%q is not one of the options", v)
+				}
+			}
+		}
+		if len(values) > 1 {
+			filled.Values = values
+		}
+	}
+	filled.Value = value
+	return filled, ""
+}
+
+// choiceExportValue returns the export value of the choice option showing value, or value
+// itself when it is an export value or no option shows it
+func choiceExportValue(field extraction.FormField, value string) string {
+	if slices.Contains(field.Options, value) {
+		return value
+	}
+	for _, choice := range field.Choices {
+		if choice.Display == value {
+			return choice.ExportValue
+		}
+	}
+	return value
+}
+
+// buttonState returns the on state of the widget whose export value is value, or an empty
+// string when no widget has it
+func buttonState(field extraction.FormField, value string) string {
+	for _, button := range field.Buttons {
+		if button.ExportValue == value && button.State != "" {
+			return button.State
+		}
+	}
+	return ""
+}
+
+// convertButtons maps the widgets of a checkbox or radio button, on the given pages, to the
+// public type
+func convertButtons(buttons []extraction.ButtonWidget, pages []int) []FormButton {
+	if len(buttons) == 0 {
+		return nil
+	}
+	converted := make([]FormButton, len(buttons))
+	for i, button := range buttons {
+		converted[i] = FormButton{State: button.State, ExportValue: button.ExportValue, Checked: button.Checked}
+		if i < len(pages) {
+			converted[i].Page = pages[i]
+		}
+	}
+	return converted
+}
+
+// convertSignature maps the state of a signature field to the public type
+func convertSignature(sig *extraction.SignatureInfo) *FormSignature {
+	if sig == nil {
+		return nil
+	}
+	converted := &FormSignature{
+		Signed:      sig.Signed,
+		Signer:      sig.Signer,
+		Reason:      sig.Reason,
+		Location:    sig.Location,
+		ContactInfo: sig.ContactInfo,
+		Filter:      sig.Filter,
+		SubFilter:   sig.SubFilter,
+		LockAction:  sig.LockAction,
+		LockFields:  sig.LockFields,
+		Certifies:   sig.Certifies,
+		Permissions: sig.Permissions,
+	}
+	if !sig.SigningTime.IsZero() {
+		converted.SigningTime = sig.SigningTime.Format(time.RFC3339)
+	}
+	return converted
+}
+
+// convertRichText converts the styled runs of a rich text field
+func convertRichText(spans []extraction.RichTextSpan) []FormTextSpan {
+	if len(spans) == 0 {
+		return nil
+	}
+	converted := make([]FormTextSpan, len(spans))
+	for i, span := range spans {
+		converted[i] = FormTextSpan(span)
+	}
+	return converted
+}
+
+// convertChoices converts the options of a choice field
+func convertChoices(choices []extraction.ChoiceOption) []FormChoice {
+	if len(choices) == 0 {
+		return nil
+	}
+	converted := make([]FormChoice, len(choices))
+	for i, choice := range choices {
+		converted[i] = FormChoice(choice)
+	}
+	return converted
+}
+
+// isFormTrue and isFormFalse recognize the values data may give a checkbox for on and off
+func isFormTrue(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1", "x":
+		return true
+	}
+	return false
+}
+
+func isFormFalse(value string) bool {
+	switch strings.ToLower(value) {
+	case "", "false", "no", "off", "0":
+		return true
+	}
+	return false
+}
+
+// encodeFieldValue writes a field's value: a name for a button state, an array for
+// several selections, and a text string otherwise
+func encodeFieldValue(fieldType string, value FormFieldValue) string {
+	var b bytes.Buffer
+	switch {
+	case fieldType == extraction.FieldTypeCheckbox || fieldType == extraction.FieldTypeRadio:
+		writeName(&b, value.Value)
+	case len(value.Values) > 0:
+		b.WriteByte('[')
+		for i, v := range value.Values {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			writeTextString(&b, v)
+		}
+		b.WriteByte(']')
+	default:
+		writeTextString(&b, value.Value)
+	}
+	return b.String()
+}
@@ -0,0 +1,287 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// xfdfNamespace is the XML namespace of XFDF documents
+const xfdfNamespace = "http://ns.adobe.com/xfdf/"
+
+// xfdfDocument is an XFDF document. Its element name is checked when reading, so files
+// without the namespace are accepted too.
+type xfdfDocument struct {
+	XMLName xml.Name
+	File    *xfdfFile   `xml:"f,omitempty"`
+	Fields  []xfdfField `xml:"fields>field"`
+}
+
+// xfdfFile names the document the form data belongs to
+type xfdfFile struct {
+	Href string `xml:"href,attr"`
+}
+
+// xfdfField is a field of an XFDF document, named by its partial name
+type xfdfField struct {
+	Name   string      `xml:"name,attr"`
+	Values []string    `xml:"value"`
+	Fields []xfdfField `xml:"field"`
+}
+
+// formDataNode is a node of the field name tree written to FDF and XFDF files
+type formDataNode struct {
+	name  string
+	field *FormFieldValue // The field named by the path to this node, if any
+	kids  []*formDataNode
+}
+
+// validFormDataFormat checks a form data format, returning the default for an empty one
+func validFormDataFormat(format, fallback string) (string, error) {
+	switch format {
+	case "":
+		return fallback, nil
+	case FormDataJSON, FormDataFDF, FormDataXFDF:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid format: %q (must be %s, %s, or %s)", format, FormDataJSON, FormDataFDF, FormDataXFDF)
+}
+
+// detectFormDataFormat recognizes FDF by its header, XFDF by its markup, and JSON by its
+// opening brace
+func detectFormDataFormat(data []byte) (string, error) {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("%FDF")):
+		return FormDataFDF, nil
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormDataXFDF, nil
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormDataJSON, nil
+	}
+	return "", fmt.Errorf("cannot detect the form data format: give format as %s, %s, or %s",
+		FormDataJSON, FormDataFDF, FormDataXFDF)
+}
+
+// fieldValues returns the values written for a field: its selections, or its one value
+func fieldValues(field FormFieldValue) []string {
+	if len(field.Values) > 0 {
+		return field.Values
+	}
+	return []string{field.Value}
+}
+
+// encodeFormData writes fields as form data for the document at source
+func encodeFormData(format string, fields []FormFieldValue, source string) ([]byte, error) {
+	switch format {
+	case FormDataFDF:
+		return encodeFDF(formDataTree(fields), source), nil
+	case FormDataXFDF:
+		return encodeXFDF(formDataTree(fields), source)
+	default:
+		values := make(map[string]any, len(fields))
+		for _, field := range fields {
+			if len(field.Values) > 0 {
+				values[field.Name] = field.Values
+			} else {
+				values[field.Name] = field.Value
+			}
+		}
+		data, err := json.MarshalIndent(values, "", "  ")
+		return append(data, '\n'), err
+	}
+}
+
+// formDataTree arranges fields by the partial names of their fully qualified names,
+// keeping the fields' order
+func formDataTree(fields []FormFieldValue) []*formDataNode {
+	root := &formDataNode{}
+	for i := range fields {
+		node := root
+		for _, partial := range strings.Split(fields[i].Name, ".") {
+			var next *formDataNode
+			for _, kid := range node.kids {
+				if kid.name == partial {
+					next = kid
+					break
+				}
+			}
+			if next == nil {
+				next = &formDataNode{name: partial}
+				node.kids = append(node.kids, next)
+			}
+			node = next
+		}
+		node.field = &fields[i]
+	}
+	return root.kids
+}
+
+// encodeFDF writes a field tree as an FDF file, with values written as in the document
+func encodeFDF(nodes []*formDataNode, source string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%FDF-1.2\n%\xe2\xe3\xcf\xd3\n1 0 obj\n<</FDF <</F ")
+	writeTextString(&b, source)
+	b.WriteString(" /Fields [")
+	var write func(nodes []*formDataNode)
+	write = func(nodes []*formDataNode) {
+		for _, node := range nodes {
+			b.WriteString("\n<</T ")
+			writeTextString(&b, node.name)
+			if field := node.field; field != nil {
+				b.WriteString(" /V " + encodeFieldValue(field.Type, *field))
+			}
+			if len(node.kids) > 0 {
+				b.WriteString(" /Kids [")
+				write(node.kids)
+				b.WriteByte(']')
+			}
+			b.WriteString(">>")
+		}
+	}
+	write(nodes)
+	b.WriteString("]>>>>\nendobj\ntrailer\n<</Root 1 0 R>>\n%%EOF\n")
+	return b.Bytes()
+}
+
+// encodeXFDF writes a field tree as an XFDF document
+func encodeXFDF(nodes []*formDataNode, source string) ([]byte, error) {
+	var convert func(nodes []*formDataNode) []xfdfField
+	convert = func(nodes []*formDataNode) []xfdfField {
+		fields := make([]xfdfField, 0, len(nodes))
+		for _, node := range nodes {
+			field := xfdfField{Name: node.name, Fields: convert(node.kids)}
+			if node.field != nil {
+				field.Values = fieldValues(*node.field)
+			}
+			fields = append(fields, field)
+		}
+		return fields
+	}
+
+	doc := xfdfDocument{
+		XMLName: xml.Name{Space: xfdfNamespace, Local: "xfdf"},
+		File:    &xfdfFile{Href: source},
+		Fields:  convert(nodes),
+	}
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// decodeFormData reads the field values of form data
+func decodeFormData(format string, data []byte) ([]extraction.FormValue, error) {
+	switch format {
+	case FormDataFDF:
+		return extraction.ParseFDF(data)
+	case FormDataXFDF:
+		return decodeXFDF(data)
+	default:
+		return decodeFormJSON(data)
+	}
+}
+
+// decodeXFDF reads the field values of an XFDF document
+func decodeXFDF(data []byte) ([]extraction.FormValue, error) {
+	var doc xfdfDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid XFDF: %w", err)
+	}
+	if doc.XMLName.Local != "xfdf" {
+		return nil, fmt.Errorf("invalid XFDF: root element is %s, not xfdf", doc.XMLName.Local)
+	}
+
+	var values []extraction.FormValue
+	var walk func(fields []xfdfField, parentName string)
+	walk = func(fields []xfdfField, parentName string) {
+		for _, field := range fields {
+			name := field.Name
+			if parentName != "" {
+				name = parentName + "." + name
+			}
+			if len(field.Values) > 0 {
+				values = append(values, extraction.FormValue{Name: name, Values: field.Values})
+			}
+			walk(field.Fields, name)
+		}
+	}
+	walk(doc.Fields, "")
+	return values, nil
+}
+
+// decodeFormJSON reads the field values of a JSON object. Values are strings, numbers,
+// booleans, or arrays of them; nested objects name fields by their partial names.
+func decodeFormJSON(data []byte) ([]extraction.FormValue, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("invalid JSON form data: %w", err)
+	}
+
+	var values []extraction.FormValue
+	var walk func(object map[string]any, parentName string) error
+	walk = func(object map[string]any, parentName string) error {
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, partial := range names {
+			name := partial
+			if parentName != "" {
+				name = parentName + "." + partial
+			}
+			switch v := object[partial].(type) {
+			case map[string]any:
+				if err := walk(v, name); err != nil {
+					return err
+				}
+			case []any:
+				value := extraction.FormValue{Name: name, Values: []string{}}
+				for _, item := range v {
+					text, ok := jsonScalar(item)
+					if !ok {
+						return fmt.Errorf("invalid JSON form data: %s holds an array of %T", name, item)
+					}
+					value.Values = append(value.Values, text)
+				}
+				values = append(values, value)
+			default:
+				text, ok := jsonScalar(v)
+				if !ok {
+					return fmt.Errorf("invalid JSON form data: %s holds %T", name, v)
+				}
+				values = append(values, extraction.FormValue{Name: name, Values: []string{text}})
+			}
+		}
+		return nil
+	}
+	if err := walk(object, ""); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// jsonScalar returns the text of a JSON string, number, boolean, or null
+func jsonScalar(v any) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
@@ -0,0 +1,368 @@
+package pdf
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// formTestPDF builds a document with a text field, a checkbox, and a choice list whose
+// widget is a separate annotation. The form is stored in the catalog and has an XFA form.
+func formTestPDF(t *testing.T) string {
+	t.Helper()
+	return createTempFile(t, "form.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R 5 0 R 6 0 R] /XFA [(template) 8 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [4 0 R 5 0 R 7 0 R] >>",
+		"<< /FT /Tx /T (name) /V (Old) /Subtype /Widget /Rect [100 700 300 720] /P 3 0 R /AP << /N 8 0 R >> >>",
+		"<< /FT /Btn /T (agree) /V /Off /AS /Off /Subtype /Widget /Rect [100 660 112 672] /P 3 0 R " +
+			"/AP << /N << /Yes 8 0 R /Off 8 0 R >> >> >>",
+		"<< /FT /Ch /T (color) /Opt [(Red) (Green)] /V (Red) /I [0] /Kids [7 0 R] >>",
+		"<< /Subtype /Widget /Parent 6 0 R /Rect [100 620 200 640] /P 3 0 R >>",
+		"<< /Type /XObject /Subtype /Form /BBox [0 0 10 10] /Length 0 >>\nstream\n\nendstream",
+	}))
+}
+
+// filledFields reopens a filled document and returns its form fields by name and its form
+func filledFields(t *testing.T, result *PDFImportFormDataResult) (map[string]extraction.FormField, pdf.Value) {
+	t.Helper()
+
+	data, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil {
+		t.Fatalf("filled data is not base64: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "filled.pdf")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		t.Fatalf("filled document does not open: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	fields := make(map[string]extraction.FormField)
+	for _, field := range extraction.ReadFormFields(r) {
+		fields[field.Name] = field
+	}
+	return fields, r.Trailer().Key("Root").Key("AcroForm")
+}
+
+func TestFormData_ExportListsFields(t *testing.T) {
+	formData := NewFormData(10 * 1024 * 1024)
+	path := formTestPDF(t)
+
+	result, err := formData.Export(PDFExportFormDataRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if result.Format != FormDataJSON || len(result.Fields) != 3 || result.Exported != 3 {
+		t.Fatalf("expected 3 fields exported as json, got %+v", result)
+	}
+
+	want := map[string]FormFieldValue{
+		"name":  {Type: extraction.FieldTypeText, Value: "Old"},
+		"agree": {Type: extraction.FieldTypeCheckbox, Value: "Off", Options: []string{"Yes"}},
+		"color": {Type: extraction.FieldTypeChoice, Value: "Red", Options: []string{"Red", "Green"}},
+	}
+	for _, field := range result.Fields {
+		expected := want[field.Name]
+		if field.Type != expected.Type || field.Value != expected.Value ||
+			strings.Join(field.Options, ",") != strings.Join(expected.Options, ",") {
+			t.Errorf("field %s: expected %+v, got %+v", field.Name, expected, field)
+		}
+	}
+	if !strings.Contains(result.Content, `"agree": "Off"`) {
+		t.Errorf("expected JSON content with the checkbox state, got %s", result.Content)
+	}
+	if len(result.Pages) != 1 || result.Pages[0].TabOrder != extraction.TabOrderAnnotation ||
+		strings.Join(result.Pages[0].Fields, ",") != "name,agree,color" {
+		t.Errorf("expected the fields of page 1 in annotation order, got %+v", result.Pages)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "data", "form.xfdf")
+	result, err = formData.Export(PDFExportFormDataRequest{Path: path, Format: FormDataXFDF, OutputPath: outputPath})
+	if err != nil {
+		t.Fatalf("Export to file failed: %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("form data not saved: %v", err)
+	}
+	if result.Content != "" || !strings.Contains(string(data), `<field name="color">`) {
+		t.Errorf("expected XFDF saved to file, got content %q and file %s", result.Content, data)
+	}
+}
+
+func TestFormData_ExportFieldHierarchy(t *testing.T) {
+	formData := NewFormData(10 * 1024 * 1024)
+	path := createTempFile(t, "subform.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [6 0 R] >>",
+		"<< /T (topmostSubform[0]) /Kids [5 0 R] >>",
+		"<< /T (Page1[0]) /Parent 4 0 R /Kids [6 0 R] >>",
+		"<< /FT /Tx /T (f1_01[0]) /V (Jane) /Parent 5 0 R /Subtype /Widget /Rect [36 700 300 720] /P 3 0 R >>",
+	}))
+	const name = "topmostSubform[0].Page1[0].f1_01[0]"
+
+	result, err := formData.Export(PDFExportFormDataRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(result.Fields) != 3 || result.Exported != 1 || !strings.Contains(result.Content, `"`+name+`": "Jane"`) {
+		t.Fatalf("expected two groups and one exported field, got %+v", result)
+	}
+	if group := result.Fields[1]; group.Type != extraction.FieldTypeGroup || group.Parent != "topmostSubform[0]" ||
+		strings.Join(group.Kids, ",") != name {
+		t.Errorf("expected the page group with its parent and kid, got %+v", group)
+	}
+
+	result, err = formData.Export(PDFExportFormDataRequest{Path: path, TerminalOnly: true})
+	if err != nil {
+		t.Fatalf("Export of terminal fields failed: %v", err)
+	}
+	if len(result.Fields) != 1 || result.Fields[0].Name != name ||
+		result.Fields[0].Parent != "topmostSubform[0].Page1[0]" {
+		t.Errorf("expected only the text field, got %+v", result.Fields)
+	}
+}
+
+func TestFormData_ImportFillsFields(t *testing.T) {
+	formData := NewFormData(10 * 1024 * 1024)
+	path := formTestPDF(t)
+
+	result, err := formData.Import(context.Background(), PDFImportFormDataRequest{
+		Path: path,
+		Data: `{"name": "Ada Lovelace", "agree": true, "color": "Green", "missing": "x", "extra": {"note": 1}}`,
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Format != FormDataJSON || len(result.Filled) != 3 {
+		t.Fatalf("expected 3 fields filled from json, got %+v", result)
+	}
+	if strings.Join(result.Unmatched, ",") != "extra.note,missing" {
+		t.Errorf("expected unmatched extra.note and missing, got %v", result.Unmatched)
+	}
+
+	fields, form := filledFields(t, result)
+	if fields["name"].Value != "Ada Lovelace" || fields["agree"].Value != "Yes" || fields["color"].Value != "Green" {
+		t.Errorf("unexpected filled values: %+v", fields)
+	}
+	if state := fields["agree"].Widgets[0].Key("AS").Name(); state != "Yes" {
+		t.Errorf("expected the checkbox to show Yes, got %q", state)
+	}
+	if !fields["name"].Widgets[0].Key("AP").IsNull() {
+		t.Error("expected the stale appearance of the text field to be dropped")
+	}
+	if !fields["color"].Field.Key("I").IsNull() {
+		t.Error("expected the stale selection indices of the choice field to be dropped")
+	}
+	if !form.Key("NeedAppearances").Bool() || !form.Key("XFA").IsNull() {
+		t.Errorf("expected the form to need appearances and have no XFA, got %v", form)
+	}
+}
+
+func TestFormData_ImportChoiceByDisplayText(t *testing.T) {
+	formData := NewFormData(10 * 1024 * 1024)
+	path := createTempFile(t, "choice.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [4 0 R] >>",
+		"<< /FT /Ch /Ff 131072 /T (state) /Opt [[(NY) (New York)] [(CA) (California)]] /V (NY) " +
+			"/Subtype /Widget /Rect [100 700 200 720] /P 3 0 R >>",
+	}))
+
+	exported, err := formData.Export(PDFExportFormDataRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	field := exported.Fields[0]
+	if len(field.Choices) != 2 || field.Choices[1] != (FormChoice{ExportValue: "CA", Display: "California"}) ||
+		!field.Choices[0].Selected || !field.Combo {
+		t.Errorf("expected the options of a combo box with New York selected, got %+v", field)
+	}
+
+	result, err := formData.Import(context.Background(), PDFImportFormDataRequest{
+		Path: path,
+		Data: `{"state": "California"}`,
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if fields, _ := filledFields(t, result); fields["state"].Value != "CA" {
+		t.Errorf("expected the option shown as California to fill its export value, got %+v", fields["state"])
+	}
+}
+
+func TestFormData_RoundTrip(t *testing.T) {
+	formData := NewFormData(10 * 1024 * 1024)
+	path := formTestPDF(t)
+
+	filled, err := formData.Import(context.Background(), PDFImportFormDataRequest{
+		Path: path,
+		Data: `{"name": "Grace (Hopper) ☺", "agree": "Yes", "color": "Green"}`,
+	})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(filled.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filledPath := createTempFile(t, "filled.pdf", string(data))
+
+	for _, format := range []string{FormDataJSON, FormDataFDF, FormDataXFDF} {
+		t.Run(format, func(t *testing.T) {
+			exported, err := formData.Export(PDFExportFormDataRequest{Path: filledPath, Format: format})
+			if err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			// The format is detected from the content
+			result, err := formData.Import(context.Background(), PDFImportFormDataRequest{
+				Path: path,
+				Data: exported.Content,
+			})
+			if err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+			if result.Format != format || len(result.Filled) != 3 || len(result.Skipped) != 0 {
+				t.Fatalf("expected 3 fields filled from %s, got %+v", format, result)
+			}
+			fields, _ := filledFields(t, result)
+			if fields["name"].Value != "Grace (Hopper) ☺" || fields["agree"].Value != "Yes" ||
+				fields["color"].Value != "Green" {
+				t.Errorf("values did not survive the round trip: %+v", fields)
+			}
+		})
+	}
+}
+
+func TestFormData_ImportSkipsInvalidValues(t *testing.T) {
+	formData := NewFormData(10 * 1024 * 1024)
+	path := formTestPDF(t)
+
+	result, err := formData.Import(context.Background(), PDFImportFormDataRequest{
+		Path: path,
+		Data: `{"name": ["a", "b"], "agree": "Maybe", "color": "Blue"}`,
+	})
+	if err == nil {
+		t.Fatalf("expected an error when nothing is filled, got %+v", result)
+	}
+
+	dataPath := createTempFile(t, "values.xfdf",
+		`<?xml version="1.0"?><xfdf><fields><field name="agree"><value>Maybe</value></field>`+
+			`<field name="name"><value>Ada</value></field></fields></xfdf>`)
+	result, err = formData.Import(context.Background(), PDFImportFormDataRequest{Path: path, DataPath: dataPath})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Format != FormDataXFDF || len(result.Filled) != 1 || len(result.Skipped) != 1 ||
+		result.Skipped[0].Name != "agree" {
+		t.Errorf("expected agree skipped and name filled, got %+v", result)
+	}
+}
+
+func TestFormData_RadioExportValues(t *testing.T) {
+	formData := NewFormData(10 * 1024 * 1024)
+	path := createTempFile(t, "radio.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R] >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [5 0 R 6 0 R] >>",
+		"<< /FT /Btn /Ff 32768 /T (size) /V /0 /Opt [(Small) (Large)] /Kids [5 0 R 6 0 R] >>",
+		"<< /Subtype /Widget /Parent 4 0 R /AS /0 /AP << /N << /0 7 0 R /Off 7 0 R >> >> >>",
+		"<< /Subtype /Widget /Parent 4 0 R /AS /Off /AP << /N << /1 7 0 R /Off 7 0 R >> >> >>",
+		"<< /Type /XObject /Subtype /Form /BBox [0 0 10 10] /Length 0 >>\nstream\n\nendstream",
+	}))
+
+	exported, err := formData.Export(PDFExportFormDataRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	field := exported.Fields[0]
+	if !field.Checked || field.ExportValue != "Small" || len(field.Buttons) != 2 || field.Buttons[1].ExportValue != "Large" {
+		t.Errorf("expected the Small button checked among Small and Large, got %+v", field)
+	}
+
+	// An export value selects the button standing for it
+	result, err := formData.Import(context.Background(), PDFImportFormDataRequest{Path: path, Data: `{"size": "Large"}`})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	fields, _ := filledFields(t, result)
+	if size := fields["size"]; size.Value != "1" || size.ExportValue != "Large" ||
+		size.Widgets[0].Key("AS").Name() != "Off" || size.Widgets[1].Key("AS").Name() != "1" {
+		t.Errorf("expected the Large button switched on, got %+v", size)
+	}
+}
+
+func TestFormData_SignedForms(t *testing.T) {
+	formData := NewFormData(10 * 1024 * 1024)
+	path := createTempFile(t, "signed.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [4 0 R 5 0 R] /SigFlags 3 >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [4 0 R 5 0 R] >>",
+		"<< /FT /Tx /T (name) /V (Ada) /Subtype /Widget /Rect [100 700 300 720] >>",
+		"<< /FT /Sig /T (signature) /V 6 0 R /Lock << /Action /All >> /Subtype /Widget /Rect [100 600 300 640] >>",
+		"<< /Type /Sig /Name (Ada Lovelace) /M (D:20240102030405Z) /Contents <00> >>",
+	}))
+
+	result, err := formData.Export(PDFExportFormDataRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !result.SignaturesExist || !result.AppendOnly || result.Exported != 1 {
+		t.Errorf("expected a signed, append-only form with one exported field, got %+v", result)
+	}
+	name, signature := result.Fields[0], result.Fields[1]
+	if name.LockedBy != "signature" {
+		t.Errorf("expected the name field locked by the signature, got %+v", name)
+	}
+	if sig := signature.Signature; sig == nil || !sig.Signed || sig.Signer != "Ada Lovelace" ||
+		sig.SigningTime != "2024-01-02T03:04:05Z" {
+		t.Errorf("expected the signer and signing time, got %+v", sig)
+	}
+
+	_, err = formData.Import(context.Background(), PDFImportFormDataRequest{Path: path, Data: `{"name": "Bob"}`})
+	if err == nil || !strings.Contains(err.Error(), "signed") {
+		t.Errorf("expected filling a signed document to fail, got %v", err)
+	}
+}
+
+func TestFormData_RejectsInvalidRequests(t *testing.T) {
+	formData := NewFormData(10 * 1024 * 1024)
+	path := formTestPDF(t)
+	noForm := createTempFile(t, "plain.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Hello) Tj ET"))
+
+	tests := []struct {
+		name string
+		req  PDFImportFormDataRequest
+	}{
+		{"empty path", PDFImportFormDataRequest{Data: "{}"}},
+		{"no data", PDFImportFormDataRequest{Path: path}},
+		{"data and data path", PDFImportFormDataRequest{Path: path, Data: "{}", DataPath: path}},
+		{"invalid format", PDFImportFormDataRequest{Path: path, Data: "{}", Format: "csv"}},
+		{"undetectable format", PDFImportFormDataRequest{Path: path, Data: "name=Ada"}},
+		{"invalid JSON", PDFImportFormDataRequest{Path: path, Data: `{"name": `}},
+		{"invalid FDF", PDFImportFormDataRequest{Path: path, Data: "%FDF-1.2\n1 0 obj\n<< >>\nendobj\n"}},
+		{"no form fields", PDFImportFormDataRequest{Path: noForm, Data: `{"name": "Ada"}`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := formData.Import(context.Background(), tt.req); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := formData.Export(PDFExportFormDataRequest{Path: path, Format: "csv"}); err == nil {
+		t.Error("expected an error for an invalid export format")
+	}
+}
@@ -0,0 +1,143 @@
+// Package fsys is the file system the PDF packages read documents from and write their
+// outputs to. Every file access of internal/pdf goes through the current FS, which is the
+// host's file system by default and can be replaced, such as by an in-memory FS in
+// environments without one (WASM, read-only containers) or in tests.
+//
+// Unlike the names of a plain fs.FS, the names an FS takes are host paths, absolute or
+// relative, as the tools receive them.
+package fsys
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// FS is a file system that can be read through the io/fs interfaces and written to. The
+// files it opens implement io.ReaderAt and io.Seeker besides fs.File, so that documents
+// can be parsed without reading them whole.
+type FS interface {
+	fs.StatFS
+	fs.ReadFileFS
+	fs.ReadDirFS
+
+	// WriteFile writes data to a file, creating it with perm or truncating it
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// WriteTemp writes data to a new file in dir, named after pattern with its last "*"
+	// replaced by a unique string, and returns the file's name. An empty dir is the
+	// directory for temporary files.
+	WriteTemp(dir, pattern string, data []byte, perm fs.FileMode) (string, error)
+	// MkdirAll creates a directory and any missing parents
+	MkdirAll(name string, perm fs.FileMode) error
+	// MkdirTemp creates a new directory in dir, named as WriteTemp names files
+	MkdirTemp(dir, pattern string) (string, error)
+	// Rename moves a file or directory, replacing a file at newname
+	Rename(oldname, newname string) error
+	// Remove deletes a file or an empty directory
+	Remove(name string) error
+	// RemoveAll deletes a file or directory and everything in it; a missing name is no error
+	RemoveAll(name string) error
+}
+
+// current holds the FS set with Use; nil means the host's file system
+var current atomic.Pointer[FS]
+
+// Use makes fsys the file system of every PDF operation in the process; nil restores the
+// host's file system
+func Use(fsys FS) {
+	if fsys == nil {
+		current.Store(nil)
+		return
+	}
+	current.Store(&fsys)
+}
+
+// Current returns the file system in use
+func Current() FS {
+	if fsys := current.Load(); fsys != nil {
+		return *fsys
+	}
+	return OS{}
+}
+
+// IsHost reports whether the file system in use is the host's, whose files external
+// programs can read
+func IsHost() bool {
+	_, ok := Current().(OS)
+	return ok
+}
+
+// Open opens a file of the current FS for reading
+func Open(name string) (fs.File, error) {
+	return Current().Open(name)
+}
+
+// Stat describes a file of the current FS
+func Stat(name string) (fs.FileInfo, error) {
+	return Current().Stat(name)
+}
+
+// ReadFile reads a whole file of the current FS
+func ReadFile(name string) ([]byte, error) {
+	return Current().ReadFile(name)
+}
+
+// ReadDir lists a directory of the current FS, sorted by name
+func ReadDir(name string) ([]fs.DirEntry, error) {
+	return Current().ReadDir(name)
+}
+
+// WalkDir walks the tree of the current FS rooted at root, as fs.WalkDir does
+func WalkDir(root string, fn fs.WalkDirFunc) error {
+	return fs.WalkDir(Current(), root, fn)
+}
+
+// Walk walks the tree of the current FS rooted at root as filepath.Walk does, describing
+// each file to fn
+func Walk(root string, fn filepath.WalkFunc) error {
+	return WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(path, nil, err)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fn(path, nil, err)
+		}
+		return fn(path, info, nil)
+	})
+}
+
+// WriteFile writes a file of the current FS
+func WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return Current().WriteFile(name, data, perm)
+}
+
+// WriteTemp writes a new uniquely named file of the current FS and returns its name
+func WriteTemp(dir, pattern string, data []byte, perm fs.FileMode) (string, error) {
+	return Current().WriteTemp(dir, pattern, data, perm)
+}
+
+// MkdirAll creates a directory of the current FS and any missing parents
+func MkdirAll(name string, perm fs.FileMode) error {
+	return Current().MkdirAll(name, perm)
+}
+
+// MkdirTemp creates a new uniquely named directory of the current FS and returns its name
+func MkdirTemp(dir, pattern string) (string, error) {
+	return Current().MkdirTemp(dir, pattern)
+}
+
+// Rename moves a file or directory of the current FS
+func Rename(oldname, newname string) error {
+	return Current().Rename(oldname, newname)
+}
+
+// Remove deletes a file or empty directory of the current FS
+func Remove(name string) error {
+	return Current().Remove(name)
+}
+
+// RemoveAll deletes a file or directory tree of the current FS
+func RemoveAll(name string) error {
+	return Current().RemoveAll(name)
+}
@@ -0,0 +1,362 @@
+package fsys
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors of the in-memory file system that io/fs has no sentinel for
+var (
+	errIsDir    = errors.New("is a directory")
+	errNotDir   = errors.New("not a directory")
+	errNotEmpty = errors.New("directory not empty")
+)
+
+// Memory is a file system held in memory. Names are cleaned host paths; the root, "/" or
+// ".", always exists, and files can only be written into existing directories, as on the
+// host. It is safe for concurrent use.
+type Memory struct {
+	mu      sync.RWMutex
+	entries map[string]*memoryEntry
+	seq     int // Counter naming temporary files and directories
+}
+
+// memoryEntry is a file or directory of a Memory file system
+type memoryEntry struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMemory creates an empty in-memory file system
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]*memoryEntry)}
+}
+
+// Open opens a file for reading, or a directory for listing
+func (m *Memory) Open(name string) (fs.File, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	name = filepath.Clean(name)
+	info, err := m.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &memoryDir{info: info, entries: m.children(name)}, nil
+	}
+	return &memoryFile{Reader: bytes.NewReader(m.entries[name].data), info: info}, nil
+}
+
+// Stat describes a file or directory
+func (m *Memory) Stat(name string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stat("stat", filepath.Clean(name))
+}
+
+// ReadFile returns a copy of a file's content
+func (m *Memory) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	name = filepath.Clean(name)
+	info, err := m.stat("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+	}
+	return bytes.Clone(m.entries[name].data), nil
+}
+
+// ReadDir lists a directory, sorted by name
+func (m *Memory) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	name = filepath.Clean(name)
+	info, err := m.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+	}
+	return m.children(name), nil
+}
+
+// WriteFile writes a copy of data to a file, creating it with perm or truncating it
+func (m *Memory) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.write("open", filepath.Clean(name), data, perm)
+}
+
+// WriteTemp writes data to a new file in dir and returns its name
+func (m *Memory) WriteTemp(dir, pattern string, data []byte, perm fs.FileMode) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name, err := m.tempName("createtemp", dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	return name, m.write("createtemp", name, data, perm)
+}
+
+// MkdirAll creates a directory and any missing parents
+func (m *Memory) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(filepath.Clean(name), perm)
+}
+
+// MkdirTemp creates a new directory in dir and returns its name
+func (m *Memory) MkdirTemp(dir, pattern string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name, err := m.tempName("mkdirtemp", dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	m.entries[name] = &memoryEntry{mode: fs.ModeDir | 0o700, modTime: time.Now()}
+	return name, nil
+}
+
+// Rename moves a file or directory, with everything in it, replacing a file at newname
+func (m *Memory) Rename(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldname, newname = filepath.Clean(oldname), filepath.Clean(newname)
+	entry, ok := m.entries[oldname]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrNotExist}
+	}
+	if !m.isDir(filepath.Dir(newname)) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrNotExist}
+	}
+	if target, ok := m.entries[newname]; ok && (target.mode.IsDir() || entry.mode.IsDir()) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+	if entry.mode.IsDir() && within(newname, oldname) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrInvalid}
+	}
+
+	moved := make(map[string]*memoryEntry)
+	for name, moving := range m.entries {
+		if within(name, oldname) {
+			moved[newname+name[len(oldname):]] = moving
+			delete(m.entries, name)
+		}
+	}
+	for name, moving := range moved {
+		m.entries[name] = moving
+	}
+	return nil
+}
+
+// Remove deletes a file or an empty directory
+func (m *Memory) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	entry, ok := m.entries[name]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if entry.mode.IsDir() && len(m.children(name)) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: errNotEmpty}
+	}
+	delete(m.entries, name)
+	return nil
+}
+
+// RemoveAll deletes a file or directory and everything in it
+func (m *Memory) RemoveAll(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	if isRoot(name) {
+		return &fs.PathError{Op: "removeall", Path: name, Err: fs.ErrInvalid}
+	}
+	for entry := range m.entries {
+		if within(entry, name) {
+			delete(m.entries, entry)
+		}
+	}
+	return nil
+}
+
+// stat describes an entry; m.mu must be held
+func (m *Memory) stat(op, name string) (fs.FileInfo, error) {
+	if isRoot(name) {
+		return &memoryInfo{name: name, mode: fs.ModeDir | 0o755}, nil
+	}
+	entry, ok := m.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return &memoryInfo{
+		name: filepath.Base(name), size: int64(len(entry.data)), mode: entry.mode, modTime: entry.modTime,
+	}, nil
+}
+
+// isDir reports whether a name is an existing directory; m.mu must be held
+func (m *Memory) isDir(name string) bool {
+	if isRoot(name) {
+		return true
+	}
+	entry, ok := m.entries[name]
+	return ok && entry.mode.IsDir()
+}
+
+// children lists the entries directly inside a directory, sorted by name; m.mu must be held
+func (m *Memory) children(dir string) []fs.DirEntry {
+	var entries []fs.DirEntry
+	for name := range m.entries {
+		if filepath.Dir(name) == dir && name != dir {
+			info, _ := m.stat("readdir", name)
+			entries = append(entries, fs.FileInfoToDirEntry(info))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries
+}
+
+// write stores a copy of data in a file of an existing directory; m.mu must be held
+func (m *Memory) write(op, name string, data []byte, perm fs.FileMode) error {
+	if !m.isDir(filepath.Dir(name)) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	entry, ok := m.entries[name]
+	if ok && entry.mode.IsDir() {
+		return &fs.PathError{Op: op, Path: name, Err: errIsDir}
+	}
+	mode := perm.Perm()
+	if ok {
+		mode = entry.mode // Truncating keeps the file's permissions, as on the host
+	}
+	m.entries[name] = &memoryEntry{data: bytes.Clone(data), mode: mode, modTime: time.Now()}
+	return nil
+}
+
+// mkdirAll creates a directory and its missing parents; m.mu must be held
+func (m *Memory) mkdirAll(name string, perm fs.FileMode) error {
+	if m.isDir(name) {
+		return nil
+	}
+	if _, ok := m.entries[name]; ok {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: errNotDir}
+	}
+	if err := m.mkdirAll(filepath.Dir(name), perm); err != nil {
+		return err
+	}
+	m.entries[name] = &memoryEntry{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// tempName picks an unused name in dir after pattern; m.mu must be held
+func (m *Memory) tempName(op, dir, pattern string) (string, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	dir = filepath.Clean(dir)
+	if strings.ContainsRune(pattern, filepath.Separator) {
+		return "", &fs.PathError{Op: op, Path: pattern, Err: fs.ErrInvalid}
+	}
+	if !m.isDir(dir) {
+		if err := m.mkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+	}
+	prefix, suffix := pattern, ""
+	if i := strings.LastIndex(pattern, "*"); i >= 0 {
+		prefix, suffix = pattern[:i], pattern[i+1:]
+	}
+	for {
+		m.seq++
+		name := filepath.Join(dir, prefix+strconv.Itoa(m.seq)+suffix)
+		if _, ok := m.entries[name]; !ok {
+			return name, nil
+		}
+	}
+}
+
+// isRoot reports whether a cleaned name is the root of its tree
+func isRoot(name string) bool {
+	return filepath.Dir(name) == name
+}
+
+// within reports whether a cleaned name is dir or lies inside it
+func within(name, dir string) bool {
+	if name == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(name, dir)
+}
+
+// memoryInfo describes an entry of a Memory file system
+type memoryInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *memoryInfo) Name() string       { return i.name }
+func (i *memoryInfo) Size() int64        { return i.size }
+func (i *memoryInfo) Mode() fs.FileMode  { return i.mode }
+func (i *memoryInfo) ModTime() time.Time { return i.modTime }
+func (i *memoryInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *memoryInfo) Sys() any           { return nil }
+
+// memoryFile is an open file of a Memory file system, reading a snapshot of its content
+type memoryFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *memoryFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memoryFile) Close() error               { return nil }
+
+// memoryDir is an open directory of a Memory file system
+type memoryDir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memoryDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memoryDir) Close() error               { return nil }
+
+func (d *memoryDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: errIsDir}
+}
+
+// ReadDir returns the next n entries, or all that are left when n is not positive
+func (d *memoryDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	left := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return left, nil
+	}
+	if len(left) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(left) {
+		n = len(left)
+	}
+	d.offset += n
+	return left[:n], nil
+}
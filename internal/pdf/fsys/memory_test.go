@@ -0,0 +1,110 @@
+package fsys
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMemory_ReadWrite(t *testing.T) {
+	m := NewMemory()
+	if err := m.WriteFile("/docs/a.pdf", []byte("x"), 0o644); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("WriteFile() into a missing directory error = %v, want ErrNotExist", err)
+	}
+	if err := m.MkdirAll("/docs/sub", 0o755); err != nil {
+		t.Fatalf("MkdirAll() unexpected error = %v", err)
+	}
+	if err := m.WriteFile("/docs/a.pdf", []byte("%PDF-1.7 body"), 0o644); err != nil {
+		t.Fatalf("WriteFile() unexpected error = %v", err)
+	}
+
+	info, err := m.Stat("/docs/a.pdf")
+	if err != nil || info.Size() != 13 || info.IsDir() || info.Name() != "a.pdf" {
+		t.Errorf("Stat() = %v, %v, want a 13 byte file", info, err)
+	}
+	f, err := m.Open("/docs/a.pdf")
+	if err != nil {
+		t.Fatalf("Open() unexpected error = %v", err)
+	}
+	defer f.Close()
+	head := make([]byte, 8)
+	if _, err := f.(io.ReaderAt).ReadAt(head, 0); err != nil || string(head) != "%PDF-1.7" {
+		t.Errorf("ReadAt() = %q, %v, want the file header", head, err)
+	}
+
+	entries, err := m.ReadDir("/docs")
+	if err != nil || len(entries) != 2 || entries[0].Name() != "a.pdf" || !entries[1].IsDir() {
+		t.Errorf("ReadDir() = %v, %v, want a.pdf and sub", entries, err)
+	}
+	var walked []string
+	err = fs.WalkDir(m, "/", func(path string, _ fs.DirEntry, err error) error {
+		walked = append(walked, path)
+		return err
+	})
+	if err != nil || strings.Join(walked, " ") != "/ /docs /docs/a.pdf /docs/sub" {
+		t.Errorf("WalkDir() visited %v (%v)", walked, err)
+	}
+}
+
+func TestMemory_TempRenameRemove(t *testing.T) {
+	m := NewMemory()
+	tmp, err := m.WriteTemp("/stage", ".fetch-*.part", []byte("data"), 0o600)
+	if err != nil {
+		t.Fatalf("WriteTemp() unexpected error = %v", err)
+	}
+	if filepath.Dir(tmp) != "/stage" || !strings.HasPrefix(filepath.Base(tmp), ".fetch-") ||
+		!strings.HasSuffix(tmp, ".part") {
+		t.Errorf("WriteTemp() = %q, want a file named after the pattern in /stage", tmp)
+	}
+	dir, err := m.MkdirTemp("/stage", "job-*")
+	if err != nil {
+		t.Fatalf("MkdirTemp() unexpected error = %v", err)
+	}
+	if err := m.Rename(tmp, filepath.Join(dir, "doc.pdf")); err != nil {
+		t.Fatalf("Rename() unexpected error = %v", err)
+	}
+	if err := m.Rename(dir, "/done"); err != nil {
+		t.Fatalf("Rename() of a directory unexpected error = %v", err)
+	}
+	if data, err := m.ReadFile("/done/doc.pdf"); err != nil || string(data) != "data" {
+		t.Errorf("ReadFile() after renames = %q, %v", data, err)
+	}
+
+	if err := m.Remove("/done"); err == nil {
+		t.Error("Remove() of a directory that is not empty succeeded")
+	}
+	if err := m.RemoveAll("/done"); err != nil {
+		t.Fatalf("RemoveAll() unexpected error = %v", err)
+	}
+	if _, err := m.Stat("/done/doc.pdf"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat() after RemoveAll error = %v, want ErrNotExist", err)
+	}
+	if err := m.RemoveAll("/missing"); err != nil {
+		t.Errorf("RemoveAll() of a missing name error = %v", err)
+	}
+}
+
+func TestUse(t *testing.T) {
+	if !IsHost() {
+		t.Fatal("IsHost() = false by default")
+	}
+	m := NewMemory()
+	Use(m)
+	t.Cleanup(func() { Use(nil) })
+	if err := WriteFile("/a.txt", []byte("a"), 0o644); err != nil {
+		t.Fatalf("WriteFile() unexpected error = %v", err)
+	}
+	if IsHost() || Current() != FS(m) {
+		t.Error("Current() is not the FS in use")
+	}
+	if _, err := m.Stat("/a.txt"); err != nil {
+		t.Errorf("Stat() of a file written through the package error = %v", err)
+	}
+	Use(nil)
+	if !IsHost() {
+		t.Error("Use(nil) did not restore the host's file system")
+	}
+}
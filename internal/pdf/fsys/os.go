@@ -0,0 +1,80 @@
+package fsys
+
+import (
+	"io/fs"
+	"os"
+)
+
+// OS is the host's file system
+type OS struct{}
+
+// Open opens a file for reading
+func (OS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// Stat describes a file, following symbolic links
+func (OS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// ReadFile reads a whole file
+func (OS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// ReadDir lists a directory, sorted by name
+func (OS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// WriteFile writes a file, creating it with perm or truncating it
+func (OS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+// WriteTemp writes data to a new file in dir and returns its name. The file is given perm
+// before it is closed, and removed again when it cannot be written whole.
+func (OS) WriteTemp(dir, pattern string, data []byte, perm fs.FileMode) (string, error) {
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// MkdirAll creates a directory and any missing parents
+func (OS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(name, perm)
+}
+
+// MkdirTemp creates a new directory in dir and returns its name
+func (OS) MkdirTemp(dir, pattern string) (string, error) {
+	return os.MkdirTemp(dir, pattern)
+}
+
+// Rename moves a file or directory
+func (OS) Rename(oldname, newname string) error {
+	return os.Rename(oldname, newname)
+}
+
+// Remove deletes a file or an empty directory
+func (OS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// RemoveAll deletes a file or directory and everything in it
+func (OS) RemoveAll(name string) error {
+	return os.RemoveAll(name)
+}
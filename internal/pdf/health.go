@@ -0,0 +1,188 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Health check statuses
+const (
+	HealthPass = "pass"
+	HealthFail = "fail"
+)
+
+// selfTestPhrase is the text of the synthetic document the self-test extracts
+const selfTestPhrase = "MCP PDF Reader self-test"
+
+// PDFHealth checks the subsystems a deployment depends on: that the PDF directories can be
+// read, that temporary files can be written, whether OCR is available, how full the memory
+// budget is, and that a synthetic one-page document extracts correctly. The result passes
+// when every required check passes; OCR is optional.
+func (s *Service) PDFHealth(ctx context.Context, req PDFHealthRequest) (*PDFHealthResult, error) {
+	result := &PDFHealthResult{Status: HealthPass, CheckedAt: time.Now().UTC().Format(time.RFC3339)}
+	for _, dir := range req.Directories {
+		result.add(runCheck("directory", true, func() (string, error) { return checkDirectory(dir) }))
+	}
+
+	tmp, err := fsys.MkdirTemp("", "mcp-pdf-reader-health-")
+	result.add(runCheck("temp", true, func() (string, error) {
+		if err != nil {
+			return "", fmt.Errorf("cannot create a temporary directory in %s: %w", os.TempDir(), err)
+		}
+		return checkWritable(tmp)
+	}))
+	if err == nil {
+		defer fsys.RemoveAll(tmp)
+	}
+
+	result.add(runCheck("ocr", false, s.regionOCR.checkAvailable))
+	result.add(runCheck("memory", true, s.extractionService.checkMemory))
+	result.add(runCheck("self_test", true, func() (string, error) {
+		if err != nil {
+			return "", fmt.Errorf("no temporary directory to write the test document in")
+		}
+		return s.extractionService.selfTest(ctx, filepath.Join(tmp, "self-test.pdf"))
+	}))
+	return result, nil
+}
+
+// add appends a check, failing the result when a required check failed
+func (r *PDFHealthResult) add(check HealthCheck) {
+	r.Checks = append(r.Checks, check)
+	if check.Required && check.Status == HealthFail {
+		r.Status = HealthFail
+	}
+}
+
+// runCheck runs one check and times it
+func runCheck(name string, required bool, check func() (string, error)) HealthCheck {
+	started := time.Now()
+	detail, err := check()
+	result := HealthCheck{Name: name, Status: HealthPass, Required: required, Detail: detail}
+	if err != nil {
+		result.Status, result.Detail = HealthFail, err.Error()
+	}
+	result.DurationMS = float64(time.Since(started).Microseconds()) / 1000
+	return result
+}
+
+// checkDirectory reports whether a directory can be listed, and how many PDFs it holds
+func checkDirectory(dir string) (string, error) {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", dir, err)
+	}
+	count := 0
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".pdf") {
+			count++
+		}
+	}
+	return fmt.Sprintf("%s is readable (%d PDF files)", dir, count), nil
+}
+
+// checkWritable writes, reads back, and removes a file in a directory
+func checkWritable(dir string) (string, error) {
+	path := filepath.Join(dir, "probe")
+	if err := fsys.WriteFile(path, []byte("probe"), exportFilePerm); err != nil {
+		return "", fmt.Errorf("cannot write to %s: %w", dir, err)
+	}
+	if data, err := fsys.ReadFile(path); err != nil || string(data) != "probe" {
+		return "", fmt.Errorf("cannot read back a file written to %s", dir)
+	}
+	if err := fsys.Remove(path); err != nil {
+		return "", fmt.Errorf("cannot remove a file from %s: %w", dir, err)
+	}
+	return fmt.Sprintf("%s is writable", filepath.Dir(dir)), nil
+}
+
+// checkAvailable reports whether the OCR program and a page renderer are installed
+func (o *RegionOCR) checkAvailable() (string, error) {
+	engine, err := lookProgram(o.engine)
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed; pdf_ocr_region cannot recognize text", o.engine)
+	}
+	backend, err := o.renderer.findBackend()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s with %s rendering, language %s", engine, backend.name, o.language), nil
+}
+
+// checkMemory reports the occupancy of the memory budget, failing when it is exhausted and
+// extractions have to wait for memory or spill their pages to disk
+func (s *ExtractionService) checkMemory() (string, error) {
+	engine, ok := s.engine.(*extraction.DefaultEngine)
+	if !ok {
+		return "no memory budget", nil
+	}
+	stats := engine.MemoryStats()
+	if stats.Limit == 0 {
+		return "no memory budget", nil
+	}
+	detail := fmt.Sprintf("%d of %d bytes in use (%.0f%%), peak %d bytes",
+		stats.InUse, stats.Limit, float64(stats.InUse)/float64(stats.Limit)*100, stats.Peak)
+	if stats.InUse >= stats.Limit {
+		return "", fmt.Errorf("memory budget exhausted: %s", detail)
+	}
+	return detail, nil
+}
+
+// selfTest writes a synthetic one-page document to path and extracts it with the engine,
+// bypassing the cache, checking that its text comes back
+func (s *ExtractionService) selfTest(ctx context.Context, path string) (string, error) {
+	if err := fsys.WriteFile(path, selfTestPDF(), exportFilePerm); err != nil {
+		return "", fmt.Errorf("cannot write the test document: %w", err)
+	}
+	result, err := s.engine.Extract(ctx, extraction.ExtractionRequest{
+		FilePath: path,
+		Config:   s.buildEngineConfig(extraction.ModeStructured, ExtractConfig{ExtractText: true}),
+	})
+	if err != nil {
+		return "", fmt.Errorf("extraction failed: %w", err)
+	}
+	if len(result.ProcessedPages) != 1 {
+		return "", fmt.Errorf("extraction processed %d pages, want 1", len(result.ProcessedPages))
+	}
+	elements, err := json.Marshal(result.Elements)
+	if err != nil || !strings.Contains(string(elements), selfTestPhrase) {
+		return "", fmt.Errorf("extraction did not return the test document's text")
+	}
+	return fmt.Sprintf("extracted 1 page with %d elements", len(result.Elements)), nil
+}
+
+// selfTestPDF builds a one-page document showing selfTestPhrase in Helvetica
+func selfTestPDF() []byte {
+	content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", selfTestPhrase)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] " +
+			"/Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}
+
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return []byte(b.String())
+}
@@ -0,0 +1,43 @@
+package pdf
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestService_PDFHealth(t *testing.T) {
+	dir := filepath.Dir(createTempFile(t, "report.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Report) Tj ET")))
+	s := NewService(1024 * 1024)
+	s.SetOCR("mcp-pdf-reader-missing-ocr", "")
+
+	result, err := s.PDFHealth(context.Background(), PDFHealthRequest{Directories: []string{dir}})
+	if err != nil {
+		t.Fatalf("PDFHealth() unexpected error = %v", err)
+	}
+	checks := map[string]HealthCheck{}
+	for _, check := range result.Checks {
+		checks[check.Name] = check
+	}
+	if detail := checks["directory"].Detail; detail != dir+" is readable (1 PDF files)" {
+		t.Errorf("PDFHealth() directory check detail = %q", detail)
+	}
+	for _, name := range []string{"directory", "temp", "memory", "self_test"} {
+		if checks[name].Status != HealthPass || !checks[name].Required {
+			t.Errorf("PDFHealth() %s check = %+v, want a required pass", name, checks[name])
+		}
+	}
+	if ocr := checks["ocr"]; ocr.Status != HealthFail || ocr.Required {
+		t.Errorf("PDFHealth() ocr check = %+v, want an optional failure", ocr)
+	}
+	if result.Status != HealthPass {
+		t.Errorf("PDFHealth() status = %s, want pass when only optional checks fail", result.Status)
+	}
+
+	result, err = s.PDFHealth(context.Background(), PDFHealthRequest{
+		Directories: []string{filepath.Join(dir, "missing")},
+	})
+	if err != nil || result.Status != HealthFail || result.Checks[0].Status != HealthFail {
+		t.Errorf("PDFHealth() of a missing directory = %+v, %v, want a failure", result, err)
+	}
+}
@@ -0,0 +1,186 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// maxDecodedImagePixels bounds the images decoded for classification, about 64MB of RGBA
+const maxDecodedImagePixels = 16_000_000
+
+// imageColor describes how the samples of an image give colors: components per sample,
+// or a palette of colors of paletteComponents each that one sample indexes
+type imageColor struct {
+	components        int
+	palette           []byte
+	paletteComponents int
+}
+
+// decodeImageXObject decodes the pixels of an image XObject. JPEG images are decoded from
+// their stored bytes; others from their Flate-compressed or unfiltered samples of 1 to 8
+// bits in gray, RGB, CMYK, ICC-based, or indexed color. Other compressions, such as
+// CCITT fax and JBIG2, are not decoded.
+func decodeImageXObject(file io.ReaderAt, obj pdf.Value) (img image.Image, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("failed to decode image: %v", rec)
+		}
+	}()
+
+	if err := checkImageSize(obj.Key("Width").Int64(), obj.Key("Height").Int64()); err != nil {
+		return nil, err
+	}
+	width, height := int(obj.Key("Width").Int64()), int(obj.Key("Height").Int64())
+
+	var filters []string
+	switch filter := obj.Key("Filter"); filter.Kind() {
+	case pdf.Name:
+		filters = []string{filter.Name()}
+	case pdf.Array:
+		for i := 0; i < filter.Len(); i++ {
+			filters = append(filters, filter.Index(i).Name())
+		}
+	}
+	if len(filters) == 1 && filters[0] == "DCTDecode" {
+		data, err := readRawStream(file, obj)
+		if err != nil {
+			return nil, err
+		}
+		// The frame the JPEG data declares is what the decoder allocates, whatever the
+		// dictionary says
+		config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkImageSize(int64(config.Width), int64(config.Height)); err != nil {
+			return nil, err
+		}
+		return jpeg.Decode(bytes.NewReader(data))
+	}
+	for _, filter := range filters {
+		if filter != "FlateDecode" && filter != "ASCII85Decode" {
+			return nil, fmt.Errorf("images compressed with %s are not decoded", filter)
+		}
+	}
+
+	bitsPerComponent := int(obj.Key("BitsPerComponent").Int64())
+	colors := imageColor{components: 1}
+	if obj.Key("ImageMask").Bool() {
+		bitsPerComponent = 1
+	} else if colors, err = imageColorSpace(obj.Key("ColorSpace")); err != nil {
+		return nil, err
+	}
+	switch bitsPerComponent {
+	case 1, 2, 4, 8:
+	default:
+		return nil, fmt.Errorf("images of %d bits per component are not decoded", bitsPerComponent)
+	}
+
+	stride := (width*colors.components*bitsPerComponent + 7) / 8
+	data, err := io.ReadAll(io.LimitReader(obj.Reader(), int64(stride*height)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image samples: %w", err)
+	}
+	if len(data) < stride*height {
+		return nil, fmt.Errorf("image samples are truncated")
+	}
+
+	// A Decode array running from 1 to 0 inverts the samples, as in masks that paint their 1s
+	decode := obj.Key("Decode")
+	invert := decode.Kind() == pdf.Array && decode.Len() >= 2 && decode.Index(0).Float64() > decode.Index(1).Float64()
+	maxSample := 1<<bitsPerComponent - 1
+	sample := func(row []byte, i int) int {
+		bit := i * bitsPerComponent
+		return int(row[bit/8]>>(8-bitsPerComponent-bit%8)) & maxSample
+	}
+	level := func(value int) uint8 {
+		if invert {
+			value = maxSample - value
+		}
+		return uint8(value * 255 / maxSample)
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	values := make([]uint8, 4)
+	for y := 0; y < height; y++ {
+		row := data[y*stride : (y+1)*stride]
+		for x := 0; x < width; x++ {
+			components := colors.components
+			if colors.palette != nil {
+				index := sample(row, x) * colors.paletteComponents
+				if index+colors.paletteComponents > len(colors.palette) {
+					continue
+				}
+				components = colors.paletteComponents
+				copy(values, colors.palette[index:index+components])
+			} else {
+				for c := 0; c < components; c++ {
+					values[c] = level(sample(row, x*components+c))
+				}
+			}
+			rgba.Set(x, y, sampleColor(values[:components]))
+		}
+	}
+	return rgba, nil
+}
+
+// checkImageSize rejects images without pixels or with more than maxDecodedImagePixels,
+// without overflowing on large sizes
+func checkImageSize(width, height int64) error {
+	if width <= 0 || height <= 0 || width > maxDecodedImagePixels/height {
+		return fmt.Errorf("image of %dx%d pixels is empty or too large to decode", width, height)
+	}
+	return nil
+}
+
+// sampleColor returns the color of one pixel's gray, RGB, or CMYK components
+func sampleColor(values []uint8) color.Color {
+	switch len(values) {
+	case 3:
+		return color.RGBA{R: values[0], G: values[1], B: values[2], A: 0xFF}
+	case 4:
+		return color.CMYK{C: values[0], M: values[1], Y: values[2], K: values[3]}
+	default:
+		return color.Gray{Y: values[0]}
+	}
+}
+
+// imageColorSpace returns how samples in a color space give colors
+func imageColorSpace(space pdf.Value) (imageColor, error) {
+	name := space.Name()
+	if space.Kind() == pdf.Array && space.Len() > 0 {
+		name = space.Index(0).Name()
+	}
+	switch name {
+	case "DeviceGray", "G", "CalGray":
+		return imageColor{components: 1}, nil
+	case "DeviceRGB", "RGB", "CalRGB", "Lab":
+		return imageColor{components: 3}, nil
+	case "DeviceCMYK", "CMYK":
+		return imageColor{components: 4}, nil
+	case "ICCBased":
+		if n := int(space.Index(1).Key("N").Int64()); n == 1 || n == 3 || n == 4 {
+			return imageColor{components: n}, nil
+		}
+	case "Indexed", "I":
+		base, err := imageColorSpace(space.Index(1))
+		if err != nil || base.palette != nil {
+			return imageColor{}, fmt.Errorf("unsupported indexed color space")
+		}
+		lookup := space.Index(3)
+		palette := []byte(lookup.RawString())
+		if lookup.Kind() == pdf.Stream {
+			if palette, err = io.ReadAll(lookup.Reader()); err != nil {
+				return imageColor{}, fmt.Errorf("failed to read color palette: %w", err)
+			}
+		}
+		return imageColor{components: 1, palette: palette, paletteComponents: base.components}, nil
+	}
+	return imageColor{}, fmt.Errorf("images in the %s color space are not decoded", name)
+}
@@ -0,0 +1,84 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// jpegImagePDF builds a one-page document whose only object of interest, 3 0 R, is a
+// DCTDecode image of the given JPEG data described as 16x16 pixels
+func jpegImagePDF(data []byte) string {
+	return buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width 16 /Height 16 /ColorSpace /DeviceGray "+
+			"/BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream", len(data), data),
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Im 3 0 R >> >> >>",
+	})
+}
+
+func TestDecodeImageXObject_JPEGFrameSize(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 16, 16)), nil); err != nil {
+		t.Fatal(err)
+	}
+	// The same image with a frame header claiming 60000x60000 pixels
+	huge := bytes.Clone(encoded.Bytes())
+	sof := bytes.Index(huge, []byte{0xFF, 0xC0})
+	if sof < 0 {
+		t.Fatal("encoded JPEG has no baseline frame header")
+	}
+	copy(huge[sof+5:], []byte{0xEA, 0x60, 0xEA, 0x60})
+
+	tests := []struct {
+		name     string
+		data     []byte
+		errorMsg string
+	}{
+		{name: "frame as described", data: encoded.Bytes()},
+		{name: "frame larger than described", data: huge, errorMsg: "too large to decode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := createTempFile(t, "jpeg.pdf", jpegImagePDF(tt.data))
+			f, r, err := pdf.Open(path)
+			if err != nil {
+				t.Fatalf("Open() unexpected error = %v", err)
+			}
+			defer f.Close()
+
+			img, err := decodeImageXObject(f, r.Page(1).V.Key("Resources").Key("XObject").Key("Im"))
+			if tt.errorMsg == "" {
+				if err != nil || img.Bounds().Dx() != 16 {
+					t.Errorf("decodeImageXObject() = %v, %v, want a 16x16 image", img, err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("decodeImageXObject() error = %v, want it to contain %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestCheckImageSize(t *testing.T) {
+	tests := []struct {
+		width, height int64
+		ok            bool
+	}{
+		{4000, 4000, true},
+		{0, 10, false},
+		{10, -1, false},
+		{4001, 4000, false},
+		{1 << 32, 1 << 32, false}, // Overflows a 64-bit product
+	}
+	for _, tt := range tests {
+		if err := checkImageSize(tt.width, tt.height); (err == nil) != tt.ok {
+			t.Errorf("checkImageSize(%d, %d) error = %v, want ok %t", tt.width, tt.height, err, tt.ok)
+		}
+	}
+}
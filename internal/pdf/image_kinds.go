@@ -0,0 +1,316 @@
+package pdf
+
+import (
+	"image"
+	"math"
+)
+
+// Kinds of images that asset classification recognizes
+const (
+	ImageKindBarcode   = "barcode"
+	ImageKindQRCode    = "qr_code"
+	ImageKindSignature = "signature"
+	ImageKindStamp     = "stamp"
+)
+
+const (
+	// decodedKindConfidence is the confidence of a code whose payload was decoded
+	decodedKindConfidence = 0.95
+	// detectedCodeConfidence is the confidence of a code whose payload could not be read
+	detectedCodeConfidence = 0.6
+	// maxClassifyDimension bounds the side of the bitmap shapes are measured on
+	maxClassifyDimension = 600
+	// maxInkShare is the largest share of dark pixels an image of marks on paper has
+	maxInkShare = 0.35
+	// minPaperShare is the least share of pixels near white an image of marks on paper has
+	minPaperShare = 0.5
+	// paperLuminance is the luminance from which a pixel counts as paper
+	paperLuminance = 192
+	// stampBand is the depth of a stamp's border as a share of its size
+	stampBand = 0.15
+	// minStampCoverage is the share of a stamp's outline its border must ink
+	minStampCoverage = 0.85
+	// stampConfidence and coloredStampConfidence rate stamps in black and in color ink
+	stampConfidence        = 0.75
+	coloredStampConfidence = 0.9
+	// minColoredSaturation is the mean saturation of ink that counts as colored
+	minColoredSaturation = 0.25
+	// signatureConfidence rates a detected signature
+	signatureConfidence = 0.7
+	// maxSignatureComponents is the most separate strokes of note a signature has
+	maxSignatureComponents = 15
+)
+
+// imageKind is what an image was classified as
+type imageKind struct {
+	kind       string
+	symbology  string
+	payload    string
+	confidence float64
+}
+
+// classifyImage recognizes barcodes and QR codes, decoding their payloads, and the
+// shapes of rubber stamps and handwritten signatures. It reports false for images of
+// none of these kinds.
+func classifyImage(img image.Image) (imageKind, bool) {
+	luminance, b := binarize(img)
+	if b.width == 0 || b.height == 0 {
+		return imageKind{}, false
+	}
+
+	payload, detected, ok := decodeQR(b)
+	if ok {
+		return imageKind{
+			kind: ImageKindQRCode, symbology: SymbologyQR, payload: payload, confidence: decodedKindConfidence,
+		}, true
+	}
+	if symbology, payload, ok := decodeLinearBarcode(b); ok {
+		return imageKind{
+			kind: ImageKindBarcode, symbology: symbology, payload: payload, confidence: decodedKindConfidence,
+		}, true
+	}
+	if detected {
+		return imageKind{kind: ImageKindQRCode, symbology: SymbologyQR, confidence: detectedCodeConfidence}, true
+	}
+	if looksLikeBarcode(b) {
+		return imageKind{kind: ImageKindBarcode, confidence: detectedCodeConfidence}, true
+	}
+
+	// Stamps and signatures are sparse ink on paper, unlike photographs and drawings
+	ink, paper := 0, 0
+	for i, l := range luminance {
+		if b.dark[i] {
+			ink++
+		} else if l >= paperLuminance {
+			paper++
+		}
+	}
+	total := float64(len(luminance))
+	if ink == 0 || float64(ink) > total*maxInkShare || float64(paper) < total*minPaperShare {
+		return imageKind{}, false
+	}
+
+	shrunk := shrinkBitmap(b, maxClassifyDimension)
+	box, inked := inkBounds(shrunk)
+	if !inked {
+		return imageKind{}, false
+	}
+	if isStamp(shrunk, box) {
+		confidence := stampConfidence
+		if inkSaturation(img, b) >= minColoredSaturation {
+			confidence = coloredStampConfidence
+		}
+		return imageKind{kind: ImageKindStamp, confidence: confidence}, true
+	}
+	if isSignature(shrunk, box, float64(ink)/total) {
+		return imageKind{kind: ImageKindSignature, confidence: signatureConfidence}, true
+	}
+	return imageKind{}, false
+}
+
+// shrinkBitmap scales a bitmap down so neither side exceeds maxSide, keeping a pixel dark
+// when any pixel it covers is, so thin strokes stay connected
+func shrinkBitmap(b bitmap, maxSide int) bitmap {
+	factor := (max(b.width, b.height) + maxSide - 1) / maxSide
+	if factor <= 1 {
+		return b
+	}
+	small := bitmap{width: (b.width + factor - 1) / factor, height: (b.height + factor - 1) / factor}
+	small.dark = make([]bool, small.width*small.height)
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			if b.dark[y*b.width+x] {
+				small.dark[y/factor*small.width+x/factor] = true
+			}
+		}
+	}
+	return small
+}
+
+// inkBounds returns the smallest rectangle holding every dark pixel of a bitmap
+func inkBounds(b bitmap) (image.Rectangle, bool) {
+	box := image.Rectangle{Min: image.Point{X: b.width, Y: b.height}}
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			if b.dark[y*b.width+x] {
+				box.Min.X, box.Min.Y = min(box.Min.X, x), min(box.Min.Y, y)
+				box.Max.X, box.Max.Y = max(box.Max.X, x+1), max(box.Max.Y, y+1)
+			}
+		}
+	}
+	return box, !box.Empty()
+}
+
+// isStamp reports whether ink forms the round or rectangular border of a rubber stamp
+// with marks inside it
+func isStamp(b bitmap, box image.Rectangle) bool {
+	width, height := float64(box.Dx()), float64(box.Dy())
+	if width < 20 || height < 20 || width > height*5 || height > width*5 {
+		return false
+	}
+	cx, cy := float64(box.Min.X)+width/2, float64(box.Min.Y)+height/2
+	rx, ry := width/2, height/2
+
+	// A round stamp inks a ring at nearly every angle around its center
+	round := width <= height*2 && height <= width*2
+	if round {
+		const steps = 360
+		covered := 0
+		for step := 0; step < steps; step++ {
+			angle := 2 * math.Pi * float64(step) / steps
+			for r := 1 - stampBand; r <= 1; r += 0.01 {
+				if b.at(int(cx+rx*r*math.Cos(angle)), int(cy+ry*r*math.Sin(angle))) {
+					covered++
+					break
+				}
+			}
+		}
+		round = float64(covered) >= steps*minStampCoverage
+	}
+	if round {
+		return hasInkWithin(b, box, func(x, y float64) bool {
+			dx, dy := (x-cx)/rx, (y-cy)/ry
+			return dx*dx+dy*dy < (1-2*stampBand)*(1-2*stampBand)
+		})
+	}
+
+	// A rectangular stamp inks a frame along all four sides
+	depth := int(math.Max(1, stampBand*math.Min(width, height)))
+	inkedAcross := func(length int, dark func(along, in int) bool) bool {
+		covered := 0
+		for along := 0; along < length; along++ {
+			for in := 0; in < depth; in++ {
+				if dark(along, in) {
+					covered++
+					break
+				}
+			}
+		}
+		return float64(covered) >= float64(length)*minStampCoverage
+	}
+	framed := inkedAcross(box.Dx(), func(along, in int) bool { return b.at(box.Min.X+along, box.Min.Y+in) }) &&
+		inkedAcross(box.Dx(), func(along, in int) bool { return b.at(box.Min.X+along, box.Max.Y-1-in) }) &&
+		inkedAcross(box.Dy(), func(along, in int) bool { return b.at(box.Min.X+in, box.Min.Y+along) }) &&
+		inkedAcross(box.Dy(), func(along, in int) bool { return b.at(box.Max.X-1-in, box.Min.Y+along) })
+	if !framed {
+		return false
+	}
+	inner := box.Inset(2 * depth)
+	return hasInkWithin(b, box, func(x, y float64) bool {
+		return image.Pt(int(x), int(y)).In(inner)
+	})
+}
+
+// hasInkWithin reports whether some but not most of the pixels of a box inside a region
+// are dark, as the text within a stamp's border makes them
+func hasInkWithin(b bitmap, box image.Rectangle, inside func(x, y float64) bool) bool {
+	ink, area := 0, 0
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		for x := box.Min.X; x < box.Max.X; x++ {
+			if inside(float64(x)+0.5, float64(y)+0.5) {
+				area++
+				if b.at(x, y) {
+					ink++
+				}
+			}
+		}
+	}
+	return area > 0 && float64(ink) >= float64(area)*0.01 && float64(ink) <= float64(area)*0.6
+}
+
+// isSignature reports whether ink forms a handwritten signature: a wide, sparse trace
+// of a few long connected strokes rather than the many small marks of printed text
+func isSignature(b bitmap, box image.Rectangle, inkShare float64) bool {
+	if inkShare < 0.005 || inkShare > 0.25 {
+		return false
+	}
+	width, height := float64(box.Dx()), float64(box.Dy())
+	if width < 20 || width < height*1.5 || width > height*12 {
+		return false
+	}
+
+	components := connectedComponents(b, box)
+	ink := 0
+	for _, c := range components {
+		ink += c.pixels
+	}
+	if float64(ink) > width*height*maxInkShare {
+		return false
+	}
+	largest, significant := components[0], 0
+	for _, c := range components {
+		if c.pixels > largest.pixels {
+			largest = c
+		}
+		if float64(c.pixels) >= float64(ink)*0.01 {
+			significant++
+		}
+	}
+	return float64(largest.bounds.Dx()) >= width*0.3 && float64(largest.bounds.Dy()) >= height*0.25 &&
+		significant <= maxSignatureComponents
+}
+
+// inkComponent is a set of dark pixels connected to one another
+type inkComponent struct {
+	pixels int
+	bounds image.Rectangle
+}
+
+// connectedComponents returns the groups of dark pixels within a box that touch,
+// counting diagonal neighbors
+func connectedComponents(b bitmap, box image.Rectangle) []inkComponent {
+	var components []inkComponent
+	seen := make([]bool, len(b.dark))
+	var stack []image.Point
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		for x := box.Min.X; x < box.Max.X; x++ {
+			if !b.at(x, y) || seen[y*b.width+x] {
+				continue
+			}
+			c := inkComponent{bounds: image.Rect(x, y, x+1, y+1)}
+			seen[y*b.width+x] = true
+			stack = append(stack[:0], image.Pt(x, y))
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				c.pixels++
+				c.bounds = c.bounds.Union(image.Rect(p.X, p.Y, p.X+1, p.Y+1))
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						n := image.Pt(p.X+dx, p.Y+dy)
+						if b.at(n.X, n.Y) && !seen[n.Y*b.width+n.X] {
+							seen[n.Y*b.width+n.X] = true
+							stack = append(stack, n)
+						}
+					}
+				}
+			}
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+// inkSaturation returns the mean saturation of an image's dark pixels, high for the
+// colored ink stamps are often pressed in
+func inkSaturation(img image.Image, b bitmap) float64 {
+	bounds := img.Bounds()
+	sum, count := 0.0, 0
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			if !b.dark[y*b.width+x] {
+				continue
+			}
+			r, g, bl, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			high, low := max(r, g, bl), min(r, g, bl)
+			if high > 0 {
+				sum += float64(high-low) / float64(high)
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
@@ -0,0 +1,95 @@
+package pdf
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+// paperImage returns a white image to draw marks on
+func paperImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	return img
+}
+
+// fillRect inks a rectangle of an image
+func fillRect(img *image.RGBA, r image.Rectangle, ink color.Color) {
+	draw.Draw(img, r, image.NewUniform(ink), image.Point{}, draw.Src)
+}
+
+// textBlocks inks rows of small separate blocks the way a line of printed text marks paper
+func textBlocks(img *image.RGBA, origin image.Point, rows, columns int, ink color.Color) {
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			x, y := origin.X+column*10, origin.Y+row*18
+			fillRect(img, image.Rect(x, y, x+6, y+10), ink)
+		}
+	}
+}
+
+func TestClassifyImage(t *testing.T) {
+	red := color.RGBA{R: 0xD0, G: 0x10, B: 0x10, A: 0xFF}
+
+	roundStamp := paperImage(300, 300)
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 300; x++ {
+			if r := math.Hypot(float64(x)-150, float64(y)-150); r >= 128 && r <= 140 {
+				roundStamp.Set(x, y, red)
+			}
+		}
+	}
+	textBlocks(roundStamp, image.Point{X: 100, Y: 125}, 2, 10, red)
+
+	frameStamp := paperImage(320, 160)
+	fillRect(frameStamp, image.Rect(10, 10, 310, 18), color.Black)
+	fillRect(frameStamp, image.Rect(10, 142, 310, 150), color.Black)
+	fillRect(frameStamp, image.Rect(10, 10, 18, 150), color.Black)
+	fillRect(frameStamp, image.Rect(302, 10, 310, 150), color.Black)
+	textBlocks(frameStamp, image.Point{X: 70, Y: 60}, 2, 18, color.Black)
+
+	signature := paperImage(400, 120)
+	for x := 20.0; x <= 380; x += 0.25 {
+		y := 60 + 35*math.Sin(x/25)*math.Cos(x/90)
+		fillRect(signature, image.Rect(int(x), int(y), int(x)+3, int(y)+3), color.Black)
+	}
+
+	printed := paperImage(400, 120)
+	textBlocks(printed, image.Point{X: 20, Y: 20}, 4, 36, color.Black)
+
+	photo := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			photo.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: uint8((x * y) % 256), A: 0xFF})
+		}
+	}
+
+	tests := []struct {
+		name           string
+		img            image.Image
+		wantKind       string
+		wantPayload    string
+		wantConfidence float64
+	}{
+		{"qr code", moduleImage(qrVersion2M, 4), ImageKindQRCode, "https://example.com/inv/42", decodedKindConfidence},
+		{"barcode", barImage(code128Modules, 2, 60), ImageKindBarcode, "INV-2024-0042", decodedKindConfidence},
+		{"round stamp in red ink", roundStamp, ImageKindStamp, "", coloredStampConfidence},
+		{"framed stamp in black ink", frameStamp, ImageKindStamp, "", stampConfidence},
+		{"signature", signature, ImageKindSignature, "", signatureConfidence},
+		{"printed text", printed, "", "", 0},
+		{"photograph", photo, "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, ok := classifyImage(tt.img)
+			if ok != (tt.wantKind != "") || kind.kind != tt.wantKind || kind.payload != tt.wantPayload {
+				t.Fatalf("classifyImage() = %+v (%t), want %q with payload %q", kind, ok, tt.wantKind, tt.wantPayload)
+			}
+			if kind.confidence != tt.wantConfidence {
+				t.Errorf("classifyImage() confidence = %g, want %g", kind.confidence, tt.wantConfidence)
+			}
+		})
+	}
+}
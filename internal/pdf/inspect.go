@@ -0,0 +1,254 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// defaultInspectBytes is how much stream data pdf_inspect_object includes by default
+	defaultInspectBytes = 1024
+	// maxInspectBytes bounds the stream data pdf_inspect_object includes
+	maxInspectBytes = 64 * 1024
+	// maxInspectDecodedLength bounds how far a stream is decoded to measure its length
+	maxInspectDecodedLength = 64 * 1024 * 1024
+	// maxInspectValues bounds the values rendered from one object
+	maxInspectValues = 2000
+	// maxInspectDepth bounds the nesting of rendered values
+	maxInspectDepth = 16
+)
+
+// inspectKinds names the kinds of PDF values
+var inspectKinds = map[pdf.ValueKind]string{
+	pdf.Null:    "null",
+	pdf.Bool:    "boolean",
+	pdf.Integer: "integer",
+	pdf.Real:    "real",
+	pdf.String:  "string",
+	pdf.Name:    "name",
+	pdf.Dict:    "dictionary",
+	pdf.Array:   "array",
+	pdf.Stream:  "stream",
+}
+
+// ObjectInspector reads single objects of PDF files for debugging malformed documents
+type ObjectInspector struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewObjectInspector creates a new object inspector with the specified constraints
+func NewObjectInspector(maxFileSize int64) *ObjectInspector {
+	return &ObjectInspector{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// Inspect loads an indirect object by number and generation and describes it: its kind and
+// type, its value with references to other objects shown as "12 0 R" rather than followed,
+// and for streams their filters, lengths, and the start of their data. Names are rendered
+// as "/Name", strings as "(text)" or "<hex>", and dictionaries as JSON objects.
+func (o *ObjectInspector) Inspect(req PDFInspectObjectRequest) (*PDFInspectObjectResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	if req.Object <= 0 || int64(req.Object) > math.MaxUint32 {
+		return nil, fmt.Errorf("object number must be positive, got %d", req.Object)
+	}
+	if req.Generation < 0 || req.Generation > math.MaxUint16 {
+		return nil, fmt.Errorf("generation must be between 0 and %d, got %d", math.MaxUint16, req.Generation)
+	}
+	maxBytes := req.MaxBytes
+	switch {
+	case maxBytes < 0:
+		return nil, fmt.Errorf("max_bytes cannot be negative")
+	case maxBytes == 0:
+		maxBytes = defaultInspectBytes
+	case maxBytes > maxInspectBytes:
+		maxBytes = maxInspectBytes
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: o.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	ref := extraction.ObjectRef{ID: uint32(req.Object), Gen: uint16(req.Generation)}
+	result := &PDFInspectObjectResult{Path: req.Path, Object: req.Object, Generation: req.Generation}
+	found := false
+	for _, entry := range crossReferenceEntries(r) {
+		if entry.id != ref.ID {
+			continue
+		}
+		if entry.gen != ref.Gen {
+			return nil, fmt.Errorf("object %d is stored with generation %d, not %d", ref.ID, entry.gen, ref.Gen)
+		}
+		if entry.inStream {
+			result.ObjectStream = int(entry.stream)
+		} else {
+			result.Offset = entry.offset
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("object %s is not in the cross-reference table", ref)
+	}
+
+	v, err := extraction.ObjectByRef(r, ref)
+	if err != nil {
+		return nil, err
+	}
+	result.Kind = inspectKinds[v.Kind()]
+	if v.Kind() == pdf.Dict || v.Kind() == pdf.Stream {
+		result.Type, result.Subtype = v.Key("Type").Name(), v.Key("Subtype").Name()
+	}
+	budget := maxInspectValues
+	result.Value = inspectValue(v, ref, 0, &budget)
+	result.Truncated = budget < 0
+	if v.Kind() == pdf.Stream {
+		result.Stream = inspectStream(doc, v, maxBytes)
+	}
+	return result, nil
+}
+
+// inspectValue renders a value found inside the object owner in JSON form, spending one of
+// the budgeted values on each; past the budget or the depth limit, values are elided as "..."
+func inspectValue(v pdf.Value, owner extraction.ObjectRef, depth int, budget *int) any {
+	*budget--
+	if *budget < 0 || depth > maxInspectDepth {
+		*budget = min(*budget, -1)
+		return "..."
+	}
+	if ref := extraction.RefOf(v); ref.ID != 0 && ref != owner {
+		return ref.String()
+	}
+
+	switch v.Kind() {
+	case pdf.Bool:
+		return v.Bool()
+	case pdf.Integer:
+		return v.Int64()
+	case pdf.Real:
+		return v.Float64()
+	case pdf.String:
+		if s := v.RawString(); isPrintable(s) {
+			return "(" + s + ")"
+		}
+		return "<" + hex.EncodeToString([]byte(v.RawString())) + ">"
+	case pdf.Name:
+		return "/" + v.Name()
+	case pdf.Array:
+		values := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, inspectValue(v.Index(i), owner, depth+1, budget))
+		}
+		return values
+	case pdf.Dict, pdf.Stream:
+		entries := make(map[string]any, len(v.Keys()))
+		for _, key := range v.Keys() {
+			entries[key] = inspectValue(v.Key(key), owner, depth+1, budget)
+		}
+		return entries
+	default:
+		return nil
+	}
+}
+
+// inspectStream describes a stream's filters and lengths and includes up to maxBytes of its
+// data, decoded when the parser supports its filters and as stored otherwise
+func inspectStream(file io.ReaderAt, v pdf.Value, maxBytes int) *InspectedStream {
+	stream := &InspectedStream{Length: v.Key("Length").Int64()}
+	switch filter := v.Key("Filter"); filter.Kind() {
+	case pdf.Name:
+		stream.Filters = []string{filter.Name()}
+	case pdf.Array:
+		for i := 0; i < filter.Len(); i++ {
+			stream.Filters = append(stream.Filters, filter.Index(i).Name())
+		}
+	}
+
+	var data []byte
+	for _, filter := range stream.Filters {
+		if filter != "FlateDecode" && filter != "ASCII85Decode" {
+			stream.DecodeError = fmt.Sprintf("streams compressed with %s are not decoded", filter)
+		}
+	}
+	if stream.DecodeError == "" {
+		var length int64
+		var err error
+		if data, length, err = decodeStreamPrefix(v, maxBytes); err != nil {
+			stream.DecodeError = err.Error()
+		} else {
+			stream.Decoded, stream.DecodedLength = true, length
+			stream.DataTruncated = length > int64(len(data))
+		}
+	}
+	if !stream.Decoded {
+		offset, err := streamDataOffset(v)
+		if err != nil {
+			stream.DecodeError = err.Error()
+			return stream
+		}
+		data = make([]byte, max(0, min(stream.Length, int64(maxBytes))))
+		n, err := file.ReadAt(data, offset)
+		if err != nil && err != io.EOF {
+			stream.DecodeError = fmt.Sprintf("failed to read stream data: %v", err)
+		}
+		data = data[:n]
+		stream.DataTruncated = stream.Length > int64(n)
+	}
+
+	switch {
+	case len(data) == 0:
+	case isPrintable(string(data)):
+		stream.Data, stream.DataEncoding = string(data), "text"
+	default:
+		stream.Data, stream.DataEncoding = hex.EncodeToString(data), "hex"
+	}
+	return stream
+}
+
+// decodeStreamPrefix decodes the first maxBytes of a stream and measures its decoded length,
+// reading no further than maxInspectDecodedLength
+func decodeStreamPrefix(v pdf.Value, maxBytes int) (data []byte, length int64, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("failed to decode stream: %v", rec)
+		}
+	}()
+
+	reader := v.Reader()
+	var prefix bytes.Buffer
+	if _, err := io.CopyN(&prefix, reader, int64(maxBytes)); err != nil && err != io.EOF {
+		return nil, 0, fmt.Errorf("failed to decode stream: %w", err)
+	}
+	rest, err := io.Copy(io.Discard, io.LimitReader(reader, maxInspectDecodedLength))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode stream: %w", err)
+	}
+	return prefix.Bytes(), int64(prefix.Len()) + rest, nil
+}
+
+// isPrintable reports whether bytes read as text: valid UTF-8 without control characters
+// other than line breaks and tabs
+func isPrintable(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	return !strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t'
+	})
+}
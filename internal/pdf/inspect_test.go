@@ -0,0 +1,112 @@
+package pdf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+func TestObjectInspector_Inspect(t *testing.T) {
+	path := createTempFile(t, "bloated.pdf", bloatedPDF(""))
+	inspector := NewObjectInspector(10 * 1024 * 1024)
+
+	page, err := inspector.Inspect(PDFInspectObjectRequest{Path: path, Object: 3})
+	if err != nil {
+		t.Fatalf("Inspect(page) unexpected error = %v", err)
+	}
+	entries, _ := page.Value.(map[string]any)
+	if page.Kind != "dictionary" || page.Type != "Page" || page.Offset == 0 || page.Stream != nil {
+		t.Errorf("Inspect(page) = %+v, want a page dictionary stored at an offset", page)
+	}
+	if entries["Parent"] != "2 0 R" || fmt.Sprint(entries["MediaBox"]) != "[0 0 612 792]" ||
+		fmt.Sprint(entries["Resources"].(map[string]any)["XObject"]) != "map[A:5 0 R B:6 0 R]" {
+		t.Errorf("Inspect(page) value = %v, want references left unresolved", page.Value)
+	}
+
+	content, err := inspector.Inspect(PDFInspectObjectRequest{Path: path, Object: 4, MaxBytes: 12})
+	if err != nil {
+		t.Fatalf("Inspect(content) unexpected error = %v", err)
+	}
+	stream := content.Stream
+	if content.Kind != "stream" || stream == nil || !stream.Decoded || stream.DataEncoding != "text" ||
+		stream.Data != "BT /F1 12 Tf" || !stream.DataTruncated || stream.DecodedLength != stream.Length {
+		t.Errorf("Inspect(content) = %+v, stream %+v, want the first 12 bytes as text", content, stream)
+	}
+
+	image, err := inspector.Inspect(PDFInspectObjectRequest{Path: path, Object: 5, MaxBytes: 4})
+	if err != nil {
+		t.Fatalf("Inspect(image) unexpected error = %v", err)
+	}
+	if image.Subtype != "Image" || image.Stream.DataEncoding != "hex" || image.Stream.Data != "00010203" {
+		t.Errorf("Inspect(image) = %+v, stream %+v, want samples in hex", image, image.Stream)
+	}
+
+	// Objects packed into object streams are found there, their streams decoded
+	result, err := NewOptimizer(10*1024*1024).Optimize(context.Background(), PDFOptimizeRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Optimize() unexpected error = %v", err)
+	}
+	_, r := optimizedDocument(t, result)
+	pageRef, contentRef := extraction.RefOf(r.Page(1).V), extraction.RefOf(r.Page(1).V.Key("Contents"))
+	data, _ := base64.StdEncoding.DecodeString(result.Data)
+	optimized := filepath.Join(t.TempDir(), "optimized.pdf")
+	if err := os.WriteFile(optimized, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err = inspector.Inspect(PDFInspectObjectRequest{Path: optimized, Object: int(pageRef.ID)})
+	if err != nil {
+		t.Fatalf("Inspect(packed page) unexpected error = %v", err)
+	}
+	if page.Type != "Page" || page.ObjectStream == 0 || page.Offset != 0 {
+		t.Errorf("Inspect(packed page) = %+v, want a page stored in an object stream", page)
+	}
+	content, err = inspector.Inspect(PDFInspectObjectRequest{Path: optimized, Object: int(contentRef.ID)})
+	if err != nil {
+		t.Fatalf("Inspect(compressed content) unexpected error = %v", err)
+	}
+	if fmt.Sprint(content.Stream.Filters) != "[FlateDecode]" || !content.Stream.Decoded ||
+		!strings.Contains(content.Stream.Data, "Hello optimize") {
+		t.Errorf("Inspect(compressed content) stream = %+v, want decoded text", content.Stream)
+	}
+}
+
+func TestObjectInspector_Errors(t *testing.T) {
+	path := createTempFile(t, "bloated.pdf", bloatedPDF(""))
+	inspector := NewObjectInspector(10 * 1024 * 1024)
+
+	tests := []struct {
+		name     string
+		req      PDFInspectObjectRequest
+		errorMsg string
+	}{
+		{name: "empty path", req: PDFInspectObjectRequest{Object: 1}, errorMsg: "path cannot be empty"},
+		{name: "missing file", req: PDFInspectObjectRequest{Path: path + ".gone", Object: 1}, errorMsg: "does not exist"},
+		{name: "object zero", req: PDFInspectObjectRequest{Path: path}, errorMsg: "must be positive"},
+		{name: "unknown object", req: PDFInspectObjectRequest{Path: path, Object: 99}, errorMsg: "not in the cross-reference"},
+		{
+			name:     "other generation",
+			req:      PDFInspectObjectRequest{Path: path, Object: 3, Generation: 1},
+			errorMsg: "stored with generation 0",
+		},
+		{
+			name:     "negative max bytes",
+			req:      PDFInspectObjectRequest{Path: path, Object: 3, MaxBytes: -1},
+			errorMsg: "cannot be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := inspector.Inspect(tt.req)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Inspect() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,576 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Invoice extraction constants
+const (
+	// invoiceValueWords is the most words a date or amount after a label may span
+	invoiceValueWords = 4
+	// invoiceVendorLines is how many lines from the top of the first page may name the vendor
+	invoiceVendorLines = 6
+	// invoiceTolerance is how far apart amounts may be and still agree, allowing for rounding
+	invoiceTolerance = 0.01
+)
+
+// invoiceTypes are the document types whose fields pdf_extract_invoice reads
+var invoiceTypes = []string{"invoice", "receipt"}
+
+// Labels of invoice fields. The invoice number follows its label; a transaction, order, or
+// reference number stands in for it on receipts.
+var (
+	invoiceNumberLabel = regexp.MustCompile(`(?i)\b(?:invoice|receipt)\s*(?:no\.?|number|num\.?|#|id)?\s*[:#.]?\s*` +
+		`([A-Z0-9][A-Z0-9/-]*\d[A-Z0-9/-]*)\b`)
+	referenceNumberLabel = regexp.MustCompile(`(?i)\b(?:transaction|order|reference|ref)\.?\s*(?:no\.?|number|#|id)` +
+		`\s*[:#.]?\s*([A-Z0-9][A-Z0-9/-]*\d[A-Z0-9/-]*)\b`)
+	invoiceDateLabel = regexp.MustCompile(`(?i)\b(?:invoice\s+date|receipt\s+date|date\s+of\s+issue|issue\s+date|` +
+		`date\s+issued|issued(?:\s+on)?|date)\b\s*:?`)
+	invoiceDueLabel    = regexp.MustCompile(`(?i)\b(?:due\s+date|date\s+due|payment\s+due|due\s+by|pay\s+by|due)\b\s*:?`)
+	invoiceVendorLabel = regexp.MustCompile(`(?i)\b(?:from|vendor|seller|supplier|merchant|sold\s+by|issued\s+by|` +
+		`billed\s+by|payable\s+to|remit\s+to)\s*:\s*`)
+	invoicePartyLabel = regexp.MustCompile(`(?i)\b(?:bill(?:ed)?\s+to|ship(?:ped)?\s+to|sold\s+to|customer|client)\b`)
+	invoiceTitle      = regexp.MustCompile(`(?i)\b(?:tax\s+)?(?:invoice|receipt)\b`)
+
+	invoiceSubtotalLabel = regexp.MustCompile(`(?i)\b(?:sub[\s-]?total|net\s+(?:total|amount)|` +
+		`total\s+(?:excl\.?|excluding|before\s+tax|net))\b`)
+	invoiceTaxLabel     = regexp.MustCompile(`(?i)\b(?:sales\s+tax|tax|vat|gst|hst)\b`)
+	invoiceTaxIncluded  = regexp.MustCompile(`(?i)\b(?:incl|including|inc)\b`)
+	invoiceAmountDue    = regexp.MustCompile(`(?i)\b(?:amount\s+due|balance\s+due|total\s+due|amount\s+payable)\b`)
+	invoiceTotalLabel   = regexp.MustCompile(`(?i)\b(?:grand\s+total|total)\b`)
+	invoiceTotalsLabels = []*regexp.Regexp{invoiceSubtotalLabel, invoiceTaxLabel, invoiceAmountDue, invoiceTotalLabel}
+)
+
+// Headers of the columns of a line item table
+var (
+	quantityHeader    = regexp.MustCompile(`(?i)\b(?:qty|quantity|hours|hrs|units|count)\b`)
+	unitHeader        = regexp.MustCompile(`(?i)\bunit\b`)
+	amountHeader      = regexp.MustCompile(`(?i)\b(?:amount|total|subtotal|extended|ext)\b`)
+	priceHeader       = regexp.MustCompile(`(?i)\b(?:price|rate|each|cost)\b`)
+	descriptionHeader = regexp.MustCompile(`(?i)\b(?:description|items?|products?|services?|details|particulars|` +
+		`article)\b`)
+	codeHeader = regexp.MustCompile(`(?i)(?:#|\b(?:no|code|sku|number|id)\.?)$`)
+)
+
+// InvoiceExtractor reads the vendor, number, dates, line items, and totals of invoices and
+// receipts
+type InvoiceExtractor struct {
+	maxFileSize int64
+	validator   *Validator
+	classifier  *Classifier
+	engine      *extraction.DefaultEngine
+}
+
+// NewInvoiceExtractor creates a new invoice extractor with the built-in classification
+// profiles
+func NewInvoiceExtractor(maxFileSize int64) *InvoiceExtractor {
+	return &InvoiceExtractor{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+		classifier:  NewClassifier(maxFileSize),
+		engine:      extraction.NewEngineWithConfig(maxFileSize, maxFileSize, false),
+	}
+}
+
+// SetProfiles sets the classification profiles that decide whether a document is an
+// invoice or receipt
+func (e *InvoiceExtractor) SetProfiles(custom *ClassificationProfiles) {
+	e.classifier.SetProfiles(custom)
+}
+
+// textLine is a line of a document and the page it is on
+type textLine struct {
+	pageLine
+	page int
+}
+
+// documentLines is the text of a document's pages read line by line, along with the
+// currencies marking its amounts
+type documentLines struct {
+	numbering     *extraction.PageNumbering
+	lines         []textLine
+	bodySizes     map[int]float64 // Median font size of each page read
+	currencies    map[string]int
+	currencyOrder []string
+	failedPages   []int
+}
+
+// newDocumentLines prepares to read the lines of a document
+func newDocumentLines(numbering *extraction.PageNumbering) *documentLines {
+	return &documentLines{numbering: numbering, bodySizes: make(map[int]float64), currencies: make(map[string]int)}
+}
+
+// read reads the lines and amounts of pages from through to; pages that cannot be read are
+// recorded as failed
+func (d *documentLines) read(ctx context.Context, from, to int) error {
+	for pageNum := from; pageNum <= to; pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pageLines, bodySize, err := readPageLines(d.numbering, pageNum)
+		if err != nil {
+			d.failedPages = append(d.failedPages, pageNum)
+			continue
+		}
+		d.bodySizes[pageNum] = bodySize
+		for _, line := range pageLines {
+			d.lines = append(d.lines, textLine{pageLine: line, page: pageNum})
+		}
+		if text, err := pageText(d.numbering, pageNum); err == nil {
+			for _, entity := range extraction.FindEntities(text, []string{extraction.EntityAmount}) {
+				if entity.Currency != "" && d.currencies[entity.Currency] == 0 {
+					d.currencyOrder = append(d.currencyOrder, entity.Currency)
+				}
+				d.currencies[entity.Currency]++
+			}
+		}
+	}
+	return nil
+}
+
+// classify reads the first pages of a document and ranks its text against the profiles of
+// a classifier, returning the best type and its score
+func (d *documentLines) classify(ctx context.Context, classifier *Classifier) (string, float64, error) {
+	if err := d.read(ctx, 1, min(d.numbering.Count(), classifyMaxPages)); err != nil {
+		return "", 0, err
+	}
+	var text strings.Builder
+	for _, line := range d.lines {
+		text.WriteString(line.Text)
+		text.WriteByte('\n')
+	}
+	if candidates := classifier.rank(text.String(), 1); len(candidates) > 0 {
+		return candidates[0].Type, candidates[0].Score, nil
+	}
+	return "", 0, nil
+}
+
+// readRest reads the pages classify did not
+func (d *documentLines) readRest(ctx context.Context) error {
+	return d.read(ctx, min(d.numbering.Count(), classifyMaxPages)+1, d.numbering.Count())
+}
+
+// currency returns the ISO code of the currency marking most amounts, the first seen of
+// those marking as many
+func (d *documentLines) currency() string {
+	best := ""
+	for _, currency := range d.currencyOrder {
+		if best == "" || d.currencies[currency] > d.currencies[best] {
+			best = currency
+		}
+	}
+	return best
+}
+
+// itemArea is the height a line item covers on its page, so its text is not read again as
+// a total
+type itemArea struct {
+	page        int
+	bottom, top float64
+}
+
+// ExtractInvoice classifies a document by the text of its first pages and, when it is an
+// invoice or receipt or req.Force is set, reads its fields: the vendor, the invoice number,
+// the invoice and due dates, the line items of tables with description and amount columns
+// or of lines ending in a quantity, price, and amount that agree, and the subtotal, tax,
+// total, and amount due. Fields are found by their labels and amounts are normalized as
+// table cell values. Totals that do not add up are reported as warnings.
+func (e *InvoiceExtractor) ExtractInvoice(
+	ctx context.Context, req PDFExtractInvoiceRequest,
+) (*PDFExtractInvoiceResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := core.OpenShared(ctx, req.Path, core.Options{Validate: e.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	numbering := extraction.NewPageNumbering(doc.Reader)
+	result := &PDFExtractInvoiceResult{Path: req.Path, TotalPages: numbering.Count()}
+	document := newDocumentLines(numbering)
+	defer func() { result.FailedPages = document.failedPages }()
+	if result.DocumentType, result.Score, err = document.classify(ctx, e.classifier); err != nil {
+		return nil, err
+	}
+	result.Detected = slices.Contains(invoiceTypes, result.DocumentType)
+	if !result.Detected && !req.Force {
+		return result, nil
+	}
+	if err := document.readRest(ctx); err != nil {
+		return nil, err
+	}
+
+	tables, err := detectTables(ctx, e.engine, req.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	invoice := &InvoiceFields{LineItems: []InvoiceLineItem{}}
+	areas := tableLineItems(tables, invoice)
+	if len(invoice.LineItems) == 0 {
+		areas = textLineItems(document.lines, invoice)
+	}
+	readInvoiceFields(document.lines, areas, invoice)
+	invoice.Currency = document.currency()
+	result.Invoice = invoice
+	result.Warnings = checkInvoiceTotals(invoice)
+	return result, nil
+}
+
+// readInvoiceFields reads the labeled fields of an invoice from its lines. Lines within
+// line items are not read for totals, and the last total of each kind wins, since totals
+// follow the items.
+func readInvoiceFields(lines []textLine, areas []itemArea, invoice *InvoiceFields) {
+	reference := ""
+	for _, line := range lines {
+		if m := invoiceNumberLabel.FindStringSubmatch(line.Text); m != nil && invoice.InvoiceNumber == "" {
+			invoice.InvoiceNumber = m[1]
+		}
+		if m := referenceNumberLabel.FindStringSubmatch(line.Text); m != nil && reference == "" {
+			reference = m[1]
+		}
+		if invoice.InvoiceDate == "" {
+			invoice.InvoiceDate = labeledDate(line.Text, invoiceDateLabel, true)
+		}
+		if invoice.DueDate == "" {
+			invoice.DueDate = labeledDate(line.Text, invoiceDueLabel, false)
+		}
+		if loc := invoiceVendorLabel.FindStringIndex(line.Text); loc != nil && invoice.Vendor == "" {
+			invoice.Vendor = vendorName(line.Text[loc[1]:])
+		}
+		if inItemArea(areas, line) {
+			continue
+		}
+
+		var field *string
+		var loc []int
+		switch {
+		case invoiceSubtotalLabel.MatchString(line.Text):
+			field, loc = &invoice.Subtotal, invoiceSubtotalLabel.FindStringIndex(line.Text)
+		case invoiceTaxLabel.MatchString(line.Text) && !invoiceTaxIncluded.MatchString(line.Text):
+			field, loc = &invoice.Tax, invoiceTaxLabel.FindStringIndex(line.Text)
+		case invoiceAmountDue.MatchString(line.Text):
+			field, loc = &invoice.AmountDue, invoiceAmountDue.FindStringIndex(line.Text)
+		case invoiceTotalLabel.MatchString(line.Text):
+			field, loc = &invoice.Total, invoiceTotalLabel.FindStringIndex(line.Text)
+		default:
+			continue
+		}
+		if amount := labeledAmount(line.Text[loc[1]:]); amount != "" {
+			*field = amount
+		}
+	}
+
+	if invoice.InvoiceNumber == "" {
+		invoice.InvoiceNumber = reference
+	}
+	if invoice.Vendor == "" {
+		invoice.Vendor = topVendor(lines)
+	}
+	if invoice.Total == "" {
+		invoice.Total = invoice.AmountDue
+	}
+}
+
+// labeledDate returns the date directly after a label on a line, as YYYY-MM-DD. With
+// afterDue set, labels following the word due, as in "Due date", are passed over.
+func labeledDate(text string, label *regexp.Regexp, afterDue bool) string {
+	for _, loc := range label.FindAllStringIndex(text, -1) {
+		before := strings.ToLower(strings.TrimSpace(text[:loc[0]]))
+		if afterDue && strings.HasSuffix(before, "due") {
+			continue
+		}
+		words := strings.Fields(text[loc[1]:])
+		for n := min(len(words), invoiceValueWords); n > 0; n-- {
+			value := strings.TrimRight(strings.Join(words[:n], " "), ",;")
+			if dataType, date := extraction.InferCellValue(value); dataType == extraction.CellTypeDate {
+				return date
+			}
+		}
+	}
+	return ""
+}
+
+// labeledAmount returns the first amount marked with a currency after a label, or else the
+// last number with decimals or thousands separators, as a percentage rate or an ID number
+// may also follow it
+func labeledAmount(text string) string {
+	words := strings.Fields(text)
+	number := ""
+	for i := range words {
+		for n := min(len(words)-i, 3); n > 0; n-- {
+			dataType, value := extraction.InferCellValue(strings.Join(words[i:i+n], " "))
+			if dataType == extraction.CellTypeCurrency {
+				return value
+			}
+		}
+		dataType, value := extraction.InferCellValue(words[i])
+		if dataType == extraction.CellTypeNumber && strings.ContainsAny(words[i], ".,") {
+			number = value
+		}
+	}
+	return number
+}
+
+// vendorName cuts a vendor's name before the label of another party on the same line
+func vendorName(text string) string {
+	if loc := invoicePartyLabel.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+	return strings.Trim(strings.Join(strings.Fields(text), " "), " ,;:-")
+}
+
+// topVendor takes the vendor's name from the first lines of the first page: the first that
+// holds more than a title, a label, a number, or contact details
+func topVendor(lines []textLine) string {
+	for i, line := range lines {
+		if i >= invoiceVendorLines || line.page != lines[0].page {
+			break
+		}
+		text := line.Text
+		labels := []*regexp.Regexp{invoiceNumberLabel, invoiceDateLabel, invoiceDueLabel, invoicePartyLabel}
+		for _, label := range labels {
+			if loc := label.FindStringIndex(text); loc != nil {
+				text = text[:loc[0]]
+			}
+		}
+		text = vendorName(invoiceTitle.ReplaceAllString(text, " "))
+		first, _ := utf8.DecodeRuneInString(text)
+		if text == "" || strings.ContainsAny(text, ":@") || !unicode.IsLetter(first) {
+			continue
+		}
+		return text
+	}
+	return ""
+}
+
+// inItemArea reports whether the middle of a line lies within a line item
+func inItemArea(areas []itemArea, line textLine) bool {
+	middle := (line.Top + line.Bottom) / 2
+	for _, area := range areas {
+		if area.page == line.page && middle >= area.bottom && middle <= area.top {
+			return true
+		}
+	}
+	return false
+}
+
+// invoiceColumns are the column indexes of a line item table, -1 when it has none
+type invoiceColumns struct {
+	description, quantity, unitPrice, amount int
+}
+
+// lineItemColumns finds the description, quantity, unit price, and amount columns of a
+// table by their headers. A table without a description column takes its first text column
+// as one; a table without an amount or a quantity and unit price holds no line items.
+func lineItemColumns(table extraction.TableElement) (invoiceColumns, bool) {
+	columns := invoiceColumns{description: -1, quantity: -1, unitPrice: -1, amount: -1}
+	assign := func(field *int, c int) {
+		if *field < 0 {
+			*field = c
+		}
+	}
+	for c, column := range table.Columns {
+		switch header := column.Header; {
+		case header == "":
+		case quantityHeader.MatchString(header):
+			assign(&columns.quantity, c)
+		case unitHeader.MatchString(header):
+			assign(&columns.unitPrice, c)
+		case amountHeader.MatchString(header):
+			assign(&columns.amount, c)
+		case priceHeader.MatchString(header):
+			assign(&columns.unitPrice, c)
+		case descriptionHeader.MatchString(header) && !codeHeader.MatchString(header):
+			assign(&columns.description, c)
+		}
+	}
+	if columns.description < 0 {
+		for c, column := range table.Columns {
+			if column.DataType == extraction.CellTypeText && c != columns.quantity && c != columns.unitPrice &&
+				c != columns.amount {
+				columns.description = c
+				break
+			}
+		}
+	}
+	ok := columns.description >= 0 && (columns.amount >= 0 || columns.quantity >= 0 && columns.unitPrice >= 0)
+	return columns, ok
+}
+
+// tableLineItems reads the line items of the tables that have them, page by page. Rows
+// labeled as totals are left to the totals, and rows with a description but no numbers
+// continue the description of the item above. The areas of the items are returned.
+func tableLineItems(tables map[int][]extraction.TableElement, invoice *InvoiceFields) []itemArea {
+	pages := make([]int, 0, len(tables))
+	for pageNum := range tables {
+		pages = append(pages, pageNum)
+	}
+	sort.Ints(pages)
+
+	var areas []itemArea
+	for _, pageNum := range pages {
+		for _, table := range tables[pageNum] {
+			columns, ok := lineItemColumns(table)
+			if !ok {
+				continue
+			}
+			first := len(invoice.LineItems)
+			for _, row := range table.Rows {
+				if row.IsHeader || isTotalsRow(row) {
+					continue
+				}
+				cells := make(map[int]extraction.TableCell)
+				for _, cell := range row.Cells {
+					cells[cell.ColIndex] = cell
+				}
+				item := InvoiceLineItem{
+					Description: strings.Join(strings.Fields(cells[columns.description].Content), " "),
+					Quantity:    cellAmount(cells, columns.quantity),
+					UnitPrice:   cellAmount(cells, columns.unitPrice),
+					Amount:      cellAmount(cells, columns.amount),
+					Page:        pageNum,
+				}
+				switch {
+				case item.Quantity == "" && item.UnitPrice == "" && item.Amount == "":
+					if item.Description == "" || len(invoice.LineItems) == first {
+						continue
+					}
+					last := &invoice.LineItems[len(invoice.LineItems)-1]
+					last.Description = strings.TrimSpace(last.Description + " " + item.Description)
+				default:
+					invoice.LineItems = append(invoice.LineItems, item)
+				}
+				box := row.BoundingBox
+				areas = append(areas, itemArea{page: pageNum, bottom: box.LowerLeft.Y, top: box.UpperRight.Y})
+			}
+		}
+	}
+	return areas
+}
+
+// isTotalsRow reports whether a cell of a row starts with the label of a total
+func isTotalsRow(row extraction.TableRow) bool {
+	for _, cell := range row.Cells {
+		for _, label := range invoiceTotalsLabels {
+			if loc := label.FindStringIndex(cell.Content); loc != nil && loc[0] == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cellAmount returns the normalized number in a column of a row, or "" when the cell is
+// missing or holds no number
+func cellAmount(cells map[int]extraction.TableCell, column int) string {
+	cell, ok := cells[column]
+	if column < 0 || !ok {
+		return ""
+	}
+	dataType, value := cell.DataType, cell.Value
+	if dataType == "" {
+		dataType, value = extraction.InferCellValue(cell.Content)
+	}
+	if dataType != extraction.CellTypeNumber && dataType != extraction.CellTypeCurrency {
+		return ""
+	}
+	return value
+}
+
+// textLineItems reads line items from lines ending in a quantity, a unit price, and an
+// amount that is their product, for invoices whose items are not laid out as a table. The
+// areas of the items are returned.
+func textLineItems(lines []textLine, invoice *InvoiceFields) []itemArea {
+	var areas []itemArea
+	for _, line := range lines {
+		words := strings.Fields(line.Text)
+		if len(words) < 4 {
+			continue
+		}
+		values := make([]float64, 3)
+		texts := make([]string, 3)
+		ok := true
+		for i, word := range words[len(words)-3:] {
+			dataType, value := extraction.InferCellValue(word)
+			number, err := strconv.ParseFloat(value, 64)
+			if (dataType != extraction.CellTypeNumber && dataType != extraction.CellTypeCurrency) || err != nil ||
+				(i == 0 && dataType != extraction.CellTypeNumber) {
+				ok = false
+				break
+			}
+			values[i], texts[i] = number, value
+		}
+		description := strings.Join(words[:len(words)-3], " ")
+		if !ok || values[0] <= 0 || !strings.ContainsFunc(description, unicode.IsLetter) ||
+			math.Abs(values[0]*values[1]-values[2]) > invoiceTolerance {
+			continue
+		}
+		invoice.LineItems = append(invoice.LineItems, InvoiceLineItem{
+			Description: description,
+			Quantity:    texts[0],
+			UnitPrice:   texts[1],
+			Amount:      texts[2],
+			Page:        line.page,
+		})
+		areas = append(areas, itemArea{page: line.page, bottom: line.Bottom, top: line.Top})
+	}
+	return areas
+}
+
+// checkInvoiceTotals reports line items that do not add up to the subtotal, or to the
+// total of an invoice without a subtotal or tax, and a subtotal and tax that do not add up
+// to the total
+func checkInvoiceTotals(invoice *InvoiceFields) []string {
+	var warnings []string
+	sum, summed := 0.0, len(invoice.LineItems) > 0
+	for _, item := range invoice.LineItems {
+		value, ok := parseAmount(item.Amount)
+		summed = summed && ok
+		sum += value
+	}
+	subtotal, hasSubtotal := parseAmount(invoice.Subtotal)
+	tax, hasTax := parseAmount(invoice.Tax)
+	total, hasTotal := parseAmount(invoice.Total)
+	sum = math.Round(sum*100) / 100
+	switch {
+	case summed && hasSubtotal && math.Abs(sum-subtotal) > invoiceTolerance:
+		warnings = append(warnings, fmt.Sprintf("the line items add up to %s, not the subtotal %s",
+			formatAmount(sum), invoice.Subtotal))
+	case summed && !hasSubtotal && !hasTax && hasTotal && math.Abs(sum-total) > invoiceTolerance:
+		warnings = append(warnings, fmt.Sprintf("the line items add up to %s, not the total %s",
+			formatAmount(sum), invoice.Total))
+	}
+	if hasSubtotal && hasTax && hasTotal && math.Abs(subtotal+tax-total) > invoiceTolerance {
+		warnings = append(warnings, fmt.Sprintf("the subtotal %s and tax %s do not add up to the total %s",
+			invoice.Subtotal, invoice.Tax, invoice.Total))
+	}
+	return warnings
+}
+
+// parseAmount parses a normalized amount, reporting false for an empty one
+func parseAmount(value string) (float64, bool) {
+	number, err := strconv.ParseFloat(value, 64)
+	return number, value != "" && err == nil
+}
+
+// formatAmount writes an amount rounded to cents, as table cell values are normalized
+func formatAmount(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
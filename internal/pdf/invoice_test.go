@@ -0,0 +1,115 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// itemizedInvoiceContent is an invoice with a ruled table of line items followed by its totals
+func itemizedInvoiceContent(total string) string {
+	var b strings.Builder
+	text := func(x, y int, s string) {
+		fmt.Fprintf(&b, "BT /F1 10 Tf %d %d Td (%s) Tj ET\n", x, y, s)
+	}
+	text(72, 750, "Acme Supplies Ltd")
+	text(450, 750, "INVOICE")
+	text(72, 730, "Invoice # INV-2024-001")
+	text(72, 715, "Invoice Date: March 1, 2024")
+	text(72, 700, "Due Date: 2024-03-31")
+	text(72, 685, "Bill To: Globex Corp")
+
+	b.WriteString("1 w\n")
+	for _, y := range []int{660, 640, 620, 600} {
+		fmt.Fprintf(&b, "100 %d m 500 %d l S\n", y, y)
+	}
+	for _, x := range []int{100, 250, 320, 410, 500} {
+		fmt.Fprintf(&b, "%d 600 m %d 660 l S\n", x, x)
+	}
+	cells := [][]string{
+		{"Description", "Qty", "Unit Price", "Amount"},
+		{"Widget", "2", "$10.00", "$20.00"},
+		{"Gadget", "1", "$30.00", "$30.00"},
+	}
+	xs := []int{105, 255, 325, 415}
+	for r, row := range cells {
+		for c, word := range row {
+			text(xs[c], 646-r*20, word)
+		}
+	}
+
+	text(320, 570, "Subtotal $50.00")
+	text(320, 555, "Tax (10%) $5.00")
+	text(320, 540, "Total "+total)
+	text(72, 500, "Payment terms: 30 days. Amount due by the due date.")
+	return b.String()
+}
+
+func TestInvoiceExtractor_ExtractInvoice(t *testing.T) {
+	extractor := NewInvoiceExtractor(100 * 1024 * 1024)
+	path := createTempFile(t, "invoice.pdf", buildTestPDF(itemizedInvoiceContent("$55.00")))
+
+	result, err := extractor.ExtractInvoice(context.Background(), PDFExtractInvoiceRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractInvoice() unexpected error = %v", err)
+	}
+	if !result.Detected || result.DocumentType != "invoice" || result.Invoice == nil {
+		t.Fatalf("ExtractInvoice() = %+v, want an invoice", result)
+	}
+	invoice := result.Invoice
+	got := []string{invoice.Vendor, invoice.InvoiceNumber, invoice.InvoiceDate, invoice.DueDate, invoice.Currency,
+		invoice.Subtotal, invoice.Tax, invoice.Total}
+	want := []string{"Acme Supplies Ltd", "INV-2024-001", "2024-03-01", "2024-03-31", "USD", "50", "5", "55"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("fields = %q, want %q", got, want)
+	}
+	wantItems := []InvoiceLineItem{
+		{Description: "Widget", Quantity: "2", UnitPrice: "10", Amount: "20", Page: 1},
+		{Description: "Gadget", Quantity: "1", UnitPrice: "30", Amount: "30", Page: 1},
+	}
+	if fmt.Sprint(invoice.LineItems) != fmt.Sprint(wantItems) {
+		t.Errorf("line items = %+v, want %+v", invoice.LineItems, wantItems)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", result.Warnings)
+	}
+
+	// A total that disagrees with the subtotal and tax is reported
+	path = createTempFile(t, "mistake.pdf", buildTestPDF(itemizedInvoiceContent("$60.00")))
+	result, err = extractor.ExtractInvoice(context.Background(), PDFExtractInvoiceRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractInvoice(mistake) unexpected error = %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "do not add up to the total 60") {
+		t.Errorf("warnings = %v, want the total reported", result.Warnings)
+	}
+}
+
+func TestInvoiceExtractor_TextLineItems(t *testing.T) {
+	extractor := NewInvoiceExtractor(100 * 1024 * 1024)
+	content := "BT /F1 10 Tf 72 750 Td (Dear Ms. Smith,) Tj ET\n" +
+		"BT /F1 10 Tf 72 730 Td (As agreed, we supplied the following:) Tj ET\n" +
+		"BT /F1 10 Tf 72 710 Td (Consulting hours 3 120.00 360.00) Tj ET\n" +
+		"BT /F1 10 Tf 72 695 Td (Travel 1 40.50 40.50) Tj ET\n" +
+		"BT /F1 10 Tf 72 675 Td (Sincerely, Jane Doe) Tj ET\n"
+	path := createTempFile(t, "letter.pdf", buildTestPDF(content))
+
+	result, err := extractor.ExtractInvoice(context.Background(), PDFExtractInvoiceRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractInvoice() unexpected error = %v", err)
+	}
+	if result.Detected || result.DocumentType != "letter" || result.Invoice != nil {
+		t.Errorf("ExtractInvoice() = %+v, want a letter without fields", result)
+	}
+
+	forced, err := extractor.ExtractInvoice(context.Background(), PDFExtractInvoiceRequest{Path: path, Force: true})
+	if err != nil {
+		t.Fatalf("ExtractInvoice(force) unexpected error = %v", err)
+	}
+	items := forced.Invoice.LineItems
+	if len(items) != 2 || items[0].Description != "Consulting hours" || items[0].Amount != "360" ||
+		items[1].UnitPrice != "40.5" {
+		t.Errorf("line items = %+v, want the consulting hours and travel", items)
+	}
+}
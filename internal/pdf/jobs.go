@@ -0,0 +1,277 @@
+package pdf
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Extraction job limits
+const (
+	maxExtractionJobs = 64        // Jobs kept at once, running or finished
+	maxRunningJobs    = 4         // Jobs extracting at once
+	jobRetention      = time.Hour // How long a finished job and its result are kept
+)
+
+// JobRunning is the status of an extraction job still extracting; jobs that stop end as
+// BatchSucceeded, BatchPartial, or BatchFailed, like the documents of a batch
+const JobRunning = "running"
+
+// extractionJob is a background extraction and, once it stops, its result
+type extractionJob struct {
+	mu       sync.Mutex
+	info     ExtractionJob
+	started  time.Time
+	finished time.Time
+	result   *PDFExtractResult
+}
+
+// extractionJobs holds the jobs of a service by ID, and the context they run under
+type extractionJobs struct {
+	mu     sync.Mutex
+	jobs   map[string]*extractionJob
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newExtractionJobs creates an empty job table
+func newExtractionJobs() *extractionJobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &extractionJobs{jobs: make(map[string]*extractionJob), ctx: ctx, cancel: cancel}
+}
+
+// StartExtraction extracts a document in the background and returns the new job at once.
+// The job runs independently of the request that started it, so cancelling or timing out
+// that request does not stop it; it stops at its own timeout, if one is given, or when the
+// service is closed. Jobs that stop early with checkpointing enabled report a resume token
+// that starts a job continuing from their last completed page.
+func (s *Service) StartExtraction(req PDFExtractStartRequest) (*ExtractionJob, error) {
+	if err := s.extractionService.validatePath(req.Path); err != nil {
+		return nil, err
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = "structured"
+	}
+	if !slices.Contains(batchModes, mode) {
+		return nil, fmt.Errorf("invalid mode: %q (must be one of %s)", mode, strings.Join(batchModes, ", "))
+	}
+	if req.Timeout < 0 {
+		return nil, fmt.Errorf("timeout cannot be negative")
+	}
+	if req.Config.PageSize != 0 || req.Config.Cursor != "" {
+		return nil, fmt.Errorf("page_size and cursor apply to pdf_extract_result, not to the job")
+	}
+
+	id := make([]byte, cursorTokenBytes)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to create job ID: %w", err)
+	}
+	job := &extractionJob{started: time.Now()}
+	job.info = ExtractionJob{
+		JobID:     hex.EncodeToString(id),
+		Path:      req.Path,
+		Mode:      mode,
+		Status:    JobRunning,
+		StartedAt: job.started.Format(time.RFC3339),
+	}
+	if err := s.jobs.add(job); err != nil {
+		return nil, err
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout := time.Duration(req.Timeout * float64(time.Second)); timeout > 0 {
+		ctx, cancel = context.WithTimeout(s.jobs.context(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(s.jobs.context())
+	}
+	ctx = extraction.WithProgress(ctx, job.progress)
+	go func() {
+		defer cancel()
+		job.finish(s.extractMode(ctx, req.Path, mode, req.Config))
+	}()
+	return job.snapshot(), nil
+}
+
+// ExtractionStatus reports how far an extraction job has come
+func (s *Service) ExtractionStatus(req PDFExtractStatusRequest) (*ExtractionJob, error) {
+	job, err := s.jobs.get(req.JobID)
+	if err != nil {
+		return nil, err
+	}
+	return job.snapshot(), nil
+}
+
+// ExtractionJobResult returns the result of a finished extraction job, in responses of
+// PageSize elements and tables when it is given. Results can be read any number of times
+// until the job expires.
+func (s *Service) ExtractionJobResult(req PDFExtractJobResultRequest) (*PDFExtractResult, error) {
+	if req.PageSize < 0 {
+		return nil, fmt.Errorf("page_size cannot be negative")
+	}
+	if req.PageSize == 0 && req.Cursor != "" {
+		return nil, fmt.Errorf("cursor requires page_size")
+	}
+	job, err := s.jobs.get(req.JobID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := job.snapshot()
+	switch info.Status {
+	case JobRunning:
+		return nil, fmt.Errorf("job %s is still running (%d of %d pages); check pdf_extract_status",
+			info.JobID, info.PagesDone, info.PagesTotal)
+	case BatchFailed:
+		return nil, fmt.Errorf("job %s failed: %s", info.JobID, info.Error)
+	}
+
+	job.mu.Lock()
+	result := *job.result
+	job.mu.Unlock()
+	if req.PageSize > 0 {
+		cursor := resultCursor{token: info.JobID}
+		if req.Cursor != "" {
+			given, err := parseCursor(req.Cursor)
+			if err != nil {
+				return nil, err
+			}
+			if given.token != cursor.token {
+				return nil, fmt.Errorf("cursor does not continue job %s", info.JobID)
+			}
+			cursor.offset = given.offset
+		}
+		paginateResult(&result, cursor, req.PageSize)
+	}
+	return &result, nil
+}
+
+// add registers a new job, making room by dropping the oldest finished jobs. It fails when
+// too many jobs are running.
+func (j *extractionJobs) add(job *extractionJob) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.expire()
+
+	running := 0
+	var finished []*extractionJob
+	for _, other := range j.jobs {
+		if other.running() {
+			running++
+		} else {
+			finished = append(finished, other)
+		}
+	}
+	if running >= maxRunningJobs {
+		return fmt.Errorf("too many extraction jobs running (max: %d); wait for one to finish", maxRunningJobs)
+	}
+	slices.SortFunc(finished, func(a, b *extractionJob) int { return a.finished.Compare(b.finished) })
+	for len(j.jobs) >= maxExtractionJobs && len(finished) > 0 {
+		delete(j.jobs, finished[0].info.JobID)
+		finished = finished[1:]
+	}
+	j.jobs[job.info.JobID] = job
+	return nil
+}
+
+// get looks up a job by ID
+func (j *extractionJobs) get(id string) (*extractionJob, error) {
+	if id == "" {
+		return nil, fmt.Errorf("job_id cannot be empty")
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.expire()
+
+	job, ok := j.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown job %q: finished jobs are kept for %s", id, jobRetention)
+	}
+	return job, nil
+}
+
+// context returns the context new jobs run under
+func (j *extractionJobs) context() context.Context {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.ctx
+}
+
+// stop cancels the running jobs, which end as failed or partial, and gives the jobs started
+// afterwards a new context
+func (j *extractionJobs) stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.cancel()
+	j.ctx, j.cancel = context.WithCancel(context.Background())
+}
+
+// expire drops the jobs that finished more than jobRetention ago; j.mu must be held
+func (j *extractionJobs) expire() {
+	for id, job := range j.jobs {
+		if !job.running() && time.Since(job.finished) > jobRetention {
+			delete(j.jobs, id)
+		}
+	}
+}
+
+// running reports whether the job is still extracting
+func (job *extractionJob) running() bool {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return job.info.Status == JobRunning
+}
+
+// progress records a progress report of the job's extraction
+func (job *extractionJob) progress(done, total int, message string) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if done >= job.info.PagesDone {
+		job.info.PagesDone, job.info.PagesTotal, job.info.Message = done, total, message
+	}
+}
+
+// finish records the outcome of the job's extraction, classified like a document of a batch
+func (job *extractionJob) finish(result *PDFExtractResult, err error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.finished = time.Now()
+	job.info.FinishedAt = job.finished.Format(time.RFC3339)
+
+	switch {
+	case err != nil:
+		job.info.Status, job.info.Error = BatchFailed, err.Error()
+	case len(result.ProcessedPages) == 0 && len(result.Errors) > 0:
+		job.info.Status, job.info.Error = BatchFailed, strings.Join(result.Errors, "; ")
+	case result.Partial:
+		job.info.Status, job.info.ResumeToken = BatchPartial, result.ResumeToken
+	default:
+		job.info.Status = BatchSucceeded
+	}
+	if result != nil {
+		job.info.PagesDone = len(result.ProcessedPages)
+		job.info.PagesTotal = max(job.info.PagesTotal, job.info.PagesDone)
+	}
+	job.result = result
+}
+
+// snapshot copies the job's description, with its elapsed time up to now or its end
+func (job *extractionJob) snapshot() *ExtractionJob {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	info := job.info
+	end := job.finished
+	if info.Status == JobRunning {
+		end = time.Now()
+	}
+	info.ElapsedMs = durationMs(end.Sub(job.started))
+	return &info
+}
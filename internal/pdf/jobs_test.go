@@ -0,0 +1,177 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForJob polls a job until it stops running
+func waitForJob(t *testing.T, service *Service, jobID string) *ExtractionJob {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, err := service.ExtractionStatus(PDFExtractStatusRequest{JobID: jobID})
+		if err != nil {
+			t.Fatalf("ExtractionStatus() unexpected error = %v", err)
+		}
+		if job.Status != JobRunning {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s still running: %+v", jobID, job)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestService_ExtractionJob(t *testing.T) {
+	service := NewService(100 * 1024 * 1024)
+	path := createTempFile(t, "report.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (First page) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Second page) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Third page) Tj ET",
+	))
+
+	started, err := service.StartExtraction(PDFExtractStartRequest{Path: path})
+	if err != nil {
+		t.Fatalf("StartExtraction() unexpected error = %v", err)
+	}
+	if len(started.JobID) != 2*cursorTokenBytes || started.Mode != "structured" || started.StartedAt == "" {
+		t.Errorf("StartExtraction() = %+v, want a structured job with an ID", started)
+	}
+
+	job := waitForJob(t, service, started.JobID)
+	if job.Status != BatchSucceeded || job.PagesDone != 3 || job.PagesTotal != 3 || job.FinishedAt == "" {
+		t.Errorf("finished job = %+v, want 3 of 3 pages succeeded", job)
+	}
+
+	result, err := service.ExtractionJobResult(PDFExtractJobResultRequest{JobID: job.JobID})
+	if err != nil {
+		t.Fatalf("ExtractionJobResult() unexpected error = %v", err)
+	}
+	if len(result.ProcessedPages) != 3 || len(result.Elements) == 0 || result.Pagination != nil {
+		t.Errorf("ExtractionJobResult() = %+v, want every page unpaginated", result)
+	}
+	total := len(result.Elements) + len(result.Tables)
+
+	// Paging through the result returns every item once, and leaves the stored result whole
+	var items int
+	req := PDFExtractJobResultRequest{JobID: job.JobID, PageSize: 1}
+	for {
+		page, err := service.ExtractionJobResult(req)
+		if err != nil {
+			t.Fatalf("ExtractionJobResult(cursor %q) unexpected error = %v", req.Cursor, err)
+		}
+		items += len(page.Elements) + len(page.Tables)
+		if page.Pagination.NextCursor == "" {
+			break
+		}
+		req.Cursor = page.Pagination.NextCursor
+	}
+	if items != total {
+		t.Errorf("paginated result has %d items, want %d", items, total)
+	}
+	result, err = service.ExtractionJobResult(PDFExtractJobResultRequest{JobID: job.JobID})
+	if err != nil || len(result.Elements)+len(result.Tables) != total {
+		t.Errorf("ExtractionJobResult() after paging = %v (%v), want the whole result", result, err)
+	}
+}
+
+func TestService_CloseStopsExtractionJobs(t *testing.T) {
+	service := NewService(100 * 1024 * 1024)
+	path := createTempFile(t, "report.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Report) Tj ET"))
+
+	root := service.jobs.context()
+	started, err := service.StartExtraction(PDFExtractStartRequest{Path: path})
+	if err != nil {
+		t.Fatalf("StartExtraction() unexpected error = %v", err)
+	}
+	service.Close()
+	if root.Err() == nil {
+		t.Errorf("context of jobs started before Close() was not cancelled")
+	}
+	waitForJob(t, service, started.JobID)
+
+	// Jobs started afterwards run under a new context
+	again, err := service.StartExtraction(PDFExtractStartRequest{Path: path})
+	if err != nil {
+		t.Fatalf("StartExtraction() after Close() unexpected error = %v", err)
+	}
+	if job := waitForJob(t, service, again.JobID); job.Status != BatchSucceeded {
+		t.Errorf("job started after Close() = %+v, want it to succeed", job)
+	}
+}
+
+func TestService_ExtractionJobErrors(t *testing.T) {
+	service := NewService(100 * 1024 * 1024)
+	path := createTempFile(t, "report.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Report) Tj ET"))
+
+	startTests := []struct {
+		name     string
+		req      PDFExtractStartRequest
+		errorMsg string
+	}{
+		{name: "empty path", req: PDFExtractStartRequest{}, errorMsg: "path cannot be empty"},
+		{name: "missing file", req: PDFExtractStartRequest{Path: path + ".gone"}, errorMsg: "does not exist"},
+		{name: "invalid mode", req: PDFExtractStartRequest{Path: path, Mode: "preview"}, errorMsg: "invalid mode"},
+		{name: "negative timeout", req: PDFExtractStartRequest{Path: path, Timeout: -1}, errorMsg: "cannot be negative"},
+		{
+			name:     "page size",
+			req:      PDFExtractStartRequest{Path: path, Config: ExtractionConfig{PageSize: 10}},
+			errorMsg: "apply to pdf_extract_result",
+		},
+	}
+	for _, tt := range startTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.StartExtraction(tt.req)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("StartExtraction() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+
+	damaged := createTempFile(t, "damaged.pdf", "%PDF-1.4\nnot a document")
+	started, err := service.StartExtraction(PDFExtractStartRequest{Path: damaged})
+	if err != nil {
+		t.Fatalf("StartExtraction(damaged) unexpected error = %v", err)
+	}
+	if job := waitForJob(t, service, started.JobID); job.Status != BatchFailed || job.Error == "" {
+		t.Errorf("damaged job = %+v, want a failure", job)
+	}
+
+	other, err := service.StartExtraction(PDFExtractStartRequest{Path: path})
+	if err != nil {
+		t.Fatalf("StartExtraction() unexpected error = %v", err)
+	}
+	waitForJob(t, service, other.JobID)
+
+	resultTests := []struct {
+		name     string
+		req      PDFExtractJobResultRequest
+		errorMsg string
+	}{
+		{name: "empty job", req: PDFExtractJobResultRequest{}, errorMsg: "job_id cannot be empty"},
+		{name: "unknown job", req: PDFExtractJobResultRequest{JobID: "nope"}, errorMsg: "unknown job"},
+		{name: "failed job", req: PDFExtractJobResultRequest{JobID: started.JobID}, errorMsg: "failed"},
+		{
+			name:     "cursor without page size",
+			req:      PDFExtractJobResultRequest{JobID: other.JobID, Cursor: "x"},
+			errorMsg: "cursor requires page_size",
+		},
+		{
+			name:     "cursor of another job",
+			req:      PDFExtractJobResultRequest{JobID: other.JobID, PageSize: 1, Cursor: started.JobID + ".1"},
+			errorMsg: "does not continue job",
+		},
+	}
+	for _, tt := range resultTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ExtractionJobResult(tt.req)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("ExtractionJobResult() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// Text layouts of pdf_read_file
+const (
+	LayoutRaw          = "raw"           // Text in content stream order, as the parser reads it
+	LayoutPositioned   = "layout"        // Lines placed by position, keeping columns apart with spaces
+	LayoutReadingOrder = "reading-order" // Columns read one after another
+	LayoutMarkdown     = "markdown"      // Headings, lists, and tables as Markdown, in reading order
+)
+
+// Column detection and layout text constants
+const (
+	// gutterMinRatio is the narrowest gap between columns, relative to the body text size
+	gutterMinRatio = 1.0
+	// gutterCrossingShare is the share of rows that may cross a gutter, such as titles and
+	// figures set across the page
+	gutterCrossingShare = 0.25
+	// minColumnRows is the fewest rows with text on each side of a gutter
+	minColumnRows = 3
+	// minColumnWords is the fewest words per line, on average, of a column of running text;
+	// label and value pairs and table cells are narrower and are read across
+	minColumnWords = 3
+	// layoutCharRatio is the width of one character of layout text, relative to the body
+	// text size
+	layoutCharRatio = 0.5
+	// maxLayoutBlankLines caps the blank lines standing for a vertical gap in layout text
+	maxLayoutBlankLines = 2
+	// layoutColumnMargin is how many columns past the page's width layout text may run, for
+	// words overhanging its right edge
+	layoutColumnMargin = 20
+	// maxPageWidth is the widest page the PDF specification allows, and the width assumed
+	// for pages whose media box is missing or wider
+	maxPageWidth = 14400.0
+)
+
+// gutter is the empty space between two columns of text
+type gutter struct {
+	left, right float64
+}
+
+// validLayout reports whether a layout is one of the supported layouts; empty is raw
+func validLayout(layout string) bool {
+	switch layout {
+	case "", LayoutRaw, LayoutPositioned, LayoutReadingOrder, LayoutMarkdown:
+		return true
+	}
+	return false
+}
+
+// layoutPageText returns a function reading the text of a page in the given layout. Tables
+// of the pages to read are detected up front for Markdown. With a region only the words
+// inside it are read, and raw text, which has no positions to clip by, is read in reading
+// order instead.
+func (r *Reader) layoutPageText(
+	ctx context.Context, path string, numbering *extraction.PageNumbering, layout string, pages []int, region *Rectangle,
+) (func(pageNum int) (string, error), error) {
+	if region != nil && (layout == "" || layout == LayoutRaw) {
+		layout = LayoutReadingOrder
+	}
+
+	switch layout {
+	case LayoutPositioned:
+		return func(pageNum int) (string, error) {
+			rows, bodySize := regionRows(numbering.Page(pageNum), region)
+			return layoutText(rows, bodySize, pageWidth(numbering, pageNum)), nil
+		}, nil
+	case LayoutReadingOrder:
+		return func(pageNum int) (string, error) {
+			rows, bodySize := regionRows(numbering.Page(pageNum), region)
+			lines := readingOrderLines(rows, findGutters(rows, bodySize))
+			texts := make([]string, len(lines))
+			for i, line := range lines {
+				texts[i] = line.Text
+			}
+			return strings.Join(texts, "\n"), nil
+		}, nil
+	case LayoutMarkdown:
+		tables, err := detectTables(ctx, r.engine, path, pages)
+		if err != nil {
+			return nil, err
+		}
+		return func(pageNum int) (string, error) {
+			rows, bodySize := regionRows(numbering.Page(pageNum), region)
+			lines := readingOrderLines(rows, findGutters(rows, bodySize))
+			pageTables := tables[pageNum]
+			if region != nil {
+				pageTables = slices.DeleteFunc(slices.Clone(pageTables), func(table extraction.TableElement) bool {
+					return !extraction.CenteredIn(table.BoundingBox, *regionBounds(region))
+				})
+			}
+			return writeMarkdown(defaultStructure.textBlocks(lines, bodySize, pageTables)), nil
+		}, nil
+	}
+	return plainPageText(numbering), nil
+}
+
+// regionRows returns the rows of words of a page, and its body text size, keeping only the
+// words inside the region when one is given
+func regionRows(page pdf.Page, region *Rectangle) ([][]extraction.WordElement, float64) {
+	rows, bodySize := pageRows(page, math.MaxInt)
+	if region == nil {
+		return rows, bodySize
+	}
+
+	bounds := *regionBounds(region)
+	kept := rows[:0]
+	for _, row := range rows {
+		row = slices.DeleteFunc(row, func(word extraction.WordElement) bool {
+			return !extraction.CenteredIn(word.BoundingBox, bounds)
+		})
+		if len(row) > 0 {
+			kept = append(kept, row)
+		}
+	}
+	return kept, bodySize
+}
+
+// plainPageText returns a function reading the text of a page in content stream order
+func plainPageText(numbering *extraction.PageNumbering) func(pageNum int) (string, error) {
+	return func(pageNum int) (string, error) {
+		return extraction.PlainText(numbering.Page(pageNum))
+	}
+}
+
+// findGutters finds the gaps separating columns of running text: vertical strips that
+// nearly every row leaves empty, with enough text on both sides
+func findGutters(rows [][]extraction.WordElement, bodySize float64) []gutter {
+	if len(rows) < 2*minColumnRows || bodySize <= 0 {
+		return nil
+	}
+
+	left, right := math.Inf(1), math.Inf(-1)
+	for _, row := range rows {
+		left = math.Min(left, row[0].BoundingBox.LowerLeft.X)
+		right = math.Max(right, row[len(row)-1].BoundingBox.UpperRight.X)
+	}
+	width := int(math.Ceil(right - left))
+	if width <= 0 {
+		return nil
+	}
+
+	// Count the rows covering each point across the text
+	coverage := make([]int, width+1)
+	for _, row := range rows {
+		covered := 0
+		for _, word := range row {
+			start := max(int(word.BoundingBox.LowerLeft.X-left), covered)
+			end := min(int(math.Ceil(word.BoundingBox.UpperRight.X-left)), width)
+			for x := start; x < end; x++ {
+				coverage[x]++
+			}
+			covered = max(covered, end)
+		}
+	}
+
+	allowed := int(float64(len(rows)) * gutterCrossingShare)
+	var gutters []gutter
+	for x := 0; x <= width; {
+		if coverage[x] > allowed {
+			x++
+			continue
+		}
+		start := x
+		for x <= width && coverage[x] <= allowed {
+			x++
+		}
+		candidate := gutter{left: left + float64(start), right: left + float64(x)}
+		if start > 0 && x <= width && candidate.right-candidate.left >= bodySize*gutterMinRatio &&
+			separatesColumns(rows, candidate) {
+			gutters = append(gutters, candidate)
+		}
+	}
+	return gutters
+}
+
+// separatesColumns reports whether enough rows have running text on each side of a gutter
+func separatesColumns(rows [][]extraction.WordElement, g gutter) bool {
+	var leftRows, leftWords, rightRows, rightWords int
+	for _, row := range rows {
+		var leftCount, rightCount int
+		for _, word := range row {
+			switch {
+			case word.BoundingBox.UpperRight.X <= g.left:
+				leftCount++
+			case word.BoundingBox.LowerLeft.X >= g.right:
+				rightCount++
+			}
+		}
+		if leftCount > 0 {
+			leftRows++
+			leftWords += leftCount
+		}
+		if rightCount > 0 {
+			rightRows++
+			rightWords += rightCount
+		}
+	}
+	return leftRows >= minColumnRows && rightRows >= minColumnRows &&
+		leftWords >= leftRows*minColumnWords && rightWords >= rightRows*minColumnWords
+}
+
+// readingOrderLines splits rows into the lines of each column and orders them as they are
+// read: a column from top to bottom before the next. Rows crossing a gutter, such as titles
+// set across the page, end the columns above them and are read on their own.
+func readingOrderLines(rows [][]extraction.WordElement, gutters []gutter) []pageLine {
+	lines := make([]pageLine, 0, len(rows))
+	columns := make([][]pageLine, len(gutters)+1)
+	flush := func() {
+		for i, column := range columns {
+			lines = append(lines, column...)
+			columns[i] = nil
+		}
+	}
+
+	for _, row := range rows {
+		segments := make([][]extraction.WordElement, len(columns))
+		crossing := false
+		for _, word := range row {
+			column := 0
+			for _, g := range gutters {
+				box := word.BoundingBox
+				if box.LowerLeft.X < g.right && box.UpperRight.X > g.left {
+					crossing = true
+				}
+				if box.LowerLeft.X >= g.right {
+					column++
+				}
+			}
+			segments[column] = append(segments[column], word)
+		}
+		if crossing {
+			flush()
+			lines = append(lines, newPageLine(row))
+			continue
+		}
+		for i, segment := range segments {
+			if len(segment) > 0 {
+				columns[i] = append(columns[i], newPageLine(segment))
+			}
+		}
+	}
+	flush()
+	return lines
+}
+
+// layoutText places the words of each row at character positions matching their position
+// on the page, so columns and indentation stay visible, and leaves blank lines for large
+// vertical gaps. Words drawn far off the page are placed at the page's right edge.
+func layoutText(rows [][]extraction.WordElement, bodySize, width float64) string {
+	if len(rows) == 0 {
+		return ""
+	}
+	charWidth := math.Max(bodySize*layoutCharRatio, 1)
+	maxColumn := int(width/charWidth) + layoutColumnMargin
+	left := math.Inf(1)
+	for _, row := range rows {
+		left = math.Min(left, row[0].BoundingBox.LowerLeft.X)
+	}
+
+	var b strings.Builder
+	previousBottom := 0.0
+	for i, row := range rows {
+		line := newPageLine(row)
+		if i > 0 {
+			b.WriteByte('\n')
+			gap := previousBottom - line.Top
+			for range min(int(gap/math.Max(bodySize, 1)), maxLayoutBlankLines) {
+				b.WriteByte('\n')
+			}
+		}
+		previousBottom = line.Bottom
+
+		column := 0
+		for j, word := range row {
+			offset := math.Round((word.BoundingBox.LowerLeft.X - left) / charWidth)
+			target := int(math.Min(offset, float64(maxColumn)))
+			if j > 0 {
+				target = max(target, column+1)
+			}
+			b.WriteString(strings.Repeat(" ", max(target-column, 0)))
+			b.WriteString(word.Text)
+			column = max(target, column) + utf8.RuneCountInString(word.Text)
+		}
+	}
+	return b.String()
+}
+
+// pageWidth returns the width of a page's media box, bounded by maxPageWidth
+func pageWidth(numbering *extraction.PageNumbering, pageNum int) float64 {
+	box := numbering.MediaBox(pageNum)
+	if box.Len() != 4 {
+		return maxPageWidth
+	}
+	width := math.Abs(box.Index(2).Float64() - box.Index(0).Float64())
+	if math.IsNaN(width) || width > maxPageWidth {
+		return maxPageWidth
+	}
+	return width
+}
+
+// layoutError describes an unsupported layout
+func layoutError(layout string) error {
+	return fmt.Errorf("invalid layout: %s (must be %s, %s, %s, or %s)",
+		layout, LayoutRaw, LayoutPositioned, LayoutReadingOrder, LayoutMarkdown)
+}
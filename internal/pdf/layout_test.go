@@ -0,0 +1,106 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// twoColumnContent is a page with a title set across two columns of eight lines each
+func twoColumnContent() string {
+	var b strings.Builder
+	b.WriteString("BT /F1 18 Tf 72 760 Td (Findings From Two Columns of Study) Tj ET\n")
+	for i := 1; i <= 8; i++ {
+		y := 730 - i*14
+		fmt.Fprintf(&b, "BT /F1 10 Tf 72 %d Td (Left column sentence %d goes on) Tj ET\n", y, i)
+		fmt.Fprintf(&b, "BT /F1 10 Tf 330 %d Td (Right column sentence %d continues) Tj ET\n", y, i)
+	}
+	return b.String()
+}
+
+func TestReader_ReadFileLayouts(t *testing.T) {
+	reader := NewReader(10 * 1024 * 1024)
+	path := createTempFile(t, "paper.pdf", buildTestPDF(twoColumnContent()))
+
+	read := func(layout string) string {
+		t.Helper()
+		result, err := reader.ReadFile(PDFReadFileRequest{Path: path, Layout: layout})
+		if err != nil {
+			t.Fatalf("ReadFile(%q) unexpected error = %v", layout, err)
+		}
+		return result.Content
+	}
+
+	// Reading order finishes the left column before starting the right one
+	lines := strings.Split(read(LayoutReadingOrder), "\n")
+	if len(lines) != 17 || lines[0] != "Findings From Two Columns of Study" ||
+		lines[8] != "Left column sentence 8 goes on" || lines[9] != "Right column sentence 1 continues" {
+		t.Errorf("ReadFile(reading-order) lines = %q, want the title, the left column, then the right column", lines)
+	}
+
+	// Layout text keeps both columns on one line, apart, below a blank line left for the gap
+	// under the title
+	lines = strings.Split(read(LayoutPositioned), "\n")
+	if len(lines) != 11 || lines[1] != "" {
+		t.Fatalf("ReadFile(layout) lines = %q, want the title, a gap, and 8 lines", lines)
+	}
+	first := lines[3]
+	left, right := strings.Index(first, "goes on"), strings.Index(first, "Right column sentence 1")
+	if left < 0 || right < left+len("goes on")+5 {
+		t.Errorf("ReadFile(layout) first column line = %q, want both columns set apart", first)
+	}
+
+	if _, err := reader.ReadFile(PDFReadFileRequest{Path: path, Layout: "columns"}); err == nil ||
+		!strings.Contains(err.Error(), "invalid layout") {
+		t.Errorf("ReadFile() with an unknown layout error = %v, want it rejected", err)
+	}
+}
+
+func TestReader_ReadFileLayoutOffPage(t *testing.T) {
+	reader := NewReader(10 * 1024 * 1024)
+	path := createTempFile(t, "off-page.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 700 Td (Start) Tj ET\nBT /F1 12 Tf 100000000000000 700 Td (Away) Tj ET"))
+
+	result, err := reader.ReadFile(PDFReadFileRequest{Path: path, Layout: LayoutPositioned})
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v", err)
+	}
+	// The word far to the right is placed just past the edge of the 612 point wide page
+	if !strings.HasPrefix(result.Content, "Start ") || !strings.HasSuffix(result.Content, "Away") ||
+		len(result.Content) > 612/6+layoutColumnMargin+len("Away") {
+		t.Errorf("ReadFile(layout) = %q (%d bytes), want both words on a line of the page's width",
+			result.Content, len(result.Content))
+	}
+}
+
+func TestReader_ReadFileMarkdown(t *testing.T) {
+	reader := NewReader(10 * 1024 * 1024)
+	path := createTempFile(t, "report.pdf", buildTestPDF(exportTestContent()))
+
+	result, err := reader.ReadFile(PDFReadFileRequest{Path: path, Layout: LayoutMarkdown})
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v", err)
+	}
+	want := "# Quarterly Report\n\n" +
+		"Sales grew in every region this quarter and inventory stayed flat.\n\n" +
+		"- First point\n- Second point\n\n" +
+		"| Name | Qty |\n| --- | --- |\n| Apple | 3 |\n| Pear | 5 |"
+	if result.Content != want || result.Layout != LayoutMarkdown {
+		t.Errorf("ReadFile() markdown = %q, want %q", result.Content, want)
+	}
+}
+
+func TestMarkdownListItem(t *testing.T) {
+	tests := map[string]string{
+		"• Apples":      "- Apples",
+		"- Pears":       "- Pears",
+		"3. Plums":      "3. Plums",
+		"(12) Cherries": "12. Cherries",
+		"a) Grapes":     "- a) Grapes",
+	}
+	for text, want := range tests {
+		if got := markdownListItem(text); got != want {
+			t.Errorf("markdownListItem(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// rectCoordinates is the number of values in a PDF rectangle array
+const rectCoordinates = 4
+
+// Links handles extraction of hyperlinks and internal cross-references
+type Links struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewLinks creates a new link extractor with the specified constraints
+func NewLinks(maxFileSize int64) *Links {
+	return &Links{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// ExtractLinks returns every link annotation with its area on the page and resolved target
+func (l *Links) ExtractLinks(req PDFExtractLinksRequest) (result *PDFExtractLinksResult, err error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: l.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			result = nil
+			err = fmt.Errorf("failed to read links: %v", rec)
+		}
+	}()
+
+	result = &PDFExtractLinksResult{
+		Path:  req.Path,
+		Links: []LinkInfo{},
+	}
+
+	numbering := extraction.NewPageNumbering(r)
+	pages := req.Pages
+	if len(pages) == 0 {
+		for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+			pages = append(pages, pageNum)
+		}
+	}
+
+	resolver := extraction.NewLinkResolver(r, numbering)
+	for _, pageNum := range pages {
+		if pageNum < 1 || pageNum > numbering.Count() {
+			return nil, pageOutOfRange(pageNum, numbering.Count())
+		}
+
+		annots := numbering.Page(pageNum).V.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			annot := annots.Index(i)
+			if annot.Key("Subtype").Name() != "Link" {
+				continue
+			}
+
+			link := resolver.Resolve(annot)
+			info := LinkInfo{
+				Page:             pageNum,
+				PageLabel:        numbering.Label(pageNum),
+				BoundingBox:      annotationRect(annot.Key("Rect")),
+				Action:           link.Action,
+				URI:              link.URI,
+				TargetPage:       link.TargetPage,
+				TargetPageLabel:  link.TargetPageLabel,
+				TargetFile:       link.TargetFile,
+				NamedDestination: link.NamedDestination,
+				DestinationType:  link.DestinationType,
+				Zoom:             link.Zoom,
+				Contents:         annot.Key("Contents").Text(),
+			}
+			result.Links = append(result.Links, info)
+
+			if link.Action == extraction.LinkActionGoTo {
+				result.InternalCount++
+			} else {
+				result.ExternalCount++
+			}
+		}
+	}
+
+	result.TotalCount = len(result.Links)
+	return result, nil
+}
+
+// annotationRect converts an annotation /Rect array, normalizing reversed corners
+func annotationRect(rect pdf.Value) Rectangle {
+	if rect.Len() < rectCoordinates {
+		return Rectangle{}
+	}
+	x1, y1 := rect.Index(0).Float64(), rect.Index(1).Float64()
+	x2, y2 := rect.Index(2).Float64(), rect.Index(3).Float64()
+	return Rectangle{
+		X:      min(x1, x2),
+		Y:      min(y1, y2),
+		Width:  max(x1, x2) - min(x1, x2),
+		Height: max(y1, y2) - min(y1, y2),
+	}
+}
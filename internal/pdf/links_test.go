@@ -0,0 +1,129 @@
+package pdf
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// linksPDFContent builds a two-page document whose first page has a web link, an explicit
+// internal link, a named-destination link, and a link into another file
+func linksPDFContent() string {
+	return buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Names << /Dests << /Names [(chapter2) [4 0 R /XYZ 0 700 2]] >> >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [5 0 R 6 0 R 7 0 R 8 0 R 9 0 R] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 700 200 712] /A << /S /URI /URI (https://example.com/docs) >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 680 200 692] /Dest [4 0 R /Fit] >>",
+		"<< /Type /Annot /Subtype /Link /Rect [200 672 72 660] /A << /S /GoTo /D (chapter2) >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 640 200 652] /A << /S /GoToR /F (other.pdf) /D [2 /Fit] >> >>",
+		"<< /Type /Annot /Subtype /Text /Rect [300 700 320 720] /Contents (Not a link) >>",
+	})
+}
+
+func TestLinks_ExtractLinksErrors(t *testing.T) {
+	links := NewLinks(100 * 1024 * 1024)
+
+	tests := []struct {
+		name     string
+		req      PDFExtractLinksRequest
+		errorMsg string
+	}{
+		{
+			name:     "empty path",
+			req:      PDFExtractLinksRequest{},
+			errorMsg: "path cannot be empty",
+		},
+		{
+			name:     "non-existent file",
+			req:      PDFExtractLinksRequest{Path: "/non/existent/file.pdf"},
+			errorMsg: "file does not exist",
+		},
+		{
+			name:     "page out of range",
+			req:      PDFExtractLinksRequest{Path: createTempFile(t, "links.pdf", linksPDFContent()), Pages: []int{3}},
+			errorMsg: "out of range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := links.ExtractLinks(tt.req)
+			if err == nil {
+				t.Fatal("ExtractLinks() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("ExtractLinks() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestLinks_ExtractLinks(t *testing.T) {
+	links := NewLinks(100 * 1024 * 1024)
+	path := createTempFile(t, "links.pdf", linksPDFContent())
+
+	result, err := links.ExtractLinks(PDFExtractLinksRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractLinks() unexpected error = %v", err)
+	}
+	if result.TotalCount != 4 || result.InternalCount != 2 || result.ExternalCount != 2 {
+		t.Fatalf("ExtractLinks() = %d links (%d internal, %d external), want 4 (2, 2)",
+			result.TotalCount, result.InternalCount, result.ExternalCount)
+	}
+
+	web := result.Links[0]
+	if web.Action != "uri" || web.URI != "https://example.com/docs" || web.Page != 1 {
+		t.Errorf("web link = %+v, want uri link on page 1", web)
+	}
+	if web.BoundingBox != (Rectangle{X: 72, Y: 700, Width: 128, Height: 12}) {
+		t.Errorf("web link area = %+v, want 72,700 128x12", web.BoundingBox)
+	}
+
+	explicit := result.Links[1]
+	if explicit.Action != "goto" || explicit.TargetPage != 2 || explicit.DestinationType != "Fit" {
+		t.Errorf("explicit link = %+v, want goto page 2 Fit", explicit)
+	}
+
+	named := result.Links[2]
+	if named.TargetPage != 2 || named.NamedDestination != "chapter2" || named.Zoom != 2 {
+		t.Errorf("named link = %+v, want chapter2 resolved to page 2 at zoom 2", named)
+	}
+	if named.BoundingBox.X != 72 || named.BoundingBox.Width != 128 {
+		t.Errorf("named link area = %+v, want reversed corners normalized", named.BoundingBox)
+	}
+
+	remote := result.Links[3]
+	if remote.Action != "gotor" || remote.TargetFile != "other.pdf" || remote.TargetPage != 3 {
+		t.Errorf("remote link = %+v, want other.pdf page 3", remote)
+	}
+}
+
+func TestExtractionService_ExtractStructuredLinks(t *testing.T) {
+	service := NewExtractionService(100 * 1024 * 1024)
+	path := createTempFile(t, "links.pdf", linksPDFContent())
+
+	result, err := service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Config: ExtractConfig{ExtractAnnotations: true},
+	})
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+
+	var resolved []*extraction.Link
+	for _, element := range result.Elements {
+		if annot, ok := element.Content.(extraction.AnnotationElement); ok && annot.Link != nil {
+			resolved = append(resolved, annot.Link)
+		}
+	}
+	if len(resolved) != 4 {
+		t.Fatalf("ExtractStructured() resolved %d links, want 4", len(resolved))
+	}
+	if resolved[0].URI != "https://example.com/docs" || resolved[2].TargetPage != 2 {
+		t.Errorf("ExtractStructured() links = %+v, %+v", resolved[0], resolved[2])
+	}
+}
@@ -0,0 +1,190 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Loaded document limits
+const (
+	maxLoadedDocuments = 64        // Documents kept at once; loading more drops the oldest
+	loadedRetention    = time.Hour // How long a loaded document is kept after it was last used
+
+	// LoadedHandlePrefix starts the handles of loaded documents, which tools accept as paths
+	LoadedHandlePrefix = "bytes:"
+)
+
+// loadedDocument is a document loaded from bytes and the file holding it
+type loadedDocument struct {
+	info     LoadedDocument
+	path     string
+	lastUsed time.Time
+}
+
+// loadedDocuments holds the documents of a service loaded from bytes by handle, in a
+// temporary directory created on first use
+type loadedDocuments struct {
+	mu   sync.Mutex
+	dir  string
+	docs map[string]*loadedDocument
+}
+
+// newLoadedDocuments creates an empty table of loaded documents
+func newLoadedDocuments() *loadedDocuments {
+	return &loadedDocuments{docs: make(map[string]*loadedDocument)}
+}
+
+// LoadBytes stores a base64-encoded PDF under a new handle that every tool accepts in place
+// of a path, for clients that hold documents in memory rather than in files. Documents are
+// kept for an hour after their last use, and the oldest are dropped when too many are loaded.
+func (s *Service) LoadBytes(req PDFLoadBytesRequest) (*LoadedDocument, error) {
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, fmt.Errorf("content cannot be empty")
+	}
+	// Data URLs such as data:application/pdf;base64,JVBERi0... carry the encoding in a prefix
+	if strings.HasPrefix(content, "data:") {
+		if _, data, ok := strings.Cut(content, ","); ok {
+			content = data
+		}
+	}
+	if int64(base64.StdEncoding.DecodedLen(len(content))) > s.maxFileSize+2 {
+		return nil, pdferrors.New(pdferrors.TooLarge, "content too large (max: %d bytes)", s.maxFileSize)
+	}
+	data, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, fmt.Errorf("content is not valid base64: %w", err)
+	}
+	if int64(len(data)) > s.maxFileSize {
+		return nil, pdferrors.New(pdferrors.TooLarge, "content too large: %d bytes (max: %d bytes)", len(data), s.maxFileSize)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, pdferrors.New(pdferrors.Corrupt, "content is not a PDF")
+	}
+	return s.loadDocument(req.Name, data)
+}
+
+// loadDocument stores a document's bytes under a new handle
+func (s *Service) loadDocument(name string, data []byte) (*LoadedDocument, error) {
+	id := make([]byte, cursorTokenBytes)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to create handle: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	doc := &loadedDocument{
+		info: LoadedDocument{
+			Handle: LoadedHandlePrefix + hex.EncodeToString(id),
+			Name:   name,
+			Size:   int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		},
+		lastUsed: time.Now(),
+	}
+	if err := s.loaded.add(doc, data); err != nil {
+		return nil, err
+	}
+	info := doc.info
+	info.Path = doc.path
+	return &info, nil
+}
+
+// ResolvePath returns the file of a loaded document's handle or of a portfolio member, and
+// other paths unchanged
+func (s *Service) ResolvePath(path string) (string, error) {
+	if container, member, ok := splitPortfolioMember(path); ok {
+		return s.resolveMember(container, member)
+	}
+	if !strings.HasPrefix(path, LoadedHandlePrefix) {
+		return path, nil
+	}
+	return s.loaded.resolve(path)
+}
+
+// add writes a document's bytes to its file and registers it, making room by dropping the
+// least recently used documents
+func (l *loadedDocuments) add(doc *loadedDocument, data []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	if l.dir == "" {
+		dir, err := fsys.MkdirTemp("", "mcp-pdf-reader-loaded-")
+		if err != nil {
+			return fmt.Errorf("cannot create directory for loaded documents: %w", err)
+		}
+		l.dir = dir
+	}
+	name := strings.TrimPrefix(doc.info.Handle, LoadedHandlePrefix) + ".pdf"
+	doc.path = filepath.Join(l.dir, name)
+	if err := fsys.WriteFile(doc.path, data, exportFilePerm); err != nil {
+		return fmt.Errorf("failed to store document: %w", err)
+	}
+
+	if len(l.docs) >= maxLoadedDocuments {
+		docs := make([]*loadedDocument, 0, len(l.docs))
+		for _, other := range l.docs {
+			docs = append(docs, other)
+		}
+		slices.SortFunc(docs, func(a, b *loadedDocument) int { return a.lastUsed.Compare(b.lastUsed) })
+		for _, oldest := range docs[:len(l.docs)-maxLoadedDocuments+1] {
+			l.drop(oldest)
+		}
+	}
+	l.docs[doc.info.Handle] = doc
+	return nil
+}
+
+// resolve looks up the file of a handle, marking the document used
+func (l *loadedDocuments) resolve(handle string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	doc, ok := l.docs[handle]
+	if !ok {
+		return "", pdferrors.New(pdferrors.NotFound,
+			"unknown handle %q: loaded documents are kept for %s after their last use", handle, loadedRetention)
+	}
+	doc.lastUsed = time.Now()
+	return doc.path, nil
+}
+
+// expire drops the documents unused for more than loadedRetention; l.mu must be held
+func (l *loadedDocuments) expire() {
+	for _, doc := range l.docs {
+		if time.Since(doc.lastUsed) > loadedRetention {
+			l.drop(doc)
+		}
+	}
+}
+
+// cleanup forgets every loaded document and removes their directory, which the next
+// document loaded creates anew
+func (l *loadedDocuments) cleanup() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.docs)
+	if l.dir != "" {
+		fsys.RemoveAll(l.dir)
+		l.dir = ""
+	}
+}
+
+// drop forgets a document and removes its file; l.mu must be held
+func (l *loadedDocuments) drop(doc *loadedDocument) {
+	delete(l.docs, doc.info.Handle)
+	fsys.Remove(doc.path)
+}
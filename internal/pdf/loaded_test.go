@@ -0,0 +1,98 @@
+package pdf
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_LoadBytes(t *testing.T) {
+	service := NewService(1024 * 1024)
+	content := buildTestPDF("BT /F1 12 Tf 72 720 Td (In memory) Tj ET")
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+
+	doc, err := service.LoadBytes(PDFLoadBytesRequest{Content: encoded, Name: "memo.pdf"})
+	if err != nil {
+		t.Fatalf("LoadBytes() unexpected error = %v", err)
+	}
+	if !strings.HasPrefix(doc.Handle, LoadedHandlePrefix) || doc.Size != int64(len(content)) || doc.Name != "memo.pdf" {
+		t.Errorf("LoadBytes() = %+v", doc)
+	}
+	path, err := service.ResolvePath(doc.Handle)
+	if err != nil || path != doc.Path {
+		t.Fatalf("ResolvePath(%s) = %q, %v, want %s", doc.Handle, path, err, doc.Path)
+	}
+	result, err := service.PDFReadFile(PDFReadFileRequest{Path: path})
+	if err != nil || !strings.Contains(result.Content, "In memory") {
+		t.Errorf("PDFReadFile() of the loaded document = %+v, %v", result, err)
+	}
+	if path, err := service.ResolvePath("/docs/report.pdf"); err != nil || path != "/docs/report.pdf" {
+		t.Errorf("ResolvePath() of a file = %q, %v, want it unchanged", path, err)
+	}
+
+	// Data URLs are accepted
+	if _, err := service.LoadBytes(PDFLoadBytesRequest{Content: "data:application/pdf;base64," + encoded}); err != nil {
+		t.Errorf("LoadBytes() of a data URL unexpected error = %v", err)
+	}
+
+	// Documents unused for too long are dropped with their files
+	service.loaded.docs[doc.Handle].lastUsed = time.Now().Add(-2 * loadedRetention)
+	if _, err := service.ResolvePath(doc.Handle); err == nil || !strings.Contains(err.Error(), "unknown handle") {
+		t.Errorf("ResolvePath() of an expired handle error = %v", err)
+	}
+	if _, err := os.Stat(doc.Path); !os.IsNotExist(err) {
+		t.Errorf("file of an expired document remains: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		service  *Service
+		content  string
+		errorMsg string
+	}{
+		{name: "empty", service: service, content: " ", errorMsg: "cannot be empty"},
+		{name: "not base64", service: service, content: "%PDF-1.4", errorMsg: "not valid base64"},
+		{name: "not a pdf", service: service, content: base64.StdEncoding.EncodeToString([]byte("hello")),
+			errorMsg: "not a PDF"},
+		{name: "too large", service: NewService(16), content: encoded, errorMsg: "too large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.service.LoadBytes(PDFLoadBytesRequest{Content: tt.content})
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("LoadBytes() error = %v, want it to contain %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestService_Close(t *testing.T) {
+	service := NewService(1024 * 1024)
+	encoded := base64.StdEncoding.EncodeToString([]byte(buildTestPDF("BT /F1 12 Tf 72 720 Td (Kept) Tj ET")))
+	doc, err := service.LoadBytes(PDFLoadBytesRequest{Content: encoded})
+	if err != nil {
+		t.Fatalf("LoadBytes() unexpected error = %v", err)
+	}
+	dir := filepath.Dir(doc.Path)
+
+	service.Close()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("directory of loaded documents remains after Close(): %v", err)
+	}
+	if _, err := service.ResolvePath(doc.Handle); err == nil {
+		t.Errorf("ResolvePath() of a handle loaded before Close() succeeded")
+	}
+
+	// The service loads documents again afterwards, into a new directory
+	again, err := service.LoadBytes(PDFLoadBytesRequest{Content: encoded})
+	if err != nil {
+		t.Fatalf("LoadBytes() after Close() unexpected error = %v", err)
+	}
+	if _, err := os.Stat(again.Path); err != nil {
+		t.Errorf("file of a document loaded after Close() is missing: %v", err)
+	}
+	service.Close()
+}
@@ -0,0 +1,292 @@
+package pdf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+)
+
+// Histogram bucket upper bounds
+var (
+	durationBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60} // Seconds
+	bytesBuckets    = []float64{1 << 10, 1 << 14, 1 << 18, 1 << 20, 1 << 22, 1 << 24, 1 << 26, 1 << 28, 1 << 30}
+	pagesBuckets    = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000}
+)
+
+// histogram counts observations in buckets, as Prometheus histograms do
+type histogram struct {
+	bounds []float64
+	counts []int64 // Observations per bucket, the last counting those above every bound
+	count  int64
+	sum    float64
+	max    float64
+}
+
+// newHistogram creates an empty histogram with the given bucket bounds
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]int64, len(bounds)+1)}
+}
+
+// observe adds an observation
+func (h *histogram) observe(value float64) {
+	h.counts[sort.SearchFloat64s(h.bounds, value)]++
+	h.count++
+	h.sum += value
+	h.max = math.Max(h.max, value)
+}
+
+// mean returns the average observation, or zero without any
+func (h *histogram) mean() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / float64(h.count)
+}
+
+// toolMetrics are the measurements of one tool's calls
+type toolMetrics struct {
+	calls    int64
+	errors   map[string]int64 // Failed calls by error code
+	duration *histogram
+	bytes    *histogram // Size of the documents each call read
+}
+
+// Metrics instruments a service: the calls of each tool and the extractions the engine ran.
+// It is safe for concurrent use.
+type Metrics struct {
+	mu          sync.Mutex
+	started     time.Time
+	tools       map[string]*toolMetrics
+	extractions struct {
+		runs     int64
+		partial  int64
+		pages    *histogram
+		duration *histogram
+	}
+}
+
+// NewMetrics creates empty metrics, counting uptime from now
+func NewMetrics() *Metrics {
+	m := &Metrics{started: time.Now(), tools: make(map[string]*toolMetrics)}
+	m.extractions.pages = newHistogram(pagesBuckets)
+	m.extractions.duration = newHistogram(durationBuckets)
+	return m
+}
+
+// ObserveTool records a tool call: how long it took, the bytes of the documents it read, and
+// the code it failed with, or an empty code when it succeeded
+func (m *Metrics) ObserveTool(name string, duration time.Duration, bytes int64, code string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tool, ok := m.tools[name]
+	if !ok {
+		tool = &toolMetrics{
+			errors:   make(map[string]int64),
+			duration: newHistogram(durationBuckets),
+			bytes:    newHistogram(bytesBuckets),
+		}
+		m.tools[name] = tool
+	}
+	tool.calls++
+	if code != "" {
+		tool.errors[code]++
+	}
+	tool.duration.observe(duration.Seconds())
+	if bytes > 0 {
+		tool.bytes.observe(float64(bytes))
+	}
+}
+
+// observeExtraction records an extraction the engine ran, leaving out results served from
+// the cache
+func (m *Metrics) observeExtraction(pages int, duration time.Duration, partial bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.extractions.runs++
+	if partial {
+		m.extractions.partial++
+	}
+	m.extractions.pages.observe(float64(pages))
+	m.extractions.duration.observe(duration.Seconds())
+}
+
+// Metrics returns the service's metrics
+func (s *Service) Metrics() *Metrics {
+	return s.metrics
+}
+
+// MetricsSummary summarizes the service's metrics for pdf_server_info
+func (s *Service) MetricsSummary() *MetricsSummary {
+	cacheStats := s.extractionService.CacheStats()
+	handleStats := core.HandleCacheStats()
+
+	m := s.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	summary := &MetricsSummary{
+		UptimeSeconds:      math.Round(time.Since(m.started).Seconds()),
+		Extractions:        m.extractions.runs,
+		PartialExtractions: m.extractions.partial,
+		PagesExtracted:     int64(m.extractions.pages.sum),
+		CacheHitRate:       hitRate(cacheStats.Hits, cacheStats.Misses),
+		HandleHitRate:      hitRate(handleStats.Hits, handleStats.Misses),
+		Tools:              []ToolMetricsSummary{},
+	}
+	for name, tool := range m.tools {
+		var errors int64
+		for _, count := range tool.errors {
+			errors += count
+		}
+		summary.ToolCalls += tool.calls
+		summary.ToolErrors += errors
+		summary.Tools = append(summary.Tools, ToolMetricsSummary{
+			Name:           name,
+			Calls:          tool.calls,
+			Errors:         errors,
+			MeanDurationMS: math.Round(tool.duration.mean() * 1000),
+			MaxDurationMS:  math.Round(tool.duration.max * 1000),
+			BytesRead:      int64(tool.bytes.sum),
+		})
+	}
+	sort.Slice(summary.Tools, func(i, j int) bool {
+		if summary.Tools[i].Calls != summary.Tools[j].Calls {
+			return summary.Tools[i].Calls > summary.Tools[j].Calls
+		}
+		return summary.Tools[i].Name < summary.Tools[j].Name
+	})
+	return summary
+}
+
+// hitRate returns the share of lookups that hit, or zero without any
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return math.Round(float64(hits)/float64(hits+misses)*1000) / 1000
+}
+
+// WriteMetrics writes the service's metrics in the Prometheus text exposition format
+func (s *Service) WriteMetrics(w io.Writer) error {
+	cacheStats := s.extractionService.CacheStats()
+	handleStats := core.HandleCacheStats()
+
+	m := s.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := bufio.NewWriter(w)
+	p := metricsWriter{out: out}
+
+	names := make([]string, 0, len(m.tools))
+	for name := range m.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p.header("mcp_pdf_uptime_seconds", "gauge", "Seconds since the server started.")
+	p.sample("mcp_pdf_uptime_seconds", "", time.Since(m.started).Seconds())
+
+	p.header("mcp_pdf_tool_calls_total", "counter", "Tool calls, by tool.")
+	for _, name := range names {
+		p.sample("mcp_pdf_tool_calls_total", labels("tool", name), float64(m.tools[name].calls))
+	}
+	p.header("mcp_pdf_tool_errors_total", "counter", "Failed tool calls, by tool and error code.")
+	for _, name := range names {
+		codes := make([]string, 0, len(m.tools[name].errors))
+		for code := range m.tools[name].errors {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			p.sample("mcp_pdf_tool_errors_total", labels("tool", name, "code", code),
+				float64(m.tools[name].errors[code]))
+		}
+	}
+	p.header("mcp_pdf_tool_duration_seconds", "histogram", "Duration of tool calls, by tool.")
+	for _, name := range names {
+		p.histogram("mcp_pdf_tool_duration_seconds", "tool", name, m.tools[name].duration)
+	}
+	p.header("mcp_pdf_tool_input_bytes", "histogram", "Size of the documents tool calls read, by tool.")
+	for _, name := range names {
+		p.histogram("mcp_pdf_tool_input_bytes", "tool", name, m.tools[name].bytes)
+	}
+
+	p.header("mcp_pdf_extractions_total", "counter", "Extractions run by the engine, excluding cached results.")
+	p.sample("mcp_pdf_extractions_total", "", float64(m.extractions.runs))
+	p.header("mcp_pdf_extractions_partial_total", "counter", "Extractions cut short by a timeout or limit.")
+	p.sample("mcp_pdf_extractions_partial_total", "", float64(m.extractions.partial))
+	p.header("mcp_pdf_extraction_pages", "histogram", "Pages processed by each extraction.")
+	p.histogram("mcp_pdf_extraction_pages", "", "", m.extractions.pages)
+	p.header("mcp_pdf_extraction_duration_seconds", "histogram", "Duration of each extraction.")
+	p.histogram("mcp_pdf_extraction_duration_seconds", "", "", m.extractions.duration)
+
+	p.header("mcp_pdf_cache_hits_total", "counter", "Extraction cache lookups that found a result.")
+	p.sample("mcp_pdf_cache_hits_total", "", float64(cacheStats.Hits))
+	p.header("mcp_pdf_cache_misses_total", "counter", "Extraction cache lookups that found none.")
+	p.sample("mcp_pdf_cache_misses_total", "", float64(cacheStats.Misses))
+	p.header("mcp_pdf_cache_evictions_total", "counter", "Results evicted from the extraction cache.")
+	p.sample("mcp_pdf_cache_evictions_total", "", float64(cacheStats.Evictions))
+	p.header("mcp_pdf_cache_bytes", "gauge", "Bytes held by the extraction cache.")
+	p.sample("mcp_pdf_cache_bytes", "", float64(cacheStats.Bytes))
+
+	p.header("mcp_pdf_document_handle_hits_total", "counter", "Document opens served by a held parsed document.")
+	p.sample("mcp_pdf_document_handle_hits_total", "", float64(handleStats.Hits))
+	p.header("mcp_pdf_document_handle_misses_total", "counter", "Document opens that parsed the file.")
+	p.sample("mcp_pdf_document_handle_misses_total", "", float64(handleStats.Misses))
+	p.header("mcp_pdf_document_handles_open", "gauge", "Parsed documents held between tool calls.")
+	p.sample("mcp_pdf_document_handles_open", "", float64(handleStats.Open))
+	return out.Flush()
+}
+
+// metricsWriter writes metrics in the Prometheus text exposition format
+type metricsWriter struct {
+	out *bufio.Writer
+}
+
+// header writes the help and type lines of a metric
+func (p metricsWriter) header(name, kind, help string) {
+	fmt.Fprintf(p.out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one sample of a metric, with its labels formatted by labels
+func (p metricsWriter) sample(name, labelText string, value float64) {
+	fmt.Fprintf(p.out, "%s%s %s\n", name, labelText, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// histogram writes the cumulative buckets, sum, and count of a histogram, labeled with one
+// label unless its name is empty
+func (p metricsWriter) histogram(name, label, value string, h *histogram) {
+	var base []string
+	if label != "" {
+		base = []string{label, value}
+	}
+	var cumulative int64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		le := strconv.FormatFloat(bound, 'g', -1, 64)
+		p.sample(name+"_bucket", labels(append(base, "le", le)...), float64(cumulative))
+	}
+	p.sample(name+"_bucket", labels(append(base, "le", "+Inf")...), float64(h.count))
+	p.sample(name+"_sum", labels(base...), h.sum)
+	p.sample(name+"_count", labels(base...), float64(h.count))
+}
+
+// labels formats alternating label names and values, escaping the values
+func labels(pairs ...string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], escaper.Replace(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,58 @@
+package pdf
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_Metrics(t *testing.T) {
+	path := createTempFile(t, "report.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Quarterly report) Tj ET"))
+	s := NewService(1024 * 1024)
+
+	if _, err := s.ExtractStructured(context.Background(), PDFExtractStructuredRequest{Path: path}); err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	s.Metrics().ObserveTool("pdf_extract_structured", 120*time.Millisecond, 2048, "")
+	s.Metrics().ObserveTool("pdf_extract_structured", 3*time.Second, 0, "NOT_FOUND")
+	s.Metrics().ObserveTool("pdf_read_file", 10*time.Millisecond, 512, "")
+
+	summary := s.MetricsSummary()
+	if summary.ToolCalls != 3 || summary.ToolErrors != 1 || summary.Extractions != 1 || summary.PagesExtracted != 1 {
+		t.Errorf("MetricsSummary() = %+v", summary)
+	}
+	if len(summary.Tools) != 2 || summary.Tools[0].Name != "pdf_extract_structured" ||
+		summary.Tools[0].MaxDurationMS != 3000 || summary.Tools[0].BytesRead != 2048 {
+		t.Errorf("MetricsSummary().Tools = %+v, want the most called tool first", summary.Tools)
+	}
+
+	var out strings.Builder
+	if err := s.WriteMetrics(&out); err != nil {
+		t.Fatalf("WriteMetrics() unexpected error = %v", err)
+	}
+	for _, want := range []string{
+		"# TYPE mcp_pdf_tool_calls_total counter",
+		`mcp_pdf_tool_calls_total{tool="pdf_extract_structured"} 2`,
+		`mcp_pdf_tool_errors_total{tool="pdf_extract_structured",code="NOT_FOUND"} 1`,
+		`mcp_pdf_tool_duration_seconds_bucket{tool="pdf_extract_structured",le="0.25"} 1`,
+		`mcp_pdf_tool_duration_seconds_bucket{tool="pdf_extract_structured",le="+Inf"} 2`,
+		`mcp_pdf_tool_input_bytes_count{tool="pdf_read_file"} 1`,
+		"mcp_pdf_extractions_total 1",
+		`mcp_pdf_extraction_pages_bucket{le="1"} 1`,
+		"mcp_pdf_cache_misses_total",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteMetrics() output lacks %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestLabels(t *testing.T) {
+	if got := labels("tool", `a"b\c`); got != `{tool="a\"b\\c"}` {
+		t.Errorf("labels() = %s", got)
+	}
+	if got := labels(); got != "" {
+		t.Errorf("labels() without pairs = %q", got)
+	}
+}
@@ -0,0 +1,373 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Region OCR defaults
+const (
+	defaultOCRDPI      = 300
+	defaultOCRLanguage = "eng"
+	// ocrEngine is the external OCR program used
+	ocrEngine = "tesseract"
+	// ocrTSVWordLevel is the level tesseract gives the rows of its TSV output that hold words
+	ocrTSVWordLevel = 5
+	// ocrTSVColumns is how many columns the rows of tesseract's TSV output have
+	ocrTSVColumns = 12
+)
+
+// Sources of OCR regions
+const (
+	OCRSourceRegion = "region"
+	OCRSourceImage  = "image"
+)
+
+// ocrLanguage matches tesseract language names such as "eng" or "deu+fra", and keeps
+// anything that could be read as an option out of its arguments
+var ocrLanguage = regexp.MustCompile(`^[A-Za-z0-9_]+(\+[A-Za-z0-9_]+)*$`)
+
+// RegionOCR recognizes the text of selected regions of a page, such as stamps and scanned
+// signature blocks, by rendering the page and running an installed OCR engine on each region
+type RegionOCR struct {
+	renderer *Renderer
+	engine   string
+	language string // Language used when a request names none
+}
+
+// NewRegionOCR creates a new region OCR with the specified constraints
+func NewRegionOCR(maxFileSize int64) *RegionOCR {
+	return &RegionOCR{
+		renderer: NewRenderer(maxFileSize),
+		engine:   ocrEngine,
+		language: defaultOCRLanguage,
+	}
+}
+
+// SetDefaults sets the OCR program run and the language recognized when a request names
+// none; empty values keep the current settings
+func (o *RegionOCR) SetDefaults(engine, language string) {
+	if engine != "" {
+		o.engine = engine
+	}
+	if language != "" {
+		o.language = language
+	}
+}
+
+// RecognizeRegions renders a page and recognizes the text of the requested regions, or of
+// every image the page places when no regions are given. Recognized words come back with
+// their boxes mapped from the render to page space.
+func (o *RegionOCR) RecognizeRegions(req PDFOCRRegionRequest) (*PDFOCRRegionResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	page := req.Page
+	if page == 0 {
+		page = 1
+	}
+	dpi := req.DPI
+	if dpi == 0 {
+		dpi = defaultOCRDPI
+	}
+	if dpi < minRenderDPI || dpi > maxRenderDPI {
+		return nil, fmt.Errorf("dpi must be between %d and %d, got %d", minRenderDPI, maxRenderDPI, dpi)
+	}
+	language := req.Language
+	if language == "" {
+		language = o.language
+	}
+	if language == "" {
+		language = defaultOCRLanguage
+	}
+	if !ocrLanguage.MatchString(language) {
+		return nil, fmt.Errorf("invalid language: %q (use tesseract language names such as eng or deu+fra)", language)
+	}
+	for i, region := range req.Regions {
+		if region.Width <= 0 || region.Height <= 0 {
+			return nil, fmt.Errorf("region %d must have a positive width and height", i+1)
+		}
+	}
+
+	fileInfo, err := fsys.Stat(req.Path)
+	if os.IsNotExist(err) {
+		return nil, pdferrors.New(pdferrors.NotFound, "file does not exist: %s", req.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot access file: %w", err)
+	}
+	if err := o.renderer.validator.ValidateFileInfo(req.Path, fileInfo); err != nil {
+		return nil, err
+	}
+
+	pageLabel, err := checkRenderSize(req.Path, page, dpi)
+	if err != nil {
+		return nil, err
+	}
+	raster, images, err := readOCRPage(req.Path, page, dpi)
+	if err != nil {
+		return nil, err
+	}
+
+	regions := make([]OCRRegion, 0, len(req.Regions))
+	for _, region := range req.Regions {
+		regions = append(regions, OCRRegion{Source: OCRSourceRegion, BoundingBox: region})
+	}
+	if len(regions) == 0 {
+		for _, image := range images {
+			regions = append(regions, OCRRegion{Source: OCRSourceImage, BoundingBox: convertBoundingBox(image.BoundingBox)})
+		}
+		if len(regions) == 0 {
+			return nil, fmt.Errorf("page %d places no images; give regions to recognize", page)
+		}
+	}
+
+	if _, err := lookProgram(o.engine); err != nil {
+		return nil, pdferrors.New(pdferrors.UnsupportedFeature,
+			"no OCR engine is installed: install %s to recognize regions", o.engine)
+	}
+	backend, err := o.renderer.findBackend()
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := runRenderBackend(backend, req.Path, page, dpi)
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(rendered))
+	if err != nil {
+		return nil, fmt.Errorf("%s produced an unreadable image: %w", backend.name, err)
+	}
+	cropper, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, fmt.Errorf("%s produced an image that cannot be cropped", backend.name)
+	}
+
+	for i := range regions {
+		pixels := raster.toPixels(regions[i].BoundingBox).Intersect(img.Bounds())
+		if pixels.Empty() {
+			return nil, fmt.Errorf("region %d lies outside page %d", i+1, page)
+		}
+		words, err := o.recognize(cropper.SubImage(pixels), language, dpi)
+		if err != nil {
+			return nil, err
+		}
+		for j := range words {
+			words[j].BoundingBox = raster.toPoints(words[j].pixels.Add(pixels.Min))
+		}
+		regions[i].Text, regions[i].Confidence = joinOCRWords(words)
+		regions[i].Words = make([]OCRWord, len(words))
+		for j, word := range words {
+			regions[i].Words[j] = word.OCRWord
+		}
+	}
+
+	return &PDFOCRRegionResult{
+		Path:      req.Path,
+		Page:      page,
+		PageLabel: pageLabel,
+		DPI:       dpi,
+		Language:  language,
+		Engine:    o.engine,
+		Renderer:  backend.name,
+		Regions:   regions,
+	}, nil
+}
+
+// pageRaster maps between page space and the pixels of a page rendered at a resolution,
+// which show the page's crop box turned by its rotation
+type pageRaster struct {
+	box      extraction.BoundingBox
+	rotation int
+	scale    float64 // Pixels per point
+}
+
+// readOCRPage returns how a page renders at dpi and where it places its images
+func readOCRPage(path string, page, dpi int) (raster pageRaster, images []extraction.ImagePlacement, err error) {
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		return pageRaster{}, nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("failed to read page: %v", rec)
+		}
+	}()
+
+	numbering := extraction.NewPageNumbering(r)
+	box, ok := extraction.PageCropBox(numbering, page)
+	if !ok {
+		return pageRaster{}, nil, fmt.Errorf("page %d has an invalid MediaBox", page)
+	}
+	images, err = extraction.PageImagePlacements(numbering.Page(page))
+	if err != nil {
+		return pageRaster{}, nil, fmt.Errorf("failed to find the images of page %d: %w", page, err)
+	}
+	raster = pageRaster{box: box, rotation: numbering.Rotation(page), scale: float64(dpi) / pointsPerInch}
+	return raster, images, nil
+}
+
+// toPixel maps a point of the page to a pixel position in the render
+func (p pageRaster) toPixel(x, y float64) (float64, float64) {
+	left, bottom := p.box.LowerLeft.X, p.box.LowerLeft.Y
+	right, top := p.box.UpperRight.X, p.box.UpperRight.Y
+	switch p.rotation {
+	case 90:
+		return (y - bottom) * p.scale, (x - left) * p.scale
+	case 180:
+		return (right - x) * p.scale, (y - bottom) * p.scale
+	case 270:
+		return (top - y) * p.scale, (right - x) * p.scale
+	default:
+		return (x - left) * p.scale, (top - y) * p.scale
+	}
+}
+
+// toPoint maps a pixel position in the render back to a point of the page
+func (p pageRaster) toPoint(px, py float64) (float64, float64) {
+	left, bottom := p.box.LowerLeft.X, p.box.LowerLeft.Y
+	right, top := p.box.UpperRight.X, p.box.UpperRight.Y
+	switch p.rotation {
+	case 90:
+		return left + py/p.scale, bottom + px/p.scale
+	case 180:
+		return right - px/p.scale, bottom + py/p.scale
+	case 270:
+		return right - py/p.scale, top - px/p.scale
+	default:
+		return left + px/p.scale, top - py/p.scale
+	}
+}
+
+// toPixels returns the pixels of the render covering a rectangle of the page, rounded out
+// to whole pixels
+func (p pageRaster) toPixels(rect Rectangle) image.Rectangle {
+	x0, y0 := p.toPixel(rect.X, rect.Y)
+	x1, y1 := p.toPixel(rect.X+rect.Width, rect.Y+rect.Height)
+	return image.Rect(
+		int(math.Floor(min(x0, x1))), int(math.Floor(min(y0, y1))),
+		int(math.Ceil(max(x0, x1))), int(math.Ceil(max(y0, y1))),
+	)
+}
+
+// toPoints returns the rectangle of the page covered by pixels of the render
+func (p pageRaster) toPoints(pixels image.Rectangle) Rectangle {
+	x0, y0 := p.toPoint(float64(pixels.Min.X), float64(pixels.Min.Y))
+	x1, y1 := p.toPoint(float64(pixels.Max.X), float64(pixels.Max.Y))
+	return Rectangle{X: min(x0, x1), Y: min(y0, y1), Width: math.Abs(x1 - x0), Height: math.Abs(y1 - y0)}
+}
+
+// ocrWord is a recognized word with its pixels in the image recognized
+type ocrWord struct {
+	OCRWord
+	pixels image.Rectangle
+	line   string // Block, paragraph, and line the engine placed the word in
+}
+
+// recognize runs the OCR engine on an image and returns the words it reads
+func (o *RegionOCR) recognize(img image.Image, language string, dpi int) ([]ocrWord, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode region: %w", err)
+	}
+	run := programRun{
+		name:   o.engine,
+		inputs: map[string][]byte{"region.png": buf.Bytes()},
+		args: func(dir string) []string {
+			return []string{filepath.Join(dir, "region.png"), "stdout", "-l", language, "--dpi", strconv.Itoa(dpi), "tsv"}
+		},
+	}
+	out, _, err := run.run()
+	if err != nil {
+		return nil, err
+	}
+	return parseOCRTSV(string(out))
+}
+
+// parseOCRTSV reads the words of tesseract's TSV output, whose rows give a level, the
+// page, block, paragraph, line, and word numbers, a pixel box, a confidence of 0-100, and
+// the text. Rows above the word level and blank words are skipped.
+func parseOCRTSV(tsv string) ([]ocrWord, error) {
+	var words []ocrWord
+	for i, line := range strings.Split(strings.TrimRight(tsv, "\n"), "\n") {
+		if i == 0 || strings.TrimSpace(line) == "" {
+			continue // Header
+		}
+		fields := strings.SplitN(strings.TrimRight(line, "\r"), "\t", ocrTSVColumns)
+		if len(fields) < ocrTSVColumns-1 {
+			return nil, fmt.Errorf("unexpected OCR output on line %d: %q", i+1, line)
+		}
+		if level, err := strconv.Atoi(fields[0]); err != nil || level != ocrTSVWordLevel {
+			continue
+		}
+		text := ""
+		if len(fields) == ocrTSVColumns {
+			text = strings.TrimSpace(fields[11])
+		}
+		if text == "" {
+			continue
+		}
+
+		var numbers [4]int
+		for j := range numbers {
+			value, err := strconv.Atoi(fields[6+j])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected OCR output on line %d: %q", i+1, line)
+			}
+			numbers[j] = value
+		}
+		confidence, err := strconv.ParseFloat(fields[10], 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected OCR output on line %d: %q", i+1, line)
+		}
+
+		words = append(words, ocrWord{
+			OCRWord: OCRWord{Text: text, Confidence: math.Max(confidence, 0) / 100},
+			pixels:  image.Rect(numbers[0], numbers[1], numbers[0]+numbers[2], numbers[1]+numbers[3]),
+			line:    strings.Join(fields[2:5], "."),
+		})
+	}
+	return words, nil
+}
+
+// joinOCRWords returns the text of recognized words, a line of text per line the engine
+// found, and their mean confidence
+func joinOCRWords(words []ocrWord) (string, float64) {
+	if len(words) == 0 {
+		return "", 0
+	}
+	var text strings.Builder
+	var total float64
+	for i, word := range words {
+		if i > 0 {
+			if word.line == words[i-1].line {
+				text.WriteString(" ")
+			} else {
+				text.WriteString("\n")
+			}
+		}
+		text.WriteString(word.Text)
+		total += word.Confidence
+	}
+	return text.String(), total / float64(len(words))
+}
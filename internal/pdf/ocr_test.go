@@ -0,0 +1,143 @@
+package pdf
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// ocrTSV is tesseract output for a stamp of two lines
+const ocrTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t50\t60\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t5\t4\t40\t10\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t5\t4\t20\t10\t96.5\tAPPROVED\n" +
+	"5\t1\t1\t1\t1\t2\t27\t4\t18\t10\t88.5\t2024\n" +
+	"5\t1\t1\t1\t2\t1\t5\t20\t30\t10\t71\tFinance\n" +
+	"5\t1\t1\t1\t2\t2\t36\t20\t4\t10\t-1\t \n"
+
+// fakeRegionOCR returns a region OCR whose renderer copies a fixed PNG and whose engine
+// prints fixed TSV, so recognition can be tested without tesseract installed
+func fakeRegionOCR(t *testing.T) *RegionOCR {
+	t.Helper()
+
+	engine := filepath.Join(t.TempDir(), "fake-tesseract")
+	script := "#!/bin/sh\ncat <<'EOF'\n" + ocrTSV + "EOF\n"
+	if err := os.WriteFile(engine, []byte(script), 0o700); err != nil { //nolint:gosec // The test script must be executable
+		t.Fatalf("failed to write fake engine: %v", err)
+	}
+	return &RegionOCR{renderer: fakeRenderer(t), engine: engine}
+}
+
+func TestRegionOCR_RecognizeRegions(t *testing.T) {
+	ocr := fakeRegionOCR(t)
+	path := createTempFile(t, "stamped.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Invoice) Tj ET"))
+
+	result, err := ocr.RecognizeRegions(PDFOCRRegionRequest{
+		Path: path, DPI: 72, Regions: []Rectangle{{X: 10, Y: 700, Width: 50, Height: 60}},
+	})
+	if err != nil {
+		t.Fatalf("RecognizeRegions() unexpected error = %v", err)
+	}
+	if result.Page != 1 || result.Language != defaultOCRLanguage || result.Renderer != "cp" || len(result.Regions) != 1 {
+		t.Fatalf("RecognizeRegions() = %+v, want one region of page 1 in English", result)
+	}
+
+	region := result.Regions[0]
+	if region.Source != OCRSourceRegion || region.Text != "APPROVED 2024\nFinance" || len(region.Words) != 3 {
+		t.Errorf("region = %+v, want the stamp's two lines", region)
+	}
+	if region.Confidence < 0.85 || region.Confidence > 0.86 {
+		t.Errorf("region confidence = %g, want the mean of its words", region.Confidence)
+	}
+	// The region starts 10 pixels right of and 32 pixels below the top-left of the render
+	want := Rectangle{X: 15, Y: 746, Width: 20, Height: 10}
+	if got := region.Words[0].BoundingBox; got != want {
+		t.Errorf("first word at %+v, want %+v in page space", got, want)
+	}
+}
+
+func TestRegionOCR_RecognizeRegionsErrors(t *testing.T) {
+	ocr := fakeRegionOCR(t)
+	path := createTempFile(t, "plain.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Invoice) Tj ET"))
+	region := []Rectangle{{X: 10, Y: 700, Width: 50, Height: 60}}
+
+	missing := fakeRegionOCR(t)
+	missing.engine = "no-such-ocr-engine"
+
+	tests := []struct {
+		name     string
+		ocr      *RegionOCR
+		req      PDFOCRRegionRequest
+		errorMsg string
+	}{
+		{name: "empty path", req: PDFOCRRegionRequest{}, errorMsg: "path cannot be empty"},
+		{name: "bad language", req: PDFOCRRegionRequest{Path: path, Language: "-psm"}, errorMsg: "invalid language"},
+		{
+			name:     "empty region",
+			req:      PDFOCRRegionRequest{Path: path, Regions: []Rectangle{{X: 10, Y: 10}}},
+			errorMsg: "positive width and height",
+		},
+		{name: "no images", req: PDFOCRRegionRequest{Path: path}, errorMsg: "places no images"},
+		{
+			name:     "outside the page",
+			req:      PDFOCRRegionRequest{Path: path, DPI: 72, Regions: []Rectangle{{X: 700, Y: 10, Width: 50, Height: 50}}},
+			errorMsg: "lies outside page 1",
+		},
+		{
+			name:     "no engine installed",
+			ocr:      missing,
+			req:      PDFOCRRegionRequest{Path: path, Regions: region},
+			errorMsg: "no OCR engine is installed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := ocr
+			if tt.ocr != nil {
+				o = tt.ocr
+			}
+			_, err := o.RecognizeRegions(tt.req)
+			if err == nil {
+				t.Fatal("RecognizeRegions() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("RecognizeRegions() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestPageRaster_RoundTrip(t *testing.T) {
+	box := extraction.BoundingBox{
+		LowerLeft:  extraction.Coordinate{X: 10, Y: 20},
+		UpperRight: extraction.Coordinate{X: 622, Y: 812},
+		Width:      612,
+		Height:     792,
+	}
+	rect := Rectangle{X: 100, Y: 500, Width: 72, Height: 36}
+
+	tests := []struct {
+		rotation int
+		want     image.Rectangle
+	}{
+		{0, image.Rect(180, 552, 324, 624)},
+		{90, image.Rect(960, 180, 1032, 324)},
+		{180, image.Rect(900, 960, 1044, 1032)},
+		{270, image.Rect(552, 900, 624, 1044)},
+	}
+	for _, tt := range tests {
+		raster := pageRaster{box: box, rotation: tt.rotation, scale: 2}
+		pixels := raster.toPixels(rect)
+		if pixels != tt.want {
+			t.Errorf("rotation %d: toPixels() = %v, want %v", tt.rotation, pixels, tt.want)
+		}
+		if back := raster.toPoints(pixels); back != rect {
+			t.Errorf("rotation %d: toPoints() = %+v, want %+v", tt.rotation, back, rect)
+		}
+	}
+}
@@ -0,0 +1,672 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"math"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// optimizedMIMEType is the MIME type of optimized documents
+	optimizedMIMEType = "application/pdf"
+	// objectStreamCapacity is the most objects packed into one object stream
+	objectStreamCapacity = 100
+	// linearizationWindow is how far into a file its linearization dictionary must start
+	linearizationWindow = 1024
+	// minDownsampleDPI is the lowest resolution pdf_optimize downsamples images to
+	minDownsampleDPI = 36
+	// downsampleJPEGQuality is the quality JPEG images are encoded at when downsampled
+	downsampleJPEGQuality = 85
+)
+
+var (
+	// linearizedPattern finds the linearization dictionary and its file length entry
+	linearizedPattern = regexp.MustCompile(`/Linearized\s[^>]*?/L\s+(\d+)`)
+	// xrefStreamPattern finds cross-reference streams
+	xrefStreamPattern = regexp.MustCompile(`/Type\s*/XRef\b`)
+	// pdfVersionPattern reads the version from the file header
+	pdfVersionPattern = regexp.MustCompile(`^%PDF-(\d\.\d)`)
+)
+
+// HighResolutionDPI is the resolution above which the optimization report suggests
+// downsampling images
+const HighResolutionDPI = 300
+
+// Optimizer reports how PDF files could be made smaller and writes smaller copies
+type Optimizer struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewOptimizer creates a new optimizer with the specified constraints
+func NewOptimizer(maxFileSize int64) *Optimizer {
+	return &Optimizer{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// optimizeSource is an opened document to report on or optimize
+type optimizeSource struct {
+	doc    *core.Document
+	reader *pdf.Reader
+	head   []byte // The file's opening bytes, holding its header and any linearization dictionary
+	size   int64
+}
+
+// open validates and opens a document, refusing encrypted ones, whose streams the copy
+// could not carry over
+func (o *Optimizer) open(path string) (*optimizeSource, error) {
+	doc, err := core.Open(path, core.Options{Validate: o.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	if !doc.Reader.Trailer().Key("Encrypt").IsNull() {
+		doc.Close()
+		return nil, fmt.Errorf("cannot optimize encrypted documents")
+	}
+	size := doc.Info().Size()
+	head := make([]byte, min(size, linearizationWindow))
+	if _, err := doc.ReadAt(head, 0); err != nil && err != io.EOF {
+		doc.Close()
+		return nil, fmt.Errorf("failed to read PDF header: %w", err)
+	}
+	return &optimizeSource{doc: doc, reader: doc.Reader, head: head, size: size}, nil
+}
+
+// Report describes how a document is stored and what rewriting it would save: whether it
+// is linearized for fast web view, how it uses object streams, the objects nothing refers
+// to, and image streams stored more than once. The estimate is the size of the copy
+// pdf_optimize writes without downsampling images.
+func (o *Optimizer) Report(ctx context.Context, req PDFOptimizeReportRequest) (*PDFOptimizeReportResult, error) {
+	source, err := o.open(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer source.doc.Close()
+
+	result := &PDFOptimizeReportResult{
+		Path:            req.Path,
+		FileSize:        source.size,
+		DuplicateImages: []DuplicateImageGroup{},
+		Recommendations: []string{},
+	}
+	if match := pdfVersionPattern.FindSubmatch(source.head); match != nil {
+		result.Version = string(match[1])
+	}
+	if match := linearizedPattern.FindSubmatch(source.head); match != nil {
+		result.Linearized = true
+		length, _ := strconv.ParseInt(string(match[1]), 10, 64)
+		result.LinearizationStale = length != source.size
+	}
+
+	entries := crossReferenceEntries(source.reader)
+	objectStreams := map[uint32]bool{}
+	for _, entry := range entries {
+		result.Objects++
+		if entry.inStream {
+			result.ObjectsInStreams++
+			objectStreams[entry.stream] = true
+		}
+	}
+	result.ObjectStreams = len(objectStreams)
+	trailer := source.reader.Trailer()
+	result.XRefStream = trailer.Key("Type").Name() == "XRef"
+
+	numbering := extraction.NewPageNumbering(source.reader)
+	resolutions, err := imageResolutions(ctx, numbering)
+	if err != nil {
+		return nil, err
+	}
+	for _, dpi := range resolutions {
+		if dpi.effective > HighResolutionDPI {
+			result.HighResolutionImages++
+		}
+	}
+
+	compact := newCompaction()
+	writer := newDocumentWriter(source.doc)
+	writer.compact = compact
+	data, err := writer.write(trailer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate the optimized size: %w", err)
+	}
+
+	// Object streams and cross-reference streams are structure rather than content, and
+	// are rebuilt rather than copied
+	xrefStreams := len(xrefStreamPattern.FindAllIndex(mustReadAll(source.doc, source.size), -1))
+	result.UnusedObjects = max(0, result.Objects-len(writer.numbers)-result.ObjectStreams-xrefStreams)
+	result.Images = compact.images
+	result.UncompressedStreams = compact.compressed
+	for _, group := range compact.groups {
+		if len(group.Objects) > 1 {
+			result.DuplicateImages = append(result.DuplicateImages, *group)
+			result.DuplicateImageBytes += int64(group.Size * (len(group.Objects) - 1))
+		}
+	}
+	sort.Slice(result.DuplicateImages, func(i, j int) bool {
+		return result.DuplicateImages[i].Objects[0] < result.DuplicateImages[j].Objects[0]
+	})
+	result.EstimatedSize = int64(len(data))
+	result.EstimatedSavings = max(0, source.size-result.EstimatedSize)
+	result.SavingsPercent = savingsPercent(source.size, result.EstimatedSavings)
+	result.Recommendations = optimizeRecommendations(result)
+
+	return result, nil
+}
+
+// optimizeRecommendations suggests what would make a reported document smaller or faster
+// to view
+func optimizeRecommendations(result *PDFOptimizeReportResult) []string {
+	recommendations := []string{}
+	if result.UnusedObjects > 0 {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"Remove %d unused objects left by earlier edits with pdf_optimize", result.UnusedObjects))
+	}
+	if len(result.DuplicateImages) > 0 {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"Store the %d repeated images once with pdf_optimize", len(result.DuplicateImages)))
+	}
+	if result.UncompressedStreams > 0 {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"Compress %d uncompressed streams with pdf_optimize", result.UncompressedStreams))
+	}
+	if result.ObjectStreams == 0 && result.Objects > objectStreamCapacity {
+		recommendations = append(recommendations,
+			"Pack objects into compressed object streams with pdf_optimize")
+	}
+	if result.HighResolutionImages > 0 {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"Downsample %d images drawn above %d DPI with pdf_optimize and max_image_dpi",
+			result.HighResolutionImages, HighResolutionDPI))
+	}
+	if !result.Linearized || result.LinearizationStale {
+		recommendations = append(recommendations,
+			"Linearize the file with a tool such as qpdf --linearize for fast web view")
+	}
+	return recommendations
+}
+
+// Optimize writes a smaller copy of a document: only the objects it still uses, each image
+// stream once, uncompressed streams compressed, and other objects packed into compressed
+// object streams with a cross-reference stream. With MaxImageDPI, images drawn at a higher
+// resolution everywhere the pages show them are downsampled to it. The copy is not
+// linearized.
+func (o *Optimizer) Optimize(ctx context.Context, req PDFOptimizeRequest) (*PDFOptimizeResult, error) {
+	if req.MaxImageDPI < 0 || req.MaxImageDPI > 0 && req.MaxImageDPI < minDownsampleDPI {
+		return nil, fmt.Errorf("max_image_dpi must be at least %d", minDownsampleDPI)
+	}
+	source, err := o.open(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer source.doc.Close()
+
+	compact := newCompaction()
+	writer := newDocumentWriter(source.doc)
+	writer.compact = compact
+
+	result := &PDFOptimizeResult{Path: req.Path, MIMEType: optimizedMIMEType, OriginalSize: source.size}
+	if req.MaxImageDPI > 0 {
+		resolutions, err := imageResolutions(ctx, extraction.NewPageNumbering(source.reader))
+		if err != nil {
+			return nil, err
+		}
+		for ref, dpi := range resolutions {
+			if dpi.effective <= req.MaxImageDPI {
+				continue
+			}
+			if edit, ok := downsampleImage(source.doc, dpi.image, req.MaxImageDPI/dpi.effective); ok {
+				writer.edits[ref] = edit
+			}
+		}
+	}
+
+	data, err := writer.write(source.reader.Trailer())
+	if err != nil {
+		return nil, fmt.Errorf("failed to write optimized document: %w", err)
+	}
+	result.Size = len(data)
+	result.Savings = source.size - int64(len(data))
+	result.SavingsPercent = savingsPercent(source.size, result.Savings)
+	result.StreamsCompressed = compact.compressed
+	result.ImagesDownsampled = compact.downsampled
+	for _, group := range compact.groups {
+		result.DuplicateImagesMerged += len(group.Objects) - 1
+	}
+
+	if req.OutputDir == "" {
+		result.Data = base64.StdEncoding.EncodeToString(data)
+		return result, nil
+	}
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+	stem := strings.TrimSuffix(filepath.Base(req.Path), filepath.Ext(req.Path))
+	result.OutputPath = filepath.Join(req.OutputDir, stem+"-optimized.pdf")
+	if err := fsys.WriteFile(result.OutputPath, data, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save optimized document: %w", err)
+	}
+	return result, nil
+}
+
+// savingsPercent returns saved bytes as a percentage of the original size, to one decimal
+func savingsPercent(size, saved int64) float64 {
+	if size == 0 {
+		return 0
+	}
+	return math.Round(float64(saved)*1000/float64(size)) / 10
+}
+
+// mustReadAll returns the whole file, or nothing when it cannot be read
+func mustReadAll(file io.ReaderAt, size int64) []byte {
+	data := make([]byte, size)
+	if _, err := file.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil
+	}
+	return data
+}
+
+// xrefEntry is an object in use in the cross-reference table as the parser read it
+type xrefEntry struct {
+	id       uint32
+	gen      uint16
+	inStream bool   // Stored in an object stream rather than at a file offset
+	stream   uint32 // The object stream holding it
+	offset   int64  // The file offset of objects not in object streams
+}
+
+// crossReferenceEntries lists the objects in use in a document's cross-reference table. The
+// parser keeps the table in an unexported field, so it is read the same way as object numbers.
+func crossReferenceEntries(r *pdf.Reader) []xrefEntry {
+	table := reflect.ValueOf(r).Elem().FieldByName("xref")
+	if !table.IsValid() || table.Kind() != reflect.Slice {
+		return nil
+	}
+	var entries []xrefEntry
+	for i := 0; i < table.Len(); i++ {
+		entry := table.Index(i)
+		ptr, stream := entry.FieldByName("ptr"), entry.FieldByName("stream")
+		inStream, offset := entry.FieldByName("inStream"), entry.FieldByName("offset")
+		if !ptr.IsValid() || !stream.IsValid() || !inStream.IsValid() || !offset.IsValid() ||
+			ptr.Field(0).Uint() == 0 {
+			continue
+		}
+		entries = append(entries, xrefEntry{
+			id:       uint32(ptr.Field(0).Uint()),
+			gen:      uint16(ptr.Field(1).Uint()),
+			inStream: inStream.Bool(),
+			stream:   uint32(stream.Field(0).Uint()),
+			offset:   offset.Int(),
+		})
+	}
+	return entries
+}
+
+// imageResolution is the resolution of an image where the pages draw it largest
+type imageResolution struct {
+	image     pdf.Value
+	effective float64 // The lower of the horizontal and vertical DPI
+}
+
+// imageResolutions returns the lowest resolution each image XObject is drawn at on any
+// page. Images drawn only inside form XObjects are not found.
+func imageResolutions(ctx context.Context, numbering *extraction.PageNumbering) (
+	map[extraction.ObjectRef]imageResolution, error,
+) {
+	resolutions := map[extraction.ObjectRef]imageResolution{}
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page := numbering.Page(pageNum)
+		placements, err := extraction.PageImagePlacements(page)
+		if err != nil {
+			continue
+		}
+		xObjects := page.V.Key("Resources").Key("XObject")
+		for _, placement := range placements {
+			img := xObjects.Key(placement.Name)
+			ref := extraction.RefOf(img)
+			width, height := placement.Size()
+			if ref.ID == 0 || width == 0 || height == 0 {
+				continue
+			}
+			dpi := math.Min(float64(img.Key("Width").Int64())/(width/pointsPerInch),
+				float64(img.Key("Height").Int64())/(height/pointsPerInch))
+			if known, ok := resolutions[ref]; !ok || dpi < known.effective {
+				resolutions[ref] = imageResolution{image: img, effective: dpi}
+			}
+		}
+	}
+	return resolutions, nil
+}
+
+// downsampleImage returns an edit writing an image scaled by factor, as JPEG when it was
+// stored as JPEG and Flate-compressed otherwise. Images whose samples cannot be decoded or
+// that carry masks or decode arrays tied to their samples are left alone, as are those the
+// smaller copy would not make smaller.
+func downsampleImage(file io.ReaderAt, v pdf.Value, factor float64) (objectEdit, bool) {
+	if v.Key("ImageMask").Bool() || !v.Key("Mask").IsNull() || !v.Key("Decode").IsNull() {
+		return nil, false
+	}
+	colors, err := imageColorSpace(v.Key("ColorSpace"))
+	if err != nil || colors.components == 4 && colors.palette == nil {
+		return nil, false
+	}
+	gray := colors.components == 1 && colors.palette == nil
+	original, err := readRawStream(file, v)
+	if err != nil {
+		return nil, false
+	}
+	img, err := decodeImageXObject(file, v)
+	if err != nil {
+		return nil, false
+	}
+
+	bounds := img.Bounds()
+	width := max(1, int(math.Round(float64(bounds.Dx())*factor)))
+	height := max(1, int(math.Round(float64(bounds.Dy())*factor)))
+	scaled := scaleImage(img, width, height)
+
+	replace := map[string]string{
+		"Width": strconv.Itoa(width), "Height": strconv.Itoa(height), "BitsPerComponent": "8",
+		"ColorSpace": "/DeviceRGB", "DecodeParms": "",
+	}
+	if gray {
+		replace["ColorSpace"] = "/DeviceGray"
+	}
+	var data []byte
+	if v.Key("Filter").Name() == "DCTDecode" {
+		var out bytes.Buffer
+		var encoded image.Image = scaled
+		if gray {
+			encoded = grayImage(scaled)
+		}
+		if err := jpeg.Encode(&out, encoded, &jpeg.Options{Quality: downsampleJPEGQuality}); err != nil {
+			return nil, false
+		}
+		data, replace["Filter"] = out.Bytes(), "/DCTDecode"
+	} else {
+		samples := make([]byte, 0, width*height*3)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				c := scaled.RGBAAt(x, y)
+				if gray {
+					samples = append(samples, color.GrayModel.Convert(c).(color.Gray).Y)
+				} else {
+					samples = append(samples, c.R, c.G, c.B)
+				}
+			}
+		}
+		if data, err = deflate(samples); err != nil {
+			return nil, false
+		}
+		replace["Filter"] = "/FlateDecode"
+	}
+	if len(data) >= len(original) {
+		return nil, false
+	}
+	replace["Length"] = strconv.Itoa(len(data))
+
+	return func(w *documentWriter, b *bytes.Buffer, v pdf.Value) error {
+		if err := w.dict(b, v, replace, 0); err != nil {
+			return err
+		}
+		w.compact.downsampled++
+		b.WriteString("\nstream\n")
+		b.Write(data)
+		b.WriteString("\nendstream")
+		return nil
+	}, true
+}
+
+// scaleImage resizes an image by averaging the source pixels each target pixel covers
+func scaleImage(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		top, bottom := y*bounds.Dy()/height, max((y+1)*bounds.Dy()/height, y*bounds.Dy()/height+1)
+		for x := 0; x < width; x++ {
+			left, right := x*bounds.Dx()/width, max((x+1)*bounds.Dx()/width, x*bounds.Dx()/width+1)
+			var r, g, b, count uint32
+			for sy := top; sy < bottom; sy++ {
+				for sx := left; sx < right; sx++ {
+					cr, cg, cb, _ := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, count = r+cr>>8, g+cg>>8, b+cb>>8, count+1
+				}
+			}
+			scaled.SetRGBA(x, y, color.RGBA{R: uint8(r / count), G: uint8(g / count), B: uint8(b / count), A: 0xFF})
+		}
+	}
+	return scaled
+}
+
+// grayImage converts an image to grayscale
+func grayImage(img *image.RGBA) *image.Gray {
+	gray := image.NewGray(img.Bounds())
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			gray.Set(x, y, img.At(x, y))
+		}
+	}
+	return gray
+}
+
+// compaction shrinks a document as the document writer copies it: identical image streams
+// are written once, unfiltered streams are compressed, and other objects are packed into
+// compressed object streams. Its methods do nothing on a nil compaction, as plain copies
+// have.
+type compaction struct {
+	written     map[string]int // Number of the copy of each image stream, by content
+	groups      map[int]*DuplicateImageGroup
+	images      int // Image streams in use, duplicates included
+	compressed  int // Streams written compressed that were stored without a filter
+	downsampled int // Images written downsampled; a duplicate's edit is never applied
+}
+
+// newCompaction creates a compaction with nothing written yet
+func newCompaction() *compaction {
+	return &compaction{written: map[string]int{}, groups: map[int]*DuplicateImageGroup{}}
+}
+
+// duplicate returns the number of the copy already written of an image stream identical to
+// v, in its samples and in every entry of its dictionary
+func (c *compaction) duplicate(w *documentWriter, v pdf.Value) (int, bool) {
+	if c == nil || v.Kind() != pdf.Stream || v.Key("Subtype").Name() != "Image" {
+		return 0, false
+	}
+	c.images++
+	key, ok := imageContentKey(w.file, v)
+	if !ok {
+		return 0, false
+	}
+	number, ok := c.written[key]
+	if ok {
+		group := c.groups[number]
+		group.Objects = append(group.Objects, int(extraction.RefOf(v).ID))
+	}
+	return number, ok
+}
+
+// original records the number of the copy of an image stream written for the first time
+func (c *compaction) original(w *documentWriter, v pdf.Value, number int) {
+	if c == nil || v.Kind() != pdf.Stream || v.Key("Subtype").Name() != "Image" {
+		return
+	}
+	key, ok := imageContentKey(w.file, v)
+	if !ok {
+		return
+	}
+	c.written[key] = number
+	c.groups[number] = &DuplicateImageGroup{
+		Objects: []int{int(extraction.RefOf(v).ID)},
+		Width:   int(v.Key("Width").Int64()),
+		Height:  int(v.Key("Height").Int64()),
+		Size:    int(v.Key("Length").Int64()),
+	}
+}
+
+// compress returns a stream's data Flate-compressed when it was stored without a filter
+// and compressing makes it smaller, updating its dictionary entries in replace. Metadata
+// streams stay readable as stored.
+func (c *compaction) compress(v pdf.Value, data []byte, replace map[string]string) ([]byte, error) {
+	if c == nil || !v.Key("Filter").IsNull() || v.Key("Type").Name() == "Metadata" || len(data) == 0 {
+		return data, nil
+	}
+	compressed, err := deflate(data)
+	if err != nil || len(compressed) >= len(data) {
+		return data, err
+	}
+	c.compressed++
+	replace["Filter"] = "/FlateDecode"
+	replace["DecodeParms"] = ""
+	replace["Length"] = strconv.Itoa(len(compressed))
+	return compressed, nil
+}
+
+// imageContentKey identifies an image stream by a digest of its stored bytes and its
+// dictionary, whose indirect values count by the object they refer to
+func imageContentKey(file io.ReaderAt, v pdf.Value) (string, bool) {
+	data, err := readRawStream(file, v)
+	if err != nil {
+		return "", false
+	}
+	digest := sha256.New()
+	digest.Write(data)
+	writeValueKey(digest, v, extraction.RefOf(v), 0)
+	return string(digest.Sum(nil)), true
+}
+
+// writeValueKey writes a description of a value that is equal for equal values
+func writeValueKey(out io.Writer, v pdf.Value, owner extraction.ObjectRef, depth int) {
+	if ref := extraction.RefOf(v); ref.ID != 0 && ref != owner || depth > maxObjectDepth {
+		fmt.Fprintf(out, "%v R ", ref)
+		return
+	}
+	switch v.Kind() {
+	case pdf.Array:
+		io.WriteString(out, "[")
+		for i := 0; i < v.Len(); i++ {
+			writeValueKey(out, v.Index(i), owner, depth+1)
+		}
+		io.WriteString(out, "]")
+	case pdf.Dict, pdf.Stream:
+		keys := v.Keys()
+		sort.Strings(keys)
+		io.WriteString(out, "<<")
+		for _, key := range keys {
+			if key != "Length" {
+				fmt.Fprintf(out, "/%s ", key)
+				writeValueKey(out, v.Key(key), owner, depth+1)
+			}
+		}
+		io.WriteString(out, ">>")
+	default:
+		fmt.Fprintf(out, "%d:%q ", v.Kind(), v.String())
+	}
+}
+
+// writeCompact writes the copy with every object that is not a stream packed into
+// compressed object streams, indexed by a compressed cross-reference stream
+func (w *documentWriter) writeCompact(trailer pdf.Value, root, info int) ([]byte, error) {
+	type location struct {
+		offset int // File offset of an object written directly
+		stream int // Object stream holding a packed object
+		index  int
+	}
+	var packed []int
+	for i, body := range w.bodies {
+		if !bytes.HasSuffix(body, []byte("endstream")) {
+			packed = append(packed, i+1)
+		}
+	}
+	// Object streams follow the copied objects, and the cross-reference stream comes last
+	xref := len(w.bodies) + (len(packed)+objectStreamCapacity-1)/objectStreamCapacity + 1
+	locations := make([]location, xref)
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	writeObject := func(number int, body []byte) {
+		locations[number-1] = location{offset: out.Len()}
+		fmt.Fprintf(&out, "%d 0 obj\n", number)
+		out.Write(body)
+		out.WriteString("\nendobj\n")
+	}
+	for i, body := range w.bodies {
+		if bytes.HasSuffix(body, []byte("endstream")) {
+			writeObject(i+1, body)
+		}
+	}
+
+	next := len(w.bodies) + 1
+	for start := 0; start < len(packed); start += objectStreamCapacity {
+		group := packed[start:min(start+objectStreamCapacity, len(packed))]
+		var index, objects bytes.Buffer
+		for i, number := range group {
+			fmt.Fprintf(&index, "%d %d ", number, objects.Len())
+			objects.Write(w.bodies[number-1])
+			objects.WriteByte('\n')
+			locations[number-1] = location{stream: next, index: i}
+		}
+		compressed, err := deflate(append(index.Bytes(), objects.Bytes()...))
+		if err != nil {
+			return nil, err
+		}
+		var body bytes.Buffer
+		fmt.Fprintf(&body, "<</Type /ObjStm /N %d /First %d /Filter /FlateDecode /Length %d>>\nstream\n",
+			len(group), index.Len(), len(compressed))
+		body.Write(compressed)
+		body.WriteString("\nendstream")
+		writeObject(next, body.Bytes())
+		next++
+	}
+
+	offset := out.Len()
+	locations[xref-1] = location{offset: offset}
+	var table bytes.Buffer
+	table.Write([]byte{0, 0, 0, 0, 0, 0xFF, 0xFF})
+	for _, loc := range locations {
+		entry := make([]byte, 7)
+		if loc.stream != 0 {
+			entry[0] = 2
+			binary.BigEndian.PutUint32(entry[1:5], uint32(loc.stream))
+			binary.BigEndian.PutUint16(entry[5:], uint16(loc.index))
+		} else {
+			entry[0] = 1
+			binary.BigEndian.PutUint32(entry[1:5], uint32(loc.offset))
+		}
+		table.Write(entry)
+	}
+	compressed, err := deflate(table.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&out, "%d 0 obj\n<</Type /XRef /Size %d /W [1 4 2] ", xref, xref+1)
+	if err := w.trailerEntries(&out, trailer, root, info); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&out, " /Filter /FlateDecode /Length %d>>\nstream\n", len(compressed))
+	out.Write(compressed)
+	fmt.Fprintf(&out, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", offset)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,185 @@
+package pdf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// bloatedPDF builds a one-page document that stores one 200x100 gradient image twice, draws
+// both copies at 360 DPI, keeps an object nothing refers to, and stores every stream
+// uncompressed. extraCatalog adds entries to the catalog.
+func bloatedPDF(extraCatalog string) string {
+	var pixels strings.Builder
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			pixels.WriteByte(byte(x))
+		}
+	}
+	image := fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width 200 /Height 100 /ColorSpace /DeviceGray "+
+		"/BitsPerComponent 8 /Length %d >>\nstream\n%s\nendstream", pixels.Len(), pixels.String())
+	content := "BT /F1 12 Tf 72 720 Td (Hello optimize) Tj ET\n" +
+		"q 40 0 0 20 72 600 cm /A Do Q\nq 40 0 0 20 172 600 cm /B Do Q\n" +
+		strings.Repeat("% padding that compresses well\n", 20)
+
+	return buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R " + extraCatalog + ">>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R " +
+			"/Resources << /Font << /F1 7 0 R >> /XObject << /A 5 0 R /B 6 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		image,
+		image,
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Orphan true >>",
+	})
+}
+
+// optimizedDocument decodes an optimized document and opens it
+func optimizedDocument(t *testing.T, result *PDFOptimizeResult) (*os.File, *pdf.Reader) {
+	t.Helper()
+
+	data, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil {
+		t.Fatalf("optimized data is not base64: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "optimized.pdf")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		t.Fatalf("optimized document does not open: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f, r
+}
+
+func TestOptimizer_Report(t *testing.T) {
+	path := createTempFile(t, "bloated.pdf", bloatedPDF(""))
+
+	result, err := NewOptimizer(10*1024*1024).Report(context.Background(), PDFOptimizeReportRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Report() unexpected error = %v", err)
+	}
+	if result.Version != "1.4" || result.Linearized || result.Objects != 8 || result.ObjectStreams != 0 {
+		t.Errorf("Report() = %+v, want 8 objects of an unlinearized PDF 1.4 file", result)
+	}
+	if result.UnusedObjects != 1 || result.Images != 2 || result.HighResolutionImages != 2 {
+		t.Errorf("Report() found %d unused objects, %d images, %d high resolution; want 1, 2, 2",
+			result.UnusedObjects, result.Images, result.HighResolutionImages)
+	}
+	if len(result.DuplicateImages) != 1 || fmt.Sprint(result.DuplicateImages[0].Objects) != "[5 6]" ||
+		result.DuplicateImageBytes != 20000 {
+		t.Errorf("Report() duplicates = %+v (%d bytes), want objects 5 and 6", result.DuplicateImages,
+			result.DuplicateImageBytes)
+	}
+	if result.UncompressedStreams != 2 {
+		t.Errorf("Report() found %d uncompressed streams, want the content and the image", result.UncompressedStreams)
+	}
+	if result.EstimatedSize >= result.FileSize/2 || result.SavingsPercent <= 50 {
+		t.Errorf("Report() estimates %d of %d bytes (%.1f%%), want under half", result.EstimatedSize,
+			result.FileSize, result.SavingsPercent)
+	}
+	if len(result.Recommendations) != 5 {
+		t.Errorf("Report() recommendations = %q, want five", result.Recommendations)
+	}
+
+	// A linearization dictionary whose length no longer matches the file is stale
+	path = createTempFile(t, "linearized.pdf", bloatedPDF("/Linearized 1 /L 1024 "))
+	result, err = NewOptimizer(10*1024*1024).Report(context.Background(), PDFOptimizeReportRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Report() unexpected error = %v", err)
+	}
+	if !result.Linearized || !result.LinearizationStale {
+		t.Errorf("Report() linearized = %t, stale = %t, want both", result.Linearized, result.LinearizationStale)
+	}
+}
+
+func TestOptimizer_Optimize(t *testing.T) {
+	path := createTempFile(t, "bloated.pdf", bloatedPDF(""))
+	optimizer := NewOptimizer(10 * 1024 * 1024)
+
+	result, err := optimizer.Optimize(context.Background(), PDFOptimizeRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Optimize() unexpected error = %v", err)
+	}
+	if result.DuplicateImagesMerged != 1 || result.StreamsCompressed != 2 || result.ImagesDownsampled != 0 {
+		t.Errorf("Optimize() = %+v, want one merged image and two compressed streams", result)
+	}
+	if result.Savings <= 0 || int64(result.Size) != result.OriginalSize-result.Savings {
+		t.Errorf("Optimize() wrote %d of %d bytes, saving %d", result.Size, result.OriginalSize, result.Savings)
+	}
+
+	_, r := optimizedDocument(t, result)
+	page := r.Page(1)
+	text, err := extraction.PlainText(page)
+	if err != nil || !strings.Contains(text, "Hello optimize") {
+		t.Errorf("optimized page text = %q (%v), want the original text", text, err)
+	}
+	xObjects := page.V.Key("Resources").Key("XObject")
+	if a, b := extraction.RefOf(xObjects.Key("A")), extraction.RefOf(xObjects.Key("B")); a != b || a.ID == 0 {
+		t.Errorf("optimized images are objects %v and %v, want one shared object", a, b)
+	}
+
+	// Downsampling to 90 DPI scales the 360 DPI image to a quarter
+	result, err = optimizer.Optimize(context.Background(), PDFOptimizeRequest{Path: path, MaxImageDPI: 90})
+	if err != nil {
+		t.Fatalf("Optimize(max_image_dpi) unexpected error = %v", err)
+	}
+	if result.ImagesDownsampled != 1 {
+		t.Errorf("Optimize(max_image_dpi) downsampled %d images, want 1", result.ImagesDownsampled)
+	}
+	f, r := optimizedDocument(t, result)
+	img := r.Page(1).V.Key("Resources").Key("XObject").Key("A")
+	if img.Key("Width").Int64() != 50 || img.Key("Height").Int64() != 25 {
+		t.Errorf("downsampled image is %dx%d, want 50x25", img.Key("Width").Int64(), img.Key("Height").Int64())
+	}
+	decoded, err := decodeImageXObject(f, img)
+	if err != nil {
+		t.Fatalf("downsampled image does not decode: %v", err)
+	}
+	if gray := decoded.At(49, 0); gray == decoded.At(0, 0) {
+		t.Errorf("downsampled image lost its gradient")
+	}
+
+	// Saved copies are named after the original
+	dir := t.TempDir()
+	result, err = optimizer.Optimize(context.Background(), PDFOptimizeRequest{Path: path, OutputDir: dir})
+	if err != nil {
+		t.Fatalf("Optimize(output_dir) unexpected error = %v", err)
+	}
+	if result.OutputPath != filepath.Join(dir, "bloated-optimized.pdf") || result.Data != "" {
+		t.Errorf("Optimize(output_dir) = %+v, want the document saved", result)
+	}
+}
+
+func TestOptimizer_Errors(t *testing.T) {
+	path := createTempFile(t, "plain.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Plain) Tj ET"))
+	optimizer := NewOptimizer(10 * 1024 * 1024)
+
+	tests := []struct {
+		name     string
+		req      PDFOptimizeRequest
+		errorMsg string
+	}{
+		{name: "empty path", req: PDFOptimizeRequest{}, errorMsg: "path cannot be empty"},
+		{name: "missing file", req: PDFOptimizeRequest{Path: path + ".gone"}, errorMsg: "does not exist"},
+		{name: "resolution too low", req: PDFOptimizeRequest{Path: path, MaxImageDPI: 10}, errorMsg: "at least 36"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := optimizer.Optimize(context.Background(), tt.req)
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Optimize() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// Outline traversal limits guarding against malformed, cyclic documents
+const (
+	maxOutlineDepth = 32
+	maxOutlineItems = 10000
+)
+
+// Outline handles extraction of a PDF's native bookmark tree
+type Outline struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewOutline creates a new outline extractor with the specified constraints
+func NewOutline(maxFileSize int64) *Outline {
+	return &Outline{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// outlineWalker carries the state shared while walking a single outline tree
+type outlineWalker struct {
+	reader   *pdf.Reader
+	pages    *extraction.PageNumbering
+	visited  map[extraction.ObjectRef]bool
+	items    int
+	maxDepth int
+}
+
+// GetOutline returns the document outline (bookmarks) as a nested structure
+func (o *Outline) GetOutline(req PDFGetOutlineRequest) (result *PDFGetOutlineResult, err error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: o.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			result = nil
+			err = fmt.Errorf("failed to read outline: %v", rec)
+		}
+	}()
+
+	return readOutline(req.Path, r, extraction.NewPageNumbering(r)), nil
+}
+
+// readOutline reads the bookmark tree and the appendices of an open document
+func readOutline(path string, r *pdf.Reader, numbering *extraction.PageNumbering) *PDFGetOutlineResult {
+	result := &PDFGetOutlineResult{
+		Path:  path,
+		Items: []OutlineItem{},
+	}
+
+	outlines := r.Trailer().Key("Root").Key("Outlines")
+	if outlines.Kind() == pdf.Dict {
+		walker := &outlineWalker{
+			reader:  r,
+			pages:   numbering,
+			visited: make(map[extraction.ObjectRef]bool),
+		}
+		result.Items = walker.walk(outlines.Key("First"), 1)
+		result.HasOutline = len(result.Items) > 0
+		result.TotalItems = walker.items
+		result.MaxDepth = walker.maxDepth
+	}
+
+	result.Appendices = detectAppendices(numbering, result.Items)
+
+	return result
+}
+
+// walk converts a sibling chain of outline items, starting at first, into OutlineItems
+func (w *outlineWalker) walk(first pdf.Value, depth int) []OutlineItem {
+	var items []OutlineItem
+	if depth > maxOutlineDepth {
+		return items
+	}
+
+	for node := first; node.Kind() == pdf.Dict; node = node.Key("Next") {
+		ref := extraction.RefOf(node)
+		if w.visited[ref] || w.items >= maxOutlineItems {
+			break
+		}
+		w.visited[ref] = true
+		w.items++
+		if depth > w.maxDepth {
+			w.maxDepth = depth
+		}
+
+		item := OutlineItem{
+			Title: node.Key("Title").Text(),
+			Level: depth,
+			Open:  node.Key("Count").Int64() > 0,
+		}
+		w.resolveTarget(node, &item)
+
+		if children := node.Key("First"); children.Kind() == pdf.Dict {
+			item.Children = w.walk(children, depth+1)
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// resolveTarget fills in the destination or URI an outline item points to
+func (w *outlineWalker) resolveTarget(node pdf.Value, item *OutlineItem) {
+	dest := node.Key("Dest")
+	if dest.IsNull() {
+		action := node.Key("A")
+		switch action.Key("S").Name() {
+		case "GoTo":
+			dest = action.Key("D")
+		case "URI":
+			item.URI = action.Key("URI").RawString()
+			return
+		default:
+			return
+		}
+	}
+
+	target := extraction.ParseDestination(extraction.ResolveDestination(w.reader, dest), w.pages)
+	item.Page = target.Page
+	item.PageLabel = w.pages.Label(target.Page)
+	item.DestinationType = target.Type
+	item.Left = target.Left
+	item.Top = target.Top
+	item.Zoom = target.Zoom
+}
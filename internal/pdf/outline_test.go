@@ -0,0 +1,123 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+)
+
+// outlinePDFContent builds a two-page document with nested bookmarks using explicit,
+// action-based, named, and URI destinations
+func outlinePDFContent() string {
+	return buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 5 0 R /Dests 10 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Outlines /First 6 0 R /Last 9 0 R /Count 4 >>",
+		"<< /Title (Introduction) /Parent 5 0 R /Next 8 0 R /First 7 0 R /Last 7 0 R /Count 1 " +
+			"/Dest [3 0 R /XYZ 72 720 1.5] >>",
+		"<< /Title (Background) /Parent 6 0 R /A << /S /GoTo /D [4 0 R /FitH 500] >> >>",
+		"<< /Title (Appendix) /Parent 5 0 R /Prev 6 0 R /Next 9 0 R /Dest /appendix >>",
+		"<< /Title (Website) /Parent 5 0 R /Prev 8 0 R /A << /S /URI /URI (https://example.com) >> >>",
+		"<< /appendix [4 0 R /Fit] >>",
+	})
+}
+
+func TestOutline_GetOutline(t *testing.T) {
+	outline := NewOutline(100 * 1024 * 1024)
+
+	tests := []struct {
+		name      string
+		path      string
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name:      "empty path",
+			path:      "",
+			wantError: true,
+			errorMsg:  "path cannot be empty",
+		},
+		{
+			name:      "non-existent file",
+			path:      "/non/existent/file.pdf",
+			wantError: true,
+			errorMsg:  "file does not exist",
+		},
+		{
+			name:      "directory instead of file",
+			path:      createTempDir(t),
+			wantError: true,
+			errorMsg:  "path is a directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := outline.GetOutline(PDFGetOutlineRequest{Path: tt.path})
+			if err == nil {
+				t.Fatal("GetOutline() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("GetOutline() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestOutline_GetOutlineNested(t *testing.T) {
+	outline := NewOutline(100 * 1024 * 1024)
+	path := createTempFile(t, "outline.pdf", outlinePDFContent())
+
+	result, err := outline.GetOutline(PDFGetOutlineRequest{Path: path})
+	if err != nil {
+		t.Fatalf("GetOutline() unexpected error = %v", err)
+	}
+
+	if !result.HasOutline || result.TotalItems != 4 || result.MaxDepth != 2 {
+		t.Fatalf("GetOutline() = has %v, items %d, depth %d; want true, 4, 2",
+			result.HasOutline, result.TotalItems, result.MaxDepth)
+	}
+	if len(result.Items) != 3 {
+		t.Fatalf("GetOutline() top-level items = %d, want 3", len(result.Items))
+	}
+
+	intro := result.Items[0]
+	if intro.Title != "Introduction" || intro.Page != 1 || intro.DestinationType != "XYZ" {
+		t.Errorf("Introduction = %+v, want page 1 XYZ", intro)
+	}
+	if intro.Zoom != 1.5 || intro.Top != 720 || !intro.Open {
+		t.Errorf("Introduction view = zoom %v top %v open %v, want 1.5 720 true", intro.Zoom, intro.Top, intro.Open)
+	}
+
+	if len(intro.Children) != 1 {
+		t.Fatalf("Introduction children = %d, want 1", len(intro.Children))
+	}
+	background := intro.Children[0]
+	if background.Title != "Background" || background.Page != 2 || background.Level != 2 {
+		t.Errorf("Background = %+v, want page 2 level 2", background)
+	}
+
+	appendix := result.Items[1]
+	if appendix.Page != 2 || appendix.DestinationType != "Fit" {
+		t.Errorf("Appendix = %+v, want named destination resolved to page 2", appendix)
+	}
+
+	website := result.Items[2]
+	if website.URI != "https://example.com" || website.Page != 0 {
+		t.Errorf("Website = %+v, want URI target", website)
+	}
+}
+
+func TestOutline_GetOutlineMissing(t *testing.T) {
+	outline := NewOutline(100 * 1024 * 1024)
+	path := createTempFile(t, "plain.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Hello) Tj ET"))
+
+	result, err := outline.GetOutline(PDFGetOutlineRequest{Path: path})
+	if err != nil {
+		t.Fatalf("GetOutline() unexpected error = %v", err)
+	}
+	if result.HasOutline || len(result.Items) != 0 {
+		t.Errorf("GetOutline() = %+v, want empty outline", result)
+	}
+}
@@ -0,0 +1,378 @@
+package pdf
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// Page window constants
+const (
+	// boundaryOvershootPages is how many pages a first/last window may grow to avoid
+	// cutting a section in half
+	boundaryOvershootPages = 2
+	// sectionOutlineDepth is the deepest outline level treated as a section start
+	sectionOutlineDepth = 2
+	// headingFontRatio is how much larger than the page's body text a heading line must be
+	headingFontRatio = 1.2
+	// headingMaxWords is the longest line still considered a heading
+	headingMaxWords = 12
+)
+
+// headingPattern matches lines that open a new section regardless of their font size
+var headingPattern = regexp.MustCompile(
+	`^(?i:chapter|section|part|appendix|annex|schedule|exhibit|attachment|article)\b|^\d+(\.\d+)*\.?\s+\p{Lu}`)
+
+// SelectPages resolves first/last page counts into the pages to read. The first window is
+// extended forward and the last window backward by up to boundaryOvershootPages when that
+// reaches a section boundary, so the opening section is read to its end and the closing
+// section from its start. When both counts are given the windows are combined.
+func SelectPages(r *pdf.Reader, firstPages, lastPages int) (selection *PageSelection, err error) {
+	if firstPages < 0 || lastPages < 0 {
+		return nil, pdferrors.New(pdferrors.InvalidArgument, "first_pages and last_pages cannot be negative")
+	}
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			selection = nil
+			err = pdferrors.New(pdferrors.Corrupt, "failed to select pages: %v", rec)
+		}
+	}()
+
+	numbering := extraction.NewPageNumbering(r)
+	total := numbering.Count()
+	selection = &PageSelection{
+		TotalPages: total,
+		FirstPages: firstPages,
+		LastPages:  lastPages,
+		Pages:      []int{},
+	}
+
+	boundaries := &sectionBoundaries{reader: r, pages: numbering, headings: make(map[int]bool)}
+	selected := make(map[int]bool)
+	requested := make(map[int]bool)
+
+	if firstPages > 0 {
+		end := min(firstPages, total)
+		for page := 1; page <= end; page++ {
+			requested[page] = true
+		}
+		end = boundaries.extendForward(end, total)
+		for page := 1; page <= end; page++ {
+			selected[page] = true
+		}
+	}
+
+	if lastPages > 0 {
+		start := max(total-lastPages+1, 1)
+		for page := start; page <= total; page++ {
+			requested[page] = true
+		}
+		start = boundaries.extendBackward(start)
+		for page := start; page <= total; page++ {
+			selected[page] = true
+		}
+	}
+
+	for page := range selected {
+		selection.Pages = append(selection.Pages, page)
+		if !requested[page] {
+			selection.Extended = append(selection.Extended, page)
+		}
+	}
+	sort.Ints(selection.Pages)
+	sort.Ints(selection.Extended)
+	selection.addLabels(numbering)
+
+	return selection, nil
+}
+
+// addLabels records the labels of the selected pages when the document labels its pages
+func (s *PageSelection) addLabels(numbering *extraction.PageNumbering) {
+	for _, page := range s.Pages {
+		if label := numbering.Label(page); label != "" {
+			if s.Labels == nil {
+				s.Labels = make(map[int]string, len(s.Pages))
+			}
+			s.Labels[page] = label
+		}
+	}
+}
+
+// ParsePageRanges parses a page list such as "1-5,9" into sorted, distinct page numbers.
+// Pages are counted from 1 and ranges include both ends.
+func ParsePageRanges(spec string) ([]int, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, pdferrors.New(pdferrors.InvalidArgument, "page list cannot be empty")
+	}
+
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, pdferrors.New(pdferrors.InvalidArgument, "invalid page range: %q", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+				return nil, pdferrors.New(pdferrors.InvalidArgument, "invalid page range: %q", part)
+			}
+		}
+		if first < 1 {
+			return nil, pdferrors.New(pdferrors.InvalidArgument, "invalid page range: %q (pages start at 1)", part)
+		}
+		if last < first {
+			return nil, pdferrors.New(pdferrors.InvalidArgument, "invalid page range: %q (end before start)", part)
+		}
+		for page := first; page <= last; page++ {
+			seen[page] = true
+		}
+	}
+
+	pages := make([]int, 0, len(seen))
+	for page := range seen {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+	return pages, nil
+}
+
+// selectListedPages returns the selection of explicitly listed pages, checking that the
+// document has them
+func selectListedPages(pages []int, total int) (*PageSelection, error) {
+	selected := append([]int{}, pages...)
+	sort.Ints(selected)
+	selected = slices.Compact(selected)
+	for _, page := range selected {
+		if page < 1 || page > total {
+			return nil, pageOutOfRange(page, total)
+		}
+	}
+	return &PageSelection{TotalPages: total, Pages: selected}, nil
+}
+
+// pageOutOfRange is the error for a page the document does not have
+func pageOutOfRange(page, total int) error {
+	return pdferrors.New(pdferrors.InvalidArgument, "page %d out of range (document has %d pages)", page, total)
+}
+
+// validateRegion checks that a page region has an area
+func validateRegion(region *Rectangle) error {
+	if region != nil && (region.Width <= 0 || region.Height <= 0) {
+		return pdferrors.New(pdferrors.InvalidArgument, "region width and height must be positive")
+	}
+	return nil
+}
+
+// regionBounds converts a page region into the engine's bounding box
+func regionBounds(region *Rectangle) *extraction.BoundingBox {
+	if region == nil {
+		return nil
+	}
+	box := newBox(region.X, region.Y, region.Width, region.Height)
+	return &box
+}
+
+// sectionBoundaries finds section starts lazily, so only pages near a window edge are analyzed
+type sectionBoundaries struct {
+	reader   *pdf.Reader
+	pages    *extraction.PageNumbering
+	outline  map[int]bool
+	headings map[int]bool // Heading analysis results by page
+}
+
+// extendForward returns the last page of a first-pages window ending at end, moved forward
+// to the end of the current section when the next section starts close enough
+func (b *sectionBoundaries) extendForward(end, total int) int {
+	if end >= total || b.isSectionStart(end+1) {
+		return end
+	}
+	for next := end + 2; next <= end+boundaryOvershootPages+1; next++ {
+		if next > total {
+			return total
+		}
+		if b.isSectionStart(next) {
+			return next - 1
+		}
+	}
+	return end
+}
+
+// extendBackward returns the first page of a last-pages window starting at start, moved back
+// to the start of the current section when it begins close enough
+func (b *sectionBoundaries) extendBackward(start int) int {
+	if start <= 1 || b.isSectionStart(start) {
+		return start
+	}
+	for prev := start - 1; prev >= start-boundaryOvershootPages && prev >= 1; prev-- {
+		if prev == 1 || b.isSectionStart(prev) {
+			return prev
+		}
+	}
+	return start
+}
+
+// isSectionStart reports whether a section begins on the page, according to the outline or
+// to the page's first line looking like a heading
+func (b *sectionBoundaries) isSectionStart(pageNum int) bool {
+	if b.outline == nil {
+		b.outline = outlineSectionStarts(b.reader, b.pages)
+	}
+	if b.outline[pageNum] {
+		return true
+	}
+
+	heading, ok := b.headings[pageNum]
+	if !ok {
+		heading = pageStartsWithHeading(b.pages.Page(pageNum))
+		b.headings[pageNum] = heading
+	}
+	return heading
+}
+
+// outlineSectionStarts returns the pages that top-level outline items point to
+func outlineSectionStarts(r *pdf.Reader, numbering *extraction.PageNumbering) map[int]bool {
+	starts := make(map[int]bool)
+
+	outlines := r.Trailer().Key("Root").Key("Outlines")
+	if outlines.Kind() != pdf.Dict {
+		return starts
+	}
+
+	walker := &outlineWalker{
+		reader:  r,
+		pages:   numbering,
+		visited: make(map[extraction.ObjectRef]bool),
+	}
+
+	var collect func(items []OutlineItem)
+	collect = func(items []OutlineItem) {
+		for _, item := range items {
+			if item.Level > sectionOutlineDepth {
+				continue
+			}
+			if item.Page > 0 {
+				starts[item.Page] = true
+			}
+			collect(item.Children)
+		}
+	}
+	collect(walker.walk(outlines.Key("First"), 1))
+
+	return starts
+}
+
+// pageStartsWithHeading reports whether the topmost line of a page is set noticeably larger
+// than the page's body text or reads like a section heading
+func pageStartsWithHeading(page pdf.Page) bool {
+	lines, bodySize := pageTopLines(page, 1)
+	if len(lines) == 0 || lines[0].Words > headingMaxWords {
+		return false
+	}
+	return lines[0].FontSize >= bodySize*headingFontRatio || headingPattern.MatchString(lines[0].Text)
+}
+
+// pageLine is a line of text on a page
+type pageLine struct {
+	Text     string
+	Words    int
+	FontSize float64 // Largest font size on the line
+	Top      float64 // Highest edge of the line's words
+	Bottom   float64 // Lowest edge of the line's words
+	Left     float64 // Left edge of the first word
+	Right    float64 // Right edge of the last word
+}
+
+// pageTopLines returns up to count lines from the top of a page, along with the median font
+// size of the page's words as a measure of its body text size
+func pageTopLines(page pdf.Page, count int) ([]pageLine, float64) {
+	rows, bodySize := pageRows(page, count)
+	lines := make([]pageLine, len(rows))
+	for i, row := range rows {
+		lines[i] = newPageLine(row)
+	}
+	return lines, bodySize
+}
+
+// pageRows groups up to count rows of words from the top of a page, each ordered left to
+// right, along with the median font size of the page's words. A row runs across the whole
+// page, so on pages set in columns it holds a line of each column.
+func pageRows(page pdf.Page, count int) ([][]extraction.WordElement, float64) {
+	if page.V.IsNull() {
+		return nil, 0
+	}
+
+	words, err := extraction.PageWords(page)
+	if err != nil {
+		return nil, 0
+	}
+	return groupRows(words, count)
+}
+
+// groupRows groups up to count rows of words from the top down, each ordered left to right,
+// along with the median font size of the words
+func groupRows(words []extraction.WordElement, count int) ([][]extraction.WordElement, float64) {
+	if len(words) == 0 {
+		return nil, 0
+	}
+
+	sizes := make([]float64, len(words))
+	for i, word := range words {
+		sizes[i] = word.Properties.FontSize
+	}
+	sort.Float64s(sizes)
+	bodySize := sizes[len(sizes)/2]
+
+	// Group words into rows from the top down, then order each row left to right
+	sorted := append([]extraction.WordElement{}, words...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].BoundingBox.UpperRight.Y > sorted[b].BoundingBox.UpperRight.Y
+	})
+
+	var rows [][]extraction.WordElement
+	rowTop := 0.0
+	for _, word := range sorted {
+		box := word.BoundingBox
+		if len(rows) == 0 || rowTop-box.UpperRight.Y > box.Height/2 {
+			if len(rows) == count {
+				break
+			}
+			rows = append(rows, nil)
+			rowTop = box.UpperRight.Y
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], word)
+	}
+	for _, row := range rows {
+		sort.SliceStable(row, func(a, b int) bool {
+			return row[a].BoundingBox.LowerLeft.X < row[b].BoundingBox.LowerLeft.X
+		})
+	}
+
+	return rows, bodySize
+}
+
+// newPageLine joins words ordered left to right into a line
+func newPageLine(words []extraction.WordElement) pageLine {
+	texts := make([]string, len(words))
+	line := pageLine{Words: len(words), Bottom: math.Inf(1), Left: math.Inf(1)}
+	for i, word := range words {
+		texts[i] = word.Text
+		line.FontSize = math.Max(line.FontSize, word.Properties.FontSize)
+		line.Top = math.Max(line.Top, word.BoundingBox.UpperRight.Y)
+		line.Bottom = math.Min(line.Bottom, word.BoundingBox.LowerLeft.Y)
+		line.Left = math.Min(line.Left, word.BoundingBox.LowerLeft.X)
+		line.Right = math.Max(line.Right, word.BoundingBox.UpperRight.X)
+	}
+	line.Text = strings.Join(texts, " ")
+	return line
+}
@@ -0,0 +1,403 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// sectionedPages returns page contents where the listed pages open with a section heading
+func sectionedPages(count int, headings map[int]string) []string {
+	pages := make([]string, count)
+	for i := range pages {
+		body := fmt.Sprintf("(Body text of page %d continues the discussion) Tj", i+1)
+		if heading, ok := headings[i+1]; ok {
+			pages[i] = fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj 0 -20 Td %s ET", heading, body)
+		} else {
+			pages[i] = fmt.Sprintf("BT /F1 12 Tf 72 720 Td %s ET", body)
+		}
+	}
+	return pages
+}
+
+// outlinedPDFContent builds a document of plain pages whose outline points at sectionPages
+func outlinedPDFContent(count int, sectionPages ...int) string {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 3 0 R >>",
+		"", // Pages tree
+		"", // Outline root
+	}
+
+	kids := make([]string, count)
+	pageIDs := make([]int, count)
+	for i := 0; i < count; i++ {
+		pageIDs[i] = len(objects) + 1
+		kids[i] = fmt.Sprintf("%d 0 R", pageIDs[i])
+		content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (Plain page %d) Tj ET", i+1)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R >>", pageIDs[i]+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), count)
+
+	firstItem := len(objects) + 1
+	for i, page := range sectionPages {
+		item := fmt.Sprintf("<< /Title (Section %d) /Parent 3 0 R /Dest [%d 0 R /Fit]", i+1, pageIDs[page-1])
+		if i+1 < len(sectionPages) {
+			item += fmt.Sprintf(" /Next %d 0 R", firstItem+i+1)
+		}
+		objects = append(objects, item+" >>")
+	}
+	objects[2] = fmt.Sprintf("<< /Type /Outlines /First %d 0 R /Last %d 0 R /Count %d >>",
+		firstItem, firstItem+len(sectionPages)-1, len(sectionPages))
+
+	return buildRawPDF(objects)
+}
+
+func openTestPDF(t *testing.T, name, content string) *pdf.Reader {
+	t.Helper()
+	f, r, err := pdf.Open(createTempFile(t, name, content))
+	if err != nil {
+		t.Fatalf("failed to open test PDF: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return r
+}
+
+func TestSelectPages(t *testing.T) {
+	headed := buildTestPDF(sectionedPages(10, map[int]string{
+		1: "1 Introduction",
+		5: "Section 2 Payment",
+		8: "Appendix A",
+	})...)
+
+	tests := []struct {
+		name         string
+		content      string
+		first, last  int
+		wantPages    []int
+		wantExtended []int
+	}{
+		{
+			name:         "first pages finish the section",
+			content:      headed,
+			first:        3,
+			wantPages:    []int{1, 2, 3, 4},
+			wantExtended: []int{4},
+		},
+		{
+			name:      "first pages end at a section boundary",
+			content:   headed,
+			first:     4,
+			wantPages: []int{1, 2, 3, 4},
+		},
+		{
+			name:      "next section too far away",
+			content:   headed,
+			first:     1,
+			wantPages: []int{1},
+		},
+		{
+			name:         "last pages start at the section heading",
+			content:      headed,
+			last:         2,
+			wantPages:    []int{8, 9, 10},
+			wantExtended: []int{8},
+		},
+		{
+			name:         "opening and closing windows combined",
+			content:      headed,
+			first:        3,
+			last:         3,
+			wantPages:    []int{1, 2, 3, 4, 8, 9, 10},
+			wantExtended: []int{4}, // Page 8 is within the requested last three pages
+		},
+		{
+			name:      "window larger than the document",
+			content:   headed,
+			first:     20,
+			wantPages: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			name:         "outline section starts",
+			content:      outlinedPDFContent(8, 1, 4),
+			first:        2,
+			wantPages:    []int{1, 2, 3},
+			wantExtended: []int{3},
+		},
+		{
+			name: "larger first line is a heading",
+			content: buildTestPDF(
+				"BT /F1 12 Tf 72 720 Td (Opening remarks about the agreement) Tj ET",
+				"BT /F1 12 Tf 72 720 Td (More remarks about the agreement) Tj ET",
+				"BT /F1 12 Tf 72 720 Td (Still more remarks) Tj ET",
+				"BT /F1 18 Tf 72 720 Td (Signatures) Tj /F1 12 Tf 0 -30 Td (Signed by the parties below) Tj "+
+					"0 -20 Td (Witnessed by the notary) Tj ET",
+				"BT /F1 12 Tf 72 720 Td (Signature lines) Tj ET",
+			),
+			last:         1,
+			wantPages:    []int{4, 5},
+			wantExtended: []int{4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := openTestPDF(t, "sections.pdf", tt.content)
+			selection, err := SelectPages(r, tt.first, tt.last)
+			if err != nil {
+				t.Fatalf("SelectPages() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(selection.Pages, tt.wantPages) {
+				t.Errorf("SelectPages() pages = %v, want %v", selection.Pages, tt.wantPages)
+			}
+			if !reflect.DeepEqual(selection.Extended, tt.wantExtended) {
+				t.Errorf("SelectPages() extended = %v, want %v", selection.Extended, tt.wantExtended)
+			}
+		})
+	}
+
+	if _, err := SelectPages(openTestPDF(t, "negative.pdf", headed), -1, 0); err == nil {
+		t.Error("SelectPages() expected error for negative first pages")
+	}
+}
+
+func TestReader_ReadFilePageWindow(t *testing.T) {
+	reader := NewReader(100 * 1024 * 1024)
+	path := createTempFile(t, "window.pdf", buildTestPDF(sectionedPages(10, map[int]string{5: "Section 2 Terms"})...))
+
+	result, err := reader.ReadFile(PDFReadFileRequest{Path: path, FirstPages: 3, LastPages: 1})
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v", err)
+	}
+	if result.PageSelection == nil || !reflect.DeepEqual(result.PageSelection.Pages, []int{1, 2, 3, 4, 10}) {
+		t.Fatalf("ReadFile() selection = %+v, want pages 1-4 and 10", result.PageSelection)
+	}
+	if !strings.Contains(result.Content, "page 4") || strings.Contains(result.Content, "page 5") {
+		t.Errorf("ReadFile() content should include page 4 and exclude page 5:\n%s", result.Content)
+	}
+	if !strings.Contains(result.Content, "--- Pages 5-9 omitted ---") {
+		t.Errorf("ReadFile() content should mark the omitted pages:\n%s", result.Content)
+	}
+}
+
+func TestExtractionService_ExtractStructuredPageWindow(t *testing.T) {
+	service := NewExtractionService(100 * 1024 * 1024)
+	path := createTempFile(t, "window.pdf", buildTestPDF(sectionedPages(6, nil)...))
+
+	result, err := service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Config: ExtractConfig{ExtractText: true, LastPages: 2},
+	})
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	if !reflect.DeepEqual(result.ProcessedPages, []int{5, 6}) || result.PageSelection == nil {
+		t.Errorf("ExtractStructured() processed %v with selection %+v, want pages 5 and 6",
+			result.ProcessedPages, result.PageSelection)
+	}
+
+	_, err = service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Config: ExtractConfig{Pages: []int{1}, FirstPages: 2},
+	})
+	if err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("ExtractStructured() error = %v, want pages/first_pages conflict", err)
+	}
+}
+
+func TestParsePageRanges(t *testing.T) {
+	tests := []struct {
+		spec     string
+		want     []int
+		errorMsg string
+	}{
+		{spec: "3", want: []int{3}},
+		{spec: "1-5,9", want: []int{1, 2, 3, 4, 5, 9}},
+		{spec: " 9, 2 - 3 ,2", want: []int{2, 3, 9}},
+		{spec: "", errorMsg: "cannot be empty"},
+		{spec: "1,,2", errorMsg: "invalid page range"},
+		{spec: "0-2", errorMsg: "pages start at 1"},
+		{spec: "5-3", errorMsg: "end before start"},
+		{spec: "1-x", errorMsg: "invalid page range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParsePageRanges(tt.spec)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("ParsePageRanges() error = %v, want it to contain %q", err, tt.errorMsg)
+				}
+				if code := pdferrors.CodeOf(err); code != pdferrors.InvalidArgument {
+					t.Errorf("ParsePageRanges() error code = %s, want %s", code, pdferrors.InvalidArgument)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePageRanges() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePageRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageErrorCodes(t *testing.T) {
+	path := createTempFile(t, "two.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (One) Tj ET", "BT /F1 12 Tf 72 720 Td (Two) Tj ET"))
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, listedErr := selectListedPages([]int{1, 9}, 2)
+	_, textErr := pageText(extraction.NewPageNumbering(r), 9)
+	_, findErr := NewTextFinder(1024 * 1024).FindText(PDFFindTextRequest{Path: path, Query: "One", Pages: []int{3}})
+	_, readErr := NewReader(1024 * 1024).ReadFile(PDFReadFileRequest{Path: path, Pages: []int{2, 4}})
+	_, windowErr := SelectPages(r, -1, 0)
+
+	// Pages a document lacks are bad arguments, whatever their messages say, not missing files
+	tests := map[string]error{
+		"listed page":      listedErr,
+		"page text":        textErr,
+		"find text page":   findErr,
+		"read file pages":  readErr,
+		"negative windows": windowErr,
+	}
+	for name, err := range tests {
+		if code := pdferrors.CodeOf(err); code != pdferrors.InvalidArgument {
+			t.Errorf("%s: error %v has code %s, want %s", name, err, code, pdferrors.InvalidArgument)
+		}
+	}
+}
+
+func TestReader_ReadFilePagesAndRegion(t *testing.T) {
+	reader := NewReader(100 * 1024 * 1024)
+	path := createTempFile(t, "pages.pdf", buildTestPDF(sectionedPages(6, nil)...))
+
+	result, err := reader.ReadFile(PDFReadFileRequest{Path: path, Pages: []int{4, 2}})
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v", err)
+	}
+	if result.PageSelection == nil || !reflect.DeepEqual(result.PageSelection.Pages, []int{2, 4}) {
+		t.Fatalf("ReadFile() selection = %+v, want pages 2 and 4", result.PageSelection)
+	}
+	if !strings.Contains(result.Content, "page 2 ") || !strings.Contains(result.Content, "--- Page 3 omitted ---") ||
+		strings.Contains(result.Content, "page 5") {
+		t.Errorf("ReadFile() content should hold pages 2 and 4 only:\n%s", result.Content)
+	}
+
+	for _, req := range []PDFReadFileRequest{
+		{Path: path, Pages: []int{7}},
+		{Path: path, Pages: []int{1}, LastPages: 1},
+		{Path: path, Region: &Rectangle{X: 0, Y: 0, Width: 0, Height: 10}},
+	} {
+		if _, err := reader.ReadFile(req); err == nil {
+			t.Errorf("ReadFile(%+v) expected an error", req)
+		}
+	}
+
+	// The right column of a two-column page, in every layout
+	paper := createTempFile(t, "paper.pdf", buildTestPDF(twoColumnContent()))
+	region := &Rectangle{X: 300, Y: 0, Width: 312, Height: 740}
+	for _, layout := range []string{LayoutRaw, LayoutPositioned, LayoutReadingOrder, LayoutMarkdown} {
+		result, err := reader.ReadFile(PDFReadFileRequest{Path: paper, Layout: layout, Region: region})
+		if err != nil {
+			t.Fatalf("ReadFile(%q) unexpected error = %v", layout, err)
+		}
+		if !strings.Contains(result.Content, "Right column sentence 8") || strings.Contains(result.Content, "Left") ||
+			strings.Contains(result.Content, "Findings") {
+			t.Errorf("ReadFile(%q) content should hold only the right column:\n%s", layout, result.Content)
+		}
+	}
+}
+
+func TestExtractionService_ExtractStructuredRegion(t *testing.T) {
+	service := NewExtractionService(100 * 1024 * 1024)
+	path := createTempFile(t, "paper.pdf", buildTestPDF(twoColumnContent()))
+
+	result, err := service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path: path,
+		Config: ExtractConfig{
+			ExtractText: true, Region: &Rectangle{X: 0, Y: 700, Width: 300, Height: 40},
+			TextNormalization: &TextNormalization{},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	var texts []string
+	for _, element := range result.Elements {
+		text := fmt.Sprint(element.Content)
+		texts = append(texts, text)
+		if element.BoundingBox.Y < 690 || element.BoundingBox.X > 300 {
+			t.Errorf("element %q at %+v lies outside the region", text, element.BoundingBox)
+		}
+	}
+	want := []string{"Left column sentence 1 goes on", "Left column sentence 2 goes on"}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("ExtractStructured() text = %q, want %q", texts, want)
+	}
+
+	_, err = service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Config: ExtractConfig{Region: &Rectangle{Width: -1, Height: 10}},
+	})
+	if err == nil {
+		t.Error("ExtractStructured() expected an error for an empty region")
+	}
+}
+
+func TestPageLabels_InSelectionAndExtraction(t *testing.T) {
+	// Two roman front matter pages, then the body numbered from 1
+	catalog := "<< /Type /Catalog /Pages 2 0 R /PageLabels << /Nums [0 << /S /r >> 2 << /S /D >>] >> >>"
+	path := createTempFile(t, "labeled.pdf", buildCatalogPDF(catalog, sectionedPages(4, nil)...))
+
+	result, err := NewReader(100 * 1024 * 1024).ReadFile(PDFReadFileRequest{Path: path, Pages: []int{2, 3}})
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v", err)
+	}
+	if want := map[int]string{2: "ii", 3: "1"}; !reflect.DeepEqual(result.PageSelection.Labels, want) {
+		t.Errorf("ReadFile() selection labels = %v, want %v", result.PageSelection.Labels, want)
+	}
+
+	extracted, err := NewExtractionService(100*1024*1024).ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Config: ExtractConfig{ExtractText: true, Pages: []int{1, 4}},
+	})
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	for _, element := range extracted.Elements {
+		if want := map[int]string{1: "i", 4: "2"}[element.PageNumber]; element.PageLabel != want {
+			t.Errorf("element on page %d labeled %q, want %q", element.PageNumber, element.PageLabel, want)
+		}
+	}
+	var labels []string
+	for _, page := range extracted.Summary.PageBreakdown {
+		labels = append(labels, page.Label)
+	}
+	if !reflect.DeepEqual(labels, []string{"i", "2"}) {
+		t.Errorf("page breakdown labels = %q, want i and 2", labels)
+	}
+
+	// Unlabeled documents report no labels
+	plain := createTempFile(t, "plain.pdf", buildTestPDF(sectionedPages(2, nil)...))
+	result, err = NewReader(100 * 1024 * 1024).ReadFile(PDFReadFileRequest{Path: plain, Pages: []int{2}})
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v", err)
+	}
+	if result.PageSelection.Labels != nil {
+		t.Errorf("ReadFile() selection labels = %v, want none", result.PageSelection.Labels)
+	}
+}
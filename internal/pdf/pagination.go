@@ -0,0 +1,111 @@
+package pdf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/cache"
+)
+
+// Pagination constants
+const (
+	// cursorCacheBytes bounds the results kept for paging when the extraction cache is
+	// disabled. Results that do not fit are extracted again for each response.
+	cursorCacheBytes = 64 * 1024 * 1024
+	// cursorTokenBytes is the length of the hash identifying a paginated extraction
+	cursorTokenBytes = 16
+)
+
+// resultCursor is a position within a paginated extraction
+type resultCursor struct {
+	token  string // Identifies the file version and request being paged through
+	offset int
+}
+
+// parseCursor decodes a cursor returned as next_cursor
+func parseCursor(cursor string) (resultCursor, error) {
+	token, offset, ok := strings.Cut(cursor, ".")
+	n, err := strconv.Atoi(offset)
+	if !ok || err != nil || n < 0 || len(token) != 2*cursorTokenBytes {
+		return resultCursor{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return resultCursor{token: token, offset: n}, nil
+}
+
+// String encodes the cursor for a response's next_cursor
+func (c resultCursor) String() string {
+	return fmt.Sprintf("%s.%d", c.token, c.offset)
+}
+
+// openCursor starts or continues paging through an extraction. The cursor's token names the
+// file version and the options shaping the result, so a cursor cannot continue a different
+// request or a file that changed since it was issued.
+func openCursor(store *cache.Cache, req PDFExtractRequest, params any) (resultCursor, error) {
+	key, err := cacheKey(store, req.Path, "extract", params)
+	if err != nil {
+		return resultCursor{}, err
+	}
+	key += fmt.Sprintf(":%g:%d", req.Config.MinConfidence, req.Config.MaxElements)
+	hash := sha256.Sum256([]byte(key))
+	cursor := resultCursor{token: hex.EncodeToString(hash[:cursorTokenBytes])}
+
+	if req.Config.Cursor == "" {
+		return cursor, nil
+	}
+	given, err := parseCursor(req.Config.Cursor)
+	if err != nil {
+		return cursor, err
+	}
+	if given.token != cursor.token {
+		return cursor, fmt.Errorf("cursor does not continue this request: the file or the extraction " +
+			"options changed since it was issued; repeat the request without a cursor")
+	}
+	cursor.offset = given.offset
+	return cursor, nil
+}
+
+// paginateResult keeps the items of one response: pageSize elements and tables in page
+// order, starting at the cursor. The structure tree and entities are only returned with the
+// first response.
+func paginateResult(result *PDFExtractResult, cursor resultCursor, pageSize int) {
+	total := len(result.Elements) + len(result.Tables)
+	end := min(cursor.offset+pageSize, total)
+
+	elements := []ContentElement{}
+	var tables []TableElement
+	index, i, j := 0, 0, 0
+	for i < len(result.Elements) || j < len(result.Tables) {
+		inPage := index >= cursor.offset && index < end
+		if j < len(result.Tables) &&
+			(i == len(result.Elements) || result.Tables[j].PageNumber < result.Elements[i].PageNumber) {
+			if inPage {
+				tables = append(tables, result.Tables[j])
+			}
+			j++
+		} else {
+			if inPage {
+				elements = append(elements, result.Elements[i])
+			}
+			i++
+		}
+		index++
+	}
+
+	result.Elements = elements
+	result.Tables = tables
+	if cursor.offset > 0 {
+		result.Structure = nil
+		result.Entities = nil
+	}
+	result.Pagination = &ResultPage{
+		Offset:     cursor.offset,
+		PageSize:   pageSize,
+		TotalItems: total,
+	}
+	if end < total {
+		result.Pagination.NextCursor = resultCursor{token: cursor.token, offset: end}.String()
+	}
+}
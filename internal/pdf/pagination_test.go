@@ -0,0 +1,107 @@
+package pdf
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractionService_Pagination(t *testing.T) {
+	pages := sectionedPages(6, nil)
+	pages[3] = ruledTableContent()
+	path := createTempFile(t, "report.pdf", buildTestPDF(pages...))
+	service := NewExtractionService(10 * 1024 * 1024)
+
+	extract := func(pageSize int, cursor string) (*PDFExtractResult, error) {
+		return service.ExtractStructured(context.Background(), PDFExtractRequest{
+			Path:   path,
+			Mode:   "structured",
+			Config: ExtractConfig{ExtractText: true, ExtractTables: true, PageSize: pageSize, Cursor: cursor},
+		})
+	}
+
+	whole, err := extract(0, "")
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	if whole.Pagination != nil {
+		t.Errorf("ExtractStructured() without page_size = %+v, want no pagination", whole.Pagination)
+	}
+	total := len(whole.Elements) + len(whole.Tables)
+	if total < 5 || len(whole.Tables) != 1 {
+		t.Fatalf("ExtractStructured() found %d items with %d tables, want a table among several items",
+			total, len(whole.Tables))
+	}
+
+	// Paging through the result returns every item once, in page order
+	var elements, tables, responses int
+	lastPage := 0
+	cursor := ""
+	for {
+		result, err := extract(2, cursor)
+		if err != nil {
+			t.Fatalf("ExtractStructured() page %d unexpected error = %v", responses+1, err)
+		}
+		responses++
+		page := result.Pagination
+		if page == nil || page.TotalItems != total || page.Offset != elements+tables {
+			t.Fatalf("ExtractStructured() pagination = %+v, want offset %d of %d", page, elements+tables, total)
+		}
+		if n := len(result.Elements) + len(result.Tables); n > 2 || (n < 2 && page.NextCursor != "") {
+			t.Errorf("ExtractStructured() returned %d items with next cursor %q", n, page.NextCursor)
+		}
+		if result.Summary.TotalElements != whole.Summary.TotalElements {
+			t.Errorf("ExtractStructured() summary counts %d elements, want the whole result's %d",
+				result.Summary.TotalElements, whole.Summary.TotalElements)
+		}
+		for _, element := range result.Elements {
+			if element.PageNumber < lastPage {
+				t.Errorf("element on page %d follows page %d", element.PageNumber, lastPage)
+			}
+			lastPage = element.PageNumber
+		}
+		elements += len(result.Elements)
+		tables += len(result.Tables)
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if elements != len(whole.Elements) || tables != 1 || responses != (total+1)/2 {
+		t.Errorf("paged through %d elements and %d tables in %d responses, want %d and 1 in %d",
+			elements, tables, responses, len(whole.Elements), (total+1)/2)
+	}
+
+	// Responses after the first are cut from the stored result rather than extracted again
+	if stats := service.cursors.Stats(); stats.Entries != 1 || stats.Hits != int64(responses-1) {
+		t.Errorf("cursor store = %+v, want one result hit %d times", stats, responses-1)
+	}
+
+	// A cursor only continues the request and file version it was issued for
+	first, err := extract(2, "")
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	next := first.Pagination.NextCursor
+	if _, err := service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Config: ExtractConfig{ExtractImages: true, PageSize: 2, Cursor: next},
+	}); err == nil || !strings.Contains(err.Error(), "does not continue this request") {
+		t.Errorf("ExtractStructured() with other options error = %v, want the cursor rejected", err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Chtimes() unexpected error = %v", err)
+	}
+	if _, err := extract(2, next); err == nil || !strings.Contains(err.Error(), "does not continue this request") {
+		t.Errorf("ExtractStructured() after the file changed error = %v, want the cursor rejected", err)
+	}
+
+	if _, err := extract(0, next); err == nil || !strings.Contains(err.Error(), "cursor requires page_size") {
+		t.Errorf("ExtractStructured() cursor without page_size error = %v, want it rejected", err)
+	}
+	if _, err := extract(2, "not-a-cursor"); err == nil || !strings.Contains(err.Error(), "invalid cursor") {
+		t.Errorf("ExtractStructured() with a malformed cursor error = %v, want it rejected", err)
+	}
+}
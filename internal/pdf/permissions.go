@@ -0,0 +1,172 @@
+package pdf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/ledongthuc/pdf"
+)
+
+// Text extraction verdicts of pdf_get_permissions
+const (
+	TextExtractionAllowed       = "allowed"            // Text may be copied and extracted
+	TextExtractionAccessibility = "accessibility_only" // Text may be extracted only to make it accessible
+	TextExtractionDenied        = "denied"             // The permissions forbid extracting text
+	TextExtractionLocked        = "password_required"  // The server cannot decrypt the document
+)
+
+// Bits of the /P entry of an encryption dictionary, numbered from 1 as in ISO 32000-1,
+// Table 22
+const (
+	permissionPrint         = 1 << (3 - 1)
+	permissionModify        = 1 << (4 - 1)
+	permissionCopy          = 1 << (5 - 1)
+	permissionAnnotate      = 1 << (6 - 1)
+	permissionFillForms     = 1 << (9 - 1)
+	permissionAccessibility = 1 << (10 - 1)
+	permissionAssemble      = 1 << (11 - 1)
+	permissionPrintHigh     = 1 << (12 - 1)
+)
+
+// Security reports how documents are encrypted and what their permissions allow
+type Security struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewSecurity creates a new security reader with the specified constraints
+func NewSecurity(maxFileSize int64) *Security {
+	return &Security{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// Permissions reports a document's encryption filter, algorithm, and key length, the
+// permissions its /P entry grants, and whether the server can extract its text: it must be
+// able to decrypt the document, and the permissions must allow copying text, or at least
+// extracting it for accessibility. Unencrypted documents allow everything. A document the
+// empty password does not open is reported as needing a password, since its encryption
+// dictionary cannot be read without parsing it.
+func (s *Security) Permissions(req PDFGetPermissionsRequest) (*PDFGetPermissionsResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: s.validator.ValidateFileInfo})
+	if errors.Is(err, core.ErrPasswordRequired) {
+		return &PDFGetPermissionsResult{
+			Path:             req.Path,
+			Encrypted:        true,
+			RequiresPassword: true,
+			TextExtraction:   TextExtractionLocked,
+			Reason:           "the document is encrypted with a user password the server does not have",
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	encrypt := doc.Reader.Trailer().Key("Encrypt")
+	result := &PDFGetPermissionsResult{
+		Path:      req.Path,
+		Encrypted: !encrypt.IsNull(),
+	}
+	if !result.Encrypted {
+		result.Permissions = DocumentPermissions{
+			Print: true, PrintHighQuality: true, Modify: true, Copy: true,
+			Annotate: true, FillForms: true, AccessibilityExtraction: true, Assemble: true,
+		}
+		result.TextExtraction = TextExtractionAllowed
+		result.Reason = "the document is not encrypted"
+		return result, nil
+	}
+
+	result.Encryption = readEncryption(encrypt)
+	result.Permissions = decodePermissions(result.Encryption.PermissionFlags, result.Encryption.Revision)
+	switch {
+	case result.Permissions.Copy:
+		result.TextExtraction = TextExtractionAllowed
+		result.Reason = "the permissions allow copying text"
+	case result.Permissions.AccessibilityExtraction:
+		result.TextExtraction = TextExtractionAccessibility
+		result.Reason = "the permissions forbid copying text but allow extracting it for accessibility"
+	default:
+		result.TextExtraction = TextExtractionDenied
+		result.Reason = "the permissions forbid extracting text"
+	}
+	return result, nil
+}
+
+// readEncryption reads the filter, version, and key length of an encryption dictionary.
+// Key lengths default to 40 bits; the crypt filters of version 4 give theirs in bytes or
+// bits, and name AES-128 or AES-256 by their method.
+func readEncryption(encrypt pdf.Value) *EncryptionInfo {
+	info := &EncryptionInfo{
+		Filter:          encrypt.Key("Filter").Name(),
+		SubFilter:       encrypt.Key("SubFilter").Name(),
+		Version:         int(encrypt.Key("V").Int64()),
+		Revision:        int(encrypt.Key("R").Int64()),
+		KeyLength:       int(encrypt.Key("Length").Int64()),
+		PermissionFlags: int32(encrypt.Key("P").Int64()),
+		Algorithm:       "RC4",
+	}
+	if meta := encrypt.Key("EncryptMetadata"); meta.Kind() == pdf.Bool {
+		info.MetadataEncrypted = meta.Bool()
+	} else {
+		info.MetadataEncrypted = true
+	}
+
+	if info.Version >= 4 {
+		filter := encrypt.Key("CF").Key(encrypt.Key("StmF").Name())
+		switch filter.Key("CFM").Name() {
+		case "AESV2":
+			info.Algorithm, info.KeyLength = "AES-128", 128
+		case "AESV3":
+			info.Algorithm, info.KeyLength = "AES-256", 256
+		case "None":
+			info.Algorithm = "none"
+		default:
+			if length := int(filter.Key("Length").Int64()); length > 0 {
+				info.KeyLength = length
+				if length <= 32 {
+					info.KeyLength = length * 8
+				}
+			}
+		}
+	}
+	if info.Version >= 5 {
+		info.Algorithm, info.KeyLength = "AES-256", 256
+	}
+	if info.KeyLength == 0 {
+		info.KeyLength = 40
+	}
+	return info
+}
+
+// decodePermissions reads the permission bits of /P. Revision 2 has only the first four;
+// its form filling, accessibility, assembly, and high-quality printing follow annotating,
+// copying, modifying, and printing.
+func decodePermissions(flags int32, revision int) DocumentPermissions {
+	has := func(bit int32) bool { return flags&bit != 0 }
+	permissions := DocumentPermissions{
+		Print:    has(permissionPrint),
+		Modify:   has(permissionModify),
+		Copy:     has(permissionCopy),
+		Annotate: has(permissionAnnotate),
+	}
+	if revision >= 3 {
+		permissions.FillForms = has(permissionFillForms)
+		permissions.AccessibilityExtraction = has(permissionAccessibility)
+		permissions.Assemble = has(permissionAssemble)
+		permissions.PrintHighQuality = permissions.Print && has(permissionPrintHigh)
+	} else {
+		permissions.FillForms = permissions.Annotate
+		permissions.AccessibilityExtraction = permissions.Copy
+		permissions.Assemble = permissions.Modify
+		permissions.PrintHighQuality = permissions.Print
+	}
+	return permissions
+}
@@ -0,0 +1,106 @@
+package pdf
+
+import (
+	"crypto/md5"
+	"crypto/rc4"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// encryptionPad is the padding of passwords of the standard security handler
+var encryptionPad = []byte("\x28\xbf\x4e\x5e\x4e\x75\x8a\x41\x64\x00\x4e\x56\xff\xfa\x01\x08" +
+	"\x2e\x2e\x00\xb6\xd0\x68\x3e\x80\x2f\x0c\xa9\xfe\x64\x53\x69\x7a")
+
+// buildEncryptedPDF builds a one-page PDF encrypted with 40-bit RC4, revision 2, and the
+// given permissions. With openable set the empty user password opens it.
+func buildEncryptedPDF(permissions int32, openable bool) string {
+	id := []byte("0123456789abcdef")
+	owner := make([]byte, 32)
+	copy(owner, "owner")
+
+	h := md5.New()
+	h.Write(encryptionPad)
+	h.Write(owner)
+	_ = binary.Write(h, binary.LittleEndian, permissions)
+	h.Write(id)
+	user := make([]byte, 32)
+	if openable {
+		c, _ := rc4.NewCipher(h.Sum(nil)[:5])
+		c.XORKeyStream(user, encryptionPad)
+	}
+
+	raw := buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		fmt.Sprintf("<< /Filter /Standard /V 1 /R 2 /O <%x> /U <%x> /P %d >>", owner, user, permissions),
+	})
+	return strings.Replace(raw, "/Root 1 0 R >>", fmt.Sprintf("/Root 1 0 R /Encrypt 4 0 R /ID [<%x> <%x>] >>", id, id), 1)
+}
+
+func TestSecurity_Permissions(t *testing.T) {
+	security := NewSecurity(100 * 1024 * 1024)
+
+	plain, err := security.Permissions(PDFGetPermissionsRequest{
+		Path: createTempFile(t, "plain.pdf", buildTestPDF("BT ET")),
+	})
+	if err != nil {
+		t.Fatalf("Permissions(plain) unexpected error = %v", err)
+	}
+	if plain.Encrypted || plain.Encryption != nil || !plain.Permissions.Copy ||
+		plain.TextExtraction != TextExtractionAllowed {
+		t.Errorf("Permissions(plain) = %+v, want an unencrypted document allowing everything", plain)
+	}
+
+	// Printing and copying allowed; the reserved high bits are set as the standard requires
+	restricted, err := security.Permissions(PDFGetPermissionsRequest{
+		Path: createTempFile(t, "restricted.pdf", buildEncryptedPDF(-64+4+16, true)),
+	})
+	if err != nil {
+		t.Fatalf("Permissions(restricted) unexpected error = %v", err)
+	}
+	enc := restricted.Encryption
+	if !restricted.Encrypted || enc == nil || enc.Filter != "Standard" || enc.Algorithm != "RC4" ||
+		enc.KeyLength != 40 || enc.Revision != 2 || !enc.MetadataEncrypted {
+		t.Errorf("Permissions(restricted) encryption = %+v, want 40-bit RC4 revision 2", enc)
+	}
+	want := DocumentPermissions{Print: true, PrintHighQuality: true, Copy: true, AccessibilityExtraction: true}
+	if restricted.Permissions != want {
+		t.Errorf("Permissions(restricted) = %+v, want %+v", restricted.Permissions, want)
+	}
+	if restricted.TextExtraction != TextExtractionAllowed {
+		t.Errorf("Permissions(restricted) text extraction = %s, want %s",
+			restricted.TextExtraction, TextExtractionAllowed)
+	}
+
+	denied, err := security.Permissions(PDFGetPermissionsRequest{
+		Path: createTempFile(t, "denied.pdf", buildEncryptedPDF(-64, true)),
+	})
+	if err != nil {
+		t.Fatalf("Permissions(denied) unexpected error = %v", err)
+	}
+	if denied.Permissions.Copy || denied.TextExtraction != TextExtractionDenied {
+		t.Errorf("Permissions(denied) = %+v, want text extraction denied", denied)
+	}
+
+	locked, err := security.Permissions(PDFGetPermissionsRequest{
+		Path: createTempFile(t, "locked.pdf", buildEncryptedPDF(-4, false)),
+	})
+	if err != nil {
+		t.Fatalf("Permissions(locked) unexpected error = %v", err)
+	}
+	if !locked.RequiresPassword || locked.TextExtraction != TextExtractionLocked {
+		t.Errorf("Permissions(locked) = %+v, want a document needing a password", locked)
+	}
+}
+
+func TestDecodePermissions(t *testing.T) {
+	// Revision 3 grants accessibility extraction separately from copying
+	got := decodePermissions(-3904+permissionAccessibility+permissionFillForms, 3)
+	want := DocumentPermissions{FillForms: true, AccessibilityExtraction: true}
+	if got != want {
+		t.Errorf("decodePermissions() = %+v, want %+v", got, want)
+	}
+}
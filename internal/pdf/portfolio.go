@@ -0,0 +1,487 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// PortfolioMemberSeparator separates the path of a portfolio from the name of one of its
+// member documents in the paths tools accept, as in binder.pdf#member=q1-report.pdf.
+// Members of portfolios nested in a portfolio are named by repeating it.
+const PortfolioMemberSeparator = "#member="
+
+// Portfolio views, from the /View entry of a collection
+const (
+	PortfolioViewDetails = "details" // A table of the members and their fields
+	PortfolioViewTile    = "tile"    // Icons of the members
+	PortfolioViewHidden  = "hidden"  // The cover sheet alone, members behind it
+	PortfolioViewCustom  = "custom"  // A navigator supplied by the document
+)
+
+// Portfolio reading defaults
+const (
+	defaultPortfolioChars = 4000 // Text kept per member when reading their text
+	maxPortfolioDepth     = 3    // Nested portfolios read into when reading their text
+)
+
+// portfolioViews maps the names of the /View entry to portfolio views
+var portfolioViews = map[string]string{
+	"D": PortfolioViewDetails,
+	"T": PortfolioViewTile,
+	"H": PortfolioViewHidden,
+	"C": PortfolioViewCustom,
+}
+
+// portfolioFieldTypes maps the subtypes of collection fields to the types reported: fields
+// holding a value of each member, or showing a property of its embedded file
+var portfolioFieldTypes = map[string]string{
+	"S":              "text",
+	"D":              "date",
+	"N":              "number",
+	"F":              "file_name",
+	"Desc":           "description",
+	"Size":           "size",
+	"ModDate":        "mod_date",
+	"CreationDate":   "creation_date",
+	"CompressedSize": "compressed_size",
+}
+
+// Portfolios reads PDF portfolios: documents whose /Collection presents the files embedded
+// in them as the content, behind a cover sheet that usually holds no more than a notice
+// to open the document in a portfolio-aware viewer
+type Portfolios struct {
+	maxFileSize int64
+	validator   *Validator
+	attachments *Attachments
+}
+
+// NewPortfolios creates a portfolio reader with the specified constraints
+func NewPortfolios(maxFileSize int64) *Portfolios {
+	return &Portfolios{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+		attachments: NewAttachments(maxFileSize),
+	}
+}
+
+// ReadPortfolio describes a portfolio: how its viewer presents it, the fields of its
+// schema, and its members with their field values. Documents without a /Collection that
+// embed files are listed the same way, as plain attachments. Members that are PDFs get
+// the path that reads them with any tool.
+func (p *Portfolios) ReadPortfolio(req PDFPortfolioRequest) (result *PDFPortfolioResult, err error) {
+	if req.Path == "" {
+		return nil, pdferrors.New(pdferrors.InvalidArgument, "path cannot be empty")
+	}
+	doc, err := core.Open(req.Path, core.Options{Validate: p.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			result = nil
+			err = pdferrors.New(pdferrors.Corrupt, "failed to read portfolio: %v", rec)
+		}
+	}()
+
+	root := doc.Reader.Trailer().Key("Root")
+	collection := root.Key("Collection")
+	result = &PDFPortfolioResult{
+		Path:        req.Path,
+		IsPortfolio: collection.Kind() == pdf.Dict,
+		Fields:      []PortfolioField{},
+		Members:     []PortfolioMember{},
+	}
+	if result.IsPortfolio {
+		result.View = portfolioViews[collection.Key("View").Name()]
+		if result.View == "" {
+			result.View = PortfolioViewDetails
+		}
+		result.InitialDocument = collection.Key("D").Text()
+		result.Fields = portfolioFields(collection.Key("Schema"))
+	}
+
+	wanted := make(map[string]bool, len(req.Members))
+	for _, name := range req.Members {
+		wanted[name] = true
+	}
+	extraction.WalkNameTree(root.Key("Names").Key("EmbeddedFiles"), func(key string, spec pdf.Value) {
+		info, payload := p.attachments.readFileSpec(spec, key)
+		if len(wanted) > 0 && !wanted[key] && !wanted[info.Name] {
+			return
+		}
+		member := PortfolioMember{
+			Key:         key,
+			Name:        info.Name,
+			Description: info.Description,
+			MIMEType:    info.MIMEType,
+			Size:        info.Size,
+			ModDate:     info.ModDate,
+			IsPDF:       isPDFPayload(payload),
+			Fields:      memberFields(spec, info, result.Fields),
+			Error:       info.Error,
+		}
+		if member.IsPDF {
+			member.MIMEType = "application/pdf"
+			member.Reference = req.Path + PortfolioMemberSeparator + key
+			result.PDFCount++
+		}
+		result.Members = append(result.Members, member)
+	})
+	if result.IsPortfolio {
+		sortPortfolioMembers(result.Members, collection.Key("Sort"), result.Fields)
+	}
+	result.TotalCount = len(result.Members)
+	return result, nil
+}
+
+// memberPayload returns the content of the member of a portfolio named by its key in the
+// name tree or its file name, which must be a PDF
+func (p *Portfolios) memberPayload(path, name string) (payload []byte, err error) {
+	doc, err := core.Open(path, core.Options{Validate: p.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			payload = nil
+			err = pdferrors.New(pdferrors.Corrupt, "failed to read portfolio: %v", rec)
+		}
+	}()
+
+	var names []string
+	found := false
+	root := doc.Reader.Trailer().Key("Root")
+	extraction.WalkNameTree(root.Key("Names").Key("EmbeddedFiles"), func(key string, spec pdf.Value) {
+		names = append(names, key)
+		if found || (key != name && fileSpecName(spec, key) != name) {
+			return
+		}
+		found = true
+		var info AttachmentInfo
+		if info, payload = p.attachments.readFileSpec(spec, key); info.Error != "" {
+			err = fmt.Errorf("cannot read member %q: %s", name, info.Error)
+		}
+	})
+	switch {
+	case !found:
+		return nil, pdferrors.New(pdferrors.NotFound, "%s has no member %q (members: %s)",
+			path, name, strings.Join(names, ", "))
+	case err != nil:
+		return nil, err
+	case !isPDFPayload(payload):
+		return nil, pdferrors.New(pdferrors.InvalidArgument,
+			"member %q is not a PDF; save it with pdf_extract_attachments", name)
+	}
+	return payload, nil
+}
+
+// isPDFPayload reports whether a file starts with a PDF header, which viewers accept
+// within the first kilobyte
+func isPDFPayload(payload []byte) bool {
+	return bytes.Contains(payload[:min(len(payload), 1024)], []byte("%PDF-"))
+}
+
+// isPortfolio reports whether a document is a portfolio, and how many files it embeds
+func isPortfolio(r *pdf.Reader) (bool, int) {
+	root := r.Trailer().Key("Root")
+	if root.Key("Collection").Kind() != pdf.Dict {
+		return false, 0
+	}
+	count := 0
+	extraction.WalkNameTree(root.Key("Names").Key("EmbeddedFiles"), func(string, pdf.Value) { count++ })
+	return true, count
+}
+
+// portfolioFields reads the fields of a collection schema in their display order
+func portfolioFields(schema pdf.Value) []PortfolioField {
+	type ordered struct {
+		field PortfolioField
+		order int64
+	}
+	var fields []ordered
+	for _, key := range schema.Keys() {
+		entry := schema.Key(key)
+		if entry.Kind() != pdf.Dict {
+			continue
+		}
+		field := PortfolioField{
+			Key: key, Name: entry.Key("N").Text(), Type: portfolioFieldTypes[entry.Key("Subtype").Name()],
+		}
+		if field.Name == "" {
+			field.Name = key
+		}
+		if field.Type == "" {
+			field.Type = "text"
+		}
+		field.Hidden = entry.Key("V").Kind() == pdf.Bool && !entry.Key("V").Bool()
+		fields = append(fields, ordered{field: field, order: entry.Key("O").Int64()})
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].order != fields[j].order {
+			return fields[i].order < fields[j].order
+		}
+		return fields[i].field.Key < fields[j].field.Key
+	})
+
+	result := make([]PortfolioField, len(fields))
+	for i, field := range fields {
+		result[i] = field.field
+	}
+	return result
+}
+
+// memberFields reads a member's values of the schema fields, by field key: the values of
+// its collection item, and the properties of its file that fields show
+func memberFields(spec pdf.Value, info AttachmentInfo, fields []PortfolioField) map[string]string {
+	values := make(map[string]string)
+	item := spec.Key("CI")
+	for _, field := range fields {
+		var value string
+		switch field.Type {
+		case "file_name":
+			value = info.Name
+		case "description":
+			value = info.Description
+		case "size", "compressed_size":
+			value = strconv.FormatInt(info.Size, 10)
+		case "mod_date":
+			value = info.ModDate
+		case "creation_date":
+			value = info.CreationDate
+		default:
+			value = collectionValue(item.Key(field.Key))
+		}
+		if value != "" {
+			values[field.Key] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// collectionValue formats a value of a collection item: text, a date, a number, or a
+// subitem holding one with a prefix to show before it
+func collectionValue(v pdf.Value) string {
+	switch v.Kind() {
+	case pdf.Integer:
+		return strconv.FormatInt(v.Int64(), 10)
+	case pdf.Real:
+		return strconv.FormatFloat(v.Float64(), 'f', -1, 64)
+	case pdf.String:
+		if text := v.RawString(); strings.HasPrefix(text, "D:") {
+			return formatPDFDate(text)
+		}
+		return v.Text()
+	case pdf.Dict:
+		value := collectionValue(v.Key("D"))
+		if prefix := v.Key("P").Text(); prefix != "" && value != "" {
+			return prefix + value
+		}
+		return value
+	}
+	return ""
+}
+
+// sortPortfolioMembers orders members as the collection's /Sort asks: by one field or
+// several, each ascending unless /A says otherwise. Numbers and sizes compare as numbers.
+func sortPortfolioMembers(members []PortfolioMember, order pdf.Value, fields []PortfolioField) {
+	var keys []string
+	switch order.Key("S").Kind() {
+	case pdf.Name:
+		keys = []string{order.Key("S").Name()}
+	case pdf.Array:
+		for i := 0; i < order.Key("S").Len(); i++ {
+			keys = append(keys, order.Key("S").Index(i).Name())
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	ascending := func(i int) bool {
+		a := order.Key("A")
+		switch a.Kind() {
+		case pdf.Bool:
+			return a.Bool()
+		case pdf.Array:
+			return i >= a.Len() || a.Index(i).Kind() != pdf.Bool || a.Index(i).Bool()
+		}
+		return true
+	}
+	numeric := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		numeric[field.Key] = field.Type == "number" || field.Type == "size" || field.Type == "compressed_size"
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		for k, key := range keys {
+			a, b := members[i].Fields[key], members[j].Fields[key]
+			if a == b {
+				continue
+			}
+			less := a < b
+			if numeric[key] {
+				x, _ := strconv.ParseFloat(a, 64)
+				y, _ := strconv.ParseFloat(b, 64)
+				less = x < y
+			}
+			return less == ascending(k)
+		}
+		return false
+	})
+}
+
+// portfolioMember identifies a member document extracted from a version of a portfolio
+type portfolioMember struct {
+	path    string
+	member  string
+	size    int64
+	modTime time.Time
+}
+
+// portfolioMembers remembers the loaded documents holding the members read so far, so that
+// consecutive calls on a member extract it once
+type portfolioMembers struct {
+	mu      sync.Mutex
+	handles map[portfolioMember]string
+}
+
+// splitPortfolioMember splits a path naming a portfolio member into the portfolio's path
+// and the member's name. Paths of existing files are never split.
+func splitPortfolioMember(path string) (container, member string, ok bool) {
+	i := strings.LastIndex(path, PortfolioMemberSeparator)
+	if i < 0 {
+		return "", "", false
+	}
+	if _, err := fsys.Stat(path); err == nil {
+		return "", "", false
+	}
+	container, member = path[:i], path[i+len(PortfolioMemberSeparator):]
+	return container, member, container != "" && member != ""
+}
+
+// resolveMember returns the file holding a member of a portfolio, extracting it into a
+// loaded document unless an earlier call did so for the same version of the portfolio
+func (s *Service) resolveMember(container, member string) (string, error) {
+	container, err := s.ResolvePath(container)
+	if err != nil {
+		return "", err
+	}
+	info, err := fsys.Stat(container)
+	if err != nil {
+		return "", pdferrors.New(pdferrors.NotFound, "file does not exist: %s", container)
+	}
+	key := portfolioMember{path: container, member: member, size: info.Size(), modTime: info.ModTime()}
+
+	s.members.mu.Lock()
+	defer s.members.mu.Unlock()
+	if handle, ok := s.members.handles[key]; ok {
+		if path, err := s.loaded.resolve(handle); err == nil {
+			return path, nil
+		}
+		delete(s.members.handles, key)
+	}
+
+	payload, err := s.portfolios.memberPayload(container, member)
+	if err != nil {
+		return "", err
+	}
+	doc, err := s.loadDocument(member, payload)
+	if err != nil {
+		return "", err
+	}
+	s.members.handles[key] = doc.Handle
+	return doc.Path, nil
+}
+
+// PDFPortfolio lists the members of a portfolio and, when asked, reads the text of each
+// member that is a PDF, recursing into nested portfolios, so that one call gives the
+// content of the whole collection with a result per member
+func (s *Service) PDFPortfolio(req PDFPortfolioRequest) (*PDFPortfolioResult, error) {
+	return s.readPortfolio(req, 0)
+}
+
+// readPortfolio reads a portfolio and, when asked, the text of its members
+func (s *Service) readPortfolio(req PDFPortfolioRequest, depth int) (*PDFPortfolioResult, error) {
+	result, err := s.portfolios.ReadPortfolio(req)
+	if err != nil || !req.IncludeText {
+		return result, err
+	}
+	maxChars := req.MaxChars
+	if maxChars <= 0 {
+		maxChars = defaultPortfolioChars
+	}
+	s.readMembers(result, maxChars, depth)
+	return result, nil
+}
+
+// readMembers reads the text of the PDF members of a portfolio nested depth levels deep,
+// and the members of the portfolios among them. Failures are recorded on the member.
+func (s *Service) readMembers(result *PDFPortfolioResult, maxChars, depth int) {
+	for i := range result.Members {
+		member := &result.Members[i]
+		if member.Reference == "" {
+			continue
+		}
+		path, err := s.ResolvePath(member.Reference)
+		if err != nil {
+			member.Error = err.Error()
+			continue
+		}
+
+		nested, err := s.portfolios.ReadPortfolio(PDFPortfolioRequest{Path: path})
+		if err == nil && nested.IsPortfolio && depth+1 < maxPortfolioDepth {
+			// Name the nested members through this member, as the caller knows it
+			nested.Path = member.Reference
+			for j := range nested.Members {
+				if nested.Members[j].Reference != "" {
+					nested.Members[j].Reference = member.Reference + PortfolioMemberSeparator + nested.Members[j].Key
+				}
+			}
+			s.readMembers(nested, maxChars, depth+1)
+			member.Portfolio = nested
+		}
+
+		read, err := s.reader.ReadFile(PDFReadFileRequest{Path: path})
+		if err != nil {
+			// The cover sheet of a nested portfolio often has no text; its members do
+			if member.Portfolio == nil {
+				member.Error = err.Error()
+			}
+			continue
+		}
+		member.Pages = read.Pages
+		member.ContentType = read.ContentType
+		member.Text, member.Truncated = truncateRunes(strings.TrimSpace(read.Content), maxChars)
+	}
+}
+
+// truncateRunes cuts text to at most n runes, reporting whether it did
+func truncateRunes(text string, n int) (string, bool) {
+	count := 0
+	for i := range text {
+		if count == n {
+			return text[:i], true
+		}
+		count++
+	}
+	return text, false
+}
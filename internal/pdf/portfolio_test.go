@@ -0,0 +1,131 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+)
+
+// portfolioFile is a file embedded in a test portfolio with its collection item values
+type portfolioFile struct {
+	name, data, item string
+}
+
+// buildPortfolioPDF builds a portfolio with a textless cover page, a schema of a quarter
+// number and a title, sorted by quarter, and the given files
+func buildPortfolioPDF(files ...portfolioFile) string {
+	names := make([]string, len(files))
+	objects := []string{
+		"", // Catalog, once the name tree is known
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+	}
+	for _, file := range files {
+		spec := len(objects) + 1
+		names = append(names, fmt.Sprintf("(%s) %d 0 R", file.name, spec))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Filespec /F (%s) /UF (%s) /EF << /F %d 0 R >> /CI << %s >> >>",
+				file.name, file.name, spec+1, file.item),
+			embeddedFileStream("/application#2Fpdf", file.data, ""))
+	}
+	objects[0] = "<< /Type /Catalog /Pages 2 0 R /Names << /EmbeddedFiles << /Names [" +
+		strings.Join(names, " ") + "] >> >> /Collection << /Type /Collection /View /D /D (q1.pdf) " +
+		"/Schema << /Quarter << /Subtype /N /N (Quarter) /O 1 >> /Title << /Subtype /S /N (Title) /O 0 >> " +
+		"/File << /Subtype /F /N (File) /O 2 /V false >> >> /Sort << /S /Quarter >> >> >>"
+	return buildRawPDF(objects)
+}
+
+func TestService_PDFPortfolio(t *testing.T) {
+	service := NewService(10 * 1024 * 1024)
+	path := createTempFile(t, "binder.pdf", buildPortfolioPDF(
+		portfolioFile{"q2.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Second quarter revenue) Tj ET"),
+			"/Quarter 2 /Title (Q2 report)"},
+		portfolioFile{"notes.txt", "plain notes", "/Quarter 3"},
+		portfolioFile{"q1.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (First quarter revenue) Tj ET"),
+			"/Quarter 1 /Title (Q1 report)"},
+	))
+
+	result, err := service.PDFPortfolio(PDFPortfolioRequest{Path: path})
+	if err != nil {
+		t.Fatalf("PDFPortfolio() unexpected error = %v", err)
+	}
+	if !result.IsPortfolio || result.View != PortfolioViewDetails || result.InitialDocument != "q1.pdf" ||
+		result.TotalCount != 3 || result.PDFCount != 2 {
+		t.Fatalf("PDFPortfolio() = %+v, want a details view of 3 members, 2 of them PDFs", result)
+	}
+	if len(result.Fields) != 3 || result.Fields[0].Name != "Title" || !result.Fields[2].Hidden {
+		t.Errorf("Fields = %+v, want Title, Quarter, and the hidden File in display order", result.Fields)
+	}
+	var order []string
+	for _, member := range result.Members {
+		order = append(order, member.Name)
+	}
+	if strings.Join(order, " ") != "q1.pdf q2.pdf notes.txt" {
+		t.Errorf("members = %v, want them sorted by quarter", order)
+	}
+	q1 := result.Members[0]
+	if !q1.IsPDF || q1.Reference != path+"#member=q1.pdf" || q1.Fields["Title"] != "Q1 report" ||
+		q1.Fields["File"] != "q1.pdf" || q1.Text != "" {
+		t.Errorf("member = %+v, want q1.pdf with its reference and field values and no text", q1)
+	}
+	if result.Members[2].IsPDF || result.Members[2].Reference != "" {
+		t.Errorf("member = %+v, want notes.txt without a reference", result.Members[2])
+	}
+
+	// The cover sheet has no text, so reading the portfolio points at its members
+	_, err = service.PDFReadFile(PDFReadFileRequest{Path: path})
+	if pdferrors.CodeOf(err) != pdferrors.InvalidArgument || !strings.Contains(err.Error(), "pdf_portfolio") {
+		t.Errorf("PDFReadFile() of the portfolio error = %v, want a pointer to pdf_portfolio", err)
+	}
+
+	// Every tool reads a member through its reference
+	resolved, err := service.ResolvePath(q1.Reference)
+	if err != nil {
+		t.Fatalf("ResolvePath() unexpected error = %v", err)
+	}
+	read, err := service.PDFReadFile(PDFReadFileRequest{Path: resolved})
+	if err != nil || !strings.Contains(read.Content, "First quarter revenue") {
+		t.Errorf("PDFReadFile() of the member = %+v, %v", read, err)
+	}
+	if again, err := service.ResolvePath(q1.Reference); err != nil || again != resolved {
+		t.Errorf("ResolvePath() again = %q, %v, want the member extracted once", again, err)
+	}
+	if _, err := service.ResolvePath(path + "#member=missing.pdf"); pdferrors.CodeOf(err) != pdferrors.NotFound {
+		t.Errorf("ResolvePath() of a missing member error = %v, want NOT_FOUND", err)
+	}
+	if _, err := service.ResolvePath(path + "#member=notes.txt"); pdferrors.CodeOf(err) != pdferrors.InvalidArgument {
+		t.Errorf("ResolvePath() of a member that is no PDF error = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestService_PDFPortfolioText(t *testing.T) {
+	service := NewService(10 * 1024 * 1024)
+	inner := buildPortfolioPDF(portfolioFile{
+		"annex.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Annex with the audited figures) Tj ET"), "/Quarter 1",
+	})
+	path := createTempFile(t, "binder.pdf", buildPortfolioPDF(
+		portfolioFile{"q1.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (First quarter revenue) Tj ET"), "/Quarter 1"},
+		portfolioFile{"annexes.pdf", inner, "/Quarter 2"},
+	))
+
+	result, err := service.PDFPortfolio(PDFPortfolioRequest{Path: path, IncludeText: true, MaxChars: 13})
+	if err != nil {
+		t.Fatalf("PDFPortfolio() unexpected error = %v", err)
+	}
+	q1, annexes := result.Members[0], result.Members[1]
+	if q1.Pages != 1 || q1.Text != "First quarter" || !q1.Truncated || q1.Error != "" {
+		t.Errorf("member = %+v, want its text cut to 13 characters", q1)
+	}
+	if annexes.Portfolio == nil || len(annexes.Portfolio.Members) != 1 || annexes.Error != "" {
+		t.Fatalf("member = %+v, want the members of the nested portfolio", annexes)
+	}
+	annex := annexes.Portfolio.Members[0]
+	if annex.Reference != path+"#member=annexes.pdf#member=annex.pdf" || annex.Text != "Annex with th" {
+		t.Errorf("nested member = %+v, want it named through its portfolio and read", annex)
+	}
+	if _, err := service.ResolvePath(annex.Reference); err != nil {
+		t.Errorf("ResolvePath() of a nested member unexpected error = %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// Preview constants
+const (
+	// previewMaxPages is the most pages a preview samples
+	previewMaxPages = 12
+	// previewScanPages is the most pages inspected for headings and tables; longer documents
+	// are inspected at evenly spaced pages
+	previewScanPages = 400
+	// DefaultPreviewElements is the element budget of a preview when none is given
+	DefaultPreviewElements = 150
+)
+
+// Reasons a page is sampled for a preview
+const (
+	PreviewReasonFirst   = "first"
+	PreviewReasonLast    = "last"
+	PreviewReasonSection = "section"
+	PreviewReasonTable   = "table"
+	PreviewReasonForm    = "form"
+)
+
+// SelectPreviewPages picks a representative sample of at most previewMaxPages pages: the
+// first and last pages, then section starts, pages with ruled tables, and pages with form
+// fields, sharing the remaining slots evenly between those kinds and spreading each kind
+// across the document. Sections come from the outline, or from page headings when the
+// document has none.
+func SelectPreviewPages(r *pdf.Reader) (selection *PreviewSelection, err error) {
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			selection = nil
+			err = fmt.Errorf("failed to select preview pages: %v", rec)
+		}
+	}()
+
+	numbering := extraction.NewPageNumbering(r)
+	total := numbering.Count()
+	selection = &PreviewSelection{TotalPages: total, Pages: []PreviewPage{}}
+	if total == 0 {
+		return selection, nil
+	}
+
+	reasons := make(map[int][]string)
+	reasons[1] = append(reasons[1], PreviewReasonFirst)
+	if total > 1 {
+		reasons[total] = append(reasons[total], PreviewReasonLast)
+	}
+
+	sections := sortedPages(outlineSectionStarts(r, numbering))
+	findHeadings := len(sections) == 0
+	var tables, forms []int
+	hasFields := r.Trailer().Key("Root").Key("AcroForm").Key("Fields").Len() > 0
+	stride := (total + previewScanPages - 1) / previewScanPages
+	for pageNum := 1; pageNum <= total; pageNum++ {
+		page := numbering.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+		if hasFields && pageHasWidgets(page) {
+			forms = append(forms, pageNum)
+		}
+		if (pageNum-1)%stride != 0 {
+			continue
+		}
+		if extraction.HasRuledTable(page) {
+			tables = append(tables, pageNum)
+		}
+		if findHeadings && pageNum > 1 && pageStartsWithHeading(page) {
+			sections = append(sections, pageNum)
+		}
+	}
+
+	kinds := []struct {
+		reason string
+		pages  []int
+	}{
+		{PreviewReasonSection, sections},
+		{PreviewReasonTable, tables},
+		{PreviewReasonForm, forms},
+	}
+	for _, kind := range kinds {
+		for _, pageNum := range kind.pages {
+			reasons[pageNum] = append(reasons[pageNum], kind.reason)
+		}
+	}
+
+	// First and last pages are always sampled; the other kinds share the remaining slots
+	chosen := map[int]bool{1: true, total: true}
+	counts := make([]int, len(kinds))
+	for i, kind := range kinds {
+		counts[i] = len(kind.pages)
+	}
+	for i, share := range fairShares(counts, previewMaxPages-len(chosen)) {
+		for _, pageNum := range spreadPages(kinds[i].pages, share) {
+			chosen[pageNum] = true
+		}
+	}
+
+	for _, pageNum := range sortedPages(chosen) {
+		selection.Pages = append(selection.Pages, PreviewPage{
+			Page:      pageNum,
+			PageLabel: numbering.Label(pageNum),
+			Reasons:   reasons[pageNum],
+		})
+	}
+	return selection, nil
+}
+
+// pageHasWidgets reports whether a page carries form field widgets
+func pageHasWidgets(page pdf.Page) bool {
+	annots := page.V.Key("Annots")
+	for i := 0; i < annots.Len(); i++ {
+		if annots.Index(i).Key("Subtype").Name() == "Widget" {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedPages returns the pages of a set in ascending order
+func sortedPages(set map[int]bool) []int {
+	pages := make([]int, 0, len(set))
+	for page := range set {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+	return pages
+}
+
+// spreadPages picks n pages spread evenly across a sorted list, keeping its first page
+func spreadPages(pages []int, n int) []int {
+	if n >= len(pages) {
+		return pages
+	}
+	picked := make([]int, n)
+	for i := range picked {
+		picked[i] = pages[i*len(pages)/n]
+	}
+	return picked
+}
+
+// fairShares divides total slots between groups wanting counts[i] slots each, one slot at
+// a time in turn, so that small groups are served in full and the rest share evenly
+func fairShares(counts []int, total int) []int {
+	shares := make([]int, len(counts))
+	for total > 0 {
+		given := false
+		for i := range counts {
+			if total > 0 && shares[i] < counts[i] {
+				shares[i]++
+				total--
+				given = true
+			}
+		}
+		if !given {
+			break
+		}
+	}
+	return shares
+}
+
+// applyPreviewBudget trims an extraction to the preview's element budget. Tables count as
+// one element each. The budget is shared evenly between the sampled pages, and each page
+// keeps its tables first and then its elements in reading order.
+func applyPreviewBudget(result *PDFExtractResult, preview *PreviewSelection) {
+	pageIndex := make(map[int]int, len(result.ProcessedPages))
+	for i, pageNum := range result.ProcessedPages {
+		pageIndex[pageNum] = i
+	}
+
+	counts := make([]int, len(result.ProcessedPages))
+	for _, table := range result.Tables {
+		if i, ok := pageIndex[table.PageNumber]; ok {
+			counts[i]++
+		}
+	}
+	for _, element := range result.Elements {
+		if i, ok := pageIndex[element.PageNumber]; ok {
+			counts[i]++
+		}
+	}
+	shares := fairShares(counts, preview.ElementBudget)
+
+	kept := make([]int, len(shares))
+	keep := func(pageNum int) bool {
+		i, ok := pageIndex[pageNum]
+		if !ok || kept[i] >= shares[i] {
+			preview.ElementsOmitted++
+			return false
+		}
+		kept[i]++
+		return true
+	}
+
+	tables := result.Tables[:0]
+	for _, table := range result.Tables {
+		if keep(table.PageNumber) {
+			tables = append(tables, table)
+		}
+	}
+	result.Tables = tables
+
+	elements := result.Elements[:0]
+	for _, element := range result.Elements {
+		if keep(element.PageNumber) {
+			elements = append(elements, element)
+		}
+	}
+	result.Elements = elements
+}
@@ -0,0 +1,126 @@
+package pdf
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// previewPDFContent builds a 30 page report with sections opening on pages 10 and 20 and a
+// ruled table on page 15
+func previewPDFContent() string {
+	pages := sectionedPages(30, map[int]string{
+		10: "Section 2 Results",
+		20: "Appendix A",
+	})
+	pages[14] = ruledTableContent()
+	return buildTestPDF(pages...)
+}
+
+func TestSelectPreviewPages(t *testing.T) {
+	r := openTestPDF(t, "report.pdf", previewPDFContent())
+
+	selection, err := SelectPreviewPages(r)
+	if err != nil {
+		t.Fatalf("SelectPreviewPages() unexpected error = %v", err)
+	}
+
+	want := []PreviewPage{
+		{Page: 1, Reasons: []string{PreviewReasonFirst}},
+		{Page: 10, Reasons: []string{PreviewReasonSection}},
+		{Page: 15, Reasons: []string{PreviewReasonTable}},
+		{Page: 20, Reasons: []string{PreviewReasonSection}},
+		{Page: 30, Reasons: []string{PreviewReasonLast}},
+	}
+	if selection.TotalPages != 30 || !reflect.DeepEqual(selection.Pages, want) {
+		t.Errorf("SelectPreviewPages() = %d pages %+v, want 30 pages %+v",
+			selection.TotalPages, selection.Pages, want)
+	}
+}
+
+func TestSelectPreviewPages_SpreadsSections(t *testing.T) {
+	sections := make([]int, 0, 40)
+	for page := 3; page <= 80; page += 2 {
+		sections = append(sections, page)
+	}
+	r := openTestPDF(t, "book.pdf", outlinedPDFContent(80, sections...))
+
+	selection, err := SelectPreviewPages(r)
+	if err != nil {
+		t.Fatalf("SelectPreviewPages() unexpected error = %v", err)
+	}
+	if len(selection.Pages) != previewMaxPages {
+		t.Fatalf("SelectPreviewPages() sampled %d pages, want %d", len(selection.Pages), previewMaxPages)
+	}
+	if first, last := selection.Pages[1].Page, selection.Pages[len(selection.Pages)-2].Page; first > 10 || last < 60 {
+		t.Errorf("SelectPreviewPages() sections span pages %d to %d, want them spread across the book", first, last)
+	}
+}
+
+func TestExtractionService_Preview(t *testing.T) {
+	path := createTempFile(t, "report.pdf", previewPDFContent())
+	service := NewExtractionService(10 * 1024 * 1024)
+
+	result, err := service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Mode:   "preview",
+		Config: ExtractConfig{MaxElements: 6},
+	})
+	if err != nil {
+		t.Fatalf("ExtractStructured() unexpected error = %v", err)
+	}
+	if result.Preview == nil {
+		t.Fatal("ExtractStructured() preview result has no preview selection")
+	}
+	if !reflect.DeepEqual(result.ProcessedPages, []int{1, 10, 15, 20, 30}) {
+		t.Errorf("ExtractStructured() processed pages = %v, want the sampled pages", result.ProcessedPages)
+	}
+
+	// The budget is shared so that every sampled page contributes, the table page its table
+	kept := len(result.Elements) + len(result.Tables)
+	if kept != 6 || result.Preview.ElementBudget != 6 || result.Preview.ElementsOmitted == 0 {
+		t.Errorf("ExtractStructured() kept %d elements with budget %d and %d omitted, want 6 kept and some omitted",
+			kept, result.Preview.ElementBudget, result.Preview.ElementsOmitted)
+	}
+	pages := make(map[int]bool)
+	for _, element := range result.Elements {
+		pages[element.PageNumber] = true
+	}
+	for _, table := range result.Tables {
+		pages[table.PageNumber] = true
+	}
+	if len(pages) != 5 {
+		t.Errorf("ExtractStructured() kept elements from pages %v, want all 5 sampled pages", pages)
+	}
+	if len(result.Tables) != 1 || result.Tables[0].PageNumber != 15 {
+		t.Errorf("ExtractStructured() tables = %d, want the table on page 15", len(result.Tables))
+	}
+
+	_, err = service.ExtractStructured(context.Background(), PDFExtractRequest{
+		Path:   path,
+		Mode:   "preview",
+		Config: ExtractConfig{Pages: []int{2}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "preview mode selects its own pages") {
+		t.Errorf("ExtractStructured() error = %v, want pages rejected in preview mode", err)
+	}
+}
+
+func TestFairShares(t *testing.T) {
+	tests := []struct {
+		counts []int
+		total  int
+		want   []int
+	}{
+		{counts: []int{10, 10, 10}, total: 6, want: []int{2, 2, 2}},
+		{counts: []int{1, 10, 10}, total: 9, want: []int{1, 4, 4}},
+		{counts: []int{2, 3}, total: 20, want: []int{2, 3}},
+		{counts: []int{5}, total: 0, want: []int{0}},
+	}
+	for _, tt := range tests {
+		if got := fairShares(tt.counts, tt.total); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fairShares(%v, %d) = %v, want %v", tt.counts, tt.total, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package pdf
+
+// programRun is a run of an external program, such as a page renderer or the OCR engine,
+// in a scratch directory of the host. External programs only see the host's file system,
+// so their inputs are written there and their output read back from there, whatever file
+// system the package otherwise uses. Programs cannot be run on WASM.
+type programRun struct {
+	name   string                    // The program
+	inputs map[string][]byte         // Files written to the scratch directory before the run
+	args   func(dir string) []string // The arguments, given the scratch directory
+	output string                    // A file the run leaves in the scratch directory, if any
+}
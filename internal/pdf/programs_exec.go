@@ -0,0 +1,56 @@
+//go:build !wasm
+
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+)
+
+// lookProgram returns the path of an installed program
+func lookProgram(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+// run runs the program for up to renderTimeout and returns its standard output and the
+// content of its output file, if it names one
+func (r programRun) run() (stdout, output []byte, err error) {
+	dir, err := os.MkdirTemp("", "pdf-"+filepath.Base(r.name)+"-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create a directory for %s: %w", r.name, err)
+	}
+	defer os.RemoveAll(dir)
+	for name, data := range r.inputs {
+		if err := os.WriteFile(filepath.Join(dir, name), data, renderFilePerm); err != nil {
+			return nil, nil, fmt.Errorf("failed to save the input of %s: %w", r.name, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), renderTimeout)
+	defer cancel()
+
+	//nolint:gosec // Programs come from fixed lists, arguments are validated and not shell-interpreted
+	cmd := exec.CommandContext(ctx, r.name, r.args(dir)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if stdout, err = cmd.Output(); err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, pdferrors.New(pdferrors.Timeout, "%s timed out after %s", r.name, renderTimeout)
+		}
+		return nil, nil, fmt.Errorf("%s failed: %w: %s", r.name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if r.output != "" {
+		if output, err = os.ReadFile(filepath.Join(dir, r.output)); err != nil {
+			return nil, nil, fmt.Errorf("%s did not produce %s: %w", r.name, r.output, err)
+		}
+	}
+	return stdout, output, nil
+}
@@ -0,0 +1,19 @@
+//go:build wasm
+
+package pdf
+
+import (
+	"fmt"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+)
+
+// lookProgram reports that no program is installed, since WASM cannot run any
+func lookProgram(name string) (string, error) {
+	return "", fmt.Errorf("%s cannot be run on this platform", name)
+}
+
+// run reports that WASM cannot run external programs
+func (r programRun) run() (stdout, output []byte, err error) {
+	return nil, nil, pdferrors.New(pdferrors.UnsupportedFeature, "%s cannot be run on this platform", r.name)
+}
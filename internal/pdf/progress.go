@@ -0,0 +1,18 @@
+package pdf
+
+import (
+	"context"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// ProgressFunc receives the progress of a long-running operation: done of total steps
+// finished, with a short description of the latest one. Structured extractions count pages
+// and batches count documents.
+type ProgressFunc func(done, total int, message string)
+
+// WithProgress returns a context whose extractions and batches report their progress to
+// report. Reports may come from several goroutines at once.
+func WithProgress(ctx context.Context, report ProgressFunc) context.Context {
+	return extraction.WithProgress(ctx, extraction.ProgressFunc(report))
+}
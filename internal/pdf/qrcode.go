@@ -0,0 +1,766 @@
+package pdf
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// QR code decoding constants
+const (
+	// maxQRVersion is the largest QR version read, 57 modules a side
+	maxQRVersion = 10
+	// qrFormatMask and qrFormatGenerator mask and protect the format information
+	qrFormatMask      = 0x5412
+	qrFormatGenerator = 0x537
+	// maxQRFormatDistance is how many bits of the format information may be misread
+	maxQRFormatDistance = 3
+	// minFinderHits is how many scanlines must cross a finder pattern for it to be taken
+	minFinderHits = 2
+	// qrAlphanumeric is the character set of alphanumeric mode
+	qrAlphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+)
+
+// QR data modes
+const (
+	qrModeTerminator     = 0
+	qrModeNumeric        = 1
+	qrModeAlphanumeric   = 2
+	qrModeStructuredJoin = 3
+	qrModeByte           = 4
+	qrModeFNC1First      = 5
+	qrModeECI            = 7
+	qrModeFNC1Second     = 9
+)
+
+// qrBlocks describes how a QR symbol's codewords split into error correction blocks: the
+// error correction codewords of each block, and the number and data codewords of the
+// blocks of the two groups
+type qrBlocks struct {
+	ecPerBlock    int
+	count1, data1 int
+	count2, data2 int
+}
+
+// qrVersionBlocks gives the blocks of each version from 1 at error correction levels L, M,
+// Q, and H
+var qrVersionBlocks = [maxQRVersion][4]qrBlocks{
+	{{7, 1, 19, 0, 0}, {10, 1, 16, 0, 0}, {13, 1, 13, 0, 0}, {17, 1, 9, 0, 0}},
+	{{10, 1, 34, 0, 0}, {16, 1, 28, 0, 0}, {22, 1, 22, 0, 0}, {28, 1, 16, 0, 0}},
+	{{15, 1, 55, 0, 0}, {26, 1, 44, 0, 0}, {18, 2, 17, 0, 0}, {22, 2, 13, 0, 0}},
+	{{20, 1, 80, 0, 0}, {18, 2, 32, 0, 0}, {26, 2, 24, 0, 0}, {16, 4, 9, 0, 0}},
+	{{26, 1, 108, 0, 0}, {24, 2, 43, 0, 0}, {18, 2, 15, 2, 16}, {22, 2, 11, 2, 12}},
+	{{18, 2, 68, 0, 0}, {16, 4, 27, 0, 0}, {24, 4, 19, 0, 0}, {28, 4, 15, 0, 0}},
+	{{20, 2, 78, 0, 0}, {18, 4, 31, 0, 0}, {18, 2, 14, 4, 15}, {26, 4, 13, 1, 14}},
+	{{24, 2, 97, 0, 0}, {22, 2, 38, 2, 39}, {22, 4, 18, 2, 19}, {26, 4, 14, 2, 15}},
+	{{30, 2, 116, 0, 0}, {22, 3, 36, 2, 37}, {20, 4, 16, 4, 17}, {24, 4, 12, 4, 13}},
+	{{18, 2, 68, 2, 69}, {26, 4, 43, 1, 44}, {24, 6, 19, 2, 20}, {28, 6, 15, 2, 16}},
+}
+
+// qrLevelIndex maps the error correction bits of the format information to the order of
+// qrVersionBlocks
+var qrLevelIndex = [4]int{1, 0, 3, 2}
+
+// qrAlignmentPositions gives the centers of the alignment patterns of each version from 1
+var qrAlignmentPositions = [maxQRVersion][]int{
+	nil, {6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34}, {6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+}
+
+// finderPattern is one of the three squares in the corners of a QR code
+type finderPattern struct {
+	x, y   float64 // Center in pixels
+	module float64 // Module size in pixels
+	hits   int     // Scanlines that crossed it
+}
+
+// qrMatrix holds the modules of a QR code, true where dark
+type qrMatrix struct {
+	dim  int
+	dark []bool
+}
+
+// at reports whether the module at column x and row y is dark
+func (m qrMatrix) at(x, y int) bool {
+	return m.dark[y*m.dim+x]
+}
+
+// decodeQR reads a QR code of up to version 10 from a bitmap. It reports whether finder
+// patterns of a QR code were found even when the code could not be read.
+func decodeQR(b bitmap) (payload string, detected, ok bool) {
+	finders := findFinderPatterns(b)
+	if len(finders) < 3 {
+		return "", false, false
+	}
+	sort.SliceStable(finders, func(i, j int) bool { return finders[i].hits > finders[j].hits })
+	topLeft, topRight, bottomLeft := orderFinders(finders[0], finders[1], finders[2])
+	if !qrCorners(topLeft, topRight, bottomLeft) {
+		return "", false, false
+	}
+
+	matrix, ok := sampleQR(b, topLeft, topRight, bottomLeft)
+	if !ok {
+		return "", true, false
+	}
+	payload, ok = readQR(matrix)
+	return payload, true, ok
+}
+
+// findFinderPatterns finds the dark, light, dark, light, dark runs of widths 1:1:3:1:1
+// that scanlines cross through a finder pattern, checked down the column through their
+// center, and merges those of one pattern
+func findFinderPatterns(b bitmap) []finderPattern {
+	var found []finderPattern
+	for y := 0; y < b.height; y++ {
+		var counts [5]int
+		state := 0
+		for x := 0; x <= b.width; x++ {
+			if x < b.width && b.at(x, y) {
+				if state%2 == 1 {
+					state++
+				}
+				counts[state]++
+				continue
+			}
+			switch {
+			case state%2 == 1:
+				counts[state]++
+			case counts[state] == 0:
+				// Light before the first dark run
+			case state < 4:
+				state++
+				counts[state]++
+			default:
+				if finderRatio(counts) {
+					found = addFinder(found, b, counts, x, y)
+				}
+				counts = [5]int{counts[2], counts[3], counts[4], 1, 0}
+				state = 3
+			}
+		}
+	}
+
+	kept := found[:0]
+	for _, finder := range found {
+		if finder.hits >= minFinderHits {
+			kept = append(kept, finder)
+		}
+	}
+	return kept
+}
+
+// finderRatio reports whether five runs have the 1:1:3:1:1 widths of a finder pattern
+func finderRatio(counts [5]int) bool {
+	total := 0
+	for _, count := range counts {
+		if count == 0 {
+			return false
+		}
+		total += count
+	}
+	if total < 7 {
+		return false
+	}
+	module := float64(total) / 7
+	tolerance := module / 2
+	for i, count := range counts {
+		want := module
+		allowed := tolerance
+		if i == 2 {
+			want, allowed = 3*module, 3*tolerance
+		}
+		if math.Abs(float64(count)-want) >= allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// addFinder checks a finder pattern a row crossed, ending before column x, along its
+// column and again along its row, and adds it to the patterns found or to the one it
+// matches
+func addFinder(found []finderPattern, b bitmap, counts [5]int, x, y int) []finderPattern {
+	centerX := float64(x-counts[4]-counts[3]) - float64(counts[2])/2
+	centerY, height, ok := finderCenter(b.height, func(i int) bool { return b.at(int(centerX), i) }, y)
+	if !ok {
+		return found
+	}
+	centerX, width, ok := finderCenter(b.width, func(i int) bool { return b.at(i, int(centerY)) }, int(centerX))
+	if !ok {
+		return found
+	}
+	module := float64(width+height) / 14
+
+	for i, finder := range found {
+		if math.Abs(finder.x-centerX) <= module && math.Abs(finder.y-centerY) <= module &&
+			math.Abs(finder.module-module) <= max(1, module/2) {
+			hits := float64(finder.hits)
+			found[i] = finderPattern{
+				x:      (finder.x*hits + centerX) / (hits + 1),
+				y:      (finder.y*hits + centerY) / (hits + 1),
+				module: (finder.module*hits + module) / (hits + 1),
+				hits:   finder.hits + 1,
+			}
+			return found
+		}
+	}
+	return append(found, finderPattern{x: centerX, y: centerY, module: module, hits: 1})
+}
+
+// finderCenter reads the runs of a finder pattern along a line through position c and
+// returns the center of the pattern along the line and its width
+func finderCenter(length int, dark func(int) bool, c int) (float64, int, bool) {
+	var counts [5]int
+	i := c
+	for ; i >= 0 && dark(i); i-- {
+		counts[2]++
+	}
+	for ; i >= 0 && !dark(i); i-- {
+		counts[1]++
+	}
+	for ; i >= 0 && dark(i); i-- {
+		counts[0]++
+	}
+	i = c + 1
+	for ; i < length && dark(i); i++ {
+		counts[2]++
+	}
+	for ; i < length && !dark(i); i++ {
+		counts[3]++
+	}
+	for ; i < length && dark(i); i++ {
+		counts[4]++
+	}
+	if !finderRatio(counts) {
+		return 0, 0, false
+	}
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return float64(i-counts[4]-counts[3]) - float64(counts[2])/2, total, true
+}
+
+// orderFinders returns three finder patterns as the top-left one, which lies opposite the
+// longest side of the triangle they make, and the top-right and bottom-left ones
+func orderFinders(a, b, c finderPattern) (topLeft, topRight, bottomLeft finderPattern) {
+	distance := func(p, q finderPattern) float64 { return math.Hypot(p.x-q.x, p.y-q.y) }
+	ab, bc, ac := distance(a, b), distance(b, c), distance(a, c)
+	switch {
+	case bc >= ab && bc >= ac:
+		topLeft, topRight, bottomLeft = a, b, c
+	case ac >= ab && ac >= bc:
+		topLeft, topRight, bottomLeft = b, a, c
+	default:
+		topLeft, topRight, bottomLeft = c, a, b
+	}
+	// Going from the top-right to the bottom-left pattern turns clockwise in image space
+	if (topRight.x-topLeft.x)*(bottomLeft.y-topLeft.y)-(topRight.y-topLeft.y)*(bottomLeft.x-topLeft.x) < 0 {
+		topRight, bottomLeft = bottomLeft, topRight
+	}
+	return topLeft, topRight, bottomLeft
+}
+
+// qrCorners reports whether three finder patterns of about one module size sit at the
+// corners of a square, as a QR code's do, rather than where texture happened to match them
+func qrCorners(topLeft, topRight, bottomLeft finderPattern) bool {
+	smallest := math.Min(topLeft.module, math.Min(topRight.module, bottomLeft.module))
+	largest := math.Max(topLeft.module, math.Max(topRight.module, bottomLeft.module))
+	acrossX, acrossY := topRight.x-topLeft.x, topRight.y-topLeft.y
+	downX, downY := bottomLeft.x-topLeft.x, bottomLeft.y-topLeft.y
+	across, down := math.Hypot(acrossX, acrossY), math.Hypot(downX, downY)
+	if across == 0 || down == 0 || largest > smallest*1.3 {
+		return false
+	}
+	cosine := (acrossX*downX + acrossY*downY) / (across * down)
+	return math.Abs(cosine) < 0.2 && math.Max(across, down) < math.Min(across, down)*1.2 &&
+		math.Min(across, down) >= 7*smallest
+}
+
+// sampleQR reads the modules of a QR code from the bitmap, mapping module positions to
+// pixels through the centers of its finder patterns
+func sampleQR(b bitmap, topLeft, topRight, bottomLeft finderPattern) (qrMatrix, bool) {
+	module := (topLeft.module + topRight.module + bottomLeft.module) / 3
+	across := math.Hypot(topRight.x-topLeft.x, topRight.y-topLeft.y)
+	down := math.Hypot(bottomLeft.x-topLeft.x, bottomLeft.y-topLeft.y)
+	dim := int(math.Round((across+down)/2/module)) + 7
+	switch dim % 4 {
+	case 0:
+		dim++
+	case 2:
+		dim--
+	case 3:
+		return qrMatrix{}, false
+	}
+	if version := (dim - 17) / 4; version < 1 || version > maxQRVersion {
+		return qrMatrix{}, false
+	}
+
+	// Finder pattern centers lie 3.5 modules in from the edges
+	span := float64(dim - 7)
+	matrix := qrMatrix{dim: dim, dark: make([]bool, dim*dim)}
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			u, v := (float64(x)-3)/span, (float64(y)-3)/span
+			px := topLeft.x + u*(topRight.x-topLeft.x) + v*(bottomLeft.x-topLeft.x)
+			py := topLeft.y + u*(topRight.y-topLeft.y) + v*(bottomLeft.y-topLeft.y)
+			matrix.dark[y*dim+x] = b.at(int(math.Floor(px)), int(math.Floor(py)))
+		}
+	}
+	return matrix, true
+}
+
+// readQR decodes the modules of a QR code: its format information, its codewords with
+// their errors corrected, and the text they encode
+func readQR(m qrMatrix) (string, bool) {
+	version := (m.dim - 17) / 4
+	level, mask, ok := readQRFormat(m)
+	if !ok {
+		return "", false
+	}
+	blocks := qrVersionBlocks[version-1][qrLevelIndex[level]]
+	ec := blocks.ecPerBlock
+	total := blocks.count1*(blocks.data1+ec) + blocks.count2*(blocks.data2+ec)
+	codewords := readQRCodewords(m, version, mask)
+	if len(codewords) < total {
+		return "", false
+	}
+
+	// Blocks are interleaved codeword by codeword, the longer blocks last
+	count := blocks.count1 + blocks.count2
+	dataLength := func(block int) int {
+		if block < blocks.count1 {
+			return blocks.data1
+		}
+		return blocks.data2
+	}
+	interleaved := make([][]byte, count)
+	next := 0
+	for i := 0; i < max(blocks.data1, blocks.data2); i++ {
+		for block := range interleaved {
+			if i < dataLength(block) {
+				interleaved[block] = append(interleaved[block], codewords[next])
+				next++
+			}
+		}
+	}
+	for i := 0; i < ec; i++ {
+		for block := range interleaved {
+			interleaved[block] = append(interleaved[block], codewords[next])
+			next++
+		}
+	}
+
+	var data []byte
+	for block, codeword := range interleaved {
+		if !correctQRErrors(codeword, ec) {
+			return "", false
+		}
+		data = append(data, codeword[:dataLength(block)]...)
+	}
+	return parseQRData(data, version)
+}
+
+// readQRFormat reads the error correction level and mask from either copy of the format
+// information, taking the valid format closest to what was read
+func readQRFormat(m qrMatrix) (level, mask int, ok bool) {
+	var first, second int
+	read := func(bits *int, x, y int) {
+		*bits <<= 1
+		if m.at(x, y) {
+			*bits |= 1
+		}
+	}
+	for x := 0; x <= 5; x++ {
+		read(&first, x, 8)
+	}
+	read(&first, 7, 8)
+	read(&first, 8, 8)
+	read(&first, 8, 7)
+	for y := 5; y >= 0; y-- {
+		read(&first, 8, y)
+	}
+	for y := m.dim - 1; y >= m.dim-7; y-- {
+		read(&second, 8, y)
+	}
+	for x := m.dim - 8; x < m.dim; x++ {
+		read(&second, x, 8)
+	}
+
+	best, bestDistance := -1, maxQRFormatDistance+1
+	for format := 0; format < 32; format++ {
+		code := qrFormatCode(format)
+		for _, bitsRead := range []int{first, second} {
+			if distance := bits.OnesCount(uint(code ^ bitsRead)); distance < bestDistance {
+				best, bestDistance = format, distance
+			}
+		}
+	}
+	if best < 0 {
+		return 0, 0, false
+	}
+	return best >> 3, best & 7, true
+}
+
+// qrFormatCode returns the masked 15-bit BCH code of five bits of format information
+func qrFormatCode(format int) int {
+	code := format << 10
+	for bit := 14; bit >= 10; bit-- {
+		if code&(1<<bit) != 0 {
+			code ^= qrFormatGenerator << (bit - 10)
+		}
+	}
+	return (format<<10 | code) ^ qrFormatMask
+}
+
+// qrFunctionModules marks the modules of a QR code that hold its finder, timing, and
+// alignment patterns and its format and version information rather than data
+func qrFunctionModules(dim, version int) []bool {
+	function := make([]bool, dim*dim)
+	mark := func(left, top, width, height int) {
+		for y := top; y < top+height; y++ {
+			for x := left; x < left+width; x++ {
+				function[y*dim+x] = true
+			}
+		}
+	}
+	mark(0, 0, 9, 9)
+	mark(dim-8, 0, 8, 9)
+	mark(0, dim-8, 9, 8)
+	positions := qrAlignmentPositions[version-1]
+	last := len(positions) - 1
+	for i, y := range positions {
+		for j, x := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue // Under a finder pattern
+			}
+			mark(x-2, y-2, 5, 5)
+		}
+	}
+	mark(6, 9, 1, dim-17)
+	mark(9, 6, dim-17, 1)
+	if version >= 7 {
+		mark(dim-11, 0, 3, 6)
+		mark(0, dim-11, 6, 3)
+	}
+	return function
+}
+
+// qrMasked reports whether a mask pattern inverts the module at a row and column
+func qrMasked(mask, row, column int) bool {
+	switch mask {
+	case 0:
+		return (row+column)%2 == 0
+	case 1:
+		return row%2 == 0
+	case 2:
+		return column%3 == 0
+	case 3:
+		return (row+column)%3 == 0
+	case 4:
+		return (row/2+column/3)%2 == 0
+	case 5:
+		return (row*column)%2+(row*column)%3 == 0
+	case 6:
+		return ((row*column)%2+(row*column)%3)%2 == 0
+	default:
+		return ((row+column)%2+(row*column)%3)%2 == 0
+	}
+}
+
+// readQRCodewords reads the data modules, unmasked, in their zigzag order: up and down
+// column pairs from the right, skipping the vertical timing pattern
+func readQRCodewords(m qrMatrix, version, mask int) []byte {
+	function := qrFunctionModules(m.dim, version)
+	var codewords []byte
+	var current byte
+	read := 0
+	up := true
+	for right := m.dim - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for i := 0; i < m.dim; i++ {
+			row := i
+			if up {
+				row = m.dim - 1 - i
+			}
+			for column := right; column > right-2; column-- {
+				if function[row*m.dim+column] {
+					continue
+				}
+				current <<= 1
+				if m.at(column, row) != qrMasked(mask, row, column) {
+					current |= 1
+				}
+				if read++; read == 8 {
+					codewords = append(codewords, current)
+					current, read = 0, 0
+				}
+			}
+		}
+		up = !up
+	}
+	return codewords
+}
+
+// galoisField is GF(256) with the QR code polynomial x^8 + x^4 + x^3 + x^2 + 1
+type galoisField struct {
+	exp [510]byte
+	log [256]int
+}
+
+// qrField is the field of QR code error correction
+var qrField = func() *galoisField {
+	f := &galoisField{}
+	x := 1
+	for i := 0; i < 255; i++ {
+		f.exp[i], f.exp[i+255] = byte(x), byte(x)
+		f.log[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	return f
+}()
+
+func (f *galoisField) mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return f.exp[f.log[a]+f.log[b]]
+}
+
+func (f *galoisField) div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return f.exp[f.log[a]+255-f.log[b]]
+}
+
+// power returns a to the power n
+func (f *galoisField) power(a byte, n int) byte {
+	if a == 0 {
+		return 0
+	}
+	return f.exp[f.log[a]*n%255]
+}
+
+// eval evaluates a polynomial, lowest degree first, at x
+func (f *galoisField) eval(poly []byte, x byte) byte {
+	var result byte
+	for i := len(poly) - 1; i >= 0; i-- {
+		result = f.mul(result, x) ^ poly[i]
+	}
+	return result
+}
+
+// correctQRErrors corrects a Reed-Solomon codeword, highest degree first, with ec error
+// correction codewords in place, reporting false when it has more errors than can be
+// corrected
+func correctQRErrors(codeword []byte, ec int) bool {
+	f := qrField
+	syndromes := make([]byte, ec)
+	clean := true
+	for i := range syndromes {
+		var s byte
+		for _, c := range codeword {
+			s = f.mul(s, f.exp[i]) ^ c
+		}
+		syndromes[i] = s
+		clean = clean && s == 0
+	}
+	if clean {
+		return true
+	}
+
+	// Berlekamp-Massey finds the error locator
+	locator, previous := []byte{1}, []byte{1}
+	errors, shift := 0, 1
+	lastDiscrepancy := byte(1)
+	for step := 0; step < ec; step++ {
+		discrepancy := syndromes[step]
+		for i := 1; i < len(locator) && i <= step; i++ {
+			discrepancy ^= f.mul(locator[i], syndromes[step-i])
+		}
+		if discrepancy == 0 {
+			shift++
+			continue
+		}
+		scale := f.div(discrepancy, lastDiscrepancy)
+		next := append([]byte(nil), locator...)
+		for len(next) < len(previous)+shift {
+			next = append(next, 0)
+		}
+		for i, c := range previous {
+			next[i+shift] ^= f.mul(scale, c)
+		}
+		if 2*errors <= step {
+			previous, errors, lastDiscrepancy, shift = locator, step+1-errors, discrepancy, 1
+		} else {
+			shift++
+		}
+		locator = next
+	}
+	if 2*errors > ec {
+		return false
+	}
+
+	// Forney's formula gives the error values at the roots of the locator
+	evaluator := make([]byte, ec)
+	for i := range evaluator {
+		for j := 0; j <= i && j < len(locator); j++ {
+			evaluator[i] ^= f.mul(locator[j], syndromes[i-j])
+		}
+	}
+	found := 0
+	for position := range codeword {
+		degree := (len(codeword) - 1 - position) % 255
+		inverse := f.exp[(255-degree)%255]
+		if f.eval(locator, inverse) != 0 {
+			continue
+		}
+		var derivative byte
+		for i := 1; i < len(locator); i += 2 {
+			derivative ^= f.mul(locator[i], f.power(inverse, i-1))
+		}
+		if derivative == 0 {
+			return false
+		}
+		codeword[position] ^= f.mul(f.exp[degree], f.div(f.eval(evaluator, inverse), derivative))
+		found++
+	}
+	return found == errors
+}
+
+// qrBitReader reads the bits of QR data codewords, most significant first
+type qrBitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *qrBitReader) remaining() int {
+	return len(r.data)*8 - r.pos
+}
+
+func (r *qrBitReader) read(n int) (int, bool) {
+	if n > r.remaining() {
+		return 0, false
+	}
+	value := 0
+	for i := 0; i < n; i++ {
+		value = value<<1 | int(r.data[r.pos/8]>>(7-r.pos%8)&1)
+		r.pos++
+	}
+	return value, true
+}
+
+// qrCountBits returns the width of the character count of a mode in a version
+func qrCountBits(mode, version int) int {
+	large := version >= 10
+	switch {
+	case mode == qrModeNumeric && large:
+		return 12
+	case mode == qrModeNumeric:
+		return 10
+	case mode == qrModeAlphanumeric && large:
+		return 11
+	case mode == qrModeAlphanumeric:
+		return 9
+	case large:
+		return 16
+	default:
+		return 8
+	}
+}
+
+// parseQRData decodes the numeric, alphanumeric, and byte segments of QR data codewords.
+// Bytes are read as UTF-8 when valid and as ISO-8859-1 otherwise; Kanji segments are not
+// supported.
+func parseQRData(data []byte, version int) (string, bool) {
+	r := &qrBitReader{data: data}
+	var text []byte
+	for r.remaining() >= 4 {
+		mode, _ := r.read(4)
+		switch mode {
+		case qrModeTerminator:
+			return qrText(text), true
+		case qrModeNumeric:
+			count, ok := r.read(qrCountBits(mode, version))
+			for ok && count > 0 {
+				digits := min(count, 3)
+				var value int
+				if value, ok = r.read(digits*3 + 1); ok {
+					digitText := strings.Repeat("0", digits) + strconv.Itoa(value)
+					text = append(text, digitText[len(digitText)-digits:]...)
+				}
+				count -= digits
+			}
+			if !ok {
+				return "", false
+			}
+		case qrModeAlphanumeric:
+			count, ok := r.read(qrCountBits(mode, version))
+			for ok && count > 0 {
+				var value int
+				if count >= 2 {
+					if value, ok = r.read(11); ok && value/45 < len(qrAlphanumeric) {
+						text = append(text, qrAlphanumeric[value/45], qrAlphanumeric[value%45])
+					}
+					count -= 2
+				} else {
+					if value, ok = r.read(6); ok && value < len(qrAlphanumeric) {
+						text = append(text, qrAlphanumeric[value])
+					}
+					count--
+				}
+			}
+			if !ok {
+				return "", false
+			}
+		case qrModeByte:
+			count, ok := r.read(qrCountBits(mode, version))
+			for ; ok && count > 0; count-- {
+				var value int
+				if value, ok = r.read(8); ok {
+					text = append(text, byte(value))
+				}
+			}
+			if !ok {
+				return "", false
+			}
+		case qrModeECI:
+			designator, _ := r.read(8)
+			switch {
+			case designator&0x80 == 0:
+			case designator&0xC0 == 0x80:
+				r.read(8)
+			default:
+				r.read(16)
+			}
+		case qrModeStructuredJoin:
+			r.read(16)
+		case qrModeFNC1First:
+		case qrModeFNC1Second:
+			r.read(8)
+		default:
+			return "", false
+		}
+	}
+	return qrText(text), true
+}
+
+// qrText returns QR byte data as text, reading it as ISO-8859-1 unless it is valid UTF-8
+func qrText(data []byte) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -0,0 +1,94 @@
+package pdf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// queryContentTypes lists the element types a content query can filter on
+var queryContentTypes = []extraction.ContentType{
+	extraction.ContentTypeText,
+	extraction.ContentTypeImage,
+	extraction.ContentTypeVector,
+	extraction.ContentTypeForm,
+	extraction.ContentTypeAnnotation,
+	extraction.ContentTypeStructural,
+	extraction.ContentTypeList,
+}
+
+// Validate checks that a content query can be run: known content types, valid page numbers,
+// confidence and area, and a text query that compiles when it is a regular expression
+func (q ContentQuery) Validate() error {
+	for _, contentType := range q.ContentTypes {
+		if !isQueryContentType(contentType) {
+			names := make([]string, len(queryContentTypes))
+			for i, known := range queryContentTypes {
+				names[i] = string(known)
+			}
+			return fmt.Errorf("invalid query: unknown content type %q (must be one of: %s)",
+				contentType, strings.Join(names, ", "))
+		}
+	}
+
+	for _, page := range q.Pages {
+		if page < 1 {
+			return fmt.Errorf("invalid query: page numbers must be 1 or greater, got %d", page)
+		}
+	}
+
+	if q.MinConfidence < 0 || q.MinConfidence > 1 {
+		return fmt.Errorf("invalid query: min_confidence must be between 0 and 1, got %g", q.MinConfidence)
+	}
+
+	if q.BoundingBox != nil && (q.BoundingBox.Width < 0 || q.BoundingBox.Height < 0) {
+		return fmt.Errorf("invalid query: bounding_box width and height cannot be negative")
+	}
+
+	if _, err := extraction.NewTextMatcher(q.TextQuery, q.Regex, q.CaseSensitive); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	return nil
+}
+
+func isQueryContentType(contentType string) bool {
+	for _, known := range queryContentTypes {
+		if contentType == string(known) {
+			return true
+		}
+	}
+	return false
+}
+
+// newMatchCounter returns a function counting the text query's matches in a string and
+// their total length, following the query's regex and case options. It returns nil when
+// the query has no text.
+func newMatchCounter(q ContentQuery) (func(string) (int, int), error) {
+	if q.TextQuery == "" {
+		return nil, nil
+	}
+
+	pattern := regexp.QuoteMeta(q.TextQuery)
+	if q.Regex {
+		pattern = q.TextQuery
+	}
+	if !q.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: invalid regular expression %q: %w", q.TextQuery, err)
+	}
+
+	return func(text string) (int, int) {
+		count, length := 0, 0
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			count++
+			length += loc[1] - loc[0]
+		}
+		return count, length
+	}, nil
+}
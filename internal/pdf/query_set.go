@@ -0,0 +1,167 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Query set limits
+const (
+	maxQuerySetFiles       = 20 // Documents accepted in one query set
+	querySetWorkers        = 4  // Documents queried concurrently
+	defaultQuerySetResults = 50 // Ranked matches returned when MaxResults is unset
+	querySetScorePrecision = 1e4
+)
+
+// QuerySet runs a content query across a list of documents concurrently, reporting the matches
+// of each document and a global ranking of the best matches across the whole set. Documents
+// that cannot be queried are reported in their file entry and do not fail the set.
+func (s *Service) QuerySet(ctx context.Context, req PDFQuerySetRequest) (*PDFQuerySetResult, error) {
+	if len(req.Paths) == 0 {
+		return nil, fmt.Errorf("paths cannot be empty")
+	}
+	if len(req.Paths) > maxQuerySetFiles {
+		return nil, fmt.Errorf("too many paths: %d (max: %d)", len(req.Paths), maxQuerySetFiles)
+	}
+
+	seen := make(map[string]bool, len(req.Paths))
+	for _, path := range req.Paths {
+		if path == "" {
+			return nil, fmt.Errorf("path cannot be empty")
+		}
+		if seen[path] {
+			return nil, fmt.Errorf("duplicate path: %s", path)
+		}
+		seen[path] = true
+	}
+
+	if err := req.Query.Validate(); err != nil {
+		return nil, err
+	}
+	countMatches, err := newMatchCounter(req.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	maxResults := req.MaxResults
+	if maxResults <= 0 {
+		maxResults = defaultQuerySetResults
+	}
+
+	results := make([]*PDFQueryResult, len(req.Paths))
+	errs := make([]error, len(req.Paths))
+
+	// Progress counts documents, like that of batches
+	fileCtx := extraction.WithProgress(ctx, nil)
+	var done atomic.Int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, querySetWorkers)
+	for i, path := range req.Paths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = s.QueryContent(fileCtx, PDFQueryContentRequest{Path: path, Query: req.Query})
+			extraction.ReportProgress(ctx, int(done.Add(1)), len(req.Paths), fmt.Sprintf("queried %s", filepath.Base(path)))
+		}(i, path)
+	}
+	wg.Wait()
+
+	result := &PDFQuerySetResult{
+		Query:   req.Query,
+		Files:   make([]QuerySetFileResult, len(req.Paths)),
+		Matches: []QuerySetMatch{},
+	}
+
+	for i, path := range req.Paths {
+		file := QuerySetFileResult{Path: path}
+		if errs[i] != nil {
+			file.Error = errs[i].Error()
+			result.FilesFailed++
+			result.Files[i] = file
+			continue
+		}
+
+		file.MatchCount = results[i].MatchCount
+		file.Summary = results[i].Summary
+		for _, element := range results[i].Elements {
+			score := scoreQueryMatch(element, countMatches)
+			file.TopScore = max(file.TopScore, score)
+			result.Matches = append(result.Matches, QuerySetMatch{Path: path, Score: score, Element: element})
+		}
+
+		result.TotalMatches += file.MatchCount
+		if file.MatchCount > 0 {
+			result.FilesMatched++
+		}
+		result.Files[i] = file
+	}
+
+	// Stable sort keeps request and document order among equally scored matches
+	sort.SliceStable(result.Matches, func(a, b int) bool {
+		return result.Matches[a].Score > result.Matches[b].Score
+	})
+	if len(result.Matches) > maxResults {
+		result.Matches = result.Matches[:maxResults]
+	}
+	for i := range result.Matches {
+		result.Matches[i].Rank = i + 1
+	}
+
+	return result, nil
+}
+
+// scoreQueryMatch ranks a match by how often the query text occurs in it, weighted by the
+// element's confidence. Match density breaks ties so that a heading naming the query ranks
+// above a long paragraph that mentions it once. Without a text query the confidence is the score.
+func scoreQueryMatch(element ContentElement, countMatches func(string) (int, int)) float64 {
+	confidence := element.Confidence
+	if confidence <= 0 {
+		confidence = 1
+	}
+
+	text := queryElementText(element)
+	if countMatches == nil || text == "" {
+		return roundScore(confidence)
+	}
+
+	occurrences, matched := countMatches(text)
+	density := float64(matched) / float64(len(text))
+	return roundScore(confidence * (float64(occurrences) + density))
+}
+
+// queryElementText returns the searchable text of a converted content element
+func queryElementText(element ContentElement) string {
+	switch content := element.Content.(type) {
+	case string:
+		return content
+	case extraction.TextElement:
+		return content.Text
+	case extraction.AnnotationElement:
+		return content.Content
+	case extraction.FigureElement:
+		return content.Caption
+	case ListElement:
+		texts := make([]string, len(content.Items))
+		for i, item := range content.Items {
+			texts[i] = item.Text
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
+// roundScore keeps scores readable in responses
+func roundScore(score float64) float64 {
+	return math.Round(score*querySetScorePrecision) / querySetScorePrecision
+}
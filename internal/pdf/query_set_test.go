@@ -0,0 +1,109 @@
+package pdf
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestService_QuerySetErrors(t *testing.T) {
+	service := NewService(100 * 1024 * 1024)
+
+	tests := []struct {
+		name     string
+		req      PDFQuerySetRequest
+		errorMsg string
+	}{
+		{
+			name:     "no paths",
+			req:      PDFQuerySetRequest{Query: ContentQuery{TextQuery: "renewal"}},
+			errorMsg: "paths cannot be empty",
+		},
+		{
+			name:     "empty path",
+			req:      PDFQuerySetRequest{Paths: []string{""}},
+			errorMsg: "path cannot be empty",
+		},
+		{
+			name:     "duplicate path",
+			req:      PDFQuerySetRequest{Paths: []string{"/a.pdf", "/a.pdf"}},
+			errorMsg: "duplicate path",
+		},
+		{
+			name:     "too many paths",
+			req:      PDFQuerySetRequest{Paths: make([]string, maxQuerySetFiles+1)},
+			errorMsg: "too many paths",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.QuerySet(context.Background(), tt.req)
+			if err == nil {
+				t.Fatal("QuerySet() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("QuerySet() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestService_QuerySet(t *testing.T) {
+	service := NewService(100 * 1024 * 1024)
+
+	heading := createTempFile(t, "heading.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (Renewal Terms) Tj ET",
+	))
+	body := createTempFile(t, "body.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (Introduction) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (This agreement continues until the renewal date set by the parties) Tj ET",
+	))
+	unrelated := createTempFile(t, "unrelated.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (Quarterly report) Tj ET",
+	))
+	missing := "/non/existent/file.pdf"
+
+	result, err := service.QuerySet(context.Background(), PDFQuerySetRequest{
+		Paths: []string{body, unrelated, missing, heading},
+		Query: ContentQuery{TextQuery: "renewal"},
+	})
+	if err != nil {
+		t.Fatalf("QuerySet() unexpected error = %v", err)
+	}
+
+	if len(result.Files) != 4 || result.Files[0].Path != body || result.Files[3].Path != heading {
+		t.Fatalf("QuerySet() files = %+v, want one entry per path in request order", result.Files)
+	}
+	if result.FilesMatched != 2 || result.FilesFailed != 1 || result.TotalMatches != 2 {
+		t.Errorf("QuerySet() matched %d files (%d failed, %d matches), want 2 (1 failed, 2 matches)",
+			result.FilesMatched, result.FilesFailed, result.TotalMatches)
+	}
+	if result.Files[1].MatchCount != 0 || !strings.Contains(result.Files[2].Error, "does not exist") {
+		t.Errorf("QuerySet() files = %+v, want no matches in unrelated.pdf and an error for the missing file",
+			result.Files)
+	}
+
+	if len(result.Matches) != 2 {
+		t.Fatalf("QuerySet() returned %d ranked matches, want 2", len(result.Matches))
+	}
+	top := result.Matches[0]
+	if top.Rank != 1 || top.Path != heading || top.Score <= result.Matches[1].Score {
+		t.Errorf("QuerySet() top match = %+v, want the dense heading match ranked first", top)
+	}
+	if result.Matches[1].Path != body || result.Matches[1].Element.PageNumber != 2 {
+		t.Errorf("QuerySet() second match = %+v, want body.pdf page 2", result.Matches[1])
+	}
+
+	limited, err := service.QuerySet(context.Background(), PDFQuerySetRequest{
+		Paths:      []string{body, heading},
+		Query:      ContentQuery{TextQuery: "renewal"},
+		MaxResults: 1,
+	})
+	if err != nil {
+		t.Fatalf("QuerySet() unexpected error = %v", err)
+	}
+	if len(limited.Matches) != 1 || limited.TotalMatches != 2 {
+		t.Errorf("QuerySet() with MaxResults 1 = %d matches of %d, want 1 of 2", len(limited.Matches), limited.TotalMatches)
+	}
+}
@@ -0,0 +1,126 @@
+package pdf
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestContentQuery_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    ContentQuery
+		errorMsg string
+	}{
+		{
+			name:  "valid query",
+			query: ContentQuery{TextQuery: `total: \$\d+`, Regex: true, ContentTypes: []string{"text"}, Pages: []int{1}},
+		},
+		{
+			name:     "unknown content type",
+			query:    ContentQuery{ContentTypes: []string{"table"}},
+			errorMsg: "unknown content type",
+		},
+		{
+			name:     "invalid page",
+			query:    ContentQuery{Pages: []int{0}},
+			errorMsg: "page numbers must be 1 or greater",
+		},
+		{
+			name:     "confidence out of range",
+			query:    ContentQuery{MinConfidence: -0.1},
+			errorMsg: "min_confidence",
+		},
+		{
+			name:     "negative area",
+			query:    ContentQuery{BoundingBox: &Rectangle{Width: -10, Height: 10}},
+			errorMsg: "bounding_box",
+		},
+		{
+			name:     "invalid regular expression",
+			query:    ContentQuery{TextQuery: "total: (", Regex: true},
+			errorMsg: "invalid regular expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestExtractionService_QueryContentOperators(t *testing.T) {
+	service := NewExtractionService(100 * 1024 * 1024)
+	path := createTempFile(t, "invoice.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 720 Td (Invoice INV-1001) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (invoice total due) Tj ET",
+	))
+
+	tests := []struct {
+		name      string
+		query     ContentQuery
+		wantPages []int
+	}{
+		{
+			name:      "case-insensitive text",
+			query:     ContentQuery{TextQuery: "INVOICE"},
+			wantPages: []int{1, 2},
+		},
+		{
+			name:      "case-sensitive text",
+			query:     ContentQuery{TextQuery: "Invoice", CaseSensitive: true},
+			wantPages: []int{1},
+		},
+		{
+			name:      "regular expression",
+			query:     ContentQuery{TextQuery: `INV-\d{4}`, Regex: true},
+			wantPages: []int{1},
+		},
+		{
+			name:      "page filter",
+			query:     ContentQuery{TextQuery: "invoice", Pages: []int{2}},
+			wantPages: []int{2},
+		},
+		{
+			name:  "area filter",
+			query: ContentQuery{TextQuery: "invoice", BoundingBox: &Rectangle{X: 0, Y: 0, Width: 612, Height: 100}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.QueryContent(context.Background(), PDFQueryRequest{Path: path, Query: tt.query})
+			if err != nil {
+				t.Fatalf("QueryContent() unexpected error = %v", err)
+			}
+
+			var pages []int
+			for _, element := range result.Elements {
+				pages = append(pages, element.PageNumber)
+			}
+			if len(pages) != len(tt.wantPages) {
+				t.Fatalf("QueryContent() matched pages %v, want %v", pages, tt.wantPages)
+			}
+			for i := range pages {
+				if pages[i] != tt.wantPages[i] {
+					t.Errorf("QueryContent() matched pages %v, want %v", pages, tt.wantPages)
+				}
+			}
+		})
+	}
+
+	_, err := service.QueryContent(context.Background(), PDFQueryRequest{Path: path, Query: ContentQuery{TextQuery: "(", Regex: true}})
+	if err == nil || !strings.Contains(err.Error(), "invalid query") {
+		t.Errorf("QueryContent() error = %v, want invalid query error", err)
+	}
+}
@@ -1,10 +1,14 @@
 package pdf
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
 	"github.com/ledongthuc/pdf"
 )
 
@@ -12,6 +16,8 @@ import (
 type Reader struct {
 	maxFileSize int64
 	maxTextSize int
+	memoryMap   bool
+	engine      *extraction.DefaultEngine // Detects tables for the Markdown layout
 }
 
 // NewReader creates a new PDF reader with the specified constraints
@@ -19,54 +25,99 @@ func NewReader(maxFileSize int64) *Reader {
 	return &Reader{
 		maxFileSize: maxFileSize,
 		maxTextSize: 10 * 1024 * 1024, // 10MB text limit
+		engine:      extraction.NewEngineWithConfig(maxFileSize, maxFileSize, false),
 	}
 }
 
+// SetMemoryMapping enables reading documents through a memory mapping where the platform supports it
+func (r *Reader) SetMemoryMapping(enabled bool) {
+	r.memoryMap = enabled
+	r.engine.SetMemoryMapping(enabled)
+}
+
 // ReadFile extracts text content from a PDF file
 func (r *Reader) ReadFile(req PDFReadFileRequest) (*PDFReadFileResult, error) {
 	if req.Path == "" {
 		return nil, fmt.Errorf("path cannot be empty")
 	}
-
-	// Check if file exists and get basic info
-	fileInfo, err := os.Stat(req.Path)
-	if os.IsNotExist(err) {
-		return nil, fmt.Errorf("file does not exist: %s", req.Path)
+	if !validLayout(req.Layout) {
+		return nil, layoutError(req.Layout)
 	}
-	if err != nil {
-		return nil, fmt.Errorf("cannot access file: %w", err)
+	if len(req.Pages) > 0 && (req.FirstPages != 0 || req.LastPages != 0) {
+		return nil, fmt.Errorf("pages cannot be combined with first_pages or last_pages")
 	}
-
-	// Validate file type
-	if err := r.validatePDFFile(req.Path, fileInfo); err != nil {
+	if err := validateRegion(req.Region); err != nil {
 		return nil, err
 	}
 
-	// Open and parse PDF
-	f, pdfReader, err := pdf.Open(req.Path)
+	// Open and parse PDF; the markdown layout's table detection reads the same document
+	ctx, release := core.WithDocuments(context.Background())
+	defer release()
+	doc, err := core.OpenShared(ctx, req.Path, core.Options{MemoryMap: r.memoryMap, Validate: r.validatePDFFile})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open PDF: %w", err)
+		return nil, err
+	}
+	defer doc.Close()
+	pdfReader := doc.Reader
+
+	// Restrict reading to the listed pages, or the opening and closing pages, when requested
+	numbering := extraction.NewPageNumbering(pdfReader)
+	var selection *PageSelection
+	switch {
+	case len(req.Pages) > 0:
+		if selection, err = selectListedPages(req.Pages, numbering.Count()); err == nil {
+			selection.addLabels(numbering)
+		}
+	case req.FirstPages != 0 || req.LastPages != 0:
+		selection, err = SelectPages(pdfReader, req.FirstPages, req.LastPages)
+	}
+	if err != nil {
+		return nil, err
 	}
-	defer f.Close()
 
-	// Extract text content
-	content, err := r.extractTextContent(pdfReader)
+	// Extract text content in the requested layout
+	var selected []int
+	if selection != nil {
+		selected = selection.Pages
+	}
+	pageText, err := r.layoutPageText(ctx, req.Path, numbering, req.Layout, selected, req.Region)
+	if err != nil {
+		return nil, err
+	}
+	var removed []RepeatedLine
+	if req.StripHeadersFooters {
+		pageText = withoutRepeatedLines(pageText, numbering, selected, &removed)
+	}
+	_, members := isPortfolio(pdfReader)
+	content, err := r.extractTextContent(numbering, selection, pageText)
+	if err != nil && members > 0 {
+		return nil, pdferrors.New(pdferrors.InvalidArgument, "%s is a PDF portfolio of %d files and its cover "+
+			"sheet has no text: list them with pdf_portfolio and read one as %s%s<name>",
+			req.Path, members, req.Path, PortfolioMemberSeparator)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract text content: %w", err)
 	}
 
 	// Analyze content type and detect images
-	contentType := r.analyzeContentType(content, pdfReader)
-	hasImages, imageCount := r.detectImages(pdfReader)
+	contentType := r.analyzeContentType(content, numbering)
+	hasImages, imageCount := r.detectImages(numbering)
 
 	result := &PDFReadFileResult{
 		Content:     content,
 		Path:        req.Path,
-		Pages:       pdfReader.NumPage(),
-		Size:        fileInfo.Size(),
+		Pages:       numbering.Count(),
+		Size:        doc.Info().Size(),
 		ContentType: contentType,
 		HasImages:   hasImages,
 		ImageCount:  imageCount,
+		Layout:      req.Layout,
+
+		PageSelection: selection,
+		Region:        req.Region,
+		RepeatedLines: removed,
+
+		PortfolioMembers: members,
 	}
 
 	return result, nil
@@ -76,35 +127,51 @@ func (r *Reader) ReadFile(req PDFReadFileRequest) (*PDFReadFileResult, error) {
 func (r *Reader) validatePDFFile(filePath string, fileInfo os.FileInfo) error {
 	// Check if it's a regular file (not a directory)
 	if fileInfo.IsDir() {
-		return fmt.Errorf("path is a directory, not a file: %s", filePath)
+		return pdferrors.New(pdferrors.InvalidArgument, "path is a directory, not a file: %s", filePath)
 	}
 
 	// Check file extension
 	if !strings.HasSuffix(strings.ToLower(filePath), ".pdf") {
-		return fmt.Errorf("file is not a PDF: %s", filePath)
+		return pdferrors.New(pdferrors.Corrupt, "file is not a PDF: %s", filePath)
 	}
 
 	// Check file size
 	if fileInfo.Size() > r.maxFileSize {
-		return fmt.Errorf("file too large: %d bytes (max: %d bytes)",
+		return pdferrors.New(pdferrors.TooLarge, "file too large: %d bytes (max: %d bytes)",
 			fileInfo.Size(), r.maxFileSize)
 	}
 
 	return nil
 }
 
-// extractTextContent extracts text content from a PDF reader
-func (r *Reader) extractTextContent(pdfReader *pdf.Reader) (string, error) {
+// extractTextContent extracts text content from a PDF reader, limited to the selected pages
+// when a selection is given, reading each page with pageText. Skipped page ranges are marked
+// in the text.
+func (r *Reader) extractTextContent(
+	numbering *extraction.PageNumbering, selection *PageSelection, pageText func(pageNum int) (string, error),
+) (string, error) {
 	var builder strings.Builder
 	totalLength := 0
 
-	for pageNum := 1; pageNum <= pdfReader.NumPage(); pageNum++ {
-		page := pdfReader.Page(pageNum)
+	pages := make([]int, 0, numbering.Count())
+	if selection != nil {
+		pages = selection.Pages
+	} else {
+		for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+			pages = append(pages, pageNum)
+		}
+	}
+	if len(pages) > 0 && pages[0] > 1 {
+		builder.WriteString(omittedPagesNote(1, pages[0]-1))
+	}
+
+	for i, pageNum := range pages {
+		page := numbering.Page(pageNum)
 		if page.V.IsNull() {
 			continue
 		}
 
-		content, err := page.GetPlainText(nil)
+		content, err := pageText(pageNum)
 		if err != nil {
 			// Continue with other pages even if one fails
 			continue
@@ -123,8 +190,13 @@ func (r *Reader) extractTextContent(pdfReader *pdf.Reader) (string, error) {
 		totalLength += len(content)
 
 		// Add page separator for readability
-		if pageNum < pdfReader.NumPage() {
+		switch {
+		case i+1 < len(pages) && pages[i+1] > pageNum+1:
+			builder.WriteString(omittedPagesNote(pageNum+1, pages[i+1]-1))
+		case i+1 < len(pages):
 			builder.WriteString("\n\n--- Page Break ---\n\n")
+		case pageNum < numbering.Count():
+			builder.WriteString(omittedPagesNote(pageNum+1, numbering.Count()))
 		}
 	}
 
@@ -136,8 +208,47 @@ func (r *Reader) extractTextContent(pdfReader *pdf.Reader) (string, error) {
 	return text, nil
 }
 
+// withoutRepeatedLines wraps a page text function to leave out the running headers, footers,
+// and watermarks repeated across the pages read, or across all pages when pages is empty,
+// collecting the lines left out
+func withoutRepeatedLines(
+	pageText func(pageNum int) (string, error), numbering *extraction.PageNumbering, pages []int,
+	removed *[]RepeatedLine,
+) func(pageNum int) (string, error) {
+	if len(pages) == 0 {
+		for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+			pages = append(pages, pageNum)
+		}
+	}
+	repeated := extraction.FindRepeatedLines(numbering, pages)
+	return func(pageNum int) (string, error) {
+		text, err := pageText(pageNum)
+		if err != nil {
+			return "", err
+		}
+		text, lines := extraction.RemoveRepeatedLines(text, repeated[pageNum])
+		for _, line := range lines {
+			*removed = append(*removed, RepeatedLine{
+				Text:        line.Text,
+				Role:        line.Role,
+				Page:        line.Page,
+				BoundingBox: convertBoundingBox(line.BoundingBox),
+			})
+		}
+		return text, nil
+	}
+}
+
+// omittedPagesNote marks a range of pages left out of a page selection
+func omittedPagesNote(from, to int) string {
+	if from == to {
+		return fmt.Sprintf("\n\n--- Page %d omitted ---\n\n", from)
+	}
+	return fmt.Sprintf("\n\n--- Pages %d-%d omitted ---\n\n", from, to)
+}
+
 // analyzeContentType determines the type of content in the PDF
-func (r *Reader) analyzeContentType(textContent string, pdfReader *pdf.Reader) string {
+func (r *Reader) analyzeContentType(textContent string, numbering *extraction.PageNumbering) string {
 	// Minimum text length to consider content meaningful
 	const minMeaningfulTextLength = 50
 
@@ -149,7 +260,7 @@ func (r *Reader) analyzeContentType(textContent string, pdfReader *pdf.Reader) s
 	textWithoutBreaks = strings.TrimSpace(textWithoutBreaks)
 
 	// Check for images
-	hasImages, _ := r.detectImages(pdfReader)
+	hasImages, _ := r.detectImages(numbering)
 
 	// Determine content type based on text and images
 	if textWithoutBreaks == "" {
@@ -177,11 +288,11 @@ func (r *Reader) analyzeContentType(textContent string, pdfReader *pdf.Reader) s
 }
 
 // detectImages scans the PDF for image objects
-func (r *Reader) detectImages(pdfReader *pdf.Reader) (bool, int) {
+func (r *Reader) detectImages(numbering *extraction.PageNumbering) (bool, int) {
 	imageCount := 0
 
-	for pageNum := 1; pageNum <= pdfReader.NumPage(); pageNum++ {
-		pageImages := r.countImagesOnPage(pdfReader, pageNum)
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		pageImages := r.countImagesOnPage(numbering, pageNum)
 		imageCount += pageImages
 	}
 
@@ -189,7 +300,7 @@ func (r *Reader) detectImages(pdfReader *pdf.Reader) (bool, int) {
 }
 
 // countImagesOnPage counts images on a specific page
-func (r *Reader) countImagesOnPage(pdfReader *pdf.Reader, pageNum int) int {
+func (r *Reader) countImagesOnPage(numbering *extraction.PageNumbering, pageNum int) int {
 	defer func() {
 		// Recover from any panics during image detection
 		if recover() != nil {
@@ -197,7 +308,7 @@ func (r *Reader) countImagesOnPage(pdfReader *pdf.Reader, pageNum int) int {
 		}
 	}()
 
-	page := pdfReader.Page(pageNum)
+	page := numbering.Page(pageNum)
 	if page.V.IsNull() {
 		return 0
 	}
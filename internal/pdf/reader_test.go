@@ -183,6 +183,43 @@ startxref
 	}
 }
 
+func TestReader_ReadFile_StripHeadersFooters(t *testing.T) {
+	reader := NewReader(100 * 1024 * 1024)
+	page := func(number, body string) string {
+		return "BT /F1 9 Tf 72 770 Td (Annual Report 2024) Tj ET\n" +
+			"BT /F1 10 Tf 72 600 Td (" + body + ") Tj ET\n" +
+			"BT /F1 9 Tf 300 40 Td (Page " + number + ") Tj ET"
+	}
+	path := createTempFile(t, "annual.pdf", buildTestPDF(
+		page("1", "Revenue grew in Europe."),
+		page("2", "Costs fell in Asia."),
+	))
+
+	result, err := reader.ReadFile(PDFReadFileRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v", err)
+	}
+	if !strings.Contains(result.Content, "Annual Report 2024") || len(result.RepeatedLines) != 0 {
+		t.Errorf("ReadFile() = %+v, want the running header kept by default", result)
+	}
+
+	result, err = reader.ReadFile(PDFReadFileRequest{Path: path, StripHeadersFooters: true})
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error = %v", err)
+	}
+	if strings.Contains(result.Content, "Annual Report") || strings.Contains(result.Content, "Page 2") ||
+		!strings.Contains(result.Content, "Asia") {
+		t.Errorf("ReadFile() content = %q, want the body without headers and footers", result.Content)
+	}
+	roles := make(map[string]int)
+	for _, line := range result.RepeatedLines {
+		roles[line.Role]++
+	}
+	if roles["header"] != 2 || roles["footer"] != 2 {
+		t.Errorf("ReadFile() repeated lines = %+v, want a header and a footer per page", result.RepeatedLines)
+	}
+}
+
 func TestReader_validatePDFFile(t *testing.T) {
 	// Create a temporary directory for test files
 	tempDir, err := os.MkdirTemp("", "pdf_validate_test")
@@ -0,0 +1,165 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// ExtractReadingOrder returns the logical blocks of a document in the order they are read:
+// headings, paragraphs, list items, and tables, page by page, with the columns of each page
+// read one after another. Pages turned by their rotation are read as displayed. Bounding
+// boxes stay in page space, as drawn.
+func (e *Exporter) ExtractReadingOrder(
+	ctx context.Context, req PDFExtractReadingOrderRequest,
+) (*PDFExtractReadingOrderResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	structure := defaultStructure
+	if req.StructureConfig != nil {
+		if err := req.StructureConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid structure_config: %w", err)
+		}
+		structure, _ = newStructureDetector(*req.StructureConfig)
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := e.open(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	numbering := extraction.NewPageNumbering(doc.Reader)
+	pages := req.Pages
+	if len(pages) == 0 {
+		for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+			pages = append(pages, pageNum)
+		}
+	}
+	for _, pageNum := range pages {
+		if pageNum < 1 || pageNum > numbering.Count() {
+			return nil, pageOutOfRange(pageNum, numbering.Count())
+		}
+	}
+
+	tables, err := detectTables(ctx, e.engine, req.Path, req.Pages)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PDFExtractReadingOrderResult{
+		Path:       req.Path,
+		TotalPages: numbering.Count(),
+		Pages:      len(pages),
+		Blocks:     []ReadingOrderBlock{},
+		Structure:  structure.config,
+	}
+
+	lines := make([][]pageLine, len(pages))
+	bodySizes := make([]float64, len(pages))
+	rasters := make([]pageRaster, len(pages))
+	for i, pageNum := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		lines[i], bodySizes[i], rasters[i], err = readingOrderPage(numbering, pageNum)
+		if err != nil {
+			result.FailedPages = append(result.FailedPages, pageNum)
+		}
+	}
+	if req.StripHeadersFooters {
+		running := findRunningLines(lines)
+		for i := range lines {
+			var removed []string
+			lines[i], removed = stripRunningLines(lines[i], running)
+			result.LinesRemoved += len(removed)
+		}
+	}
+
+	for i, pageNum := range pages {
+		pageTables := make([]extraction.TableElement, len(tables[pageNum]))
+		for j, table := range tables[pageNum] {
+			pageTables[j] = table
+			pageTables[j].BoundingBox = rasters[i].toDisplay(table.BoundingBox)
+		}
+		for _, block := range structure.textBlocks(lines[i], bodySizes[i], pageTables) {
+			element := ReadingOrderBlock{
+				Order:       len(result.Blocks) + 1,
+				Page:        pageNum,
+				PageLabel:   numbering.Label(pageNum),
+				Type:        block.kind,
+				Level:       block.level,
+				Text:        block.text,
+				Rows:        block.rows,
+				BoundingBox: rasters[i].fromDisplay(block.bounds),
+			}
+			if block.kind == blockTable {
+				element.Text = writePlainTable(block.rows)
+			}
+			result.Blocks = append(result.Blocks, element)
+		}
+	}
+	return result, nil
+}
+
+// readingOrderPage reads the lines of a page in reading order, placed on the page as it is
+// displayed, and returns the mapping between the displayed page and page space
+func readingOrderPage(
+	numbering *extraction.PageNumbering, pageNum int,
+) (lines []pageLine, bodySize float64, raster pageRaster, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			lines, bodySize, err = nil, 0, fmt.Errorf("failed to read page %d: %v", pageNum, rec)
+		}
+	}()
+
+	raster = pageRaster{rotation: numbering.Rotation(pageNum), scale: 1}
+	if box, ok := extraction.PageCropBox(numbering, pageNum); ok {
+		raster.box = box
+	} else {
+		raster.rotation = 0
+	}
+	words, err := extraction.PageWords(numbering.Page(pageNum))
+	if err != nil {
+		return nil, 0, raster, err
+	}
+	for i := range words {
+		words[i].BoundingBox = raster.toDisplay(words[i].BoundingBox)
+	}
+	rows, bodySize := groupRows(words, math.MaxInt)
+	return readingOrderLines(rows, findGutters(rows, bodySize)), bodySize, raster, nil
+}
+
+// toDisplay turns a box of an unscaled raster's page into the page as displayed, turned by
+// its rotation, keeping y growing upward so rows still read from the largest y down.
+// Unrotated pages are left as drawn.
+func (p pageRaster) toDisplay(box extraction.BoundingBox) extraction.BoundingBox {
+	if p.rotation == 0 {
+		return box
+	}
+	x0, y0 := p.toPixel(box.LowerLeft.X, box.LowerLeft.Y)
+	x1, y1 := p.toPixel(box.UpperRight.X, box.UpperRight.Y)
+	return extraction.BoundingBox{
+		LowerLeft:  extraction.Coordinate{X: min(x0, x1), Y: -max(y0, y1)},
+		UpperRight: extraction.Coordinate{X: max(x0, x1), Y: -min(y0, y1)},
+		Width:      math.Abs(x1 - x0),
+		Height:     math.Abs(y1 - y0),
+	}
+}
+
+// fromDisplay turns a rectangle of the page as displayed by toDisplay back into page space
+func (p pageRaster) fromDisplay(rect Rectangle) Rectangle {
+	if p.rotation == 0 {
+		return rect
+	}
+	x0, y0 := p.toPoint(rect.X, -rect.Y)
+	x1, y1 := p.toPoint(rect.X+rect.Width, -(rect.Y + rect.Height))
+	return Rectangle{X: min(x0, x1), Y: min(y0, y1), Width: math.Abs(x1 - x0), Height: math.Abs(y1 - y0)}
+}
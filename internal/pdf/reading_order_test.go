@@ -0,0 +1,95 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+func TestExporter_ExtractReadingOrder(t *testing.T) {
+	var b strings.Builder
+	text := func(size, x, y int, s string) {
+		fmt.Fprintf(&b, "BT /F1 %d Tf %d %d Td (%s) Tj ET\n", size, x, y, s)
+	}
+	text(18, 72, 740, "Field Notes")
+	left := []string{"The left column opens a story", "and runs down the page for", "several lines of running text",
+		"set in a narrow measure", "that keeps going for a while,", "before the reader moves on."}
+	right := []string{"The right column is read only", "after the left one is done,", "even though its lines sit",
+		"beside those of the left", "at the very same heights", "all the way down the page."}
+	for i := range left {
+		text(10, 72, 700-i*14, left[i])
+		text(10, 320, 700-i*14, right[i])
+	}
+	text(9, 300, 40, "Page 1")
+	path := createTempFile(t, "columns.pdf", buildTestPDF(b.String()))
+
+	exporter := NewExporter(100 * 1024 * 1024)
+	result, err := exporter.ExtractReadingOrder(context.Background(), PDFExtractReadingOrderRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractReadingOrder() unexpected error = %v", err)
+	}
+
+	var got []string
+	for _, block := range result.Blocks {
+		got = append(got, fmt.Sprintf("%d %s %s", block.Order, block.Type, block.Text))
+	}
+	want := []string{
+		"1 heading Field Notes",
+		"2 paragraph " + strings.Join(left, " "),
+		"3 paragraph " + strings.Join(right, " "),
+		"4 paragraph Page 1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("blocks =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if box := result.Blocks[2].BoundingBox; box.X < 300 || box.Y > 640 || box.Height < 60 {
+		t.Errorf("right column bounding box = %+v, want the right column", box)
+	}
+
+	if _, err := exporter.ExtractReadingOrder(context.Background(),
+		PDFExtractReadingOrderRequest{Path: path, Pages: []int{2}}); err == nil {
+		t.Error("ExtractReadingOrder() expected an error for a page out of range")
+	}
+}
+
+func TestPageRaster_Display(t *testing.T) {
+	box := extraction.BoundingBox{
+		LowerLeft:  extraction.Coordinate{X: 0, Y: 0},
+		UpperRight: extraction.Coordinate{X: 612, Y: 792},
+		Width:      612,
+		Height:     792,
+	}
+	word := extraction.BoundingBox{
+		LowerLeft:  extraction.Coordinate{X: 100, Y: 700},
+		UpperRight: extraction.Coordinate{X: 150, Y: 710},
+		Width:      50,
+		Height:     10,
+	}
+	for _, rotation := range []int{0, 90, 180, 270} {
+		raster := pageRaster{box: box, rotation: rotation, scale: 1}
+		display := raster.toDisplay(word)
+		back := raster.fromDisplay(Rectangle{X: display.LowerLeft.X, Y: display.LowerLeft.Y,
+			Width: display.Width, Height: display.Height})
+		if back != (Rectangle{X: 100, Y: 700, Width: 50, Height: 10}) {
+			t.Errorf("rotation %d: round trip = %+v, want the word's box", rotation, back)
+		}
+	}
+
+	// Turned a quarter clockwise, the left edge of the page is its top as displayed, so a
+	// word near the left edge is read before one further right
+	raster := pageRaster{box: box, rotation: 90, scale: 1}
+	near := raster.toDisplay(extraction.BoundingBox{
+		LowerLeft:  extraction.Coordinate{X: 50, Y: 300},
+		UpperRight: extraction.Coordinate{X: 60, Y: 340},
+	})
+	far := raster.toDisplay(extraction.BoundingBox{
+		LowerLeft:  extraction.Coordinate{X: 400, Y: 300},
+		UpperRight: extraction.Coordinate{X: 410, Y: 340},
+	})
+	if near.UpperRight.Y <= far.UpperRight.Y || near.Width != 40 {
+		t.Errorf("displayed boxes near = %+v, far = %+v, want the word near the left edge on top", near, far)
+	}
+}
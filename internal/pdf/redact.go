@@ -0,0 +1,263 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+	"github.com/ledongthuc/pdf"
+)
+
+// redactedMIMEType is the MIME type of redacted documents
+const redactedMIMEType = "application/pdf"
+
+// Redactor removes content from PDF pages and writes the redacted document
+type Redactor struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewRedactor creates a new redactor with the specified constraints
+func NewRedactor(maxFileSize int64) *Redactor {
+	return &Redactor{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// pageRedaction is the work planned for one page
+type pageRedaction struct {
+	areas  []extraction.BoundingBox
+	annots int // Redaction annotations applied, which are removed from the page
+}
+
+// Redact writes a copy of the document with the requested regions, every occurrence of the
+// terms, and the areas of existing redaction annotations removed and painted black. Text
+// and images in the areas are deleted from the content streams rather than covered, and the
+// applied annotations are removed. The copy is rebuilt from the objects the document still
+// uses, so earlier revisions of edited pages do not survive in it.
+func (r *Redactor) Redact(ctx context.Context, req PDFRedactRequest) (*PDFRedactResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	var terms [][]string
+	for _, term := range req.Terms {
+		if words := strings.Fields(term); len(words) > 0 {
+			terms = append(terms, words)
+		}
+	}
+	for _, region := range req.Regions {
+		if region.Width <= 0 || region.Height <= 0 {
+			return nil, fmt.Errorf("region on page %d must have a positive width and height", region.Page)
+		}
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: r.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	reader := doc.Reader
+
+	if !reader.Trailer().Key("Encrypt").IsNull() {
+		return nil, fmt.Errorf("cannot redact encrypted documents")
+	}
+
+	result := &PDFRedactResult{
+		Path:     req.Path,
+		MIMEType: redactedMIMEType,
+		Pages:    []int{},
+		Regions:  []RedactionRegion{},
+	}
+
+	plan := make(map[int]*pageRedaction)
+	planPage := func(pageNum int) *pageRedaction {
+		if plan[pageNum] == nil {
+			plan[pageNum] = &pageRedaction{}
+		}
+		return plan[pageNum]
+	}
+	numbering := extraction.NewPageNumbering(reader)
+	for _, region := range req.Regions {
+		if region.Page < 1 || region.Page > numbering.Count() {
+			return nil, pageOutOfRange(region.Page, numbering.Count())
+		}
+		planPage(region.Page).areas = append(planPage(region.Page).areas, extraction.BoundingBox{
+			LowerLeft:  extraction.Coordinate{X: region.X, Y: region.Y},
+			UpperRight: extraction.Coordinate{X: region.X + region.Width, Y: region.Y + region.Height},
+			Width:      region.Width,
+			Height:     region.Height,
+		})
+	}
+
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page := numbering.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+
+		if len(terms) > 0 {
+			text, err := pageText(numbering, pageNum)
+			if err != nil {
+				return nil, fmt.Errorf("cannot search page %d: %w", pageNum, err)
+			}
+			for _, words := range terms {
+				for _, match := range text.Find(findTextPattern(words, req.CaseSensitive, req.WholeWord)) {
+					result.TermMatches++
+					planPage(pageNum).areas = append(planPage(pageNum).areas, match.Rects...)
+				}
+			}
+		}
+
+		if annotated := extraction.PageRedactions(page); len(annotated) > 0 {
+			planned := planPage(pageNum)
+			planned.areas = append(planned.areas, annotated...)
+			annots := page.V.Key("Annots")
+			for i := 0; i < annots.Len(); i++ {
+				if annots.Index(i).Key("Subtype").Name() == "Redact" {
+					planned.annots++
+				}
+			}
+		}
+	}
+	if len(req.Regions) == 0 && len(terms) == 0 && len(plan) == 0 {
+		return nil, fmt.Errorf("nothing to redact: give regions or terms, or add redaction annotations")
+	}
+
+	writer := newDocumentWriter(doc)
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		planned := plan[pageNum]
+		if planned == nil || len(planned.areas) == 0 {
+			continue
+		}
+		page := numbering.Page(pageNum)
+		content, err := extraction.RedactPageContent(page, planned.areas)
+		if err != nil {
+			return nil, fmt.Errorf("cannot redact page %d: %w", pageNum, err)
+		}
+
+		result.Pages = append(result.Pages, pageNum)
+		result.Annotations += planned.annots
+		result.GlyphsRemoved += content.Glyphs
+		result.ImagesRemoved += content.Images
+		for _, area := range planned.areas {
+			result.Regions = append(result.Regions, RedactionRegion{
+				Page:   pageNum,
+				X:      area.LowerLeft.X,
+				Y:      area.LowerLeft.Y,
+				Width:  area.Width,
+				Height: area.Height,
+			})
+		}
+		writer.edits[extraction.RefOf(page.V)] = redactPageEdit(page, content)
+	}
+
+	data, err := writer.write(reader.Trailer())
+	if err != nil {
+		return nil, fmt.Errorf("failed to write redacted document: %w", err)
+	}
+	result.Size = len(data)
+
+	if req.OutputDir == "" {
+		result.Data = base64.StdEncoding.EncodeToString(data)
+		return result, nil
+	}
+
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+	stem := strings.TrimSuffix(filepath.Base(req.Path), filepath.Ext(req.Path))
+	result.OutputPath = filepath.Join(req.OutputDir, stem+"-redacted.pdf")
+	if err := fsys.WriteFile(result.OutputPath, data, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save redacted document: %w", err)
+	}
+
+	return result, nil
+}
+
+// redactPageEdit writes a page with its redacted content stream, the rewritten forms that
+// content draws, and without its redaction annotations
+func redactPageEdit(page pdf.Page, content *extraction.RedactedContent) objectEdit {
+	return func(w *documentWriter, b *bytes.Buffer, v pdf.Value) error {
+		contents, err := w.addStream(pdf.Value{}, nil, content.Content)
+		if err != nil {
+			return err
+		}
+		replace := map[string]string{"Contents": fmt.Sprintf("%d 0 R", contents)}
+
+		if len(content.Forms) > 0 {
+			resources, err := w.redactedResources(page.Resources(), content.Forms)
+			if err != nil {
+				return err
+			}
+			replace["Resources"] = resources
+		}
+
+		annots := page.V.Key("Annots")
+		if annots.Len() > 0 {
+			var kept bytes.Buffer
+			kept.WriteByte('[')
+			for i := 0; i < annots.Len(); i++ {
+				annot := annots.Index(i)
+				if annot.Key("Subtype").Name() == "Redact" {
+					continue
+				}
+				if kept.Len() > 1 {
+					kept.WriteByte(' ')
+				}
+				if err := w.child(&kept, annot, extraction.RefOf(annots), 1); err != nil {
+					return err
+				}
+			}
+			kept.WriteByte(']')
+			replace["Annots"] = kept.String()
+		}
+
+		return w.dict(b, v, replace, 0)
+	}
+}
+
+// redactedResources writes a copy of a resource dictionary whose XObjects include the
+// rewritten forms
+func (w *documentWriter) redactedResources(resources pdf.Value, forms []extraction.RedactedForm) (string, error) {
+	names := make(map[string]string, len(forms))
+	for _, form := range forms {
+		number, err := w.addRedactedForm(form)
+		if err != nil {
+			return "", err
+		}
+		names[form.Name] = fmt.Sprintf("%d 0 R", number)
+	}
+
+	var xobjects bytes.Buffer
+	if err := w.dict(&xobjects, resources.Key("XObject"), names, 1); err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := w.dict(&b, resources, map[string]string{"XObject": xobjects.String()}, 0); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// addRedactedForm adds the copy of a form XObject with its redacted content
+func (w *documentWriter) addRedactedForm(form extraction.RedactedForm) (int, error) {
+	replace := map[string]string{}
+	if len(form.Content.Forms) > 0 {
+		resources, err := w.redactedResources(form.Resources, form.Content.Forms)
+		if err != nil {
+			return 0, err
+		}
+		replace["Resources"] = resources
+	}
+	return w.addStream(form.Form, replace, form.Content.Content)
+}
@@ -0,0 +1,162 @@
+package pdf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// redactedText decodes a redacted document and returns the text of each page
+func redactedText(t *testing.T, result *PDFRedactResult) []string {
+	t.Helper()
+
+	data, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil {
+		t.Fatalf("redacted data is not base64: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "redacted.pdf")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		t.Fatalf("redacted document does not open: %v", err)
+	}
+	defer f.Close()
+
+	pages := make([]string, r.NumPage())
+	for i := range pages {
+		text, err := extraction.PlainText(r.Page(i + 1))
+		if err != nil {
+			t.Fatalf("page %d of redacted document unreadable: %v", i+1, err)
+		}
+		pages[i] = text
+	}
+	return pages
+}
+
+func TestRedactor_RemovesTermsAndRegions(t *testing.T) {
+	path := createTempFile(t, "statement.pdf", buildTestPDF(
+		"BT /F1 12 Tf 72 700 Td (Account 12345 closed) Tj ET\n"+
+			"BT /F1 12 Tf 72 650 Td (Balance due) Tj ET",
+		"BT /F1 12 Tf 72 700 Td (Reference 12345) Tj ET",
+	))
+
+	result, err := NewRedactor(10*1024*1024).Redact(context.Background(), PDFRedactRequest{
+		Path:    path,
+		Terms:   []string{"12345"},
+		Regions: []RedactionRegion{{Page: 1, X: 60, Y: 640, Width: 200, Height: 25}},
+	})
+	if err != nil {
+		t.Fatalf("Redact() unexpected error = %v", err)
+	}
+
+	if result.TermMatches != 2 {
+		t.Errorf("Redact() term matches = %d, want 2", result.TermMatches)
+	}
+	if fmt.Sprint(result.Pages) != "[1 2]" {
+		t.Errorf("Redact() pages = %v, want [1 2]", result.Pages)
+	}
+	if want := len("12345")*2 + len("Balancedue"); result.GlyphsRemoved < want {
+		t.Errorf("Redact() removed %d glyphs, want at least %d", result.GlyphsRemoved, want)
+	}
+
+	pages := redactedText(t, result)
+	for i, text := range pages {
+		for _, removed := range []string{"12345", "Balance"} {
+			if strings.Contains(text, removed) {
+				t.Errorf("page %d still contains %q: %q", i+1, removed, text)
+			}
+		}
+	}
+	if !strings.Contains(pages[0], "Account") || !strings.Contains(pages[0], "closed") {
+		t.Errorf("page 1 lost text outside the redactions: %q", pages[0])
+	}
+	if !strings.Contains(pages[1], "Reference") {
+		t.Errorf("page 2 lost text outside the redactions: %q", pages[1])
+	}
+}
+
+func TestRedactor_AppliesRedactionAnnotations(t *testing.T) {
+	content := "BT /F1 12 Tf 72 700 Td (Patient Jane Roe) Tj ET"
+	path := createTempFile(t, "annotated.pdf", buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> " +
+			"/Contents 5 0 R /Annots [6 0 R] >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content)+1, content),
+		"<< /Type /Annot /Subtype /Redact /Rect [119 690 200 715] >>",
+	}))
+
+	// Marked text is left out of extraction before the redaction is applied
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := extraction.PlainText(r.Page(1))
+	f.Close()
+	if err != nil {
+		t.Fatalf("PlainText() unexpected error = %v", err)
+	}
+	if strings.Contains(text, "Jane") || !strings.Contains(text, "Patient") {
+		t.Errorf("PlainText() = %q, want Patient without the redacted name", text)
+	}
+
+	outputDir := t.TempDir()
+	result, err := NewRedactor(10*1024*1024).Redact(context.Background(), PDFRedactRequest{
+		Path:      path,
+		OutputDir: outputDir,
+	})
+	if err != nil {
+		t.Fatalf("Redact() unexpected error = %v", err)
+	}
+	if result.Annotations != 1 || result.GlyphsRemoved == 0 {
+		t.Errorf("Redact() annotations = %d, glyphs = %d, want the annotation applied",
+			result.Annotations, result.GlyphsRemoved)
+	}
+	if result.OutputPath != filepath.Join(outputDir, "annotated-redacted.pdf") {
+		t.Errorf("Redact() output path = %q", result.OutputPath)
+	}
+
+	f, r, err = pdf.Open(result.OutputPath)
+	if err != nil {
+		t.Fatalf("redacted document does not open: %v", err)
+	}
+	defer f.Close()
+	page := r.Page(1)
+	if n := page.V.Key("Annots").Len(); n != 0 {
+		t.Errorf("redacted page keeps %d annotations, want the redaction removed", n)
+	}
+	raw, err := page.GetPlainText(nil)
+	if err != nil {
+		t.Fatalf("GetPlainText() unexpected error = %v", err)
+	}
+	if strings.Contains(raw, "Jane") || strings.Contains(raw, "Roe") {
+		t.Errorf("redacted page content still holds the name: %q", raw)
+	}
+}
+
+func TestRedactor_RequiresSomethingToRedact(t *testing.T) {
+	path := createTempFile(t, "plain.pdf", buildTestPDF("BT /F1 12 Tf 72 700 Td (Nothing here) Tj ET"))
+
+	_, err := NewRedactor(10*1024*1024).Redact(context.Background(), PDFRedactRequest{Path: path})
+	if err == nil || !strings.Contains(err.Error(), "nothing to redact") {
+		t.Errorf("Redact() error = %v, want nothing to redact", err)
+	}
+
+	_, err = NewRedactor(10*1024*1024).Redact(context.Background(), PDFRedactRequest{
+		Path:    path,
+		Regions: []RedactionRegion{{Page: 2, X: 0, Y: 0, Width: 10, Height: 10}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("Redact() error = %v, want page out of range", err)
+	}
+}
@@ -0,0 +1,341 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// maxObjectDepth limits the nesting of direct objects written inline
+const maxObjectDepth = 64
+
+// objectEdit writes the replacement body of an edited object
+type objectEdit func(w *documentWriter, b *bytes.Buffer, v pdf.Value) error
+
+// documentWriter writes the objects reachable from a document's trailer into a new file with
+// a fresh cross-reference table. Objects are renumbered, so unreachable objects, earlier
+// revisions, and object streams are left behind; edited objects are written by their edit.
+type documentWriter struct {
+	file     io.ReaderAt // Source of raw stream data, which is copied without decoding
+	edits    map[extraction.ObjectRef]objectEdit
+	numbers  map[extraction.ObjectRef]int
+	bodies   [][]byte // Object bodies by number - 1
+	pending  []pendingObject
+	compact  *compaction   // Set to shrink the copy, as pdf_optimize does
+	sanitize *sanitization // Set to leave out metadata and active content, as pdf_sanitize does
+}
+
+// pendingObject is a source object that has a number but has not been written yet
+type pendingObject struct {
+	number int
+	value  pdf.Value
+}
+
+// newDocumentWriter creates a writer copying from the given file
+func newDocumentWriter(file io.ReaderAt) *documentWriter {
+	return &documentWriter{
+		file:    file,
+		edits:   map[extraction.ObjectRef]objectEdit{},
+		numbers: map[extraction.ObjectRef]int{},
+	}
+}
+
+// reference returns the new number of an indirect source object, scheduling it for writing
+func (w *documentWriter) reference(v pdf.Value) int {
+	ref := extraction.RefOf(v)
+	if number, ok := w.numbers[ref]; ok {
+		return number
+	}
+	if number, ok := w.compact.duplicate(w, v); ok {
+		w.numbers[ref] = number
+		return number
+	}
+	w.bodies = append(w.bodies, nil)
+	number := len(w.bodies)
+	w.numbers[ref] = number
+	w.pending = append(w.pending, pendingObject{number: number, value: v})
+	w.compact.original(w, v, number)
+	return number
+}
+
+// addObject adds a new object with the given body and returns its number
+func (w *documentWriter) addObject(body []byte) int {
+	w.bodies = append(w.bodies, body)
+	return len(w.bodies)
+}
+
+// addStream adds a new Flate-compressed stream. Its dictionary is v's, or empty for a null
+// v, with the entries in replace applied as by dict.
+func (w *documentWriter) addStream(v pdf.Value, replace map[string]string, data []byte) (int, error) {
+	compressed, err := deflate(data)
+	if err != nil {
+		return 0, err
+	}
+
+	entries := map[string]string{"Filter": "/FlateDecode", "DecodeParms": "", "Length": strconv.Itoa(len(compressed))}
+	for key, value := range replace {
+		entries[key] = value
+	}
+	var b bytes.Buffer
+	if err := w.dict(&b, v, entries, 0); err != nil {
+		return 0, err
+	}
+	b.WriteString("\nstream\n")
+	b.Write(compressed)
+	b.WriteString("\nendstream")
+	return w.addObject(b.Bytes()), nil
+}
+
+// deflate compresses data as FlateDecode reads it
+func deflate(data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// write copies the document and returns the new file
+func (w *documentWriter) write(trailer pdf.Value) ([]byte, error) {
+	root := w.reference(trailer.Key("Root"))
+	info := 0
+	if trailer.Key("Info").Kind() == pdf.Dict {
+		info = w.reference(trailer.Key("Info"))
+	}
+
+	for len(w.pending) > 0 {
+		next := w.pending[0]
+		w.pending = w.pending[1:]
+		var b bytes.Buffer
+		if err := w.object(&b, next.value); err != nil {
+			return nil, fmt.Errorf("failed to copy object %s: %w", extraction.RefOf(next.value), err)
+		}
+		w.bodies[next.number-1] = b.Bytes()
+	}
+	if w.compact != nil {
+		return w.writeCompact(trailer, root, info)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(w.bodies))
+	for i, body := range w.bodies {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n", i+1)
+		out.Write(body)
+		out.WriteString("\nendobj\n")
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(w.bodies)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<</Size %d ", len(w.bodies)+1)
+	if err := w.trailerEntries(&out, trailer, root, info); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&out, ">>\nstartxref\n%d\n%%%%EOF\n", xref)
+	return out.Bytes(), nil
+}
+
+// trailerEntries writes the Root, Info, and ID entries of the new trailer
+func (w *documentWriter) trailerEntries(b *bytes.Buffer, trailer pdf.Value, root, info int) error {
+	fmt.Fprintf(b, "/Root %d 0 R", root)
+	if info != 0 {
+		fmt.Fprintf(b, " /Info %d 0 R", info)
+	}
+	if id := trailer.Key("ID"); id.Kind() == pdf.Array {
+		b.WriteString(" /ID ")
+		return w.value(b, id, extraction.RefOf(trailer), 0)
+	}
+	return nil
+}
+
+// object writes the body of an indirect object
+func (w *documentWriter) object(b *bytes.Buffer, v pdf.Value) error {
+	if edit, ok := w.edits[extraction.RefOf(v)]; ok {
+		return edit(w, b, v)
+	}
+	if v.Kind() != pdf.Stream {
+		return w.value(b, v, extraction.RefOf(v), 0)
+	}
+
+	data, err := readRawStream(w.file, v)
+	if err != nil {
+		return err
+	}
+	replace := map[string]string{"Length": strconv.Itoa(len(data))}
+	if data, err = w.compact.compress(v, data, replace); err != nil {
+		return err
+	}
+	if err := w.dict(b, v, replace, 0); err != nil {
+		return err
+	}
+	b.WriteString("\nstream\n")
+	b.Write(data)
+	b.WriteString("\nendstream")
+	return nil
+}
+
+// readRawStream returns the stream's bytes as stored in the file. The parser keeps the offset
+// of stream data in an unexported field, so it is read the same way as object numbers.
+func readRawStream(file io.ReaderAt, v pdf.Value) ([]byte, error) {
+	offset, err := streamDataOffset(v)
+	if err != nil {
+		return nil, err
+	}
+	length := v.Key("Length").Int64()
+	if length < 0 {
+		return nil, pdferrors.New(pdferrors.Corrupt, "invalid stream length %d", length)
+	}
+
+	// Reading through a section allocates what the file holds rather than what /Length claims
+	buf, err := io.ReadAll(io.NewSectionReader(file, offset, length))
+	if err != nil {
+		return nil, pdferrors.New(pdferrors.Corrupt, "failed to read stream data: %w", err)
+	}
+	if int64(len(buf)) < length {
+		return nil, pdferrors.New(pdferrors.Corrupt, "failed to read stream data: %w", io.ErrUnexpectedEOF)
+	}
+	return buf, nil
+}
+
+// streamDataOffset returns the file offset at which a stream's data starts
+func streamDataOffset(v pdf.Value) (int64, error) {
+	data := reflect.ValueOf(v).FieldByName("data")
+	if !data.IsValid() || data.Kind() != reflect.Interface || data.IsNil() {
+		return 0, pdferrors.New(pdferrors.Corrupt, "stream data not found")
+	}
+	// Other values, such as dictionaries, hold no stream
+	if data.Elem().Kind() != reflect.Struct {
+		return 0, pdferrors.New(pdferrors.Corrupt, "stream data not found")
+	}
+	offset := data.Elem().FieldByName("offset")
+	if !offset.IsValid() {
+		return 0, pdferrors.New(pdferrors.Corrupt, "stream data not found")
+	}
+	return offset.Int(), nil
+}
+
+// child writes a value found inside the object owner: a reference when it was loaded from
+// another object, the value itself otherwise
+func (w *documentWriter) child(b *bytes.Buffer, v pdf.Value, owner extraction.ObjectRef, depth int) error {
+	if ref := extraction.RefOf(v); ref.ID != 0 && ref != owner {
+		fmt.Fprintf(b, "%d 0 R", w.reference(v))
+		return nil
+	}
+	return w.value(b, v, owner, depth)
+}
+
+// value writes a direct value
+func (w *documentWriter) value(b *bytes.Buffer, v pdf.Value, owner extraction.ObjectRef, depth int) error {
+	if depth > maxObjectDepth {
+		return fmt.Errorf("objects nested more than %d deep", maxObjectDepth)
+	}
+
+	switch v.Kind() {
+	case pdf.Null:
+		b.WriteString("null")
+	case pdf.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case pdf.Integer:
+		b.WriteString(strconv.FormatInt(v.Int64(), 10))
+	case pdf.Real:
+		b.WriteString(strconv.FormatFloat(v.Float64(), 'f', -1, 64))
+	case pdf.String:
+		fmt.Fprintf(b, "<%x>", v.RawString())
+	case pdf.Name:
+		writeName(b, v.Name())
+	case pdf.Array:
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			if err := w.child(b, v.Index(i), owner, depth+1); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case pdf.Dict:
+		return w.dict(b, v, nil, depth)
+	case pdf.Stream:
+		return fmt.Errorf("stream stored as a direct object")
+	}
+	return nil
+}
+
+// dict writes a dictionary or stream dictionary. Entries are replaced by the literal values
+// in replace, dropped when their replacement is empty, and added when missing from v.
+// Entries a sanitizing writer leaves out are dropped as well.
+func (w *documentWriter) dict(b *bytes.Buffer, v pdf.Value, replace map[string]string, depth int) error {
+	owner := extraction.RefOf(v)
+	b.WriteString("<<")
+	for _, key := range v.Keys() {
+		if replacement, ok := replace[key]; ok {
+			if replacement != "" {
+				writeName(b, key)
+				b.WriteString(" " + replacement + " ")
+			}
+			continue
+		}
+		value := v.Key(key)
+		if w.sanitize.drops(key, value) {
+			continue
+		}
+		writeName(b, key)
+		b.WriteByte(' ')
+		if err := w.child(b, value, owner, depth+1); err != nil {
+			return err
+		}
+		b.WriteByte(' ')
+	}
+
+	var added []string
+	for key, replacement := range replace {
+		if replacement != "" && v.Key(key).IsNull() {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	for _, key := range added {
+		writeName(b, key)
+		b.WriteString(" " + replace[key] + " ")
+	}
+	b.WriteString(">>")
+	return nil
+}
+
+// replaceEdit returns an edit writing a dictionary with the entries in replace applied as
+// by dict
+func replaceEdit(replace map[string]string) objectEdit {
+	return func(w *documentWriter, b *bytes.Buffer, v pdf.Value) error {
+		return w.dict(b, v, replace, 0)
+	}
+}
+
+// writeName writes a name, escaping bytes that cannot appear in it literally
+func writeName(b *bytes.Buffer, name string) {
+	b.WriteByte('/')
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < '!' || c > '~' || bytes.IndexByte([]byte("#()<>[]{}/%"), c) >= 0 {
+			fmt.Fprintf(b, "#%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+}
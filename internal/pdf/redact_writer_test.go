@@ -0,0 +1,42 @@
+package pdf
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/ledongthuc/pdf"
+)
+
+func TestReadRawStream_Codes(t *testing.T) {
+	path := createTempFile(t, "stream.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Stream) Tj ET"))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	contents := r.Page(1).V.Key("Contents")
+
+	if raw, err := readRawStream(f, contents); err != nil || !bytes.Contains(raw, []byte("(Stream) Tj")) {
+		t.Fatalf("readRawStream() = %q, %v, want the content stream", raw, err)
+	}
+
+	// Streams cut short by the end of the file and values without stream data are damage,
+	// not missing files
+	offset, err := streamDataOffset(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, truncatedErr := readRawStream(bytes.NewReader(data[:offset+4]), contents)
+	_, missingErr := readRawStream(f, r.Page(1).V)
+	for name, err := range map[string]error{"truncated": truncatedErr, "not a stream": missingErr} {
+		if code := pdferrors.CodeOf(err); code != pdferrors.Corrupt {
+			t.Errorf("%s: readRawStream() error %v has code %s, want %s", name, err, code, pdferrors.Corrupt)
+		}
+	}
+}
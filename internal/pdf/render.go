@@ -0,0 +1,262 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Rendering limits and defaults
+const (
+	defaultRenderDPI   = 150
+	minRenderDPI       = 36
+	maxRenderDPI       = 600
+	maxRenderPixels    = 40_000_000 // Refuse renders that would decode to more than ~160MB of RGBA
+	defaultJPEGQuality = 85
+	renderTimeout      = 60 * time.Second
+	pointsPerInch      = 72.0
+
+	// renderFilePerm is the permission used for saved renders
+	renderFilePerm = 0o600
+)
+
+// Render formats
+const (
+	RenderFormatPNG  = "png"
+	RenderFormatJPEG = "jpeg"
+)
+
+// renderBackend is an external program that rasterizes a PDF page to a PNG file
+type renderBackend struct {
+	name string
+	args func(path string, page, dpi int, output string) []string
+}
+
+// defaultRenderBackends are tried in order; the first one installed is used
+var defaultRenderBackends = []renderBackend{
+	{
+		name: "pdftoppm", // poppler-utils
+		args: func(path string, page, dpi int, output string) []string {
+			return []string{"-f", strconv.Itoa(page), "-l", strconv.Itoa(page), "-r", strconv.Itoa(dpi),
+				"-png", "-singlefile", path, strings.TrimSuffix(output, ".png")}
+		},
+	},
+	{
+		name: "mutool", // mupdf-tools
+		args: func(path string, page, dpi int, output string) []string {
+			return []string{"draw", "-q", "-r", strconv.Itoa(dpi), "-F", "png", "-o", output, path, strconv.Itoa(page)}
+		},
+	},
+}
+
+// Renderer rasterizes PDF pages to PNG or JPEG images using an installed PDF renderer
+type Renderer struct {
+	maxFileSize int64
+	validator   *Validator
+	backends    []renderBackend
+}
+
+// NewRenderer creates a new page renderer with the specified constraints
+func NewRenderer(maxFileSize int64) *Renderer {
+	return &Renderer{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+		backends:    defaultRenderBackends,
+	}
+}
+
+// RenderPage rasterizes one page at the requested resolution, returning the image
+// base64-encoded or saving it to the output directory
+func (r *Renderer) RenderPage(req PDFRenderPageRequest) (*PDFRenderPageResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	page := req.Page
+	if page == 0 {
+		page = 1
+	}
+	dpi := req.DPI
+	if dpi == 0 {
+		dpi = defaultRenderDPI
+	}
+	if dpi < minRenderDPI || dpi > maxRenderDPI {
+		return nil, fmt.Errorf("dpi must be between %d and %d, got %d", minRenderDPI, maxRenderDPI, dpi)
+	}
+
+	format := strings.ToLower(req.Format)
+	switch format {
+	case "", RenderFormatPNG:
+		format = RenderFormatPNG
+	case RenderFormatJPEG, "jpg":
+		format = RenderFormatJPEG
+	default:
+		return nil, fmt.Errorf("invalid format: %s (must be %s or %s)", req.Format, RenderFormatPNG, RenderFormatJPEG)
+	}
+
+	quality := req.Quality
+	if quality == 0 {
+		quality = defaultJPEGQuality
+	}
+	if quality < 1 || quality > 100 {
+		return nil, fmt.Errorf("quality must be between 1 and 100, got %d", quality)
+	}
+
+	fileInfo, err := fsys.Stat(req.Path)
+	if os.IsNotExist(err) {
+		return nil, pdferrors.New(pdferrors.NotFound, "file does not exist: %s", req.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if err := r.validator.ValidateFileInfo(req.Path, fileInfo); err != nil {
+		return nil, err
+	}
+
+	pageLabel, err := checkRenderSize(req.Path, page, dpi)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := r.findBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := runRenderBackend(backend, req.Path, page, dpi)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := png.Decode(bytes.NewReader(rendered))
+	if err != nil {
+		return nil, fmt.Errorf("%s produced an unreadable image: %w", backend.name, err)
+	}
+
+	data := rendered
+	mimeType := "image/png"
+	if format == RenderFormatJPEG {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+		}
+		data = buf.Bytes()
+		mimeType = "image/jpeg"
+	}
+
+	result := &PDFRenderPageResult{
+		Path:      req.Path,
+		Page:      page,
+		PageLabel: pageLabel,
+		DPI:       dpi,
+		Format:    format,
+		MIMEType:  mimeType,
+		Width:     img.Bounds().Dx(),
+		Height:    img.Bounds().Dy(),
+		Size:      len(data),
+		Renderer:  backend.name,
+	}
+
+	if req.OutputDir == "" {
+		result.Data = base64.StdEncoding.EncodeToString(data)
+		return result, nil
+	}
+
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+	stem := strings.TrimSuffix(filepath.Base(req.Path), filepath.Ext(req.Path))
+	ext := format
+	if format == RenderFormatJPEG {
+		ext = "jpg"
+	}
+	result.OutputPath = filepath.Join(req.OutputDir, fmt.Sprintf("%s-page-%d.%s", stem, page, ext))
+	if err := fsys.WriteFile(result.OutputPath, data, renderFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save rendered page: %w", err)
+	}
+
+	return result, nil
+}
+
+// checkRenderSize verifies the page exists and that rendering it at dpi stays within
+// maxRenderPixels, and returns the page's label
+func checkRenderSize(path string, page, dpi int) (label string, err error) {
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		return "", err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("failed to read page: %v", rec)
+		}
+	}()
+
+	numbering := extraction.NewPageNumbering(r)
+	if page < 1 || page > numbering.Count() {
+		return "", pageOutOfRange(page, numbering.Count())
+	}
+
+	width, height := pageDimensions(numbering.Page(page))
+	if rotation := numbering.Rotation(page); rotation == 90 || rotation == 270 {
+		width, height = height, width
+	}
+	scale := float64(dpi) / pointsPerInch
+	if pixels := width * scale * height * scale; pixels > maxRenderPixels {
+		return "", fmt.Errorf("page %d at %d dpi would be %.0fx%.0f pixels, over the %d pixel limit; use a lower dpi",
+			page, dpi, width*scale, height*scale, maxRenderPixels)
+	}
+	return numbering.Label(page), nil
+}
+
+// findBackend returns the first installed renderer
+func (r *Renderer) findBackend() (renderBackend, error) {
+	names := make([]string, 0, len(r.backends))
+	for _, backend := range r.backends {
+		if _, err := lookProgram(backend.name); err == nil {
+			return backend, nil
+		}
+		names = append(names, backend.name)
+	}
+	return renderBackend{}, pdferrors.New(pdferrors.UnsupportedFeature,
+		"no page renderer is installed: install one of %s "+
+			"(poppler-utils or mupdf-tools)", strings.Join(names, ", "))
+}
+
+// runRenderBackend renders a page to PNG and returns the PNG bytes. Documents of file
+// systems other than the host's are copied to the host for the renderer to read.
+func runRenderBackend(backend renderBackend, path string, page, dpi int) ([]byte, error) {
+	run := programRun{name: backend.name, output: "page.png"}
+	if !fsys.IsHost() {
+		data, err := fsys.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PDF: %w", err)
+		}
+		run.inputs = map[string][]byte{"document.pdf": data}
+	}
+	run.args = func(dir string) []string {
+		document := path
+		if run.inputs != nil {
+			document = filepath.Join(dir, "document.pdf")
+		}
+		return backend.args(document, page, dpi, filepath.Join(dir, run.output))
+	}
+	_, data, err := run.run()
+	return data, err
+}
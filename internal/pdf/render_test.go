@@ -0,0 +1,117 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRenderer returns a renderer whose backend copies a fixed PNG instead of rendering,
+// so the pipeline can be tested without poppler or mupdf installed
+func fakeRenderer(t *testing.T) *Renderer {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, 85, 110))
+	for x := 0; x < 85; x++ {
+		img.Set(x, 10, color.Black)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode fixture: %v", err)
+	}
+	fixture := createTempFile(t, "page.png", buf.String())
+
+	renderer := NewRenderer(100 * 1024 * 1024)
+	renderer.backends = []renderBackend{{
+		name: "cp",
+		args: func(_ string, _, _ int, output string) []string { return []string{fixture, output} },
+	}}
+	return renderer
+}
+
+func TestRenderer_RenderPage(t *testing.T) {
+	renderer := fakeRenderer(t)
+	path := createTempFile(t, "report.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Page one) Tj ET"))
+
+	result, err := renderer.RenderPage(PDFRenderPageRequest{Path: path})
+	if err != nil {
+		t.Fatalf("RenderPage() unexpected error = %v", err)
+	}
+	if result.Page != 1 || result.DPI != defaultRenderDPI || result.Format != RenderFormatPNG {
+		t.Errorf("RenderPage() = page %d at %d dpi as %s, want defaults", result.Page, result.DPI, result.Format)
+	}
+	if result.Width != 85 || result.Height != 110 || result.Renderer != "cp" {
+		t.Errorf("RenderPage() = %dx%d by %s, want 85x110 by cp", result.Width, result.Height, result.Renderer)
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil || len(data) != result.Size || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Errorf("RenderPage() data is not the %d byte PNG (err %v)", result.Size, err)
+	}
+
+	outputDir := filepath.Join(t.TempDir(), "renders")
+	result, err = renderer.RenderPage(PDFRenderPageRequest{Path: path, Format: "jpg", Quality: 70, OutputDir: outputDir})
+	if err != nil {
+		t.Fatalf("RenderPage() unexpected error = %v", err)
+	}
+	if result.Data != "" || result.OutputPath != filepath.Join(outputDir, "report-page-1.jpg") {
+		t.Errorf("RenderPage() = output %q with data %t, want a saved file only", result.OutputPath, result.Data != "")
+	}
+	saved, err := os.ReadFile(result.OutputPath)
+	if err != nil || !bytes.HasPrefix(saved, []byte{0xFF, 0xD8}) || result.MIMEType != "image/jpeg" {
+		t.Errorf("saved render is not a JPEG (err %v, mime %s)", err, result.MIMEType)
+	}
+}
+
+func TestRenderer_RenderPageErrors(t *testing.T) {
+	renderer := fakeRenderer(t)
+	path := createTempFile(t, "report.pdf", buildTestPDF("BT /F1 12 Tf 72 720 Td (Page one) Tj ET"))
+
+	missing := NewRenderer(100 * 1024 * 1024)
+	missing.backends = []renderBackend{{name: "no-such-pdf-renderer"}}
+
+	tests := []struct {
+		name     string
+		renderer *Renderer
+		req      PDFRenderPageRequest
+		errorMsg string
+	}{
+		{name: "empty path", req: PDFRenderPageRequest{}, errorMsg: "path cannot be empty"},
+		{name: "dpi too high", req: PDFRenderPageRequest{Path: path, DPI: 1200}, errorMsg: "dpi must be between"},
+		{name: "unknown format", req: PDFRenderPageRequest{Path: path, Format: "gif"}, errorMsg: "invalid format"},
+		{name: "bad quality", req: PDFRenderPageRequest{Path: path, Quality: 101}, errorMsg: "quality must be"},
+		{
+			name:     "non-existent file",
+			req:      PDFRenderPageRequest{Path: "/non/existent/file.pdf"},
+			errorMsg: "file does not exist",
+		},
+		{name: "page out of range", req: PDFRenderPageRequest{Path: path, Page: 2}, errorMsg: "out of range"},
+		{
+			name:     "no renderer installed",
+			renderer: missing,
+			req:      PDFRenderPageRequest{Path: path},
+			errorMsg: "no page renderer is installed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := renderer
+			if tt.renderer != nil {
+				r = tt.renderer
+			}
+			_, err := r.RenderPage(tt.req)
+			if err == nil {
+				t.Fatal("RenderPage() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("RenderPage() error = %v, want error containing %v", err, tt.errorMsg)
+			}
+		})
+	}
+}
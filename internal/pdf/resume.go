@@ -0,0 +1,493 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Resume extraction constants
+const (
+	// resumeHeaderWords is the longest line that may name a position, employer, or school;
+	// longer lines describe it
+	resumeHeaderWords = 12
+	// resumeHeaderLines is how many lines may name an entry before a line naming another
+	// starts the next entry
+	resumeHeaderLines = 2
+	// resumeHeadingWords is the longest capitalized heading taken as a section without being
+	// a known section name
+	resumeHeadingWords = 4
+)
+
+// Resume sections recognized by their headings
+const (
+	ResumeSectionSummary    = "summary"
+	ResumeSectionExperience = "experience"
+	ResumeSectionEducation  = "education"
+	ResumeSectionSkills     = "skills"
+)
+
+// resumeSections maps the headings of a resume, lowercased and without punctuation, to the
+// sections they open. Headings missing here are kept as other sections.
+var resumeSections = map[string]string{
+	"summary": ResumeSectionSummary, "professional summary": ResumeSectionSummary,
+	"profile": ResumeSectionSummary, "professional profile": ResumeSectionSummary,
+	"objective": ResumeSectionSummary, "career objective": ResumeSectionSummary,
+	"about": ResumeSectionSummary, "about me": ResumeSectionSummary,
+
+	"experience": ResumeSectionExperience, "work experience": ResumeSectionExperience,
+	"professional experience": ResumeSectionExperience, "relevant experience": ResumeSectionExperience,
+	"employment": ResumeSectionExperience, "employment history": ResumeSectionExperience,
+	"work history": ResumeSectionExperience, "career history": ResumeSectionExperience,
+
+	"education": ResumeSectionEducation, "academic background": ResumeSectionEducation,
+	"education and training": ResumeSectionEducation, "academic history": ResumeSectionEducation,
+
+	"skills": ResumeSectionSkills, "technical skills": ResumeSectionSkills, "key skills": ResumeSectionSkills,
+	"core competencies": ResumeSectionSkills, "competencies": ResumeSectionSkills,
+	"skills and expertise": ResumeSectionSkills, "technologies": ResumeSectionSkills,
+}
+
+// otherResumeSections are headings of sections read as plain lines
+var otherResumeSections = []string{
+	"certifications", "certificates", "licenses and certifications", "projects", "languages", "awards",
+	"honors", "honors and awards", "publications", "interests", "hobbies", "references", "volunteer",
+	"volunteering", "volunteer experience", "activities", "courses", "training",
+}
+
+// Dates and date ranges of resume entries: a month and year, a numeric month and year, or
+// a year, up to another or the present
+var (
+	resumeDate = `(?:(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*\.?\s+\d{4}|` +
+		`\d{1,2}/\d{4}|\d{4}-\d{2}|(?:19|20)\d{2})`
+	resumeDateRange = regexp.MustCompile(`(?i)\b(` + resumeDate + `)\s*(?:–|—|-|to|until)\s*(` + resumeDate +
+		`|present|current|now|today|ongoing)\b`)
+	resumeSingleDate = regexp.MustCompile(`(?i)\b(` + resumeDate + `)\b`)
+)
+
+// Parts of resume entries
+var (
+	resumeSeparator = regexp.MustCompile(`\s+(?:at|@|\||—|–|-|·)\s+|,\s+`)
+	resumeLocation  = regexp.MustCompile(`(?:^|[\s,|·–—-]+)((?:\p{Lu}[\p{L}.]*\s?)+,\s*\p{Lu}{2}|Remote)$`)
+	jobTitleWords   = regexp.MustCompile(`(?i)\b(?:engineer|developer|programmer|manager|director|analyst|intern|` +
+		`consultant|designer|lead|specialist|officer|assistant|associate|scientist|architect|administrator|` +
+		`coordinator|head|president|founder|vp|chief|teacher|nurse|accountant|editor|writer|technician|` +
+		`representative|supervisor|researcher|professor|lecturer)\b`)
+	degreeWords = regexp.MustCompile(`(?i)\b(?:B\.?S\.?c?|B\.?A\.?|M\.?S\.?c?|M\.?A\.?|MBA|Ph\.?D\.?|BEng|MEng|` +
+		`B\.?Tech|M\.?Tech|Bachelor|Master|Doctor(?:ate)?|Associate|Diploma|Certificate)\b`)
+	institutionWords = regexp.MustCompile(`(?i)\b(?:university|college|institute|school|academy|polytechnic)\b`)
+	skillSeparator   = regexp.MustCompile(`\s*[,;|•·]\s*`)
+	resumeLink       = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s,;|]+|\b(?:linkedin\.com|github\.com)/[^\s,;|]+`)
+)
+
+// ResumeParser segments resumes into contact details, experience, education, and skills
+type ResumeParser struct {
+	maxFileSize int64
+	validator   *Validator
+	classifier  *Classifier
+}
+
+// NewResumeParser creates a new resume parser with the built-in classification profiles
+func NewResumeParser(maxFileSize int64) *ResumeParser {
+	return &ResumeParser{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+		classifier:  NewClassifier(maxFileSize),
+	}
+}
+
+// SetProfiles sets the classification profiles that decide whether a document is a resume
+func (p *ResumeParser) SetProfiles(custom *ClassificationProfiles) {
+	p.classifier.SetProfiles(custom)
+}
+
+// resumeSection is the lines under a heading of a resume
+type resumeSection struct {
+	heading string
+	kind    string // One of the ResumeSection constants, or empty for other sections
+	lines   []textLine
+}
+
+// resumeEntry is a position or school as read: the short lines naming it, its dates, and
+// the lines describing it
+type resumeEntry struct {
+	headers    []string
+	start, end string
+	dated      bool
+	details    []string
+	page       int
+}
+
+// ExtractResume classifies a document by the text of its first pages and, when it is a
+// resume or req.Force is set, splits it into sections at the headings the structure
+// detector finds or that name a known section. The lines above the first section give the
+// name and, with the email addresses, phone numbers, and street address found as entities on
+// the first page, the contact details. Experience and education are split into entries at
+// lines with dates or after their descriptions; skills are split at commas, semicolons,
+// bars, and bullets.
+func (p *ResumeParser) ExtractResume(
+	ctx context.Context, req PDFExtractResumeRequest,
+) (*PDFExtractResumeResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := core.OpenShared(ctx, req.Path, core.Options{Validate: p.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	numbering := extraction.NewPageNumbering(doc.Reader)
+	result := &PDFExtractResumeResult{Path: req.Path, TotalPages: numbering.Count()}
+	document := newDocumentLines(numbering)
+	defer func() { result.FailedPages = document.failedPages }()
+	if result.DocumentType, result.Score, err = document.classify(ctx, p.classifier); err != nil {
+		return nil, err
+	}
+	result.Detected = result.DocumentType == "resume"
+	if !result.Detected && !req.Force {
+		return result, nil
+	}
+	if err := document.readRest(ctx); err != nil {
+		return nil, err
+	}
+
+	resume := &Resume{Experience: []ResumeExperience{}, Education: []ResumeEducation{}, Skills: []string{}}
+	top, sections := resumeSectionsOf(document)
+	resume.Contact = resumeContact(numbering, top)
+	for _, section := range sections {
+		switch section.kind {
+		case ResumeSectionSummary:
+			resume.Summary = strings.TrimSpace(resume.Summary + " " + joinLines(section.lines))
+		case ResumeSectionExperience:
+			for _, entry := range resumeEntries(section.lines) {
+				resume.Experience = append(resume.Experience, experienceOf(entry))
+			}
+		case ResumeSectionEducation:
+			for _, entry := range resumeEntries(section.lines) {
+				resume.Education = append(resume.Education, educationOf(entry))
+			}
+		case ResumeSectionSkills:
+			resume.Skills = appendSkills(resume.Skills, section.lines)
+		default:
+			other := ResumeSection{Heading: section.heading}
+			for _, line := range section.lines {
+				other.Lines = append(other.Lines, withoutListMarker(line.Text))
+			}
+			resume.Sections = append(resume.Sections, other)
+		}
+	}
+	result.Resume = resume
+	return result, nil
+}
+
+// resumeSectionsOf splits a document's lines at the headings of its sections, returning
+// the lines above the first heading separately
+func resumeSectionsOf(document *documentLines) (top []textLine, sections []resumeSection) {
+	for _, line := range document.lines {
+		heading := strings.Trim(strings.TrimSpace(line.Text), ":")
+		key := sectionKey(heading)
+		kind, known := resumeSections[key]
+		isHeading := known || slices.Contains(otherResumeSections, key) ||
+			(defaultStructure.isHeading(line.pageLine, document.bodySizes[line.page]) &&
+				line.Words <= resumeHeadingWords && strings.ToUpper(heading) == heading &&
+				strings.ContainsFunc(heading, unicode.IsLetter) && !resumeSingleDate.MatchString(heading) &&
+				len(sections) > 0)
+		switch {
+		case isHeading:
+			sections = append(sections, resumeSection{heading: heading, kind: kind})
+		case len(sections) == 0:
+			top = append(top, line)
+		default:
+			last := &sections[len(sections)-1]
+			last.lines = append(last.lines, line)
+		}
+	}
+	return top, sections
+}
+
+// sectionKey lowercases a heading and reduces it to its words, with & read as and
+func sectionKey(heading string) string {
+	heading = strings.ReplaceAll(strings.ToLower(heading), "&", " and ")
+	words := strings.FieldsFunc(heading, func(r rune) bool { return !unicode.IsLetter(r) })
+	return strings.Join(words, " ")
+}
+
+// resumeContact reads the contact details of a resume: its name from the lines above the
+// first section, set largest among those that are only words, and the email addresses,
+// phone numbers, street address, and links of the first page
+func resumeContact(numbering *extraction.PageNumbering, top []textLine) ResumeContact {
+	contact := ResumeContact{}
+	nameSize := 0.0
+	for _, line := range top {
+		text := strings.TrimSpace(line.Text)
+		words := strings.Fields(text)
+		nameLike := len(words) >= 2 && len(words) <= 4 && !strings.ContainsFunc(text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsSpace(r) && !strings.ContainsRune(".-'", r)
+		})
+		if nameLike && line.FontSize > nameSize {
+			contact.Name, nameSize = text, line.FontSize
+		}
+	}
+
+	if numbering.Count() == 0 {
+		return contact
+	}
+	text, err := pageText(numbering, 1)
+	if err != nil {
+		return contact
+	}
+	types := []string{extraction.EntityEmail, extraction.EntityPhone, extraction.EntityAddress}
+	for _, entity := range extraction.FindEntities(text, types) {
+		switch entity.Type {
+		case extraction.EntityEmail:
+			contact.Emails = appendUnique(contact.Emails, entity.Value)
+		case extraction.EntityPhone:
+			contact.Phones = appendUnique(contact.Phones, entity.Value)
+		case extraction.EntityAddress:
+			if contact.Address == "" {
+				contact.Address = entity.Value
+			}
+		}
+	}
+	for _, link := range resumeLink.FindAllString(text.Text, -1) {
+		contact.Links = appendUnique(contact.Links, strings.TrimRight(link, "./"))
+	}
+	return contact
+}
+
+// appendUnique appends a value not already in values
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+// resumeEntries splits the lines of an experience or education section into entries. A
+// short line names the entry: it starts a new one once the current entry has a
+// description, has as many naming lines as an entry takes, or has dates when the line has
+// too. Bulleted and long lines describe the entry, and lowercase lines continue the
+// description above.
+func resumeEntries(lines []textLine) []resumeEntry {
+	var entries []resumeEntry
+	for _, line := range lines {
+		text := strings.TrimSpace(line.Text)
+		marker, bulleted := defaultStructure.listMarker(text)
+		first, _ := firstLetter(text)
+		var current *resumeEntry
+		if len(entries) > 0 {
+			current = &entries[len(entries)-1]
+		}
+
+		switch {
+		case current != nil && !bulleted && len(current.details) > 0 && unicode.IsLower(first):
+			current.details[len(current.details)-1] += " " + text
+			continue
+		case bulleted || len(strings.Fields(text)) > resumeHeaderWords:
+			if current == nil {
+				entries = append(entries, resumeEntry{page: line.page})
+				current = &entries[len(entries)-1]
+			}
+			current.details = append(current.details, strings.TrimSpace(strings.TrimPrefix(text, marker)))
+			continue
+		}
+
+		header, start, end, dated := cutResumeDates(text)
+		if current == nil || len(current.details) > 0 || len(current.headers) >= resumeHeaderLines ||
+			(dated && current.dated) {
+			entries = append(entries, resumeEntry{page: line.page})
+			current = &entries[len(entries)-1]
+		}
+		if header != "" {
+			current.headers = append(current.headers, header)
+		}
+		if dated && !current.dated {
+			current.start, current.end, current.dated = start, end, true
+		}
+	}
+	return entries
+}
+
+// firstLetter returns the first letter of text
+func firstLetter(text string) (rune, bool) {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// cutResumeDates removes a date range, or else a single date, from a line, returning the
+// rest of the line and the dates as YYYY-MM or YYYY. A single date is an end date, as of a
+// graduation; a range ending now ends at "present".
+func cutResumeDates(text string) (rest, start, end string, dated bool) {
+	if m := resumeDateRange.FindStringSubmatchIndex(text); m != nil {
+		start = resumeMonth(text[m[2]:m[3]])
+		end = resumeMonth(text[m[4]:m[5]])
+		rest = text[:m[0]] + " " + text[m[1]:]
+		dated = true
+	} else if m := resumeSingleDate.FindStringSubmatchIndex(text); m != nil {
+		end = resumeMonth(text[m[2]:m[3]])
+		rest = text[:m[0]] + " " + text[m[1]:]
+		dated = true
+	} else {
+		rest = text
+	}
+	rest = strings.Trim(strings.Join(strings.Fields(rest), " "), " ,;|·–—-()")
+	return rest, start, end, dated
+}
+
+// resumeMonth normalizes a resume date as YYYY-MM, or YYYY when it has no month, and the
+// words for now as "present"
+func resumeMonth(text string) string {
+	text = strings.TrimSpace(text)
+	switch lower := strings.ToLower(text); {
+	case lower == "present" || lower == "current" || lower == "now" || lower == "today" || lower == "ongoing":
+		return "present"
+	case len(text) == 4:
+		return text
+	}
+	if month, year, ok := strings.Cut(text, "/"); ok {
+		return fmt.Sprintf("%s-%02s", year, month)
+	}
+	if len(text) == 7 && text[4] == '-' {
+		return text
+	}
+	words := strings.Fields(text)
+	if len(words) == 2 && len(words[0]) >= 3 {
+		month := strings.ToUpper(words[0][:1]) + strings.ToLower(words[0][1:3])
+		if date, err := time.Parse("Jan 2006", month+" "+words[1]); err == nil {
+			return date.Format("2006-01")
+		}
+	}
+	return text
+}
+
+// splitEntryHeader splits the lines naming an entry into their parts, setting aside a
+// trailing location
+func splitEntryHeader(headers []string) (parts []string, location string) {
+	for _, header := range headers {
+		if m := resumeLocation.FindStringSubmatchIndex(header); m != nil && m[0] > 0 {
+			location = header[m[2]:m[3]]
+			header = header[:m[0]]
+		}
+		for _, part := range resumeSeparator.Split(header, -1) {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return parts, location
+}
+
+// experienceOf names the title and company of a position: the part naming a job title is
+// the title and the first other part the company, or else the first part is the title
+func experienceOf(entry resumeEntry) ResumeExperience {
+	experience := ResumeExperience{
+		StartDate:  entry.start,
+		EndDate:    entry.end,
+		Current:    entry.end == "present",
+		Highlights: entry.details,
+		Page:       entry.page,
+	}
+	parts, location := splitEntryHeader(entry.headers)
+	experience.Location = location
+	title := slices.IndexFunc(parts, jobTitleWords.MatchString)
+	if title < 0 && len(parts) > 0 {
+		title = 0
+	}
+	for i, part := range parts {
+		switch {
+		case i == title:
+			experience.Title = part
+		case experience.Company == "":
+			experience.Company = part
+		}
+	}
+	return experience
+}
+
+// educationOf names the degree and institution of a school: the part naming a degree is
+// the degree, the part naming a kind of school the institution, and the first other part
+// fills whichever is missing
+func educationOf(entry resumeEntry) ResumeEducation {
+	education := ResumeEducation{
+		StartDate: entry.start,
+		EndDate:   entry.end,
+		Details:   entry.details,
+		Page:      entry.page,
+	}
+	parts, location := splitEntryHeader(entry.headers)
+	education.Location = location
+	var rest []string
+	for _, part := range parts {
+		switch {
+		case education.Degree == "" && degreeWords.MatchString(part):
+			education.Degree = part
+		case education.Institution == "" && institutionWords.MatchString(part):
+			education.Institution = part
+		default:
+			rest = append(rest, part)
+		}
+	}
+	for _, part := range rest {
+		switch {
+		case education.Institution == "":
+			education.Institution = part
+		case education.Degree == "":
+			education.Degree = part
+		case education.Field == "":
+			education.Field = part
+		}
+	}
+	return education
+}
+
+// appendSkills splits the lines of a skills section into skills, dropping a label before a
+// colon and skills already listed
+func appendSkills(skills []string, lines []textLine) []string {
+	for _, line := range lines {
+		text := withoutListMarker(line.Text)
+		if _, after, ok := strings.Cut(text, ":"); ok {
+			text = after
+		}
+		for _, skill := range skillSeparator.Split(text, -1) {
+			skill = strings.Trim(strings.TrimSpace(skill), ".")
+			if skill == "" || slices.ContainsFunc(skills, func(s string) bool { return strings.EqualFold(s, skill) }) {
+				continue
+			}
+			skills = append(skills, skill)
+		}
+	}
+	return skills
+}
+
+// withoutListMarker removes the bullet or number opening a line
+func withoutListMarker(text string) string {
+	text = strings.TrimSpace(text)
+	if marker, ok := defaultStructure.listMarker(text); ok {
+		text = strings.TrimSpace(strings.TrimPrefix(text, marker))
+	}
+	return text
+}
+
+// joinLines joins the text of lines with single spaces
+func joinLines(lines []textLine) string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = strings.TrimSpace(line.Text)
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,101 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestResumeParser_ExtractResume(t *testing.T) {
+	var b strings.Builder
+	y := 760
+	line := func(size, x int, s string) {
+		fmt.Fprintf(&b, "BT /F1 %d Tf %d %d Td (%s) Tj ET\n", size, x, y, s)
+		y -= size + 6
+	}
+	line(20, 72, "Jane Doe")
+	line(10, 72, "jane.doe@example.com | 555-123-4567 | linkedin.com/in/janedoe")
+	line(14, 72, "SUMMARY")
+	line(10, 72, "Backend engineer with eight years of experience building payment systems.")
+	line(14, 72, "EXPERIENCE")
+	line(10, 72, "Senior Software Engineer, Acme Corp - Austin, TX")
+	line(10, 72, "Jan 2020 - Present")
+	line(10, 80, "- Led the migration of billing to event sourcing")
+	line(10, 80, "- Cut checkout latency by forty percent across")
+	line(10, 90, "all regions")
+	line(10, 72, "Globex | Software Developer")
+	line(10, 72, "06/2016 - 12/2019")
+	line(10, 80, "- Built the reporting pipeline")
+	line(14, 72, "EDUCATION")
+	line(10, 72, "B.S. Computer Science, University of Texas 2016")
+	line(14, 72, "SKILLS")
+	line(10, 72, "Languages: Go, Python, SQL")
+	line(10, 72, "Kafka; PostgreSQL; go")
+	line(14, 72, "CERTIFICATIONS")
+	line(10, 72, "AWS Certified Solutions Architect")
+	path := createTempFile(t, "resume.pdf", buildTestPDF(b.String()))
+
+	parser := NewResumeParser(100 * 1024 * 1024)
+	result, err := parser.ExtractResume(context.Background(), PDFExtractResumeRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractResume() unexpected error = %v", err)
+	}
+	if !result.Detected || result.DocumentType != "resume" || result.Resume == nil {
+		t.Fatalf("ExtractResume() = %+v, want a resume", result)
+	}
+	resume := result.Resume
+
+	contact := resume.Contact
+	if contact.Name != "Jane Doe" || fmt.Sprint(contact.Emails) != "[jane.doe@example.com]" ||
+		len(contact.Phones) != 1 || fmt.Sprint(contact.Links) != "[linkedin.com/in/janedoe]" {
+		t.Errorf("contact = %+v, want Jane Doe's email, phone, and LinkedIn", contact)
+	}
+	if !strings.HasPrefix(resume.Summary, "Backend engineer") {
+		t.Errorf("summary = %q", resume.Summary)
+	}
+
+	var got []string
+	for _, job := range resume.Experience {
+		got = append(got, fmt.Sprintf("%s|%s|%s|%s|%s|%v|%q", job.Title, job.Company, job.Location,
+			job.StartDate, job.EndDate, job.Current, job.Highlights))
+	}
+	want := []string{
+		`Senior Software Engineer|Acme Corp|Austin, TX|2020-01|present|true|["Led the migration of billing to ` +
+			`event sourcing" "Cut checkout latency by forty percent across all regions"]`,
+		`Software Developer|Globex||2016-06|2019-12|false|["Built the reporting pipeline"]`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("experience =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if len(resume.Education) != 1 || resume.Education[0].Degree != "B.S. Computer Science" ||
+		resume.Education[0].Institution != "University of Texas" || resume.Education[0].EndDate != "2016" {
+		t.Errorf("education = %+v, want the B.S. from the University of Texas", resume.Education)
+	}
+	if fmt.Sprint(resume.Skills) != "[Go Python SQL Kafka PostgreSQL]" {
+		t.Errorf("skills = %q, want five distinct skills", resume.Skills)
+	}
+	if len(resume.Sections) != 1 || resume.Sections[0].Heading != "CERTIFICATIONS" {
+		t.Errorf("sections = %+v, want the certifications", resume.Sections)
+	}
+}
+
+func TestCutResumeDates(t *testing.T) {
+	tests := []struct {
+		text, rest, start, end string
+	}{
+		{"Acme Corp, March 2019 to June 2021", "Acme Corp", "2019-03", "2021-06"},
+		{"Globex 2015-2018", "Globex", "2015", "2018"},
+		{"Initech (2021-05 - current)", "Initech", "2021-05", "present"},
+		{"MIT, 2012", "MIT", "", "2012"},
+		{"Software Engineer", "Software Engineer", "", ""},
+	}
+	for _, tt := range tests {
+		rest, start, end, _ := cutResumeDates(tt.text)
+		if rest != tt.rest || start != tt.start || end != tt.end {
+			t.Errorf("cutResumeDates(%q) = %q, %q, %q, want %q, %q, %q",
+				tt.text, rest, start, end, tt.rest, tt.start, tt.end)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+	"github.com/ledongthuc/pdf"
+)
+
+// sanitizedMIMEType is the MIME type of sanitized documents
+const sanitizedMIMEType = "application/pdf"
+
+// sanitizeKeptInfo are the Info dictionary entries that describe the content rather than
+// who made the document and how, and are kept when metadata is removed
+var sanitizeKeptInfo = []string{"Title", "Subject", "Keywords"}
+
+// Sanitizer writes copies of PDFs without sensitive metadata and active or hidden content,
+// as needed before documents are shared outside an organization
+type Sanitizer struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewSanitizer creates a new sanitizer with the specified constraints
+func NewSanitizer(maxFileSize int64) *Sanitizer {
+	return &Sanitizer{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// sanitization is what a document writer leaves out of the copy pdf_sanitize writes, and
+// the tally of what it left out
+type sanitization struct {
+	metadata    bool
+	javascript  bool
+	attachments bool
+	result      *PDFSanitizeResult
+}
+
+// Sanitize writes a copy of a document without its authoring metadata (the Info entries
+// other than the title, subject, and keywords, XMP metadata with its edit history, and
+// application private data), JavaScript and launch actions, embedded files, and the content
+// of layers hidden by default. Hidden layers are removed from page content streams and
+// annotations; content inside form XObjects is left in place. Encrypted documents are not
+// supported.
+func (s *Sanitizer) Sanitize(ctx context.Context, req PDFSanitizeRequest) (*PDFSanitizeResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: s.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	reader := doc.Reader
+	trailer := reader.Trailer()
+	if !trailer.Key("Encrypt").IsNull() {
+		return nil, fmt.Errorf("cannot sanitize encrypted documents")
+	}
+
+	result := &PDFSanitizeResult{Path: req.Path, MIMEType: sanitizedMIMEType}
+	writer := newDocumentWriter(doc)
+	writer.sanitize = &sanitization{
+		metadata:    !req.KeepMetadata,
+		javascript:  !req.KeepJavaScript,
+		attachments: !req.KeepAttachments,
+		result:      result,
+	}
+
+	if edit, ok := sanitizeInfo(trailer.Key("Info"), req, result); ok {
+		writer.edits[extraction.RefOf(trailer.Key("Info"))] = edit
+	}
+
+	hidden := map[extraction.ObjectRef]string{}
+	if !req.KeepHiddenLayers {
+		hidden = extraction.HiddenLayers(reader)
+		for _, name := range hidden {
+			result.HiddenLayers = append(result.HiddenLayers, name)
+		}
+		sort.Strings(result.HiddenLayers)
+	}
+
+	numbering := extraction.NewPageNumbering(reader)
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page := numbering.Page(pageNum)
+		content, removed, err := extraction.StripHiddenLayers(page, hidden)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("page %d: hidden layers not removed: %v", pageNum, err))
+		}
+		result.HiddenContent += removed
+
+		var dropped []int
+		annots := page.V.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			annot := annots.Index(i)
+			switch {
+			case !req.KeepAttachments && annot.Key("Subtype").Name() == "FileAttachment":
+				result.EmbeddedFiles++
+			case extraction.InHiddenLayer(annot.Key("OC"), hidden):
+				result.HiddenContent++
+			default:
+				continue
+			}
+			dropped = append(dropped, i)
+		}
+		if content != nil || len(dropped) > 0 {
+			writer.edits[extraction.RefOf(page.V)] = sanitizePageEdit(page, content, dropped)
+		}
+	}
+
+	data, err := writer.write(trailer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write sanitized document: %w", err)
+	}
+	result.Size = len(data)
+
+	if req.OutputDir == "" {
+		result.Data = base64.StdEncoding.EncodeToString(data)
+		return result, nil
+	}
+	if err := fsys.MkdirAll(req.OutputDir, attachmentDirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create output directory %s: %w", req.OutputDir, err)
+	}
+	stem := strings.TrimSuffix(filepath.Base(req.Path), filepath.Ext(req.Path))
+	result.OutputPath = filepath.Join(req.OutputDir, stem+"-sanitized.pdf")
+	if err := fsys.WriteFile(result.OutputPath, data, exportFilePerm); err != nil {
+		return nil, fmt.Errorf("failed to save sanitized document: %w", err)
+	}
+	return result, nil
+}
+
+// sanitizeInfo returns the edit writing the Info dictionary without its authoring entries
+// and with the requested author, recording the entries it removes or replaces. A direct Info
+// dictionary cannot be edited and is noted.
+func sanitizeInfo(info pdf.Value, req PDFSanitizeRequest, result *PDFSanitizeResult) (objectEdit, bool) {
+	if info.Kind() != pdf.Dict {
+		if req.Author != "" {
+			result.Warnings = append(result.Warnings, "the document has no Info dictionary to record the author in")
+		}
+		return nil, false
+	}
+
+	replace := map[string]string{}
+	if !req.KeepMetadata {
+		for _, key := range info.Keys() {
+			if !slices.Contains(sanitizeKeptInfo, key) {
+				replace[key] = ""
+			}
+		}
+	}
+	if req.Author != "" {
+		var author bytes.Buffer
+		writeTextString(&author, req.Author)
+		replace["Author"] = author.String()
+	}
+	if len(replace) == 0 {
+		return nil, false
+	}
+	if extraction.RefOf(info).ID == 0 {
+		result.Warnings = append(result.Warnings, "the Info dictionary is stored in the trailer and was copied as is")
+		return nil, false
+	}
+	for key := range replace {
+		result.InfoEntries = append(result.InfoEntries, key)
+	}
+	sort.Strings(result.InfoEntries)
+	return replaceEdit(replace), true
+}
+
+// sanitizePageEdit writes a page with its content stream replaced, when content is not nil,
+// and without the annotations at the dropped indexes of its Annots array
+func sanitizePageEdit(page pdf.Page, content []byte, dropped []int) objectEdit {
+	return func(w *documentWriter, b *bytes.Buffer, v pdf.Value) error {
+		replace := map[string]string{}
+		if content != nil {
+			contents, err := w.addStream(pdf.Value{}, nil, content)
+			if err != nil {
+				return err
+			}
+			replace["Contents"] = fmt.Sprintf("%d 0 R", contents)
+		}
+
+		if len(dropped) > 0 {
+			annots := page.V.Key("Annots")
+			var kept bytes.Buffer
+			kept.WriteByte('[')
+			for i := 0; i < annots.Len(); i++ {
+				if slices.Contains(dropped, i) {
+					continue
+				}
+				if kept.Len() > 1 {
+					kept.WriteByte(' ')
+				}
+				if err := w.child(&kept, annots.Index(i), extraction.RefOf(annots), 1); err != nil {
+					return err
+				}
+			}
+			kept.WriteByte(']')
+			replace["Annots"] = kept.String()
+		}
+
+		return w.dict(b, v, replace, 0)
+	}
+}
+
+// drops reports whether a dictionary entry is left out of the copy, counting what it holds:
+// XMP metadata and private data, JavaScript and launch actions and the document's named
+// scripts, and embedded files wherever they are attached
+func (s *sanitization) drops(key string, v pdf.Value) bool {
+	if s == nil {
+		return false
+	}
+	switch {
+	case s.metadata && key == "Metadata" && v.Kind() == pdf.Stream:
+		s.result.MetadataStreams++
+	case s.metadata && key == "PieceInfo":
+		s.result.PrivateData++
+	case s.javascript && key == "JavaScript" && v.Kind() == pdf.Dict:
+		extraction.WalkNameTree(v, func(string, pdf.Value) { s.result.JavaScriptActions++ })
+	case s.javascript && v.Kind() == pdf.Dict && slices.Contains([]string{"JavaScript", "Launch"}, v.Key("S").Name()):
+		s.result.JavaScriptActions++
+	case s.attachments && key == "EmbeddedFiles" && v.Kind() == pdf.Dict:
+		extraction.WalkNameTree(v, func(string, pdf.Value) { s.result.EmbeddedFiles++ })
+	case s.attachments && key == "EF":
+		s.result.EmbeddedFiles++
+	default:
+		return false
+	}
+	return true
+}
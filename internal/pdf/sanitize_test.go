@@ -0,0 +1,114 @@
+package pdf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// sensitivePDF builds a one-page document with authoring metadata, XMP metadata, a document
+// script and an open action script, an embedded file attached both to the document and to an
+// annotation, and a layer hidden by default beside one that is shown
+func sensitivePDF() string {
+	content := "BT /F1 12 Tf 72 720 Td (Public text) Tj ET\n" +
+		"/OC /Shown BDC BT /F1 12 Tf 72 700 Td (Layer text) Tj ET EMC\n" +
+		"/OC /Hidden BDC BT /F1 12 Tf 72 680 Td (Secret notes) Tj ET EMC"
+	xmp := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><xmpMM:History>saved</xmpMM:History></x:xmpmeta>`
+	stream := func(dict, data string) string {
+		return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+	}
+	raw := buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Metadata 6 0 R /Names << /JavaScript 7 0 R /EmbeddedFiles 8 0 R >> " +
+			"/OpenAction << /S /JavaScript /JS (app.alert\\(1\\)) >> " +
+			"/OCProperties << /OCGs [9 0 R 10 0 R] /D << /OFF [10 0 R] >> >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 5 0 R /Annots [11 0 R] " +
+			"/Resources << /Font << /F1 4 0 R >> /Properties << /Shown 9 0 R /Hidden 10 0 R >> >> >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		stream("", content),
+		stream("/Type /Metadata /Subtype /XML", xmp),
+		"<< /Names [(init) << /S /JavaScript /JS (app.alert\\(2\\)) >>] >>",
+		"<< /Names [(notes.txt) 12 0 R] >>",
+		"<< /Type /OCG /Name (Shown) >>",
+		"<< /Type /OCG /Name (Reviewer notes) >>",
+		"<< /Type /Annot /Subtype /FileAttachment /Rect [72 600 82 610] /FS 12 0 R >>",
+		"<< /Type /Filespec /F (notes.txt) /EF << /F 13 0 R >> >>",
+		stream("/Type /EmbeddedFile", "hello"),
+		"<< /Title (Quarterly report) /Author (Jane Doe) /Producer (Acme Writer) /CreationDate (D:20240101) >>",
+	})
+	return strings.Replace(raw, "/Root 1 0 R >>", "/Root 1 0 R /Info 14 0 R >>", 1)
+}
+
+func TestSanitizer_Sanitize(t *testing.T) {
+	path := createTempFile(t, "sensitive.pdf", sensitivePDF())
+
+	result, err := NewSanitizer(10*1024*1024).Sanitize(context.Background(),
+		PDFSanitizeRequest{Path: path, Author: "Legal"})
+	if err != nil {
+		t.Fatalf("Sanitize() unexpected error = %v", err)
+	}
+	if fmt.Sprint(result.InfoEntries) != "[Author CreationDate Producer]" || result.MetadataStreams != 1 ||
+		result.JavaScriptActions != 2 || result.EmbeddedFiles != 2 ||
+		fmt.Sprint(result.HiddenLayers) != "[Reviewer notes]" || result.HiddenContent != 1 {
+		t.Errorf("Sanitize() = %+v", result)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil {
+		t.Fatalf("sanitized data is not base64: %v", err)
+	}
+	sanitized := filepath.Join(t.TempDir(), "sanitized.pdf")
+	if err := os.WriteFile(sanitized, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, r, err := pdf.Open(sanitized)
+	if err != nil {
+		t.Fatalf("sanitized document does not open: %v", err)
+	}
+	defer f.Close()
+
+	info := r.Trailer().Key("Info")
+	if info.Key("Title").Text() != "Quarterly report" || info.Key("Author").Text() != "Legal" ||
+		!info.Key("Producer").IsNull() {
+		t.Errorf("Info = %v, want the title kept and the author replaced", info)
+	}
+	catalog := r.Trailer().Key("Root")
+	for _, key := range []string{"Metadata", "OpenAction"} {
+		if !catalog.Key(key).IsNull() {
+			t.Errorf("catalog still has %s", key)
+		}
+	}
+	if names := catalog.Key("Names"); len(names.Keys()) != 0 {
+		t.Errorf("catalog names = %v, want no scripts or embedded files", names.Keys())
+	}
+
+	page := r.Page(1)
+	if page.V.Key("Annots").Len() != 0 {
+		t.Errorf("page annotations = %v, want the file attachment removed", page.V.Key("Annots"))
+	}
+	text, err := extraction.PlainText(page)
+	if err != nil {
+		t.Fatalf("PlainText() unexpected error = %v", err)
+	}
+	if !strings.Contains(text, "Layer text") || strings.Contains(text, "Secret") {
+		t.Errorf("page text = %q, want the shown layer without the hidden one", text)
+	}
+
+	// Kept content is copied as is
+	result, err = NewSanitizer(10*1024*1024).Sanitize(context.Background(), PDFSanitizeRequest{Path: path,
+		KeepMetadata: true, KeepJavaScript: true, KeepAttachments: true, KeepHiddenLayers: true})
+	if err != nil {
+		t.Fatalf("Sanitize() unexpected error = %v", err)
+	}
+	if len(result.InfoEntries) > 0 || result.MetadataStreams+result.JavaScriptActions+result.EmbeddedFiles+
+		result.HiddenContent > 0 {
+		t.Errorf("Sanitize() keeping everything = %+v, want nothing removed", result)
+	}
+}
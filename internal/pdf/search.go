@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
 )
 
 // Search handles PDF search and discovery operations
@@ -28,14 +30,14 @@ func (s *Search) SearchDirectory(req PDFSearchDirectoryRequest) (*PDFSearchDirec
 	}
 
 	// Check if directory exists
-	if _, err := os.Stat(req.Directory); os.IsNotExist(err) {
+	if _, err := fsys.Stat(req.Directory); os.IsNotExist(err) {
 		return nil, fmt.Errorf("directory does not exist: %s", req.Directory)
 	}
 
 	var pdfFiles []FileInfo
 	query := strings.ToLower(strings.TrimSpace(req.Query))
 
-	err := filepath.Walk(req.Directory, func(path string, info os.FileInfo, err error) error {
+	err := fsys.Walk(req.Directory, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			// Continue walking even if we encounter an error with a specific file
 			return nil //nolint:nilerr // Intentionally continue on file errors
@@ -187,7 +189,7 @@ func (s *Search) SearchByPattern(directory, pattern string) (*PDFSearchDirectory
 
 	var pdfFiles []FileInfo
 
-	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+	err := fsys.Walk(directory, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil //nolint:nilerr // Intentionally continue on file errors
 		}
@@ -0,0 +1,198 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Section kinds reported for outline sections; appendices report their own kind
+const sectionKindOutline = "section"
+
+// Sections handles extraction of a single named section, appendix, or exhibit
+type Sections struct {
+	maxFileSize int64
+	validator   *Validator
+	reader      *Reader
+}
+
+// NewSections creates a new section extractor with the specified constraints
+func NewSections(maxFileSize int64) *Sections {
+	return &Sections{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+		reader:      NewReader(maxFileSize),
+	}
+}
+
+// sectionCandidate is a named unit of a document and the pages it spans
+type sectionCandidate struct {
+	kind      string
+	label     string
+	title     string
+	startPage int
+	endPage   int
+}
+
+// ExtractSection returns the text of one section, addressed by appendix name ("Exhibit A",
+// "Annex 3"), by appendix label ("A"), or by outline title
+func (s *Sections) ExtractSection(req PDFExtractSectionRequest) (result *PDFExtractSectionResult, err error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	if strings.TrimSpace(req.Section) == "" {
+		return nil, fmt.Errorf("section cannot be empty")
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: s.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			result = nil
+			err = fmt.Errorf("failed to read section: %v", rec)
+		}
+	}()
+
+	numbering := extraction.NewPageNumbering(r)
+	outline := readOutline(req.Path, r, numbering)
+	candidates := sectionCandidates(outline, numbering.Count())
+
+	section, err := findSection(candidates, req.Section)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]int, 0, section.endPage-section.startPage+1)
+	for pageNum := section.startPage; pageNum <= section.endPage; pageNum++ {
+		pages = append(pages, pageNum)
+	}
+
+	content, err := s.reader.extractTextContent(numbering, &PageSelection{TotalPages: numbering.Count(), Pages: pages},
+		plainPageText(numbering))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PDFExtractSectionResult{
+		Path:      req.Path,
+		Section:   req.Section,
+		Kind:      section.kind,
+		Label:     section.label,
+		Title:     section.title,
+		StartPage: section.startPage,
+		EndPage:   section.endPage,
+		Content:   content,
+	}, nil
+}
+
+// sectionCandidates lists the appendices followed by every outline item with a page. An
+// outline section runs until the next item at the same or a higher level.
+func sectionCandidates(outline *PDFGetOutlineResult, totalPages int) []sectionCandidate {
+	candidates := make([]sectionCandidate, 0, len(outline.Appendices))
+	for _, appendix := range outline.Appendices {
+		candidates = append(candidates, sectionCandidate{
+			kind:      appendix.Kind,
+			label:     appendix.Label,
+			title:     appendix.Title,
+			startPage: appendix.StartPage,
+			endPage:   appendix.EndPage,
+		})
+	}
+
+	var flat []OutlineItem
+	var flatten func(items []OutlineItem)
+	flatten = func(items []OutlineItem) {
+		for _, item := range items {
+			if item.Page > 0 {
+				flat = append(flat, item)
+			}
+			flatten(item.Children)
+		}
+	}
+	flatten(outline.Items)
+
+	for i, item := range flat {
+		endPage := totalPages
+		for _, next := range flat[i+1:] {
+			if next.Level <= item.Level {
+				endPage = max(next.Page-1, item.Page)
+				break
+			}
+		}
+		candidates = append(candidates, sectionCandidate{
+			kind:      sectionKindOutline,
+			title:     item.Title,
+			startPage: item.Page,
+			endPage:   min(endPage, totalPages),
+		})
+	}
+
+	return candidates
+}
+
+// findSection picks the candidate named by query, preferring an exact appendix name, then a
+// unique appendix label, then an exact outline title, then a unique outline title prefix
+func findSection(candidates []sectionCandidate, query string) (sectionCandidate, error) {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+
+	matchers := []func(c sectionCandidate) bool{
+		func(c sectionCandidate) bool {
+			if c.kind == sectionKindOutline {
+				return false
+			}
+			// Labels are case-sensitive in headings so that "Schedule of fees" is not "Schedule OF"
+			kind, label, _, ok := parseAppendixHeading(strings.ToUpper(query))
+			return ok && kind == c.kind && strings.EqualFold(label, c.label)
+		},
+		func(c sectionCandidate) bool {
+			return c.kind != sectionKindOutline && strings.EqualFold(query, c.label)
+		},
+		func(c sectionCandidate) bool {
+			return c.kind == sectionKindOutline && strings.EqualFold(query, c.title)
+		},
+		func(c sectionCandidate) bool {
+			return c.kind == sectionKindOutline && strings.HasPrefix(strings.ToLower(c.title), query)
+		},
+	}
+
+	for _, matches := range matchers {
+		var found []sectionCandidate
+		for _, candidate := range candidates {
+			if matches(candidate) {
+				found = append(found, candidate)
+			}
+		}
+		switch {
+		case len(found) == 1:
+			return found[0], nil
+		case len(found) > 1:
+			return sectionCandidate{}, fmt.Errorf("section %q is ambiguous: matches %s", query, sectionNames(found))
+		}
+	}
+
+	if len(candidates) == 0 {
+		return sectionCandidate{}, fmt.Errorf("section %q not found: document has no outline or appendices", query)
+	}
+	return sectionCandidate{}, fmt.Errorf("section %q not found; available sections: %s", query, sectionNames(candidates))
+}
+
+// sectionNames lists candidates for error messages
+func sectionNames(candidates []sectionCandidate) string {
+	names := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if c.kind == sectionKindOutline {
+			names = append(names, fmt.Sprintf("%q", c.title))
+		} else {
+			names = append(names, fmt.Sprintf("%q", Appendix{Kind: c.kind, Label: c.label}.Name()))
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,316 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+// Risk levels of pdf_security_scan findings; a document's risk is that of its most severe
+// finding
+const (
+	SecurityRiskNone   = "none"
+	SecurityRiskLow    = "low"
+	SecurityRiskMedium = "medium"
+	SecurityRiskHigh   = "high"
+)
+
+// Categories of pdf_security_scan findings
+const (
+	SecurityFindingJavaScript   = "javascript"          // JavaScript action
+	SecurityFindingLaunch       = "launch"              // Action opening a file or running a program
+	SecurityFindingExecutable   = "embedded_executable" // Embedded program or script
+	SecurityFindingEmbeddedFile = "embedded_file"       // Other embedded file
+	SecurityFindingURI          = "suspicious_uri"      // URI action whose address is suspicious
+	SecurityFindingXFAScript    = "xfa_script"          // Script in an XFA form
+)
+
+// securityScriptExcerpt is how many characters of a script a finding quotes
+const securityScriptExcerpt = 80
+
+// executableExtensions are file name extensions of programs and scripts that run when opened
+var executableExtensions = []string{
+	".app", ".bat", ".cmd", ".com", ".cpl", ".dll", ".exe", ".hta", ".jar", ".js", ".jse", ".lnk",
+	".msi", ".pif", ".ps1", ".scr", ".sh", ".vbe", ".vbs", ".wsf",
+}
+
+// executableSignatures are the leading bytes of executable formats: PE, ELF, Mach-O, and
+// scripts with an interpreter line
+var executableSignatures = [][]byte{
+	[]byte("MZ"), []byte("\x7fELF"), {0xcf, 0xfa, 0xed, 0xfe}, {0xce, 0xfa, 0xed, 0xfe},
+	{0xca, 0xfe, 0xba, 0xbe}, []byte("#!"),
+}
+
+// webSchemes are the URI schemes links are expected to use
+var webSchemes = []string{"http", "https", "mailto"}
+
+// SecurityScanner looks for active content in PDFs, such as scripts and launch actions, so
+// that dangerous files can be refused before they are processed
+type SecurityScanner struct {
+	maxFileSize int64
+	validator   *Validator
+	attachments *Attachments
+}
+
+// NewSecurityScanner creates a new security scanner with the specified constraints
+func NewSecurityScanner(maxFileSize int64) *SecurityScanner {
+	return &SecurityScanner{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+		attachments: NewAttachments(maxFileSize),
+	}
+}
+
+// Scan reports a document's JavaScript at the document, page, annotation, and field levels,
+// its launch actions, embedded executables, URI actions to suspicious addresses, and XFA
+// scripts, and rates the risk they pose. Scripts and launches that run without the user
+// acting on the content, such as an open action, are high risk, as are embedded executables
+// and XFA scripts; scripts run by links and fields and suspicious URIs are medium risk, and
+// other embedded files low risk. Content is only read, never run.
+func (s *SecurityScanner) Scan(ctx context.Context, req PDFSecurityScanRequest) (*PDFSecurityScanResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: s.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	reader := doc.Reader
+
+	result := &PDFSecurityScanResult{Path: req.Path, Findings: []SecurityFinding{}}
+	numbering := extraction.NewPageNumbering(reader)
+	for _, action := range extraction.DocumentActions(reader, numbering) {
+		s.scanAction(result, action)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.scanEmbeddedFiles(result, reader, numbering)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.scanXFA(result, reader)
+
+	result.Risk = SecurityRiskNone
+	for _, finding := range result.Findings {
+		if securityRiskRank(finding.Risk) > securityRiskRank(result.Risk) {
+			result.Risk = finding.Risk
+		}
+	}
+	result.Summary = securitySummary(result)
+	return result, nil
+}
+
+// scanAction records the findings of one action
+func (s *SecurityScanner) scanAction(result *PDFSecurityScanResult, action extraction.Action) {
+	finding := SecurityFinding{
+		Location:  actionLocation(action),
+		Page:      action.Page,
+		Automatic: action.Automatic,
+	}
+	switch action.Type {
+	case "JavaScript":
+		result.JavaScriptActions++
+		finding.Category = SecurityFindingJavaScript
+		finding.Risk = SecurityRiskMedium
+		if action.Automatic {
+			result.AutomaticJavaScript++
+			finding.Risk = SecurityRiskHigh
+		}
+		finding.Detail = scriptExcerpt(action.Script)
+	case "Launch":
+		result.LaunchActions++
+		finding.Category = SecurityFindingLaunch
+		finding.Risk = SecurityRiskHigh
+		finding.Detail = "launches " + action.Target
+		if action.Target == "" {
+			finding.Detail = "launches an application"
+		}
+	case "URI":
+		result.URIActions++
+		reason := suspiciousURI(action.Target)
+		if reason == "" {
+			return
+		}
+		result.SuspiciousURIs++
+		finding.Category = SecurityFindingURI
+		finding.Risk = SecurityRiskMedium
+		finding.Detail = fmt.Sprintf("%s %s", action.Target, reason)
+	default:
+		return
+	}
+	result.Findings = append(result.Findings, finding)
+}
+
+// scanEmbeddedFiles records the files embedded in the document and attached to annotations,
+// flagging programs by their name or leading bytes
+func (s *SecurityScanner) scanEmbeddedFiles(
+	result *PDFSecurityScanResult, r *pdf.Reader, numbering *extraction.PageNumbering,
+) {
+	// The parser panics on malformed objects; files found so far are kept
+	defer func() {
+		_ = recover()
+	}()
+
+	add := func(spec pdf.Value, key string, page int) {
+		info, payload := s.attachments.readFileSpec(spec, key)
+		result.EmbeddedFiles++
+		finding := SecurityFinding{
+			Category: SecurityFindingEmbeddedFile,
+			Risk:     SecurityRiskLow,
+			Location: "embedded files",
+			Page:     page,
+			Detail:   info.Name,
+		}
+		if page > 0 {
+			finding.Location = "file attachment annotation"
+		}
+		if executable := isExecutable(info.Name, payload); executable != "" {
+			result.EmbeddedExecutables++
+			finding.Category = SecurityFindingExecutable
+			finding.Risk = SecurityRiskHigh
+			finding.Detail = fmt.Sprintf("%s (%s)", info.Name, executable)
+		}
+		result.Findings = append(result.Findings, finding)
+	}
+
+	embedded := r.Trailer().Key("Root").Key("Names").Key("EmbeddedFiles")
+	extraction.WalkNameTree(embedded, func(key string, spec pdf.Value) {
+		add(spec, key, 0)
+	})
+	for pageNum := 1; pageNum <= numbering.Count(); pageNum++ {
+		annots := numbering.Page(pageNum).V.Key("Annots")
+		for i := 0; i < annots.Len(); i++ {
+			if annot := annots.Index(i); annot.Key("Subtype").Name() == "FileAttachment" {
+				add(annot.Key("FS"), annot.Key("Contents").Text(), pageNum)
+			}
+		}
+	}
+}
+
+// scanXFA records whether the form is an XFA form and how many scripts its template holds.
+// The XFA packets are a single stream or an array of packet names and streams.
+func (s *SecurityScanner) scanXFA(result *PDFSecurityScanResult, r *pdf.Reader) {
+	// The parser panics on malformed objects
+	defer func() {
+		_ = recover()
+	}()
+
+	xfa := r.Trailer().Key("Root").Key("AcroForm").Key("XFA")
+	var packets []pdf.Value
+	switch xfa.Kind() {
+	case pdf.Stream:
+		packets = append(packets, xfa)
+	case pdf.Array:
+		for i := 1; i < xfa.Len(); i += 2 {
+			packets = append(packets, xfa.Index(i))
+		}
+	default:
+		return
+	}
+
+	result.XFA = true
+	for _, packet := range packets {
+		if data, err := s.attachments.readStream(packet); err == nil {
+			result.XFAScripts += bytes.Count(bytes.ToLower(data), []byte("<script"))
+		}
+	}
+	if result.XFAScripts > 0 {
+		result.Findings = append(result.Findings, SecurityFinding{
+			Category: SecurityFindingXFAScript,
+			Risk:     SecurityRiskHigh,
+			Location: "XFA form",
+			Detail:   fmt.Sprintf("%d scripts in the form template", result.XFAScripts),
+		})
+	}
+}
+
+// actionLocation describes where an action is attached and what runs it, such as
+// "document open" or `field "total" calculate`
+func actionLocation(action extraction.Action) string {
+	switch {
+	case action.Source == extraction.ActionSourceField:
+		return fmt.Sprintf("field %q %s", action.Name, action.Trigger)
+	case action.Source == extraction.ActionSourceAnnotation:
+		return fmt.Sprintf("%s annotation %s", action.Name, action.Trigger)
+	case action.Name != "":
+		return fmt.Sprintf("document script %q", action.Name)
+	}
+	return fmt.Sprintf("%s %s", action.Source, action.Trigger)
+}
+
+// scriptExcerpt returns the start of a script on one line
+func scriptExcerpt(script string) string {
+	script = strings.Join(strings.Fields(script), " ")
+	if runes := []rune(script); len(runes) > securityScriptExcerpt {
+		return string(runes[:securityScriptExcerpt]) + "…"
+	}
+	return script
+}
+
+// suspiciousURI returns why a URI is suspicious, or an empty string: it does not parse, uses
+// a scheme other than the web and mail ones, names its host by IP address or in punycode, or
+// puts credentials before the host, which hides the real host from readers
+func suspiciousURI(uri string) string {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return "cannot be parsed"
+	}
+	scheme := strings.ToLower(u.Scheme)
+	switch {
+	case !slices.Contains(webSchemes, scheme):
+		return fmt.Sprintf("uses the %q scheme", scheme)
+	case scheme == "mailto":
+		return ""
+	case u.User != nil:
+		return "hides its host behind credentials"
+	case net.ParseIP(u.Hostname()) != nil:
+		return "points at an IP address"
+	case strings.Contains(strings.ToLower(u.Hostname()), "xn--"):
+		return "uses a punycode host name"
+	}
+	return ""
+}
+
+// isExecutable returns why an embedded file is taken to be a program, or an empty string
+func isExecutable(name string, payload []byte) string {
+	if ext := strings.ToLower(filepath.Ext(name)); slices.Contains(executableExtensions, ext) {
+		return ext + " file"
+	}
+	for _, signature := range executableSignatures {
+		if bytes.HasPrefix(payload, signature) {
+			return "executable content"
+		}
+	}
+	return ""
+}
+
+// securityRiskRank orders risk levels from none to high
+func securityRiskRank(risk string) int {
+	return slices.Index([]string{SecurityRiskNone, SecurityRiskLow, SecurityRiskMedium, SecurityRiskHigh}, risk)
+}
+
+// securitySummary describes the risk of a document in a sentence
+func securitySummary(result *PDFSecurityScanResult) string {
+	switch result.Risk {
+	case SecurityRiskHigh:
+		return "the document carries content that can run code or open other programs; do not process it " +
+			"unless its source is trusted"
+	case SecurityRiskMedium:
+		return "the document carries scripts or links that run when the user interacts with it"
+	case SecurityRiskLow:
+		return "the document carries embedded files but no active content"
+	}
+	return "no active content was found"
+}
@@ -0,0 +1,98 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// activeContentPDF builds a one-page document that opens with a script and has a named
+// document script, an embedded executable, links to an IP address, a web site, and a
+// launched program, a field with a keystroke script, and an XFA form with a script
+func activeContentPDF() string {
+	xfa := `<template><field name="total"><event activity="initialize"><script>app.launchURL("x")</script>` +
+		`</event></field></template>`
+	stream := func(dict, data string) string {
+		return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+	}
+	return buildRawPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /OpenAction << /S /JavaScript /JS (this.exportDataObject\\(\\)) >> " +
+			"/Names << /JavaScript << /Names [(init) << /S /JavaScript /JS (var a = 1;) >>] >> " +
+			"/EmbeddedFiles << /Names [(invoice.pdf.exe) 4 0 R] >> >> " +
+			"/AcroForm << /Fields [8 0 R] /XFA 9 0 R >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Annots [6 0 R 7 0 R 10 0 R 8 0 R] >>",
+		"<< /Type /Filespec /F (invoice.pdf.exe) /EF << /F 5 0 R >> >>",
+		stream("/Type /EmbeddedFile", "MZ\x90\x00"),
+		"<< /Type /Annot /Subtype /Link /Rect [72 700 200 712] /A << /S /URI /URI (http://192.0.2.7/login) >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [72 680 200 692] /A << /S /Launch /F (cmd.exe) " +
+			"/Next << /S /URI /URI (https://example.com/help) >> >> >>",
+		"<< /FT /Tx /T (amount) /Subtype /Widget /Rect [72 600 272 620] /P 3 0 R " +
+			"/AA << /K << /S /JavaScript /JS (AFNumber_Keystroke\\(2\\)) >> >> >>",
+		stream("", xfa),
+		"<< /Type /Annot /Subtype /Text /Rect [72 560 82 570] /Contents (note) >>",
+	})
+}
+
+func TestSecurityScanner_Scan(t *testing.T) {
+	path := createTempFile(t, "active.pdf", activeContentPDF())
+	scanner := NewSecurityScanner(10 * 1024 * 1024)
+
+	result, err := scanner.Scan(context.Background(), PDFSecurityScanRequest{Path: path})
+	if err != nil {
+		t.Fatalf("Scan() unexpected error = %v", err)
+	}
+
+	var got []string
+	for _, finding := range result.Findings {
+		got = append(got, fmt.Sprintf("%s %s %s %d", finding.Category, finding.Risk, finding.Location, finding.Page))
+	}
+	want := []string{
+		"javascript high document open 0",
+		`javascript high document script "init" 0`,
+		"suspicious_uri medium Link annotation activate 1",
+		"launch high Link annotation activate 1",
+		`javascript medium field "amount" keystroke 1`,
+		"embedded_executable high embedded files 0",
+		"xfa_script high XFA form 0",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if result.Risk != SecurityRiskHigh || result.JavaScriptActions != 3 || result.AutomaticJavaScript != 2 ||
+		result.LaunchActions != 1 || result.URIActions != 2 || result.SuspiciousURIs != 1 ||
+		result.EmbeddedFiles != 1 || result.EmbeddedExecutables != 1 || !result.XFA || result.XFAScripts != 1 {
+		t.Errorf("Scan() = %+v", result)
+	}
+
+	clean := createTempFile(t, "clean.pdf", buildTestPDF("BT /F1 12 Tf 72 700 Td (Hello) Tj ET"))
+	result, err = scanner.Scan(context.Background(), PDFSecurityScanRequest{Path: clean})
+	if err != nil {
+		t.Fatalf("Scan() unexpected error = %v", err)
+	}
+	if result.Risk != SecurityRiskNone || len(result.Findings) != 0 {
+		t.Errorf("Scan() of a plain document = %+v, want no findings", result)
+	}
+}
+
+func TestSuspiciousURI(t *testing.T) {
+	tests := []struct {
+		uri        string
+		suspicious bool
+	}{
+		{"https://example.com/path?q=1", false},
+		{"mailto:someone@example.com", false},
+		{"http://203.0.113.9/payload", true},
+		{"https://[2001:db8::1]/", true},
+		{"https://trusted.com@evil.example/", true},
+		{"https://xn--pple-43d.com/", true},
+		{"file:///C:/Windows/System32/calc.exe", true},
+		{"javascript:alert(1)", true},
+	}
+	for _, tt := range tests {
+		if got := suspiciousURI(tt.uri); (got != "") != tt.suspicious {
+			t.Errorf("suspiciousURI(%q) = %q, want suspicious %v", tt.uri, got, tt.suspicious)
+		}
+	}
+}
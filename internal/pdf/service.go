@@ -1,9 +1,18 @@
 package pdf
 
 import (
+	"context"
 	"fmt"
+	"time"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
 )
 
+// maxHeldDocuments bounds the parsed documents kept open between tool calls
+const maxHeldDocuments = 16
+
 // Service handles PDF file operations by orchestrating various PDF components
 type Service struct {
 	maxFileSize       int64
@@ -12,11 +21,46 @@ type Service struct {
 	stats             *Stats
 	assets            *Assets
 	search            *Search
+	templates         *TemplateMatcher
+	outline           *Outline
+	sections          *Sections
+	renderer          *Renderer
+	regionOCR         *RegionOCR
+	exporter          *Exporter
+	attachments       *Attachments
+	links             *Links
+	finder            *TextFinder
+	comparer          *Comparer
+	redactor          *Redactor
+	annotator         *Annotator
+	optimizer         *Optimizer
+	inspector         *ObjectInspector
+	security          *Security
+	jobs              *extractionJobs
+	loaded            *loadedDocuments
+	portfolios        *Portfolios
+	members           *portfolioMembers
+	formData          *FormData
+	entities          *EntityExtractor
+	classifier        *Classifier
+	invoices          *InvoiceExtractor
+	statements        *StatementReader
+	resumes           *ResumeParser
+	clauses           *ClauseReader
+	accessibility     *AccessibilityAuditor
+	sanitizer         *Sanitizer
+	urlFetcher        *URLFetcher
+	securityScanner   *SecurityScanner
 	extractionService *ExtractionService
+	escalation        EscalationPolicy
+	metrics           *Metrics
 }
 
 // NewService creates a new PDF service with all components
 func NewService(maxFileSize int64) *Service {
+	metrics := NewMetrics()
+	extractionService := NewExtractionService(maxFileSize)
+	extractionService.metrics = metrics
 	return &Service{
 		maxFileSize:       maxFileSize,
 		reader:            NewReader(maxFileSize),
@@ -24,13 +68,134 @@ func NewService(maxFileSize int64) *Service {
 		stats:             NewStats(maxFileSize),
 		assets:            NewAssets(maxFileSize),
 		search:            NewSearch(maxFileSize),
-		extractionService: NewExtractionService(maxFileSize),
+		templates:         NewTemplateMatcher(maxFileSize),
+		outline:           NewOutline(maxFileSize),
+		sections:          NewSections(maxFileSize),
+		renderer:          NewRenderer(maxFileSize),
+		regionOCR:         NewRegionOCR(maxFileSize),
+		exporter:          NewExporter(maxFileSize),
+		attachments:       NewAttachments(maxFileSize),
+		links:             NewLinks(maxFileSize),
+		finder:            NewTextFinder(maxFileSize),
+		comparer:          NewComparer(maxFileSize),
+		redactor:          NewRedactor(maxFileSize),
+		annotator:         NewAnnotator(maxFileSize),
+		optimizer:         NewOptimizer(maxFileSize),
+		inspector:         NewObjectInspector(maxFileSize),
+		security:          NewSecurity(maxFileSize),
+		jobs:              newExtractionJobs(),
+		loaded:            newLoadedDocuments(),
+		portfolios:        NewPortfolios(maxFileSize),
+		members:           &portfolioMembers{handles: make(map[portfolioMember]string)},
+		formData:          NewFormData(maxFileSize),
+		entities:          NewEntityExtractor(maxFileSize),
+		classifier:        NewClassifier(maxFileSize),
+		invoices:          NewInvoiceExtractor(maxFileSize),
+		statements:        NewStatementReader(maxFileSize),
+		resumes:           NewResumeParser(maxFileSize),
+		clauses:           NewClauseReader(maxFileSize),
+		accessibility:     NewAccessibilityAuditor(maxFileSize),
+		sanitizer:         NewSanitizer(maxFileSize),
+		urlFetcher:        NewURLFetcher(maxFileSize),
+		securityScanner:   NewSecurityScanner(maxFileSize),
+		extractionService: extractionService,
+		metrics:           metrics,
 	}
 }
 
+// Close stops the running extraction jobs and removes the files the service keeps between
+// tool calls, such as the documents loaded from bytes, whose handles stop resolving. The
+// service remains usable.
+func (s *Service) Close() {
+	s.jobs.stop()
+	s.loaded.cleanup()
+}
+
+// SetEscalationPolicy sets the quality safeguards applied to every result with extracted text
+func (s *Service) SetEscalationPolicy(policy EscalationPolicy) {
+	s.escalation = policy
+}
+
+// SetClassificationProfiles adds custom document type profiles to those of pdf_classify_document,
+// pdf_extract_invoice, pdf_extract_transactions, and pdf_extract_resume
+func (s *Service) SetClassificationProfiles(profiles *ClassificationProfiles) {
+	s.classifier.SetProfiles(profiles)
+	s.invoices.SetProfiles(profiles)
+	s.statements.SetProfiles(profiles)
+	s.resumes.SetProfiles(profiles)
+}
+
+// SetMemoryMapping enables memory-mapped reads for the text reader and the extraction
+// engine; platforms without mmap keep using buffered reads
+func (s *Service) SetMemoryMapping(enabled bool) {
+	s.reader.SetMemoryMapping(enabled)
+	s.extractionService.SetMemoryMapping(enabled)
+}
+
+// SetCacheSize enables caching of extraction results, metadata, and page information up to
+// maxBytes; zero disables the cache
+func (s *Service) SetCacheSize(maxBytes int64) {
+	s.extractionService.SetCacheSize(maxBytes)
+}
+
+// SetDocumentTTL keeps parsed documents open for ttl after the tool call reading them, so
+// that consecutive calls on the same file, such as reading it and then its tables and form
+// fields, reuse its parsed cross-reference table. Documents whose file changed are parsed
+// afresh. Zero disables reuse. The setting applies to every service in the process.
+func (s *Service) SetDocumentTTL(ttl time.Duration) {
+	core.SetHandleCache(ttl, maxHeldDocuments)
+}
+
+// SetMemoryBudget limits the memory held by the pages of structured extractions running at
+// once; pages over the limit are spilled to temporary files or, failing that, left out of
+// partial results. Zero removes the limit.
+func (s *Service) SetMemoryBudget(maxBytes int64) {
+	s.extractionService.SetMemoryBudget(maxBytes)
+}
+
+// SetExtractionLimits bounds the pages processed and elements returned by each structured
+// extraction and the time spent on each of its pages; zero values remove a limit
+func (s *Service) SetExtractionLimits(maxPages, maxElements int, pageTimeout time.Duration) {
+	s.extractionService.SetLimits(maxPages, maxElements, pageTimeout)
+}
+
+// SetCheckpointDir saves the pages of structured extractions under dir as they finish, so
+// that a timed-out or interrupted extraction can be resumed; an empty dir disables it
+func (s *Service) SetCheckpointDir(dir string) {
+	s.extractionService.SetCheckpointDir(dir)
+}
+
+// SetOCR sets the OCR program pdf_ocr_region runs, a name on the PATH or a path, and the
+// language it recognizes when a request names none; empty values keep the defaults
+func (s *Service) SetOCR(engine, language string) {
+	s.regionOCR.SetDefaults(engine, language)
+}
+
+// SetFetchDir sets the staging directory pdf_fetch_url downloads PDFs into; without one,
+// fetching is refused
+func (s *Service) SetFetchDir(dir string) {
+	s.urlFetcher.SetDir(dir)
+}
+
+// SetFileSystem makes every service read documents from and write outputs to fsys instead
+// of the host's file system, such as an in-memory one where there is no writable disk; nil
+// restores the host's. Page renderers and the OCR engine still run on the host, reading
+// copies of the documents. The setting applies to every service in the process.
+func (s *Service) SetFileSystem(f fsys.FS) {
+	fsys.Use(f)
+}
+
 // PDFReadFile reads the content of a PDF file
 func (s *Service) PDFReadFile(req PDFReadFileRequest) (*PDFReadFileResult, error) {
-	return s.reader.ReadFile(req)
+	result, err := s.reader.ReadFile(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Escalation, err = s.escalation.Evaluate(result.Content); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // PDFAssetsFile extracts visual assets like images from a PDF file
@@ -53,11 +218,215 @@ func (s *Service) PDFSearchDirectory(req PDFSearchDirectoryRequest) (*PDFSearchD
 	return s.search.SearchDirectory(req)
 }
 
+// PDFSearchContentDirectory searches the text of every PDF file in a directory
+func (s *Service) PDFSearchContentDirectory(req PDFSearchContentRequest) (*PDFSearchContentResult, error) {
+	return s.search.SearchContent(req)
+}
+
 // PDFStatsDirectory returns statistics about PDF files in a directory
 func (s *Service) PDFStatsDirectory(req PDFStatsDirectoryRequest) (*PDFStatsDirectoryResult, error) {
 	return s.stats.GetDirectoryStats(req)
 }
 
+// PDFFingerprint computes a layout fingerprint for a PDF file
+func (s *Service) PDFFingerprint(req PDFFingerprintRequest) (*DocumentFingerprint, error) {
+	return s.templates.Fingerprint(req)
+}
+
+// PDFMatchTemplate identifies which known template a PDF file corresponds to by layout
+func (s *Service) PDFMatchTemplate(req PDFMatchTemplateRequest) (*PDFMatchTemplateResult, error) {
+	return s.templates.MatchTemplate(req)
+}
+
+// PDFGetOutline returns the document outline (bookmarks) of a PDF file
+func (s *Service) PDFGetOutline(req PDFGetOutlineRequest) (*PDFGetOutlineResult, error) {
+	return s.outline.GetOutline(req)
+}
+
+// PDFExtractSection returns the text of one section, appendix, or exhibit of a PDF file
+func (s *Service) PDFExtractSection(req PDFExtractSectionRequest) (*PDFExtractSectionResult, error) {
+	result, err := s.sections.ExtractSection(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Escalation, err = s.escalation.Evaluate(result.Content); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PDFRenderPage rasterizes a page of a PDF file to a PNG or JPEG image
+func (s *Service) PDFRenderPage(req PDFRenderPageRequest) (*PDFRenderPageResult, error) {
+	return s.renderer.RenderPage(req)
+}
+
+// PDFGenerateThumbnails makes page thumbnails of every PDF in a directory
+func (s *Service) PDFGenerateThumbnails(
+	ctx context.Context, req PDFGenerateThumbnailsRequest,
+) (*PDFGenerateThumbnailsResult, error) {
+	return s.renderer.GenerateThumbnails(ctx, req)
+}
+
+// PDFOCRRegion recognizes the text of regions or images of a page with an installed OCR engine
+func (s *Service) PDFOCRRegion(req PDFOCRRegionRequest) (*PDFOCRRegionResult, error) {
+	return s.regionOCR.RecognizeRegions(req)
+}
+
+// PDFExportDocument converts the detected structure of a PDF into a DOCX or ODT document
+func (s *Service) PDFExportDocument(ctx context.Context, req PDFExportDocumentRequest) (*PDFExportDocumentResult, error) {
+	return s.exporter.ExportDocument(ctx, req)
+}
+
+// PDFExportBook converts a long-form PDF into an EPUB or a navigable HTML bundle
+func (s *Service) PDFExportBook(ctx context.Context, req PDFExportBookRequest) (*PDFExportBookResult, error) {
+	return s.exporter.ExportBook(ctx, req)
+}
+
+// PDFExportText exports a PDF's cleaned text as plain text, Markdown, or JSON Lines
+func (s *Service) PDFExportText(ctx context.Context, req PDFExportTextRequest) (*PDFExportTextResult, error) {
+	return s.exporter.ExportText(ctx, req)
+}
+
+// PDFExtractReadingOrder returns the logical blocks of a PDF in reading order
+func (s *Service) PDFExtractReadingOrder(
+	ctx context.Context, req PDFExtractReadingOrderRequest,
+) (*PDFExtractReadingOrderResult, error) {
+	return s.exporter.ExtractReadingOrder(ctx, req)
+}
+
+// PDFExportComments exports a PDF's review comments and markup as threads of replies
+func (s *Service) PDFExportComments(
+	ctx context.Context, req PDFExportCommentsRequest,
+) (*PDFExportCommentsResult, error) {
+	return s.exporter.ExportComments(ctx, req)
+}
+
+// PDFChunkContent splits a PDF's text into section-aligned chunks for retrieval pipelines
+func (s *Service) PDFChunkContent(ctx context.Context, req PDFChunkContentRequest) (*PDFChunkContentResult, error) {
+	return s.exporter.ChunkContent(ctx, req)
+}
+
+// PDFExtractAttachments lists and optionally extracts the files embedded in a PDF
+func (s *Service) PDFExtractAttachments(req PDFExtractAttachmentsRequest) (*PDFExtractAttachmentsResult, error) {
+	return s.attachments.ExtractAttachments(req)
+}
+
+// PDFExtractLinks returns the hyperlinks and internal cross-references in a PDF
+func (s *Service) PDFExtractLinks(req PDFExtractLinksRequest) (*PDFExtractLinksResult, error) {
+	return s.links.ExtractLinks(req)
+}
+
+// PDFFindText finds every occurrence of a phrase with its page and bounding box
+func (s *Service) PDFFindText(req PDFFindTextRequest) (*PDFFindTextResult, error) {
+	return s.finder.FindText(req)
+}
+
+// PDFRedact writes a copy of a PDF with the requested regions and terms removed
+func (s *Service) PDFRedact(ctx context.Context, req PDFRedactRequest) (*PDFRedactResult, error) {
+	return s.redactor.Redact(ctx, req)
+}
+
+// PDFAnnotate writes a copy of a PDF with highlight, note, and rectangle annotations added
+func (s *Service) PDFAnnotate(ctx context.Context, req PDFAnnotateRequest) (*PDFAnnotateResult, error) {
+	return s.annotator.Annotate(ctx, req)
+}
+
+// PDFOptimizeReport reports how a PDF is stored and what optimizing it would save
+func (s *Service) PDFOptimizeReport(
+	ctx context.Context, req PDFOptimizeReportRequest,
+) (*PDFOptimizeReportResult, error) {
+	return s.optimizer.Report(ctx, req)
+}
+
+// PDFAccessibilityReport audits a PDF against WCAG and PDF/UA
+func (s *Service) PDFAccessibilityReport(
+	ctx context.Context, req PDFAccessibilityReportRequest,
+) (*PDFAccessibilityReportResult, error) {
+	return s.accessibility.Report(ctx, req)
+}
+
+// PDFOptimize writes a smaller copy of a PDF
+func (s *Service) PDFOptimize(ctx context.Context, req PDFOptimizeRequest) (*PDFOptimizeResult, error) {
+	return s.optimizer.Optimize(ctx, req)
+}
+
+// PDFSanitize writes a copy of a PDF without sensitive metadata and active or hidden content
+func (s *Service) PDFSanitize(ctx context.Context, req PDFSanitizeRequest) (*PDFSanitizeResult, error) {
+	return s.sanitizer.Sanitize(ctx, req)
+}
+
+// PDFSecurityScan reports the active content of a PDF and the risk it poses
+func (s *Service) PDFSecurityScan(ctx context.Context, req PDFSecurityScanRequest) (*PDFSecurityScanResult, error) {
+	return s.securityScanner.Scan(ctx, req)
+}
+
+// PDFFetchURL downloads a PDF over HTTPS into the staging directory for the other tools to read
+func (s *Service) PDFFetchURL(ctx context.Context, req PDFFetchURLRequest) (*PDFFetchURLResult, error) {
+	return s.urlFetcher.Fetch(ctx, req)
+}
+
+// PDFInspectObject describes one indirect object of a PDF for debugging
+func (s *Service) PDFInspectObject(req PDFInspectObjectRequest) (*PDFInspectObjectResult, error) {
+	return s.inspector.Inspect(req)
+}
+
+// PDFGetPermissions reports a PDF's encryption, its permissions, and whether its text may be extracted
+func (s *Service) PDFGetPermissions(req PDFGetPermissionsRequest) (*PDFGetPermissionsResult, error) {
+	return s.security.Permissions(req)
+}
+
+// PDFExportFormData lists a PDF's form fields and writes their values as form data
+func (s *Service) PDFExportFormData(req PDFExportFormDataRequest) (*PDFExportFormDataResult, error) {
+	return s.formData.Export(req)
+}
+
+// PDFImportFormData writes a copy of a PDF with its form fields filled from form data
+func (s *Service) PDFImportFormData(ctx context.Context, req PDFImportFormDataRequest) (*PDFImportFormDataResult, error) {
+	return s.formData.Import(ctx, req)
+}
+
+// PDFExtractEntities finds dates, amounts, contact details, and identifiers in a PDF's text
+func (s *Service) PDFExtractEntities(ctx context.Context, req PDFExtractEntitiesRequest) (*PDFExtractEntitiesResult, error) {
+	return s.entities.ExtractEntities(ctx, req)
+}
+
+// PDFClassifyDocument ranks the document types a PDF may be by the keywords and patterns in its text
+func (s *Service) PDFClassifyDocument(
+	ctx context.Context, req PDFClassifyDocumentRequest,
+) (*PDFClassifyDocumentResult, error) {
+	return s.classifier.Classify(ctx, req)
+}
+
+// PDFExtractInvoice reads the vendor, number, dates, line items, and totals of an invoice or receipt
+func (s *Service) PDFExtractInvoice(
+	ctx context.Context, req PDFExtractInvoiceRequest,
+) (*PDFExtractInvoiceResult, error) {
+	return s.invoices.ExtractInvoice(ctx, req)
+}
+
+// PDFExtractTransactions reads the transactions and balances of a bank statement
+func (s *Service) PDFExtractTransactions(
+	ctx context.Context, req PDFExtractTransactionsRequest,
+) (*PDFExtractTransactionsResult, error) {
+	return s.statements.ExtractTransactions(ctx, req)
+}
+
+// PDFExtractResume reads the contact details, experience, education, and skills of a resume
+func (s *Service) PDFExtractResume(ctx context.Context, req PDFExtractResumeRequest) (*PDFExtractResumeResult, error) {
+	return s.resumes.ExtractResume(ctx, req)
+}
+
+// PDFGetClauses reads the numbered clauses of a legal document as a tree
+func (s *Service) PDFGetClauses(ctx context.Context, req PDFGetClausesRequest) (*PDFGetClausesResult, error) {
+	return s.clauses.GetClauses(ctx, req)
+}
+
+// PDFCompareSet computes pairwise similarity across a set of PDF files
+func (s *Service) PDFCompareSet(req PDFCompareSetRequest) (*PDFCompareSetResult, error) {
+	return s.comparer.CompareSet(req)
+}
+
 // GetMaxFileSize returns the maximum file size limit
 func (s *Service) GetMaxFileSize() int64 {
 	return s.maxFileSize
@@ -172,7 +541,8 @@ func (s *Service) PDFServerInfo(req PDFServerInfoRequest, serverName, version,
 IMPORTANT NOTES:
 - Always use absolute file paths
 - The server can handle files up to ` + fmt.Sprintf("%d", s.maxFileSize/(1024*1024)) + `MB
-- For scanned documents, pdf_assets_file will extract images but cannot perform OCR
+- For scanned documents, pdf_assets_file will extract images but cannot perform OCR; pdf_ocr_region
+  recognizes the text of regions or images of a page when tesseract is installed
 - Some PDFs may have images that cannot be extracted due to format limitations`
 
 	result := &PDFServerInfoResult{
@@ -190,7 +560,7 @@ IMPORTANT NOTES:
 }
 
 // ExtractStructured performs structured content extraction with positioning and formatting
-func (s *Service) ExtractStructured(req PDFExtractStructuredRequest) (*PDFExtractResult, error) {
+func (s *Service) ExtractStructured(ctx context.Context, req PDFExtractStructuredRequest) (*PDFExtractResult, error) {
 	// Convert to internal request format
 	extractReq := PDFExtractRequest{
 		Path:   req.Path,
@@ -203,51 +573,103 @@ func (s *Service) ExtractStructured(req PDFExtractStructuredRequest) (*PDFExtrac
 		extractReq.Mode = "structured"
 	}
 
-	return s.extractionService.ExtractStructured(extractReq)
+	return s.escalateExtraction(s.extractionService.ExtractStructured(ctx, extractReq))
 }
 
 // ExtractTables performs table detection and extraction
-func (s *Service) ExtractTables(req PDFExtractTablesRequest) (*PDFExtractResult, error) {
+func (s *Service) ExtractTables(ctx context.Context, req PDFExtractTablesRequest) (*PDFExtractResult, error) {
+	format, err := validTableExport(req.ExportFormat, req.OutputPath)
+	if err != nil {
+		return nil, err
+	}
+
 	extractReq := PDFExtractRequest{
 		Path:   req.Path,
 		Mode:   "table",
 		Config: ExtractConfig(req.Config),
 	}
 
-	return s.extractionService.ExtractTables(extractReq)
+	result, err := s.escalateExtraction(s.extractionService.ExtractTables(ctx, extractReq))
+	if err != nil || format == "" {
+		return result, err
+	}
+	if result.Export, err = exportTables(result.Tables, format, req.OutputPath); err != nil {
+		// The tables were extracted; only writing them out failed
+		return nil, pdferrors.WithPartial(err, result)
+	}
+	return result, nil
 }
 
 // ExtractSemantic performs semantic content grouping
-func (s *Service) ExtractSemantic(req PDFExtractSemanticRequest) (*PDFExtractResult, error) {
+func (s *Service) ExtractSemantic(ctx context.Context, req PDFExtractSemanticRequest) (*PDFExtractResult, error) {
 	extractReq := PDFExtractRequest{
 		Path:   req.Path,
 		Mode:   "semantic",
 		Config: ExtractConfig(req.Config),
 	}
 
-	return s.extractionService.ExtractSemantic(extractReq)
+	return s.escalateExtraction(s.extractionService.ExtractSemantic(ctx, extractReq))
 }
 
 // ExtractComplete performs comprehensive extraction of all content types
-func (s *Service) ExtractComplete(req PDFExtractCompleteRequest) (*PDFExtractResult, error) {
+func (s *Service) ExtractComplete(ctx context.Context, req PDFExtractCompleteRequest) (*PDFExtractResult, error) {
 	extractReq := PDFExtractRequest{
 		Path:   req.Path,
 		Mode:   "complete",
 		Config: ExtractConfig(req.Config),
 	}
 
-	return s.extractionService.ExtractComplete(extractReq)
+	return s.escalateExtraction(s.extractionService.ExtractComplete(ctx, extractReq))
+}
+
+// escalateExtraction applies the escalation policy to an extraction result
+func (s *Service) escalateExtraction(result *PDFExtractResult, err error) (*PDFExtractResult, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Escalation, err = s.escalation.Evaluate(extractedText(result)); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-// QueryContent searches extracted content using the provided query
-func (s *Service) QueryContent(req PDFQueryContentRequest) (*PDFQueryResult, error) {
+// QueryContent searches extracted content using the provided query. A query naming a
+// clause searches only the elements within that clause and its subdivisions.
+func (s *Service) QueryContent(ctx context.Context, req PDFQueryContentRequest) (*PDFQueryResult, error) {
 	queryReq := PDFQueryRequest(req)
 
-	result, err := s.extractionService.QueryContent(queryReq)
+	var clause *Clause
+	if req.Query.Clause != "" {
+		var err error
+		if clause, err = s.clauses.findClause(ctx, req.Path, req.Query.Clause); err != nil {
+			return nil, err
+		}
+		if len(queryReq.Query.Pages) == 0 {
+			for page := clause.Page; page <= clause.EndPage; page++ {
+				queryReq.Query.Pages = append(queryReq.Query.Pages, page)
+			}
+		}
+	}
+
+	result, err := s.extractionService.QueryContent(ctx, queryReq)
 	if err != nil {
 		return nil, err
 	}
 
+	if clause != nil {
+		elements := []ContentElement{}
+		for _, element := range result.Elements {
+			if clause.contains(element) {
+				elements = append(elements, element)
+			}
+		}
+		result.Elements = elements
+		result.MatchCount = len(elements)
+		result.Summary = s.extractionService.buildQuerySummary(elements)
+		clause.Children = nil
+	}
+
 	// Convert back to MCP format
 	return &PDFQueryResult{
 		FilePath:   result.FilePath,
@@ -255,6 +677,7 @@ func (s *Service) QueryContent(req PDFQueryContentRequest) (*PDFQueryResult, err
 		MatchCount: result.MatchCount,
 		Elements:   s.convertElements(result.Elements),
 		Summary:    result.Summary,
+		Clause:     clause,
 	}, nil
 }
 
@@ -266,26 +689,9 @@ func (s *Service) GetPageInfo(req PDFGetPageInfoRequest) (*PDFPageInfoResult, er
 		return nil, err
 	}
 
-	// Convert to MCP format
-	mcpPages := make([]PageInfo, len(pages))
-	for i, page := range pages {
-		mcpPages[i] = PageInfo{
-			Number:   page.Number,
-			Width:    page.Width,
-			Height:   page.Height,
-			Rotation: page.Rotation,
-			MediaBox: Rectangle{
-				X:      page.MediaBox.X,
-				Y:      page.MediaBox.Y,
-				Width:  page.MediaBox.Width,
-				Height: page.MediaBox.Height,
-			},
-		}
-	}
-
 	return &PDFPageInfoResult{
 		FilePath: path,
-		Pages:    mcpPages,
+		Pages:    pages,
 	}, nil
 }
 
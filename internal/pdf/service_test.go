@@ -1,9 +1,13 @@
 package pdf
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
 )
 
 func TestNewService(t *testing.T) {
@@ -442,3 +446,47 @@ func TestService_PDFStatsFile_ErrorHandling(t *testing.T) {
 		t.Error("result should be nil on error")
 	}
 }
+
+func TestService_SetFileSystem(t *testing.T) {
+	memory := fsys.NewMemory()
+	if err := memory.MkdirAll("/docs", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.WriteFile("/docs/report.pdf", []byte(buildTestPDF(exportTextPage("1", "Europe", "distri"))),
+		0o644); err != nil {
+		t.Fatal(err)
+	}
+	service := NewService(1024 * 1024)
+	service.SetFileSystem(memory)
+	t.Cleanup(func() { service.SetFileSystem(nil) })
+
+	read, err := service.PDFReadFile(PDFReadFileRequest{Path: "/docs/report.pdf"})
+	if err != nil {
+		t.Fatalf("PDFReadFile() unexpected error = %v", err)
+	}
+	if read.Pages != 1 || !strings.Contains(read.Content, "Europe") {
+		t.Errorf("PDFReadFile() = %+v, want the page read from memory", read)
+	}
+
+	search, err := service.PDFSearchDirectory(PDFSearchDirectoryRequest{Directory: "/docs"})
+	if err != nil {
+		t.Fatalf("PDFSearchDirectory() unexpected error = %v", err)
+	}
+	if search.TotalCount != 1 || search.Files[0].Path != "/docs/report.pdf" {
+		t.Errorf("PDFSearchDirectory() = %+v, want the document in memory", search)
+	}
+
+	export, err := service.PDFExportText(context.Background(), PDFExportTextRequest{
+		Path: "/docs/report.pdf", OutputDir: "/out",
+	})
+	if err != nil {
+		t.Fatalf("PDFExportText() unexpected error = %v", err)
+	}
+	data, err := memory.ReadFile(export.OutputPath)
+	if err != nil || !strings.Contains(string(data), "Europe") {
+		t.Errorf("exported %q (%v), want the text written to memory", data, err)
+	}
+	if _, err := os.Stat("/out"); !os.IsNotExist(err) {
+		t.Errorf("Stat(/out) error = %v, want nothing written to the host", err)
+	}
+}
@@ -3,9 +3,11 @@ package pdf
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
 	"github.com/ledongthuc/pdf"
 )
 
@@ -29,32 +31,23 @@ func (s *Stats) GetFileStats(req PDFStatsFileRequest) (*PDFStatsFileResult, erro
 		return nil, fmt.Errorf("path cannot be empty")
 	}
 
-	// Check if file exists and get basic info
-	fileInfo, err := os.Stat(req.Path)
-	if os.IsNotExist(err) {
-		return nil, fmt.Errorf("file does not exist: %s", req.Path)
-	}
+	// Open the document, which also describes the file
+	doc, err := core.Open(req.Path, core.Options{Validate: s.validator.ValidateFileInfo})
 	if err != nil {
-		return nil, fmt.Errorf("cannot access file: %w", err)
-	}
-
-	// Validate file
-	if err := s.validator.ValidateFileInfo(req.Path, fileInfo); err != nil {
 		return nil, err
 	}
+	defer doc.Close()
+	r := doc.Reader
 
-	// Open and parse PDF for metadata
-	f, r, err := pdf.Open(req.Path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open PDF: %w", err)
-	}
-	defer f.Close()
-
+	numbering := extraction.NewPageNumbering(r)
 	result := &PDFStatsFileResult{
 		Path:         req.Path,
-		Size:         fileInfo.Size(),
-		Pages:        r.NumPage(),
-		ModifiedDate: fileInfo.ModTime().Format("2006-01-02 15:04:05"),
+		Size:         doc.Info().Size(),
+		Pages:        numbering.Count(),
+		ModifiedDate: doc.Info().ModTime().Format("2006-01-02 15:04:05"),
+	}
+	for _, issue := range numbering.Issues {
+		result.PageIssues = append(result.PageIssues, issue.Message)
 	}
 
 	// Extract metadata if available
@@ -73,7 +66,7 @@ func (s *Stats) GetDirectoryStats(req PDFStatsDirectoryRequest) (*PDFStatsDirect
 	}
 
 	// Check if directory exists
-	if _, err := os.Stat(directory); os.IsNotExist(err) {
+	if _, err := fsys.Stat(directory); os.IsNotExist(err) {
 		return nil, fmt.Errorf("directory does not exist: %s", directory)
 	}
 
@@ -84,7 +77,7 @@ func (s *Stats) GetDirectoryStats(req PDFStatsDirectoryRequest) (*PDFStatsDirect
 	var smallestFile int64 = int64(^uint64(0) >> 1) // Max int64
 	var smallestFileName string
 
-	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+	err := fsys.Walk(directory, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil //nolint:nilerr // Continue despite errors
 		}
@@ -0,0 +1,252 @@
+package pdf
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// Structure detection presets
+const (
+	StructurePresetDefault  = "default"
+	StructurePresetAcademic = "academic"
+	StructurePresetLegal    = "legal"
+	StructurePresetForm     = "form"
+)
+
+// StructureConfig tunes how the lines of a page are classified into headings, list items,
+// and paragraphs. Ratios are relative to the page's body text size. Patterns are regular
+// expressions matched against the start of each line.
+type StructureConfig struct {
+	Preset             string   `json:"preset,omitempty"`
+	HeadingFontRatio   float64  `json:"heading_font_ratio"`   // Size from which a short line is a heading
+	HeadingLevel1Ratio float64  `json:"heading_level1_ratio"` // Size of first level headings
+	HeadingLevel2Ratio float64  `json:"heading_level2_ratio"` // Size of second level headings; smaller are third level
+	HeadingMaxWords    int      `json:"heading_max_words"`    // Longest line still considered a heading
+	HeadingPatterns    []string `json:"heading_patterns"`     // Lines that are headings at any size
+	ListPatterns       []string `json:"list_patterns"`        // Markers opening a list item
+	ParagraphGapRatio  float64  `json:"paragraph_gap_ratio"`  // Gap between lines that starts a new paragraph
+	DetectHeadings     bool     `json:"detect_headings"`
+	DetectLists        bool     `json:"detect_lists"`
+	MergeLines         bool     `json:"merge_lines"` // Join lines into paragraphs and list items
+}
+
+// StructurePresets returns the names of the structure detection presets
+func StructurePresets() []string {
+	return []string{StructurePresetDefault, StructurePresetAcademic, StructurePresetLegal, StructurePresetForm}
+}
+
+// NewStructureConfig returns the settings of a preset; an empty name is the default
+func NewStructureConfig(preset string) (StructureConfig, error) {
+	config := StructureConfig{
+		Preset:             StructurePresetDefault,
+		HeadingFontRatio:   headingFontRatio,
+		HeadingLevel1Ratio: headingLevel1Ratio,
+		HeadingLevel2Ratio: headingLevel2Ratio,
+		HeadingMaxWords:    headingMaxWords,
+		HeadingPatterns:    []string{headingPattern.String()},
+		ListPatterns:       []string{listItemPattern.String()},
+		ParagraphGapRatio:  paragraphGapRatio,
+		DetectHeadings:     true,
+		DetectLists:        true,
+		MergeLines:         true,
+	}
+
+	switch strings.ToLower(preset) {
+	case "", StructurePresetDefault:
+	case StructurePresetAcademic:
+		// Papers number their sections and set headings only slightly larger than the body
+		config.Preset = StructurePresetAcademic
+		config.HeadingFontRatio = 1.1
+		config.HeadingMaxWords = 15
+		config.HeadingPatterns = []string{
+			`^\d+(\.\d+)*\.?\s+\p{Lu}`,
+			`^(?i:abstract|introduction|related work|methods?|results|discussion|conclusions?|` +
+				`references|bibliography|acknowledge?ments?|appendix)\b`,
+		}
+		config.ListPatterns = []string{`^(?:[•▪◦‣∙·*\-–]|\(?(\d{1,3}|[a-z]|[ivx]{1,4})[.)])\s+`}
+	case StructurePresetLegal:
+		// Headings are set at body size and named; numbered clauses are list items
+		config.Preset = StructurePresetLegal
+		config.HeadingFontRatio = 1.15
+		config.HeadingMaxWords = 10
+		config.HeadingPatterns = []string{
+			`^(?i:article|section|chapter|part|schedule|exhibit|annex|appendix)\s+[\dIVXLC]+\b`,
+			`^\p{Lu}[\p{Lu}\s,&-]+$`,
+		}
+		config.ListPatterns = []string{
+			`^(?:[•▪◦‣∙·*\-–§]|\(?(\d{1,3}(\.\d{1,3})*|[a-z]{1,2}|[ivxlc]{1,5}|[A-Z])[.)])\s+`,
+			`^(\d{1,3}(\.\d{1,3})+)\s+`,
+		}
+		config.ParagraphGapRatio = 0.6
+	case StructurePresetForm:
+		// Forms are labels and short answers; keep each line on its own
+		config.Preset = StructurePresetForm
+		config.HeadingFontRatio = 1.3
+		config.HeadingMaxWords = 8
+		config.HeadingPatterns = nil
+		config.DetectLists = false
+		config.MergeLines = false
+	default:
+		return config, fmt.Errorf("invalid structure preset: %s (must be one of %s)",
+			preset, strings.Join(StructurePresets(), ", "))
+	}
+	return config, nil
+}
+
+// Validate checks that the ratios are in range and the patterns compile
+func (c StructureConfig) Validate() error {
+	switch {
+	case c.HeadingFontRatio <= 0:
+		return fmt.Errorf("heading_font_ratio must be greater than 0, got %g", c.HeadingFontRatio)
+	case c.HeadingLevel2Ratio < c.HeadingFontRatio:
+		return fmt.Errorf("heading_level2_ratio (%g) cannot be below heading_font_ratio (%g)",
+			c.HeadingLevel2Ratio, c.HeadingFontRatio)
+	case c.HeadingLevel1Ratio < c.HeadingLevel2Ratio:
+		return fmt.Errorf("heading_level1_ratio (%g) cannot be below heading_level2_ratio (%g)",
+			c.HeadingLevel1Ratio, c.HeadingLevel2Ratio)
+	case c.HeadingMaxWords < 1:
+		return fmt.Errorf("heading_max_words must be at least 1, got %d", c.HeadingMaxWords)
+	case c.ParagraphGapRatio < 0:
+		return fmt.Errorf("paragraph_gap_ratio cannot be negative, got %g", c.ParagraphGapRatio)
+	}
+	_, err := newStructureDetector(c)
+	return err
+}
+
+// structureDetector classifies lines with a compiled structure configuration
+type structureDetector struct {
+	config   StructureConfig
+	headings []*regexp.Regexp
+	lists    []*regexp.Regexp
+}
+
+// defaultStructure is the detector of the default preset
+var defaultStructure = func() *structureDetector {
+	config, _ := NewStructureConfig(StructurePresetDefault)
+	detector, err := newStructureDetector(config)
+	if err != nil {
+		panic(err)
+	}
+	return detector
+}()
+
+// newStructureDetector compiles the patterns of a configuration
+func newStructureDetector(config StructureConfig) (*structureDetector, error) {
+	detector := &structureDetector{config: config}
+	for _, pattern := range config.HeadingPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid heading pattern %q: %w", pattern, err)
+		}
+		detector.headings = append(detector.headings, re)
+	}
+	for _, pattern := range config.ListPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid list pattern %q: %w", pattern, err)
+		}
+		detector.lists = append(detector.lists, re)
+	}
+	return detector, nil
+}
+
+// textBlocks turns lines in reading order into headings, paragraphs, and list items, placing
+// each table where its first line would have been
+func (d *structureDetector) textBlocks(
+	lines []pageLine, bodySize float64, tables []extraction.TableElement,
+) []exportBlock {
+	var blocks []exportBlock
+	placed := make([]bool, len(tables))
+	current := -1 // Index of the paragraph or list item that following lines may continue
+	previousBottom := 0.0
+	for _, line := range lines {
+		if index := tableContaining(tables, line); index >= 0 {
+			if !placed[index] {
+				blocks = append(blocks, tableBlock(tables[index]))
+				placed[index] = true
+			}
+			current = -1
+			continue
+		}
+
+		gap := previousBottom - line.Top
+		previousBottom = line.Bottom
+		marker, listItem := d.listMarker(line.Text)
+		switch {
+		// A numbered line at body size is a list item rather than a numbered heading
+		case d.isHeading(line, bodySize) && (!listItem || line.FontSize >= bodySize*d.config.HeadingFontRatio):
+			blocks = append(blocks, exportBlock{kind: blockHeading, level: d.headingLevel(line, bodySize),
+				text: line.Text, bounds: lineBounds(line)})
+			current = -1
+			continue
+		case listItem:
+			blocks = append(blocks, exportBlock{kind: blockListItem, ordered: orderedMarker(marker),
+				text: line.Text, bounds: lineBounds(line)})
+			current = len(blocks) - 1
+			continue
+		}
+
+		// Continue the open paragraph or list item unless the gap above is paragraph-sized, or
+		// the line sits above the one before, as the first line of the next column does
+		if d.config.MergeLines && current >= 0 && gap <= bodySize*d.config.ParagraphGapRatio && gap >= -bodySize {
+			blocks[current].text += " " + line.Text
+			blocks[current].bounds = unionRectangles(blocks[current].bounds, lineBounds(line))
+			continue
+		}
+		blocks = append(blocks, exportBlock{kind: blockParagraph, text: line.Text, bounds: lineBounds(line)})
+		current = len(blocks) - 1
+	}
+
+	for i, table := range tables {
+		if !placed[i] {
+			blocks = append(blocks, tableBlock(table))
+		}
+	}
+	return blocks
+}
+
+// isHeading reports whether a short line is set noticeably larger than the body text or
+// matches a heading pattern
+func (d *structureDetector) isHeading(line pageLine, bodySize float64) bool {
+	if !d.config.DetectHeadings || line.Words > d.config.HeadingMaxWords {
+		return false
+	}
+	return line.FontSize >= bodySize*d.config.HeadingFontRatio ||
+		slices.ContainsFunc(d.headings, func(re *regexp.Regexp) bool { return re.MatchString(line.Text) })
+}
+
+// headingLevel ranks a heading by how much larger than the body text it is set
+func (d *structureDetector) headingLevel(line pageLine, bodySize float64) int {
+	switch {
+	case line.FontSize >= bodySize*d.config.HeadingLevel1Ratio:
+		return 1
+	case line.FontSize >= bodySize*d.config.HeadingLevel2Ratio:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// listMarker returns the marker opening a list item
+func (d *structureDetector) listMarker(text string) (string, bool) {
+	if !d.config.DetectLists {
+		return "", false
+	}
+	for _, re := range d.lists {
+		if marker := re.FindString(text); marker != "" {
+			return marker, true
+		}
+	}
+	return "", false
+}
+
+// orderedMarker reports whether a list marker numbers or letters its item rather than
+// being a bullet
+func orderedMarker(marker string) bool {
+	return strings.ContainsFunc(marker, func(r rune) bool { return unicode.IsDigit(r) || unicode.IsLetter(r) })
+}
@@ -0,0 +1,399 @@
+package pdf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+	"github.com/ledongthuc/pdf"
+)
+
+// Fingerprinting and matching constants
+const (
+	// fingerprintGridSize is the number of rows and columns of the text density grid
+	fingerprintGridSize = 8
+	// fingerprintMaxPages limits how many leading pages are fingerprinted
+	fingerprintMaxPages = 5
+	// fingerprintMaxAnchors limits the number of anchor words kept per page
+	fingerprintMaxAnchors = 24
+	// fingerprintQuantLevels is the number of levels used when hashing density grids
+	fingerprintQuantLevels = 4
+	// anchorMinLength is the minimum number of letters in an anchor word
+	anchorMinLength = 3
+	// anchorPositionTolerance is the normalized distance within which anchors are considered aligned
+	anchorPositionTolerance = 0.05
+	// layoutWeight is the weight of density similarity in the combined score
+	layoutWeight = 0.6
+	// defaultTemplateMinScore is the default score required to report a match
+	defaultTemplateMinScore = 0.75
+	// defaultPageWidth and defaultPageHeight are US Letter dimensions used when MediaBox is missing
+	defaultPageWidth  = 612.0
+	defaultPageHeight = 792.0
+)
+
+// TemplateMatcher computes layout fingerprints and matches documents against known templates
+type TemplateMatcher struct {
+	maxFileSize int64
+	validator   *Validator
+}
+
+// NewTemplateMatcher creates a new template matcher with the specified constraints
+func NewTemplateMatcher(maxFileSize int64) *TemplateMatcher {
+	return &TemplateMatcher{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+	}
+}
+
+// Fingerprint computes the layout fingerprint of a PDF file
+func (m *TemplateMatcher) Fingerprint(req PDFFingerprintRequest) (*DocumentFingerprint, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	doc, err := core.Open(req.Path, core.Options{Validate: m.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+	r := doc.Reader
+
+	numbering := extraction.NewPageNumbering(r)
+	fingerprint := &DocumentFingerprint{
+		Path:      req.Path,
+		PageCount: numbering.Count(),
+		GridSize:  fingerprintGridSize,
+		Pages:     []PageFingerprint{},
+	}
+
+	for pageNum := 1; pageNum <= numbering.Count() && pageNum <= fingerprintMaxPages; pageNum++ {
+		page := numbering.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+
+		pageFingerprint, err := m.fingerprintPage(page, pageNum)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fingerprint page %d: %w", pageNum, err)
+		}
+		fingerprint.Pages = append(fingerprint.Pages, *pageFingerprint)
+	}
+
+	fingerprint.Hash = m.hashFingerprint(fingerprint)
+	return fingerprint, nil
+}
+
+// MatchTemplate compares a document against template PDFs and ranks them by layout similarity
+func (m *TemplateMatcher) MatchTemplate(req PDFMatchTemplateRequest) (*PDFMatchTemplateResult, error) {
+	if len(req.Templates) == 0 {
+		return nil, fmt.Errorf("at least one template must be provided")
+	}
+
+	document, err := m.Fingerprint(PDFFingerprintRequest{Path: req.Path})
+	if err != nil {
+		return nil, err
+	}
+
+	minScore := req.MinScore
+	if minScore <= 0 {
+		minScore = defaultTemplateMinScore
+	}
+
+	result := &PDFMatchTemplateResult{
+		Path:     req.Path,
+		Matches:  []TemplateMatch{},
+		MinScore: minScore,
+	}
+
+	templatePaths, err := m.resolveTemplates(req.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, templatePath := range templatePaths {
+		if samePath(templatePath, req.Path) {
+			continue
+		}
+
+		template, err := m.Fingerprint(PDFFingerprintRequest{Path: templatePath})
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", templatePath, err))
+			continue
+		}
+
+		match := CompareFingerprints(document, template)
+		match.Matched = match.Score >= minScore
+		result.Matches = append(result.Matches, match)
+	}
+
+	sort.SliceStable(result.Matches, func(i, j int) bool {
+		return result.Matches[i].Score > result.Matches[j].Score
+	})
+
+	if len(result.Matches) > 0 && result.Matches[0].Matched {
+		best := result.Matches[0]
+		result.BestMatch = &best
+	}
+
+	return result, nil
+}
+
+// CompareFingerprints scores how closely a document's layout matches a template's layout.
+// The score combines text density similarity with the fraction of template anchors found
+// at the same position in the document.
+func CompareFingerprints(document, template *DocumentFingerprint) TemplateMatch {
+	match := TemplateMatch{
+		TemplatePath: template.Path,
+		TemplateName: strings.TrimSuffix(filepath.Base(template.Path), filepath.Ext(template.Path)),
+	}
+
+	pages := min(len(document.Pages), len(template.Pages))
+	if pages == 0 {
+		return match
+	}
+
+	layoutTotal, anchorTotal := 0.0, 0.0
+	anchorPages := 0
+	for i := 0; i < pages; i++ {
+		layoutTotal += cosineSimilarity(document.Pages[i].Density, template.Pages[i].Density)
+		if len(template.Pages[i].Anchors) > 0 {
+			anchorTotal += anchorOverlap(document.Pages[i].Anchors, template.Pages[i].Anchors)
+			anchorPages++
+		}
+	}
+
+	match.LayoutSimilarity = layoutTotal / float64(pages)
+	if anchorPages > 0 {
+		match.AnchorSimilarity = anchorTotal / float64(anchorPages)
+		match.Score = layoutWeight*match.LayoutSimilarity + (1-layoutWeight)*match.AnchorSimilarity
+	} else {
+		match.Score = match.LayoutSimilarity
+	}
+
+	// Documents with a different page count are less likely to share a template
+	if document.PageCount != template.PageCount {
+		longer := math.Max(float64(document.PageCount), float64(template.PageCount))
+		match.Score *= float64(pages) / math.Min(longer, fingerprintMaxPages)
+	}
+
+	return match
+}
+
+// fingerprintPage builds the density grid and anchor list for a single page
+func (m *TemplateMatcher) fingerprintPage(page pdf.Page, pageNum int) (*PageFingerprint, error) {
+	width, height := pageDimensions(page)
+
+	words, err := extraction.PageWords(page)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := &PageFingerprint{
+		Page:    pageNum,
+		Width:   width,
+		Height:  height,
+		Density: make([]float64, fingerprintGridSize*fingerprintGridSize),
+	}
+
+	// Weight each grid cell by the number of characters whose word center falls inside it
+	totalChars := 0.0
+	for _, word := range words {
+		box := word.BoundingBox
+		x := (box.LowerLeft.X + box.UpperRight.X) / 2 / width
+		y := 1 - (box.LowerLeft.Y+box.UpperRight.Y)/2/height
+		col := clampGridIndex(x)
+		row := clampGridIndex(y)
+		chars := float64(len([]rune(word.Text)))
+		fingerprint.Density[row*fingerprintGridSize+col] += chars
+		totalChars += chars
+	}
+	if totalChars > 0 {
+		for i := range fingerprint.Density {
+			fingerprint.Density[i] /= totalChars
+		}
+	}
+
+	fingerprint.Anchors = selectAnchors(words, width, height)
+	return fingerprint, nil
+}
+
+// selectAnchors picks words likely to be fixed template labels: field labels ending with a
+// colon and the first word of each line
+func selectAnchors(words []extraction.WordElement, width, height float64) []LayoutAnchor {
+	var anchors []LayoutAnchor
+	seen := make(map[string]bool)
+	lastY := math.Inf(1)
+
+	for _, word := range words {
+		box := word.BoundingBox
+		lineStart := math.Abs(box.LowerLeft.Y-lastY) > box.Height/2
+		lastY = box.LowerLeft.Y
+
+		label := strings.HasSuffix(word.Text, ":")
+		if !lineStart && !label {
+			continue
+		}
+
+		text := normalizeAnchorText(word.Text)
+		if len(text) < anchorMinLength || seen[text] {
+			continue
+		}
+		seen[text] = true
+
+		anchors = append(anchors, LayoutAnchor{
+			Text: text,
+			X:    box.LowerLeft.X / width,
+			Y:    1 - box.UpperRight.Y/height,
+		})
+		if len(anchors) >= fingerprintMaxAnchors {
+			break
+		}
+	}
+
+	return anchors
+}
+
+// normalizeAnchorText lowercases a word and keeps only letters, so that values such as
+// dates and amounts never become anchors
+func normalizeAnchorText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// anchorOverlap returns the fraction of template anchors present at the same position in the document
+func anchorOverlap(document, template []LayoutAnchor) float64 {
+	if len(template) == 0 {
+		return 0
+	}
+
+	found := 0
+	for _, want := range template {
+		for _, got := range document {
+			if got.Text == want.Text &&
+				math.Abs(got.X-want.X) <= anchorPositionTolerance &&
+				math.Abs(got.Y-want.Y) <= anchorPositionTolerance {
+				found++
+				break
+			}
+		}
+	}
+
+	return float64(found) / float64(len(template))
+}
+
+// cosineSimilarity compares two density grids; two empty grids are considered identical
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	dot, normA, normB := 0.0, 0.0, 0.0
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	if normA == 0 && normB == 0 {
+		return 1
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// hashFingerprint hashes quantized density grids so identical layouts share a hash
+func (m *TemplateMatcher) hashFingerprint(fingerprint *DocumentFingerprint) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d:", fingerprint.PageCount)
+	for _, page := range fingerprint.Pages {
+		maxDensity := 0.0
+		for _, d := range page.Density {
+			maxDensity = math.Max(maxDensity, d)
+		}
+		for _, d := range page.Density {
+			level := 0
+			if maxDensity > 0 {
+				level = int(math.Round(d / maxDensity * (fingerprintQuantLevels - 1)))
+			}
+			fmt.Fprintf(h, "%d", level)
+		}
+		h.Write([]byte("|"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// resolveTemplates expands template directories into the PDF files they contain
+func (m *TemplateMatcher) resolveTemplates(templates []string) ([]string, error) {
+	var paths []string
+	for _, template := range templates {
+		info, err := fsys.Stat(template)
+		if err != nil {
+			return nil, fmt.Errorf("cannot access template: %w", err)
+		}
+
+		if !info.IsDir() {
+			paths = append(paths, template)
+			continue
+		}
+
+		entries, err := fsys.ReadDir(template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template directory: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(strings.ToLower(entry.Name()), ".pdf") {
+				paths = append(paths, filepath.Join(template, entry.Name()))
+			}
+		}
+	}
+	return paths, nil
+}
+
+// pageDimensions returns the page width and height from its MediaBox
+func pageDimensions(page pdf.Page) (float64, float64) {
+	mediaBox := extraction.InheritedAttribute(page.V, "MediaBox")
+	if mediaBox.Kind() != pdf.Array || mediaBox.Len() < 4 {
+		return defaultPageWidth, defaultPageHeight
+	}
+
+	width := mediaBox.Index(2).Float64() - mediaBox.Index(0).Float64()
+	height := mediaBox.Index(3).Float64() - mediaBox.Index(1).Float64()
+	if width <= 0 || height <= 0 {
+		return defaultPageWidth, defaultPageHeight
+	}
+	return width, height
+}
+
+// clampGridIndex maps a normalized coordinate onto a grid index
+func clampGridIndex(v float64) int {
+	index := int(v * fingerprintGridSize)
+	if index < 0 {
+		return 0
+	}
+	if index >= fingerprintGridSize {
+		return fingerprintGridSize - 1
+	}
+	return index
+}
+
+// samePath reports whether two paths refer to the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,212 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// invoiceContent lays out labeled fields in the style of a simple invoice form
+func invoiceContent(number, customer, total string) string {
+	var b strings.Builder
+	fields := [][2]string{
+		{"Invoice:", number},
+		{"Customer:", customer},
+		{"Date:", "2024-01-15"},
+		{"Total:", total},
+	}
+	for i, field := range fields {
+		y := 700 - i*30
+		fmt.Fprintf(&b, "BT /F1 12 Tf 72 %d Td (%s) Tj ET\n", y, field[0])
+		fmt.Fprintf(&b, "BT /F1 12 Tf 200 %d Td (%s) Tj ET\n", y, field[1])
+	}
+	return b.String()
+}
+
+// letterContent lays out a paragraph-style letter near the bottom of the page
+func letterContent() string {
+	var b strings.Builder
+	lines := []string{
+		"Dear Reader",
+		"Thank you for your continued interest in our products and services",
+		"We look forward to working with you again in the coming year",
+		"Sincerely yours",
+	}
+	for i, line := range lines {
+		fmt.Fprintf(&b, "BT /F1 10 Tf 300 %d Td (%s) Tj ET\n", 300-i*14, line)
+	}
+	return b.String()
+}
+
+func TestTemplateMatcher_Fingerprint(t *testing.T) {
+	matcher := NewTemplateMatcher(100 * 1024 * 1024)
+
+	tests := []struct {
+		name      string
+		path      string
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name:      "empty path",
+			path:      "",
+			wantError: true,
+			errorMsg:  "path cannot be empty",
+		},
+		{
+			name:      "non-existent file",
+			path:      "/non/existent/file.pdf",
+			wantError: true,
+			errorMsg:  "file does not exist",
+		},
+		{
+			name:      "non-PDF file",
+			path:      createTempFile(t, "test.txt", "not a pdf"),
+			wantError: true,
+			errorMsg:  "file is not a PDF",
+		},
+		{
+			name: "valid PDF",
+			path: createTempFile(t, "invoice.pdf", buildTestPDF(invoiceContent("1001", "Acme", "$10.00"))),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := matcher.Fingerprint(PDFFingerprintRequest{Path: tt.path})
+
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Fingerprint() expected error but got none")
+					return
+				}
+				if !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("Fingerprint() error = %v, want error containing %v", err, tt.errorMsg)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Fingerprint() unexpected error = %v", err)
+			}
+			if result.PageCount != 1 || len(result.Pages) != 1 {
+				t.Fatalf("Fingerprint() pages = %d/%d, want 1/1", result.PageCount, len(result.Pages))
+			}
+			if len(result.Pages[0].Density) != fingerprintGridSize*fingerprintGridSize {
+				t.Errorf("Fingerprint() density cells = %d, want %d",
+					len(result.Pages[0].Density), fingerprintGridSize*fingerprintGridSize)
+			}
+			if result.Hash == "" {
+				t.Error("Fingerprint() returned empty hash")
+			}
+
+			anchors := make(map[string]bool)
+			for _, anchor := range result.Pages[0].Anchors {
+				anchors[anchor.Text] = true
+			}
+			for _, want := range []string{"invoice", "customer", "date", "total"} {
+				if !anchors[want] {
+					t.Errorf("Fingerprint() anchors missing %q: %v", want, result.Pages[0].Anchors)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplateMatcher_MatchTemplate(t *testing.T) {
+	matcher := NewTemplateMatcher(100 * 1024 * 1024)
+
+	templateDir := createTempDir(t)
+	invoiceTemplate := filepath.Join(templateDir, "invoice.pdf")
+	letterTemplate := filepath.Join(templateDir, "letter.pdf")
+	invoicePDF := buildTestPDF(invoiceContent("0000", "Template", "$0.00"))
+	if err := os.WriteFile(invoiceTemplate, []byte(invoicePDF), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	if err := os.WriteFile(letterTemplate, []byte(buildTestPDF(letterContent())), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	document := createTempFile(t, "incoming.pdf", buildTestPDF(invoiceContent("2002", "Globex Corporation", "$99.50")))
+
+	t.Run("directory of templates", func(t *testing.T) {
+		result, err := matcher.MatchTemplate(PDFMatchTemplateRequest{
+			Path:      document,
+			Templates: []string{templateDir},
+		})
+		if err != nil {
+			t.Fatalf("MatchTemplate() unexpected error = %v", err)
+		}
+		if len(result.Matches) != 2 {
+			t.Fatalf("MatchTemplate() matches = %d, want 2", len(result.Matches))
+		}
+		if result.BestMatch == nil || result.BestMatch.TemplateName != "invoice" {
+			t.Fatalf("MatchTemplate() best match = %+v, want invoice", result.BestMatch)
+		}
+		if result.Matches[1].Matched {
+			t.Errorf("MatchTemplate() letter template should not match, score %.2f", result.Matches[1].Score)
+		}
+	})
+
+	t.Run("no matching template", func(t *testing.T) {
+		result, err := matcher.MatchTemplate(PDFMatchTemplateRequest{
+			Path:      document,
+			Templates: []string{letterTemplate},
+		})
+		if err != nil {
+			t.Fatalf("MatchTemplate() unexpected error = %v", err)
+		}
+		if result.BestMatch != nil {
+			t.Errorf("MatchTemplate() best match = %+v, want none", result.BestMatch)
+		}
+	})
+
+	t.Run("no templates", func(t *testing.T) {
+		if _, err := matcher.MatchTemplate(PDFMatchTemplateRequest{Path: document}); err == nil {
+			t.Error("MatchTemplate() expected error for missing templates")
+		}
+	})
+
+	t.Run("missing template path", func(t *testing.T) {
+		_, err := matcher.MatchTemplate(PDFMatchTemplateRequest{
+			Path:      document,
+			Templates: []string{"/non/existent/template.pdf"},
+		})
+		if err == nil {
+			t.Error("MatchTemplate() expected error for missing template")
+		}
+	})
+}
+
+func TestCompareFingerprints(t *testing.T) {
+	page := PageFingerprint{
+		Page:    1,
+		Density: []float64{0.5, 0.5, 0, 0},
+		Anchors: []LayoutAnchor{{Text: "name", X: 0.1, Y: 0.1}},
+	}
+	template := &DocumentFingerprint{Path: "/templates/form.pdf", PageCount: 1, Pages: []PageFingerprint{page}}
+
+	identical := CompareFingerprints(&DocumentFingerprint{PageCount: 1, Pages: []PageFingerprint{page}}, template)
+	if abs(identical.Score-1) > 0.0001 {
+		t.Errorf("CompareFingerprints() identical score = %v, want 1", identical.Score)
+	}
+	if identical.TemplateName != "form" {
+		t.Errorf("CompareFingerprints() TemplateName = %q, want form", identical.TemplateName)
+	}
+
+	different := CompareFingerprints(&DocumentFingerprint{PageCount: 1, Pages: []PageFingerprint{{
+		Page:    1,
+		Density: []float64{0, 0, 0.5, 0.5},
+		Anchors: []LayoutAnchor{{Text: "name", X: 0.8, Y: 0.8}},
+	}}}, template)
+	if different.Score != 0 {
+		t.Errorf("CompareFingerprints() disjoint score = %v, want 0", different.Score)
+	}
+
+	empty := CompareFingerprints(&DocumentFingerprint{}, template)
+	if empty.Score != 0 {
+		t.Errorf("CompareFingerprints() empty score = %v, want 0", empty.Score)
+	}
+}
@@ -0,0 +1,259 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image/png"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/fsys"
+)
+
+// Thumbnail limits and defaults
+const (
+	defaultThumbnailSize    = 256  // Longest side in pixels when MaxSize is unset
+	minThumbnailSize        = 32   // Smallest MaxSize accepted
+	maxThumbnailSize        = 1024 // Largest MaxSize accepted
+	defaultThumbnailWorkers = 4    // Documents rendered concurrently when MaxConcurrency is unset
+	maxThumbnailWorkers     = 16   // Upper bound on MaxConcurrency
+)
+
+// GenerateThumbnails renders small PNG previews of chosen pages, the first by default, of
+// every PDF in a directory and its subdirectories, scaled so their longest side is at most
+// MaxSize pixels. Thumbnails are written beside each document as <name>-page-<n>-thumb.png,
+// or under OutputDir in the same layout as the directory. A thumbnail newer than its document
+// is reused unless Overwrite is set, so repeated calls only render what changed. Documents
+// are rendered concurrently; pages that cannot be rendered are reported with their error and
+// do not fail the others.
+func (r *Renderer) GenerateThumbnails(
+	ctx context.Context, req PDFGenerateThumbnailsRequest,
+) (*PDFGenerateThumbnailsResult, error) {
+	maxSize := req.MaxSize
+	if maxSize == 0 {
+		maxSize = defaultThumbnailSize
+	}
+	if maxSize < minThumbnailSize || maxSize > maxThumbnailSize {
+		return nil, fmt.Errorf("max_size must be between %d and %d, got %d", minThumbnailSize, maxThumbnailSize, maxSize)
+	}
+
+	workers := req.MaxConcurrency
+	switch {
+	case workers < 0:
+		return nil, fmt.Errorf("max_concurrency must be positive, got %d", workers)
+	case workers == 0:
+		workers = defaultThumbnailWorkers
+	case workers > maxThumbnailWorkers:
+		workers = maxThumbnailWorkers
+	}
+
+	pages := req.Pages
+	if len(pages) == 0 {
+		pages = []int{1}
+	}
+
+	files, err := NewSearch(r.maxFileSize).FindPDFsInDirectory(req.Directory)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := r.findBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([][]Thumbnail, len(files))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, file := range files {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ctx.Err() == nil {
+				found[i] = r.fileThumbnails(backend, req, path, pages, maxSize)
+			}
+		}(i, file.Path)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &PDFGenerateThumbnailsResult{
+		Directory:  req.Directory,
+		OutputDir:  req.OutputDir,
+		MaxSize:    maxSize,
+		Renderer:   backend.name,
+		Files:      len(files),
+		Thumbnails: []Thumbnail{},
+	}
+	for _, thumbnails := range found {
+		for _, thumbnail := range thumbnails {
+			switch {
+			case thumbnail.Error != "":
+				result.Failed++
+			case thumbnail.Reused:
+				result.Reused++
+			default:
+				result.Generated++
+			}
+		}
+		result.Thumbnails = append(result.Thumbnails, thumbnails...)
+	}
+	return result, nil
+}
+
+// fileThumbnails makes the thumbnails of one document's pages
+func (r *Renderer) fileThumbnails(
+	backend renderBackend, req PDFGenerateThumbnailsRequest, path string, pages []int, maxSize int,
+) []Thumbnail {
+	sizes, labels, err := thumbnailPages(path, pages)
+	thumbnails := make([]Thumbnail, 0, len(pages))
+	for _, page := range pages {
+		thumbnail := Thumbnail{Path: path, Page: page, PageLabel: labels[page]}
+		size, ok := sizes[page]
+		switch {
+		case err != nil:
+			thumbnail.Error = err.Error()
+		case !ok:
+			thumbnail.Error = fmt.Sprintf("page %d out of range (document has %d pages)", page, len(labels))
+		default:
+			thumbnail.OutputPath = thumbnailPath(req.Directory, req.OutputDir, path, page)
+			if err := r.makeThumbnail(backend, &thumbnail, size, maxSize, req.Overwrite, req.IncludeData); err != nil {
+				thumbnail.Error = err.Error()
+			}
+		}
+		thumbnails = append(thumbnails, thumbnail)
+	}
+	return thumbnails
+}
+
+// makeThumbnail reuses a thumbnail newer than its document or renders it, scales it to fit
+// maxSize, and saves it
+func (r *Renderer) makeThumbnail(
+	backend renderBackend, thumbnail *Thumbnail, size [2]float64, maxSize int, overwrite, includeData bool,
+) error {
+	if !overwrite {
+		if data, ok := freshThumbnail(thumbnail.OutputPath, thumbnail.Path); ok {
+			thumbnail.Reused = true
+			return fillThumbnail(thumbnail, data, includeData)
+		}
+	}
+
+	dpi := max(int(math.Ceil(float64(maxSize)*pointsPerInch/max(size[0], size[1], 1))), 1)
+	rendered, err := runRenderBackend(backend, thumbnail.Path, thumbnail.Page, dpi)
+	if err != nil {
+		return err
+	}
+	img, err := png.Decode(bytes.NewReader(rendered))
+	if err != nil {
+		return fmt.Errorf("%s produced an unreadable image: %w", backend.name, err)
+	}
+
+	// Renderers round page sizes up, so the image can be a pixel or so over the limit
+	if width, height := img.Bounds().Dx(), img.Bounds().Dy(); width > maxSize || height > maxSize {
+		scale := float64(maxSize) / float64(max(width, height))
+		img = scaleImage(img, max(int(float64(width)*scale), 1), max(int(float64(height)*scale), 1))
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	if err := fsys.MkdirAll(filepath.Dir(thumbnail.OutputPath), attachmentDirPerm); err != nil {
+		return fmt.Errorf("cannot create thumbnail directory: %w", err)
+	}
+	if err := fsys.WriteFile(thumbnail.OutputPath, buf.Bytes(), renderFilePerm); err != nil {
+		return fmt.Errorf("failed to save thumbnail: %w", err)
+	}
+	return fillThumbnail(thumbnail, buf.Bytes(), includeData)
+}
+
+// thumbnailPages returns the sizes in points, as displayed after rotation, of the requested
+// pages a document has, and the labels of all its pages
+func thumbnailPages(path string, pages []int) (sizes map[int][2]float64, labels map[int]string, err error) {
+	doc, err := core.Open(path, core.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer doc.Close()
+
+	// The parser panics on malformed objects
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("failed to read pages: %v", rec)
+		}
+	}()
+
+	numbering := extraction.NewPageNumbering(doc.Reader)
+	sizes = make(map[int][2]float64, len(pages))
+	labels = make(map[int]string, numbering.Count())
+	for page := 1; page <= numbering.Count(); page++ {
+		labels[page] = numbering.Label(page)
+	}
+	for _, page := range pages {
+		if page < 1 || page > numbering.Count() {
+			continue
+		}
+		width, height := pageDimensions(numbering.Page(page))
+		if rotation := numbering.Rotation(page); rotation == 90 || rotation == 270 {
+			width, height = height, width
+		}
+		sizes[page] = [2]float64{width, height}
+	}
+	return sizes, labels, nil
+}
+
+// thumbnailPath returns where the thumbnail of a page is saved: beside the document, or
+// under outputDir at the document's path relative to the scanned directory
+func thumbnailPath(directory, outputDir, path string, page int) string {
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name := fmt.Sprintf("%s-page-%d-thumb.png", stem, page)
+	if outputDir == "" {
+		return filepath.Join(filepath.Dir(path), name)
+	}
+	rel, err := filepath.Rel(directory, filepath.Dir(path))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = ""
+	}
+	return filepath.Join(outputDir, rel, name)
+}
+
+// freshThumbnail reads a saved thumbnail that was written after its document last changed
+func freshThumbnail(thumbnailPath, documentPath string) ([]byte, bool) {
+	thumbnailInfo, err := fsys.Stat(thumbnailPath)
+	if err != nil {
+		return nil, false
+	}
+	documentInfo, err := fsys.Stat(documentPath)
+	if err != nil || thumbnailInfo.ModTime().Before(documentInfo.ModTime()) {
+		return nil, false
+	}
+	data, err := fsys.ReadFile(thumbnailPath)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// fillThumbnail records a thumbnail's dimensions and size, and its data when requested
+func fillThumbnail(thumbnail *Thumbnail, data []byte, includeData bool) error {
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("thumbnail is not a PNG image: %w", err)
+	}
+	thumbnail.Width, thumbnail.Height = config.Width, config.Height
+	thumbnail.Size = len(data)
+	if includeData {
+		thumbnail.Data = base64.StdEncoding.EncodeToString(data)
+	}
+	return nil
+}
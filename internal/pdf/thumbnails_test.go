@@ -0,0 +1,87 @@
+package pdf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderer_GenerateThumbnails(t *testing.T) {
+	renderer := fakeRenderer(t)
+	dir := t.TempDir()
+	content := buildTestPDF("BT /F1 12 Tf 72 720 Td (Page one) Tj ET")
+	if err := os.MkdirAll(filepath.Join(dir, "2024"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"report.pdf", filepath.Join("2024", "invoice.pdf")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := filepath.Join(t.TempDir(), "thumbnails")
+	req := PDFGenerateThumbnailsRequest{Directory: dir, Pages: []int{1, 2}, MaxSize: 64, OutputDir: cache}
+	result, err := renderer.GenerateThumbnails(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateThumbnails() unexpected error = %v", err)
+	}
+	if result.Files != 2 || result.Generated != 2 || result.Reused != 0 || result.Failed != 2 {
+		t.Fatalf("GenerateThumbnails() = %+v, want 2 generated and page 2 failing in both files", result)
+	}
+	saved := map[string]bool{}
+	for _, thumbnail := range result.Thumbnails {
+		if thumbnail.Page == 2 {
+			if thumbnail.Error == "" {
+				t.Errorf("thumbnail of missing page 2 of %s has no error", thumbnail.Path)
+			}
+			continue
+		}
+		// The 85x110 render is scaled to fit 64 pixels
+		if thumbnail.Width != 49 || thumbnail.Height != 64 || thumbnail.Data != "" {
+			t.Errorf("thumbnail = %+v, want 49x64 without data", thumbnail)
+		}
+		if _, err := os.Stat(thumbnail.OutputPath); err != nil {
+			t.Errorf("thumbnail not saved: %v", err)
+		}
+		saved[thumbnail.OutputPath] = true
+	}
+	for _, want := range []string{
+		filepath.Join(cache, "report-page-1-thumb.png"),
+		filepath.Join(cache, "2024", "invoice-page-1-thumb.png"),
+	} {
+		if !saved[want] {
+			t.Errorf("thumbnails saved as %v, want %s", saved, want)
+		}
+	}
+
+	// Saved thumbnails newer than their documents are reused unless overwritten
+	req.Pages = nil
+	req.IncludeData = true
+	result, err = renderer.GenerateThumbnails(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GenerateThumbnails() unexpected error = %v", err)
+	}
+	if result.Reused != 2 || result.Generated != 0 || result.Thumbnails[0].Data == "" {
+		t.Errorf("GenerateThumbnails() again = %+v, want both reused with data", result)
+	}
+	req.Overwrite = true
+	result, err = renderer.GenerateThumbnails(context.Background(), req)
+	if err != nil || result.Generated != 2 {
+		t.Errorf("GenerateThumbnails() overwriting = %+v, %v, want both generated", result, err)
+	}
+
+	// Without a cache directory thumbnails go beside the documents
+	result, err = renderer.GenerateThumbnails(context.Background(), PDFGenerateThumbnailsRequest{Directory: dir})
+	if err != nil {
+		t.Fatalf("GenerateThumbnails() unexpected error = %v", err)
+	}
+	if result.Generated != 2 || result.Thumbnails[0].OutputPath != filepath.Join(dir, "2024", "invoice-page-1-thumb.png") {
+		t.Errorf("GenerateThumbnails() beside documents = %+v", result.Thumbnails)
+	}
+
+	if _, err := renderer.GenerateThumbnails(context.Background(),
+		PDFGenerateThumbnailsRequest{Directory: dir, MaxSize: 8}); err == nil {
+		t.Error("GenerateThumbnails() accepted a size under the minimum")
+	}
+}
@@ -0,0 +1,386 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+)
+
+// maxBalanceWarnings is how many transactions whose balance does not follow are reported
+const maxBalanceWarnings = 5
+
+// statementTypes are the document types whose transactions pdf_extract_transactions reads
+var statementTypes = []string{"bank_statement"}
+
+// Headers of the columns of a transaction table
+var (
+	balanceHeader          = regexp.MustCompile(`(?i)\bbalance\b`)
+	dateHeader             = regexp.MustCompile(`(?i)\b(?:date|posted|posting)\b`)
+	debitHeader            = regexp.MustCompile(`(?i)\b(?:debits?|withdrawals?|paid\s+out|money\s+out|payments?|dr)\b`)
+	creditHeader           = regexp.MustCompile(`(?i)\b(?:credits?|deposits?|paid\s+in|money\s+in|receipts?|cr)\b`)
+	transactionAmountLabel = regexp.MustCompile(`(?i)\bamount\b`)
+	narrativeHeader        = regexp.MustCompile(`(?i)\b(?:description|details|transactions?|narrative|particulars|` +
+		`memo|payee|reference)\b`)
+)
+
+// Labels of the balances a statement starts and ends with
+var (
+	openingBalanceLabel = regexp.MustCompile(`(?i)\b(?:opening|previous|beginning|starting)\s+balance\b|` +
+		`\bbalance\s+(?:brought\s+forward|b/f|forward)\b`)
+	closingBalanceLabel = regexp.MustCompile(`(?i)\b(?:closing|ending|new|final)\s+balance\b|` +
+		`\bbalance\s+(?:carried\s+forward|c/f)\b`)
+)
+
+// balanceMark is the CR or DR marking the side of an amount on some statements
+var balanceMark = regexp.MustCompile(`(?i)\s*\b(CR|DR)\.?$`)
+
+// StatementReader reads the transactions of bank statements
+type StatementReader struct {
+	maxFileSize int64
+	validator   *Validator
+	classifier  *Classifier
+	engine      *extraction.DefaultEngine
+}
+
+// NewStatementReader creates a new statement reader with the built-in classification
+// profiles
+func NewStatementReader(maxFileSize int64) *StatementReader {
+	return &StatementReader{
+		maxFileSize: maxFileSize,
+		validator:   NewValidator(maxFileSize),
+		classifier:  NewClassifier(maxFileSize),
+		engine:      extraction.NewEngineWithConfig(maxFileSize, maxFileSize, false),
+	}
+}
+
+// SetProfiles sets the classification profiles that decide whether a document is a bank
+// statement
+func (r *StatementReader) SetProfiles(custom *ClassificationProfiles) {
+	r.classifier.SetProfiles(custom)
+}
+
+// transactionColumns are the column indexes of a transaction table, -1 when it has none
+type transactionColumns struct {
+	date, description, amount, debit, credit, balance int
+	count                                             int // Columns of the table
+}
+
+// statementEntry is a transaction as read, with whether its amount carried a sign
+type statementEntry struct {
+	StatementTransaction
+	value, balance float64
+	hasBalance     bool
+	signed         bool
+}
+
+// ExtractTransactions classifies a document by the text of its first pages and, when it is
+// a bank statement or req.Force is set, reads the transactions of its tables that have date
+// and amount, or debit and credit, columns. Tables without headers on later pages continue
+// the columns of the table before them. Rows without a date or amount continue the
+// description of the transaction above, or lend it their amount when it had none; rows
+// without a date but with an amount share the date above. Debits are negative; unsigned
+// amounts in a single column take the sign that makes the balance follow. The opening and
+// closing balances are read from their labels, and balances that do not follow from the
+// amounts are reported as warnings.
+func (r *StatementReader) ExtractTransactions(
+	ctx context.Context, req PDFExtractTransactionsRequest,
+) (*PDFExtractTransactionsResult, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	ctx, release := core.WithDocuments(ctx)
+	defer release()
+	doc, err := core.OpenShared(ctx, req.Path, core.Options{Validate: r.validator.ValidateFileInfo})
+	if err != nil {
+		return nil, err
+	}
+	defer doc.Close()
+
+	numbering := extraction.NewPageNumbering(doc.Reader)
+	result := &PDFExtractTransactionsResult{Path: req.Path, TotalPages: numbering.Count()}
+	document := newDocumentLines(numbering)
+	defer func() { result.FailedPages = document.failedPages }()
+	if result.DocumentType, result.Score, err = document.classify(ctx, r.classifier); err != nil {
+		return nil, err
+	}
+	result.Detected = slices.Contains(statementTypes, result.DocumentType)
+	if !result.Detected && !req.Force {
+		return result, nil
+	}
+	if err := document.readRest(ctx); err != nil {
+		return nil, err
+	}
+
+	tables, err := detectTables(ctx, r.engine, req.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	statement := &BankStatement{Currency: document.currency(), Transactions: []StatementTransaction{}}
+	for _, line := range document.lines {
+		if loc := openingBalanceLabel.FindStringIndex(line.Text); loc != nil && statement.OpeningBalance == "" {
+			statement.OpeningBalance = labeledAmount(line.Text[loc[1]:])
+		}
+		if loc := closingBalanceLabel.FindStringIndex(line.Text); loc != nil {
+			if amount := labeledAmount(line.Text[loc[1]:]); amount != "" {
+				statement.ClosingBalance = amount
+			}
+		}
+	}
+
+	entries := tableTransactions(tables, statementYear(document.lines))
+	result.Warnings = reconcileTransactions(entries, statement)
+	result.Statement = statement
+	return result, nil
+}
+
+// transactionTableColumns finds the columns of a transaction table by their headers. A
+// column headed both debit and credit holds amounts marked with their side.
+func transactionTableColumns(table extraction.TableElement) (transactionColumns, bool) {
+	columns := transactionColumns{date: -1, description: -1, amount: -1, debit: -1, credit: -1, balance: -1,
+		count: len(table.Columns)}
+	assign := func(field *int, c int) {
+		if *field < 0 {
+			*field = c
+		}
+	}
+	for c, column := range table.Columns {
+		switch header := column.Header; {
+		case header == "":
+		case balanceHeader.MatchString(header):
+			assign(&columns.balance, c)
+		case dateHeader.MatchString(header):
+			assign(&columns.date, c)
+		case debitHeader.MatchString(header) && creditHeader.MatchString(header):
+			assign(&columns.amount, c)
+		case debitHeader.MatchString(header):
+			assign(&columns.debit, c)
+		case creditHeader.MatchString(header):
+			assign(&columns.credit, c)
+		case transactionAmountLabel.MatchString(header):
+			assign(&columns.amount, c)
+		case narrativeHeader.MatchString(header):
+			assign(&columns.description, c)
+		}
+	}
+	ok := columns.date >= 0 && (columns.amount >= 0 || columns.debit >= 0 || columns.credit >= 0)
+	return columns, ok
+}
+
+// tableTransactions reads the transactions of the tables that hold them, page by page.
+// Dates without a year take the given one.
+func tableTransactions(tables map[int][]extraction.TableElement, year string) []statementEntry {
+	pages := make([]int, 0, len(tables))
+	for pageNum := range tables {
+		pages = append(pages, pageNum)
+	}
+	sort.Ints(pages)
+
+	var entries []statementEntry
+	var pending *statementEntry // A dated transaction whose amount is on a following row
+	var previous transactionColumns
+	for _, pageNum := range pages {
+		for _, table := range tables[pageNum] {
+			columns, ok := transactionTableColumns(table)
+			continuation := false
+			if !ok {
+				// A table on a later page may repeat the columns without their headers
+				if previous.count == 0 || previous.count != len(table.Columns) {
+					continue
+				}
+				columns, continuation = previous, true
+			}
+			previous = columns
+
+			for _, row := range table.Rows {
+				if row.IsHeader && !continuation {
+					continue
+				}
+				cells := make(map[int]string)
+				for _, cell := range row.Cells {
+					cells[cell.ColIndex] = strings.Join(strings.Fields(cell.Content), " ")
+				}
+				description := cells[columns.description]
+				if openingBalanceLabel.MatchString(description) || closingBalanceLabel.MatchString(description) {
+					continue
+				}
+
+				entry := statementEntry{StatementTransaction: StatementTransaction{
+					Date:        statementDate(cells[columns.date], year),
+					Description: description,
+					Page:        pageNum,
+				}}
+				hasAmount := readTransactionAmount(cells, columns, &entry)
+				if balance, _, ok := statementAmount(cells[columns.balance]); ok && columns.balance >= 0 {
+					entry.balance, entry.hasBalance = balance, true
+					entry.Balance = formatAmount(balance)
+				}
+
+				switch {
+				case entry.Date != "" && !hasAmount:
+					pending = &entry
+					continue
+				case entry.Date == "" && !hasAmount:
+					switch {
+					case pending != nil:
+						pending.Description = strings.TrimSpace(pending.Description + " " + description)
+					case len(entries) > 0:
+						last := &entries[len(entries)-1]
+						last.Description = strings.TrimSpace(last.Description + " " + description)
+					}
+					continue
+				case entry.Date == "" && pending != nil:
+					entry.Date = pending.Date
+					entry.Description = strings.TrimSpace(pending.Description + " " + description)
+				case entry.Date == "" && len(entries) > 0:
+					entry.Date = entries[len(entries)-1].Date
+				case entry.Date == "":
+					continue
+				}
+				pending = nil
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// readTransactionAmount reads the amount of a row: from its amount column, or as a credit
+// less a debit. It reports whether the row has one.
+func readTransactionAmount(cells map[int]string, columns transactionColumns, entry *statementEntry) bool {
+	found := false
+	if columns.amount >= 0 {
+		if value, signed, ok := statementAmount(cells[columns.amount]); ok {
+			entry.value, entry.signed, found = value, signed, true
+		}
+	}
+	if columns.credit >= 0 {
+		if value, _, ok := statementAmount(cells[columns.credit]); ok {
+			entry.value, entry.signed, found = entry.value+math.Abs(value), true, true
+		}
+	}
+	if columns.debit >= 0 {
+		if value, _, ok := statementAmount(cells[columns.debit]); ok {
+			entry.value, entry.signed, found = entry.value-math.Abs(value), true, true
+		}
+	}
+	entry.Amount = formatAmount(entry.value)
+	return found
+}
+
+// statementAmount parses an amount that may be marked CR or DR, reporting whether it
+// carried a sign or mark
+func statementAmount(text string) (value float64, signed, ok bool) {
+	text = strings.TrimSpace(text)
+	sign := 1.0
+	if m := balanceMark.FindStringSubmatch(text); m != nil {
+		text, signed = strings.TrimSpace(text[:len(text)-len(m[0])]), true
+		if strings.EqualFold(m[1], "DR") {
+			sign = -1
+		}
+	}
+	dataType, normalized := extraction.InferCellValue(text)
+	if dataType != extraction.CellTypeNumber && dataType != extraction.CellTypeCurrency {
+		return 0, false, false
+	}
+	number, err := strconv.ParseFloat(normalized, 64)
+	if err != nil {
+		return 0, false, false
+	}
+	signed = signed || strings.ContainsAny(text, "-−(")
+	return sign * number, signed, true
+}
+
+// statementDate normalizes a transaction date as YYYY-MM-DD, completing dates written
+// without a year, such as 03/15 or 15 Mar, with the statement's year
+func statementDate(text, year string) string {
+	if text == "" {
+		return ""
+	}
+	candidates := []string{text}
+	if year != "" {
+		candidates = append(candidates, text+" "+year, text+"/"+year)
+	}
+	for _, candidate := range candidates {
+		if dataType, date := extraction.InferCellValue(candidate); dataType == extraction.CellTypeDate {
+			return date
+		}
+	}
+	return ""
+}
+
+// statementYear returns the year of the first complete date on a statement's lines
+func statementYear(lines []textLine) string {
+	for _, line := range lines {
+		words := strings.Fields(line.Text)
+		for i := range words {
+			for n := min(len(words)-i, invoiceValueWords); n > 0; n-- {
+				value := strings.TrimRight(strings.Join(words[i:i+n], " "), ",;")
+				if dataType, date := extraction.InferCellValue(value); dataType == extraction.CellTypeDate {
+					return date[:4]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// reconcileTransactions signs unsigned amounts so that each balance follows from the one
+// before, fills in the statement's transactions and totals, and reports the balances that
+// still do not follow, and a closing balance that the opening balance and amounts do not
+// reach
+func reconcileTransactions(entries []statementEntry, statement *BankStatement) []string {
+	var warnings []string
+	opening, hasOpening := parseAmount(statement.OpeningBalance)
+	balance, hasBalance := opening, hasOpening
+	mismatches := 0
+	credits, debits := 0.0, 0.0
+	for i := range entries {
+		entry := &entries[i]
+		if hasBalance && entry.hasBalance && !entry.signed &&
+			math.Abs(balance+entry.value-entry.balance) > invoiceTolerance &&
+			math.Abs(balance-entry.value-entry.balance) <= invoiceTolerance {
+			entry.value = -entry.value
+			entry.Amount = formatAmount(entry.value)
+		}
+		if hasBalance && entry.hasBalance && math.Abs(balance+entry.value-entry.balance) > invoiceTolerance {
+			if mismatches < maxBalanceWarnings {
+				warnings = append(warnings, fmt.Sprintf("the balance %s after %q on %s does not follow from %s and %s",
+					entry.Balance, entry.Description, entry.Date, formatAmount(balance), entry.Amount))
+			}
+			mismatches++
+		}
+		switch {
+		case entry.hasBalance:
+			balance, hasBalance = entry.balance, true
+		case hasBalance:
+			balance += entry.value
+		}
+		if entry.value > 0 {
+			credits += entry.value
+		} else {
+			debits -= entry.value
+		}
+		statement.Transactions = append(statement.Transactions, entry.StatementTransaction)
+	}
+	if mismatches > maxBalanceWarnings {
+		warnings = append(warnings, fmt.Sprintf("%d more balances do not follow", mismatches-maxBalanceWarnings))
+	}
+	statement.TotalCredits, statement.TotalDebits = formatAmount(credits), formatAmount(debits)
+
+	if closing, ok := parseAmount(statement.ClosingBalance); ok && hasOpening && len(entries) > 0 {
+		if reached := opening + credits - debits; math.Abs(reached-closing) > invoiceTolerance {
+			warnings = append(warnings, fmt.Sprintf("the opening balance %s and the transactions reach %s, "+
+				"not the closing balance %s", statement.OpeningBalance, formatAmount(reached), statement.ClosingBalance))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,105 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// ruledGrid draws a table with a cell border around every cell; xs and ys are the column
+// and row boundaries from left to right and from the top down
+func ruledGrid(xs, ys []int, cells [][]string) string {
+	var b strings.Builder
+	b.WriteString("1 w\n")
+	for _, y := range ys {
+		fmt.Fprintf(&b, "%d %d m %d %d l S\n", xs[0], y, xs[len(xs)-1], y)
+	}
+	for _, x := range xs {
+		fmt.Fprintf(&b, "%d %d m %d %d l S\n", x, ys[len(ys)-1], x, ys[0])
+	}
+	for r, row := range cells {
+		for c, text := range row {
+			if text != "" {
+				fmt.Fprintf(&b, "BT /F1 9 Tf %d %d Td (%s) Tj ET\n", xs[c]+4, ys[r+1]+6, text)
+			}
+		}
+	}
+	return b.String()
+}
+
+func TestStatementReader_ExtractTransactions(t *testing.T) {
+	xs := []int{60, 120, 300, 380, 460, 540}
+	first := "BT /F1 12 Tf 60 750 Td (First Bank) Tj ET\n" +
+		"BT /F1 10 Tf 60 730 Td (Account Statement) Tj ET\n" +
+		"BT /F1 10 Tf 60 715 Td (Statement period: March 1, 2024 to March 31, 2024) Tj ET\n" +
+		"BT /F1 10 Tf 60 700 Td (Account number 12345678) Tj ET\n" +
+		"BT /F1 10 Tf 60 685 Td (Opening balance $1,000.00) Tj ET\n" +
+		ruledGrid(xs, []int{660, 640, 620, 600, 580, 560}, [][]string{
+			{"Date", "Description", "Withdrawals", "Deposits", "Balance"},
+			{"03/01", "Coffee Shop", "4.50", "", "995.50"},
+			{"", "Card ending 1234", "", "", ""},
+			{"03/02", "Salary", "", "2,000.00", "2,995.50"},
+			{"03/05", "Rent", "1,200.00", "", "1,795.50"},
+		})
+	// The table continues on the next page without its headers
+	second := ruledGrid(xs, []int{700, 680, 660}, [][]string{
+		{"03/07", "Refund", "", "20.00", "1,815.50"},
+		{"03/09", "ATM withdrawal", "100.00", "", "1,715.50"},
+	}) + "BT /F1 10 Tf 60 630 Td (Closing balance $1,715.50) Tj ET\n"
+	path := createTempFile(t, "statement.pdf", buildTestPDF(first, second))
+
+	reader := NewStatementReader(100 * 1024 * 1024)
+	result, err := reader.ExtractTransactions(context.Background(), PDFExtractTransactionsRequest{Path: path})
+	if err != nil {
+		t.Fatalf("ExtractTransactions() unexpected error = %v", err)
+	}
+	if !result.Detected || result.DocumentType != "bank_statement" || result.Statement == nil {
+		t.Fatalf("ExtractTransactions() = %+v, want a bank statement", result)
+	}
+	statement := result.Statement
+	var got []string
+	for _, tx := range statement.Transactions {
+		got = append(got, fmt.Sprintf("%s %s %s %s p%d", tx.Date, tx.Description, tx.Amount, tx.Balance, tx.Page))
+	}
+	want := []string{
+		"2024-03-01 Coffee Shop Card ending 1234 -4.5 995.5 p1",
+		"2024-03-02 Salary 2000 2995.5 p1",
+		"2024-03-05 Rent -1200 1795.5 p1",
+		"2024-03-07 Refund 20 1815.5 p2",
+		"2024-03-09 ATM withdrawal -100 1715.5 p2",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("transactions =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if statement.OpeningBalance != "1000" || statement.ClosingBalance != "1715.5" || statement.Currency != "USD" ||
+		statement.TotalCredits != "2020" || statement.TotalDebits != "1304.5" {
+		t.Errorf("statement = %+v, want balances 1000 and 1715.5 in USD", statement)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", result.Warnings)
+	}
+}
+
+func TestReconcileTransactions(t *testing.T) {
+	entry := func(date string, value, balance float64) statementEntry {
+		return statementEntry{
+			StatementTransaction: StatementTransaction{Date: date, Amount: formatAmount(value),
+				Balance: formatAmount(balance)},
+			value: value, balance: balance, hasBalance: true,
+		}
+	}
+	// Unsigned amounts take the sign the balances call for; a balance that follows from
+	// neither sign is reported
+	entries := []statementEntry{entry("2024-03-01", 50, 50), entry("2024-03-02", 20, 70), entry("2024-03-03", 5, 60)}
+	statement := &BankStatement{OpeningBalance: "100", ClosingBalance: "60"}
+	warnings := reconcileTransactions(entries, statement)
+
+	if statement.Transactions[0].Amount != "-50" || statement.Transactions[1].Amount != "20" {
+		t.Errorf("transactions = %+v, want a debit and a credit", statement.Transactions)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "the balance 60") ||
+		!strings.Contains(warnings[1], "reach 75") {
+		t.Errorf("warnings = %q, want the third balance and the closing balance reported", warnings)
+	}
+}
@@ -11,22 +11,54 @@ type FileInfo struct {
 // ImageInfo represents information about an image in a PDF
 type ImageInfo struct {
 	PageNumber int    `json:"page_number"`
+	PageLabel  string `json:"page_label,omitempty"`
 	Width      int    `json:"width"`
 	Height     int    `json:"height"`
 	Format     string `json:"format"`
 	Size       int64  `json:"size"`
+	// Set when classification recognizes the image
+	Kind       string  `json:"kind,omitempty"`      // barcode, qr_code, signature, or stamp
+	Symbology  string  `json:"symbology,omitempty"` // code128, ean13, code39, or qr for decoded codes
+	Payload    string  `json:"payload,omitempty"`   // The decoded content of a barcode or QR code
+	Confidence float64 `json:"confidence,omitempty"`
+	// Where the page draws the image; empty for images drawn only inside form XObjects
+	Placements   []ImagePlacement `json:"placements,omitempty"`
+	EffectiveDPI float64          `json:"effective_dpi,omitempty"` // The lowest resolution of any placement
+	LowDPI       bool             `json:"low_dpi,omitempty"`       // Drawn below the requested minimum DPI
+}
+
+// ImagePlacement represents one drawing of an image on its page and the resolution it
+// prints at there
+type ImagePlacement struct {
+	BoundingBox  Rectangle `json:"bounding_box"`
+	WidthPoints  float64   `json:"width_points"`
+	HeightPoints float64   `json:"height_points"`
+	WidthInches  float64   `json:"width_inches"`
+	HeightInches float64   `json:"height_inches"`
+	DPIX         float64   `json:"dpi_x"`
+	DPIY         float64   `json:"dpi_y"`
+	EffectiveDPI float64   `json:"effective_dpi"` // The lower of the two
 }
 
 // Request Types
 
 // PDFReadFileRequest represents a request to read a PDF file
 type PDFReadFileRequest struct {
-	Path string `json:"path"`
+	Path       string     `json:"path"`
+	FirstPages int        `json:"first_pages,omitempty"` // Read only the opening pages
+	LastPages  int        `json:"last_pages,omitempty"`  // Read only the closing pages
+	Layout     string     `json:"layout,omitempty"`      // raw (default), layout, reading-order, or markdown
+	Pages      []int      `json:"pages,omitempty"`       // Read only these pages
+	Region     *Rectangle `json:"region,omitempty"`      // Read only this part of each page
+	// Leave out running headers, footers, page numbers, and watermarks
+	StripHeadersFooters bool `json:"strip_headers_footers,omitempty"`
 }
 
 // PDFAssetsFileRequest represents a request to get visual assets from a PDF file
 type PDFAssetsFileRequest struct {
-	Path string `json:"path"`
+	Path     string  `json:"path"`
+	Classify bool    `json:"classify,omitempty"` // Decode images to recognize codes, signatures, and stamps
+	MinDPI   float64 `json:"min_dpi,omitempty"`  // Resolution below which images are flagged (default: 300)
 }
 
 // PDFValidateFileRequest represents a request to validate a PDF file
@@ -45,6 +77,15 @@ type PDFSearchDirectoryRequest struct {
 	Query     string `json:"query"`
 }
 
+// PDFSearchContentRequest represents a request to search the text of every PDF in a directory
+type PDFSearchContentRequest struct {
+	Directory      string `json:"directory"`
+	Query          string `json:"query"`
+	CaseSensitive  bool   `json:"case_sensitive,omitempty"`
+	MaxResults     int    `json:"max_results,omitempty"`     // Snippets returned across all files
+	MaxConcurrency int    `json:"max_concurrency,omitempty"` // Documents searched at once
+}
+
 // PDFStatsDirectoryRequest represents a request to get directory statistics
 type PDFStatsDirectoryRequest struct {
 	Directory string `json:"directory"`
@@ -61,6 +102,33 @@ type PDFReadFileResult struct {
 	ContentType string `json:"content_type"` // "text", "scanned_images", "mixed", "no_content"
 	HasImages   bool   `json:"has_images"`   // Whether the PDF contains extractable images
 	ImageCount  int    `json:"image_count"`  // Number of images detected
+	Layout      string `json:"layout,omitempty"`
+	// Pages read when pages, first_pages, or last_pages was requested
+	PageSelection *PageSelection `json:"page_selection,omitempty"`
+	// Part of each page read, when a region was requested
+	Region *Rectangle `json:"region,omitempty"`
+	// Running headers, footers, and watermarks left out, when strip_headers_footers was requested
+	RepeatedLines []RepeatedLine `json:"repeated_lines,omitempty"`
+	// Outcome of the configured escalation policy
+	Escalation *QualityEscalation `json:"escalation,omitempty"`
+	// Files embedded in a portfolio, whose cover sheet is all the content read
+	PortfolioMembers int `json:"portfolio_members,omitempty"`
+}
+
+// RepeatedLine is a line of text drawn at about the same position on many pages
+type RepeatedLine struct {
+	Text        string    `json:"text"`
+	Role        string    `json:"role"` // header, footer, or watermark
+	Page        int       `json:"page"`
+	BoundingBox Rectangle `json:"bounding_box"`
+}
+
+// QualityEscalation reports the quality of extracted text and the escalation rules it triggered
+type QualityEscalation struct {
+	DecodeQuality float64  `json:"decode_quality"`
+	NeedsHuman    bool     `json:"needs_human"`
+	Triggered     []string `json:"triggered,omitempty"` // Rules below their threshold, e.g. "decode_quality<0.6:needs_human"
+	Warnings      []string `json:"warnings,omitempty"`
 }
 
 // PDFAssetsFileResult represents the result of a PDF assets extraction operation
@@ -68,6 +136,9 @@ type PDFAssetsFileResult struct {
 	Path       string      `json:"path"`
 	Images     []ImageInfo `json:"images"`
 	TotalCount int         `json:"total_count"`
+	Classified int         `json:"classified,omitempty"` // Images decoded and examined when classifying
+	MinDPI     float64     `json:"min_dpi"`
+	LowDPI     int         `json:"low_dpi_count"` // Images drawn below MinDPI
 }
 
 // PDFValidateFileResult represents the result of a PDF validation operation
@@ -88,6 +159,8 @@ type PDFStatsFileResult struct {
 	Author       string `json:"author,omitempty"`
 	Subject      string `json:"subject,omitempty"`
 	Producer     string `json:"producer,omitempty"`
+
+	PageIssues []string `json:"page_issues,omitempty"` // Where the page tree disagrees with itself
 }
 
 // PDFSearchDirectoryResult represents the result of a PDF search operation
@@ -98,6 +171,35 @@ type PDFSearchDirectoryResult struct {
 	SearchQuery string     `json:"search_query,omitempty"`
 }
 
+// ContentSnippet is a match and its surrounding text
+type ContentSnippet struct {
+	Page      int    `json:"page"`
+	PageLabel string `json:"page_label,omitempty"`
+	Text      string `json:"text"`
+}
+
+// ContentSearchFile reports the matches found in one document
+type ContentSearchFile struct {
+	Path       string           `json:"path"`
+	MatchCount int              `json:"match_count"`
+	Pages      []int            `json:"pages,omitempty"`
+	Snippets   []ContentSnippet `json:"snippets,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// PDFSearchContentResult represents the documents of a directory that contain a query
+type PDFSearchContentResult struct {
+	Directory     string              `json:"directory"`
+	Query         string              `json:"query"`
+	CaseSensitive bool                `json:"case_sensitive"`
+	Files         []ContentSearchFile `json:"files"` // Matching and failed files, most matches first
+	TotalMatches  int                 `json:"total_matches"`
+	FilesSearched int                 `json:"files_searched"`
+	FilesMatched  int                 `json:"files_matched"`
+	FilesFailed   int                 `json:"files_failed"`
+	Truncated     bool                `json:"truncated"` // Snippets were cut at max_results
+}
+
 // PDFStatsDirectoryResult represents the result of directory statistics
 type PDFStatsDirectoryResult struct {
 	Directory        string `json:"directory"`
@@ -117,14 +219,63 @@ type PDFServerInfoRequest struct {
 
 // PDFServerInfoResult represents server information and usage guidance
 type PDFServerInfoResult struct {
-	ServerName        string     `json:"server_name"`
-	Version           string     `json:"version"`
-	DefaultDirectory  string     `json:"default_directory"`
-	MaxFileSize       int64      `json:"max_file_size"`
-	AvailableTools    []ToolInfo `json:"available_tools"`
-	DirectoryContents []FileInfo `json:"directory_contents"`
-	UsageGuidance     string     `json:"usage_guidance"`
-	SupportedFormats  []string   `json:"supported_formats"`
+	ServerName        string          `json:"server_name"`
+	Version           string          `json:"version"`
+	DefaultDirectory  string          `json:"default_directory"`
+	Roots             []string        `json:"roots,omitempty"` // Directories whose PDFs are exposed, the default first
+	MaxFileSize       int64           `json:"max_file_size"`
+	AvailableTools    []ToolInfo      `json:"available_tools"`
+	DirectoryContents []FileInfo      `json:"directory_contents"`
+	UsageGuidance     string          `json:"usage_guidance"`
+	SupportedFormats  []string        `json:"supported_formats"`
+	EnabledTools      []string        `json:"enabled_tools,omitempty"`  // Tools the server registered
+	DisabledTools     []string        `json:"disabled_tools,omitempty"` // Tools the configuration withheld
+	ReadOnly          bool            `json:"read_only"`                // Whether output files are refused
+	Metrics           *MetricsSummary `json:"metrics,omitempty"`        // Activity since the server started
+}
+
+// PDFHealthRequest represents a request to check the server's health
+type PDFHealthRequest struct {
+	Directories []string `json:"directories"` // PDF directories that must be readable
+}
+
+// PDFHealthResult reports the health of each subsystem
+type PDFHealthResult struct {
+	Status    string        `json:"status"` // pass when every required check passed, else fail
+	Checks    []HealthCheck `json:"checks"`
+	CheckedAt string        `json:"checked_at"` // RFC 3339
+}
+
+// HealthCheck is the outcome of checking one subsystem
+type HealthCheck struct {
+	Name       string  `json:"name"`     // directory, temp, ocr, memory, or self_test
+	Status     string  `json:"status"`   // pass or fail
+	Required   bool    `json:"required"` // Whether a failure fails the result
+	Detail     string  `json:"detail"`   // What was found, or why the check failed
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// MetricsSummary summarizes a server's activity since it started
+type MetricsSummary struct {
+	UptimeSeconds      float64              `json:"uptime_seconds"`
+	ToolCalls          int64                `json:"tool_calls"`
+	ToolErrors         int64                `json:"tool_errors"`
+	Extractions        int64                `json:"extractions"`         // Extractions run, excluding cached results
+	PartialExtractions int64                `json:"partial_extractions"` // Extractions cut short by a timeout or limit
+	PagesExtracted     int64                `json:"pages_extracted"`
+	CacheHitRate       float64              `json:"cache_hit_rate"`  // Share of extraction cache lookups that hit
+	HandleHitRate      float64              `json:"handle_hit_rate"` // Share of document opens served by a held document
+	Tools              []ToolMetricsSummary `json:"tools"`           // Most called first
+}
+
+// ToolMetricsSummary summarizes the calls of one tool
+type ToolMetricsSummary struct {
+	Name           string  `json:"name"`
+	Calls          int64   `json:"calls"`
+	Errors         int64   `json:"errors"`
+	MeanDurationMS float64 `json:"mean_duration_ms"`
+	MaxDurationMS  float64 `json:"max_duration_ms"`
+	BytesRead      int64   `json:"bytes_read"` // Total size of the documents the calls read
 }
 
 // ToolInfo represents information about an available tool
@@ -147,8 +298,10 @@ type PDFExtractStructuredRequest struct {
 
 // PDFExtractTablesRequest represents a request for table extraction
 type PDFExtractTablesRequest struct {
-	Path   string           `json:"path"`
-	Config ExtractionConfig `json:"config,omitempty"`
+	Path         string           `json:"path"`
+	Config       ExtractionConfig `json:"config,omitempty"`
+	ExportFormat string           `json:"export_format,omitempty"` // csv or xlsx; inferred from OutputPath when empty
+	OutputPath   string           `json:"output_path,omitempty"`   // Write the tables here
 }
 
 // PDFExtractSemanticRequest represents a request for semantic content extraction
@@ -183,15 +336,34 @@ type PDFGetMetadataRequest struct {
 
 // ExtractionConfig provides configuration for extraction operations
 type ExtractionConfig struct {
-	ExtractText        bool    `json:"extract_text,omitempty"`
-	ExtractImages      bool    `json:"extract_images,omitempty"`
-	ExtractTables      bool    `json:"extract_tables,omitempty"`
-	ExtractForms       bool    `json:"extract_forms,omitempty"`
-	ExtractAnnotations bool    `json:"extract_annotations,omitempty"`
-	IncludeCoordinates bool    `json:"include_coordinates,omitempty"`
-	IncludeFormatting  bool    `json:"include_formatting,omitempty"`
-	Pages              []int   `json:"pages,omitempty"`
-	MinConfidence      float64 `json:"min_confidence,omitempty"`
+	ExtractText        bool       `json:"extract_text,omitempty"`
+	ExtractImages      bool       `json:"extract_images,omitempty"`
+	ExtractTables      bool       `json:"extract_tables,omitempty"`
+	ExtractForms       bool       `json:"extract_forms,omitempty"`
+	ExtractAnnotations bool       `json:"extract_annotations,omitempty"`
+	IncludeCoordinates bool       `json:"include_coordinates,omitempty"`
+	IncludeFormatting  bool       `json:"include_formatting,omitempty"`
+	Pages              []int      `json:"pages,omitempty"`
+	FirstPages         int        `json:"first_pages,omitempty"` // Opening pages, extended to a section boundary
+	LastPages          int        `json:"last_pages,omitempty"`  // Closing pages, extended to a section boundary
+	MinConfidence      float64    `json:"min_confidence,omitempty"`
+	MaxWorkers         int        `json:"max_workers,omitempty"`  // Pages extracted concurrently; 0 uses the default
+	MaxElements        int        `json:"max_elements,omitempty"` // Element budget of preview mode
+	ResumeToken        string     `json:"resume_token,omitempty"` // Continues a partial, checkpointed extraction
+	PageSize           int        `json:"page_size,omitempty"`    // Elements and tables per response; 0 returns all
+	Cursor             string     `json:"cursor,omitempty"`       // Continues a paginated result from its next_cursor
+	Region             *Rectangle `json:"region,omitempty"`       // Part of each page to extract; nil is the whole page
+	Coordinates        string     `json:"coordinates,omitempty"`  // pdf, top-left, or normalized; empty is pdf
+
+	// TextNormalization joins the lines of structured and semantic text; nil applies the default
+	TextNormalization *TextNormalization `json:"text_normalization,omitempty"`
+}
+
+// TextNormalization selects how extracted text broken into lines is put back together
+type TextNormalization struct {
+	MergeLines         bool `json:"merge_lines"`          // Join the lines of a paragraph
+	Dehyphenate        bool `json:"dehyphenate"`          // Join words broken with a hyphen at the end of a line
+	PreserveHardBreaks bool `json:"preserve_hard_breaks"` // Keep the line breaks of code, verse, and addresses
 }
 
 // ContentQuery represents a query for filtering content
@@ -200,7 +372,10 @@ type ContentQuery struct {
 	Pages         []int      `json:"pages,omitempty"`
 	BoundingBox   *Rectangle `json:"bounding_box,omitempty"`
 	TextQuery     string     `json:"text_query,omitempty"`
+	Regex         bool       `json:"regex,omitempty"`          // TextQuery is a regular expression
+	CaseSensitive bool       `json:"case_sensitive,omitempty"` // Match TextQuery case exactly
 	MinConfidence float64    `json:"min_confidence,omitempty"`
+	Clause        string     `json:"clause,omitempty"` // Number of the clause to search, as "1.1.2" or "Article IV"
 }
 
 // Rectangle represents a rectangular area
@@ -215,23 +390,112 @@ type Rectangle struct {
 
 // PDFExtractResult represents the result of content extraction
 type PDFExtractResult struct {
-	FilePath       string            `json:"file_path"`
-	Mode           string            `json:"mode"`
-	TotalPages     int               `json:"total_pages"`
-	ProcessedPages []int             `json:"processed_pages"`
-	Elements       []ContentElement  `json:"elements"`
-	Tables         []TableElement    `json:"tables,omitempty"`
-	Summary        ExtractionSummary `json:"summary"`
-	Metadata       DocumentMetadata  `json:"metadata"`
-	Warnings       []string          `json:"warnings,omitempty"`
-	Errors         []string          `json:"errors,omitempty"`
+	FilePath       string             `json:"file_path"`
+	Mode           string             `json:"mode"`
+	TotalPages     int                `json:"total_pages"`
+	ProcessedPages []int              `json:"processed_pages"`
+	Elements       []ContentElement   `json:"elements"`
+	Tables         []TableElement     `json:"tables,omitempty"`
+	Structure      *StructureTree     `json:"structure,omitempty"` // Tagged documents, in semantic and complete modes
+	Entities       []NamedEntity      `json:"entities,omitempty"`  // In semantic mode
+	Summary        ExtractionSummary  `json:"summary"`
+	Metadata       DocumentMetadata   `json:"metadata"`
+	Warnings       []string           `json:"warnings,omitempty"`
+	Errors         []string           `json:"errors,omitempty"`
+	Issues         []ParseIssue       `json:"issues,omitempty"` // Structured form of Warnings and Errors
+	ReadStats      *ReadStats         `json:"read_stats,omitempty"`
+	Timing         *ExtractionTiming  `json:"timing,omitempty"`
+	Partial        bool               `json:"partial,omitempty"`        // Stopped by a timeout or cancellation before all pages
+	ResumeToken    string             `json:"resume_token,omitempty"`   // Pass back to continue a partial result
+	PageSelection  *PageSelection     `json:"page_selection,omitempty"` // Set for first_pages/last_pages requests
+	Preview        *PreviewSelection  `json:"preview,omitempty"`        // Set for preview mode
+	Escalation     *QualityEscalation `json:"escalation,omitempty"`     // Outcome of the escalation policy
+	Pagination     *ResultPage        `json:"pagination,omitempty"`     // Set when page_size is given
+	Export         *TableExport       `json:"export,omitempty"`         // Set when tables were exported
+	Decoding       []PageDecoding     `json:"decoding,omitempty"`       // How well each page's text mapped to Unicode
+}
+
+// TableExport lists the files extracted tables were written to
+type TableExport struct {
+	Format string   `json:"format"` // csv or xlsx
+	Tables int      `json:"tables"`
+	Files  []string `json:"files"` // One CSV file per table, or the workbook
+}
+
+// ResultPage locates one response of a paginated extraction within the whole result. Items
+// are the result's elements and tables in page order; the summary, processed pages, and
+// issues always describe the whole result.
+type ResultPage struct {
+	Offset     int    `json:"offset"`                // Index of the first item in this response
+	PageSize   int    `json:"page_size"`             // Items per response
+	TotalItems int    `json:"total_items"`           // Items in the whole result
+	NextCursor string `json:"next_cursor,omitempty"` // Pass back as cursor for the next response; empty on the last
+}
+
+// ReadStats describes how the document was read from storage and the adaptive read
+// parameters chosen for it
+type ReadStats struct {
+	StorageClass     string  `json:"storage_class"` // "local" or "remote", from observed latency
+	ChunkSize        int64   `json:"chunk_size"`
+	ReadAhead        int     `json:"read_ahead"`
+	PhysicalReads    int     `json:"physical_reads"`
+	CacheHits        int     `json:"cache_hits"`
+	BytesRead        int64   `json:"bytes_read"`
+	AvgReadLatencyMs float64 `json:"avg_read_latency_ms"`
+	ThroughputMBps   float64 `json:"throughput_mbps"`
+	MemoryMapped     bool    `json:"memory_mapped,omitempty"` // Read through a memory mapping instead
+}
+
+// ExtractionTiming breaks down where extraction time went so slow documents can be
+// narrowed down to the pages responsible. Phase times are summed over pages, so they can
+// exceed the total when pages are extracted concurrently.
+type ExtractionTiming struct {
+	TotalMs        float64      `json:"total_ms"`
+	ParseMs        float64      `json:"parse_ms"`         // Loading page objects and dimensions
+	FilterDecodeMs float64      `json:"filter_decode_ms"` // Decoding content streams
+	ExtractMs      float64      `json:"extract_ms"`       // Text, image, vector, form, and annotation stages
+	PostProcessMs  float64      `json:"post_process_ms"`  // Table detection and document-level grouping
+	SlowestPages   []PageTiming `json:"slowest_pages,omitempty"`
+}
+
+// PageTiming is the time spent on a single page, in milliseconds
+type PageTiming struct {
+	Page           int     `json:"page"`
+	TotalMs        float64 `json:"total_ms"`
+	ParseMs        float64 `json:"parse_ms"`
+	FilterDecodeMs float64 `json:"filter_decode_ms"`
+	ExtractMs      float64 `json:"extract_ms"`
+	PostProcessMs  float64 `json:"post_process_ms"`
+}
+
+// PageDecoding reports how well the character codes drawn on a page mapped to Unicode.
+// Codes without a mapping are extracted as U+FFFD.
+type PageDecoding struct {
+	Page       int      `json:"page"`
+	Glyphs     int      `json:"glyphs"`
+	Unmapped   int      `json:"unmapped,omitempty"`
+	Guessed    int      `json:"guessed,omitempty"` // Read without a known encoding
+	Confidence float64  `json:"confidence"`        // Share of codes mapped, guesses counting half
+	Fonts      []string `json:"fonts,omitempty"`   // Fonts with unmapped or guessed codes
+}
+
+// ParseIssue locates a problem encountered while reading a document
+type ParseIssue struct {
+	Severity string `json:"severity"` // "error" or "warning"
+	Stage    string `json:"stage"`    // Extraction stage, e.g. text, images, tables
+	Page     int    `json:"page,omitempty"`
+	Object   string `json:"object,omitempty"` // Indirect reference such as "12 0 R"
+	Offset   int64  `json:"offset,omitempty"` // Byte offset reported by the parser
+	Message  string `json:"message"`
 }
 
 // ContentElement represents a piece of extracted content
 type ContentElement struct {
 	ID          string                 `json:"id"`
 	Type        string                 `json:"type"`
+	Role        string                 `json:"role,omitempty"` // header, footer, or watermark for repeated structural text
 	PageNumber  int                    `json:"page_number"`
+	PageLabel   string                 `json:"page_label,omitempty"`
 	BoundingBox Rectangle              `json:"bounding_box"`
 	Content     interface{}            `json:"content"`
 	Properties  map[string]interface{} `json:"properties,omitempty"`
@@ -241,13 +505,54 @@ type ContentElement struct {
 	Confidence  float64                `json:"confidence,omitempty"`
 }
 
+// ListElement is the content of a list element: items in reading order, with nested items
+// following the item they belong to at a deeper level
+type ListElement struct {
+	Ordered bool       `json:"ordered"` // The outermost items are numbered or lettered
+	Items   []ListItem `json:"items"`
+}
+
+// ListItem is an item of a list
+type ListItem struct {
+	Text        string    `json:"text"`         // Without its marker
+	Marker      string    `json:"marker"`       // As printed, such as "•" or "2."
+	MarkerStyle string    `json:"marker_style"` // bullet, decimal, lower-alpha, upper-alpha, lower-roman, or upper-roman
+	Level       int       `json:"level"`        // Nesting depth, 0 for the outermost items
+	BoundingBox Rectangle `json:"bounding_box"`
+}
+
+// StructureElement is an element of a tagged document's logical structure, such as a
+// heading, paragraph, table cell, or figure
+type StructureElement struct {
+	Role       string             `json:"role"`           // Standard structure type, after role mapping
+	Type       string             `json:"type,omitempty"` // Type used by the document, when role mapped
+	Page       int                `json:"page,omitempty"`
+	Text       string             `json:"text,omitempty"`
+	AltText    string             `json:"alt_text,omitempty"`
+	ActualText string             `json:"actual_text,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Lang       string             `json:"lang,omitempty"`
+	Children   []StructureElement `json:"children,omitempty"`
+}
+
+// StructureTree is the logical structure of a tagged document, read from its structure tree
+type StructureTree struct {
+	Elements     []StructureElement `json:"elements"`
+	ElementCount int                `json:"element_count"`
+	Truncated    bool               `json:"truncated,omitempty"`
+}
+
 // TableElement represents extracted table data
 type TableElement struct {
-	Rows       []TableRow `json:"rows"`
-	Columns    []TableCol `json:"columns"`
-	CellCount  int        `json:"cell_count"`
-	HasHeaders bool       `json:"has_headers,omitempty"`
-	Confidence float64    `json:"confidence,omitempty"`
+	Rows        []TableRow `json:"rows"`
+	Columns     []TableCol `json:"columns"`
+	CellCount   int        `json:"cell_count"`
+	HasHeaders  bool       `json:"has_headers,omitempty"`
+	Confidence  float64    `json:"confidence,omitempty"`
+	PageNumber  int        `json:"page_number,omitempty"`
+	PageLabel   string     `json:"page_label,omitempty"`
+	BoundingBox Rectangle  `json:"bounding_box"`
+	Strategy    string     `json:"strategy,omitempty"` // text_alignment, ruling_lines, or both
 }
 
 // TableRow represents a table row
@@ -271,8 +576,11 @@ type TableCell struct {
 	RowIndex    int       `json:"row_index"`
 	ColIndex    int       `json:"col_index"`
 	Content     string    `json:"content"`
+	Value       string    `json:"value,omitempty"` // Content normalized for its data type
 	BoundingBox Rectangle `json:"bounding_box"`
-	DataType    string    `json:"data_type,omitempty"`
+	RowSpan     int       `json:"row_span,omitempty"`  // Rows a merged cell covers, from RowIndex down
+	ColSpan     int       `json:"col_span,omitempty"`  // Columns a merged cell covers, from ColIndex right
+	DataType    string    `json:"data_type,omitempty"` // text, number, currency, percent, or date
 	Confidence  float64   `json:"confidence,omitempty"`
 }
 
@@ -284,11 +592,13 @@ type ExtractionSummary struct {
 	HasStructure  bool           `json:"has_structure"`
 	Quality       string         `json:"quality"`
 	Suggestions   []string       `json:"suggestions,omitempty"`
+	Truncations   []string       `json:"truncations,omitempty"` // Server limits that cut the result short
 }
 
 // PageSummary provides summary for a single page
 type PageSummary struct {
 	Page     int            `json:"page"`
+	Label    string         `json:"label,omitempty"`
 	Elements int            `json:"elements"`
 	Types    map[string]int `json:"types"`
 }
@@ -317,6 +627,7 @@ type PDFQueryResult struct {
 	MatchCount int              `json:"match_count"`
 	Elements   []ContentElement `json:"elements"`
 	Summary    QuerySummary     `json:"summary"`
+	Clause     *Clause          `json:"clause,omitempty"` // Clause searched, without its subdivisions
 }
 
 // QuerySummary provides query result summary
@@ -329,11 +640,17 @@ type QuerySummary struct {
 // PageInfo represents information about a PDF page
 type PageInfo struct {
 	Number   int       `json:"number"`
-	Width    float64   `json:"width"`
-	Height   float64   `json:"height"`
+	Label    string    `json:"label,omitempty"`
+	Width    float64   `json:"width"`  // As displayed, after rotation
+	Height   float64   `json:"height"` // As displayed, after rotation
 	Rotation int       `json:"rotation"`
 	MediaBox Rectangle `json:"media_box"`
 	CropBox  Rectangle `json:"crop_box,omitempty"`
+	BleedBox Rectangle `json:"bleed_box,omitempty"`
+	TrimBox  Rectangle `json:"trim_box,omitempty"`
+	ArtBox   Rectangle `json:"art_box,omitempty"`
+	// Boxes the document sets for the page, such as "TrimBox"; the others take their defaults
+	ExplicitBoxes []string `json:"explicit_boxes,omitempty"`
 }
 
 // PDFPageInfoResult represents page information results
@@ -347,3 +664,1552 @@ type PDFMetadataResult struct {
 	FilePath string           `json:"file_path"`
 	Metadata DocumentMetadata `json:"metadata"`
 }
+
+// Template Matching Types
+
+// PDFFingerprintRequest represents a request to compute a layout fingerprint
+type PDFFingerprintRequest struct {
+	Path string `json:"path"`
+}
+
+// PDFMatchTemplateRequest represents a request to match a document against known templates
+type PDFMatchTemplateRequest struct {
+	Path      string   `json:"path"`
+	Templates []string `json:"templates"` // Template PDF files or directories containing them
+	MinScore  float64  `json:"min_score,omitempty"`
+}
+
+// LayoutAnchor is a landmark word with a position normalized to the page size,
+// measured from the top-left corner
+type LayoutAnchor struct {
+	Text string  `json:"text"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+// PageFingerprint describes the layout of a single page
+type PageFingerprint struct {
+	Page    int            `json:"page"`
+	Width   float64        `json:"width"`
+	Height  float64        `json:"height"`
+	Density []float64      `json:"density"` // Row-major text density grid, top row first
+	Anchors []LayoutAnchor `json:"anchors,omitempty"`
+}
+
+// DocumentFingerprint describes the layout of a document
+type DocumentFingerprint struct {
+	Path      string            `json:"path"`
+	PageCount int               `json:"page_count"`
+	GridSize  int               `json:"grid_size"`
+	Hash      string            `json:"hash"` // Hash of the quantized density grids
+	Pages     []PageFingerprint `json:"pages"`
+}
+
+// TemplateMatch describes how closely a document matches a template
+type TemplateMatch struct {
+	TemplatePath     string  `json:"template_path"`
+	TemplateName     string  `json:"template_name"`
+	Score            float64 `json:"score"`
+	LayoutSimilarity float64 `json:"layout_similarity"`
+	AnchorSimilarity float64 `json:"anchor_similarity"`
+	Matched          bool    `json:"matched"`
+}
+
+// PDFMatchTemplateResult represents the result of template matching
+type PDFMatchTemplateResult struct {
+	Path      string          `json:"path"`
+	BestMatch *TemplateMatch  `json:"best_match,omitempty"`
+	Matches   []TemplateMatch `json:"matches"`
+	MinScore  float64         `json:"min_score"`
+	Errors    []string        `json:"errors,omitempty"`
+}
+
+// Outline Types
+
+// PDFGetOutlineRequest represents a request for a document's outline (bookmarks)
+type PDFGetOutlineRequest struct {
+	Path string `json:"path"`
+}
+
+// OutlineItem represents a single bookmark and its nested children
+type OutlineItem struct {
+	Title           string        `json:"title"`
+	Level           int           `json:"level"`
+	Page            int           `json:"page,omitempty"`             // 1-based destination page
+	PageLabel       string        `json:"page_label,omitempty"`       // Label a viewer shows for it
+	DestinationType string        `json:"destination_type,omitempty"` // XYZ, Fit, FitH, FitV, FitR, ...
+	Left            float64       `json:"left,omitempty"`
+	Top             float64       `json:"top,omitempty"`
+	Zoom            float64       `json:"zoom,omitempty"` // 0 means keep the current zoom
+	URI             string        `json:"uri,omitempty"`
+	Open            bool          `json:"open,omitempty"`
+	Children        []OutlineItem `json:"children,omitempty"`
+}
+
+// PDFGetOutlineResult represents the outline of a document
+type PDFGetOutlineResult struct {
+	Path       string        `json:"path"`
+	HasOutline bool          `json:"has_outline"`
+	TotalItems int           `json:"total_items"`
+	MaxDepth   int           `json:"max_depth"`
+	Items      []OutlineItem `json:"items"`
+	Appendices []Appendix    `json:"appendices,omitempty"` // Appendices, exhibits, schedules, ...
+}
+
+// Appendix is an appendix, exhibit, schedule, annex, attachment, or addendum with the pages it spans
+type Appendix struct {
+	Kind      string `json:"kind"`  // appendix, exhibit, schedule, annex, attachment, addendum
+	Label     string `json:"label"` // "A", "3", "B-1", "IV"
+	Title     string `json:"title,omitempty"`
+	StartPage int    `json:"start_page"`
+	EndPage   int    `json:"end_page"`
+	Source    string `json:"source"` // "outline" or "heading"
+}
+
+// Render Types
+
+// PDFRenderPageRequest represents a request to rasterize a page
+type PDFRenderPageRequest struct {
+	Path      string `json:"path"`
+	Page      int    `json:"page,omitempty"`       // 1-based, default 1
+	DPI       int    `json:"dpi,omitempty"`        // Default 150
+	Format    string `json:"format,omitempty"`     // "png" (default) or "jpeg"
+	Quality   int    `json:"quality,omitempty"`    // JPEG quality 1-100, default 85
+	OutputDir string `json:"output_dir,omitempty"` // Save the image here instead of returning it
+}
+
+// PDFRenderPageResult represents a rendered page image
+type PDFRenderPageResult struct {
+	Path       string `json:"path"`
+	Page       int    `json:"page"`
+	PageLabel  string `json:"page_label,omitempty"`
+	DPI        int    `json:"dpi"`
+	Format     string `json:"format"`
+	MIMEType   string `json:"mime_type"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Size       int    `json:"size"`     // Encoded image size in bytes
+	Renderer   string `json:"renderer"` // External program that rasterized the page
+	OutputPath string `json:"output_path,omitempty"`
+	Data       string `json:"data,omitempty"` // Base64-encoded image when no output directory was given
+}
+
+// PDFGenerateThumbnailsRequest represents a request to make page thumbnails of every PDF in
+// a directory
+type PDFGenerateThumbnailsRequest struct {
+	Directory      string `json:"directory"`
+	Pages          []int  `json:"pages,omitempty"`           // Pages of each document (default: the first)
+	MaxSize        int    `json:"max_size,omitempty"`        // Longest side in pixels, 32-1024 (default: 256)
+	OutputDir      string `json:"output_dir,omitempty"`      // Cache directory; thumbnails go beside documents if empty
+	Overwrite      bool   `json:"overwrite,omitempty"`       // Render thumbnails even when a fresh one is saved
+	IncludeData    bool   `json:"include_data,omitempty"`    // Return the images base64-encoded as well
+	MaxConcurrency int    `json:"max_concurrency,omitempty"` // Documents rendered at once
+}
+
+// Thumbnail is the saved preview of one page of a document
+type Thumbnail struct {
+	Path       string `json:"path"` // The document
+	Page       int    `json:"page"`
+	PageLabel  string `json:"page_label,omitempty"`
+	OutputPath string `json:"output_path,omitempty"`
+	Width      int    `json:"width,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	Size       int    `json:"size,omitempty"`   // PNG size in bytes
+	Reused     bool   `json:"reused,omitempty"` // Saved earlier and newer than the document
+	Error      string `json:"error,omitempty"`
+	Data       string `json:"data,omitempty"` // Base64-encoded PNG when requested
+}
+
+// PDFGenerateThumbnailsResult represents the thumbnails made for a directory of PDFs
+type PDFGenerateThumbnailsResult struct {
+	Directory  string      `json:"directory"`
+	OutputDir  string      `json:"output_dir,omitempty"`
+	MaxSize    int         `json:"max_size"`
+	Renderer   string      `json:"renderer"` // External program that rasterized the pages
+	Files      int         `json:"files"`    // Documents found
+	Generated  int         `json:"generated"`
+	Reused     int         `json:"reused"`
+	Failed     int         `json:"failed"`
+	Thumbnails []Thumbnail `json:"thumbnails"`
+}
+
+// URL Fetch Types
+
+// PDFFetchURLRequest represents a request to download a PDF for the other tools to read
+type PDFFetchURLRequest struct {
+	URL string `json:"url"` // https URL of the PDF
+}
+
+// PDFFetchURLResult represents a PDF downloaded into the staging directory
+type PDFFetchURLResult struct {
+	URL         string `json:"url"`
+	FinalURL    string `json:"final_url"` // URL the document came from after redirects
+	Path        string `json:"path"`      // Local path to pass to the other tools
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type,omitempty"`
+	SHA256      string `json:"sha256"`
+}
+
+// Loaded Document Types
+
+// PDFLoadBytesRequest represents a request to load a PDF held in memory by the client
+type PDFLoadBytesRequest struct {
+	Content string `json:"content"`        // Base64-encoded PDF, optionally as a data: URL
+	Name    string `json:"name,omitempty"` // Name to remember the document by, such as its original file name
+}
+
+// LoadedDocument describes a PDF loaded from bytes
+type LoadedDocument struct {
+	Handle string `json:"handle"` // Pass as the path of any tool
+	Path   string `json:"path"`   // Temporary file holding the document
+	Name   string `json:"name,omitempty"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Portfolio Types
+
+// PDFPortfolioRequest represents a request for the members of a PDF portfolio
+type PDFPortfolioRequest struct {
+	Path        string   `json:"path"`
+	Members     []string `json:"members,omitempty"`      // Only these members, by key or file name; all when empty
+	IncludeText bool     `json:"include_text,omitempty"` // Read the text of each PDF member
+	MaxChars    int      `json:"max_chars,omitempty"`    // Text kept per member; 4000 when zero
+}
+
+// PDFPortfolioResult describes a portfolio and its members
+type PDFPortfolioResult struct {
+	Path            string            `json:"path"`
+	IsPortfolio     bool              `json:"is_portfolio"`               // The document has a /Collection
+	View            string            `json:"view,omitempty"`             // details, tile, hidden, or custom
+	InitialDocument string            `json:"initial_document,omitempty"` // Key of the member shown first
+	Fields          []PortfolioField  `json:"fields"`                     // Columns of the details view, in order
+	Members         []PortfolioMember `json:"members"`
+	TotalCount      int               `json:"total_count"`
+	PDFCount        int               `json:"pdf_count"`
+}
+
+// PortfolioField is a field of a portfolio's schema, a column of its details view
+type PortfolioField struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Type   string `json:"type"` // text, date, number, file_name, description, size, mod_date, ...
+	Hidden bool   `json:"hidden,omitempty"`
+}
+
+// PortfolioMember is a file of a portfolio
+type PortfolioMember struct {
+	Key         string            `json:"key"` // Name in the document's EmbeddedFiles name tree
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	MIMEType    string            `json:"mime_type"`
+	Size        int64             `json:"size"`
+	ModDate     string            `json:"mod_date,omitempty"`
+	IsPDF       bool              `json:"is_pdf"`
+	Reference   string            `json:"reference,omitempty"` // Path reading a PDF member with any tool
+	Fields      map[string]string `json:"fields,omitempty"`    // Values of the schema fields, by field key
+
+	// Read when include_text is set
+	Pages       int                 `json:"pages,omitempty"`
+	ContentType string              `json:"content_type,omitempty"`
+	Text        string              `json:"text,omitempty"`
+	Truncated   bool                `json:"truncated,omitempty"`
+	Portfolio   *PDFPortfolioResult `json:"portfolio,omitempty"` // Members of a nested portfolio
+
+	Error string `json:"error,omitempty"`
+}
+
+// OCR Types
+
+// PDFOCRRegionRequest represents a request to recognize the text of regions of a page
+type PDFOCRRegionRequest struct {
+	Path     string      `json:"path"`
+	Page     int         `json:"page,omitempty"`     // 1-based, default 1
+	Regions  []Rectangle `json:"regions,omitempty"`  // In points; the page's images when empty
+	Language string      `json:"language,omitempty"` // Tesseract language such as "eng" or "deu+fra", default "eng"
+	DPI      int         `json:"dpi,omitempty"`      // Render resolution, default 300
+}
+
+// PDFOCRRegionResult represents the text recognized in regions of a page
+type PDFOCRRegionResult struct {
+	Path      string      `json:"path"`
+	Page      int         `json:"page"`
+	PageLabel string      `json:"page_label,omitempty"`
+	DPI       int         `json:"dpi"`
+	Language  string      `json:"language"`
+	Engine    string      `json:"engine"`   // External program that recognized the text
+	Renderer  string      `json:"renderer"` // External program that rasterized the page
+	Regions   []OCRRegion `json:"regions"`
+}
+
+// OCRRegion is the text recognized in one region of a page
+type OCRRegion struct {
+	Source      string    `json:"source"` // "region" when requested, "image" for an image of the page
+	BoundingBox Rectangle `json:"bounding_box"`
+	Text        string    `json:"text"`
+	Confidence  float64   `json:"confidence"` // Mean word confidence, 0-1
+	Words       []OCRWord `json:"words,omitempty"`
+}
+
+// OCRWord is a recognized word with its box in page space
+type OCRWord struct {
+	Text        string    `json:"text"`
+	Confidence  float64   `json:"confidence"` // 0-1
+	BoundingBox Rectangle `json:"bounding_box"`
+}
+
+// Section Types
+
+// PDFExtractSectionRequest represents a request for the text of one section or appendix
+type PDFExtractSectionRequest struct {
+	Path    string `json:"path"`
+	Section string `json:"section"` // "Exhibit A", "Annex 3", "B", or an outline title
+}
+
+// PDFExtractSectionResult represents the text of a section and the pages it spans
+type PDFExtractSectionResult struct {
+	Path      string `json:"path"`
+	Section   string `json:"section"`
+	Kind      string `json:"kind"` // "section" for outline items, otherwise the appendix kind
+	Label     string `json:"label,omitempty"`
+	Title     string `json:"title,omitempty"`
+	StartPage int    `json:"start_page"`
+	EndPage   int    `json:"end_page"`
+	Content   string `json:"content"`
+	// Outcome of the configured escalation policy
+	Escalation *QualityEscalation `json:"escalation,omitempty"`
+}
+
+// Attachment Types
+
+// PDFExtractAttachmentsRequest represents a request to list or extract embedded files
+type PDFExtractAttachmentsRequest struct {
+	Path           string   `json:"path"`
+	Names          []string `json:"names,omitempty"`           // Only these attachments; empty means all
+	OutputDir      string   `json:"output_dir,omitempty"`      // Save payloads into this directory when set
+	IncludeContent bool     `json:"include_content,omitempty"` // Return payloads base64-encoded
+}
+
+// AttachmentInfo describes a single embedded file
+type AttachmentInfo struct {
+	Name             string `json:"name"`
+	Description      string `json:"description,omitempty"`
+	MIMEType         string `json:"mime_type"`
+	Size             int64  `json:"size"`
+	Source           string `json:"source"`         // embedded_files or annotation
+	Page             int    `json:"page,omitempty"` // Page of the file attachment annotation
+	PageLabel        string `json:"page_label,omitempty"`
+	CreationDate     string `json:"creation_date,omitempty"`
+	ModDate          string `json:"mod_date,omitempty"`
+	Checksum         string `json:"checksum,omitempty"` // MD5 of the decoded payload
+	ChecksumMismatch bool   `json:"checksum_mismatch,omitempty"`
+	SavedPath        string `json:"saved_path,omitempty"`
+	Content          string `json:"content,omitempty"` // Base64-encoded payload
+	Error            string `json:"error,omitempty"`
+}
+
+// PDFExtractAttachmentsResult represents the embedded files found in a document
+type PDFExtractAttachmentsResult struct {
+	Path        string           `json:"path"`
+	OutputDir   string           `json:"output_dir,omitempty"`
+	Attachments []AttachmentInfo `json:"attachments"`
+	TotalCount  int              `json:"total_count"`
+	TotalSize   int64            `json:"total_size"`
+}
+
+// Link Types
+
+// PDFExtractLinksRequest represents a request for the links in a document
+type PDFExtractLinksRequest struct {
+	Path  string `json:"path"`
+	Pages []int  `json:"pages,omitempty"` // Specific pages; empty means all
+}
+
+// LinkInfo describes a single link annotation and where it leads
+type LinkInfo struct {
+	Page             int       `json:"page"`
+	PageLabel        string    `json:"page_label,omitempty"`
+	BoundingBox      Rectangle `json:"bounding_box"`
+	Action           string    `json:"action"` // uri, goto, gotor, launch, named, other
+	URI              string    `json:"uri,omitempty"`
+	TargetPage       int       `json:"target_page,omitempty"`
+	TargetPageLabel  string    `json:"target_page_label,omitempty"`
+	TargetFile       string    `json:"target_file,omitempty"`
+	NamedDestination string    `json:"named_destination,omitempty"`
+	DestinationType  string    `json:"destination_type,omitempty"`
+	Zoom             float64   `json:"zoom,omitempty"`
+	Contents         string    `json:"contents,omitempty"`
+}
+
+// PDFExtractLinksResult represents the links found in a document
+type PDFExtractLinksResult struct {
+	Path          string     `json:"path"`
+	Links         []LinkInfo `json:"links"`
+	TotalCount    int        `json:"total_count"`
+	InternalCount int        `json:"internal_count"` // Links to pages of this document
+	ExternalCount int        `json:"external_count"` // Web links, other files, and viewer actions
+}
+
+// Find Text Types
+
+// PDFFindTextRequest represents a request for every occurrence of a phrase with its position
+type PDFFindTextRequest struct {
+	Path          string     `json:"path"`
+	Query         string     `json:"query"`
+	CaseSensitive bool       `json:"case_sensitive,omitempty"`
+	WholeWord     bool       `json:"whole_word,omitempty"`  // Only match the phrase at word boundaries
+	Pages         []int      `json:"pages,omitempty"`       // Specific pages; empty means all
+	MaxResults    int        `json:"max_results,omitempty"` // Occurrences returned; 0 uses the default
+	Region        *Rectangle `json:"region,omitempty"`      // Part of each page to search; nil is the whole page
+}
+
+// TextOccurrence is one occurrence of a phrase. Coordinates are in PDF points with the origin
+// at the bottom-left corner of the page.
+type TextOccurrence struct {
+	Page        int         `json:"page"`
+	PageLabel   string      `json:"page_label,omitempty"`
+	Text        string      `json:"text"` // The matched text as it appears on the page
+	BoundingBox Rectangle   `json:"bounding_box"`
+	Rects       []Rectangle `json:"rects"` // One rectangle per line the occurrence spans
+	Context     string      `json:"context"`
+}
+
+// PDFFindTextResult represents the occurrences of a phrase in a document
+type PDFFindTextResult struct {
+	Path          string           `json:"path"`
+	Query         string           `json:"query"`
+	CaseSensitive bool             `json:"case_sensitive"`
+	WholeWord     bool             `json:"whole_word"`
+	Matches       []TextOccurrence `json:"matches"`
+	TotalMatches  int              `json:"total_matches"` // Including occurrences beyond max_results
+	Pages         []int            `json:"pages"`         // Pages with at least one occurrence
+	FailedPages   []int            `json:"failed_pages,omitempty"`
+	Truncated     bool             `json:"truncated,omitempty"`
+}
+
+// Entity types of pdf_extract_entities
+const (
+	EntityDate          = "date"           // Normalized to YYYY-MM-DD
+	EntityAmount        = "amount"         // A currency amount, normalized to a plain decimal
+	EntityEmail         = "email"          // Normalized to lower case
+	EntityPhone         = "phone"          // Normalized to its digits, with a leading + when international
+	EntityAddress       = "address"        // A street address, with city, state, and ZIP code when given
+	EntityInvoiceNumber = "invoice_number" // The number following an invoice label
+	EntitySSN           = "ssn"            // A US Social Security number
+)
+
+// PDFExtractEntitiesRequest represents a request for the named entities in a document
+type PDFExtractEntitiesRequest struct {
+	Path          string     `json:"path"`
+	Types         []string   `json:"types,omitempty"` // Entity types to find; empty means all
+	Pages         []int      `json:"pages,omitempty"` // Specific pages; empty means all
+	MinConfidence float64    `json:"min_confidence,omitempty"`
+	Region        *Rectangle `json:"region,omitempty"` // Part of each page to search; nil is the whole page
+}
+
+// NamedEntity is a typed value found in a document's text, such as a date or an email
+// address. Coordinates are in PDF points with the origin at the bottom-left of the page.
+type NamedEntity struct {
+	Type        string    `json:"type"`
+	Text        string    `json:"text"`               // The matched text as it appears on the page
+	Value       string    `json:"value"`              // Normalized as described by the entity type
+	Currency    string    `json:"currency,omitempty"` // ISO code of an amount's currency
+	Page        int       `json:"page"`
+	PageLabel   string    `json:"page_label,omitempty"`
+	BoundingBox Rectangle `json:"bounding_box"`
+	Confidence  float64   `json:"confidence"`
+}
+
+// PDFExtractEntitiesResult represents the named entities found in a document
+type PDFExtractEntitiesResult struct {
+	Path        string         `json:"path"`
+	TotalPages  int            `json:"total_pages"`
+	Entities    []NamedEntity  `json:"entities"`
+	Counts      map[string]int `json:"counts"` // Entities by type
+	FailedPages []int          `json:"failed_pages,omitempty"`
+}
+
+// Document Classification Types
+
+// PDFClassifyDocumentRequest represents a request to rank the document types a PDF may be
+type PDFClassifyDocumentRequest struct {
+	Path string `json:"path"`
+	TopN int    `json:"top_n,omitempty"` // Candidates to return; 3 when zero
+}
+
+// ClassificationCandidate is a document type a PDF may be and how well its text fits
+type ClassificationCandidate struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Score       float64  `json:"score"`   // Share of the profile's keywords and patterns found, 0 to 1
+	Matched     []string `json:"matched"` // Keywords and patterns found in the text
+	Source      string   `json:"source"`  // builtin or custom
+}
+
+// PDFClassifyDocumentResult represents the candidate types of a document, best first
+type PDFClassifyDocumentResult struct {
+	Path        string                    `json:"path"`
+	TotalPages  int                       `json:"total_pages"`
+	PagesRead   int                       `json:"pages_read"` // Pages from the start whose text was scored
+	Profiles    int                       `json:"profiles"`   // Profiles the text was scored against
+	Candidates  []ClassificationCandidate `json:"candidates"`
+	FailedPages []int                     `json:"failed_pages,omitempty"`
+}
+
+// Invoice Extraction Types
+
+// PDFExtractInvoiceRequest represents a request to read the fields of an invoice or receipt
+type PDFExtractInvoiceRequest struct {
+	Path  string `json:"path"`
+	Force bool   `json:"force,omitempty"` // Read the fields even when the document is classified otherwise
+}
+
+// InvoiceLineItem is an item billed on an invoice. Numbers are normalized as table cell
+// values.
+type InvoiceLineItem struct {
+	Description string `json:"description"`
+	Quantity    string `json:"quantity,omitempty"`
+	UnitPrice   string `json:"unit_price,omitempty"`
+	Amount      string `json:"amount,omitempty"`
+	Page        int    `json:"page"`
+}
+
+// InvoiceFields are the fields of an invoice or receipt. Dates are YYYY-MM-DD and amounts
+// are normalized as table cell values.
+type InvoiceFields struct {
+	Vendor        string            `json:"vendor,omitempty"`
+	InvoiceNumber string            `json:"invoice_number,omitempty"`
+	InvoiceDate   string            `json:"invoice_date,omitempty"`
+	DueDate       string            `json:"due_date,omitempty"`
+	Currency      string            `json:"currency,omitempty"` // ISO code of the currency marking most amounts
+	LineItems     []InvoiceLineItem `json:"line_items"`
+	Subtotal      string            `json:"subtotal,omitempty"`
+	Tax           string            `json:"tax,omitempty"`
+	Total         string            `json:"total,omitempty"`
+	AmountDue     string            `json:"amount_due,omitempty"`
+}
+
+// PDFExtractInvoiceResult represents the classification of a document and, for invoices and
+// receipts, their fields
+type PDFExtractInvoiceResult struct {
+	Path         string         `json:"path"`
+	TotalPages   int            `json:"total_pages"`
+	DocumentType string         `json:"document_type,omitempty"` // Best matching type of pdf_classify_document
+	Score        float64        `json:"score"`
+	Detected     bool           `json:"detected"`          // Classified as an invoice or receipt
+	Invoice      *InvoiceFields `json:"invoice,omitempty"` // Fields, when detected or forced
+	Warnings     []string       `json:"warnings,omitempty"`
+	FailedPages  []int          `json:"failed_pages,omitempty"`
+}
+
+// Transaction Extraction Types
+
+// PDFExtractTransactionsRequest represents a request to read the transactions of a bank
+// statement
+type PDFExtractTransactionsRequest struct {
+	Path  string `json:"path"`
+	Force bool   `json:"force,omitempty"` // Read the transactions even when the document is classified otherwise
+}
+
+// StatementTransaction is a transaction on a bank statement. Amounts are normalized as
+// table cell values.
+type StatementTransaction struct {
+	Date        string `json:"date"` // YYYY-MM-DD
+	Description string `json:"description"`
+	Amount      string `json:"amount"`            // Credits positive, debits negative
+	Balance     string `json:"balance,omitempty"` // Balance after the transaction, when the statement shows it
+	Page        int    `json:"page"`
+}
+
+// BankStatement is the transactions of a statement and the balances around them
+type BankStatement struct {
+	Currency       string                 `json:"currency,omitempty"` // ISO code of the currency marking most amounts
+	OpeningBalance string                 `json:"opening_balance,omitempty"`
+	ClosingBalance string                 `json:"closing_balance,omitempty"`
+	Transactions   []StatementTransaction `json:"transactions"`
+	TotalCredits   string                 `json:"total_credits"`
+	TotalDebits    string                 `json:"total_debits"` // Sum of the debits, as a positive amount
+}
+
+// PDFExtractTransactionsResult represents the classification of a document and, for bank
+// statements, their transactions
+type PDFExtractTransactionsResult struct {
+	Path         string         `json:"path"`
+	TotalPages   int            `json:"total_pages"`
+	DocumentType string         `json:"document_type,omitempty"` // Best matching type of pdf_classify_document
+	Score        float64        `json:"score"`
+	Detected     bool           `json:"detected"`            // Classified as a bank statement
+	Statement    *BankStatement `json:"statement,omitempty"` // Transactions, when detected or forced
+	Warnings     []string       `json:"warnings,omitempty"`  // Balances that do not follow from the amounts
+	FailedPages  []int          `json:"failed_pages,omitempty"`
+}
+
+// Resume Extraction Types
+
+// PDFExtractResumeRequest represents a request to read the sections of a resume
+type PDFExtractResumeRequest struct {
+	Path  string `json:"path"`
+	Force bool   `json:"force,omitempty"` // Read the sections even when the document is classified otherwise
+}
+
+// ResumeContact is the contact details at the top of a resume
+type ResumeContact struct {
+	Name    string   `json:"name,omitempty"`
+	Emails  []string `json:"emails,omitempty"`
+	Phones  []string `json:"phones,omitempty"`
+	Address string   `json:"address,omitempty"`
+	Links   []string `json:"links,omitempty"` // Web, LinkedIn, and GitHub addresses
+}
+
+// ResumeExperience is a position listed under the experience of a resume. Dates are YYYY-MM,
+// or YYYY when the resume gives no month.
+type ResumeExperience struct {
+	Title      string   `json:"title,omitempty"`
+	Company    string   `json:"company,omitempty"`
+	Location   string   `json:"location,omitempty"`
+	StartDate  string   `json:"start_date,omitempty"`
+	EndDate    string   `json:"end_date,omitempty"` // "present" for a current position
+	Current    bool     `json:"current"`
+	Highlights []string `json:"highlights,omitempty"`
+	Page       int      `json:"page"`
+}
+
+// ResumeEducation is a school listed under the education of a resume
+type ResumeEducation struct {
+	Institution string   `json:"institution,omitempty"`
+	Degree      string   `json:"degree,omitempty"`
+	Field       string   `json:"field,omitempty"`
+	Location    string   `json:"location,omitempty"`
+	StartDate   string   `json:"start_date,omitempty"`
+	EndDate     string   `json:"end_date,omitempty"` // Graduation, when the resume gives one date
+	Details     []string `json:"details,omitempty"`
+	Page        int      `json:"page"`
+}
+
+// ResumeSection is a section of a resume outside the standard ones, such as certifications
+// or languages
+type ResumeSection struct {
+	Heading string   `json:"heading"`
+	Lines   []string `json:"lines"`
+}
+
+// Resume is the standardized content of a resume
+type Resume struct {
+	Contact    ResumeContact      `json:"contact"`
+	Summary    string             `json:"summary,omitempty"`
+	Experience []ResumeExperience `json:"experience"`
+	Education  []ResumeEducation  `json:"education"`
+	Skills     []string           `json:"skills"`
+	Sections   []ResumeSection    `json:"sections,omitempty"`
+}
+
+// PDFExtractResumeResult represents the classification of a document and, for resumes,
+// their sections
+type PDFExtractResumeResult struct {
+	Path         string  `json:"path"`
+	TotalPages   int     `json:"total_pages"`
+	DocumentType string  `json:"document_type,omitempty"` // Best matching type of pdf_classify_document
+	Score        float64 `json:"score"`
+	Detected     bool    `json:"detected"`         // Classified as a resume
+	Resume       *Resume `json:"resume,omitempty"` // Sections, when detected or forced
+	FailedPages  []int   `json:"failed_pages,omitempty"`
+}
+
+// Clause Types
+
+// PDFGetClausesRequest represents a request for the clause tree of a legal document
+type PDFGetClausesRequest struct {
+	Path        string `json:"path"`
+	IncludeText bool   `json:"include_text,omitempty"` // Include the text of each clause
+}
+
+// Clause is a numbered clause of a legal document with its subdivisions. The anchor is
+// derived from the number alone, as "article-4", "section-4.1", "s12-a", or "1.1.2-a", so
+// it stays the same across versions of a document that keep the numbering.
+type Clause struct {
+	Number   string   `json:"number"` // As printed, as "Article IV", "1.1.2", or "(a)"
+	Anchor   string   `json:"anchor"`
+	Kind     string   `json:"kind"` // article, section, paragraph, decimal, letter, roman, or number
+	Title    string   `json:"title,omitempty"`
+	Text     string   `json:"text,omitempty"` // Text of the clause before its first subdivision
+	Level    int      `json:"level"`
+	Page     int      `json:"page"`
+	EndPage  int      `json:"end_page"`
+	Top      float64  `json:"top"`    // Top of the clause number on its first page
+	Bottom   float64  `json:"bottom"` // Bottom of the last line of the clause on its last page
+	Children []Clause `json:"children,omitempty"`
+}
+
+// PDFGetClausesResult represents the clause tree of a document
+type PDFGetClausesResult struct {
+	Path        string   `json:"path"`
+	TotalPages  int      `json:"total_pages"`
+	ClauseCount int      `json:"clause_count"`
+	Clauses     []Clause `json:"clauses"`
+	FailedPages []int    `json:"failed_pages,omitempty"`
+}
+
+// Query Set Types
+
+// PDFQuerySetRequest represents a query run jointly across a set of documents
+type PDFQuerySetRequest struct {
+	Paths      []string     `json:"paths"`
+	Query      ContentQuery `json:"query"`
+	MaxResults int          `json:"max_results,omitempty"` // Ranked matches to return; 0 uses the default
+}
+
+// QuerySetFileResult summarizes the matches in one document of a query set
+type QuerySetFileResult struct {
+	Path       string       `json:"path"`
+	MatchCount int          `json:"match_count"`
+	TopScore   float64      `json:"top_score"`
+	Summary    QuerySummary `json:"summary"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// QuerySetMatch is a single match in the global ranking across all documents
+type QuerySetMatch struct {
+	Rank    int            `json:"rank"`
+	Path    string         `json:"path"`
+	Score   float64        `json:"score"`
+	Element ContentElement `json:"element"`
+}
+
+// PDFQuerySetResult represents the merged results of a query set
+type PDFQuerySetResult struct {
+	Query        ContentQuery         `json:"query"`
+	Files        []QuerySetFileResult `json:"files"`   // Per-document results in request order
+	Matches      []QuerySetMatch      `json:"matches"` // Best matches across all documents, by score
+	TotalMatches int                  `json:"total_matches"`
+	FilesMatched int                  `json:"files_matched"`
+	FilesFailed  int                  `json:"files_failed"`
+}
+
+// Batch Types
+
+// PDFBatchExtractRequest represents a request to extract a directory or list of documents
+type PDFBatchExtractRequest struct {
+	Directory      string           `json:"directory,omitempty"` // Every PDF under it, unless Paths is given
+	Paths          []string         `json:"paths,omitempty"`
+	Mode           string           `json:"mode,omitempty"` // structured, semantic, table, or complete
+	Config         ExtractionConfig `json:"config,omitempty"`
+	OutputDir      string           `json:"output_dir"`                // Where per-file results and the manifest are written
+	MaxConcurrency int              `json:"max_concurrency,omitempty"` // Documents extracted at once; 0 uses the default
+}
+
+// BatchFileResult records the extraction of one document of a batch
+type BatchFileResult struct {
+	Path           string  `json:"path"`
+	Status         string  `json:"status"`                // succeeded, partial, or failed
+	OutputPath     string  `json:"output_path,omitempty"` // The JSON result, unless the document failed
+	Error          string  `json:"error,omitempty"`
+	TotalPages     int     `json:"total_pages,omitempty"`
+	ProcessedPages int     `json:"processed_pages,omitempty"`
+	Elements       int     `json:"elements,omitempty"`
+	Tables         int     `json:"tables,omitempty"`
+	Warnings       int     `json:"warnings,omitempty"`
+	DurationMs     float64 `json:"duration_ms"`
+}
+
+// PDFBatchExtractResult is the manifest of a batch extraction, also written to ManifestPath
+type PDFBatchExtractResult struct {
+	Mode         string            `json:"mode"`
+	Directory    string            `json:"directory,omitempty"`
+	OutputDir    string            `json:"output_dir"`
+	ManifestPath string            `json:"manifest_path"`
+	Files        []BatchFileResult `json:"files"` // In request or directory order
+	TotalFiles   int               `json:"total_files"`
+	Succeeded    int               `json:"succeeded"`
+	Partial      int               `json:"partial"`
+	Failed       int               `json:"failed"`
+	DurationMs   float64           `json:"duration_ms"`
+}
+
+// Extraction Job Types
+
+// PDFExtractStartRequest represents a request to extract a document in the background
+type PDFExtractStartRequest struct {
+	Path    string           `json:"path"`
+	Mode    string           `json:"mode,omitempty"` // structured, semantic, table, or complete
+	Config  ExtractionConfig `json:"config,omitempty"`
+	Timeout float64          `json:"timeout,omitempty"` // Seconds before the job stops with the pages extracted so far
+}
+
+// PDFExtractStatusRequest represents a request for the state of an extraction job
+type PDFExtractStatusRequest struct {
+	JobID string `json:"job_id"`
+}
+
+// PDFExtractJobResultRequest represents a request for the result of a finished extraction job
+type PDFExtractJobResultRequest struct {
+	JobID    string `json:"job_id"`
+	PageSize int    `json:"page_size,omitempty"` // Elements and tables per response; 0 returns all
+	Cursor   string `json:"cursor,omitempty"`    // Continues a paginated result from its next_cursor
+}
+
+// ExtractionJob describes a background extraction and how far it has come
+type ExtractionJob struct {
+	JobID       string  `json:"job_id"`
+	Path        string  `json:"path"`
+	Mode        string  `json:"mode"`
+	Status      string  `json:"status"`                 // running, succeeded, partial, or failed
+	PagesDone   int     `json:"pages_done"`             // Pages extracted so far
+	PagesTotal  int     `json:"pages_total,omitempty"`  // Pages to extract, once known
+	Message     string  `json:"message,omitempty"`      // The latest progress report
+	Error       string  `json:"error,omitempty"`        // Why a failed job failed
+	ResumeToken string  `json:"resume_token,omitempty"` // Pass to pdf_extract_start to continue a partial job
+	StartedAt   string  `json:"started_at"`             // RFC 3339
+	FinishedAt  string  `json:"finished_at,omitempty"`  // RFC 3339, once the job stops
+	ElapsedMs   float64 `json:"elapsed_ms"`
+}
+
+// Comparison Types
+
+// PDFCompareSetRequest represents a request to compare a set of documents pairwise
+type PDFCompareSetRequest struct {
+	Paths     []string `json:"paths"`
+	Threshold float64  `json:"threshold,omitempty"` // Similarity that joins documents into a cluster
+}
+
+// ComparedDocument summarizes how one document relates to the rest of the set
+type ComparedDocument struct {
+	Path              string  `json:"path"`
+	Pages             int     `json:"pages"`
+	Words             int     `json:"words"`
+	AverageSimilarity float64 `json:"average_similarity"`
+	ClosestPath       string  `json:"closest_path,omitempty"`
+	ClosestSimilarity float64 `json:"closest_similarity"`
+	Cluster           int     `json:"cluster,omitempty"` // Cluster ID; 0 when the document is not clustered
+	Outlier           bool    `json:"outlier"`
+	Error             string  `json:"error,omitempty"`
+}
+
+// DocumentPairSimilarity describes the similarity of two documents
+type DocumentPairSimilarity struct {
+	PathA                string  `json:"path_a"`
+	PathB                string  `json:"path_b"`
+	TextSimilarity       float64 `json:"text_similarity"`
+	StructuralSimilarity float64 `json:"structural_similarity"`
+	Similarity           float64 `json:"similarity"` // Weighted combination of text and structure
+}
+
+// DocumentCluster is a group of documents linked by similarities at or above the threshold
+type DocumentCluster struct {
+	ID            int      `json:"id"`
+	Paths         []string `json:"paths"`
+	MinSimilarity float64  `json:"min_similarity"` // Lowest pairwise similarity within the cluster
+}
+
+// PDFCompareSetResult represents the pairwise comparison of a document set
+type PDFCompareSetResult struct {
+	Documents []ComparedDocument       `json:"documents"` // In request order
+	Matrix    [][]float64              `json:"matrix"`    // Combined similarity, indexed like Documents
+	Pairs     []DocumentPairSimilarity `json:"pairs"`     // Most similar first
+	Clusters  []DocumentCluster        `json:"clusters"`
+	Outliers  []string                 `json:"outliers"`
+	Threshold float64                  `json:"threshold"`
+}
+
+// Page Window Types
+
+// PageSelection describes the pages chosen for first_pages/last_pages requests
+type PageSelection struct {
+	TotalPages int   `json:"total_pages"`
+	FirstPages int   `json:"first_pages,omitempty"`
+	LastPages  int   `json:"last_pages,omitempty"`
+	Pages      []int `json:"pages"`
+	Extended   []int `json:"extended,omitempty"` // Pages added to avoid cutting a section
+	// Labels a viewer shows for the selected pages, when the document labels its pages
+	Labels map[int]string `json:"labels,omitempty"`
+}
+
+// PreviewPage is a page sampled for a preview extraction
+type PreviewPage struct {
+	Page      int      `json:"page"`
+	PageLabel string   `json:"page_label,omitempty"`
+	Reasons   []string `json:"reasons"` // first, last, section, table, form
+}
+
+// PreviewSelection describes the pages and element budget of a preview extraction
+type PreviewSelection struct {
+	TotalPages      int           `json:"total_pages"`
+	Pages           []PreviewPage `json:"pages"`
+	ElementBudget   int           `json:"element_budget"`
+	ElementsOmitted int           `json:"elements_omitted,omitempty"` // Elements and tables dropped to fit the budget
+}
+
+// Document Export Types
+
+// PDFExportDocumentRequest represents a request to convert a PDF into an editable document
+type PDFExportDocumentRequest struct {
+	Path      string `json:"path"`
+	Format    string `json:"format,omitempty"`     // "docx" (default) or "odt"
+	OutputDir string `json:"output_dir,omitempty"` // Save the document here instead of returning it
+
+	StructureConfig *StructureConfig `json:"structure_config,omitempty"` // Default preset when nil
+}
+
+// PDFExportDocumentResult represents an exported document and the structure found for it
+type PDFExportDocumentResult struct {
+	Path       string `json:"path"`
+	Format     string `json:"format"`
+	MIMEType   string `json:"mime_type"`
+	Pages      int    `json:"pages"`
+	Headings   int    `json:"headings"`
+	Paragraphs int    `json:"paragraphs"`
+	ListItems  int    `json:"list_items"`
+	Tables     int    `json:"tables"`
+	Images     int    `json:"images"` // Exported as placeholder paragraphs
+	Size       int    `json:"size"`   // Document size in bytes
+	OutputPath string `json:"output_path,omitempty"`
+	Data       string `json:"data,omitempty"` // Base64-encoded document when no output directory was given
+
+	Structure StructureConfig `json:"structure_config"` // Heuristics the structure was detected with
+}
+
+// PDFExportBookRequest represents a request to convert a long-form PDF into an EPUB or HTML bundle
+type PDFExportBookRequest struct {
+	Path      string `json:"path"`
+	Format    string `json:"format,omitempty"`     // "epub" (default) or "html"
+	OutputDir string `json:"output_dir,omitempty"` // Save the book here instead of returning it
+}
+
+// BookChapter is one file of an exported book. Pages are anchored as File#page-<n>.
+type BookChapter struct {
+	Title     string `json:"title,omitempty"` // Empty for the single chapter of a document without an outline
+	File      string `json:"file"`
+	StartPage int    `json:"start_page"`
+	EndPage   int    `json:"end_page"` // Before StartPage when the chapter holds only its title
+}
+
+// PDFExportBookResult represents an exported book
+type PDFExportBookResult struct {
+	Path              string        `json:"path"`
+	Format            string        `json:"format"`
+	MIMEType          string        `json:"mime_type"`
+	Title             string        `json:"title"`
+	Pages             int           `json:"pages"`
+	Chapters          []BookChapter `json:"chapters"`
+	Images            int           `json:"images"`             // Embedded as PNG
+	ImagePlaceholders int           `json:"image_placeholders"` // Images the parser cannot decode
+	Size              int           `json:"size"`               // Archive size, or total size of the saved HTML files
+	OutputPath        string        `json:"output_path,omitempty"`
+	Data              string        `json:"data,omitempty"` // Base64-encoded archive when no output directory was given
+}
+
+// PDFExportTextRequest represents a request to export a PDF's cleaned text
+type PDFExportTextRequest struct {
+	Path                string `json:"path"`
+	Format              string `json:"format,omitempty"` // "text" (default), "markdown", or "jsonl"
+	Pages               []int  `json:"pages,omitempty"`  // All pages when empty
+	StripHeadersFooters bool   `json:"strip_headers_footers,omitempty"`
+	Dehyphenate         bool   `json:"dehyphenate,omitempty"` // Join words broken across lines
+	OutputDir           string `json:"output_dir,omitempty"`  // Save the text here instead of returning it
+
+	StructureConfig *StructureConfig `json:"structure_config,omitempty"` // Default preset when nil
+}
+
+// TextExportElement is one line of a JSON Lines text export
+type TextExportElement struct {
+	Page        int        `json:"page"`
+	PageLabel   string     `json:"page_label,omitempty"`
+	Type        string     `json:"type"` // heading, paragraph, list_item, or table
+	Level       int        `json:"level,omitempty"`
+	Text        string     `json:"text"`           // Table rows are tab-separated lines
+	Rows        [][]string `json:"rows,omitempty"` // Table cells
+	BoundingBox Rectangle  `json:"bounding_box"`
+}
+
+// PDFExportTextResult represents a PDF's exported text
+type PDFExportTextResult struct {
+	Path           string   `json:"path"`
+	Format         string   `json:"format"`
+	MIMEType       string   `json:"mime_type"`
+	Pages          int      `json:"pages"`
+	Elements       int      `json:"elements"`                  // Headings, paragraphs, list items, and tables
+	HeadersFooters []string `json:"headers_footers,omitempty"` // Running lines removed, as first found
+	LinesRemoved   int      `json:"lines_removed,omitempty"`
+	Dehyphenated   int      `json:"dehyphenated,omitempty"` // Words joined across lines
+	Size           int      `json:"size"`                   // Text size in bytes
+	OutputPath     string   `json:"output_path,omitempty"`
+	Content        string   `json:"content,omitempty"` // The text when no output directory was given
+	FailedPages    []int    `json:"failed_pages,omitempty"`
+}
+
+// PDFExtractReadingOrderRequest represents a request for a PDF's logical blocks in reading order
+type PDFExtractReadingOrderRequest struct {
+	Path                string `json:"path"`
+	Pages               []int  `json:"pages,omitempty"` // All pages when empty
+	StripHeadersFooters bool   `json:"strip_headers_footers,omitempty"`
+
+	StructureConfig *StructureConfig `json:"structure_config,omitempty"` // Default preset when nil
+}
+
+// ReadingOrderBlock is a heading, paragraph, list item, or table in reading order
+type ReadingOrderBlock struct {
+	Order       int        `json:"order"` // Position in reading order across the document, from 1
+	Page        int        `json:"page"`
+	PageLabel   string     `json:"page_label,omitempty"`
+	Type        string     `json:"type"` // heading, paragraph, list_item, or table
+	Level       int        `json:"level,omitempty"`
+	Text        string     `json:"text"`           // Table rows are tab-separated lines
+	Rows        [][]string `json:"rows,omitempty"` // Table cells
+	BoundingBox Rectangle  `json:"bounding_box"`   // In page space, before the page's rotation
+}
+
+// PDFExtractReadingOrderResult represents a PDF's logical blocks in reading order
+type PDFExtractReadingOrderResult struct {
+	Path         string              `json:"path"`
+	TotalPages   int                 `json:"total_pages"`
+	Pages        int                 `json:"pages"` // Pages read
+	Blocks       []ReadingOrderBlock `json:"blocks"`
+	LinesRemoved int                 `json:"lines_removed,omitempty"` // Running headers and footers
+	Structure    StructureConfig     `json:"structure_config"`        // Heuristics the blocks were detected with
+	FailedPages  []int               `json:"failed_pages,omitempty"`
+}
+
+// PDFChunkContentRequest represents a request to split a PDF's text into chunks for retrieval
+type PDFChunkContentRequest struct {
+	Path          string `json:"path"`
+	Pages         []int  `json:"pages,omitempty"`          // All pages when empty
+	MaxTokens     int    `json:"max_tokens,omitempty"`     // 512 when neither limit is set
+	MaxCharacters int    `json:"max_characters,omitempty"` // Limit chunks by characters instead of tokens
+	Overlap       int    `json:"overlap,omitempty"`        // Text repeated from the previous chunk, in the limit's unit
+
+	StructureConfig *StructureConfig `json:"structure_config,omitempty"` // Default preset when nil
+}
+
+// ContentChunk is a run of text from one section of a document
+type ContentChunk struct {
+	Order       int      `json:"order"` // Position in the document, from 1
+	Text        string   `json:"text"`  // Markdown, with the section's heading opening its first chunk
+	StartPage   int      `json:"start_page"`
+	EndPage     int      `json:"end_page"`
+	Section     string   `json:"section,omitempty"`      // Nearest heading above the chunk
+	SectionPath []string `json:"section_path,omitempty"` // Headings from the top level down to Section
+	Characters  int      `json:"characters"`
+	Tokens      int      `json:"tokens"`            // Estimated at four characters per token
+	Overlap     int      `json:"overlap,omitempty"` // Leading characters repeated from the previous chunk
+}
+
+// PDFChunkContentResult represents a document split into chunks
+type PDFChunkContentResult struct {
+	Path       string         `json:"path"`
+	TotalPages int            `json:"total_pages"`
+	Unit       string         `json:"unit"` // tokens or characters
+	Limit      int            `json:"limit"`
+	Overlap    int            `json:"overlap"`
+	Chunks     []ContentChunk `json:"chunks"`
+
+	Structure StructureConfig `json:"structure_config"` // Heuristics the sections were detected with
+}
+
+// RedactionRegion is an area of a page to redact, in PDF points from the lower-left corner
+type RedactionRegion struct {
+	Page   int     `json:"page"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// PDFRedactRequest represents a request to remove content from a PDF
+type PDFRedactRequest struct {
+	Path          string            `json:"path"`
+	Regions       []RedactionRegion `json:"regions,omitempty"`
+	Terms         []string          `json:"terms,omitempty"` // Phrases to redact wherever they occur
+	CaseSensitive bool              `json:"case_sensitive,omitempty"`
+	WholeWord     bool              `json:"whole_word,omitempty"`
+	OutputDir     string            `json:"output_dir,omitempty"` // Save the document here instead of returning it
+}
+
+// PDFRedactResult represents a redacted document
+type PDFRedactResult struct {
+	Path          string            `json:"path"`
+	MIMEType      string            `json:"mime_type"`
+	Pages         []int             `json:"pages"`   // Pages with content redacted
+	Regions       []RedactionRegion `json:"regions"` // Every area redacted: regions, term matches, and annotations
+	TermMatches   int               `json:"term_matches"`
+	Annotations   int               `json:"annotations"` // Existing redaction annotations applied
+	GlyphsRemoved int               `json:"glyphs_removed"`
+	ImagesRemoved int               `json:"images_removed"`
+	Size          int               `json:"size"` // Document size in bytes
+	OutputPath    string            `json:"output_path,omitempty"`
+	Data          string            `json:"data,omitempty"` // Base64-encoded document when no output directory was given
+}
+
+// PDFAccessibilityReportRequest represents a request to audit a PDF's accessibility
+type PDFAccessibilityReportRequest struct {
+	Path          string `json:"path"`
+	Pages         []int  `json:"pages,omitempty"`          // Pages to audit (default: all pages)
+	CheckContrast bool   `json:"check_contrast,omitempty"` // Render pages to sample text contrast
+}
+
+// AccessibilityIssue is a problem keeping a document from being read with assistive
+// technology, with the WCAG success criterion or PDF/UA clause it falls under
+type AccessibilityIssue struct {
+	Rule     string `json:"rule"`     // One of the AccessRule constants
+	Severity string `json:"severity"` // error or warning
+	Page     int    `json:"page,omitempty"`
+	Message  string `json:"message"`
+	Standard string `json:"standard"`
+}
+
+// PDFAccessibilityReportResult represents a WCAG and PDF/UA oriented accessibility audit
+type PDFAccessibilityReportResult struct {
+	Path              string               `json:"path"`
+	TotalPages        int                  `json:"total_pages"`
+	Pages             int                  `json:"pages"` // Pages audited
+	Tagged            bool                 `json:"tagged"`
+	Language          string               `json:"language,omitempty"` // Document language from the catalog
+	Title             string               `json:"title,omitempty"`
+	Figures           int                  `json:"figures"` // Figures in the structure tree
+	FiguresWithoutAlt int                  `json:"figures_without_alt"`
+	UntaggedPages     []int                `json:"untagged_pages,omitempty"` // Pages drawing content outside tags
+	FormFields        int                  `json:"form_fields"`
+	UnlabeledFields   int                  `json:"unlabeled_fields"`
+	ContrastChecked   bool                 `json:"contrast_checked"`
+	ContrastSamples   int                  `json:"contrast_samples,omitempty"` // Lines sampled
+	Renderer          string               `json:"renderer,omitempty"`
+	Errors            int                  `json:"errors"`
+	Warnings          int                  `json:"warnings"`
+	Issues            []AccessibilityIssue `json:"issues"`
+	Notes             []string             `json:"notes,omitempty"` // Checks that could not be made
+	FailedPages       []int                `json:"failed_pages,omitempty"`
+}
+
+// PDFOptimizeReportRequest represents a request to report how a PDF could be made smaller
+type PDFOptimizeReportRequest struct {
+	Path string `json:"path"`
+}
+
+// DuplicateImageGroup is an image stored more than once with identical samples and dictionary
+type DuplicateImageGroup struct {
+	Objects []int `json:"objects"` // Numbers of the image objects, the first being kept
+	Width   int   `json:"width"`
+	Height  int   `json:"height"`
+	Size    int   `json:"size"` // Stored size of each copy in bytes
+}
+
+// PDFOptimizeReportResult represents how a PDF is stored and what optimizing it would save
+type PDFOptimizeReportResult struct {
+	Path                 string                `json:"path"`
+	FileSize             int64                 `json:"file_size"`
+	Version              string                `json:"version,omitempty"`             // From the file header
+	Linearized           bool                  `json:"linearized"`                    // Prepared for fast web view
+	LinearizationStale   bool                  `json:"linearization_stale,omitempty"` // Changed since linearized
+	Objects              int                   `json:"objects"`                       // In the cross-reference table
+	ObjectStreams        int                   `json:"object_streams"`
+	ObjectsInStreams     int                   `json:"objects_in_streams"`
+	XRefStream           bool                  `json:"xref_stream"`    // Cross-reference table stored as a stream
+	UnusedObjects        int                   `json:"unused_objects"` // Objects nothing in the document refers to
+	Images               int                   `json:"images"`
+	DuplicateImages      []DuplicateImageGroup `json:"duplicate_images"`
+	DuplicateImageBytes  int64                 `json:"duplicate_image_bytes"` // Bytes taken by the extra copies
+	UncompressedStreams  int                   `json:"uncompressed_streams"`  // Streams compression would shrink
+	HighResolutionImages int                   `json:"high_resolution_images"`
+	EstimatedSize        int64                 `json:"estimated_size"` // What pdf_optimize writes without downsampling
+	EstimatedSavings     int64                 `json:"estimated_savings"`
+	SavingsPercent       float64               `json:"savings_percent"`
+	Recommendations      []string              `json:"recommendations"`
+}
+
+// PDFOptimizeRequest represents a request to write a smaller copy of a PDF
+type PDFOptimizeRequest struct {
+	Path        string  `json:"path"`
+	MaxImageDPI float64 `json:"max_image_dpi,omitempty"` // Downsample images drawn above this resolution
+	OutputDir   string  `json:"output_dir,omitempty"`    // Save the document here instead of returning it
+}
+
+// PDFOptimizeResult represents an optimized document
+type PDFOptimizeResult struct {
+	Path                  string  `json:"path"`
+	MIMEType              string  `json:"mime_type"`
+	OriginalSize          int64   `json:"original_size"`
+	Size                  int     `json:"size"`    // Document size in bytes
+	Savings               int64   `json:"savings"` // Negative when the copy is larger
+	SavingsPercent        float64 `json:"savings_percent"`
+	DuplicateImagesMerged int     `json:"duplicate_images_merged"`
+	StreamsCompressed     int     `json:"streams_compressed"`
+	ImagesDownsampled     int     `json:"images_downsampled"`
+	OutputPath            string  `json:"output_path,omitempty"`
+	Data                  string  `json:"data,omitempty"` // Base64-encoded document when no output directory was given
+}
+
+// PDFSanitizeRequest represents a request to write a copy of a PDF without sensitive
+// metadata and active or hidden content. Everything is removed unless kept.
+type PDFSanitizeRequest struct {
+	Path             string `json:"path"`
+	Author           string `json:"author,omitempty"`             // Recorded in place of the original author
+	KeepMetadata     bool   `json:"keep_metadata,omitempty"`      // Keep the Info dictionary and XMP metadata
+	KeepJavaScript   bool   `json:"keep_javascript,omitempty"`    // Keep JavaScript and launch actions
+	KeepAttachments  bool   `json:"keep_attachments,omitempty"`   // Keep embedded files
+	KeepHiddenLayers bool   `json:"keep_hidden_layers,omitempty"` // Keep content in layers hidden by default
+	OutputDir        string `json:"output_dir,omitempty"`         // Save the document here instead of returning it
+}
+
+// PDFSanitizeResult represents a sanitized copy of a PDF and what was removed from it
+type PDFSanitizeResult struct {
+	Path              string   `json:"path"`
+	MIMEType          string   `json:"mime_type"`
+	Size              int      `json:"size"`                   // Document size in bytes
+	InfoEntries       []string `json:"info_entries,omitempty"` // Info dictionary entries removed or replaced
+	MetadataStreams   int      `json:"metadata_streams"`       // XMP metadata, including its edit history
+	PrivateData       int      `json:"private_data"`           // Application data kept in PieceInfo dictionaries
+	JavaScriptActions int      `json:"javascript_actions"`     // JavaScript and launch actions, including document scripts
+	EmbeddedFiles     int      `json:"embedded_files"`         // Embedded files and file attachment annotations
+	HiddenLayers      []string `json:"hidden_layers,omitempty"`
+	HiddenContent     int      `json:"hidden_content"` // Content sequences, XObjects, and annotations in hidden layers
+	Warnings          []string `json:"warnings,omitempty"`
+	OutputPath        string   `json:"output_path,omitempty"`
+	Data              string   `json:"data,omitempty"` // Base64-encoded document when no output directory was given
+}
+
+// PDFSecurityScanRequest represents a request to scan a PDF for active content
+type PDFSecurityScanRequest struct {
+	Path string `json:"path"`
+}
+
+// SecurityFinding is a piece of active content or an embedded file found by a security scan
+type SecurityFinding struct {
+	Category  string `json:"category"` // One of the SecurityFinding constants
+	Risk      string `json:"risk"`     // low, medium, or high
+	Location  string `json:"location"` // Where it is attached and what runs it, e.g. "document open"
+	Page      int    `json:"page,omitempty"`
+	Automatic bool   `json:"automatic,omitempty"` // Runs without the user acting on the content
+	Detail    string `json:"detail"`              // Script excerpt, address, or file name
+}
+
+// PDFSecurityScanResult represents the active content of a PDF and the risk it poses
+type PDFSecurityScanResult struct {
+	Path                string            `json:"path"`
+	Risk                string            `json:"risk"` // none, low, medium, or high
+	Summary             string            `json:"summary"`
+	JavaScriptActions   int               `json:"javascript_actions"`
+	AutomaticJavaScript int               `json:"automatic_javascript"` // Scripts run on open, close, and page events
+	LaunchActions       int               `json:"launch_actions"`
+	URIActions          int               `json:"uri_actions"`
+	SuspiciousURIs      int               `json:"suspicious_uris"`
+	EmbeddedFiles       int               `json:"embedded_files"`
+	EmbeddedExecutables int               `json:"embedded_executables"`
+	XFA                 bool              `json:"xfa"` // The form is an XFA form
+	XFAScripts          int               `json:"xfa_scripts"`
+	Findings            []SecurityFinding `json:"findings"`
+}
+
+// PDFInspectObjectRequest represents a request to inspect one indirect object of a PDF
+type PDFInspectObjectRequest struct {
+	Path       string `json:"path"`
+	Object     int    `json:"object"`
+	Generation int    `json:"generation,omitempty"`
+	MaxBytes   int    `json:"max_bytes,omitempty"` // Stream data to include, 1024 bytes by default
+}
+
+// InspectedStream describes the data of a stream object
+type InspectedStream struct {
+	Filters       []string `json:"filters,omitempty"`
+	Length        int64    `json:"length"`                   // Stored length from the stream dictionary
+	DecodedLength int64    `json:"decoded_length,omitempty"` // Length after decoding, when decoded
+	Decoded       bool     `json:"decoded"`                  // Whether Data holds decoded rather than stored bytes
+	Data          string   `json:"data,omitempty"`
+	DataEncoding  string   `json:"data_encoding,omitempty"` // text or hex
+	DataTruncated bool     `json:"data_truncated,omitempty"`
+	DecodeError   string   `json:"decode_error,omitempty"` // Why the data is shown as stored
+}
+
+// PDFInspectObjectResult represents one indirect object of a PDF as the parser reads it
+type PDFInspectObjectResult struct {
+	Path         string           `json:"path"`
+	Object       int              `json:"object"`
+	Generation   int              `json:"generation"`
+	Offset       int64            `json:"offset,omitempty"`        // File offset of objects stored directly
+	ObjectStream int              `json:"object_stream,omitempty"` // Object stream holding compressed objects
+	Kind         string           `json:"kind"`                    // null, boolean, integer, real, string, ...
+	Type         string           `json:"type,omitempty"`
+	Subtype      string           `json:"subtype,omitempty"`
+	Value        any              `json:"value"` // The object, or a stream's dictionary, in JSON form
+	Truncated    bool             `json:"truncated,omitempty"`
+	Stream       *InspectedStream `json:"stream,omitempty"`
+}
+
+// Annotation types of pdf_annotate
+const (
+	AnnotationHighlight = "highlight" // Translucent highlight over text
+	AnnotationNote      = "note"      // Sticky note shown as an icon
+	AnnotationRectangle = "rectangle" // Rectangle outline
+)
+
+// AnnotationSpec is an annotation to add, placed at a region of a page or around every
+// occurrence of a phrase. Regions are in PDF points from the lower-left corner.
+type AnnotationSpec struct {
+	Type    string  `json:"type"`
+	Page    int     `json:"page,omitempty"` // Page of the region; limits a phrase search to one page
+	X       float64 `json:"x,omitempty"`
+	Y       float64 `json:"y,omitempty"`
+	Width   float64 `json:"width,omitempty"`
+	Height  float64 `json:"height,omitempty"`
+	Text    string  `json:"text,omitempty"`    // Phrase to annotate wherever it occurs, instead of a region
+	Comment string  `json:"comment,omitempty"` // Text shown in the annotation's popup
+	Color   string  `json:"color,omitempty"`   // Hex color such as "#ffcc00"
+	Author  string  `json:"author,omitempty"`
+}
+
+// PDFAnnotateRequest represents a request to add annotations to a PDF
+type PDFAnnotateRequest struct {
+	Path          string           `json:"path"`
+	Annotations   []AnnotationSpec `json:"annotations"`
+	CaseSensitive bool             `json:"case_sensitive,omitempty"`
+	WholeWord     bool             `json:"whole_word,omitempty"`
+	OutputDir     string           `json:"output_dir,omitempty"` // Save the document here instead of returning it
+}
+
+// AddedAnnotation is an annotation written to the document
+type AddedAnnotation struct {
+	Type    string  `json:"type"`
+	Page    int     `json:"page"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Width   float64 `json:"width"`
+	Height  float64 `json:"height"`
+	Text    string  `json:"text,omitempty"` // Matched text, for annotations placed around a phrase
+	Comment string  `json:"comment,omitempty"`
+}
+
+// PDFAnnotateResult represents an annotated document
+type PDFAnnotateResult struct {
+	Path        string            `json:"path"`
+	MIMEType    string            `json:"mime_type"`
+	Pages       []int             `json:"pages"` // Pages with annotations added
+	Annotations []AddedAnnotation `json:"annotations"`
+	Unmatched   []string          `json:"unmatched,omitempty"` // Phrases found nowhere, which added nothing
+	Size        int               `json:"size"`                // Document size in bytes
+	OutputPath  string            `json:"output_path,omitempty"`
+	Data        string            `json:"data,omitempty"` // Base64-encoded document when no output directory was given
+}
+
+// PDFGetPermissionsRequest represents a request for a PDF's encryption and permissions
+type PDFGetPermissionsRequest struct {
+	Path string `json:"path"`
+}
+
+// EncryptionInfo describes the encryption dictionary of a PDF
+type EncryptionInfo struct {
+	Filter            string `json:"filter"`               // Security handler, Standard for passwords
+	SubFilter         string `json:"sub_filter,omitempty"` // Public-key handler format
+	Version           int    `json:"version"`              // /V, the algorithm
+	Revision          int    `json:"revision"`             // /R, the revision of the security handler
+	Algorithm         string `json:"algorithm"`            // RC4, AES-128, AES-256, or none
+	KeyLength         int    `json:"key_length"`           // Bits
+	PermissionFlags   int32  `json:"permission_flags"`     // The raw /P entry
+	MetadataEncrypted bool   `json:"metadata_encrypted"`
+}
+
+// DocumentPermissions are the operations the permissions of a PDF allow
+type DocumentPermissions struct {
+	Print                   bool `json:"print"`
+	PrintHighQuality        bool `json:"print_high_quality"`
+	Modify                  bool `json:"modify"`
+	Copy                    bool `json:"copy"`     // Copying or otherwise extracting text and graphics
+	Annotate                bool `json:"annotate"` // Adding or changing annotations and filling forms
+	FillForms               bool `json:"fill_forms"`
+	AccessibilityExtraction bool `json:"accessibility_extraction"` // Extracting text for accessibility
+	Assemble                bool `json:"assemble"`                 // Inserting, rotating, or deleting pages
+}
+
+// PDFGetPermissionsResult represents the encryption and permissions of a PDF
+type PDFGetPermissionsResult struct {
+	Path             string              `json:"path"`
+	Encrypted        bool                `json:"encrypted"`
+	RequiresPassword bool                `json:"requires_password,omitempty"` // The empty password does not open it
+	Encryption       *EncryptionInfo     `json:"encryption,omitempty"`
+	Permissions      DocumentPermissions `json:"permissions"`
+	// Whether the server can extract text: allowed, accessibility_only, denied, or password_required
+	TextExtraction string `json:"text_extraction"`
+	Reason         string `json:"reason"`
+}
+
+// PDFExportCommentsRequest represents a request to export a PDF's review comments
+type PDFExportCommentsRequest struct {
+	Path       string `json:"path"`
+	Format     string `json:"format,omitempty"` // json or markdown; json when empty
+	Pages      []int  `json:"pages,omitempty"`
+	OutputPath string `json:"output_path,omitempty"` // Save the comments here instead of returning them
+}
+
+// ReviewComment is a comment or markup annotation with the replies to it
+type ReviewComment struct {
+	ID         string          `json:"id"`   // ID of the annotation element
+	Type       string          `json:"type"` // Annotation subtype, such as Highlight or Text
+	Page       int             `json:"page"`
+	PageLabel  string          `json:"page_label,omitempty"`
+	Author     string          `json:"author,omitempty"`
+	Subject    string          `json:"subject,omitempty"`
+	Date       string          `json:"date,omitempty"` // Last modified, or created, in RFC 3339
+	Text       string          `json:"text,omitempty"`
+	QuotedText string          `json:"quoted_text,omitempty"` // Text the markup covers
+	Replies    []ReviewComment `json:"replies,omitempty"`     // Oldest first
+}
+
+// PDFExportCommentsResult represents the threads of review comments in a PDF
+type PDFExportCommentsResult struct {
+	Path       string          `json:"path"`
+	Format     string          `json:"format"`
+	Threads    []ReviewComment `json:"threads"`
+	Comments   int             `json:"comments"`          // Comments in every thread, replies included
+	Authors    []string        `json:"authors,omitempty"` // In the order they first appear
+	Content    string          `json:"content,omitempty"` // The comments when no output path was given
+	OutputPath string          `json:"output_path,omitempty"`
+}
+
+// Form data formats of pdf_export_form_data and pdf_import_form_data
+const (
+	FormDataJSON = "json" // An object mapping fully qualified field names to values
+	FormDataFDF  = "fdf"  // Forms Data Format
+	FormDataXFDF = "xfdf" // XML Forms Data Format
+)
+
+// FormFieldValue is a form field and the value it holds
+type FormFieldValue struct {
+	Name     string   `json:"name"`             // Fully qualified name
+	Type     string   `json:"type"`             // text, checkbox, radio, choice, button, signature, or group
+	Parent   string   `json:"parent,omitempty"` // Fully qualified name of the parent field
+	Kids     []string `json:"kids,omitempty"`   // Fully qualified names of the child fields
+	Value    string   `json:"value,omitempty"`
+	Values   []string `json:"values,omitempty"`  // Selections of a multiple-selection list
+	Options  []string `json:"options,omitempty"` // Choice export values, or the on states of buttons
+	ReadOnly bool     `json:"read_only,omitempty"`
+	Page     int      `json:"page,omitempty"` // Page of the field's first widget
+	// Checkbox and radio button state
+	Checked     bool         `json:"checked,omitempty"`
+	ExportValue string       `json:"export_value,omitempty"` // What the on button stands for
+	Buttons     []FormButton `json:"buttons,omitempty"`      // Each widget, such as the kids of a radio group
+	// Signature field state, and the signature whose signing locked this field
+	Signature *FormSignature `json:"signature,omitempty"`
+	LockedBy  string         `json:"locked_by,omitempty"`
+	// JavaScript of the field's keystroke, format, validate, and calculate actions, never
+	// run. A calculated field's value is computed by viewers, so Value may be stale.
+	Actions    map[string]string `json:"actions,omitempty"`
+	Calculated bool              `json:"calculated,omitempty"`
+	// Text field layout: a comb field spaces its value over CombCells cells, one character
+	// each, and a rich text field gives its styled text, whose plain text is Value
+	Multiline       bool           `json:"multiline,omitempty"`
+	Password        bool           `json:"password,omitempty"`
+	DoNotSpellCheck bool           `json:"do_not_spell_check,omitempty"`
+	CombCells       int            `json:"comb_cells,omitempty"`
+	RichText        []FormTextSpan `json:"rich_text,omitempty"`
+	// Choice field options, each with the export value the field holds and the text it
+	// shows, and the field's layout
+	Choices     []FormChoice `json:"choices,omitempty"`
+	Combo       bool         `json:"combo,omitempty"`    // A drop-down combo box rather than a list
+	Editable    bool         `json:"editable,omitempty"` // A combo box taking text of the user's own
+	Sorted      bool         `json:"sorted,omitempty"`
+	MultiSelect bool         `json:"multi_select,omitempty"`
+}
+
+// FormChoice is an option of a choice field
+type FormChoice struct {
+	ExportValue string `json:"export_value"`
+	Display     string `json:"display"`
+	Selected    bool   `json:"selected,omitempty"`
+}
+
+// FormTextSpan is a run of a rich text field's value in one style
+type FormTextSpan struct {
+	Text      string  `json:"text"`
+	Bold      bool    `json:"bold,omitempty"`
+	Italic    bool    `json:"italic,omitempty"`
+	Underline bool    `json:"underline,omitempty"`
+	Font      string  `json:"font,omitempty"`
+	Size      float64 `json:"size,omitempty"`  // Points
+	Color     string  `json:"color,omitempty"` // As written, such as #FF0000
+}
+
+// FormSignature describes a signature field: whether it is signed, what the signer stated,
+// and the fields it locks. Signatures are read, not verified.
+type FormSignature struct {
+	Signed      bool     `json:"signed"`
+	Signer      string   `json:"signer,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+	Location    string   `json:"location,omitempty"`
+	ContactInfo string   `json:"contact_info,omitempty"`
+	SigningTime string   `json:"signing_time,omitempty"` // RFC 3339
+	Filter      string   `json:"filter,omitempty"`       // Signature handler, e.g. Adobe.PPKLite
+	SubFilter   string   `json:"sub_filter,omitempty"`   // Encoding, e.g. adbe.pkcs7.detached
+	LockAction  string   `json:"lock_action,omitempty"`  // All, Include, or Exclude
+	LockFields  []string `json:"lock_fields,omitempty"`
+	Certifies   bool     `json:"certifies,omitempty"`   // A certification (DocMDP) signature
+	Permissions int      `json:"permissions,omitempty"` // 1 no changes, 2 form filling and signing, 3 also annotations
+}
+
+// FormButton is one widget of a checkbox or radio button field
+type FormButton struct {
+	State       string `json:"state,omitempty"` // Appearance state shown when on
+	ExportValue string `json:"export_value,omitempty"`
+	Checked     bool   `json:"checked"`
+	Page        int    `json:"page,omitempty"`
+}
+
+// PDFExportFormDataRequest represents a request to export the values of a PDF's form
+type PDFExportFormDataRequest struct {
+	Path       string `json:"path"`
+	Format     string `json:"format,omitempty"`      // json (default), fdf, or xfdf
+	OutputPath string `json:"output_path,omitempty"` // Save the form data here instead of returning it
+	// List only the terminal fields, which hold the values, leaving out the groups above them
+	TerminalOnly bool `json:"terminal_only,omitempty"`
+}
+
+// PDFExportFormDataResult represents the exported form data of a PDF
+type PDFExportFormDataResult struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	// Every field in field tree order, including buttons, signatures, and groups, which are
+	// not exported
+	Fields   []FormFieldValue `json:"fields"`
+	Exported int              `json:"exported"`
+	// The form's /SigFlags: the document is signed, and changes must be appended to keep
+	// the signatures valid
+	SignaturesExist bool `json:"signatures_exist,omitempty"`
+	AppendOnly      bool `json:"append_only,omitempty"`
+	// Fields in the order viewers calculate them, from the form's /CO array
+	CalculationOrder []string `json:"calculation_order,omitempty"`
+	// The pages showing fields, each with its fields in the order the Tab key visits them
+	Pages      []FormPage `json:"pages,omitempty"`
+	Content    string     `json:"content,omitempty"` // The form data when no output path was given
+	OutputPath string     `json:"output_path,omitempty"`
+}
+
+// FormPage lists the form fields a page shows in tab order
+type FormPage struct {
+	Page     int      `json:"page"`
+	Tabs     string   `json:"tabs,omitempty"` // The page's /Tabs entry: R, C, S, A, or W
+	TabOrder string   `json:"tab_order"`      // row, column, structure, or annotation
+	Fields   []string `json:"fields"`         // Fully qualified names in tab order
+}
+
+// PDFImportFormDataRequest represents a request to fill a PDF's form from form data
+type PDFImportFormDataRequest struct {
+	Path      string `json:"path"`
+	Data      string `json:"data,omitempty"`       // Form data, unless read from DataPath
+	DataPath  string `json:"data_path,omitempty"`  // File holding the form data
+	Format    string `json:"format,omitempty"`     // json, fdf, or xfdf; detected from the data when empty
+	OutputDir string `json:"output_dir,omitempty"` // Save the document here instead of returning it
+}
+
+// SkippedFormValue is a value of the form data that was not filled in
+type SkippedFormValue struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// PDFImportFormDataResult represents a PDF filled from form data
+type PDFImportFormDataResult struct {
+	Path       string             `json:"path"`
+	Format     string             `json:"format"`
+	MIMEType   string             `json:"mime_type"`
+	Filled     []FormFieldValue   `json:"filled"`              // Fields given a value
+	Unmatched  []string           `json:"unmatched,omitempty"` // Names with no field in the document
+	Skipped    []SkippedFormValue `json:"skipped,omitempty"`   // Values the field cannot hold
+	Size       int                `json:"size"`                // Document size in bytes
+	OutputPath string             `json:"output_path,omitempty"`
+	Data       string             `json:"data,omitempty"` // Base64-encoded document when no output directory was given
+}
@@ -1,11 +1,11 @@
 package pdf
 
 import (
-	"fmt"
 	"os"
 	"strings"
 
-	"github.com/ledongthuc/pdf"
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/core"
+	pdferrors "github.com/a3tai/mcp-pdf-reader/internal/pdf/errors"
 )
 
 // Validator handles PDF file validation operations
@@ -37,49 +37,14 @@ func (v *Validator) ValidateFile(req PDFValidateFileRequest) (*PDFValidateFileRe
 	return result, nil
 }
 
-// validatePDFFile performs detailed validation on a PDF file
+// validatePDFFile performs detailed validation on a PDF file: the checks of
+// ValidateFileInfo, and that the file parses as a PDF
 func (v *Validator) validatePDFFile(filePath string) error {
-	if filePath == "" {
-		return fmt.Errorf("path cannot be empty")
-	}
-
-	// Check if file exists and get basic info
-	fileInfo, err := os.Stat(filePath)
-	if os.IsNotExist(err) {
-		return fmt.Errorf("file does not exist: %s", filePath)
-	}
+	doc, err := core.Open(filePath, core.Options{Validate: v.ValidateFileInfo})
 	if err != nil {
-		return fmt.Errorf("cannot access file: %w", err)
-	}
-
-	// Check if it's a regular file (not a directory)
-	if fileInfo.IsDir() {
-		return fmt.Errorf("path is a directory, not a file: %s", filePath)
+		return err
 	}
-
-	// Check file extension
-	if !strings.HasSuffix(strings.ToLower(filePath), ".pdf") {
-		return fmt.Errorf("file is not a PDF: %s", filePath)
-	}
-
-	// Check file size
-	if fileInfo.Size() == 0 {
-		return fmt.Errorf("file is empty: %s", filePath)
-	}
-
-	if fileInfo.Size() > v.maxFileSize {
-		return fmt.Errorf("file too large: %d bytes (max: %d bytes)",
-			fileInfo.Size(), v.maxFileSize)
-	}
-
-	// Try to open the PDF to validate it's a valid PDF file
-	f, _, err := pdf.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("invalid PDF file: %w", err)
-	}
-	defer f.Close()
-
-	return nil
+	return doc.Close()
 }
 
 // IsValidPDF performs a quick check to see if a file is a valid PDF
@@ -90,19 +55,19 @@ func (v *Validator) IsValidPDF(filePath string) bool {
 // ValidateFileInfo performs basic validation on file info without opening the PDF
 func (v *Validator) ValidateFileInfo(filePath string, fileInfo os.FileInfo) error {
 	if fileInfo.IsDir() {
-		return fmt.Errorf("path is a directory, not a file: %s", filePath)
+		return pdferrors.New(pdferrors.InvalidArgument, "path is a directory, not a file: %s", filePath)
 	}
 
 	if !strings.HasSuffix(strings.ToLower(filePath), ".pdf") {
-		return fmt.Errorf("file is not a PDF: %s", filePath)
+		return pdferrors.New(pdferrors.Corrupt, "file is not a PDF: %s", filePath)
 	}
 
 	if fileInfo.Size() == 0 {
-		return fmt.Errorf("file is empty: %s", filePath)
+		return pdferrors.New(pdferrors.Corrupt, "file is empty: %s", filePath)
 	}
 
 	if fileInfo.Size() > v.maxFileSize {
-		return fmt.Errorf("file too large: %d bytes (max: %d bytes)",
+		return pdferrors.New(pdferrors.TooLarge, "file too large: %d bytes (max: %d bytes)",
 			fileInfo.Size(), v.maxFileSize)
 	}
 
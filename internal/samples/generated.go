@@ -0,0 +1,249 @@
+package samples
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Samples of the corpus are built here when no public file with a stable address covers
+// their category. They are written uncompressed, so their bytes, and with them their
+// checksums, do not depend on the compressor of the Go release that builds them.
+
+// buildPDF lays out numbered objects, the first being the catalog, into a PDF file with a
+// cross-reference table
+func buildPDF(objects []string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// stream formats a stream object holding data
+func stream(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+// textSample builds a single page of Helvetica text
+func textSample() []byte {
+	lines := []string{
+		"Sample Document",
+		"This single page of text exercises reading, searching, and extracting.",
+		"It uses one of the standard fonts, so every viewer shows it the same way.",
+	}
+	var content bytes.Buffer
+	for i, line := range lines {
+		size := 12
+		if i == 0 {
+			size = 18
+		}
+		fmt.Fprintf(&content, "BT /F1 %d Tf 72 %d Td (%s) Tj ET\n", size, 720-i*24, line)
+	}
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> " +
+			"/Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		stream("", content.Bytes()),
+	})
+}
+
+// multilingualLines are pangrams and sentences in several scripts, written left to right
+// in logical order
+var multilingualLines = []string{
+	"English: The quick brown fox jumps over the lazy dog.",
+	"Français : Le cœur déçu mais l'âme plutôt naïve.",
+	"Deutsch: Zwölf Boxkämpfer jagen Viktor quer über den Sylter Deich.",
+	"Ελληνικά: Ξεσκεπάζω την ψυχοφθόρα βδελυγμία.",
+	"Русский: Съешь же ещё этих мягких французских булок.",
+	"العربية: نص حكيم له سر قاطع وذو شأن عظيم",
+	"中文：我能吞下玻璃而不伤身体。",
+	"日本語：いろはにほへと ちりぬるを",
+	"한국어: 다람쥐 헌 쳇바퀴에 타고파",
+}
+
+// multilingualSample builds a page of text in several scripts through one composite font
+// whose character IDs a ToUnicode map gives the text of. The font is not embedded, so
+// viewers substitute one; text extraction needs only the map.
+func multilingualSample() []byte {
+	cids := make(map[rune]int)
+	var runes []rune
+	var content bytes.Buffer
+	for i, line := range multilingualLines {
+		fmt.Fprintf(&content, "BT /F1 12 Tf 72 %d Td <", 720-i*24)
+		for _, r := range line {
+			if cids[r] == 0 {
+				runes = append(runes, r)
+				cids[r] = len(runes)
+			}
+			fmt.Fprintf(&content, "%04X", cids[r])
+		}
+		content.WriteString("> Tj ET\n")
+	}
+
+	// Ideographs and kana are set a full em wide, other characters a little over half
+	var widths strings.Builder
+	for i, r := range runes {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+			(r >= 0xFF00 && r <= 0xFFEF) || (r >= 0x3000 && r <= 0x303F) {
+			fmt.Fprintf(&widths, "%d [1000] ", i+1)
+		}
+	}
+
+	var cmap strings.Builder
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for start := 0; start < len(runes); start += 100 {
+		end := min(start+100, len(runes))
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", end-start)
+		for i := start; i < end; i++ {
+			fmt.Fprintf(&cmap, "<%04X> <", i+1)
+			for _, unit := range utf16.Encode([]rune{runes[i]}) {
+				fmt.Fprintf(&cmap, "%04X", unit)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R /Lang (mul) >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> " +
+			"/Contents 8 0 R >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /NotoSans /Encoding /Identity-H " +
+			"/DescendantFonts [5 0 R] /ToUnicode 7 0 R >>",
+		"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /NotoSans " +
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> " +
+			"/FontDescriptor 6 0 R /DW 600 /W [" + strings.TrimSpace(widths.String()) + "] /CIDToGIDMap /Identity >>",
+		"<< /Type /FontDescriptor /FontName /NotoSans /Flags 32 /FontBBox [0 -250 1000 900] " +
+			"/ItalicAngle 0 /Ascent 900 /Descent -250 /CapHeight 700 /StemV 80 >>",
+		stream("", []byte(cmap.String())),
+		stream("", content.Bytes()),
+	})
+}
+
+// Scanned page raster: a letter page at 100 dots per inch, in black and white
+const (
+	scanWidth  = 850
+	scanHeight = 1100
+	scanScale  = 3 // Dots per pixel of the bitmap font
+)
+
+// scannedLines are the words drawn into the scanned page
+var scannedLines = []string{
+	"SCANNED SAMPLE PAGE",
+	"THIS PAGE HAS NO TEXT LAYER",
+	"ITS WORDS ARE PIXELS OF ONE IMAGE",
+	"RECOGNIZE THEM WITH OCR",
+	"PAGE 1 OF 1",
+}
+
+// scannedSample builds a page holding only an image of text, the way a scanner without
+// text recognition writes one: a 1-bit raster, slightly rotated as paper fed askew is, with
+// specks of dust
+func scannedSample() []byte {
+	ink := make([][]bool, scanHeight)
+	for y := range ink {
+		ink[y] = make([]bool, scanWidth)
+	}
+	for i, line := range scannedLines {
+		top := 120 + i*12*scanScale
+		for j, r := range line {
+			glyph := bitmapFont[r]
+			for row, bits := range glyph {
+				for col := 0; col < 5; col++ {
+					if bits&(1<<(4-col)) == 0 {
+						continue
+					}
+					for dy := 0; dy < scanScale; dy++ {
+						for dx := 0; dx < scanScale; dx++ {
+							x := 100 + (j*6+col)*scanScale + dx
+							y := top + row*scanScale + dy
+							// A skew of one dot in every 80 across the page
+							y += x / 80
+							if x < scanWidth && y < scanHeight {
+								ink[y][x] = true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	// Dust from a fixed linear congruential sequence, so every build is the same
+	seed := uint32(4761)
+	for range 600 {
+		seed = seed*1664525 + 1013904223
+		x := int(seed>>8) % scanWidth
+		seed = seed*1664525 + 1013904223
+		y := int(seed>>8) % scanHeight
+		ink[y][x] = true
+	}
+
+	stride := (scanWidth + 7) / 8
+	pixels := make([]byte, stride*scanHeight)
+	for y, row := range ink {
+		for x, black := range row {
+			// Samples of 1 are white in DeviceGray
+			if !black {
+				pixels[y*stride+x/8] |= 0x80 >> (x % 8)
+			}
+		}
+	}
+
+	content := []byte("q 612 0 0 792 0 0 cm /Scan Do Q")
+	return buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Scan 4 0 R >> >> " +
+			"/Contents 5 0 R >>",
+		stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray "+
+			"/BitsPerComponent 1", scanWidth, scanHeight), pixels),
+		stream("", content),
+	})
+}
+
+// bitmapFont holds 5x7 glyphs for the characters of scannedLines, one row of five bits per
+// byte from the top
+var bitmapFont = map[rune][7]byte{
+	' ': {},
+	'1': {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'A': {0x0E, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'B': {0x1E, 0x11, 0x11, 0x1E, 0x11, 0x11, 0x1E},
+	'C': {0x0E, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0E},
+	'D': {0x1E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x1E},
+	'E': {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x1F},
+	'F': {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x10},
+	'G': {0x0E, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0F},
+	'H': {0x11, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'I': {0x0E, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'L': {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1F},
+	'M': {0x11, 0x1B, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N': {0x11, 0x19, 0x15, 0x13, 0x11, 0x11, 0x11},
+	'O': {0x0E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'P': {0x1E, 0x11, 0x11, 0x1E, 0x10, 0x10, 0x10},
+	'R': {0x1E, 0x11, 0x11, 0x1E, 0x14, 0x12, 0x11},
+	'S': {0x0F, 0x10, 0x10, 0x0E, 0x01, 0x01, 0x1E},
+	'T': {0x1F, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'W': {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0A},
+	'X': {0x11, 0x11, 0x0A, 0x04, 0x0A, 0x11, 0x11},
+	'Y': {0x11, 0x11, 0x0A, 0x04, 0x04, 0x04, 0x04},
+	'Z': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1F},
+}
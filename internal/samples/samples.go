@@ -0,0 +1,285 @@
+package samples
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSubdirectory is the directory, relative to the PDF directory, that receives samples
+	DefaultSubdirectory = "samples"
+
+	// Sample categories
+	CategoryText   = "text"
+	CategoryForms  = "forms"
+	CategoryTables = "tables"
+	CategoryScans  = "scans"
+	CategoryI18n   = "multi-language"
+
+	// defaultTimeout bounds the time spent downloading a single sample
+	defaultTimeout = 60 * time.Second
+	// filePerm is the permission used for downloaded samples
+	filePerm = 0o644
+	// dirPerm is the permission used for the samples directory
+	dirPerm = 0o750
+)
+
+// pdfMagic is the header every downloaded sample must start with
+var pdfMagic = []byte("%PDF-")
+
+// Sample describes a public PDF in the sample corpus, downloaded from its URL or, for
+// samples of the built-in corpus without one, built by the downloader
+type Sample struct {
+	Name        string `json:"name"`
+	URL         string `json:"url,omitempty"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	SHA256      string `json:"sha256,omitempty"` // Checksum verified before the sample is stored
+
+	build func() []byte // Builds the sample when it has no URL
+}
+
+// FileName returns the name of the file the sample is stored as
+func (s Sample) FileName() string {
+	return s.Name + ".pdf"
+}
+
+// Status values reported for each sample
+const (
+	StatusDownloaded = "downloaded"
+	StatusBuilt      = "built"
+	StatusExisting   = "existing"
+	StatusFailed     = "failed"
+)
+
+// Result reports the outcome of downloading a single sample
+type Result struct {
+	Sample Sample `json:"sample"`
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Size   int64  `json:"size,omitempty"`
+	SHA256 string `json:"sha256,omitempty"` // Checksum of the stored file, when it was just stored
+	Error  string `json:"error,omitempty"`
+}
+
+// DefaultCorpus returns the curated list of PDFs used for onboarding and fixtures. Public
+// files are addressed by a release tag or a dated revision, so their contents do not change
+// under them; the text, scanned, and multi-language samples are built with pinned checksums.
+func DefaultCorpus() []Sample {
+	return []Sample{
+		{
+			Name:        "text-sample",
+			Category:    CategoryText,
+			Description: "Minimal single-page text document in a standard font",
+			SHA256:      "ba410ded92a7a4184380a96a1c004868fdec248bd06bac8e4f69da10a7000d35",
+			build:       textSample,
+		},
+		{
+			Name: "tracemonkey-paper",
+			URL: "https://raw.githubusercontent.com/mozilla/pdf.js/v4.0.379/" +
+				"web/compressed.tracemonkey-pldi-09.pdf",
+			Category:    CategoryTables,
+			Description: "Two-column research paper with tables, figures, and references",
+		},
+		{
+			Name:        "irs-form-w9",
+			URL:         "https://www.irs.gov/pub/irs-prior/fw9--2024.pdf",
+			Category:    CategoryForms,
+			Description: "Fillable government form with text fields and checkboxes",
+		},
+		{
+			Name:        "irs-form-1040",
+			URL:         "https://www.irs.gov/pub/irs-prior/f1040--2023.pdf",
+			Category:    CategoryForms,
+			Description: "Multi-page fillable tax form with ruled tabular sections",
+		},
+		{
+			Name:        "scanned-page",
+			Category:    CategoryScans,
+			Description: "Page holding only a slightly skewed black and white image of text, for OCR",
+			SHA256:      "623dcbb1136bb4c8dd4e8794f522e1cefbab68ec1ba5393e6cd8c9a324314e66",
+			build:       scannedSample,
+		},
+		{
+			Name:        "multi-language",
+			Category:    CategoryI18n,
+			Description: "Text in Latin, Greek, Cyrillic, Arabic, Chinese, Japanese, and Korean scripts",
+			SHA256:      "c709b37521c3a3df6c19bb21a1acaa0307b444962a48f630e13dad7087278eab",
+			build:       multilingualSample,
+		},
+	}
+}
+
+// LoadManifest reads a JSON array of samples from a file, replacing the default corpus
+func LoadManifest(path string) ([]Sample, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var corpus []Sample
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	for i, sample := range corpus {
+		if sample.Name == "" || sample.URL == "" {
+			return nil, fmt.Errorf("manifest entry %d: name and url are required", i)
+		}
+		if strings.ContainsAny(sample.Name, `/\`) {
+			return nil, fmt.Errorf("manifest entry %d: invalid name %q", i, sample.Name)
+		}
+	}
+
+	return corpus, nil
+}
+
+// FilterByCategory returns the samples in the given category; an empty category returns all
+func FilterByCategory(corpus []Sample, category string) []Sample {
+	if category == "" {
+		return corpus
+	}
+
+	var filtered []Sample
+	for _, sample := range corpus {
+		if strings.EqualFold(sample.Category, category) {
+			filtered = append(filtered, sample)
+		}
+	}
+	return filtered
+}
+
+// Downloader fetches sample PDFs into a local directory
+type Downloader struct {
+	client      *http.Client
+	maxFileSize int64
+}
+
+// NewDownloader creates a downloader that rejects samples larger than maxFileSize
+func NewDownloader(maxFileSize int64) *Downloader {
+	return &Downloader{
+		client:      &http.Client{Timeout: defaultTimeout},
+		maxFileSize: maxFileSize,
+	}
+}
+
+// Download fetches each sample into dir, skipping files that already exist. Failures are
+// reported per sample so that one unavailable URL does not abort the whole corpus.
+func (d *Downloader) Download(ctx context.Context, dir string, corpus []Sample) ([]Result, error) {
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("cannot create samples directory %s: %w", dir, err)
+	}
+
+	results := make([]Result, 0, len(corpus))
+	for _, sample := range corpus {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results = append(results, d.downloadSample(ctx, dir, sample))
+	}
+
+	return results, nil
+}
+
+// downloadSample fetches a single sample, writing it atomically through a temporary file
+func (d *Downloader) downloadSample(ctx context.Context, dir string, sample Sample) Result {
+	target := filepath.Join(dir, sample.FileName())
+	result := Result{Sample: sample, Path: target}
+
+	if info, err := os.Stat(target); err == nil {
+		result.Status = StatusExisting
+		result.Size = info.Size()
+		return result
+	}
+
+	status := StatusDownloaded
+	var data []byte
+	var err error
+	if sample.build != nil {
+		status, data = StatusBuilt, sample.build()
+	} else {
+		data, err = d.fetch(ctx, sample)
+	}
+	if err == nil {
+		err = d.verify(sample, data)
+	}
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	tmp := target + ".part"
+	if err := os.WriteFile(tmp, data, filePerm); err != nil {
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("failed to write sample: %v", err)
+		return result
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("failed to store sample: %v", err)
+		return result
+	}
+
+	sum := sha256.Sum256(data)
+	result.Status = status
+	result.Size = int64(len(data))
+	result.SHA256 = hex.EncodeToString(sum[:])
+	return result
+}
+
+// fetch downloads the sample contents
+func (d *Downloader) fetch(ctx context.Context, sample Sample) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sample.URL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sample URL: %w", err)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, d.maxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	return data, nil
+}
+
+// verify checks a sample's contents against the size limit, the PDF header, and the
+// sample's checksum, if it has one
+func (d *Downloader) verify(sample Sample, data []byte) error {
+	if int64(len(data)) > d.maxFileSize {
+		return fmt.Errorf("sample too large (max: %d bytes)", d.maxFileSize)
+	}
+
+	if !bytes.HasPrefix(data, pdfMagic) {
+		return fmt.Errorf("sample is not a PDF")
+	}
+
+	if sample.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), sample.SHA256) {
+			return fmt.Errorf("checksum mismatch")
+		}
+	}
+
+	return nil
+}
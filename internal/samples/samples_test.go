@@ -0,0 +1,264 @@
+package samples
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a3tai/mcp-pdf-reader/internal/pdf/extraction"
+	"github.com/ledongthuc/pdf"
+)
+
+const testPDF = "%PDF-1.4\n% sample\n%%EOF\n"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sample.pdf":
+			w.Write([]byte(testPDF))
+		case "/page.html":
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloader_Download(t *testing.T) {
+	server := newTestServer(t)
+	sum := sha256.Sum256([]byte(testPDF))
+
+	corpus := []Sample{
+		{Name: "good", URL: server.URL + "/sample.pdf", Category: CategoryText},
+		{Name: "verified", URL: server.URL + "/sample.pdf", SHA256: hex.EncodeToString(sum[:])},
+		{Name: "bad-checksum", URL: server.URL + "/sample.pdf", SHA256: strings.Repeat("0", 64)},
+		{Name: "not-pdf", URL: server.URL + "/page.html"},
+		{Name: "missing", URL: server.URL + "/missing.pdf"},
+		{Name: "built", SHA256: hex.EncodeToString(sum[:]), build: func() []byte { return []byte(testPDF) }},
+		{Name: "built-bad-checksum", SHA256: strings.Repeat("0", 64), build: func() []byte { return []byte(testPDF) }},
+	}
+
+	dir := filepath.Join(t.TempDir(), DefaultSubdirectory)
+	downloader := NewDownloader(1024 * 1024)
+
+	results, err := downloader.Download(context.Background(), dir, corpus)
+	if err != nil {
+		t.Fatalf("Download() unexpected error = %v", err)
+	}
+
+	want := map[string]string{
+		"good":         StatusDownloaded,
+		"verified":     StatusDownloaded,
+		"bad-checksum": StatusFailed,
+		"not-pdf":      StatusFailed,
+		"missing":      StatusFailed,
+
+		"built":              StatusBuilt,
+		"built-bad-checksum": StatusFailed,
+	}
+	for _, result := range results {
+		if result.Status != want[result.Sample.Name] {
+			t.Errorf("Download() %s status = %s (%s), want %s",
+				result.Sample.Name, result.Status, result.Error, want[result.Sample.Name])
+		}
+		_, statErr := os.Stat(result.Path)
+		stored := result.Status == StatusDownloaded || result.Status == StatusBuilt
+		if stored && result.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("Download() %s checksum = %q, want the stored file's", result.Sample.Name, result.SHA256)
+		}
+		if exists := statErr == nil; exists != stored {
+			t.Errorf("Download() %s file exists = %v with status %s", result.Sample.Name, exists, result.Status)
+		}
+	}
+
+	// A second run keeps existing files
+	results, err = downloader.Download(context.Background(), dir, corpus[:1])
+	if err != nil {
+		t.Fatalf("Download() unexpected error = %v", err)
+	}
+	if results[0].Status != StatusExisting {
+		t.Errorf("Download() second run status = %s, want %s", results[0].Status, StatusExisting)
+	}
+}
+
+func TestDownloader_DownloadTooLarge(t *testing.T) {
+	server := newTestServer(t)
+	dir := t.TempDir()
+
+	results, err := NewDownloader(8).Download(context.Background(), dir, []Sample{
+		{Name: "large", URL: server.URL + "/sample.pdf"},
+	})
+	if err != nil {
+		t.Fatalf("Download() unexpected error = %v", err)
+	}
+	if results[0].Status != StatusFailed || !strings.Contains(results[0].Error, "too large") {
+		t.Errorf("Download() = %+v, want too large failure", results[0])
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		content   string
+		wantCount int
+		wantError bool
+	}{
+		{
+			name:      "valid manifest",
+			content:   `[{"name": "scan", "url": "https://example.com/scan.pdf", "category": "scans"}]`,
+			wantCount: 1,
+		},
+		{
+			name:      "missing url",
+			content:   `[{"name": "scan"}]`,
+			wantError: true,
+		},
+		{
+			name:      "path in name",
+			content:   `[{"name": "../scan", "url": "https://example.com/scan.pdf"}]`,
+			wantError: true,
+		},
+		{
+			name:      "invalid json",
+			content:   `{`,
+			wantError: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "manifest"+string(rune('a'+i))+".json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("Failed to write manifest: %v", err)
+			}
+
+			corpus, err := LoadManifest(path)
+			if tt.wantError {
+				if err == nil {
+					t.Error("LoadManifest() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadManifest() unexpected error = %v", err)
+			}
+			if len(corpus) != tt.wantCount {
+				t.Errorf("LoadManifest() count = %d, want %d", len(corpus), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestDefaultCorpus(t *testing.T) {
+	covered := map[string]bool{
+		CategoryText: false, CategoryForms: false, CategoryTables: false, CategoryScans: false, CategoryI18n: false,
+	}
+	names := make(map[string]bool)
+	for _, sample := range DefaultCorpus() {
+		if _, ok := covered[sample.Category]; !ok {
+			t.Errorf("sample %s has unknown category %q", sample.Name, sample.Category)
+		}
+		covered[sample.Category] = true
+		if names[sample.Name] {
+			t.Errorf("sample name %s is used twice", sample.Name)
+		}
+		names[sample.Name] = true
+
+		// Built samples must match their pinned checksums, and public files must be
+		// addressed by a tag or revision rather than a branch or the current edition
+		switch {
+		case sample.build != nil:
+			sum := sha256.Sum256(sample.build())
+			if got := hex.EncodeToString(sum[:]); got != sample.SHA256 {
+				t.Errorf("sample %s builds with checksum %s, pinned %s", sample.Name, got, sample.SHA256)
+			}
+		case sample.URL == "":
+			t.Errorf("sample %s has neither a URL nor a builder", sample.Name)
+		default:
+			for _, moving := range []string{"/master/", "/main/", "/irs-pdf/"} {
+				if strings.Contains(sample.URL, moving) {
+					t.Errorf("sample %s URL %s follows %s, whose contents change", sample.Name, sample.URL, moving)
+				}
+			}
+		}
+	}
+	for category, ok := range covered {
+		if !ok {
+			t.Errorf("no sample in category %s", category)
+		}
+	}
+}
+
+func TestBuiltSamples(t *testing.T) {
+	open := func(t *testing.T, data []byte) pdf.Page {
+		t.Helper()
+		reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("built sample does not parse: %v", err)
+		}
+		if reader.NumPage() != 1 {
+			t.Fatalf("built sample has %d pages, want 1", reader.NumPage())
+		}
+		return reader.Page(1)
+	}
+
+	text, err := extraction.PlainText(open(t, multilingualSample()))
+	if err != nil {
+		t.Fatalf("PlainText() of the multi-language sample error = %v", err)
+	}
+	if got := strings.Split(strings.TrimSpace(text), "\n"); !reflect.DeepEqual(got, multilingualLines) {
+		t.Errorf("multi-language sample text = %q, want %q", got, multilingualLines)
+	}
+
+	page := open(t, scannedSample())
+	if text, _ := extraction.PlainText(page); strings.TrimSpace(text) != "" {
+		t.Errorf("scanned sample has text %q, want only an image", text)
+	}
+	image := page.V.Key("Resources").Key("XObject").Key("Scan")
+	if image.Key("Width").Int64() != scanWidth || image.Key("BitsPerComponent").Int64() != 1 {
+		t.Errorf("scanned sample image = %v, want a %d dot wide black and white raster", image, scanWidth)
+	}
+	for _, line := range scannedLines {
+		for _, r := range line {
+			if _, ok := bitmapFont[r]; !ok {
+				t.Errorf("scanned line %q draws %q, which the bitmap font lacks", line, r)
+			}
+		}
+	}
+
+	text, err = extraction.PlainText(open(t, textSample()))
+	if err != nil || !strings.Contains(text, "Sample Document") {
+		t.Errorf("PlainText() of the text sample = %q, %v", text, err)
+	}
+}
+
+func TestFilterByCategory(t *testing.T) {
+	corpus := DefaultCorpus()
+
+	if got := FilterByCategory(corpus, ""); len(got) != len(corpus) {
+		t.Errorf("FilterByCategory(\"\") = %d samples, want %d", len(got), len(corpus))
+	}
+
+	forms := FilterByCategory(corpus, "FORMS")
+	if len(forms) == 0 {
+		t.Fatal("FilterByCategory(forms) returned no samples")
+	}
+	for _, sample := range forms {
+		if sample.Category != CategoryForms {
+			t.Errorf("FilterByCategory(forms) returned %s in category %s", sample.Name, sample.Category)
+		}
+	}
+}